@@ -5,14 +5,24 @@
 //   - includes: Analyze include directive relationships in RST files
 //   - usage: Find all files that use a target file
 //   - procedures: Analyze procedure variations and statistics
+//   - versions: Inventory versionadded, versionchanged, and deprecated directives
+//   - deprecated-directives: Scope migrations of deprecated and legacy directives
+//   - feedback-hotspots: Rank pages by negative feedback, weighted by code example count and staleness
+//   - encoding: Find invisible and look-alike characters that break copy-pasted code
+//   - duplicate-headings: Find duplicate headings within pages and duplicate page titles across pages
 //
 // Future subcommands could include analyzing cross-references, broken links, or content metrics.
 package analyze
 
 import (
+	deprecated_directives "github.com/mongodb/code-example-tooling/audit-cli/commands/analyze/deprecated-directives"
+	duplicate_headings "github.com/mongodb/code-example-tooling/audit-cli/commands/analyze/duplicate-headings"
+	"github.com/mongodb/code-example-tooling/audit-cli/commands/analyze/encoding"
+	feedback_hotspots "github.com/mongodb/code-example-tooling/audit-cli/commands/analyze/feedback-hotspots"
 	"github.com/mongodb/code-example-tooling/audit-cli/commands/analyze/includes"
 	"github.com/mongodb/code-example-tooling/audit-cli/commands/analyze/procedures"
 	"github.com/mongodb/code-example-tooling/audit-cli/commands/analyze/usage"
+	"github.com/mongodb/code-example-tooling/audit-cli/commands/analyze/versions"
 	"github.com/spf13/cobra"
 )
 
@@ -30,6 +40,11 @@ Currently supports:
   - includes: Analyze include directive relationships (forward dependencies)
   - usage: Find all files that use a target file (reverse dependencies)
   - procedures: Analyze procedure variations and statistics
+  - versions: Inventory versioned content directives and flag EOL versions
+  - deprecated-directives: Report deprecated directive usages and estimate migration effort
+  - feedback-hotspots: Rank pages whose code examples should be fixed first, using docs feedback
+  - encoding: Find smart quotes, non-breaking spaces, and invisible characters in code
+  - duplicate-headings: Find headings whose anchors collide and page titles repeated across pages
 
 Future subcommands may support analyzing cross-references, broken links, or content metrics.`,
 	}
@@ -38,6 +53,11 @@ Future subcommands may support analyzing cross-references, broken links, or cont
 	cmd.AddCommand(includes.NewIncludesCommand())
 	cmd.AddCommand(usage.NewUsageCommand())
 	cmd.AddCommand(procedures.NewProceduresCommand())
+	cmd.AddCommand(versions.NewVersionsCommand())
+	cmd.AddCommand(deprecated_directives.NewDeprecatedDirectivesCommand())
+	cmd.AddCommand(feedback_hotspots.NewFeedbackHotspotsCommand())
+	cmd.AddCommand(encoding.NewEncodingCommand())
+	cmd.AddCommand(duplicate_headings.NewDuplicateHeadingsCommand())
 
 	return cmd
 }
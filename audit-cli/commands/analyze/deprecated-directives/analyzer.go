@@ -0,0 +1,222 @@
+package deprecated_directives
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// AnalyzeDeprecatedDirectives scans a file or directory for usages of deprecated directives.
+//
+// Directories are scanned recursively, processing only .rst, .txt, and .md files. Usages are
+// grouped by directory, using the first depth components of each file's directory relative to
+// rootPath, and each usage is estimated to take its directive's minutes to migrate.
+//
+// Parameters:
+//   - rootPath: Path to the file or directory to scan
+//   - directives: The deprecated directives to look for
+//   - depth: Number of directory levels to group usages by (at least 1)
+//
+// Returns:
+//   - *Analysis: The analysis results
+//   - error: Any error encountered during analysis
+func AnalyzeDeprecatedDirectives(rootPath string, directives []DeprecatedDirective, depth int) (*Analysis, error) {
+	if depth < 1 {
+		return nil, fmt.Errorf("--depth must be at least 1, got %d", depth)
+	}
+	matchers, err := newMatchers(directives)
+	if err != nil {
+		return nil, err
+	}
+
+	fileInfo, err := os.Stat(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access path %s: %w", rootPath, err)
+	}
+
+	var files []string
+	baseDir := rootPath
+	if fileInfo.IsDir() {
+		allFiles, err := rst.TraverseDirectory(rootPath, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to traverse directory %s: %w", rootPath, err)
+		}
+		for _, file := range allFiles {
+			if rst.ShouldProcessFile(file) {
+				files = append(files, file)
+			}
+		}
+	} else {
+		files = []string{rootPath}
+		baseDir = filepath.Dir(rootPath)
+	}
+
+	analysis := &Analysis{
+		RootPath:    rootPath,
+		Directives:  make([]DirectiveSummary, 0),
+		Directories: make([]DirectorySummary, 0),
+		Usages:      make([]Usage, 0),
+	}
+	minutes := make(map[string]int, len(matchers))
+	for _, m := range matchers {
+		minutes[m.directive.Name] = m.directive.Minutes
+	}
+
+	for _, file := range files {
+		usages, err := findUsages(file, matchers)
+		if err != nil {
+			return nil, err
+		}
+		analysis.FilesScanned++
+		directory := groupDirectory(baseDir, file, depth)
+		for i := range usages {
+			usages[i].Directory = directory
+		}
+		analysis.Usages = append(analysis.Usages, usages...)
+	}
+
+	sort.SliceStable(analysis.Usages, func(i, j int) bool {
+		if analysis.Usages[i].FilePath != analysis.Usages[j].FilePath {
+			return analysis.Usages[i].FilePath < analysis.Usages[j].FilePath
+		}
+		return analysis.Usages[i].LineNum < analysis.Usages[j].LineNum
+	})
+
+	summarize(analysis, matchers, minutes)
+	return analysis, nil
+}
+
+// findUsages returns the usages of deprecated directives in a single file.
+func findUsages(filePath string, matchers []matcher) ([]Usage, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	var usages []Usage
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		matches := rst.AnyDirectiveRegex.FindStringSubmatch(scanner.Text())
+		if matches == nil {
+			continue
+		}
+
+		argument := strings.TrimSpace(matches[2])
+		m, ok := match(matchers, matches[1], argument)
+		if !ok {
+			continue
+		}
+		usages = append(usages, Usage{
+			FilePath: filePath,
+			LineNum:  lineNum,
+			Name:     m.directive.Name,
+			Argument: argument,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+
+	return usages, nil
+}
+
+// groupDirectory returns the first depth components of the file's directory relative to baseDir,
+// or "." for files directly in baseDir.
+func groupDirectory(baseDir string, filePath string, depth int) string {
+	relPath, err := filepath.Rel(baseDir, filepath.Dir(filePath))
+	if err != nil || relPath == "." {
+		return "."
+	}
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/")
+}
+
+// summarize fills in the totals and the per-directive and per-directory summaries from the usages.
+// Directives without usages are included so the report shows everything that was checked.
+func summarize(analysis *Analysis, matchers []matcher, minutes map[string]int) {
+	byName := make(map[string]*DirectiveSummary, len(matchers))
+	for _, m := range matchers {
+		analysis.Directives = append(analysis.Directives, DirectiveSummary{
+			Name:        m.directive.Name,
+			Replacement: m.directive.Replacement,
+			Effort:      m.directive.Effort,
+			Minutes:     m.directive.Minutes,
+		})
+	}
+	for i := range analysis.Directives {
+		byName[analysis.Directives[i].Name] = &analysis.Directives[i]
+	}
+
+	byDirectory := make(map[string]*DirectorySummary)
+	filesByName := make(map[string]map[string]bool)
+	filesByDirectory := make(map[string]map[string]bool)
+	affected := make(map[string]bool)
+	totalMinutes := 0
+
+	for _, usage := range analysis.Usages {
+		usageMinutes := minutes[usage.Name]
+		totalMinutes += usageMinutes
+		affected[usage.FilePath] = true
+
+		summary := byName[usage.Name]
+		summary.Usages++
+		if filesByName[usage.Name] == nil {
+			filesByName[usage.Name] = make(map[string]bool)
+		}
+		filesByName[usage.Name][usage.FilePath] = true
+
+		directory := byDirectory[usage.Directory]
+		if directory == nil {
+			directory = &DirectorySummary{Directory: usage.Directory, ByName: make(map[string]int)}
+			byDirectory[usage.Directory] = directory
+			filesByDirectory[usage.Directory] = make(map[string]bool)
+		}
+		directory.Usages++
+		directory.ByName[usage.Name]++
+		directory.Hours += float64(usageMinutes) / 60
+		filesByDirectory[usage.Directory][usage.FilePath] = true
+	}
+
+	for i := range analysis.Directives {
+		summary := &analysis.Directives[i]
+		summary.Files = len(filesByName[summary.Name])
+		summary.Hours = roundHours(float64(summary.Usages*summary.Minutes) / 60)
+	}
+	for name, directory := range byDirectory {
+		directory.Files = len(filesByDirectory[name])
+		directory.Hours = roundHours(directory.Hours)
+		analysis.Directories = append(analysis.Directories, *directory)
+	}
+
+	analysis.TotalUsages = len(analysis.Usages)
+	analysis.FilesAffected = len(affected)
+	analysis.TotalHours = roundHours(float64(totalMinutes) / 60)
+
+	sort.SliceStable(analysis.Directives, func(i, j int) bool {
+		return analysis.Directives[i].Hours > analysis.Directives[j].Hours
+	})
+	sort.Slice(analysis.Directories, func(i, j int) bool {
+		if analysis.Directories[i].Hours != analysis.Directories[j].Hours {
+			return analysis.Directories[i].Hours > analysis.Directories[j].Hours
+		}
+		return analysis.Directories[i].Directory < analysis.Directories[j].Directory
+	})
+}
+
+// roundHours rounds an estimate to one decimal place.
+func roundHours(hours float64) float64 {
+	return math.Round(hours*10) / 10
+}
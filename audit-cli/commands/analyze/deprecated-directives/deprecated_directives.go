@@ -0,0 +1,110 @@
+// Package deprecated_directives provides functionality for scoping deprecated directive migrations.
+//
+// This package implements the "analyze deprecated-directives" subcommand, which reports
+// usages of deprecated and legacy directives across RST files, with counts by directory
+// and an estimate of the effort to migrate them.
+package deprecated_directives
+
+import (
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// NewDeprecatedDirectivesCommand creates the deprecated-directives subcommand.
+//
+// This command scans a file or directory for deprecated directives and reports usages
+// by directive and by directory, with estimated migration effort.
+//
+// Usage:
+//
+//	analyze deprecated-directives /path/to/source
+//	analyze deprecated-directives /path/to/source --depth 2
+//	analyze deprecated-directives /path/to/source --list-all --format csv
+//	analyze deprecated-directives /path/to/source --list directives.json
+//
+// Flags:
+//   - --list: JSON file of deprecated directives to use instead of the built-in list
+//   - --depth: Number of directory levels to group usages by
+//   - --list-all: List every usage with its file and line number
+//   - --format: Output format (text, json, csv, or markdown)
+//   - --output-file: Write results to a file instead of stdout
+func NewDeprecatedDirectivesCommand() *cobra.Command {
+	var (
+		listFile   string
+		depth      int
+		listAll    bool
+		outputOpts output.Options
+	)
+
+	cmd := &cobra.Command{
+		Use:   "deprecated-directives [filepath]",
+		Short: "Report usages of deprecated directives and estimate the migration effort",
+		Long: `Report usages of deprecated and legacy directives in reStructuredText files.
+
+This command scans a file, or a directory recursively, for directives on the deprecated
+list and reports usages by directive and by directory, with an estimate of the effort to
+migrate them. The built-in list includes:
+  - .. cssclass:: and .. rst-class::
+  - .. admonition:: (untyped admonitions)
+  - .. code:: and .. sourcecode::
+  - .. only::
+  - .. tabs-drivers:: and .. tabs-platforms::
+  - steps-yaml: .. include:: of generated /includes/steps/ files
+
+Each directive has an effort level (low, medium, or high) that is estimated at 5, 15, or
+60 minutes per usage. Use --list to provide your own list as JSON.
+
+Examples:
+  # Summarize deprecated directives in a project
+  analyze deprecated-directives /path/to/manual/source
+
+  # Group usages by two directory levels
+  analyze deprecated-directives /path/to/manual/source --depth 2
+
+  # Write every usage to a CSV file for a migration backlog
+  analyze deprecated-directives /path/to/manual/source --list-all --format csv --output-file backlog.csv
+
+  # Use a custom directive list
+  analyze deprecated-directives /path/to/manual/source --list directives.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDeprecatedDirectives(args[0], listFile, depth, listAll, outputOpts)
+		},
+	}
+
+	cmd.Flags().StringVar(&listFile, "list", "", "JSON file of deprecated directives to use instead of the built-in list")
+	cmd.Flags().IntVar(&depth, "depth", 1, "Number of directory levels to group usages by")
+	cmd.Flags().BoolVar(&listAll, "list-all", false, "List every usage with its file and line number")
+	output.AddFlags(cmd, &outputOpts)
+
+	return cmd
+}
+
+// runDeprecatedDirectives executes the deprecated directive analysis operation.
+func runDeprecatedDirectives(path string, listFile string, depth int, listAll bool, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
+	directives := DefaultDirectives
+	if listFile != "" {
+		loaded, err := LoadDirectives(listFile)
+		if err != nil {
+			return err
+		}
+		directives = loaded
+	}
+
+	analysis, err := AnalyzeDeprecatedDirectives(path, directives, depth)
+	if err != nil {
+		return err
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return PrintAnalysis(w, analysis, listAll)
+}
@@ -0,0 +1,141 @@
+// Package deprecated_directives provides tests for the deprecated directive analysis functionality.
+package deprecated_directives
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestAnalyzeDeprecatedDirectives tests counting deprecated directives with the built-in list.
+func TestAnalyzeDeprecatedDirectives(t *testing.T) {
+	testDataDir := filepath.Join("..", "..", "..", "testdata", "analyze-deprecated-directives", "source")
+
+	analysis, err := AnalyzeDeprecatedDirectives(testDataDir, DefaultDirectives, 1)
+	if err != nil {
+		t.Fatalf("AnalyzeDeprecatedDirectives failed: %v", err)
+	}
+
+	if analysis.FilesScanned != 3 {
+		t.Errorf("Expected 3 files scanned, got %d", analysis.FilesScanned)
+	}
+	if analysis.FilesAffected != 3 {
+		t.Errorf("Expected 3 files affected, got %d", analysis.FilesAffected)
+	}
+
+	// 2 cssclass (one nested in a list-table), admonition, steps-yaml include, code, only, tabs-drivers.
+	// The include of a non-steps file and the typed note aren't counted.
+	if analysis.TotalUsages != 7 {
+		t.Errorf("Expected 7 usages, got %d", analysis.TotalUsages)
+	}
+
+	usages := make(map[string]int)
+	for _, summary := range analysis.Directives {
+		usages[summary.Name] = summary.Usages
+	}
+	expected := map[string]int{
+		"cssclass":     2,
+		"admonition":   1,
+		"steps-yaml":   1,
+		"code":         1,
+		"only":         1,
+		"tabs-drivers": 1,
+		"rst-class":    0,
+	}
+	for name, count := range expected {
+		if usages[name] != count {
+			t.Errorf("Expected %d %s usages, got %d", count, name, usages[name])
+		}
+	}
+
+	// 2*5 + 5 + 60 + 5 + 15 + 15 minutes
+	if analysis.TotalHours != 1.8 {
+		t.Errorf("Expected 1.8 estimated hours, got %v", analysis.TotalHours)
+	}
+	if analysis.Directives[0].Name != "steps-yaml" {
+		t.Errorf("Expected steps-yaml to be the most effort, got %s", analysis.Directives[0].Name)
+	}
+
+	directories := make(map[string]DirectorySummary)
+	for _, directory := range analysis.Directories {
+		directories[directory.Directory] = directory
+	}
+	if directories["."].Usages != 3 || directories["tutorial"].Usages != 3 || directories["reference"].Usages != 1 {
+		t.Errorf("Unexpected usages by directory: %+v", analysis.Directories)
+	}
+	if directories["tutorial"].Hours != 1.3 {
+		t.Errorf("Expected 1.3 hours for tutorial, got %v", directories["tutorial"].Hours)
+	}
+}
+
+// TestAnalyzeDeprecatedDirectivesDepth tests grouping usages by more than one directory level.
+func TestAnalyzeDeprecatedDirectivesDepth(t *testing.T) {
+	testDataDir := filepath.Join("..", "..", "..", "testdata", "analyze-deprecated-directives", "source")
+
+	analysis, err := AnalyzeDeprecatedDirectives(testDataDir, DefaultDirectives, 2)
+	if err != nil {
+		t.Fatalf("AnalyzeDeprecatedDirectives failed: %v", err)
+	}
+
+	found := false
+	for _, directory := range analysis.Directories {
+		if directory.Directory == "reference/operators" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected usages grouped under reference/operators, got %+v", analysis.Directories)
+	}
+
+	if _, err := AnalyzeDeprecatedDirectives(testDataDir, DefaultDirectives, 0); err == nil {
+		t.Error("Expected error for a depth of 0")
+	}
+}
+
+// TestLoadDirectives tests analyzing with a custom directive list.
+func TestLoadDirectives(t *testing.T) {
+	listFile := filepath.Join("..", "..", "..", "testdata", "analyze-deprecated-directives", "directives.json")
+	testDataDir := filepath.Join("..", "..", "..", "testdata", "analyze-deprecated-directives", "source")
+
+	directives, err := LoadDirectives(listFile)
+	if err != nil {
+		t.Fatalf("LoadDirectives failed: %v", err)
+	}
+
+	analysis, err := AnalyzeDeprecatedDirectives(testDataDir, directives, 1)
+	if err != nil {
+		t.Fatalf("AnalyzeDeprecatedDirectives failed: %v", err)
+	}
+	if analysis.TotalUsages != 2 {
+		t.Errorf("Expected 2 usages, got %d", analysis.TotalUsages)
+	}
+	for _, summary := range analysis.Directives {
+		if summary.Name == "note" && summary.Minutes != 2 {
+			t.Errorf("Expected the custom minutes for note, got %d", summary.Minutes)
+		}
+		if summary.Name == "steps-yaml" && summary.Minutes != 60 {
+			t.Errorf("Expected the high effort estimate for steps-yaml, got %d", summary.Minutes)
+		}
+	}
+}
+
+// TestNewMatchersValidation tests that invalid directive lists are rejected.
+func TestNewMatchersValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		directives []DeprecatedDirective
+	}{
+		{"empty list", nil},
+		{"missing name", []DeprecatedDirective{{Replacement: "x"}}},
+		{"duplicate name", []DeprecatedDirective{{Name: "code"}, {Name: "code"}}},
+		{"unknown effort", []DeprecatedDirective{{Name: "code", Effort: "huge"}}},
+		{"invalid argument pattern", []DeprecatedDirective{{Name: "include", Argument: "["}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newMatchers(tt.directives); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
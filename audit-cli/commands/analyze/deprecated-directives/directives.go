@@ -0,0 +1,108 @@
+package deprecated_directives
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// DefaultDirectives is the built-in list of deprecated and legacy directives. Pass --list to use a different list.
+var DefaultDirectives = []DeprecatedDirective{
+	{Name: "cssclass", Replacement: "the directive's :class: option or a container directive", Effort: EffortLow},
+	{Name: "rst-class", Replacement: "the directive's :class: option or a container directive", Effort: EffortLow},
+	{Name: "admonition", Replacement: "a typed admonition such as note, tip, important, or warning", Effort: EffortLow},
+	{Name: "code", Replacement: "code-block", Effort: EffortLow},
+	{Name: "sourcecode", Replacement: "code-block", Effort: EffortLow},
+	{Name: "only", Replacement: "selected-content, or separate pages", Effort: EffortMedium},
+	{Name: "tabs-drivers", Replacement: "tabs with :tabset: drivers", Effort: EffortMedium},
+	{Name: "tabs-platforms", Replacement: "tabs with :tabset: platforms", Effort: EffortMedium},
+	{Name: "steps-yaml", Directive: "include", Argument: `^/includes/steps/`, Replacement: "procedure and step directives", Effort: EffortHigh},
+}
+
+// matcher matches directive lines against one deprecated directive.
+type matcher struct {
+	directive DeprecatedDirective
+	argument  *regexp.Regexp
+}
+
+// LoadDirectives reads a deprecated directive list from a JSON file.
+//
+// The file contains an array of objects with the same fields as DefaultDirectives:
+//
+//	[{"name": "cssclass", "replacement": ":class: option", "effort": "low"}]
+//
+// Parameters:
+//   - path: Path to the JSON file
+//
+// Returns:
+//   - []DeprecatedDirective: The directives in the file
+//   - error: Any error reading or parsing the file
+func LoadDirectives(path string) ([]DeprecatedDirective, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directive list %s: %w", path, err)
+	}
+	var directives []DeprecatedDirective
+	if err := json.Unmarshal(data, &directives); err != nil {
+		return nil, fmt.Errorf("failed to parse directive list %s: %w", path, err)
+	}
+	return directives, nil
+}
+
+// newMatchers validates the directives, fills in defaults, and compiles their argument patterns.
+func newMatchers(directives []DeprecatedDirective) ([]matcher, error) {
+	if len(directives) == 0 {
+		return nil, fmt.Errorf("the deprecated directive list is empty")
+	}
+
+	matchers := make([]matcher, 0, len(directives))
+	seen := make(map[string]bool)
+	for i, directive := range directives {
+		if directive.Name == "" {
+			return nil, fmt.Errorf("directive %d: name is required", i)
+		}
+		if seen[directive.Name] {
+			return nil, fmt.Errorf("directive %s: listed more than once", directive.Name)
+		}
+		seen[directive.Name] = true
+
+		if directive.Directive == "" {
+			directive.Directive = directive.Name
+		}
+		if directive.Effort == "" {
+			directive.Effort = EffortMedium
+		}
+		if _, ok := effortMinutes[directive.Effort]; !ok {
+			return nil, fmt.Errorf("directive %s: effort must be low, medium, or high, got %q", directive.Name, directive.Effort)
+		}
+		if directive.Minutes == 0 {
+			directive.Minutes = effortMinutes[directive.Effort]
+		}
+
+		m := matcher{directive: directive}
+		if directive.Argument != "" {
+			re, err := regexp.Compile(directive.Argument)
+			if err != nil {
+				return nil, fmt.Errorf("directive %s: invalid argument pattern: %w", directive.Name, err)
+			}
+			m.argument = re
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// match returns the first matcher for the directive name and argument, if any.
+func match(matchers []matcher, name string, argument string) (matcher, bool) {
+	for _, m := range matchers {
+		if m.directive.Directive != name {
+			continue
+		}
+		if m.argument != nil && !m.argument.MatchString(argument) {
+			continue
+		}
+		return m, true
+	}
+	return matcher{}, false
+}
@@ -0,0 +1,123 @@
+package deprecated_directives
+
+import (
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// PrintAnalysis writes the analysis results in the writer's format.
+//
+// Text output is a summary followed by the per-directive and per-directory tables and,
+// optionally, the usage list. JSON output is the full analysis. CSV output is a single
+// table: the usage list when listAll is set, otherwise the per-directory counts. Markdown
+// output contains each table under its own heading.
+//
+// Parameters:
+//   - w: The output writer, which determines the format and destination
+//   - analysis: The analysis results to print
+//   - listAll: If true, list every usage with its file and line
+func PrintAnalysis(w *output.Writer, analysis *Analysis, listAll bool) error {
+	switch w.Format() {
+	case output.FormatJSON:
+		return w.WriteJSON(analysis)
+	case output.FormatCSV:
+		if listAll {
+			return w.WriteTable(usagesTable(analysis))
+		}
+		return w.WriteTable(directoryTable(analysis))
+	case output.FormatMarkdown:
+		return printTables(w, analysis, listAll)
+	default:
+		return printText(w, analysis, listAll)
+	}
+}
+
+// printText writes the analysis results in human-readable text format.
+func printText(w *output.Writer, analysis *Analysis, listAll bool) error {
+	w.Println("============================================================")
+	w.Println(w.Colorize("DEPRECATED DIRECTIVE ANALYSIS", output.Bold))
+	w.Println("============================================================")
+	w.Printf("Path: %s\n", analysis.RootPath)
+	w.Printf("Files Scanned: %d\n", analysis.FilesScanned)
+	w.Printf("Files With Deprecated Directives: %d\n", analysis.FilesAffected)
+	w.Printf("Total Usages: %s\n", w.Colorize(output.FormatValue(analysis.TotalUsages), output.Yellow))
+	w.Printf("Estimated Migration Effort: %s hours\n", output.FormatValue(analysis.TotalHours))
+	w.Println("============================================================")
+	w.Println()
+
+	if analysis.TotalUsages == 0 {
+		w.Println("No deprecated directives found.")
+		return nil
+	}
+
+	return printTables(w, analysis, listAll)
+}
+
+// printTables writes the per-directive and per-directory tables and, if requested, the usage list.
+func printTables(w *output.Writer, analysis *Analysis, listAll bool) error {
+	if err := w.WriteTable(directiveTable(analysis)); err != nil {
+		return err
+	}
+	w.Println()
+	if err := w.WriteTable(directoryTable(analysis)); err != nil {
+		return err
+	}
+	w.Println()
+
+	if listAll {
+		if err := w.WriteTable(usagesTable(analysis)); err != nil {
+			return err
+		}
+		w.Println()
+	}
+	return nil
+}
+
+// directiveTable builds the table of usages and effort by deprecated directive.
+func directiveTable(analysis *Analysis) *output.Table {
+	table := output.NewTable("By Directive:",
+		output.Column{Header: "Directive"},
+		output.Column{Header: "Usages", Align: output.AlignRight},
+		output.Column{Header: "Files", Align: output.AlignRight},
+		output.Column{Header: "Effort"},
+		output.Column{Header: "Est. Hours", Align: output.AlignRight},
+		output.Column{Header: "Replacement", MaxWidth: 60},
+	)
+	for _, summary := range analysis.Directives {
+		table.AddRow(summary.Name, summary.Usages, summary.Files, summary.Effort, summary.Hours, summary.Replacement)
+	}
+	return table
+}
+
+// directoryTable builds the table of usages and effort by directory.
+func directoryTable(analysis *Analysis) *output.Table {
+	table := output.NewTable("By Directory:",
+		output.Column{Header: "Directory"},
+		output.Column{Header: "Usages", Align: output.AlignRight},
+		output.Column{Header: "Files", Align: output.AlignRight},
+		output.Column{Header: "Est. Hours", Align: output.AlignRight},
+	)
+	for _, directory := range analysis.Directories {
+		table.AddRow(directory.Directory, directory.Usages, directory.Files, directory.Hours)
+	}
+	if len(table.Rows) == 0 {
+		table.Footer = "(none)"
+	}
+	return table
+}
+
+// usagesTable builds a table with one row per usage.
+func usagesTable(analysis *Analysis) *output.Table {
+	table := output.NewTable("All Usages:",
+		output.Column{Header: "File"},
+		output.Column{Header: "Line", Align: output.AlignRight},
+		output.Column{Header: "Directive"},
+		output.Column{Header: "Argument", MaxWidth: 60},
+	)
+	for _, usage := range analysis.Usages {
+		table.AddRow(usage.FilePath, usage.LineNum, usage.Name, usage.Argument)
+	}
+	if len(table.Rows) == 0 {
+		table.Footer = "(none)"
+	}
+	return table
+}
@@ -0,0 +1,66 @@
+package deprecated_directives
+
+// Effort levels for migrating one usage of a deprecated directive, with the minutes each is estimated to take.
+const (
+	EffortLow    = "low"
+	EffortMedium = "medium"
+	EffortHigh   = "high"
+)
+
+// effortMinutes is the estimated time to migrate one usage at each effort level.
+var effortMinutes = map[string]int{
+	EffortLow:    5,
+	EffortMedium: 15,
+	EffortHigh:   60,
+}
+
+// DeprecatedDirective describes a directive, or a form of one, that should be migrated.
+type DeprecatedDirective struct {
+	Name        string `json:"name"`                // Label shown in reports, e.g. "cssclass" or "steps-yaml"
+	Directive   string `json:"directive,omitempty"` // Directive name to match (defaults to Name)
+	Argument    string `json:"argument,omitempty"`  // Regex the directive argument must match (optional)
+	Replacement string `json:"replacement"`         // What to migrate to
+	Effort      string `json:"effort"`              // low, medium, or high
+	Minutes     int    `json:"minutes,omitempty"`   // Minutes per usage (defaults to the effort level's estimate)
+}
+
+// Usage is a single use of a deprecated directive.
+type Usage struct {
+	FilePath  string `json:"file_path"` // Path to the file containing the directive
+	LineNum   int    `json:"line_num"`  // Line number of the directive (1-based)
+	Directory string `json:"directory"` // Directory the usage is grouped under
+	Name      string `json:"name"`      // Name of the matched deprecated directive
+	Argument  string `json:"argument"`  // Directive argument
+}
+
+// DirectiveSummary contains the usages of one deprecated directive.
+type DirectiveSummary struct {
+	Name        string  `json:"name"`
+	Replacement string  `json:"replacement"`
+	Effort      string  `json:"effort"`
+	Minutes     int     `json:"minutes_per_usage"`
+	Usages      int     `json:"usages"`
+	Files       int     `json:"files"`
+	Hours       float64 `json:"estimated_hours"`
+}
+
+// DirectorySummary contains the usages of all deprecated directives in one directory.
+type DirectorySummary struct {
+	Directory string         `json:"directory"`
+	Usages    int            `json:"usages"`
+	Files     int            `json:"files"`
+	Hours     float64        `json:"estimated_hours"`
+	ByName    map[string]int `json:"by_name"` // Usages by deprecated directive name
+}
+
+// Analysis contains the results of scanning for deprecated directives.
+type Analysis struct {
+	RootPath      string             `json:"root_path"`       // File or directory that was analyzed
+	FilesScanned  int                `json:"files_scanned"`   // Number of files scanned
+	FilesAffected int                `json:"files_affected"`  // Number of files with at least one usage
+	TotalUsages   int                `json:"total_usages"`    // Total number of usages found
+	TotalHours    float64            `json:"estimated_hours"` // Estimated migration effort for all usages
+	Directives    []DirectiveSummary `json:"directives"`      // Per-directive summaries, most effort first
+	Directories   []DirectorySummary `json:"directories"`     // Per-directory summaries, most effort first
+	Usages        []Usage            `json:"usages"`          // All usages, sorted by file and line
+}
@@ -0,0 +1,235 @@
+package duplicate_headings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/projectinfo"
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// adornmentChars are the characters reStructuredText section headings can be underlined
+// and overlined with.
+const adornmentChars = "=-~`^\"'+*#:.<>_!$%&(),/;?@[\\]{|}"
+
+var (
+	// roleTargetRegex matches an inline role with an explicit target, e.g. :ref:`Title <target>`,
+	// capturing its text
+	roleTargetRegex = regexp.MustCompile(":[\\w:-]+:`([^`<]*?)\\s*<[^`>]*>`")
+	// roleRegex matches the name of an inline role, e.g. :dbcommand:
+	roleRegex = regexp.MustCompile(":[\\w:-]+:`")
+	// nonAnchorRegex matches the characters an anchor ID replaces with a hyphen
+	nonAnchorRegex = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// AnalyzeDuplicateHeadings scans a file or directory for duplicate headings and page titles.
+//
+// Pages are .txt files. Within each page, headings that generate the same anchor ID are
+// reported, since only the first of them can be linked to. Across pages, titles that more
+// than one page in the same source directory has are reported, since they're hard to tell
+// apart in search results. Versioned projects have a source directory per version, so the
+// same title in two versions isn't a duplicate.
+//
+// Parameters:
+//   - rootPath: Path to the page or directory to scan
+//
+// Returns:
+//   - *Analysis: The analysis results
+//   - error: Any error encountered during analysis
+func AnalyzeDuplicateHeadings(rootPath string) (*Analysis, error) {
+	fileInfo, err := os.Stat(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access path %s: %w", rootPath, err)
+	}
+
+	var pages []string
+	if fileInfo.IsDir() {
+		allFiles, err := rst.TraverseDirectory(rootPath, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to traverse directory %s: %w", rootPath, err)
+		}
+		for _, file := range allFiles {
+			if strings.ToLower(filepath.Ext(file)) == ".txt" {
+				pages = append(pages, file)
+			}
+		}
+	} else {
+		pages = []string{rootPath}
+	}
+	sort.Strings(pages)
+
+	analysis := &Analysis{
+		RootPath:          rootPath,
+		DuplicateHeadings: make([]DuplicateHeading, 0),
+		DuplicateTitles:   make([]DuplicateTitle, 0),
+	}
+
+	// Titles by source directory and normalized title
+	titles := make(map[string]map[string]*DuplicateTitle)
+	for _, page := range pages {
+		content, err := os.ReadFile(page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", page, err)
+		}
+		analysis.PagesScanned++
+
+		headings := ParseHeadings(string(content))
+		duplicates := findDuplicateHeadings(page, headings)
+		if len(duplicates) > 0 {
+			analysis.PagesWithDuplicateHeadings++
+			analysis.DuplicateHeadings = append(analysis.DuplicateHeadings, duplicates...)
+		}
+
+		if len(headings) == 0 {
+			continue
+		}
+		sourceDir := displaySourceDir(rootPath, page)
+		if titles[sourceDir] == nil {
+			titles[sourceDir] = make(map[string]*DuplicateTitle)
+		}
+		key := normalizeTitle(headings[0].Text)
+		title, ok := titles[sourceDir][key]
+		if !ok {
+			title = &DuplicateTitle{Title: headings[0].Text, SourceDir: sourceDir}
+			titles[sourceDir][key] = title
+		}
+		title.Pages = append(title.Pages, PageTitle{FilePath: page, LineNum: headings[0].LineNum})
+	}
+
+	for _, byTitle := range titles {
+		for _, title := range byTitle {
+			if len(title.Pages) > 1 {
+				analysis.DuplicateTitles = append(analysis.DuplicateTitles, *title)
+			}
+		}
+	}
+	sort.Slice(analysis.DuplicateTitles, func(i, j int) bool {
+		a, b := analysis.DuplicateTitles[i], analysis.DuplicateTitles[j]
+		if a.SourceDir != b.SourceDir {
+			return a.SourceDir < b.SourceDir
+		}
+		return a.Title < b.Title
+	})
+
+	return analysis, nil
+}
+
+// ParseHeadings returns the section headings in a page's content, in order. Headings are a
+// line of text at the start of a line, underlined, and optionally overlined, with a line of
+// one repeated punctuation character at least as long as the text.
+//
+// Parameters:
+//   - content: The page content
+//
+// Returns:
+//   - []Heading: The headings, with their anchor IDs and line numbers
+func ParseHeadings(content string) []Heading {
+	lines := strings.Split(content, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t\r")
+	}
+
+	var headings []Heading
+	for i := 0; i+1 < len(lines); i++ {
+		line := lines[i]
+
+		// Overlined: adornment, text, and the same adornment
+		if isAdornment(line) && i+2 < len(lines) && lines[i+2] == line {
+			text := strings.TrimSpace(lines[i+1])
+			if text != "" && !isAdornment(text) && utf8.RuneCountInString(line) >= utf8.RuneCountInString(text) {
+				headings = append(headings, newHeading(text, i+1))
+				i += 2
+				continue
+			}
+		}
+
+		// Underlined: text at the start of a line, then an adornment
+		if line == "" || line[0] == ' ' || line[0] == '\t' || strings.HasPrefix(line, "..") || isAdornment(line) {
+			continue
+		}
+		underline := lines[i+1]
+		if isAdornment(underline) && utf8.RuneCountInString(underline) >= utf8.RuneCountInString(line) {
+			headings = append(headings, newHeading(line, i))
+			i++
+		}
+	}
+	return headings
+}
+
+// newHeading returns the heading with the text, on a 0-based line index.
+func newHeading(text string, index int) Heading {
+	return Heading{Text: text, Anchor: AnchorID(text), LineNum: index + 1}
+}
+
+// isAdornment reports whether a line is a heading underline or overline: at least two of the
+// same punctuation character.
+func isAdornment(line string) bool {
+	if len(line) < 2 || !strings.ContainsRune(adornmentChars, rune(line[0])) {
+		return false
+	}
+	return strings.Count(line, line[:1]) == len(line)
+}
+
+// AnchorID returns the anchor ID the docs build generates for a heading: its text without
+// role markup, lowercased, with each run of other characters than letters and numbers
+// replaced with a hyphen.
+//
+// Example: "Use the :dbcommand:`find` Command" becomes "use-the-find-command".
+func AnchorID(text string) string {
+	text = roleTargetRegex.ReplaceAllString(text, "$1")
+	text = roleRegex.ReplaceAllString(text, "")
+	text = nonAnchorRegex.ReplaceAllString(strings.ToLower(text), "-")
+	return strings.Trim(text, "-")
+}
+
+// normalizeTitle returns the key page titles are compared by: lowercased, with whitespace
+// collapsed.
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
+// findDuplicateHeadings returns the anchors more than one of a page's headings generate.
+func findDuplicateHeadings(page string, headings []Heading) []DuplicateHeading {
+	byAnchor := make(map[string][]Heading)
+	var anchors []string
+	for _, heading := range headings {
+		if heading.Anchor == "" {
+			continue
+		}
+		if _, seen := byAnchor[heading.Anchor]; !seen {
+			anchors = append(anchors, heading.Anchor)
+		}
+		byAnchor[heading.Anchor] = append(byAnchor[heading.Anchor], heading)
+	}
+
+	var duplicates []DuplicateHeading
+	for _, anchor := range anchors {
+		if len(byAnchor[anchor]) > 1 {
+			duplicates = append(duplicates, DuplicateHeading{FilePath: page, Anchor: anchor, Headings: byAnchor[anchor]})
+		}
+	}
+	return duplicates
+}
+
+// displaySourceDir returns the source directory a page is in, as a path under rootPath if
+// it's inside it. Pages outside a source directory are grouped under rootPath.
+func displaySourceDir(rootPath string, page string) string {
+	sourceDir, err := projectinfo.FindSourceDirectory(page)
+	if err != nil {
+		return rootPath
+	}
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return sourceDir
+	}
+	rel, err := filepath.Rel(absRoot, sourceDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return sourceDir
+	}
+	return filepath.Join(rootPath, rel)
+}
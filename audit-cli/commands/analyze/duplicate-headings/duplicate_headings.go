@@ -0,0 +1,86 @@
+// Package duplicate_headings provides functionality for finding duplicate headings and page titles.
+//
+// This package implements the "analyze duplicate-headings" subcommand, which reports section
+// headings that repeat within a page, whose anchors collide, and page titles that repeat
+// across the pages of a project, which are hard to tell apart in search results.
+package duplicate_headings
+
+import (
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// NewDuplicateHeadingsCommand creates the duplicate-headings subcommand.
+//
+// This command scans a page or a directory of pages and reports duplicate headings within
+// each page and duplicate titles across pages, with the file and line of each.
+//
+// Usage:
+//
+//	analyze duplicate-headings /path/to/source
+//	analyze duplicate-headings /path/to/source --format csv --output-file duplicates.csv
+//
+// Flags:
+//   - --format: Output format (text, json, csv, or markdown)
+//   - --output-file: Write results to a file instead of stdout
+func NewDuplicateHeadingsCommand() *cobra.Command {
+	var outputOpts output.Options
+
+	cmd := &cobra.Command{
+		Use:   "duplicate-headings [filepath]",
+		Short: "Find duplicate headings within pages and duplicate page titles across pages",
+		Long: `Find duplicate section headings within pages and duplicate page titles across pages.
+
+This command scans a page, or a directory of pages recursively, and reports:
+  - Headings within a page that generate the same anchor ID. Only the first heading can
+    be linked to, so links to the others go to the wrong section.
+  - Page titles that more than one page in the same source directory has. Pages with the
+    same title are hard to tell apart in search results.
+
+Pages are .txt files, and a page's title is its first heading. Anchor IDs are the heading
+text without role markup, lowercased, with other characters than letters and numbers
+replaced with hyphens, so "Find Documents" and "Find documents" collide. Titles are
+compared case-insensitively. Versioned projects have a source directory per version, so
+the same title in two versions isn't reported.
+
+Headings in included files aren't checked; run the command on a page to check only that page.
+
+Examples:
+  # Report duplicate headings and titles in a project
+  analyze duplicate-headings /path/to/manual/source
+
+  # Check a single page
+  analyze duplicate-headings /path/to/manual/source/reference/operator.txt
+
+  # Write every duplicate to a CSV file
+  analyze duplicate-headings /path/to/manual/source --format csv --output-file duplicates.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDuplicateHeadings(args[0], outputOpts)
+		},
+	}
+
+	output.AddFlags(cmd, &outputOpts)
+
+	return cmd
+}
+
+// runDuplicateHeadings executes the duplicate headings analysis operation.
+func runDuplicateHeadings(path string, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
+	analysis, err := AnalyzeDuplicateHeadings(path)
+	if err != nil {
+		return err
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return PrintAnalysis(w, analysis)
+}
@@ -0,0 +1,165 @@
+// Package duplicate_headings provides tests for the duplicate headings analysis functionality.
+package duplicate_headings
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// writeFile writes content to a file under dir, creating its directories, and returns its path.
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// TestParseHeadings tests that underlined and overlined headings are found with their line
+// numbers, and that indented text, directives, and short underlines aren't headings.
+func TestParseHeadings(t *testing.T) {
+	content := "=========\n" +
+		"Find Data\n" +
+		"=========\n" +
+		"\n" +
+		".. code-block:: rst\n" +
+		"\n" +
+		"   Not a Heading\n" +
+		"   -------------\n" +
+		"\n" +
+		"Use the :dbcommand:`find` Command\n" +
+		"---------------------------------\n" +
+		"\n" +
+		"Too short\n" +
+		"---\n" +
+		"\n" +
+		"See :ref:`Query Filters <query-filters>`\r\n" +
+		"~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~\r\n"
+
+	headings := ParseHeadings(content)
+
+	expected := []Heading{
+		{Text: "Find Data", Anchor: "find-data", LineNum: 2},
+		{Text: "Use the :dbcommand:`find` Command", Anchor: "use-the-find-command", LineNum: 10},
+		{Text: "See :ref:`Query Filters <query-filters>`", Anchor: "see-query-filters", LineNum: 16},
+	}
+	if len(headings) != len(expected) {
+		t.Fatalf("Expected headings %+v, got %+v", expected, headings)
+	}
+	for i := range expected {
+		if headings[i] != expected[i] {
+			t.Errorf("Expected heading %d to be %+v, got %+v", i, expected[i], headings[i])
+		}
+	}
+}
+
+// TestAnalyzeDuplicateHeadings tests that headings sharing an anchor within a page, and titles
+// shared by pages in the same source directory, are reported, but titles shared across
+// versions aren't.
+func TestAnalyzeDuplicateHeadings(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "manual/v8.0/source/crud.txt", "====\n"+
+		"CRUD\n"+
+		"====\n"+
+		"\n"+
+		"Examples\n"+
+		"--------\n"+
+		"\n"+
+		"Insert\n"+
+		"~~~~~~\n"+
+		"\n"+
+		"examples\n"+
+		"--------\n"+
+		"\n"+
+		"Update\n"+
+		"~~~~~~\n")
+	writeFile(t, dir, "manual/v8.0/source/reference/crud.txt", "Crud\n"+
+		"====\n")
+	writeFile(t, dir, "manual/v8.0/source/includes/crud.rst", "CRUD\n"+
+		"====\n")
+	writeFile(t, dir, "manual/v7.0/source/crud.txt", "CRUD\n"+
+		"====\n")
+
+	analysis, err := AnalyzeDuplicateHeadings(dir)
+	if err != nil {
+		t.Fatalf("AnalyzeDuplicateHeadings failed: %v", err)
+	}
+
+	if analysis.PagesScanned != 3 {
+		t.Errorf("Expected 3 pages scanned, got %d", analysis.PagesScanned)
+	}
+	if analysis.PagesWithDuplicateHeadings != 1 || len(analysis.DuplicateHeadings) != 1 {
+		t.Fatalf("Expected one duplicate heading, got %+v", analysis.DuplicateHeadings)
+	}
+	duplicate := analysis.DuplicateHeadings[0]
+	if duplicate.Anchor != "examples" || len(duplicate.Headings) != 2 ||
+		duplicate.Headings[0].LineNum != 5 || duplicate.Headings[1].LineNum != 11 {
+		t.Errorf("Unexpected duplicate heading: %+v", duplicate)
+	}
+
+	if len(analysis.DuplicateTitles) != 1 {
+		t.Fatalf("Expected one duplicate title, got %+v", analysis.DuplicateTitles)
+	}
+	title := analysis.DuplicateTitles[0]
+	if title.Title != "CRUD" || title.SourceDir != filepath.Join(dir, "manual/v8.0/source") {
+		t.Errorf("Unexpected duplicate title: %+v", title)
+	}
+	if len(title.Pages) != 2 ||
+		title.Pages[0].FilePath != filepath.Join(dir, "manual/v8.0/source/crud.txt") || title.Pages[0].LineNum != 2 ||
+		title.Pages[1].FilePath != filepath.Join(dir, "manual/v8.0/source/reference/crud.txt") || title.Pages[1].LineNum != 1 {
+		t.Errorf("Unexpected pages with duplicate title: %+v", title.Pages)
+	}
+}
+
+// TestPrintAnalysisCSV tests that CSV output has one row per duplicate heading and page.
+func TestPrintAnalysisCSV(t *testing.T) {
+	analysis := &Analysis{
+		DuplicateHeadings: []DuplicateHeading{{
+			FilePath: "source/crud.txt",
+			Anchor:   "examples",
+			Headings: []Heading{{Text: "Examples", LineNum: 5}, {Text: "examples", LineNum: 11}},
+		}},
+		DuplicateTitles: []DuplicateTitle{{
+			Title:     "CRUD",
+			SourceDir: "source",
+			Pages:     []PageTitle{{FilePath: "source/crud.txt", LineNum: 2}, {FilePath: "source/reference/crud.txt", LineNum: 1}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := PrintAnalysis(output.NewWriter(&buf, output.FormatCSV), analysis); err != nil {
+		t.Fatalf("PrintAnalysis failed: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV output: %v", err)
+	}
+
+	expected := [][]string{
+		{"Kind", "File", "Line", "Text", "Duplicates"},
+		{"heading", "source/crud.txt", "5", "Examples", "2"},
+		{"heading", "source/crud.txt", "11", "examples", "2"},
+		{"title", "source/crud.txt", "2", "CRUD", "2"},
+		{"title", "source/reference/crud.txt", "1", "CRUD", "2"},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Expected %d CSV rows, got %v", len(expected), records)
+	}
+	for i := range expected {
+		for j := range expected[i] {
+			if records[i][j] != expected[i][j] {
+				t.Errorf("Expected row %d to be %v, got %v", i, expected[i], records[i])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package duplicate_headings
+
+import (
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// Kinds of duplicates, as reported in CSV output.
+const (
+	kindHeading = "heading" // A heading that repeats within a page
+	kindTitle   = "title"   // A page title that repeats across pages
+)
+
+// PrintAnalysis writes the analysis results in the writer's format.
+//
+// Text output is a summary followed by the duplicate headings and duplicate titles tables.
+// JSON output is the full analysis. CSV output has one row per duplicate heading or page.
+// Markdown output contains each table under its own heading.
+//
+// Parameters:
+//   - w: The output writer, which determines the format and destination
+//   - analysis: The analysis results to print
+func PrintAnalysis(w *output.Writer, analysis *Analysis) error {
+	switch w.Format() {
+	case output.FormatJSON:
+		return w.WriteJSON(analysis)
+	case output.FormatCSV:
+		return w.WriteTable(locationsTable(analysis))
+	case output.FormatMarkdown:
+		return printTables(w, analysis)
+	default:
+		return printText(w, analysis)
+	}
+}
+
+// printText writes the analysis results in human-readable text format.
+func printText(w *output.Writer, analysis *Analysis) error {
+	w.Println("============================================================")
+	w.Println(w.Colorize("DUPLICATE HEADINGS ANALYSIS", output.Bold))
+	w.Println("============================================================")
+	w.Printf("Path: %s\n", analysis.RootPath)
+	w.Printf("Pages Scanned: %d\n", analysis.PagesScanned)
+	w.Printf("Duplicate Headings: %s in %d pages\n",
+		w.Colorize(output.FormatValue(len(analysis.DuplicateHeadings)), output.Yellow), analysis.PagesWithDuplicateHeadings)
+	w.Printf("Duplicate Page Titles: %s\n", w.Colorize(output.FormatValue(len(analysis.DuplicateTitles)), output.Yellow))
+	w.Println("============================================================")
+	w.Println()
+
+	if len(analysis.DuplicateHeadings) == 0 && len(analysis.DuplicateTitles) == 0 {
+		w.Println("No duplicate headings or page titles found.")
+		return nil
+	}
+
+	return printTables(w, analysis)
+}
+
+// printTables writes the duplicate headings and duplicate titles tables.
+func printTables(w *output.Writer, analysis *Analysis) error {
+	if err := w.WriteTable(headingsTable(analysis)); err != nil {
+		return err
+	}
+	w.Println()
+	if err := w.WriteTable(titlesTable(analysis)); err != nil {
+		return err
+	}
+	w.Println()
+	return nil
+}
+
+// headingsTable builds a table with one row per heading that repeats within a page.
+func headingsTable(analysis *Analysis) *output.Table {
+	table := output.NewTable("Duplicate Headings Within Pages:",
+		output.Column{Header: "File"},
+		output.Column{Header: "Line", Align: output.AlignRight},
+		output.Column{Header: "Heading", MaxWidth: 50},
+		output.Column{Header: "Anchor", MaxWidth: 40},
+	)
+	for _, duplicate := range analysis.DuplicateHeadings {
+		for _, heading := range duplicate.Headings {
+			table.AddRow(duplicate.FilePath, heading.LineNum, heading.Text, duplicate.Anchor)
+		}
+	}
+	if len(table.Rows) == 0 {
+		table.Footer = "(none)"
+	}
+	return table
+}
+
+// titlesTable builds a table with one row per page whose title another page has.
+func titlesTable(analysis *Analysis) *output.Table {
+	table := output.NewTable("Duplicate Page Titles:",
+		output.Column{Header: "Title", MaxWidth: 50},
+		output.Column{Header: "File"},
+		output.Column{Header: "Line", Align: output.AlignRight},
+	)
+	for _, duplicate := range analysis.DuplicateTitles {
+		for _, page := range duplicate.Pages {
+			table.AddRow(duplicate.Title, page.FilePath, page.LineNum)
+		}
+	}
+	if len(table.Rows) == 0 {
+		table.Footer = "(none)"
+	}
+	return table
+}
+
+// locationsTable builds a table with one row per duplicate heading or page, for CSV output.
+func locationsTable(analysis *Analysis) *output.Table {
+	table := output.NewTable("Duplicates:",
+		output.Column{Header: "Kind"},
+		output.Column{Header: "File"},
+		output.Column{Header: "Line", Align: output.AlignRight},
+		output.Column{Header: "Text"},
+		output.Column{Header: "Duplicates", Align: output.AlignRight},
+	)
+	for _, duplicate := range analysis.DuplicateHeadings {
+		for _, heading := range duplicate.Headings {
+			table.AddRow(kindHeading, duplicate.FilePath, heading.LineNum, heading.Text, len(duplicate.Headings))
+		}
+	}
+	for _, duplicate := range analysis.DuplicateTitles {
+		for _, page := range duplicate.Pages {
+			table.AddRow(kindTitle, page.FilePath, page.LineNum, duplicate.Title, len(duplicate.Pages))
+		}
+	}
+	return table
+}
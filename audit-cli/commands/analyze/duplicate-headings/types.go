@@ -0,0 +1,38 @@
+package duplicate_headings
+
+// Heading is a section heading in a page.
+type Heading struct {
+	Text    string `json:"text"`     // Heading text, as written
+	Anchor  string `json:"anchor"`   // Anchor ID generated from the text
+	LineNum int    `json:"line_num"` // Line number of the heading text (1-based)
+}
+
+// DuplicateHeading is an anchor that more than one heading in a page generates. Only the
+// first heading can be linked to by the anchor.
+type DuplicateHeading struct {
+	FilePath string    `json:"file_path"` // Path to the page
+	Anchor   string    `json:"anchor"`    // Anchor ID the headings share
+	Headings []Heading `json:"headings"`  // Headings that generate the anchor, in page order
+}
+
+// PageTitle is the title of a page: its first heading.
+type PageTitle struct {
+	FilePath string `json:"file_path"` // Path to the page
+	LineNum  int    `json:"line_num"`  // Line number of the title (1-based)
+}
+
+// DuplicateTitle is a page title that more than one page in a source directory has.
+type DuplicateTitle struct {
+	Title     string      `json:"title"`      // Title of the first page, as written
+	SourceDir string      `json:"source_dir"` // Source directory the pages are in
+	Pages     []PageTitle `json:"pages"`      // Pages with the title, sorted by path
+}
+
+// Analysis contains the results of scanning for duplicate headings and page titles.
+type Analysis struct {
+	RootPath                   string             `json:"root_path"`                     // File or directory that was analyzed
+	PagesScanned               int                `json:"pages_scanned"`                 // Number of pages scanned
+	PagesWithDuplicateHeadings int                `json:"pages_with_duplicate_headings"` // Pages with at least one duplicate heading
+	DuplicateHeadings          []DuplicateHeading `json:"duplicate_headings"`            // Duplicate headings, sorted by file and line
+	DuplicateTitles            []DuplicateTitle   `json:"duplicate_titles"`              // Duplicate titles, sorted by source directory and title
+}
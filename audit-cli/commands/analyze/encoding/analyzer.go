@@ -0,0 +1,375 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// File types, which determine how code is recognized.
+const (
+	fileTypeRST      = "rst"      // reStructuredText, and YAML files that contain it
+	fileTypeMarkdown = "markdown" // Markdown
+	fileTypeCode     = "code"     // Source files, which are all code
+)
+
+// fileTypes maps the extensions that are scanned to their file type.
+var fileTypes = map[string]string{
+	".rst":   fileTypeRST,
+	".txt":   fileTypeRST,
+	".yaml":  fileTypeRST,
+	".yml":   fileTypeRST,
+	".md":    fileTypeMarkdown,
+	".c":     fileTypeCode,
+	".cpp":   fileTypeCode,
+	".cs":    fileTypeCode,
+	".go":    fileTypeCode,
+	".java":  fileTypeCode,
+	".js":    fileTypeCode,
+	".jsx":   fileTypeCode,
+	".mjs":   fileTypeCode,
+	".cjs":   fileTypeCode,
+	".json":  fileTypeCode,
+	".kt":    fileTypeCode,
+	".php":   fileTypeCode,
+	".ps1":   fileTypeCode,
+	".py":    fileTypeCode,
+	".rb":    fileTypeCode,
+	".rs":    fileTypeCode,
+	".scala": fileTypeCode,
+	".sh":    fileTypeCode,
+	".swift": fileTypeCode,
+	".ts":    fileTypeCode,
+	".tsx":   fileTypeCode,
+}
+
+// codeDirectives are the directives whose content is code.
+var codeDirectives = map[string]bool{
+	"code-block":    true,
+	"code":          true,
+	"sourcecode":    true,
+	"io-code-block": true,
+	"input":         true,
+	"output":        true,
+}
+
+var (
+	// directiveOptionRegex matches a directive option line, e.g. ":linenos:"
+	directiveOptionRegex = regexp.MustCompile(`^\s*:[\w-]+:`)
+	// rstLiteralRegex matches an inline literal, e.g. ``db.find()``
+	rstLiteralRegex = regexp.MustCompile("``[^`]+``")
+	// markdownCodeRegex matches inline code, e.g. `db.find()`
+	markdownCodeRegex = regexp.MustCompile("`[^`]+`")
+)
+
+// AnalyzeEncoding scans a file or directory for characters that break copy-pasted code.
+//
+// Directories are scanned recursively, processing reStructuredText, Markdown, YAML, and
+// source files. Byte order marks, zero-width characters, and invalid UTF-8 are reported
+// anywhere. Non-breaking spaces, smart quotes, and dashes are only reported in code: code
+// blocks, literal blocks, and inline literals in reStructuredText and YAML, fenced code and
+// inline code in Markdown, and anywhere in source files.
+//
+// If fix is true, each file is rewritten with the safe replacements for its issues. Issues
+// without a safe replacement are left for a manual fix.
+//
+// Parameters:
+//   - rootPath: Path to the file or directory to scan
+//   - fix: If true, replace fixable characters in place
+//
+// Returns:
+//   - *Analysis: The analysis results
+//   - error: Any error encountered during analysis
+func AnalyzeEncoding(rootPath string, fix bool) (*Analysis, error) {
+	fileInfo, err := os.Stat(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access path %s: %w", rootPath, err)
+	}
+
+	var files []string
+	if fileInfo.IsDir() {
+		allFiles, err := rst.TraverseDirectory(rootPath, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to traverse directory %s: %w", rootPath, err)
+		}
+		for _, file := range allFiles {
+			if fileType(file) != "" {
+				files = append(files, file)
+			}
+		}
+	} else {
+		files = []string{rootPath}
+	}
+
+	analysis := &Analysis{
+		RootPath: rootPath,
+		Kinds:    make([]KindSummary, 0, len(Kinds)),
+		Issues:   make([]Issue, 0),
+	}
+
+	for _, file := range files {
+		issues, rewritten, err := scanFile(file, fix)
+		if err != nil {
+			return nil, err
+		}
+		analysis.FilesScanned++
+		if rewritten {
+			analysis.FilesFixed++
+		}
+		analysis.Issues = append(analysis.Issues, issues...)
+	}
+
+	sort.SliceStable(analysis.Issues, func(i, j int) bool {
+		a, b := analysis.Issues[i], analysis.Issues[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		if a.LineNum != b.LineNum {
+			return a.LineNum < b.LineNum
+		}
+		return a.Column < b.Column
+	})
+
+	summarize(analysis)
+	return analysis, nil
+}
+
+// fileType returns the type of a file by its extension, or "" if it isn't scanned.
+func fileType(filePath string) string {
+	return fileTypes[strings.ToLower(filepath.Ext(filePath))]
+}
+
+// scanFile returns the issues in a single file and, if fix is true, rewrites the file with
+// the safe replacements, reporting whether it was rewritten.
+func scanFile(filePath string, fix bool) ([]Issue, bool, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+
+	typ := fileType(filePath)
+	if typ == "" {
+		typ = fileTypeRST
+	}
+	issues, fixed := scanContent(filePath, content, typ)
+	if !fix || bytes.Equal(fixed, content) {
+		return issues, false, nil
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to access file %s: %w", filePath, err)
+	}
+	if err := os.WriteFile(filePath, fixed, info.Mode().Perm()); err != nil {
+		return nil, false, fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	for i := range issues {
+		issues[i].Fixed = issues[i].Fix != ""
+	}
+	return issues, true, nil
+}
+
+// scanContent returns the issues in a file's content and the content with every fixable
+// issue replaced.
+func scanContent(filePath string, content []byte, typ string) ([]Issue, []byte) {
+	var issues []Issue
+	var fixed bytes.Buffer
+	fixed.Grow(len(content))
+
+	ctx := newCodeContext(typ)
+	offset := 0
+	for lineNum, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		inCode := ctx.next(strings.TrimRight(string(line), "\r\n"))
+
+		column := 0
+		for i := 0; i < len(line); {
+			r, size := utf8.DecodeRune(line[i:])
+			column++
+			original := line[i : i+size]
+			issue, ok := checkRune(r, size, offset+i == 0, inCode(i))
+			i += size
+			if !ok {
+				fixed.Write(original)
+				continue
+			}
+
+			issue.FilePath = filePath
+			issue.LineNum = lineNum + 1
+			issue.Column = column
+			issues = append(issues, issue)
+			if issue.Fix != "" {
+				fixed.WriteString(problemCharacters[r].replacement)
+			} else {
+				fixed.Write(original)
+			}
+		}
+		offset += len(line)
+	}
+
+	return issues, fixed.Bytes()
+}
+
+// checkRune returns the issue for a character, if it's a problem where it appears.
+func checkRune(r rune, size int, atStart bool, inCode bool) (Issue, bool) {
+	if r == utf8.RuneError && size == 1 {
+		return Issue{Kind: KindInvalidUTF8, Character: "invalid UTF-8 byte", InCode: inCode}, true
+	}
+	if r == '\uFEFF' && atStart {
+		return Issue{Kind: KindBOM, Character: describeCharacter(r, "BYTE ORDER MARK"), InCode: inCode, Fix: "remove"}, true
+	}
+
+	c, ok := problemCharacters[r]
+	if !ok || (c.codeOnly && !inCode) {
+		return Issue{}, false
+	}
+	return Issue{
+		Kind:      c.kind,
+		Character: describeCharacter(r, c.name),
+		InCode:    inCode,
+		Fix:       describeFix(c),
+	}, true
+}
+
+// codeContext tracks which lines of a file are code as the file is read line by line.
+type codeContext struct {
+	typ string
+
+	// reStructuredText
+	blockIndent   int  // Indentation of the directive or paragraph that opened a code block, or -1
+	inOptions     bool // Whether the code block's directive options haven't ended yet
+	literalIndent int  // Indentation of a paragraph ending in "::", or -1
+
+	// Markdown
+	fence string // The fence that opened a code block, or ""
+}
+
+// newCodeContext creates a code context for a file of the given type.
+func newCodeContext(typ string) *codeContext {
+	return &codeContext{typ: typ, blockIndent: -1, literalIndent: -1}
+}
+
+// next reads the next line and returns a function reporting whether the byte at an offset
+// in the line is in code.
+func (c *codeContext) next(line string) func(offset int) bool {
+	all := func(int) bool { return true }
+	none := func(int) bool { return false }
+
+	switch c.typ {
+	case fileTypeCode:
+		return all
+	case fileTypeMarkdown:
+		trimmed := strings.TrimSpace(line)
+		if c.fence != "" {
+			if strings.HasPrefix(trimmed, c.fence) {
+				c.fence = ""
+			}
+			return all
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			c.fence = trimmed[:3]
+			return all
+		}
+		return inSpans(line, markdownCodeRegex)
+	}
+
+	trimmed := strings.TrimSpace(line)
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+	if c.blockIndent >= 0 {
+		if trimmed == "" {
+			c.inOptions = false
+			return all
+		}
+		if indent > c.blockIndent {
+			if c.inOptions && directiveOptionRegex.MatchString(line) {
+				return inSpans(line, rstLiteralRegex)
+			}
+			c.inOptions = false
+			return all
+		}
+		c.blockIndent = -1
+	}
+
+	if c.literalIndent >= 0 && trimmed != "" {
+		if indent > c.literalIndent {
+			c.blockIndent = c.literalIndent
+			c.literalIndent = -1
+			return all
+		}
+		c.literalIndent = -1
+	}
+
+	if matches := rst.AnyDirectiveRegex.FindStringSubmatch(line); matches != nil {
+		if codeDirectives[matches[1]] {
+			c.blockIndent = indent
+			c.inOptions = true
+		}
+		return none
+	}
+	if strings.HasSuffix(trimmed, "::") {
+		c.literalIndent = indent
+	}
+	return inSpans(line, rstLiteralRegex)
+}
+
+// inSpans returns a function reporting whether the byte at an offset in the line is inside
+// a match of the regex.
+func inSpans(line string, re *regexp.Regexp) func(offset int) bool {
+	spans := re.FindAllStringIndex(line, -1)
+	return func(offset int) bool {
+		for _, span := range spans {
+			if offset >= span[0] && offset < span[1] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// summarize fills in the totals and the per-kind summaries from the issues. Kinds without
+// issues are included so the report shows everything that was checked.
+func summarize(analysis *Analysis) {
+	byKind := make(map[string]*KindSummary, len(Kinds))
+	for _, kind := range Kinds {
+		analysis.Kinds = append(analysis.Kinds, KindSummary{Kind: kind})
+	}
+	for i := range analysis.Kinds {
+		byKind[analysis.Kinds[i].Kind] = &analysis.Kinds[i]
+	}
+
+	filesByKind := make(map[string]map[string]bool)
+	affected := make(map[string]bool)
+	for _, issue := range analysis.Issues {
+		affected[issue.FilePath] = true
+		analysis.TotalIssues++
+
+		summary := byKind[issue.Kind]
+		summary.Issues++
+		if filesByKind[issue.Kind] == nil {
+			filesByKind[issue.Kind] = make(map[string]bool)
+		}
+		filesByKind[issue.Kind][issue.FilePath] = true
+		if issue.Fix != "" {
+			summary.Fixable++
+			analysis.Fixable++
+		}
+		if issue.Fixed {
+			analysis.Fixed++
+		}
+	}
+
+	for i := range analysis.Kinds {
+		analysis.Kinds[i].Files = len(filesByKind[analysis.Kinds[i].Kind])
+	}
+	analysis.FilesAffected = len(affected)
+}
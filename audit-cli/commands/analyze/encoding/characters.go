@@ -0,0 +1,55 @@
+package encoding
+
+import "fmt"
+
+// problemCharacter describes a character that breaks copy-pasted code.
+type problemCharacter struct {
+	name        string
+	kind        string
+	codeOnly    bool   // Only reported in code; prose may use the character on purpose
+	fixable     bool   // Whether replacing it with replacement is always safe
+	replacement string // What --fix replaces it with; empty removes it
+}
+
+// problemCharacters are the characters reported, by code point. A byte order mark at the
+// start of a file is reported as KindBOM; anywhere else it's an invisible character.
+//
+// Zero-width joiners aren't fixed because they're part of emoji and some scripts, and
+// dashes aren't fixed because an en or em dash may have replaced "-" or "--".
+var problemCharacters = map[rune]problemCharacter{
+	'\uFEFF': {name: "ZERO WIDTH NO-BREAK SPACE", kind: KindZeroWidth, fixable: true},
+	'\u200B': {name: "ZERO WIDTH SPACE", kind: KindZeroWidth, fixable: true},
+	'\u2060': {name: "WORD JOINER", kind: KindZeroWidth, fixable: true},
+	'\u00AD': {name: "SOFT HYPHEN", kind: KindZeroWidth, fixable: true},
+	'\u200C': {name: "ZERO WIDTH NON-JOINER", kind: KindZeroWidth, codeOnly: true},
+	'\u200D': {name: "ZERO WIDTH JOINER", kind: KindZeroWidth, codeOnly: true},
+	'\u00A0': {name: "NO-BREAK SPACE", kind: KindNonBreakingSpace, codeOnly: true, fixable: true, replacement: " "},
+	'\u202F': {name: "NARROW NO-BREAK SPACE", kind: KindNonBreakingSpace, codeOnly: true, fixable: true, replacement: " "},
+	'\u2007': {name: "FIGURE SPACE", kind: KindNonBreakingSpace, codeOnly: true, fixable: true, replacement: " "},
+	'\u2018': {name: "LEFT SINGLE QUOTATION MARK", kind: KindSmartQuote, codeOnly: true, fixable: true, replacement: "'"},
+	'\u2019': {name: "RIGHT SINGLE QUOTATION MARK", kind: KindSmartQuote, codeOnly: true, fixable: true, replacement: "'"},
+	'\u201C': {name: "LEFT DOUBLE QUOTATION MARK", kind: KindSmartQuote, codeOnly: true, fixable: true, replacement: `"`},
+	'\u201D': {name: "RIGHT DOUBLE QUOTATION MARK", kind: KindSmartQuote, codeOnly: true, fixable: true, replacement: `"`},
+	'\u2013': {name: "EN DASH", kind: KindDash, codeOnly: true},
+	'\u2014': {name: "EM DASH", kind: KindDash, codeOnly: true},
+	'\u2212': {name: "MINUS SIGN", kind: KindDash, codeOnly: true, fixable: true, replacement: "-"},
+}
+
+// describeCharacter returns a character's code point and name, e.g. "U+00A0 NO-BREAK SPACE".
+func describeCharacter(r rune, name string) string {
+	return fmt.Sprintf("U+%04X %s", r, name)
+}
+
+// describeFix describes a character's safe replacement, or returns "" if it needs a manual fix.
+func describeFix(c problemCharacter) string {
+	switch {
+	case !c.fixable:
+		return ""
+	case c.replacement == "":
+		return "remove"
+	case c.replacement == " ":
+		return "space"
+	default:
+		return fmt.Sprintf("replace with %s", c.replacement)
+	}
+}
@@ -0,0 +1,100 @@
+// Package encoding provides functionality for finding characters that break copy-pasted code.
+//
+// This package implements the "analyze encoding" subcommand, which reports byte order marks,
+// invisible characters, non-breaking spaces, smart quotes, and other characters that look
+// right on the page but break code examples when they're copied, and can replace them.
+package encoding
+
+import (
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// NewEncodingCommand creates the encoding subcommand.
+//
+// This command scans a file or directory for problem characters and reports each one with
+// its file, line, and column. With --fix, it replaces the characters that have a safe
+// replacement.
+//
+// Usage:
+//
+//	analyze encoding /path/to/source
+//	analyze encoding /path/to/source --fix
+//	analyze encoding /path/to/source --format csv --output-file issues.csv
+//
+// Flags:
+//   - --fix: Replace characters that have a safe replacement, rewriting files in place
+//   - --format: Output format (text, json, csv, or markdown)
+//   - --output-file: Write results to a file instead of stdout
+func NewEncodingCommand() *cobra.Command {
+	var (
+		fix        bool
+		outputOpts output.Options
+	)
+
+	cmd := &cobra.Command{
+		Use:   "encoding [filepath]",
+		Short: "Find invisible and look-alike characters that break copy-pasted code",
+		Long: `Find characters that break code examples when they're copied and pasted.
+
+This command scans a file, or a directory recursively, and reports the file, line, and
+column of each problem character. It scans reStructuredText, Markdown, YAML, and source
+files. It reports:
+  - bom: A byte order mark at the start of a file
+  - zero-width: Zero-width spaces, word joiners, and soft hyphens anywhere, and
+    zero-width joiners in code
+  - non-breaking-space: Non-breaking, narrow, and figure spaces in code
+  - smart-quote: Curly single and double quotes in code
+  - dash: En dashes, em dashes, and minus signs in code
+  - invalid-utf8: Bytes that aren't valid UTF-8
+
+Code is the content of code-block, code, sourcecode, io-code-block, input, and output
+directives, literal blocks after "::", and inline literals in reStructuredText and YAML;
+fenced code and inline code in Markdown; and the whole of a source file. Prose may use
+non-breaking spaces, smart quotes, and dashes on purpose, so they aren't reported there.
+
+Use --fix to rewrite files with safe replacements: invisible characters are removed,
+non-breaking spaces become spaces, smart quotes become straight quotes, and minus signs
+become hyphens. En dashes, em dashes, zero-width joiners, and invalid UTF-8 need a manual
+fix, because the right replacement depends on the context.
+
+Examples:
+  # Report problem characters in a project
+  analyze encoding /path/to/manual/source
+
+  # Replace the characters that have a safe replacement
+  analyze encoding /path/to/manual/source --fix
+
+  # Write every issue to a CSV file
+  analyze encoding /path/to/manual/source --format csv --output-file issues.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEncoding(args[0], fix, outputOpts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&fix, "fix", false, "Replace characters that have a safe replacement, rewriting files in place")
+	output.AddFlags(cmd, &outputOpts)
+
+	return cmd
+}
+
+// runEncoding executes the encoding analysis operation.
+func runEncoding(path string, fix bool, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
+	analysis, err := AnalyzeEncoding(path, fix)
+	if err != nil {
+		return err
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return PrintAnalysis(w, analysis)
+}
@@ -0,0 +1,194 @@
+// Package encoding provides tests for the encoding analysis functionality.
+package encoding
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile writes content to a file in dir and returns its path.
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// kinds returns the kinds of the issues, in order.
+func kinds(issues []Issue) []string {
+	var result []string
+	for _, issue := range issues {
+		result = append(result, issue.Kind)
+	}
+	return result
+}
+
+// TestAnalyzeEncodingRST tests that characters that are only a problem in code are reported in
+// code blocks, literal blocks, and inline literals, but not in prose.
+func TestAnalyzeEncodingRST(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "page.rst", "\uFEFFTitle\n"+
+		"=====\n"+
+		"\n"+
+		"Prose can use \u201Csmart quotes\u201D\u00A0and dashes \u2013 and ``db.find(\u2018x\u2019)``.\n"+
+		"\n"+
+		".. code-block:: javascript\n"+
+		"   :caption: The \u201Cfind\u201D method\n"+
+		"\n"+
+		"   db.find({ name:\u00A0\u201Cx\u201D })\n"+
+		"\n"+
+		"Back to prose \u201Chere\u201D with a zero\u200Bwidth space.\n"+
+		"\n"+
+		"Run this::\n"+
+		"\n"+
+		"   mongosh \u2013\u2013quiet\n")
+
+	analysis, err := AnalyzeEncoding(dir, false)
+	if err != nil {
+		t.Fatalf("AnalyzeEncoding failed: %v", err)
+	}
+
+	expected := []string{
+		KindBOM,
+		KindSmartQuote, KindSmartQuote, // inline literal
+		KindNonBreakingSpace, KindSmartQuote, KindSmartQuote, // code block
+		KindZeroWidth,      // prose
+		KindDash, KindDash, // literal block
+	}
+	got := kinds(analysis.Issues)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected issues %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected issue %d to be %s, got %s", i, expected[i], got[i])
+		}
+	}
+
+	first := analysis.Issues[0]
+	if first.LineNum != 1 || first.Column != 1 || first.Fix != "remove" {
+		t.Errorf("Unexpected BOM issue: %+v", first)
+	}
+	nbsp := analysis.Issues[3]
+	if nbsp.LineNum != 9 || nbsp.Column != 19 || !nbsp.InCode || nbsp.Character != "U+00A0 NO-BREAK SPACE" {
+		t.Errorf("Unexpected non-breaking space issue: %+v", nbsp)
+	}
+	if analysis.Issues[7].Fix != "" {
+		t.Errorf("Expected en dashes to need a manual fix, got %q", analysis.Issues[7].Fix)
+	}
+
+	if analysis.FilesScanned != 1 || analysis.FilesAffected != 1 {
+		t.Errorf("Expected 1 file scanned and affected, got %d and %d", analysis.FilesScanned, analysis.FilesAffected)
+	}
+	if analysis.TotalIssues != 9 || analysis.Fixable != 7 {
+		t.Errorf("Expected 9 issues and 7 fixable, got %d and %d", analysis.TotalIssues, analysis.Fixable)
+	}
+}
+
+// TestAnalyzeEncodingMarkdownAndCode tests Markdown code and source files, which are all code.
+func TestAnalyzeEncodingMarkdownAndCode(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "README.md", "Use \u201Cquotes\u201D in prose and `print(\u201Cx\u201D)` inline.\n"+
+		"\n"+
+		"```python\n"+
+		"x\u00A0= 1 \u2212 2\n"+
+		"```\n")
+	writeFile(t, dir, "example.py", "print(\u2018hello\u2019)\n")
+	writeFile(t, dir, "image.png", "\u201Cnot scanned\u201D")
+
+	analysis, err := AnalyzeEncoding(dir, false)
+	if err != nil {
+		t.Fatalf("AnalyzeEncoding failed: %v", err)
+	}
+
+	if analysis.FilesScanned != 2 {
+		t.Errorf("Expected 2 files scanned, got %d", analysis.FilesScanned)
+	}
+	counts := make(map[string]int)
+	for _, summary := range analysis.Kinds {
+		counts[summary.Kind] = summary.Issues
+	}
+	if counts[KindSmartQuote] != 4 || counts[KindNonBreakingSpace] != 1 || counts[KindDash] != 1 {
+		t.Errorf("Unexpected issues by kind: %+v", analysis.Kinds)
+	}
+}
+
+// TestAnalyzeEncodingInvalidUTF8 tests that invalid bytes are reported and kept by --fix.
+func TestAnalyzeEncodingInvalidUTF8(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "example.js", "const a = \"\xff\";\u200B\n")
+
+	analysis, err := AnalyzeEncoding(path, true)
+	if err != nil {
+		t.Fatalf("AnalyzeEncoding failed: %v", err)
+	}
+
+	got := kinds(analysis.Issues)
+	if len(got) != 2 || got[0] != KindInvalidUTF8 || got[1] != KindZeroWidth {
+		t.Fatalf("Expected invalid-utf8 and zero-width issues, got %v", got)
+	}
+	if analysis.Issues[0].Fixed || !analysis.Issues[1].Fixed {
+		t.Errorf("Expected only the zero-width space to be fixed: %+v", analysis.Issues)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read fixed file: %v", err)
+	}
+	if string(content) != "const a = \"\xff\";\n" {
+		t.Errorf("Unexpected fixed content: %q", content)
+	}
+}
+
+// TestAnalyzeEncodingFix tests that --fix replaces fixable characters and leaves the rest.
+func TestAnalyzeEncodingFix(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "page.rst", "\uFEFFProse \u201Cquotes\u201D stay.\n"+
+		"\n"+
+		".. code-block:: shell\n"+
+		"\n"+
+		"   echo \u201Chi\u201D\u00A0\u2014 done\u00AD\n")
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatalf("failed to chmod: %v", err)
+	}
+
+	analysis, err := AnalyzeEncoding(dir, true)
+	if err != nil {
+		t.Fatalf("AnalyzeEncoding failed: %v", err)
+	}
+
+	if analysis.Fixed != 5 || analysis.FilesFixed != 1 {
+		t.Errorf("Expected 5 fixes in 1 file, got %d in %d", analysis.Fixed, analysis.FilesFixed)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read fixed file: %v", err)
+	}
+	want := "Prose \u201Cquotes\u201D stay.\n" +
+		"\n" +
+		".. code-block:: shell\n" +
+		"\n" +
+		"   echo \"hi\" \u2014 done\n"
+	if string(content) != want {
+		t.Errorf("Unexpected fixed content:\ngot:  %q\nwant: %q", content, want)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat fixed file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected file mode to be kept, got %v", info.Mode().Perm())
+	}
+
+	// A second run finds only the issue that needs a manual fix
+	analysis, err = AnalyzeEncoding(dir, true)
+	if err != nil {
+		t.Fatalf("AnalyzeEncoding failed: %v", err)
+	}
+	if analysis.TotalIssues != 1 || analysis.Issues[0].Kind != KindDash || analysis.FilesFixed != 0 {
+		t.Errorf("Expected only the em dash on a second run, got %+v", analysis.Issues)
+	}
+}
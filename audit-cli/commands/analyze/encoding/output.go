@@ -0,0 +1,108 @@
+package encoding
+
+import (
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// PrintAnalysis writes the analysis results in the writer's format.
+//
+// Text output is a summary followed by the per-kind table and the issue list. JSON output
+// is the full analysis. CSV output is the issue list. Markdown output contains each table
+// under its own heading.
+//
+// Parameters:
+//   - w: The output writer, which determines the format and destination
+//   - analysis: The analysis results to print
+func PrintAnalysis(w *output.Writer, analysis *Analysis) error {
+	switch w.Format() {
+	case output.FormatJSON:
+		return w.WriteJSON(analysis)
+	case output.FormatCSV:
+		return w.WriteTable(issuesTable(analysis))
+	case output.FormatMarkdown:
+		return printTables(w, analysis)
+	default:
+		return printText(w, analysis)
+	}
+}
+
+// printText writes the analysis results in human-readable text format.
+func printText(w *output.Writer, analysis *Analysis) error {
+	w.Println("============================================================")
+	w.Println(w.Colorize("ENCODING ANALYSIS", output.Bold))
+	w.Println("============================================================")
+	w.Printf("Path: %s\n", analysis.RootPath)
+	w.Printf("Files Scanned: %d\n", analysis.FilesScanned)
+	w.Printf("Files With Issues: %d\n", analysis.FilesAffected)
+	w.Printf("Total Issues: %s\n", w.Colorize(output.FormatValue(analysis.TotalIssues), output.Yellow))
+	w.Printf("Fixable: %d\n", analysis.Fixable)
+	if analysis.FilesFixed > 0 {
+		w.Printf("Fixed: %d in %d files\n", analysis.Fixed, analysis.FilesFixed)
+	}
+	w.Println("============================================================")
+	w.Println()
+
+	if analysis.TotalIssues == 0 {
+		w.Println("No problem characters found.")
+		return nil
+	}
+
+	return printTables(w, analysis)
+}
+
+// printTables writes the per-kind table and the issue list.
+func printTables(w *output.Writer, analysis *Analysis) error {
+	if err := w.WriteTable(kindTable(analysis)); err != nil {
+		return err
+	}
+	w.Println()
+	if err := w.WriteTable(issuesTable(analysis)); err != nil {
+		return err
+	}
+	w.Println()
+	return nil
+}
+
+// kindTable builds the table of issues by kind.
+func kindTable(analysis *Analysis) *output.Table {
+	table := output.NewTable("By Kind:",
+		output.Column{Header: "Kind"},
+		output.Column{Header: "Issues", Align: output.AlignRight},
+		output.Column{Header: "Files", Align: output.AlignRight},
+		output.Column{Header: "Fixable", Align: output.AlignRight},
+	)
+	for _, summary := range analysis.Kinds {
+		table.AddRow(summary.Kind, summary.Issues, summary.Files, summary.Fixable)
+	}
+	return table
+}
+
+// issuesTable builds a table with one row per issue.
+func issuesTable(analysis *Analysis) *output.Table {
+	table := output.NewTable("All Issues:",
+		output.Column{Header: "File"},
+		output.Column{Header: "Line", Align: output.AlignRight},
+		output.Column{Header: "Column", Align: output.AlignRight},
+		output.Column{Header: "Character", MaxWidth: 40},
+		output.Column{Header: "In Code"},
+		output.Column{Header: "Fix"},
+	)
+	for _, issue := range analysis.Issues {
+		fix := issue.Fix
+		switch {
+		case fix == "":
+			fix = "manual"
+		case issue.Fixed:
+			fix += " (fixed)"
+		}
+		inCode := "no"
+		if issue.InCode {
+			inCode = "yes"
+		}
+		table.AddRow(issue.FilePath, issue.LineNum, issue.Column, issue.Character, inCode, fix)
+	}
+	if len(table.Rows) == 0 {
+		table.Footer = "(none)"
+	}
+	return table
+}
@@ -0,0 +1,47 @@
+package encoding
+
+// Kinds of problem characters.
+const (
+	KindBOM              = "bom"                // Byte order mark at the start of a file
+	KindZeroWidth        = "zero-width"         // Invisible characters such as zero-width spaces and soft hyphens
+	KindNonBreakingSpace = "non-breaking-space" // Non-breaking and other fixed-width spaces in code
+	KindSmartQuote       = "smart-quote"        // Curly quotes in code
+	KindDash             = "dash"               // En dashes, em dashes, and minus signs in code
+	KindInvalidUTF8      = "invalid-utf8"       // Bytes that aren't valid UTF-8
+)
+
+// Kinds lists every kind of problem character, in report order.
+var Kinds = []string{KindBOM, KindZeroWidth, KindNonBreakingSpace, KindSmartQuote, KindDash, KindInvalidUTF8}
+
+// Issue is a single problem character.
+type Issue struct {
+	FilePath  string `json:"file_path"`     // Path to the file containing the character
+	LineNum   int    `json:"line_num"`      // Line number (1-based)
+	Column    int    `json:"column"`        // Column in characters (1-based)
+	Kind      string `json:"kind"`          // Kind of problem character
+	Character string `json:"character"`     // Code point and name, e.g. "U+00A0 NO-BREAK SPACE"
+	InCode    bool   `json:"in_code"`       // Whether the character is in code
+	Fix       string `json:"fix,omitempty"` // Safe replacement, described; empty if it needs a manual fix
+	Fixed     bool   `json:"fixed"`         // Whether --fix replaced the character
+}
+
+// KindSummary contains the issues of one kind.
+type KindSummary struct {
+	Kind    string `json:"kind"`
+	Issues  int    `json:"issues"`
+	Files   int    `json:"files"`
+	Fixable int    `json:"fixable"`
+}
+
+// Analysis contains the results of scanning for problem characters.
+type Analysis struct {
+	RootPath      string        `json:"root_path"`      // File or directory that was analyzed
+	FilesScanned  int           `json:"files_scanned"`  // Number of files scanned
+	FilesAffected int           `json:"files_affected"` // Number of files with at least one issue
+	TotalIssues   int           `json:"total_issues"`   // Total number of issues found
+	Fixable       int           `json:"fixable"`        // Issues with a safe replacement
+	Fixed         int           `json:"fixed"`          // Issues replaced by --fix
+	FilesFixed    int           `json:"files_fixed"`    // Files rewritten by --fix
+	Kinds         []KindSummary `json:"kinds"`          // Per-kind summaries, in the order of Kinds
+	Issues        []Issue       `json:"issues"`         // All issues, sorted by file, line, and column
+}
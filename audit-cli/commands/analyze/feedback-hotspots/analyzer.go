@@ -0,0 +1,139 @@
+package feedback_hotspots
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RankHotspots combines negative feedback with code example metrics and ranks the pages
+// whose code examples most need attention.
+//
+// A page's score is its negative feedback count, weighted by the number of code examples
+// on the page and by how long ago its examples last changed:
+//
+//	score = negative feedback × log2(1 + code examples) × (1 + years since examples changed)
+//
+// Pages without code examples, or without code example metrics, score zero and aren't ranked.
+//
+// Parameters:
+//   - feedback: Feedback entries; entries outside the negative categories or before Since are ignored
+//   - pages: Code example metrics for each page
+//   - opts: Ranking options
+//
+// Returns:
+//   - *Analysis: The ranked hotspots, highest score first
+func RankHotspots(feedback []FeedbackEntry, pages []PageMetrics, opts Options) *Analysis {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	negative := make(map[string]bool, len(opts.NegativeCategories))
+	for _, category := range opts.NegativeCategories {
+		negative[strings.ToLower(strings.TrimSpace(category))] = true
+	}
+
+	analysis := &Analysis{
+		GeneratedAt:        opts.Now,
+		NegativeCategories: opts.NegativeCategories,
+	}
+	if !opts.Since.IsZero() {
+		since := opts.Since
+		analysis.Since = &since
+	}
+
+	counts := make(map[string]int)
+	for _, entry := range feedback {
+		if !negative[strings.ToLower(entry.Category)] {
+			continue
+		}
+		if !opts.Since.IsZero() && entry.CreatedAt.Before(opts.Since) {
+			continue
+		}
+		key := NormalizeURL(entry.URL)
+		if key == "" {
+			continue
+		}
+		counts[key]++
+		analysis.NegativeFeedback++
+	}
+	analysis.PagesWithFeedback = len(counts)
+
+	metrics := make(map[string]PageMetrics, len(pages))
+	for _, page := range pages {
+		metrics[NormalizeURL(page.URL)] = page
+	}
+
+	for key, count := range counts {
+		page, found := metrics[key]
+		if !found {
+			analysis.UnmatchedPages++
+			continue
+		}
+		if count < opts.MinFeedback || page.CodeExamples == 0 {
+			continue
+		}
+
+		lastUpdated := page.ExamplesUpdated
+		if lastUpdated.IsZero() {
+			lastUpdated = page.PageLastModified
+		}
+		staleDays := 0
+		if !lastUpdated.IsZero() && opts.Now.After(lastUpdated) {
+			staleDays = int(opts.Now.Sub(lastUpdated).Hours() / 24)
+		}
+
+		analysis.Hotspots = append(analysis.Hotspots, Hotspot{
+			URL:              page.URL,
+			Project:          page.Project,
+			NegativeFeedback: count,
+			CodeExamples:     page.CodeExamples,
+			LastUpdated:      lastUpdated,
+			StaleDays:        staleDays,
+			Score:            score(count, page.CodeExamples, staleDays),
+		})
+	}
+
+	sort.Slice(analysis.Hotspots, func(i, j int) bool {
+		a, b := analysis.Hotspots[i], analysis.Hotspots[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.URL < b.URL
+	})
+	if opts.Limit > 0 && len(analysis.Hotspots) > opts.Limit {
+		analysis.Hotspots = analysis.Hotspots[:opts.Limit]
+	}
+	for i := range analysis.Hotspots {
+		analysis.Hotspots[i].Rank = i + 1
+	}
+
+	return analysis
+}
+
+// score weights a page's negative feedback by its example count and staleness, rounded to two decimals.
+func score(negativeFeedback int, codeExamples int, staleDays int) float64 {
+	value := float64(negativeFeedback) * math.Log2(1+float64(codeExamples)) * (1 + float64(staleDays)/365)
+	return math.Round(value*100) / 100
+}
+
+// NormalizeURL reduces a page URL to its host and path so the URLs recorded with feedback
+// match the URLs in the code example database. The scheme, "www.", query string, fragment,
+// and trailing slash are dropped, and the result is lowercase.
+func NormalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return strings.ToLower(strings.TrimSuffix(rawURL, "/"))
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	return host + strings.TrimSuffix(strings.ToLower(parsed.Path), "/")
+}
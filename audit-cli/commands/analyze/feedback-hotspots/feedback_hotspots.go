@@ -0,0 +1,164 @@
+// Package feedback_hotspots provides functionality for prioritizing code example fixes.
+//
+// This package implements the "analyze feedback-hotspots" subcommand, which combines reader
+// feedback from the docs feedback database with code example metrics from the code example
+// database, and ranks pages by negative feedback weighted by example count and staleness.
+package feedback_hotspots
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// Environment variables holding the connection strings. They should be for read-only users.
+const (
+	FeedbackURIEnv = "FEEDBACK_MONGODB_URI"
+	MetricsURIEnv  = "CODE_METRICS_MONGODB_URI"
+)
+
+// NewFeedbackHotspotsCommand creates the feedback-hotspots subcommand.
+//
+// This command reads negative feedback and code example metrics, and ranks the pages whose
+// code examples should be fixed first.
+//
+// Usage:
+//
+//	analyze feedback-hotspots --feedback-db docs_feedback --feedback-collection feedback --metrics-db code_metrics
+//	analyze feedback-hotspots ... --since 2025-01-01 --limit 50
+//	analyze feedback-hotspots ... --format csv --output-file hotspots.csv
+//
+// Flags:
+//   - --feedback-db: Database containing the docs feedback
+//   - --feedback-collection: Collection containing the docs feedback
+//   - --metrics-db: Database containing the code example metrics
+//   - --negative-categories: Feedback categories counted as negative
+//   - --since: Only count feedback left on or after this date (YYYY-MM-DD)
+//   - --min-feedback: Minimum negative feedback entries for a page to be ranked
+//   - --limit: Maximum number of pages to list (0 for all)
+//   - --format: Output format (text, json, csv, or markdown)
+//   - --output-file: Write results to a file instead of stdout
+func NewFeedbackHotspotsCommand() *cobra.Command {
+	var (
+		feedbackDB         string
+		feedbackCollection string
+		metricsDB          string
+		negativeCategories []string
+		since              string
+		minFeedback        int
+		limit              int
+		outputOpts         output.Options
+	)
+
+	cmd := &cobra.Command{
+		Use:   "feedback-hotspots",
+		Short: "Rank pages by negative feedback, weighted by code example count and staleness",
+		Long: `Rank docs pages by how urgently their code examples need attention.
+
+This command reads negative reader feedback from the docs feedback database and code example
+metrics from the code example database, matches them by page URL, and produces a prioritized
+"fix first" list. Each page's score is its negative feedback count weighted by the number of
+code examples on the page and by how long ago its examples last changed:
+
+  score = negative feedback × log2(1 + code examples) × (1 + years since examples changed)
+
+Pages without code examples aren't listed.
+
+The connection strings are read from the ` + FeedbackURIEnv + ` and ` + MetricsURIEnv + `
+environment variables. The command only reads from either database, and refuses to run with
+credentials that have write privileges, so use read-only users.
+
+Examples:
+  # List the 25 pages to fix first
+  analyze feedback-hotspots --feedback-db docs_feedback --feedback-collection feedback --metrics-db code_metrics
+
+  # Only count feedback from this year, and list pages with at least 3 negative entries
+  analyze feedback-hotspots --feedback-db docs_feedback --feedback-collection feedback --metrics-db code_metrics \
+    --since 2025-01-01 --min-feedback 3
+
+  # Write the full ranking to a CSV file
+  analyze feedback-hotspots --feedback-db docs_feedback --feedback-collection feedback --metrics-db code_metrics \
+    --limit 0 --format csv --output-file hotspots.csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFeedbackHotspots(cmd.Context(), feedbackDB, feedbackCollection, metricsDB, negativeCategories, since, minFeedback, limit, outputOpts)
+		},
+	}
+
+	cmd.Flags().StringVar(&feedbackDB, "feedback-db", "", "Database containing the docs feedback (required)")
+	cmd.Flags().StringVar(&feedbackCollection, "feedback-collection", "", "Collection containing the docs feedback (required)")
+	cmd.Flags().StringVar(&metricsDB, "metrics-db", "", "Database containing the code example metrics (required)")
+	cmd.Flags().StringSliceVar(&negativeCategories, "negative-categories", DefaultNegativeCategories, "Feedback categories counted as negative")
+	cmd.Flags().StringVar(&since, "since", "", "Only count feedback left on or after this date (YYYY-MM-DD)")
+	cmd.Flags().IntVar(&minFeedback, "min-feedback", 1, "Minimum negative feedback entries for a page to be ranked")
+	cmd.Flags().IntVar(&limit, "limit", 25, "Maximum number of pages to list (0 for all)")
+	output.AddFlags(cmd, &outputOpts)
+	_ = cmd.MarkFlagRequired("feedback-db")
+	_ = cmd.MarkFlagRequired("feedback-collection")
+	_ = cmd.MarkFlagRequired("metrics-db")
+
+	return cmd
+}
+
+// runFeedbackHotspots executes the feedback hotspot analysis operation.
+func runFeedbackHotspots(ctx context.Context, feedbackDB, feedbackCollection, metricsDB string, negativeCategories []string, since string, minFeedback, limit int, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+	if len(negativeCategories) == 0 {
+		return fmt.Errorf("--negative-categories must list at least one category")
+	}
+	opts := Options{
+		NegativeCategories: negativeCategories,
+		MinFeedback:        minFeedback,
+		Limit:              limit,
+		Now:                time.Now(),
+	}
+	if since != "" {
+		parsed, err := time.Parse("2006-01-02", since)
+		if err != nil {
+			return fmt.Errorf("--since %q is not in YYYY-MM-DD format", since)
+		}
+		opts.Since = parsed
+	}
+
+	feedbackURI := os.Getenv(FeedbackURIEnv)
+	metricsURI := os.Getenv(MetricsURIEnv)
+	if feedbackURI == "" || metricsURI == "" {
+		return fmt.Errorf("set %s and %s to connection strings for read-only users", FeedbackURIEnv, MetricsURIEnv)
+	}
+
+	feedbackClient, err := Connect(ctx, "feedback", feedbackURI)
+	if err != nil {
+		return err
+	}
+	defer feedbackClient.Disconnect(ctx)
+	metricsClient, err := Connect(ctx, "code metrics", metricsURI)
+	if err != nil {
+		return err
+	}
+	defer metricsClient.Disconnect(ctx)
+
+	feedback, err := LoadFeedback(ctx, feedbackClient.Database(feedbackDB).Collection(feedbackCollection), negativeCategories, opts.Since)
+	if err != nil {
+		return err
+	}
+	pages, err := LoadPageMetrics(ctx, metricsClient.Database(metricsDB))
+	if err != nil {
+		return err
+	}
+
+	analysis := RankHotspots(feedback, pages, opts)
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return PrintAnalysis(w, analysis)
+}
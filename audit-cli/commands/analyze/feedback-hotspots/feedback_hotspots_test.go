@@ -0,0 +1,136 @@
+package feedback_hotspots
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+var testNow = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func testPages() []PageMetrics {
+	return []PageMetrics{
+		{URL: "https://www.mongodb.com/docs/atlas/connect/", Project: "atlas", CodeExamples: 12, ExamplesUpdated: testNow.AddDate(-2, 0, 0)},
+		{URL: "https://www.mongodb.com/docs/manual/crud/", Project: "manual", CodeExamples: 3, ExamplesUpdated: testNow.AddDate(0, 0, -10)},
+		{URL: "https://www.mongodb.com/docs/manual/intro/", Project: "manual", CodeExamples: 0},
+		{URL: "https://www.mongodb.com/docs/compass/query/", Project: "compass", CodeExamples: 4, PageLastModified: testNow.AddDate(-1, 0, 0)},
+	}
+}
+
+func feedbackFor(url string, category string, count int) []FeedbackEntry {
+	entries := make([]FeedbackEntry, count)
+	for i := range entries {
+		entries[i] = FeedbackEntry{URL: url, Category: category, CreatedAt: testNow.AddDate(0, -1, 0)}
+	}
+	return entries
+}
+
+func TestRankHotspots(t *testing.T) {
+	var feedback []FeedbackEntry
+	feedback = append(feedback, feedbackFor("mongodb.com/docs/atlas/connect", "Negative", 2)...)
+	feedback = append(feedback, feedbackFor("https://www.mongodb.com/docs/manual/crud/?tab=shell#insert", "negative", 5)...)
+	feedback = append(feedback, feedbackFor("https://www.mongodb.com/docs/manual/crud/", "positive", 10)...)
+	feedback = append(feedback, feedbackFor("https://www.mongodb.com/docs/manual/intro/", "unhelpful", 7)...)
+	feedback = append(feedback, feedbackFor("https://www.mongodb.com/docs/compass/query/", "negative", 1)...)
+	feedback = append(feedback, feedbackFor("https://www.mongodb.com/docs/unknown/page/", "negative", 3)...)
+
+	analysis := RankHotspots(feedback, testPages(), Options{NegativeCategories: DefaultNegativeCategories, Now: testNow})
+
+	if analysis.NegativeFeedback != 18 {
+		t.Errorf("expected 18 negative feedback entries, got %d", analysis.NegativeFeedback)
+	}
+	if analysis.PagesWithFeedback != 5 {
+		t.Errorf("expected 5 pages with feedback, got %d", analysis.PagesWithFeedback)
+	}
+	if analysis.UnmatchedPages != 1 {
+		t.Errorf("expected 1 unmatched page, got %d", analysis.UnmatchedPages)
+	}
+
+	// The intro page has no code examples, so it isn't ranked
+	var urls []string
+	for _, hotspot := range analysis.Hotspots {
+		urls = append(urls, hotspot.URL)
+	}
+	want := []string{
+		"https://www.mongodb.com/docs/atlas/connect/",
+		"https://www.mongodb.com/docs/manual/crud/",
+		"https://www.mongodb.com/docs/compass/query/",
+	}
+	if strings.Join(urls, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected ranking:\ngot:  %v\nwant: %v", urls, want)
+	}
+
+	atlas := analysis.Hotspots[0]
+	if atlas.Rank != 1 || atlas.NegativeFeedback != 2 || atlas.StaleDays != 731 {
+		t.Errorf("unexpected atlas hotspot: %+v", atlas)
+	}
+	// 2 × log2(13) × (1 + 731/365)
+	if atlas.Score != 22.22 {
+		t.Errorf("expected score 22.22, got %v", atlas.Score)
+	}
+
+	// Pages without example dates fall back to when the page last changed
+	compass := analysis.Hotspots[2]
+	if compass.StaleDays != 365 || !compass.LastUpdated.Equal(testNow.AddDate(-1, 0, 0)) {
+		t.Errorf("expected compass staleness from the page date, got %+v", compass)
+	}
+}
+
+func TestRankHotspotsOptions(t *testing.T) {
+	var feedback []FeedbackEntry
+	feedback = append(feedback, feedbackFor("https://www.mongodb.com/docs/atlas/connect/", "negative", 1)...)
+	feedback = append(feedback, feedbackFor("https://www.mongodb.com/docs/manual/crud/", "negative", 3)...)
+	feedback = append(feedback, FeedbackEntry{URL: "https://www.mongodb.com/docs/compass/query/", Category: "negative", CreatedAt: testNow.AddDate(-1, 0, 0)})
+
+	analysis := RankHotspots(feedback, testPages(), Options{
+		NegativeCategories: []string{"negative"},
+		Since:              testNow.AddDate(0, -6, 0),
+		MinFeedback:        2,
+		Now:                testNow,
+	})
+	if analysis.NegativeFeedback != 4 {
+		t.Errorf("expected feedback before --since to be ignored, got %d entries", analysis.NegativeFeedback)
+	}
+	if len(analysis.Hotspots) != 1 || analysis.Hotspots[0].Project != "manual" {
+		t.Errorf("expected only the page with 3 entries to be ranked, got %+v", analysis.Hotspots)
+	}
+
+	analysis = RankHotspots(feedback, testPages(), Options{NegativeCategories: []string{"negative"}, Limit: 1, Now: testNow})
+	if len(analysis.Hotspots) != 1 {
+		t.Errorf("expected --limit to cap the hotspots, got %d", len(analysis.Hotspots))
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.mongodb.com/docs/atlas/", "mongodb.com/docs/atlas"},
+		{"http://mongodb.com/docs/Atlas", "mongodb.com/docs/atlas"},
+		{"www.mongodb.com/docs/atlas/?tab=go#connect", "mongodb.com/docs/atlas"},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeURL(tt.url); got != tt.want {
+			t.Errorf("NormalizeURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestPrintAnalysisCSV(t *testing.T) {
+	feedback := feedbackFor("https://www.mongodb.com/docs/manual/crud/", "negative", 2)
+	analysis := RankHotspots(feedback, testPages(), Options{NegativeCategories: DefaultNegativeCategories, Now: testNow})
+
+	var buf bytes.Buffer
+	if err := PrintAnalysis(output.NewWriter(&buf, output.FormatCSV), analysis); err != nil {
+		t.Fatalf("PrintAnalysis failed: %v", err)
+	}
+	want := "Rank,Score,Negative,Examples,Stale Days,Project,URL\n1,4.11,2,3,10,manual,https://www.mongodb.com/docs/manual/crud/\n"
+	if buf.String() != want {
+		t.Errorf("unexpected CSV output\ngot:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,69 @@
+package feedback_hotspots
+
+import (
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// PrintAnalysis writes the ranked hotspots in the writer's format.
+//
+// Text output is a summary followed by the hotspot table. JSON output is the full analysis.
+// CSV and markdown output are the hotspot table.
+//
+// Parameters:
+//   - w: The output writer, which determines the format and destination
+//   - analysis: The analysis results to print
+func PrintAnalysis(w *output.Writer, analysis *Analysis) error {
+	switch w.Format() {
+	case output.FormatJSON:
+		return w.WriteJSON(analysis)
+	case output.FormatCSV, output.FormatMarkdown:
+		return w.WriteTable(hotspotTable(analysis))
+	default:
+		return printText(w, analysis)
+	}
+}
+
+// printText writes the analysis results in human-readable text format.
+func printText(w *output.Writer, analysis *Analysis) error {
+	w.Println("============================================================")
+	w.Println(w.Colorize("FEEDBACK HOTSPOTS", output.Bold))
+	w.Println("============================================================")
+	w.Printf("Negative Categories: %s\n", strings.Join(analysis.NegativeCategories, ", "))
+	if analysis.Since != nil {
+		w.Printf("Feedback Since: %s\n", analysis.Since.Format("2006-01-02"))
+	}
+	w.Printf("Negative Feedback: %d entries on %d pages\n", analysis.NegativeFeedback, analysis.PagesWithFeedback)
+	w.Printf("Pages Without Code Example Metrics: %d\n", analysis.UnmatchedPages)
+	w.Println("============================================================")
+	w.Println()
+
+	if len(analysis.Hotspots) == 0 {
+		w.Println("No pages with code examples have negative feedback.")
+		return nil
+	}
+
+	if err := w.WriteTable(hotspotTable(analysis)); err != nil {
+		return err
+	}
+	w.Println()
+	return nil
+}
+
+// hotspotTable builds the table of ranked pages.
+func hotspotTable(analysis *Analysis) *output.Table {
+	table := output.NewTable("Fix First:",
+		output.Column{Header: "Rank", Align: output.AlignRight},
+		output.Column{Header: "Score", Align: output.AlignRight},
+		output.Column{Header: "Negative", Align: output.AlignRight},
+		output.Column{Header: "Examples", Align: output.AlignRight},
+		output.Column{Header: "Stale Days", Align: output.AlignRight},
+		output.Column{Header: "Project"},
+		output.Column{Header: "URL", MaxWidth: 80},
+	)
+	for _, hotspot := range analysis.Hotspots {
+		table.AddRow(hotspot.Rank, hotspot.Score, hotspot.NegativeFeedback, hotspot.CodeExamples, hotspot.StaleDays, hotspot.Project, hotspot.URL)
+	}
+	return table
+}
@@ -0,0 +1,220 @@
+package feedback_hotspots
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+// writeActions are privileges that let a user change data. The command refuses to run with
+// credentials that have any of them, so it can never modify either dataset.
+var writeActions = map[string]bool{
+	"insert":                 true,
+	"update":                 true,
+	"remove":                 true,
+	"createCollection":       true,
+	"createIndex":            true,
+	"dropCollection":         true,
+	"dropDatabase":           true,
+	"dropIndex":              true,
+	"renameCollectionSameDB": true,
+}
+
+// Connect opens a connection for reading and checks that the credentials are read-only.
+//
+// Parameters:
+//   - ctx: Context for the connection
+//   - name: Name of the dataset, used in error messages
+//   - uri: Connection string
+//
+// Returns:
+//   - *mongo.Client: The connected client; the caller must disconnect it
+//   - error: Any error connecting, or if the credentials can write
+func Connect(ctx context.Context, name string, uri string) (*mongo.Client, error) {
+	client, err := mongo.Connect(options.Client().ApplyURI(uri).SetReadPreference(readpref.SecondaryPreferred()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the %s database: %w", name, err)
+	}
+	if err := checkReadOnly(ctx, client); err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("%s database: %w", name, err)
+	}
+	return client, nil
+}
+
+// checkReadOnly returns an error if the connected user has any privilege that can write data.
+func checkReadOnly(ctx context.Context, client *mongo.Client) error {
+	var status struct {
+		AuthInfo struct {
+			Privileges []struct {
+				Actions []string `bson:"actions"`
+			} `bson:"authenticatedUserPrivileges"`
+		} `bson:"authInfo"`
+	}
+	command := bson.D{{Key: "connectionStatus", Value: 1}, {Key: "showPrivileges", Value: true}}
+	if err := client.Database("admin").RunCommand(ctx, command).Decode(&status); err != nil {
+		return fmt.Errorf("failed to check credentials: %w", err)
+	}
+	for _, privilege := range status.AuthInfo.Privileges {
+		for _, action := range privilege.Actions {
+			if writeActions[action] {
+				return fmt.Errorf("credentials have the %q privilege; use a read-only user", action)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadFeedback reads the negative feedback entries from the feedback collection.
+//
+// Parameters:
+//   - ctx: Context for the query
+//   - collection: The feedback collection
+//   - categories: Feedback categories counted as negative, matched case-insensitively
+//   - since: Only read feedback left on or after this time; zero reads all feedback
+//
+// Returns:
+//   - []FeedbackEntry: The matching feedback entries
+//   - error: Any error reading the collection
+func LoadFeedback(ctx context.Context, collection *mongo.Collection, categories []string, since time.Time) ([]FeedbackEntry, error) {
+	quoted := make([]string, len(categories))
+	for i, category := range categories {
+		quoted[i] = regexp.QuoteMeta(strings.TrimSpace(category))
+	}
+	filter := bson.D{
+		{Key: "category", Value: bson.D{
+			{Key: "$regex", Value: "^(" + strings.Join(quoted, "|") + ")$"},
+			{Key: "$options", Value: "i"},
+		}},
+	}
+	if !since.IsZero() {
+		filter = append(filter, bson.E{Key: "createdAt", Value: bson.D{{Key: "$gte", Value: since}}})
+	}
+	projection := bson.D{
+		{Key: "page.url", Value: 1},
+		{Key: "page.docs_property", Value: 1},
+		{Key: "category", Value: 1},
+		{Key: "createdAt", Value: 1},
+	}
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feedback: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []FeedbackEntry
+	for cursor.Next(ctx) {
+		var doc struct {
+			Page struct {
+				URL          string `bson:"url"`
+				DocsProperty string `bson:"docs_property"`
+			} `bson:"page"`
+			Category  string    `bson:"category"`
+			CreatedAt time.Time `bson:"createdAt"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode feedback: %w", err)
+		}
+		entries = append(entries, FeedbackEntry{
+			URL:          doc.Page.URL,
+			DocsProperty: doc.Page.DocsProperty,
+			Category:     doc.Category,
+			CreatedAt:    doc.CreatedAt,
+		})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read feedback: %w", err)
+	}
+	return entries, nil
+}
+
+// LoadPageMetrics reads the code example metrics for every current page in the code example
+// database, which has one collection per docs project.
+//
+// Parameters:
+//   - ctx: Context for the queries
+//   - db: The code example database
+//
+// Returns:
+//   - []PageMetrics: Metrics for each page that hasn't been removed
+//   - error: Any error reading the database
+func LoadPageMetrics(ctx context.Context, db *mongo.Database) ([]PageMetrics, error) {
+	collections, err := db.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list code example collections: %w", err)
+	}
+
+	filter := bson.D{
+		{Key: "page_url", Value: bson.D{{Key: "$exists", Value: true}}},
+		{Key: "is_removed", Value: bson.D{{Key: "$ne", Value: true}}},
+	}
+	projection := bson.D{
+		{Key: "page_url", Value: 1},
+		{Key: "project_name", Value: 1},
+		{Key: "code_nodes_total", Value: 1},
+		{Key: "date_last_updated", Value: 1},
+		{Key: "nodes.date_added", Value: 1},
+		{Key: "nodes.date_updated", Value: 1},
+		{Key: "nodes.is_removed", Value: 1},
+	}
+
+	var pages []PageMetrics
+	for _, name := range collections {
+		cursor, err := db.Collection(name).Find(ctx, filter, options.Find().SetProjection(projection))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query collection %s: %w", name, err)
+		}
+		for cursor.Next(ctx) {
+			var doc struct {
+				PageURL         string    `bson:"page_url"`
+				ProjectName     string    `bson:"project_name"`
+				CodeNodesTotal  int       `bson:"code_nodes_total"`
+				DateLastUpdated time.Time `bson:"date_last_updated"`
+				Nodes           []struct {
+					DateAdded   time.Time `bson:"date_added"`
+					DateUpdated time.Time `bson:"date_updated"`
+					IsRemoved   bool      `bson:"is_removed"`
+				} `bson:"nodes"`
+			}
+			if err := cursor.Decode(&doc); err != nil {
+				cursor.Close(ctx)
+				return nil, fmt.Errorf("failed to decode page in collection %s: %w", name, err)
+			}
+
+			page := PageMetrics{
+				URL:              doc.PageURL,
+				Project:          doc.ProjectName,
+				CodeExamples:     doc.CodeNodesTotal,
+				PageLastModified: doc.DateLastUpdated,
+			}
+			if page.Project == "" {
+				page.Project = name
+			}
+			for _, node := range doc.Nodes {
+				if node.IsRemoved {
+					continue
+				}
+				for _, date := range []time.Time{node.DateAdded, node.DateUpdated} {
+					if date.After(page.ExamplesUpdated) {
+						page.ExamplesUpdated = date
+					}
+				}
+			}
+			pages = append(pages, page)
+		}
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read collection %s: %w", name, err)
+		}
+	}
+	return pages, nil
+}
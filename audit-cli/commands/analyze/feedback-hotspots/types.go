@@ -0,0 +1,55 @@
+package feedback_hotspots
+
+import "time"
+
+// DefaultNegativeCategories are the feedback categories counted as negative feedback.
+var DefaultNegativeCategories = []string{"negative", "unhelpful"}
+
+// FeedbackEntry is a single piece of reader feedback about a docs page.
+type FeedbackEntry struct {
+	URL          string    // URL of the page the feedback is about
+	DocsProperty string    // Docs project the page belongs to, e.g. "cloud-docs"
+	Category     string    // Feedback category, e.g. "negative"
+	CreatedAt    time.Time // When the feedback was left
+}
+
+// PageMetrics contains the code example metrics for a docs page, from the code example database.
+type PageMetrics struct {
+	URL              string    // Production URL of the page
+	Project          string    // Docs project the page belongs to
+	CodeExamples     int       // Number of code examples on the page
+	ExamplesUpdated  time.Time // Most recent time a code example on the page was added or updated
+	PageLastModified time.Time // Last time the page changed, used if no example has a date
+}
+
+// Hotspot is a page ranked by how urgently its code examples need attention.
+type Hotspot struct {
+	Rank             int       `json:"rank"`
+	URL              string    `json:"url"`
+	Project          string    `json:"project"`
+	NegativeFeedback int       `json:"negative_feedback"` // Negative feedback entries about the page
+	CodeExamples     int       `json:"code_examples"`
+	LastUpdated      time.Time `json:"last_updated"` // When the page's code examples last changed
+	StaleDays        int       `json:"stale_days"`   // Days since the page's code examples last changed
+	Score            float64   `json:"score"`
+}
+
+// Options controls how hotspots are ranked.
+type Options struct {
+	NegativeCategories []string  // Feedback categories counted as negative, matched case-insensitively
+	Since              time.Time // Only count feedback left on or after this time; zero counts all feedback
+	MinFeedback        int       // Pages need at least this many negative feedback entries to be ranked
+	Limit              int       // Maximum number of hotspots to return; 0 returns all
+	Now                time.Time // Time staleness is measured from
+}
+
+// Analysis contains the ranked hotspots and how they were found.
+type Analysis struct {
+	GeneratedAt        time.Time  `json:"generated_at"`
+	Since              *time.Time `json:"since,omitempty"`
+	NegativeCategories []string   `json:"negative_categories"`
+	NegativeFeedback   int        `json:"negative_feedback"`   // Negative feedback entries considered
+	PagesWithFeedback  int        `json:"pages_with_feedback"` // Pages with negative feedback
+	UnmatchedPages     int        `json:"unmatched_pages"`     // Pages with negative feedback but no code example metrics
+	Hotspots           []Hotspot  `json:"hotspots"`
+}
@@ -12,6 +12,7 @@ package includes
 import (
 	"fmt"
 
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -24,11 +25,13 @@ import (
 //   - --tree: Display results as a hierarchical tree structure
 //   - --list: Display results as a flat list of all files
 //   - -v, --verbose: Show detailed processing information
+//   - --output-file: Write results to a file instead of stdout
 func NewIncludesCommand() *cobra.Command {
 	var (
 		showTree bool
 		showList bool
-		verbose  bool
+		verbose    bool
+		outputOpts output.Options
 	)
 
 	cmd := &cobra.Command{
@@ -48,13 +51,14 @@ If neither flag is specified, shows a summary with basic statistics.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			filePath := args[0]
-			return runAnalyze(filePath, showTree, showList, verbose)
+			return runAnalyze(filePath, showTree, showList, verbose, outputOpts)
 		},
 	}
 
 	cmd.Flags().BoolVar(&showTree, "tree", false, "Display results as a hierarchical tree structure")
 	cmd.Flags().BoolVar(&showList, "list", false, "Display results as a flat list of all files")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed processing information")
+	output.AddFileFlags(cmd, &outputOpts)
 
 	return cmd
 }
@@ -69,31 +73,38 @@ If neither flag is specified, shows a summary with basic statistics.`,
 //   - showTree: If true, display tree structure
 //   - showList: If true, display flat list
 //   - verbose: If true, show detailed processing information
+//   - outputOpts: Output destination
 //
 // Returns:
 //   - error: Any error encountered during analysis
-func runAnalyze(filePath string, showTree bool, showList bool, verbose bool) error {
+func runAnalyze(filePath string, showTree bool, showList bool, verbose bool, outputOpts output.Options) error {
 	// Perform the analysis
 	analysis, err := AnalyzeIncludes(filePath, verbose)
 	if err != nil {
 		return fmt.Errorf("failed to analyze includes: %w", err)
 	}
 
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
 	// Display results based on flags
 	if showTree && showList {
 		// Both flags specified - show both outputs
-		PrintTree(analysis)
-		fmt.Println()
-		PrintList(analysis)
+		PrintTree(w, analysis)
+		w.Println()
+		PrintList(w, analysis)
 	} else if showTree {
 		// Only tree
-		PrintTree(analysis)
+		PrintTree(w, analysis)
 	} else if showList {
 		// Only list
-		PrintList(analysis)
+		PrintList(w, analysis)
 	} else {
 		// Neither flag - show summary
-		PrintSummary(analysis)
+		PrintSummary(w, analysis)
 	}
 
 	return nil
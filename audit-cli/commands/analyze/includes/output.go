@@ -1,10 +1,10 @@
 package includes
 
 import (
-	"fmt"
 	"path/filepath"
 	"strings"
 
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 	"github.com/mongodb/code-example-tooling/audit-cli/internal/projectinfo"
 )
 
@@ -14,23 +14,24 @@ import (
 // tree-style formatting with box-drawing characters.
 //
 // Parameters:
+//   - w: The output writer
 //   - analysis: The analysis results containing the tree structure
-func PrintTree(analysis *IncludeAnalysis) {
-	fmt.Println("============================================================")
-	fmt.Println("INCLUDE TREE")
-	fmt.Println("============================================================")
-	fmt.Printf("Root File: %s\n", analysis.RootFile)
-	fmt.Printf("Unique Files: %d\n", analysis.TotalFiles)
-	fmt.Printf("Include Directives: %d\n", analysis.TotalIncludeDirectives)
-	fmt.Printf("Max Depth: %d\n", analysis.MaxDepth)
-	fmt.Println("============================================================")
-	fmt.Println()
+func PrintTree(w *output.Writer, analysis *IncludeAnalysis) {
+	w.Println("============================================================")
+	w.Println("INCLUDE TREE")
+	w.Println("============================================================")
+	w.Printf("Root File: %s\n", analysis.RootFile)
+	w.Printf("Unique Files: %d\n", analysis.TotalFiles)
+	w.Printf("Include Directives: %d\n", analysis.TotalIncludeDirectives)
+	w.Printf("Max Depth: %d\n", analysis.MaxDepth)
+	w.Println("============================================================")
+	w.Println()
 
 	if analysis.Tree != nil {
-		printTreeNode(analysis.Tree, "", true, true)
+		printTreeNode(w, analysis.Tree, "", true, true)
 	}
 
-	fmt.Println()
+	w.Println()
 }
 
 // printTreeNode recursively prints a tree node with proper formatting.
@@ -38,24 +39,25 @@ func PrintTree(analysis *IncludeAnalysis) {
 // This function uses box-drawing characters to create a visual tree structure.
 //
 // Parameters:
+//   - w: The output writer
 //   - node: The node to print
 //   - prefix: Prefix string for indentation
 //   - isLast: Whether this is the last child of its parent
 //   - isRoot: Whether this is the root node
-func printTreeNode(node *IncludeNode, prefix string, isLast bool, isRoot bool) {
+func printTreeNode(w *output.Writer, node *IncludeNode, prefix string, isLast bool, isRoot bool) {
 	if node == nil {
 		return
 	}
 
 	// Print the current node
 	if isRoot {
-		fmt.Printf("%s\n", formatDisplayPath(node.FilePath))
+		w.Printf("%s\n", formatDisplayPath(node.FilePath))
 	} else {
 		connector := "├── "
 		if isLast {
 			connector = "└── "
 		}
-		fmt.Printf("%s%s%s\n", prefix, connector, formatDisplayPath(node.FilePath))
+		w.Printf("%s%s%s\n", prefix, connector, formatDisplayPath(node.FilePath))
 	}
 
 	// Print children
@@ -70,7 +72,7 @@ func printTreeNode(node *IncludeNode, prefix string, isLast bool, isRoot bool) {
 
 	for i, child := range node.Children {
 		isLastChild := i == len(node.Children)-1
-		printTreeNode(child, childPrefix, isLastChild, false)
+		printTreeNode(w, child, childPrefix, isLastChild, false)
 	}
 }
 
@@ -80,22 +82,23 @@ func printTreeNode(node *IncludeNode, prefix string, isLast bool, isRoot bool) {
 // in the order they were discovered (depth-first traversal).
 //
 // Parameters:
+//   - w: The output writer
 //   - analysis: The analysis results containing the file list
-func PrintList(analysis *IncludeAnalysis) {
-	fmt.Println("============================================================")
-	fmt.Println("INCLUDE FILE LIST")
-	fmt.Println("============================================================")
-	fmt.Printf("Root File: %s\n", analysis.RootFile)
-	fmt.Printf("Unique Files: %d\n", analysis.TotalFiles)
-	fmt.Printf("Include Directives: %d\n", analysis.TotalIncludeDirectives)
-	fmt.Println("============================================================")
-	fmt.Println()
+func PrintList(w *output.Writer, analysis *IncludeAnalysis) {
+	w.Println("============================================================")
+	w.Println("INCLUDE FILE LIST")
+	w.Println("============================================================")
+	w.Printf("Root File: %s\n", analysis.RootFile)
+	w.Printf("Unique Files: %d\n", analysis.TotalFiles)
+	w.Printf("Include Directives: %d\n", analysis.TotalIncludeDirectives)
+	w.Println("============================================================")
+	w.Println()
 
 	for i, file := range analysis.AllFiles {
-		fmt.Printf("%3d. %s\n", i+1, file)
+		w.Printf("%3d. %s\n", i+1, file)
 	}
 
-	fmt.Println()
+	w.Println()
 }
 
 // PrintSummary prints a brief summary of the analysis.
@@ -104,20 +107,21 @@ func PrintList(analysis *IncludeAnalysis) {
 // providing basic statistics about the include structure.
 //
 // Parameters:
+//   - w: The output writer
 //   - analysis: The analysis results
-func PrintSummary(analysis *IncludeAnalysis) {
-	fmt.Println("============================================================")
-	fmt.Println("INCLUDE ANALYSIS SUMMARY")
-	fmt.Println("============================================================")
-	fmt.Printf("Root File: %s\n", analysis.RootFile)
-	fmt.Printf("Unique Files: %d\n", analysis.TotalFiles)
-	fmt.Printf("Include Directives: %d\n", analysis.TotalIncludeDirectives)
-	fmt.Printf("Max Depth: %d\n", analysis.MaxDepth)
-	fmt.Println("============================================================")
-	fmt.Println()
-	fmt.Println("Use --tree to see the hierarchical structure")
-	fmt.Println("Use --list to see a flat list of all files")
-	fmt.Println()
+func PrintSummary(w *output.Writer, analysis *IncludeAnalysis) {
+	w.Println("============================================================")
+	w.Println("INCLUDE ANALYSIS SUMMARY")
+	w.Println("============================================================")
+	w.Printf("Root File: %s\n", analysis.RootFile)
+	w.Printf("Unique Files: %d\n", analysis.TotalFiles)
+	w.Printf("Include Directives: %d\n", analysis.TotalIncludeDirectives)
+	w.Printf("Max Depth: %d\n", analysis.MaxDepth)
+	w.Println("============================================================")
+	w.Println()
+	w.Println("Use --tree to see the hierarchical structure")
+	w.Println("Use --list to see a flat list of all files")
+	w.Println()
 }
 
 // formatDisplayPath formats a file path for display in the tree or verbose output.
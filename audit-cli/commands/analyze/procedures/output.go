@@ -1,8 +1,9 @@
 package procedures
 
 import (
-	"fmt"
 	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 )
 
 // OutputOptions controls what information is displayed in the output.
@@ -14,16 +15,16 @@ type OutputOptions struct {
 	StepCount      bool // Show step count for each procedure
 }
 
-// PrintReport prints the analysis report to stdout based on the output options.
-func PrintReport(report *AnalysisReport, options OutputOptions) {
+// PrintReport prints the analysis report to the writer based on the output options.
+func PrintReport(w *output.Writer, report *AnalysisReport, options OutputOptions) {
 	// If no special options are set, just print the count
 	if !options.ListAll && !options.ListSummary && !options.Implementation && !options.SubProcedures && !options.StepCount {
-		printSummary(report)
+		printSummary(w, report)
 		return
 	}
 
 	// Print detailed report
-	printDetailedReport(report, options)
+	printDetailedReport(w, report, options)
 }
 
 // groupProceduresByHeading groups procedures by their heading and returns the groups and order.
@@ -62,50 +63,50 @@ func calculateTotals(headingGroups map[string][]ProcedureAnalysis) (int, int) {
 }
 
 // printSummary prints a summary of the analysis.
-func printSummary(report *AnalysisReport) {
-	fmt.Printf("File: %s\n", report.FilePath)
-	fmt.Printf("Total unique procedures: %d\n", len(report.Procedures))
-	fmt.Printf("Total procedure appearances: %d\n", report.TotalVariations)
+func printSummary(w *output.Writer, report *AnalysisReport) {
+	w.Printf("File: %s\n", report.FilePath)
+	w.Printf("Total unique procedures: %d\n", len(report.Procedures))
+	w.Printf("Total procedure appearances: %d\n", report.TotalVariations)
 }
 
 // printDetailedReport prints a detailed analysis report.
-func printDetailedReport(report *AnalysisReport, options OutputOptions) {
-	fmt.Printf("Procedure Analysis for: %s\n", report.FilePath)
-	fmt.Println(strings.Repeat("=", 80))
+func printDetailedReport(w *output.Writer, report *AnalysisReport, options OutputOptions) {
+	w.Printf("Procedure Analysis for: %s\n", report.FilePath)
+	w.Println(strings.Repeat("=", 80))
 
 	// Group procedures by heading first to get accurate counts
 	headingGroups, headingOrder := groupProceduresByHeading(report.Procedures)
 	totalUniqueProcedures, totalAppearances := calculateTotals(headingGroups)
 
-	fmt.Printf("\nTotal unique procedures: %d\n", totalUniqueProcedures)
-	fmt.Printf("Total procedure appearances: %d\n\n", totalAppearances)
+	w.Printf("\nTotal unique procedures: %d\n", totalUniqueProcedures)
+	w.Printf("Total procedure appearances: %d\n\n", totalAppearances)
 
 	// Print implementation type summary if requested
 	if options.Implementation {
-		fmt.Println("Procedures by implementation type:")
+		w.Println("Procedures by implementation type:")
 		for implType, count := range report.ProceduresByType {
-			fmt.Printf("  - %s: %d\n", implType, count)
+			w.Printf("  - %s: %d\n", implType, count)
 		}
-		fmt.Println()
+		w.Println()
 	}
 
 	// Print details grouped by heading (headingGroups already created above)
-	fmt.Println("Procedures by Heading:")
-	fmt.Println(strings.Repeat("-", 80))
+	w.Println("Procedures by Heading:")
+	w.Println(strings.Repeat("-", 80))
 
 	headingNum := 1
 	for _, heading := range headingOrder {
 		procedures := headingGroups[heading]
 
-		fmt.Printf("\n%d. %s\n", headingNum, heading)
-		fmt.Printf("   Unique procedures: %d\n", len(procedures))
+		w.Printf("\n%d. %s\n", headingNum, heading)
+		w.Printf("   Unique procedures: %d\n", len(procedures))
 
 		// Calculate total appearances for this heading
 		totalAppearances := 0
 		for _, proc := range procedures {
 			totalAppearances += proc.VariationCount
 		}
-		fmt.Printf("   Total appearances: %d\n", totalAppearances)
+		w.Printf("   Total appearances: %d\n", totalAppearances)
 
 		// If only showing summary, skip the individual procedure details
 		if options.ListSummary && !options.ListAll {
@@ -118,25 +119,25 @@ func printDetailedReport(report *AnalysisReport, options OutputOptions) {
 
 		// Show each unique procedure under this heading
 		for i, analysis := range procedures {
-			fmt.Printf("\n   ")
+			w.Printf("\n   ")
 
 			// Only show sub-numbering if there are multiple unique procedures
 			if useSubNumbering {
-				fmt.Printf("%d.%d. ", headingNum, i+1)
+				w.Printf("%d.%d. ", headingNum, i+1)
 			}
 
 			// Show the first step to distinguish procedures (only if there are multiple)
 			if useSubNumbering {
 				if len(analysis.Procedure.Steps) > 0 && analysis.Procedure.Steps[0].Title != "" {
-					fmt.Printf("%s\n", analysis.Procedure.Steps[0].Title)
+					w.Printf("%s\n", analysis.Procedure.Steps[0].Title)
 				} else if len(analysis.Procedure.Steps) > 0 {
-					fmt.Printf("(Untitled first step)\n")
+					w.Printf("(Untitled first step)\n")
 				} else {
-					fmt.Printf("(No steps)\n")
+					w.Printf("(No steps)\n")
 				}
 			} else {
 				// For single procedures, just show the step count
-				fmt.Printf("Steps: %d\n", len(analysis.Procedure.Steps))
+				w.Printf("Steps: %d\n", len(analysis.Procedure.Steps))
 			}
 
 			// Indent based on whether we're using sub-numbering
@@ -147,44 +148,44 @@ func printDetailedReport(report *AnalysisReport, options OutputOptions) {
 
 			// Only show step count if we already showed the first step title
 			if useSubNumbering {
-				fmt.Printf("%sSteps: %d\n", indent, len(analysis.Procedure.Steps))
+				w.Printf("%sSteps: %d\n", indent, len(analysis.Procedure.Steps))
 			}
 
 			// Print implementation type if requested
 			if options.Implementation {
-				fmt.Printf("%sImplementation: %s\n", indent, analysis.Implementation)
+				w.Printf("%sImplementation: %s\n", indent, analysis.Implementation)
 			}
 
 			// Print sub-procedures flag if requested
 			if options.SubProcedures {
 				if analysis.HasSubSteps {
-					fmt.Printf("%sContains sub-procedures: yes\n", indent)
+					w.Printf("%sContains sub-procedures: yes\n", indent)
 				} else {
-					fmt.Printf("%sContains sub-procedures: no\n", indent)
+					w.Printf("%sContains sub-procedures: no\n", indent)
 				}
 			}
 
 			// Print selections if requested
 			if options.ListAll {
 				if analysis.VariationCount == 1 {
-					fmt.Printf("%sAppears in 1 selection:\n", indent)
+					w.Printf("%sAppears in 1 selection:\n", indent)
 				} else {
-					fmt.Printf("%sAppears in %d selections:\n", indent, analysis.VariationCount)
+					w.Printf("%sAppears in %d selections:\n", indent, analysis.VariationCount)
 				}
 
 				if len(analysis.Variations) > 0 && analysis.Variations[0] != "(no variations)" {
 					for _, variation := range analysis.Variations {
-						fmt.Printf("%s  - %s\n", indent, variation)
+						w.Printf("%s  - %s\n", indent, variation)
 					}
 				} else {
-					fmt.Printf("%s  (single variation, no tabs or selections)\n", indent)
+					w.Printf("%s  (single variation, no tabs or selections)\n", indent)
 				}
 			} else if options.ListSummary {
 				// For summary, just show the count without listing all selections
 				if analysis.VariationCount == 1 {
-					fmt.Printf("%sAppears in 1 selection\n", indent)
+					w.Printf("%sAppears in 1 selection\n", indent)
 				} else {
-					fmt.Printf("%sAppears in %d selections\n", indent, analysis.VariationCount)
+					w.Printf("%sAppears in %d selections\n", indent, analysis.VariationCount)
 				}
 			}
 		}
@@ -192,6 +193,6 @@ func printDetailedReport(report *AnalysisReport, options OutputOptions) {
 		headingNum++
 	}
 
-	fmt.Println()
+	w.Println()
 }
 
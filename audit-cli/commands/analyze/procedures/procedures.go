@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -26,6 +27,7 @@ import (
 //   - --implementation: Show how each procedure is implemented
 //   - --sub-procedures: Indicate if procedures contain nested sub-procedures
 //   - --step-count: Show step count for each procedure
+//   - --output-file: Write results to a file instead of stdout
 func NewProceduresCommand() *cobra.Command {
 	var (
 		listAll        bool
@@ -33,6 +35,7 @@ func NewProceduresCommand() *cobra.Command {
 		implementation bool
 		subProcedures  bool
 		stepCount      bool
+		outputOpts     output.Options
 	)
 
 	cmd := &cobra.Command{
@@ -60,7 +63,7 @@ By default, outputs a summary count. Use flags to get more detailed information.
 				StepCount:      stepCount,
 			}
 
-			return runAnalyze(filePath, options)
+			return runAnalyze(filePath, options, outputOpts)
 		},
 	}
 
@@ -69,12 +72,13 @@ By default, outputs a summary count. Use flags to get more detailed information.
 	cmd.Flags().BoolVar(&implementation, "implementation", false, "Show how each procedure is implemented")
 	cmd.Flags().BoolVar(&subProcedures, "sub-procedures", false, "Indicate if procedures contain nested sub-procedures")
 	cmd.Flags().BoolVar(&stepCount, "step-count", false, "Show step count for each procedure")
+	output.AddFileFlags(cmd, &outputOpts)
 
 	return cmd
 }
 
 // runAnalyze executes the analysis operation.
-func runAnalyze(filePath string, options OutputOptions) error {
+func runAnalyze(filePath string, options OutputOptions, outputOpts output.Options) error {
 	// Verify the file exists
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
@@ -91,13 +95,19 @@ func runAnalyze(filePath string, options OutputOptions) error {
 		return err
 	}
 
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
 	if report.TotalProcedures == 0 {
-		fmt.Println("No procedures found in the file.")
+		w.Println("No procedures found in the file.")
 		return nil
 	}
 
 	// Print the report
-	PrintReport(report, options)
+	PrintReport(w, report, options)
 
 	return nil
 }
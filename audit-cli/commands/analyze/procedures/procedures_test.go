@@ -1,7 +1,11 @@
 package procedures
 
 import (
+	"bytes"
+	"strings"
 	"testing"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 )
 
 func TestAnalyzeFile(t *testing.T) {
@@ -127,26 +131,35 @@ func TestPrintReport(t *testing.T) {
 	}
 
 	// Test with default options (just summary)
+	var buf bytes.Buffer
 	options := OutputOptions{}
-	PrintReport(report, options)
+	PrintReport(output.NewWriter(&buf, output.FormatText), report, options)
+	if !strings.Contains(buf.String(), "Total unique procedures:") {
+		t.Errorf("Expected summary output, got:\n%s", buf.String())
+	}
 
 	// Test with ListSummary
+	buf.Reset()
 	options = OutputOptions{
 		ListSummary: true,
 	}
-	PrintReport(report, options)
+	PrintReport(output.NewWriter(&buf, output.FormatText), report, options)
+	if !strings.Contains(buf.String(), "Procedures by Heading:") {
+		t.Errorf("Expected detailed output, got:\n%s", buf.String())
+	}
 
 	// Test with ListAll and all details
+	buf.Reset()
 	options = OutputOptions{
 		ListAll:        true,
 		Implementation: true,
 		SubProcedures:  true,
 		StepCount:      true,
 	}
-	PrintReport(report, options)
-
-	// This test just ensures PrintReport doesn't panic
-	// In a real test, we might capture stdout and verify the output
+	PrintReport(output.NewWriter(&buf, output.FormatText), report, options)
+	if !strings.Contains(buf.String(), "Procedures by implementation type:") {
+		t.Errorf("Expected implementation details, got:\n%s", buf.String())
+	}
 }
 
 func TestProcedureAnalysisDetails(t *testing.T) {
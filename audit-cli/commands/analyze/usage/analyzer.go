@@ -287,6 +287,161 @@ func analyzeUsageRecursiveHelper(targetFile, sourceDir string, includeToctree, v
 	return nil
 }
 
+// AnalyzeUsageTree builds the full usage tree for the target file.
+//
+// Like AnalyzeUsageRecursive, this function follows the usage tree upward until it reaches
+// .txt files. Instead of flattening the results, it keeps every hop: each node records the
+// directive type and line numbers it uses to reference its parent, so tooling can render the
+// chain from an include file to the published pages. A file that is used from several places
+// appears under each of them. Cycles are marked and not expanded again.
+//
+// Parameters:
+//   - targetFile: Absolute path to the file to analyze
+//   - includeToctree: If true, include toctree entries in the search
+//   - verbose: If true, show progress information
+//   - excludePattern: Glob pattern for paths to exclude (empty string means no exclusion)
+//
+// Returns:
+//   - *UsageAnalysis: The analysis results, with UsageTree set and UsingFiles containing the .txt pages
+//   - error: Any error encountered during analysis
+func AnalyzeUsageTree(targetFile string, includeToctree bool, verbose bool, excludePattern string) (*UsageAnalysis, error) {
+	// Get absolute path
+	absTargetFile, err := filepath.Abs(targetFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	// Find the source directory
+	sourceDir, err := projectinfo.FindSourceDirectory(absTargetFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find source directory: %w\n\nThe source directory is detected by looking for a 'source' directory in the file's path.\nMake sure the target file is within a documentation repository with a 'source' directory.", err)
+	}
+
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Building usage tree for: %s\n\n", absTargetFile)
+	}
+
+	root := &UsageNode{
+		FilePath: absTargetFile,
+		IsPage:   filepath.Ext(absTargetFile) == ".txt",
+	}
+	builder := &usageTreeBuilder{
+		sourceDir:      sourceDir,
+		includeToctree: includeToctree,
+		verbose:        verbose,
+		excludePattern: excludePattern,
+		usagesByFile:   make(map[string][]FileUsage),
+		pages:          make(map[string]bool),
+	}
+	ancestors := map[string]bool{absTargetFile: true}
+	if err := builder.expand(root, ancestors, 0); err != nil {
+		return nil, err
+	}
+
+	// The flat list contains the pages at the leaves of the tree, as with AnalyzeUsageRecursive
+	var pageUsages []FileUsage
+	for page := range builder.pages {
+		pageUsages = append(pageUsages, FileUsage{
+			FilePath:      page,
+			DirectiveType: "include",
+			UsagePath:     page,
+		})
+	}
+	sort.Slice(pageUsages, func(i, j int) bool {
+		return pageUsages[i].FilePath < pageUsages[j].FilePath
+	})
+
+	return &UsageAnalysis{
+		TargetFile:  absTargetFile,
+		SourceDir:   sourceDir,
+		UsingFiles:  pageUsages,
+		UsageTree:   root,
+		TotalUsages: len(pageUsages),
+		TotalFiles:  len(pageUsages),
+	}, nil
+}
+
+// usageTreeBuilder holds the state for building a usage tree.
+//
+// Usages are cached per file, since a shared include is usually reached through several branches
+// and each lookup scans the whole source directory.
+type usageTreeBuilder struct {
+	sourceDir      string
+	includeToctree bool
+	verbose        bool
+	excludePattern string
+	usagesByFile   map[string][]FileUsage
+	pages          map[string]bool
+}
+
+// expand adds a child for every file that uses the node's file, and recursively expands
+// children that are not .txt pages.
+//
+// Parameters:
+//   - node: The node to expand
+//   - ancestors: Files on the path from the root to this node (to detect cycles)
+//   - depth: Current depth (for indentation in verbose mode)
+//
+// Returns:
+//   - error: Any error encountered during analysis
+func (b *usageTreeBuilder) expand(node *UsageNode, ancestors map[string]bool, depth int) error {
+	usages, ok := b.usagesByFile[node.FilePath]
+	if !ok {
+		analysis, err := AnalyzeUsage(node.FilePath, b.includeToctree, false, b.excludePattern)
+		if err != nil {
+			return err
+		}
+		usages = analysis.UsingFiles
+		b.usagesByFile[node.FilePath] = usages
+	}
+
+	groups := GroupUsagesByFile(usages)
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].FilePath != groups[j].FilePath {
+			return groups[i].FilePath < groups[j].FilePath
+		}
+		return groups[i].DirectiveType < groups[j].DirectiveType
+	})
+
+	for _, group := range groups {
+		child := &UsageNode{
+			FilePath:      group.FilePath,
+			DirectiveType: group.DirectiveType,
+			UsagePath:     group.Usages[0].UsagePath,
+			IsPage:        filepath.Ext(group.FilePath) == ".txt",
+		}
+		for _, usage := range group.Usages {
+			child.LineNumbers = append(child.LineNumbers, usage.LineNumber)
+		}
+		sort.Ints(child.LineNumbers)
+		node.Children = append(node.Children, child)
+
+		if b.verbose {
+			relPath, _ := filepath.Rel(b.sourceDir, child.FilePath)
+			indent := strings.Repeat("  ", depth)
+			fmt.Fprintf(os.Stderr, "%s-> [%s] %s %v\n", indent, child.DirectiveType, relPath, child.LineNumbers)
+		}
+
+		if child.IsPage {
+			b.pages[child.FilePath] = true
+			continue
+		}
+		if ancestors[child.FilePath] {
+			child.Cycle = true
+			continue
+		}
+
+		ancestors[child.FilePath] = true
+		err := b.expand(child, ancestors, depth+1)
+		delete(ancestors, child.FilePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // findUsagesInFile searches a single file for usages of the target file.
 //
 // This function scans through the file line by line looking for include,
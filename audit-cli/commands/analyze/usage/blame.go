@@ -0,0 +1,160 @@
+package usage
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/gitutil"
+)
+
+// uncommittedSHA is the commit git blame reports for lines that haven't been committed yet.
+const uncommittedSHA = "0000000000000000000000000000000000000000"
+
+// AddBlame adds the last commit to change the target file, each using file, and each
+// directive line to the analysis, so writers can find who to ask about a change.
+//
+// Files and lines that haven't been committed get no blame information. Each file is
+// looked up once, no matter how many usages it has.
+//
+// Parameters:
+//   - analysis: The analysis results to add blame information to
+//
+// Returns:
+//   - error: An error if the target file isn't in a git repository, or git fails
+func AddBlame(analysis *UsageAnalysis) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("--blame requires git to be installed")
+	}
+	if _, err := gitutil.Run(filepath.Dir(analysis.TargetFile), "rev-parse", "--show-toplevel"); err != nil {
+		return fmt.Errorf("--blame requires the target file to be in a git repository: %w", err)
+	}
+
+	b := &blamer{files: make(map[string]*BlameInfo)}
+
+	var err error
+	if analysis.TargetLastChanged, err = b.lastChanged(analysis.TargetFile); err != nil {
+		return err
+	}
+	for i := range analysis.UsingFiles {
+		usage := &analysis.UsingFiles[i]
+		if usage.LastChanged, err = b.lastChanged(usage.FilePath); err != nil {
+			return err
+		}
+		if usage.LineNumber > 0 && usage.LastChanged != nil {
+			if usage.LineLastChanged, err = lineLastChanged(usage.FilePath, usage.LineNumber); err != nil {
+				return err
+			}
+		}
+	}
+	if analysis.UsageTree != nil {
+		if err := b.addToTree(analysis.UsageTree); err != nil {
+			return err
+		}
+	}
+
+	analysis.Blamed = true
+	return nil
+}
+
+// blamer caches the last commit to change each file.
+type blamer struct {
+	files map[string]*BlameInfo
+}
+
+// lastChanged returns the last commit to change filePath, or nil if it was never committed.
+func (b *blamer) lastChanged(filePath string) (*BlameInfo, error) {
+	if info, ok := b.files[filePath]; ok {
+		return info, nil
+	}
+
+	out, err := gitutil.Run(filepath.Dir(filePath), "log", "-1", "--format=%H%x09%an%x09%ae%x09%at", "--", filepath.Base(filePath))
+	if err != nil {
+		return nil, err
+	}
+	var info *BlameInfo
+	if out != "" {
+		fields := strings.Split(out, "\t")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected git log output for %s: %q", filePath, out)
+		}
+		info = &BlameInfo{Commit: fields[0], Author: fields[1], Email: fields[2], Date: formatUnixDate(fields[3])}
+	}
+
+	b.files[filePath] = info
+	return info, nil
+}
+
+// addToTree adds the last commit to change each file in the usage tree.
+func (b *blamer) addToTree(node *UsageNode) error {
+	var err error
+	if node.LastChanged, err = b.lastChanged(node.FilePath); err != nil {
+		return err
+	}
+	for _, child := range node.Children {
+		if err := b.addToTree(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lineLastChanged returns the last commit to change a line of filePath, or nil if the
+// line hasn't been committed.
+func lineLastChanged(filePath string, line int) (*BlameInfo, error) {
+	lineRange := fmt.Sprintf("%d,%d", line, line)
+	out, err := gitutil.Run(filepath.Dir(filePath), "blame", "--porcelain", "-L", lineRange, "--", filepath.Base(filePath))
+	if err != nil {
+		return nil, err
+	}
+	return parseBlamePorcelain(out), nil
+}
+
+// parseBlamePorcelain parses the git blame --porcelain output for a single line.
+func parseBlamePorcelain(out string) *BlameInfo {
+	lines := strings.Split(out, "\n")
+	header := strings.Fields(lines[0])
+	if len(header) == 0 || header[0] == uncommittedSHA {
+		return nil
+	}
+
+	info := &BlameInfo{Commit: header[0]}
+	for _, line := range lines[1:] {
+		// The line's content comes last, after a tab
+		if strings.HasPrefix(line, "\t") {
+			break
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			info.Author = value
+		case "author-mail":
+			info.Email = strings.Trim(value, "<>")
+		case "author-time":
+			info.Date = formatUnixDate(value)
+		}
+	}
+	return info
+}
+
+// formatUnixDate formats a Unix timestamp from git as YYYY-MM-DD in UTC.
+func formatUnixDate(value string) string {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return value
+	}
+	return time.Unix(seconds, 0).UTC().Format("2006-01-02")
+}
+
+// String formats the blame information for text output, such as
+// "Jane Doe <jane@example.com>, 2025-01-15 (a1b2c3d)".
+func (info *BlameInfo) String() string {
+	commit := info.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	return fmt.Sprintf("%s <%s>, %s (%s)", info.Author, info.Email, info.Date, commit)
+}
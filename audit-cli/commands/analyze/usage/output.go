@@ -1,83 +1,114 @@
 package usage
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
-)
-
-// OutputFormat represents the output format for the analysis results.
-type OutputFormat string
 
-const (
-	// FormatText is the default human-readable text format
-	FormatText OutputFormat = "text"
-	// FormatJSON is the JSON format
-	FormatJSON OutputFormat = "json"
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 )
 
-// PrintAnalysis prints the analysis results in the specified format.
+// PrintAnalysis prints the analysis results in the writer's format.
+//
+// CSV and markdown output contain one row per using file, with its directive type
+// and number of usages.
 //
 // Parameters:
+//   - w: The output writer, which determines the format and destination
 //   - analysis: The analysis results to print
-//   - format: The output format (text or json)
 //   - verbose: If true, show additional details
 //   - recursive: If true, indicates recursive mode was used
-func PrintAnalysis(analysis *UsageAnalysis, format OutputFormat, verbose bool, recursive bool) error {
-	switch format {
-	case FormatJSON:
-		return printJSON(analysis)
-	case FormatText:
-		printText(analysis, verbose, recursive)
-		return nil
+func PrintAnalysis(w *output.Writer, analysis *UsageAnalysis, verbose bool, recursive bool) error {
+	switch w.Format() {
+	case output.FormatJSON:
+		return printJSON(w, analysis)
+	case output.FormatCSV, output.FormatMarkdown:
+		return w.WriteTable(usageTable(analysis))
 	default:
-		return fmt.Errorf("unknown output format: %s", format)
+		printText(w, analysis, verbose, recursive)
+		return nil
+	}
+}
+
+// usageTable builds a table with one row per file that uses the target.
+func usageTable(analysis *UsageAnalysis) *output.Table {
+	table := output.NewTable("Usages of "+analysis.TargetFile,
+		output.Column{Header: "File"},
+		output.Column{Header: "Directive"},
+		output.Column{Header: "Usages", Align: output.AlignRight},
+		output.Column{Header: "Lines"},
+	)
+	if analysis.Blamed {
+		table.Columns = append(table.Columns,
+			output.Column{Header: "Last Changed By"},
+			output.Column{Header: "Last Changed"},
+		)
+	}
+	for _, group := range GroupUsagesByFile(analysis.UsingFiles) {
+		relPath, err := filepath.Rel(analysis.SourceDir, group.FilePath)
+		if err != nil {
+			relPath = group.FilePath
+		}
+		var lines []string
+		for _, usage := range group.Usages {
+			lines = append(lines, output.FormatValue(usage.LineNumber))
+		}
+		row := []interface{}{relPath, group.DirectiveType, group.Count, strings.Join(lines, " ")}
+		if analysis.Blamed {
+			if lastChanged := group.Usages[0].LastChanged; lastChanged != nil {
+				row = append(row, lastChanged.Author+" <"+lastChanged.Email+">", lastChanged.Date)
+			} else {
+				row = append(row, "", "")
+			}
+		}
+		table.AddRow(row...)
 	}
+	return table
 }
 
 // printText prints the analysis results in human-readable text format.
-func printText(analysis *UsageAnalysis, verbose bool, recursive bool) {
-	fmt.Println("============================================================")
+func printText(w *output.Writer, analysis *UsageAnalysis, verbose bool, recursive bool) {
+	w.Println("============================================================")
 	if recursive {
-		fmt.Println("RECURSIVE USAGE ANALYSIS")
+		w.Println("RECURSIVE USAGE ANALYSIS")
 	} else {
-		fmt.Println("USAGE ANALYSIS")
+		w.Println("USAGE ANALYSIS")
+	}
+	w.Println("============================================================")
+	w.Printf("Target File: %s\n", analysis.TargetFile)
+	if analysis.Blamed {
+		w.Printf("Last Changed: %s\n", formatLastChanged(analysis.TargetLastChanged))
 	}
-	fmt.Println("============================================================")
-	fmt.Printf("Target File: %s\n", analysis.TargetFile)
 	if recursive {
-		fmt.Printf("Total .txt Files: %d\n", analysis.TotalFiles)
-		fmt.Println("(Showing only .txt documentation pages)")
+		w.Printf("Total .txt Files: %d\n", analysis.TotalFiles)
+		w.Println("(Showing only .txt documentation pages)")
 	} else {
-		fmt.Printf("Total Files: %d\n", analysis.TotalFiles)
-		fmt.Printf("Total Usages: %d\n", analysis.TotalUsages)
+		w.Printf("Total Files: %d\n", analysis.TotalFiles)
+		w.Printf("Total Usages: %d\n", analysis.TotalUsages)
 	}
-	fmt.Println("============================================================")
-	fmt.Println()
+	w.Println("============================================================")
+	w.Println()
 
 	if analysis.TotalUsages == 0 {
 		if recursive {
-			fmt.Println("No .txt files ultimately use this file.")
-			fmt.Println()
-			fmt.Println("This could mean:")
-			fmt.Println("  - The file is only used by other include files, not by any .txt pages")
-			fmt.Println("  - The file might be orphaned (not used)")
-			fmt.Println("  - The file is used with a different path")
+			w.Println("No .txt files ultimately use this file.")
+			w.Println()
+			w.Println("This could mean:")
+			w.Println("  - The file is only used by other include files, not by any .txt pages")
+			w.Println("  - The file might be orphaned (not used)")
+			w.Println("  - The file is used with a different path")
 		} else {
-			fmt.Println("No files use this file.")
-			fmt.Println()
-			fmt.Println("This could mean:")
-			fmt.Println("  - The file is not included in any documentation pages")
-			fmt.Println("  - The file might be orphaned (not used)")
-			fmt.Println("  - The file is used with a different path")
+			w.Println("No files use this file.")
+			w.Println()
+			w.Println("This could mean:")
+			w.Println("  - The file is not included in any documentation pages")
+			w.Println("  - The file might be orphaned (not used)")
+			w.Println("  - The file is used with a different path")
 		}
-		fmt.Println()
-		fmt.Println("Note: By default, only content inclusion directives are searched.")
-		fmt.Println("Use --include-toctree to also search for toctree navigation links.")
-		fmt.Println()
+		w.Println()
+		w.Println("Note: By default, only content inclusion directives are searched.")
+		w.Println("Use --include-toctree to also search for toctree navigation links.")
+		w.Println()
 		return
 	}
 
@@ -94,18 +125,18 @@ func printText(analysis *UsageAnalysis, verbose bool, recursive bool) {
 				totalRefs := len(refs)
 				if uniqueFiles == totalRefs {
 					// No duplicates - just show count
-					fmt.Printf("%-20s: %d\n", directiveType, uniqueFiles)
+					w.Printf("%-20s: %d\n", directiveType, uniqueFiles)
 				} else {
 					// Has duplicates - show both counts
 					if uniqueFiles == 1 {
-						fmt.Printf("%-20s: %d file, %d usages\n", directiveType, uniqueFiles, totalRefs)
+						w.Printf("%-20s: %d file, %d usages\n", directiveType, uniqueFiles, totalRefs)
 					} else {
-						fmt.Printf("%-20s: %d files, %d usages\n", directiveType, uniqueFiles, totalRefs)
+						w.Printf("%-20s: %d files, %d usages\n", directiveType, uniqueFiles, totalRefs)
 					}
 				}
 			}
 		}
-		fmt.Println()
+		w.Println()
 	}
 
 	// Group usages by file
@@ -121,49 +152,93 @@ func printText(analysis *UsageAnalysis, verbose bool, recursive bool) {
 
 		if recursive {
 			// In recursive mode, just show the .txt file paths
-			fmt.Printf("%3d. %s\n", i+1, relPath)
+			w.Printf("%3d. %s\n", i+1, relPath)
+			if analysis.Blamed {
+				w.Printf("     Last changed: %s\n", formatLastChanged(group.Usages[0].LastChanged))
+			}
 		} else {
 			// Print file path with directive type label
 			if group.Count > 1 {
 				// Multiple usages from this file
-				fmt.Printf("%3d. [%s] %s (%d usages)\n", i+1, group.DirectiveType, relPath, group.Count)
+				w.Printf("%3d. [%s] %s (%d usages)\n", i+1, group.DirectiveType, relPath, group.Count)
 			} else {
 				// Single usage
-				fmt.Printf("%3d. [%s] %s\n", i+1, group.DirectiveType, relPath)
+				w.Printf("%3d. [%s] %s\n", i+1, group.DirectiveType, relPath)
+			}
+
+			if analysis.Blamed {
+				w.Printf("     Last changed: %s\n", formatLastChanged(group.Usages[0].LastChanged))
 			}
 
 			// Print line numbers in verbose mode
 			if verbose {
 				for _, usage := range group.Usages {
-					fmt.Printf("     Line %d: %s\n", usage.LineNumber, usage.UsagePath)
+					if analysis.Blamed {
+						w.Printf("     Line %d: %s [%s]\n", usage.LineNumber, usage.UsagePath, formatLastChanged(usage.LineLastChanged))
+					} else {
+						w.Printf("     Line %d: %s\n", usage.LineNumber, usage.UsagePath)
+					}
 				}
 			}
 		}
 	}
 
-	fmt.Println()
+	w.Println()
+}
+
+// formatLastChanged formats blame information for text output.
+func formatLastChanged(info *BlameInfo) string {
+	if info == nil {
+		return "not committed"
+	}
+	return info.String()
 }
 
 // printJSON prints the analysis results in JSON format.
-func printJSON(analysis *UsageAnalysis) error {
+func printJSON(w *output.Writer, analysis *UsageAnalysis) error {
 	// Create a JSON-friendly structure
-	output := struct {
-		TargetFile  string      `json:"target_file"`
-		SourceDir   string      `json:"source_dir"`
-		TotalFiles  int         `json:"total_files"`
-		TotalUsages int         `json:"total_usages"`
-		UsingFiles  []FileUsage `json:"using_files"`
+	result := struct {
+		TargetFile        string      `json:"target_file"`
+		TargetLastChanged *BlameInfo  `json:"target_last_changed,omitempty"`
+		SourceDir         string      `json:"source_dir"`
+		TotalFiles        int         `json:"total_files"`
+		TotalUsages       int         `json:"total_usages"`
+		UsingFiles        []FileUsage `json:"using_files"`
 	}{
-		TargetFile:  analysis.TargetFile,
-		SourceDir:   analysis.SourceDir,
-		TotalFiles:  analysis.TotalFiles,
-		TotalUsages: analysis.TotalUsages,
-		UsingFiles:  analysis.UsingFiles,
+		TargetFile:        analysis.TargetFile,
+		TargetLastChanged: analysis.TargetLastChanged,
+		SourceDir:         analysis.SourceDir,
+		TotalFiles:        analysis.TotalFiles,
+		TotalUsages:       analysis.TotalUsages,
+		UsingFiles:        analysis.UsingFiles,
+	}
+
+	return w.WriteJSON(result)
+}
+
+// PrintUsageTree prints the full usage tree as nested JSON.
+//
+// Each node includes the directive type and line numbers used to reference its parent,
+// so tooling can render the chain from the target file to the published pages.
+func PrintUsageTree(w *output.Writer, analysis *UsageAnalysis) error {
+	result := struct {
+		TargetFile string     `json:"target_file"`
+		SourceDir  string     `json:"source_dir"`
+		TotalPages int        `json:"total_pages"`
+		Pages      []string   `json:"pages"`
+		Tree       *UsageNode `json:"tree"`
+	}{
+		TargetFile: analysis.TargetFile,
+		SourceDir:  analysis.SourceDir,
+		TotalPages: analysis.TotalFiles,
+		Pages:      []string{},
+		Tree:       analysis.UsageTree,
+	}
+	for _, usage := range analysis.UsingFiles {
+		result.Pages = append(result.Pages, usage.FilePath)
 	}
 
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(output)
+	return w.WriteJSON(result)
 }
 
 // groupByDirectiveType groups usages by their directive type.
@@ -216,11 +291,12 @@ func GetDirectiveTypeLabel(directiveType string) string {
 // This is useful for piping to other commands or for simple scripting.
 //
 // Parameters:
+//   - w: The output writer
 //   - analysis: The analysis results
 //
 // Returns:
 //   - error: Any error encountered during printing
-func PrintPathsOnly(analysis *UsageAnalysis) error {
+func PrintPathsOnly(w *output.Writer, analysis *UsageAnalysis) error {
 	// Get unique file paths (in case there are duplicates)
 	seen := make(map[string]bool)
 	var paths []string
@@ -243,7 +319,7 @@ func PrintPathsOnly(analysis *UsageAnalysis) error {
 
 	// Print each path
 	for _, path := range paths {
-		fmt.Println(path)
+		w.Println(path)
 	}
 
 	return nil
@@ -254,26 +330,27 @@ func PrintPathsOnly(analysis *UsageAnalysis) error {
 // This is useful for getting a quick overview of usage counts.
 //
 // Parameters:
+//   - w: The output writer
 //   - analysis: The analysis results
 //
 // Returns:
 //   - error: Any error encountered during printing
-func PrintSummary(analysis *UsageAnalysis) error {
-	fmt.Printf("Total Files: %d\n", analysis.TotalFiles)
-	fmt.Printf("Total Usages: %d\n", analysis.TotalUsages)
+func PrintSummary(w *output.Writer, analysis *UsageAnalysis) error {
+	w.Printf("Total Files: %d\n", analysis.TotalFiles)
+	w.Printf("Total Usages: %d\n", analysis.TotalUsages)
 
 	if analysis.TotalUsages > 0 {
 		// Group by directive type
 		byDirectiveType := groupByDirectiveType(analysis.UsingFiles)
 
 		// Print breakdown by type
-		fmt.Println("\nBy Type:")
+		w.Println("\nBy Type:")
 		directiveTypes := []string{"include", "literalinclude", "io-code-block", "toctree"}
 		for _, directiveType := range directiveTypes {
 			if usages, ok := byDirectiveType[directiveType]; ok {
 				uniqueFiles := countUniqueFiles(usages)
 				totalUsages := len(usages)
-				fmt.Printf("  %-20s: %d files, %d usages\n", directiveType, uniqueFiles, totalUsages)
+				w.Printf("  %-20s: %d files, %d usages\n", directiveType, uniqueFiles, totalUsages)
 			}
 		}
 	}
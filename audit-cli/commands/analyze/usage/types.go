@@ -11,8 +11,8 @@ type UsageAnalysis struct {
 	// UsingFiles is a flat list of all files that use the target
 	UsingFiles []FileUsage
 
-	// UsageTree is a hierarchical tree structure of usages
-	// (for future use - showing nested usages)
+	// UsageTree is a hierarchical tree structure of usages, rooted at the target file.
+	// Only populated by AnalyzeUsageTree.
 	UsageTree *UsageNode
 
 	// TotalUsages is the total number of directive occurrences
@@ -23,6 +23,13 @@ type UsageAnalysis struct {
 
 	// SourceDir is the source directory that was searched
 	SourceDir string
+
+	// TargetLastChanged is the last commit to change the target file.
+	// Only populated by AddBlame, and nil if the file isn't committed.
+	TargetLastChanged *BlameInfo
+
+	// Blamed is true if AddBlame added last-change information to the analysis
+	Blamed bool
 }
 
 // FileUsage represents a single file that uses the target file.
@@ -41,25 +48,46 @@ type FileUsage struct {
 
 	// LineNumber is the line number where the usage occurs
 	LineNumber int `json:"line_number"`
+
+	// LastChanged is the last commit to change the using file (only populated by AddBlame)
+	LastChanged *BlameInfo `json:"last_changed,omitempty"`
+
+	// LineLastChanged is the last commit to change the line with the directive
+	// (only populated by AddBlame, and only for usages with a line number)
+	LineLastChanged *BlameInfo `json:"line_last_changed,omitempty"`
 }
 
 // UsageNode represents a node in the usage tree.
 //
 // This structure is used to build a hierarchical view of usages,
-// showing which files use the target and which files use those files.
+// showing which files use the target and which files use those files,
+// up to the .txt files that represent published pages.
 type UsageNode struct {
 	// FilePath is the absolute path to this file
-	FilePath string
+	FilePath string `json:"file_path"`
 
-	// DirectiveType is the type of directive used to reference the file
-	DirectiveType string
+	// DirectiveType is the type of directive this file uses to reference its parent
+	// (empty for the root node)
+	DirectiveType string `json:"directive_type,omitempty"`
+
+	// UsagePath is the path used in the directive (as written in the file)
+	UsagePath string `json:"usage_path,omitempty"`
+
+	// LineNumbers are the line numbers in this file where the parent is referenced
+	LineNumbers []int `json:"line_numbers,omitempty"`
+
+	// IsPage is true if this file is a .txt documentation page
+	IsPage bool `json:"is_page"`
 
-	// UsagePath is the path used in the directive
-	UsagePath string
+	// LastChanged is the last commit to change this file (only populated by AddBlame)
+	LastChanged *BlameInfo `json:"last_changed,omitempty"`
 
-	// Children are files that include this file
-	// (for building nested usage trees)
-	Children []*UsageNode
+	// Cycle is true if this file already appears higher in the same branch.
+	// Its children are not expanded again.
+	Cycle bool `json:"cycle,omitempty"`
+
+	// Children are files that use this file
+	Children []*UsageNode `json:"children,omitempty"`
 }
 
 // GroupedFileUsage represents a file with all its usages of the target.
@@ -80,3 +108,19 @@ type GroupedFileUsage struct {
 	Count int
 }
 
+// BlameInfo identifies the last commit to change a file or a line.
+//
+// Writers use it to find the right reviewer when changing shared content.
+type BlameInfo struct {
+	// Author is the name of the commit's author
+	Author string `json:"author"`
+
+	// Email is the email address of the commit's author
+	Email string `json:"email"`
+
+	// Commit is the full commit SHA
+	Commit string `json:"commit"`
+
+	// Date is the commit's author date (YYYY-MM-DD)
+	Date string `json:"date"`
+}
@@ -16,6 +16,7 @@ package usage
 import (
 	"fmt"
 
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -29,7 +30,8 @@ import (
 //   analyze usage /path/to/code-example.js
 //
 // Flags:
-//   - --format: Output format (text or json)
+//   - --format: Output format (text, json, csv, or markdown)
+//   - --output-file: Write results to a file instead of stdout
 //   - -v, --verbose: Show detailed information including line numbers
 //   - -c, --count-only: Only show the count of references
 //   - --paths-only: Only show the file paths
@@ -38,9 +40,11 @@ import (
 //   - --include-toctree: Include toctree entries (navigation links) in addition to content inclusion directives
 //   - --exclude: Exclude paths matching this glob pattern (e.g., '*/archive/*')
 //   - -r, --recursive: Recursively follow usage tree until reaching only .txt files (documentation pages)
+//   - --json-tree: Output the full usage tree as nested JSON, with directive types and line numbers at each hop
+//   - --blame: Show the last author and commit to change the target file and each file that uses it
 func NewUsageCommand() *cobra.Command {
 	var (
-		format         string
+		outputOpts     output.Options
 		verbose        bool
 		countOnly      bool
 		pathsOnly      bool
@@ -49,6 +53,8 @@ func NewUsageCommand() *cobra.Command {
 		includeToctree bool
 		excludePattern string
 		recursive      bool
+		jsonTree       bool
+		blame          bool
 	)
 
 	cmd := &cobra.Command{
@@ -108,14 +114,23 @@ Examples:
   analyze usage /path/to/file.rst --directive-type include
 
   # Recursively follow usage tree to find all .txt documentation pages
-  analyze usage /path/to/includes/fact.rst --recursive`,
+  analyze usage /path/to/includes/fact.rst --recursive
+
+  # Output the full usage tree from the file to each page as nested JSON
+  analyze usage /path/to/includes/fact.rst --json-tree
+
+  # Show who last changed the include and each page that uses it, to find a reviewer
+  analyze usage /path/to/includes/fact.rst --blame --verbose
+
+  # Write the files that use an include to a markdown table for a ticket
+  analyze usage /path/to/includes/fact.rst --format markdown --output-file usages.md`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runUsage(args[0], format, verbose, countOnly, pathsOnly, summaryOnly, directiveType, includeToctree, excludePattern, recursive)
+			return runUsage(args[0], outputOpts, verbose, countOnly, pathsOnly, summaryOnly, directiveType, includeToctree, excludePattern, recursive, jsonTree, blame)
 		},
 	}
 
-	cmd.Flags().StringVar(&format, "format", "text", "Output format (text or json)")
+	output.AddFlags(cmd, &outputOpts)
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed information including line numbers")
 	cmd.Flags().BoolVarP(&countOnly, "count-only", "c", false, "Only show the count of usages")
 	cmd.Flags().BoolVar(&pathsOnly, "paths-only", false, "Only show the file paths (one per line)")
@@ -124,6 +139,8 @@ Examples:
 	cmd.Flags().BoolVar(&includeToctree, "include-toctree", false, "Include toctree entries (navigation links) in addition to content inclusion directives")
 	cmd.Flags().StringVar(&excludePattern, "exclude", "", "Exclude paths matching this glob pattern (e.g., '*/archive/*' or '*/deprecated/*')")
 	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Recursively follow usage tree until reaching only .txt files (documentation pages)")
+	cmd.Flags().BoolVar(&jsonTree, "json-tree", false, "Output the full usage tree as nested JSON, with directive types and line numbers at each hop")
+	cmd.Flags().BoolVar(&blame, "blame", false, "Show the last author and commit to change the target file and each file that uses it (requires git)")
 
 	return cmd
 }
@@ -134,7 +151,7 @@ Examples:
 //
 // Parameters:
 //   - targetFile: Path to the file to analyze
-//   - format: Output format (text or json)
+//   - outputOpts: Output format and destination
 //   - verbose: If true, show detailed information
 //   - countOnly: If true, only show the count
 //   - pathsOnly: If true, only show the file paths
@@ -143,10 +160,12 @@ Examples:
 //   - includeToctree: If true, include toctree entries in the search
 //   - excludePattern: Glob pattern for paths to exclude (empty string means no exclusion)
 //   - recursive: If true, recursively follow usage tree until reaching only .txt files
+//   - jsonTree: If true, output the full usage tree as nested JSON
+//   - blame: If true, add the last commit to change the target and each using file
 //
 // Returns:
 //   - error: Any error encountered during analysis
-func runUsage(targetFile, format string, verbose, countOnly, pathsOnly, summaryOnly bool, directiveType string, includeToctree bool, excludePattern string, recursive bool) error {
+func runUsage(targetFile string, outputOpts output.Options, verbose, countOnly, pathsOnly, summaryOnly bool, directiveType string, includeToctree bool, excludePattern string, recursive bool, jsonTree bool, blame bool) error {
 	// Validate directive type if specified
 	if directiveType != "" {
 		validTypes := map[string]bool{
@@ -161,10 +180,10 @@ func runUsage(targetFile, format string, verbose, countOnly, pathsOnly, summaryO
 	}
 
 	// Validate format
-	outputFormat := OutputFormat(format)
-	if outputFormat != FormatText && outputFormat != FormatJSON {
-		return fmt.Errorf("invalid format: %s (must be 'text' or 'json')", format)
+	if err := outputOpts.Validate(); err != nil {
+		return err
 	}
+	textFormat := outputOpts.Format == "" || outputOpts.Format == string(output.FormatText)
 
 	// Validate flag combinations
 	exclusiveFlags := 0
@@ -180,8 +199,36 @@ func runUsage(targetFile, format string, verbose, countOnly, pathsOnly, summaryO
 	if exclusiveFlags > 1 {
 		return fmt.Errorf("cannot use --count-only, --paths-only, and --summary together")
 	}
-	if (countOnly || pathsOnly || summaryOnly) && outputFormat == FormatJSON {
-		return fmt.Errorf("--count-only, --paths-only, and --summary are not compatible with --format json")
+	if (countOnly || pathsOnly || summaryOnly) && !textFormat {
+		return fmt.Errorf("--count-only, --paths-only, and --summary are only compatible with --format text")
+	}
+	if jsonTree && (countOnly || pathsOnly || summaryOnly) {
+		return fmt.Errorf("--json-tree is not compatible with --count-only, --paths-only, or --summary")
+	}
+	if jsonTree && directiveType != "" {
+		return fmt.Errorf("--json-tree is not compatible with --directive-type")
+	}
+	if blame && (countOnly || pathsOnly || summaryOnly) {
+		return fmt.Errorf("--blame is not compatible with --count-only, --paths-only, or --summary")
+	}
+
+	// The usage tree is always recursive and always JSON
+	if jsonTree {
+		analysis, err := AnalyzeUsageTree(targetFile, includeToctree, verbose, excludePattern)
+		if err != nil {
+			return fmt.Errorf("failed to analyze usage: %w", err)
+		}
+		if blame {
+			if err := AddBlame(analysis); err != nil {
+				return err
+			}
+		}
+		w, err := output.Open(outputOpts)
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+		return PrintUsageTree(w, analysis)
 	}
 
 	// Perform analysis
@@ -205,23 +252,35 @@ func runUsage(targetFile, format string, verbose, countOnly, pathsOnly, summaryO
 		analysis = FilterByDirectiveType(analysis, directiveType)
 	}
 
+	if blame {
+		if err := AddBlame(analysis); err != nil {
+			return err
+		}
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
 	// Handle count-only output
 	if countOnly {
-		fmt.Println(analysis.TotalUsages)
+		w.Println(analysis.TotalUsages)
 		return nil
 	}
 
 	// Handle paths-only output
 	if pathsOnly {
-		return PrintPathsOnly(analysis)
+		return PrintPathsOnly(w, analysis)
 	}
 
 	// Handle summary-only output
 	if summaryOnly {
-		return PrintSummary(analysis)
+		return PrintSummary(w, analysis)
 	}
 
 	// Print full results
-	return PrintAnalysis(analysis, outputFormat, verbose, recursive)
+	return PrintAnalysis(w, analysis, verbose, recursive)
 }
 
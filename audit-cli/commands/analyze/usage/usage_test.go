@@ -1,7 +1,10 @@
 package usage
 
 import (
+	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -429,3 +432,155 @@ func TestGroupByDirectiveType(t *testing.T) {
 	}
 }
 
+
+// TestAnalyzeUsageTree tests that AnalyzeUsageTree keeps every hop from the target file to the pages.
+func TestAnalyzeUsageTree(t *testing.T) {
+	sourceDir, err := filepath.Abs("../../../testdata/usage-tree/source")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	targetFile := filepath.Join(sourceDir, "includes", "shared.rst")
+
+	analysis, err := AnalyzeUsageTree(targetFile, false, false, "")
+	if err != nil {
+		t.Fatalf("AnalyzeUsageTree failed: %v", err)
+	}
+
+	root := analysis.UsageTree
+	if root == nil {
+		t.Fatal("expected usage tree to be set")
+	}
+	if root.FilePath != targetFile {
+		t.Errorf("expected root %s, got %s", targetFile, root.FilePath)
+	}
+
+	// shared.rst is used by loop-a.rst, wrapper.rst, and page-a.txt (sorted by path)
+	expectedChildren := []string{"includes/loop-a.rst", "includes/wrapper.rst", "page-a.txt"}
+	if len(root.Children) != len(expectedChildren) {
+		t.Fatalf("expected %d children, got %d", len(expectedChildren), len(root.Children))
+	}
+	for i, expected := range expectedChildren {
+		if root.Children[i].FilePath != filepath.Join(sourceDir, expected) {
+			t.Errorf("child %d: expected %s, got %s", i, expected, root.Children[i].FilePath)
+		}
+	}
+
+	// page-a.txt includes shared.rst directly on line 10 and is a leaf
+	pageA := root.Children[2]
+	if !pageA.IsPage || len(pageA.Children) != 0 {
+		t.Errorf("expected page-a.txt to be a page leaf")
+	}
+	if pageA.DirectiveType != "include" || len(pageA.LineNumbers) != 1 || pageA.LineNumbers[0] != 10 {
+		t.Errorf("expected page-a.txt to include shared.rst on line 10, got %s %v", pageA.DirectiveType, pageA.LineNumbers)
+	}
+
+	// wrapper.rst is included twice by page-b.txt
+	wrapper := root.Children[1]
+	if len(wrapper.Children) != 2 {
+		t.Fatalf("expected wrapper.rst to have 2 children, got %d", len(wrapper.Children))
+	}
+	pageB := wrapper.Children[1]
+	if len(pageB.LineNumbers) != 2 || pageB.LineNumbers[0] != 5 || pageB.LineNumbers[1] != 10 {
+		t.Errorf("expected page-b.txt to include wrapper.rst on lines 5 and 10, got %v", pageB.LineNumbers)
+	}
+
+	// loop-a.rst and loop-b.rst include each other; the repeat is marked as a cycle and not expanded
+	loopB := root.Children[0].Children[0]
+	if len(loopB.Children) != 1 || !loopB.Children[0].Cycle || len(loopB.Children[0].Children) != 0 {
+		t.Errorf("expected loop-b.rst -> loop-a.rst to be marked as a cycle")
+	}
+
+	// The flat list contains the unique pages at the leaves
+	if analysis.TotalFiles != 2 || len(analysis.UsingFiles) != 2 {
+		t.Errorf("expected 2 pages, got %d", analysis.TotalFiles)
+	}
+}
+
+// TestAddBlame tests adding the last commit to change the target, the using files, and the directive lines.
+func TestAddBlame(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repoDir := t.TempDir()
+	sourceDir := filepath.Join(repoDir, "source")
+	git := func(author string, date string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoDir}, args...)...)
+		email := strings.ToLower(author) + "@example.com"
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+author, "GIT_AUTHOR_EMAIL="+email,
+			"GIT_COMMITTER_NAME="+author, "GIT_COMMITTER_EMAIL="+email,
+			"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+		)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+	writeFile := func(name string, content string) {
+		t.Helper()
+		path := filepath.Join(sourceDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("Alice", "2024-01-15T12:00:00Z", "init", "-q")
+	writeFile("includes/shared.rst", "Shared content.\n")
+	git("Alice", "2024-01-15T12:00:00Z", "add", ".")
+	git("Alice", "2024-01-15T12:00:00Z", "commit", "-q", "-m", "Add shared include")
+
+	writeFile("page.txt", "Title\n=====\n\n.. include:: /includes/shared.rst\n")
+	git("Bob", "2024-02-15T12:00:00Z", "add", ".")
+	git("Bob", "2024-02-15T12:00:00Z", "commit", "-q", "-m", "Use shared include")
+
+	// Carol changes the page after Bob added the include
+	writeFile("page.txt", "Title\n=====\n\n.. include:: /includes/shared.rst\n\nMore content.\n")
+	git("Carol", "2024-03-15T12:00:00Z", "commit", "-q", "-am", "Add more content")
+
+	// A draft that hasn't been committed
+	writeFile("draft.txt", ".. include:: /includes/shared.rst\n")
+
+	analysis, err := AnalyzeUsage(filepath.Join(sourceDir, "includes", "shared.rst"), false, false, "")
+	if err != nil {
+		t.Fatalf("AnalyzeUsage failed: %v", err)
+	}
+	if err := AddBlame(analysis); err != nil {
+		t.Fatalf("AddBlame failed: %v", err)
+	}
+
+	if !analysis.Blamed {
+		t.Error("expected the analysis to be marked as blamed")
+	}
+	if analysis.TargetLastChanged == nil || analysis.TargetLastChanged.Author != "Alice" || analysis.TargetLastChanged.Date != "2024-01-15" {
+		t.Errorf("expected the target to be last changed by Alice on 2024-01-15, got %+v", analysis.TargetLastChanged)
+	}
+
+	usages := make(map[string]FileUsage)
+	for _, usage := range analysis.UsingFiles {
+		usages[filepath.Base(usage.FilePath)] = usage
+	}
+	page := usages["page.txt"]
+	if page.LastChanged == nil || page.LastChanged.Author != "Carol" || page.LastChanged.Email != "carol@example.com" {
+		t.Errorf("expected page.txt to be last changed by Carol, got %+v", page.LastChanged)
+	}
+	if page.LineLastChanged == nil || page.LineLastChanged.Author != "Bob" || page.LineLastChanged.Date != "2024-02-15" {
+		t.Errorf("expected the include in page.txt to be last changed by Bob on 2024-02-15, got %+v", page.LineLastChanged)
+	}
+	draft := usages["draft.txt"]
+	if draft.LastChanged != nil || draft.LineLastChanged != nil {
+		t.Errorf("expected no blame information for an uncommitted file, got %+v", draft)
+	}
+
+	// The source directory must be in a git repository
+	outside := &UsageAnalysis{TargetFile: filepath.Join(t.TempDir(), "source", "includes", "shared.rst")}
+	if err := os.MkdirAll(filepath.Dir(outside.TargetFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddBlame(outside); err == nil || !strings.Contains(err.Error(), "git repository") {
+		t.Errorf("expected an error outside a git repository, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package versions
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// versionArgRegex matches the version number at the start of a directive argument.
+// Example: "7.0", "v1.2.3", "4.4 Removed in 5.0"
+var versionArgRegex = regexp.MustCompile(`^v?(\d+(?:\.\d+){0,2})\b`)
+
+// AnalyzeVersions scans a file or directory for versioned content directives.
+//
+// Directories are scanned recursively, processing only .rst, .txt, and .md files.
+// If eolBefore is set, each directive whose version is older than eolBefore is
+// flagged as referencing an end-of-life version.
+//
+// Parameters:
+//   - rootPath: Path to the file or directory to scan
+//   - eolBefore: Versions older than this are flagged as EOL (empty to disable)
+//
+// Returns:
+//   - *VersionsAnalysis: The analysis results
+//   - error: Any error encountered during analysis
+func AnalyzeVersions(rootPath string, eolBefore string) (*VersionsAnalysis, error) {
+	var cutoff []int
+	if eolBefore != "" {
+		parsed, ok := parseVersion(eolBefore)
+		if !ok {
+			return nil, fmt.Errorf("invalid --eol-before version: %s", eolBefore)
+		}
+		cutoff = parsed
+	}
+
+	fileInfo, err := os.Stat(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access path %s: %w", rootPath, err)
+	}
+
+	var files []string
+	if fileInfo.IsDir() {
+		allFiles, err := rst.TraverseDirectory(rootPath, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to traverse directory %s: %w", rootPath, err)
+		}
+		for _, file := range allFiles {
+			if rst.ShouldProcessFile(file) {
+				files = append(files, file)
+			}
+		}
+	} else {
+		files = []string{rootPath}
+	}
+
+	analysis := NewVersionsAnalysis(rootPath, eolBefore)
+	for _, file := range files {
+		directives, err := findVersionDirectives(file)
+		if err != nil {
+			return nil, err
+		}
+		analysis.FilesScanned++
+		for _, directive := range directives {
+			if cutoff != nil && directive.Version != "" {
+				version, _ := parseVersion(directive.Version)
+				directive.IsEOL = compareVersions(version, cutoff) < 0
+			}
+			analysis.AddDirective(directive)
+		}
+	}
+
+	sort.SliceStable(analysis.Directives, func(i, j int) bool {
+		if analysis.Directives[i].FilePath != analysis.Directives[j].FilePath {
+			return analysis.Directives[i].FilePath < analysis.Directives[j].FilePath
+		}
+		return analysis.Directives[i].LineNum < analysis.Directives[j].LineNum
+	})
+
+	return analysis, nil
+}
+
+// findVersionDirectives returns all versioned content directives in a single file.
+func findVersionDirectives(filePath string) ([]VersionDirective, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	var directives []VersionDirective
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		matches := rst.VersionDirectiveRegex.FindStringSubmatch(scanner.Text())
+		if matches == nil {
+			continue
+		}
+
+		argument := strings.TrimSpace(matches[2])
+		version := ""
+		if versionMatch := versionArgRegex.FindStringSubmatch(argument); versionMatch != nil {
+			version = versionMatch[1]
+		}
+
+		directives = append(directives, VersionDirective{
+			FilePath:      filePath,
+			LineNum:       lineNum,
+			DirectiveType: matches[1],
+			Argument:      argument,
+			Version:       version,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+
+	return directives, nil
+}
+
+// parseVersion parses a version string like "7.0" or "v4.4.1" into its numeric components.
+func parseVersion(version string) ([]int, bool) {
+	matches := versionArgRegex.FindStringSubmatch(strings.TrimSpace(version))
+	if matches == nil {
+		return nil, false
+	}
+
+	var parts []int
+	for _, part := range strings.Split(matches[1], ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// compareVersions compares two parsed versions, treating missing components as zero.
+// Returns -1 if a < b, 0 if a == b, and 1 if a > b.
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// sortedVersions returns the keys of VersionCounts sorted from newest to oldest,
+// with unparseable versions last.
+func sortedVersions(analysis *VersionsAnalysis) []string {
+	versions := make([]string, 0, len(analysis.VersionCounts))
+	for version := range analysis.VersionCounts {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		a, aOK := parseVersion(versions[i])
+		b, bOK := parseVersion(versions[j])
+		if aOK != bOK {
+			return aOK
+		}
+		if !aOK {
+			return versions[i] < versions[j]
+		}
+		if cmp := compareVersions(a, b); cmp != 0 {
+			return cmp > 0
+		}
+		return versions[i] < versions[j]
+	})
+	return versions
+}
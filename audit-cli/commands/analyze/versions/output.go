@@ -0,0 +1,147 @@
+package versions
+
+import (
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// directiveTypes lists the directive types in display order.
+var directiveTypes = []string{"versionadded", "versionchanged", "deprecated"}
+
+// PrintAnalysis writes the analysis results in the writer's format.
+//
+// Text output is a summary followed by counts and, optionally, the directive list.
+// JSON output is the full analysis. CSV output is a single table: the directive list
+// when listAll or onlyEOL is set, otherwise the counts by version. Markdown output
+// contains each table under its own heading.
+//
+// Parameters:
+//   - w: The output writer, which determines the format and destination
+//   - analysis: The analysis results to print
+//   - listAll: If true, list every directive with its file and line
+//   - onlyEOL: If true, only list directives that reference EOL versions
+func PrintAnalysis(w *output.Writer, analysis *VersionsAnalysis, listAll bool, onlyEOL bool) error {
+	switch w.Format() {
+	case output.FormatJSON:
+		return printJSON(w, analysis, onlyEOL)
+	case output.FormatCSV:
+		if listAll || onlyEOL {
+			return w.WriteTable(directivesTable("", analysis.Directives, onlyEOL))
+		}
+		return w.WriteTable(versionTable(analysis))
+	case output.FormatMarkdown:
+		return printTables(w, analysis, listAll, onlyEOL)
+	default:
+		return printText(w, analysis, listAll, onlyEOL)
+	}
+}
+
+// printText writes the analysis results in human-readable text format.
+func printText(w *output.Writer, analysis *VersionsAnalysis, listAll bool, onlyEOL bool) error {
+	w.Println("============================================================")
+	w.Println(w.Colorize("VERSIONED CONTENT ANALYSIS", output.Bold))
+	w.Println("============================================================")
+	w.Printf("Path: %s\n", analysis.RootPath)
+	w.Printf("Files Scanned: %d\n", analysis.FilesScanned)
+	w.Printf("Total Version Directives: %d\n", analysis.TotalCount)
+	if analysis.EOLBefore != "" {
+		w.Printf("EOL Cutoff: versions before %s\n", analysis.EOLBefore)
+		w.Printf("Directives Referencing EOL Versions: %s\n", w.Colorize(output.FormatValue(analysis.EOLCount), output.Yellow))
+	}
+	w.Println("============================================================")
+	w.Println()
+
+	if analysis.TotalCount == 0 {
+		w.Println("No version directives found.")
+		return nil
+	}
+
+	return printTables(w, analysis, listAll, onlyEOL)
+}
+
+// printTables writes the count tables and, if requested, the directive lists.
+func printTables(w *output.Writer, analysis *VersionsAnalysis, listAll bool, onlyEOL bool) error {
+	if !onlyEOL {
+		if err := w.WriteTable(typeTable(analysis)); err != nil {
+			return err
+		}
+		w.Println()
+		if err := w.WriteTable(versionTable(analysis)); err != nil {
+			return err
+		}
+		w.Println()
+	}
+
+	if analysis.EOLBefore != "" && (listAll || onlyEOL) {
+		if err := w.WriteTable(directivesTable("Directives Referencing EOL Versions (candidates for removal):", analysis.Directives, true)); err != nil {
+			return err
+		}
+		w.Println()
+	}
+
+	if listAll && !onlyEOL {
+		if err := w.WriteTable(directivesTable("All Directives:", analysis.Directives, false)); err != nil {
+			return err
+		}
+		w.Println()
+	}
+	return nil
+}
+
+// typeTable builds the table of counts by directive type.
+func typeTable(analysis *VersionsAnalysis) *output.Table {
+	table := output.NewTable("By Directive Type:",
+		output.Column{Header: "Directive"},
+		output.Column{Header: "Count", Align: output.AlignRight},
+	)
+	for _, directiveType := range directiveTypes {
+		table.AddRow(directiveType, analysis.TypeCounts[directiveType])
+	}
+	return table
+}
+
+// versionTable builds the table of counts by version, with one column per directive type.
+func versionTable(analysis *VersionsAnalysis) *output.Table {
+	table := output.NewTable("By Version:", output.Column{Header: "Version"})
+	for _, directiveType := range directiveTypes {
+		table.Columns = append(table.Columns, output.Column{Header: directiveType, Align: output.AlignRight})
+	}
+	for _, version := range sortedVersions(analysis) {
+		counts := analysis.VersionCounts[version]
+		table.AddRow(version, counts["versionadded"], counts["versionchanged"], counts["deprecated"])
+	}
+	return table
+}
+
+// directivesTable builds a table with one row per directive, optionally only those flagged as EOL.
+func directivesTable(title string, directives []VersionDirective, eolOnly bool) *output.Table {
+	table := output.NewTable(title,
+		output.Column{Header: "File"},
+		output.Column{Header: "Line", Align: output.AlignRight},
+		output.Column{Header: "Directive"},
+		output.Column{Header: "Argument", MaxWidth: 60},
+	)
+	for _, directive := range directives {
+		if eolOnly && !directive.IsEOL {
+			continue
+		}
+		table.AddRow(directive.FilePath, directive.LineNum, directive.DirectiveType, directive.Argument)
+	}
+	if len(table.Rows) == 0 {
+		table.Footer = "(none)"
+	}
+	return table
+}
+
+// printJSON writes the analysis results in JSON format.
+func printJSON(w *output.Writer, analysis *VersionsAnalysis, onlyEOL bool) error {
+	result := *analysis
+	if onlyEOL {
+		result.Directives = make([]VersionDirective, 0)
+		for _, directive := range analysis.Directives {
+			if directive.IsEOL {
+				result.Directives = append(result.Directives, directive)
+			}
+		}
+	}
+	return w.WriteJSON(result)
+}
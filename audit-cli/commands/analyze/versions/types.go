@@ -0,0 +1,60 @@
+package versions
+
+// VersionDirective represents a single versioned content directive found in a file.
+type VersionDirective struct {
+	FilePath      string `json:"file_path"`      // Path to the file containing the directive
+	LineNum       int    `json:"line_num"`       // Line number of the directive (1-based)
+	DirectiveType string `json:"directive_type"` // versionadded, versionchanged, or deprecated
+	Argument      string `json:"argument"`       // Full directive argument, e.g. "7.0" or "4.4 Removed in 5.0"
+	Version       string `json:"version"`        // Normalized version parsed from the argument, e.g. "7.0"
+	IsEOL         bool   `json:"is_eol"`         // Whether the version is older than the EOL cutoff
+}
+
+// VersionsAnalysis contains the results of analyzing versioned content directives.
+type VersionsAnalysis struct {
+	RootPath      string                    `json:"root_path"`            // File or directory that was analyzed
+	EOLBefore     string                    `json:"eol_before,omitempty"` // EOL cutoff version, if one was provided
+	FilesScanned  int                       `json:"files_scanned"`        // Number of files scanned
+	TotalCount    int                       `json:"total_count"`          // Total number of version directives found
+	EOLCount      int                       `json:"eol_count"`            // Number of directives that reference EOL versions
+	TypeCounts    map[string]int            `json:"type_counts"`          // Counts by directive type
+	VersionCounts map[string]map[string]int `json:"version_counts"`       // Counts by version, then by directive type
+	Unparseable   int                       `json:"unparseable"`          // Directives whose argument doesn't start with a version
+	Directives    []VersionDirective        `json:"directives"`           // All directives found, sorted by file and line
+}
+
+// NewVersionsAnalysis creates a new initialized VersionsAnalysis.
+func NewVersionsAnalysis(rootPath string, eolBefore string) *VersionsAnalysis {
+	return &VersionsAnalysis{
+		RootPath:      rootPath,
+		EOLBefore:     eolBefore,
+		TypeCounts:    make(map[string]int),
+		VersionCounts: make(map[string]map[string]int),
+		Directives:    make([]VersionDirective, 0),
+	}
+}
+
+// AddDirective updates the analysis with a directive.
+func (a *VersionsAnalysis) AddDirective(directive VersionDirective) {
+	a.TotalCount++
+	a.TypeCounts[directive.DirectiveType]++
+
+	version := directive.Version
+	if version == "" {
+		a.Unparseable++
+		version = unknownVersion
+	}
+	if a.VersionCounts[version] == nil {
+		a.VersionCounts[version] = make(map[string]int)
+	}
+	a.VersionCounts[version][directive.DirectiveType]++
+
+	if directive.IsEOL {
+		a.EOLCount++
+	}
+
+	a.Directives = append(a.Directives, directive)
+}
+
+// unknownVersion is the VersionCounts key used for directives without a parseable version
+const unknownVersion = "(unknown)"
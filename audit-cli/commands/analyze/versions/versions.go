@@ -0,0 +1,106 @@
+// Package versions provides functionality for analyzing versioned content directives.
+//
+// This package implements the "analyze versions" subcommand, which inventories
+// versionadded, versionchanged, and deprecated directives across RST files and
+// flags those that reference end-of-life versions so they can be removed.
+package versions
+
+import (
+	"fmt"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// NewVersionsCommand creates the versions subcommand.
+//
+// This command scans a file or directory for versioned content directives and
+// reports counts by directive type and version.
+//
+// Usage:
+//
+//	analyze versions /path/to/source
+//	analyze versions /path/to/source --eol-before 5.0
+//	analyze versions /path/to/source --eol-before 5.0 --only-eol
+//	analyze versions /path/to/source --list-all --format json
+//
+// Flags:
+//   - --eol-before: Flag directives referencing versions older than this version as EOL
+//   - --only-eol: Only report directives that reference EOL versions (requires --eol-before)
+//   - --list-all: List every directive with its file and line number
+//   - --format: Output format (text, json, csv, or markdown)
+//   - --output-file: Write results to a file instead of stdout
+func NewVersionsCommand() *cobra.Command {
+	var (
+		eolBefore  string
+		onlyEOL    bool
+		listAll    bool
+		outputOpts output.Options
+	)
+
+	cmd := &cobra.Command{
+		Use:   "versions [filepath]",
+		Short: "Inventory versionadded, versionchanged, and deprecated directives",
+		Long: `Inventory versioned content directives in reStructuredText files.
+
+This command scans a file, or a directory recursively, for the following directives
+and reports them with their version arguments:
+  - .. versionadded::
+  - .. versionchanged::
+  - .. deprecated::
+
+Use --eol-before to flag directives that reference end-of-life versions. These
+are candidates for removal during version-cleanup projects.
+
+Examples:
+  # Summarize version directives in a project
+  analyze versions /path/to/manual/source
+
+  # Flag directives that reference versions older than 5.0
+  analyze versions /path/to/manual/source --eol-before 5.0
+
+  # List only the EOL directives with their file and line
+  analyze versions /path/to/manual/source --eol-before 5.0 --only-eol
+
+  # Output every directive as JSON
+  analyze versions /path/to/manual/source --list-all --format json
+
+  # Write the EOL directives to a CSV file for a cleanup ticket
+  analyze versions /path/to/manual/source --eol-before 5.0 --only-eol --format csv --output-file eol.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVersions(args[0], eolBefore, onlyEOL, listAll, outputOpts)
+		},
+	}
+
+	cmd.Flags().StringVar(&eolBefore, "eol-before", "", "Flag directives referencing versions older than this version as EOL")
+	cmd.Flags().BoolVar(&onlyEOL, "only-eol", false, "Only report directives that reference EOL versions (requires --eol-before)")
+	cmd.Flags().BoolVar(&listAll, "list-all", false, "List every directive with its file and line number")
+	output.AddFlags(cmd, &outputOpts)
+
+	return cmd
+}
+
+// runVersions executes the versions analysis operation.
+func runVersions(path string, eolBefore string, onlyEOL bool, listAll bool, outputOpts output.Options) error {
+	if onlyEOL && eolBefore == "" {
+		return fmt.Errorf("--only-eol requires --eol-before")
+	}
+
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
+	analysis, err := AnalyzeVersions(path, eolBefore)
+	if err != nil {
+		return err
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return PrintAnalysis(w, analysis, listAll, onlyEOL)
+}
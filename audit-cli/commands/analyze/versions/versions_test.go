@@ -0,0 +1,105 @@
+// Package versions provides tests for the versions analysis functionality.
+package versions
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestAnalyzeVersions tests counting directives by type and version across a directory.
+func TestAnalyzeVersions(t *testing.T) {
+	testDataDir := filepath.Join("..", "..", "..", "testdata", "analyze-versions", "source")
+
+	analysis, err := AnalyzeVersions(testDataDir, "")
+	if err != nil {
+		t.Fatalf("AnalyzeVersions failed: %v", err)
+	}
+
+	// index.txt and includes/fact-options.rst; notes.yaml is skipped
+	if analysis.FilesScanned != 2 {
+		t.Errorf("Expected 2 files scanned, got %d", analysis.FilesScanned)
+	}
+
+	if analysis.TotalCount != 6 {
+		t.Errorf("Expected 6 directives, got %d", analysis.TotalCount)
+	}
+
+	expectedTypes := map[string]int{
+		"versionadded":   2,
+		"versionchanged": 2,
+		"deprecated":     2,
+	}
+	for directiveType, expected := range expectedTypes {
+		if analysis.TypeCounts[directiveType] != expected {
+			t.Errorf("Expected %d %s directives, got %d", expected, directiveType, analysis.TypeCounts[directiveType])
+		}
+	}
+
+	if analysis.VersionCounts["3.6.2"]["versionadded"] != 1 {
+		t.Errorf("Expected nested versionadded with v-prefix to be counted as 3.6.2, got %v", analysis.VersionCounts)
+	}
+	if analysis.VersionCounts["4.2"]["deprecated"] != 1 {
+		t.Errorf("Expected deprecated 4.2 to be counted, got %v", analysis.VersionCounts)
+	}
+	if analysis.Unparseable != 1 {
+		t.Errorf("Expected 1 unparseable directive, got %d", analysis.Unparseable)
+	}
+	if analysis.EOLCount != 0 {
+		t.Errorf("Expected no EOL directives without a cutoff, got %d", analysis.EOLCount)
+	}
+}
+
+// TestAnalyzeVersionsEOL tests flagging directives older than the EOL cutoff.
+func TestAnalyzeVersionsEOL(t *testing.T) {
+	testFile := filepath.Join("..", "..", "..", "testdata", "analyze-versions", "source", "index.txt")
+
+	analysis, err := AnalyzeVersions(testFile, "5.0")
+	if err != nil {
+		t.Fatalf("AnalyzeVersions failed: %v", err)
+	}
+
+	if analysis.EOLCount != 2 {
+		t.Fatalf("Expected 2 EOL directives, got %d", analysis.EOLCount)
+	}
+
+	expectedEOL := map[int]bool{5: false, 9: true, 13: true}
+	for _, directive := range analysis.Directives {
+		if directive.IsEOL != expectedEOL[directive.LineNum] {
+			t.Errorf("Line %d (%s %s): expected IsEOL=%v, got %v",
+				directive.LineNum, directive.DirectiveType, directive.Argument,
+				expectedEOL[directive.LineNum], directive.IsEOL)
+		}
+	}
+}
+
+// TestAnalyzeVersionsInvalidCutoff tests that an unparseable cutoff is rejected.
+func TestAnalyzeVersionsInvalidCutoff(t *testing.T) {
+	testDataDir := filepath.Join("..", "..", "..", "testdata", "analyze-versions", "source")
+
+	if _, err := AnalyzeVersions(testDataDir, "latest"); err == nil {
+		t.Error("Expected error for invalid --eol-before version")
+	}
+}
+
+// TestCompareVersions tests version comparison with differing component counts.
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"5.0", "5.0", 0},
+		{"5", "5.0.0", 0},
+		{"4.4", "5.0", -1},
+		{"7.0", "5.0", 1},
+		{"4.10", "4.9", 1},
+		{"v3.6.2", "3.6", 1},
+	}
+
+	for _, tt := range tests {
+		a, _ := parseVersion(tt.a)
+		b, _ := parseVersion(tt.b)
+		if got := compareVersions(a, b); got != tt.expected {
+			t.Errorf("compareVersions(%s, %s) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
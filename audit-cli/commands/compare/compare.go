@@ -3,12 +3,14 @@
 // This package serves as the parent command for various comparison operations.
 // Currently supports:
 //   - file-contents: Compare file contents across different versions
+//   - rendered-output: Compare the rendered HTML of two pages (experimental)
 //
 // Future subcommands could include comparing metadata, structure, or other aspects.
 package compare
 
 import (
 	"github.com/mongodb/code-example-tooling/audit-cli/commands/compare/file-contents"
+	"github.com/mongodb/code-example-tooling/audit-cli/commands/compare/rendered-output"
 	"github.com/spf13/cobra"
 )
 
@@ -24,11 +26,15 @@ func NewCompareCommand() *cobra.Command {
 
 Currently supports comparing file contents to identify differences between
 the same file across multiple documentation versions. This helps writers
-understand how content has diverged across versions and identify maintenance work.`,
+understand how content has diverged across versions and identify maintenance work.
+
+Also supports comparing the rendered output of two pages, which catches
+differences from includes and source constants that a raw diff misses.`,
 	}
 
 	// Add subcommands
 	cmd.AddCommand(file_contents.NewFileContentsCommand())
+	cmd.AddCommand(rendered_output.NewRenderedOutputCommand())
 
 	return cmd
 }
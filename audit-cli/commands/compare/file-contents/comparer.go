@@ -16,12 +16,13 @@ import (
 //   - file1Path: Path to the first file
 //   - file2Path: Path to the second file
 //   - generateDiff: If true, generate unified diff for differences
+//   - opts: Differences to ignore, such as whitespace or comments
 //   - verbose: If true, show detailed processing information
 //
 // Returns:
 //   - *ComparisonResult: The comparison result
 //   - error: Any error encountered during comparison
-func CompareFiles(file1Path, file2Path string, generateDiff bool, verbose bool) (*ComparisonResult, error) {
+func CompareFiles(file1Path, file2Path string, generateDiff bool, opts DiffOptions, verbose bool) (*ComparisonResult, error) {
 	if verbose {
 		fmt.Printf("Comparing files:\n")
 		fmt.Printf("  File 1: %s\n", file1Path)
@@ -44,6 +45,7 @@ func CompareFiles(file1Path, file2Path string, generateDiff bool, verbose bool)
 	result := &ComparisonResult{
 		ReferenceFile: file1Path,
 		TotalFiles:    1,
+		Options:       opts,
 	}
 
 	comparison := FileComparison{
@@ -51,15 +53,21 @@ func CompareFiles(file1Path, file2Path string, generateDiff bool, verbose bool)
 		FilePath: file2Path,
 	}
 
-	if AreFilesIdentical(string(content1), string(content2)) {
+	normalized1 := NormalizeContent(string(content1), file1Path, opts)
+	normalized2 := NormalizeContent(string(content2), file2Path, opts)
+	if AreFilesIdentical(normalized1, normalized2) {
 		comparison.Status = FileMatches
+		comparison.IgnoredDifferences = !AreFilesIdentical(string(content1), string(content2))
 		result.MatchingFiles = 1
+		if comparison.IgnoredDifferences {
+			result.IgnoredDifferenceFiles = 1
+		}
 	} else {
 		comparison.Status = FileDiffers
 		result.DifferingFiles = 1
 
 		if generateDiff {
-			diff, err := GenerateDiff(file1Path, string(content1), file2Path, string(content2))
+			diff, err := GenerateDiff(file1Path, normalized1, file2Path, normalized2)
 			if err != nil {
 				return nil, fmt.Errorf("failed to generate diff: %w", err)
 			}
@@ -82,12 +90,13 @@ func CompareFiles(file1Path, file2Path string, generateDiff bool, verbose bool)
 //   - productDir: Path to the product directory
 //   - versions: List of version identifiers to compare
 //   - generateDiff: If true, generate unified diff for differences
+//   - opts: Differences to ignore, such as whitespace or comments
 //   - verbose: If true, show detailed processing information
 //
 // Returns:
 //   - *ComparisonResult: The comparison result
 //   - error: Any error encountered during comparison
-func CompareVersions(referenceFile, productDir string, versions []string, generateDiff bool, verbose bool) (*ComparisonResult, error) {
+func CompareVersions(referenceFile, productDir string, versions []string, generateDiff bool, opts DiffOptions, verbose bool) (*ComparisonResult, error) {
 	if verbose {
 		fmt.Printf("Comparing file across %d versions...\n", len(versions))
 		fmt.Printf("  Reference file: %s\n", referenceFile)
@@ -122,6 +131,7 @@ func CompareVersions(referenceFile, productDir string, versions []string, genera
 		ReferenceFile:    referenceFile,
 		ReferenceVersion: referenceVersion,
 		TotalFiles:       len(versionPaths),
+		Options:          opts,
 	}
 
 	// Compare each version
@@ -130,13 +140,16 @@ func CompareVersions(referenceFile, productDir string, versions []string, genera
 			fmt.Printf("  Checking %s: %s\n", vp.Version, vp.FilePath)
 		}
 
-		comparison := compareFile(referenceFile, string(referenceContent), vp, generateDiff, verbose)
+		comparison := compareFile(referenceFile, string(referenceContent), vp, generateDiff, opts, verbose)
 		result.Comparisons = append(result.Comparisons, comparison)
 
 		// Update counters
 		switch comparison.Status {
 		case FileMatches:
 			result.MatchingFiles++
+			if comparison.IgnoredDifferences {
+				result.IgnoredDifferenceFiles++
+			}
 		case FileDiffers:
 			result.DifferingFiles++
 		case FileNotFound:
@@ -158,11 +171,12 @@ func CompareVersions(referenceFile, productDir string, versions []string, genera
 //   - referenceContent: Content of the reference file
 //   - versionPath: The version path to compare
 //   - generateDiff: If true, generate unified diff for differences
+//   - opts: Differences to ignore, such as whitespace or comments
 //   - verbose: If true, show detailed processing information
 //
 // Returns:
 //   - FileComparison: The comparison result for this file
-func compareFile(referencePath, referenceContent string, versionPath projectinfo.VersionPath, generateDiff bool, verbose bool) FileComparison {
+func compareFile(referencePath, referenceContent string, versionPath projectinfo.VersionPath, generateDiff bool, opts DiffOptions, verbose bool) FileComparison {
 	comparison := FileComparison{
 		Version:  versionPath.Version,
 		FilePath: versionPath.FilePath,
@@ -188,11 +202,18 @@ func compareFile(referencePath, referenceContent string, versionPath projectinfo
 		return comparison
 	}
 
-	// Compare contents
-	if AreFilesIdentical(referenceContent, string(content)) {
+	// Compare contents, ignoring the differences the options ignore
+	normalizedReference := NormalizeContent(referenceContent, referencePath, opts)
+	normalizedContent := NormalizeContent(string(content), versionPath.FilePath, opts)
+	if AreFilesIdentical(normalizedReference, normalizedContent) {
 		comparison.Status = FileMatches
+		comparison.IgnoredDifferences = !AreFilesIdentical(referenceContent, string(content))
 		if verbose {
-			fmt.Printf("    → Matches\n")
+			if comparison.IgnoredDifferences {
+				fmt.Printf("    → Matches (ignoring differences)\n")
+			} else {
+				fmt.Printf("    → Matches\n")
+			}
 		}
 	} else {
 		comparison.Status = FileDiffers
@@ -201,7 +222,7 @@ func compareFile(referencePath, referenceContent string, versionPath projectinfo
 		}
 
 		if generateDiff {
-			diff, err := GenerateDiff(referencePath, referenceContent, versionPath.FilePath, string(content))
+			diff, err := GenerateDiff(referencePath, normalizedReference, versionPath.FilePath, normalizedContent)
 			if err != nil {
 				comparison.Status = FileError
 				comparison.Error = fmt.Errorf("failed to generate diff: %w", err)
@@ -12,6 +12,9 @@
 //   - Default: Summary of differences
 //   - --show-paths: Include file paths
 //   - --show-diff: Include unified diffs
+//
+// --ignore-whitespace and --ignore-comments ignore whitespace-only and
+// comment-only differences, so results focus on semantic changes.
 package file_contents
 
 import (
@@ -19,6 +22,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 	"github.com/mongodb/code-example-tooling/audit-cli/internal/projectinfo"
 	"github.com/spf13/cobra"
 )
@@ -41,13 +45,18 @@ import (
 //   - -V, --versions: Comma-separated list of versions (optional; auto-discovers all versions if not specified)
 //   - --show-paths: Display file paths of files that differ
 //   - -d, --show-diff: Display unified diff output
+//   - -w, --ignore-whitespace: Ignore differences in indentation, spacing, and blank lines
+//   - --ignore-comments: Ignore differences in comments
 //   - -v, --verbose: Show detailed processing information
+//   - --output-file: Write results to a file instead of stdout
 func NewFileContentsCommand() *cobra.Command {
 	var (
-		versions  string
-		showPaths bool
-		showDiff  bool
-		verbose   bool
+		versions   string
+		showPaths  bool
+		showDiff   bool
+		diffOpts   DiffOptions
+		verbose    bool
+		outputOpts output.Options
 	)
 
 	cmd := &cobra.Command{
@@ -81,18 +90,27 @@ The command provides progressive output detail:
   - --show-paths: Include file paths grouped by status
   - --show-diff: Include unified diffs (implies --show-paths)
 
+To focus on semantic changes, --ignore-whitespace ignores differences in
+indentation, spacing, and blank lines, and --ignore-comments ignores
+differences in comments. Comments are detected from the file extension:
+rST comments in .rst and .txt files, and line and block comments in code
+files. Diffs then show the normalized contents.
+
 Files that don't exist in certain versions are reported separately and
 do not cause errors.`,
 		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runCompare(args, versions, showPaths, showDiff, verbose)
+			return runCompare(args, versions, showPaths, showDiff, diffOpts, verbose, outputOpts)
 		},
 	}
 
 	cmd.Flags().StringVarP(&versions, "versions", "V", "", "Comma-separated list of versions (optional; auto-discovers all versions if not specified)")
 	cmd.Flags().BoolVar(&showPaths, "show-paths", false, "Display file paths of files that differ")
 	cmd.Flags().BoolVarP(&showDiff, "show-diff", "d", false, "Display unified diff output")
+	cmd.Flags().BoolVarP(&diffOpts.IgnoreWhitespace, "ignore-whitespace", "w", false, "Ignore differences in indentation, spacing, and blank lines")
+	cmd.Flags().BoolVar(&diffOpts.IgnoreComments, "ignore-comments", false, "Ignore differences in comments")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed processing information")
+	output.AddFileFlags(cmd, &outputOpts)
 
 	return cmd
 }
@@ -107,18 +125,20 @@ do not cause errors.`,
 //   - versions: Comma-separated version list (for version comparison)
 //   - showPaths: If true, show file paths
 //   - showDiff: If true, show diffs
+//   - diffOpts: Differences to ignore
 //   - verbose: If true, show detailed processing information
+//   - outputOpts: Output destination
 //
 // Returns:
 //   - error: Any error encountered during comparison
-func runCompare(args []string, versions string, showPaths, showDiff, verbose bool) error {
+func runCompare(args []string, versions string, showPaths, showDiff bool, diffOpts DiffOptions, verbose bool, outputOpts output.Options) error {
 	// Validate arguments based on mode
 	if len(args) == 2 {
 		// Direct comparison mode
 		if versions != "" {
 			return fmt.Errorf("--versions cannot be used with two file arguments")
 		}
-		return runDirectComparison(args[0], args[1], showPaths, showDiff, verbose)
+		return runDirectComparison(args[0], args[1], showPaths, showDiff, diffOpts, verbose, outputOpts)
 	} else if len(args) == 1 {
 		// Version comparison mode
 		// Convert to absolute path
@@ -149,7 +169,7 @@ func runCompare(args []string, versions string, showPaths, showDiff, verbose boo
 			}
 		}
 
-		return runVersionComparison(absPath, productDir, versions, showPaths, showDiff, verbose)
+		return runVersionComparison(absPath, productDir, versions, showPaths, showDiff, diffOpts, verbose, outputOpts)
 	}
 
 	return fmt.Errorf("expected 1 or 2 file arguments")
@@ -162,18 +182,19 @@ func runCompare(args []string, versions string, showPaths, showDiff, verbose boo
 //   - file2: Path to the second file
 //   - showPaths: If true, show file paths
 //   - showDiff: If true, show diffs
+//   - diffOpts: Differences to ignore
 //   - verbose: If true, show detailed processing information
+//   - outputOpts: Output destination
 //
 // Returns:
 //   - error: Any error encountered during comparison
-func runDirectComparison(file1, file2 string, showPaths, showDiff, verbose bool) error {
-	result, err := CompareFiles(file1, file2, showDiff, verbose)
+func runDirectComparison(file1, file2 string, showPaths, showDiff bool, diffOpts DiffOptions, verbose bool, outputOpts output.Options) error {
+	result, err := CompareFiles(file1, file2, showDiff, diffOpts, verbose)
 	if err != nil {
 		return fmt.Errorf("comparison failed: %w", err)
 	}
 
-	PrintComparisonResult(result, showPaths, showDiff)
-	return nil
+	return printResult(result, showPaths, showDiff, outputOpts)
 }
 
 // runVersionComparison performs a version-based comparison.
@@ -184,23 +205,36 @@ func runDirectComparison(file1, file2 string, showPaths, showDiff, verbose bool)
 //   - versionsStr: Comma-separated version list
 //   - showPaths: If true, show file paths
 //   - showDiff: If true, show diffs
+//   - diffOpts: Differences to ignore
 //   - verbose: If true, show detailed processing information
+//   - outputOpts: Output destination
 //
 // Returns:
 //   - error: Any error encountered during comparison
-func runVersionComparison(referenceFile, productDir, versionsStr string, showPaths, showDiff, verbose bool) error {
+func runVersionComparison(referenceFile, productDir, versionsStr string, showPaths, showDiff bool, diffOpts DiffOptions, verbose bool, outputOpts output.Options) error {
 	// Parse versions
 	versionList := parseVersions(versionsStr)
 	if len(versionList) == 0 {
 		return fmt.Errorf("no versions specified")
 	}
 
-	result, err := CompareVersions(referenceFile, productDir, versionList, showDiff, verbose)
+	result, err := CompareVersions(referenceFile, productDir, versionList, showDiff, diffOpts, verbose)
 	if err != nil {
 		return fmt.Errorf("comparison failed: %w", err)
 	}
 
-	PrintComparisonResult(result, showPaths, showDiff)
+	return printResult(result, showPaths, showDiff, outputOpts)
+}
+
+// printResult opens the output destination and prints the comparison result to it.
+func printResult(result *ComparisonResult, showPaths, showDiff bool, outputOpts output.Options) error {
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	PrintComparisonResult(w, result, showPaths, showDiff)
 	return nil
 }
 
@@ -1,6 +1,8 @@
 package file_contents
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -55,7 +57,7 @@ func TestCompareFiles(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := CompareFiles(tt.file1, tt.file2, tt.generateDiff, false)
+			result, err := CompareFiles(tt.file1, tt.file2, tt.generateDiff, DiffOptions{}, false)
 
 			if tt.expectError {
 				if err == nil {
@@ -144,7 +146,7 @@ func TestCompareVersions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := CompareVersions(tt.referenceFile, tt.productDir, tt.versions, tt.generateDiff, false)
+			result, err := CompareVersions(tt.referenceFile, tt.productDir, tt.versions, tt.generateDiff, DiffOptions{}, false)
 
 			if tt.expectError {
 				if err == nil {
@@ -533,3 +535,142 @@ func TestParseVersions(t *testing.T) {
 		})
 	}
 }
+
+// TestNormalizeContent tests ignoring whitespace and comment differences
+func TestNormalizeContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		content1 string
+		content2 string
+		opts     DiffOptions
+		wantSame bool
+	}{
+		{
+			name:     "whitespace differs without options",
+			path:     "example.py",
+			content1: "def f():\n    return 1\n",
+			content2: "def f():\n\treturn  1\n\n",
+			wantSame: false,
+		},
+		{
+			name:     "whitespace ignored",
+			path:     "example.py",
+			content1: "def f():\n    return 1\n",
+			content2: "def f():\n\treturn  1   \n\n",
+			opts:     DiffOptions{IgnoreWhitespace: true},
+			wantSame: true,
+		},
+		{
+			name:     "whitespace ignored but code differs",
+			path:     "example.py",
+			content1: "def f():\n    return 1\n",
+			content2: "def f():\n    return 2\n",
+			opts:     DiffOptions{IgnoreWhitespace: true},
+			wantSame: false,
+		},
+		{
+			name:     "Go line and block comments ignored",
+			path:     "example.go",
+			content1: "package main\n\n// Connect connects\nfunc Connect() {\n\tdial() // dial the server\n}\n",
+			content2: "package main\n\n/* Connect opens\n   a connection */\nfunc Connect() {\n\tdial()\n}\n",
+			opts:     DiffOptions{IgnoreComments: true},
+			wantSame: true,
+		},
+		{
+			name:     "comment markers in strings are kept",
+			path:     "example.js",
+			content1: "const uri = \"mongodb://localhost\";\n",
+			content2: "const uri = \"mongodb://example.com\";\n",
+			opts:     DiffOptions{IgnoreComments: true},
+			wantSame: false,
+		},
+		{
+			name:     "Python comments ignored",
+			path:     "example.py",
+			content1: "# Connect to the server\nclient = MongoClient(uri)\n",
+			content2: "client = MongoClient(uri)  # uses the URI\n",
+			opts:     DiffOptions{IgnoreComments: true},
+			wantSame: true,
+		},
+		{
+			name:     "rST comments ignored, directives kept",
+			path:     "example.rst",
+			content1: "Intro\n\n.. TODO: update for v8\n   when released\n\n.. code-block:: go\n\n   fmt.Println()\n",
+			content2: "Intro\n\n.. code-block:: go\n\n   fmt.Println()\n",
+			opts:     DiffOptions{IgnoreComments: true},
+			wantSame: true,
+		},
+		{
+			name:     "rST directive changes are not comments",
+			path:     "example.rst",
+			content1: ".. code-block:: go\n",
+			content2: ".. code-block:: python\n",
+			opts:     DiffOptions{IgnoreComments: true},
+			wantSame: false,
+		},
+		{
+			name:     "unknown extensions keep comments",
+			path:     "example.unknown",
+			content1: "value # one\n",
+			content2: "value # two\n",
+			opts:     DiffOptions{IgnoreComments: true},
+			wantSame: false,
+		},
+		{
+			name:     "comments and whitespace ignored together",
+			path:     "example.java",
+			content1: "int x = 1; // set x\n",
+			content2: "int  x = 1;\n\n",
+			opts:     DiffOptions{IgnoreWhitespace: true, IgnoreComments: true},
+			wantSame: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := NormalizeContent(tt.content1, tt.path, tt.opts)
+			got2 := NormalizeContent(tt.content2, tt.path, tt.opts)
+			if (got1 == got2) != tt.wantSame {
+				t.Errorf("normalized contents same = %v, want %v\n%q\n%q", got1 == got2, tt.wantSame, got1, got2)
+			}
+		})
+	}
+}
+
+// TestCompareFilesWithDiffOptions tests that ignored differences count as matches
+func TestCompareFilesWithDiffOptions(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "v1", "example.py")
+	file2 := filepath.Join(dir, "v2", "example.py")
+	for path, content := range map[string]string{
+		file1: "# Connect\nclient = MongoClient(uri)\n",
+		file2: "client  =  MongoClient(uri)\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := CompareFiles(file1, file2, true, DiffOptions{}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DifferingFiles != 1 {
+		t.Errorf("expected 1 differing file without options, got %d", result.DifferingFiles)
+	}
+
+	result, err = CompareFiles(file1, file2, true, DiffOptions{IgnoreWhitespace: true, IgnoreComments: true}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MatchingFiles != 1 || result.IgnoredDifferenceFiles != 1 {
+		t.Errorf("expected 1 matching file with ignored differences, got %d matching, %d ignored", result.MatchingFiles, result.IgnoredDifferenceFiles)
+	}
+	if !result.Comparisons[0].IgnoredDifferences || result.Comparisons[0].Diff != "" {
+		t.Errorf("expected ignored differences and no diff, got %+v", result.Comparisons[0])
+	}
+}
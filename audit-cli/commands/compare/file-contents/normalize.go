@@ -0,0 +1,197 @@
+package file_contents
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// commentSyntax describes how a language writes comments.
+type commentSyntax struct {
+	// line starts a comment that runs to the end of the line, such as "//" or "#"
+	line []string
+	// blockStart and blockEnd delimit a block comment, such as "/*" and "*/"
+	blockStart string
+	blockEnd   string
+	// quotes are the characters that delimit string literals, whose contents are never comments
+	quotes string
+}
+
+var (
+	cStyleComments   = commentSyntax{line: []string{"//"}, blockStart: "/*", blockEnd: "*/", quotes: "\"'`"}
+	hashComments     = commentSyntax{line: []string{"#"}, quotes: "\"'"}
+	configComments   = commentSyntax{line: []string{"#"}, quotes: "\""}
+	rustComments     = commentSyntax{line: []string{"//"}, blockStart: "/*", blockEnd: "*/", quotes: "\""}
+	dashDashComments = commentSyntax{line: []string{"--"}, blockStart: "/*", blockEnd: "*/", quotes: "\"'"}
+	markupComments   = commentSyntax{blockStart: "<!--", blockEnd: "-->"}
+)
+
+// commentSyntaxByExtension maps file extensions to their comment syntax.
+var commentSyntaxByExtension = map[string]commentSyntax{
+	".c": cStyleComments, ".h": cStyleComments, ".cpp": cStyleComments, ".hpp": cStyleComments, ".cc": cStyleComments,
+	".cs": cStyleComments, ".java": cStyleComments, ".kt": cStyleComments, ".scala": cStyleComments, ".groovy": cStyleComments,
+	".go": cStyleComments, ".rs": rustComments, ".swift": cStyleComments, ".dart": cStyleComments, ".php": cStyleComments,
+	".js": cStyleComments, ".mjs": cStyleComments, ".cjs": cStyleComments, ".jsx": cStyleComments,
+	".ts": cStyleComments, ".tsx": cStyleComments, ".json5": cStyleComments,
+	".py": hashComments, ".rb": hashComments, ".sh": hashComments, ".bash": hashComments, ".zsh": hashComments,
+	".ps1": hashComments, ".pl": hashComments, ".r": hashComments,
+	".yaml": configComments, ".yml": configComments, ".toml": configComments, ".ini": configComments, ".conf": configComments,
+	".sql": dashDashComments, ".lua": dashDashComments,
+	".html": markupComments, ".xml": markupComments, ".xhtml": markupComments,
+}
+
+// rstCommentPattern matches the first line of an rST comment: ".." alone, or followed by text that isn't a
+// directive, hyperlink target, substitution definition, footnote, or citation.
+var rstCommentPattern = regexp.MustCompile(`^(\s*)\.\.(\s*$|\s+([^\s_|\[].*)?$)`)
+
+// rstDirectivePattern matches the text after ".. " for directives such as ".. code-block:: go".
+var rstDirectivePattern = regexp.MustCompile(`^\S+::`)
+
+// NormalizeContent removes the differences the options ignore from a file's contents.
+//
+// With IgnoreComments, comments are removed using the comment syntax of the file's
+// extension: rST comments for .rst and .txt files, and line and block comments for
+// common programming languages. Files with other extensions keep their comments.
+// With IgnoreWhitespace, each line is trimmed, runs of spaces and tabs are collapsed
+// to a single space, and blank lines are dropped.
+//
+// Parameters:
+//   - content: The file contents
+//   - filePath: The file path, used to detect the comment syntax
+//   - opts: The differences to ignore
+//
+// Returns:
+//   - string: The normalized contents
+func NormalizeContent(content, filePath string, opts DiffOptions) string {
+	if opts.IgnoreComments {
+		content = stripComments(content, strings.ToLower(filepath.Ext(filePath)))
+	}
+	if opts.IgnoreWhitespace {
+		content = collapseWhitespace(content)
+	}
+	return content
+}
+
+// stripComments removes the comments from content, using the comment syntax for the extension.
+func stripComments(content, ext string) string {
+	if ext == ".rst" || ext == ".txt" {
+		return stripRSTComments(content)
+	}
+	syntax, ok := commentSyntaxByExtension[ext]
+	if !ok {
+		return content
+	}
+	return stripCodeComments(content, syntax)
+}
+
+// stripRSTComments removes rST comments: a ".." line that isn't a directive or target,
+// and the lines indented under it.
+func stripRSTComments(content string) string {
+	lines := strings.Split(content, "\n")
+	var kept []string
+	commentIndent := -1
+	for _, line := range lines {
+		if commentIndent >= 0 {
+			trimmed := strings.TrimLeft(line, " \t")
+			if trimmed == "" || len(line)-len(trimmed) > commentIndent {
+				continue
+			}
+			commentIndent = -1
+		}
+		if match := rstCommentPattern.FindStringSubmatch(line); match != nil && !rstDirectivePattern.MatchString(match[3]) {
+			commentIndent = len(match[1])
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// commentMark stands in for a removed comment until the lines it was on are cleaned up.
+const commentMark = '\x00'
+
+// stripCodeComments removes line and block comments from source code, leaving string
+// literals alone. Lines that held only a comment are dropped, and trailing whitespace
+// left before a comment is trimmed, so comment-only changes don't leave differences.
+func stripCodeComments(content string, syntax commentSyntax) string {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if quote != 0 {
+			b.WriteByte(c)
+			if c == '\\' && i+1 < len(content) {
+				i++
+				b.WriteByte(content[i])
+			} else if c == quote || (c == '\n' && quote != '`') {
+				quote = 0
+			}
+			continue
+		}
+		if strings.IndexByte(syntax.quotes, c) >= 0 {
+			quote = c
+			b.WriteByte(c)
+			continue
+		}
+		if syntax.blockStart != "" && strings.HasPrefix(content[i:], syntax.blockStart) {
+			comment := content[i:]
+			if end := strings.Index(content[i+len(syntax.blockStart):], syntax.blockEnd); end >= 0 {
+				comment = content[i : i+len(syntax.blockStart)+end+len(syntax.blockEnd)]
+			}
+			// Mark every line the comment spans
+			b.WriteByte(commentMark)
+			b.WriteString(strings.Repeat("\n"+string(commentMark), strings.Count(comment, "\n")))
+			i += len(comment) - 1
+			continue
+		}
+		if startsLineComment(content[i:], syntax) {
+			b.WriteByte(commentMark)
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				break
+			}
+			i += end - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	var kept []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if strings.IndexByte(line, commentMark) < 0 {
+			kept = append(kept, line)
+			continue
+		}
+		line = strings.TrimRight(strings.ReplaceAll(line, string(commentMark), ""), " \t\r")
+		if strings.TrimSpace(line) != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// startsLineComment reports whether text starts with one of the syntax's line comment markers.
+func startsLineComment(text string, syntax commentSyntax) bool {
+	for _, marker := range syntax.line {
+		if strings.HasPrefix(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// collapseWhitespace trims each line, collapses runs of spaces and tabs, and drops blank lines.
+func collapseWhitespace(content string) string {
+	var kept []string
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		kept = append(kept, strings.Join(fields, " "))
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return strings.Join(kept, "\n") + "\n"
+}
@@ -1,8 +1,9 @@
 package file_contents
 
 import (
-	"fmt"
 	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 )
 
 // PrintComparisonResult prints the comparison result with progressive detail levels.
@@ -13,91 +14,116 @@ import (
 //   - showDiff: Summary + paths + diffs
 //
 // Parameters:
+//   - w: The output writer
 //   - result: The comparison result to print
 //   - showPaths: If true, show file paths
 //   - showDiff: If true, show diffs (implies showPaths)
-func PrintComparisonResult(result *ComparisonResult, showPaths bool, showDiff bool) {
+func PrintComparisonResult(w *output.Writer, result *ComparisonResult, showPaths bool, showDiff bool) {
 	// If showDiff is true, we also need to show paths
 	if showDiff {
 		showPaths = true
 	}
 
 	// Print summary
-	printSummary(result)
+	printSummary(w, result)
 
 	// Print paths if requested
 	if showPaths {
-		fmt.Println()
-		printPaths(result)
+		w.Println()
+		printPaths(w, result)
 	}
 
 	// Print diffs if requested
 	if showDiff {
-		fmt.Println()
-		printDiffs(result)
+		w.Println()
+		printDiffs(w, result)
 	}
 }
 
 // printSummary prints a summary of the comparison results.
-func printSummary(result *ComparisonResult) {
+func printSummary(w *output.Writer, result *ComparisonResult) {
 	if result.ReferenceVersion != "" {
 		// Version comparison mode
-		fmt.Printf("Comparing file across %d versions...\n", result.TotalFiles)
+		w.Printf("Comparing file across %d versions...\n", result.TotalFiles)
 	} else {
 		// Direct comparison mode
-		fmt.Println("Comparing files...")
+		w.Println("Comparing files...")
+	}
+	if result.Options.Enabled() {
+		w.Printf("Ignoring differences in %s\n", ignoredDifferences(result.Options))
 	}
 
 	if result.AllMatch() {
 		// All files match
-		fmt.Printf("✓ All versions match (%d/%d files identical)\n", result.MatchingFiles, result.TotalFiles)
+		w.Printf("%s All versions match (%d/%d files identical)\n", w.Colorize("✓", output.Green), result.MatchingFiles, result.TotalFiles)
+		printIgnoredCount(w, result)
 	} else if result.HasDifferences() {
 		// Some files differ
-		fmt.Printf("⚠ Differences found: %d of %d versions differ", result.DifferingFiles, result.TotalFiles)
+		w.Printf("%s Differences found: %d of %d versions differ", w.Colorize("⚠", output.Yellow), result.DifferingFiles, result.TotalFiles)
 		if result.ReferenceVersion != "" {
-			fmt.Printf(" from %s\n", result.ReferenceVersion)
+			w.Printf(" from %s\n", result.ReferenceVersion)
 		} else {
-			fmt.Println()
+			w.Println()
 		}
 
 		// Show breakdown
 		if result.MatchingFiles > 0 {
-			fmt.Printf("  - %d version(s) match\n", result.MatchingFiles)
+			w.Printf("  - %d version(s) match\n", result.MatchingFiles)
+			printIgnoredCount(w, result)
 		}
 		if result.DifferingFiles > 0 {
-			fmt.Printf("  - %d version(s) differ\n", result.DifferingFiles)
+			w.Printf("  - %d version(s) differ\n", result.DifferingFiles)
 		}
 		if result.NotFoundFiles > 0 {
-			fmt.Printf("  - %d version(s) not found (file does not exist)\n", result.NotFoundFiles)
+			w.Printf("  - %d version(s) not found (file does not exist)\n", result.NotFoundFiles)
 		}
 		if result.ErrorFiles > 0 {
-			fmt.Printf("  - %d version(s) had errors\n", result.ErrorFiles)
+			w.Printf("  - %d version(s) had errors\n", result.ErrorFiles)
 		}
 
 		// Show hints (only in version comparison mode)
 		if result.ReferenceVersion != "" {
-			fmt.Println()
-			fmt.Println("Use --show-paths to see which files differ")
-			fmt.Println("Use --show-diff to see the differences")
+			w.Println()
+			w.Println("Use --show-paths to see which files differ")
+			w.Println("Use --show-diff to see the differences")
 		} else {
 			// Direct comparison mode - only show diff hint
-			fmt.Println()
-			fmt.Println("Use --show-diff to see the differences")
+			w.Println()
+			w.Println("Use --show-diff to see the differences")
 		}
 	} else if result.NotFoundFiles > 0 || result.ErrorFiles > 0 {
 		// No differences, but some files not found or had errors
-		fmt.Printf("✓ No differences found among existing files\n")
+		w.Printf("%s No differences found among existing files\n", w.Colorize("✓", output.Green))
 		if result.NotFoundFiles > 0 {
-			fmt.Printf("  - %d version(s) not found (file does not exist)\n", result.NotFoundFiles)
+			w.Printf("  - %d version(s) not found (file does not exist)\n", result.NotFoundFiles)
 		}
 		if result.ErrorFiles > 0 {
-			fmt.Printf("  - %d version(s) had errors\n", result.ErrorFiles)
+			w.Printf("  - %d version(s) had errors\n", result.ErrorFiles)
 		}
 	}
 }
 
+// printIgnoredCount prints how many of the matching files differ only in ignored differences.
+func printIgnoredCount(w *output.Writer, result *ComparisonResult) {
+	if result.IgnoredDifferenceFiles > 0 {
+		w.Printf("    (%d only after ignoring differences in %s)\n", result.IgnoredDifferenceFiles, ignoredDifferences(result.Options))
+	}
+}
+
+// ignoredDifferences describes the differences the options ignore, such as "whitespace and comments".
+func ignoredDifferences(opts DiffOptions) string {
+	var ignored []string
+	if opts.IgnoreWhitespace {
+		ignored = append(ignored, "whitespace")
+	}
+	if opts.IgnoreComments {
+		ignored = append(ignored, "comments")
+	}
+	return strings.Join(ignored, " and ")
+}
+
 // printPaths prints the file paths grouped by status.
-func printPaths(result *ComparisonResult) {
+func printPaths(w *output.Writer, result *ComparisonResult) {
 	// Group comparisons by status
 	var matching, differing, notFound, errors []FileComparison
 	for _, comp := range result.Comparisons {
@@ -115,12 +141,14 @@ func printPaths(result *ComparisonResult) {
 
 	// Print matching files
 	if len(matching) > 0 {
-		fmt.Println("Files that match:")
+		w.Println("Files that match:")
 		for _, comp := range matching {
 			if comp.Version == result.ReferenceVersion {
-				fmt.Printf("  ✓ %s (reference)\n", comp.FilePath)
+				w.Printf("  %s %s (reference)\n", w.Colorize("✓", output.Green), comp.FilePath)
+			} else if comp.IgnoredDifferences {
+				w.Printf("  %s %s (ignored differences)\n", w.Colorize("✓", output.Green), comp.FilePath)
 			} else {
-				fmt.Printf("  ✓ %s\n", comp.FilePath)
+				w.Printf("  %s %s\n", w.Colorize("✓", output.Green), comp.FilePath)
 			}
 		}
 	}
@@ -128,39 +156,39 @@ func printPaths(result *ComparisonResult) {
 	// Print differing files
 	if len(differing) > 0 {
 		if len(matching) > 0 {
-			fmt.Println()
+			w.Println()
 		}
-		fmt.Println("Files that differ:")
+		w.Println("Files that differ:")
 		for _, comp := range differing {
-			fmt.Printf("  ✗ %s\n", comp.FilePath)
+			w.Printf("  %s %s\n", w.Colorize("✗", output.Red), comp.FilePath)
 		}
 	}
 
 	// Print not found files
 	if len(notFound) > 0 {
 		if len(matching) > 0 || len(differing) > 0 {
-			fmt.Println()
+			w.Println()
 		}
-		fmt.Println("Files not found:")
+		w.Println("Files not found:")
 		for _, comp := range notFound {
-			fmt.Printf("  - %s\n", comp.FilePath)
+			w.Printf("  - %s\n", comp.FilePath)
 		}
 	}
 
 	// Print error files
 	if len(errors) > 0 {
 		if len(matching) > 0 || len(differing) > 0 || len(notFound) > 0 {
-			fmt.Println()
+			w.Println()
 		}
-		fmt.Println("Files with errors:")
+		w.Println("Files with errors:")
 		for _, comp := range errors {
-			fmt.Printf("  ⚠ %s: %v\n", comp.FilePath, comp.Error)
+			w.Printf("  %s %s: %v\n", w.Colorize("⚠", output.Yellow), comp.FilePath, comp.Error)
 		}
 	}
 }
 
 // printDiffs prints the unified diffs for files that differ.
-func printDiffs(result *ComparisonResult) {
+func printDiffs(w *output.Writer, result *ComparisonResult) {
 	// Find files with diffs
 	var diffsToShow []FileComparison
 	for _, comp := range result.Comparisons {
@@ -173,31 +201,56 @@ func printDiffs(result *ComparisonResult) {
 		return
 	}
 
-	fmt.Println("Diffs:")
-	fmt.Println(strings.Repeat("=", 80))
+	w.Println("Diffs:")
+	w.Println(strings.Repeat("=", 80))
 
 	for i, comp := range diffsToShow {
 		if i > 0 {
-			fmt.Println()
+			w.Println()
 		}
 
 		// Print header
 		if result.ReferenceVersion != "" {
-			fmt.Printf("Diff: %s vs %s\n", result.ReferenceVersion, comp.Version)
+			w.Printf("Diff: %s vs %s\n", result.ReferenceVersion, comp.Version)
 		} else {
-			fmt.Printf("Diff: %s\n", comp.Version)
+			w.Printf("Diff: %s\n", comp.Version)
 		}
-		fmt.Println(strings.Repeat("-", 80))
+		w.Println(strings.Repeat("-", 80))
 
 		// Print the diff
-		fmt.Print(comp.Diff)
+		printDiff(w, comp.Diff)
 
 		// Ensure there's a newline at the end
 		if !strings.HasSuffix(comp.Diff, "\n") {
-			fmt.Println()
+			w.Println()
 		}
 	}
 
-	fmt.Println(strings.Repeat("=", 80))
+	w.Println(strings.Repeat("=", 80))
 }
 
+// printDiff prints a unified diff, coloring added lines green and removed lines red
+// when color is enabled.
+func printDiff(w *output.Writer, diff string) {
+	lines := strings.SplitAfter(diff, "\n")
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			w.Print(line)
+		case strings.HasPrefix(line, "+"):
+			w.Print(colorizeLine(w, line, output.Green))
+		case strings.HasPrefix(line, "-"):
+			w.Print(colorizeLine(w, line, output.Red))
+		case strings.HasPrefix(line, "@@"):
+			w.Print(colorizeLine(w, line, output.Cyan))
+		default:
+			w.Print(line)
+		}
+	}
+}
+
+// colorizeLine colors a line without including its trailing newline in the colored text.
+func colorizeLine(w *output.Writer, line string, color output.Color) string {
+	trimmed := strings.TrimSuffix(line, "\n")
+	return w.Colorize(trimmed, color) + line[len(trimmed):]
+}
@@ -31,6 +31,19 @@ func (s FileStatus) String() string {
 	}
 }
 
+// DiffOptions controls which differences a comparison ignores.
+type DiffOptions struct {
+	// IgnoreWhitespace ignores differences in indentation, spacing, and blank lines
+	IgnoreWhitespace bool
+	// IgnoreComments ignores differences in comments
+	IgnoreComments bool
+}
+
+// Enabled returns true if any differences are ignored.
+func (o DiffOptions) Enabled() bool {
+	return o.IgnoreWhitespace || o.IgnoreComments
+}
+
 // FileComparison represents the comparison result for a single file.
 type FileComparison struct {
 	// Version is the version identifier (e.g., "v8.0", "upcoming")
@@ -41,8 +54,11 @@ type FileComparison struct {
 	Status FileStatus
 	// Error is any error encountered (only set if Status == FileError)
 	Error error
-	// Diff is the unified diff output (only set if Status == FileDiffers and diff was requested)
+	// Diff is the unified diff output (only set if Status == FileDiffers and diff was requested).
+	// When differences are ignored, it's the diff of the normalized contents.
 	Diff string
+	// IgnoredDifferences is true if the file matches only because the diff options ignored its differences
+	IgnoredDifferences bool
 }
 
 // ComparisonResult represents the overall comparison result.
@@ -63,6 +79,10 @@ type ComparisonResult struct {
 	NotFoundFiles int
 	// ErrorFiles is the number of files with errors
 	ErrorFiles int
+	// IgnoredDifferenceFiles is the number of matching files whose only differences were ignored
+	IgnoredDifferenceFiles int
+	// Options are the diff options the comparison used
+	Options DiffOptions
 }
 
 // HasDifferences returns true if any files differ from the reference.
@@ -0,0 +1,61 @@
+package rendered_output
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/aymanbagabas/go-udiff"
+)
+
+// CompareRendered renders two pages and compares the rendered output.
+//
+// Parameters:
+//   - file1: Path to the first page
+//   - file2: Path to the second page
+//   - renderer: The renderer to use for both pages
+//   - verbose: If true, show detailed processing information
+//
+// Returns:
+//   - *RenderedComparison: The comparison result
+//   - error: Any error encountered reading or rendering the pages
+func CompareRendered(file1, file2 string, renderer Renderer, verbose bool) (*RenderedComparison, error) {
+	source1, err := os.ReadFile(file1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", file1, err)
+	}
+	source2, err := os.ReadFile(file2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", file2, err)
+	}
+
+	if verbose {
+		fmt.Printf("Rendering with %s renderer:\n", renderer.Name())
+		fmt.Printf("  File 1: %s\n", file1)
+		fmt.Printf("  File 2: %s\n", file2)
+	}
+
+	rendered1, err := renderer.Render(file1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", file1, err)
+	}
+	rendered2, err := renderer.Render(file2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", file2, err)
+	}
+
+	result := &RenderedComparison{
+		File1:             file1,
+		File2:             file2,
+		Renderer:          renderer.Name(),
+		SourceIdentical:   bytes.Equal(source1, source2),
+		RenderedIdentical: rendered1 == rendered2,
+		Rendered1:         rendered1,
+		Rendered2:         rendered2,
+	}
+	if !result.RenderedIdentical {
+		result.Diff = udiff.Unified(file1, file2, rendered1, rendered2)
+	}
+
+	return result, nil
+}
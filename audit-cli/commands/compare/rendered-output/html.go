@@ -0,0 +1,555 @@
+package rendered_output
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/projectinfo"
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+var (
+	// Matches: .. directive-name:: argument
+	directiveRegex = regexp.MustCompile(`^\.\.\s+([\w:-]+)::\s*(.*)$`)
+
+	// Matches: .. _label-name:
+	labelRegex = regexp.MustCompile(`^\.\.\s+_([^:]+):\s*$`)
+
+	// Matches: .. |name| replace:: text
+	substitutionDefRegex = regexp.MustCompile(`^\.\.\s+\|([^|]+)\|\s+replace::\s*(.*)$`)
+
+	// Matches directive options and field list items: :name: value
+	fieldRegex = regexp.MustCompile(`^:([^:` + "`" + `]+):(?:\s+(.*))?$`)
+
+	// Matches bullet list items: - item, * item, + item
+	bulletRegex = regexp.MustCompile(`^([-*+])\s+`)
+
+	// Matches enumerated list items: 1. item, #. item, a) item
+	enumeratedRegex = regexp.MustCompile(`^(\d+|#|[a-zA-Z])[.)]\s+`)
+
+	// Matches source constants: {+name+}
+	constantRegex = regexp.MustCompile(`\{\+([^+]+)\+\}`)
+
+	// Matches inline markup, in order of precedence: literals, roles, links, strong,
+	// emphasis, and substitution references
+	inlineRegex = regexp.MustCompile("``(.+?)``" +
+		"|:([\\w:-]+):`([^`]+)`" +
+		"|`([^`<]*?)\\s*<([^>`]+)>`__?" +
+		`|\*\*(.+?)\*\*` +
+		`|\*([^*\s][^*]*?)\*` +
+		`|\|([^|\s][^|]*?)\|`)
+
+	// Matches a role's explicit title and target: title <target>
+	roleTargetRegex = regexp.MustCompile(`^(.*?)\s*<([^>]+)>$`)
+)
+
+// codeDirectives are rendered as preformatted text instead of being parsed as RST.
+var codeDirectives = map[string]bool{
+	"code-block": true,
+	"code":       true,
+	"sourcecode": true,
+}
+
+// htmlRenderer renders expanded RST lines to a minimal HTML document.
+//
+// The output isn't meant to look like the published page. It's meant to contain
+// everything that affects what readers see (text, headings, code, directive options,
+// and link targets) one element per line, so two renders can be diffed. Whitespace
+// that doesn't affect the rendered page, such as how paragraphs are wrapped, is
+// normalized away.
+type htmlRenderer struct {
+	filePath      string
+	config        *projectinfo.SnootyConfig
+	substitutions map[string]string
+	headingStyles []string
+	out           []string
+}
+
+// RenderRST renders a page to minimal HTML.
+//
+// Include directives are expanded, source constants ({+name+}) and substitutions
+// (|name|) are resolved from the project's snooty.toml and the page itself, and
+// literalinclude directives are replaced with the content of the included file.
+//
+// Parameters:
+//   - filePath: Path to the RST page to render
+//
+// Returns:
+//   - string: The rendered HTML
+//   - error: Any error encountered reading the page or its snooty.toml
+func RenderRST(filePath string) (string, error) {
+	lines, err := rst.ExpandIncludes(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	config, err := projectinfo.LoadSnootyConfig(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	return renderLines(filePath, lines, config), nil
+}
+
+// renderLines renders already-expanded lines with the given project config.
+func renderLines(filePath string, lines []string, config *projectinfo.SnootyConfig) string {
+	r := &htmlRenderer{
+		filePath:      filePath,
+		config:        config,
+		substitutions: make(map[string]string),
+	}
+	for key, value := range config.Substitutions {
+		r.substitutions[key] = value
+	}
+
+	resolved := make([]string, len(lines))
+	for i, line := range lines {
+		resolved[i] = r.resolveConstants(strings.TrimRight(line, " \t\r"))
+	}
+
+	// Substitutions apply to the whole page, including text before their definition
+	for _, line := range resolved {
+		if matches := substitutionDefRegex.FindStringSubmatch(strings.TrimSpace(line)); matches != nil {
+			r.substitutions[matches[1]] = strings.TrimSpace(matches[2])
+		}
+	}
+
+	r.renderBlocks(resolved)
+	return strings.Join(r.out, "\n") + "\n"
+}
+
+// resolveConstants replaces {+name+} with the value from snooty.toml. Unknown
+// constants are left as-is so they show up in the diff.
+func (r *htmlRenderer) resolveConstants(line string) string {
+	return constantRegex.ReplaceAllStringFunc(line, func(match string) string {
+		name := constantRegex.FindStringSubmatch(match)[1]
+		if value, ok := r.config.Constants[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+func (r *htmlRenderer) emit(line string) {
+	r.out = append(r.out, line)
+}
+
+// renderBlocks renders a sequence of body elements. Lines are expected to be dedented
+// so that top-level elements start at column 0.
+func (r *htmlRenderer) renderBlocks(lines []string) {
+	i := 0
+	for i < len(lines) {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			i++
+			continue
+		}
+
+		// Indented text at the top level is a block quote
+		if indentOf(line) > 0 {
+			end := blockEnd(lines, i, 1)
+			r.emit("<blockquote>")
+			r.renderBlocks(dedent(lines[i:end]))
+			r.emit("</blockquote>")
+			i = end
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "..") {
+			i = r.renderExplicitMarkup(lines, i)
+			continue
+		}
+
+		if level, text, next, ok := r.matchHeading(lines, i); ok {
+			r.emit(fmt.Sprintf("<h%d>%s</h%d>", level, r.renderInline(text, true), level))
+			i = next
+			continue
+		}
+
+		if isUnderline(trimmed) && utf8.RuneCountInString(trimmed) >= 4 {
+			r.emit("<hr>")
+			i++
+			continue
+		}
+
+		if listTag, _ := listMarker(trimmed); listTag != "" {
+			i = r.renderList(lines, i, listTag)
+			continue
+		}
+
+		if matches := fieldRegex.FindStringSubmatch(trimmed); matches != nil {
+			end := blockEnd(lines, i+1, 1)
+			value := joinParagraph(append([]string{matches[2]}, lines[i+1:end]...))
+			r.emit(fmt.Sprintf(`<p class="field"><strong>%s</strong>: %s</p>`, html.EscapeString(matches[1]), r.renderInline(value, true)))
+			i = end
+			continue
+		}
+
+		i = r.renderParagraph(lines, i)
+	}
+}
+
+// renderParagraph renders a paragraph and, if it ends with "::", the literal block after it.
+func (r *htmlRenderer) renderParagraph(lines []string, start int) int {
+	end := start
+	for end < len(lines) && strings.TrimSpace(lines[end]) != "" && indentOf(lines[end]) == 0 {
+		end++
+	}
+	text := joinParagraph(lines[start:end])
+
+	literal := strings.HasSuffix(text, "::")
+	if literal {
+		// "Paragraph::" renders as "Paragraph:", while "Paragraph ::" and a bare "::" drop the colons
+		if text == "::" || strings.HasSuffix(text, " ::") {
+			text = strings.TrimSpace(strings.TrimSuffix(text, "::"))
+		} else {
+			text = strings.TrimSuffix(text, ":")
+		}
+	}
+	if text != "" {
+		r.emit("<p>" + r.renderInline(text, true) + "</p>")
+	}
+
+	if !literal {
+		return end
+	}
+	bodyStart := end
+	for bodyStart < len(lines) && strings.TrimSpace(lines[bodyStart]) == "" {
+		bodyStart++
+	}
+	if bodyStart >= len(lines) || indentOf(lines[bodyStart]) == 0 {
+		return end
+	}
+	bodyEnd := blockEnd(lines, bodyStart, 1)
+	r.emitPre("literal-block", nil, strings.Join(dedent(lines[bodyStart:bodyEnd]), "\n"))
+	return bodyEnd
+}
+
+// renderExplicitMarkup renders a line starting with "..": a directive, a label, a
+// substitution definition, or a comment.
+func (r *htmlRenderer) renderExplicitMarkup(lines []string, start int) int {
+	trimmed := strings.TrimSpace(lines[start])
+	end := blockEnd(lines, start+1, 1)
+
+	if matches := labelRegex.FindStringSubmatch(trimmed); matches != nil {
+		r.emit(fmt.Sprintf(`<span id="%s"></span>`, html.EscapeString(matches[1])))
+		return start + 1
+	}
+
+	if matches := substitutionDefRegex.FindStringSubmatch(trimmed); matches != nil {
+		r.substitutions[matches[1]] = joinParagraph(append([]string{matches[2]}, lines[start+1:end]...))
+		return end
+	}
+
+	matches := directiveRegex.FindStringSubmatch(trimmed)
+	if matches == nil {
+		// Comments don't render
+		return end
+	}
+
+	name, argument := matches[1], strings.TrimSpace(matches[2])
+	options, content := splitOptions(dedent(lines[start+1 : end]))
+	r.renderDirective(name, argument, options, content)
+	return end
+}
+
+// renderDirective renders a directive with its argument, options, and content.
+func (r *htmlRenderer) renderDirective(name, argument string, options map[string]string, content []string) {
+	switch {
+	case codeDirectives[name]:
+		attrs := copyOptions(options)
+		if argument != "" {
+			attrs["language"] = argument
+		}
+		r.emitPre(name, attrs, strings.Join(content, "\n"))
+
+	case name == string(rst.LiteralInclude) || ((name == "input" || name == "output") && argument != ""):
+		code, err := rst.ExtractLiteralIncludeContent(r.filePath, rst.Directive{
+			Type:     rst.LiteralInclude,
+			Argument: argument,
+			Options:  options,
+		})
+		attrs := copyOptions(options)
+		if err != nil {
+			attrs["error"] = err.Error()
+		}
+		r.emitPre(name, attrs, code)
+
+	case name == "include":
+		// Includes that couldn't be expanded are kept so the unresolved path is visible
+		r.emit(fmt.Sprintf(`<div class="include" data-path="%s"></div>`, html.EscapeString(argument)))
+
+	case name == "toctree":
+		r.emit(`<nav class="toctree"` + formatAttributes(options) + ">")
+		for _, entry := range content {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				r.emit("<li>" + html.EscapeString(entry) + "</li>")
+			}
+		}
+		r.emit("</nav>")
+
+	default:
+		r.emit(fmt.Sprintf(`<div class="%s"%s>`, html.EscapeString(name), formatAttributes(options)))
+		if argument != "" {
+			r.emit(`<p class="directive-argument">` + r.renderInline(argument, true) + "</p>")
+		}
+		r.renderBlocks(content)
+		r.emit("</div>")
+	}
+}
+
+// renderList renders consecutive list items of the same kind as one list.
+func (r *htmlRenderer) renderList(lines []string, start int, listTag string) int {
+	r.emit("<" + listTag + ">")
+	i := start
+	for i < len(lines) {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" {
+			i++
+			continue
+		}
+		tag, width := listMarker(trimmed)
+		if indentOf(lines[i]) != 0 || tag != listTag {
+			break
+		}
+
+		// Item content is aligned with the text after the marker
+		end := blockEnd(lines, i+1, width)
+		item := []string{lines[i][width:]}
+		for _, itemLine := range lines[i+1 : end] {
+			if len(itemLine) >= width {
+				itemLine = itemLine[width:]
+			}
+			item = append(item, itemLine)
+		}
+		r.emit("<li>")
+		r.renderBlocks(item)
+		r.emit("</li>")
+		i = end
+	}
+	r.emit("</" + listTag + ">")
+	return i
+}
+
+// matchHeading reports whether a heading starts at lines[i], with or without an
+// overline. Heading levels follow the order in which underline styles first appear,
+// the same way RST assigns them.
+func (r *htmlRenderer) matchHeading(lines []string, i int) (int, string, int, bool) {
+	text := strings.TrimSpace(lines[i])
+	style := ""
+	next := i + 2
+
+	if isUnderline(text) && i+2 < len(lines) && strings.TrimSpace(lines[i+2]) == text {
+		// Overlined heading
+		style = "over" + text[:1]
+		text = strings.TrimSpace(lines[i+1])
+		next = i + 3
+	} else if i+1 < len(lines) && !isUnderline(text) {
+		underline := strings.TrimSpace(lines[i+1])
+		if !isUnderline(underline) || indentOf(lines[i+1]) != 0 ||
+			utf8.RuneCountInString(underline) < utf8.RuneCountInString(text) {
+			return 0, "", 0, false
+		}
+		style = underline[:1]
+	} else {
+		return 0, "", 0, false
+	}
+
+	level := 0
+	for index, existing := range r.headingStyles {
+		if existing == style {
+			level = index + 1
+			break
+		}
+	}
+	if level == 0 {
+		r.headingStyles = append(r.headingStyles, style)
+		level = len(r.headingStyles)
+	}
+	if level > 6 {
+		level = 6
+	}
+	return level, text, next, true
+}
+
+// renderInline renders inline markup in a line of text and escapes everything else.
+func (r *htmlRenderer) renderInline(text string, substitute bool) string {
+	var result strings.Builder
+	last := 0
+	for _, m := range inlineRegex.FindAllStringSubmatchIndex(text, -1) {
+		result.WriteString(html.EscapeString(text[last:m[0]]))
+		last = m[1]
+
+		group := func(n int) string {
+			if m[2*n] < 0 {
+				return ""
+			}
+			return text[m[2*n]:m[2*n+1]]
+		}
+
+		switch {
+		case m[2] >= 0:
+			result.WriteString("<code>" + html.EscapeString(group(1)) + "</code>")
+		case m[4] >= 0:
+			role, value := group(2), group(3)
+			title, target := value, value
+			if matches := roleTargetRegex.FindStringSubmatch(value); matches != nil {
+				title, target = matches[1], matches[2]
+				if title == "" {
+					title = target
+				}
+			}
+			result.WriteString(fmt.Sprintf(`<span class="%s" data-target="%s">%s</span>`,
+				html.EscapeString(role), html.EscapeString(target), html.EscapeString(title)))
+		case m[10] >= 0:
+			title, url := group(4), group(5)
+			if title == "" {
+				title = url
+			}
+			result.WriteString(fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(title)))
+		case m[12] >= 0:
+			result.WriteString("<strong>" + html.EscapeString(group(6)) + "</strong>")
+		case m[14] >= 0:
+			result.WriteString("<em>" + html.EscapeString(group(7)) + "</em>")
+		case m[16] >= 0:
+			name := group(8)
+			value, ok := r.substitutions[name]
+			if !substitute || !ok {
+				// Unknown substitutions are kept so they show up in the diff
+				result.WriteString(html.EscapeString(text[m[0]:m[1]]))
+				continue
+			}
+			result.WriteString(r.renderInline(value, false))
+		}
+	}
+	result.WriteString(html.EscapeString(text[last:]))
+	return result.String()
+}
+
+// emitPre writes a preformatted block. Content is escaped but otherwise kept as-is.
+func (r *htmlRenderer) emitPre(class string, attrs map[string]string, content string) {
+	r.emit(fmt.Sprintf(`<pre class="%s"%s>`, html.EscapeString(class), formatAttributes(attrs)))
+	for _, line := range strings.Split(strings.Trim(content, "\n"), "\n") {
+		r.emit(html.EscapeString(line))
+	}
+	r.emit("</pre>")
+}
+
+// splitOptions separates a directive's leading option lines from its content.
+func splitOptions(body []string) (map[string]string, []string) {
+	options := make(map[string]string)
+	i := 0
+	for i < len(body) {
+		matches := fieldRegex.FindStringSubmatch(strings.TrimSpace(body[i]))
+		if matches == nil || indentOf(body[i]) != 0 {
+			break
+		}
+		options[matches[1]] = strings.TrimSpace(matches[2])
+		i++
+	}
+	return options, body[i:]
+}
+
+// formatAttributes formats options as data attributes, sorted by name so the output is stable.
+func formatAttributes(attrs map[string]string) string {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result strings.Builder
+	for _, key := range keys {
+		result.WriteString(fmt.Sprintf(` data-%s="%s"`, html.EscapeString(key), html.EscapeString(attrs[key])))
+	}
+	return result.String()
+}
+
+func copyOptions(options map[string]string) map[string]string {
+	attrs := make(map[string]string, len(options))
+	for key, value := range options {
+		attrs[key] = value
+	}
+	return attrs
+}
+
+// listMarker returns the list tag ("ul" or "ol") and marker width for a list item line.
+func listMarker(line string) (string, int) {
+	if match := bulletRegex.FindString(line); match != "" {
+		return "ul", len(match)
+	}
+	if match := enumeratedRegex.FindString(line); match != "" {
+		return "ol", len(match)
+	}
+	return "", 0
+}
+
+// blockEnd returns the index after the last line of an indented block starting at
+// start. The block continues through blank lines and lines indented at least minIndent.
+func blockEnd(lines []string, start int, minIndent int) int {
+	end := start
+	last := start
+	for end < len(lines) {
+		if strings.TrimSpace(lines[end]) == "" {
+			end++
+			continue
+		}
+		if indentOf(lines[end]) < minIndent {
+			break
+		}
+		end++
+		last = end
+	}
+	return last
+}
+
+// dedent removes the common leading whitespace from non-blank lines.
+func dedent(lines []string) []string {
+	minIndent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if indent := indentOf(line); minIndent == -1 || indent < minIndent {
+			minIndent = indent
+		}
+	}
+
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= minIndent && minIndent > 0 {
+			result[i] = line[minIndent:]
+		} else {
+			result[i] = strings.TrimSpace(line)
+		}
+	}
+	return result
+}
+
+// joinParagraph joins wrapped lines into a single line of text.
+func joinParagraph(lines []string) string {
+	var parts []string
+	for _, line := range lines {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			parts = append(parts, trimmed)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// isUnderline reports whether a line is a run of a single RST section adornment character.
+func isUnderline(line string) bool {
+	if len(line) < 2 || !strings.ContainsRune("=-~`^\"'+*#:.", rune(line[0])) {
+		return false
+	}
+	return strings.Count(line, line[:1]) == len(line)
+}
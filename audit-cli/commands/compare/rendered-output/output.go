@@ -0,0 +1,62 @@
+package rendered_output
+
+import (
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// PrintRenderedComparison prints a summary of the comparison and, if requested, the
+// diff of the rendered output.
+//
+// Parameters:
+//   - w: The output writer
+//   - result: The comparison result to print
+//   - showDiff: If true, show the unified diff of the rendered HTML
+func PrintRenderedComparison(w *output.Writer, result *RenderedComparison, showDiff bool) {
+	w.Printf("Comparing rendered output (%s renderer)...\n", result.Renderer)
+	w.Printf("  File 1: %s\n", result.File1)
+	w.Printf("  File 2: %s\n", result.File2)
+	w.Println()
+
+	switch {
+	case result.SourceIdentical && result.RenderedIdentical:
+		w.Printf("%s Files are identical\n", w.Colorize("✓", output.Green))
+	case result.OnlySourceDiffers():
+		w.Printf("%s Rendered output matches\n", w.Colorize("✓", output.Green))
+		w.Println("  The source files differ, but only in ways that don't change the rendered page")
+	case result.OnlyRenderedDiffers():
+		w.Printf("%s Rendered output differs, but the source files are identical\n", w.Colorize("⚠", output.Yellow))
+		w.Println("  The differences come from included files, source constants, or literalinclude files")
+	default:
+		w.Printf("%s Rendered output differs\n", w.Colorize("⚠", output.Yellow))
+	}
+
+	if result.RenderedIdentical {
+		return
+	}
+
+	if !showDiff {
+		w.Println()
+		w.Println("Use --show-diff to see the differences")
+		return
+	}
+
+	w.Println()
+	w.Println("Diff:")
+	w.Println(strings.Repeat("-", 80))
+	for _, line := range strings.Split(strings.TrimSuffix(result.Diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			w.Println(line)
+		case strings.HasPrefix(line, "+"):
+			w.Println(w.Colorize(line, output.Green))
+		case strings.HasPrefix(line, "-"):
+			w.Println(w.Colorize(line, output.Red))
+		case strings.HasPrefix(line, "@@"):
+			w.Println(w.Colorize(line, output.Cyan))
+		default:
+			w.Println(line)
+		}
+	}
+}
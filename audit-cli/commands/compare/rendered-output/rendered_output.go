@@ -0,0 +1,111 @@
+// Package rendered_output provides functionality for comparing the rendered output of two pages.
+//
+// This package implements the "compare rendered-output" subcommand, which renders two
+// versions of a page to HTML and diffs the result. Unlike a raw RST diff, the rendered
+// diff includes the content of included files, resolved source constants and
+// substitutions, and literalinclude content, so it catches differences that come from
+// outside the page itself.
+//
+// Rendering uses a minimal embedded renderer by default, or an external build command
+// with --render-command.
+//
+// This command is experimental.
+package rendered_output
+
+import (
+	"fmt"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// NewRenderedOutputCommand creates the rendered-output subcommand.
+//
+// Usage:
+//
+//	compare rendered-output file1.txt file2.txt
+//
+// Flags:
+//   - --render-command: Render with an external command instead of the embedded renderer
+//   - -d, --show-diff: Display a unified diff of the rendered HTML
+//   - -v, --verbose: Show detailed processing information
+//   - --output-file: Write results to a file instead of stdout
+func NewRenderedOutputCommand() *cobra.Command {
+	var (
+		renderCommand string
+		showDiff      bool
+		verbose       bool
+		outputOpts    output.Options
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rendered-output [file1] [file2]",
+		Short: "Compare the rendered HTML of two pages (experimental)",
+		Long: `Render two versions of a page to HTML and compare the rendered output.
+
+A raw diff of two RST files misses differences that come from outside the page:
+included files, source constants ({+name+}) from each project's snooty.toml,
+substitutions, and literalinclude files. This command renders each page with
+those resolved, then compares the results.
+
+By default, pages are rendered with a minimal embedded renderer. Its HTML isn't
+the published page, but it contains the text, headings, code, directive options,
+and link targets, one element per line. Changes that don't affect the rendered
+page, such as re-wrapping a paragraph, don't show up as differences.
+
+To compare the output of a real build instead, pass --render-command. The command
+is run once per page and must write HTML to stdout. Use {file} where the page path
+goes; if {file} isn't present, the path is appended as the last argument.
+
+This command is experimental.
+
+Examples:
+  # Compare a page across two versions
+  compare rendered-output manual/manual/source/page.txt manual/v8.0/source/page.txt
+
+  # Show the rendered diff
+  compare rendered-output file1.txt file2.txt --show-diff
+
+  # Render with an external build script
+  compare rendered-output file1.txt file2.txt --render-command "./render-page.sh {file}"`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRenderedOutput(args[0], args[1], renderCommand, showDiff, verbose, outputOpts)
+		},
+	}
+
+	cmd.Flags().StringVar(&renderCommand, "render-command", "", "Render pages with this command instead of the embedded renderer ({file} is replaced with the page path)")
+	cmd.Flags().BoolVarP(&showDiff, "show-diff", "d", false, "Display unified diff of the rendered output")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed processing information")
+	output.AddFileFlags(cmd, &outputOpts)
+
+	return cmd
+}
+
+// runRenderedOutput executes the comparison and prints the result.
+//
+// Parameters:
+//   - file1: Path to the first page
+//   - file2: Path to the second page
+//   - renderCommand: External render command (empty for the embedded renderer)
+//   - showDiff: If true, show the rendered diff
+//   - verbose: If true, show detailed processing information
+//   - outputOpts: Output destination
+//
+// Returns:
+//   - error: Any error encountered during comparison
+func runRenderedOutput(file1, file2, renderCommand string, showDiff, verbose bool, outputOpts output.Options) error {
+	result, err := CompareRendered(file1, file2, NewRenderer(renderCommand), verbose)
+	if err != nil {
+		return fmt.Errorf("comparison failed: %w", err)
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	PrintRenderedComparison(w, result, showDiff)
+	return nil
+}
@@ -0,0 +1,133 @@
+package rendered_output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/projectinfo"
+)
+
+const renderedTestDataDir = "../../../testdata/compare/rendered"
+
+// TestCompareRenderedFindsDifferencesOutsideThePage tests that identical pages with different
+// includes and constants are reported as rendered differences
+func TestCompareRenderedFindsDifferencesOutsideThePage(t *testing.T) {
+	result, err := CompareRendered(
+		renderedTestDataDir+"/v1/source/page.txt",
+		renderedTestDataDir+"/v2/source/page.txt",
+		EmbeddedRenderer{}, false)
+	if err != nil {
+		t.Fatalf("CompareRendered failed: %v", err)
+	}
+
+	if !result.OnlyRenderedDiffers() {
+		t.Errorf("expected identical source and different rendered output, got source identical=%v rendered identical=%v",
+			result.SourceIdentical, result.RenderedIdentical)
+	}
+
+	expectedChanges := []string{
+		"-<p>Install MongoDB Product 7.0 with the installer.</p>",
+		"+<p>Install MongoDB Product 8.0 with the installer.</p>",
+		"+<p>Older releases need an <strong>automatic</strong> upgrade.</p>",
+		"+./install --version 8.0",
+	}
+	for _, expected := range expectedChanges {
+		if !strings.Contains(result.Diff, expected) {
+			t.Errorf("expected diff to contain %q, got:\n%s", expected, result.Diff)
+		}
+	}
+
+	if !strings.Contains(result.Rendered1, "print(&#34;hello&#34;)") {
+		t.Errorf("expected literalinclude content in rendered output, got:\n%s", result.Rendered1)
+	}
+	if strings.Contains(result.Rendered1, "comment") {
+		t.Errorf("expected comments not to render, got:\n%s", result.Rendered1)
+	}
+}
+
+// TestCompareRenderedIgnoresWrapping tests that re-wrapping a paragraph and adding a comment
+// don't count as rendered differences
+func TestCompareRenderedIgnoresWrapping(t *testing.T) {
+	result, err := CompareRendered(
+		renderedTestDataDir+"/v1/source/wrapped.txt",
+		renderedTestDataDir+"/v2/source/wrapped.txt",
+		EmbeddedRenderer{}, false)
+	if err != nil {
+		t.Fatalf("CompareRendered failed: %v", err)
+	}
+
+	if !result.OnlySourceDiffers() {
+		t.Errorf("expected different source and identical rendered output, got source identical=%v rendered identical=%v",
+			result.SourceIdentical, result.RenderedIdentical)
+	}
+	if result.Diff != "" {
+		t.Errorf("expected no diff, got:\n%s", result.Diff)
+	}
+}
+
+// TestRenderLines tests the embedded renderer's handling of individual constructs
+func TestRenderLines(t *testing.T) {
+	config := &projectinfo.SnootyConfig{
+		Constants:     map[string]string{"version": "8.0"},
+		Substitutions: map[string]string{},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "heading levels follow underline order",
+			input:    "Title\n=====\n\nSection\n-------\n\nOther\n=====",
+			expected: "<h1>Title</h1>\n<h2>Section</h2>\n<h1>Other</h1>\n",
+		},
+		{
+			name:     "inline markup",
+			input:    "Use ``find()`` with :ref:`queries <query-page>` and **care**.",
+			expected: `<p>Use <code>find()</code> with <span class="ref" data-target="query-page">queries</span> and <strong>care</strong>.</p>` + "\n",
+		},
+		{
+			name:     "unknown constants are kept",
+			input:    "Version {+version+} of {+missing+}",
+			expected: "<p>Version 8.0 of {+missing+}</p>\n",
+		},
+		{
+			name:     "substitutions defined after use",
+			input:    "Run |cmd|.\n\n.. |cmd| replace:: ``mongosh``",
+			expected: "<p>Run <code>mongosh</code>.</p>\n",
+		},
+		{
+			name:     "directive options and content",
+			input:    ".. tab:: Shell\n   :tabid: shell\n\n   Connect first.",
+			expected: `<div class="tab" data-tabid="shell">` + "\n" + `<p class="directive-argument">Shell</p>` + "\n<p>Connect first.</p>\n</div>\n",
+		},
+		{
+			name:     "bullet list",
+			input:    "- one\n- two\n  continued",
+			expected: "<ul>\n<li>\n<p>one</p>\n</li>\n<li>\n<p>two continued</p>\n</li>\n</ul>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderLines("page.txt", strings.Split(tt.input, "\n"), config)
+			if got != tt.expected {
+				t.Errorf("renderLines() =\n%s\nwant:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCommandRendererAppendsFilePath tests that the page path is appended when the command
+// doesn't use {file}
+func TestCommandRendererAppendsFilePath(t *testing.T) {
+	path := renderedTestDataDir + "/v1/source/wrapped.txt"
+	rendered, err := CommandRenderer{Command: "cat"}.Render(path)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.HasPrefix(rendered, "Wrapping\n") {
+		t.Errorf("expected the command's stdout, got:\n%s", rendered)
+	}
+}
@@ -0,0 +1,88 @@
+package rendered_output
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// FilePlaceholder is replaced with the page path in a --render-command.
+const FilePlaceholder = "{file}"
+
+// Renderer renders a documentation page to HTML.
+type Renderer interface {
+	// Name describes the renderer in output
+	Name() string
+	// Render returns the rendered HTML for the page at filePath
+	Render(filePath string) (string, error)
+}
+
+// NewRenderer returns the embedded renderer, or a CommandRenderer if command is set.
+func NewRenderer(command string) Renderer {
+	if strings.TrimSpace(command) == "" {
+		return EmbeddedRenderer{}
+	}
+	return CommandRenderer{Command: command}
+}
+
+// EmbeddedRenderer renders pages with the minimal built-in RST renderer.
+//
+// It resolves includes, source constants, substitutions, and literalincludes, but
+// doesn't know about every Snooty directive or role. Use a CommandRenderer when you
+// need the output of a real build.
+type EmbeddedRenderer struct{}
+
+// Name returns "embedded".
+func (EmbeddedRenderer) Name() string {
+	return "embedded"
+}
+
+// Render renders the page with RenderRST.
+func (EmbeddedRenderer) Render(filePath string) (string, error) {
+	return RenderRST(filePath)
+}
+
+// CommandRenderer renders pages by running an external build command, such as a
+// wrapper script around a local Snooty parser and frontend build.
+//
+// The command is split on whitespace and run without a shell. Each {file} argument is
+// replaced with the page path; if there's no {file} argument, the path is appended.
+// The command must write the rendered HTML to stdout.
+type CommandRenderer struct {
+	Command string
+}
+
+// Name returns the command.
+func (c CommandRenderer) Name() string {
+	return c.Command
+}
+
+// Render runs the command for the page and returns its stdout.
+func (c CommandRenderer) Render(filePath string) (string, error) {
+	args := strings.Fields(c.Command)
+	if len(args) == 0 {
+		return "", fmt.Errorf("render command is empty")
+	}
+
+	replaced := false
+	for i, arg := range args {
+		if strings.Contains(arg, FilePlaceholder) {
+			args[i] = strings.ReplaceAll(arg, FilePlaceholder, filePath)
+			replaced = true
+		}
+	}
+	if !replaced {
+		args = append(args, filePath)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("render command failed for %s: %w\n%s", filePath, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
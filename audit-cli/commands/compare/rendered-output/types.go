@@ -0,0 +1,34 @@
+package rendered_output
+
+// RenderedComparison is the result of comparing the rendered output of two pages.
+type RenderedComparison struct {
+	// File1 is the path to the first page
+	File1 string
+	// File2 is the path to the second page
+	File2 string
+	// Renderer is the name of the renderer used
+	Renderer string
+	// SourceIdentical is true if the raw RST files are byte-for-byte identical
+	SourceIdentical bool
+	// RenderedIdentical is true if the rendered HTML is identical
+	RenderedIdentical bool
+	// Diff is the unified diff of the rendered HTML (empty if identical)
+	Diff string
+	// Rendered1 is the rendered HTML for File1
+	Rendered1 string
+	// Rendered2 is the rendered HTML for File2
+	Rendered2 string
+}
+
+// OnlyRenderedDiffers returns true if the source files are identical but the rendered
+// output differs. These are the differences a raw diff misses: they come from included
+// files, source constants, or literalinclude files.
+func (c *RenderedComparison) OnlyRenderedDiffers() bool {
+	return c.SourceIdentical && !c.RenderedIdentical
+}
+
+// OnlySourceDiffers returns true if the source files differ but render the same, such
+// as when only paragraph wrapping or comments changed.
+func (c *RenderedComparison) OnlySourceDiffers() bool {
+	return !c.SourceIdentical && c.RenderedIdentical
+}
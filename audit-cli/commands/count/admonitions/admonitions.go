@@ -0,0 +1,109 @@
+// Package admonitions implements the admonitions subcommand for counting admonition directives.
+//
+// This package counts note, important, warning, tip, and other admonition directives in the
+// documentation monorepo by file and by directory, and flags the files with more admonitions per
+// line than a configurable threshold. It supports style audits of over-noted pages.
+package admonitions
+
+import (
+	"fmt"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// NewAdmonitionsCommand creates the admonitions subcommand.
+//
+// This command counts admonition directives by directory and flags dense files.
+//
+// Usage:
+//
+//	count admonitions /path/to/docs-monorepo
+//	count admonitions /path/to/docs-monorepo --for-project manual
+//	count admonitions /path/to/docs-monorepo --dense-pages --max-density 5
+//
+// Flags:
+//   - --for-project: Only count admonitions for a specific project
+//   - --dense-pages: List each file over the density threshold
+//   - --max-density: Most admonitions per 100 non-blank lines before a file is flagged (default: 3)
+//   - --min-admonitions: Fewest admonitions a file must have to be flagged (default: 3)
+//   - --format: Output format (text, json, csv, or markdown)
+//   - --output-file: Write results to a file instead of stdout
+func NewAdmonitionsCommand() *cobra.Command {
+	var (
+		forProject string
+		densePages bool
+		threshold  Threshold
+		outputOpts output.Options
+	)
+
+	cmd := &cobra.Command{
+		Use:   "admonitions [directory-path]",
+		Short: "Count admonition directives and flag pages with too many",
+		Long: `Count admonition directives in the MongoDB documentation monorepo by directory.
+
+This command navigates to the content directory and scans the .txt and .rst files in each
+project (the directories under content/, except code-examples). It counts note, important,
+warning, and tip admonitions, and other typed admonitions such as caution, danger, example,
+and seealso. Admonitions shown inside a code-block or io-code-block aren't counted. Includes
+are counted as files of their own.
+
+A file's density is its admonitions per 100 non-blank lines. A file is over the threshold
+if its density is over --max-density and it has at least --min-admonitions admonitions, so
+short pages with a single note aren't flagged.
+
+By default, shows the counts for each directory and how many of its files are over the
+threshold. With --dense-pages, lists every file over the threshold, densest first.
+
+Examples:
+  # Count admonitions by directory
+  count admonitions /path/to/docs-monorepo
+
+  # Count admonitions for a specific project
+  count admonitions /path/to/docs-monorepo --for-project manual
+
+  # List the files with more than 5 admonitions per 100 lines
+  count admonitions /path/to/docs-monorepo --dense-pages --max-density 5
+
+  # List every file with at least one admonition as CSV
+  count admonitions /path/to/docs-monorepo --dense-pages --max-density 0 --min-admonitions 1 --format csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAdmonitions(args[0], forProject, densePages, threshold, outputOpts)
+		},
+	}
+
+	cmd.Flags().StringVar(&forProject, "for-project", "", "Only count admonitions for a specific project")
+	cmd.Flags().BoolVar(&densePages, "dense-pages", false, "List each file over the density threshold")
+	cmd.Flags().Float64Var(&threshold.MaxDensity, "max-density", 3, "Most admonitions per 100 non-blank lines before a file is flagged")
+	cmd.Flags().IntVar(&threshold.MinAdmonitions, "min-admonitions", 3, "Fewest admonitions a file must have to be flagged")
+	output.AddFlags(cmd, &outputOpts)
+
+	return cmd
+}
+
+// runAdmonitions executes the admonition counting operation.
+func runAdmonitions(dirPath string, forProject string, densePages bool, threshold Threshold, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+	if threshold.MaxDensity < 0 {
+		return fmt.Errorf("--max-density must not be negative")
+	}
+	if threshold.MinAdmonitions < 1 {
+		return fmt.Errorf("--min-admonitions must be at least 1")
+	}
+
+	result, err := CountAdmonitions(dirPath, forProject, threshold)
+	if err != nil {
+		return fmt.Errorf("failed to count admonitions: %w", err)
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return PrintResults(w, result, densePages)
+}
@@ -0,0 +1,127 @@
+// Package admonitions provides tests for the admonition counting functionality.
+package admonitions
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+var testDataDir = filepath.Join("..", "..", "..", "testdata", "count-admonitions")
+
+var defaultThreshold = Threshold{MaxDensity: 3, MinAdmonitions: 3}
+
+// TestCountAdmonitions tests counting admonitions by file and directory.
+func TestCountAdmonitions(t *testing.T) {
+	result, err := CountAdmonitions(testDataDir, "", defaultThreshold)
+	if err != nil {
+		t.Fatalf("CountAdmonitions failed: %v", err)
+	}
+
+	// The code-examples directory at the root of content is skipped, as are admonitions in code blocks
+	if result.TotalCount != 9 {
+		t.Errorf("Expected total count 9, got %d", result.TotalCount)
+	}
+	if len(result.Files) != 5 {
+		t.Fatalf("Expected 5 files, got %d", len(result.Files))
+	}
+
+	crud := result.Files[3]
+	if crud.File != "manual/source/tutorial/crud.txt" {
+		t.Fatalf("Expected files in path order, got %s at index 3", crud.File)
+	}
+	expectedTypes := map[string]int{"note": 1, "important": 1, "warning": 1, "tip": 1}
+	for admonitionType, count := range expectedTypes {
+		if crud.ByType[admonitionType] != count {
+			t.Errorf("Expected %d %s in crud.txt, got %d", count, admonitionType, crud.ByType[admonitionType])
+		}
+	}
+	if crud.Lines != 19 {
+		t.Errorf("Expected 19 non-blank lines in crud.txt, got %d", crud.Lines)
+	}
+
+	tutorial := result.DirectoryCounts["manual/source/tutorial"]
+	if tutorial == nil {
+		t.Fatalf("Expected counts for manual/source/tutorial, got %v", result.DirectoryCounts)
+	}
+	if tutorial.Files != 2 || tutorial.Total != 5 || tutorial.Dense != 1 {
+		t.Errorf("Unexpected manual/source/tutorial counts: %+v", tutorial)
+	}
+	if index := result.DirectoryCounts["manual/source"]; index == nil || index.ByType[OtherType] != 1 {
+		t.Errorf("Expected seealso to count as other in manual/source, got %+v", index)
+	}
+
+	if len(result.Dense) != 1 || result.Dense[0] != crud {
+		t.Errorf("Expected only crud.txt over the threshold, got %+v", result.Dense)
+	}
+}
+
+// TestCountAdmonitionsForProject tests filtering by project.
+func TestCountAdmonitionsForProject(t *testing.T) {
+	result, err := CountAdmonitions(testDataDir, "drivers", defaultThreshold)
+	if err != nil {
+		t.Fatalf("CountAdmonitions failed: %v", err)
+	}
+
+	if result.TotalCount != 1 {
+		t.Errorf("Expected total count 1, got %d", result.TotalCount)
+	}
+	if len(result.DirectoryCounts) != 1 || result.DirectoryCounts["drivers/source"] == nil {
+		t.Errorf("Expected only drivers/source in the counts, got %v", result.DirectoryCounts)
+	}
+}
+
+// TestThresholdExceeded tests which files are flagged for having too many admonitions.
+func TestThresholdExceeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     FileCounts
+		expected bool
+	}{
+		{"dense", FileCounts{Lines: 50, Total: 3}, true},
+		{"sparse", FileCounts{Lines: 200, Total: 3}, false},
+		{"at the threshold", FileCounts{Lines: 100, Total: 3}, false},
+		{"short page with few admonitions", FileCounts{Lines: 10, Total: 2}, false},
+		{"empty", FileCounts{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultThreshold.Exceeded(&tt.file); got != tt.expected {
+				t.Errorf("Exceeded() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestPrintResultsDensePages tests listing the files over the threshold as CSV.
+func TestPrintResultsDensePages(t *testing.T) {
+	result, err := CountAdmonitions(testDataDir, "", Threshold{MaxDensity: 20, MinAdmonitions: 1})
+	if err != nil {
+		t.Fatalf("CountAdmonitions failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := PrintResults(output.NewWriter(&buf, output.FormatCSV), result, true); err != nil {
+		t.Fatalf("PrintResults failed: %v", err)
+	}
+
+	expected := "Project,File,Lines,Total,Note,Important,Warning,Tip,Other,Per 100 Lines\n" +
+		"manual,manual/source/includes/note-auth.rst,2,1,1,0,0,0,0,50.0\n" +
+		"manual,manual/source/index.txt,8,2,1,0,0,0,1,25.0\n" +
+		"manual,manual/source/tutorial/crud.txt,19,4,1,1,1,1,0,21.1\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected CSV output:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+
+	buf.Reset()
+	if err := PrintResults(output.NewWriter(&buf, output.FormatText), result, false); err != nil {
+		t.Fatalf("PrintResults failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Total: 9 admonitions in 5 files; 3 of 5 files over 20.0 admonitions per 100 lines") {
+		t.Errorf("Expected totals in the footer, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,194 @@
+// Package admonitions provides counting functionality for admonition directives.
+package admonitions
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/projectinfo"
+)
+
+// admonitionRegex matches an admonition directive at any indentation, capturing the indentation and type.
+// Untyped ".. admonition::" directives aren't counted; they're reported by analyze deprecated-directives.
+var admonitionRegex = regexp.MustCompile(`^(\s*)\.\.\s+(note|important|warning|tip|caution|danger|example|seealso|see)::`)
+
+// literalBlockRegex matches the directives whose indented content is code, where an admonition is
+// an example rather than part of the page.
+var literalBlockRegex = regexp.MustCompile(`^(\s*)\.\.\s+(code-block|code|sourcecode|io-code-block|input|output)::`)
+
+// CountAdmonitions counts admonition directives in the .txt and .rst files in the content directory, by
+// file and by directory, and flags the files over the threshold.
+//
+// This function navigates to the content directory from the monorepo root. The code-examples
+// directory at the root of content is skipped. Includes are counted as files of their own rather
+// than as part of the pages that include them.
+//
+// Parameters:
+//   - dirPath: Path to the monorepo root or content directory
+//   - forProject: If non-empty, only count admonitions for this project
+//   - threshold: Decides which files are flagged for having too many admonitions
+//
+// Returns:
+//   - *CountResult: The counting results
+//   - error: Any error encountered during counting
+func CountAdmonitions(dirPath string, forProject string, threshold Threshold) (*CountResult, error) {
+	absDirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	if _, err := os.Stat(absDirPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", absDirPath)
+	}
+
+	contentDir, err := projectinfo.FindContentDirectory(absDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CountResult{
+		DirectoryCounts: make(map[string]*DirectoryCounts),
+		Threshold:       threshold,
+		ContentDir:      contentDir,
+	}
+	err = filepath.Walk(contentDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(contentDir, filePath)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		if info.IsDir() {
+			// Example files at the root of content aren't part of any project's pages
+			if filepath.Dir(filePath) == contentDir && info.Name() == "code-examples" {
+				return filepath.SkipDir
+			}
+			// Only walk the requested project
+			if forProject != "" && filepath.Dir(filePath) == contentDir && info.Name() != forProject {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ext := strings.ToLower(filepath.Ext(filePath))
+		if ext != ".txt" && ext != ".rst" {
+			return nil
+		}
+		relPath = filepath.ToSlash(relPath)
+		project, _, inProject := strings.Cut(relPath, "/")
+		if !inProject {
+			// File is directly in content directory, not in a project
+			return nil
+		}
+
+		file, err := countFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+		file.File = relPath
+		file.Project = project
+		result.add(file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk content directory: %w", err)
+	}
+
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].File < result.Files[j].File
+	})
+	for _, file := range result.Files {
+		if threshold.Exceeded(file) {
+			result.Dense = append(result.Dense, file)
+		}
+	}
+	sort.SliceStable(result.Dense, func(i, j int) bool {
+		return result.Dense[i].Density() > result.Dense[j].Density()
+	})
+	return result, nil
+}
+
+// add records a file's counts in the totals for its directory.
+func (r *CountResult) add(file *FileCounts) {
+	r.Files = append(r.Files, file)
+	r.TotalCount += file.Total
+
+	dir := path.Dir(file.File)
+	counts, ok := r.DirectoryCounts[dir]
+	if !ok {
+		counts = &DirectoryCounts{ByType: make(map[string]int)}
+		r.DirectoryCounts[dir] = counts
+	}
+	counts.Files++
+	counts.Total += file.Total
+	for admonitionType, n := range file.ByType {
+		counts.ByType[admonitionType] += n
+	}
+	if r.Threshold.Exceeded(file) {
+		counts.Dense++
+	}
+}
+
+// countFile counts the admonitions and non-blank lines in a file. Admonitions in the content of a
+// code-block or io-code-block are skipped.
+func countFile(filePath string) (*FileCounts, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	file := &FileCounts{ByType: make(map[string]int)}
+	literalIndent := -1 // Indentation of the open code directive, or -1 if none is open
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		file.Lines++
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if literalIndent >= 0 {
+			if indent > literalIndent {
+				continue
+			}
+			literalIndent = -1
+		}
+
+		if match := literalBlockRegex.FindStringSubmatch(line); match != nil {
+			literalIndent = len(match[1])
+			continue
+		}
+		if match := admonitionRegex.FindStringSubmatch(line); match != nil {
+			file.Total++
+			file.ByType[columnFor(match[2])]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// columnFor returns the column an admonition type is counted in.
+func columnFor(admonitionType string) string {
+	for _, t := range AdmonitionTypes {
+		if t == admonitionType {
+			return t
+		}
+	}
+	return OtherType
+}
@@ -0,0 +1,115 @@
+// Package admonitions provides output formatting for admonition counts.
+package admonitions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// typeColumns are the admonition type columns, in output order.
+var typeColumns = append(append([]string{}, AdmonitionTypes...), OtherType)
+
+// PrintResults writes the counting results.
+//
+// If densePages is true, writes one row per file over the density threshold, densest first.
+// Otherwise, writes the counts for each directory broken down by admonition type.
+//
+// Parameters:
+//   - w: The output writer, which determines the format and destination
+//   - result: The counting results
+//   - densePages: If true, list the files over the threshold
+func PrintResults(w *output.Writer, result *CountResult, densePages bool) error {
+	if densePages {
+		return printDense(w, result)
+	}
+	return printByDirectory(w, result)
+}
+
+// printByDirectory writes the counts for each directory.
+func printByDirectory(w *output.Writer, result *CountResult) error {
+	if len(result.DirectoryCounts) == 0 && w.Format() == output.FormatText {
+		w.Println("No files found")
+		return nil
+	}
+
+	var dirs []string
+	for dir := range result.DirectoryCounts {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	columns := []output.Column{
+		{Header: "Directory"},
+		{Header: "Files", Align: output.AlignRight},
+		{Header: "Total", Align: output.AlignRight},
+	}
+	for _, admonitionType := range typeColumns {
+		columns = append(columns, output.Column{Header: typeHeader(admonitionType), Align: output.AlignRight})
+	}
+	columns = append(columns, output.Column{Header: "Over Threshold", Align: output.AlignRight})
+
+	table := output.NewTable("Admonitions by Directory:", columns...)
+	files, dense := 0, 0
+	for _, dir := range dirs {
+		counts := result.DirectoryCounts[dir]
+		row := []interface{}{dir, counts.Files, counts.Total}
+		for _, admonitionType := range typeColumns {
+			row = append(row, counts.ByType[admonitionType])
+		}
+		row = append(row, counts.Dense)
+		table.AddRow(row...)
+		files += counts.Files
+		dense += counts.Dense
+	}
+	table.Footer = fmt.Sprintf("Total: %d admonitions in %d files; %d of %d files over %s",
+		result.TotalCount, files, dense, files, describeThreshold(result.Threshold))
+
+	return w.WriteTable(table)
+}
+
+// printDense writes the files over the density threshold.
+func printDense(w *output.Writer, result *CountResult) error {
+	if len(result.Dense) == 0 && w.Format() == output.FormatText {
+		w.Printf("No files over %s\n", describeThreshold(result.Threshold))
+		return nil
+	}
+
+	columns := []output.Column{
+		{Header: "Project"},
+		{Header: "File"},
+		{Header: "Lines", Align: output.AlignRight},
+		{Header: "Total", Align: output.AlignRight},
+	}
+	for _, admonitionType := range typeColumns {
+		columns = append(columns, output.Column{Header: typeHeader(admonitionType), Align: output.AlignRight})
+	}
+	columns = append(columns, output.Column{Header: "Per 100 Lines", Align: output.AlignRight})
+
+	table := output.NewTable("Files Over the Admonition Density Threshold:", columns...)
+	for _, file := range result.Dense {
+		row := []interface{}{file.Project, file.File, file.Lines, file.Total}
+		for _, admonitionType := range typeColumns {
+			row = append(row, file.ByType[admonitionType])
+		}
+		row = append(row, fmt.Sprintf("%.1f", file.Density()))
+		table.AddRow(row...)
+	}
+	table.Footer = fmt.Sprintf("%d of %d files over %s",
+		len(result.Dense), len(result.Files), describeThreshold(result.Threshold))
+
+	return w.WriteTable(table)
+}
+
+// describeThreshold describes the density threshold for table footers.
+func describeThreshold(threshold Threshold) string {
+	return fmt.Sprintf("%.1f admonitions per 100 lines (with at least %d admonitions)",
+		threshold.MaxDensity, threshold.MinAdmonitions)
+}
+
+// typeHeader returns the column header for an admonition type.
+func typeHeader(admonitionType string) string {
+	return strings.ToUpper(admonitionType[:1]) + admonitionType[1:]
+}
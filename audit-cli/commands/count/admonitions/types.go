@@ -0,0 +1,73 @@
+// Package admonitions provides functionality for counting admonition directives.
+package admonitions
+
+// AdmonitionTypes are the admonition types with a column of their own, in output order. Other types
+// are counted as OtherType.
+var AdmonitionTypes = []string{"note", "important", "warning", "tip"}
+
+// OtherType is the column for the admonition types not in AdmonitionTypes, such as caution or seealso.
+const OtherType = "other"
+
+// FileCounts holds the admonition counts for one file.
+type FileCounts struct {
+	// File is the path of the file, relative to the content directory
+	File string
+	// Project is the project directory under content
+	Project string
+	// Lines is the number of non-blank lines in the file
+	Lines int
+	// Total is the number of admonitions in the file
+	Total int
+	// ByType maps each admonition type, or OtherType, to its count
+	ByType map[string]int
+}
+
+// Density returns the file's admonitions per 100 non-blank lines.
+func (f *FileCounts) Density() float64 {
+	if f.Lines == 0 {
+		return 0
+	}
+	return float64(f.Total) * 100 / float64(f.Lines)
+}
+
+// DirectoryCounts holds the admonition counts for the files directly in one directory.
+type DirectoryCounts struct {
+	// Files is the number of files scanned in the directory
+	Files int
+	// Total is the number of admonitions in the directory's files
+	Total int
+	// ByType maps each admonition type, or OtherType, to its count
+	ByType map[string]int
+	// Dense is the number of the directory's files over the density threshold
+	Dense int
+}
+
+// Threshold decides which files have too many admonitions.
+type Threshold struct {
+	// MaxDensity is the most admonitions per 100 non-blank lines a file can have without being flagged
+	MaxDensity float64
+	// MinAdmonitions is the fewest admonitions a file must have to be flagged, so a short page with
+	// one note isn't
+	MinAdmonitions int
+}
+
+// Exceeded reports whether the file has too many admonitions.
+func (t Threshold) Exceeded(file *FileCounts) bool {
+	return file.Total >= t.MinAdmonitions && file.Density() > t.MaxDensity
+}
+
+// CountResult represents the result of counting admonitions.
+type CountResult struct {
+	// TotalCount is the total number of admonitions counted
+	TotalCount int
+	// Files lists the counts for each file scanned, in path order
+	Files []*FileCounts
+	// DirectoryCounts maps directory paths, relative to the content directory, to their counts
+	DirectoryCounts map[string]*DirectoryCounts
+	// Dense lists the files over the density threshold, densest first
+	Dense []*FileCounts
+	// Threshold is the density threshold the files were checked against
+	Threshold Threshold
+	// ContentDir is the path to the content directory
+	ContentDir string
+}
@@ -0,0 +1,186 @@
+// Package code_examples implements the code-examples subcommand for counting code examples.
+//
+// This package counts code-example directives (code-block, literalinclude, and
+// io-code-block) in reStructuredText files. It can also sample a git repository's
+// history at monthly intervals to produce a trend of example counts over time,
+// without needing the code example metrics database.
+package code_examples
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// NewCodeExamplesCommand creates the code-examples subcommand.
+//
+// This command counts code-example directives in a file or directory.
+//
+// Usage:
+//
+//	count code-examples /path/to/source
+//	count code-examples /path/to/source --count-by-directive
+//	count code-examples /path/to/source --trend --since 2024-01
+//	count code-examples /path/to/source --trend --since 2024-01 --until 2024-12 --output-file trend.csv
+//
+// Flags:
+//   - --count-by-directive: Display counts for each directive type
+//   - --trend: Count examples at monthly intervals in the git history and output CSV
+//   - --since: First month to sample, in YYYY-MM format (requires --trend)
+//   - --until: Last month to sample, in YYYY-MM format (default: current month)
+//   - --ref: Git ref whose history to sample (default: HEAD)
+//   - --format: Output format (text, json, csv, or markdown; default csv with --trend)
+//   - --output-file: Write results to a file instead of stdout
+//   - -v, --verbose: Show progress while sampling history
+func NewCodeExamplesCommand() *cobra.Command {
+	var (
+		countByDirective bool
+		trend            bool
+		since            string
+		until            string
+		ref              string
+		legacyOutput     string
+		verbose          bool
+		outputOpts       output.Options
+	)
+
+	cmd := &cobra.Command{
+		Use:   "code-examples [filepath]",
+		Short: "Count code examples in reStructuredText files",
+		Long: `Count code examples in reStructuredText files.
+
+This command scans a file, or a directory recursively, and counts the following
+code-example directives:
+  - .. code-block::
+  - .. literalinclude::
+  - .. io-code-block::
+
+By default, returns only a total count of all code examples.
+
+With --trend, the path must be inside a git repository. The command samples the
+repository at the start of each month from --since to --until, extracts the path
+as it was at the last commit before that date (using git archive, so the working
+tree is never changed), and counts the examples at each point. The result is a
+CSV with one row per month, unless --format is set. This is useful for repos the code example metrics
+database doesn't track.
+
+Examples:
+  # Get total count of code examples
+  count code-examples /path/to/source
+
+  # Show counts broken down by directive type
+  count code-examples /path/to/source --count-by-directive
+
+  # Monthly trend since January 2024, printed as CSV
+  count code-examples /path/to/source --trend --since 2024-01
+
+  # Monthly trend for 2024 from the main branch, written to a file
+  count code-examples /path/to/source --trend --since 2024-01 --until 2024-12 --ref main --output-file trend.csv
+
+  # Monthly trend as a markdown table
+  count code-examples /path/to/source --trend --since 2024-01 --format markdown`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if legacyOutput != "" {
+				outputOpts.OutputFile = legacyOutput
+			}
+			if trend {
+				if !cmd.Flags().Changed("format") {
+					outputOpts.Format = string(output.FormatCSV)
+				}
+				return runTrend(args[0], since, until, ref, verbose, outputOpts)
+			}
+			if since != "" || until != "" || cmd.Flags().Changed("ref") {
+				return fmt.Errorf("--since, --until, and --ref require --trend")
+			}
+			return runCount(args[0], countByDirective, outputOpts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&countByDirective, "count-by-directive", false, "Display counts for each directive type")
+	cmd.Flags().BoolVar(&trend, "trend", false, "Count examples at monthly intervals in the git history and output CSV")
+	cmd.Flags().StringVar(&since, "since", "", "First month to sample, in YYYY-MM format (requires --trend)")
+	cmd.Flags().StringVar(&until, "until", "", "Last month to sample, in YYYY-MM format (default: current month)")
+	cmd.Flags().StringVar(&ref, "ref", "HEAD", "Git ref whose history to sample")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show progress while sampling history")
+	output.AddFlags(cmd, &outputOpts)
+	cmd.Flags().StringVarP(&legacyOutput, "output", "o", "", "Write results to this file instead of stdout")
+	_ = cmd.Flags().MarkDeprecated("output", "use --output-file instead")
+
+	return cmd
+}
+
+// runCount executes the code example counting operation.
+func runCount(path string, countByDirective bool, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
+	result, err := CountCodeExamples(path)
+	if err != nil {
+		return fmt.Errorf("failed to count code examples: %w", err)
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return PrintResults(w, result, countByDirective)
+}
+
+// runTrend executes the trend counting operation.
+func runTrend(path string, since string, until string, ref string, verbose bool, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+	if since == "" {
+		return fmt.Errorf("--trend requires --since")
+	}
+	sinceMonth, err := parseMonth(since)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	untilMonth := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.UTC)
+	if until != "" {
+		untilMonth, err = parseMonth(until)
+		if err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+	if untilMonth.Before(sinceMonth) {
+		return fmt.Errorf("--until (%s) is before --since (%s)", until, since)
+	}
+
+	points, err := CountTrend(path, ref, sinceMonth, untilMonth, verbose)
+	if err != nil {
+		return fmt.Errorf("failed to count code example trend: %w", err)
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	if err := PrintTrend(w, points); err != nil {
+		return err
+	}
+	if outputOpts.OutputFile != "" {
+		fmt.Fprintf(os.Stderr, "Wrote %d months to %s\n", len(points), outputOpts.OutputFile)
+	}
+	return nil
+}
+
+// parseMonth parses a YYYY-MM string into the first day of that month in UTC.
+func parseMonth(value string) (time.Time, error) {
+	month, err := time.Parse("2006-01", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not in YYYY-MM format", value)
+	}
+	return month, nil
+}
@@ -0,0 +1,137 @@
+// Package code_examples provides tests for the code-examples counting functionality.
+package code_examples
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// TestCountCodeExamples tests counting each directive type in the shared test files.
+func TestCountCodeExamples(t *testing.T) {
+	sourceDir := filepath.Join("..", "..", "..", "testdata", "input-files", "source")
+
+	tests := []struct {
+		file          string
+		directiveType rst.DirectiveType
+		expected      int
+	}{
+		{"code-block-test.rst", rst.CodeBlock, 7},
+		{"literalinclude-test.rst", rst.LiteralInclude, 7},
+		{"io-code-block-test.rst", rst.IoCodeBlock, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			result, err := CountCodeExamples(filepath.Join(sourceDir, tt.file))
+			if err != nil {
+				t.Fatalf("CountCodeExamples failed: %v", err)
+			}
+			if result.TotalCount != tt.expected {
+				t.Errorf("Expected total count %d, got %d", tt.expected, result.TotalCount)
+			}
+			if result.DirectiveCounts[tt.directiveType] != tt.expected {
+				t.Errorf("Expected %s count %d, got %d", tt.directiveType, tt.expected, result.DirectiveCounts[tt.directiveType])
+			}
+			if result.FilesScanned != 1 {
+				t.Errorf("Expected 1 file scanned, got %d", result.FilesScanned)
+			}
+		})
+	}
+}
+
+// TestCountTrend tests sampling a git repository's history at monthly intervals.
+func TestCountTrend(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repoDir := t.TempDir()
+	git := func(date string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoDir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+		)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+	writeFile := func(name string, content string) {
+		t.Helper()
+		path := filepath.Join(repoDir, "source", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("2024-01-15T12:00:00Z", "init", "-q")
+	// The source directory doesn't exist in the first commit
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("readme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("2024-01-15T12:00:00Z", "add", "-A")
+	git("2024-01-15T12:00:00Z", "commit", "-q", "-m", "initial")
+
+	writeFile("page.rst", ".. code-block:: python\n\n   print('one')\n")
+	git("2024-02-10T12:00:00Z", "add", "-A")
+	git("2024-02-10T12:00:00Z", "commit", "-q", "-m", "add page")
+
+	writeFile("page.rst", ".. code-block:: python\n\n   print('one')\n\nIncluded example:\n\n.. literalinclude:: /code/example.py\n   :language: python\n\nMore text.\n")
+	writeFile("other.txt", ".. code-block:: js\n\n   console.log('two')\n")
+	git("2024-03-20T12:00:00Z", "add", "-A")
+	git("2024-03-20T12:00:00Z", "commit", "-q", "-m", "add more examples")
+
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	points, err := CountTrend(filepath.Join(repoDir, "source"), "HEAD", since, until, false)
+	if err != nil {
+		t.Fatalf("CountTrend failed: %v", err)
+	}
+
+	// Each month is sampled at its first day, so a commit shows up in the following month
+	expectedTotals := []int{0, 0, 1, 3, 3}
+	if len(points) != len(expectedTotals) {
+		t.Fatalf("Expected %d points, got %d", len(expectedTotals), len(points))
+	}
+	for i, expected := range expectedTotals {
+		if points[i].Result.TotalCount != expected {
+			t.Errorf("%s: expected total %d, got %d", points[i].Month.Format("2006-01"), expected, points[i].Result.TotalCount)
+		}
+	}
+	if points[0].Commit != "" {
+		t.Errorf("Expected no commit before the repo existed, got %s", points[0].Commit)
+	}
+	if points[1].Commit == "" {
+		t.Error("Expected a commit for 2024-02 even though the path didn't exist yet")
+	}
+	if points[3].Result.DirectiveCounts[rst.LiteralInclude] != 1 {
+		t.Errorf("Expected 1 literalinclude in 2024-04, got %d", points[3].Result.DirectiveCounts[rst.LiteralInclude])
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTrendCSV(&buf, points); err != nil {
+		t.Fatalf("WriteTrendCSV failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "month,commit,commit_date,total,code-block,literalinclude,io-code-block,files" {
+		t.Errorf("Unexpected CSV header: %s", lines[0])
+	}
+	if len(lines) != len(points)+1 {
+		t.Errorf("Expected %d CSV lines, got %d", len(points)+1, len(lines))
+	}
+	if !strings.HasPrefix(lines[4], "2024-04,"+points[3].Commit+",2024-03-20,3,2,1,0,2") {
+		t.Errorf("Unexpected CSV row for 2024-04: %s", lines[4])
+	}
+}
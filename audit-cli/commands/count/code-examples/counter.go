@@ -0,0 +1,57 @@
+// Package code_examples provides counting functionality for code examples.
+package code_examples
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// CountCodeExamples counts code-example directives in a file or directory.
+//
+// Directories are scanned recursively. Only .rst, .txt, and .md files are parsed.
+// Each io-code-block counts as a single example.
+//
+// Parameters:
+//   - path: Path to the file or directory to count
+//
+// Returns:
+//   - *CountResult: The counting results
+//   - error: Any error encountered during counting
+func CountCodeExamples(path string) (*CountResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access path %s: %w", path, err)
+	}
+
+	var files []string
+	if info.IsDir() {
+		files, err = rst.TraverseDirectory(path, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to traverse directory: %w", err)
+		}
+	} else {
+		files = []string{path}
+	}
+
+	result := NewCountResult()
+	for _, file := range files {
+		if !rst.ShouldProcessFile(file) {
+			continue
+		}
+
+		directives, err := rst.ParseDirectives(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		result.FilesScanned++
+		for _, directive := range directives {
+			result.DirectiveCounts[directive.Type]++
+			result.TotalCount++
+		}
+	}
+
+	return result, nil
+}
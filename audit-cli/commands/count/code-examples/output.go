@@ -0,0 +1,80 @@
+// Package code_examples provides output formatting for code example counts.
+package code_examples
+
+import (
+	"io"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// PrintResults writes the counting results.
+//
+// If countByDirective is true, writes a breakdown by directive type.
+// Otherwise, writes only the total count.
+//
+// Parameters:
+//   - w: The output writer, which determines the format and destination
+//   - result: The counting results
+//   - countByDirective: If true, show breakdown by directive type
+func PrintResults(w *output.Writer, result *CountResult, countByDirective bool) error {
+	if !countByDirective {
+		if w.Format() == output.FormatText {
+			w.Println(result.TotalCount)
+			return nil
+		}
+		table := output.NewTable("", output.Column{Header: "Total", Align: output.AlignRight})
+		table.AddRow(result.TotalCount)
+		return w.WriteTable(table)
+	}
+
+	table := output.NewTable("Directive Counts:",
+		output.Column{Header: "Directive"},
+		output.Column{Header: "Count", Align: output.AlignRight},
+	)
+	for _, directiveType := range CountedDirectives {
+		table.AddRow(string(directiveType), result.DirectiveCounts[directiveType])
+	}
+	table.Footer = "Total: " + output.FormatValue(result.TotalCount) + " (in " + output.FormatValue(result.FilesScanned) + " files)"
+	return w.WriteTable(table)
+}
+
+// PrintTrend writes trend points with one row per month.
+//
+// Columns: month, commit, commit_date, total, one column per directive type, and files.
+func PrintTrend(w *output.Writer, points []TrendPoint) error {
+	return w.WriteTable(trendTable(points))
+}
+
+// WriteTrendCSV writes trend points as CSV with a header row.
+func WriteTrendCSV(w io.Writer, points []TrendPoint) error {
+	return output.NewWriter(w, output.FormatCSV).WriteTable(trendTable(points))
+}
+
+// trendTable builds the table of trend points.
+func trendTable(points []TrendPoint) *output.Table {
+	columns := []output.Column{
+		{Header: "month"},
+		{Header: "commit"},
+		{Header: "commit_date"},
+		{Header: "total", Align: output.AlignRight},
+	}
+	for _, directiveType := range CountedDirectives {
+		columns = append(columns, output.Column{Header: string(directiveType), Align: output.AlignRight})
+	}
+	columns = append(columns, output.Column{Header: "files", Align: output.AlignRight})
+	table := output.NewTable("", columns...)
+
+	for _, point := range points {
+		commitDate := ""
+		if !point.CommitDate.IsZero() {
+			commitDate = point.CommitDate.Format("2006-01-02")
+		}
+		row := []interface{}{point.Month.Format("2006-01"), point.Commit, commitDate, point.Result.TotalCount}
+		for _, directiveType := range CountedDirectives {
+			row = append(row, point.Result.DirectiveCounts[directiveType])
+		}
+		row = append(row, point.Result.FilesScanned)
+		table.AddRow(row...)
+	}
+	return table
+}
@@ -0,0 +1,181 @@
+// Package code_examples provides git history sampling for code example trends.
+package code_examples
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/gitutil"
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// CountTrend counts code examples at the start of each month from since to until.
+//
+// For each month, it finds the last commit on ref before the first day of the month,
+// extracts the path as it was at that commit with git archive, and counts the
+// examples in the extracted copy. The working tree is never changed. Months that
+// resolve to the same commit reuse the previous count.
+//
+// Parameters:
+//   - path: Path to a file or directory inside a git repository
+//   - ref: Git ref whose history to sample
+//   - since: First month to sample
+//   - until: Last month to sample
+//   - verbose: If true, print progress to stderr
+//
+// Returns:
+//   - []TrendPoint: One point per month, in chronological order
+//   - error: Any error encountered
+func CountTrend(path string, ref string, since time.Time, until time.Time, verbose bool) ([]TrendPoint, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	absPath, err = filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access path %s: %w", path, err)
+	}
+
+	gitDir := absPath
+	if info, err := os.Stat(absPath); err == nil && !info.IsDir() {
+		gitDir = filepath.Dir(absPath)
+	}
+	repoRoot, err := gitutil.Run(gitDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("--trend requires the path to be in a git repository: %w", err)
+	}
+	relPath, err := filepath.Rel(repoRoot, absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get path relative to repository: %w", err)
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	tempDir, err := os.MkdirTemp("", "audit-cli-trend-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var points []TrendPoint
+	var previous *TrendPoint
+	for month := since; !month.After(until); month = month.AddDate(0, 1, 0) {
+		point := TrendPoint{Month: month}
+
+		commit, err := gitutil.Run(repoRoot, "rev-list", "-1", "--before="+month.Format(time.RFC3339), ref)
+		if err != nil {
+			return nil, err
+		}
+		point.Commit = commit
+
+		switch {
+		case commit == "":
+			// The repository had no commits yet
+			point.Result = NewCountResult()
+		case previous != nil && previous.Commit == commit:
+			point.CommitDate = previous.CommitDate
+			point.Result = previous.Result
+		default:
+			if verbose {
+				fmt.Fprintf(os.Stderr, "Counting %s at %s\n", month.Format("2006-01"), commit[:12])
+			}
+			point.CommitDate, err = getCommitDate(repoRoot, commit)
+			if err != nil {
+				return nil, err
+			}
+			point.Result, err = countAtCommit(repoRoot, commit, relPath, tempDir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to count at %s: %w", commit, err)
+			}
+		}
+
+		points = append(points, point)
+		previous = &points[len(points)-1]
+	}
+
+	return points, nil
+}
+
+// countAtCommit extracts relPath at commit into a fresh directory under tempDir and counts it.
+// Returns zero counts if relPath didn't exist at that commit.
+func countAtCommit(repoRoot string, commit string, relPath string, tempDir string) (*CountResult, error) {
+	if relPath != "." {
+		if _, err := gitutil.Run(repoRoot, "cat-file", "-e", commit+":"+relPath); err != nil {
+			return NewCountResult(), nil
+		}
+	}
+
+	archiveArgs := []string{"archive", "--format=tar", commit}
+	if relPath != "." {
+		archiveArgs = append(archiveArgs, "--", relPath)
+	}
+	cmd := exec.Command("git", append([]string{"-C", repoRoot}, archiveArgs...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	archive, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git archive: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	extractDir := filepath.Join(tempDir, commit)
+	if err := extractRSTFiles(bytes.NewReader(archive), extractDir); err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(extractDir)
+
+	return CountCodeExamples(filepath.Join(extractDir, filepath.FromSlash(relPath)))
+}
+
+// extractRSTFiles extracts the files that CountCodeExamples would parse from a tar stream into dir.
+func extractRSTFiles(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || !rst.ShouldProcessFile(header.Name) {
+			continue
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path in archive: %s", header.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		file, err := os.Create(target)
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+		if _, err := io.Copy(file, tr); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		file.Close()
+	}
+}
+
+// getCommitDate returns the committer date of a commit.
+func getCommitDate(repoRoot string, commit string) (time.Time, error) {
+	output, err := gitutil.Run(repoRoot, "show", "-s", "--format=%ct", commit)
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse commit date %q: %w", output, err)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
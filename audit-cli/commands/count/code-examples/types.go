@@ -0,0 +1,40 @@
+// Package code_examples provides functionality for counting code examples.
+package code_examples
+
+import (
+	"time"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// CountResult represents the result of counting code examples.
+type CountResult struct {
+	// TotalCount is the total number of code examples counted
+	TotalCount int
+	// DirectiveCounts maps directive types to their counts
+	DirectiveCounts map[rst.DirectiveType]int
+	// FilesScanned is the number of RST files scanned
+	FilesScanned int
+}
+
+// TrendPoint represents the code example counts at one point in the repository history.
+type TrendPoint struct {
+	// Month is the first day of the sampled month
+	Month time.Time
+	// Commit is the last commit before the start of the month (empty if the repo had no commits yet)
+	Commit string
+	// CommitDate is the committer date of Commit
+	CommitDate time.Time
+	// Result is the count at that commit (zero counts if the path didn't exist yet)
+	Result *CountResult
+}
+
+// CountedDirectives lists the directive types counted, in output order.
+var CountedDirectives = []rst.DirectiveType{rst.CodeBlock, rst.LiteralInclude, rst.IoCodeBlock}
+
+// NewCountResult creates an empty CountResult.
+func NewCountResult() *CountResult {
+	return &CountResult{
+		DirectiveCounts: make(map[rst.DirectiveType]int),
+	}
+}
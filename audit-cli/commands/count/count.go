@@ -4,11 +4,17 @@
 // Currently supports:
 //   - tested-examples: Count tested code examples in the MongoDB documentation monorepo
 //   - pages: Count documentation pages (.txt files) in the MongoDB documentation monorepo
+//   - code-examples: Count code-example directives in RST files, optionally as a trend over git history
+//   - io-code-blocks: Count io-code-block directives by project and flag missing or placeholder output
+//   - admonitions: Count admonition directives by directory and flag pages with too many
 //
 // These commands help writers track coverage metrics and report to stakeholders.
 package count
 
 import (
+	"github.com/mongodb/code-example-tooling/audit-cli/commands/count/admonitions"
+	code_examples "github.com/mongodb/code-example-tooling/audit-cli/commands/count/code-examples"
+	io_code_blocks "github.com/mongodb/code-example-tooling/audit-cli/commands/count/io-code-blocks"
 	"github.com/mongodb/code-example-tooling/audit-cli/commands/count/pages"
 	"github.com/mongodb/code-example-tooling/audit-cli/commands/count/tested-examples"
 	"github.com/spf13/cobra"
@@ -28,12 +34,18 @@ Helps writers track coverage metrics and report statistics to stakeholders.
 
 Currently supports:
   - tested-examples: Count tested code examples in the documentation monorepo
-  - pages: Count documentation pages (.txt files) in the documentation monorepo`,
+  - pages: Count documentation pages (.txt files) in the documentation monorepo
+  - code-examples: Count code-example directives in RST files, optionally as a trend over git history
+  - io-code-blocks: Count io-code-block directives by project and flag missing or placeholder output
+  - admonitions: Count admonition directives by directory and flag pages with too many`,
 	}
 
 	// Add subcommands
 	cmd.AddCommand(tested_examples.NewTestedExamplesCommand())
 	cmd.AddCommand(pages.NewPagesCommand())
+	cmd.AddCommand(code_examples.NewCodeExamplesCommand())
+	cmd.AddCommand(io_code_blocks.NewIoCodeBlocksCommand())
+	cmd.AddCommand(admonitions.NewAdmonitionsCommand())
 
 	return cmd
 }
@@ -0,0 +1,147 @@
+// Package io_code_blocks provides counting functionality for io-code-block directives.
+package io_code_blocks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/projectinfo"
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// testedOutputDir is the include path prefix of output files generated and checked by the tested code examples
+const testedOutputDir = "/code-examples/tested/"
+
+// placeholderRegex matches output content that only stands in for real output: ellipses, a single
+// angle-bracket placeholder such as <output>, or a TODO/TBD marker.
+var placeholderRegex = regexp.MustCompile(`(?i)^(\.{3,}|…|<[^<>\n]+>|todo|tbd|todo:.*)$`)
+
+// CountIoCodeBlocks counts io-code-block directives in the content directory by project and output status.
+//
+// This function navigates to the content directory from the monorepo root and parses the
+// .rst, .txt, and .md files in each project. The code-examples directory at the root of
+// content is skipped.
+//
+// Parameters:
+//   - dirPath: Path to the monorepo root or content directory
+//   - forProject: If non-empty, only count io-code-blocks for this project
+//
+// Returns:
+//   - *CountResult: The counting results
+//   - error: Any error encountered during counting
+func CountIoCodeBlocks(dirPath string, forProject string) (*CountResult, error) {
+	absDirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	if _, err := os.Stat(absDirPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", absDirPath)
+	}
+
+	contentDir, err := projectinfo.FindContentDirectory(absDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := NewCountResult(contentDir)
+	err = filepath.Walk(contentDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(contentDir, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		if info.IsDir() {
+			// Example files at the root of content aren't part of any project's pages
+			if filepath.Dir(path) == contentDir && info.Name() == "code-examples" {
+				return filepath.SkipDir
+			}
+			// Only walk the requested project
+			if forProject != "" && filepath.Dir(path) == contentDir && info.Name() != forProject {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !rst.ShouldProcessFile(path) {
+			return nil
+		}
+		project := strings.Split(relPath, string(filepath.Separator))[0]
+		if project == relPath {
+			// File is directly in content directory, not in a project
+			return nil
+		}
+
+		directives, err := rst.ParseDirectives(path)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		result.FilesScanned++
+
+		for _, directive := range directives {
+			if directive.Type != rst.IoCodeBlock {
+				continue
+			}
+			status := ClassifyOutput(directive)
+			result.add(project, status)
+			if status == OutputMissing || status == OutputPlaceholder {
+				result.Flagged = append(result.Flagged, FlaggedBlock{
+					Project: project,
+					File:    filepath.ToSlash(relPath),
+					Line:    directive.LineNum,
+					Status:  status,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk content directory: %w", err)
+	}
+
+	sort.SliceStable(result.Flagged, func(i, j int) bool {
+		if result.Flagged[i].File != result.Flagged[j].File {
+			return result.Flagged[i].File < result.Flagged[j].File
+		}
+		return result.Flagged[i].Line < result.Flagged[j].Line
+	})
+	return result, nil
+}
+
+// ClassifyOutput returns the output status of an io-code-block directive.
+//
+// Output included from the tested code examples directory is tested. Inline output is a
+// placeholder if it's empty or only holds a placeholder such as "...", "<output>", or "TODO";
+// other inline output, and output included from other files, is untested.
+func ClassifyOutput(directive rst.Directive) OutputStatus {
+	output := directive.OutputDirective
+	if output == nil {
+		return OutputMissing
+	}
+	if output.Argument != "" {
+		if strings.Contains(output.Argument, testedOutputDir) {
+			return OutputTested
+		}
+		return OutputUntested
+	}
+	if isPlaceholder(output.Content) {
+		return OutputPlaceholder
+	}
+	return OutputUntested
+}
+
+// isPlaceholder reports whether inline output content is empty or only a placeholder.
+func isPlaceholder(content string) bool {
+	content = strings.TrimSpace(content)
+	return content == "" || placeholderRegex.MatchString(content)
+}
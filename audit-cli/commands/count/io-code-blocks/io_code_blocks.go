@@ -0,0 +1,98 @@
+// Package io_code_blocks implements the io-code-blocks subcommand for counting io-code-block directives.
+//
+// This package counts io-code-block directives in the documentation monorepo by project, and
+// classifies each by its output: included from a tested output file, untested, a placeholder,
+// or missing. It quantifies how many examples show output that no test verifies.
+package io_code_blocks
+
+import (
+	"fmt"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// NewIoCodeBlocksCommand creates the io-code-blocks subcommand.
+//
+// This command counts io-code-block directives by project and output status.
+//
+// Usage:
+//
+//	count io-code-blocks /path/to/docs-monorepo
+//	count io-code-blocks /path/to/docs-monorepo --for-project manual
+//	count io-code-blocks /path/to/docs-monorepo --missing-output
+//
+// Flags:
+//   - --for-project: Only count io-code-blocks for a specific project
+//   - --missing-output: List each io-code-block with missing or placeholder output
+//   - --format: Output format (text, json, csv, or markdown)
+//   - --output-file: Write results to a file instead of stdout
+func NewIoCodeBlocksCommand() *cobra.Command {
+	var (
+		forProject    string
+		missingOutput bool
+		outputOpts    output.Options
+	)
+
+	cmd := &cobra.Command{
+		Use:   "io-code-blocks [directory-path]",
+		Short: "Count io-code-block directives and flag missing or placeholder output",
+		Long: `Count io-code-block directives in the MongoDB documentation monorepo by project.
+
+This command navigates to the content directory and parses the .rst, .txt, and .md files in
+each project (the directories under content/, except code-examples). Each io-code-block is
+classified by its output:
+
+  - tested:      the output is included from a file under /code-examples/tested/
+  - untested:    the output is inline, or included from another file
+  - placeholder: the inline output is empty or only a placeholder, such as "...",
+                 "<output>", "TODO", or "TBD"
+  - missing:     the io-code-block has no output directive
+
+By default, shows the counts for each project. With --missing-output, lists every
+io-code-block whose output is missing or a placeholder, with its file and line.
+
+Examples:
+  # Count io-code-blocks by project and output status
+  count io-code-blocks /path/to/docs-monorepo
+
+  # Count io-code-blocks for a specific project
+  count io-code-blocks /path/to/docs-monorepo --for-project manual
+
+  # List the io-code-blocks with missing or placeholder output
+  count io-code-blocks /path/to/docs-monorepo --missing-output
+
+  # Write the list to a CSV file
+  count io-code-blocks /path/to/docs-monorepo --missing-output --format csv --output-file missing-output.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runIoCodeBlocks(args[0], forProject, missingOutput, outputOpts)
+		},
+	}
+
+	cmd.Flags().StringVar(&forProject, "for-project", "", "Only count io-code-blocks for a specific project")
+	cmd.Flags().BoolVar(&missingOutput, "missing-output", false, "List each io-code-block with missing or placeholder output")
+	output.AddFlags(cmd, &outputOpts)
+
+	return cmd
+}
+
+// runIoCodeBlocks executes the io-code-block counting operation.
+func runIoCodeBlocks(dirPath string, forProject string, missingOutput bool, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
+	result, err := CountIoCodeBlocks(dirPath, forProject)
+	if err != nil {
+		return fmt.Errorf("failed to count io-code-blocks: %w", err)
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return PrintResults(w, result, missingOutput)
+}
@@ -0,0 +1,136 @@
+// Package io_code_blocks provides tests for the io-code-blocks counting functionality.
+package io_code_blocks
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+var testDataDir = filepath.Join("..", "..", "..", "testdata", "count-io-code-blocks")
+
+// TestCountIoCodeBlocks tests counting io-code-blocks by project and output status.
+func TestCountIoCodeBlocks(t *testing.T) {
+	result, err := CountIoCodeBlocks(testDataDir, "")
+	if err != nil {
+		t.Fatalf("CountIoCodeBlocks failed: %v", err)
+	}
+
+	// The code-examples directory at the root of content is skipped
+	if result.TotalCount != 7 {
+		t.Errorf("Expected total count 7, got %d", result.TotalCount)
+	}
+	if len(result.ProjectCounts) != 2 {
+		t.Fatalf("Expected 2 projects, got %d", len(result.ProjectCounts))
+	}
+
+	expected := map[string]map[OutputStatus]int{
+		"manual":  {OutputTested: 1, OutputUntested: 2, OutputPlaceholder: 2, OutputMissing: 1},
+		"drivers": {OutputTested: 1},
+	}
+	for project, statuses := range expected {
+		counts := result.ProjectCounts[project]
+		if counts == nil {
+			t.Errorf("Expected counts for %s", project)
+			continue
+		}
+		for _, status := range OutputStatuses {
+			if counts.ByStatus[status] != statuses[status] {
+				t.Errorf("Expected %s %s count %d, got %d", project, status, statuses[status], counts.ByStatus[status])
+			}
+		}
+	}
+
+	expectedFlagged := []FlaggedBlock{
+		{Project: "manual", File: "manual/source/aggregation.txt", Line: 15, Status: OutputPlaceholder},
+		{Project: "manual", File: "manual/source/queries.txt", Line: 35, Status: OutputPlaceholder},
+		{Project: "manual", File: "manual/source/queries.txt", Line: 50, Status: OutputMissing},
+	}
+	if len(result.Flagged) != len(expectedFlagged) {
+		t.Fatalf("Expected %d flagged blocks, got %d: %+v", len(expectedFlagged), len(result.Flagged), result.Flagged)
+	}
+	for i, block := range expectedFlagged {
+		if result.Flagged[i] != block {
+			t.Errorf("Flagged[%d] = %+v, want %+v", i, result.Flagged[i], block)
+		}
+	}
+}
+
+// TestCountIoCodeBlocksForProject tests filtering by project.
+func TestCountIoCodeBlocksForProject(t *testing.T) {
+	result, err := CountIoCodeBlocks(testDataDir, "drivers")
+	if err != nil {
+		t.Fatalf("CountIoCodeBlocks failed: %v", err)
+	}
+
+	if result.TotalCount != 1 {
+		t.Errorf("Expected total count 1, got %d", result.TotalCount)
+	}
+	if len(result.ProjectCounts) != 1 || result.ProjectCounts["drivers"] == nil {
+		t.Errorf("Expected only drivers in the counts, got %v", result.ProjectCounts)
+	}
+	if len(result.Flagged) != 0 {
+		t.Errorf("Expected no flagged blocks, got %+v", result.Flagged)
+	}
+}
+
+// TestClassifyOutput tests classifying io-code-block output.
+func TestClassifyOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   *rst.SubDirective
+		expected OutputStatus
+	}{
+		{"no output", nil, OutputMissing},
+		{"tested file", &rst.SubDirective{Argument: "/code-examples/tested/python/pymongo/out.txt"}, OutputTested},
+		{"other file", &rst.SubDirective{Argument: "/includes/out.txt"}, OutputUntested},
+		{"inline", &rst.SubDirective{Content: "{ _id: 1 }"}, OutputUntested},
+		{"empty", &rst.SubDirective{}, OutputPlaceholder},
+		{"ellipsis", &rst.SubDirective{Content: " ... "}, OutputPlaceholder},
+		{"angle brackets", &rst.SubDirective{Content: "<result>"}, OutputPlaceholder},
+		{"todo", &rst.SubDirective{Content: "TODO: add output"}, OutputPlaceholder},
+		{"document with ellipsis", &rst.SubDirective{Content: "{\n  _id: 1,\n  ...\n}"}, OutputUntested},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directive := rst.Directive{Type: rst.IoCodeBlock, OutputDirective: tt.output}
+			if got := ClassifyOutput(directive); got != tt.expected {
+				t.Errorf("ClassifyOutput() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestPrintResultsMissingOutput tests listing the flagged io-code-blocks as CSV.
+func TestPrintResultsMissingOutput(t *testing.T) {
+	result, err := CountIoCodeBlocks(testDataDir, "")
+	if err != nil {
+		t.Fatalf("CountIoCodeBlocks failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := PrintResults(output.NewWriter(&buf, output.FormatCSV), result, true); err != nil {
+		t.Fatalf("PrintResults failed: %v", err)
+	}
+
+	expected := "Project,File,Line,Output\n" +
+		"manual,manual/source/aggregation.txt,15,placeholder\n" +
+		"manual,manual/source/queries.txt,35,placeholder\n" +
+		"manual,manual/source/queries.txt,50,missing\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected CSV output:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+
+	buf.Reset()
+	if err := PrintResults(output.NewWriter(&buf, output.FormatText), result, false); err != nil {
+		t.Fatalf("PrintResults failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Total: 7 (in 3 files); 1 missing output, 2 placeholder output") {
+		t.Errorf("Expected totals in the footer, got:\n%s", buf.String())
+	}
+}
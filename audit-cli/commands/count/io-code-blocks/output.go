@@ -0,0 +1,98 @@
+// Package io_code_blocks provides output formatting for io-code-block counts.
+package io_code_blocks
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// PrintResults writes the counting results.
+//
+// If missingOutput is true, writes one row per io-code-block with missing or placeholder output.
+// Otherwise, writes the counts for each project broken down by output status.
+//
+// Parameters:
+//   - w: The output writer, which determines the format and destination
+//   - result: The counting results
+//   - missingOutput: If true, list the flagged io-code-blocks
+func PrintResults(w *output.Writer, result *CountResult, missingOutput bool) error {
+	if missingOutput {
+		return printFlagged(w, result)
+	}
+	return printByProject(w, result)
+}
+
+// printByProject writes the counts for each project.
+func printByProject(w *output.Writer, result *CountResult) error {
+	if len(result.ProjectCounts) == 0 && w.Format() == output.FormatText {
+		w.Println("No io-code-blocks found")
+		return nil
+	}
+
+	var projectNames []string
+	for name := range result.ProjectCounts {
+		projectNames = append(projectNames, name)
+	}
+	sort.Strings(projectNames)
+
+	columns := []output.Column{
+		{Header: "Project"},
+		{Header: "Total", Align: output.AlignRight},
+	}
+	for _, status := range OutputStatuses {
+		columns = append(columns, output.Column{Header: statusHeader(status), Align: output.AlignRight})
+	}
+	table := output.NewTable("io-code-block Output by Project:", columns...)
+	totals := make(map[OutputStatus]int)
+	for _, name := range projectNames {
+		counts := result.ProjectCounts[name]
+		row := []interface{}{name, counts.Total}
+		for _, status := range OutputStatuses {
+			row = append(row, counts.ByStatus[status])
+			totals[status] += counts.ByStatus[status]
+		}
+		table.AddRow(row...)
+	}
+	table.Footer = fmt.Sprintf("Total: %d (in %d files); %d missing output, %d placeholder output",
+		result.TotalCount, result.FilesScanned, totals[OutputMissing], totals[OutputPlaceholder])
+
+	return w.WriteTable(table)
+}
+
+// printFlagged writes the io-code-blocks with missing or placeholder output.
+func printFlagged(w *output.Writer, result *CountResult) error {
+	if len(result.Flagged) == 0 && w.Format() == output.FormatText {
+		w.Println("No io-code-blocks with missing or placeholder output")
+		return nil
+	}
+
+	table := output.NewTable("io-code-blocks Missing Output:",
+		output.Column{Header: "Project"},
+		output.Column{Header: "File"},
+		output.Column{Header: "Line", Align: output.AlignRight},
+		output.Column{Header: "Output"},
+	)
+	for _, block := range result.Flagged {
+		table.AddRow(block.Project, block.File, block.Line, string(block.Status))
+	}
+	table.Footer = fmt.Sprintf("%d of %d io-code-blocks are missing output or use a placeholder",
+		len(result.Flagged), result.TotalCount)
+
+	return w.WriteTable(table)
+}
+
+// statusHeader returns the column header for an output status.
+func statusHeader(status OutputStatus) string {
+	switch status {
+	case OutputTested:
+		return "Tested"
+	case OutputUntested:
+		return "Untested"
+	case OutputPlaceholder:
+		return "Placeholder"
+	default:
+		return "Missing"
+	}
+}
@@ -0,0 +1,73 @@
+// Package io_code_blocks provides functionality for counting io-code-block directives.
+package io_code_blocks
+
+// OutputStatus describes the output of an io-code-block.
+type OutputStatus string
+
+const (
+	// OutputTested is output included from a file in the tested code examples directory
+	OutputTested OutputStatus = "tested"
+	// OutputUntested is output written inline, or included from a file outside the tested code examples directory
+	OutputUntested OutputStatus = "untested"
+	// OutputPlaceholder is output that only holds a placeholder, such as "..." or "<output>"
+	OutputPlaceholder OutputStatus = "placeholder"
+	// OutputMissing means the io-code-block has no output directive
+	OutputMissing OutputStatus = "missing"
+)
+
+// OutputStatuses lists the output statuses, in output order.
+var OutputStatuses = []OutputStatus{OutputTested, OutputUntested, OutputPlaceholder, OutputMissing}
+
+// ProjectCounts holds the io-code-block counts for one project.
+type ProjectCounts struct {
+	// Total is the number of io-code-block directives in the project
+	Total int
+	// ByStatus maps each output status to the number of io-code-blocks with that status
+	ByStatus map[OutputStatus]int
+}
+
+// FlaggedBlock is an io-code-block whose output is missing or a placeholder.
+type FlaggedBlock struct {
+	// Project is the project directory under content
+	Project string
+	// File is the path of the file, relative to the content directory
+	File string
+	// Line is the line number of the io-code-block directive (1-based)
+	Line int
+	// Status is OutputMissing or OutputPlaceholder
+	Status OutputStatus
+}
+
+// CountResult represents the result of counting io-code-blocks.
+type CountResult struct {
+	// TotalCount is the total number of io-code-block directives counted
+	TotalCount int
+	// ProjectCounts maps project directory names to their counts
+	ProjectCounts map[string]*ProjectCounts
+	// Flagged lists the io-code-blocks with missing or placeholder output, in file order
+	Flagged []FlaggedBlock
+	// FilesScanned is the number of files scanned
+	FilesScanned int
+	// ContentDir is the path to the content directory
+	ContentDir string
+}
+
+// NewCountResult creates an empty CountResult for the given content directory.
+func NewCountResult(contentDir string) *CountResult {
+	return &CountResult{
+		ProjectCounts: make(map[string]*ProjectCounts),
+		ContentDir:    contentDir,
+	}
+}
+
+// add records an io-code-block in project with the given output status.
+func (r *CountResult) add(project string, status OutputStatus) {
+	counts, ok := r.ProjectCounts[project]
+	if !ok {
+		counts = &ProjectCounts{ByStatus: make(map[OutputStatus]int)}
+		r.ProjectCounts[project] = counts
+	}
+	counts.Total++
+	counts.ByStatus[status]++
+	r.TotalCount++
+}
@@ -38,7 +38,7 @@ func CountPages(dirPath string, forProject string, excludeDirs []string, current
 	}
 
 	// Find the content directory
-	contentDir, err := findContentDirectory(absDirPath)
+	contentDir, err := projectinfo.FindContentDirectory(absDirPath)
 	if err != nil {
 		return nil, err
 	}
@@ -178,23 +178,6 @@ func CountPages(dirPath string, forProject string, excludeDirs []string, current
 	return result, nil
 }
 
-// findContentDirectory finds the content directory from the given path.
-// It checks if the path is already a content directory, or if it contains one.
-func findContentDirectory(dirPath string) (string, error) {
-	// Check if this is already a content directory
-	if filepath.Base(dirPath) == "content" {
-		return dirPath, nil
-	}
-
-	// Check if there's a content subdirectory
-	contentDir := filepath.Join(dirPath, "content")
-	if _, err := os.Stat(contentDir); err == nil {
-		return contentDir, nil
-	}
-
-	return "", fmt.Errorf("content directory not found in: %s\n\nPlease provide the path to the monorepo root or content directory", dirPath)
-}
-
 // extractProjectName extracts the project name from a relative path.
 // Returns the first directory component, which represents the project.
 func extractProjectName(relPath string) string {
@@ -2,40 +2,47 @@
 package pages
 
 import (
-	"fmt"
 	"sort"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 )
 
-// PrintResults prints the counting results.
+// PrintResults writes the counting results.
 //
-// If countByProject is true, prints a breakdown by project.
-// If byVersion is true, prints a breakdown by project and version.
-// Otherwise, prints only the total count.
+// If countByProject is true, writes a breakdown by project.
+// If byVersion is true, writes a breakdown by project and version.
+// Otherwise, writes only the total count.
 //
 // Parameters:
+//   - w: The output writer, which determines the format and destination
 //   - result: The counting results
 //   - countByProject: If true, show breakdown by project
 //   - byVersion: If true, show breakdown by project and version
-func PrintResults(result *CountResult, countByProject bool, byVersion bool) {
+func PrintResults(w *output.Writer, result *CountResult, countByProject bool, byVersion bool) error {
 	if byVersion {
-		printByVersion(result)
+		return printByVersion(w, result)
 	} else if countByProject {
-		printByProject(result)
-	} else {
-		printTotal(result)
+		return printByProject(w, result)
 	}
+	return printTotal(w, result)
 }
 
-// printTotal prints only the total count as a single integer.
-func printTotal(result *CountResult) {
-	fmt.Println(result.TotalCount)
+// printTotal writes only the total count. In text format, this is a single integer.
+func printTotal(w *output.Writer, result *CountResult) error {
+	if w.Format() == output.FormatText {
+		w.Println(result.TotalCount)
+		return nil
+	}
+	table := output.NewTable("", output.Column{Header: "Total", Align: output.AlignRight})
+	table.AddRow(result.TotalCount)
+	return w.WriteTable(table)
 }
 
-// printByProject prints a breakdown of counts by project.
-func printByProject(result *CountResult) {
-	if len(result.ProjectCounts) == 0 {
-		fmt.Println("No pages found")
-		return
+// printByProject writes a breakdown of counts by project.
+func printByProject(w *output.Writer, result *CountResult) error {
+	if len(result.ProjectCounts) == 0 && w.Format() == output.FormatText {
+		w.Println("No pages found")
+		return nil
 	}
 
 	// Get sorted list of project names
@@ -45,26 +52,23 @@ func printByProject(result *CountResult) {
 	}
 	sort.Strings(projectNames)
 
-	// Print header
-	fmt.Println("Page Counts by Project:")
-	fmt.Println()
-
-	// Print each project with its count
+	table := output.NewTable("Page Counts by Project:",
+		output.Column{Header: "Project"},
+		output.Column{Header: "Count", Align: output.AlignRight},
+	)
 	for _, name := range projectNames {
-		count := result.ProjectCounts[name]
-		fmt.Printf("  %-30s %5d\n", name, count)
+		table.AddRow(name, result.ProjectCounts[name])
 	}
+	table.Footer = "Total: " + output.FormatValue(result.TotalCount)
 
-	// Print total
-	fmt.Println()
-	fmt.Printf("Total: %d\n", result.TotalCount)
+	return w.WriteTable(table)
 }
 
-// printByVersion prints a breakdown of counts by project and version.
-func printByVersion(result *CountResult) {
-	if len(result.VersionCounts) == 0 {
-		fmt.Println("No pages found")
-		return
+// printByVersion writes a breakdown of counts by project and version.
+func printByVersion(w *output.Writer, result *CountResult) error {
+	if len(result.VersionCounts) == 0 && w.Format() == output.FormatText {
+		w.Println("No pages found")
+		return nil
 	}
 
 	// Get sorted list of project names
@@ -74,12 +78,14 @@ func printByVersion(result *CountResult) {
 	}
 	sort.Strings(projectNames)
 
-	// Print each project with its versions
+	table := output.NewTable("Page Counts by Project and Version:",
+		output.Column{Header: "Project"},
+		output.Column{Header: "Version"},
+		output.Column{Header: "Count", Align: output.AlignRight},
+	)
 	for _, projectName := range projectNames {
 		versionCounts := result.VersionCounts[projectName]
 
-		fmt.Printf("Project: %s\n", projectName)
-
 		// Get sorted list of version names
 		var versionNames []string
 		for version := range versionCounts {
@@ -87,20 +93,15 @@ func printByVersion(result *CountResult) {
 		}
 		sort.Strings(versionNames)
 
-		// Print each version with its count
 		for _, versionName := range versionNames {
-			count := versionCounts[versionName]
 			displayName := versionName
 			if displayName == "" {
 				displayName = "(no version)"
 			}
-			fmt.Printf("  %-28s %5d\n", displayName, count)
+			table.AddRow(projectName, displayName, versionCounts[versionName])
 		}
-
-		fmt.Println()
 	}
+	table.Footer = "Total: " + output.FormatValue(result.TotalCount)
 
-	// Print total
-	fmt.Printf("Total: %d\n", result.TotalCount)
+	return w.WriteTable(table)
 }
-
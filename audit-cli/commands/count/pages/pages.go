@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +24,10 @@ import (
 //   - --for-project: Only count pages for a specific project
 //   - --count-by-project: Display a list of projects with counts for each
 //   - --exclude-dirs: Comma-separated list of directory names to exclude
+//   - --current-only: Only count pages in the current version
+//   - --by-version: Display counts grouped by project and version
+//   - --format: Output format (text, json, csv, or markdown)
+//   - --output-file: Write results to a file instead of stdout
 func NewPagesCommand() *cobra.Command {
 	var (
 		forProject     string
@@ -30,6 +35,7 @@ func NewPagesCommand() *cobra.Command {
 		excludeDirs    string
 		currentOnly    bool
 		byVersion      bool
+		outputOpts     output.Options
 	)
 
 	cmd := &cobra.Command{
@@ -73,10 +79,13 @@ Examples:
   count pages /path/to/docs-monorepo --current-only
 
   # Show counts by version
-  count pages /path/to/docs-monorepo --by-version`,
+  count pages /path/to/docs-monorepo --by-version
+
+  # Write counts by project to a CSV file
+  count pages /path/to/docs-monorepo --count-by-project --format csv --output-file pages.csv`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runPages(args[0], forProject, countByProject, excludeDirs, currentOnly, byVersion)
+			return runPages(args[0], forProject, countByProject, excludeDirs, currentOnly, byVersion, outputOpts)
 		},
 	}
 
@@ -85,12 +94,17 @@ Examples:
 	cmd.Flags().StringVar(&excludeDirs, "exclude-dirs", "", "Comma-separated list of directory names to exclude")
 	cmd.Flags().BoolVar(&currentOnly, "current-only", false, "Only count pages in the current version")
 	cmd.Flags().BoolVar(&byVersion, "by-version", false, "Display counts grouped by project and version")
+	output.AddFlags(cmd, &outputOpts)
 
 	return cmd
 }
 
 // runPages executes the pages counting operation.
-func runPages(dirPath string, forProject string, countByProject bool, excludeDirs string, currentOnly bool, byVersion bool) error {
+func runPages(dirPath string, forProject string, countByProject bool, excludeDirs string, currentOnly bool, byVersion bool, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
 	// Validate flag combinations
 	if forProject != "" && countByProject {
 		return fmt.Errorf("cannot use --for-project and --count-by-project together")
@@ -122,7 +136,11 @@ func runPages(dirPath string, forProject string, countByProject bool, excludeDir
 	}
 
 	// Print the results
-	PrintResults(result, countByProject, byVersion)
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
 
-	return nil
+	return PrintResults(w, result, countByProject, byVersion)
 }
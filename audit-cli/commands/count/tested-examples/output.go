@@ -2,36 +2,43 @@
 package tested_examples
 
 import (
-	"fmt"
 	"sort"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 )
 
-// PrintResults prints the counting results.
+// PrintResults writes the counting results.
 //
-// If countByProduct is true, prints a breakdown by product.
-// Otherwise, prints only the total count.
+// If countByProduct is true, writes a breakdown by product.
+// Otherwise, writes only the total count.
 //
 // Parameters:
+//   - w: The output writer, which determines the format and destination
 //   - result: The counting results
 //   - countByProduct: If true, show breakdown by product
-func PrintResults(result *CountResult, countByProduct bool) {
+func PrintResults(w *output.Writer, result *CountResult, countByProduct bool) error {
 	if countByProduct {
-		printByProduct(result)
-	} else {
-		printTotal(result)
+		return printByProduct(w, result)
 	}
+	return printTotal(w, result)
 }
 
-// printTotal prints only the total count as a single integer.
-func printTotal(result *CountResult) {
-	fmt.Println(result.TotalCount)
+// printTotal writes only the total count. In text format, this is a single integer.
+func printTotal(w *output.Writer, result *CountResult) error {
+	if w.Format() == output.FormatText {
+		w.Println(result.TotalCount)
+		return nil
+	}
+	table := output.NewTable("", output.Column{Header: "Total", Align: output.AlignRight})
+	table.AddRow(result.TotalCount)
+	return w.WriteTable(table)
 }
 
-// printByProduct prints a breakdown of counts by product.
-func printByProduct(result *CountResult) {
-	if len(result.ProductCounts) == 0 {
-		fmt.Println("No files found")
-		return
+// printByProduct writes a breakdown of counts by product.
+func printByProduct(w *output.Writer, result *CountResult) error {
+	if len(result.ProductCounts) == 0 && w.Format() == output.FormatText {
+		w.Println("No files found")
+		return nil
 	}
 
 	// Get sorted list of product keys
@@ -41,19 +48,15 @@ func printByProduct(result *CountResult) {
 	}
 	sort.Strings(productKeys)
 
-	// Print header
-	fmt.Println("Product Counts:")
-	fmt.Println()
-
-	// Print each product with its count
+	table := output.NewTable("Product Counts:",
+		output.Column{Header: "Product"},
+		output.Column{Header: "Count", Align: output.AlignRight},
+		output.Column{Header: "Name"},
+	)
 	for _, key := range productKeys {
-		count := result.ProductCounts[key]
-		productInfo := ProductMap[key]
-		fmt.Printf("  %-25s %5d  (%s)\n", key, count, productInfo.Name)
+		table.AddRow(key, result.ProductCounts[key], ProductMap[key].Name)
 	}
+	table.Footer = "Total: " + output.FormatValue(result.TotalCount)
 
-	// Print total
-	fmt.Println()
-	fmt.Printf("Total: %d\n", result.TotalCount)
+	return w.WriteTable(table)
 }
-
@@ -4,6 +4,7 @@ package tested_examples
 import (
 	"fmt"
 
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -21,11 +22,14 @@ import (
 //   - --for-product: Only count code examples for a specific product
 //   - --count-by-product: Display a list of products with counts for each
 //   - --exclude-output: Only count source files, excluding output files (.txt, .sh)
+//   - --format: Output format (text, json, csv, or markdown)
+//   - --output-file: Write results to a file instead of stdout
 func NewTestedExamplesCommand() *cobra.Command {
 	var (
 		forProduct     string
 		countByProduct bool
 		excludeOutput  bool
+		outputOpts     output.Options
 	)
 
 	cmd := &cobra.Command{
@@ -58,22 +62,30 @@ Examples:
   count tested-examples /path/to/docs-monorepo --exclude-output
 
   # Combine flags: count source files for a specific product
-  count tested-examples /path/to/docs-monorepo --for-product pymongo --exclude-output`,
+  count tested-examples /path/to/docs-monorepo --for-product pymongo --exclude-output
+
+  # Output counts by product as a markdown table for a ticket
+  count tested-examples /path/to/docs-monorepo --count-by-product --format markdown`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runTestedExamples(args[0], forProduct, countByProduct, excludeOutput)
+			return runTestedExamples(args[0], forProduct, countByProduct, excludeOutput, outputOpts)
 		},
 	}
 
 	cmd.Flags().StringVar(&forProduct, "for-product", "", "Only count code examples for a specific product")
 	cmd.Flags().BoolVar(&countByProduct, "count-by-product", false, "Display counts for each product")
 	cmd.Flags().BoolVar(&excludeOutput, "exclude-output", false, "Only count source files (exclude .txt and .sh files)")
+	output.AddFlags(cmd, &outputOpts)
 
 	return cmd
 }
 
 // runTestedExamples executes the tested-examples counting operation.
-func runTestedExamples(monorepoPath string, forProduct string, countByProduct bool, excludeOutput bool) error {
+func runTestedExamples(monorepoPath string, forProduct string, countByProduct bool, excludeOutput bool, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
 	// Validate product if specified
 	if forProduct != "" && !IsValidProduct(forProduct) {
 		return fmt.Errorf("invalid product: %s\n\n%s", forProduct, GetProductList())
@@ -91,8 +103,12 @@ func runTestedExamples(monorepoPath string, forProduct string, countByProduct bo
 	}
 
 	// Print the results
-	PrintResults(result, countByProduct)
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
 
-	return nil
+	return PrintResults(w, result, countByProduct)
 }
 
@@ -0,0 +1,95 @@
+package code_examples
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// Include attribution modes, selected with --attribute-includes.
+const (
+	// AttributeToFile counts each example once, under the file it's physically in.
+	AttributeToFile = "file"
+	// AttributeToPages counts an example from an included file once for every page that consumes it.
+	AttributeToPages = "page"
+)
+
+// ValidateAttributionMode returns an error if mode isn't a supported attribution mode.
+func ValidateAttributionMode(mode string) error {
+	if mode != AttributeToFile && mode != AttributeToPages {
+		return fmt.Errorf("invalid --attribute-includes value %q (must be %s or %s)", mode, AttributeToFile, AttributeToPages)
+	}
+	return nil
+}
+
+// FindIncludeConsumers maps every file reached through include directives from the given files
+// to the pages that consume it.
+//
+// A page is one of the given files that isn't itself included by another of the given files.
+// A page consumes every file its include chain reaches, directly or through other includes.
+// Included files are keyed by absolute path. Pages are listed as given, sorted.
+//
+// Parameters:
+//   - files: The files being processed
+//
+// Returns:
+//   - map[string][]string: The consuming pages for each included file
+//   - error: Any error encountered resolving paths
+func FindIncludeConsumers(files []string) (map[string][]string, error) {
+	reached := make(map[string]map[string]bool, len(files))
+	included := make(map[string]bool)
+	pages := make(map[string]string, len(files)) // absolute path -> path as given
+	var roots []string
+
+	for _, file := range files {
+		absPath, err := filepath.Abs(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve path %s: %w", file, err)
+		}
+		if _, seen := reached[absPath]; seen {
+			continue
+		}
+		roots = append(roots, absPath)
+		pages[absPath] = file
+
+		files := make(map[string]bool)
+		collectIncludes(absPath, files)
+		delete(files, absPath)
+		reached[absPath] = files
+		for includedFile := range files {
+			included[includedFile] = true
+		}
+	}
+
+	consumers := make(map[string][]string)
+	for _, root := range roots {
+		if included[root] {
+			continue
+		}
+		for includedFile := range reached[root] {
+			consumers[includedFile] = append(consumers[includedFile], pages[root])
+		}
+	}
+	for _, consumingPages := range consumers {
+		sort.Strings(consumingPages)
+	}
+	return consumers, nil
+}
+
+// collectIncludes adds every file reachable from filePath through include directives to files.
+func collectIncludes(filePath string, files map[string]bool) {
+	includes, err := rst.FindIncludeDirectives(filePath)
+	if err != nil {
+		return
+	}
+	for _, include := range includes {
+		absPath, err := filepath.Abs(include)
+		if err != nil || files[absPath] {
+			continue
+		}
+		files[absPath] = true
+		collectIncludes(absPath, files)
+	}
+}
@@ -9,6 +9,8 @@
 // The extracted code examples are written to individual files with standardized naming:
 //   {source-base}.{directive-type}.{index}.{ext}
 //
+// or in a layout templated with --layout, like ${language}/${page}/${n}.${ext}.
+//
 // Supports recursive directory scanning and following include directives to process
 // entire documentation trees.
 package code_examples
@@ -16,7 +18,9 @@ package code_examples
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -30,25 +34,48 @@ import (
 //   - --dry-run: Show what would be extracted without writing files
 //   - -v, --verbose: Show detailed processing information
 //   - --preserve-dirs: Preserve directory structure when used with --recursive
+//   - --attribute-includes: Count examples from included files under the file ("file") or each consuming page ("page")
+//   - --layout: Name output files with a layout preset or template
+//   - --output-file: Write the report to a file instead of stdout
 func NewCodeExamplesCommand() *cobra.Command {
 	var (
-		recursive      bool
-		followIncludes bool
-		outputDir      string
-		dryRun         bool
-		verbose        bool
-		preserveDirs   bool
+		recursive         bool
+		followIncludes    bool
+		outputDir         string
+		dryRun            bool
+		verbose           bool
+		preserveDirs      bool
+		attributeIncludes string
+		layout            string
+		outputOpts        output.Options
 	)
 
 	cmd := &cobra.Command{
 		Use:   "code-examples [filepath]",
 		Short: "Extract code examples from reStructuredText files",
 		Long: `Extract code examples from reStructuredText directives (code-block, literalinclude, io-code-block)
-and output them as individual files.`,
+and output them as individual files.
+
+With --follow-includes, examples from included files are attributed both to the file
+they're in and to every page that consumes it, directly or through other includes. The
+report lists the consuming pages for each included file. Use --attribute-includes to
+choose how examples are counted:
+  - file: count each example once, under the included file (default)
+  - page: count an example once for every page that consumes its file, so examples in
+    shared includes aren't undercounted
+
+Use --layout to choose how output files are named, so the output fits its consumer:
+` + layoutHelp(),
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ValidateAttributionMode(attributeIncludes); err != nil {
+				return err
+			}
+			if err := ValidateLayout(layout); err != nil {
+				return err
+			}
 			filePath := args[0]
-			return runExtract(filePath, recursive, followIncludes, outputDir, dryRun, verbose, preserveDirs)
+			return runExtract(filePath, recursive, followIncludes, outputDir, dryRun, verbose, preserveDirs, attributeIncludes, layout, outputOpts)
 		},
 	}
 
@@ -58,6 +85,9 @@ and output them as individual files.`,
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be outputted without writing files")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Provide additional information during execution")
 	cmd.Flags().BoolVar(&preserveDirs, "preserve-dirs", false, "Preserve directory structure in output (use with --recursive)")
+	cmd.Flags().StringVar(&attributeIncludes, "attribute-includes", AttributeToFile, "Count examples from included files per file or per consuming page: file or page (use with --follow-includes)")
+	cmd.Flags().StringVar(&layout, "layout", LayoutFlat, "Output file layout: flat, by-language, or a template like '${language}/${page}/${n}.${ext}'")
+	output.AddFileFlags(cmd, &outputOpts)
 
 	return cmd
 }
@@ -80,27 +110,69 @@ and output them as individual files.`,
 //   - *Report: Statistics about the extraction operation
 //   - error: Any error encountered during extraction
 func RunExtract(filePath string, outputDir string, recursive bool, followIncludes bool, dryRun bool, verbose bool, preserveDirs bool) (*Report, error) {
-	report, err := runExtractInternal(filePath, recursive, followIncludes, outputDir, dryRun, verbose, preserveDirs)
+	report, err := runExtractInternal(filePath, recursive, followIncludes, outputDir, dryRun, verbose, preserveDirs, AttributeToFile, LayoutFlat)
 	return report, err
 }
 
+// RunExtractWithAttribution executes the extraction operation with an include attribution mode
+// and returns the report.
+//
+// This function is exported for use in tests. It behaves like RunExtract, but counts
+// examples from included files according to attribution (AttributeToFile or AttributeToPages).
+func RunExtractWithAttribution(filePath string, outputDir string, recursive bool, followIncludes bool, dryRun bool, verbose bool, preserveDirs bool, attribution string) (*Report, error) {
+	if err := ValidateAttributionMode(attribution); err != nil {
+		return nil, err
+	}
+	return runExtractInternal(filePath, recursive, followIncludes, outputDir, dryRun, verbose, preserveDirs, attribution, LayoutFlat)
+}
+
+// RunExtractWithLayout executes the extraction operation with an include attribution mode
+// and an output layout, and returns the report.
+//
+// This function is exported for use in tests. It behaves like RunExtractWithAttribution, but
+// names output files with layout (LayoutFlat, LayoutByLanguage, or a template).
+func RunExtractWithLayout(filePath string, outputDir string, recursive bool, followIncludes bool, dryRun bool, verbose bool, preserveDirs bool, attribution string, layout string) (*Report, error) {
+	if err := ValidateAttributionMode(attribution); err != nil {
+		return nil, err
+	}
+	if err := ValidateLayout(layout); err != nil {
+		return nil, err
+	}
+	return runExtractInternal(filePath, recursive, followIncludes, outputDir, dryRun, verbose, preserveDirs, attribution, layout)
+}
+
 // runExtract executes the extraction operation (internal wrapper for CLI).
 //
-// This is a thin wrapper around runExtractInternal that discards the report
+// This is a thin wrapper around runExtractInternal that writes the report
 // and only returns errors, suitable for use in the CLI command handler.
-func runExtract(filePath string, recursive bool, followIncludes bool, outputDir string, dryRun bool, verbose bool, preserveDirs bool) error {
-	_, err := runExtractInternal(filePath, recursive, followIncludes, outputDir, dryRun, verbose, preserveDirs)
-	return err
+func runExtract(filePath string, recursive bool, followIncludes bool, outputDir string, dryRun bool, verbose bool, preserveDirs bool, attribution string, layout string, outputOpts output.Options) error {
+	report, err := runExtractInternal(filePath, recursive, followIncludes, outputDir, dryRun, verbose, preserveDirs, attribution, layout)
+	if err != nil {
+		return err
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	if dryRun {
+		w.Println("\n[DRY RUN MODE - No files were written]")
+	}
+	PrintReport(w, report, verbose)
+	return nil
 }
 
 // runExtractInternal executes the extraction operation
-func runExtractInternal(filePath string, recursive bool, followIncludes bool, outputDir string, dryRun bool, verbose bool, preserveDirs bool) (*Report, error) {
+func runExtractInternal(filePath string, recursive bool, followIncludes bool, outputDir string, dryRun bool, verbose bool, preserveDirs bool, attribution string, layout string) (*Report, error) {
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to access path %s: %w", filePath, err)
 	}
 
 	report := NewReport()
+	report.AttributionMode = attribution
 
 	var filesToProcess []string
 	var rootPath string
@@ -137,9 +209,22 @@ func runExtractInternal(filePath string, recursive bool, followIncludes bool, ou
 		}
 	}
 
+	// Find the pages that consume each included file, so examples can be attributed to them
+	if followIncludes {
+		report.IncludeConsumers, err = FindIncludeConsumers(filesToProcess)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Track visited files to prevent circular includes
 	visited := make(map[string]bool)
 
+	// Number each source file's examples in order, and track the paths written so a layout
+	// that names two examples the same is reported
+	positions := make(map[string]int)
+	outputPaths := make(map[string]bool)
+
 	for _, file := range filesToProcess {
 		if verbose {
 			fmt.Printf("Processing: %s\n", file)
@@ -158,11 +243,21 @@ func runExtractInternal(filePath string, recursive bool, followIncludes bool, ou
 		}
 
 		for _, example := range examples {
-			outputPath, err := WriteCodeExample(example, outputDir, rootPath, dryRun, preserveDirs)
+			if absPath, err := filepath.Abs(example.SourceFile); err == nil {
+				example.ConsumingPages = report.IncludeConsumers[absPath]
+			}
+			positions[example.SourceFile]++
+			example.Position = positions[example.SourceFile]
+
+			outputPath, err := WriteCodeExampleWithLayout(example, outputDir, rootPath, dryRun, preserveDirs, layout)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to write code example: %v\n", err)
 				continue
 			}
+			if outputPaths[outputPath] {
+				fmt.Fprintf(os.Stderr, "Warning: %s is written for more than one code example; only the last is kept (use ${n} or ${index} in --layout to tell them apart)\n", outputPath)
+			}
+			outputPaths[outputPath] = true
 
 			if verbose {
 				if dryRun {
@@ -179,10 +274,5 @@ func runExtractInternal(filePath string, recursive bool, followIncludes bool, ou
 		}
 	}
 
-	if dryRun {
-		fmt.Println("\n[DRY RUN MODE - No files were written]")
-	}
-	PrintReport(report, verbose)
-
 	return report, nil
 }
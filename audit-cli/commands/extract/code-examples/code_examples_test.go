@@ -696,3 +696,159 @@ func TestPreserveDirsWithoutRecursive(t *testing.T) {
 		t.Errorf("Expected 7 files in output directory, got %d", len(files))
 	}
 }
+
+// writeSharedIncludeFixture writes two pages that include the same file, which contains
+// one code example, plus a page with an example of its own
+func writeSharedIncludeFixture(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "source")
+	files := map[string]string{
+		"page-one.rst":        "Page One\n========\n\n.. include:: /includes/shared.rst\n",
+		"page-two.rst":        "Page Two\n========\n\n.. include:: /includes/shared.rst\n",
+		"page-three.rst":      "Page Three\n==========\n\n.. code-block:: python\n\n   print('hello')\n",
+		"includes/shared.rst": ".. code-block:: javascript\n\n   db.movies.find()\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+// TestIncludeAttributionModes tests that examples from shared includes are counted per file or per page
+func TestIncludeAttributionModes(t *testing.T) {
+	dir := writeSharedIncludeFixture(t)
+
+	fileReport, err := RunExtractWithAttribution(dir, t.TempDir(), true, true, true, false, false, AttributeToFile)
+	if err != nil {
+		t.Fatalf("RunExtractWithAttribution failed: %v", err)
+	}
+	if count := fileReport.LanguageCounts["javascript"]; count != 1 {
+		t.Errorf("Expected the shared example to be counted once by file, got %d", count)
+	}
+	if fileReport.SharedExamples != 1 {
+		t.Errorf("Expected 1 shared example, got %d", fileReport.SharedExamples)
+	}
+
+	includePath, _ := filepath.Abs(filepath.Join(dir, "includes", "shared.rst"))
+	pages := fileReport.IncludeConsumers[includePath]
+	expectedPages := []string{filepath.Join(dir, "page-one.rst"), filepath.Join(dir, "page-two.rst")}
+	if len(pages) != 2 || pages[0] != expectedPages[0] || pages[1] != expectedPages[1] {
+		t.Errorf("Expected consuming pages %v, got %v", expectedPages, pages)
+	}
+
+	pageReport, err := RunExtractWithAttribution(dir, t.TempDir(), true, true, true, false, false, AttributeToPages)
+	if err != nil {
+		t.Fatalf("RunExtractWithAttribution failed: %v", err)
+	}
+	if count := pageReport.LanguageCounts["javascript"]; count != 2 {
+		t.Errorf("Expected the shared example to be counted once per consuming page, got %d", count)
+	}
+	if count := pageReport.LanguageCounts["python"]; count != 1 {
+		t.Errorf("Expected examples on pages to be counted once, got %d", count)
+	}
+	for _, page := range expectedPages {
+		if stats, ok := pageReport.SourcePathStats[page]; !ok || stats.LanguageCounts["javascript"] != 1 {
+			t.Errorf("Expected the shared example to be attributed to %s", page)
+		}
+	}
+	if _, ok := pageReport.SourcePathStats[filepath.Join(dir, "includes", "shared.rst")]; ok {
+		t.Errorf("Expected no statistics under the included file in page mode")
+	}
+}
+
+// TestInvalidAttributionMode tests that unknown attribution modes are rejected
+func TestInvalidAttributionMode(t *testing.T) {
+	if _, err := RunExtractWithAttribution(".", t.TempDir(), false, false, true, false, false, "directory"); err == nil {
+		t.Error("Expected an error for an invalid attribution mode")
+	}
+}
+
+// TestRenderLayout tests naming examples with layout presets and templates
+func TestRenderLayout(t *testing.T) {
+	examples := []CodeExample{
+		{SourceFile: "docs/my-doc.txt", DirectiveName: CodeBlock, Language: Python, Index: 2, Position: 3},
+		{SourceFile: "docs/my-doc.txt", DirectiveName: IoCodeBlock, Language: JavaScript, Index: 1, Position: 4, SubType: "input"},
+		{SourceFile: "docs/my-doc.txt", DirectiveName: LiteralInclude, Language: "", Index: 1, Position: 1},
+	}
+
+	// The flat layout matches the standard naming
+	for _, example := range examples {
+		if got, want := RenderLayout(LayoutFlat, example), GenerateOutputFilename(example); got != want {
+			t.Errorf("Expected flat layout %s, got %s", want, got)
+		}
+	}
+
+	tests := []struct {
+		layout   string
+		example  CodeExample
+		expected string
+	}{
+		{LayoutByLanguage, examples[0], "python/my-doc/3.py"},
+		{LayoutByLanguage, examples[2], "undefined/my-doc/1.txt"},
+		{"${page}/${directive}-${index}.${subtype}.${ext}", examples[0], "my-doc/code-block-2.py"},
+		{"${page}/${directive}-${index}.${subtype}.${ext}", examples[1], "my-doc/io-code-block-1.input.js"},
+		{"${language}/${subtype}/${n}.${ext}", examples[0], "python/3.py"},
+	}
+	for _, tt := range tests {
+		if got := RenderLayout(tt.layout, tt.example); got != tt.expected {
+			t.Errorf("Expected %s with layout %s, got %s", tt.expected, tt.layout, got)
+		}
+	}
+}
+
+// TestValidateLayout tests that presets and valid templates are accepted and invalid templates are rejected
+func TestValidateLayout(t *testing.T) {
+	for _, layout := range []string{LayoutFlat, LayoutByLanguage, "${language}/${page}/${n}.${ext}", "examples/${n}.txt"} {
+		if err := ValidateLayout(layout); err != nil {
+			t.Errorf("Expected layout %q to be valid, got %v", layout, err)
+		}
+	}
+	for _, layout := range []string{"", "${lang}/${n}.${ext}", "/tmp/${n}.${ext}", "../${page}/${n}.${ext}"} {
+		if err := ValidateLayout(layout); err == nil {
+			t.Errorf("Expected layout %q to be rejected", layout)
+		}
+	}
+}
+
+// TestExtractWithLayout tests writing examples in a templated layout
+func TestExtractWithLayout(t *testing.T) {
+	testDataDir := filepath.Join("..", "..", "..", "testdata")
+	inputFile := filepath.Join(testDataDir, "input-files", "source", "io-code-block-test.rst")
+	tempDir := t.TempDir()
+
+	report, err := RunExtractWithLayout(inputFile, tempDir, false, false, false, false, false, AttributeToFile, LayoutByLanguage)
+	if err != nil {
+		t.Fatalf("RunExtractWithLayout failed: %v", err)
+	}
+	if report.OutputFilesWritten != 11 {
+		t.Errorf("Expected 11 output files, got %d", report.OutputFilesWritten)
+	}
+
+	// Examples are numbered in page order across directives and subtypes, so each gets its own file
+	written := 0
+	err = filepath.WalkDir(tempDir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			written++
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk output directory: %v", err)
+	}
+	if written != 11 {
+		t.Errorf("Expected 11 files on disk, got %d", written)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "javascript", "io-code-block-test", "1.js")); err != nil {
+		t.Errorf("Expected the first example at javascript/io-code-block-test/1.js: %v", err)
+	}
+
+	if _, err := RunExtractWithLayout(inputFile, tempDir, false, false, true, false, false, AttributeToFile, "${unknown}"); err == nil {
+		t.Error("Expected an error for an invalid layout")
+	}
+}
@@ -0,0 +1,156 @@
+package code_examples
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Output layout presets, selected with --layout. Any other --layout value is used as a template.
+const (
+	// LayoutFlat names files {source-base}.{directive-type}.{index}.{ext}, the default naming.
+	LayoutFlat = "flat"
+	// LayoutByLanguage writes each page's examples to a directory per language, numbered in page order.
+	LayoutByLanguage = "by-language"
+)
+
+// layoutPresets maps each preset to its template.
+var layoutPresets = map[string]string{
+	LayoutFlat:       "${page}.${directive}.${index}.${subtype}.${ext}",
+	LayoutByLanguage: "${language}/${page}/${n}.${ext}",
+}
+
+// layoutVariables are the variables a layout template can use, with what each is replaced with.
+var layoutVariables = []struct {
+	Name        string
+	Description string
+}{
+	{"page", "the source file's name without its extension"},
+	{"directive", "the directive type: code-block, literalinclude, or io-code-block"},
+	{"index", "the occurrence of the directive type in the source file (1-based)"},
+	{"n", "the example's position among all examples in the source file (1-based)"},
+	{"subtype", "input or output for io-code-block examples; empty otherwise"},
+	{"language", "the normalized language"},
+	{"ext", "the file extension for the language, without the dot"},
+}
+
+// layoutVariablePattern matches a ${name} reference in a layout template.
+var layoutVariablePattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// LayoutTemplate returns the template for a --layout value: the preset's template for a preset name,
+// or the value itself.
+func LayoutTemplate(layout string) string {
+	if template, ok := layoutPresets[layout]; ok {
+		return template
+	}
+	return layout
+}
+
+// ValidateLayout returns an error if a --layout value isn't a preset or a valid template.
+//
+// A template must only reference known variables and must produce a relative path that stays
+// inside the output directory.
+func ValidateLayout(layout string) error {
+	template := LayoutTemplate(layout)
+	if strings.TrimSpace(template) == "" {
+		return fmt.Errorf("invalid --layout value: must be %s, %s, or a template", LayoutFlat, LayoutByLanguage)
+	}
+	for _, match := range layoutVariablePattern.FindAllStringSubmatch(template, -1) {
+		if !isLayoutVariable(match[1]) {
+			return fmt.Errorf("invalid --layout template %q: unknown variable ${%s} (must be one of %s)",
+				layout, match[1], strings.Join(layoutVariableNames(), ", "))
+		}
+	}
+	if path.IsAbs(template) || filepath.IsAbs(template) {
+		return fmt.Errorf("invalid --layout template %q: must be a path relative to the output directory", layout)
+	}
+	for _, segment := range strings.Split(template, "/") {
+		if segment == ".." {
+			return fmt.Errorf("invalid --layout template %q: must not leave the output directory", layout)
+		}
+	}
+	return nil
+}
+
+// RenderLayout returns an example's output path, relative to the output directory, for a --layout value.
+//
+// Variables that are empty for the example, like ${subtype} outside io-code-block, drop the dots
+// around them, and path segments left empty are removed. With the flat layout, the result matches
+// GenerateOutputFilename.
+//
+// Parameters:
+//   - layout: A preset name or a template
+//   - example: The code example to name
+//
+// Returns:
+//   - string: The output path, using forward slashes
+func RenderLayout(layout string, example CodeExample) string {
+	sourceBase := filepath.Base(example.SourceFile)
+	sourceBase = strings.TrimSuffix(sourceBase, filepath.Ext(sourceBase))
+	language := example.Language
+	if language == "" {
+		language = Undefined
+	}
+
+	values := map[string]string{
+		"page":      sourceBase,
+		"directive": string(example.DirectiveName),
+		"index":     strconv.Itoa(example.Index),
+		"n":         strconv.Itoa(example.Position),
+		"subtype":   example.SubType,
+		"language":  language,
+		"ext":       strings.TrimPrefix(GetFileExtensionFromLanguage(example.Language), "."),
+	}
+	if example.DirectiveName != IoCodeBlock {
+		values["subtype"] = ""
+	}
+	rendered := layoutVariablePattern.ReplaceAllStringFunc(LayoutTemplate(layout), func(reference string) string {
+		return values[reference[2:len(reference)-1]]
+	})
+
+	var segments []string
+	for _, segment := range strings.Split(rendered, "/") {
+		for strings.Contains(segment, "..") {
+			segment = strings.ReplaceAll(segment, "..", ".")
+		}
+		if segment = strings.Trim(segment, "."); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isLayoutVariable returns true if name is a variable a template can use.
+func isLayoutVariable(name string) bool {
+	for _, variable := range layoutVariables {
+		if variable.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// layoutVariableNames returns the variables a template can use as ${name} references.
+func layoutVariableNames() []string {
+	names := make([]string, 0, len(layoutVariables))
+	for _, variable := range layoutVariables {
+		names = append(names, "${"+variable.Name+"}")
+	}
+	return names
+}
+
+// layoutHelp describes the layout presets and template variables for the command's help.
+func layoutHelp() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  - %s: %s (default)\n", LayoutFlat, layoutPresets[LayoutFlat])
+	fmt.Fprintf(&b, "  - %s: %s\n", LayoutByLanguage, layoutPresets[LayoutByLanguage])
+	b.WriteString("  - any other value is a template using these variables:\n")
+	for _, variable := range layoutVariables {
+		fmt.Fprintf(&b, "      ${%s}: %s\n", variable.Name, variable.Description)
+	}
+	b.WriteString("    Empty variables drop the dots around them, so ${subtype} only appears for io-code-block.")
+	return b.String()
+}
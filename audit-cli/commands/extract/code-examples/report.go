@@ -1,12 +1,13 @@
 package code_examples
 
 import (
-	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 )
 
-// PrintReport prints the extraction report to stdout.
+// PrintReport prints the extraction report to the writer.
 //
 // Displays statistics about the extraction operation including:
 //   - Number of files traversed
@@ -16,25 +17,26 @@ import (
 //   - Per-source-file statistics (if verbose is true)
 //
 // Parameters:
+//   - w: The output writer, which determines the destination
 //   - report: The report to print
 //   - verbose: If true, show detailed breakdown including file paths and per-source stats
-func PrintReport(report *Report, verbose bool) {
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("CODE EXTRACTION REPORT")
-	fmt.Println(strings.Repeat("=", 60))
+func PrintReport(w *output.Writer, report *Report, verbose bool) {
+	w.Println("\n" + strings.Repeat("=", 60))
+	w.Println("CODE EXTRACTION REPORT")
+	w.Println(strings.Repeat("=", 60))
 
-	fmt.Printf("\nFiles Traversed: %d\n", report.FilesTraversed)
+	w.Printf("\nFiles Traversed: %d\n", report.FilesTraversed)
 	if verbose && len(report.TraversedFilepaths) > 0 {
-		fmt.Println("\nTraversed Filepaths:")
+		w.Println("\nTraversed Filepaths:")
 		for _, path := range report.TraversedFilepaths {
-			fmt.Printf("  - %s\n", path)
+			w.Printf("  - %s\n", path)
 		}
 	}
 
-	fmt.Printf("\nOutput Files Written: %d\n", report.OutputFilesWritten)
+	w.Printf("\nOutput Files Written: %d\n", report.OutputFilesWritten)
 
 	if len(report.LanguageCounts) > 0 {
-		fmt.Println("\nCode Examples by Language:")
+		w.Println("\nCode Examples by Language:")
 
 		languages := make([]string, 0, len(report.LanguageCounts))
 		for lang := range report.LanguageCounts {
@@ -45,30 +47,52 @@ func PrintReport(report *Report, verbose bool) {
 		if verbose {
 			for _, lang := range languages {
 				count := report.LanguageCounts[lang]
-				fmt.Printf("  %-15s: %d\n", lang, count)
+				w.Printf("  %-15s: %d\n", lang, count)
 			}
 		} else {
 			total := 0
 			for _, count := range report.LanguageCounts {
 				total += count
 			}
-			fmt.Printf("  Total: %d (use --verbose for breakdown)\n", total)
+			w.Printf("  Total: %d (use --verbose for breakdown)\n", total)
 		}
 	}
 
 	if len(report.DirectiveCounts) > 0 {
-		fmt.Println("\nCode Examples by Directive Type:")
+		w.Println("\nCode Examples by Directive Type:")
 
 		directives := []DirectiveType{CodeBlock, LiteralInclude, IoCodeBlock}
 		for _, directive := range directives {
 			if count, exists := report.DirectiveCounts[directive]; exists {
-				fmt.Printf("  %-20s: %d\n", directive, count)
+				w.Printf("  %-20s: %d\n", directive, count)
+			}
+		}
+	}
+
+	if len(report.IncludeConsumers) > 0 {
+		w.Printf("\nInclude Attribution: %s\n", report.AttributionMode)
+		w.Printf("  Included Files: %d\n", len(report.IncludeConsumers))
+		w.Printf("  Shared Examples: %d (in files consumed by more than one page)\n", report.SharedExamples)
+
+		if verbose {
+			includedFiles := make([]string, 0, len(report.IncludeConsumers))
+			for path := range report.IncludeConsumers {
+				includedFiles = append(includedFiles, path)
+			}
+			sort.Strings(includedFiles)
+
+			w.Println("\n  Consuming Pages by Included File:")
+			for _, path := range includedFiles {
+				w.Printf("    %s:\n", path)
+				for _, page := range report.IncludeConsumers[path] {
+					w.Printf("      - %s\n", page)
+				}
 			}
 		}
 	}
 
 	if verbose && len(report.SourcePathStats) > 0 {
-		fmt.Println("\nStatistics by Source File:")
+		w.Println("\nStatistics by Source File:")
 
 		sourcePaths := make([]string, 0, len(report.SourcePathStats))
 		for path := range report.SourcePathStats {
@@ -78,20 +102,20 @@ func PrintReport(report *Report, verbose bool) {
 
 		for _, sourcePath := range sourcePaths {
 			stats := report.SourcePathStats[sourcePath]
-			fmt.Printf("\n  %s:\n", sourcePath)
+			w.Printf("\n  %s:\n", sourcePath)
 
 			if len(stats.DirectiveCounts) > 0 {
-				fmt.Println("    Directives:")
+				w.Println("    Directives:")
 				directives := []DirectiveType{CodeBlock, LiteralInclude, IoCodeBlock}
 				for _, directive := range directives {
 					if count, exists := stats.DirectiveCounts[directive]; exists {
-						fmt.Printf("      %-20s: %d\n", directive, count)
+						w.Printf("      %-20s: %d\n", directive, count)
 					}
 				}
 			}
 
 			if len(stats.LanguageCounts) > 0 {
-				fmt.Println("    Languages:")
+				w.Println("    Languages:")
 				languages := make([]string, 0, len(stats.LanguageCounts))
 				for lang := range stats.LanguageCounts {
 					languages = append(languages, lang)
@@ -100,15 +124,15 @@ func PrintReport(report *Report, verbose bool) {
 
 				for _, lang := range languages {
 					count := stats.LanguageCounts[lang]
-					fmt.Printf("      %-15s: %d\n", lang, count)
+					w.Printf("      %-15s: %d\n", lang, count)
 				}
 			}
 
 			if len(stats.OutputFiles) > 0 {
-				fmt.Printf("    Output Files: %d\n", len(stats.OutputFiles))
+				w.Printf("    Output Files: %d\n", len(stats.OutputFiles))
 			}
 		}
 	}
 
-	fmt.Println("\n" + strings.Repeat("=", 60))
+	w.Println("\n" + strings.Repeat("=", 60))
 }
@@ -23,6 +23,10 @@ type CodeExample struct {
 	Content       string        // The actual code content
 	Index         int           // The occurrence index of this directive in the source file (1-based)
 	SubType       string        // For io-code-block: "input" or "output"
+	Position      int           // The position of this example among all examples extracted from the source file (1-based)
+	// ConsumingPages are the pages that include SourceFile, directly or through other includes.
+	// Empty if SourceFile is a page itself, or includes weren't followed.
+	ConsumingPages []string
 }
 
 // Report contains statistics about the extraction operation.
@@ -35,6 +39,9 @@ type Report struct {
 	LanguageCounts     map[string]int            // Count of examples by language
 	DirectiveCounts    map[DirectiveType]int     // Count of examples by directive type
 	SourcePathStats    map[string]*SourceStats   // Per-file statistics
+	AttributionMode    string                    // How examples from included files are counted (AttributeToFile or AttributeToPages)
+	IncludeConsumers   map[string][]string       // Consuming pages for each included file, by absolute path
+	SharedExamples     int                       // Examples from included files consumed by more than one page
 }
 
 // SourceStats contains statistics for a single source file.
@@ -53,6 +60,8 @@ func NewReport() *Report {
 		LanguageCounts:     make(map[string]int),
 		DirectiveCounts:    make(map[DirectiveType]int),
 		SourcePathStats:    make(map[string]*SourceStats),
+		AttributionMode:    AttributeToFile,
+		IncludeConsumers:   make(map[string][]string),
 	}
 }
 
@@ -69,19 +78,33 @@ func NewSourceStats() *SourceStats {
 //
 // This method updates both global statistics and per-source-file statistics.
 // It should be called once for each code example that is successfully extracted.
+//
+// With AttributeToPages, an example from an included file is counted once for each
+// of its consuming pages, and its statistics are recorded under each page.
 func (r *Report) AddCodeExample(example CodeExample, outputPath string) {
-	// Update global counts
-	r.LanguageCounts[example.Language]++
-	r.DirectiveCounts[example.DirectiveName]++
+	if len(example.ConsumingPages) > 1 {
+		r.SharedExamples++
+	}
+
+	sources := []string{example.SourceFile}
+	if r.AttributionMode == AttributeToPages && len(example.ConsumingPages) > 0 {
+		sources = example.ConsumingPages
+	}
+
+	for _, source := range sources {
+		// Update global counts
+		r.LanguageCounts[example.Language]++
+		r.DirectiveCounts[example.DirectiveName]++
 
-	// Update source-specific stats
-	if _, exists := r.SourcePathStats[example.SourceFile]; !exists {
-		r.SourcePathStats[example.SourceFile] = NewSourceStats()
+		// Update source-specific stats
+		if _, exists := r.SourcePathStats[source]; !exists {
+			r.SourcePathStats[source] = NewSourceStats()
+		}
+		stats := r.SourcePathStats[source]
+		stats.DirectiveCounts[example.DirectiveName]++
+		stats.LanguageCounts[example.Language]++
+		stats.OutputFiles = append(stats.OutputFiles, outputPath)
 	}
-	stats := r.SourcePathStats[example.SourceFile]
-	stats.DirectiveCounts[example.DirectiveName]++
-	stats.LanguageCounts[example.Language]++
-	stats.OutputFiles = append(stats.OutputFiles, outputPath)
 }
 
 // AddTraversedFile adds a file to the list of traversed files.
@@ -25,10 +25,22 @@ import (
 //   - string: The full path to the output file
 //   - error: Any error encountered during writing
 func WriteCodeExample(example CodeExample, outputDir string, rootPath string, dryRun bool, preserveDirs bool) (string, error) {
-	filename := GenerateOutputFilename(example)
+	return WriteCodeExampleWithLayout(example, outputDir, rootPath, dryRun, preserveDirs, LayoutFlat)
+}
+
+// WriteCodeExampleWithLayout writes a code example to a file in the output directory,
+// named by an output layout.
+//
+// It behaves like WriteCodeExample, but names the file with RenderLayout instead of
+// GenerateOutputFilename. The layout's path may include directories, which are created
+// under the output directory (or under the preserved directory with preserveDirs).
+//
+// Parameters:
+//   - layout: A layout preset (LayoutFlat or LayoutByLanguage) or a template
+func WriteCodeExampleWithLayout(example CodeExample, outputDir string, rootPath string, dryRun bool, preserveDirs bool, layout string) (string, error) {
+	filename := filepath.FromSlash(RenderLayout(layout, example))
 
 	var outputPath string
-	var targetDir string
 
 	if preserveDirs && rootPath != "" {
 		// Compute the relative path from rootPath to the source file's directory
@@ -48,12 +60,10 @@ func WriteCodeExample(example CodeExample, outputDir string, rootPath string, dr
 			return "", fmt.Errorf("failed to compute relative path: %w", err)
 		}
 
-		// Create the target directory preserving the structure
-		targetDir = filepath.Join(outputDir, relPath)
-		outputPath = filepath.Join(targetDir, filename)
+		// Write under the target directory preserving the structure
+		outputPath = filepath.Join(outputDir, relPath, filename)
 	} else {
-		// Flat structure - all files in the output directory
-		targetDir = outputDir
+		// Flat structure - the layout's path under the output directory
 		outputPath = filepath.Join(outputDir, filename)
 	}
 
@@ -61,7 +71,7 @@ func WriteCodeExample(example CodeExample, outputDir string, rootPath string, dr
 		return outputPath, nil
 	}
 
-	if err := os.MkdirAll(targetDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create output directory: %w", err)
 	}
 
@@ -0,0 +1,138 @@
+package examples_diff_stub
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hunkHeaderRegex matches a unified diff hunk header, e.g. "@@ -12,7 +12,9 @@ Heading".
+// The line counts are optional and default to 1.
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseDiff parses unified diff output, such as from git diff, into file diffs.
+//
+// Both git diffs and plain unified diffs (diff -u) are supported. Binary files,
+// mode changes, and renames without content changes produce file diffs without hunks.
+//
+// Parameters:
+//   - r: Reader for the diff
+//
+// Returns:
+//   - []FileDiff: The file diffs in order of appearance
+//   - error: Any error encountered reading or parsing the diff
+func ParseDiff(r io.Reader) ([]FileDiff, error) {
+	var files []FileDiff
+	var current *FileDiff
+	var hunk *Hunk
+	oldRemaining, newRemaining := 0, 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+
+		// Lines inside a hunk are counted, so removed lines starting with "--" aren't taken as headers
+		if hunk != nil && (oldRemaining > 0 || newRemaining > 0) {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				hunk.NewLines = append(hunk.NewLines, line[1:])
+				newRemaining--
+			case strings.HasPrefix(line, "-"):
+				hunk.OldLines = append(hunk.OldLines, line[1:])
+				oldRemaining--
+			case strings.HasPrefix(line, `\`):
+				// "\ No newline at end of file"
+			default:
+				// Context line; some tools strip the leading space from blank lines
+				text := strings.TrimPrefix(line, " ")
+				hunk.OldLines = append(hunk.OldLines, text)
+				hunk.NewLines = append(hunk.NewLines, text)
+				oldRemaining--
+				newRemaining--
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, FileDiff{})
+			current = &files[len(files)-1]
+			hunk = nil
+			current.OldPath, current.NewPath = parseGitDiffPaths(strings.TrimPrefix(line, "diff --git "))
+
+		case strings.HasPrefix(line, "--- "):
+			// Plain unified diffs have no "diff --git" line before the file headers
+			if current == nil || hunk != nil {
+				files = append(files, FileDiff{})
+				current = &files[len(files)-1]
+				hunk = nil
+			}
+			current.OldPath = parseFileHeaderPath(strings.TrimPrefix(line, "--- "), "a/")
+
+		case strings.HasPrefix(line, "+++ ") && current != nil:
+			current.NewPath = parseFileHeaderPath(strings.TrimPrefix(line, "+++ "), "b/")
+
+		case strings.HasPrefix(line, "@@ "):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: hunk header before file header", lineNum)
+			}
+			matches := hunkHeaderRegex.FindStringSubmatch(line)
+			if matches == nil {
+				return nil, fmt.Errorf("line %d: invalid hunk header: %s", lineNum, line)
+			}
+			current.Hunks = append(current.Hunks, Hunk{
+				OldStart: atoi(matches[1], 1),
+				NewStart: atoi(matches[3], 1),
+			})
+			hunk = &current.Hunks[len(current.Hunks)-1]
+			oldRemaining = atoi(matches[2], 1)
+			newRemaining = atoi(matches[4], 1)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read diff: %w", err)
+	}
+	return files, nil
+}
+
+// parseGitDiffPaths returns the old and new paths from the arguments of a "diff --git" line.
+//
+// These are only used when the diff has no "---" and "+++" headers, such as for pure
+// renames, so paths containing " b/" are not disambiguated.
+func parseGitDiffPaths(args string) (string, string) {
+	oldPath, newPath, found := strings.Cut(args, " b/")
+	if !found {
+		return "", ""
+	}
+	return strings.TrimPrefix(oldPath, "a/"), newPath
+}
+
+// parseFileHeaderPath returns the path from a "---" or "+++" header, without the git
+// prefix or a trailing timestamp. Returns "" for /dev/null.
+func parseFileHeaderPath(header string, prefix string) string {
+	path, _, _ := strings.Cut(header, "\t")
+	path = strings.TrimSpace(path)
+	if path == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(path, prefix)
+}
+
+// atoi parses a hunk header number, returning def for an empty value.
+func atoi(value string, def int) int {
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return def
+	}
+	return n
+}
@@ -0,0 +1,123 @@
+// Package examples_diff_stub provides functionality for summarizing code example changes in a diff.
+//
+// This package implements the "extract examples-diff-stub" subcommand, which reads a git
+// diff of a docs repository and generates a Markdown summary of the code examples that
+// were added, removed, or changed, for pasting into the pull request description.
+package examples_diff_stub
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// NewExamplesDiffStubCommand creates the examples-diff-stub subcommand.
+//
+// This command reads a unified diff from a file or stdin and reports the code examples
+// it adds, removes, or changes, with their page, language, and size.
+//
+// Usage:
+//
+//	git diff --unified=1000 main | extract examples-diff-stub
+//	extract examples-diff-stub changes.diff --format json
+//
+// Flags:
+//   - --format: Output format (markdown, text, json, or csv; default markdown)
+//   - --output-file: Write results to a file instead of stdout
+func NewExamplesDiffStubCommand() *cobra.Command {
+	var (
+		format     string
+		outputOpts output.Options
+	)
+
+	cmd := &cobra.Command{
+		Use:   "examples-diff-stub [diff-file]",
+		Short: "Summarize code example changes in a diff for a PR description",
+		Long: `Summarize the code examples added, removed, or changed in a git diff.
+
+This command reads a unified diff of a docs repository from a file, or from stdin if no
+file or "-" is given, and finds the code-block, literalinclude, and io-code-block
+directives in the changed RST files. It writes a Markdown summary with each example's
+page, line, language, and size, suitable for pasting into the pull request description
+so reviewers can focus on the example changes.
+
+Only directives whose opening line is inside a diff hunk are found. Generate the diff
+with more context, such as --unified=1000, so changes in the middle of long code blocks
+are attributed to their directive.
+
+Examples:
+  # Summarize the examples changed on the current branch
+  git diff --unified=1000 main...HEAD | extract examples-diff-stub
+
+  # Summarize a saved diff as JSON
+  extract examples-diff-stub changes.diff --format json
+
+  # Write the stub to a file
+  git diff --unified=1000 main...HEAD | extract examples-diff-stub --output-file pr-examples.md`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			diffPath := "-"
+			if len(args) == 1 {
+				diffPath = args[0]
+			}
+			outputOpts.Format = format
+			return runExamplesDiffStub(diffPath, cmd.InOrStdin(), outputOpts)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", string(output.FormatMarkdown), "Output format: markdown, text, json, or csv")
+	output.AddFileFlags(cmd, &outputOpts)
+
+	return cmd
+}
+
+// runExamplesDiffStub executes the examples-diff-stub operation.
+func runExamplesDiffStub(diffPath string, stdin io.Reader, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
+	summary, err := SummarizeDiffFile(diffPath, stdin)
+	if err != nil {
+		return err
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return PrintSummary(w, summary)
+}
+
+// SummarizeDiffFile reads a diff from a file, or from stdin if the path is "-", and
+// summarizes its code example changes.
+//
+// Parameters:
+//   - diffPath: Path to the diff file, or "-" for stdin
+//   - stdin: Reader to use for stdin
+//
+// Returns:
+//   - *Summary: The code example changes in the diff
+//   - error: Any error encountered reading or parsing the diff
+func SummarizeDiffFile(diffPath string, stdin io.Reader) (*Summary, error) {
+	reader := stdin
+	if diffPath != "-" {
+		file, err := os.Open(diffPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open diff %s: %w", diffPath, err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	files, err := ParseDiff(reader)
+	if err != nil {
+		return nil, err
+	}
+	return Summarize(files), nil
+}
@@ -0,0 +1,147 @@
+// Package examples_diff_stub provides tests for the examples-diff-stub functionality.
+package examples_diff_stub
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// expectedChanges are the example changes in both testdata diffs, which are the same
+// change generated with default and full context.
+var expectedChanges = []ExampleChange{
+	{Status: Changed, File: "source/connect.txt", Directive: "code-block", Language: "python", Line: 13, Lines: 3, OldLines: 2},
+	{Status: Added, File: "source/connect.txt", Directive: "code-block", Language: "javascript", Line: 21, Lines: 2},
+	{Status: Removed, File: "source/connect.txt", Directive: "literalinclude", Language: "python", Source: "/includes/examples/legacy.py", Line: 34},
+	{Status: Added, File: "source/includes/verify.rst", Directive: "code-block", Language: "shell", Line: 1, Lines: 1},
+}
+
+// TestSummarizeDiffFile tests summarizing diffs generated with default and full context.
+func TestSummarizeDiffFile(t *testing.T) {
+	for _, name := range []string{"default-context.diff", "full-context.diff"} {
+		t.Run(name, func(t *testing.T) {
+			diffPath := filepath.Join("..", "..", "..", "testdata", "extract-examples-diff-stub", name)
+
+			summary, err := SummarizeDiffFile(diffPath, nil)
+			if err != nil {
+				t.Fatalf("SummarizeDiffFile failed: %v", err)
+			}
+
+			// snooty.toml isn't an RST file
+			if summary.FilesScanned != 2 {
+				t.Errorf("Expected 2 files scanned, got %d", summary.FilesScanned)
+			}
+			if summary.Added != 2 || summary.Removed != 1 || summary.Changed != 1 {
+				t.Errorf("Expected 2 added, 1 removed, 1 changed, got %d, %d, %d", summary.Added, summary.Removed, summary.Changed)
+			}
+			if len(summary.Changes) != len(expectedChanges) {
+				t.Fatalf("Expected %d changes, got %d: %+v", len(expectedChanges), len(summary.Changes), summary.Changes)
+			}
+			for i, expected := range expectedChanges {
+				if summary.Changes[i] != expected {
+					t.Errorf("Change %d:\nexpected %+v\n     got %+v", i, expected, summary.Changes[i])
+				}
+			}
+		})
+	}
+}
+
+// TestSummarizeDiffFileFromStdin tests reading the diff from stdin.
+func TestSummarizeDiffFileFromStdin(t *testing.T) {
+	diff := `diff --git a/source/index.txt b/source/index.txt
+--- a/source/index.txt
++++ b/source/index.txt
+@@ -1,4 +1,4 @@
+ .. code-block:: go
+
+-   fmt.Println("hello")
++   fmt.Println("hello, world")
+`
+	summary, err := SummarizeDiffFile("-", strings.NewReader(diff))
+	if err != nil {
+		t.Fatalf("SummarizeDiffFile failed: %v", err)
+	}
+	if summary.Changed != 1 || summary.Total() != 1 {
+		t.Fatalf("Expected 1 changed example, got %+v", summary)
+	}
+	if summary.Changes[0].Language != "go" || summary.Changes[0].Line != 1 {
+		t.Errorf("Unexpected change: %+v", summary.Changes[0])
+	}
+}
+
+// TestParseDiff tests parsing hunks, including removed lines that look like file headers.
+func TestParseDiff(t *testing.T) {
+	diff := `--- old/page.rst	2025-01-01 00:00:00
++++ new/page.rst	2025-01-02 00:00:00
+@@ -5,2 +5,2 @@ Heading
+ context
+---- removed line that looks like a header
++added
+@@ -20 +19 @@
+-x
++y
+`
+	files, err := ParseDiff(strings.NewReader(diff))
+	if err != nil {
+		t.Fatalf("ParseDiff failed: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(files))
+	}
+	file := files[0]
+	if file.OldPath != "old/page.rst" || file.NewPath != "new/page.rst" {
+		t.Errorf("Unexpected paths: %q, %q", file.OldPath, file.NewPath)
+	}
+	if len(file.Hunks) != 2 {
+		t.Fatalf("Expected 2 hunks, got %d", len(file.Hunks))
+	}
+	hunk := file.Hunks[0]
+	if hunk.OldStart != 5 || hunk.NewStart != 5 {
+		t.Errorf("Unexpected hunk start: %d, %d", hunk.OldStart, hunk.NewStart)
+	}
+	if strings.Join(hunk.OldLines, "|") != "context|--- removed line that looks like a header" {
+		t.Errorf("Unexpected old lines: %q", hunk.OldLines)
+	}
+	if strings.Join(hunk.NewLines, "|") != "context|added" {
+		t.Errorf("Unexpected new lines: %q", hunk.NewLines)
+	}
+	if file.Hunks[1].OldStart != 20 || strings.Join(file.Hunks[1].NewLines, "|") != "y" {
+		t.Errorf("Unexpected second hunk: %+v", file.Hunks[1])
+	}
+}
+
+// TestPrintSummaryMarkdown tests the PR description stub.
+func TestPrintSummaryMarkdown(t *testing.T) {
+	summary := &Summary{FilesScanned: 2, Added: 2, Removed: 1, Changed: 1, Changes: expectedChanges}
+
+	var buf bytes.Buffer
+	if err := PrintSummary(output.NewWriter(&buf, output.FormatMarkdown), summary); err != nil {
+		t.Fatalf("PrintSummary failed: %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"## Code Example Changes",
+		"**2 added**, **1 removed**, **1 changed** across 2 pages.",
+		"Languages: python (2), javascript (1), shell (1)",
+		"| Status | Page | Line | Directive | Language | Size |",
+		"| changed | connect | 13 | code-block | python | 2 → 3 lines |",
+		"| removed | connect | 34 | literalinclude | python | /includes/examples/legacy.py |",
+		"| added | includes/verify | 1 | code-block | shell | 1 line |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	buf.Reset()
+	if err := PrintSummary(output.NewWriter(&buf, output.FormatMarkdown), &Summary{}); err != nil {
+		t.Fatalf("PrintSummary failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No code examples were added, removed, or changed.") {
+		t.Errorf("Unexpected output for an empty summary:\n%s", buf.String())
+	}
+}
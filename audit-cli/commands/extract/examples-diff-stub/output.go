@@ -0,0 +1,153 @@
+package examples_diff_stub
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// PrintSummary writes the summary in the writer's format.
+//
+// Markdown output is a stub for a pull request description: a heading, a one-line
+// summary, and a table of changed examples. Text output is the same table with a
+// summary header. JSON output is the full summary, and CSV output is the table.
+//
+// Parameters:
+//   - w: The output writer, which determines the format and destination
+//   - summary: The code example changes to print
+func PrintSummary(w *output.Writer, summary *Summary) error {
+	switch w.Format() {
+	case output.FormatJSON:
+		return w.WriteJSON(summary)
+	case output.FormatCSV:
+		return w.WriteTable(changesTable(summary))
+	case output.FormatMarkdown:
+		return printMarkdown(w, summary)
+	default:
+		return printText(w, summary)
+	}
+}
+
+// printMarkdown writes the summary as a pull request description stub.
+func printMarkdown(w *output.Writer, summary *Summary) error {
+	w.Println("## Code Example Changes")
+	w.Println()
+
+	if summary.Total() == 0 {
+		w.Println("No code examples were added, removed, or changed.")
+		return nil
+	}
+
+	w.Printf("%s across %d %s.\n", countsLine(summary), changedFiles(summary), plural(changedFiles(summary), "page", "pages"))
+	w.Println()
+	w.Printf("Languages: %s\n", languagesLine(summary))
+	w.Println()
+
+	table := changesTable(summary)
+	table.Title = ""
+	return w.WriteTable(table)
+}
+
+// printText writes the summary in human-readable text format.
+func printText(w *output.Writer, summary *Summary) error {
+	w.Println("============================================================")
+	w.Println(w.Colorize("CODE EXAMPLE CHANGES", output.Bold))
+	w.Println("============================================================")
+	w.Printf("RST Files In Diff: %d\n", summary.FilesScanned)
+	w.Printf("Added: %s\n", w.Colorize(output.FormatValue(summary.Added), output.Green))
+	w.Printf("Removed: %s\n", w.Colorize(output.FormatValue(summary.Removed), output.Red))
+	w.Printf("Changed: %s\n", w.Colorize(output.FormatValue(summary.Changed), output.Yellow))
+	w.Println("============================================================")
+	w.Println()
+
+	if summary.Total() == 0 {
+		w.Println("No code examples were added, removed, or changed.")
+		return nil
+	}
+
+	return w.WriteTable(changesTable(summary))
+}
+
+// changesTable builds the table of example changes, one row per example.
+func changesTable(summary *Summary) *output.Table {
+	table := output.NewTable("Changed Examples:",
+		output.Column{Header: "Status"},
+		output.Column{Header: "Page"},
+		output.Column{Header: "Line", Align: output.AlignRight},
+		output.Column{Header: "Directive"},
+		output.Column{Header: "Language"},
+		output.Column{Header: "Size"},
+	)
+	for _, change := range summary.Changes {
+		table.AddRow(string(change.Status), pageName(change.File), change.Line, change.Directive, change.Language, formatSize(change))
+	}
+	return table
+}
+
+// formatSize describes the size of an example: its line count, the change in line count,
+// or the referenced file for examples that aren't inline.
+func formatSize(change ExampleChange) string {
+	if change.Lines == 0 && change.OldLines == 0 && change.Source != "" {
+		return change.Source
+	}
+	if change.Status == Changed && change.OldLines != change.Lines {
+		return fmt.Sprintf("%d → %d lines", change.OldLines, change.Lines)
+	}
+	return fmt.Sprintf("%d %s", change.Lines, plural(change.Lines, "line", "lines"))
+}
+
+// countsLine returns e.g. "**2 added**, **1 changed**", omitting zero counts.
+func countsLine(summary *Summary) string {
+	var parts []string
+	for _, count := range []struct {
+		n     int
+		label string
+	}{{summary.Added, "added"}, {summary.Removed, "removed"}, {summary.Changed, "changed"}} {
+		if count.n > 0 {
+			parts = append(parts, fmt.Sprintf("**%d %s**", count.n, count.label))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// languagesLine returns the changed examples' languages with counts, most common first.
+func languagesLine(summary *Summary) string {
+	counts := make(map[string]int)
+	for _, change := range summary.Changes {
+		counts[change.Language]++
+	}
+	languages := make([]string, 0, len(counts))
+	for language := range counts {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if counts[languages[i]] != counts[languages[j]] {
+			return counts[languages[i]] > counts[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+
+	parts := make([]string, len(languages))
+	for i, language := range languages {
+		parts[i] = fmt.Sprintf("%s (%d)", language, counts[language])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// changedFiles returns the number of files with at least one example change.
+func changedFiles(summary *Summary) int {
+	files := make(map[string]bool)
+	for _, change := range summary.Changes {
+		files[change.File] = true
+	}
+	return len(files)
+}
+
+func plural(n int, singular string, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
@@ -0,0 +1,204 @@
+package examples_diff_stub
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	code_examples "github.com/mongodb/code-example-tooling/audit-cli/commands/extract/code-examples"
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// Summarize finds the code examples that were added, removed, or changed in each RST file of a diff.
+//
+// Each side of each hunk is parsed for code-block, literalinclude, and io-code-block directives.
+// Examples that appear unchanged on both sides are ignored. The remaining examples are paired by
+// directive, language, and referenced file, in order: pairs are reported as changed, and examples
+// with no pair as added or removed.
+//
+// Only directives whose opening line is inside a hunk are found, so a change to the middle of a
+// long code block is missed unless the diff has enough context (e.g. git diff --unified=1000).
+//
+// Parameters:
+//   - files: The file diffs to summarize
+//
+// Returns:
+//   - *Summary: The example changes, by file and line
+func Summarize(files []FileDiff) *Summary {
+	summary := &Summary{Changes: []ExampleChange{}}
+
+	for _, file := range files {
+		if !rst.ShouldProcessFile(file.Path()) {
+			continue
+		}
+		summary.FilesScanned++
+
+		var oldExamples, newExamples []Example
+		for _, hunk := range file.Hunks {
+			oldExamples = append(oldExamples, findExamples(hunk.OldLines, hunk.OldStart)...)
+			newExamples = append(newExamples, findExamples(hunk.NewLines, hunk.NewStart)...)
+		}
+
+		for _, change := range compareExamples(oldExamples, newExamples) {
+			change.File = file.Path()
+			summary.Changes = append(summary.Changes, change)
+			switch change.Status {
+			case Added:
+				summary.Added++
+			case Removed:
+				summary.Removed++
+			case Changed:
+				summary.Changed++
+			}
+		}
+	}
+
+	return summary
+}
+
+// findExamples parses the code examples in one side of a hunk. startLine is the line
+// number of the first line in the file, so examples report their line in the file.
+func findExamples(lines []string, startLine int) []Example {
+	directives, err := rst.ParseDirectivesFromReader(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		return nil
+	}
+
+	examples := make([]Example, 0, len(directives))
+	for _, directive := range directives {
+		example := Example{
+			Directive: string(directive.Type),
+			Line:      startLine + directive.LineNum - 1,
+		}
+
+		switch directive.Type {
+		case rst.CodeBlock:
+			example.Language = directive.Argument
+			if example.Language == "" {
+				example.Language = directive.Options["language"]
+			}
+			example.Lines = countLines(directive.Content)
+			example.signature = directive.Content
+		case rst.LiteralInclude:
+			example.Language = directive.Options["language"]
+			example.Source = directive.Argument
+		case rst.IoCodeBlock:
+			if input := directive.InputDirective; input != nil {
+				example.Language = input.Options["language"]
+				example.Source = input.Argument
+				example.Lines = countLines(input.Content)
+				example.signature = input.Content
+			}
+			if output := directive.OutputDirective; output != nil {
+				example.signature += "\x00" + output.Argument + "\x00" + output.Content
+			}
+		}
+
+		if example.Language == "" {
+			example.Language = code_examples.Undefined
+		}
+		example.Language = code_examples.NormalizeLanguage(example.Language)
+		example.signature = fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s",
+			example.Directive, example.Language, example.Source, formatOptions(directive.Options), example.signature)
+		examples = append(examples, example)
+	}
+	return examples
+}
+
+// compareExamples matches the examples from the old and new sides of a file's hunks and
+// returns the ones that were added, removed, or changed, ordered by line.
+func compareExamples(oldExamples []Example, newExamples []Example) []ExampleChange {
+	oldMatched := make([]bool, len(oldExamples))
+	newMatched := make([]bool, len(newExamples))
+
+	// Unchanged examples, such as those in context lines, appear identically on both sides
+	for i, newExample := range newExamples {
+		for j, oldExample := range oldExamples {
+			if !oldMatched[j] && oldExample.signature == newExample.signature {
+				oldMatched[j], newMatched[i] = true, true
+				break
+			}
+		}
+	}
+
+	var changes []ExampleChange
+	for i, newExample := range newExamples {
+		if newMatched[i] {
+			continue
+		}
+		change := newChange(Added, newExample)
+		for j, oldExample := range oldExamples {
+			if !oldMatched[j] && sameExample(oldExample, newExample) {
+				oldMatched[j] = true
+				change.Status = Changed
+				change.OldLines = oldExample.Lines
+				break
+			}
+		}
+		changes = append(changes, change)
+	}
+	for j, oldExample := range oldExamples {
+		if !oldMatched[j] {
+			changes = append(changes, newChange(Removed, oldExample))
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Line < changes[j].Line
+	})
+	return changes
+}
+
+// sameExample returns true if two examples are likely the same example before and after a change.
+func sameExample(a Example, b Example) bool {
+	return a.Directive == b.Directive && a.Language == b.Language && a.Source == b.Source
+}
+
+func newChange(status ChangeStatus, example Example) ExampleChange {
+	return ExampleChange{
+		Status:    status,
+		Directive: example.Directive,
+		Language:  example.Language,
+		Source:    example.Source,
+		Line:      example.Line,
+		Lines:     example.Lines,
+	}
+}
+
+// formatOptions returns directive options in a stable order, so option changes such
+// as a new :emphasize-lines: value count as changes.
+func formatOptions(options map[string]string) string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, ":%s: %s\n", key, options[key])
+	}
+	return b.String()
+}
+
+// countLines returns the number of lines in inline content.
+func countLines(content string) int {
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return 0
+	}
+	return strings.Count(content, "\n") + 1
+}
+
+// pageName returns a short name for a file in the summary: its path under source/,
+// without the extension, or the full path for files outside a source directory.
+func pageName(path string) string {
+	slashPath := filepath.ToSlash(path)
+	if _, rest, found := strings.Cut(slashPath, "/source/"); found {
+		slashPath = rest
+	} else if strings.HasPrefix(slashPath, "source/") {
+		slashPath = strings.TrimPrefix(slashPath, "source/")
+	}
+	return strings.TrimSuffix(slashPath, filepath.Ext(slashPath))
+}
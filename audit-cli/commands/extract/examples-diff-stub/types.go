@@ -0,0 +1,77 @@
+package examples_diff_stub
+
+// ChangeStatus is how a code example changed in a diff.
+type ChangeStatus string
+
+const (
+	// Added is an example that only appears on the new side of the diff
+	Added ChangeStatus = "added"
+	// Removed is an example that only appears on the old side of the diff
+	Removed ChangeStatus = "removed"
+	// Changed is an example whose content, language, or options changed
+	Changed ChangeStatus = "changed"
+)
+
+// FileDiff is the diff of one file, parsed from unified diff output.
+type FileDiff struct {
+	OldPath string // Path before the change; empty for new files
+	NewPath string // Path after the change; empty for deleted files
+	Hunks   []Hunk // Hunks in order of appearance
+}
+
+// Path returns the file's path after the change, or before it for deleted files.
+func (f FileDiff) Path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+	return f.OldPath
+}
+
+// Hunk is one hunk of a file diff, split into the old and new sides.
+//
+// Context lines appear on both sides, so each side is a contiguous part of the
+// old or new file that can be parsed on its own.
+type Hunk struct {
+	OldStart int      // First line of the old side in the old file (1-based)
+	NewStart int      // First line of the new side in the new file (1-based)
+	OldLines []string // Context and removed lines
+	NewLines []string // Context and added lines
+}
+
+// Example is a code example found on one side of a hunk.
+type Example struct {
+	Directive string `json:"directive"`        // code-block, literalinclude, or io-code-block
+	Language  string `json:"language"`         // Normalized language
+	Source    string `json:"source,omitempty"` // Referenced file, for literalinclude and io-code-block input
+	Line      int    `json:"line"`             // Line of the directive in the old or new file
+	Lines     int    `json:"lines"`            // Lines of inline content; 0 for file references
+
+	// signature identifies the example's content and options, for matching unchanged examples
+	signature string
+}
+
+// ExampleChange is a code example that was added, removed, or changed.
+type ExampleChange struct {
+	Status    ChangeStatus `json:"status"`
+	File      string       `json:"file"`
+	Directive string       `json:"directive"`
+	Language  string       `json:"language"`
+	Source    string       `json:"source,omitempty"`
+	Line      int          `json:"line"`      // Line in the new file, or in the old file for removed examples
+	Lines     int          `json:"lines"`     // Lines of inline content after the change, or before it for removed examples
+	OldLines  int          `json:"old_lines"` // Lines of inline content before the change, for changed examples
+}
+
+// Summary contains the code example changes in a diff.
+type Summary struct {
+	FilesScanned int             `json:"files_scanned"` // RST files in the diff
+	Added        int             `json:"added"`
+	Removed      int             `json:"removed"`
+	Changed      int             `json:"changed"`
+	Changes      []ExampleChange `json:"changes"`
+}
+
+// Total returns the number of examples that were added, removed, or changed.
+func (s *Summary) Total() int {
+	return s.Added + s.Removed + s.Changed
+}
@@ -4,13 +4,19 @@
 // Currently supports:
 //   - code-examples: Extract code examples from RST directives
 //   - procedures: Extract procedure variations from RST files
+//   - examples-diff-stub: Summarize code example changes in a diff for PR descriptions
+//   - sharedinclude-matrix: Map shared includes to the products that use them
+//   - extracts: Extract content blocks from extracts and release YAML files
 //
 // Future subcommands could include extracting tables, images, or other structured content.
 package extract
 
 import (
 	"github.com/mongodb/code-example-tooling/audit-cli/commands/extract/code-examples"
+	"github.com/mongodb/code-example-tooling/audit-cli/commands/extract/examples-diff-stub"
+	"github.com/mongodb/code-example-tooling/audit-cli/commands/extract/extracts"
 	"github.com/mongodb/code-example-tooling/audit-cli/commands/extract/procedures"
+	"github.com/mongodb/code-example-tooling/audit-cli/commands/extract/sharedinclude-matrix"
 	"github.com/spf13/cobra"
 )
 
@@ -26,7 +32,10 @@ func NewExtractCommand() *cobra.Command {
 
 Currently supports extracting code examples from directives like literalinclude,
 code-block, and io-code-block, as well as extracting procedure variations from
-composable tutorials, tabs, and procedure directives. Future subcommands may
+composable tutorials, tabs, and procedure directives. It can also summarize the
+code examples changed in a git diff for pull request descriptions, map shared
+includes to the products that use them, and render the content blocks of
+extracts and release YAML files. Future subcommands may
 support extracting other types of structured content such as tables, images,
 or metadata.`,
 	}
@@ -34,6 +43,9 @@ or metadata.`,
 	// Add subcommands
 	cmd.AddCommand(code_examples.NewCodeExamplesCommand())
 	cmd.AddCommand(procedures.NewProceduresCommand())
+	cmd.AddCommand(examples_diff_stub.NewExamplesDiffStubCommand())
+	cmd.AddCommand(sharedinclude_matrix.NewSharedIncludeMatrixCommand())
+	cmd.AddCommand(extracts.NewExtractsCommand())
 
 	return cmd
 }
@@ -0,0 +1,152 @@
+// Package extracts provides functionality for extracting extract and release content blocks from YAML files.
+//
+// This package implements the "extract extracts" subcommand. The build system turns each document of an
+// extracts-*.yaml or release-*.yaml file into an RST file named by its ref, which pages include as
+// /includes/extracts/{ref}.rst or /includes/release/{ref}.rst. This command performs the same
+// transformation, resolving inheritance and replacements, so writers can inspect exactly what each block
+// renders without running a docs build.
+//
+// With --expand-includes, include directives in each block are expanded inline, including includes of
+// other extract and release blocks.
+package extracts
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// NewExtractsCommand creates the extracts subcommand.
+//
+// This command extracts each content block in extracts and release YAML files to its own RST file.
+// Supports various flags for controlling behavior:
+//   - -o, --output: Output directory for extracted files
+//   - --ref: Extract only the block with this ref
+//   - --expand-includes: Expand include directives inline
+//   - --dry-run: Show what would be extracted without writing files
+//   - -v, --verbose: Show detailed processing information
+//   - --output-file: Write the summary to a file instead of stdout
+func NewExtractsCommand() *cobra.Command {
+	var (
+		outputDir      string
+		ref            string
+		expandIncludes bool
+		dryRun         bool
+		verbose        bool
+		outputOpts     output.Options
+	)
+
+	cmd := &cobra.Command{
+		Use:   "extracts [filepath|directory]",
+		Short: "Extract content blocks from extracts and release YAML files",
+		Long: `Extract content blocks from extracts-*.yaml and release-*.yaml files.
+
+The docs build turns each document in these files into an RST file named by its
+ref, which pages include as /includes/extracts/{ref}.rst or
+/includes/release/{ref}.rst. This command performs the same transformation, so
+you can inspect exactly what each block renders without running a build:
+  - Blocks that inherit from another block take its content and replacements
+  - {{name}} placeholders are replaced with the block's replacement values
+
+Each block is written to {kind}/{ref}.rst in the output directory. When given a
+directory, every extracts and release file in the tree is processed, and output
+paths mirror the YAML files' directories, such as
+source/includes/extracts/{ref}.rst.
+
+By default, include directives are preserved in the output. Use --expand-includes
+to expand them inline, including includes of other extract and release blocks.
+
+Examples:
+  # Extract every block in a file
+  extract extracts source/includes/extracts-install.yaml -o ./output
+
+  # Extract every block in a project
+  extract extracts path/to/project/source -o ./output
+
+  # Show what a single block renders, with its includes expanded
+  extract extracts source/includes/extracts-install.yaml --ref install-intro --expand-includes`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExtract(args[0], ref, outputDir, expandIncludes, dryRun, verbose, outputOpts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputDir, "output", "o", "./output", "Output directory for extracted files")
+	cmd.Flags().StringVar(&ref, "ref", "", "Extract only the block with this ref")
+	cmd.Flags().BoolVar(&expandIncludes, "expand-includes", false, "Expand include directives inline instead of preserving them")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be extracted without writing files")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Provide additional information during execution")
+	output.AddFileFlags(cmd, &outputOpts)
+
+	return cmd
+}
+
+// runExtract executes the extracts operation.
+func runExtract(filePath string, ref string, outputDir string, expandIncludes bool, dryRun bool, verbose bool, outputOpts output.Options) error {
+	if verbose {
+		fmt.Printf("Parsing content blocks from %s\n", filePath)
+		if expandIncludes {
+			fmt.Println("Expanding include directives inline")
+		}
+	}
+
+	extracts, report, err := Parse(filePath, expandIncludes)
+	if err != nil {
+		return err
+	}
+
+	if ref != "" {
+		var matching []Extract
+		for _, extract := range extracts {
+			if extract.Ref == ref {
+				matching = append(matching, extract)
+			}
+		}
+		if len(matching) == 0 {
+			return fmt.Errorf("no block with ref %s found in %s", ref, filePath)
+		}
+		extracts = matching
+	}
+
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	if len(extracts) == 0 {
+		w.Println("No extract or release blocks found.")
+		return nil
+	}
+
+	if verbose {
+		w.Printf("\nFound %d blocks in %d files:\n", len(extracts), report.FilesProcessed)
+		for _, extract := range extracts {
+			w.Printf("  %s (%s)\n", extract.Ref, extract.SourceFile)
+			if extract.Inherits != "" {
+				w.Printf("    Inherits from: %s\n", extract.Inherits)
+			}
+		}
+		w.Println()
+	}
+
+	filesWritten, err := WriteAllExtracts(extracts, outputDir, dryRun, verbose)
+	if err != nil {
+		return err
+	}
+	report.FilesWritten = filesWritten
+
+	if dryRun {
+		w.Printf("Dry run complete. Would have written %d files to %s\n", report.FilesWritten, outputDir)
+	} else {
+		w.Printf("Successfully extracted %d blocks to %s\n", report.FilesWritten, outputDir)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package extracts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testIncludesDir = "../../../testdata/input-files/source/includes"
+
+func extractsByRef(t *testing.T, extracts []Extract) map[string]Extract {
+	t.Helper()
+	byRef := make(map[string]Extract, len(extracts))
+	for _, extract := range extracts {
+		byRef[extract.Ref] = extract
+	}
+	return byRef
+}
+
+func TestParseFile(t *testing.T) {
+	extracts, report, err := Parse(filepath.Join(testIncludesDir, "extracts-test.yaml"), false)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(extracts) != 2 {
+		t.Fatalf("Expected 2 extracts, got %d", len(extracts))
+	}
+	if report.FilesProcessed != 1 {
+		t.Errorf("Expected 1 file processed, got %d", report.FilesProcessed)
+	}
+
+	intro := extracts[0]
+	if intro.Ref != "test-extract-intro" || intro.Kind != KindExtracts {
+		t.Errorf("Expected first extract to be test-extract-intro in extracts, got %s in %s", intro.Ref, intro.Kind)
+	}
+	if intro.OutputFile != filepath.Join("extracts", "test-extract-intro.rst") {
+		t.Errorf("Unexpected output file: %s", intro.OutputFile)
+	}
+	if !strings.Contains(intro.Content, ".. include:: /includes/intro.rst") {
+		t.Errorf("Expected include directive to be preserved, got:\n%s", intro.Content)
+	}
+}
+
+func TestParseFileExpandIncludes(t *testing.T) {
+	extracts, _, err := Parse(filepath.Join(testIncludesDir, "extracts-test.yaml"), true)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	intro := extractsByRef(t, extracts)["test-extract-intro"]
+	if strings.Contains(intro.Content, ".. include::") {
+		t.Errorf("Expected include directive to be expanded, got:\n%s", intro.Content)
+	}
+	if !strings.Contains(intro.Content, "This is an included introduction section.") {
+		t.Errorf("Expected included content, got:\n%s", intro.Content)
+	}
+	// literalinclude directives aren't includes
+	if !strings.Contains(intro.Content, ".. literalinclude:: /code-examples/example.py") {
+		t.Errorf("Expected literalinclude to be preserved, got:\n%s", intro.Content)
+	}
+}
+
+func TestParseInheritAndReplacements(t *testing.T) {
+	extracts, _, err := Parse(filepath.Join(testIncludesDir, "release-test.yaml"), true)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	byRef := extractsByRef(t, extracts)
+
+	base := byRef["test-release-base"]
+	if base.Kind != KindRelease || base.OutputFile != filepath.Join("release", "test-release-base.rst") {
+		t.Errorf("Unexpected kind %s or output file %s", base.Kind, base.OutputFile)
+	}
+	if !strings.HasPrefix(base.Content, "MongoDB 8.0 is available.") {
+		t.Errorf("Expected replacements to be applied, got:\n%s", base.Content)
+	}
+	// Includes of other extracts render that block, not the whole YAML file
+	if !strings.Contains(base.Content, "This extract references the examples file.") ||
+		strings.Contains(base.Content, "test-extract-intro") {
+		t.Errorf("Expected only the included extract's content, got:\n%s", base.Content)
+	}
+
+	patch := byRef["test-release-patch"]
+	if patch.Inherits != "test-release-base" {
+		t.Errorf("Expected test-release-patch to inherit from test-release-base, got %q", patch.Inherits)
+	}
+	if !strings.HasPrefix(patch.Content, "MongoDB 8.0.1 is available.") {
+		t.Errorf("Expected inherited content with merged replacements, got:\n%s", patch.Content)
+	}
+
+	loop := byRef["test-release-loop"]
+	if !strings.Contains(loop.Content, ".. include:: /includes/release/test-release-loop.rst") {
+		t.Errorf("Expected self-include to be preserved, got:\n%s", loop.Content)
+	}
+}
+
+func TestParseDirectory(t *testing.T) {
+	extracts, report, err := Parse(testIncludesDir, false)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if report.FilesProcessed != 2 {
+		t.Errorf("Expected 2 files processed, got %d", report.FilesProcessed)
+	}
+	if len(extracts) != 5 {
+		t.Errorf("Expected 5 extracts, got %d", len(extracts))
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", report.Warnings)
+	}
+}
+
+func TestParseRejectsOtherFiles(t *testing.T) {
+	if _, _, err := Parse(filepath.Join(testIncludesDir, "intro.rst"), false); err == nil {
+		t.Error("Expected an error for a file that isn't an extracts or release file")
+	}
+}
+
+func TestWriteAllExtracts(t *testing.T) {
+	extracts, _, err := Parse(filepath.Join(testIncludesDir, "release-test.yaml"), false)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	outputDir := t.TempDir()
+	written, err := WriteAllExtracts(extracts, outputDir, false, false)
+	if err != nil {
+		t.Fatalf("WriteAllExtracts failed: %v", err)
+	}
+	if written != 3 {
+		t.Errorf("Expected 3 files written, got %d", written)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "release", "test-release-patch.rst"))
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "MongoDB 8.0.1 is available.") {
+		t.Errorf("Unexpected output file content:\n%s", content)
+	}
+}
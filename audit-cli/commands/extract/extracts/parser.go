@@ -0,0 +1,349 @@
+package extracts
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+	"gopkg.in/yaml.v3"
+)
+
+// maxInheritDepth bounds inherit chains, which only loop when the YAML is broken.
+const maxInheritDepth = 10
+
+// KindOf returns the kind of YAML content file at the path, or "" if it isn't an extracts or release file.
+func KindOf(filePath string) string {
+	base := filepath.Base(filePath)
+	ext := filepath.Ext(base)
+	if ext != ".yaml" && ext != ".yml" {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(base, "extracts-"):
+		return KindExtracts
+	case strings.HasPrefix(base, "release-"):
+		return KindRelease
+	}
+	return ""
+}
+
+// FindYAMLFiles returns every extracts-*.yaml and release-*.yaml file in a directory tree.
+func FindYAMLFiles(rootDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(rootDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if filePath != rootDir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if KindOf(filePath) != "" {
+			files = append(files, filePath)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Parse extracts every content block from an extracts or release YAML file, or from every such file in a
+// directory tree.
+//
+// Each block is rendered the way the build system renders it: blocks that inherit from another block take
+// its fields, and {{name}} placeholders are replaced with the block's replacement values. Output files mirror
+// the paths the build system writes, {kind}/{ref}.rst next to the YAML file, relative to the directory.
+//
+// Parameters:
+//   - filePath: Path to a YAML file or a directory
+//   - expandIncludes: If true, expands .. include:: directives in each block inline
+//
+// Returns:
+//   - []Extract: The extracted content blocks
+//   - *ExtractionReport: Counts and warnings for blocks that were skipped or only partly resolved
+//   - error: Any error encountered reading the path
+func Parse(filePath string, expandIncludes bool) ([]Extract, *ExtractionReport, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to access path %s: %w", filePath, err)
+	}
+
+	rootDir := ""
+	files := []string{filePath}
+	if info.IsDir() {
+		rootDir = filePath
+		if files, err = FindYAMLFiles(filePath); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan %s: %w", filePath, err)
+		}
+	} else if KindOf(filePath) == "" {
+		return nil, nil, fmt.Errorf("%s is not an extracts-*.yaml or release-*.yaml file", filePath)
+	}
+
+	p := newParser(expandIncludes)
+	var extracts []Extract
+	defined := make(map[string]string) // output file -> YAML file that defined it
+
+	for _, file := range files {
+		docs, err := p.documents(file)
+		if err != nil {
+			if rootDir == "" {
+				return nil, nil, err
+			}
+			p.report.AddWarning(err.Error())
+			continue
+		}
+		p.report.FilesProcessed++
+
+		kind := KindOf(file)
+		outputDir := kind
+		if rootDir != "" {
+			rel, err := filepath.Rel(rootDir, filepath.Dir(file))
+			if err != nil {
+				return nil, nil, err
+			}
+			outputDir = filepath.Join(rel, kind)
+		}
+
+		for _, doc := range docs {
+			if doc.Ref == "" {
+				continue
+			}
+			outputFile := filepath.Join(outputDir, doc.Ref+".rst")
+			if previous, ok := defined[outputFile]; ok {
+				p.report.AddWarning(fmt.Sprintf("%s: duplicate ref %s, already defined in %s", file, doc.Ref, previous))
+				continue
+			}
+
+			extract, err := p.extract(file, doc.Ref)
+			if err != nil {
+				p.report.AddWarning(err.Error())
+				continue
+			}
+			extract.OutputFile = outputFile
+			defined[outputFile] = file
+			extracts = append(extracts, extract)
+		}
+	}
+
+	p.report.Extracts = len(extracts)
+	return extracts, p.report, nil
+}
+
+// parser renders content blocks, caching each YAML file's documents since inherited and included blocks are
+// often in other files.
+type parser struct {
+	expandIncludes bool
+	files          map[string][]extractDocument
+	report         *ExtractionReport
+}
+
+func newParser(expandIncludes bool) *parser {
+	return &parser{
+		expandIncludes: expandIncludes,
+		files:          make(map[string][]extractDocument),
+		report:         &ExtractionReport{},
+	}
+}
+
+// documents returns the documents of a multi-document YAML file.
+func (p *parser) documents(filePath string) ([]extractDocument, error) {
+	if docs, ok := p.files[filePath]; ok {
+		return docs, nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	var docs []extractDocument
+	decoder := yaml.NewDecoder(file)
+	for {
+		var doc extractDocument
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+		}
+		docs = append(docs, doc)
+	}
+
+	p.files[filePath] = docs
+	return docs, nil
+}
+
+// extract renders the block with the ref in the YAML file.
+func (p *parser) extract(filePath, ref string) (Extract, error) {
+	doc, err := p.resolve(filePath, ref, 0)
+	if err != nil {
+		return Extract{}, err
+	}
+
+	extract := Extract{
+		Ref:        ref,
+		Kind:       KindOf(filePath),
+		SourceFile: filePath,
+		Content:    p.render(filePath, ref, doc),
+	}
+	if doc.Inherit != nil {
+		extract.Inherits = doc.Inherit.Ref
+	}
+	return extract, nil
+}
+
+// render returns a resolved block's RST, with replacements applied and, if requested, includes expanded.
+func (p *parser) render(filePath, ref string, doc extractDocument) string {
+	content := doc.renderedContent()
+	if p.expandIncludes {
+		// Resolved include paths are absolute, so the block's own key has to be too to catch self-includes
+		key := filePath + "#" + ref
+		if absPath, err := filepath.Abs(filePath); err == nil {
+			key = absPath + "#" + ref
+		}
+		content = p.expand(filePath, content, map[string]bool{key: true})
+	}
+	return strings.TrimRight(content, "\n") + "\n"
+}
+
+// resolve returns the document with the ref, with the fields it inherits filled in. The block's own content
+// replaces its parent's, and its replacements are merged over its parent's.
+func (p *parser) resolve(filePath, ref string, depth int) (extractDocument, error) {
+	if depth > maxInheritDepth {
+		return extractDocument{}, fmt.Errorf("%s: inherit chain of %s is more than %d deep", filePath, ref, maxInheritDepth)
+	}
+
+	docs, err := p.documents(filePath)
+	if err != nil {
+		return extractDocument{}, err
+	}
+	var doc *extractDocument
+	for i := range docs {
+		if docs[i].Ref == ref {
+			doc = &docs[i]
+			break
+		}
+	}
+	if doc == nil {
+		return extractDocument{}, fmt.Errorf("%s: ref %s not found", filePath, ref)
+	}
+	if doc.Inherit == nil {
+		return *doc, nil
+	}
+
+	parentFile := filePath
+	if doc.Inherit.File != "" {
+		// Inherited files are named relative to the includes directory, next to this file
+		parentFile = filepath.Join(filepath.Dir(filePath), filepath.Base(doc.Inherit.File))
+	}
+	parent, err := p.resolve(parentFile, doc.Inherit.Ref, depth+1)
+	if err != nil {
+		return extractDocument{}, fmt.Errorf("%s: %s inherits from %s: %w", filePath, ref, doc.Inherit.Ref, err)
+	}
+
+	resolved := *doc
+	if resolved.Content == nil {
+		resolved.Content = parent.Content
+	}
+	resolved.Replacement = make(map[string]string, len(parent.Replacement)+len(doc.Replacement))
+	for name, value := range parent.Replacement {
+		resolved.Replacement[name] = value
+	}
+	for name, value := range doc.Replacement {
+		resolved.Replacement[name] = value
+	}
+	return resolved, nil
+}
+
+// expand replaces each .. include:: directive in content with the content it includes, indented to match the
+// directive. Includes of other extracts and release blocks are replaced with that block's rendered content.
+// Directives that can't be resolved, that include YAML steps files, or that would include themselves are kept
+// as-is.
+func (p *parser) expand(filePath string, content string, including map[string]bool) string {
+	lines := strings.Split(content, "\n")
+	result := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		matches := rst.IncludeDirectiveRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if len(matches) < 2 {
+			result = append(result, line)
+			continue
+		}
+
+		included, ok := p.included(filePath, strings.TrimSpace(matches[1]), including)
+		if !ok {
+			result = append(result, line)
+			continue
+		}
+
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		for _, includedLine := range strings.Split(strings.TrimRight(included, "\n"), "\n") {
+			if strings.TrimSpace(includedLine) == "" {
+				result = append(result, "")
+			} else {
+				result = append(result, indent+includedLine)
+			}
+		}
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// included returns the expanded content an include directive in filePath includes.
+func (p *parser) included(filePath, includePath string, including map[string]bool) (string, bool) {
+	resolvedPath, err := rst.ResolveIncludePath(filePath, includePath)
+	if err != nil {
+		p.report.AddWarning(fmt.Sprintf("%s: could not resolve include %s", filePath, includePath))
+		return "", false
+	}
+
+	key := resolvedPath
+	var content string
+	if KindOf(resolvedPath) != "" {
+		ref := strings.TrimSuffix(path.Base(includePath), path.Ext(includePath))
+		key = resolvedPath + "#" + ref
+		if including[key] {
+			return "", false
+		}
+		doc, err := p.resolve(resolvedPath, ref, 0)
+		if err != nil {
+			p.report.AddWarning(fmt.Sprintf("%s: could not resolve include %s: %v", filePath, includePath, err))
+			return "", false
+		}
+		content = doc.renderedContent()
+	} else {
+		if including[key] || strings.HasSuffix(resolvedPath, ".yaml") {
+			return "", false
+		}
+		data, err := os.ReadFile(resolvedPath)
+		if err != nil {
+			p.report.AddWarning(fmt.Sprintf("%s: could not read include %s: %v", filePath, includePath, err))
+			return "", false
+		}
+		content = string(data)
+	}
+
+	including[key] = true
+	defer delete(including, key)
+	return p.expand(resolvedPath, content, including), true
+}
+
+// renderedContent returns a resolved document's content with its replacements applied.
+func (doc extractDocument) renderedContent() string {
+	if doc.Content == nil {
+		return ""
+	}
+	content := *doc.Content
+	for name, value := range doc.Replacement {
+		content = strings.ReplaceAll(content, "{{"+name+"}}", value)
+	}
+	return content
+}
@@ -0,0 +1,44 @@
+package extracts
+
+// Kinds of YAML content files, named for the directory the build system writes their content blocks to.
+const (
+	KindExtracts = "extracts" // extracts-*.yaml, included as /includes/extracts/{ref}.rst
+	KindRelease  = "release"  // release-*.yaml, included as /includes/release/{ref}.rst
+)
+
+// Extract represents a single content block from an extracts or release YAML file.
+type Extract struct {
+	Ref        string // The block's ref, which names the RST file the build system writes
+	Kind       string // KindExtracts or KindRelease
+	SourceFile string // Path to the YAML file that defines the block
+	Inherits   string // The ref the block inherits from, if any
+	Content    string // The RST the block renders, after inheritance and replacements
+	OutputFile string // Path to the output file, relative to the output directory
+}
+
+// extractDocument is one document of a multi-document extracts or release YAML file.
+type extractDocument struct {
+	Ref         string            `yaml:"ref"`
+	Content     *string           `yaml:"content"`
+	Inherit     *inheritSpec      `yaml:"inherit"`
+	Replacement map[string]string `yaml:"replacement"`
+}
+
+// inheritSpec names the block a block inherits from. An empty File means the same YAML file.
+type inheritSpec struct {
+	Ref  string `yaml:"ref"`
+	File string `yaml:"file"`
+}
+
+// ExtractionReport contains statistics about the extraction operation.
+type ExtractionReport struct {
+	FilesProcessed int      // Number of YAML files processed
+	Extracts       int      // Number of content blocks extracted
+	FilesWritten   int      // Number of output files written
+	Warnings       []string // Blocks that were skipped or only partly resolved
+}
+
+// AddWarning adds a warning to the report.
+func (r *ExtractionReport) AddWarning(warning string) {
+	r.Warnings = append(r.Warnings, warning)
+}
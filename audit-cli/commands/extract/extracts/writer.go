@@ -0,0 +1,67 @@
+package extracts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteExtract writes a content block to its output file.
+//
+// Parameters:
+//   - extract: The content block to write
+//   - outputDir: Directory the block's output path is relative to
+//   - dryRun: If true, don't actually write the file
+//
+// Returns:
+//   - error: Any error encountered during writing
+func WriteExtract(extract Extract, outputDir string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+
+	outputPath := filepath.Join(outputDir, extract.OutputFile)
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := os.WriteFile(outputPath, []byte(extract.Content), 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", outputPath, err)
+	}
+
+	return nil
+}
+
+// WriteAllExtracts writes all content blocks to files.
+//
+// Parameters:
+//   - extracts: Content blocks to write
+//   - outputDir: Directory where files should be written
+//   - dryRun: If true, don't actually write files
+//   - verbose: If true, print each file written
+//
+// Returns:
+//   - int: Number of files written (or would be written in dry run mode)
+//   - error: Any error encountered during writing
+func WriteAllExtracts(extracts []Extract, outputDir string, dryRun bool, verbose bool) (int, error) {
+	filesWritten := 0
+
+	for _, extract := range extracts {
+		if err := WriteExtract(extract, outputDir, dryRun); err != nil {
+			return filesWritten, err
+		}
+
+		if verbose || dryRun {
+			outputPath := filepath.Join(outputDir, extract.OutputFile)
+			if dryRun {
+				fmt.Printf("  [DRY RUN] Would write: %s\n", outputPath)
+			} else {
+				fmt.Printf("  Wrote: %s\n", outputPath)
+			}
+		}
+
+		filesWritten++
+	}
+
+	return filesWritten, nil
+}
@@ -0,0 +1,199 @@
+package procedures
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// IndexFileName is the name of the global index file written to the output directory
+// when extracting procedures from a directory.
+const IndexFileName = "index.json"
+
+// ExtractionIndex lists every procedure extracted from a directory, so the output can be
+// navigated without walking the output tree.
+type ExtractionIndex struct {
+	Root           string       `json:"root"`            // Directory the pages were read from
+	PagesProcessed int          `json:"pages_processed"` // Number of pages parsed
+	PagesWithProcs int          `json:"pages_with_procedures"`
+	Procedures     []IndexEntry `json:"procedures"`
+}
+
+// IndexEntry describes a single extracted procedure in the index.
+type IndexEntry struct {
+	Page       string   `json:"page"`                 // Page path relative to the root directory
+	Title      string   `json:"title"`                // Procedure heading
+	Steps      int      `json:"steps"`                // Number of top-level steps
+	Selections []string `json:"selections,omitempty"` // Selections the procedure appears in
+	OutputFile string   `json:"output_file"`          // Output path relative to the output directory
+}
+
+// FindPages returns all RST pages under rootDir, sorted by path.
+//
+// Files in "includes" directories are skipped because they are fragments that are
+// extracted as part of the pages that include them (with --expand-includes).
+//
+// Parameters:
+//   - rootDir: Directory to search, such as a monorepo content directory
+//
+// Returns:
+//   - []string: Paths of the pages found
+//   - error: Any error encountered while walking the directory
+func FindPages(rootDir string) ([]string, error) {
+	files, err := rst.TraverseDirectory(rootDir, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to traverse directory %s: %w", rootDir, err)
+	}
+
+	var pages []string
+	for _, file := range files {
+		if !rst.ShouldProcessFile(file) || isInIncludesDirectory(rootDir, file) {
+			continue
+		}
+		pages = append(pages, file)
+	}
+	sort.Strings(pages)
+	return pages, nil
+}
+
+// isInIncludesDirectory reports whether any directory between rootDir and file is named "includes".
+func isInIncludesDirectory(rootDir string, file string) bool {
+	relPath, err := filepath.Rel(rootDir, file)
+	if err != nil {
+		return false
+	}
+	for _, part := range strings.Split(filepath.Dir(relPath), string(filepath.Separator)) {
+		if part == "includes" {
+			return true
+		}
+	}
+	return false
+}
+
+// PageOutputDir returns the directory where procedures from a page are written: the page's
+// path relative to rootDir, without its extension, under outputDir.
+//
+// Example: rootDir "content", page "content/manual/source/install.txt", outputDir "out"
+// gives "out/manual/source/install".
+func PageOutputDir(rootDir string, outputDir string, page string) (string, error) {
+	relPath, err := filepath.Rel(rootDir, page)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s relative to %s: %w", page, rootDir, err)
+	}
+	return filepath.Join(outputDir, strings.TrimSuffix(relPath, filepath.Ext(relPath))), nil
+}
+
+// RunBatchExtract extracts procedures from every page under rootDir.
+//
+// Output is organized by page path, so the procedures from "manual/source/install.txt" are
+// written to "{outputDir}/manual/source/install/". An index of all extracted procedures is
+// written to "{outputDir}/index.json". Pages that can't be parsed are recorded in the report
+// and skipped.
+//
+// Parameters:
+//   - rootDir: Directory to process recursively
+//   - selection: Extract only procedures that appear in this selection (empty for all)
+//   - outputDir: Directory where files are written
+//   - dryRun: If true, don't write any files
+//   - verbose: If true, print each file as it's written
+//   - expandIncludes: If true, expand include directives inline
+//
+// Returns:
+//   - *ExtractionReport: Statistics about the extraction
+//   - *ExtractionIndex: The index of extracted procedures
+//   - error: Any error that stopped the extraction
+func RunBatchExtract(rootDir string, selection string, outputDir string, dryRun bool, verbose bool, expandIncludes bool) (*ExtractionReport, *ExtractionIndex, error) {
+	pages, err := FindPages(rootDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if verbose {
+		fmt.Printf("Found %d pages to process in %s\n", len(pages), rootDir)
+	}
+
+	report := NewExtractionReport()
+	index := &ExtractionIndex{Root: rootDir, Procedures: []IndexEntry{}}
+
+	for _, page := range pages {
+		if verbose {
+			fmt.Printf("Processing: %s\n", page)
+		}
+
+		variations, err := ParseFile(page, selection, expandIncludes)
+		report.FilesProcessed++
+		if err != nil {
+			report.AddError(fmt.Sprintf("%s: %v", page, err))
+			continue
+		}
+		if len(variations) == 0 {
+			continue
+		}
+
+		pageDir, err := PageOutputDir(rootDir, outputDir, page)
+		if err != nil {
+			report.AddError(err.Error())
+			continue
+		}
+		written, err := WriteAllVariations(variations, pageDir, dryRun, verbose)
+		report.FilesWritten += written
+		if err != nil {
+			report.AddError(fmt.Sprintf("%s: %v", page, err))
+			continue
+		}
+
+		relPage, _ := filepath.Rel(rootDir, page)
+		relDir, _ := filepath.Rel(outputDir, pageDir)
+		for _, v := range variations {
+			entry := IndexEntry{
+				Page:       filepath.ToSlash(relPage),
+				Title:      v.Procedure.Title,
+				Steps:      len(v.Procedure.Steps),
+				OutputFile: filepath.ToSlash(filepath.Join(relDir, v.OutputFile)),
+			}
+			if v.VariationName != "" {
+				entry.Selections = strings.Split(v.VariationName, "; ")
+			}
+			index.Procedures = append(index.Procedures, entry)
+		}
+		report.TotalProcedures += len(variations)
+		report.TotalVariations += len(variations)
+		index.PagesWithProcs++
+	}
+	index.PagesProcessed = report.FilesProcessed
+
+	if dryRun {
+		fmt.Printf("Would write: %s\n", filepath.Join(outputDir, IndexFileName))
+		return report, index, nil
+	}
+
+	if err := WriteIndex(index, outputDir); err != nil {
+		return report, index, err
+	}
+
+	return report, index, nil
+}
+
+// WriteIndex writes the extraction index as JSON to {outputDir}/index.json.
+func WriteIndex(index *ExtractionIndex, outputDir string) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	indexPath := filepath.Join(outputDir, IndexFileName)
+	if err := os.WriteFile(indexPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write index %s: %w", indexPath, err)
+	}
+
+	return nil
+}
@@ -11,14 +11,20 @@
 //
 //	{heading}-{selection}.rst
 //
+// When given a directory, such as a monorepo content directory, every page in the tree is
+// processed. Output is organized by page path, with a global index.json listing every
+// extracted procedure.
+//
 // Supports filtering to extract only specific variations using the --selection flag.
 package procedures
 
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -27,9 +33,10 @@ import (
 // This command extracts procedure variations from RST files and writes them to
 // individual files in the output directory. Supports various flags for controlling behavior:
 //   - --selection: Extract only a specific variation (by selection or tabid)
-//   - -o, --output: Output directory for extracted files
+//   - -o, --output: Output directory for extracted files (organized by page path for directories)
 //   - --dry-run: Show what would be extracted without writing files
 //   - -v, --verbose: Show detailed processing information
+//   - --output-file: Write the summary to a file instead of stdout
 func NewProceduresCommand() *cobra.Command {
 	var (
 		selection         string
@@ -39,10 +46,11 @@ func NewProceduresCommand() *cobra.Command {
 		expandIncludes    bool
 		showSteps         bool
 		showSubProcedures bool
+		outputOpts        output.Options
 	)
 
 	cmd := &cobra.Command{
-		Use:   "procedures [filepath]",
+		Use:   "procedures [filepath|directory]",
 		Short: "Extract procedure variations from reStructuredText files",
 		Long: `Extract procedure variations from reStructuredText files.
 
@@ -59,11 +67,19 @@ The output files are named using the format: {heading}-{selection}.rst
 For example: "connect-to-cluster-python.rst", "create-index-drivers.rst"
 
 By default, include directives are preserved in the output. Use --expand-includes
-to inline the content of included files.`,
+to inline the content of included files.
+
+If the path is a directory, such as a monorepo content directory, all pages in the
+tree are processed (files in "includes" directories are skipped). Procedures are
+written to a subdirectory per page, matching the page's path:
+
+  {output}/{page-path-without-extension}/{procedure}.rst
+
+and a global index of every extracted procedure is written to {output}/index.json.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			filePath := args[0]
-			return runExtract(filePath, selection, outputDir, dryRun, verbose, expandIncludes, showSteps, showSubProcedures)
+			return runExtract(filePath, selection, outputDir, dryRun, verbose, expandIncludes, showSteps, showSubProcedures, outputOpts)
 		},
 	}
 
@@ -74,20 +90,27 @@ to inline the content of included files.`,
 	cmd.Flags().BoolVar(&expandIncludes, "expand-includes", false, "Expand include directives inline instead of preserving them")
 	cmd.Flags().BoolVar(&showSteps, "show-steps", false, "Show detailed information about each step in the procedure")
 	cmd.Flags().BoolVar(&showSubProcedures, "show-sub-procedures", false, "Show information about detected sub-procedures within steps")
+	output.AddFileFlags(cmd, &outputOpts)
 
 	return cmd
 }
 
 // runExtract executes the extraction operation.
-func runExtract(filePath string, selection string, outputDir string, dryRun bool, verbose bool, expandIncludes bool, showSteps bool, showSubProcedures bool) error {
+func runExtract(filePath string, selection string, outputDir string, dryRun bool, verbose bool, expandIncludes bool, showSteps bool, showSubProcedures bool, outputOpts output.Options) error {
 	// Verify the file exists
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to access path %s: %w", filePath, err)
 	}
 
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
 	if fileInfo.IsDir() {
-		return fmt.Errorf("path %s is a directory; please specify a file", filePath)
+		return runBatchExtract(w, filePath, selection, outputDir, dryRun, verbose, expandIncludes)
 	}
 
 	// Parse the file and extract procedure variations
@@ -104,32 +127,32 @@ func runExtract(filePath string, selection string, outputDir string, dryRun bool
 	}
 
 	if len(variations) == 0 {
-		fmt.Println("No procedures found in the file.")
+		w.Println("No procedures found in the file.")
 		return nil
 	}
 
 	// Report what was found
 	if verbose || dryRun {
-		fmt.Printf("\nFound %d unique procedures:\n", len(variations))
+		w.Printf("\nFound %d unique procedures:\n", len(variations))
 		for i, v := range variations {
-			fmt.Printf("\n%d. %s\n", i+1, v.Procedure.Title)
-			fmt.Printf("   Output file: %s\n", v.OutputFile)
-			fmt.Printf("   Steps: %d\n", len(v.Procedure.Steps))
+			w.Printf("\n%d. %s\n", i+1, v.Procedure.Title)
+			w.Printf("   Output file: %s\n", v.OutputFile)
+			w.Printf("   Steps: %d\n", len(v.Procedure.Steps))
 
 			if v.VariationName != "" {
 				// Split the selections and format as a list
 				selections := strings.Split(v.VariationName, "; ")
-				fmt.Printf("   Appears in %d selections:\n", len(selections))
+				w.Printf("   Appears in %d selections:\n", len(selections))
 				for _, sel := range selections {
-					fmt.Printf("     - %s\n", sel)
+					w.Printf("     - %s\n", sel)
 				}
 			} else {
-				fmt.Printf("   Appears in: (no specific selections)\n")
+				w.Printf("   Appears in: (no specific selections)\n")
 			}
 
 			// Show step details if requested
 			if showSteps {
-				fmt.Printf("\n   Step Details:\n")
+				w.Printf("\n   Step Details:\n")
 				for stepIdx, step := range v.Procedure.Steps {
 					// Check if the title already contains numbering
 					hasNumbering := false
@@ -144,9 +167,9 @@ func runExtract(filePath string, selection string, outputDir string, dryRun bool
 					}
 
 					if hasNumbering {
-						fmt.Printf("   - %s\n", title)
+						w.Printf("   - %s\n", title)
 					} else {
-						fmt.Printf("   %d. %s\n", stepIdx+1, title)
+						w.Printf("   %d. %s\n", stepIdx+1, title)
 					}
 
 					if len(step.SubProcedures) > 0 {
@@ -154,28 +177,28 @@ func runExtract(filePath string, selection string, outputDir string, dryRun bool
 						for _, subProc := range step.SubProcedures {
 							totalSubSteps += len(subProc.Steps)
 						}
-						fmt.Printf("      Contains %d sub-procedures with a total of %d sub-steps\n", len(step.SubProcedures), totalSubSteps)
+						w.Printf("      Contains %d sub-procedures with a total of %d sub-steps\n", len(step.SubProcedures), totalSubSteps)
 					}
 					if len(step.Variations) > 0 {
-						fmt.Printf("      Contains %d variations\n", len(step.Variations))
+						w.Printf("      Contains %d variations\n", len(step.Variations))
 					}
 				}
 			}
 
 			// Show sub-procedure information if requested
 			if showSubProcedures && v.Procedure.HasSubSteps {
-				fmt.Printf("\n   Sub-Procedures:\n")
+				w.Printf("\n   Sub-Procedures:\n")
 				for stepIdx, step := range v.Procedure.Steps {
 					if len(step.SubProcedures) > 0 {
 						totalSubSteps := 0
 						for _, subProc := range step.SubProcedures {
 							totalSubSteps += len(subProc.Steps)
 						}
-						fmt.Printf("   Step %d (%s) contains %d sub-procedures with a total of %d sub-steps\n",
+						w.Printf("   Step %d (%s) contains %d sub-procedures with a total of %d sub-steps\n",
 							stepIdx+1, step.Title, len(step.SubProcedures), totalSubSteps)
 
 						for subProcIdx, subProc := range step.SubProcedures {
-							fmt.Printf("\n      Sub-procedure %d (%d steps):\n", subProcIdx+1, len(subProc.Steps))
+							w.Printf("\n      Sub-procedure %d (%d steps):\n", subProcIdx+1, len(subProc.Steps))
 							for subStepIdx, subStep := range subProc.Steps {
 								// Use the appropriate marker based on list type
 								marker := ""
@@ -197,14 +220,14 @@ func runExtract(filePath string, selection string, outputDir string, dryRun bool
 									}
 								}
 
-								fmt.Printf("         %s. %s\n", marker, title)
+								w.Printf("         %s. %s\n", marker, title)
 							}
 						}
 					}
 				}
 			}
 		}
-		fmt.Println()
+		w.Println()
 	}
 
 	// Write the variations
@@ -215,9 +238,32 @@ func runExtract(filePath string, selection string, outputDir string, dryRun bool
 
 	// Print summary
 	if dryRun {
-		fmt.Printf("Dry run complete. Would have written %d files to %s\n", len(variations), outputDir)
+		w.Printf("Dry run complete. Would have written %d files to %s\n", len(variations), outputDir)
+	} else {
+		w.Printf("Successfully extracted %d unique procedures to %s\n", filesWritten, outputDir)
+	}
+
+	return nil
+}
+
+// runBatchExtract extracts procedures from every page in a directory and writes a summary.
+func runBatchExtract(w *output.Writer, rootDir string, selection string, outputDir string, dryRun bool, verbose bool, expandIncludes bool) error {
+	report, index, err := RunBatchExtract(rootDir, selection, outputDir, dryRun, verbose, expandIncludes)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range report.Errors {
+		fmt.Fprintf(os.Stderr, "Warning: failed to extract procedures from %s\n", e)
+	}
+
+	if dryRun {
+		w.Printf("Dry run complete. Would have written %d procedures from %d of %d pages to %s\n",
+			report.FilesWritten, index.PagesWithProcs, report.FilesProcessed, outputDir)
 	} else {
-		fmt.Printf("Successfully extracted %d unique procedures to %s\n", filesWritten, outputDir)
+		w.Printf("Successfully extracted %d unique procedures from %d of %d pages to %s\n",
+			report.FilesWritten, index.PagesWithProcs, report.FilesProcessed, outputDir)
+		w.Printf("Index written to %s\n", filepath.Join(outputDir, IndexFileName))
 	}
 
 	return nil
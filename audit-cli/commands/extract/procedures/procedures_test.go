@@ -1,6 +1,7 @@
 package procedures
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -369,3 +370,115 @@ func TestParseTabsWithProcedures(t *testing.T) {
 
 	t.Logf("Found %d unique procedures from tabs", len(variations))
 }
+
+func TestFindPagesSkipsIncludes(t *testing.T) {
+	rootDir := t.TempDir()
+	for _, path := range []string{
+		"manual/source/install.txt",
+		"manual/source/includes/steps-install.rst",
+		"atlas/source/index.rst",
+		"atlas/source/images/diagram.png",
+	} {
+		fullPath := filepath.Join(rootDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte("Title\n=====\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	pages, err := FindPages(rootDir)
+	if err != nil {
+		t.Fatalf("FindPages failed: %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(rootDir, "atlas/source/index.rst"),
+		filepath.Join(rootDir, "manual/source/install.txt"),
+	}
+	if len(pages) != len(expected) {
+		t.Fatalf("Expected %d pages, got %d: %v", len(expected), len(pages), pages)
+	}
+	for i := range expected {
+		if pages[i] != expected[i] {
+			t.Errorf("Expected page %d to be %s, got %s", i, expected[i], pages[i])
+		}
+	}
+}
+
+func TestRunBatchExtract(t *testing.T) {
+	rootDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	source, err := os.ReadFile("../../../testdata/input-files/source/procedure-test.rst")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	pagePath := filepath.Join(rootDir, "manual", "source", "tutorial.txt")
+	if err := os.MkdirAll(filepath.Dir(pagePath), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(pagePath, source, 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+	// A page without procedures is processed but doesn't appear in the index
+	if err := os.WriteFile(filepath.Join(rootDir, "manual", "source", "index.txt"), []byte("Home\n====\n\nWelcome.\n"), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+
+	report, index, err := RunBatchExtract(rootDir, "", outputDir, false, false, false)
+	if err != nil {
+		t.Fatalf("RunBatchExtract failed: %v", err)
+	}
+
+	if report.FilesProcessed != 2 {
+		t.Errorf("Expected 2 pages processed, got %d", report.FilesProcessed)
+	}
+	if index.PagesWithProcs != 1 {
+		t.Errorf("Expected 1 page with procedures, got %d", index.PagesWithProcs)
+	}
+	if report.FilesWritten != 5 || len(index.Procedures) != 5 {
+		t.Errorf("Expected 5 procedures written and indexed, got %d written and %d indexed", report.FilesWritten, len(index.Procedures))
+	}
+
+	for _, entry := range index.Procedures {
+		if entry.Page != "manual/source/tutorial.txt" {
+			t.Errorf("Expected page manual/source/tutorial.txt, got %s", entry.Page)
+		}
+		if !strings.HasPrefix(entry.OutputFile, "manual/source/tutorial/") {
+			t.Errorf("Expected output file under manual/source/tutorial/, got %s", entry.OutputFile)
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(entry.OutputFile))); err != nil {
+			t.Errorf("Expected output file %s to exist: %v", entry.OutputFile, err)
+		}
+	}
+
+	// The index is written to the root of the output directory
+	data, err := os.ReadFile(filepath.Join(outputDir, IndexFileName))
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	var written ExtractionIndex
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("Failed to parse index: %v", err)
+	}
+	if len(written.Procedures) != len(index.Procedures) {
+		t.Errorf("Expected index file to list %d procedures, got %d", len(index.Procedures), len(written.Procedures))
+	}
+}
+
+func TestRunBatchExtractDryRun(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "output")
+
+	report, index, err := RunBatchExtract("../../../testdata/input-files/source", "", outputDir, true, false, false)
+	if err != nil {
+		t.Fatalf("RunBatchExtract failed: %v", err)
+	}
+	if len(index.Procedures) == 0 || report.FilesWritten != len(index.Procedures) {
+		t.Errorf("Expected procedures to be counted in dry run, got %d written and %d indexed", report.FilesWritten, len(index.Procedures))
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Error("Dry run should not create the output directory")
+	}
+}
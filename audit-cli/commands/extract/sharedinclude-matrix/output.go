@@ -0,0 +1,38 @@
+package sharedinclude_matrix
+
+import (
+	"fmt"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// PrintMatrix writes one row per shared include, with the number of products that use
+// it, its total usages, and a column of usage counts for each product. A product's cell
+// is 0 if it doesn't use the include.
+func PrintMatrix(w *output.Writer, matrix *Matrix) error {
+	if len(matrix.Includes) == 0 && w.Format() == output.FormatText {
+		w.Println("No sharedinclude directives found")
+		return nil
+	}
+
+	columns := []output.Column{
+		{Header: "Include"},
+		{Header: "Products", Align: output.AlignRight},
+		{Header: "Total", Align: output.AlignRight},
+	}
+	for _, product := range matrix.Products {
+		columns = append(columns, output.Column{Header: product, Key: product, Align: output.AlignRight})
+	}
+
+	table := output.NewTable("Shared Include Usage by Product:", columns...)
+	for _, include := range matrix.Includes {
+		row := []interface{}{include, matrix.Consumers(include), matrix.Total(include)}
+		for _, product := range matrix.Products {
+			row = append(row, matrix.Usages[include][product])
+		}
+		table.AddRow(row...)
+	}
+	table.Footer = fmt.Sprintf("%d shared includes used by %d products", len(matrix.Includes), len(matrix.Products))
+
+	return w.WriteTable(table)
+}
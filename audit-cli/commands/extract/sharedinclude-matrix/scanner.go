@@ -0,0 +1,134 @@
+package sharedinclude_matrix
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/projectinfo"
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
+)
+
+// scannedExtensions are the files searched for sharedinclude directives. YAML files are
+// included because extract and step files contain RST within their content blocks.
+var scannedExtensions = map[string]bool{
+	".txt":  true,
+	".rst":  true,
+	".yaml": true,
+	".yml":  true,
+}
+
+// BuildMatrix finds the sharedinclude directives in every product in the monorepo.
+//
+// Each directory under content/ is a product. Usages in every version of a versioned
+// product are counted toward that product.
+//
+// Parameters:
+//   - dirPath: Path to the monorepo root or its content directory
+//   - excludeDirs: Directory names to skip, such as archived products
+//
+// Returns:
+//   - *Matrix: The shared includes and the products that use them
+//   - error: Any error encountered finding or reading files
+func BuildMatrix(dirPath string, excludeDirs []string) (*Matrix, error) {
+	absDirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	if _, err := os.Stat(absDirPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", absDirPath)
+	}
+
+	contentDir, err := projectinfo.FindContentDirectory(absDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[string]bool, len(excludeDirs))
+	for _, dir := range excludeDirs {
+		excluded[dir] = true
+	}
+
+	matrix := &Matrix{
+		ContentDir: contentDir,
+		Usages:     make(map[string]map[string]int),
+	}
+
+	err = filepath.Walk(contentDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != contentDir && excluded[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !scannedExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(contentDir, path)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(relPath, string(filepath.Separator))
+		if len(parts) < 2 {
+			// Files at the root of content don't belong to a product
+			return nil
+		}
+		product := parts[0]
+
+		includes, err := findSharedIncludes(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		for _, include := range includes {
+			if matrix.Usages[include] == nil {
+				matrix.Usages[include] = make(map[string]int)
+			}
+			matrix.Usages[include][product]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[string]bool)
+	for include, byProduct := range matrix.Usages {
+		matrix.Includes = append(matrix.Includes, include)
+		for product := range byProduct {
+			products[product] = true
+		}
+	}
+	for product := range products {
+		matrix.Products = append(matrix.Products, product)
+	}
+	sort.Strings(matrix.Includes)
+	sort.Strings(matrix.Products)
+
+	return matrix, nil
+}
+
+// findSharedIncludes returns the path of each sharedinclude directive in a file, in order.
+func findSharedIncludes(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var includes []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if matches := rst.SharedIncludeDirectiveRegex.FindStringSubmatch(scanner.Text()); matches != nil {
+			includes = append(includes, strings.TrimPrefix(matches[1], "/"))
+		}
+	}
+	return includes, scanner.Err()
+}
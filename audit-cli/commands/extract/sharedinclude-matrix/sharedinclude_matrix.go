@@ -0,0 +1,103 @@
+// Package sharedinclude_matrix provides functionality for mapping shared includes to the products that use them.
+//
+// This package implements the "extract sharedinclude-matrix" subcommand, which scans the
+// documentation monorepo for sharedinclude directives and writes a matrix of each shared
+// include by each consuming product, with usage counts. Shared content owners can use it
+// to see every consumer of an include before making a breaking edit.
+package sharedinclude_matrix
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// NewSharedIncludeMatrixCommand creates the sharedinclude-matrix subcommand.
+//
+// Usage:
+//
+//	extract sharedinclude-matrix /path/to/docs-monorepo
+//	extract sharedinclude-matrix /path/to/docs-monorepo --output-file sharedincludes.csv
+//	extract sharedinclude-matrix /path/to/docs-monorepo --format text
+//
+// Flags:
+//   - --format: Output format (csv, text, json, or markdown; default csv)
+//   - --output-file: Write results to a file instead of stdout
+//   - --exclude-dirs: Comma-separated list of directory names to exclude
+func NewSharedIncludeMatrixCommand() *cobra.Command {
+	var (
+		format      string
+		excludeDirs string
+		outputOpts  output.Options
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sharedinclude-matrix [directory-path]",
+		Short: "Map each shared include to the products that use it",
+		Long: `Write a matrix of shared includes by the products that use them.
+
+This command searches the RST (.txt, .rst) and YAML (.yaml, .yml) files in the
+documentation monorepo for sharedinclude directives. It writes one row per shared
+include, with the number of products that use it, its total usages, and a column
+for each product with that product's usage count.
+
+Each directory under content/ is a product. Usages in every version of a versioned
+product are counted toward that product.
+
+Use it before a breaking edit to a shared include to find every product that needs
+to be checked. The default output is CSV, for opening in a spreadsheet.
+
+Examples:
+  # Write the matrix as CSV
+  extract sharedinclude-matrix /path/to/docs-monorepo
+
+  # Write the matrix to a file
+  extract sharedinclude-matrix /path/to/docs-monorepo --output-file sharedincludes.csv
+
+  # Show the matrix as an aligned table
+  extract sharedinclude-matrix /path/to/docs-monorepo --format text
+
+  # Skip archived content
+  extract sharedinclude-matrix /path/to/docs-monorepo --exclude-dirs archive`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputOpts.Format = format
+			return runSharedIncludeMatrix(args[0], excludeDirs, outputOpts)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", string(output.FormatCSV), "Output format: csv, text, json, or markdown")
+	cmd.Flags().StringVar(&excludeDirs, "exclude-dirs", "", "Comma-separated list of directory names to exclude")
+	output.AddFileFlags(cmd, &outputOpts)
+
+	return cmd
+}
+
+// runSharedIncludeMatrix executes the sharedinclude-matrix operation.
+func runSharedIncludeMatrix(dirPath string, excludeDirs string, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
+	var excludeDirsList []string
+	for _, dir := range strings.Split(excludeDirs, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			excludeDirsList = append(excludeDirsList, dir)
+		}
+	}
+
+	matrix, err := BuildMatrix(dirPath, excludeDirsList)
+	if err != nil {
+		return fmt.Errorf("failed to build sharedinclude matrix: %w", err)
+	}
+
+	w, err := output.Open(outputOpts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return PrintMatrix(w, matrix)
+}
@@ -0,0 +1,89 @@
+package sharedinclude_matrix
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+var testDataDir = filepath.Join("..", "..", "..", "testdata", "sharedinclude-matrix")
+
+func TestBuildMatrix(t *testing.T) {
+	matrix, err := BuildMatrix(testDataDir, nil)
+	if err != nil {
+		t.Fatalf("BuildMatrix failed: %v", err)
+	}
+
+	wantIncludes := []string{"dbx/compatibility-table-legend.rst", "dbx/retired.rst", "server/connection-string.rst"}
+	if !reflect.DeepEqual(matrix.Includes, wantIncludes) {
+		t.Errorf("Includes = %v, want %v", matrix.Includes, wantIncludes)
+	}
+	wantProducts := []string{"archive", "atlas", "drivers", "manual"}
+	if !reflect.DeepEqual(matrix.Products, wantProducts) {
+		t.Errorf("Products = %v, want %v", matrix.Products, wantProducts)
+	}
+
+	// Usages in both driver versions count toward the drivers product
+	legend := "dbx/compatibility-table-legend.rst"
+	if got := matrix.Usages[legend]["drivers"]; got != 2 {
+		t.Errorf("drivers usages of %s = %d, want 2", legend, got)
+	}
+	if got := matrix.Consumers(legend); got != 2 {
+		t.Errorf("Consumers(%s) = %d, want 2", legend, got)
+	}
+
+	// The leading slash in atlas is normalized, and the YAML step in manual is counted
+	connection := "server/connection-string.rst"
+	if got := matrix.Usages[connection]; !reflect.DeepEqual(got, map[string]int{"manual": 2, "atlas": 1}) {
+		t.Errorf("usages of %s = %v, want manual: 2, atlas: 1", connection, got)
+	}
+	if got := matrix.Total(connection); got != 3 {
+		t.Errorf("Total(%s) = %d, want 3", connection, got)
+	}
+}
+
+func TestBuildMatrixExcludeDirs(t *testing.T) {
+	matrix, err := BuildMatrix(filepath.Join(testDataDir, "content"), []string{"archive"})
+	if err != nil {
+		t.Fatalf("BuildMatrix failed: %v", err)
+	}
+
+	if _, ok := matrix.Usages["dbx/retired.rst"]; ok {
+		t.Error("expected includes used only in excluded directories to be omitted")
+	}
+	if !reflect.DeepEqual(matrix.Products, []string{"atlas", "drivers", "manual"}) {
+		t.Errorf("Products = %v, want atlas, drivers, manual", matrix.Products)
+	}
+}
+
+func TestBuildMatrixRequiresContentDirectory(t *testing.T) {
+	if _, err := BuildMatrix(filepath.Join(testDataDir, "content", "manual"), nil); err == nil {
+		t.Error("expected an error for a directory without a content directory")
+	}
+}
+
+func TestPrintMatrixCSV(t *testing.T) {
+	matrix, err := BuildMatrix(testDataDir, []string{"archive"})
+	if err != nil {
+		t.Fatalf("BuildMatrix failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := PrintMatrix(output.NewWriter(&buf, output.FormatCSV), matrix); err != nil {
+		t.Fatalf("PrintMatrix failed: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"Include,Products,Total,atlas,drivers,manual",
+		"dbx/compatibility-table-legend.rst,2,3,0,2,1",
+		"server/connection-string.rst,2,3,1,0,2",
+		"",
+	}, "\n")
+	if buf.String() != want {
+		t.Errorf("CSV output =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,23 @@
+package sharedinclude_matrix
+
+// Matrix is the usage of each shared include by each product in the monorepo.
+type Matrix struct {
+	ContentDir string                    // Absolute path to the monorepo content directory
+	Products   []string                  // Products that use at least one shared include, sorted
+	Includes   []string                  // Shared include paths, sorted
+	Usages     map[string]map[string]int // Shared include path -> product -> number of usages
+}
+
+// Total returns the number of times a shared include is used across all products.
+func (m *Matrix) Total(include string) int {
+	total := 0
+	for _, count := range m.Usages[include] {
+		total += count
+	}
+	return total
+}
+
+// Consumers returns the number of products that use a shared include.
+func (m *Matrix) Consumers(include string) int {
+	return len(m.Usages[include])
+}
@@ -15,6 +15,8 @@
 //   - Language detection based on file extension
 //   - Case-insensitive search (default) or case-sensitive search (--case-sensitive flag)
 //   - Exact word matching (default) or partial matching (--partial-match flag)
+//   - Guarded bulk replacement (--replace flag) with a dry-run preview, per-file confirmation,
+//     and automatic git branch creation
 package find_string
 
 import (
@@ -23,6 +25,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 	"github.com/mongodb/code-example-tooling/audit-cli/internal/rst"
 	"github.com/spf13/cobra"
 )
@@ -38,6 +41,11 @@ import (
 //   - -v, --verbose: Show file paths and language breakdown
 //   - --case-sensitive: Make search case-sensitive (default: case-insensitive)
 //   - --partial-match: Allow partial matches within words (default: exact word matching)
+//   - --replace: Replace each match with this text (previews changes unless --apply is set)
+//   - --apply: Write replacements to files after creating a git branch
+//   - -y, --yes: Apply replacements to every file without per-file confirmation
+//   - --branch: Name of the git branch to create for replacements
+//   - --output-file: Write the report to a file instead of stdout
 func NewFindStringCommand() *cobra.Command {
 	var (
 		recursive      bool
@@ -45,6 +53,11 @@ func NewFindStringCommand() *cobra.Command {
 		verbose        bool
 		caseSensitive  bool
 		partialMatch   bool
+		replacement    string
+		apply          bool
+		yes            bool
+		branch         string
+		outputOpts     output.Options
 	)
 
 	cmd := &cobra.Command{
@@ -58,12 +71,49 @@ and scope maintenance work related to specific changes.
 
 By default, the search is case-insensitive and matches exact words only. Use --case-sensitive
 to make the search case-sensitive, or --partial-match to allow matching the substring as part
-of larger words (e.g., "curl" matching "libcurl").`,
+of larger words (e.g., "curl" matching "libcurl").
+
+Use --replace to replace every match with new text, using the same matching rules as the
+search. Replace mode is guarded:
+  - Without --apply, it only previews the changes (dry run)
+  - With --apply, it creates a git branch first and refuses to run if the repository
+    has uncommitted changes
+  - Each file is confirmed interactively unless --yes is set
+
+Examples:
+  # Preview replacing a renamed product
+  search find-string ./source "Atlas Data Lake" -r --case-sensitive --replace "Atlas Data Federation"
+
+  # Apply the replacement, confirming each file
+  search find-string ./source "Atlas Data Lake" -r --case-sensitive --replace "Atlas Data Federation" --apply
+
+  # Apply to all files on a named branch without prompting
+  search find-string ./source "docs.mongodb.org" -r --partial-match --replace "www.mongodb.com/docs" --apply --yes --branch update-urls`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			filePath := args[0]
 			substring := args[1]
-			return runSearch(filePath, substring, recursive, followIncludes, verbose, caseSensitive, partialMatch)
+			if !cmd.Flags().Changed("replace") && (apply || yes || branch != "") {
+				return fmt.Errorf("--apply, --yes, and --branch require --replace")
+			}
+
+			w, err := output.Open(outputOpts)
+			if err != nil {
+				return err
+			}
+			defer w.Close()
+
+			if cmd.Flags().Changed("replace") {
+				options := ReplaceOptions{
+					Replacement: replacement,
+					Apply:       apply,
+					Yes:         yes,
+					Branch:      branch,
+					Output:      w,
+				}
+				return runReplace(filePath, substring, recursive, followIncludes, verbose, caseSensitive, partialMatch, options)
+			}
+			return runSearch(w, filePath, substring, recursive, followIncludes, verbose, caseSensitive, partialMatch)
 		},
 	}
 
@@ -72,6 +122,11 @@ of larger words (e.g., "curl" matching "libcurl").`,
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Provide additional information during execution")
 	cmd.Flags().BoolVar(&caseSensitive, "case-sensitive", false, "Make search case-sensitive (default: case-insensitive)")
 	cmd.Flags().BoolVar(&partialMatch, "partial-match", false, "Allow partial matches within words (default: exact word matching)")
+	cmd.Flags().StringVar(&replacement, "replace", "", "Replace each match with this text (previews changes unless --apply is set)")
+	cmd.Flags().BoolVar(&apply, "apply", false, "Write replacements to files after creating a git branch")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Apply replacements to every file without per-file confirmation")
+	cmd.Flags().StringVar(&branch, "branch", "", "Name of the git branch to create for replacements (default: audit-cli/replace-<timestamp>)")
+	output.AddFileFlags(cmd, &outputOpts)
 
 	return cmd
 }
@@ -99,11 +154,15 @@ func RunSearch(filePath string, substring string, recursive bool, followIncludes
 
 // runSearch executes the search operation (internal wrapper for CLI).
 //
-// This is a thin wrapper around runSearchInternal that discards the report
+// This is a thin wrapper around runSearchInternal that writes the report
 // and only returns errors, suitable for use in the CLI command handler.
-func runSearch(filePath string, substring string, recursive bool, followIncludes bool, verbose bool, caseSensitive bool, partialMatch bool) error {
-	_, err := runSearchInternal(filePath, substring, recursive, followIncludes, verbose, caseSensitive, partialMatch)
-	return err
+func runSearch(w *output.Writer, filePath string, substring string, recursive bool, followIncludes bool, verbose bool, caseSensitive bool, partialMatch bool) error {
+	report, err := runSearchInternal(filePath, substring, recursive, followIncludes, verbose, caseSensitive, partialMatch)
+	if err != nil {
+		return err
+	}
+	PrintReport(w, report, verbose)
+	return nil
 }
 
 // runSearchInternal contains the core logic for the search-code-examples command
@@ -176,8 +235,6 @@ func runSearchInternal(filePath string, substring string, recursive bool, follow
 		}
 	}
 
-	PrintReport(report, verbose)
-
 	return report, nil
 }
 
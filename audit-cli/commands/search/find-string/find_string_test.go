@@ -1,8 +1,16 @@
 package find_string
 
 import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/gitutil"
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 )
 
 // TestDefaultBehaviorCaseInsensitive tests that search is case-insensitive by default
@@ -248,3 +256,222 @@ func TestNoMatches(t *testing.T) {
 	}
 }
 
+
+// TestReplaceDryRunDoesNotWriteFiles tests that replace mode previews changes without --apply
+func TestReplaceDryRunDoesNotWriteFiles(t *testing.T) {
+	testDataDir := filepath.Join("..", "..", "..", "testdata", "search-test-files")
+	wordBoundariesFile := filepath.Join(testDataDir, "word-boundaries.txt")
+
+	before, err := os.ReadFile(wordBoundariesFile)
+	if err != nil {
+		t.Fatalf("failed to read test file: %v", err)
+	}
+
+	report, err := RunReplace(wordBoundariesFile, "curl", false, false, false, false, false, ReplaceOptions{Replacement: "wget"})
+	if err != nil {
+		t.Fatalf("RunReplace failed: %v", err)
+	}
+
+	// Exact word matching: "curl is a tool", "_curl_" is not a match, "curl-config" is a match
+	if report.TotalMatches != 2 {
+		t.Errorf("Expected 2 exact-word matches, got %d", report.TotalMatches)
+	}
+	if report.Applied {
+		t.Error("Expected dry run not to apply changes")
+	}
+
+	after, err := os.ReadFile(wordBoundariesFile)
+	if err != nil {
+		t.Fatalf("failed to read test file: %v", err)
+	}
+	if string(before) != string(after) {
+		t.Error("Dry run modified the file")
+	}
+}
+
+// TestReplaceWritesToOutput tests that the report and preview go to the configured writer
+func TestReplaceWritesToOutput(t *testing.T) {
+	testDataDir := filepath.Join("..", "..", "..", "testdata", "search-test-files")
+	wordBoundariesFile := filepath.Join(testDataDir, "word-boundaries.txt")
+
+	var buf bytes.Buffer
+	options := ReplaceOptions{Replacement: "wget", Output: output.NewWriter(&buf, output.FormatText)}
+	if _, err := RunReplace(wordBoundariesFile, "curl", false, false, false, false, false, options); err != nil {
+		t.Fatalf("RunReplace failed: %v", err)
+	}
+
+	for _, expected := range []string{"SEARCH REPORT", "REPLACE PREVIEW", "Total replacements: 2", "Dry run: no files were changed"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, buf.String())
+		}
+	}
+}
+
+// TestReplaceInLine tests that replacements follow the search matching rules
+func TestReplaceInLine(t *testing.T) {
+	tests := []struct {
+		name          string
+		file          string
+		substring     string
+		caseSensitive bool
+		partialMatch  bool
+		expected      string
+	}{
+		{"case-insensitive exact word", "mixed-case.txt", "curl", false, false, "This file has wget in uppercase.\nAlso has wget in mixed case.\nAnd wget in lowercase."},
+		{"case-sensitive exact word", "mixed-case.txt", "CURL", true, false, "This file has wget in uppercase.\nAlso has Curl in mixed case.\nAnd curl in lowercase."},
+		{"partial match", "word-boundaries.txt", "curl", true, true, "Testing word boundaries:\nwget is a tool\nlibwget is a library\nwgetopt is an option\n_wget_ with underscores\nwget-config is a script\nprewget and postwget"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join("..", "..", "..", "testdata", "search-test-files", tt.file)
+			result, err := planFileReplacement(testFile, tt.substring, "wget", tt.caseSensitive, tt.partialMatch)
+			if err != nil {
+				t.Fatalf("planFileReplacement failed: %v", err)
+			}
+			if got := strings.TrimRight(result.NewContent, "\n"); got != tt.expected {
+				t.Errorf("Unexpected content:\n%s\nexpected:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestReplaceApply tests that --apply creates a branch and honors per-file confirmation
+func TestReplaceApply(t *testing.T) {
+	repoDir := newTestGitRepo(t, map[string]string{
+		"a.txt": "Use Atlas Data Lake here.\n",
+		"b.txt": "Atlas Data Lake is also here.\n",
+	})
+
+	options := ReplaceOptions{
+		Replacement: "Atlas Data Federation",
+		Apply:       true,
+		Branch:      "rename-product",
+		Input:       strings.NewReader("y\nn\n"),
+	}
+	report, err := RunReplace(repoDir, "Atlas Data Lake", false, false, false, true, false, options)
+	if err != nil {
+		t.Fatalf("RunReplace failed: %v", err)
+	}
+
+	if report.Branch != "rename-product" {
+		t.Errorf("Expected branch rename-product, got %s", report.Branch)
+	}
+	if branch, _ := gitutil.Run(repoDir, "rev-parse", "--abbrev-ref", "HEAD"); branch != "rename-product" {
+		t.Errorf("Expected HEAD on rename-product, got %s", branch)
+	}
+	if len(report.FilesWritten) != 1 || len(report.FilesSkipped) != 1 {
+		t.Fatalf("Expected 1 file written and 1 skipped, got %v and %v", report.FilesWritten, report.FilesSkipped)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(repoDir, "a.txt"))
+	if string(content) != "Use Atlas Data Federation here.\n" {
+		t.Errorf("a.txt not replaced: %q", content)
+	}
+	content, _ = os.ReadFile(filepath.Join(repoDir, "b.txt"))
+	if string(content) != "Atlas Data Lake is also here.\n" {
+		t.Errorf("b.txt should have been skipped: %q", content)
+	}
+}
+
+// TestReplaceApplyAllDeclined tests that declining every file doesn't report the replacement as applied
+func TestReplaceApplyAllDeclined(t *testing.T) {
+	repoDir := newTestGitRepo(t, map[string]string{
+		"a.txt": "curl\n",
+	})
+
+	options := ReplaceOptions{Replacement: "wget", Apply: true, Input: strings.NewReader("n\n")}
+	report, err := RunReplace(repoDir, "curl", false, false, false, false, false, options)
+	if err != nil {
+		t.Fatalf("RunReplace failed: %v", err)
+	}
+	if report.Applied {
+		t.Error("Expected Applied to be false when every file is declined")
+	}
+	if len(report.FilesSkipped) != 1 {
+		t.Errorf("Expected 1 file skipped, got %v", report.FilesSkipped)
+	}
+}
+
+// TestReplaceApplyWriteErrorReturnsPartialReport tests that a failed write returns the files already written
+func TestReplaceApplyWriteErrorReturnsPartialReport(t *testing.T) {
+	repoDir := newTestGitRepo(t, map[string]string{
+		"a.txt": "curl\n",
+		"b.txt": "curl\n",
+	})
+
+	// Remove b.txt after a.txt is confirmed, so writing it fails
+	input := &funcReader{answers: []string{"y\n", "y\n"}, before: map[int]func(){
+		1: func() { os.Remove(filepath.Join(repoDir, "b.txt")) },
+	}}
+	options := ReplaceOptions{Replacement: "wget", Apply: true, Input: input}
+	report, err := RunReplace(repoDir, "curl", false, false, false, false, false, options)
+	if err == nil {
+		t.Fatal("Expected an error writing b.txt")
+	}
+	if report == nil {
+		t.Fatal("Expected a partial report along with the error")
+	}
+	if !report.Applied || len(report.FilesWritten) != 1 || filepath.Base(report.FilesWritten[0]) != "a.txt" {
+		t.Errorf("Expected a.txt written and applied, got applied=%v written=%v", report.Applied, report.FilesWritten)
+	}
+}
+
+// funcReader returns one answer per Read, running the matching before func first.
+type funcReader struct {
+	answers []string
+	before  map[int]func()
+	reads   int
+}
+
+func (r *funcReader) Read(p []byte) (int, error) {
+	if r.reads >= len(r.answers) {
+		return 0, io.EOF
+	}
+	if before, ok := r.before[r.reads]; ok {
+		before()
+	}
+	n := copy(p, r.answers[r.reads])
+	r.reads++
+	return n, nil
+}
+
+// TestReplaceApplyRefusesDirtyRepo tests that --apply refuses to run with uncommitted changes
+func TestReplaceApplyRefusesDirtyRepo(t *testing.T) {
+	repoDir := newTestGitRepo(t, map[string]string{
+		"a.txt": "curl\n",
+	})
+	if err := os.WriteFile(filepath.Join(repoDir, "a.txt"), []byte("curl curl\n"), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+
+	_, err := RunReplace(repoDir, "curl", false, false, false, false, false, ReplaceOptions{Replacement: "wget", Apply: true, Yes: true})
+	if err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Errorf("Expected uncommitted changes error, got %v", err)
+	}
+}
+
+// newTestGitRepo creates a git repository in a temp directory with the given files committed.
+func newTestGitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial"},
+	} {
+		if _, err := gitutil.Run(dir, args...); err != nil {
+			t.Fatalf("failed to set up git repo: %v", err)
+		}
+	}
+	return dir
+}
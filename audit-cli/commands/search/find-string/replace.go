@@ -0,0 +1,266 @@
+package find_string
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/gitutil"
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
+// RunReplace searches for the substring and replaces it in every matching file.
+//
+// This function is exported for use in tests. It uses the same matching rules as RunSearch,
+// so the files changed are exactly the files the search reports. Changes are always previewed
+// first; they are only written when options.Apply is true, after creating a git branch and
+// confirming each file (unless options.Yes is true).
+//
+// Parameters:
+//   - filePath: Path to file or directory to search
+//   - substring: The substring to replace
+//   - recursive: If true, recursively search subdirectories
+//   - followIncludes: If true, follow .. include:: directives
+//   - verbose: If true, show detailed information during search
+//   - caseSensitive: If true, matching is case-sensitive; if false, case-insensitive
+//   - partialMatch: If true, replace partial matches within words; if false, whole words only
+//   - options: Replacement text and guard options
+//
+// Returns:
+//   - *ReplaceReport: The planned and applied replacements. If applying stops partway, the report
+//     of the files written so far is returned along with the error.
+//   - error: Any error encountered
+func RunReplace(filePath string, substring string, recursive bool, followIncludes bool, verbose bool, caseSensitive bool, partialMatch bool, options ReplaceOptions) (*ReplaceReport, error) {
+	searchReport, err := runSearchInternal(filePath, substring, recursive, followIncludes, verbose, caseSensitive, partialMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	w := options.Output
+	if w == nil {
+		w = output.NewWriter(os.Stdout, output.FormatText)
+	}
+	PrintReport(w, searchReport, verbose)
+
+	report := &ReplaceReport{}
+	for _, file := range searchReport.FilesWithSubstring {
+		replacement, err := planFileReplacement(file, substring, options.Replacement, caseSensitive, partialMatch)
+		if err != nil {
+			return nil, err
+		}
+		if replacement.Matches == 0 {
+			continue
+		}
+		report.Files = append(report.Files, replacement)
+		report.TotalMatches += replacement.Matches
+	}
+
+	printReplacePreview(w, report, substring, options.Replacement)
+
+	if !options.Apply {
+		w.Println("\nDry run: no files were changed. Re-run with --apply to write these changes.")
+		return report, nil
+	}
+	if len(report.Files) == 0 {
+		return report, nil
+	}
+
+	branch, err := createReplaceBranch(report.Files, options.Branch)
+	if err != nil {
+		return nil, err
+	}
+	report.Branch = branch
+
+	input := options.Input
+	if input == nil {
+		input = os.Stdin
+	}
+	reader := bufio.NewReader(input)
+	applyAll := options.Yes
+
+	for i, file := range report.Files {
+		if !applyAll {
+			answer, err := confirmFile(reader, file)
+			if err != nil {
+				report.Applied = len(report.FilesWritten) > 0
+				return report, err
+			}
+			switch answer {
+			case "a":
+				applyAll = true
+			case "q":
+				for _, remaining := range report.Files[i:] {
+					report.FilesSkipped = append(report.FilesSkipped, remaining.FilePath)
+				}
+				report.Applied = len(report.FilesWritten) > 0
+				printReplaceSummary(w, report)
+				return report, nil
+			case "n":
+				report.FilesSkipped = append(report.FilesSkipped, file.FilePath)
+				continue
+			}
+		}
+
+		if err := writeFilePreservingMode(file.FilePath, file.NewContent); err != nil {
+			// Return the files already written, so they can be reviewed on the branch
+			report.Applied = len(report.FilesWritten) > 0
+			printReplaceSummary(w, report)
+			return report, err
+		}
+		report.FilesWritten = append(report.FilesWritten, file.FilePath)
+	}
+
+	report.Applied = len(report.FilesWritten) > 0
+	printReplaceSummary(w, report)
+	return report, nil
+}
+
+// runReplace executes the replace operation (internal wrapper for CLI).
+func runReplace(filePath string, substring string, recursive bool, followIncludes bool, verbose bool, caseSensitive bool, partialMatch bool, options ReplaceOptions) error {
+	_, err := RunReplace(filePath, substring, recursive, followIncludes, verbose, caseSensitive, partialMatch, options)
+	return err
+}
+
+// planFileReplacement computes the replacement for a single file without writing it.
+func planFileReplacement(filePath string, substring string, replacement string, caseSensitive bool, partialMatch bool) (FileReplacement, error) {
+	result := FileReplacement{FilePath: filePath}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return result, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	pattern := regexp.QuoteMeta(substring)
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re := regexp.MustCompile(pattern)
+
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		newLine, count := replaceInLine(line, re, replacement, partialMatch)
+		if count == 0 {
+			continue
+		}
+		result.Matches += count
+		result.Changes = append(result.Changes, LineChange{
+			LineNum: i + 1,
+			Before:  line,
+			After:   newLine,
+		})
+		lines[i] = newLine
+	}
+	result.NewContent = strings.Join(lines, "\n")
+
+	return result, nil
+}
+
+// replaceInLine replaces matches of re in a single line.
+//
+// When partialMatch is false, matches are only replaced when they are whole words, using the
+// same word boundary rules as the search.
+func replaceInLine(line string, re *regexp.Regexp, replacement string, partialMatch bool) (string, int) {
+	var sb strings.Builder
+	count := 0
+	last := 0
+	for _, loc := range re.FindAllStringIndex(line, -1) {
+		start, end := loc[0], loc[1]
+		if !partialMatch {
+			beforeOK := start == 0 || !isWordChar(rune(line[start-1]))
+			afterOK := end >= len(line) || !isWordChar(rune(line[end]))
+			if !beforeOK || !afterOK {
+				continue
+			}
+		}
+		sb.WriteString(line[last:start])
+		sb.WriteString(replacement)
+		last = end
+		count++
+	}
+	if count == 0 {
+		return line, 0
+	}
+	sb.WriteString(line[last:])
+	return sb.String(), count
+}
+
+// createReplaceBranch creates and checks out a git branch for the changes.
+//
+// The files must all be in the same git repository, and the repository must have no uncommitted
+// changes, so the replacement can be reviewed with git diff and reverted as a unit.
+func createReplaceBranch(files []FileReplacement, branch string) (string, error) {
+	dir := filepath.Dir(files[0].FilePath)
+	repoRoot, err := gitutil.Run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("--apply requires the files to be in a git repository: %w", err)
+	}
+
+	for _, file := range files[1:] {
+		otherRoot, err := gitutil.Run(filepath.Dir(file.FilePath), "rev-parse", "--show-toplevel")
+		if err != nil || otherRoot != repoRoot {
+			return "", fmt.Errorf("--apply requires all files to be in the same git repository (%s is not in %s)", file.FilePath, repoRoot)
+		}
+	}
+
+	status, err := gitutil.Run(repoRoot, "status", "--porcelain")
+	if err != nil {
+		return "", err
+	}
+	if status != "" {
+		return "", fmt.Errorf("git repository %s has uncommitted changes; commit or stash them before using --apply", repoRoot)
+	}
+
+	if branch == "" {
+		branch = "audit-cli/replace-" + time.Now().Format("20060102-150405")
+	}
+	if _, err := gitutil.Run(repoRoot, "checkout", "-b", branch); err != nil {
+		return "", fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+
+	return branch, nil
+}
+
+// confirmFile asks whether to apply the changes to a file, prompting on stderr so the prompts
+// don't mix with the report. Returns "y", "n", "a" (apply to this and all remaining files), or "q" (stop).
+func confirmFile(reader *bufio.Reader, file FileReplacement) (string, error) {
+	for {
+		fmt.Fprintf(os.Stderr, "Apply %d change(s) to %s? [y]es/[n]o/[a]ll/[q]uit: ", file.Matches, file.FilePath)
+		answer, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		switch answer {
+		case "y", "yes":
+			return "y", nil
+		case "n", "no":
+			return "n", nil
+		case "a", "all":
+			return "a", nil
+		case "q", "quit":
+			return "q", nil
+		}
+		if err == io.EOF {
+			// No more input: treat as quit rather than applying changes without confirmation
+			fmt.Fprintln(os.Stderr)
+			return "q", nil
+		}
+	}
+}
+
+// writeFilePreservingMode writes content to an existing file, keeping its permissions.
+func writeFilePreservingMode(filePath string, content string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to access %s: %w", filePath, err)
+	}
+	if err := os.WriteFile(filePath, []byte(content), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	return nil
+}
@@ -1,12 +1,13 @@
 package find_string
 
 import (
-	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
 )
 
-// PrintReport prints the search report to stdout.
+// PrintReport prints the search report to the writer.
 //
 // Displays statistics about the search operation including:
 //   - Number of files scanned
@@ -15,18 +16,19 @@ import (
 //   - List of file paths containing the substring (if verbose is true)
 //
 // Parameters:
+//   - w: The output writer, which determines the destination
 //   - report: The report to print
 //   - verbose: If true, show detailed breakdown including file paths and language counts
-func PrintReport(report *SearchReport, verbose bool) {
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("SEARCH REPORT")
-	fmt.Println(strings.Repeat("=", 60))
+func PrintReport(w *output.Writer, report *SearchReport, verbose bool) {
+	w.Println("\n" + strings.Repeat("=", 60))
+	w.Println("SEARCH REPORT")
+	w.Println(strings.Repeat("=", 60))
 
-	fmt.Printf("\nFiles Scanned: %d\n", report.FilesScanned)
-	fmt.Printf("Files Containing Substring: %d\n", report.FilesContaining)
+	w.Printf("\nFiles Scanned: %d\n", report.FilesScanned)
+	w.Printf("Files Containing Substring: %d\n", report.FilesContaining)
 
 	if verbose && len(report.LanguageCounts) > 0 {
-		fmt.Println("\nFiles Containing Substring by Language:")
+		w.Println("\nFiles Containing Substring by Language:")
 
 		languages := make([]string, 0, len(report.LanguageCounts))
 		for lang := range report.LanguageCounts {
@@ -36,16 +38,58 @@ func PrintReport(report *SearchReport, verbose bool) {
 
 		for _, lang := range languages {
 			count := report.LanguageCounts[lang]
-			fmt.Printf("  %-15s: %d\n", lang, count)
+			w.Printf("  %-15s: %d\n", lang, count)
 		}
 	}
 
 	if verbose && len(report.FilesWithSubstring) > 0 {
-		fmt.Println("\nFiles Containing Substring:")
+		w.Println("\nFiles Containing Substring:")
 		for _, path := range report.FilesWithSubstring {
-			fmt.Printf("  - %s\n", path)
+			w.Printf("  - %s\n", path)
+		}
+	}
+
+	w.Println(strings.Repeat("=", 60))
+}
+
+// printReplacePreview prints the planned replacements as a line-by-line diff.
+func printReplacePreview(w *output.Writer, report *ReplaceReport, substring string, replacement string) {
+	w.Println("\n" + strings.Repeat("=", 60))
+	w.Println("REPLACE PREVIEW")
+	w.Println(strings.Repeat("=", 60))
+	w.Printf("\nReplace: %q\n", substring)
+	w.Printf("With:    %q\n", replacement)
+	w.Printf("Files to change: %d\n", len(report.Files))
+	w.Printf("Total replacements: %d\n", report.TotalMatches)
+
+	for _, file := range report.Files {
+		w.Printf("\n%s (%d)\n", file.FilePath, file.Matches)
+		for _, change := range file.Changes {
+			w.Printf("  %d:\n", change.LineNum)
+			w.Printf("    - %s\n", change.Before)
+			w.Printf("    + %s\n", change.After)
 		}
 	}
 
-	fmt.Println(strings.Repeat("=", 60))
+	w.Println(strings.Repeat("=", 60))
+}
+
+// printReplaceSummary prints the results of applying replacements.
+func printReplaceSummary(w *output.Writer, report *ReplaceReport) {
+	w.Println("\n" + strings.Repeat("=", 60))
+	w.Println("REPLACE SUMMARY")
+	w.Println(strings.Repeat("=", 60))
+	w.Printf("\nBranch: %s\n", report.Branch)
+	w.Printf("Files changed: %d\n", len(report.FilesWritten))
+	w.Printf("Files skipped: %d\n", len(report.FilesSkipped))
+	if len(report.FilesSkipped) > 0 {
+		w.Println("\nSkipped files:")
+		for _, path := range report.FilesSkipped {
+			w.Printf("  - %s\n", path)
+		}
+	}
+	if len(report.FilesWritten) > 0 {
+		w.Println("\nReview the changes with 'git diff', then commit them on this branch.")
+	}
+	w.Println(strings.Repeat("=", 60))
 }
@@ -1,5 +1,11 @@
 package find_string
 
+import (
+	"io"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/output"
+)
+
 // SearchResult contains the results of searching a single file.
 //
 // Used internally during the search operation to track results for each file.
@@ -43,3 +49,41 @@ func (r *SearchReport) AddResult(result SearchResult) {
 		}
 	}
 }
+
+// ReplaceOptions configures replace mode.
+//
+// Replace mode always previews the changes first. Files are only written when Apply is true,
+// and each file is confirmed interactively unless Yes is true.
+type ReplaceOptions struct {
+	Replacement string         // Text that replaces each match (may be empty to delete matches)
+	Apply       bool           // Write changes to files; if false, only preview them (dry run)
+	Yes         bool           // Apply to every file without per-file confirmation
+	Branch      string         // Git branch to create before writing (default: generated from the current time)
+	Input       io.Reader      // Source of confirmation answers (default: os.Stdin)
+	Output      *output.Writer // Destination of the search report, preview, and summary (default: os.Stdout)
+}
+
+// LineChange describes a single line changed by a replacement.
+type LineChange struct {
+	LineNum int    // Line number in the file (1-based)
+	Before  string // Line content before the replacement
+	After   string // Line content after the replacement
+}
+
+// FileReplacement describes the planned replacements for a single file.
+type FileReplacement struct {
+	FilePath   string       // Path to the file
+	Matches    int          // Number of matches replaced in the file
+	Changes    []LineChange // Lines changed by the replacement
+	NewContent string       // Full file content after the replacement
+}
+
+// ReplaceReport contains the results of a replace operation.
+type ReplaceReport struct {
+	Files        []FileReplacement // Planned replacements, one per file with matches
+	TotalMatches int               // Total matches across all files
+	Applied      bool              // Whether any changes were written (false for a dry run or if every file was declined)
+	Branch       string            // Git branch created for the changes (empty for a dry run)
+	FilesWritten []string          // Files that were changed
+	FilesSkipped []string          // Files the user declined to change
+}
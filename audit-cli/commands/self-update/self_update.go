@@ -0,0 +1,138 @@
+// Package self_update implements the self-update command, which replaces the running
+// audit-cli binary with a release from GitHub.
+package self_update
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/release"
+	"github.com/spf13/cobra"
+)
+
+// NewSelfUpdateCommand creates the self-update command.
+//
+// current is the version of the running binary, set at build time.
+//
+// Usage:
+//
+//	self-update
+//	self-update --check
+//	self-update --version v1.4.0
+//
+// Flags:
+//   - --check: Report whether an update is available without installing it
+//   - --version: Install a specific release instead of the latest
+//   - --force: Reinstall even if the release isn't newer than the running binary
+func NewSelfUpdateCommand(current string) *cobra.Command {
+	var (
+		check   bool
+		version string
+		force   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Update audit-cli to the latest release",
+		Long: `Download the latest audit-cli release from GitHub and replace the running binary with it.
+
+The release archive for this platform is verified against the release's checksums file
+before the binary is replaced. If audit-cli was installed with Homebrew or Scoop, update it
+with 'brew upgrade audit-cli' or 'scoop update audit-cli' instead, so the package manager
+keeps track of the installed version.
+
+Set GITHUB_TOKEN to avoid GitHub's rate limit for unauthenticated requests.
+
+Examples:
+  # Update to the latest release
+  self-update
+
+  # Check whether an update is available
+  self-update --check
+
+  # Install a specific release, such as to roll back
+  self-update --version v1.4.0 --force`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			executable, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("cannot find the running executable: %w", err)
+			}
+			if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+				executable = resolved
+			}
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			opts := updateOptions{
+				Current:    current,
+				Version:    version,
+				Check:      check,
+				Force:      force,
+				Executable: executable,
+			}
+			return runSelfUpdate(ctx, cmd.OutOrStdout(), release.NewClient(), opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "Report whether an update is available without installing it")
+	cmd.Flags().StringVar(&version, "version", "", "Install a specific release (e.g., v1.4.0) instead of the latest")
+	cmd.Flags().BoolVar(&force, "force", false, "Reinstall even if the release isn't newer than the running binary")
+
+	return cmd
+}
+
+// updateOptions holds the settings for a self-update.
+type updateOptions struct {
+	Current    string // Version of the running binary
+	Version    string // Release to install; empty for the latest
+	Check      bool   // Only report whether an update is available
+	Force      bool   // Install even if the release isn't newer
+	Executable string // Path of the binary to replace
+}
+
+// runSelfUpdate finds the release to install and replaces the executable with it.
+func runSelfUpdate(ctx context.Context, w io.Writer, client *release.Client, opts updateOptions) error {
+	var (
+		rel *release.Release
+		err error
+	)
+	if opts.Version != "" {
+		rel, err = client.ByTag(ctx, opts.Version)
+	} else {
+		rel, err = client.Latest(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find release: %w", err)
+	}
+
+	newer := release.CompareVersions(rel.Version(), opts.Current) > 0
+	if opts.Check {
+		if newer {
+			fmt.Fprintf(w, "Update available: %s -> %s\n", opts.Current, rel.TagName)
+		} else {
+			fmt.Fprintf(w, "audit-cli %s is up to date.\n", opts.Current)
+		}
+		return nil
+	}
+	if !newer && !opts.Force {
+		fmt.Fprintf(w, "audit-cli %s is up to date (release %s). Use --force to reinstall.\n", opts.Current, rel.TagName)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Downloading audit-cli %s...\n", rel.TagName)
+	binary, err := client.FetchBinary(ctx, rel)
+	if err != nil {
+		return err
+	}
+	if err := release.ReplaceExecutable(opts.Executable, binary); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", opts.Executable, err)
+	}
+
+	fmt.Fprintf(w, "Updated audit-cli %s -> %s (%s)\n", opts.Current, rel.TagName, opts.Executable)
+	return nil
+}
@@ -0,0 +1,129 @@
+package self_update
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/release"
+)
+
+// newReleaseServer serves a v1.4.0 release whose archive for this platform holds binary.
+func newReleaseServer(t *testing.T, binary string) *httptest.Server {
+	t.Helper()
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "audit-cli", Mode: 0o755, Size: int64(len(binary)), Typeflag: tar.TypeReg})
+	tw.Write([]byte(binary))
+	tw.Close()
+	gz.Close()
+
+	archiveName := release.ArchiveName("1.4.0", runtime.GOOS, runtime.GOARCH)
+	sum := sha256.Sum256(archive.Bytes())
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/mongodb/code-example-tooling/releases":
+			json.NewEncoder(w).Encode([]release.Release{{
+				TagName: "v1.4.0",
+				Assets: []release.Asset{
+					{Name: archiveName, BrowserDownloadURL: server.URL + "/download/archive"},
+					{Name: release.ChecksumsName("1.4.0"), BrowserDownloadURL: server.URL + "/download/checksums"},
+				},
+			}})
+		case "/download/archive":
+			w.Write(archive.Bytes())
+		case "/download/checksums":
+			w.Write([]byte(hex.EncodeToString(sum[:]) + "  " + archiveName + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeExecutable(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit-cli")
+	if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSelfUpdateInstallsNewerRelease(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test release only has a .tar.gz archive")
+	}
+	server := newReleaseServer(t, "v1.4.0 binary")
+	client := &release.Client{APIURL: server.URL, Repo: release.DefaultRepo}
+	executable := writeExecutable(t, "v1.3.0 binary")
+
+	var out bytes.Buffer
+	err := runSelfUpdate(context.Background(), &out, client, updateOptions{Current: "1.3.0", Executable: executable})
+	if err != nil {
+		t.Fatalf("runSelfUpdate failed: %v", err)
+	}
+
+	got, _ := os.ReadFile(executable)
+	if string(got) != "v1.4.0 binary" {
+		t.Errorf("executable = %q, want the v1.4.0 binary", got)
+	}
+	if !strings.Contains(out.String(), "1.3.0 -> v1.4.0") {
+		t.Errorf("output = %q, want update summary", out.String())
+	}
+}
+
+func TestSelfUpdateSkipsCurrentRelease(t *testing.T) {
+	server := newReleaseServer(t, "v1.4.0 binary")
+	client := &release.Client{APIURL: server.URL, Repo: release.DefaultRepo}
+	executable := writeExecutable(t, "v1.4.0 installed")
+
+	var out bytes.Buffer
+	if err := runSelfUpdate(context.Background(), &out, client, updateOptions{Current: "1.4.0", Executable: executable}); err != nil {
+		t.Fatalf("runSelfUpdate failed: %v", err)
+	}
+
+	got, _ := os.ReadFile(executable)
+	if string(got) != "v1.4.0 installed" {
+		t.Error("executable was replaced even though it's up to date")
+	}
+	if !strings.Contains(out.String(), "up to date") {
+		t.Errorf("output = %q, want up-to-date message", out.String())
+	}
+}
+
+func TestSelfUpdateCheckDoesNotInstall(t *testing.T) {
+	server := newReleaseServer(t, "v1.4.0 binary")
+	client := &release.Client{APIURL: server.URL, Repo: release.DefaultRepo}
+	executable := writeExecutable(t, "dev build")
+
+	var out bytes.Buffer
+	opts := updateOptions{Current: release.DevVersion, Check: true, Executable: executable}
+	if err := runSelfUpdate(context.Background(), &out, client, opts); err != nil {
+		t.Fatalf("runSelfUpdate failed: %v", err)
+	}
+
+	got, _ := os.ReadFile(executable)
+	if string(got) != "dev build" {
+		t.Error("executable was replaced by --check")
+	}
+	if !strings.Contains(out.String(), "Update available: dev -> v1.4.0") {
+		t.Errorf("output = %q, want update available message", out.String())
+	}
+}
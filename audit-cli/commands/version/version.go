@@ -0,0 +1,78 @@
+// Package version implements the version command, which prints the audit-cli version
+// and optionally checks GitHub for a newer release.
+package version
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+
+	"github.com/mongodb/code-example-tooling/audit-cli/internal/release"
+	"github.com/spf13/cobra"
+)
+
+// NewVersionCommand creates the version command.
+//
+// current is the version of the running binary, set at build time.
+//
+// Usage:
+//
+//	version
+//	version --check
+//
+// Flags:
+//   - --check: Also check GitHub for a newer release
+func NewVersionCommand(current string) *cobra.Command {
+	var check bool
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the audit-cli version",
+		Long: `Print the audit-cli version, and the platform it was built for.
+
+With --check, also look up the latest release on GitHub and report whether it's newer.
+Run 'audit-cli self-update' to install it.
+
+Examples:
+  # Print the version
+  version
+
+  # Check for a newer release
+  version --check`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVersion(cmd.Context(), cmd.OutOrStdout(), current, check, release.NewClient())
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "Check GitHub for a newer release")
+
+	return cmd
+}
+
+// runVersion prints the version, and the latest release if check is set.
+func runVersion(ctx context.Context, w io.Writer, current string, check bool, client *release.Client) error {
+	fmt.Fprintf(w, "audit-cli %s (%s/%s)\n", current, runtime.GOOS, runtime.GOARCH)
+	if !check {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	latest, err := client.Latest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check for updates: %w", err)
+	}
+
+	switch {
+	case current == release.DevVersion:
+		fmt.Fprintf(w, "Latest release: %s. This is a development build; run 'audit-cli self-update' to install the release.\n", latest.TagName)
+	case release.CompareVersions(latest.Version(), current) > 0:
+		fmt.Fprintf(w, "A newer release is available: %s. Run 'audit-cli self-update' to install it.\n", latest.TagName)
+	default:
+		fmt.Fprintln(w, "audit-cli is up to date.")
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+// Package gitutil runs git commands for commands that read history or commit changes.
+package gitutil
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Run runs a git command in dir and returns its trimmed output.
+//
+// Parameters:
+//   - dir: Directory to run git in, passed as git -C
+//   - args: The git subcommand and its arguments
+//
+// Returns:
+//   - string: The command's combined output, trimmed of surrounding whitespace
+//   - error: An error including the command and git's output if it failed
+func Run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
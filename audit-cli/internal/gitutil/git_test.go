@@ -0,0 +1,31 @@
+package gitutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Run(dir, "init", "-q"); err != nil {
+		t.Fatalf("git init failed: %v", err)
+	}
+
+	output, err := Run(dir, "rev-parse", "--is-inside-work-tree")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if output != "true" {
+		t.Errorf("Run() = %q, want %q", output, "true")
+	}
+}
+
+func TestRunError(t *testing.T) {
+	_, err := Run(t.TempDir(), "log", "-1")
+	if err == nil {
+		t.Fatal("Run() expected an error outside a repository")
+	}
+	if !strings.HasPrefix(err.Error(), "git log -1: ") {
+		t.Errorf("Run() error = %q, want it to start with the command", err)
+	}
+}
@@ -0,0 +1,39 @@
+package output
+
+import (
+	"os"
+)
+
+// Color is an ANSI terminal style.
+type Color string
+
+const (
+	Bold   Color = "\033[1m"
+	Red    Color = "\033[31m"
+	Green  Color = "\033[32m"
+	Yellow Color = "\033[33m"
+	Cyan   Color = "\033[36m"
+	reset        = "\033[0m"
+)
+
+// Colorize wraps text in the given style if the Writer has color enabled.
+// Otherwise it returns the text unchanged.
+func (w *Writer) Colorize(text string, color Color) string {
+	if !w.color || text == "" {
+		return text
+	}
+	return string(color) + text + reset
+}
+
+// isTerminal reports whether the file is an interactive terminal that should get
+// color. It respects the NO_COLOR convention (https://no-color.org) and TERM=dumb.
+func isTerminal(file *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
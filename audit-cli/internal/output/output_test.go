@@ -0,0 +1,165 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sampleTable() *Table {
+	table := NewTable("Page Counts by Project:",
+		Column{Header: "Project"},
+		Column{Header: "Page Count", Align: AlignRight},
+	)
+	table.AddRow("atlas", 120)
+	table.AddRow("manual", 7)
+	table.Footer = "Total: 127"
+	return table
+}
+
+func render(t *testing.T, format Format, table *Table) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := NewWriter(&buf, format).WriteTable(table); err != nil {
+		t.Fatalf("WriteTable(%s) failed: %v", format, err)
+	}
+	return buf.String()
+}
+
+func TestWriteTableText(t *testing.T) {
+	want := `Page Counts by Project:
+
+  Project  Page Count
+  -------  ----------
+  atlas           120
+  manual            7
+
+Total: 127
+`
+	if got := render(t, FormatText, sampleTable()); got != want {
+		t.Errorf("text output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteTableCSV(t *testing.T) {
+	want := "Project,Page Count\natlas,120\nmanual,7\n"
+	if got := render(t, FormatCSV, sampleTable()); got != want {
+		t.Errorf("CSV output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteTableMarkdown(t *testing.T) {
+	table := sampleTable()
+	table.AddRow("a|b", 1)
+
+	got := render(t, FormatMarkdown, table)
+	for _, want := range []string{
+		"### Page Counts by Project\n",
+		"| Project | Page Count |\n",
+		"| --- | ---: |\n",
+		"| atlas | 120 |\n",
+		`| a\|b | 1 |`,
+		"Total: 127\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown output missing %q\ngot:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteTableJSON(t *testing.T) {
+	got := render(t, FormatJSON, sampleTable())
+
+	// Keys follow column order rather than alphabetical order
+	if strings.Index(got, `"project"`) > strings.Index(got, `"page_count"`) {
+		t.Errorf("expected keys in column order, got:\n%s", got)
+	}
+
+	var rows []map[string]interface{}
+	if err := json.Unmarshal([]byte(got), &rows); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, got)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0]["project"] != "atlas" || rows[0]["page_count"] != float64(120) {
+		t.Errorf("unexpected first row: %v", rows[0])
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		value    string
+		maxWidth int
+		want     string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"this value is too long", 10, "this va..."},
+		{"unlimited", 0, "unlimited"},
+		{"abcdef", 2, "ab"},
+		{"héllo wörld", 8, "héllo..."},
+	}
+
+	for _, tt := range tests {
+		if got := Truncate(tt.value, tt.maxWidth); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.value, tt.maxWidth, got, tt.want)
+		}
+	}
+}
+
+func TestMaxWidthOnlyAppliesToDisplayFormats(t *testing.T) {
+	table := NewTable("", Column{Header: "Path", MaxWidth: 10})
+	table.AddRow("source/includes/very-long-file-name.rst")
+
+	if got := render(t, FormatText, table); !strings.Contains(got, "source/...") {
+		t.Errorf("expected truncated text output, got:\n%s", got)
+	}
+	if got := render(t, FormatCSV, table); !strings.Contains(got, "source/includes/very-long-file-name.rst") {
+		t.Errorf("expected full value in CSV output, got:\n%s", got)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, value := range []string{"text", "json", "csv", "markdown", "md", "JSON"} {
+		if _, err := ParseFormat(value); err != nil {
+			t.Errorf("ParseFormat(%q) returned error: %v", value, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestOpenOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+
+	writer, err := Open(Options{Format: "csv", OutputFile: path})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := writer.WriteTable(sampleTable()); err != nil {
+		t.Fatalf("WriteTable failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "Project,Page Count\n") {
+		t.Errorf("unexpected file content:\n%s", content)
+	}
+}
+
+func TestColorizeDisabledWithoutTerminal(t *testing.T) {
+	writer := NewWriter(&bytes.Buffer{}, FormatText)
+	if got := writer.Colorize("title", Bold); got != "title" {
+		t.Errorf("expected no color codes, got %q", got)
+	}
+}
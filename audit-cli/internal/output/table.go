@@ -0,0 +1,287 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Alignment is the horizontal alignment of a column in text and markdown output.
+type Alignment int
+
+const (
+	// AlignLeft left-aligns the column (the default)
+	AlignLeft Alignment = iota
+	// AlignRight right-aligns the column, for counts and other numbers
+	AlignRight
+)
+
+// truncationMarker replaces the end of cell values that are longer than their column's MaxWidth.
+const truncationMarker = "..."
+
+// Column describes one column of a Table.
+type Column struct {
+	// Header is the column title in text, CSV, and markdown output
+	Header string
+	// Key is the field name in JSON output. Defaults to the header in snake_case.
+	Key string
+	// Align is the column alignment in text and markdown output
+	Align Alignment
+	// MaxWidth truncates longer values in text and markdown output. Zero means no limit.
+	// CSV and JSON output always contain the full value.
+	MaxWidth int
+}
+
+// Table is a titled set of rows with consistent columns.
+type Table struct {
+	// Title is written above the table in text and markdown output
+	Title string
+	// Columns defines the table's columns, in order
+	Columns []Column
+	// Rows holds one value per column for each row. Values keep their type for JSON output.
+	Rows [][]interface{}
+	// Footer is written below the table in text and markdown output, i.e. a total
+	Footer string
+}
+
+// NewTable creates an empty table with the given title and columns.
+func NewTable(title string, columns ...Column) *Table {
+	return &Table{Title: title, Columns: columns}
+}
+
+// AddRow appends a row. Missing values are left blank; extra values are ignored.
+func (t *Table) AddRow(values ...interface{}) {
+	row := make([]interface{}, len(t.Columns))
+	copy(row, values)
+	t.Rows = append(t.Rows, row)
+}
+
+// WriteTable writes the table in the Writer's format.
+//
+// In JSON format, the table is written as an array of objects keyed by column key.
+// Commands that write several tables should build a single value and use WriteJSON
+// instead, so the output is one valid JSON document.
+func (w *Writer) WriteTable(table *Table) error {
+	switch w.format {
+	case FormatJSON:
+		return w.writeTableJSON(table)
+	case FormatCSV:
+		return w.writeTableCSV(table)
+	case FormatMarkdown:
+		return w.writeTableMarkdown(table)
+	default:
+		return w.writeTableText(table)
+	}
+}
+
+func (w *Writer) writeTableText(table *Table) error {
+	if table.Title != "" {
+		w.Println(w.Colorize(table.Title, Bold))
+		w.Println()
+	}
+
+	headers := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		headers[i] = column.Header
+	}
+	cells := table.cells(true)
+	widths := columnWidths(headers, cells)
+
+	separators := make([]string, len(table.Columns))
+	for i := range table.Columns {
+		separators[i] = strings.Repeat("-", widths[i])
+	}
+
+	w.Println(w.Colorize(table.textLine(headers, widths), Bold))
+	w.Println(table.textLine(separators, widths))
+	for _, row := range cells {
+		w.Println(table.textLine(row, widths))
+	}
+
+	if table.Footer != "" {
+		w.Println()
+		w.Println(table.Footer)
+	}
+	return nil
+}
+
+// textLine pads each value to its column width and joins them with two-space gutters.
+// Trailing whitespace is trimmed so the output diffs cleanly.
+func (t *Table) textLine(values []string, widths []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(value))
+		if t.Columns[i].Align == AlignRight {
+			parts[i] = padding + value
+		} else {
+			parts[i] = value + padding
+		}
+	}
+	return strings.TrimRight("  "+strings.Join(parts, "  "), " ")
+}
+
+func (w *Writer) writeTableMarkdown(table *Table) error {
+	if table.Title != "" {
+		w.Printf("### %s\n\n", strings.TrimSuffix(table.Title, ":"))
+	}
+
+	headers := make([]string, len(table.Columns))
+	alignments := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		headers[i] = escapeMarkdown(column.Header)
+		if column.Align == AlignRight {
+			alignments[i] = "---:"
+		} else {
+			alignments[i] = "---"
+		}
+	}
+	w.Printf("| %s |\n", strings.Join(headers, " | "))
+	w.Printf("| %s |\n", strings.Join(alignments, " | "))
+	for _, row := range table.cells(true) {
+		for i, value := range row {
+			row[i] = escapeMarkdown(value)
+		}
+		w.Printf("| %s |\n", strings.Join(row, " | "))
+	}
+
+	if table.Footer != "" {
+		w.Println()
+		w.Println(table.Footer)
+	}
+	w.Println()
+	return nil
+}
+
+func (w *Writer) writeTableCSV(table *Table) error {
+	writer := csv.NewWriter(w.out)
+	headers := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		headers[i] = column.Header
+	}
+	if err := writer.Write(headers); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, row := range table.cells(false) {
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (w *Writer) writeTableJSON(table *Table) error {
+	rows := make([]jsonRow, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		rows = append(rows, jsonRow{columns: table.Columns, values: row})
+	}
+	return w.WriteJSON(rows)
+}
+
+// jsonRow marshals a table row as an object whose keys follow the column order.
+type jsonRow struct {
+	columns []Column
+	values  []interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r jsonRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, column := range r.columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(column.jsonKey())
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonKey returns the column's JSON key, deriving a snake_case key from the header if needed.
+func (c Column) jsonKey() string {
+	if c.Key != "" {
+		return c.Key
+	}
+	key := strings.ToLower(strings.TrimSpace(c.Header))
+	key = strings.NewReplacer(" ", "_", "-", "_", "%", "percent").Replace(key)
+	return key
+}
+
+// cells formats every value as a string, optionally truncating to each column's MaxWidth.
+func (t *Table) cells(truncate bool) [][]string {
+	cells := make([][]string, len(t.Rows))
+	for i, row := range t.Rows {
+		cells[i] = make([]string, len(t.Columns))
+		for j := range t.Columns {
+			value := FormatValue(row[j])
+			if truncate {
+				value = Truncate(value, t.Columns[j].MaxWidth)
+			}
+			cells[i][j] = value
+		}
+	}
+	return cells
+}
+
+// FormatValue formats a cell value for text, CSV, and markdown output.
+// Nil values are blank and floats use the shortest exact representation.
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Truncate shortens a value to at most maxWidth characters, ending with "..." when
+// it was cut. A maxWidth of zero or less means no limit.
+func Truncate(value string, maxWidth int) string {
+	if maxWidth <= 0 || utf8.RuneCountInString(value) <= maxWidth {
+		return value
+	}
+	if maxWidth <= len(truncationMarker) {
+		return string([]rune(value)[:maxWidth])
+	}
+	return string([]rune(value)[:maxWidth-len(truncationMarker)]) + truncationMarker
+}
+
+func columnWidths(headers []string, rows [][]string) []int {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = utf8.RuneCountInString(header)
+	}
+	for _, row := range rows {
+		for i, value := range row {
+			if width := utf8.RuneCountInString(value); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+	return widths
+}
+
+func escapeMarkdown(value string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ").Replace(value)
+}
@@ -0,0 +1,175 @@
+// Package output provides a shared rendering layer for command results.
+//
+// Commands describe their results as tables (or as JSON-serializable values) and
+// write them through a Writer, which handles the output format, column alignment,
+// truncation, colorization, and writing to a file instead of stdout. Adding a new
+// output format is a change to this package rather than to every command.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Format is an output format for command results.
+type Format string
+
+const (
+	// FormatText is the default human-readable format with aligned columns
+	FormatText Format = "text"
+	// FormatJSON is indented JSON
+	FormatJSON Format = "json"
+	// FormatCSV is comma-separated values with a header row
+	FormatCSV Format = "csv"
+	// FormatMarkdown is a GitHub-flavored markdown table, for pasting into tickets and docs
+	FormatMarkdown Format = "markdown"
+)
+
+// Formats lists every supported output format.
+var Formats = []Format{FormatText, FormatJSON, FormatCSV, FormatMarkdown}
+
+// ParseFormat validates a --format flag value. "md" is accepted as an alias for markdown.
+func ParseFormat(value string) (Format, error) {
+	format := Format(strings.ToLower(strings.TrimSpace(value)))
+	if format == "md" {
+		format = FormatMarkdown
+	}
+	for _, supported := range Formats {
+		if format == supported {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("invalid format: %s (must be one of: %s)", value, formatList())
+}
+
+// Options holds the output flags shared by commands.
+type Options struct {
+	// Format is the requested output format (text, json, csv, or markdown)
+	Format string
+	// OutputFile is the path to write results to. Empty means stdout.
+	OutputFile string
+	// NoColor disables colorized text output
+	NoColor bool
+}
+
+// Validate checks the options without opening the output file, so commands can fail
+// fast on an invalid --format before doing any work.
+func (opts Options) Validate() error {
+	if opts.Format == "" {
+		return nil
+	}
+	_, err := ParseFormat(opts.Format)
+	return err
+}
+
+// AddFlags registers the --format, --output-file, and --no-color flags on a command.
+//
+// Commands that already define their own --format flag should register --output-file
+// and --no-color with AddFileFlags instead.
+func AddFlags(cmd *cobra.Command, opts *Options) {
+	cmd.Flags().StringVar(&opts.Format, "format", string(FormatText), "Output format: "+formatList())
+	AddFileFlags(cmd, opts)
+}
+
+// AddFileFlags registers only the --output-file and --no-color flags on a command.
+func AddFileFlags(cmd *cobra.Command, opts *Options) {
+	cmd.Flags().StringVar(&opts.OutputFile, "output-file", "", "Write results to this file instead of stdout")
+	cmd.Flags().BoolVar(&opts.NoColor, "no-color", false, "Disable colorized output")
+}
+
+// Writer writes command results in a single output format.
+//
+// Always call Close when done; it closes the output file, if one was opened.
+type Writer struct {
+	out    io.Writer
+	closer io.Closer
+	format Format
+	color  bool
+}
+
+// Open validates the options and returns a Writer for stdout or for the output file.
+//
+// Color is only enabled for text output to a terminal, and is disabled by --no-color
+// or the NO_COLOR environment variable.
+func Open(opts Options) (*Writer, error) {
+	format := FormatText
+	if opts.Format != "" {
+		parsed, err := ParseFormat(opts.Format)
+		if err != nil {
+			return nil, err
+		}
+		format = parsed
+	}
+
+	if opts.OutputFile == "" {
+		return &Writer{
+			out:    os.Stdout,
+			format: format,
+			color:  format == FormatText && !opts.NoColor && isTerminal(os.Stdout),
+		}, nil
+	}
+
+	file, err := os.Create(opts.OutputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file %s: %w", opts.OutputFile, err)
+	}
+	return &Writer{out: file, closer: file, format: format}, nil
+}
+
+// NewWriter returns a Writer that writes to w without color. It's mainly useful in tests.
+func NewWriter(w io.Writer, format Format) *Writer {
+	return &Writer{out: w, format: format}
+}
+
+// Format returns the Writer's output format.
+func (w *Writer) Format() Format {
+	return w.format
+}
+
+// Write implements io.Writer so commands can write free-form text output directly.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.out.Write(p)
+}
+
+// Print writes free-form text.
+func (w *Writer) Print(args ...interface{}) {
+	fmt.Fprint(w.out, args...)
+}
+
+// Printf writes formatted free-form text.
+func (w *Writer) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(w.out, format, args...)
+}
+
+// Println writes a line of free-form text.
+func (w *Writer) Println(args ...interface{}) {
+	fmt.Fprintln(w.out, args...)
+}
+
+// WriteJSON writes any value as indented JSON, regardless of the Writer's format.
+func (w *Writer) WriteJSON(value interface{}) error {
+	encoder := json.NewEncoder(w.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// Close closes the output file, if the Writer opened one.
+func (w *Writer) Close() error {
+	if w.closer == nil {
+		return nil
+	}
+	return w.closer.Close()
+}
+
+func formatList() string {
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, ", ")
+}
@@ -1,6 +1,7 @@
 package projectinfo
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -50,6 +51,29 @@ func TestFindSourceDirectory(t *testing.T) {
 	}
 }
 
+func TestFindContentDirectory(t *testing.T) {
+	root := t.TempDir()
+	contentDir := filepath.Join(root, "content")
+	if err := os.Mkdir(contentDir, 0755); err != nil {
+		t.Fatalf("failed to create content directory: %v", err)
+	}
+
+	// Both the monorepo root and the content directory resolve to the content directory
+	for _, dirPath := range []string{root, contentDir} {
+		got, err := FindContentDirectory(dirPath)
+		if err != nil {
+			t.Fatalf("FindContentDirectory(%s) error = %v", dirPath, err)
+		}
+		if got != contentDir {
+			t.Errorf("FindContentDirectory(%s) = %v, want %v", dirPath, got, contentDir)
+		}
+	}
+
+	if _, err := FindContentDirectory(t.TempDir()); err == nil {
+		t.Error("FindContentDirectory() expected an error for a directory without content")
+	}
+}
+
 func TestDetectProjectInfo(t *testing.T) {
 	tests := []struct {
 		name        string
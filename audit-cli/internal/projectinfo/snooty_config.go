@@ -0,0 +1,119 @@
+package projectinfo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SnootyConfigFileName is the name of the project configuration file that sits next to
+// the source directory.
+const SnootyConfigFileName = "snooty.toml"
+
+// SnootyConfig holds the values from a project's snooty.toml that change how a page renders.
+type SnootyConfig struct {
+	// Path is the snooty.toml file the values were read from (empty if none was found)
+	Path string
+
+	// Constants are the source constants from the [constants] table, used as {+name+}
+	Constants map[string]string
+
+	// Substitutions are the values from the [substitutions] table, used as |name|
+	Substitutions map[string]string
+}
+
+// LoadSnootyConfig finds and reads the snooty.toml for the project containing filePath.
+//
+// The file is expected in the parent of the source directory. A project without a
+// snooty.toml gets an empty config rather than an error, so callers can still render
+// pages that don't use constants.
+//
+// Only simple string values (key = "value") in the [constants] and [substitutions]
+// tables are read. Other tables and value types are ignored.
+//
+// Parameters:
+//   - filePath: Path to a file within the documentation tree
+//
+// Returns:
+//   - *SnootyConfig: The constants and substitutions for the project
+//   - error: Any error encountered reading the file
+func LoadSnootyConfig(filePath string) (*SnootyConfig, error) {
+	config := &SnootyConfig{
+		Constants:     make(map[string]string),
+		Substitutions: make(map[string]string),
+	}
+
+	sourceDir, err := FindSourceDirectory(filePath)
+	if err != nil {
+		return config, nil
+	}
+
+	configPath := filepath.Join(filepath.Dir(sourceDir), SnootyConfigFileName)
+	file, err := os.Open(configPath)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", configPath, err)
+	}
+	defer file.Close()
+	config.Path = configPath
+
+	var table map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			switch strings.Trim(line, "[] ") {
+			case "constants":
+				table = config.Constants
+			case "substitutions":
+				table = config.Substitutions
+			default:
+				table = nil
+			}
+			continue
+		}
+
+		if table == nil {
+			continue
+		}
+		key, value, ok := parseTOMLString(line)
+		if ok {
+			table[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	return config, nil
+}
+
+// parseTOMLString parses a single-line TOML string assignment such as: version = "8.0"
+func parseTOMLString(line string) (string, string, bool) {
+	key, value, found := strings.Cut(line, "=")
+	if !found {
+		return "", "", false
+	}
+	key = strings.Trim(strings.TrimSpace(key), `"`)
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return "", "", false
+	}
+	quote := value[0]
+	if quote != '"' && quote != '\'' {
+		return "", "", false
+	}
+	end := strings.IndexByte(value[1:], quote)
+	if end == -1 {
+		return "", "", false
+	}
+	return key, value[1 : end+1], true
+}
@@ -53,3 +53,28 @@ func FindSourceDirectory(filePath string) (string, error) {
 	}
 }
 
+// FindContentDirectory finds the content directory of a docs monorepo.
+//
+// Commands that scan the whole monorepo accept either the monorepo root or its
+// "content" directory, which holds one subdirectory per project.
+//
+// Parameters:
+//   - dirPath: Path to the monorepo root or its content directory
+//
+// Returns:
+//   - string: Path to the content directory
+//   - error: Error if dirPath isn't a content directory and doesn't contain one
+func FindContentDirectory(dirPath string) (string, error) {
+	// Check if this is already a content directory
+	if filepath.Base(dirPath) == "content" {
+		return dirPath, nil
+	}
+
+	// Check if there's a content subdirectory
+	contentDir := filepath.Join(dirPath, "content")
+	if _, err := os.Stat(contentDir); err == nil {
+		return contentDir, nil
+	}
+
+	return "", fmt.Errorf("content directory not found in: %s\n\nPlease provide the path to the monorepo root or content directory", dirPath)
+}
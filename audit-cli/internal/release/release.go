@@ -0,0 +1,379 @@
+// Package release finds audit-cli releases on GitHub and installs them.
+//
+// Releases are published by GoReleaser (see .goreleaser.yaml) with one archive per
+// platform and a checksums file:
+//
+//	audit-cli_1.4.0_darwin_arm64.tar.gz
+//	audit-cli_1.4.0_windows_amd64.zip
+//	audit-cli_1.4.0_checksums.txt
+//
+// The version and self-update commands use this package to check for newer releases
+// and to replace the running binary in place.
+package release
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAPIURL is the GitHub REST API the client reads releases from.
+	DefaultAPIURL = "https://api.github.com"
+
+	// DefaultRepo is the repository audit-cli releases are published to.
+	DefaultRepo = "mongodb/code-example-tooling"
+
+	// BinaryName is the name of the executable in release archives.
+	BinaryName = "audit-cli"
+
+	// DevVersion is the version reported by builds that weren't made from a release tag.
+	DevVersion = "dev"
+)
+
+// maxDownloadBytes limits how much of a release asset is read.
+const maxDownloadBytes = 200 << 20
+
+// versionTag matches release tags, such as "v1.4.0".
+var versionTag = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)$`)
+
+// Release is a published GitHub release.
+type Release struct {
+	TagName    string  `json:"tag_name"`
+	HTMLURL    string  `json:"html_url"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// Version returns the release's version without the leading "v".
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Asset returns the release asset with the given name.
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Client reads releases from GitHub.
+type Client struct {
+	APIURL     string
+	Repo       string
+	Token      string // Optional; raises the API rate limit
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for the default repository. GITHUB_TOKEN is used
+// if it's set.
+func NewClient() *Client {
+	return &Client{
+		APIURL:     DefaultAPIURL,
+		Repo:       DefaultRepo,
+		Token:      os.Getenv("GITHUB_TOKEN"),
+		HTTPClient: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Latest returns the newest release that isn't a draft or prerelease. Releases
+// whose tags aren't versions, such as releases of other tools in the repository,
+// are skipped.
+func (c *Client) Latest(ctx context.Context) (*Release, error) {
+	var releases []Release
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/repos/%s/releases?per_page=100", c.APIURL, c.Repo), &releases); err != nil {
+		return nil, err
+	}
+
+	var latest *Release
+	for i := range releases {
+		rel := &releases[i]
+		if rel.Draft || rel.Prerelease || !versionTag.MatchString(rel.TagName) {
+			continue
+		}
+		if latest == nil || CompareVersions(rel.Version(), latest.Version()) > 0 {
+			latest = rel
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no releases found in %s", c.Repo)
+	}
+	return latest, nil
+}
+
+// ByTag returns the release with the given tag, such as "v1.4.0".
+func (c *Client) ByTag(ctx context.Context, tag string) (*Release, error) {
+	if !strings.HasPrefix(tag, "v") {
+		tag = "v" + tag
+	}
+	var rel Release
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/repos/%s/releases/tags/%s", c.APIURL, c.Repo, tag), &rel); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// Download returns the content of a release asset.
+func (c *Client) Download(ctx context.Context, asset Asset) ([]byte, error) {
+	resp, err := c.get(ctx, asset.BrowserDownloadURL, "application/octet-stream")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	return data, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, url string, out interface{}) error {
+	resp, err := c.get(ctx, url, "application/vnd.github+json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %w", url, err)
+	}
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, url string, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s returned status %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// CompareVersions compares two versions such as "1.4.0" or "v1.10.2", returning
+// -1, 0, or 1. Versions that can't be parsed, such as DevVersion, are older than
+// any release.
+func CompareVersions(a, b string) int {
+	pa, okA := parseVersion(a)
+	pb, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	m := versionTag.FindStringSubmatch(strings.TrimSpace(v))
+	if m == nil {
+		return parts, false
+	}
+	for i := range parts {
+		parts[i], _ = strconv.Atoi(m[i+1])
+	}
+	return parts, true
+}
+
+// ArchiveName returns the name of the release archive for a platform.
+func ArchiveName(version, goos, goarch string) string {
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("%s_%s_%s_%s.%s", BinaryName, strings.TrimPrefix(version, "v"), goos, goarch, ext)
+}
+
+// ChecksumsName returns the name of the release's checksums file.
+func ChecksumsName(version string) string {
+	return fmt.Sprintf("%s_%s_checksums.txt", BinaryName, strings.TrimPrefix(version, "v"))
+}
+
+// FetchBinary downloads the release archive for the current platform, verifies it
+// against the release's checksums file, and returns the executable inside it.
+func (c *Client) FetchBinary(ctx context.Context, rel *Release) ([]byte, error) {
+	archiveName := ArchiveName(rel.Version(), runtime.GOOS, runtime.GOARCH)
+	archiveAsset, ok := rel.Asset(archiveName)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no build for %s/%s (expected %s)", rel.TagName, runtime.GOOS, runtime.GOARCH, archiveName)
+	}
+	checksumsAsset, ok := rel.Asset(ChecksumsName(rel.Version()))
+	if !ok {
+		return nil, fmt.Errorf("release %s has no checksums file", rel.TagName)
+	}
+
+	checksums, err := c.Download(ctx, checksumsAsset)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := c.Download(ctx, archiveAsset)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyChecksum(archive, archiveName, checksums); err != nil {
+		return nil, err
+	}
+	return ExtractBinary(archive, archiveName)
+}
+
+// VerifyChecksum checks data against the SHA-256 checksum listed for name in a
+// checksums file, which has one "<hex digest>  <file name>" line per file.
+func VerifyChecksum(data []byte, name string, checksums []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[1] != name {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("no checksum listed for %s", name)
+}
+
+// ExtractBinary returns the audit-cli executable from a release archive. The
+// archive type is taken from its name.
+func ExtractBinary(archive []byte, archiveName string) ([]byte, error) {
+	if strings.HasSuffix(archiveName, ".zip") {
+		return extractFromZip(archive, BinaryName+".exe")
+	}
+	return extractFromTarGz(archive, BinaryName)
+}
+
+func extractFromTarGz(archive []byte, binary string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == binary {
+			return io.ReadAll(io.LimitReader(tr, maxDownloadBytes))
+		}
+	}
+	return nil, fmt.Errorf("archive doesn't contain %s", binary)
+}
+
+func extractFromZip(archive []byte, binary string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if filepath.Base(f.Name) != binary {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from archive: %w", binary, err)
+		}
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, maxDownloadBytes))
+	}
+	return nil, fmt.Errorf("archive doesn't contain %s", binary)
+}
+
+// ReplaceExecutable replaces the executable at path with binary. The new binary is
+// written next to the old one and renamed over it, so a failed update leaves the
+// old binary in place. On Windows, where a running executable can't be
+// overwritten, the old binary is first moved aside to path + ".old".
+func ReplaceExecutable(path string, binary []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".new-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s: %w", filepath.Dir(path), err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()|0o111); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		oldPath := path + ".old"
+		_ = os.Remove(oldPath)
+		if err := os.Rename(path, oldPath); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,209 @@
+package release
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// tarGz builds a .tar.gz archive holding the given files.
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func checksumLine(data []byte, name string) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]) + "  " + name + "\n"
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.4.0", "1.4.0", 0},
+		{"v1.4.0", "1.4.0", 0},
+		{"1.10.0", "1.9.3", 1},
+		{"1.4.0", "1.4.1", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0", DevVersion, 1},
+		{DevVersion, "1.0.0", -1},
+		{DevVersion, DevVersion, 0},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLatestSkipsDraftsPrereleasesAndOtherTags(t *testing.T) {
+	releases := []Release{
+		{TagName: "v2.0.0-rc.1", Prerelease: true},
+		{TagName: "v1.9.0", Draft: true},
+		{TagName: "copier-2025-01-01"},
+		{TagName: "v1.3.2"},
+		{TagName: "v1.10.0"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/mongodb/code-example-tooling/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization header = %q, want bearer token", got)
+		}
+		json.NewEncoder(w).Encode(releases)
+	}))
+	defer server.Close()
+
+	client := &Client{APIURL: server.URL, Repo: DefaultRepo, Token: "token"}
+	latest, err := client.Latest(context.Background())
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	if latest.TagName != "v1.10.0" {
+		t.Errorf("Latest = %s, want v1.10.0", latest.TagName)
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("archive contents")
+	checksums := []byte(checksumLine([]byte("other"), "other.tar.gz") + checksumLine(data, "audit-cli.tar.gz"))
+
+	if err := VerifyChecksum(data, "audit-cli.tar.gz", checksums); err != nil {
+		t.Errorf("VerifyChecksum failed for matching data: %v", err)
+	}
+	if err := VerifyChecksum([]byte("tampered"), "audit-cli.tar.gz", checksums); err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Errorf("VerifyChecksum = %v, want checksum mismatch", err)
+	}
+	if err := VerifyChecksum(data, "missing.tar.gz", checksums); err == nil {
+		t.Error("VerifyChecksum succeeded for a file with no checksum")
+	}
+}
+
+func TestExtractBinary(t *testing.T) {
+	archive := tarGz(t, map[string]string{"README.md": "readme", "audit-cli": "binary"})
+	got, err := ExtractBinary(archive, "audit-cli_1.4.0_linux_amd64.tar.gz")
+	if err != nil {
+		t.Fatalf("ExtractBinary failed: %v", err)
+	}
+	if string(got) != "binary" {
+		t.Errorf("ExtractBinary = %q, want %q", got, "binary")
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, _ := zw.Create("audit-cli.exe")
+	f.Write([]byte("windows binary"))
+	zw.Close()
+	got, err = ExtractBinary(buf.Bytes(), "audit-cli_1.4.0_windows_amd64.zip")
+	if err != nil {
+		t.Fatalf("ExtractBinary (zip) failed: %v", err)
+	}
+	if string(got) != "windows binary" {
+		t.Errorf("ExtractBinary (zip) = %q, want %q", got, "windows binary")
+	}
+
+	if _, err := ExtractBinary(tarGz(t, map[string]string{"README.md": "readme"}), "a.tar.gz"); err == nil {
+		t.Error("ExtractBinary succeeded for an archive without the binary")
+	}
+}
+
+func TestFetchBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a .tar.gz archive")
+	}
+	archiveName := ArchiveName("1.4.0", runtime.GOOS, runtime.GOARCH)
+	archive := tarGz(t, map[string]string{"audit-cli": "new binary"})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/download/" + archiveName:
+			w.Write(archive)
+		case "/download/checksums.txt":
+			w.Write([]byte(checksumLine(archive, archiveName)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	rel := &Release{TagName: "v1.4.0", Assets: []Asset{
+		{Name: archiveName, BrowserDownloadURL: server.URL + "/download/" + archiveName},
+		{Name: ChecksumsName("1.4.0"), BrowserDownloadURL: server.URL + "/download/checksums.txt"},
+	}}
+	client := &Client{APIURL: server.URL, Repo: DefaultRepo}
+	binary, err := client.FetchBinary(context.Background(), rel)
+	if err != nil {
+		t.Fatalf("FetchBinary failed: %v", err)
+	}
+	if string(binary) != "new binary" {
+		t.Errorf("FetchBinary = %q, want %q", binary, "new binary")
+	}
+
+	rel.Assets = rel.Assets[1:]
+	if _, err := client.FetchBinary(context.Background(), rel); err == nil || !strings.Contains(err.Error(), "no build") {
+		t.Errorf("FetchBinary = %v, want missing build error", err)
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit-cli")
+	if err := os.WriteFile(path, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReplaceExecutable(path, []byte("new")); err != nil {
+		t.Fatalf("ReplaceExecutable failed: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "new" {
+		t.Errorf("executable content = %q, want %q", got, "new")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+		t.Errorf("executable mode = %v, want executable", info.Mode())
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the executable to remain, found %d entries", len(entries))
+	}
+}
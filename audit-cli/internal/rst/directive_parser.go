@@ -13,6 +13,7 @@ package rst
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"strings"
@@ -105,8 +106,23 @@ func ParseDirectives(filePath string) ([]Directive, error) {
 	}
 	defer file.Close()
 
+	return ParseDirectivesFromReader(file)
+}
+
+// ParseDirectivesFromReader parses all directives from RST content.
+//
+// This is the same as ParseDirectives, for content that isn't in a file, such as
+// one side of a diff hunk. Line numbers are relative to the start of the content.
+//
+// Parameters:
+//   - r: Reader for the RST content to parse
+//
+// Returns:
+//   - []Directive: Slice of all parsed directives in order of appearance
+//   - error: Any error encountered during parsing
+func ParseDirectivesFromReader(r io.Reader) ([]Directive, error) {
 	var directives []Directive
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 	lineNum := 0
 
 	for scanner.Scan() {
@@ -55,3 +55,19 @@ var ComposableTutorialDirectiveRegex = regexp.MustCompile(`^\.\.\s+composable-tu
 // Example: .. selected-content::
 var SelectedContentDirectiveRegex = regexp.MustCompile(`^\.\.\s+selected-content::`)
 
+// VersionDirectiveRegex matches versioned content directives in RST files.
+// Captures the directive name (versionadded, versionchanged, or deprecated) and its argument.
+// Also matches directives that start a bullet list item, such as in a list-table cell.
+// Example: .. versionadded:: 7.0
+var VersionDirectiveRegex = regexp.MustCompile(`^\s*(?:[-*]\s+)?\.\.\s+(versionadded|versionchanged|deprecated)::\s*(.*)$`)
+
+// AnyDirectiveRegex matches any directive in RST files, at any indentation.
+// Captures the directive name and its argument.
+// Also matches directives after bullet list markers, such as in a list-table cell ("* - .. cssclass::").
+// Example: .. cssclass:: table-striped
+var AnyDirectiveRegex = regexp.MustCompile(`^\s*(?:[-*]\s+)*\.\.\s+([A-Za-z][\w.:-]*)::\s*(.*)$`)
+
+// SharedIncludeDirectiveRegex matches .. sharedinclude:: directives, at any indentation.
+// Captures the path of the include in the shared content repository.
+// Example: .. sharedinclude:: dbx/compatibility-table-legend.rst
+var SharedIncludeDirectiveRegex = regexp.MustCompile(`^\s*\.\.\s+sharedinclude::\s+(\S+)`)
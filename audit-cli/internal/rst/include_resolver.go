@@ -431,3 +431,23 @@ func ResolveTemplateVariable(yamlFilePath, varName string) (string, error) {
 
 
 
+
+// ExpandIncludes reads a file and replaces each .. include:: directive with the content
+// of the included file, recursively.
+//
+// Includes that can't be resolved are left in place as directives. Steps files are
+// converted to procedure directives, the same way procedure parsing expands them.
+//
+// Parameters:
+//   - filePath: Path to the RST file to expand
+//
+// Returns:
+//   - []string: The file's lines with includes expanded
+//   - error: Any error encountered reading the file
+func ExpandIncludes(filePath string) ([]string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	return expandIncludesInLines(filePath, strings.Split(string(content), "\n"))
+}
@@ -10,6 +10,8 @@
 //   - analyze: Analyze RST file structures and relationships
 //   - compare: Compare files across different versions
 //   - count: Count documentation content (code examples, pages)
+//
+// The version and self-update commands report and update the installed version.
 package main
 
 import (
@@ -18,9 +20,15 @@ import (
 	"github.com/mongodb/code-example-tooling/audit-cli/commands/count"
 	"github.com/mongodb/code-example-tooling/audit-cli/commands/extract"
 	"github.com/mongodb/code-example-tooling/audit-cli/commands/search"
+	self_update "github.com/mongodb/code-example-tooling/audit-cli/commands/self-update"
+	"github.com/mongodb/code-example-tooling/audit-cli/commands/version"
 	"github.com/spf13/cobra"
 )
 
+// buildVersion is the release version, set at build time with
+// -ldflags "-X main.buildVersion=1.4.0". Builds from source report "dev".
+var buildVersion = "dev"
+
 func main() {
 	var rootCmd = &cobra.Command{
 		Use:   "audit-cli",
@@ -43,6 +51,8 @@ Designed for maintenance tasks, scoping work, and reporting to stakeholders.`,
 	rootCmd.AddCommand(analyze.NewAnalyzeCommand())
 	rootCmd.AddCommand(compare.NewCompareCommand())
 	rootCmd.AddCommand(count.NewCountCommand())
+	rootCmd.AddCommand(version.NewVersionCommand(buildVersion))
+	rootCmd.AddCommand(self_update.NewSelfUpdateCommand(buildVersion))
 
 	err := rootCmd.Execute()
 	if err != nil {
@@ -15,4 +15,8 @@ type CodeNode struct {
 	DateRemoved     time.Time `bson:"date_removed,omitempty"`
 	IsRemoved       bool      `bson:"is_removed,omitempty"`
 	InstancesOnPage int       `bson:"instances_on_page,omitempty"`
+	IncludePath     string    `bson:"include_path,omitempty"`
+	SourceRepo      string    `bson:"source_repo,omitempty"`
+	SourcePath      string    `bson:"source_path,omitempty"`
+	Anchor          string    `bson:"anchor,omitempty"`
 }
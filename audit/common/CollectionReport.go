@@ -3,4 +3,7 @@ package common
 type CollectionReport struct {
 	ID      string                        `bson:"_id" json:"_id"`
 	Version map[string]CollectionInfoView `bson:"version" json:"version"`
+	// History holds the collection's totals by date (YYYY-MM-DD) for trend reports. Each run records the date it ran,
+	// and backfill records the dates of archived Snooty snapshots from before GDCD ran.
+	History map[string]CollectionInfoView `bson:"history,omitempty" json:"history,omitempty"`
 }
@@ -85,4 +85,33 @@ func PerformAggregation(db *mongo.Database, ctx context.Context) {
 	//utils.PrintPageIdsWithNodeLangCountMismatch(pageIdsWithNodeLangCountMismatch)
 	//utils.PrintPageIdNewAppliedUsageExampleCounts(productSubProductCounter)
 	utils.PrintMonthlyUsageExampleCounts(productSubProductCounter, monthForReporting)
+
+	// To export the results to a spreadsheet instead of copying tables from the console, build a sheet for each map you
+	// populated above, then write them to a single XLSX workbook with one tab per sheet, and/or one CSV file per sheet
+	//sheets := []types.Sheet{
+	//	exports.BuildProductLanguageSheet(nestedOneLevelMap),
+	//	exports.BuildLanguageSheet(simpleMap),
+	//	exports.BuildMonthlyUsageExampleSheet(productSubProductCounter, monthForReporting),
+	//}
+	//if err := exports.WriteSheetsToXLSX(exports.DefaultReportFileName("xlsx"), sheets); err != nil {
+	//	log.Fatalf("Failed to export report: %v", err)
+	//}
+	//if _, err := exports.WriteSheetsToCSV("reports", sheets); err != nil {
+	//	log.Fatalf("Failed to export report: %v", err)
+	//}
+
+	// To catch upstream parser bugs before reporting the numbers, compare the output against snapshots of earlier runs,
+	// flag counts that are unusually far from their history, then save this run as a new snapshot. Anomaly checks need at
+	// least three earlier snapshots. Use the same snapshot name for the same aggregation every run.
+	//productLanguageCounts := anomalies.FlattenNestedCounts(nestedOneLevelMap)
+	//history, err := anomalies.LoadSnapshots(anomalies.DefaultSnapshotDir, "product-language-counts")
+	//if err != nil {
+	//	log.Fatalf("Failed to load snapshots: %v", err)
+	//}
+	//productLanguageAnomalies := anomalies.DetectAnomalies(productLanguageCounts, history, anomalies.DefaultZScoreThreshold)
+	//utils.PrintAnomaliesToConsole(productLanguageAnomalies, "Product Language")
+	//sheets = append(sheets, exports.BuildAnomalySheet(productLanguageAnomalies))
+	//if _, err := anomalies.SaveSnapshot(anomalies.DefaultSnapshotDir, "product-language-counts", productLanguageCounts); err != nil {
+	//	log.Fatalf("Failed to save snapshot: %v", err)
+	//}
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// writeActions are the privilege actions that let a user change data or collections in a database.
+var writeActions = map[string]bool{
+	"insert":                   true,
+	"update":                   true,
+	"remove":                   true,
+	"bypassDocumentValidation": true,
+	"createCollection":         true,
+	"dropCollection":           true,
+	"dropDatabase":             true,
+	"renameCollectionSameDB":   true,
+	"createIndex":              true,
+	"dropIndex":                true,
+	"collMod":                  true,
+	"convertToCapped":          true,
+}
+
+// connectionStatus holds the parts of the `connectionStatus` command's response used to check the user's privileges.
+type connectionStatus struct {
+	AuthInfo struct {
+		AuthenticatedUsers []struct {
+			User string `bson:"user"`
+			DB   string `bson:"db"`
+		} `bson:"authenticatedUsers"`
+		AuthenticatedUserPrivileges []struct {
+			Resource struct {
+				DB          *string `bson:"db"`
+				Collection  *string `bson:"collection"`
+				AnyResource bool    `bson:"anyResource"`
+			} `bson:"resource"`
+			Actions []string `bson:"actions"`
+		} `bson:"authenticatedUserPrivileges"`
+	} `bson:"authInfo"`
+}
+
+// VerifyReadOnlyUser returns an error unless the connection string's user is authenticated and can't write to the
+// dbName database. Run with `--read-only` when you only want to pull numbers, so a connection string for a user with
+// write access to the production metrics DB can't be used by mistake.
+func VerifyReadOnlyUser(client *mongo.Client, dbName string, ctx context.Context) error {
+	var status connectionStatus
+	command := bson.D{{Key: "connectionStatus", Value: 1}, {Key: "showPrivileges", Value: true}}
+	if err := client.Database("admin").RunCommand(ctx, command).Decode(&status); err != nil {
+		return fmt.Errorf("failed to check the connection's privileges: %w", err)
+	}
+	if len(status.AuthInfo.AuthenticatedUsers) == 0 {
+		return fmt.Errorf("the connection isn't authenticated as a user, so its privileges can't be verified")
+	}
+
+	found := make(map[string]bool)
+	for _, privilege := range status.AuthInfo.AuthenticatedUserPrivileges {
+		resource := privilege.Resource
+		// A resource with an empty db matches every database
+		if !resource.AnyResource && (resource.DB == nil || (*resource.DB != "" && *resource.DB != dbName)) {
+			continue
+		}
+		for _, action := range privilege.Actions {
+			if writeActions[action] {
+				found[action] = true
+			}
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+
+	actions := make([]string, 0, len(found))
+	for action := range found {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	user := status.AuthInfo.AuthenticatedUsers[0]
+	return fmt.Errorf("user %s@%s can write to %s (%s); use a read-only user with --read-only",
+		user.User, user.DB, dbName, strings.Join(actions, ", "))
+}
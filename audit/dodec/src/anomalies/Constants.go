@@ -0,0 +1,18 @@
+package anomalies
+
+const (
+	// DefaultZScoreThreshold flags counts more than three standard deviations from the historical mean
+	DefaultZScoreThreshold = 3.0
+
+	// MinHistoryPoints is the number of snapshots a key needs before we check it. With fewer points, the standard
+	// deviation isn't meaningful.
+	MinHistoryPoints = 3
+
+	// MinStdDev is the smallest standard deviation we divide by. Counts that never changed have a standard deviation
+	// of 0, which would flag a change of a single example; this makes a count that held steady at 100 need to move by
+	// more than DefaultZScoreThreshold examples to be flagged.
+	MinStdDev = 1.0
+
+	// DefaultSnapshotDir is where SaveSnapshot writes snapshots when no directory is given
+	DefaultSnapshotDir = "snapshots"
+)
@@ -0,0 +1,79 @@
+package anomalies
+
+import (
+	"dodec/types"
+	"math"
+	"sort"
+)
+
+// DetectAnomalies compares each key's current count against its counts in the historical snapshots, and returns the
+// keys whose z-score - the number of standard deviations from the historical mean - is at or above the threshold in
+// either direction. For example, a product's usage example count doubling overnight has a very high z-score, and often
+// points to a parser bug upstream rather than a real change in the docs.
+//
+// A key that is missing from a snapshot counts as 0 in that snapshot, and a key that is missing from the current counts
+// counts as 0 now, so keys that suddenly disappear are flagged too. Keys with fewer than MinHistoryPoints snapshots are
+// skipped. Results are sorted by the absolute z-score, largest first.
+func DetectAnomalies(current map[string]int, history []types.Snapshot, threshold float64) []types.Anomaly {
+	if len(history) < MinHistoryPoints {
+		return nil
+	}
+
+	keys := make(map[string]bool)
+	for key := range current {
+		keys[key] = true
+	}
+	for _, snapshot := range history {
+		for key := range snapshot.Counts {
+			keys[key] = true
+		}
+	}
+
+	var anomalies []types.Anomaly
+	for key := range keys {
+		if key == types.Total {
+			continue
+		}
+		values := make([]float64, 0, len(history))
+		for _, snapshot := range history {
+			values = append(values, float64(snapshot.Counts[key]))
+		}
+
+		mean, stdDev := meanAndStdDev(values)
+		zScore := (float64(current[key]) - mean) / math.Max(stdDev, MinStdDev)
+		if math.Abs(zScore) < threshold {
+			continue
+		}
+		anomalies = append(anomalies, types.Anomaly{
+			Key:           key,
+			Current:       current[key],
+			Mean:          mean,
+			StdDev:        stdDev,
+			ZScore:        zScore,
+			HistoryPoints: len(values),
+		})
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		if math.Abs(anomalies[i].ZScore) != math.Abs(anomalies[j].ZScore) {
+			return math.Abs(anomalies[i].ZScore) > math.Abs(anomalies[j].ZScore)
+		}
+		return anomalies[i].Key < anomalies[j].Key
+	})
+	return anomalies
+}
+
+// meanAndStdDev returns the mean and population standard deviation of the values
+func meanAndStdDev(values []float64) (float64, float64) {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+
+	var squaredDiffs float64
+	for _, value := range values {
+		squaredDiffs += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(squaredDiffs / float64(len(values)))
+}
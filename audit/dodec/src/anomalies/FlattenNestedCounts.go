@@ -0,0 +1,14 @@
+package anomalies
+
+// FlattenNestedCounts turns a `nestedOneLevelMap` - as returned by aggregations such as GetProductLanguageCounts - into
+// a flat map keyed by "<outer key> / <inner key>", i.e. "Drivers / python", so it can be snapshotted and checked for
+// anomalies like a `simpleMap`.
+func FlattenNestedCounts(nestedOneLevelMap map[string]map[string]int) map[string]int {
+	flattened := make(map[string]int)
+	for outerKey, innerMap := range nestedOneLevelMap {
+		for innerKey, count := range innerMap {
+			flattened[outerKey+" / "+innerKey] = count
+		}
+	}
+	return flattened
+}
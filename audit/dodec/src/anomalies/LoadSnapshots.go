@@ -0,0 +1,40 @@
+package anomalies
+
+import (
+	"dodec/types"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LoadSnapshots reads every snapshot saved for an aggregation with SaveSnapshot, sorted oldest first. It returns an
+// empty slice if no snapshots exist yet. If snapshotDir is empty, it uses DefaultSnapshotDir.
+func LoadSnapshots(snapshotDir string, name string) ([]types.Snapshot, error) {
+	if snapshotDir == "" {
+		snapshotDir = DefaultSnapshotDir
+	}
+	filePaths, err := filepath.Glob(filepath.Join(snapshotDir, name, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	snapshots := make([]types.Snapshot, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot %s: %w", filePath, err)
+		}
+		var snapshot types.Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot %s: %w", filePath, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].TakenAt.Before(snapshots[j].TakenAt)
+	})
+	return snapshots, nil
+}
@@ -0,0 +1,39 @@
+package anomalies
+
+import (
+	"dodec/types"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SaveSnapshot writes the counts for an aggregation to `<snapshotDir>/<name>/<timestamp>.json`, and returns the path
+// of the file it wrote. Save a snapshot after each aggregation run to build up the history that DetectAnomalies compares
+// against. If snapshotDir is empty, it uses DefaultSnapshotDir.
+func SaveSnapshot(snapshotDir string, name string, counts map[string]int) (string, error) {
+	if snapshotDir == "" {
+		snapshotDir = DefaultSnapshotDir
+	}
+	dir := filepath.Join(snapshotDir, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory %s: %w", dir, err)
+	}
+
+	snapshot := types.Snapshot{
+		Name:    name,
+		TakenAt: time.Now().UTC(),
+		Counts:  counts,
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	filePath := filepath.Join(dir, snapshot.TakenAt.Format("20060102-150405")+".json")
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write snapshot %s: %w", filePath, err)
+	}
+	return filePath, nil
+}
@@ -0,0 +1,145 @@
+package exports
+
+import (
+	"dodec/types"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// BuildProductLanguageSheet pivots a `nestedOneLevelMap` keyed by product, then programming language - as returned by
+// aggregations.GetProductLanguageCounts - into a "By Product" sheet. Rows are sorted by product name, then by count
+// descending within each product, and include the language's share of the product's total.
+func BuildProductLanguageSheet(productLanguageMap map[string]map[string]int) types.Sheet {
+	sheet := types.Sheet{
+		Name:         "By Product",
+		Columns:      []string{"Product", "Language", "Count", "% of Product"},
+		ColumnWidths: []float64{30, 20, 12, 14},
+	}
+
+	var products []string
+	for product := range productLanguageMap {
+		products = append(products, product)
+	}
+	sort.Strings(products)
+
+	for _, product := range products {
+		languageCounts := productLanguageMap[product]
+		productTotal := 0
+		for _, count := range languageCounts {
+			productTotal += count
+		}
+		for _, item := range sortedKeyCounts(languageCounts) {
+			sheet.Rows = append(sheet.Rows, []interface{}{product, item.Key, item.Count, percentOf(item.Count, productTotal)})
+		}
+	}
+	return sheet
+}
+
+// BuildLanguageSheet turns a `simpleMap` keyed by programming language - as returned by aggregations.GetLanguageCounts -
+// into a "By Language" sheet sorted by count descending. The `types.Total` key, if present, is used as the denominator
+// for the percentage column and is not written as its own row.
+func BuildLanguageSheet(languageCountMap map[string]int) types.Sheet {
+	sheet := types.Sheet{
+		Name:         "By Language",
+		Columns:      []string{"Language", "Count", "% of Total"},
+		ColumnWidths: []float64{20, 12, 12},
+	}
+
+	counts := make(map[string]int, len(languageCountMap))
+	for language, count := range languageCountMap {
+		if language != types.Total {
+			counts[language] = count
+		}
+	}
+	total, hasTotal := languageCountMap[types.Total]
+	if !hasTotal {
+		for _, count := range counts {
+			total += count
+		}
+	}
+
+	for _, item := range sortedKeyCounts(counts) {
+		sheet.Rows = append(sheet.Rows, []interface{}{item.Key, item.Count, percentOf(item.Count, total)})
+	}
+	return sheet
+}
+
+// BuildMonthlyUsageExampleSheet pivots the counter populated by aggregations.FindUsageExamplesForMonth into a "By Month"
+// sheet with one row per product and sub-product. As with utils.PrintMonthlyUsageExampleCounts, product counts that
+// aren't attributed to a sub-product are reported under a "None" sub-product.
+func BuildMonthlyUsageExampleSheet(productSubProductCounter types.NewAppliedUsageExampleCounterByProductSubProduct, monthForReporting time.Month) types.Sheet {
+	sheet := types.Sheet{
+		Name:         "By Month",
+		Columns:      []string{"Month", "Product", "Product Count", "Sub-product", "Sub-product Count"},
+		ColumnWidths: []float64{12, 30, 15, 25, 18},
+	}
+
+	var products []string
+	for product := range productSubProductCounter.ProductAggregateCount {
+		products = append(products, product)
+	}
+	sort.Strings(products)
+
+	month := monthForReporting.String()
+	for _, product := range products {
+		aggregateCount := productSubProductCounter.ProductAggregateCount[product]
+		subProductCounts := productSubProductCounter.ProductSubProductCounts[product]
+
+		var subProducts []string
+		subProductSum := 0
+		for subProduct, count := range subProductCounts {
+			subProducts = append(subProducts, subProduct)
+			subProductSum += count
+		}
+		sort.Strings(subProducts)
+
+		for _, subProduct := range subProducts {
+			sheet.Rows = append(sheet.Rows, []interface{}{month, product, aggregateCount, subProduct, subProductCounts[subProduct]})
+		}
+		if subProductSum < aggregateCount {
+			sheet.Rows = append(sheet.Rows, []interface{}{month, product, aggregateCount, "None", aggregateCount - subProductSum})
+		}
+	}
+	return sheet
+}
+
+// BuildAnomalySheet turns the anomalies returned by anomalies.DetectAnomalies into an "Anomalies" sheet, so counts that
+// are unusually far from their historical values are flagged in the report next to the numbers they affect.
+func BuildAnomalySheet(anomalies []types.Anomaly) types.Sheet {
+	sheet := types.Sheet{
+		Name:         "Anomalies",
+		Columns:      []string{"Key", "Current", "Historical Mean", "Std Dev", "Z-Score", "Snapshots"},
+		ColumnWidths: []float64{40, 12, 16, 12, 12, 12},
+	}
+	for _, anomaly := range anomalies {
+		sheet.Rows = append(sheet.Rows, []interface{}{anomaly.Key, anomaly.Current, anomaly.Mean, anomaly.StdDev, anomaly.ZScore, anomaly.HistoryPoints})
+	}
+	return sheet
+}
+
+// DefaultReportFileName returns a dated file name for an exported report, i.e. `code-example-report-2025-11-03.xlsx`
+func DefaultReportFileName(extension string) string {
+	return fmt.Sprintf("code-example-report-%s.%s", time.Now().Format("2006-01-02"), extension)
+}
+
+func sortedKeyCounts(countMap map[string]int) []types.KeyCount {
+	var keyCounts []types.KeyCount
+	for key, count := range countMap {
+		keyCounts = append(keyCounts, types.KeyCount{Key: key, Count: count})
+	}
+	sort.Slice(keyCounts, func(i, j int) bool {
+		if keyCounts[i].Count == keyCounts[j].Count {
+			return keyCounts[i].Key < keyCounts[j].Key
+		}
+		return keyCounts[i].Count > keyCounts[j].Count
+	})
+	return keyCounts
+}
+
+func percentOf(count int, total int) types.Percent {
+	if total == 0 {
+		return 0
+	}
+	return types.Percent(float64(count) / float64(total))
+}
@@ -0,0 +1,68 @@
+package exports
+
+import (
+	"dodec/types"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteSheetsToCSV writes each sheet to its own CSV file in outputDir, named after the sheet, i.e. the "By Product"
+// sheet becomes `by-product.csv`. It returns the paths of the files it wrote. Use this when a spreadsheet tool other
+// than Excel needs the data, or to diff report output between runs.
+func WriteSheetsToCSV(outputDir string, sheets []types.Sheet) ([]string, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+	}
+
+	var paths []string
+	for _, sheet := range sheets {
+		filePath := filepath.Join(outputDir, csvFileName(sheet.Name))
+		if err := writeSheetToCSV(filePath, sheet); err != nil {
+			return paths, err
+		}
+		paths = append(paths, filePath)
+	}
+	return paths, nil
+}
+
+func writeSheetToCSV(filePath string, sheet types.Sheet) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(sheet.Columns); err != nil {
+		return fmt.Errorf("failed to write header to %s: %w", filePath, err)
+	}
+	for _, row := range sheet.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			if percent, ok := value.(types.Percent); ok {
+				record[i] = fmt.Sprintf("%.1f%%", float64(percent)*100)
+			} else {
+				record[i] = fmt.Sprint(value)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write row to %s: %w", filePath, err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvFileName(sheetName string) string {
+	name := strings.ToLower(strings.TrimSpace(sheetName))
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, name)
+	return name + ".csv"
+}
@@ -0,0 +1,206 @@
+package exports
+
+import (
+	"archive/zip"
+	"dodec/types"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Style indexes into the cellXfs list in xlsxStyles
+const (
+	styleDefault = 0
+	styleHeader  = 1
+	stylePercent = 2
+)
+
+const xlsxContentTypesHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+// xlsxStyles defines a default style, a bold header style with a gray fill and bottom border, and a percent style.
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="1"><numFmt numFmtId="164" formatCode="0.0%"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill><fill><patternFill patternType="solid"><fgColor rgb="FFD9D9D9"/><bgColor indexed="64"/></patternFill></fill></fills>
+<borders count="2"><border><left/><right/><top/><bottom/><diagonal/></border><border><left/><right/><top/><bottom style="thin"><color auto="1"/></bottom><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="3">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="0" fontId="1" fillId="2" borderId="1" xfId="0" applyFont="1" applyFill="1" applyBorder="1"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+</cellXfs>
+</styleSheet>`
+
+// WriteSheetsToXLSX writes the sheets to a single Excel workbook at filePath, with one tab per sheet. Each tab has a bold
+// header row that stays frozen while scrolling, an autofilter across the header, and percentages formatted as such, so
+// the workbook can be shared without any manual formatting.
+func WriteSheetsToXLSX(filePath string, sheets []types.Sheet) error {
+	if len(sheets) == 0 {
+		return fmt.Errorf("no sheets to write to %s", filePath)
+	}
+	for _, sheet := range sheets {
+		if err := validateSheet(sheet); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	zipWriter := zip.NewWriter(file)
+
+	parts := map[string]string{
+		"[Content_Types].xml":        buildContentTypes(len(sheets)),
+		"_rels/.rels":                xlsxRootRels,
+		"xl/workbook.xml":            buildWorkbook(sheets),
+		"xl/_rels/workbook.xml.rels": buildWorkbookRels(len(sheets)),
+		"xl/styles.xml":              xlsxStyles,
+	}
+	for i, sheet := range sheets {
+		parts[fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1)] = buildWorksheet(sheet)
+	}
+
+	for name, content := range parts {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to workbook: %w", name, err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			return fmt.Errorf("failed to write %s to workbook: %w", name, err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finalize workbook %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// validateSheet checks that the sheet can be written. Excel limits tab names to 31 characters and disallows some symbols.
+func validateSheet(sheet types.Sheet) error {
+	if sheet.Name == "" || len(sheet.Name) > 31 || strings.ContainsAny(sheet.Name, `[]:*?/\`) {
+		return fmt.Errorf("invalid sheet name %q: must be 1-31 characters and not contain []:*?/\\", sheet.Name)
+	}
+	if len(sheet.ColumnWidths) > 0 && len(sheet.ColumnWidths) != len(sheet.Columns) {
+		return fmt.Errorf("sheet %s has %d columns but %d column widths", sheet.Name, len(sheet.Columns), len(sheet.ColumnWidths))
+	}
+	return nil
+}
+
+func buildContentTypes(sheetCount int) string {
+	var sb strings.Builder
+	sb.WriteString(xlsxContentTypesHeader)
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&sb, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`+"\n", i)
+	}
+	sb.WriteString(`</Types>`)
+	return sb.String()
+}
+
+func buildWorkbook(sheets []types.Sheet) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sb.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range sheets {
+		fmt.Fprintf(&sb, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(sheet.Name), i+1, i+1)
+	}
+	sb.WriteString(`</sheets></workbook>`)
+	return sb.String()
+}
+
+func buildWorkbookRels(sheetCount int) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sb.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	// The styles relationship ID must not collide with the worksheet IDs
+	fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheetCount+1)
+	sb.WriteString(`</Relationships>`)
+	return sb.String()
+}
+
+func buildWorksheet(sheet types.Sheet) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+
+	// Freeze the header row
+	sb.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+
+	if len(sheet.ColumnWidths) > 0 {
+		sb.WriteString(`<cols>`)
+		for i, width := range sheet.ColumnWidths {
+			fmt.Fprintf(&sb, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, i+1, i+1, width)
+		}
+		sb.WriteString(`</cols>`)
+	}
+
+	sb.WriteString(`<sheetData>`)
+	header := make([]interface{}, len(sheet.Columns))
+	for i, column := range sheet.Columns {
+		header[i] = column
+	}
+	writeRow(&sb, 1, header, styleHeader)
+	for i, row := range sheet.Rows {
+		writeRow(&sb, i+2, row, styleDefault)
+	}
+	sb.WriteString(`</sheetData>`)
+
+	if len(sheet.Columns) > 0 {
+		fmt.Fprintf(&sb, `<autoFilter ref="A1:%s%d"/>`, columnLetter(len(sheet.Columns)-1), len(sheet.Rows)+1)
+	}
+	sb.WriteString(`</worksheet>`)
+	return sb.String()
+}
+
+func writeRow(sb *strings.Builder, rowNumber int, values []interface{}, style int) {
+	fmt.Fprintf(sb, `<row r="%d">`, rowNumber)
+	for i, value := range values {
+		ref := fmt.Sprintf("%s%d", columnLetter(i), rowNumber)
+		switch v := value.(type) {
+		case types.Percent:
+			fmt.Fprintf(sb, `<c r="%s" s="%d"><v>%g</v></c>`, ref, stylePercent, float64(v))
+		case int:
+			fmt.Fprintf(sb, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, v)
+		case float64:
+			fmt.Fprintf(sb, `<c r="%s" s="%d"><v>%g</v></c>`, ref, style, v)
+		default:
+			fmt.Fprintf(sb, `<c r="%s" s="%d" t="inlineStr"><is><t>%s</t></is></c>`, ref, style, escapeXML(fmt.Sprint(v)))
+		}
+	}
+	sb.WriteString(`</row>`)
+}
+
+// columnLetter converts a zero-based column index to its spreadsheet letter, i.e. 0 -> A, 26 -> AA
+func columnLetter(index int) string {
+	letters := ""
+	for index >= 0 {
+		letters = string(rune('A'+index%26)) + letters
+		index = index/26 - 1
+	}
+	return letters
+}
+
+func escapeXML(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"dodec/updates"
+	"flag"
 	"log"
 	"os"
 
@@ -11,6 +13,9 @@ import (
 )
 
 func main() {
+	readOnly := flag.Bool("read-only", false, "Refuse to run update helpers, and verify the connection's user can't write to DB_NAME")
+	flag.Parse()
+
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found")
 	}
@@ -38,8 +43,16 @@ func main() {
 		log.Fatal("Set your 'DB_NAME' environment variable. ")
 	}
 
+	// In read-only mode, refuse to run update helpers and to connect as a user that can write to the DB
+	if *readOnly {
+		updates.SetReadOnly(true)
+		if err := VerifyReadOnlyUser(client, dbName, ctx); err != nil {
+			log.Fatalf("Read-only mode: %v", err)
+		}
+	}
+
 	/* To copy the DB for testing, uncomment the following line.
-	 * Optionally, comment out lines 46-47 below to skip performing an aggregation after copying the DB.
+	 * Optionally, comment out the PerformAggregation call below to skip performing an aggregation after copying the DB.
 	 * NOTE: Update the DB name in the CopyDBForTesting func.
 	 */
 	//updates.CopyDBForTesting(client, ctx)
@@ -0,0 +1,12 @@
+package types
+
+// Anomaly describes a key whose current count is unusually far from its historical counts. ZScore is the number of
+// standard deviations the current count is from the historical mean; positive means the count went up.
+type Anomaly struct {
+	Key           string
+	Current       int
+	Mean          float64
+	StdDev        float64
+	ZScore        float64
+	HistoryPoints int
+}
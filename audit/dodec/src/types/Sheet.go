@@ -0,0 +1,13 @@
+package types
+
+// Sheet holds tabular data for one spreadsheet tab or CSV file. Rows contain string, int, float64, or Percent values.
+// ColumnWidths is optional; when set, it must have the same length as Columns.
+type Sheet struct {
+	Name         string
+	Columns      []string
+	ColumnWidths []float64
+	Rows         [][]interface{}
+}
+
+// Percent is a fraction between 0 and 1 that exporters format as a percentage, i.e. 0.25 renders as 25.0%.
+type Percent float64
@@ -0,0 +1,11 @@
+package types
+
+import "time"
+
+// Snapshot holds the output of one aggregation run, flattened to a map of key to count, so later runs can compare
+// against it. Name identifies the aggregation, i.e. "product-language-counts".
+type Snapshot struct {
+	Name    string         `json:"name"`
+	TakenAt time.Time      `json:"taken_at"`
+	Counts  map[string]int `json:"counts"`
+}
@@ -14,6 +14,7 @@ import (
 // that correspond to the Docs Taxonomy. The mappings are defined in the `common` module. If the document in the
 // collection already has the applicable field(s), no change is made.
 func AddProductNames(db *mongo.Database, ctx context.Context) {
+	refuseIfReadOnly("AddProductNames")
 	emptyFilter := bson.D{}
 	collectionNames, err := db.ListCollectionNames(ctx, emptyFilter)
 
@@ -11,6 +11,7 @@ import (
 // ChangeProductName sets the `product` field value to a new value that you specify for all documents in the given collection.
 // Run this function to populate any changes made to the mapped product names in `GetProductSubProduct` in GDCD.
 func ChangeProductName(db *mongo.Database, ctx context.Context) {
+	refuseIfReadOnly("ChangeProductName")
 
 	// ===== CONFIGURATION: Set these values before running =====
 	collection := db.Collection("atlas-architecture") // collection to update (this should match the project name in GDCD)
@@ -11,6 +11,7 @@ import (
 // ChangeProjectName sets the `project_name` field value to a new value that you specify for all documents in the given
 // collection. Then, it renames the collection. This should match the project name defined in `common`
 func ChangeProjectName(client *mongo.Client, dbName string, ctx context.Context) {
+	refuseIfReadOnly("ChangeProjectName")
 
 	// ===== CONFIGURATION: Set these values before running =====
 	oldProjectName := "cluster-sync" // Existing collection to update (this should match the old project name in `common`)
@@ -9,6 +9,7 @@ import (
 )
 
 func CopyDBForTesting(client *mongo.Client, ctx context.Context) {
+	refuseIfReadOnly("CopyDBForTesting")
 	sourceDb := client.Database("code_metrics")
 	// TODO: Update this name to the current date to distinguish from other backups
 	targetDb := client.Database("backup_code_metrics_April_30")
@@ -0,0 +1,18 @@
+package updates
+
+import "log"
+
+// readOnly is set when dodec runs with `--read-only`, so the update helpers refuse to run.
+var readOnly bool
+
+// SetReadOnly turns read-only mode on or off for the update helpers.
+func SetReadOnly(enabled bool) {
+	readOnly = enabled
+}
+
+// refuseIfReadOnly exits before an update helper writes anything when dodec runs with `--read-only`.
+func refuseIfReadOnly(helper string) {
+	if readOnly {
+		log.Fatalf("%s modifies the database and can't run with --read-only", helper)
+	}
+}
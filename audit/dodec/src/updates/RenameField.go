@@ -11,6 +11,7 @@ import (
 
 // RenameField changes a field name from oldFieldName to newFieldName for every document in all the collections.
 func RenameField(db *mongo.Database, ctx context.Context) {
+	refuseIfReadOnly("RenameField")
 	// List collection names
 	collections, err := db.ListCollectionNames(ctx, bson.D{})
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 // RenameValue looks for any document where a field whose name and old value match the filter you define, and sets the
 // field's value to the new value you define.
 func RenameValue(db *mongo.Database, ctx context.Context) {
+	refuseIfReadOnly("RenameValue")
 	// List collection names
 	collections, err := db.ListCollectionNames(ctx, bson.D{})
 	if err != nil {
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"dodec/types"
+	"fmt"
+)
+
+// PrintAnomaliesToConsole prints a table of the anomalies returned by anomalies.DetectAnomalies for an aggregation,
+// with the current count, the historical mean and standard deviation, and the z-score for each key. If there are no
+// anomalies, it prints a single line saying so.
+func PrintAnomaliesToConsole(anomalies []types.Anomaly, tableLabel string) {
+	if len(anomalies) == 0 {
+		fmt.Printf("\nNo anomalies found in %s\n", tableLabel)
+		return
+	}
+	fmt.Printf("\n%s Anomalies\n", tableLabel)
+	fmt.Printf("%d counts are unusually far from their historical values. Check for upstream parser or ingest issues before reporting these numbers.\n", len(anomalies))
+	columnNames := []interface{}{"Key", "Current", "Historical Mean", "Std Dev", "Z-Score", "Direction"}
+	columnWidths := []int{40, 10, 16, 10, 10, 10}
+	printSeparator(columnWidths...)
+	printRow(columnWidths, columnNames...)
+	printSeparator(columnWidths...)
+	for _, anomaly := range anomalies {
+		direction := "up"
+		if anomaly.ZScore < 0 {
+			direction = "down"
+		}
+		printRow(columnWidths, anomaly.Key, anomaly.Current, fmt.Sprintf("%.1f", anomaly.Mean), fmt.Sprintf("%.1f", anomaly.StdDev), fmt.Sprintf("%.1f", anomaly.ZScore), direction)
+	}
+	printSeparator(columnWidths...)
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"gdcd/db"
+	"gdcd/types"
+)
+
+// AddProjectToRunReport snapshots the project's current pages and language counts from Atlas and adds them to the run
+// report, along with the issues, counts, and timing from the project report. Call it after the project's changes are
+// written.
+func AddProjectToRunReport(runReport types.RunReport, projectName string, report types.ProjectReport) types.RunReport {
+	snapshot := db.GetProjectSnapshot(projectName)
+	for _, issue := range report.Issues {
+		snapshot.Issues = append(snapshot.Issues, types.NewReportedIssue(issue))
+	}
+	snapshot.Counter = report.Counter
+	snapshot.Timing = report.Timing
+	runReport.Projects[projectName] = snapshot
+	return runReport
+}
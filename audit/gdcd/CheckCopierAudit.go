@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"gdcd/db"
+	"gdcd/types"
+	"gdcd/utils"
+	"log"
+	"sort"
+)
+
+// CheckCopierAudit cross-checks the example files each project's pages literalinclude against the examples-copier's
+// audit records, and adds an issue to the project's snapshot for each page that includes a file the copier has
+// marked deprecated, removed, or never synced. It does nothing unless COPIER_AUDIT_DATABASE is set. Call it once
+// every project's changes are written, so it checks the pages as they are after the run.
+func CheckCopierAudit(runReport types.RunReport, config types.CopierAuditConfig) types.RunReport {
+	if !config.Enabled() {
+		return runReport
+	}
+	records, err := db.GetCopierFileRecords(config)
+	if err != nil {
+		log.Printf("Skipping the examples-copier cross-check: %v\n", err)
+		return runReport
+	}
+	statuses := utils.LatestCopierFileStatuses(records)
+	log.Printf("Examples-copier audit records cover %d files\n", len(statuses))
+
+	projectNames := make([]string, 0, len(runReport.Projects))
+	for projectName := range runReport.Projects {
+		projectNames = append(projectNames, projectName)
+	}
+	sort.Strings(projectNames)
+
+	flagged := 0
+	for _, projectName := range projectNames {
+		issues := utils.CrossCheckCopierAudit(projectName, db.GetIncludedExamples(projectName), statuses)
+		if len(issues) == 0 {
+			continue
+		}
+		snapshot := runReport.Projects[projectName]
+		for _, issue := range issues {
+			log.Printf("Examples-copier cross-check for %s: %s\n", projectName, issue.Message)
+			snapshot.Issues = append(snapshot.Issues, types.NewReportedIssue(issue))
+		}
+		runReport.Projects[projectName] = snapshot
+		flagged += len(issues)
+	}
+	if flagged > 0 {
+		fmt.Printf("%d literalincludes reference example files the examples-copier deprecated or never synced - see the run report\n", flagged)
+	}
+	return runReport
+}
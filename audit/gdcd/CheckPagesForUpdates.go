@@ -16,8 +16,10 @@ import (
 // CheckPagesForUpdates takes the slice of incoming pages for a given project that we got from the Snooty Data API, plus
 // other things initialized in main() that are needed here. We iterate through the pages in the project, checking for
 // things that need to be added, removed, or updated. We compile a report for the project, which we're currently outputting
-// to a log file on the local file system. Then, we perform a batch update with all the changes for this project.
-func CheckPagesForUpdates(pages []types.PageWrapper, project types.ProjectDetails, llm *ollama.LLM, ctx context.Context, report types.ProjectReport) {
+// to a log file on the local file system. Then, we perform a batch update with all the changes for this project and
+// return the completed report. The report's Timing lists how long each stage took, and the slowPageCount slowest pages.
+func CheckPagesForUpdates(pages []types.PageWrapper, project types.ProjectDetails, llm *ollama.LLM, ctx context.Context, report types.ProjectReport, slowPageCount int) types.ProjectReport {
+	profiler := utils.NewPageProfiler()
 	incomingPageIdsMatchingExistingPages := make(map[string]bool)
 	incomingDeletedPageCount := 0
 
@@ -29,22 +31,33 @@ func CheckPagesForUpdates(pages []types.PageWrapper, project types.ProjectDetail
 	var movedPages []types.NewOrMovedPage
 	var updatedPages []common.DocsPage
 	for _, page := range pages {
+		pageId := utils.ConvertSnootyPageIdToAtlasPageId(page.Data.PageID)
+		profiler.Add(pageId, types.FetchStage, page.FetchDuration)
+		profiler.Add(pageId, types.ParseStage, page.ParseDuration)
+
 		// The Snooty Data API returns pages that may have been deleted. If the page is deleted, we want to check and see
 		// if it exists already in the DB, and delete it if it does. If we haven't already made an entry for it, we
 		// don't need to do anything else.
 		if page.Data.Deleted {
-			report = HandleDeletedIncomingPages(project.ProjectName, page, report)
+			ProfilePage(profiler, pageId, types.DBStage, func() {
+				report = HandleDeletedIncomingPages(project.ProjectName, page, report)
+			})
 			incomingDeletedPageCount++
 			utils.UpdateSecondaryTarget()
 		} else {
-			maybeExistingPage := CheckForExistingPage(project.ProjectName, page)
+			var maybeExistingPage *common.DocsPage
+			ProfilePage(profiler, pageId, types.DBStage, func() {
+				maybeExistingPage = CheckForExistingPage(project.ProjectName, page)
+			})
 			if maybeExistingPage != nil {
 				// If there is an existing document in Atlas, update the existing page
 				// If the code example counts are the same on the incoming page as they are on the existing page,
 				// we treat that as an unchanged page and it does not return an updated page - it returns nil
 				incomingPageIdsMatchingExistingPages[maybeExistingPage.ID] = true
 				var updatedPage *common.DocsPage
-				updatedPage, report = UpdateExistingPage(*maybeExistingPage, page, report, llm, ctx)
+				ProfilePage(profiler, pageId, types.OtherStage, func() {
+					updatedPage, report = UpdateExistingPage(*maybeExistingPage, page, report, llm, ctx)
+				})
 				if updatedPage != nil {
 					updatedPages = append(updatedPages, *updatedPage)
 				}
@@ -53,7 +66,10 @@ func CheckPagesForUpdates(pages []types.PageWrapper, project types.ProjectDetail
 				// If there is no existing document in Atlas that matches the page, we need to make a new page. BUT!
 				// It might actually be a new or moved page. So store it in a temp `maybeNewPages` slice so we can compare
 				// it against removed pages later and potentially call it a "moved" page, instead.
-				newOrMovedPage := getNewOrMovedPageDetails(page.Data)
+				var newOrMovedPage types.NewOrMovedPage
+				ProfilePage(profiler, pageId, types.OtherStage, func() {
+					newOrMovedPage = getNewOrMovedPageDetails(page.Data)
+				})
 				maybeNewPages = append(maybeNewPages, newOrMovedPage)
 			}
 		}
@@ -66,9 +82,12 @@ func CheckPagesForUpdates(pages []types.PageWrapper, project types.ProjectDetail
 	// If we have new pages, create the corresponding DocsPage and increment the project report for them
 	if newPages != nil {
 		for _, page := range newPages {
-			newPage := MakeNewPage(page.PageData, project.ProjectName, project.ProdUrl, llm, ctx)
+			var newPage common.DocsPage
+			ProfilePage(profiler, page.PageId, types.OtherStage, func() {
+				newPage = MakeNewPage(page.PageData, project.ProjectName, project.ProdUrl, llm, ctx)
+				report = UpdateProjectReportForNewPage(newPage, report)
+			})
 			newPageDBEntries = append(newPageDBEntries, newPage)
-			report = UpdateProjectReportForNewPage(newPage, report)
 			utils.UpdateSecondaryTarget()
 		}
 	}
@@ -77,7 +96,10 @@ func CheckPagesForUpdates(pages []types.PageWrapper, project types.ProjectDetail
 	if movedPages != nil {
 		for _, page := range movedPages {
 			var movedPage common.DocsPage
-			oldPage := db.GetAtlasPageData(project.ProjectName, page.OldPageId)
+			var oldPage *common.DocsPage
+			ProfilePage(profiler, page.NewPageId, types.DBStage, func() {
+				oldPage = db.GetAtlasPageData(project.ProjectName, page.OldPageId)
+			})
 
 			if oldPage != nil {
 				movedPage = *oldPage
@@ -86,19 +108,26 @@ func CheckPagesForUpdates(pages []types.PageWrapper, project types.ProjectDetail
 				movedPage.DateLastUpdated = time.Now()
 				movedPage.PageURL = newPageUrl
 			} else {
-				movedPage = MakeNewPage(page.PageData, project.ProjectName, project.ProdUrl, llm, ctx)
+				ProfilePage(profiler, page.NewPageId, types.OtherStage, func() {
+					movedPage = MakeNewPage(page.PageData, project.ProjectName, project.ProdUrl, llm, ctx)
+				})
 				movedPage.DateAdded = page.DateAdded
 			}
 
 			// Remove the old page from the DB
-			db.RemovePageFromAtlas(project.ProjectName, page.OldPageId)
+			ProfilePage(profiler, page.NewPageId, types.DBStage, func() {
+				db.RemovePageFromAtlas(project.ProjectName, page.OldPageId)
+			})
 
 			// Append the "moved" page to the `newPageDBEntries` array. Because the page ID doesn't match the old one,
 			// we write it to the DB as a new page. Because we just deleted the old page, it works out to the same count
 			// and provides the up-to-date data in the DB.
 			newPageDBEntries = append(newPageDBEntries, movedPage)
 
-			incomingAstCodeNodes, incomingAstLiteralIncludeNodes, incomingAstIoCodeBlockNodes := snooty.GetCodeExamplesFromIncomingData(page.PageData.AST)
+			var incomingAstCodeNodes, incomingAstLiteralIncludeNodes, incomingAstIoCodeBlockNodes []types.ASTNode
+			ProfilePage(profiler, page.NewPageId, types.OtherStage, func() {
+				incomingAstCodeNodes, incomingAstLiteralIncludeNodes, incomingAstIoCodeBlockNodes = snooty.GetCodeExamplesFromIncomingData(page.PageData.AST)
+			})
 			incomingAstCodeNodeCount := len(incomingAstCodeNodes)
 			incomingAstLiteralIncludeNodesCount := len(incomingAstLiteralIncludeNodes)
 			incomingAstIoCodeBlockNodesCount := len(incomingAstIoCodeBlockNodes)
@@ -122,11 +151,22 @@ func CheckPagesForUpdates(pages []types.PageWrapper, project types.ProjectDetail
 	report.Counter.TotalCurrentPageCount = report.Counter.TotalCurrentPageCount - incomingDeletedPageCount
 	summaryDoc, report = HandleCollectionSummariesDocument(project, report)
 
-	// Output the project report to the log
+	// Add the pages we couldn't parse and the snippets the LLM failed to categorize, then output the project report to the log
+	report = reportErrorIssues(report)
 	LogReportForProject(project.ProjectName, report)
 
 	// At this point, we have all the new and updated pages and an updated summary. Write updates to Atlas.
+	writeStart := time.Now()
 	db.BatchUpdateCollection(project.ProjectName, newPageDBEntries, updatedPages, summaryDoc)
+	var writtenPageIds []string
+	for _, page := range append(newPageDBEntries, updatedPages...) {
+		writtenPageIds = append(writtenPageIds, page.ID)
+	}
+	profiler.Spread(writtenPageIds, types.DBStage, time.Since(writeStart))
+
+	report.Timing = profiler.Summary(slowPageCount)
+	LogTimingForProject(project.ProjectName, report.Timing)
+	return report
 }
 
 func getNewOrMovedPageDetails(metadata types.PageMetadata) types.NewOrMovedPage {
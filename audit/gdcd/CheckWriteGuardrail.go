@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"gdcd/db"
+	"gdcd/types"
+	"gdcd/utils"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// CheckWriteGuardrail compares each collection's page count with the pre-run backup. If any changed by more than the
+// configured threshold, the change has to be confirmed - at the prompt, or with GDCD_CONFIRM_LARGE_DELTA - or the
+// collections over the threshold are restored from the backup. The result is added to the run report, and the
+// snapshots of restored projects are retaken. Call it once every project's changes are written.
+func CheckWriteGuardrail(runReport types.RunReport, backupDbName string, config types.WriteGuardrailConfig) types.RunReport {
+	result := &types.WriteGuardrailResult{
+		BackupDB:        backupDbName,
+		MaxDeltaPercent: config.MaxDeltaPercent,
+		Outcome:         types.GuardrailPassed,
+	}
+	runReport.WriteGuardrail = result
+	if config.MaxDeltaPercent <= 0 {
+		return runReport
+	}
+
+	deltas := utils.CollectionDeltas(db.CountPagesPerCollection(backupDbName), db.CountPagesPerCollection(os.Getenv("DB_NAME")))
+	result.Exceeded = utils.DeltasOverThreshold(deltas, config.MaxDeltaPercent)
+	if len(result.Exceeded) == 0 {
+		log.Printf("Write guardrail passed: no collection's page count changed by more than %.1f%%\n", config.MaxDeltaPercent)
+		return runReport
+	}
+
+	fmt.Printf("\nThese collections' page counts changed by more than %.1f%% since backup %s:\n", config.MaxDeltaPercent, backupDbName)
+	for _, delta := range result.Exceeded {
+		line := fmt.Sprintf("  %s: %d -> %d pages (%+.1f%%)", delta.Collection, delta.Before, delta.After, delta.PercentChange())
+		fmt.Println(line)
+		log.Printf("Write guardrail:%s\n", line)
+	}
+
+	switch {
+	case config.Confirmed:
+		log.Println("Write guardrail: keeping the changes, confirmed by GDCD_CONFIRM_LARGE_DELTA")
+		result.Outcome = types.GuardrailConfirmed
+	case isInteractive(os.Stdin) && confirmKeep(os.Stdin, os.Stdout):
+		log.Println("Write guardrail: keeping the changes, confirmed at the prompt")
+		result.Outcome = types.GuardrailConfirmed
+	default:
+		var collections []string
+		for _, delta := range result.Exceeded {
+			collections = append(collections, delta.Collection)
+		}
+		fmt.Printf("Restoring %d collections from backup %s\n", len(collections), backupDbName)
+		db.RestoreCollectionsFromBackup(backupDbName, collections)
+		result.Outcome = types.GuardrailRestored
+
+		// The project snapshots were taken before the restore, so retake them, keeping the run's issues and counts
+		for _, collection := range collections {
+			if snapshot, ok := runReport.Projects[collection]; ok {
+				restored := db.GetProjectSnapshot(collection)
+				snapshot.PageIDs = restored.PageIDs
+				snapshot.CodeExampleCount = restored.CodeExampleCount
+				snapshot.LanguageCounts = restored.LanguageCounts
+				runReport.Projects[collection] = snapshot
+			}
+		}
+	}
+	return runReport
+}
+
+// confirmKeep asks whether to keep the changes, and returns true only if the answer is "keep"
+func confirmKeep(in io.Reader, out io.Writer) bool {
+	fmt.Fprint(out, "Type 'keep' to keep these changes, or anything else to restore these collections from the backup: ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	return strings.TrimSpace(strings.ToLower(answer)) == "keep"
+}
+
+// isInteractive returns true if file is a terminal someone can answer a prompt at
+func isInteractive(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfirmKeep(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   bool
+	}{
+		{"keep\n", true},
+		{"  KEEP \n", true},
+		{"keep", true},
+		{"yes\n", false},
+		{"\n", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		if got := confirmKeep(strings.NewReader(test.answer), &out); got != test.want {
+			t.Errorf("FAILED: confirmKeep(%q) = %v, want %v", test.answer, got, test.want)
+		}
+		if !strings.Contains(out.String(), "Type 'keep'") {
+			t.Errorf("FAILED: want a prompt, got %q", out.String())
+		}
+	}
+}
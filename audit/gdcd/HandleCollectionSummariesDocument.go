@@ -11,9 +11,14 @@ func HandleCollectionSummariesDocument(project types.ProjectDetails, report type
 	summaryDoc := db.GetAtlasProjectSummaryData(project.ProjectName)
 	var latestCollectionInfo common.CollectionInfoView
 	collectionVersionKey := ""
-	// If we haven't audited this collection before, there will be no collection info document
-	if summaryDoc == nil {
-		return db.MakeSummariesDocument(project, report), report
+	// If we haven't audited this collection before, there will be no collection info document, or only one that
+	// backfill created to hold the collection's history
+	if summaryDoc == nil || len(summaryDoc.Version) == 0 {
+		newSummaryDoc := db.MakeSummariesDocument(project, report)
+		if summaryDoc != nil {
+			newSummaryDoc.History = summaryDoc.History
+		}
+		return db.RecordSummaryHistory(newSummaryDoc, project.Version), report
 	} else {
 		// If we have retrieved a summary doc from the DB, it may contain more than one version
 		elementIndex := 0
@@ -65,10 +70,10 @@ func HandleCollectionSummariesDocument(project types.ProjectDetails, report type
 	}
 	sumOfExpectedCodeNodes := report.Counter.UpdatedCodeNodesCount + report.Counter.UnchangedCodeNodesCount + report.Counter.NewCodeNodesCount
 	if sumOfExpectedCodeNodes != report.Counter.IncomingCodeNodesCount {
-		report = utils.ReportIssues(types.CodeNodeCountIssue, report, project.ProjectName, sumOfExpectedCodeNodes, report.Counter.IncomingCodeNodesCount)
+		report = utils.ReportIssues(types.ProjectCodeNodeCountIssue, report, project.ProjectName, sumOfExpectedCodeNodes, report.Counter.IncomingCodeNodesCount)
 	}
 	if latestCollectionInfo.TotalPageCount != report.Counter.TotalCurrentPageCount {
 		report = utils.ReportChanges(types.ProjectSummaryPageCountChange, report, project.ProjectName, latestCollectionInfo.TotalPageCount, report.Counter.TotalCurrentPageCount)
 	}
-	return *summaryDoc, report
+	return db.RecordSummaryHistory(*summaryDoc, project.Version), report
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"gdcd/types"
 	"log"
+	"time"
 )
 
 func LogReportForProject(projectName string, report types.ProjectReport) {
@@ -18,12 +19,32 @@ func LogReportForProject(projectName string, report types.ProjectReport) {
 	if len(report.Issues) > 0 {
 		log.Printf("\nIssues with data in project %s\n", projectName)
 		for _, issue := range report.Issues {
-			log.Printf("%s: %s", issue.Type.String(), issue.Data.(string))
+			log.Printf("%s (%s): %s", issue.Type.String(), issue.Type.Code(), issue.Message)
 		}
 	} else if len(report.Issues) == 0 {
 		log.Printf("No issues with data in project %s\n", projectName)
 	}
+	if report.Counter.ExcludedPagesCount > 0 {
+		log.Printf("Pages excluded by facet or template for %s: %d\n", projectName, report.Counter.ExcludedPagesCount)
+	}
 	if report.Counter.NewAppliedUsageExamplesCount > 0 {
 		log.Printf("New applied usage examples for %s: %d\n", projectName, report.Counter.NewAppliedUsageExamplesCount)
 	}
 }
+
+// LogTimingForProject logs how long the project's pages took to process, by stage, and its slowest pages
+func LogTimingForProject(projectName string, timing *types.ProjectTiming) {
+	if timing == nil || timing.Pages == 0 {
+		return
+	}
+	log.Printf("\nProcessed %d pages in project %s in %s (fetch %s, parse %s, LLM %s, DB %s, other %s)\n", timing.Pages, projectName,
+		formatMs(timing.TotalMs), formatMs(timing.FetchMs), formatMs(timing.ParseMs), formatMs(timing.LLMMs), formatMs(timing.DBMs), formatMs(timing.OtherMs))
+	for _, page := range timing.SlowestPages {
+		log.Printf("Slow page %s: %s (fetch %s, parse %s, LLM %s, DB %s, other %s)", page.PageID,
+			formatMs(page.TotalMs), formatMs(page.FetchMs), formatMs(page.ParseMs), formatMs(page.LLMMs), formatMs(page.DBMs), formatMs(page.OtherMs))
+	}
+}
+
+func formatMs(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
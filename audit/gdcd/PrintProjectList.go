@@ -0,0 +1,24 @@
+package main
+
+import (
+	"common"
+	"fmt"
+	"gdcd/types"
+	"os"
+	"text/tabwriter"
+)
+
+// PrintProjectList prints the projects a run would process, with the version and product family used for each
+func PrintProjectList(projects []types.ProjectDetails) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "PROJECT\tVERSION\tPRODUCT\tURL")
+	for _, project := range projects {
+		product := common.GetProductInfo(project.ProjectName).ProductName
+		if product == "" {
+			product = "-"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", project.ProjectName, project.Version, product, project.ProdUrl)
+	}
+	writer.Flush()
+	fmt.Printf("\n%d projects would be processed\n", len(projects))
+}
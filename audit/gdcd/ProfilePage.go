@@ -0,0 +1,30 @@
+package main
+
+import (
+	add_code_examples "gdcd/add-code-examples"
+	"gdcd/snooty"
+	"gdcd/types"
+	"gdcd/utils"
+	"time"
+)
+
+// DefaultSlowPageCount is how many of each project's slowest pages are listed in the run report
+const DefaultSlowPageCount = 10
+
+// ProfilePage runs fn for a page and adds how long it took to the profiler. Time fn spends walking page ASTs and
+// categorizing snippets with the LLM is added to those stages, and the rest to stage.
+func ProfilePage(profiler *utils.PageProfiler, pageID string, stage types.PageStage, fn func()) {
+	// Discard time recorded outside a profiled call so it isn't added to this page
+	snooty.TakeASTParseDuration()
+	add_code_examples.TakeLLMDuration()
+
+	start := time.Now()
+	fn()
+	elapsed := time.Since(start)
+
+	parse := snooty.TakeASTParseDuration()
+	llm := add_code_examples.TakeLLMDuration()
+	profiler.Add(pageID, types.ParseStage, parse)
+	profiler.Add(pageID, types.LLMStage, llm)
+	profiler.Add(pageID, stage, elapsed-parse-llm)
+}
@@ -0,0 +1,29 @@
+package add_code_examples
+
+import "common"
+
+// IsUncategorized reports whether a code node stored in Atlas still needs a category: it's current, and it has no
+// category or the LLM couldn't assign one.
+func IsUncategorized(node common.CodeNode) bool {
+	return !node.IsRemoved && (node.Category == "" || node.Category == Uncategorized)
+}
+
+// CategorizeStoredNodes assigns a category to each uncategorized code node in nodes, in place, using categorize, which
+// returns the category and whether the LLM assigned it. Nodes that are still uncategorized afterward are left as they
+// were. Returns how many nodes got a category.
+func CategorizeStoredNodes(nodes []common.CodeNode, categorize func(code string, lang string) (string, bool)) int {
+	categorized := 0
+	for i, node := range nodes {
+		if !IsUncategorized(node) {
+			continue
+		}
+		category, llmCategorized := categorize(node.Code, node.Language)
+		if category == "" || category == Uncategorized {
+			continue
+		}
+		nodes[i].Category = category
+		nodes[i].LLMCategorized = llmCategorized
+		categorized++
+	}
+	return categorized
+}
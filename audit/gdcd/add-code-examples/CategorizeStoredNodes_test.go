@@ -0,0 +1,47 @@
+package add_code_examples
+
+import (
+	"common"
+	"testing"
+)
+
+func TestCategorizeStoredNodes(t *testing.T) {
+	nodes := []common.CodeNode{
+		{Code: "db.movies.find()", Language: common.JavaScript},
+		{Code: "mongosh", Language: common.Shell, Category: Uncategorized, LLMCategorized: true},
+		{Code: "{ \"title\": 1 }", Language: common.JSON, Category: common.ExampleReturnObject},
+		{Code: "db.old.find()", Language: common.JavaScript, IsRemoved: true},
+		{Code: "???", Language: common.Text},
+	}
+
+	var seen []string
+	categorized := CategorizeStoredNodes(nodes, func(code string, lang string) (string, bool) {
+		seen = append(seen, code)
+		switch lang {
+		case common.JavaScript:
+			return common.UsageExample, true
+		case common.Shell:
+			return common.NonMongoCommand, false
+		}
+		return Uncategorized, true
+	})
+
+	if categorized != 2 {
+		t.Errorf("got %d categorized nodes, want 2", categorized)
+	}
+	if len(seen) != 3 {
+		t.Errorf("got %d nodes sent to categorize, want 3 (current uncategorized nodes only): %v", len(seen), seen)
+	}
+	if nodes[0].Category != common.UsageExample || !nodes[0].LLMCategorized {
+		t.Errorf("got %s (LLM %t) for the first node, want %s (LLM true)", nodes[0].Category, nodes[0].LLMCategorized, common.UsageExample)
+	}
+	if nodes[1].Category != common.NonMongoCommand || nodes[1].LLMCategorized {
+		t.Errorf("got %s (LLM %t) for the second node, want %s (LLM false)", nodes[1].Category, nodes[1].LLMCategorized, common.NonMongoCommand)
+	}
+	if nodes[3].Category != "" {
+		t.Errorf("removed nodes shouldn't be categorized, got %s", nodes[3].Category)
+	}
+	if nodes[4].Category != "" {
+		t.Errorf("nodes that are still uncategorized should be left as they were, got %s", nodes[4].Category)
+	}
+}
@@ -0,0 +1,54 @@
+package add_code_examples
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CategoryCacheStats reports how many snippets the LLM categorized and how many duplicates reused a cached result.
+type CategoryCacheStats struct {
+	LLMCalls  int
+	CacheHits int
+}
+
+/* categoryCache holds LLM categories for snippets we have already categorized during this run. The same example file
+ * often appears in multiple directories and projects, so we categorize each unique snippet once and fan the result out
+ * to its duplicates. The key includes the language category and project type because both change the LLM prompt.
+ */
+var categoryCache = struct {
+	sync.Mutex
+	categories map[string]string
+	stats      CategoryCacheStats
+}{categories: make(map[string]string)}
+
+func makeCategoryCacheKey(contents string, langCategory string, isDriverProject bool) string {
+	hash := sha256.Sum256([]byte(strings.TrimSpace(contents)))
+	return hex.EncodeToString(hash[:]) + "|" + langCategory + "|" + strconv.FormatBool(isDriverProject)
+}
+
+func getCachedCategory(key string) (string, bool) {
+	categoryCache.Lock()
+	defer categoryCache.Unlock()
+	category, ok := categoryCache.categories[key]
+	if ok {
+		categoryCache.stats.CacheHits++
+	}
+	return category, ok
+}
+
+func cacheCategory(key string, category string) {
+	categoryCache.Lock()
+	defer categoryCache.Unlock()
+	categoryCache.categories[key] = category
+	categoryCache.stats.LLMCalls++
+}
+
+// GetCategoryCacheStats returns the LLM call and cache hit counts for this run.
+func GetCategoryCacheStats() CategoryCacheStats {
+	categoryCache.Lock()
+	defer categoryCache.Unlock()
+	return categoryCache.stats
+}
@@ -0,0 +1,48 @@
+package add_code_examples
+
+import (
+	"common"
+	"context"
+	"testing"
+)
+
+func TestGetCategoryReusesCachedCategoryForDuplicateSnippets(t *testing.T) {
+	contents := "client.db(\"sample_mflix\").collection(\"movies\").find({ year: 1999 })"
+	lang := common.JavaScript
+
+	// Seed the cache as if the LLM had already categorized this snippet. GetCategory must not call the LLM - it is nil
+	// The language category for JavaScript is common.JavaScript
+	cacheCategory(makeCategoryCacheKey(contents, common.JavaScript, false), common.UsageExample)
+	before := GetCategoryCacheStats()
+
+	// Surrounding whitespace doesn't change the snippet, so the duplicate should still hit the cache
+	category, llmCategorized := GetCategory("\n  "+contents+"\n", lang, nil, context.Background(), false)
+	if category != common.UsageExample {
+		t.Errorf("got category %s, want %s", category, common.UsageExample)
+	}
+	if !llmCategorized {
+		t.Errorf("expected cached category to be reported as LLM categorized")
+	}
+
+	after := GetCategoryCacheStats()
+	if after.CacheHits != before.CacheHits+1 {
+		t.Errorf("got %d cache hits, want %d", after.CacheHits, before.CacheHits+1)
+	}
+	if after.LLMCalls != before.LLMCalls {
+		t.Errorf("got %d LLM calls, want %d", after.LLMCalls, before.LLMCalls)
+	}
+}
+
+func TestMakeCategoryCacheKeyDistinguishesLanguageCategoryAndProjectType(t *testing.T) {
+	contents := "db.movies.find()"
+	base := makeCategoryCacheKey(contents, common.Shell, false)
+	if base != makeCategoryCacheKey("  "+contents+"\n", common.Shell, false) {
+		t.Errorf("expected surrounding whitespace to be ignored")
+	}
+	if base == makeCategoryCacheKey(contents, common.Text, false) {
+		t.Errorf("expected different language categories to have different keys")
+	}
+	if base == makeCategoryCacheKey(contents, common.Shell, true) {
+		t.Errorf("expected driver and non-driver projects to have different keys")
+	}
+}
@@ -0,0 +1,128 @@
+package add_code_examples
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gdcd/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StringMatchModel is recorded as the model for snippets categorized by string matching instead of the LLM
+const StringMatchModel = "string-match"
+
+// driftPreviewLength is how much of a drifted snippet the drift report shows
+const driftPreviewLength = 200
+
+/* categoryHistory holds the category each unique snippet was assigned in earlier runs, keyed like the category cache,
+ * plus what this run assigned. When a snippet is categorized again and gets a different category - because the
+ * taxonomy, the prompts, or the model changed - the change is reported as drift instead of silently rewriting the
+ * dataset. The history is loaded at the start of a run and saved at the end.
+ */
+var categoryHistory = struct {
+	sync.Mutex
+	runID   string
+	entries map[string]types.CategoryHistoryEntry
+	drift   map[string]types.CategoryDrift
+}{entries: make(map[string]types.CategoryHistoryEntry), drift: make(map[string]types.CategoryDrift)}
+
+// LoadCategoryHistory reads the categories assigned in earlier runs from path. A missing file starts an empty history.
+func LoadCategoryHistory(path string, runID string) error {
+	entries := make(map[string]types.CategoryHistoryEntry)
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading category history %q: %w", path, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("parsing category history %q: %w", path, err)
+		}
+	}
+
+	categoryHistory.Lock()
+	defer categoryHistory.Unlock()
+	categoryHistory.runID = runID
+	categoryHistory.entries = entries
+	categoryHistory.drift = make(map[string]types.CategoryDrift)
+	return nil
+}
+
+// SaveCategoryHistory writes the history, including the categories assigned during this run, to path
+func SaveCategoryHistory(path string) error {
+	categoryHistory.Lock()
+	data, err := json.MarshalIndent(categoryHistory.entries, "", "  ")
+	categoryHistory.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshalling category history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating category history directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing category history %q: %w", path, err)
+	}
+	return nil
+}
+
+// GetCategoryDrift returns the snippets whose category changed since an earlier run, sorted by language and hash
+func GetCategoryDrift() []types.CategoryDrift {
+	categoryHistory.Lock()
+	defer categoryHistory.Unlock()
+	drift := make([]types.CategoryDrift, 0, len(categoryHistory.drift))
+	for _, d := range categoryHistory.drift {
+		drift = append(drift, d)
+	}
+	sort.Slice(drift, func(i, j int) bool {
+		if drift[i].Language != drift[j].Language {
+			return drift[i].Language < drift[j].Language
+		}
+		return drift[i].SHA256Hash < drift[j].SHA256Hash
+	})
+	return drift
+}
+
+// recordCategory records the category assigned to a snippet in the history, and records drift if an earlier run
+// assigned it a different category
+func recordCategory(key string, contents string, lang string, category string, llmCategorized bool) {
+	model := StringMatchModel
+	if llmCategorized {
+		model = MODEL
+	}
+
+	categoryHistory.Lock()
+	defer categoryHistory.Unlock()
+	previous, seen := categoryHistory.entries[key]
+	if seen && previous.Category != category && previous.RunID != categoryHistory.runID {
+		categoryHistory.drift[key] = types.CategoryDrift{
+			SHA256Hash:  strings.SplitN(key, "|", 2)[0],
+			Language:    lang,
+			OldCategory: previous.Category,
+			NewCategory: category,
+			OldModel:    previous.Model,
+			NewModel:    model,
+			OldRunID:    previous.RunID,
+			Preview:     snippetPreview(contents),
+		}
+	}
+	categoryHistory.entries[key] = types.CategoryHistoryEntry{
+		Category:       category,
+		Model:          model,
+		LLMCategorized: llmCategorized,
+		Language:       lang,
+		RunID:          categoryHistory.runID,
+		CategorizedAt:  time.Now(),
+	}
+}
+
+func snippetPreview(contents string) string {
+	preview := strings.TrimSpace(contents)
+	if len(preview) > driftPreviewLength {
+		preview = preview[:driftPreviewLength] + "..."
+	}
+	return preview
+}
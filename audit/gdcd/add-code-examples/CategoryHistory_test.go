@@ -0,0 +1,52 @@
+package add_code_examples
+
+import (
+	"common"
+	"path/filepath"
+	"testing"
+)
+
+func TestCategoryHistoryReportsDriftBetweenRuns(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "category-history.json")
+	key := makeCategoryCacheKey("db.movies.find()", common.Shell, false)
+	unchanged := makeCategoryCacheKey("db.movies.drop()", common.Shell, false)
+
+	if err := LoadCategoryHistory(historyFile, "run-1"); err != nil {
+		t.Fatalf("loading a missing history file: %v", err)
+	}
+	recordCategory(key, "db.movies.find()", common.Shell, common.UsageExample, true)
+	recordCategory(unchanged, "db.movies.drop()", common.Shell, common.NonMongoCommand, false)
+	if drift := GetCategoryDrift(); len(drift) != 0 {
+		t.Errorf("got %d drifted snippets on the first run, want 0", len(drift))
+	}
+	if err := SaveCategoryHistory(historyFile); err != nil {
+		t.Fatalf("saving history: %v", err)
+	}
+
+	if err := LoadCategoryHistory(historyFile, "run-2"); err != nil {
+		t.Fatalf("loading history: %v", err)
+	}
+	recordCategory(key, "db.movies.find()", common.Shell, common.SyntaxExample, false)
+	recordCategory(unchanged, "db.movies.drop()", common.Shell, common.NonMongoCommand, false)
+
+	drift := GetCategoryDrift()
+	if len(drift) != 1 {
+		t.Fatalf("got %d drifted snippets, want 1", len(drift))
+	}
+	got := drift[0]
+	if got.OldCategory != common.UsageExample || got.NewCategory != common.SyntaxExample {
+		t.Errorf("got drift %s -> %s, want %s -> %s", got.OldCategory, got.NewCategory, common.UsageExample, common.SyntaxExample)
+	}
+	if got.OldModel != MODEL || got.NewModel != StringMatchModel {
+		t.Errorf("got models %s -> %s, want %s -> %s", got.OldModel, got.NewModel, MODEL, StringMatchModel)
+	}
+	if got.OldRunID != "run-1" {
+		t.Errorf("got old run ID %s, want run-1", got.OldRunID)
+	}
+	if got.Preview != "db.movies.find()" {
+		t.Errorf("got preview %q", got.Preview)
+	}
+	if len(got.SHA256Hash) != 64 {
+		t.Errorf("expected the drift to be identified by the snippet hash, got %q", got.SHA256Hash)
+	}
+}
@@ -9,5 +9,6 @@ const (
 
 	// Other
 
-	MODEL = "qwen2.5-coder"
+	MODEL         = "qwen2.5-coder"
+	Uncategorized = "Uncategorized"
 )
@@ -5,6 +5,8 @@ import (
 	"context"
 	"gdcd/add-code-examples/utils"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/tmc/langchaingo/llms/ollama"
 )
@@ -21,23 +23,36 @@ func GetCategory(contents string, lang string, llm *ollama.LLM, ctx context.Cont
 	langCategory := utils.GetLanguageCategory(lang)
 	category, stringMatchSuccessful := utils.CheckForStringMatch(contents, langCategory)
 	llmCategorized := false
+	cacheKey := makeCategoryCacheKey(contents, langCategory, isDriverProject)
 	if stringMatchSuccessful {
 		/* The bool we are returning from this func represents whether the LLM categorized the snippet
 		 * If we have successfully used string matching to categorize the snippet, the LLM does not process it, so we
 		 * return false here
 		 */
+		recordCategory(cacheKey, contents, lang, category, llmCategorized)
 		return category, llmCategorized
 	} else {
-		category, err = LLMAssignCategory(contents, langCategory, llm, ctx, isDriverProject)
+		// If we have already categorized an identical snippet during this run, reuse its category instead of calling the LLM again
+		if cachedCategory, ok := getCachedCategory(cacheKey); ok {
+			return cachedCategory, true
+		}
+		llmCtx, cancel := withLLMTimeout(ctx)
+		start := time.Now()
+		category, err = LLMAssignCategory(contents, langCategory, llm, llmCtx, isDriverProject)
+		recordLLMDuration(time.Since(start))
+		cancel()
 		if err != nil {
+			// Don't cache errors - they may be transient, so a duplicate of this snippet should get another try
 			log.Printf("Error categorizing snippet with LLM: %v", err)
+			recordLLMFailure(lang, strings.SplitN(cacheKey, "|", 2)[0], err)
 			return "Uncategorized", true
 		}
-		if utils.SliceContainsString(validCategories, category) {
-			llmCategorized = true
-			return category, llmCategorized
-		} else {
-			return "Uncategorized", true
+		if !utils.SliceContainsString(validCategories, category) {
+			category = "Uncategorized"
 		}
+		cacheCategory(cacheKey, category)
+		llmCategorized = true
+		recordCategory(cacheKey, contents, lang, category, llmCategorized)
+		return category, llmCategorized
 	}
 }
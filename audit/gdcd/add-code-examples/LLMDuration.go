@@ -0,0 +1,28 @@
+package add_code_examples
+
+import (
+	"sync"
+	"time"
+)
+
+// llmDuration is the time spent in LLM categorization calls since TakeLLMDuration was last called, so the caller can
+// attribute it to the page whose snippets it was categorizing.
+var llmDuration = struct {
+	sync.Mutex
+	total time.Duration
+}{}
+
+func recordLLMDuration(duration time.Duration) {
+	llmDuration.Lock()
+	defer llmDuration.Unlock()
+	llmDuration.total += duration
+}
+
+// TakeLLMDuration returns the time spent in LLM categorization calls since the last call, and resets it.
+func TakeLLMDuration() time.Duration {
+	llmDuration.Lock()
+	defer llmDuration.Unlock()
+	taken := llmDuration.total
+	llmDuration.total = 0
+	return taken
+}
@@ -0,0 +1,75 @@
+package add_code_examples
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultLLMTimeout is how long a single categorization call can take before we give up on it.
+const DefaultLLMTimeout = 2 * time.Minute
+
+// LLMFailure is an LLM categorization call that failed. Timeout is true if the call ran out of time, so it can be
+// reported separately from other errors.
+type LLMFailure struct {
+	Language   string
+	SHA256Hash string
+	Timeout    bool
+	Err        error
+}
+
+// llmFailures holds the failed LLM calls since TakeLLMFailures was last called, so the caller can report them against
+// the project whose snippets it was categorizing.
+var llmFailures = struct {
+	sync.Mutex
+	timeout  time.Duration
+	failures []LLMFailure
+}{timeout: DefaultLLMTimeout}
+
+// SetLLMTimeout sets how long a single categorization call can take. A timeout of 0 or less disables it.
+func SetLLMTimeout(timeout time.Duration) {
+	llmFailures.Lock()
+	defer llmFailures.Unlock()
+	llmFailures.timeout = timeout
+}
+
+// withLLMTimeout returns a context for a single categorization call
+func withLLMTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	llmFailures.Lock()
+	timeout := llmFailures.timeout
+	llmFailures.Unlock()
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func recordLLMFailure(lang string, sha256Hash string, err error) {
+	llmFailures.Lock()
+	defer llmFailures.Unlock()
+	llmFailures.failures = append(llmFailures.failures, LLMFailure{
+		Language:   lang,
+		SHA256Hash: sha256Hash,
+		Timeout:    isTimeout(err),
+		Err:        err,
+	})
+}
+
+// TakeLLMFailures returns the LLM failures recorded since the last call, and clears them.
+func TakeLLMFailures() []LLMFailure {
+	llmFailures.Lock()
+	defer llmFailures.Unlock()
+	taken := llmFailures.failures
+	llmFailures.failures = nil
+	return taken
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,28 @@
+package add_code_examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestTakeLLMFailuresSeparatesTimeouts(t *testing.T) {
+	TakeLLMFailures()
+	recordLLMFailure("python", "abc123", fmt.Errorf("failed to categorize snippet: %w", context.DeadlineExceeded))
+	recordLLMFailure("go", "def456", errors.New("model not found"))
+
+	failures := TakeLLMFailures()
+	if len(failures) != 2 {
+		t.Fatalf("FAILED: got %d failures, want 2", len(failures))
+	}
+	if !failures[0].Timeout || failures[0].Language != "python" || failures[0].SHA256Hash != "abc123" {
+		t.Errorf("FAILED: got %+v, want a python timeout for abc123", failures[0])
+	}
+	if failures[1].Timeout {
+		t.Errorf("FAILED: got a timeout for %v, want an error", failures[1].Err)
+	}
+	if len(TakeLLMFailures()) != 0 {
+		t.Errorf("FAILED: failures weren't cleared")
+	}
+}
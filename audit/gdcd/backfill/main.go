@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"gdcd/db"
+	"gdcd/snooty"
+	"gdcd/types"
+	"gdcd/utils"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// backfillRunIDSuffix is appended to the snapshot date to make the run ID of a backfilled run report, so backfilled
+// reports sort by date alongside reports from runs and can't be confused with them
+const backfillRunIDSuffix = "-backfill"
+
+func main() {
+	logDir := flag.String("logs", "./logs", "Directory to write the backfilled run reports to")
+	before := flag.String("before", "", "Only backfill snapshots dated before this date (YYYY-MM-DD), such as the date of the first GDCD run")
+	writeDB := flag.Bool("db", false, "Also add each date's project totals to the history in the project's summaries document, using the APP_ENV .env file")
+	flag.Usage = func() {
+		fmt.Println("Usage: go run ./backfill [-logs <dir>] [-before <date>] [-db] <snapshot-archive>")
+		fmt.Println("The archive has a directory per date (YYYY-MM-DD) holding one Snooty Data API documents dump per project,")
+		fmt.Println("named <project>.json or <project>.ndjson. A run report is written for each date, and with -db, each")
+		fmt.Println("project's totals for the date are added to the summaries history that trend reports read.")
+		fmt.Println("Example: go run ./backfill -before 2025-03-03 -db ./snooty-archive")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	var cutoff time.Time
+	if *before != "" {
+		var err error
+		cutoff, err = time.Parse(snooty.ArchivedSnapshotDateLayout, *before)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -before date %q, expected YYYY-MM-DD\n", *before)
+			os.Exit(1)
+		}
+	}
+
+	if *writeDB {
+		loadEnv()
+	}
+
+	snapshots, err := snooty.FindArchivedSnapshots(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(*logDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: creating %q: %v\n", *logDir, err)
+		os.Exit(1)
+	}
+
+	reports := make(map[time.Time]*types.RunReport)
+	for _, snapshot := range snapshots {
+		if !cutoff.IsZero() && !snapshot.Date.Before(cutoff) {
+			continue
+		}
+		report, ok := reports[snapshot.Date]
+		if !ok {
+			report = &types.RunReport{
+				RunID:      snapshot.Date.Format(snooty.ArchivedSnapshotDateLayout) + backfillRunIDSuffix,
+				StartedAt:  snapshot.Date,
+				Projects:   make(map[string]types.ProjectSnapshot),
+				Backfilled: true,
+			}
+			reports[snapshot.Date] = report
+		}
+		pages, err := readArchivedPages(snapshot.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		report.Projects[snapshot.ProjectName] = snooty.MakeProjectSnapshotFromPages(pages)
+	}
+
+	if len(reports) == 0 {
+		fmt.Println("No archived snapshots to backfill.")
+		return
+	}
+	var dates []time.Time
+	for date := range reports {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	for _, date := range dates {
+		report := reports[date]
+		reportFile, err := utils.WriteRunReport(*logDir, *report)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		codeExamples := 0
+		for _, project := range report.Projects {
+			codeExamples += project.CodeExampleCount
+		}
+		fmt.Printf("%s: %d projects, %d code examples -> %s\n", report.RunID, len(report.Projects), codeExamples, reportFile)
+		if *writeDB {
+			writeSummaryHistory(*report)
+		}
+	}
+}
+
+// writeSummaryHistory adds the report's totals for each project to the history in the project's summaries document.
+// Dates a run or an earlier backfill already recorded are skipped.
+func writeSummaryHistory(report types.RunReport) {
+	var projectNames []string
+	for projectName := range report.Projects {
+		projectNames = append(projectNames, projectName)
+	}
+	sort.Strings(projectNames)
+	for _, projectName := range projectNames {
+		entry := db.MakeBackfilledSummaryHistory(report.Projects[projectName], report.StartedAt)
+		added, err := db.AddSummaryHistory(projectName, entry)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !added {
+			fmt.Printf("  %s: %s is already in the summaries history, skipped\n", projectName, entry.LastUpdatedAtUTC.Format(db.SummaryHistoryDateLayout))
+		}
+	}
+}
+
+// loadEnv loads the .env file for APP_ENV, which has the MONGODB_URI and DB_NAME of the database to write to
+func loadEnv() {
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		log.Fatal("APP_ENV is not set")
+	}
+	var envFile string
+	switch env {
+	case "development":
+		envFile = ".env.development"
+	case "production":
+		envFile = ".env.production"
+	default:
+		log.Fatalf("Unknown environment: %s", env)
+	}
+	if err := godotenv.Load(envFile); err != nil {
+		log.Fatalf("Error loading %s file", envFile)
+	}
+}
+
+// readArchivedPages reads the pages from an archived Snooty Data API documents dump
+func readArchivedPages(path string) ([]types.PageWrapper, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening snapshot %q: %w", path, err)
+	}
+	defer file.Close()
+	return snooty.ReadPagesForGitHubUser(*bufio.NewReader(file)), nil
+}
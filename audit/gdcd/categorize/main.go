@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"gdcd/add-code-examples"
+	"gdcd/db"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/tmc/langchaingo/llms/ollama"
+)
+
+func main() {
+	projects := flag.String("projects", "", "Comma-separated projects (Atlas collections) to categorize; defaults to every project in DB_NAME")
+	dryRun := flag.Bool("dry-run", false, "Categorize code examples and report the results without writing them to Atlas")
+	categoryHistoryFile := flag.String("category-history", "./logs/category-history.json", "File that keeps snippet categories between runs, shared with GDCD runs")
+	llmTimeout := flag.Duration("llm-timeout", add_code_examples.DefaultLLMTimeout, "How long a single LLM categorization call can take before it's skipped")
+	flag.Usage = func() {
+		fmt.Println("Usage: go run ./categorize [-projects <names>] [-dry-run] [-category-history <file>] [-llm-timeout <duration>]")
+		fmt.Println("Categorizes the code examples in Atlas that have no category, or that the LLM couldn't categorize,")
+		fmt.Println("and writes the categories back. Uses the same APP_ENV .env file as a GDCD run.")
+		fmt.Println("Example: go run ./categorize -projects atlas,compass -dry-run")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		log.Fatal("APP_ENV is not set")
+	}
+	var envFile string
+	switch env {
+	case "development":
+		envFile = ".env.development"
+	case "production":
+		envFile = ".env.production"
+	default:
+		log.Fatalf("Unknown environment: %s", env)
+	}
+	if err := godotenv.Load(envFile); err != nil {
+		log.Fatalf("Error loading %s file", envFile)
+	}
+
+	collections := projectCollections(*projects)
+	if len(collections) == 0 {
+		fmt.Println("No projects to categorize.")
+		return
+	}
+
+	ctx := context.Background()
+	add_code_examples.SetLLMTimeout(*llmTimeout)
+	llm, err := ollama.New(ollama.WithModel(add_code_examples.MODEL))
+	if err != nil {
+		log.Fatalf("failed to connect to ollama: %v", err)
+	}
+
+	// Categories assigned here go in the same history as GDCD runs, so later runs report drift against them
+	startTime := time.Now()
+	runID := "categorize-" + startTime.Format("2006-01-02-15-04-05")
+	if err := add_code_examples.LoadCategoryHistory(*categoryHistoryFile, runID); err != nil {
+		log.Fatalf("Failed to load category history: %v", err)
+	}
+
+	totalCategorized, totalRemaining := 0, 0
+	for _, collection := range collections {
+		pages := db.GetUncategorizedPages(collection)
+		if len(pages) == 0 {
+			continue
+		}
+
+		categorized, remaining := 0, 0
+		for i := range pages {
+			isDriversProject := pages[i].Product == "Drivers"
+			categorized += add_code_examples.CategorizeStoredNodes(*pages[i].Nodes, func(code string, lang string) (string, bool) {
+				return add_code_examples.GetCategory(code, lang, llm, ctx, isDriversProject)
+			})
+			for _, node := range *pages[i].Nodes {
+				if add_code_examples.IsUncategorized(node) {
+					remaining++
+				}
+			}
+		}
+
+		if !*dryRun {
+			db.UpdatePageCodeNodes(collection, pages)
+		}
+		fmt.Printf("%s: categorized %d code examples on %d pages, %d still uncategorized\n", collection, categorized, len(pages), remaining)
+		totalCategorized += categorized
+		totalRemaining += remaining
+	}
+
+	for _, failure := range add_code_examples.TakeLLMFailures() {
+		log.Printf("LLM failed to categorize %s snippet %s: %v\n", failure.Language, failure.SHA256Hash, failure.Err)
+	}
+	cacheStats := add_code_examples.GetCategoryCacheStats()
+	fmt.Printf("Categorized %d code examples (%d LLM calls, %d cached), %d still uncategorized, in %s\n",
+		totalCategorized, cacheStats.LLMCalls, cacheStats.CacheHits, totalRemaining, time.Since(startTime).Round(time.Second))
+	if *dryRun {
+		fmt.Println("Dry run: nothing was written to Atlas.")
+		return
+	}
+	if err := add_code_examples.SaveCategoryHistory(*categoryHistoryFile); err != nil {
+		log.Printf("Failed to save category history: %v\n", err)
+	}
+}
+
+// projectCollections returns the collections to categorize: the projects named in the -projects flag, or every
+// collection in DB_NAME that has pages
+func projectCollections(projects string) []string {
+	var collections []string
+	if projects != "" {
+		for _, project := range strings.Split(projects, ",") {
+			if project = strings.TrimSpace(project); project != "" {
+				collections = append(collections, project)
+			}
+		}
+		return collections
+	}
+	for collection, count := range db.CountPagesPerCollection(os.Getenv("DB_NAME")) {
+		if count > 0 {
+			collections = append(collections, collection)
+		}
+	}
+	sort.Strings(collections)
+	return collections
+}
@@ -23,6 +23,9 @@ func HandleUpdatedPageNodes(updatedPageNodes []types.ASTNodeWrapper, incomingSha
 		codeNode.Code = whiteSpaceTrimmedString
 		codeNode.SHA256Hash = hash
 		codeNode.DateUpdated = time.Now()
+		codeNode.IncludePath = incomingNode.Node.IncludePath
+		codeNode.SourceRepo, codeNode.SourcePath = snooty.GetExamplesRepoSource(incomingNode.Node.IncludePath)
+		codeNode.Anchor = incomingNode.Node.Anchor
 		if incomingNode.InstancesOnPage > 1 {
 			codeNode.InstancesOnPage = incomingNode.InstancesOnPage
 			updatedCodeNodeCount += incomingNode.InstancesOnPage
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"common"
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// AddSummaryHistory adds a dated entry to the history of a project's summaries document, creating the document if the
+// project doesn't have one yet. A date that's already in the history, such as one a run recorded, is left alone, and
+// AddSummaryHistory returns false.
+func AddSummaryHistory(collectionName string, info common.CollectionInfoView) (bool, error) {
+	uri := os.Getenv("MONGODB_URI")
+	docs := "www.mongodb.com/docs/drivers/go/current/"
+	if uri == "" {
+		log.Fatal("Set your 'MONGODB_URI' environment variable. " +
+			"See: " + docs +
+			"usage-examples/#environment-variable")
+	}
+	client, err := mongo.Connect(options.Client().
+		ApplyURI(uri))
+	if err != nil {
+		return false, fmt.Errorf("connecting to MongoDB: %w", err)
+	}
+	var dbName = os.Getenv("DB_NAME")
+	var ctx = context.Background()
+	defer func() {
+		if err = client.Disconnect(ctx); err != nil {
+			log.Printf("Failed to disconnect from MongoDB: %v", err)
+		}
+	}()
+	collection := client.Database(dbName).Collection(collectionName)
+
+	field := "history." + info.LastUpdatedAtUTC.Format(SummaryHistoryDateLayout)
+	filter := bson.D{
+		{Key: "_id", Value: "summaries"},
+		{Key: field, Value: bson.D{{Key: "$exists", Value: false}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: field, Value: info}}}}
+	// If the summaries document already has the date, the filter doesn't match it and the upsert tries to insert a
+	// second summaries document, which fails with a duplicate key error
+	_, err = collection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("adding %s to the %s summaries history: %w", field, collectionName, err)
+	}
+	return true, nil
+}
@@ -14,7 +14,9 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
-func BackUpDb() {
+// BackUpDb copies every collection in DB_NAME to a backup database named for today's date, drops the oldest backup,
+// and returns the name of the new backup.
+func BackUpDb() string {
 	uri := os.Getenv("MONGODB_URI")
 	docs := "www.mongodb.com/docs/drivers/go/current/"
 	if uri == "" {
@@ -88,7 +90,17 @@ func BackUpDb() {
 
 	// Drop the oldest backup. Get a list of backup names so we can find the oldest backup.
 	backupNames := getBackupDbNames(client, ctx)
-	oldestBackup := findOldestBackup(backupNames)
+	// Never drop the backup we just made; the end-of-run write guardrail compares against it
+	var olderBackups []string
+	for _, name := range backupNames {
+		if name != targetDBName {
+			olderBackups = append(olderBackups, name)
+		}
+	}
+	if len(olderBackups) == 0 {
+		return targetDBName
+	}
+	oldestBackup := findOldestBackup(olderBackups)
 	// Get a handle for the database
 	dbToDrop := client.Database(oldestBackup)
 
@@ -98,6 +110,7 @@ func BackUpDb() {
 		log.Fatalf("Failed to drop database %v: %v", oldestBackup, err)
 	}
 	log.Printf("Oldest backup database '%s' dropped successfully\n", oldestBackup)
+	return targetDBName
 }
 
 // The cluster contains a mix of databases - some are backups, and some are other databases.
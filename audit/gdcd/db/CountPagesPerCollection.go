@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// CountPagesPerCollection counts the page documents in each collection of a database, skipping the summaries
+// document. Pass DB_NAME for the live database, or the name BackUpDb returned for the pre-run backup.
+func CountPagesPerCollection(dbName string) map[string]int64 {
+	uri := os.Getenv("MONGODB_URI")
+	docs := "www.mongodb.com/docs/drivers/go/current/"
+	if uri == "" {
+		log.Fatal("Set your 'MONGODB_URI' environment variable. " +
+			"See: " + docs +
+			"usage-examples/#environment-variable")
+	}
+	client, err := mongo.Connect(options.Client().
+		ApplyURI(uri))
+	var ctx = context.Background()
+	if err != nil {
+		log.Printf("Failed to connect to MongoDB: %v", err)
+	}
+	defer func() {
+		if err = client.Disconnect(ctx); err != nil {
+			log.Printf("Failed to disconnect from MongoDB: %v", err)
+		}
+	}()
+	database := client.Database(dbName)
+	collectionNames, err := database.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		log.Fatalf("Error listing collections in %s: %v", dbName, err)
+	}
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "$ne", Value: "summaries"}}}}
+	counts := make(map[string]int64, len(collectionNames))
+	for _, collName := range collectionNames {
+		count, err := database.Collection(collName).CountDocuments(ctx, filter)
+		if err != nil {
+			log.Fatalf("Error counting documents in %s.%s: %v", dbName, collName, err)
+		}
+		counts[collName] = count
+	}
+	return counts
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"gdcd/types"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// copierWriteRecord is the part of an examples-copier write log record the cross-check reads
+type copierWriteRecord struct {
+	Timestamp  time.Time `bson:"timestamp"`
+	TargetRepo string    `bson:"target_repo"`
+	Files      []string  `bson:"files"`
+	Deletions  []string  `bson:"deletions"`
+}
+
+// copierDeprecationEvent is the part of an examples-copier deprecation audit event the cross-check reads
+type copierDeprecationEvent struct {
+	Timestamp  time.Time `bson:"timestamp"`
+	TargetRepo string    `bson:"target_repo"`
+	TargetPath string    `bson:"target_path"`
+}
+
+// GetCopierFileRecords reads what the examples-copier's audit records say happened to the files it manages: each file
+// its commits and PRs wrote or deleted, from its write log, and each file it marked deprecated, from its audit events.
+// It only reads; GDCD never writes to the copier's database.
+func GetCopierFileRecords(config types.CopierAuditConfig) ([]types.CopierFileRecord, error) {
+	if config.URI == "" {
+		return nil, fmt.Errorf("set COPIER_MONGODB_URI or MONGODB_URI to read the examples-copier's audit records")
+	}
+	ctx := context.Background()
+	client, err := mongo.Connect(options.Client().ApplyURI(config.URI))
+	if err != nil {
+		return nil, fmt.Errorf("connect to the examples-copier's database: %w", err)
+	}
+	defer client.Disconnect(ctx)
+	database := client.Database(config.Database)
+
+	var records []types.CopierFileRecord
+	cursor, err := database.Collection(config.WriteLogCollection).Find(ctx, bson.D{},
+		options.Find().SetProjection(bson.D{{Key: "timestamp", Value: 1}, {Key: "target_repo", Value: 1}, {Key: "files", Value: 1}, {Key: "deletions", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("read write log %s: %w", config.WriteLogCollection, err)
+	}
+	var writes []copierWriteRecord
+	if err := cursor.All(ctx, &writes); err != nil {
+		return nil, fmt.Errorf("read write log %s: %w", config.WriteLogCollection, err)
+	}
+	for _, write := range writes {
+		for _, path := range write.Files {
+			records = append(records, types.CopierFileRecord{
+				CopierFileKey: types.CopierFileKey{Repo: write.TargetRepo, Path: path},
+				Status:        types.CopierFileSynced,
+				Timestamp:     write.Timestamp,
+			})
+		}
+		for _, path := range write.Deletions {
+			records = append(records, types.CopierFileRecord{
+				CopierFileKey: types.CopierFileKey{Repo: write.TargetRepo, Path: path},
+				Status:        types.CopierFileRemoved,
+				Timestamp:     write.Timestamp,
+			})
+		}
+	}
+
+	cursor, err = database.Collection(config.EventsCollection).Find(ctx, bson.D{{Key: "event_type", Value: "deprecation"}},
+		options.Find().SetProjection(bson.D{{Key: "timestamp", Value: 1}, {Key: "target_repo", Value: 1}, {Key: "target_path", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("read audit events %s: %w", config.EventsCollection, err)
+	}
+	var deprecations []copierDeprecationEvent
+	if err := cursor.All(ctx, &deprecations); err != nil {
+		return nil, fmt.Errorf("read audit events %s: %w", config.EventsCollection, err)
+	}
+	for _, event := range deprecations {
+		records = append(records, types.CopierFileRecord{
+			CopierFileKey: types.CopierFileKey{Repo: event.TargetRepo, Path: event.TargetPath},
+			Status:        types.CopierFileDeprecated,
+			Timestamp:     event.Timestamp,
+		})
+	}
+	return records, nil
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"common"
+	"context"
+	"gdcd/types"
+	"log"
+	"os"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// GetIncludedExamples reads the current pages for a project from Atlas and returns each literalinclude of a file in an
+// examples directory the examples-copier manages: the code examples with a source repo. Removed pages and code
+// examples are skipped.
+func GetIncludedExamples(collectionName string) []types.IncludedExample {
+	uri := os.Getenv("MONGODB_URI")
+	docs := "www.mongodb.com/docs/drivers/go/current/"
+	if uri == "" {
+		log.Fatal("Set your 'MONGODB_URI' environment variable. " +
+			"See: " + docs +
+			"usage-examples/#environment-variable")
+	}
+	client, err := mongo.Connect(options.Client().
+		ApplyURI(uri))
+	var dbName = os.Getenv("DB_NAME")
+	var ctx = context.Background()
+	if err != nil {
+		log.Printf("Failed to connect to MongoDB: %v", err)
+	}
+	defer func() {
+		if err = client.Disconnect(ctx); err != nil {
+			log.Printf("Failed to disconnect from MongoDB: %v", err)
+		}
+	}()
+	collection := client.Database(dbName).Collection(collectionName)
+	filter := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$ne", Value: "summaries"}}},
+		{Key: "is_removed", Value: bson.D{{Key: "$ne", Value: true}}},
+		{Key: "nodes.source_repo", Value: bson.D{{Key: "$exists", Value: true}}},
+	}
+	projection := bson.D{{Key: "_id", Value: 1}, {Key: "nodes", Value: 1}}
+
+	var includes []types.IncludedExample
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		log.Printf("Failed to get included examples for project %s: %v\n", collectionName, err)
+		return includes
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var page common.DocsPage
+		if err := cursor.Decode(&page); err != nil {
+			log.Printf("Failed to decode document: %v\n", err)
+			continue
+		}
+		if page.Nodes == nil {
+			continue
+		}
+		for _, node := range *page.Nodes {
+			if node.IsRemoved || node.SourceRepo == "" {
+				continue
+			}
+			includes = append(includes, types.IncludedExample{
+				PageID:        page.ID,
+				IncludePath:   node.IncludePath,
+				CopierFileKey: types.CopierFileKey{Repo: node.SourceRepo, Path: node.SourcePath},
+			})
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		log.Printf("Failed to cursor: %v\n", err)
+	}
+	return includes
+}
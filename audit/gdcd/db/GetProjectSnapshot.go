@@ -0,0 +1,73 @@
+package db
+
+import (
+	"common"
+	"context"
+	"gdcd/types"
+	"log"
+	"os"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// GetProjectSnapshot reads the current pages for a project from Atlas and returns their IDs along with the total code
+// example count for each language. Call it after the project's batch update so the snapshot reflects this run.
+func GetProjectSnapshot(collectionName string) types.ProjectSnapshot {
+	uri := os.Getenv("MONGODB_URI")
+	docs := "www.mongodb.com/docs/drivers/go/current/"
+	if uri == "" {
+		log.Fatal("Set your 'MONGODB_URI' environment variable. " +
+			"See: " + docs +
+			"usage-examples/#environment-variable")
+	}
+	client, err := mongo.Connect(options.Client().
+		ApplyURI(uri))
+	var dbName = os.Getenv("DB_NAME")
+	var ctx = context.Background()
+	if err != nil {
+		log.Printf("Failed to connect to MongoDB: %v", err)
+	}
+	defer func() {
+		if err = client.Disconnect(ctx); err != nil {
+			log.Printf("Failed to disconnect from MongoDB: %v", err)
+		}
+	}()
+	// Define the database and collection
+	collection := client.Database(dbName).Collection(collectionName)
+	// Skip the summaries document and any pages flagged as removed
+	filter := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$ne", Value: "summaries"}}},
+		{Key: "is_removed", Value: bson.D{{Key: "$ne", Value: true}}},
+	}
+	projection := bson.D{{Key: "_id", Value: 1}, {Key: "code_nodes_total", Value: 1}, {Key: "languages", Value: 1}}
+
+	snapshot := types.ProjectSnapshot{
+		LanguageCounts: make(map[string]int),
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		log.Printf("Failed to get snapshot for project %s: %v\n", collectionName, err)
+		return snapshot
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var page common.DocsPage
+		if err := cursor.Decode(&page); err != nil {
+			log.Printf("Failed to decode document: %v\n", err)
+			continue
+		}
+		snapshot.PageIDs = append(snapshot.PageIDs, page.ID)
+		snapshot.CodeExampleCount += page.CodeNodesTotal
+		for language, counts := range page.Languages.ToMap() {
+			snapshot.LanguageCounts[language] += counts.Total
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		log.Printf("Failed to cursor: %v\n", err)
+	}
+	sort.Strings(snapshot.PageIDs)
+	return snapshot
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"common"
+	"context"
+	"log"
+	"os"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// GetUncategorizedPages reads the current pages for a project from Atlas that have at least one current code example
+// without a category, or that the LLM couldn't categorize. Pages are returned with all of their code nodes, so they can
+// be written back whole with UpdatePageCodeNodes.
+func GetUncategorizedPages(collectionName string) []common.DocsPage {
+	uri := os.Getenv("MONGODB_URI")
+	docs := "www.mongodb.com/docs/drivers/go/current/"
+	if uri == "" {
+		log.Fatal("Set your 'MONGODB_URI' environment variable. " +
+			"See: " + docs +
+			"usage-examples/#environment-variable")
+	}
+	client, err := mongo.Connect(options.Client().
+		ApplyURI(uri))
+	var dbName = os.Getenv("DB_NAME")
+	var ctx = context.Background()
+	if err != nil {
+		log.Printf("Failed to connect to MongoDB: %v", err)
+	}
+	defer func() {
+		if err = client.Disconnect(ctx); err != nil {
+			log.Printf("Failed to disconnect from MongoDB: %v", err)
+		}
+	}()
+	collection := client.Database(dbName).Collection(collectionName)
+	filter := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$ne", Value: "summaries"}}},
+		{Key: "is_removed", Value: bson.D{{Key: "$ne", Value: true}}},
+		{Key: "nodes", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+			{Key: "is_removed", Value: bson.D{{Key: "$ne", Value: true}}},
+			{Key: "category", Value: bson.D{{Key: "$in", Value: bson.A{"", nil, "Uncategorized"}}}},
+		}}}},
+	}
+	projection := bson.D{{Key: "_id", Value: 1}, {Key: "nodes", Value: 1}, {Key: "product", Value: 1}}
+
+	var pages []common.DocsPage
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		log.Printf("Failed to get uncategorized pages for project %s: %v\n", collectionName, err)
+		return pages
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var page common.DocsPage
+		if err := cursor.Decode(&page); err != nil {
+			log.Printf("Failed to decode document: %v\n", err)
+			continue
+		}
+		if page.Nodes != nil {
+			pages = append(pages, page)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		log.Printf("Failed to cursor: %v\n", err)
+	}
+	return pages
+}
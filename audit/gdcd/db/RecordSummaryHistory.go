@@ -0,0 +1,34 @@
+package db
+
+import (
+	"common"
+	"gdcd/types"
+	"time"
+)
+
+// SummaryHistoryDateLayout is the layout of the dates that key a summaries document's history
+const SummaryHistoryDateLayout = "2006-01-02"
+
+// RecordSummaryHistory records the collection info for a version in the summaries document's history, under the date
+// the info was last updated. A later run on the same day replaces that day's entry.
+func RecordSummaryHistory(summaries common.CollectionReport, version string) common.CollectionReport {
+	info, ok := summaries.Version[version]
+	if !ok {
+		return summaries
+	}
+	if summaries.History == nil {
+		summaries.History = make(map[string]common.CollectionInfoView)
+	}
+	summaries.History[info.LastUpdatedAtUTC.Format(SummaryHistoryDateLayout)] = info
+	return summaries
+}
+
+// MakeBackfilledSummaryHistory makes the summaries history entry for a project snapshot reconstructed from an archived
+// Snooty snapshot taken on date. The totals are the ones a run over the same pages would record.
+func MakeBackfilledSummaryHistory(snapshot types.ProjectSnapshot, date time.Time) common.CollectionInfoView {
+	return common.CollectionInfoView{
+		TotalPageCount:   snapshot.Counter.TotalCurrentPageCount,
+		TotalCodeCount:   snapshot.CodeExampleCount,
+		LastUpdatedAtUTC: date.UTC(),
+	}
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"common"
+	"gdcd/types"
+	"testing"
+	"time"
+)
+
+func TestRecordSummaryHistory(t *testing.T) {
+	backfilled := MakeBackfilledSummaryHistory(types.ProjectSnapshot{
+		CodeExampleCount: 40,
+		Counter:          types.ProjectCounts{TotalCurrentPageCount: 12},
+	}, time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC))
+	if backfilled.TotalPageCount != 12 || backfilled.TotalCodeCount != 40 {
+		t.Errorf("FAILED: backfilled entry has %d pages and %d code examples, want 12 and 40", backfilled.TotalPageCount, backfilled.TotalCodeCount)
+	}
+
+	runAt := time.Date(2025, 3, 3, 18, 0, 0, 0, time.UTC)
+	summaries := common.CollectionReport{
+		ID: "summaries",
+		Version: map[string]common.CollectionInfoView{
+			"master": {TotalPageCount: 20, TotalCodeCount: 75, LastUpdatedAtUTC: runAt},
+		},
+		History: map[string]common.CollectionInfoView{"2024-09-01": backfilled},
+	}
+	summaries = RecordSummaryHistory(summaries, "master")
+
+	if len(summaries.History) != 2 {
+		t.Fatalf("FAILED: got %d history entries, want 2: %+v", len(summaries.History), summaries.History)
+	}
+	if summaries.History["2024-09-01"] != backfilled {
+		t.Errorf("FAILED: the backfilled entry changed: %+v", summaries.History["2024-09-01"])
+	}
+	if got := summaries.History["2025-03-03"]; got.TotalCodeCount != 75 || !got.LastUpdatedAtUTC.Equal(runAt) {
+		t.Errorf("FAILED: the run's entry is %+v, want the master version's totals", got)
+	}
+
+	// A version the document doesn't have records nothing
+	if got := RecordSummaryHistory(common.CollectionReport{}, "master"); got.History != nil {
+		t.Errorf("FAILED: recorded history for a missing version: %+v", got.History)
+	}
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// RestoreCollectionsFromBackup replaces each of the named collections in DB_NAME with its copy in the backup
+// database, undoing this run's writes to them. A collection the backup doesn't have is dropped, since it didn't
+// exist before the run.
+func RestoreCollectionsFromBackup(backupDbName string, collectionNames []string) {
+	uri := os.Getenv("MONGODB_URI")
+	docs := "www.mongodb.com/docs/drivers/go/current/"
+	if uri == "" {
+		log.Fatal("Set your 'MONGODB_URI' environment variable. " +
+			"See: " + docs +
+			"usage-examples/#environment-variable")
+	}
+	client, err := mongo.Connect(options.Client().
+		ApplyURI(uri))
+	var dbName = os.Getenv("DB_NAME")
+	var ctx = context.Background()
+	if err != nil {
+		log.Printf("Failed to connect to MongoDB: %v", err)
+	}
+	defer func() {
+		if err = client.Disconnect(ctx); err != nil {
+			log.Printf("Failed to disconnect from MongoDB: %v", err)
+		}
+	}()
+	sourceDb := client.Database(backupDbName)
+	targetDb := client.Database(dbName)
+
+	for _, collName := range collectionNames {
+		// Read the backup before dropping anything, so a failed read leaves the collection as it is
+		cursor, err := sourceDb.Collection(collName).Find(ctx, bson.D{})
+		if err != nil {
+			log.Fatalf("Error finding documents in backup collection %s: %v", collName, err)
+		}
+		var documents []interface{}
+		for cursor.Next(ctx) {
+			var doc bson.M
+			if err = cursor.Decode(&doc); err != nil {
+				log.Fatalf("Error decoding document in backup collection %s: %v", collName, err)
+			}
+			documents = append(documents, doc)
+		}
+		if err := cursor.Err(); err != nil {
+			log.Fatalf("Error reading backup collection %s: %v", collName, err)
+		}
+		cursor.Close(ctx)
+
+		targetColl := targetDb.Collection(collName)
+		if err := targetColl.Drop(ctx); err != nil {
+			log.Fatalf("Failed to drop collection %s before restoring it: %v", collName, err)
+		}
+		if len(documents) > 0 {
+			if _, err := targetColl.InsertMany(ctx, documents); err != nil {
+				log.Fatalf("Error restoring documents into collection %s: %v", collName, err)
+			}
+		}
+		log.Printf("Restored collection %s from backup %s (%d documents)\n", collName, backupDbName, len(documents))
+	}
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"common"
+	"context"
+	"log"
+	"os"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// UpdatePageCodeNodes replaces the code nodes of each page in Atlas with the page's nodes, leaving the rest of the
+// page document as it is. Use it to write back changes to the metadata of existing code examples, such as their
+// category, without the rest of a GDCD run. Returns how many pages were modified.
+func UpdatePageCodeNodes(collectionName string, pages []common.DocsPage) int64 {
+	if len(pages) == 0 {
+		return 0
+	}
+	uri := os.Getenv("MONGODB_URI")
+	docs := "www.mongodb.com/docs/drivers/go/current/"
+	if uri == "" {
+		log.Fatal("Set your 'MONGODB_URI' environment variable. " +
+			"See: " + docs +
+			"usage-examples/#environment-variable")
+	}
+	client, err := mongo.Connect(options.Client().
+		ApplyURI(uri))
+	var dbName = os.Getenv("DB_NAME")
+	var ctx = context.Background()
+	if err != nil {
+		log.Printf("Failed to connect to MongoDB: %v", err)
+	}
+	defer func() {
+		if err = client.Disconnect(ctx); err != nil {
+			log.Printf("Failed to disconnect from MongoDB: %v", err)
+		}
+	}()
+	collection := client.Database(dbName).Collection(collectionName)
+	models := make([]mongo.WriteModel, 0, len(pages))
+	for _, page := range pages {
+		filter := bson.D{{Key: "_id", Value: page.ID}}
+		update := bson.D{{Key: "$set", Value: bson.D{{Key: "nodes", Value: page.Nodes}}}}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update))
+	}
+	result, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		log.Printf("Failed to update code nodes for collection %s: %v", collectionName, err)
+	}
+	if result == nil {
+		return 0
+	}
+	log.Printf("Atlas: For collection %s: updated code nodes on %v documents\n", collectionName, result.ModifiedCount)
+	return result.ModifiedCount
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"gdcd/add-code-examples"
 	"gdcd/db"
@@ -11,6 +12,8 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -18,6 +21,12 @@ import (
 )
 
 func main() {
+	listProjects := flag.Bool("list-projects", false, "Print the projects that would be processed, then exit without parsing them")
+	categoryHistoryFile := flag.String("category-history", "./logs/category-history.json", "File that keeps snippet categories between runs, used to report category drift")
+	slowPageCount := flag.Int("slow-pages", DefaultSlowPageCount, "How many of each project's slowest pages to list in the run report, with the time each processing stage took")
+	llmTimeout := flag.Duration("llm-timeout", add_code_examples.DefaultLLMTimeout, "How long a single LLM categorization call can take before it's reported as an LLMTimeout issue")
+	flag.Parse()
+
 	// Set up logging + a console display to show progress
 	// Logs are saved to a timestamped file in the logs directory, which is ignored by git
 	// NOTE: the GDCD tool can take a long time to run (~1.5-2hrs, depending on your machine)
@@ -34,6 +43,13 @@ func main() {
 	fmt.Println("Log file created:", logFile.Name())
 	defer logFile.Close()
 
+	// The run report is named after the log file so the two can be matched up. Compare run reports with report-diff.
+	runReport := types.RunReport{
+		RunID:     strings.TrimSuffix(filepath.Base(logFile.Name()), "-app.log"),
+		StartedAt: startTime,
+		Projects:  make(map[string]types.ProjectSnapshot),
+	}
+
 	// Determine the environment
 	env := os.Getenv("APP_ENV")
 	if env == "" {
@@ -60,8 +76,26 @@ func main() {
 	client := &http.Client{
 		Timeout: 30 * time.Second, // Set a timeout
 	}
+	projectFilter, err := utils.LoadProjectFilter()
+	if err != nil {
+		log.Fatalf("Invalid project filter: %v", err)
+	}
+	pageFilter, err := utils.LoadPageFilter()
+	if err != nil {
+		log.Fatalf("Invalid page filter: %v", err)
+	}
+	guardrailConfig, err := utils.LoadWriteGuardrailConfig()
+	if err != nil {
+		log.Fatalf("Invalid write guardrail settings: %v", err)
+	}
 	// Uncomment to parse all projects
-	projectsToParse := snooty.GetProjects(client)
+	projectsToParse := snooty.GetProjects(client, projectFilter)
+
+	// With --list-projects, show what a run would process without parsing anything
+	if *listProjects {
+		PrintProjectList(projectsToParse)
+		return
+	}
 
 	// Uncomment to parse a single project during testing
 	// compass := types.ProjectDetails{
@@ -87,19 +121,27 @@ func main() {
 
 	// Initialize the LLM
 	ctx := context.Background()
+	add_code_examples.SetLLMTimeout(*llmTimeout)
 	llm, err := ollama.New(ollama.WithModel(add_code_examples.MODEL))
 	if err != nil {
 		log.Fatalf("failed to connect to ollama: %v", err)
 	}
 
+	// Load the categories from earlier runs so we can report snippets whose category changes during this run
+	if err := add_code_examples.LoadCategoryHistory(*categoryHistoryFile, runReport.RunID); err != nil {
+		log.Fatalf("Failed to load category history: %v", err)
+	}
+
 	// Backup the current database
-	db.BackUpDb()
+	backupDbName := db.BackUpDb()
 
 	// Process pages for every project in the projectsToParse array
 	firstProject := true
 	for _, project := range projectsToParse {
 		// Get pages from the API
 		pages := snooty.GetProjectPages(project, client)
+		// Drop pages excluded by facet or template, so their code-formatted text doesn't count as examples
+		pages, excludedPageIds := snooty.FilterPages(pages, project.ProjectName, pageFilter)
 		pageCount := len(pages)
 		log.Printf("Found %d docs pages for project %s\n", pageCount, project.ProjectName)
 		report := types.ProjectReport{
@@ -108,6 +150,7 @@ func main() {
 			Issues:      nil,
 			Counter: types.ProjectCounts{
 				TotalCurrentPageCount: pageCount,
+				ExcludedPagesCount:    len(excludedPageIds),
 			},
 		}
 		if pageCount > 0 {
@@ -117,19 +160,81 @@ func main() {
 			} else {
 				utils.SetNewSecondaryTarget(pageCount, project.ProjectName)
 			}
-			CheckPagesForUpdates(pages, project, llm, ctx, report)
+			report = CheckPagesForUpdates(pages, project, llm, ctx, report, *slowPageCount)
 			utils.UpdatePrimaryTarget()
 		} else {
 			report = utils.ReportIssues(types.PagesNotFoundIssue, report, project.ProjectName)
+			report = reportErrorIssues(report)
 			LogReportForProject(project.ProjectName, report)
 			utils.UpdatePrimaryTarget()
 		}
+		runReport = AddProjectToRunReport(runReport, project.ProjectName, report)
 	}
 	utils.FinishPrintingProgressIndicators()
 
+	// Make sure no collection lost or gained an unexpected share of its pages, restoring it from the backup if so
+	runReport = CheckWriteGuardrail(runReport, backupDbName, guardrailConfig)
+
+	// Flag pages that include example files the examples-copier has deprecated or never synced
+	runReport = CheckCopierAudit(runReport, utils.LoadCopierAuditConfig())
+
+	cacheStats := add_code_examples.GetCategoryCacheStats()
+	log.Printf("LLM categorized %d unique snippets and reused cached categories for %d duplicate snippets\n", cacheStats.LLMCalls, cacheStats.CacheHits)
+
+	// Report snippets whose category changed since an earlier run, so taxonomy or model changes can be reviewed
+	runReport.CategoryDrift = add_code_examples.GetCategoryDrift()
+	for _, drift := range runReport.CategoryDrift {
+		log.Printf("Category drift for %s snippet %s: %s (%s, run %s) -> %s (%s)\n", drift.Language, drift.SHA256Hash, drift.OldCategory, drift.OldModel, drift.OldRunID, drift.NewCategory, drift.NewModel)
+	}
+	if len(runReport.CategoryDrift) > 0 {
+		fmt.Printf("%d snippets changed category since an earlier run - see category_drift in the run report\n", len(runReport.CategoryDrift))
+	}
+	if err := add_code_examples.SaveCategoryHistory(*categoryHistoryFile); err != nil {
+		log.Printf("Failed to save category history: %v\n", err)
+	}
+
+	// Count issues per code so downstream tooling can track how often each class of issue happens across runs
+	runReport.IssueCounts = utils.CountIssues(runReport)
+
+	reportFile, err := utils.WriteRunReport(logDir, runReport)
+	if err != nil {
+		log.Printf("Failed to write run report: %v\n", err)
+	} else {
+		fmt.Println("Run report created:", reportFile)
+	}
+
+	// Post per-project summaries to the docs team's Slack channel after production runs
+	if env == "production" {
+		notificationConfig, err := utils.LoadNotificationConfig()
+		if err != nil {
+			log.Printf("Not posting run summaries: %v\n", err)
+		} else if posted, err := utils.NotifyDocsTeam(ctx, runReport, notificationConfig); err != nil {
+			log.Printf("Failed to post run summaries to Slack: %v\n", err)
+		} else if notificationConfig.WebhookURL != "" {
+			log.Printf("Posted run summaries for %d projects to Slack\n", posted)
+		}
+	}
+
 	// Log some completion details to console
 	endTime := time.Now()
 	formattedTime = endTime.Format("2006-01-02 15:04:05")
 	fmt.Println("Completed at ", formattedTime)
 	fmt.Println("Parsing projects took ", endTime.Sub(startTime))
 }
+
+// reportErrorIssues adds the pages that couldn't be parsed and the LLM calls that failed since it was last called to
+// the project report. Call it once per project, before logging the report.
+func reportErrorIssues(report types.ProjectReport) types.ProjectReport {
+	for _, parseError := range snooty.TakeParseErrors() {
+		report = utils.ReportErrorIssue(types.ASTParseErrorIssue, report, parseError.PageID, "", parseError.Err)
+	}
+	for _, failure := range add_code_examples.TakeLLMFailures() {
+		issueType := types.LLMErrorIssue
+		if failure.Timeout {
+			issueType = types.LLMTimeoutIssue
+		}
+		detail := fmt.Sprintf("%s snippet %s", failure.Language, failure.SHA256Hash)
+		report = utils.ReportErrorIssue(issueType, report, "", detail, failure.Err)
+	}
+	return report
+}
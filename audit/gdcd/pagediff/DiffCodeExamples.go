@@ -0,0 +1,34 @@
+package pagediff
+
+import (
+	"common"
+	compare_code_examples "gdcd/compare-code-examples"
+	"gdcd/types"
+)
+
+// DiffCodeExamples compares the stored code examples currently on a page with incoming code example nodes, and sorts
+// them into unchanged, updated, new, and removed examples. Pass only stored examples that aren't marked IsRemoved.
+// An incoming example with the same whitespace-trimmed code as a stored example is unchanged. An incoming example that
+// IsUpdatedExample considers an edit of a stored example is updated, and any other incoming example is new. Stored
+// examples that no incoming example matched have been removed from the page.
+func DiffCodeExamples(existingNodes []common.CodeNode, incomingNodes []types.ASTNode) Diff {
+	matches := compare_code_examples.MatchCodeExamples(existingNodes, incomingNodes)
+	diff := Diff{
+		Unchanged: matches.Unchanged,
+		Removed:   matches.Removed,
+	}
+	for _, wrapper := range matches.Updated {
+		diff.Updated = append(diff.Updated, UpdatedExample{
+			Existing:        matches.UpdatedSha256ToCodeNodeMap[HashCode(wrapper.Node.Value)],
+			Incoming:        wrapper.Node,
+			InstancesOnPage: wrapper.InstancesOnPage,
+		})
+	}
+	for _, wrapper := range matches.New {
+		diff.New = append(diff.New, NewExample{
+			Incoming:        wrapper.Node,
+			InstancesOnPage: wrapper.InstancesOnPage,
+		})
+	}
+	return diff
+}
@@ -0,0 +1,49 @@
+package pagediff
+
+import (
+	"common"
+	"gdcd/compare-code-examples/data"
+	"gdcd/types"
+	"testing"
+)
+
+func TestDiffCodeExamplesSortsExamples(t *testing.T) {
+	unchangedNode, unchangedASTNode := data.GetUnchangedNodes()
+	updatedNode, updatedASTNode := data.GetUpdatedNodes()
+	removedNode, _ := data.GetRemovedNodes()
+	newASTNodes := data.GetNewASTNodes(1)
+
+	existingNodes := []common.CodeNode{unchangedNode, updatedNode, removedNode}
+	incomingNodes := []types.ASTNode{unchangedASTNode, updatedASTNode, newASTNodes[0]}
+	diff := DiffCodeExamples(existingNodes, incomingNodes)
+
+	if len(diff.Unchanged) != 1 || diff.Unchanged[0].SHA256Hash != unchangedNode.SHA256Hash {
+		t.Errorf("FAILED: got unchanged %v, want the unchanged node", diff.Unchanged)
+	}
+	if len(diff.Updated) != 1 {
+		t.Fatalf("FAILED: got %d updated examples, want 1", len(diff.Updated))
+	}
+	if diff.Updated[0].Existing.SHA256Hash != updatedNode.SHA256Hash {
+		t.Errorf("FAILED: updated example matched %s, want %s", diff.Updated[0].Existing.SHA256Hash, updatedNode.SHA256Hash)
+	}
+	if diff.Updated[0].Incoming.Value != updatedASTNode.Value || diff.Updated[0].InstancesOnPage != 1 {
+		t.Errorf("FAILED: got updated example %+v, want the incoming updated node once", diff.Updated[0])
+	}
+	if len(diff.New) != 1 || diff.New[0].Incoming.Value != newASTNodes[0].Value {
+		t.Errorf("FAILED: got new %v, want the new node", diff.New)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].SHA256Hash != removedNode.SHA256Hash {
+		t.Errorf("FAILED: got removed %v, want the removed node", diff.Removed)
+	}
+}
+
+func TestDiffCodeExamplesCountsRepeatedExamples(t *testing.T) {
+	unchangedNode, unchangedASTNode := data.GetUnchangedNodes()
+	diff := DiffCodeExamples([]common.CodeNode{unchangedNode}, []types.ASTNode{unchangedASTNode, unchangedASTNode})
+	if len(diff.Unchanged) != 1 || diff.Unchanged[0].InstancesOnPage != 2 {
+		t.Errorf("FAILED: got unchanged %v, want the unchanged node with 2 instances on the page", diff.Unchanged)
+	}
+	if len(diff.New) != 0 || len(diff.Removed) != 0 {
+		t.Errorf("FAILED: got %d new and %d removed examples, want none", len(diff.New), len(diff.Removed))
+	}
+}
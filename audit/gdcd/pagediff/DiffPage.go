@@ -0,0 +1,36 @@
+package pagediff
+
+import (
+	"common"
+	compare_code_examples "gdcd/compare-code-examples"
+	"gdcd/types"
+)
+
+// DiffPage compares a page stored in the database with the incoming AST for the page, following the rules GDCD uses
+// when it updates an existing page. GDCD only compares code examples when the number of code examples on the incoming
+// page differs from the number stored for the page. Otherwise, it treats the examples as unchanged and PageDiff's
+// Compared is false. When the stored page has no code examples, every incoming example is new, and when the incoming
+// page has none, every stored example that isn't already removed is removed.
+func DiffPage(existingPage common.DocsPage, ast types.AST) PageDiff {
+	var existingCurrentNodes []common.CodeNode
+	pageDiff := PageDiff{Examples: ExtractCodeExamples(ast)}
+	existingCodeNodeCount := 0
+	if existingPage.Nodes != nil {
+		// The same split as db.GetCurrentRemovedAtlasCodeNodes, without depending on the db package
+		for _, node := range *existingPage.Nodes {
+			if node.IsRemoved {
+				pageDiff.PreviouslyRemoved = append(pageDiff.PreviouslyRemoved, node)
+			} else {
+				existingCurrentNodes = append(existingCurrentNodes, node)
+			}
+		}
+		existingCodeNodeCount = compare_code_examples.GetCodeNodeCount(*existingPage.Nodes)
+	}
+	if len(pageDiff.Examples.Code) == existingCodeNodeCount {
+		return pageDiff
+	}
+
+	pageDiff.Compared = true
+	pageDiff.Diff = DiffCodeExamples(existingCurrentNodes, pageDiff.Examples.Code)
+	return pageDiff
+}
@@ -0,0 +1,44 @@
+package pagediff
+
+import (
+	"common"
+	"gdcd/compare-code-examples/data"
+	"gdcd/types"
+	"testing"
+)
+
+func TestDiffPageSkipsComparisonWhenCountsMatch(t *testing.T) {
+	unchangedNode, _ := data.GetUnchangedNodes()
+	nodes := []common.CodeNode{unchangedNode}
+	page := common.DocsPage{Nodes: &nodes}
+	ast := types.AST{Children: data.GetNewASTNodes(1)}
+
+	pageDiff := DiffPage(page, ast)
+	if pageDiff.Compared {
+		t.Errorf("FAILED: page with the same number of code examples was compared")
+	}
+	if len(pageDiff.Examples.Code) != 1 {
+		t.Errorf("FAILED: got %d code examples, want 1", len(pageDiff.Examples.Code))
+	}
+}
+
+func TestDiffPageComparesWhenCountsChange(t *testing.T) {
+	unchangedNode, unchangedASTNode := data.GetUnchangedNodes()
+	previouslyRemovedNode, _ := data.GetRemovedNodes()
+	previouslyRemovedNode.IsRemoved = true
+	nodes := []common.CodeNode{unchangedNode, previouslyRemovedNode}
+	page := common.DocsPage{Nodes: &nodes}
+	ast := types.AST{Children: append([]types.ASTNode{unchangedASTNode}, data.GetNewASTNodes(2)...)}
+
+	pageDiff := DiffPage(page, ast)
+	if !pageDiff.Compared {
+		t.Fatalf("FAILED: page with a different number of code examples wasn't compared")
+	}
+	if len(pageDiff.Diff.Unchanged) != 1 || len(pageDiff.Diff.New) != 2 || len(pageDiff.Diff.Removed) != 0 {
+		t.Errorf("FAILED: got %d unchanged, %d new, %d removed, want 1, 2, 0",
+			len(pageDiff.Diff.Unchanged), len(pageDiff.Diff.New), len(pageDiff.Diff.Removed))
+	}
+	if len(pageDiff.PreviouslyRemoved) != 1 {
+		t.Errorf("FAILED: got %d previously removed examples, want 1", len(pageDiff.PreviouslyRemoved))
+	}
+}
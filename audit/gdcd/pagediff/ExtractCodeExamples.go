@@ -0,0 +1,17 @@
+package pagediff
+
+import (
+	"gdcd/snooty"
+	"gdcd/types"
+)
+
+// ExtractCodeExamples returns the code examples on a page's AST, as GDCD collects them. It also records on each node
+// the file a literalinclude came from and the anchor of the section it appears in, so the AST is modified in place.
+func ExtractCodeExamples(ast types.AST) CodeExamples {
+	code, literalIncludes, ioCodeBlocks := snooty.GetCodeExamplesFromIncomingData(ast)
+	return CodeExamples{
+		Code:            code,
+		LiteralIncludes: literalIncludes,
+		IoCodeBlocks:    ioCodeBlocks,
+	}
+}
@@ -0,0 +1,9 @@
+package pagediff
+
+import "gdcd/snooty"
+
+// HashCode returns the SHA256 hash GDCD stores for a code example, which is the hash of the whitespace-trimmed code.
+// Two examples with the same hash are the same example.
+func HashCode(code string) string {
+	return snooty.MakeSha256HashForCode(code)
+}
@@ -0,0 +1,16 @@
+package pagediff
+
+import (
+	"common"
+	compare_code_examples "gdcd/compare-code-examples"
+	"gdcd/types"
+)
+
+// IsUpdatedExample reports whether GDCD considers incomingCode an edit of existingCode rather than a different code
+// example: the whitespace-trimmed code changes by less than the percentage of characters GDCD accepts for an update.
+// Identical code is unchanged rather than updated, so check HashCode first to tell them apart.
+func IsUpdatedExample(existingCode string, incomingCode string) bool {
+	existing := map[string]common.CodeNode{HashCode(existingCode): {Code: existingCode}}
+	_, matchedNode := compare_code_examples.CodeNewOrUpdated(existing, types.ASTNode{Value: incomingCode})
+	return matchedNode != nil
+}
@@ -0,0 +1,25 @@
+package pagediff
+
+import (
+	"gdcd/compare-code-examples/data"
+	"testing"
+)
+
+func TestIsUpdatedExampleForSmallEdit(t *testing.T) {
+	if !IsUpdatedExample("db.collection.find(\"foo\")", "db.collection.find(\"food\")") {
+		t.Errorf("FAILED: a one character edit should be an update")
+	}
+}
+
+func TestIsUpdatedExampleForDifferentCode(t *testing.T) {
+	if IsUpdatedExample("1234567890", "abcdefghij") {
+		t.Errorf("FAILED: entirely different code should not be an update")
+	}
+}
+
+func TestIsUpdatedExampleMatchesComparison(t *testing.T) {
+	codeNode, astNode := data.GetUpdatedNodes()
+	if !IsUpdatedExample(codeNode.Code, astNode.Value) {
+		t.Errorf("FAILED: the updated test nodes should be an update")
+	}
+}
@@ -0,0 +1,56 @@
+// Package pagediff is the importable API for GDCD's parsing and diffing core. It extracts code examples from a Snooty
+// page AST and compares them with stored code examples using the same functions the GDCD run uses, so other tools
+// (like audit-cli) get the exact same results without approximating them. Nothing in this package reads or writes the
+// database or calls the LLM.
+package pagediff
+
+import (
+	"common"
+	"gdcd/types"
+)
+
+// CodeExamples are the code example nodes on a page, by directive
+type CodeExamples struct {
+	// Every code node on the page, including the code nodes inside literalinclude and io-code-block directives.
+	// These are the nodes GDCD stores and compares.
+	Code []types.ASTNode
+	// The literalinclude directive nodes on the page
+	LiteralIncludes []types.ASTNode
+	// The io-code-block directive nodes on the page
+	IoCodeBlocks []types.ASTNode
+}
+
+// UpdatedExample is an incoming code example that is a partial match for a stored code example
+type UpdatedExample struct {
+	Existing        common.CodeNode
+	Incoming        types.ASTNode
+	InstancesOnPage int
+}
+
+// NewExample is an incoming code example that doesn't match any stored code example
+type NewExample struct {
+	Incoming        types.ASTNode
+	InstancesOnPage int
+}
+
+// Diff is the result of comparing stored code examples with incoming code examples
+type Diff struct {
+	// Stored code examples that are an exact match for an incoming example, with InstancesOnPage set to the number
+	// of times the example appears on the incoming page
+	Unchanged []common.CodeNode
+	Updated   []UpdatedExample
+	New       []NewExample
+	// Stored code examples that no incoming example matched
+	Removed []common.CodeNode
+}
+
+// PageDiff is the result of comparing a stored page with the incoming version of the page
+type PageDiff struct {
+	Examples CodeExamples
+	// False when GDCD treats the page's code examples as unchanged without comparing them, because the incoming page
+	// has the same number of code examples as the stored page. Diff is empty in that case.
+	Compared bool
+	Diff     Diff
+	// Stored code examples that a previous run already marked as removed. GDCD keeps them on the page unchanged.
+	PreviouslyRemoved []common.CodeNode
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gdcd/types"
+	"gdcd/utils"
+	"os"
+)
+
+func main() {
+	logDir := flag.String("logs", "./logs", "Directory containing run reports, used to resolve run IDs")
+	flag.Usage = func() {
+		fmt.Println("Usage: go run ./report-diff [-logs <dir>] <old-report> <new-report>")
+		fmt.Println("Each report can be a path to a run report file or a run ID.")
+		fmt.Println("Example: go run ./report-diff 2025-09-17-18-00-12 2025-09-24-18-01-30")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	oldReport, err := utils.LoadRunReport(flag.Arg(0), *logDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	newReport, err := utils.LoadRunReport(flag.Arg(1), *logDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	printDiff(utils.DiffRunReports(oldReport, newReport))
+}
+
+func printDiff(diff types.RunReportDiff) {
+	fmt.Printf("=== REPORT DIFF: %s -> %s ===\n", diff.OldRunID, diff.NewRunID)
+
+	fmt.Println("\n=== PROJECTS ===")
+	if len(diff.ProjectsAdded) == 0 && len(diff.ProjectsRemoved) == 0 {
+		fmt.Println("No projects added or removed.")
+	}
+	for _, project := range diff.ProjectsAdded {
+		fmt.Printf("ADDED: %s\n", project)
+	}
+	for _, project := range diff.ProjectsRemoved {
+		fmt.Printf("REMOVED: %s\n", project)
+	}
+
+	fmt.Println("\n=== EXAMPLES PER LANGUAGE ===")
+	if len(diff.LanguageDeltas) == 0 {
+		fmt.Println("No language count changes.")
+	}
+	printLanguageDeltas(diff.LanguageDeltas, "")
+
+	fmt.Println("\n=== ISSUES PER CODE ===")
+	if len(diff.IssueCodeDeltas) == 0 {
+		fmt.Println("No issue count changes.")
+	}
+	for _, delta := range diff.IssueCodeDeltas {
+		fmt.Printf("%s: %d -> %d (%+d)\n", delta.Code, delta.Old, delta.New, delta.Delta)
+	}
+
+	fmt.Println("\n=== PROJECT CHANGES ===")
+	if len(diff.Projects) == 0 {
+		fmt.Println("No changes in projects present in both runs.")
+	}
+	for _, project := range diff.Projects {
+		fmt.Printf("\n[%s] code examples %+d\n", project.ProjectName, project.CodeExampleDelta)
+		for _, page := range project.PagesAdded {
+			fmt.Printf("  PAGE ADDED: %s\n", page)
+		}
+		for _, page := range project.PagesRemoved {
+			fmt.Printf("  PAGE REMOVED: %s\n", page)
+		}
+		printLanguageDeltas(project.LanguageDeltas, "  LANGUAGE ")
+		for _, issue := range project.IssuesIntroduced {
+			fmt.Printf("  ISSUE INTRODUCED: %s\n", issue)
+		}
+		for _, issue := range project.IssuesResolved {
+			fmt.Printf("  ISSUE RESOLVED: %s\n", issue)
+		}
+	}
+}
+
+func printLanguageDeltas(deltas []types.LanguageDelta, prefix string) {
+	for _, delta := range deltas {
+		fmt.Printf("%s%s: %d -> %d (%+d)\n", prefix, delta.Language, delta.Old, delta.New, delta.Delta)
+	}
+}
@@ -0,0 +1,28 @@
+package snooty
+
+import (
+	"sync"
+	"time"
+)
+
+// astParseDuration is the time spent walking page ASTs for code examples since TakeASTParseDuration was last called,
+// so the caller can attribute it to the page it was processing.
+var astParseDuration = struct {
+	sync.Mutex
+	total time.Duration
+}{}
+
+func recordASTParseDuration(duration time.Duration) {
+	astParseDuration.Lock()
+	defer astParseDuration.Unlock()
+	astParseDuration.total += duration
+}
+
+// TakeASTParseDuration returns the time spent walking page ASTs since the last call, and resets it.
+func TakeASTParseDuration() time.Duration {
+	astParseDuration.Lock()
+	defer astParseDuration.Unlock()
+	taken := astParseDuration.total
+	astParseDuration.total = 0
+	return taken
+}
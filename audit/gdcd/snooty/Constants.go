@@ -4,3 +4,21 @@ const (
 	GitHubUsernameNetlify        = "netlify"
 	GitHubUsernameDocsBuilderBot = "docs-builder-bot"
 )
+
+// ExamplesRepoSource maps the path docs pages use to literalinclude files from an examples directory to the repo
+// and path where the canonical source files live.
+type ExamplesRepoSource struct {
+	IncludePathPrefix string
+	Repo              string
+	RepoPathPrefix    string
+}
+
+// ExamplesRepoSources lists the examples directories whose files are managed by the examples-copier. When a
+// literalinclude path starts with one of these prefixes, we record the source repo and path on the code example.
+var ExamplesRepoSources = []ExamplesRepoSource{
+	{
+		IncludePathPrefix: "/code-examples/tested/",
+		Repo:              "mongodb/docs",
+		RepoPathPrefix:    "content/code-examples/tested/",
+	},
+}
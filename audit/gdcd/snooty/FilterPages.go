@@ -0,0 +1,57 @@
+package snooty
+
+import (
+	"gdcd/types"
+	"strings"
+)
+
+// FilterPages drops the project's pages that match the filter's facet or template exclusions, and returns the pages
+// to process and the IDs of the pages it dropped. Deleted pages are always kept, so pages stored before an exclusion
+// was configured are still removed when they're deleted from the docs.
+func FilterPages(pages []types.PageWrapper, project string, filter types.PageFilter) ([]types.PageWrapper, []string) {
+	if len(filter.ExcludeFacets) == 0 && len(filter.ExcludeTemplates) == 0 {
+		return pages, nil
+	}
+	var kept []types.PageWrapper
+	var excludedPageIds []string
+	for _, page := range pages {
+		if !page.Data.Deleted && PageIsExcluded(page.Data, project, filter) {
+			excludedPageIds = append(excludedPageIds, page.Data.PageID)
+			continue
+		}
+		kept = append(kept, page)
+	}
+	return kept, excludedPageIds
+}
+
+// PageIsExcluded reports whether the page has a facet, at any level of sub-facets, or a template that the filter
+// excludes for the project
+func PageIsExcluded(page types.PageMetadata, project string, filter types.PageFilter) bool {
+	for _, rule := range filter.ExcludeTemplates {
+		if ruleAppliesToProject(rule, project) && strings.EqualFold(rule.Value, page.AST.Options.Template) {
+			return true
+		}
+	}
+	for _, rule := range filter.ExcludeFacets {
+		if ruleAppliesToProject(rule, project) && hasFacet(page.Facets, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleAppliesToProject(rule types.PageFilterRule, project string) bool {
+	return rule.Project == "" || rule.Project == project
+}
+
+func hasFacet(facets []types.Facet, rule types.PageFilterRule) bool {
+	for _, facet := range facets {
+		if strings.EqualFold(facet.Category, rule.Category) && strings.EqualFold(facet.Value, rule.Value) {
+			return true
+		}
+		if hasFacet(facet.SubFacets, rule) {
+			return true
+		}
+	}
+	return false
+}
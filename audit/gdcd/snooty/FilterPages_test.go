@@ -0,0 +1,59 @@
+package snooty
+
+import (
+	"encoding/json"
+	"gdcd/types"
+	"reflect"
+	"testing"
+)
+
+func TestFilterPages(t *testing.T) {
+	var reference types.PageWrapper
+	if err := json.Unmarshal(LoadJsonTestDataFromFile("page-with-code-nodes.json"), &reference); err != nil {
+		t.Fatalf("FAILED: could not parse test data: %v", err)
+	}
+	changelog := types.PageWrapper{Data: types.PageMetadata{
+		PageID: "changelog",
+		Facets: []types.Facet{{Category: "genre", Value: "reference", SubFacets: []types.Facet{{Category: "genre", Value: "Changelog"}}}},
+	}}
+	generated := types.PageWrapper{Data: types.PageMetadata{PageID: "generated", AST: types.AST{Options: types.PageOptions{Template: "changelog"}}}}
+	deleted := types.PageWrapper{Data: types.PageMetadata{PageID: "deleted", Deleted: true, AST: types.AST{Options: types.PageOptions{Template: "changelog"}}}}
+	pages := []types.PageWrapper{reference, changelog, generated, deleted}
+
+	tests := []struct {
+		name         string
+		project      string
+		filter       types.PageFilter
+		wantKept     []string
+		wantExcluded []string
+	}{
+		{"Keeps every page without rules", "atlas-cli", types.PageFilter{}, []string{reference.Data.PageID, "changelog", "generated", "deleted"}, nil},
+		{"Excludes pages by facet, including sub-facets", "atlas-cli",
+			types.PageFilter{ExcludeFacets: []types.PageFilterRule{{Category: "genre", Value: "changelog"}}},
+			[]string{reference.Data.PageID, "generated", "deleted"}, []string{"changelog"}},
+		{"Excludes pages by facet from the test data", "atlas-cli",
+			types.PageFilter{ExcludeFacets: []types.PageFilterRule{{Category: "genre", Value: "reference"}}},
+			[]string{"generated", "deleted"}, []string{reference.Data.PageID, "changelog"}},
+		{"Excludes pages by template, but keeps deleted pages", "atlas-cli",
+			types.PageFilter{ExcludeTemplates: []types.PageFilterRule{{Value: "changelog"}}},
+			[]string{reference.Data.PageID, "changelog", "deleted"}, []string{"generated"}},
+		{"Skips rules for other projects", "atlas-cli",
+			types.PageFilter{ExcludeTemplates: []types.PageFilterRule{{Project: "c-driver", Value: "changelog"}}},
+			[]string{reference.Data.PageID, "changelog", "generated", "deleted"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, excluded := FilterPages(pages, tt.project, tt.filter)
+			var keptIds []string
+			for _, page := range kept {
+				keptIds = append(keptIds, page.Data.PageID)
+			}
+			if !reflect.DeepEqual(keptIds, tt.wantKept) {
+				t.Errorf("FAILED: kept %v, want %v", keptIds, tt.wantKept)
+			}
+			if !reflect.DeepEqual(excluded, tt.wantExcluded) {
+				t.Errorf("FAILED: excluded %v, want %v", excluded, tt.wantExcluded)
+			}
+		})
+	}
+}
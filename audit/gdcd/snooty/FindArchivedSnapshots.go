@@ -0,0 +1,57 @@
+package snooty
+
+import (
+	"fmt"
+	"gdcd/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchivedSnapshotDateLayout is the layout of the dated directory names in an archive of Snooty snapshots
+const ArchivedSnapshotDateLayout = "2006-01-02"
+
+// FindArchivedSnapshots lists the archived Snooty Data API dumps in archiveDir. The archive has a directory for each
+// date, named like "2024-03-01", containing one file per project named after the project, such as "node.json" or
+// "node.ndjson". Each file holds the newline-delimited JSON response from the project's documents endpoint on that
+// date. Entries that don't follow this layout are skipped. Snapshots are returned oldest first, then by project name.
+func FindArchivedSnapshots(archiveDir string) ([]types.ArchivedSnapshot, error) {
+	dateDirs, err := os.ReadDir(archiveDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot archive %q: %w", archiveDir, err)
+	}
+	var snapshots []types.ArchivedSnapshot
+	for _, dateDir := range dateDirs {
+		if !dateDir.IsDir() {
+			continue
+		}
+		date, err := time.Parse(ArchivedSnapshotDateLayout, dateDir.Name())
+		if err != nil {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(archiveDir, dateDir.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading snapshot directory %q: %w", dateDir.Name(), err)
+		}
+		for _, file := range files {
+			ext := filepath.Ext(file.Name())
+			if file.IsDir() || (ext != ".json" && ext != ".ndjson") {
+				continue
+			}
+			snapshots = append(snapshots, types.ArchivedSnapshot{
+				ProjectName: strings.TrimSuffix(file.Name(), ext),
+				Date:        date,
+				Path:        filepath.Join(archiveDir, dateDir.Name(), file.Name()),
+			})
+		}
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if !snapshots[i].Date.Equal(snapshots[j].Date) {
+			return snapshots[i].Date.Before(snapshots[j].Date)
+		}
+		return snapshots[i].ProjectName < snapshots[j].ProjectName
+	})
+	return snapshots, nil
+}
@@ -1,8 +1,17 @@
 package snooty
 
-import "gdcd/types"
+import (
+	"gdcd/types"
+	"time"
+)
 
 func GetCodeExamplesFromIncomingData(incomingData types.AST) ([]types.ASTNode, []types.ASTNode, []types.ASTNode) {
+	start := time.Now()
+	defer func() { recordASTParseDuration(time.Since(start)) }()
+	// Record which file each literalinclude code node came from before collecting the code nodes
+	SetLiteralIncludePaths(incomingData.Children)
+	// Record the section each code node appears in, so the DB can link to the example's location on the page
+	SetSectionAnchors(incomingData.Children, "")
 	incomingCodeNodes := FindNodesByType(incomingData.Children, "code")
 	incomingLiteralIncludeNodes := FindNodesByName(incomingData.Children, "literalinclude")
 	incomingIoCodeBlockNodes := FindNodesByName(incomingData.Children, "io-code-block")
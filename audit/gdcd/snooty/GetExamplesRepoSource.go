@@ -0,0 +1,14 @@
+package snooty
+
+import "strings"
+
+// GetExamplesRepoSource returns the repo and repo-relative path of the canonical source file for a literalinclude path,
+// using the ExamplesRepoSources mappings. It returns empty strings if the path isn't in a mapped examples directory.
+func GetExamplesRepoSource(includePath string) (string, string) {
+	for _, source := range ExamplesRepoSources {
+		if strings.HasPrefix(includePath, source.IncludePathPrefix) {
+			return source.Repo, source.RepoPathPrefix + strings.TrimPrefix(includePath, source.IncludePathPrefix)
+		}
+	}
+	return "", ""
+}
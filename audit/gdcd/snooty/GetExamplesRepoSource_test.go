@@ -0,0 +1,24 @@
+package snooty
+
+import "testing"
+
+func TestGetExamplesRepoSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		includePath string
+		wantRepo    string
+		wantPath    string
+	}{
+		{"Maps tested code example to its source repo path", "/code-examples/tested/python/pymongo/crud/insert.py", "mongodb/docs", "content/code-examples/tested/python/pymongo/crud/insert.py"},
+		{"Returns empty strings for includes outside mapped directories", "/includes/usage-examples/connect-sample-app.c", "", ""},
+		{"Returns empty strings for code without an include path", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRepo, gotPath := GetExamplesRepoSource(tt.includePath)
+			if gotRepo != tt.wantRepo || gotPath != tt.wantPath {
+				t.Errorf("GetExamplesRepoSource() = (%v, %v), want (%v, %v)", gotRepo, gotPath, tt.wantRepo, tt.wantPath)
+			}
+		})
+	}
+}
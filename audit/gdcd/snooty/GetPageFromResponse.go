@@ -2,20 +2,26 @@ package snooty
 
 import (
 	"encoding/json"
+	"fmt"
 	"gdcd/types"
 	"log"
 )
 
 // GetPageFromResponse checks the "type" of the newline-delimited JSON blob, and if it is a "page",
 // deserializes it to a page object and returns it. If the JSON blob is a timestamp, metadata, or asset, we ignore it.
+// If the blob can't be parsed, we record a ParseError for the caller to report, and return nil.
 func GetPageFromResponse(line []byte) *types.PageWrapper {
 	var generic map[string]interface{}
 	if err := json.Unmarshal(line, &generic); err != nil {
-		log.Fatalf("Failed to unmarshal line: %v", err)
+		log.Printf("Failed to unmarshal line: %v", err)
+		recordParseError("", fmt.Errorf("failed to unmarshal line: %w", err))
+		return nil
 	}
 	typeField, ok := generic["type"].(string)
 	if !ok {
-		log.Fatalf("Type field is missing or not a string in line: %s", line)
+		log.Printf("Type field is missing or not a string in line: %s", line)
+		recordParseError(pageIDFromGenericLine(generic), fmt.Errorf("type field is missing or not a string"))
+		return nil
 	}
 
 	// Process based on typeField
@@ -33,7 +39,9 @@ func GetPageFromResponse(line []byte) *types.PageWrapper {
 	case "page":
 		var page types.PageWrapper
 		if err := json.Unmarshal(line, &page); err != nil {
-			log.Fatalf("Failed to unmarshal PageMetadata: %v", err)
+			log.Printf("Failed to unmarshal PageMetadata: %v", err)
+			recordParseError(pageIDFromGenericLine(generic), fmt.Errorf("failed to unmarshal page AST: %w", err))
+			return nil
 		}
 		return &page
 		//// Because of the DOP bug duplicating pages with different GitHub usernames, we can pick which username to return.
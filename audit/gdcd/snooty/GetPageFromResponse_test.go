@@ -59,3 +59,34 @@ func TestPageWithWrongUsernameShouldReturnNothing(t *testing.T) {
 		t.Errorf("FAILED: got a page, should have nothing")
 	}
 }
+
+func TestMalformedPageRecordsParseError(t *testing.T) {
+	TakeParseErrors()
+	inputJSON := []byte(`{"type": "page", "data": {"page_id": "docs/landing", "ast": "not an AST"}}`)
+	maybePage := GetPageFromResponse(inputJSON)
+	if maybePage != nil {
+		t.Errorf("FAILED: got a page, want nothing")
+	}
+	parseErrors := TakeParseErrors()
+	if len(parseErrors) != 1 {
+		t.Fatalf("FAILED: got %d parse errors, want 1", len(parseErrors))
+	}
+	if parseErrors[0].PageID != "docs/landing" {
+		t.Errorf("FAILED: got page ID %s, want docs/landing", parseErrors[0].PageID)
+	}
+	if len(TakeParseErrors()) != 0 {
+		t.Errorf("FAILED: parse errors weren't cleared")
+	}
+}
+
+func TestInvalidJSONRecordsParseError(t *testing.T) {
+	TakeParseErrors()
+	maybePage := GetPageFromResponse([]byte(`{"type": "page", `))
+	if maybePage != nil {
+		t.Errorf("FAILED: got a page, want nothing")
+	}
+	parseErrors := TakeParseErrors()
+	if len(parseErrors) != 1 || parseErrors[0].PageID != "" {
+		t.Errorf("FAILED: got %v, want one parse error without a page ID", parseErrors)
+	}
+}
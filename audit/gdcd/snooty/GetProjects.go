@@ -38,7 +38,8 @@ func getLastSegment(rawURL string) string {
 	return seg
 }
 
-func GetProjects(client *http.Client) []types.ProjectDetails {
+// GetProjects returns the projects to parse from the Snooty Data API, narrowed by the given filter
+func GetProjects(client *http.Client, filter types.ProjectFilter) []types.ProjectDetails {
 	env := os.Getenv("APP_ENV")
 	var response types.Response
 	if env == "testing" {
@@ -71,60 +72,7 @@ func GetProjects(client *http.Client) []types.ProjectDetails {
 		}
 	}
 
-	ignoreProjectNames := []string{
-		"atlas-open-service-broker",
-		"realm",
-		"docs-app-services",
-		"datalake",
-		"intellij",
-		"mongodb-vscode",
-		"mms-docs",
-		"visual-studio-extension",
-		"guides",
-		"atlas-app-services",
-		"mongoid-railsmdb",
-		"cluster-sync", // The Snooty Data API currently lists `cluster-sync` and `mongosync` as independent projects. We don't want to process twice, so ignore the `cluster-sync` entry.
-	}
-
-	var collectionsToParse []types.ProjectDetails
-	for _, docsProject := range response.Data {
-		var version string
-		var prodUrl string
-		if !contains(ignoreProjectNames, docsProject.Project) {
-			for _, branch := range docsProject.Branches {
-				if branch.Active && branch.IsStableBranch {
-					// Some of the FullUrl fields have trailing slashes, and some don't. When we use the ProdUrl to make
-					// the PageUrl, we add a slash, so we need to remove a trailing slash if one exists here so we don't
-					// have double slashes.
-					urlWithNoTrailingSlash := removeTrailingSlash(branch.FullUrl)
-
-					// If the docs project has only one branch, we can assume it's unversioned and just use "main" as
-					// the version to fetch documents. i.e. https://www.mongodb.com/docs/mongodb-shell/
-					if len(docsProject.Branches) == 1 {
-						version = "main"
-					} else {
-						// If the docs project has more than one branch, we need to use the active, stable branch name's
-						// last segment of the URL as the version to fetch documents. i.e. https://www.mongodb.com/docs/atlas/operator/current/
-						lastSegment := getLastSegment(urlWithNoTrailingSlash)
-						version = lastSegment
-					}
-					prodUrl = urlWithNoTrailingSlash
-					break
-				}
-			}
-			// If the project does not have an active, stable branch, we don't want to try to get the project details
-			if version != "" {
-				collectionDetails := types.ProjectDetails{
-					ProjectName: docsProject.Project,
-					Version:     version,
-					ProdUrl:     prodUrl,
-				}
-				collectionsToParse = append(collectionsToParse, collectionDetails)
-			} else {
-				log.Printf("Skipping project %s because it does not have an active, stable branch", docsProject.Project)
-			}
-		}
-	}
+	collectionsToParse := SelectProjects(response, filter)
 	log.Println("Found ", len(collectionsToParse), "collections to parse from the Snooty Data API")
 	return collectionsToParse
 }
@@ -9,7 +9,7 @@ import (
 )
 
 func TestStubbedProjectsReturnTheCorrectNumberOfProjects(t *testing.T) {
-	projectDocuments := GetProjects(&http.Client{Timeout: 5 * time.Second})
+	projectDocuments := GetProjects(&http.Client{Timeout: 5 * time.Second}, types.ProjectFilter{})
 	projectDocumentCount := len(projectDocuments)
 	expectedProjectDocumentCount := 1
 	if projectDocumentCount != expectedProjectDocumentCount {
@@ -18,7 +18,7 @@ func TestStubbedProjectsReturnTheCorrectNumberOfProjects(t *testing.T) {
 }
 
 func TestStubbedProjectsReturnCorrectProjectDetails(t *testing.T) {
-	projectDocuments := GetProjects(&http.Client{Timeout: 5 * time.Second})
+	projectDocuments := GetProjects(&http.Client{Timeout: 5 * time.Second}, types.ProjectFilter{})
 	expectedProjectDocument := types.ProjectDetails{
 		ProjectName: "spark-connector",
 		Version:     "v10.4",
@@ -25,6 +25,7 @@ func MakeCodeNodeFromSnootyAST(snootyNode types.ASTNode, llm *ollama.LLM, ctx co
 		category = maybeCategory
 		llmCategorized = false
 	}
+	sourceRepo, sourcePath := GetExamplesRepoSource(snootyNode.IncludePath)
 	return common.CodeNode{
 		Code:           whiteSpaceTrimmedCode,
 		Language:       language,
@@ -33,5 +34,9 @@ func MakeCodeNodeFromSnootyAST(snootyNode types.ASTNode, llm *ollama.LLM, ctx co
 		SHA256Hash:     hashString,
 		LLMCategorized: llmCategorized,
 		DateAdded:      time.Now(),
+		IncludePath:    snootyNode.IncludePath,
+		SourceRepo:     sourceRepo,
+		SourcePath:     sourcePath,
+		Anchor:         snootyNode.Anchor,
 	}
 }
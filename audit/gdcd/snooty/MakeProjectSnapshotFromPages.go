@@ -0,0 +1,52 @@
+package snooty
+
+import (
+	"common"
+	add_code_examples "gdcd/add-code-examples"
+	"gdcd/types"
+	"gdcd/utils"
+	"sort"
+)
+
+// MakeProjectSnapshotFromPages computes a project snapshot directly from Snooty pages, without reading the database:
+// the page IDs, the code example count, and the code example count for each language. Pages flagged as deleted are
+// skipped. The counts match what GetProjectSnapshot would read from Atlas after a run over the same pages, so
+// snapshots from archived Snooty responses can be compared with snapshots from runs.
+func MakeProjectSnapshotFromPages(pages []types.PageWrapper) types.ProjectSnapshot {
+	snapshot := types.ProjectSnapshot{
+		LanguageCounts: make(map[string]int),
+	}
+	canonicalLanguages := make(map[string]bool)
+	for _, language := range common.CanonicalLanguages {
+		canonicalLanguages[language] = true
+	}
+	for _, page := range pages {
+		if page.Data.Deleted {
+			continue
+		}
+		codeNodes, literalIncludeNodes, ioCodeBlockNodes := GetCodeExamplesFromIncomingData(page.Data.AST)
+		for _, node := range codeNodes {
+			language := add_code_examples.GetNormalizedLanguageFromASTNode(node)
+			if !canonicalLanguages[language] {
+				language = common.Undefined
+			}
+			snapshot.LanguageCounts[language]++
+		}
+		snapshot.PageIDs = append(snapshot.PageIDs, utils.ConvertSnootyPageIdToAtlasPageId(page.Data.PageID))
+		snapshot.CodeExampleCount += len(codeNodes)
+		snapshot.Counter.IncomingCodeNodesCount += len(codeNodes)
+		snapshot.Counter.IncomingLiteralIncludeCount += len(literalIncludeNodes)
+		snapshot.Counter.IncomingIoCodeBlockCount += len(ioCodeBlockNodes)
+	}
+	snapshot.Counter.TotalCurrentPageCount = len(snapshot.PageIDs)
+	// Pages in Atlas have an entry for every canonical language, even if the count is 0
+	if len(snapshot.PageIDs) > 0 {
+		for language := range canonicalLanguages {
+			if _, exists := snapshot.LanguageCounts[language]; !exists {
+				snapshot.LanguageCounts[language] = 0
+			}
+		}
+	}
+	sort.Strings(snapshot.PageIDs)
+	return snapshot
+}
@@ -0,0 +1,70 @@
+package snooty
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFindArchivedSnapshots(t *testing.T) {
+	archiveDir := t.TempDir()
+	for _, file := range []string{"2024-03-01/node.json", "2024-03-01/c.ndjson", "2024-01-15/c.json", "2024-01-15/notes.txt", "latest/c.json"} {
+		path := filepath.Join(archiveDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshots, err := FindArchivedSnapshots(archiveDir)
+	if err != nil {
+		t.Fatalf("FindArchivedSnapshots failed: %v", err)
+	}
+	want := []struct {
+		project string
+		date    string
+	}{{"c", "2024-01-15"}, {"c", "2024-03-01"}, {"node", "2024-03-01"}}
+	if len(snapshots) != len(want) {
+		t.Fatalf("FAILED: got %d snapshots, want %d: %+v", len(snapshots), len(want), snapshots)
+	}
+	for i, w := range want {
+		date, _ := time.Parse(ArchivedSnapshotDateLayout, w.date)
+		if snapshots[i].ProjectName != w.project || !snapshots[i].Date.Equal(date) {
+			t.Errorf("FAILED: snapshot %d is %s on %s, want %s on %s", i, snapshots[i].ProjectName, snapshots[i].Date.Format(ArchivedSnapshotDateLayout), w.project, w.date)
+		}
+	}
+
+	if _, err := FindArchivedSnapshots(filepath.Join(archiveDir, "missing")); err == nil {
+		t.Error("FAILED: expected an error for a missing archive")
+	}
+}
+
+func TestMakeProjectSnapshotFromPages(t *testing.T) {
+	pages := ReadPagesForGitHubUser(*bufio.NewReader(bytes.NewReader(LoadJsonTestDataFromFile("c-driver-project-documents-stub.json"))))
+	snapshot := MakeProjectSnapshotFromPages(pages)
+
+	if len(snapshot.PageIDs) != len(pages) || snapshot.Counter.TotalCurrentPageCount != len(pages) {
+		t.Errorf("FAILED: got %d page IDs and a page count of %d, want %d", len(snapshot.PageIDs), snapshot.Counter.TotalCurrentPageCount, len(pages))
+	}
+	languageTotal := 0
+	for _, count := range snapshot.LanguageCounts {
+		languageTotal += count
+	}
+	if snapshot.CodeExampleCount == 0 || languageTotal != snapshot.CodeExampleCount {
+		t.Errorf("FAILED: got %d code examples and %d across languages, want the same non-zero count", snapshot.CodeExampleCount, languageTotal)
+	}
+	if _, exists := snapshot.LanguageCounts["python"]; !exists {
+		t.Error("FAILED: want an entry for every canonical language")
+	}
+
+	// Deleted pages aren't counted
+	pages[0].Data.Deleted = true
+	if got := MakeProjectSnapshotFromPages(pages); len(got.PageIDs) != len(pages)-1 {
+		t.Errorf("FAILED: got %d page IDs with a deleted page, want %d", len(got.PageIDs), len(pages)-1)
+	}
+}
@@ -0,0 +1,45 @@
+package snooty
+
+import (
+	"sync"
+)
+
+// ParseError is a line of a Snooty Data API response that couldn't be parsed. PageID is empty if the line was too
+// malformed to tell which page it was for.
+type ParseError struct {
+	PageID string
+	Err    error
+}
+
+// parseErrors holds the parse errors recorded since TakeParseErrors was last called, so the caller can report them
+// against the project whose pages it was reading instead of stopping the run.
+var parseErrors = struct {
+	sync.Mutex
+	errors []ParseError
+}{}
+
+func recordParseError(pageID string, err error) {
+	parseErrors.Lock()
+	defer parseErrors.Unlock()
+	parseErrors.errors = append(parseErrors.errors, ParseError{PageID: pageID, Err: err})
+}
+
+// TakeParseErrors returns the parse errors recorded since the last call, and clears them.
+func TakeParseErrors() []ParseError {
+	parseErrors.Lock()
+	defer parseErrors.Unlock()
+	taken := parseErrors.errors
+	parseErrors.errors = nil
+	return taken
+}
+
+// pageIDFromGenericLine returns the page ID of a page line that was unmarshalled to a generic map, or an empty string
+// if it doesn't have one.
+func pageIDFromGenericLine(generic map[string]interface{}) string {
+	data, ok := generic["data"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	pageID, _ := data["page_id"].(string)
+	return pageID
+}
@@ -6,6 +6,7 @@ import (
 	"gdcd/types"
 	"io"
 	"log"
+	"time"
 )
 
 // ReadPagesForGitHubUser creates a slice of []types.PageWrapper with logic to avoid double-counting pages as a workaround
@@ -13,7 +14,9 @@ import (
 func ReadPagesForGitHubUser(reader bufio.Reader) []types.PageWrapper {
 	var allIncomingDocsPages []types.PageWrapper
 	for {
+		start := time.Now()
 		line, err := reader.ReadBytes('\n') // Read until newline
+		fetchDuration := time.Since(start)
 		if err != nil {
 			if err == io.EOF {
 				break
@@ -24,8 +27,11 @@ func ReadPagesForGitHubUser(reader bufio.Reader) []types.PageWrapper {
 		trimmedLine := bytes.TrimSpace(line)
 		var maybePage *types.PageWrapper
 		if len(trimmedLine) > 0 { // Process non-empty lines
+			start = time.Now()
 			maybePage = GetPageFromResponse(trimmedLine)
 			if maybePage != nil {
+				maybePage.FetchDuration = fetchDuration
+				maybePage.ParseDuration = time.Since(start)
 				allIncomingDocsPages = append(allIncomingDocsPages, *maybePage)
 			}
 		}
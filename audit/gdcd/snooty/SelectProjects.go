@@ -0,0 +1,94 @@
+package snooty
+
+import (
+	"common"
+	"gdcd/types"
+	"log"
+	"strings"
+)
+
+// IgnoreProjectNames lists projects GDCD never processes unless they're explicitly allowed in the project filter
+var IgnoreProjectNames = []string{
+	"atlas-open-service-broker",
+	"realm",
+	"docs-app-services",
+	"datalake",
+	"intellij",
+	"mongodb-vscode",
+	"mms-docs",
+	"visual-studio-extension",
+	"guides",
+	"atlas-app-services",
+	"mongoid-railsmdb",
+	"cluster-sync", // The Snooty Data API currently lists `cluster-sync` and `mongosync` as independent projects. We don't want to process twice, so ignore the `cluster-sync` entry.
+}
+
+// SelectProjects applies the project filter to the Snooty Data API projects list and returns the details we need to
+// fetch each remaining project's pages.
+func SelectProjects(response types.Response, filter types.ProjectFilter) []types.ProjectDetails {
+	var collectionsToParse []types.ProjectDetails
+	for _, docsProject := range response.Data {
+		if !projectMatchesFilter(docsProject.Project, filter) {
+			continue
+		}
+		var version string
+		var prodUrl string
+		for _, branch := range docsProject.Branches {
+			if (branch.Active || filter.IncludeInactiveBranches) && branch.IsStableBranch {
+				// Some of the FullUrl fields have trailing slashes, and some don't. When we use the ProdUrl to make
+				// the PageUrl, we add a slash, so we need to remove a trailing slash if one exists here so we don't
+				// have double slashes.
+				urlWithNoTrailingSlash := removeTrailingSlash(branch.FullUrl)
+
+				// If the docs project has only one branch, we can assume it's unversioned and just use "main" as
+				// the version to fetch documents. i.e. https://www.mongodb.com/docs/mongodb-shell/
+				if len(docsProject.Branches) == 1 {
+					version = "main"
+				} else {
+					// If the docs project has more than one branch, we need to use the active, stable branch name's
+					// last segment of the URL as the version to fetch documents. i.e. https://www.mongodb.com/docs/atlas/operator/current/
+					lastSegment := getLastSegment(urlWithNoTrailingSlash)
+					version = lastSegment
+				}
+				prodUrl = urlWithNoTrailingSlash
+				break
+			}
+		}
+		// If the project does not have an active, stable branch, we don't want to try to get the project details
+		if version != "" {
+			collectionDetails := types.ProjectDetails{
+				ProjectName: docsProject.Project,
+				Version:     version,
+				ProdUrl:     prodUrl,
+			}
+			collectionsToParse = append(collectionsToParse, collectionDetails)
+		} else {
+			log.Printf("Skipping project %s because it does not have an active, stable branch", docsProject.Project)
+		}
+	}
+	return collectionsToParse
+}
+
+// projectMatchesFilter reports whether a project passes the deny, allow, and product family filters
+func projectMatchesFilter(project string, filter types.ProjectFilter) bool {
+	if contains(filter.DenyProjects, project) {
+		return false
+	}
+	if len(filter.AllowProjects) > 0 {
+		if !contains(filter.AllowProjects, project) {
+			return false
+		}
+	} else if contains(IgnoreProjectNames, project) {
+		return false
+	}
+	if len(filter.ProductFamilies) > 0 {
+		productName := common.GetProductInfo(project).ProductName
+		for _, family := range filter.ProductFamilies {
+			if strings.EqualFold(family, productName) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package snooty
+
+import (
+	"gdcd/types"
+	"reflect"
+	"testing"
+)
+
+func makeProjectsResponseForTesting() types.Response {
+	stableBranch := func(url string, active bool) []types.Branch {
+		return []types.Branch{{GitBranchName: "master", Active: active, FullUrl: url, IsStableBranch: true}}
+	}
+	return types.Response{Data: []types.DocsProject{
+		{Project: "cloud-docs", Branches: stableBranch("https://mongodb.com/docs/atlas/", true)},
+		{Project: "node", Branches: stableBranch("https://mongodb.com/docs/drivers/node/current/", true)},
+		{Project: "compass", Branches: stableBranch("https://mongodb.com/docs/compass/current", false)},
+		{Project: "realm", Branches: stableBranch("https://mongodb.com/docs/realm/", true)},
+	}}
+}
+
+func selectedProjectNames(projects []types.ProjectDetails) []string {
+	var names []string
+	for _, project := range projects {
+		names = append(names, project.ProjectName)
+	}
+	return names
+}
+
+func TestSelectProjects(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter types.ProjectFilter
+		want   []string
+	}{
+		{"default skips ignored and inactive projects", types.ProjectFilter{}, []string{"cloud-docs", "node"}},
+		{"product family", types.ProjectFilter{ProductFamilies: []string{"drivers"}}, []string{"node"}},
+		{"allow list overrides ignore list", types.ProjectFilter{AllowProjects: []string{"realm", "node"}}, []string{"node", "realm"}},
+		{"deny wins over allow", types.ProjectFilter{AllowProjects: []string{"node"}, DenyProjects: []string{"node"}}, nil},
+		{"include inactive branches", types.ProjectFilter{IncludeInactiveBranches: true}, []string{"cloud-docs", "node", "compass"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectedProjectNames(SelectProjects(makeProjectsResponseForTesting(), tt.filter))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FAILED: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package snooty
+
+import "gdcd/types"
+
+// SetLiteralIncludePaths recursively finds `literalinclude` nodes and sets the IncludePath on their child `code` nodes
+// to the path of the included file. It updates the nodes in place, so code nodes found afterward carry the path.
+func SetLiteralIncludePaths(nodes []types.ASTNode) {
+	for i := range nodes {
+		if nodes[i].Name == "literalinclude" && len(nodes[i].Argument) > 0 {
+			// The first argument of a literalinclude is the path to the included file
+			includePath := nodes[i].Argument[0].Value
+			for j := range nodes[i].Children {
+				if nodes[i].Children[j].Type == "code" {
+					nodes[i].Children[j].IncludePath = includePath
+				}
+			}
+		}
+		SetLiteralIncludePaths(nodes[i].Children)
+	}
+}
@@ -0,0 +1,35 @@
+package snooty
+
+import (
+	"gdcd/types"
+	"testing"
+)
+
+func TestSetLiteralIncludePathsShouldSetPathOnLiteralIncludeCodeNodes(t *testing.T) {
+	inputNodes := LoadASTNodeTestDataFromFile(t, "page-with-code-nodes.json")
+	SetLiteralIncludePaths(inputNodes)
+	codeNodes := FindNodesByType(inputNodes, "code")
+	var nodesWithIncludePath int
+	for _, node := range codeNodes {
+		if node.IncludePath != "" {
+			nodesWithIncludePath++
+			want := "/includes/usage-examples/connect-sample-app.c"
+			if node.IncludePath != want {
+				t.Errorf("FAILED: got include path %s, want %s", node.IncludePath, want)
+			}
+		}
+	}
+	// The test data has one literalinclude, and the other code nodes are code-blocks
+	if nodesWithIncludePath != 1 {
+		t.Errorf("FAILED: got %d code nodes with an include path, want 1", nodesWithIncludePath)
+	}
+}
+
+func TestSetLiteralIncludePathsShouldIgnoreLiteralIncludeWithoutFilepath(t *testing.T) {
+	node := MakeLiteralIncludeNodeForTesting(true, "c", false)
+	nodes := []types.ASTNode{node}
+	SetLiteralIncludePaths(nodes)
+	if nodes[0].Children[0].IncludePath != "" {
+		t.Errorf("FAILED: got include path %s, want empty string", nodes[0].Children[0].IncludePath)
+	}
+}
@@ -0,0 +1,24 @@
+package snooty
+
+import "gdcd/types"
+
+// SetSectionAnchors recursively finds `code` nodes and sets their Anchor to the ID of the heading of the nearest
+// enclosing `section`. Appending the anchor to the page URL as a fragment links to the section where the example
+// appears. Pass an empty anchor for the top-level nodes of a page. It updates the nodes in place.
+func SetSectionAnchors(nodes []types.ASTNode, anchor string) {
+	for i := range nodes {
+		childAnchor := anchor
+		if nodes[i].Type == "section" {
+			for _, child := range nodes[i].Children {
+				if child.Type == "heading" && child.ID != "" {
+					childAnchor = child.ID
+					break
+				}
+			}
+		}
+		if nodes[i].Type == "code" {
+			nodes[i].Anchor = anchor
+		}
+		SetSectionAnchors(nodes[i].Children, childAnchor)
+	}
+}
@@ -0,0 +1,44 @@
+package snooty
+
+import (
+	"gdcd/types"
+	"testing"
+)
+
+func TestSetSectionAnchorsShouldSetNearestSectionHeadingID(t *testing.T) {
+	inputNodes := LoadASTNodeTestDataFromFile(t, "page-with-code-nodes.json")
+	SetSectionAnchors(inputNodes, "")
+	codeNodes := FindNodesByType(inputNodes, "code")
+	want := []string{
+		"overview",
+		"local-deployment",
+		"atlas",
+		"replica-set",
+		"enable-tls",
+		"enable-tls",
+		"disable-hostname-verification",
+		"disable-hostname-verification",
+		"compression-algorithms",
+		"compression-algorithms",
+		"zlib-compression-level",
+		"zlib-compression-level",
+		"server-selection",
+		"stable-api",
+	}
+	if len(codeNodes) != len(want) {
+		t.Fatalf("FAILED: got %d code nodes, want %d", len(codeNodes), len(want))
+	}
+	for i, node := range codeNodes {
+		if node.Anchor != want[i] {
+			t.Errorf("FAILED: code node %d got anchor %s, want %s", i, node.Anchor, want[i])
+		}
+	}
+}
+
+func TestSetSectionAnchorsShouldLeaveAnchorEmptyOutsideSections(t *testing.T) {
+	nodes := []types.ASTNode{{Type: "code", Lang: "c", Value: "printf(\"hello\");"}}
+	SetSectionAnchors(nodes, "")
+	if nodes[0].Anchor != "" {
+		t.Errorf("FAILED: got anchor %s, want empty string", nodes[0].Anchor)
+	}
+}
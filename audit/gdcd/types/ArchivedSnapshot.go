@@ -0,0 +1,11 @@
+package types
+
+import "time"
+
+// ArchivedSnapshot is an archived dump of the Snooty Data API documents response for one project, taken on a given
+// date. Backfill reads these to reconstruct project metrics for dates before GDCD started recording runs.
+type ArchivedSnapshot struct {
+	ProjectName string
+	Date        time.Time
+	Path        string
+}
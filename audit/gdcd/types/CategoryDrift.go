@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+// CategoryHistoryEntry is the category a snippet was last assigned, and how. GDCD keeps one entry per unique snippet
+// across runs so it can tell when a snippet's category changes.
+type CategoryHistoryEntry struct {
+	Category       string    `json:"category"`
+	Model          string    `json:"model"` // The LLM model, or "string-match" if a pattern categorized the snippet
+	LLMCategorized bool      `json:"llm_categorized"`
+	Language       string    `json:"language"`
+	RunID          string    `json:"run_id"`
+	CategorizedAt  time.Time `json:"categorized_at"`
+}
+
+// CategoryDrift is a snippet whose category changed since an earlier run, so taxonomy or model changes can be reviewed
+// before they rewrite the dataset.
+type CategoryDrift struct {
+	SHA256Hash  string `json:"sha_256_hash"` // Matches the sha_256_hash of the snippet's code nodes
+	Language    string `json:"language"`
+	OldCategory string `json:"old_category"`
+	NewCategory string `json:"new_category"`
+	OldModel    string `json:"old_model"`
+	NewModel    string `json:"new_model"`
+	OldRunID    string `json:"old_run_id"`
+	Preview     string `json:"preview"` // The start of the snippet
+}
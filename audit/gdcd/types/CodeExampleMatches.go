@@ -0,0 +1,20 @@
+package types
+
+import "common"
+
+// CodeExampleMatches is the result of matching the code examples coming in on a page against the code examples already
+// stored for the page. Every incoming AST node lands in exactly one of Unchanged (as its matching code node), Updated,
+// or New, and every existing code node that no incoming node matched lands in Removed.
+type CodeExampleMatches struct {
+	// Existing code nodes that are an exact match for an incoming AST node, with InstancesOnPage set to the number
+	// of times the code appears on the incoming page
+	Unchanged []common.CodeNode
+	// Incoming AST nodes that are a partial match for an existing code node
+	Updated []ASTNodeWrapper
+	// The existing code node each updated AST node matched, keyed by the SHA256 hash of the AST node's code
+	UpdatedSha256ToCodeNodeMap map[string]common.CodeNode
+	// Incoming AST nodes that don't match any existing code node
+	New []ASTNodeWrapper
+	// Existing code nodes that no incoming AST node matched
+	Removed []common.CodeNode
+}
@@ -0,0 +1,44 @@
+package types
+
+import "time"
+
+// CopierAuditConfig says where to read the examples-copier's audit records, to cross-check the example files docs
+// pages include against what the copier has synced
+type CopierAuditConfig struct {
+	URI                string // The copier's MONGO_URI
+	Database           string // The copier's AUDIT_DATABASE. Empty turns the cross-check off.
+	EventsCollection   string // The copier's AUDIT_COLLECTION, where deprecated files are recorded
+	WriteLogCollection string // The copier's WRITE_LOG_COLLECTION, where the files each commit and PR wrote are recorded
+}
+
+// Enabled reports whether the cross-check runs
+func (c CopierAuditConfig) Enabled() bool {
+	return c.Database != ""
+}
+
+// Statuses of a file in the examples-copier's audit records
+const (
+	CopierFileSynced     = "synced"     // The copier last wrote the file
+	CopierFileDeprecated = "deprecated" // The file's source was deleted, so the copier added it to a deprecation file
+	CopierFileRemoved    = "removed"    // The copier last deleted the file from the destination
+)
+
+// CopierFileKey identifies a file in a repo the examples-copier writes to
+type CopierFileKey struct {
+	Repo string
+	Path string
+}
+
+// CopierFileRecord is one thing the examples-copier's audit records say happened to a file
+type CopierFileRecord struct {
+	CopierFileKey
+	Status    string
+	Timestamp time.Time
+}
+
+// IncludedExample is a literalinclude of a file in an examples directory the examples-copier manages
+type IncludedExample struct {
+	PageID      string
+	IncludePath string
+	CopierFileKey
+}
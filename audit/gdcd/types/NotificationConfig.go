@@ -0,0 +1,10 @@
+package types
+
+// NotificationConfig controls the per-project run summaries GDCD posts to the docs team's Slack channel.
+type NotificationConfig struct {
+	WebhookURL string // Slack incoming webhook URL. Summaries aren't posted when this is empty.
+	Channel    string // Overrides the webhook's default channel when set
+	// MinChanges is the minimum number of page and code example changes a project needs before it gets a summary.
+	// Projects with issues always get a summary.
+	MinChanges int
+}
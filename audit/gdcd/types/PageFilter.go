@@ -0,0 +1,21 @@
+package types
+
+// PageFilter skips pages that aren't worth counting, like machine-generated changelogs full of code-formatted text
+// that aren't real examples. Skipped pages are dropped as if the Snooty Data API hadn't returned them, so their code
+// examples don't count toward a project's metrics. An empty filter keeps every page.
+type PageFilter struct {
+	// ExcludeFacets skips pages with a matching facet, such as genre=changelog
+	ExcludeFacets []PageFilterRule
+	// ExcludeTemplates skips pages built with a matching Snooty template, such as changelog
+	ExcludeTemplates []PageFilterRule
+}
+
+// PageFilterRule matches pages by a facet or template value, optionally in a single project.
+type PageFilterRule struct {
+	// Project limits the rule to one project. An empty project applies the rule to every project.
+	Project string
+	// Category is the facet category, such as "genre". It's empty for template rules.
+	Category string
+	// Value is the facet value or template name. Matching is case-insensitive.
+	Value string
+}
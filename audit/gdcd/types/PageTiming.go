@@ -0,0 +1,39 @@
+package types
+
+// PageStage is a stage of processing a page, used to profile where a run spends its time
+type PageStage int
+
+const (
+	FetchStage PageStage = iota // Reading the page from the Snooty Data API response
+	ParseStage                  // Unmarshalling the page and walking its AST for code examples
+	LLMStage                    // Categorizing the page's code examples with the LLM
+	DBStage                     // Reading and writing the page in Atlas, including its share of the project's batch write
+	OtherStage                  // Everything else, such as comparing incoming code examples to existing ones
+	pageStageCount
+)
+
+// PageStageCount is the number of page stages
+const PageStageCount = int(pageStageCount)
+
+// PageTiming is how long a run spent processing one page, by stage, in milliseconds
+type PageTiming struct {
+	PageID  string `json:"page_id"`
+	TotalMs int64  `json:"total_ms"`
+	FetchMs int64  `json:"fetch_ms"`
+	ParseMs int64  `json:"parse_ms"`
+	LLMMs   int64  `json:"llm_ms"`
+	DBMs    int64  `json:"db_ms"`
+	OtherMs int64  `json:"other_ms"`
+}
+
+// ProjectTiming is how long a run spent processing a project's pages, by stage, in milliseconds, and the slowest pages
+type ProjectTiming struct {
+	Pages        int          `json:"pages"`
+	TotalMs      int64        `json:"total_ms"`
+	FetchMs      int64        `json:"fetch_ms"`
+	ParseMs      int64        `json:"parse_ms"`
+	LLMMs        int64        `json:"llm_ms"`
+	DBMs         int64        `json:"db_ms"`
+	OtherMs      int64        `json:"other_ms"`
+	SlowestPages []PageTiming `json:"slowest_pages,omitempty"`
+}
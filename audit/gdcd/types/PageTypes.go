@@ -3,11 +3,15 @@ package types
 import (
 	"encoding/json"
 	"errors"
+	"time"
 )
 
 type PageWrapper struct {
 	Type string       `json:"type"`
 	Data PageMetadata `json:"data"`
+	// How long reading the page from the Snooty Data API response and unmarshalling it took, for profiling
+	FetchDuration time.Duration `json:"-"`
+	ParseDuration time.Duration `json:"-"`
 }
 
 // Position represents the line position in the text.
@@ -55,6 +59,12 @@ type ASTNode struct {
 	EmphasizeLines EmphasizeLines         `json:"emphasize_lines,omitempty"`
 	LineNumbers    bool                   `json:"lineos,omitempty"`
 	Category       string                 `json:"category,omitempty"`
+	// IncludePath is not part of the Snooty data. GDCD sets it on `code` nodes that come from a `literalinclude`
+	// to the path of the included file, as written in the directive.
+	IncludePath string `json:"-"`
+	// Anchor is not part of the Snooty data. GDCD sets it on `code` nodes to the ID of the heading of the nearest
+	// enclosing section, which is the fragment that links to the example's location on the rendered page.
+	Anchor string `json:"-"`
 }
 
 // ToctreeEntry details entries contained within a toctree.
@@ -66,6 +76,8 @@ type ToctreeEntry struct {
 // PageOptions holds various configuration settings for the page.
 type PageOptions struct {
 	Headings []Heading `json:"headings"`
+	// Template is the Snooty template the page is built with, if it sets one, such as "changelog"
+	Template string `json:"template,omitempty"`
 }
 
 // Heading encapsulates heading information, including depth and title.
@@ -82,6 +94,7 @@ type PageMetadata struct {
 	PageID         string  `json:"page_id"`
 	AST            AST     `json:"ast"`
 	BuildID        string  `json:"build_id"`
+	Facets         []Facet `json:"facets,omitempty"`
 	CreatedAt      string  `json:"created_at"`
 	Deleted        bool    `json:"deleted"`
 	Filename       string  `json:"filename"`
@@ -89,6 +102,15 @@ type PageMetadata struct {
 	UpdatedAt      string  `json:"updated_at"`
 }
 
+// Facet is a taxonomy tag on the page, such as genre=reference. Facets can have sub-facets, such as the versions of a
+// target product.
+type Facet struct {
+	Category    string  `json:"category"`
+	Value       string  `json:"value"`
+	SubFacets   []Facet `json:"sub_facets,omitempty"`
+	DisplayName string  `json:"display_name,omitempty"`
+}
+
 // EmphasizeLines custom type to hold explicit line numbers
 type EmphasizeLines []int
 
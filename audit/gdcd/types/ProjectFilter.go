@@ -0,0 +1,18 @@
+package types
+
+// ProjectFilter narrows the projects GDCD discovers from the Snooty Data API. Filters are applied at discovery time,
+// so projects that don't match are never fetched or parsed. An empty filter keeps every project the tool processes
+// by default.
+type ProjectFilter struct {
+	// ProductFamilies keeps only projects whose product name (from the `common` product mappings) is in this list,
+	// such as "Atlas" or "Drivers". Matching is case-insensitive.
+	ProductFamilies []string
+	// AllowProjects keeps only the named projects. Allowed projects are processed even if they're in the built-in
+	// ignore list.
+	AllowProjects []string
+	// DenyProjects skips the named projects, in addition to the built-in ignore list. Deny wins over allow.
+	DenyProjects []string
+	// IncludeInactiveBranches processes projects whose stable branch is no longer marked active. By default, only
+	// projects with an active, stable branch are processed.
+	IncludeInactiveBranches bool
+}
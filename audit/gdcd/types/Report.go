@@ -15,6 +15,7 @@ type ProjectCounts struct {
 	RemovedPagesCount            int
 	TotalCurrentPageCount        int
 	NewAppliedUsageExamplesCount int
+	ExcludedPagesCount           int // pages the page filter skipped by facet or template
 }
 
 // ChangeType represents the type of change.
@@ -45,6 +46,29 @@ const (
 	CodeNodeCountIssue
 	PageCountIssue
 	PageNotRemovedIssue
+	ProjectCodeNodeCountIssue
+	ASTParseErrorIssue
+	LLMTimeoutIssue
+	LLMErrorIssue
+	IncludesDeprecatedExampleIssue
+	IncludesUnsyncedExampleIssue
+)
+
+// IssueCode is the stable, machine-readable name of an IssueType. Run reports record issues by code, so
+// downstream tooling can alert on and count specific classes of issues.
+type IssueCode string
+
+const (
+	IssueCodePagesNotFound                IssueCode = "PagesNotFound"
+	IssueCodeCodeNodeCountMismatch        IssueCode = "CodeNodeCountMismatch"
+	IssueCodePageCountMismatch            IssueCode = "PageCountMismatch"
+	IssueCodePageNotRemoved               IssueCode = "PageNotRemoved"
+	IssueCodeProjectCodeNodeCountMismatch IssueCode = "ProjectCodeNodeCountMismatch"
+	IssueCodeASTParseError                IssueCode = "ASTParseError"
+	IssueCodeLLMTimeout                   IssueCode = "LLMTimeout"
+	IssueCodeLLMError                     IssueCode = "LLMError"
+	IssueCodeIncludesDeprecatedExample    IssueCode = "IncludesDeprecatedExample"
+	IssueCodeIncludesUnsyncedExample      IssueCode = "IncludesUnsyncedExample"
 )
 
 // Change represents a change happening to data.
@@ -54,8 +78,19 @@ type Change struct {
 }
 
 type Issue struct {
-	Type IssueType   // The type of change
-	Data interface{} // The data associated with the issue
+	Type    IssueType    // The type of issue
+	Message string       // A human-readable description of the issue
+	Context IssueContext // Where the issue was found, and what was expected
+}
+
+// IssueContext is the structured detail of an issue. Fields that don't apply to the issue type are empty.
+type IssueContext struct {
+	ProjectName string `json:"project,omitempty"`
+	PageID      string `json:"page_id,omitempty"`
+	Expected    *int   `json:"expected,omitempty"`
+	Actual      *int   `json:"actual,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Detail      string `json:"detail,omitempty"` // Anything else that identifies the issue, such as a snippet hash
 }
 
 // String returns a string representation of the ChangeType for easier readability.
@@ -65,7 +100,12 @@ func (ct ChangeType) String() string {
 
 // String returns a string representation of the IssueType for easier readability.
 func (it IssueType) String() string {
-	return [...]string{"Pages not found", "Code node count issue", "Page count issue", "Page not removed issue"}[it]
+	return [...]string{"Pages not found", "Code node count issue", "Page count issue", "Page not removed issue", "Project code node count issue", "AST parse error", "LLM timeout", "LLM error", "Includes deprecated example", "Includes unsynced example"}[it]
+}
+
+// Code returns the IssueCode for the IssueType.
+func (it IssueType) Code() IssueCode {
+	return [...]IssueCode{IssueCodePagesNotFound, IssueCodeCodeNodeCountMismatch, IssueCodePageCountMismatch, IssueCodePageNotRemoved, IssueCodeProjectCodeNodeCountMismatch, IssueCodeASTParseError, IssueCodeLLMTimeout, IssueCodeLLMError, IssueCodeIncludesDeprecatedExample, IssueCodeIncludesUnsyncedExample}[it]
 }
 
 type ProjectReport struct {
@@ -73,4 +113,5 @@ type ProjectReport struct {
 	Changes     []Change
 	Issues      []Issue
 	Counter     ProjectCounts
+	Timing      *ProjectTiming // Set once the project's pages are processed
 }
@@ -0,0 +1,119 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// RunReport is a machine-readable summary of a single GDCD run. It is written next to the run's log file so the
+// report-diff tool can compare consecutive runs without querying the database.
+type RunReport struct {
+	RunID     string                     `json:"run_id"`
+	StartedAt time.Time                  `json:"started_at"`
+	Projects  map[string]ProjectSnapshot `json:"projects"`
+	// Backfilled is true for reports reconstructed from archived Snooty snapshots rather than written by a run.
+	// StartedAt is the snapshot date.
+	Backfilled bool `json:"backfilled,omitempty"`
+	// CategoryDrift lists snippets categorized during the run whose category differs from an earlier run
+	CategoryDrift []CategoryDrift `json:"category_drift,omitempty"`
+	// IssueCounts is the number of issues the run reported across all projects, by issue code
+	IssueCounts map[IssueCode]int `json:"issue_counts,omitempty"`
+	// WriteGuardrail is the result of comparing each collection's page count with the pre-run backup
+	WriteGuardrail *WriteGuardrailResult `json:"write_guardrail,omitempty"`
+}
+
+// ProjectSnapshot captures the state of a project in the database at the end of a run, plus any issues the run
+// reported for the project.
+type ProjectSnapshot struct {
+	PageIDs          []string        `json:"page_ids"`
+	CodeExampleCount int             `json:"code_example_count"`
+	LanguageCounts   map[string]int  `json:"language_counts"`
+	Issues           []ReportedIssue `json:"issues"`
+	Counter          ProjectCounts   `json:"counter"`
+	// Timing is how long the run spent on the project's pages, by stage, with the slowest pages
+	Timing *ProjectTiming `json:"timing,omitempty"`
+}
+
+// ReportedIssue is an issue as recorded in a run report: a stable code, a human-readable message, and the
+// structured context the issue was found in.
+type ReportedIssue struct {
+	Code    IssueCode `json:"code"`
+	Message string    `json:"message"`
+	IssueContext
+}
+
+// NewReportedIssue converts an issue from a project report to its run report form.
+func NewReportedIssue(issue Issue) ReportedIssue {
+	return ReportedIssue{Code: issue.Type.Code(), Message: issue.Message, IssueContext: issue.Context}
+}
+
+// String returns the issue code and message, for logs and notifications.
+func (i ReportedIssue) String() string {
+	if i.Code == "" {
+		return i.Message
+	}
+	return string(i.Code) + ": " + i.Message
+}
+
+// UnmarshalJSON reads issues from run reports written before issues had codes, which recorded each issue as an
+// "<issue type>: <message>" string. The code is recovered from the issue type where possible.
+func (i *ReportedIssue) UnmarshalJSON(data []byte) error {
+	var legacy string
+	if err := json.Unmarshal(data, &legacy); err == nil {
+		*i = ReportedIssue{Message: legacy}
+		for issueType := PagesNotFoundIssue; issueType <= LLMErrorIssue; issueType++ {
+			if message, found := strings.CutPrefix(legacy, issueType.String()+": "); found {
+				*i = ReportedIssue{Code: issueType.Code(), Message: message}
+				break
+			}
+		}
+		return nil
+	}
+	type reportedIssue ReportedIssue
+	return json.Unmarshal(data, (*reportedIssue)(i))
+}
+
+// RunReportDiff describes what changed between two runs.
+type RunReportDiff struct {
+	OldRunID        string
+	NewRunID        string
+	ProjectsAdded   []string
+	ProjectsRemoved []string
+	Projects        []ProjectDiff // Only projects present in both runs that have changes
+	LanguageDeltas  []LanguageDelta
+	IssueCodeDeltas []IssueCodeDelta // Change in how often each issue code was reported, across all projects
+}
+
+// IssueCodeDelta is the change in the number of issues reported with a code between two runs.
+type IssueCodeDelta struct {
+	Code  IssueCode
+	Old   int
+	New   int
+	Delta int
+}
+
+// ProjectDiff describes what changed in a single project between two runs.
+type ProjectDiff struct {
+	ProjectName      string
+	PagesAdded       []string
+	PagesRemoved     []string
+	CodeExampleDelta int
+	LanguageDeltas   []LanguageDelta
+	IssuesIntroduced []ReportedIssue
+	IssuesResolved   []ReportedIssue
+}
+
+// LanguageDelta is the change in code example count for a language between two runs.
+type LanguageDelta struct {
+	Language string
+	Old      int
+	New      int
+	Delta    int
+}
+
+// HasChanges reports whether anything changed in the project between the two runs
+func (d ProjectDiff) HasChanges() bool {
+	return len(d.PagesAdded) > 0 || len(d.PagesRemoved) > 0 || d.CodeExampleDelta != 0 ||
+		len(d.LanguageDeltas) > 0 || len(d.IssuesIntroduced) > 0 || len(d.IssuesResolved) > 0
+}
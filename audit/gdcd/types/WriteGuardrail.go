@@ -0,0 +1,43 @@
+package types
+
+// WriteGuardrailConfig controls the check at the end of a run that compares each collection's page count with the
+// pre-run backup, so a parser bug can't mass-delete (or mass-insert) records unnoticed.
+type WriteGuardrailConfig struct {
+	// MaxDeltaPercent is the largest change in a collection's page count, as a percentage of its count in the
+	// backup, that's kept without confirmation. 0 turns the guardrail off.
+	MaxDeltaPercent float64
+	// Confirmed keeps changes over the threshold without asking, for unattended runs that are expected to make them
+	Confirmed bool
+}
+
+// CollectionDelta is the change in a collection's page count between the pre-run backup and the end of the run
+type CollectionDelta struct {
+	Collection string `json:"collection"`
+	Before     int64  `json:"before"`
+	After      int64  `json:"after"`
+}
+
+// PercentChange returns the change as a percentage of the count in the backup: negative when pages were removed.
+// Collections the backup doesn't have are new, so their change is 0.
+func (d CollectionDelta) PercentChange() float64 {
+	if d.Before == 0 {
+		return 0
+	}
+	return float64(d.After-d.Before) / float64(d.Before) * 100
+}
+
+// Write guardrail outcomes, recorded in the run report
+const (
+	GuardrailPassed    = "passed"    // No collection changed by more than the threshold
+	GuardrailConfirmed = "confirmed" // Changes over the threshold were kept, at the prompt or by GDCD_CONFIRM_LARGE_DELTA
+	GuardrailRestored  = "restored"  // Collections over the threshold were restored from the backup
+)
+
+// WriteGuardrailResult records the write guardrail check in the run report
+type WriteGuardrailResult struct {
+	BackupDB        string  `json:"backup_db"`
+	MaxDeltaPercent float64 `json:"max_delta_percent"`
+	Outcome         string  `json:"outcome"`
+	// Exceeded lists the collections whose page count changed by more than MaxDeltaPercent
+	Exceeded []CollectionDelta `json:"exceeded,omitempty"`
+}
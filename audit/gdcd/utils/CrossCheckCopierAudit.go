@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"fmt"
+	"gdcd/types"
+	"sort"
+)
+
+// LatestCopierFileStatuses returns the most recent record for each file in the examples-copier's audit records
+func LatestCopierFileStatuses(records []types.CopierFileRecord) map[types.CopierFileKey]types.CopierFileRecord {
+	latest := make(map[types.CopierFileKey]types.CopierFileRecord, len(records))
+	for _, record := range records {
+		if existing, ok := latest[record.CopierFileKey]; !ok || record.Timestamp.After(existing.Timestamp) {
+			latest[record.CopierFileKey] = record
+		}
+	}
+	return latest
+}
+
+// CrossCheckCopierAudit returns an issue for each page that includes an example file the examples-copier has marked
+// deprecated, removed, or never synced, according to the latest status of each file. A page that includes the same
+// file more than once gets one issue for it.
+func CrossCheckCopierAudit(projectName string, includes []types.IncludedExample, statuses map[types.CopierFileKey]types.CopierFileRecord) []types.Issue {
+	sorted := append([]types.IncludedExample(nil), includes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].PageID != sorted[j].PageID {
+			return sorted[i].PageID < sorted[j].PageID
+		}
+		return sorted[i].IncludePath < sorted[j].IncludePath
+	})
+
+	var issues []types.Issue
+	seen := make(map[types.IncludedExample]bool)
+	for _, include := range sorted {
+		if seen[include] {
+			continue
+		}
+		seen[include] = true
+
+		var issueType types.IssueType
+		var problem string
+		record, found := statuses[include.CopierFileKey]
+		switch {
+		case !found:
+			issueType = types.IncludesUnsyncedExampleIssue
+			problem = "which the examples-copier has never synced"
+		case record.Status == types.CopierFileDeprecated:
+			issueType = types.IncludesDeprecatedExampleIssue
+			problem = "which the examples-copier marked deprecated on " + record.Timestamp.Format("2006-01-02")
+		case record.Status == types.CopierFileRemoved:
+			issueType = types.IncludesDeprecatedExampleIssue
+			problem = "which the examples-copier removed on " + record.Timestamp.Format("2006-01-02")
+		default:
+			continue
+		}
+		issues = append(issues, types.Issue{
+			Type:    issueType,
+			Message: fmt.Sprintf("Page ID: %s - includes %s, %s", include.PageID, include.IncludePath, problem),
+			Context: types.IssueContext{
+				ProjectName: projectName,
+				PageID:      include.PageID,
+				Detail:      include.Repo + "/" + include.Path,
+			},
+		})
+	}
+	return issues
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"gdcd/types"
+	"testing"
+	"time"
+)
+
+func TestLatestCopierFileStatuses(t *testing.T) {
+	key := types.CopierFileKey{Repo: "mongodb/docs", Path: "content/code-examples/tested/python/insert.py"}
+	earlier := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.AddDate(0, 1, 0)
+	statuses := LatestCopierFileStatuses([]types.CopierFileRecord{
+		{CopierFileKey: key, Status: types.CopierFileDeprecated, Timestamp: later},
+		{CopierFileKey: key, Status: types.CopierFileSynced, Timestamp: earlier},
+	})
+	if got := statuses[key].Status; got != types.CopierFileDeprecated {
+		t.Errorf("FAILED: got status %s, want %s", got, types.CopierFileDeprecated)
+	}
+}
+
+func TestCrossCheckCopierAudit(t *testing.T) {
+	synced := types.CopierFileKey{Repo: "mongodb/docs", Path: "content/code-examples/tested/python/insert.py"}
+	deprecated := types.CopierFileKey{Repo: "mongodb/docs", Path: "content/code-examples/tested/python/old.py"}
+	removed := types.CopierFileKey{Repo: "mongodb/docs", Path: "content/code-examples/tested/python/gone.py"}
+	unsynced := types.CopierFileKey{Repo: "mongodb/docs", Path: "content/code-examples/tested/python/handwritten.py"}
+	date := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	statuses := map[types.CopierFileKey]types.CopierFileRecord{
+		synced:     {CopierFileKey: synced, Status: types.CopierFileSynced, Timestamp: date},
+		deprecated: {CopierFileKey: deprecated, Status: types.CopierFileDeprecated, Timestamp: date},
+		removed:    {CopierFileKey: removed, Status: types.CopierFileRemoved, Timestamp: date},
+	}
+	includes := []types.IncludedExample{
+		{PageID: "crud|insert", IncludePath: "/code-examples/tested/python/insert.py", CopierFileKey: synced},
+		{PageID: "crud|update", IncludePath: "/code-examples/tested/python/old.py", CopierFileKey: deprecated},
+		{PageID: "crud|update", IncludePath: "/code-examples/tested/python/old.py", CopierFileKey: deprecated},
+		{PageID: "crud|delete", IncludePath: "/code-examples/tested/python/gone.py", CopierFileKey: removed},
+		{PageID: "crud|delete", IncludePath: "/code-examples/tested/python/handwritten.py", CopierFileKey: unsynced},
+	}
+
+	issues := CrossCheckCopierAudit("pymongo", includes, statuses)
+	want := []struct {
+		issueType types.IssueType
+		message   string
+	}{
+		{types.IncludesDeprecatedExampleIssue, "Page ID: crud|delete - includes /code-examples/tested/python/gone.py, which the examples-copier removed on 2025-03-04"},
+		{types.IncludesUnsyncedExampleIssue, "Page ID: crud|delete - includes /code-examples/tested/python/handwritten.py, which the examples-copier has never synced"},
+		{types.IncludesDeprecatedExampleIssue, "Page ID: crud|update - includes /code-examples/tested/python/old.py, which the examples-copier marked deprecated on 2025-03-04"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("FAILED: got %d issues, want %d: %v", len(issues), len(want), issues)
+	}
+	for i, w := range want {
+		if issues[i].Type != w.issueType || issues[i].Message != w.message {
+			t.Errorf("FAILED: issue %d is (%v, %s), want (%v, %s)", i, issues[i].Type, issues[i].Message, w.issueType, w.message)
+		}
+	}
+	if issues[0].Context.ProjectName != "pymongo" || issues[0].Context.Detail != removed.Repo+"/"+removed.Path {
+		t.Errorf("FAILED: got context %+v", issues[0].Context)
+	}
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"gdcd/types"
+	"sort"
+)
+
+// DiffRunReports compares two run reports and returns the projects, pages, language counts, and issues that changed
+// from oldReport to newReport. Projects that are only in one of the reports are listed as added or removed, and their
+// pages are not listed individually.
+func DiffRunReports(oldReport types.RunReport, newReport types.RunReport) types.RunReportDiff {
+	diff := types.RunReportDiff{
+		OldRunID: oldReport.RunID,
+		NewRunID: newReport.RunID,
+	}
+
+	oldLanguageTotals := make(map[string]int)
+	newLanguageTotals := make(map[string]int)
+	for _, snapshot := range oldReport.Projects {
+		for language, count := range snapshot.LanguageCounts {
+			oldLanguageTotals[language] += count
+		}
+	}
+	for _, snapshot := range newReport.Projects {
+		for language, count := range snapshot.LanguageCounts {
+			newLanguageTotals[language] += count
+		}
+	}
+	diff.LanguageDeltas = diffLanguageCounts(oldLanguageTotals, newLanguageTotals)
+	diff.IssueCodeDeltas = diffIssueCounts(CountIssues(oldReport), CountIssues(newReport))
+
+	for _, projectName := range sortedKeys(newReport.Projects) {
+		oldSnapshot, exists := oldReport.Projects[projectName]
+		if !exists {
+			diff.ProjectsAdded = append(diff.ProjectsAdded, projectName)
+			continue
+		}
+		newSnapshot := newReport.Projects[projectName]
+		projectDiff := types.ProjectDiff{
+			ProjectName:      projectName,
+			PagesAdded:       stringsOnlyIn(newSnapshot.PageIDs, oldSnapshot.PageIDs),
+			PagesRemoved:     stringsOnlyIn(oldSnapshot.PageIDs, newSnapshot.PageIDs),
+			CodeExampleDelta: newSnapshot.CodeExampleCount - oldSnapshot.CodeExampleCount,
+			LanguageDeltas:   diffLanguageCounts(oldSnapshot.LanguageCounts, newSnapshot.LanguageCounts),
+			IssuesIntroduced: issuesOnlyIn(newSnapshot.Issues, oldSnapshot.Issues),
+			IssuesResolved:   issuesOnlyIn(oldSnapshot.Issues, newSnapshot.Issues),
+		}
+		if projectDiff.HasChanges() {
+			diff.Projects = append(diff.Projects, projectDiff)
+		}
+	}
+	for _, projectName := range sortedKeys(oldReport.Projects) {
+		if _, exists := newReport.Projects[projectName]; !exists {
+			diff.ProjectsRemoved = append(diff.ProjectsRemoved, projectName)
+		}
+	}
+	return diff
+}
+
+// diffLanguageCounts returns the languages whose counts differ, sorted by language name
+func diffLanguageCounts(oldCounts map[string]int, newCounts map[string]int) []types.LanguageDelta {
+	languages := make(map[string]bool)
+	for language := range oldCounts {
+		languages[language] = true
+	}
+	for language := range newCounts {
+		languages[language] = true
+	}
+	var deltas []types.LanguageDelta
+	for language := range languages {
+		if oldCounts[language] != newCounts[language] {
+			deltas = append(deltas, types.LanguageDelta{
+				Language: language,
+				Old:      oldCounts[language],
+				New:      newCounts[language],
+				Delta:    newCounts[language] - oldCounts[language],
+			})
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].Language < deltas[j].Language
+	})
+	return deltas
+}
+
+// stringsOnlyIn returns the sorted values in a that are not in b
+func stringsOnlyIn(a []string, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, value := range b {
+		inB[value] = true
+	}
+	var result []string
+	for _, value := range a {
+		if !inB[value] {
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// issuesOnlyIn returns the issues in a that are not in b, sorted by code and message. Issues are the same if
+// their code and message match, so an issue whose counts change is reported as resolved and introduced.
+func issuesOnlyIn(a []types.ReportedIssue, b []types.ReportedIssue) []types.ReportedIssue {
+	inB := make(map[string]bool, len(b))
+	for _, issue := range b {
+		inB[issue.String()] = true
+	}
+	var result []types.ReportedIssue
+	for _, issue := range a {
+		if !inB[issue.String()] {
+			result = append(result, issue)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+	return result
+}
+
+// CountIssues returns the number of issues reported across all projects in the run, by issue code. Issues
+// from older reports whose code couldn't be recovered aren't counted.
+func CountIssues(report types.RunReport) map[types.IssueCode]int {
+	counts := make(map[types.IssueCode]int)
+	for _, snapshot := range report.Projects {
+		for _, issue := range snapshot.Issues {
+			if issue.Code != "" {
+				counts[issue.Code]++
+			}
+		}
+	}
+	return counts
+}
+
+// diffIssueCounts returns the issue codes whose counts differ, sorted by code
+func diffIssueCounts(oldCounts map[types.IssueCode]int, newCounts map[types.IssueCode]int) []types.IssueCodeDelta {
+	codes := make(map[types.IssueCode]bool)
+	for code := range oldCounts {
+		codes[code] = true
+	}
+	for code := range newCounts {
+		codes[code] = true
+	}
+	var deltas []types.IssueCodeDelta
+	for code := range codes {
+		if oldCounts[code] != newCounts[code] {
+			deltas = append(deltas, types.IssueCodeDelta{
+				Code:  code,
+				Old:   oldCounts[code],
+				New:   newCounts[code],
+				Delta: newCounts[code] - oldCounts[code],
+			})
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].Code < deltas[j].Code
+	})
+	return deltas
+}
+
+func sortedKeys(projects map[string]types.ProjectSnapshot) []string {
+	keys := make([]string, 0, len(projects))
+	for key := range projects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
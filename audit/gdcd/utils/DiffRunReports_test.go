@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"encoding/json"
+	"gdcd/types"
+	"reflect"
+	"testing"
+)
+
+func TestDiffRunReports(t *testing.T) {
+	oldReport := types.RunReport{
+		RunID: "old",
+		Projects: map[string]types.ProjectSnapshot{
+			"node": {
+				PageIDs:          []string{"crud|insert", "crud|update", "quick-start"},
+				CodeExampleCount: 10,
+				LanguageCounts:   map[string]int{"javascript": 8, "shell": 2},
+				Issues: []types.ReportedIssue{
+					{Code: types.IssueCodePageCountMismatch, Message: "Project node: expected current pages from summing changes is 4, got 3"},
+					{Code: types.IssueCodeLLMTimeout, Message: "python snippet abc - context deadline exceeded"},
+				},
+			},
+			"unchanged": {
+				PageIDs:          []string{"index"},
+				CodeExampleCount: 1,
+				LanguageCounts:   map[string]int{"python": 1},
+			},
+			"retired": {
+				PageIDs:        []string{"index"},
+				LanguageCounts: map[string]int{"c": 3},
+			},
+		},
+	}
+	newReport := types.RunReport{
+		RunID: "new",
+		Projects: map[string]types.ProjectSnapshot{
+			"node": {
+				PageIDs:          []string{"crud|insert", "crud|upsert", "quick-start"},
+				CodeExampleCount: 12,
+				LanguageCounts:   map[string]int{"javascript": 11, "shell": 1},
+				Issues: []types.ReportedIssue{
+					{Code: types.IssueCodeProjectCodeNodeCountMismatch, Message: "Project node: expected 12 code nodes, got 11"},
+					{Code: types.IssueCodeLLMTimeout, Message: "python snippet abc - context deadline exceeded"},
+				},
+			},
+			"unchanged": {
+				PageIDs:          []string{"index"},
+				CodeExampleCount: 1,
+				LanguageCounts:   map[string]int{"python": 1},
+			},
+			"pymongo": {
+				PageIDs:        []string{"index"},
+				LanguageCounts: map[string]int{"python": 4},
+			},
+		},
+	}
+
+	diff := DiffRunReports(oldReport, newReport)
+
+	if !reflect.DeepEqual(diff.ProjectsAdded, []string{"pymongo"}) {
+		t.Errorf("ProjectsAdded = %v, want [pymongo]", diff.ProjectsAdded)
+	}
+	if !reflect.DeepEqual(diff.ProjectsRemoved, []string{"retired"}) {
+		t.Errorf("ProjectsRemoved = %v, want [retired]", diff.ProjectsRemoved)
+	}
+
+	wantLanguages := []types.LanguageDelta{
+		{Language: "c", Old: 3, New: 0, Delta: -3},
+		{Language: "javascript", Old: 8, New: 11, Delta: 3},
+		{Language: "python", Old: 1, New: 5, Delta: 4},
+		{Language: "shell", Old: 2, New: 1, Delta: -1},
+	}
+	if !reflect.DeepEqual(diff.LanguageDeltas, wantLanguages) {
+		t.Errorf("LanguageDeltas = %v, want %v", diff.LanguageDeltas, wantLanguages)
+	}
+
+	wantIssueCodes := []types.IssueCodeDelta{
+		{Code: types.IssueCodePageCountMismatch, Old: 1, New: 0, Delta: -1},
+		{Code: types.IssueCodeProjectCodeNodeCountMismatch, Old: 0, New: 1, Delta: 1},
+	}
+	if !reflect.DeepEqual(diff.IssueCodeDeltas, wantIssueCodes) {
+		t.Errorf("IssueCodeDeltas = %v, want %v", diff.IssueCodeDeltas, wantIssueCodes)
+	}
+
+	if len(diff.Projects) != 1 {
+		t.Fatalf("Projects = %v, want only node to have changes", diff.Projects)
+	}
+	node := diff.Projects[0]
+	if node.ProjectName != "node" {
+		t.Errorf("ProjectName = %s, want node", node.ProjectName)
+	}
+	if !reflect.DeepEqual(node.PagesAdded, []string{"crud|upsert"}) {
+		t.Errorf("PagesAdded = %v, want [crud|upsert]", node.PagesAdded)
+	}
+	if !reflect.DeepEqual(node.PagesRemoved, []string{"crud|update"}) {
+		t.Errorf("PagesRemoved = %v, want [crud|update]", node.PagesRemoved)
+	}
+	if node.CodeExampleDelta != 2 {
+		t.Errorf("CodeExampleDelta = %d, want 2", node.CodeExampleDelta)
+	}
+	if len(node.IssuesIntroduced) != 1 || len(node.IssuesResolved) != 1 {
+		t.Fatalf("IssuesIntroduced = %v, IssuesResolved = %v, want one of each", node.IssuesIntroduced, node.IssuesResolved)
+	}
+	if node.IssuesIntroduced[0].Code != types.IssueCodeProjectCodeNodeCountMismatch {
+		t.Errorf("IssuesIntroduced = %v, want the project code node count issue", node.IssuesIntroduced)
+	}
+}
+
+func TestReportedIssueReadsLegacyStrings(t *testing.T) {
+	data := []byte(`["Page count issue: Project node: expected current pages from summing changes is 4, got 3", "Something unexpected", {"code": "ASTParseError", "message": "Page ID: index - bad AST", "page_id": "index"}]`)
+	var issues []types.ReportedIssue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := []types.ReportedIssue{
+		{Code: types.IssueCodePageCountMismatch, Message: "Project node: expected current pages from summing changes is 4, got 3"},
+		{Message: "Something unexpected"},
+		{Code: types.IssueCodeASTParseError, Message: "Page ID: index - bad AST", IssueContext: types.IssueContext{PageID: "index"}},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("issues = %+v, want %+v", issues, want)
+	}
+}
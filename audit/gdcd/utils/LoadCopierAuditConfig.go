@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"gdcd/types"
+	"os"
+)
+
+// LoadCopierAuditConfig reads where the examples-copier's audit records are from the environment. The cross-check
+// runs when COPIER_AUDIT_DATABASE is set. COPIER_MONGODB_URI defaults to MONGODB_URI, and the collections default to
+// the copier's own defaults.
+func LoadCopierAuditConfig() types.CopierAuditConfig {
+	config := types.CopierAuditConfig{
+		URI:                os.Getenv("COPIER_MONGODB_URI"),
+		Database:           os.Getenv("COPIER_AUDIT_DATABASE"),
+		EventsCollection:   os.Getenv("COPIER_AUDIT_COLLECTION"),
+		WriteLogCollection: os.Getenv("COPIER_WRITE_LOG_COLLECTION"),
+	}
+	if config.URI == "" {
+		config.URI = os.Getenv("MONGODB_URI")
+	}
+	if config.EventsCollection == "" {
+		config.EventsCollection = "events"
+	}
+	if config.WriteLogCollection == "" {
+		config.WriteLogCollection = "copier_writes"
+	}
+	return config
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"fmt"
+	"gdcd/types"
+	"os"
+	"strconv"
+)
+
+// DefaultMinChanges skips summaries only for projects with no changes at all
+const DefaultMinChanges = 1
+
+// LoadNotificationConfig reads the Slack notification settings from the environment:
+// SLACK_WEBHOOK_URL, SLACK_CHANNEL, and SLACK_MIN_CHANGES.
+func LoadNotificationConfig() (types.NotificationConfig, error) {
+	config := types.NotificationConfig{
+		WebhookURL: os.Getenv("SLACK_WEBHOOK_URL"),
+		Channel:    os.Getenv("SLACK_CHANNEL"),
+		MinChanges: DefaultMinChanges,
+	}
+	if value := os.Getenv("SLACK_MIN_CHANGES"); value != "" {
+		minChanges, err := strconv.Atoi(value)
+		if err != nil || minChanges < 0 {
+			return config, fmt.Errorf("SLACK_MIN_CHANGES must be a non-negative integer, got %q", value)
+		}
+		config.MinChanges = minChanges
+	}
+	return config, nil
+}
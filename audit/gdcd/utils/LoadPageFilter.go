@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"fmt"
+	"gdcd/types"
+	"os"
+	"strings"
+)
+
+// LoadPageFilter reads the page exclusion rules from the environment. GDCD_EXCLUDE_FACETS takes a comma-separated list
+// of category=value facets, and GDCD_EXCLUDE_TEMPLATES takes a comma-separated list of Snooty template names. Prefix
+// an entry with a project name and a colon, like "atlas-cli:genre=changelog", to apply it to only that project.
+func LoadPageFilter() (types.PageFilter, error) {
+	var filter types.PageFilter
+	for _, entry := range splitList(os.Getenv("GDCD_EXCLUDE_FACETS")) {
+		project, facet := splitProjectScope(entry)
+		category, value, ok := strings.Cut(facet, "=")
+		category, value = strings.TrimSpace(category), strings.TrimSpace(value)
+		if !ok || category == "" || value == "" {
+			return filter, fmt.Errorf("GDCD_EXCLUDE_FACETS entries must look like category=value, got %q", entry)
+		}
+		filter.ExcludeFacets = append(filter.ExcludeFacets, types.PageFilterRule{Project: project, Category: category, Value: value})
+	}
+	for _, entry := range splitList(os.Getenv("GDCD_EXCLUDE_TEMPLATES")) {
+		project, template := splitProjectScope(entry)
+		if template == "" {
+			return filter, fmt.Errorf("GDCD_EXCLUDE_TEMPLATES entries must name a template, got %q", entry)
+		}
+		filter.ExcludeTemplates = append(filter.ExcludeTemplates, types.PageFilterRule{Project: project, Value: template})
+	}
+	return filter, nil
+}
+
+// splitProjectScope splits the optional "project:" prefix from a page filter entry
+func splitProjectScope(entry string) (string, string) {
+	project, rule, ok := strings.Cut(entry, ":")
+	if !ok {
+		return "", entry
+	}
+	return strings.TrimSpace(project), strings.TrimSpace(rule)
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"gdcd/types"
+	"reflect"
+	"testing"
+)
+
+func TestLoadPageFilterReadsRulesFromEnvironment(t *testing.T) {
+	t.Setenv("GDCD_EXCLUDE_FACETS", "genre=changelog, atlas-cli : genre = reference,,")
+	t.Setenv("GDCD_EXCLUDE_TEMPLATES", "changelog,c-driver:api-reference")
+
+	filter, err := LoadPageFilter()
+	if err != nil {
+		t.Fatalf("FAILED: unexpected error: %v", err)
+	}
+	wantFacets := []types.PageFilterRule{
+		{Category: "genre", Value: "changelog"},
+		{Project: "atlas-cli", Category: "genre", Value: "reference"},
+	}
+	if !reflect.DeepEqual(filter.ExcludeFacets, wantFacets) {
+		t.Errorf("FAILED: got facet rules %v, want %v", filter.ExcludeFacets, wantFacets)
+	}
+	wantTemplates := []types.PageFilterRule{
+		{Value: "changelog"},
+		{Project: "c-driver", Value: "api-reference"},
+	}
+	if !reflect.DeepEqual(filter.ExcludeTemplates, wantTemplates) {
+		t.Errorf("FAILED: got template rules %v, want %v", filter.ExcludeTemplates, wantTemplates)
+	}
+}
+
+func TestLoadPageFilterRejectsInvalidRules(t *testing.T) {
+	t.Setenv("GDCD_EXCLUDE_FACETS", "changelog")
+	if _, err := LoadPageFilter(); err == nil {
+		t.Errorf("FAILED: want an error for a facet without a category")
+	}
+	t.Setenv("GDCD_EXCLUDE_FACETS", "")
+	t.Setenv("GDCD_EXCLUDE_TEMPLATES", "atlas-cli:")
+	if _, err := LoadPageFilter(); err == nil {
+		t.Errorf("FAILED: want an error for a template rule without a template")
+	}
+}
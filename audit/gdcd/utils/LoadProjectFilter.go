@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"fmt"
+	"gdcd/types"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadProjectFilter reads the project discovery filters from the environment: GDCD_PRODUCT_FAMILIES,
+// GDCD_ALLOW_PROJECTS, and GDCD_DENY_PROJECTS take comma-separated lists, and GDCD_INCLUDE_INACTIVE_BRANCHES takes
+// a boolean.
+func LoadProjectFilter() (types.ProjectFilter, error) {
+	filter := types.ProjectFilter{
+		ProductFamilies: splitList(os.Getenv("GDCD_PRODUCT_FAMILIES")),
+		AllowProjects:   splitList(os.Getenv("GDCD_ALLOW_PROJECTS")),
+		DenyProjects:    splitList(os.Getenv("GDCD_DENY_PROJECTS")),
+	}
+	if value := os.Getenv("GDCD_INCLUDE_INACTIVE_BRANCHES"); value != "" {
+		include, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("GDCD_INCLUDE_INACTIVE_BRANCHES must be true or false, got %q", value)
+		}
+		filter.IncludeInactiveBranches = include
+	}
+	return filter, nil
+}
+
+// splitList splits a comma-separated list, trimming whitespace and dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadProjectFilterReadsListsFromEnvironment(t *testing.T) {
+	t.Setenv("GDCD_PRODUCT_FAMILIES", "Atlas, Drivers")
+	t.Setenv("GDCD_ALLOW_PROJECTS", "")
+	t.Setenv("GDCD_DENY_PROJECTS", "cloud-docs,,charts ")
+	t.Setenv("GDCD_INCLUDE_INACTIVE_BRANCHES", "true")
+
+	filter, err := LoadProjectFilter()
+	if err != nil {
+		t.Fatalf("FAILED: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(filter.ProductFamilies, []string{"Atlas", "Drivers"}) {
+		t.Errorf("FAILED: got product families %v", filter.ProductFamilies)
+	}
+	if filter.AllowProjects != nil {
+		t.Errorf("FAILED: got allow projects %v, want none", filter.AllowProjects)
+	}
+	if !reflect.DeepEqual(filter.DenyProjects, []string{"cloud-docs", "charts"}) {
+		t.Errorf("FAILED: got deny projects %v", filter.DenyProjects)
+	}
+	if !filter.IncludeInactiveBranches {
+		t.Errorf("FAILED: want IncludeInactiveBranches to be true")
+	}
+}
+
+func TestLoadProjectFilterRejectsInvalidBoolean(t *testing.T) {
+	t.Setenv("GDCD_INCLUDE_INACTIVE_BRANCHES", "sometimes")
+	if _, err := LoadProjectFilter(); err == nil {
+		t.Errorf("FAILED: want an error for an invalid GDCD_INCLUDE_INACTIVE_BRANCHES value")
+	}
+}
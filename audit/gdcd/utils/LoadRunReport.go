@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"gdcd/types"
+	"os"
+	"path/filepath"
+)
+
+// LoadRunReport reads a run report. The argument can be a path to a report file, or a run ID such as
+// "2025-09-24-18-01-30", which is resolved to the matching report file in logDir.
+func LoadRunReport(pathOrRunID string, logDir string) (types.RunReport, error) {
+	var report types.RunReport
+	reportFile := pathOrRunID
+	if _, err := os.Stat(reportFile); err != nil {
+		reportFile = filepath.Join(logDir, pathOrRunID+RunReportFileSuffix)
+	}
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		return report, fmt.Errorf("no run report found for %q (looked for %q): %w", pathOrRunID, reportFile, err)
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("parsing run report %q: %w", reportFile, err)
+	}
+	return report, nil
+}
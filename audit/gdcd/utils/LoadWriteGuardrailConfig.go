@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"fmt"
+	"gdcd/types"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// DefaultMaxDeltaPercent is how much a collection's page count can change in one run before the change has to be
+// confirmed
+const DefaultMaxDeltaPercent = 20
+
+// LoadWriteGuardrailConfig reads the write guardrail settings from the environment: GDCD_MAX_COLLECTION_DELTA_PERCENT
+// takes a non-negative number, and GDCD_CONFIRM_LARGE_DELTA takes a boolean.
+func LoadWriteGuardrailConfig() (types.WriteGuardrailConfig, error) {
+	config := types.WriteGuardrailConfig{MaxDeltaPercent: DefaultMaxDeltaPercent}
+	if value := os.Getenv("GDCD_MAX_COLLECTION_DELTA_PERCENT"); value != "" {
+		percent, err := strconv.ParseFloat(value, 64)
+		if err != nil || percent < 0 {
+			return config, fmt.Errorf("GDCD_MAX_COLLECTION_DELTA_PERCENT must be a non-negative number, got %q", value)
+		}
+		config.MaxDeltaPercent = percent
+	}
+	if value := os.Getenv("GDCD_CONFIRM_LARGE_DELTA"); value != "" {
+		confirmed, err := strconv.ParseBool(value)
+		if err != nil {
+			return config, fmt.Errorf("GDCD_CONFIRM_LARGE_DELTA must be true or false, got %q", value)
+		}
+		config.Confirmed = confirmed
+	}
+	return config, nil
+}
+
+// CollectionDeltas pairs each collection's page count in the backup with its count now, sorted by collection.
+// Collections missing from either side count as 0 there.
+func CollectionDeltas(before map[string]int64, after map[string]int64) []types.CollectionDelta {
+	names := make(map[string]bool, len(after))
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+	deltas := make([]types.CollectionDelta, 0, len(names))
+	for name := range names {
+		deltas = append(deltas, types.CollectionDelta{Collection: name, Before: before[name], After: after[name]})
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].Collection < deltas[j].Collection })
+	return deltas
+}
+
+// DeltasOverThreshold returns the deltas whose page count changed by more than maxPercent of the count in the backup,
+// in either direction. A threshold of 0 returns none.
+func DeltasOverThreshold(deltas []types.CollectionDelta, maxPercent float64) []types.CollectionDelta {
+	if maxPercent <= 0 {
+		return nil
+	}
+	var exceeded []types.CollectionDelta
+	for _, delta := range deltas {
+		change := delta.PercentChange()
+		if change > maxPercent || change < -maxPercent {
+			exceeded = append(exceeded, delta)
+		}
+	}
+	return exceeded
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"gdcd/types"
+	"reflect"
+	"testing"
+)
+
+func TestLoadWriteGuardrailConfigDefaults(t *testing.T) {
+	t.Setenv("GDCD_MAX_COLLECTION_DELTA_PERCENT", "")
+	t.Setenv("GDCD_CONFIRM_LARGE_DELTA", "")
+
+	config, err := LoadWriteGuardrailConfig()
+	if err != nil {
+		t.Fatalf("FAILED: unexpected error: %v", err)
+	}
+	if config.MaxDeltaPercent != DefaultMaxDeltaPercent || config.Confirmed {
+		t.Errorf("FAILED: got %+v, want the default threshold without confirmation", config)
+	}
+}
+
+func TestLoadWriteGuardrailConfigReadsEnvironment(t *testing.T) {
+	t.Setenv("GDCD_MAX_COLLECTION_DELTA_PERCENT", "12.5")
+	t.Setenv("GDCD_CONFIRM_LARGE_DELTA", "true")
+
+	config, err := LoadWriteGuardrailConfig()
+	if err != nil {
+		t.Fatalf("FAILED: unexpected error: %v", err)
+	}
+	if config.MaxDeltaPercent != 12.5 || !config.Confirmed {
+		t.Errorf("FAILED: got %+v", config)
+	}
+}
+
+func TestLoadWriteGuardrailConfigRejectsInvalidValues(t *testing.T) {
+	t.Setenv("GDCD_MAX_COLLECTION_DELTA_PERCENT", "-5")
+	if _, err := LoadWriteGuardrailConfig(); err == nil {
+		t.Errorf("FAILED: want an error for a negative GDCD_MAX_COLLECTION_DELTA_PERCENT")
+	}
+	t.Setenv("GDCD_MAX_COLLECTION_DELTA_PERCENT", "")
+	t.Setenv("GDCD_CONFIRM_LARGE_DELTA", "maybe")
+	if _, err := LoadWriteGuardrailConfig(); err == nil {
+		t.Errorf("FAILED: want an error for an invalid GDCD_CONFIRM_LARGE_DELTA value")
+	}
+}
+
+func TestDeltasOverThreshold(t *testing.T) {
+	deltas := CollectionDeltas(
+		map[string]int64{"node": 100, "pymongo": 200, "compass": 50, "retired": 10},
+		map[string]int64{"node": 40, "pymongo": 210, "compass": 70, "new-project": 30},
+	)
+	want := []types.CollectionDelta{
+		{Collection: "compass", Before: 50, After: 70},
+		{Collection: "new-project", Before: 0, After: 30},
+		{Collection: "node", Before: 100, After: 40},
+		{Collection: "pymongo", Before: 200, After: 210},
+		{Collection: "retired", Before: 10, After: 0},
+	}
+	if !reflect.DeepEqual(deltas, want) {
+		t.Fatalf("FAILED: got deltas %+v", deltas)
+	}
+
+	// compass grew 40%, node lost 60%, and retired lost everything; new projects and small changes are fine
+	exceeded := DeltasOverThreshold(deltas, 20)
+	var names []string
+	for _, delta := range exceeded {
+		names = append(names, delta.Collection)
+	}
+	if !reflect.DeepEqual(names, []string{"compass", "node", "retired"}) {
+		t.Errorf("FAILED: got collections over the threshold %v", names)
+	}
+
+	if exceeded := DeltasOverThreshold(deltas, 0); exceeded != nil {
+		t.Errorf("FAILED: a threshold of 0 turns the guardrail off, got %v", exceeded)
+	}
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"gdcd/types"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/notify"
+)
+
+// maxAttachmentsPerMessage keeps each Slack message within the number of attachments Slack renders reliably
+const maxAttachmentsPerMessage = 20
+
+// maxIssuesPerProject limits how many issues are listed in a project's summary; the rest are in the run report
+const maxIssuesPerProject = 5
+
+// ProjectChangeCount returns the number of page and code example changes the run made to a project
+func ProjectChangeCount(counter types.ProjectCounts) int {
+	return counter.NewPagesCount + counter.RemovedPagesCount + counter.NewCodeNodesCount +
+		counter.UpdatedCodeNodesCount + counter.RemovedCodeNodesCount
+}
+
+// ShouldNotifyForProject reports whether a project's summary meets the threshold for posting: it has at least
+// minChanges changes, or it has issues.
+func ShouldNotifyForProject(snapshot types.ProjectSnapshot, minChanges int) bool {
+	if len(snapshot.Issues) > 0 {
+		return true
+	}
+	changes := ProjectChangeCount(snapshot.Counter)
+	return changes > 0 && changes >= minChanges
+}
+
+// BuildRunSummaryMessages builds the Slack messages summarizing the run, with one attachment per project that meets
+// the notification threshold. Projects are sorted by name and split across messages as needed. Returns nil if no
+// project meets the threshold.
+func BuildRunSummaryMessages(report types.RunReport, config types.NotificationConfig) []*notify.Message {
+	var projectNames []string
+	for name, snapshot := range report.Projects {
+		if ShouldNotifyForProject(snapshot, config.MinChanges) {
+			projectNames = append(projectNames, name)
+		}
+	}
+	sort.Strings(projectNames)
+
+	var messages []*notify.Message
+	for start := 0; start < len(projectNames); start += maxAttachmentsPerMessage {
+		end := min(start+maxAttachmentsPerMessage, len(projectNames))
+		message := &notify.Message{
+			Channel: config.Channel,
+			Text: fmt.Sprintf("GDCD run %s: %d of %d projects changed or reported issues (%d-%d)",
+				report.RunID, len(projectNames), len(report.Projects), start+1, end),
+		}
+		for _, name := range projectNames[start:end] {
+			message.Attachments = append(message.Attachments, buildProjectAttachment(name, report.Projects[name], report.StartedAt))
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func buildProjectAttachment(projectName string, snapshot types.ProjectSnapshot, startedAt time.Time) notify.Attachment {
+	counter := snapshot.Counter
+	color := notify.ColorGood
+	text := ""
+	if len(snapshot.Issues) > 0 {
+		color = notify.ColorWarning
+		var issues []string
+		for _, issue := range snapshot.Issues[:min(len(snapshot.Issues), maxIssuesPerProject)] {
+			issues = append(issues, issue.String())
+		}
+		if len(snapshot.Issues) > maxIssuesPerProject {
+			issues = append(issues, fmt.Sprintf("... and %d more", len(snapshot.Issues)-maxIssuesPerProject))
+		}
+		text = fmt.Sprintf("```\n%s```", notify.FormatList(issues))
+	}
+	return notify.Attachment{
+		Color: color,
+		Title: projectName,
+		Text:  text,
+		Fields: []notify.Field{
+			{Title: "Pages Scanned", Value: fmt.Sprintf("%d", counter.TotalCurrentPageCount), Short: true},
+			{Title: "Pages New/Removed", Value: fmt.Sprintf("%d / %d", counter.NewPagesCount, counter.RemovedPagesCount), Short: true},
+			{Title: "Examples New/Updated/Removed", Value: fmt.Sprintf("%d / %d / %d", counter.NewCodeNodesCount, counter.UpdatedCodeNodesCount, counter.RemovedCodeNodesCount), Short: true},
+			{Title: "Issues", Value: fmt.Sprintf("%d", len(snapshot.Issues)), Short: true},
+		},
+		Footer:    "GDCD",
+		Timestamp: startedAt.Unix(),
+	}
+}
+
+// NotifyDocsTeam posts the per-project run summaries to the docs team's Slack channel and returns the number of
+// projects it posted summaries for. It does nothing if no webhook URL is configured.
+func NotifyDocsTeam(ctx context.Context, report types.RunReport, config types.NotificationConfig) (int, error) {
+	client := notify.NewClient(config.WebhookURL)
+	if !client.IsEnabled() {
+		return 0, nil
+	}
+	posted := 0
+	var errs []string
+	for _, message := range BuildRunSummaryMessages(report, config) {
+		if err := client.Send(ctx, message); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		posted += len(message.Attachments)
+	}
+	if len(errs) > 0 {
+		return posted, fmt.Errorf("posting run summaries: %s", strings.Join(errs, "; "))
+	}
+	return posted, nil
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"gdcd/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/code-copier/notify"
+)
+
+func TestShouldNotifyForProject(t *testing.T) {
+	tests := []struct {
+		name       string
+		snapshot   types.ProjectSnapshot
+		minChanges int
+		want       bool
+	}{
+		{"no changes", types.ProjectSnapshot{Counter: types.ProjectCounts{TotalCurrentPageCount: 40}}, 1, false},
+		{"no changes with zero threshold", types.ProjectSnapshot{}, 0, false},
+		{"changes meet threshold", types.ProjectSnapshot{Counter: types.ProjectCounts{NewCodeNodesCount: 2, RemovedPagesCount: 1}}, 3, true},
+		{"changes below threshold", types.ProjectSnapshot{Counter: types.ProjectCounts{UpdatedCodeNodesCount: 2}}, 3, false},
+		{"issues always notify", types.ProjectSnapshot{Issues: []types.ReportedIssue{{Code: types.IssueCodePagesNotFound, Message: "No documents found for project node"}}}, 10, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShouldNotifyForProject(tt.snapshot, tt.minChanges); got != tt.want {
+				t.Errorf("ShouldNotifyForProject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRunSummaryMessages(t *testing.T) {
+	report := types.RunReport{
+		RunID: "2025-09-24-18-01-30",
+		Projects: map[string]types.ProjectSnapshot{
+			"node":      {Counter: types.ProjectCounts{TotalCurrentPageCount: 120, NewCodeNodesCount: 4, UpdatedCodeNodesCount: 2, RemovedCodeNodesCount: 1}},
+			"compass":   {Issues: []types.ReportedIssue{{Code: types.IssueCodePagesNotFound, Message: "No documents found for project compass"}}},
+			"unchanged": {Counter: types.ProjectCounts{TotalCurrentPageCount: 10}},
+		},
+	}
+	messages := BuildRunSummaryMessages(report, types.NotificationConfig{Channel: "#docs-team", MinChanges: 1})
+	if len(messages) != 1 {
+		t.Fatalf("got %d messages, want 1", len(messages))
+	}
+	message := messages[0]
+	if message.Channel != "#docs-team" {
+		t.Errorf("Channel = %q, want #docs-team", message.Channel)
+	}
+	if len(message.Attachments) != 2 {
+		t.Fatalf("got %d attachments, want 2", len(message.Attachments))
+	}
+	compass, node := message.Attachments[0], message.Attachments[1]
+	if compass.Title != "compass" || compass.Color != notify.ColorWarning {
+		t.Errorf("first attachment = %q (%s), want compass (warning)", compass.Title, compass.Color)
+	}
+	if node.Title != "node" || node.Color != notify.ColorGood {
+		t.Errorf("second attachment = %q (%s), want node (good)", node.Title, node.Color)
+	}
+	if got := node.Fields[2].Value; got != "4 / 2 / 1" {
+		t.Errorf("examples field = %q, want \"4 / 2 / 1\"", got)
+	}
+
+	if messages := BuildRunSummaryMessages(types.RunReport{Projects: map[string]types.ProjectSnapshot{"unchanged": {}}}, types.NotificationConfig{MinChanges: 1}); messages != nil {
+		t.Errorf("expected no messages for a run without changes, got %d", len(messages))
+	}
+}
+
+func TestBuildRunSummaryMessages_SplitsLargeRuns(t *testing.T) {
+	report := types.RunReport{Projects: map[string]types.ProjectSnapshot{}}
+	for i := 0; i < maxAttachmentsPerMessage+5; i++ {
+		report.Projects[string(rune('a'+i))] = types.ProjectSnapshot{Counter: types.ProjectCounts{NewPagesCount: 1}}
+	}
+	messages := BuildRunSummaryMessages(report, types.NotificationConfig{MinChanges: 1})
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(messages))
+	}
+	if len(messages[0].Attachments) != maxAttachmentsPerMessage || len(messages[1].Attachments) != 5 {
+		t.Errorf("got %d and %d attachments, want %d and 5", len(messages[0].Attachments), len(messages[1].Attachments), maxAttachmentsPerMessage)
+	}
+}
+
+func TestNotifyDocsTeam(t *testing.T) {
+	var received []notify.Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message notify.Message
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		received = append(received, message)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	report := types.RunReport{
+		RunID: "run",
+		Projects: map[string]types.ProjectSnapshot{
+			"node":      {Counter: types.ProjectCounts{NewCodeNodesCount: 1}},
+			"unchanged": {},
+		},
+	}
+	posted, err := NotifyDocsTeam(context.Background(), report, types.NotificationConfig{WebhookURL: server.URL, MinChanges: 1})
+	if err != nil {
+		t.Fatalf("NotifyDocsTeam() error = %v", err)
+	}
+	if posted != 1 || len(received) != 1 {
+		t.Errorf("posted %d projects in %d messages, want 1 in 1", posted, len(received))
+	}
+
+	// Without a webhook URL, nothing is posted
+	posted, err = NotifyDocsTeam(context.Background(), report, types.NotificationConfig{})
+	if err != nil || posted != 0 {
+		t.Errorf("NotifyDocsTeam() without webhook = %d, %v; want 0, nil", posted, err)
+	}
+}
+
+func TestLoadNotificationConfig(t *testing.T) {
+	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.com/services/TEST")
+	t.Setenv("SLACK_CHANNEL", "#docs-team")
+	t.Setenv("SLACK_MIN_CHANGES", "")
+
+	config, err := LoadNotificationConfig()
+	if err != nil {
+		t.Fatalf("LoadNotificationConfig() error = %v", err)
+	}
+	if config.MinChanges != DefaultMinChanges || config.Channel != "#docs-team" {
+		t.Errorf("LoadNotificationConfig() = %+v", config)
+	}
+
+	t.Setenv("SLACK_MIN_CHANGES", "5")
+	if config, _ := LoadNotificationConfig(); config.MinChanges != 5 {
+		t.Errorf("MinChanges = %d, want 5", config.MinChanges)
+	}
+
+	t.Setenv("SLACK_MIN_CHANGES", "lots")
+	if _, err := LoadNotificationConfig(); err == nil {
+		t.Error("expected an error for a non-numeric SLACK_MIN_CHANGES")
+	}
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"gdcd/types"
+	"sort"
+	"time"
+)
+
+// PageProfiler adds up how long each page of a project takes to process, by stage. Pages are processed in several
+// passes - existing pages first, then new and moved pages once they've been told apart - so a page's time can be
+// added to more than once.
+type PageProfiler struct {
+	pages map[string]*[types.PageStageCount]time.Duration
+}
+
+func NewPageProfiler() *PageProfiler {
+	return &PageProfiler{pages: make(map[string]*[types.PageStageCount]time.Duration)}
+}
+
+// Add records time spent on a page in a stage
+func (p *PageProfiler) Add(pageID string, stage types.PageStage, duration time.Duration) {
+	if duration <= 0 {
+		return
+	}
+	stages, ok := p.pages[pageID]
+	if !ok {
+		stages = &[types.PageStageCount]time.Duration{}
+		p.pages[pageID] = stages
+	}
+	stages[stage] += duration
+}
+
+// Spread divides time spent on several pages at once, such as a batch write, evenly between them
+func (p *PageProfiler) Spread(pageIDs []string, stage types.PageStage, duration time.Duration) {
+	if len(pageIDs) == 0 {
+		return
+	}
+	share := duration / time.Duration(len(pageIDs))
+	for _, pageID := range pageIDs {
+		p.Add(pageID, stage, share)
+	}
+}
+
+// Summary returns the project's time by stage and its slowest pages, slowest first. Pages that took the same time
+// are sorted by page ID.
+func (p *PageProfiler) Summary(slowest int) *types.ProjectTiming {
+	summary := &types.ProjectTiming{Pages: len(p.pages)}
+	var pages []types.PageTiming
+	for pageID, stages := range p.pages {
+		page := makePageTiming(pageID, stages)
+		summary.TotalMs += page.TotalMs
+		summary.FetchMs += page.FetchMs
+		summary.ParseMs += page.ParseMs
+		summary.LLMMs += page.LLMMs
+		summary.DBMs += page.DBMs
+		summary.OtherMs += page.OtherMs
+		pages = append(pages, page)
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		if pages[i].TotalMs != pages[j].TotalMs {
+			return pages[i].TotalMs > pages[j].TotalMs
+		}
+		return pages[i].PageID < pages[j].PageID
+	})
+	if slowest < len(pages) {
+		pages = pages[:max(slowest, 0)]
+	}
+	if len(pages) > 0 {
+		summary.SlowestPages = pages
+	}
+	return summary
+}
+
+func makePageTiming(pageID string, stages *[types.PageStageCount]time.Duration) types.PageTiming {
+	var total time.Duration
+	for _, duration := range stages {
+		total += duration
+	}
+	return types.PageTiming{
+		PageID:  pageID,
+		TotalMs: total.Milliseconds(),
+		FetchMs: stages[types.FetchStage].Milliseconds(),
+		ParseMs: stages[types.ParseStage].Milliseconds(),
+		LLMMs:   stages[types.LLMStage].Milliseconds(),
+		DBMs:    stages[types.DBStage].Milliseconds(),
+		OtherMs: stages[types.OtherStage].Milliseconds(),
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"gdcd/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPageProfilerSummary(t *testing.T) {
+	profiler := NewPageProfiler()
+	profiler.Add("node|crud", types.FetchStage, 20*time.Millisecond)
+	profiler.Add("node|crud", types.LLMStage, 3*time.Second)
+	profiler.Add("node|crud", types.OtherStage, 5*time.Millisecond)
+	profiler.Add("node|index", types.ParseStage, 40*time.Millisecond)
+	profiler.Add("node|quick-start", types.FetchStage, 10*time.Millisecond)
+	profiler.Add("node|quick-start", types.DBStage, 0)
+	// The batch write is split between the pages it wrote
+	profiler.Spread([]string{"node|crud", "node|index"}, types.DBStage, 100*time.Millisecond)
+
+	got := profiler.Summary(2)
+	want := &types.ProjectTiming{
+		Pages:   3,
+		TotalMs: 3175,
+		FetchMs: 30,
+		ParseMs: 40,
+		LLMMs:   3000,
+		DBMs:    100,
+		OtherMs: 5,
+		SlowestPages: []types.PageTiming{
+			{PageID: "node|crud", TotalMs: 3075, FetchMs: 20, LLMMs: 3000, DBMs: 50, OtherMs: 5},
+			{PageID: "node|index", TotalMs: 90, ParseMs: 40, DBMs: 50},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Summary() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPageProfilerSummaryWithoutSlowPages(t *testing.T) {
+	profiler := NewPageProfiler()
+	profiler.Add("node|crud", types.FetchStage, time.Second)
+
+	got := profiler.Summary(0)
+	if got.Pages != 1 || got.TotalMs != 1000 {
+		t.Errorf("Summary() = %+v, want 1 page taking 1000ms", got)
+	}
+	if got.SlowestPages != nil {
+		t.Errorf("SlowestPages = %+v, want none", got.SlowestPages)
+	}
+	if empty := NewPageProfiler().Summary(10); empty.Pages != 0 || empty.SlowestPages != nil {
+		t.Errorf("Summary() of an empty profiler = %+v, want no pages", empty)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"gdcd/types"
 )
 
+// ReportIssues adds an issue to the project report. stringArg is the project name for PagesNotFoundIssue,
+// PageCountIssue, and ProjectCodeNodeCountIssue, and the page ID for CodeNodeCountIssue and PageNotRemovedIssue.
+// For count issues, counts are the expected and actual counts.
 func ReportIssues(issueType types.IssueType, report types.ProjectReport, stringArg string, counts ...int) types.ProjectReport {
 	numberOfCounts := len(counts)
 	var count1 int
@@ -19,23 +22,59 @@ func ReportIssues(issueType types.IssueType, report types.ProjectReport, stringA
 		// Counts could be an empty array, in which case we do nothing
 	}
 
+	context := types.IssueContext{ProjectName: report.ProjectName}
+	if numberOfCounts == 2 {
+		context.Expected = &count1
+		context.Actual = &count2
+	}
+
 	var message string
 	switch issueType {
 	case types.PagesNotFoundIssue:
 		message = fmt.Sprintf("No documents found for project %s", stringArg)
+		context.ProjectName = stringArg
 	case types.CodeNodeCountIssue:
+		message = fmt.Sprintf("Page ID: %s - expected %d code nodes, got %d", stringArg, count1, count2)
+		context.PageID = stringArg
+	case types.ProjectCodeNodeCountIssue:
 		message = fmt.Sprintf("Project %s: expected %d code nodes, got %d", stringArg, count1, count2)
+		context.ProjectName = stringArg
 	case types.PageCountIssue:
 		message = fmt.Sprintf("Project %s: expected current pages from summing changes is %d, got %d", stringArg, count1, count2)
+		context.ProjectName = stringArg
 	case types.PageNotRemovedIssue:
 		message = fmt.Sprintf("Page ID: %s - tried to remove page but had an issue", stringArg)
+		context.PageID = stringArg
 	default:
-		message = "Change type not handled in ReportChanges function"
+		message = "Issue type not handled in ReportIssues function"
 	}
 
 	issue := types.Issue{
-		Type: issueType,
-		Data: message,
+		Type:    issueType,
+		Message: message,
+		Context: context,
+	}
+	report.Issues = append(report.Issues, issue)
+	return report
+}
+
+// ReportErrorIssue adds an issue caused by an error, such as a page that couldn't be parsed or an LLM call that
+// failed, to the project report. pageID is empty if the error isn't tied to a page; detail identifies what failed
+// when the page doesn't, such as a snippet's language and hash.
+func ReportErrorIssue(issueType types.IssueType, report types.ProjectReport, pageID string, detail string, err error) types.ProjectReport {
+	subject := detail
+	if pageID != "" {
+		subject = "Page ID: " + pageID
+	}
+	issue := types.Issue{
+		Type:    issueType,
+		Message: fmt.Sprintf("%s - %v", subject, err),
+		Context: types.IssueContext{
+			ProjectName: report.ProjectName,
+			PageID:      pageID,
+			Error:       err.Error(),
+			Detail:      detail,
+		},
 	}
 	report.Issues = append(report.Issues, issue)
 	return report
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"errors"
+	"gdcd/types"
+	"testing"
+)
+
+func TestReportIssuesRecordsContext(t *testing.T) {
+	report := types.ProjectReport{ProjectName: "node"}
+	report = ReportIssues(types.CodeNodeCountIssue, report, "crud|insert", 12, 11)
+
+	if len(report.Issues) != 1 {
+		t.Fatalf("got %d issues, want 1", len(report.Issues))
+	}
+	issue := report.Issues[0]
+	if issue.Type.Code() != types.IssueCodeCodeNodeCountMismatch {
+		t.Errorf("Code() = %s, want %s", issue.Type.Code(), types.IssueCodeCodeNodeCountMismatch)
+	}
+	context := issue.Context
+	if context.ProjectName != "node" || context.PageID != "crud|insert" {
+		t.Errorf("Context = %+v, want project node and page crud|insert", context)
+	}
+	if context.Expected == nil || *context.Expected != 12 || context.Actual == nil || *context.Actual != 11 {
+		t.Errorf("Context counts = %v / %v, want 12 / 11", context.Expected, context.Actual)
+	}
+}
+
+func TestReportErrorIssue(t *testing.T) {
+	report := types.ProjectReport{ProjectName: "node"}
+	report = ReportErrorIssue(types.LLMTimeoutIssue, report, "", "python snippet abc", errors.New("context deadline exceeded"))
+
+	issue := report.Issues[0]
+	if issue.Message != "python snippet abc - context deadline exceeded" {
+		t.Errorf("Message = %q", issue.Message)
+	}
+	reported := types.NewReportedIssue(issue)
+	if reported.Code != types.IssueCodeLLMTimeout || reported.Detail != "python snippet abc" || reported.Error != "context deadline exceeded" {
+		t.Errorf("NewReportedIssue() = %+v", reported)
+	}
+}
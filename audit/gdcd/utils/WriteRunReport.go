@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"gdcd/types"
+	"os"
+	"path/filepath"
+)
+
+// RunReportFileSuffix is appended to the run ID to name the run report file, mirroring the "-app.log" log file suffix
+const RunReportFileSuffix = "-report.json"
+
+// WriteRunReport writes the run report as JSON to the log directory, named after the run ID, and returns the file path
+func WriteRunReport(logDir string, report types.RunReport) (string, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshalling run report: %w", err)
+	}
+	reportFile := filepath.Join(logDir, report.RunID+RunReportFileSuffix)
+	if err := os.WriteFile(reportFile, data, 0o644); err != nil {
+		return "", fmt.Errorf("writing run report %q: %w", reportFile, err)
+	}
+	return reportFile, nil
+}
@@ -80,6 +80,58 @@ func main() {
 	// Configure GitHub permissions
 	services.ConfigurePermissions()
 
+	if container.Maintenance.Enabled(context.Background()) {
+		// The config repo or credentials may be mid-change, so skip warmup; webhooks are only queued
+		services.LogWarning("Starting in maintenance mode: merged PRs will be queued, not processed")
+	} else {
+		// Pre-load config and installation tokens before accepting traffic
+		warmupCtx, cancelWarmup := context.WithTimeout(context.Background(), 60*time.Second)
+		if err := services.Warmup(warmupCtx, container); err != nil {
+			// Not fatal: the config and tokens are loaded again when the first webhook arrives
+			services.LogWarning(fmt.Sprintf("Warmup failed: %v", err))
+		}
+		cancelWarmup()
+
+		// Process anything queued while a previous instance was in maintenance mode
+		if n, err := services.DrainMaintenanceQueue(context.Background(), config, container); err != nil {
+			services.LogWarning(fmt.Sprintf("Failed to drain maintenance queue: %v", err))
+		} else if n > 0 {
+			services.LogInfo(fmt.Sprintf("Processing %d merged PRs queued during maintenance", n))
+		}
+	}
+
+	// Retry uploads that failed with transient GitHub errors until the server stops
+	retryCtx, stopRetries := context.WithCancel(context.Background())
+	defer stopRetries()
+	go container.RetryQueue.Run(retryCtx)
+
+	// Open the PRs held by the destination repos' PR limits as they have budget, until the server stops
+	throttleCtx, stopThrottle := context.WithCancel(context.Background())
+	defer stopThrottle()
+	go container.PRThrottle.Run(throttleCtx)
+
+	// Check the destination builds of workflows with verify_build until the server stops
+	verifyCtx, stopVerifying := context.WithCancel(context.Background())
+	defer stopVerifying()
+	go container.BuildVerifier.Run(verifyCtx)
+
+	// Merge PRs held by workflows' approval gates once they're approved, until the server stops
+	approvalCtx, stopApprovals := context.WithCancel(context.Background())
+	defer stopApprovals()
+	go container.ApprovalGate.Run(approvalCtx)
+
+	// Reload the copier config on an interval until the server stops
+	if container.ConfigWatcher != nil {
+		watchCtx, stopWatching := context.WithCancel(context.Background())
+		defer stopWatching()
+		go container.ConfigWatcher.Run(watchCtx)
+	}
+
+	// Reconcile destinations with their sources on an interval until the server stops
+	reconcileCtx, stopReconciling := context.WithCancel(context.Background())
+	defer stopReconciling()
+	go container.Reconciler.Run(reconcileCtx)
+
 	// Print startup banner
 	printBanner(config, container)
 
@@ -117,11 +169,31 @@ func printBanner(config *configs.Config, container *services.ServiceContainer) {
 	fmt.Printf("║  Config File:  %-48s║\n", config.ConfigFile)
 	fmt.Printf("║  Dry Run:      %-48v║\n", config.DryRun)
 	fmt.Printf("║  Audit Log:    %-48v║\n", config.AuditEnabled)
+	fmt.Printf("║  Write Log:    %-48v║\n", config.WriteLogEnabled)
+	fmt.Printf("║  Mirror:       %-48s║\n", mirrorSummary(config))
 	fmt.Printf("║  Metrics:      %-48v║\n", config.MetricsEnabled)
+	fmt.Printf("║  Maintenance:  %-48v║\n", config.MaintenanceMode)
+	fmt.Printf("║  Retries:      %-48s║\n", retrySummary(config))
 	fmt.Println("╚════════════════════════════════════════════════════════════════╝")
 	fmt.Println()
 }
 
+// retrySummary describes the upload retry queue settings for the startup banner
+func retrySummary(config *configs.Config) string {
+	if config.UploadRetryMaxAttempts <= 0 {
+		return "disabled"
+	}
+	return fmt.Sprintf("%d attempts (%s store)", config.UploadRetryMaxAttempts, config.UploadRetryStore)
+}
+
+// mirrorSummary describes the examples mirror for the startup banner
+func mirrorSummary(config *configs.Config) string {
+	if config.MirrorRepo == "" {
+		return "disabled"
+	}
+	return config.MirrorRepo + "@" + config.MirrorBranch
+}
+
 func validateConfiguration(container *services.ServiceContainer) error {
 	ctx := context.Background()
 	_, err := container.ConfigLoader.LoadConfig(ctx, container.Config)
@@ -137,12 +209,44 @@ func startWebServer(config *configs.Config, container *services.ServiceContainer
 		handleWebhook(w, r, config, container)
 	})
 
-	// Health endpoint
-	mux.HandleFunc("/health", services.HealthHandler(container.FileStateService, container.StartTime))
+	// GitLab merge request webhook endpoint (if configured)
+	if config.GitLabEnabled() {
+		mux.HandleFunc(config.GitLabWebhookPath, func(w http.ResponseWriter, r *http.Request) {
+			handleGitLabWebhook(w, r, config, container)
+		})
+	}
+
+	// Bitbucket pull request webhook endpoint (if configured)
+	if config.BitbucketEnabled() {
+		mux.HandleFunc(config.BitbucketWebhookPath, func(w http.ResponseWriter, r *http.Request) {
+			handleBitbucketWebhook(w, r, config, container)
+		})
+	}
+
+	// Health endpoints
+	mux.HandleFunc("/health", services.HealthHandler(container.RunFileStates, container.StartTime))
+	mux.HandleFunc("/healthz", services.HealthzHandler(container))
+	mux.HandleFunc("/readyz", services.ReadyzHandler(container))
+
+	// App Engine warmup endpoint
+	mux.HandleFunc("/_ah/warmup", services.WarmupHandler(container))
+
+	// Admin API (if an admin token is configured)
+	if config.AdminToken != "" {
+		mux.HandleFunc("/admin/maintenance", services.MaintenanceHandler(config, container))
+		mux.HandleFunc("/admin/runs", services.RunsHandler(config, container))
+		mux.HandleFunc("/admin/dashboard", services.DashboardHandler(config, container))
+		if container.ConfigWatcher != nil {
+			mux.HandleFunc("/admin/reload", services.ConfigReloadHandler(config, container.ConfigWatcher))
+		}
+		mux.HandleFunc("/admin/reconcile", services.ReconcileHandler(config, container.Reconciler))
+		mux.HandleFunc("/admin/trigger/", services.TriggerHandler(config, container))
+		mux.HandleFunc("/admin/replay/", services.ReplayHandler(config, container))
+	}
 
 	// Metrics endpoint (if enabled)
 	if config.MetricsEnabled {
-		mux.HandleFunc("/metrics", services.MetricsHandler(container.MetricsCollector, container.FileStateService))
+		mux.HandleFunc("/metrics", services.MetricsHandler(container.MetricsCollector, container.RunFileStates))
 	}
 
 	// Info endpoint
@@ -154,10 +258,30 @@ func startWebServer(config *configs.Config, container *services.ServiceContainer
 		w.Header().Set("Content-Type", "text/plain")
 		fmt.Fprintf(w, "GitHub Code Example Copier\n")
 		fmt.Fprintf(w, "Webhook endpoint: %s\n", config.WebserverPath)
+		if config.GitLabEnabled() {
+			fmt.Fprintf(w, "GitLab webhook endpoint: %s\n", config.GitLabWebhookPath)
+		}
+		if config.BitbucketEnabled() {
+			fmt.Fprintf(w, "Bitbucket webhook endpoint: %s\n", config.BitbucketWebhookPath)
+		}
 		fmt.Fprintf(w, "Health check: /health\n")
+		fmt.Fprintf(w, "Liveness: /healthz\n")
+		fmt.Fprintf(w, "Readiness: /readyz\n")
 		if config.MetricsEnabled {
 			fmt.Fprintf(w, "Metrics: /metrics\n")
 		}
+		if config.AdminToken != "" {
+			fmt.Fprintf(w, "Maintenance: /admin/maintenance\n")
+			fmt.Fprintf(w, "Dashboard: /admin/dashboard\n")
+			if container.ConfigWatcher != nil {
+				fmt.Fprintf(w, "Config reload: /admin/reload\n")
+			}
+			fmt.Fprintf(w, "Reconcile: /admin/reconcile\n")
+			fmt.Fprintf(w, "Trigger: /admin/trigger/<manual|replay>\n")
+			if container.WebhookArchive != nil {
+				fmt.Fprintf(w, "Replay delivery: /admin/replay/<delivery-id>\n")
+			}
+		}
 	})
 
 	// Create server
@@ -171,18 +295,15 @@ func startWebServer(config *configs.Config, container *services.ServiceContainer
 	}
 
 	// Handle graceful shutdown
+	shutdownComplete := make(chan struct{})
 	go func() {
+		defer close(shutdownComplete)
+
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		<-sigChan
 
-		log.Println("Shutting down server...")
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		if err := server.Shutdown(ctx); err != nil {
-			log.Printf("Server shutdown error: %v\n", err)
-		}
+		shutdownServer(server, container)
 	}()
 
 	// Start server
@@ -191,9 +312,36 @@ func startWebServer(config *configs.Config, container *services.ServiceContainer
 		return fmt.Errorf("server error: %w", err)
 	}
 
+	// ListenAndServe returns as soon as shutdown starts; wait for in-flight work to drain before exiting
+	<-shutdownComplete
 	return nil
 }
 
+// shutdownServer stops accepting requests, then waits for in-flight webhook processing to finish.
+// Anything still running when the shutdown timeout expires is recorded so the PRs can be replayed.
+func shutdownServer(server *http.Server, container *services.ServiceContainer) {
+	timeout := time.Duration(container.Config.ShutdownTimeout) * time.Second
+	log.Printf("Shutting down server (waiting up to %v for %d in-flight webhooks)...\n", timeout, container.InFlight.Count())
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown error: %v\n", err)
+	}
+
+	unfinished := container.InFlight.Drain(ctx)
+	if len(unfinished) == 0 {
+		log.Println("All in-flight webhooks finished")
+		return
+	}
+
+	// Use a fresh context: the shutdown context has already expired
+	recordCtx, cancelRecord := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelRecord()
+	services.RecordUnfinishedJobs(recordCtx, container, unfinished)
+}
+
 func handleWebhook(w http.ResponseWriter, r *http.Request, config *configs.Config, container *services.ServiceContainer) {
 	// Record webhook received
 	container.MetricsCollector.RecordWebhookReceived()
@@ -217,3 +365,41 @@ func handleWebhook(w http.ResponseWriter, r *http.Request, config *configs.Confi
 	// Record processing time
 	container.MetricsCollector.RecordWebhookProcessed(time.Since(startTime))
 }
+
+func handleGitLabWebhook(w http.ResponseWriter, r *http.Request, config *configs.Config, container *services.ServiceContainer) {
+	container.MetricsCollector.RecordWebhookReceived()
+	startTime := time.Now()
+
+	baseCtx, rid := services.WithRequestID(r)
+	ctx, cancel := context.WithTimeout(baseCtx, 60*time.Second)
+	defer cancel()
+
+	r = r.WithContext(ctx)
+
+	services.LogWebhookOperation(ctx, "receive", "GitLab webhook received", nil, map[string]interface{}{
+		"request_id": rid,
+	})
+
+	services.HandleGitLabWebhookWithContainer(w, r, config, container)
+
+	container.MetricsCollector.RecordWebhookProcessed(time.Since(startTime))
+}
+
+func handleBitbucketWebhook(w http.ResponseWriter, r *http.Request, config *configs.Config, container *services.ServiceContainer) {
+	container.MetricsCollector.RecordWebhookReceived()
+	startTime := time.Now()
+
+	baseCtx, rid := services.WithRequestID(r)
+	ctx, cancel := context.WithTimeout(baseCtx, 60*time.Second)
+	defer cancel()
+
+	r = r.WithContext(ctx)
+
+	services.LogWebhookOperation(ctx, "receive", "Bitbucket webhook received", nil, map[string]interface{}{
+		"request_id": rid,
+	})
+
+	services.HandleBitbucketWebhookWithContainer(w, r, config, container)
+
+	container.MetricsCollector.RecordWebhookProcessed(time.Since(startTime))
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/services"
+)
+
+func main() {
+	// Command-line flags
+	envFile := flag.String("env", "./configs/.env", "Path to environment file")
+	destination := flag.String("destination", "", "Backfill workflows that copy to this repo (owner/repo)")
+	workflows := flag.String("workflow", "", "Backfill these workflows, comma-separated")
+	mode := flag.String("mode", services.BackfillModeSnapshot, "Files to replay: snapshot (every file) or prs (files changed by merged PRs)")
+	since := flag.String("since", "", "With -mode prs, only PRs merged on or after this date (YYYY-MM-DD)")
+	dryRun := flag.Bool("dry-run", false, "Report what would be copied without opening a PR")
+	help := flag.Bool("help", false, "Show help")
+
+	flag.Parse()
+
+	if *help {
+		printHelp()
+		return
+	}
+
+	opts := services.BackfillOptions{
+		Destination: *destination,
+		Mode:        *mode,
+		DryRun:      *dryRun,
+	}
+	if *workflows != "" {
+		for _, name := range strings.Split(*workflows, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.Workflows = append(opts.Workflows, name)
+			}
+		}
+	}
+	if len(opts.Workflows) == 0 && opts.Destination == "" {
+		fmt.Println("Error: -destination or -workflow is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *since != "" {
+		t, err := time.Parse("2006-01-02", *since)
+		if err != nil {
+			fmt.Printf("Error: invalid -since date %q (expected YYYY-MM-DD)\n", *since)
+			os.Exit(1)
+		}
+		opts.Since = t
+	}
+
+	config, err := configs.LoadEnvironment(*envFile)
+	if err != nil {
+		fmt.Printf("❌ Error loading environment: %v\n", err)
+		os.Exit(1)
+	}
+
+	container, err := services.NewServiceContainer(config)
+	if err != nil {
+		fmt.Printf("❌ Failed to initialize services: %v\n", err)
+		os.Exit(1)
+	}
+	defer container.Close(context.Background())
+
+	services.ConfigurePermissions()
+
+	result, err := services.RunBackfill(context.Background(), config, container, opts)
+	if err != nil {
+		fmt.Printf("❌ Backfill failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !printResult(result, opts.DryRun) {
+		os.Exit(1)
+	}
+}
+
+// printResult prints a summary of the backfill and returns false if anything failed
+func printResult(result *services.BackfillResult, dryRun bool) bool {
+	fmt.Println("\nSources:")
+	for _, source := range result.Sources {
+		fmt.Printf("  %s@%s (%s)\n", source.Repo, source.Branch, services.ShortSHA(source.CommitSHA))
+		if source.PRCount > 0 {
+			fmt.Printf("    %d file(s) from %d merged PR(s)\n", source.Files, source.PRCount)
+		} else {
+			fmt.Printf("    %d file(s)\n", source.Files)
+		}
+		fmt.Printf("    Workflows: %s\n", strings.Join(source.Workflows, ", "))
+	}
+
+	if dryRun {
+		fmt.Println("\nDry run - nothing was copied:")
+		for _, report := range result.DryRuns {
+			fmt.Printf("  %s: %d file(s)\n", report.Workflow, report.FileCount())
+			for _, target := range report.Targets {
+				for _, file := range target.Files {
+					fmt.Printf("    %s:%s %s\n", target.Repo, target.Branch, file)
+				}
+			}
+		}
+	} else {
+		keys := make([]string, 0, len(result.Uploads))
+		byName := make(map[string]services.UploadResult, len(result.Uploads))
+		for key, upload := range result.Uploads {
+			name := key.RepoName + ":" + key.BranchPath
+			keys = append(keys, name)
+			byName[name] = upload
+		}
+		sort.Strings(keys)
+
+		fmt.Println("\nDestinations:")
+		if len(keys) == 0 {
+			fmt.Println("  No files matched")
+		}
+		for _, name := range keys {
+			upload := byName[name]
+			switch {
+			case upload.Err != nil:
+				fmt.Printf("  ❌ %s: %v\n", name, upload.Err)
+			case upload.PRURL != "":
+				fmt.Printf("  ✅ %s: %s\n", name, upload.PRURL)
+			default:
+				fmt.Printf("  ✅ %s\n", name)
+			}
+		}
+	}
+
+	ok := true
+	for _, upload := range result.Uploads {
+		if upload.Err != nil {
+			ok = false
+		}
+	}
+	if len(result.Errors) > 0 {
+		ok = false
+		fmt.Println("\nErrors:")
+		for _, e := range result.Errors {
+			fmt.Printf("  ❌ %s\n", e)
+		}
+	}
+	return ok
+}
+
+func printHelp() {
+	fmt.Println(`backfill - Copy a source repo's existing examples to a destination
+
+Runs the configured workflows against every file in their source branch, or
+the files changed by its merged PRs, and opens a single pull request per
+destination branch. Use it when onboarding a new destination repo.
+
+Usage:
+  backfill -destination <owner/repo> [options]
+  backfill -workflow <name>[,<name>...] [options]
+
+Options:
+  -env <file>            Path to environment file (default: ./configs/.env)
+  -destination <repo>    Backfill workflows that copy to this repo
+  -workflow <names>      Backfill these workflows, comma-separated
+  -mode <mode>           snapshot (default) or prs
+  -since <YYYY-MM-DD>    With -mode prs, only PRs merged on or after this date
+  -dry-run               Report what would be copied without opening a PR
+  -help                  Show this help
+
+Examples:
+  # Preview the backfill for a new destination
+  backfill -destination mongodb/docs-sample-apps -dry-run
+
+  # Copy files changed by PRs merged this year
+  backfill -workflow python-examples -mode prs -since 2025-01-01`)
+}
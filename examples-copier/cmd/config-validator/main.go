@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"sort"
 	"strings"
 
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
 	"github.com/mongodb/code-example-tooling/code-copier/services"
 	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"golang.org/x/oauth2"
 )
 
 func main() {
@@ -21,10 +29,32 @@ func main() {
 	pattern := testPatternCmd.String("pattern", "", "Pattern to test (required)")
 	filePath := testPatternCmd.String("file", "", "File path to test against (required)")
 
+	testPatternsCmd := flag.NewFlagSet("test-patterns", flag.ExitOnError)
+	testPatternsFile := testPatternsCmd.String("config", "", "Path to config file (required)")
+	testPatternsPaths := testPatternsCmd.String("paths", "", "File with one source path per line, or - for stdin (required)")
+	testPatternsRepo := testPatternsCmd.String("repo", "", "Only test workflows that copy from this source repository")
+	testPatternsUnmatched := testPatternsCmd.Bool("unmatched", false, "List the paths no workflow copies")
+	testPatternsJSON := testPatternsCmd.Bool("json", false, "Output the results as JSON")
+
 	testTransformCmd := flag.NewFlagSet("test-transform", flag.ExitOnError)
 	transformSource := testTransformCmd.String("source", "", "Source file path (required)")
-	transformTemplate := testTransformCmd.String("template", "", "Transform template (required)")
+	transformTemplate := testTransformCmd.String("template", "", "Transform template (required unless -from is set)")
 	transformVars := testTransformCmd.String("vars", "", "Variables as key=value pairs, comma-separated")
+	transformFrom := testTransformCmd.String("from", "", "Test a move transformation from this path instead of a template")
+	transformTo := testTransformCmd.String("to", "", "Move transformation destination path (required with -from)")
+	transformStripPrefix := testTransformCmd.String("strip-prefix", "", "Move transformation strip_prefix (optional)")
+
+	diffCmd := flag.NewFlagSet("diff", flag.ExitOnError)
+	diffOld := diffCmd.String("old", "", "Path to the current config file (required)")
+	diffNew := diffCmd.String("new", "", "Path to the proposed config file (required)")
+	diffJSON := diffCmd.Bool("json", false, "Output the diff as JSON")
+
+	simulateCmd := flag.NewFlagSet("simulate", flag.ExitOnError)
+	simulateFile := simulateCmd.String("config", "", "Path to config file (required)")
+	simulateRepo := simulateCmd.String("repo", "", "Source repository as owner/name (required)")
+	simulateRef := simulateCmd.String("ref", "main", "Source branch to list the tree of")
+	simulateUnmatched := simulateCmd.Bool("unmatched", false, "List the files each workflow doesn't copy")
+	simulateJSON := simulateCmd.Bool("json", false, "Output the mapping as JSON")
 
 	initCmd := flag.NewFlagSet("init", flag.ExitOnError)
 	initTemplate := initCmd.String("template", "basic", "Template to use: basic, glob, or regex")
@@ -54,8 +84,30 @@ func main() {
 		}
 		testPattern(*patternType, *pattern, *filePath)
 
+	case "test-patterns":
+		testPatternsCmd.Parse(os.Args[2:])
+		if *testPatternsFile == "" || *testPatternsPaths == "" {
+			fmt.Println("Error: -config and -paths are required")
+			testPatternsCmd.Usage()
+			os.Exit(1)
+		}
+		testPatterns(*testPatternsFile, *testPatternsPaths, *testPatternsRepo, *testPatternsUnmatched, *testPatternsJSON)
+
 	case "test-transform":
 		testTransformCmd.Parse(os.Args[2:])
+		if *transformFrom != "" {
+			if *transformSource == "" || *transformTo == "" {
+				fmt.Println("Error: -source and -to are required with -from")
+				testTransformCmd.Usage()
+				os.Exit(1)
+			}
+			testMove(*transformSource, types.MoveTransform{
+				From:        *transformFrom,
+				To:          *transformTo,
+				StripPrefix: *transformStripPrefix,
+			})
+			break
+		}
 		if *transformSource == "" || *transformTemplate == "" {
 			fmt.Println("Error: -source and -template are required")
 			testTransformCmd.Usage()
@@ -63,6 +115,24 @@ func main() {
 		}
 		testTransform(*transformSource, *transformTemplate, *transformVars)
 
+	case "diff":
+		diffCmd.Parse(os.Args[2:])
+		if *diffOld == "" || *diffNew == "" {
+			fmt.Println("Error: -old and -new are required")
+			diffCmd.Usage()
+			os.Exit(1)
+		}
+		diffConfigs(*diffOld, *diffNew, *diffJSON)
+
+	case "simulate":
+		simulateCmd.Parse(os.Args[2:])
+		if *simulateFile == "" || *simulateRepo == "" {
+			fmt.Println("Error: -config and -repo are required")
+			simulateCmd.Usage()
+			os.Exit(1)
+		}
+		simulate(*simulateFile, *simulateRepo, *simulateRef, *simulateUnmatched, *simulateJSON)
+
 	case "init":
 		initCmd.Parse(os.Args[2:])
 		initConfig(*initTemplate, *initOutput)
@@ -82,13 +152,20 @@ func printUsage() {
 	fmt.Println("Commands:")
 	fmt.Println("  validate       Validate a workflow configuration file")
 	fmt.Println("  test-pattern   Test a pattern against a file path")
+	fmt.Println("  test-patterns  Test every workflow's patterns against a list of file paths")
 	fmt.Println("  test-transform Test a path transformation")
+	fmt.Println("  diff           Show how workflows change between two config files")
+	fmt.Println("  simulate       Map a repository's files through the workflows that copy from it")
 	fmt.Println("  init           Initialize a new workflow config file from template")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  config-validator validate -config .copier/workflows/config.yaml -v")
 	fmt.Println("  config-validator test-pattern -type glob -pattern 'examples/**/*.go' -file 'examples/go/main.go'")
+	fmt.Println("  config-validator test-patterns -config config.yaml -paths paths.txt")
 	fmt.Println("  config-validator test-transform -source 'examples/go/main.go' -template 'code/${filename}'")
+	fmt.Println("  config-validator test-transform -source 'examples/go/main.go' -from 'examples' -to 'code-examples'")
+	fmt.Println("  config-validator diff -old main-config.yaml -new config.yaml")
+	fmt.Println("  config-validator simulate -config config.yaml -repo mongodb/docs-code-examples -ref main")
 	fmt.Println("  config-validator init -template basic -output workflow-config.yaml")
 }
 
@@ -111,12 +188,15 @@ func validateConfig(configFile string, verbose bool) {
 	if verbose {
 		fmt.Println()
 		fmt.Printf("Number of Workflows: %d\n", len(config.Workflows))
+		fmt.Printf("Conflict Policy: %s\n", config.GetConflictPolicy())
 		fmt.Println()
 
-		for i, workflow := range config.Workflows {
+		// Workflows are listed in the order they process a file more than one matches
+		for i, workflow := range config.WorkflowsByPriority() {
 			fmt.Printf("Workflow %d: %s\n", i+1, workflow.Name)
+			fmt.Printf("  Priority: %d\n", workflow.Priority)
 			fmt.Printf("  Source: %s @ %s\n", workflow.Source.Repo, workflow.Source.Branch)
-			fmt.Printf("  Destination: %s @ %s\n", workflow.Destination.Repo, workflow.Destination.Branch)
+			fmt.Printf("  Destination: %s @ %s\n", workflow.Destination.Repo, strings.Join(workflow.Destination.BranchPatterns(), ", "))
 			fmt.Printf("  Transformations: %d\n", len(workflow.Transformations))
 			fmt.Printf("  Commit Strategy: %s\n", workflow.CommitStrategy.Type)
 			if workflow.DeprecationCheck != nil && workflow.DeprecationCheck.Enabled {
@@ -127,6 +207,163 @@ func validateConfig(configFile string, verbose bool) {
 	}
 }
 
+func loadConfigFile(configFile string) *types.YAMLConfig {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		fmt.Printf("❌ Error reading config file %s: %v\n", configFile, err)
+		os.Exit(1)
+	}
+
+	loader := services.NewConfigLoader()
+	config, err := loader.LoadConfigFromContent(string(content), configFile)
+	if err != nil {
+		fmt.Printf("❌ Config validation failed for %s: %v\n", configFile, err)
+		os.Exit(1)
+	}
+	return config
+}
+
+func diffConfigs(oldFile, newFile string, asJSON bool) {
+	diff := services.DiffConfigs(loadConfigFile(oldFile), loadConfigFile(newFile))
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(diff); err != nil {
+			fmt.Printf("❌ Error writing diff: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if diff.IsEmpty() {
+		fmt.Println("✅ No workflow changes")
+		return
+	}
+
+	fmt.Printf("Workflows: %d added, %d removed, %d renamed, %d changed\n",
+		len(diff.Added), len(diff.Removed), len(diff.Renamed), len(diff.Changed))
+
+	for _, w := range diff.Added {
+		fmt.Printf("\n+ Added: %s\n", w.Name)
+		fmt.Printf("    %s -> %s\n", w.Source, w.Destination)
+	}
+	for _, w := range diff.Removed {
+		fmt.Printf("\n- Removed: %s\n", w.Name)
+		fmt.Printf("    %s -> %s\n", w.Source, w.Destination)
+	}
+	for _, r := range diff.Renamed {
+		fmt.Printf("\n~ Renamed: %s -> %s\n", r.From, r.To)
+	}
+	for _, c := range diff.Changed {
+		fmt.Printf("\n~ Changed: %s\n", c.Workflow)
+		for _, change := range c.Changes {
+			switch {
+			case change.Old == "":
+				fmt.Printf("    %s: + %s\n", change.Field, change.New)
+			case change.New == "":
+				fmt.Printf("    %s: - %s\n", change.Field, change.Old)
+			default:
+				fmt.Printf("    %s: %s -> %s\n", change.Field, change.Old, change.New)
+			}
+		}
+	}
+}
+
+func simulate(configFile, repo, ref string, showUnmatched, asJSON bool) {
+	config := loadConfigFile(configFile)
+
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" {
+		fmt.Printf("❌ Invalid repository %q (must be owner/name)\n", repo)
+		os.Exit(1)
+	}
+
+	// GITHUB_TOKEN is only needed for private repos and to avoid the unauthenticated rate limit
+	var httpClient *http.Client
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		httpClient = oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+	}
+	client := github.NewClient(httpClient)
+	tree, truncated, err := services.GetRepoTree(context.Background(), client, owner, name, ref)
+	if err != nil {
+		fmt.Printf("❌ Error listing repository tree: %v\n", err)
+		os.Exit(1)
+	}
+	paths := make([]string, 0, len(tree))
+	for path := range tree {
+		paths = append(paths, path)
+	}
+
+	// Files the source repo's ignore file lists aren't copied, as when the copier loads its config
+	ignoreFile := configs.NewConfig().CopierIgnoreFile
+	if _, ok := tree[ignoreFile]; ok {
+		file, _, _, err := client.Repositories.GetContents(context.Background(), owner, name, ignoreFile,
+			&github.RepositoryContentGetOptions{Ref: ref})
+		var content string
+		if err == nil {
+			content, err = file.GetContent()
+		}
+		if err != nil {
+			fmt.Printf("❌ Error reading %s: %v\n", ignoreFile, err)
+			os.Exit(1)
+		}
+		for i := range config.Workflows {
+			if !config.Workflows[i].Source.IsBranchPattern() {
+				config.Workflows[i].SourceIgnore = strings.Split(content, "\n")
+			}
+		}
+	}
+
+	simulations := services.SimulateWorkflows(config, repo, ref, paths)
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(simulations); err != nil {
+			fmt.Printf("❌ Error writing mapping: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if truncated {
+		fmt.Println("⚠️  GitHub truncated the repository tree; some files are missing from the mapping")
+	}
+	if len(simulations) == 0 {
+		fmt.Printf("❌ No workflows copy from %s@%s\n", repo, ref)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Files in %s@%s: %d\n", repo, ref, len(paths))
+	for _, s := range simulations {
+		fmt.Printf("\nWorkflow: %s -> %s\n", s.Workflow, s.Destination)
+		for _, m := range s.Mappings {
+			fmt.Printf("  %s -> %s\n", m.Source, m.Target)
+		}
+		fmt.Printf("  %d copied, %d excluded, %d unmatched\n", len(s.Mappings), len(s.Excluded), len(s.Unmatched))
+		if showUnmatched {
+			for _, path := range s.Excluded {
+				fmt.Printf("  excluded: %s\n", path)
+			}
+			for _, path := range s.Unmatched {
+				fmt.Printf("  unmatched: %s\n", path)
+			}
+		}
+
+		targets := make([]string, 0, len(s.Collisions))
+		for target := range s.Collisions {
+			targets = append(targets, target)
+		}
+		sort.Strings(targets)
+		for _, target := range targets {
+			fmt.Printf("  ⚠️  %s is the target of %d files: %s\n", target, len(s.Collisions[target]), strings.Join(s.Collisions[target], ", "))
+		}
+	}
+}
+
 func testPattern(patternType, pattern, filePath string) {
 	var pt types.PatternType
 	switch patternType {
@@ -162,6 +399,70 @@ func testPattern(patternType, pattern, filePath string) {
 	}
 }
 
+func testPatterns(configFile, pathsFile, repo string, showUnmatched, asJSON bool) {
+	config := loadConfigFile(configFile)
+
+	var content []byte
+	var err error
+	if pathsFile == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(pathsFile)
+	}
+	if err != nil {
+		fmt.Printf("❌ Error reading paths: %v\n", err)
+		os.Exit(1)
+	}
+
+	result := services.MatchPatternCorpus(config, repo, strings.Split(string(content), "\n"))
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(result); err != nil {
+			fmt.Printf("❌ Error writing results: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(result.Workflows) == 0 {
+		fmt.Printf("❌ No workflows copy from %s\n", repo)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Paths: %d\n", result.Paths)
+	fmt.Printf("Matched: %d\n", result.Matched)
+	fmt.Printf("Unmatched: %d\n", len(result.Unmatched))
+	fmt.Printf("Matched by more than one workflow: %d\n", len(result.MultipleMatches))
+
+	for _, w := range result.Workflows {
+		fmt.Printf("\nWorkflow: %s (%s)\n", w.Workflow, w.Source)
+		fmt.Printf("  %d matched, %d excluded, %d unmatched\n", w.Matched, w.Excluded, w.Unmatched)
+	}
+
+	if len(result.MultipleMatches) > 0 {
+		paths := make([]string, 0, len(result.MultipleMatches))
+		for path := range result.MultipleMatches {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		fmt.Printf("\n⚠️  Paths matched by more than one workflow (conflict policy: %s):\n", config.GetConflictPolicy())
+		for _, path := range paths {
+			fmt.Printf("  %s: %s\n", path, strings.Join(result.MultipleMatches[path], ", "))
+		}
+	}
+
+	if showUnmatched && len(result.Unmatched) > 0 {
+		fmt.Println("\nPaths no workflow copies:")
+		for _, path := range result.Unmatched {
+			fmt.Printf("  %s\n", path)
+		}
+	}
+}
+
 func testTransform(source, template, varsStr string) {
 	variables := make(map[string]string)
 	if varsStr != "" {
@@ -186,6 +487,19 @@ func testTransform(source, template, varsStr string) {
 	fmt.Printf("Result: %s\n", result)
 }
 
+func testMove(source string, move types.MoveTransform) {
+	validator := services.NewConfigValidator()
+	result, err := validator.TestMove(source, move)
+	if err != nil {
+		fmt.Printf("❌ Transform error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("✅ Transform successful!")
+	fmt.Printf("Source: %s\n", source)
+	fmt.Printf("Result: %s\n", result)
+}
+
 func initConfig(templateName, output string) {
 	// Simple workflow config template
 	template := `# Workflow Configuration
@@ -11,6 +11,8 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
+	"strings"
 
 	"github.com/google/go-github/v48/github"
 )
@@ -23,6 +25,13 @@ func main() {
 	webhookURL := flag.String("url", "http://localhost:8080/events", "Webhook URL")
 	secret := flag.String("secret", "", "Webhook secret for signature")
 	payloadFile := flag.String("payload", "", "Path to custom payload JSON file")
+	fromGit := flag.String("from-git", "", "Print a PR payload synthesized from `git diff --name-status <range>` in a local repository; never sent")
+	gitDir := flag.String("git-dir", ".", "Local repository to read with -from-git")
+	baseBranch := flag.String("base", "main", "Base branch of the synthesized PR (with -from-git)")
+	comment := flag.String("comment", "", "Send an issue_comment payload for a comment with this body on the PR, like /copy")
+	association := flag.String("association", "OWNER", "Commenter's author_association (with -comment)")
+	dispatch := flag.Bool("dispatch", false, "Send a workflow_dispatch payload whose pr_number input is the PR")
+	eventType := flag.String("event", "", "X-GitHub-Event header (default: pull_request, issue_comment with -comment, or workflow_dispatch with -dispatch)")
 	dryRun := flag.Bool("dry-run", false, "Print payload without sending")
 	help := flag.Bool("help", false, "Show help")
 
@@ -44,8 +53,45 @@ func main() {
 			os.Exit(1)
 		}
 		fmt.Printf("✓ Loaded payload from %s\n", *payloadFile)
+	} else if *comment != "" {
+		// Option 2: Comment on a PR, like a copy command
+		payload = createCommentPayload(*owner, *repo, *prNumber, *comment, *association)
+		if *eventType == "" {
+			*eventType = "issue_comment"
+		}
+		fmt.Printf("✓ Created comment %q on PR #%d\n", *comment, prOrDefault(*prNumber))
+	} else if *dispatch {
+		// Option 3: Dispatch a workflow with the PR as its input
+		payload = createDispatchPayload(*owner, *repo, *prNumber)
+		if *eventType == "" {
+			*eventType = "workflow_dispatch"
+		}
+		fmt.Printf("✓ Created workflow dispatch for PR #%d\n", prOrDefault(*prNumber))
+	} else if *fromGit != "" {
+		// Option 4: Synthesize a PR from changes in a local repository. The copier lists a PR's files from
+		// GitHub by number rather than reading them from the payload, so sending it would copy whatever PR
+		// has that number; the payload is only printed.
+		if sent := sendFlagsSet(); len(sent) > 0 {
+			fmt.Printf("Error: -from-git payloads are only printed, never sent; remove %s\n", strings.Join(sent, ", "))
+			os.Exit(1)
+		}
+		*dryRun = true
+		var files []map[string]interface{}
+		payload, files, err = createGitDiffPayload(*gitDir, *fromGit, *baseBranch, *owner, *repo, *prNumber)
+		if err != nil {
+			fmt.Printf("Error reading local changes: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Synthesized PR from %d files changed in git diff %s\n", len(files), *fromGit)
+		for _, file := range files {
+			if previous, ok := file["previous_filename"]; ok {
+				fmt.Printf("  %-8s %s -> %s\n", file["status"], previous, file["filename"])
+			} else {
+				fmt.Printf("  %-8s %s\n", file["status"], file["filename"])
+			}
+		}
 	} else if *prNumber > 0 {
-		// Option 2: Fetch PR data from GitHub
+		// Option 5: Fetch PR data from GitHub
 		if *owner == "" || *repo == "" {
 			fmt.Println("Error: -owner and -repo are required when using -pr")
 			os.Exit(1)
@@ -58,26 +104,35 @@ func main() {
 		}
 		fmt.Printf("✓ Fetched PR #%d from %s/%s\n", *prNumber, *owner, *repo)
 	} else {
-		// Option 3: Use example payload
+		// Option 6: Use example payload
 		payload = createExamplePayload()
 		fmt.Println("✓ Using example payload")
 	}
 
+	if *eventType == "" {
+		*eventType = "pull_request"
+	}
+
 	// Pretty print payload if dry-run
 	if *dryRun {
-		fmt.Println("\n=== Payload ===")
+		fmt.Printf("\n=== Payload (%s) ===\n", *eventType)
 		var prettyJSON bytes.Buffer
 		if err := json.Indent(&prettyJSON, payload, "", "  "); err == nil {
 			fmt.Println(prettyJSON.String())
 		} else {
 			fmt.Println(string(payload))
 		}
-		fmt.Println("\n=== Dry-run mode: Not sending webhook ===")
+		if *fromGit != "" {
+			fmt.Println("\n=== -from-git payloads are never sent ===")
+			fmt.Println("To check which workflows match these files, pass the same range to config-validator test-patterns.")
+		} else {
+			fmt.Println("\n=== Dry-run mode: Not sending webhook ===")
+		}
 		return
 	}
 
 	// Send webhook
-	if err := sendWebhook(*webhookURL, payload, *secret); err != nil {
+	if err := sendWebhook(*webhookURL, payload, *secret, *eventType); err != nil {
 		fmt.Printf("Error sending webhook: %v\n", err)
 		os.Exit(1)
 	}
@@ -98,6 +153,14 @@ Options:
   -url string     Webhook URL (default: http://localhost:8080/events)
   -secret string  Webhook secret for HMAC signature
   -payload string Path to custom payload JSON file
+  -from-git range Print a PR payload synthesized from git diff --name-status <range>
+  -git-dir string Local repository to read with -from-git (default: .)
+  -base string    Base branch of the synthesized PR (default: main)
+  -comment string Send an issue_comment payload for a comment with this body on the PR
+  -association s  Commenter's author_association with -comment (default: OWNER)
+  -dispatch       Send a workflow_dispatch payload whose pr_number input is the PR
+  -event string   X-GitHub-Event header (default: pull_request, issue_comment
+                  with -comment, or workflow_dispatch with -dispatch)
   -dry-run        Print payload without sending
   -help           Show this help
 
@@ -112,6 +175,19 @@ Examples:
   # Use custom payload file
   test-webhook -payload webhook-payload.json
 
+  # Print a PR synthesized from commits not yet pushed, or from uncommitted changes
+  test-webhook -from-git origin/main..HEAD
+  test-webhook -from-git HEAD -git-dir ../docs-sample-apps
+
+  # Comment a copy command on a merged PR
+  test-webhook -comment /copy -pr 123 -owner myorg -repo myrepo
+
+  # Dispatch a workflow that copies a merged PR again
+  test-webhook -dispatch -pr 123 -owner myorg -repo myrepo
+
+  # Send a custom payload for another event type
+  test-webhook -payload comment.json -event issue_comment
+
   # Dry-run to see payload
   test-webhook -pr 123 -owner myorg -repo myrepo -dry-run
 
@@ -122,10 +198,30 @@ Examples:
 
 Environment Variables:
   GITHUB_TOKEN    GitHub personal access token (for fetching PR data)
+
+With -from-git, the payload is printed but never sent: the copier lists a PR's files
+from GitHub by number, so sending it would copy whatever PR has that number instead of
+the local changes. The repository is the -owner and -repo flags if set, or the local
+repository's origin remote. The PR number is -pr if set, or 0, which no PR has.
+
+With -comment or -dispatch, nothing is fetched from GitHub: the copier looks up the PR itself.
+The repository defaults to myorg/source-repo and the PR number to 42, like the
+example payload.
   WEBHOOK_SECRET  Default webhook secret (can be overridden with -secret)
 `)
 }
 
+// sendFlagsSet returns the flags set on the command line that only matter when a payload is sent
+func sendFlagsSet() []string {
+	var set []string
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "url" || f.Name == "secret" || f.Name == "event" {
+			set = append(set, "-"+f.Name)
+		}
+	})
+	return set
+}
+
 func fetchPRPayload(owner, repo string, prNumber int) ([]byte, error) {
 	token := os.Getenv("GITHUB_TOKEN")
 	if token == "" {
@@ -218,6 +314,146 @@ func fetchPRPayload(owner, repo string, prNumber int) ([]byte, error) {
 	return json.Marshal(payload)
 }
 
+// createGitDiffPayload synthesizes a merged pull_request payload for the files changed in a range of a
+// local repository, as git diff --name-status lists them. The range is anything git diff accepts: a
+// commit range like main..HEAD for unpushed commits, or a single commit like HEAD for uncommitted
+// changes. Files are listed in the payload's "files" field, in the format of GitHub's list PR files
+// API, which are also returned. The payload is for inspection only: its number is prNumber, or 0 if
+// that isn't set, and its merge commit is the local HEAD, which may not exist on GitHub.
+func createGitDiffPayload(dir, diffRange, baseBranch, owner, repo string, prNumber int) ([]byte, []map[string]interface{}, error) {
+	files, err := gitChangedFiles(dir, diffRange)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("git diff %s lists no changed files", diffRange)
+	}
+
+	if owner == "" || repo == "" {
+		remoteOwner, remoteRepo, err := gitRemoteRepo(dir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w; set -owner and -repo", err)
+		}
+		if owner == "" {
+			owner = remoteOwner
+		}
+		if repo == "" {
+			repo = remoteRepo
+		}
+	}
+	headSHA, err := runGit(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, nil, err
+	}
+	headRef, err := runGit(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, nil, err
+	}
+	fullName := fmt.Sprintf("%s/%s", owner, repo)
+	repoInfo := map[string]interface{}{
+		"name":      repo,
+		"full_name": fullName,
+	}
+	payload := map[string]interface{}{
+		"action": "closed",
+		"number": prNumber,
+		"pull_request": map[string]interface{}{
+			"number":           prNumber,
+			"title":            fmt.Sprintf("Local changes: git diff %s", diffRange),
+			"state":            "closed",
+			"merged":           true,
+			"merge_commit_sha": headSHA,
+			"changed_files":    len(files),
+			"head": map[string]interface{}{
+				"ref":  headRef,
+				"sha":  headSHA,
+				"repo": repoInfo,
+			},
+			"base": map[string]interface{}{
+				"ref":  baseBranch,
+				"repo": repoInfo,
+			},
+		},
+		"repository": map[string]interface{}{
+			"name":      repo,
+			"full_name": fullName,
+			"owner": map[string]interface{}{
+				"login": owner,
+			},
+		},
+		"files": files,
+	}
+
+	data, err := json.Marshal(payload)
+	return data, files, err
+}
+
+// gitChangedFiles lists the files changed in a range with git diff --name-status, with GitHub's
+// statuses: added, removed, modified, renamed, copied, or changed (for a file type change)
+func gitChangedFiles(dir, diffRange string) ([]map[string]interface{}, error) {
+	out, err := runGit(dir, "-c", "core.quotePath=false", "diff", "--name-status", "-M", diffRange, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []map[string]interface{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		file := map[string]interface{}{"filename": fields[len(fields)-1]}
+		switch fields[0][0] {
+		case 'A':
+			file["status"] = "added"
+		case 'D':
+			file["status"] = "removed"
+		case 'R':
+			file["status"] = "renamed"
+			file["previous_filename"] = fields[1]
+		case 'C':
+			file["status"] = "copied"
+			file["previous_filename"] = fields[1]
+		case 'T':
+			file["status"] = "changed"
+		default:
+			file["status"] = "modified"
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// gitRemoteRepo returns the owner and name of the GitHub repository the local repository's origin
+// remote points to, from an HTTPS or SSH URL
+func gitRemoteRepo(dir string) (string, string, error) {
+	url, err := runGit(dir, "remote", "get-url", "origin")
+	if err != nil {
+		return "", "", err
+	}
+	path := strings.TrimSuffix(url, ".git")
+	if i := strings.Index(path, "github.com"); i >= 0 {
+		path = path[i+len("github.com"):]
+	}
+	parts := strings.Split(strings.Trim(path, ":/"), "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", "", fmt.Errorf("can't read the repository from origin remote %s", url)
+	}
+	return parts[len(parts)-2], parts[len(parts)-1], nil
+}
+
+// runGit runs a git command in dir and returns its trimmed output
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 func createExamplePayload() []byte {
 	payload := map[string]interface{}{
 		"action": "closed",
@@ -256,14 +492,94 @@ func createExamplePayload() []byte {
 	return data
 }
 
-func sendWebhook(url string, payload []byte, secret string) error {
+// createCommentPayload returns an issue_comment payload for a new comment on a PR. Empty owner, repo,
+// and PR number default to the example payload's.
+func createCommentPayload(owner, repo string, prNumber int, body, association string) []byte {
+	if owner == "" {
+		owner = "myorg"
+	}
+	if repo == "" {
+		repo = "source-repo"
+	}
+	prNumber = prOrDefault(prNumber)
+	fullName := owner + "/" + repo
+
+	payload := map[string]interface{}{
+		"action": "created",
+		"issue": map[string]interface{}{
+			"number": prNumber,
+			"state":  "closed",
+			"pull_request": map[string]interface{}{
+				"url":      fmt.Sprintf("https://api.github.com/repos/%s/pulls/%d", fullName, prNumber),
+				"html_url": fmt.Sprintf("https://github.com/%s/pull/%d", fullName, prNumber),
+			},
+		},
+		"comment": map[string]interface{}{
+			"body":               body,
+			"author_association": association,
+			"user": map[string]interface{}{
+				"login": "test-webhook",
+			},
+		},
+		"repository": map[string]interface{}{
+			"name":      repo,
+			"full_name": fullName,
+			"owner": map[string]interface{}{
+				"login": owner,
+			},
+		},
+	}
+
+	data, _ := json.Marshal(payload)
+	return data
+}
+
+func createDispatchPayload(owner, repo string, prNumber int) []byte {
+	if owner == "" {
+		owner = "myorg"
+	}
+	if repo == "" {
+		repo = "source-repo"
+	}
+
+	payload := map[string]interface{}{
+		"inputs": map[string]interface{}{
+			"pr_number": fmt.Sprintf("%d", prOrDefault(prNumber)),
+		},
+		"ref":      "refs/heads/main",
+		"workflow": ".github/workflows/copy.yml",
+		"sender": map[string]interface{}{
+			"login": "test-webhook",
+		},
+		"repository": map[string]interface{}{
+			"name":      repo,
+			"full_name": owner + "/" + repo,
+			"owner": map[string]interface{}{
+				"login": owner,
+			},
+		},
+	}
+
+	data, _ := json.Marshal(payload)
+	return data
+}
+
+// prOrDefault returns the PR number, or the example payload's if it's not set
+func prOrDefault(prNumber int) int {
+	if prNumber > 0 {
+		return prNumber
+	}
+	return 42
+}
+
+func sendWebhook(url string, payload []byte, secret string, eventType string) error {
 	req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-GitHub-Event", "pull_request")
+	req.Header.Set("X-GitHub-Event", eventType)
 
 	// Add signature if secret provided
 	if secret != "" {
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/services"
+)
+
+func main() {
+	// Command-line flags
+	envFile := flag.String("env", "./configs/.env", "Path to environment file")
+	repo := flag.String("repo", "", "List writes copied from or to this repo (owner/repo)")
+	since := flag.String("since", "", "List writes on or after this date (YYYY-MM-DD)")
+	until := flag.String("until", "", "List writes on or before this date (YYYY-MM-DD)")
+	limit := flag.Int("limit", 100, "Maximum number of writes to list; 0 lists all")
+	asJSON := flag.Bool("json", false, "Output the writes as JSON")
+	help := flag.Bool("help", false, "Show help")
+
+	flag.Parse()
+
+	if *help {
+		printHelp()
+		return
+	}
+
+	query := services.WriteQuery{Repo: *repo, Limit: *limit}
+	if *since != "" {
+		query.Since = parseDate("-since", *since)
+	}
+	if *until != "" {
+		// Include the whole day
+		query.Until = parseDate("-until", *until).AddDate(0, 0, 1)
+	}
+
+	config, err := configs.LoadEnvironment(*envFile)
+	if err != nil {
+		fmt.Printf("❌ Error loading environment: %v\n", err)
+		os.Exit(1)
+	}
+	if err := services.LoadMongoURI(config); err != nil {
+		fmt.Printf("❌ Error loading MongoDB URI: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	mongoClient := services.NewSharedMongoClient(config.MongoURI)
+	defer mongoClient.Disconnect(ctx)
+	store, err := services.NewMongoWriteLogStore(ctx, mongoClient, config.AuditDatabase, config.WriteLogCollection)
+	if err != nil {
+		fmt.Printf("❌ Failed to connect to the write log: %v\n", err)
+		os.Exit(1)
+	}
+
+	records, err := store.List(ctx, query)
+	if err != nil {
+		fmt.Printf("❌ Failed to list writes: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Records are verified when the signing key is available
+	key := []byte(config.WriteLogSigningKey)
+	invalid := 0
+	for _, record := range records {
+		if len(key) > 0 && !services.VerifyWriteRecord(key, record) {
+			invalid++
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(records); err != nil {
+			fmt.Printf("❌ Error writing records: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		printRecords(records, key)
+	}
+
+	if invalid > 0 {
+		fmt.Fprintf(os.Stderr, "❌ %d record(s) failed signature verification\n", invalid)
+		os.Exit(1)
+	}
+}
+
+// parseDate parses a YYYY-MM-DD flag value as midnight UTC, exiting if it's invalid
+func parseDate(flagName, value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		fmt.Printf("Error: invalid %s date %q (expected YYYY-MM-DD)\n", flagName, value)
+		os.Exit(1)
+	}
+	return t
+}
+
+// printRecords prints each write, most recent first, marking records whose signature doesn't match
+func printRecords(records []*services.WriteRecord, key []byte) {
+	if len(records) == 0 {
+		fmt.Println("No writes found")
+		return
+	}
+	for _, record := range records {
+		status := "✅"
+		switch {
+		case len(key) == 0:
+			status = "•"
+		case !services.VerifyWriteRecord(key, record):
+			status = "❌ signature mismatch"
+		}
+
+		source := record.SourceRepo
+		if record.SourcePRNumber > 0 {
+			source = fmt.Sprintf("%s#%d", source, record.SourcePRNumber)
+		}
+		fmt.Printf("%s %s  %s@%s -> %s:%s\n", status, record.Timestamp.UTC().Format(time.RFC3339),
+			source, services.ShortSHA(record.SourceCommitSHA), record.TargetRepo, record.TargetBranch)
+		if len(record.Workflows) > 0 {
+			fmt.Printf("    Workflows: %s\n", strings.Join(record.Workflows, ", "))
+		}
+		if record.CommitSHA != "" {
+			fmt.Printf("    Commit: %s (%s)\n", record.CommitSHA, record.CommitStrategy)
+		}
+		if record.TargetPRURL != "" {
+			fmt.Printf("    PR: %s\n", record.TargetPRURL)
+		}
+		fmt.Printf("    Files: %d written, %d deleted\n", len(record.Files), len(record.Deletions))
+	}
+	fmt.Printf("\n%d write(s)\n", len(records))
+}
+
+func printHelp() {
+	fmt.Println(`write-log - List the commits and PRs the copier made
+
+Lists the records in the write log, most recent first, and verifies each
+record's signature with WRITE_LOG_SIGNING_KEY. Exits with status 1 if any
+record fails verification.
+
+Usage:
+  write-log [options]
+
+Options:
+  -env <file>            Path to environment file (default: ./configs/.env)
+  -repo <repo>           Only writes copied from or to this repo
+  -since <YYYY-MM-DD>    Only writes on or after this date (UTC)
+  -until <YYYY-MM-DD>    Only writes on or before this date (UTC)
+  -limit <n>             Maximum number of writes to list (default: 100; 0 lists all)
+  -json                  Output the writes as JSON
+  -help                  Show this help
+
+Examples:
+  # Writes to a destination repo in January
+  write-log -repo mongodb/docs-sample-apps -since 2025-01-01 -until 2025-01-31
+
+  # Export every write as JSON
+  write-log -limit 0 -json > writes.json`)
+}
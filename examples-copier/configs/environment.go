@@ -50,6 +50,30 @@ type Config struct {
 	SlackIconEmoji  string
 	SlackEnabled    bool
 
+	// GitLab merge request webhooks
+	GitLabWebhookPath   string
+	GitLabWebhookSecret string // Secret token GitLab sends in the X-Gitlab-Token header
+	GitLabBaseURL       string
+	GitLabToken         string // Access token used to read GitLab source repos
+
+	// Bitbucket Cloud pull request webhooks and destination repos
+	BitbucketWebhookPath   string
+	BitbucketWebhookSecret string // Secret Bitbucket signs payloads with, sent in the X-Hub-Signature header
+	BitbucketBaseURL       string
+	BitbucketUsername      string // Username for BitbucketToken when it's an app password; empty for access tokens
+	BitbucketToken         string // Access token or app password used to read and write Bitbucket repos
+
+	// GitHub Enterprise Server: API and upload base URLs for installations not on github.com
+	GitHubAPIBaseURL        string            // For INSTALLATION_ID and orgs without their own; empty for github.com
+	GitHubUploadBaseURL     string            // Defaults to the uploads URL of the GitHubAPIBaseURL instance
+	GitHubOrgAPIBaseURLs    map[string]string // Per-org API base URLs, keyed by org
+	GitHubOrgUploadBaseURLs map[string]string // Per-org upload base URLs, keyed by org
+
+	// Orgs whose installations belong to a GitHub App other than GITHUB_APP_ID
+	GitHubOrgAppIDs          map[string]string // App IDs, keyed by org
+	GitHubOrgAppKeys         map[string]string // Private key secret names (or env vars with SKIP_SECRET_MANAGER), keyed by org
+	GitHubOrgInstallationIDs map[string]string // Installation IDs, keyed by org; orgs without one are looked up
+
 	// GitHub API retry configuration
 	GitHubAPIMaxRetries        int
 	GitHubAPIInitialRetryDelay int // in milliseconds
@@ -57,6 +81,88 @@ type Config struct {
 	// PR merge polling configuration
 	PRMergePollMaxAttempts int
 	PRMergePollInterval    int // in milliseconds
+
+	// Graceful shutdown configuration
+	ShutdownTimeout int // in seconds
+
+	// Maintenance mode: accept webhooks but defer processing until maintenance ends
+	MaintenanceMode       bool
+	MaintenanceQueueFile  string // File where deferred deliveries are persisted for the "file" store
+	MaintenanceStore      string // "file" or "mongodb"
+	MaintenanceCollection string // MongoDB collection for the "mongodb" store, in AUDIT_DATABASE
+	AdminToken            string // Bearer token for the admin API; the API is disabled if empty
+
+	// Upload retry queue: retry uploads that failed with transient GitHub errors
+	UploadRetryMaxAttempts  int    // Retries before an upload is dead-lettered; 0 disables the retry queue
+	UploadRetryInitialDelay int    // in seconds, doubled after each failed retry
+	UploadRetryMaxDelay     int    // in seconds
+	UploadRetryStore        string // "memory" or "mongodb"
+	UploadRetryCollection   string // MongoDB collection for the "mongodb" store, in AUDIT_DATABASE
+
+	// Run history: recent webhook runs shown on the admin dashboard
+	RunHistorySize       int    // Runs to keep; 0 disables run history
+	RunHistoryStore      string // "memory" or "mongodb"
+	RunHistoryCollection string // MongoDB collection for the "mongodb" store, in AUDIT_DATABASE
+
+	// Webhook archive: raw webhook payloads kept so operators can replay a delivery through /admin/replay
+	WebhookArchiveSize       int    // Deliveries to keep; 0 disables the archive
+	WebhookArchiveStore      string // "memory" or "mongodb"
+	WebhookArchiveCollection string // MongoDB collection for the "mongodb" store, in AUDIT_DATABASE
+
+	// Write log: a signed record of every commit and PR the copier makes, in AUDIT_DATABASE
+	WriteLogEnabled    bool
+	WriteLogCollection string
+	WriteLogSigningKey string // HMAC key each record is signed with
+
+	// Loop prevention: label added to copier PRs so webhooks for them are skipped
+	CopierPRLabel string
+
+	// Copy commands: a PR comment starting with this command copies the merged PR again; empty disables them
+	CopyCommand string
+
+	// Branch commit strategy: days before an unchanged source PR branch is deleted; 0 keeps them
+	CopierBranchTTLDays int
+
+	// Source ignore file: gitignore-style file in source repos listing files not to copy; empty disables it
+	CopierIgnoreFile string
+
+	// Scheduling: limits on merged changes processed at once; 0 means no limit
+	MaxConcurrentRuns        int // Across all source repos
+	MaxConcurrentRunsPerRepo int // For one source repo
+
+	// Upload fan-out: destination repos uploaded to at once, and limits on GitHub requests per installation
+	UploadConcurrency        int // Destination repos uploaded to at once; 1 uploads one at a time
+	GitHubConcurrentRequests int // GitHub requests in flight per installation; 0 means no limit
+	GitHubWriteInterval      int // Minimum milliseconds between POST, PATCH, PUT, and DELETE requests per installation
+	GitHubRateLimitReserve   int // Requests left in the rate limit window at which requests pause until it resets; 0 never pauses early
+
+	// PR limits: PRs opened per destination repo, past which PRs are held and batched; 0 means no limit
+	PRLimitPerHour    int
+	PRLimitPerDay     int
+	PRLimitStore      string // "memory" or "mongodb"
+	PRLimitCollection string // MongoDB collection for the "mongodb" store, in AUDIT_DATABASE
+
+	// Config reload: re-fetch the copier config on an interval, keeping the last-known-good config if it fails
+	ConfigReloadInterval int // in seconds; 0 fetches the config for every webhook instead
+
+	// Reconciliation: compare destinations with their sources on an interval and open catch-up PRs for drift
+	ReconcileInterval int // in seconds; 0 only reconciles on request
+
+	// Leases: keep work that mustn't run on two instances at once, like reconciliation, to one instance
+	LeaseStore      string // "memory" or "mongodb"
+	LeaseCollection string // MongoDB collection for the "mongodb" store, in AUDIT_DATABASE
+
+	// Build verification: how often the check runs on commits from workflows with verify_build are polled
+	BuildCheckPollInterval int // in seconds
+
+	// Approval gate: how often PRs held by workflows' approval gates are checked for approval, and where they're kept
+	ApprovalGatePollInterval int    // in seconds
+	ApprovalGateStore        string // "memory" or "mongodb"
+	ApprovalGateCollection   string // MongoDB collection for the "mongodb" store, in AUDIT_DATABASE
+
+	// Examples mirror: read-only repo that workflows with mirror enabled also copy to, by product and language
+	MirrorRepo   string // "owner/name"; empty disables the mirror
+	MirrorBranch string
 }
 
 const (
@@ -95,10 +201,112 @@ const (
 	SlackUsername              = "SLACK_USERNAME"
 	SlackIconEmoji             = "SLACK_ICON_EMOJI"
 	SlackEnabled               = "SLACK_ENABLED"
+	GitLabWebhookPath          = "GITLAB_WEBHOOK_PATH"
+	GitLabWebhookSecret        = "GITLAB_WEBHOOK_SECRET"
+	GitLabBaseURL              = "GITLAB_BASE_URL"
+	GitLabToken                = "GITLAB_TOKEN"
+	BitbucketWebhookPath       = "BITBUCKET_WEBHOOK_PATH"
+	BitbucketWebhookSecret     = "BITBUCKET_WEBHOOK_SECRET"
+	BitbucketBaseURL           = "BITBUCKET_BASE_URL"
+	BitbucketUsername          = "BITBUCKET_USERNAME"
+	BitbucketToken             = "BITBUCKET_TOKEN"
+	GitHubAPIBaseURL           = "GITHUB_API_BASE_URL"
+	GitHubUploadBaseURL        = "GITHUB_UPLOAD_BASE_URL"
+	GitHubOrgAPIBaseURLs       = "GITHUB_ORG_API_BASE_URLS"
+	GitHubOrgUploadBaseURLs    = "GITHUB_ORG_UPLOAD_BASE_URLS"
+	GitHubOrgAppIDs            = "GITHUB_ORG_APP_IDS"
+	GitHubOrgAppKeys           = "GITHUB_ORG_APP_KEYS"
+	GitHubOrgInstallationIDs   = "GITHUB_ORG_INSTALLATION_IDS"
 	GitHubAPIMaxRetries        = "GITHUB_API_MAX_RETRIES"
 	GitHubAPIInitialRetryDelay = "GITHUB_API_INITIAL_RETRY_DELAY"
 	PRMergePollMaxAttempts     = "PR_MERGE_POLL_MAX_ATTEMPTS"
 	PRMergePollInterval        = "PR_MERGE_POLL_INTERVAL"
+	ShutdownTimeout            = "SHUTDOWN_TIMEOUT"
+	MaintenanceMode            = "MAINTENANCE_MODE"
+	MaintenanceQueueFile       = "MAINTENANCE_QUEUE_FILE"
+	MaintenanceStore           = "MAINTENANCE_STORE"
+	MaintenanceCollection      = "MAINTENANCE_COLLECTION"
+	AdminToken                 = "ADMIN_TOKEN"
+	UploadRetryMaxAttempts     = "UPLOAD_RETRY_MAX_ATTEMPTS"
+	UploadRetryInitialDelay    = "UPLOAD_RETRY_INITIAL_DELAY"
+	UploadRetryMaxDelay        = "UPLOAD_RETRY_MAX_DELAY"
+	UploadRetryStore           = "UPLOAD_RETRY_STORE"
+	UploadRetryCollection      = "UPLOAD_RETRY_COLLECTION"
+	RunHistorySize             = "RUN_HISTORY_SIZE"
+	RunHistoryStore            = "RUN_HISTORY_STORE"
+	RunHistoryCollection       = "RUN_HISTORY_COLLECTION"
+	WebhookArchiveSize         = "WEBHOOK_ARCHIVE_SIZE"
+	WebhookArchiveStore        = "WEBHOOK_ARCHIVE_STORE"
+	WebhookArchiveCollection   = "WEBHOOK_ARCHIVE_COLLECTION"
+	WriteLogEnabled            = "WRITE_LOG_ENABLED"
+	WriteLogCollection         = "WRITE_LOG_COLLECTION"
+	WriteLogSigningKey         = "WRITE_LOG_SIGNING_KEY"
+	CopierPRLabel              = "COPIER_PR_LABEL"
+	CopyCommand                = "COPY_COMMAND"
+	CopierBranchTTLDays        = "COPIER_BRANCH_TTL_DAYS"
+	CopierIgnoreFile           = "COPIER_IGNORE_FILE"
+	MaxConcurrentRuns          = "MAX_CONCURRENT_RUNS"
+	MaxConcurrentRunsPerRepo   = "MAX_CONCURRENT_RUNS_PER_REPO"
+	UploadConcurrency          = "UPLOAD_CONCURRENCY"
+	GitHubConcurrentRequests   = "GITHUB_MAX_CONCURRENT_REQUESTS"
+	GitHubWriteInterval        = "GITHUB_WRITE_INTERVAL_MS"
+	GitHubRateLimitReserve     = "GITHUB_RATE_LIMIT_RESERVE"
+	PRLimitPerHour             = "PR_LIMIT_PER_HOUR"
+	PRLimitPerDay              = "PR_LIMIT_PER_DAY"
+	PRLimitStore               = "PR_LIMIT_STORE"
+	PRLimitCollection          = "PR_LIMIT_COLLECTION"
+	ConfigReloadInterval       = "CONFIG_RELOAD_INTERVAL"
+	ReconcileInterval          = "RECONCILE_INTERVAL"
+	LeaseStore                 = "LEASE_STORE"
+	LeaseCollection            = "LEASE_COLLECTION"
+	BuildCheckPollInterval     = "BUILD_CHECK_POLL_INTERVAL"
+	ApprovalGatePollInterval   = "APPROVAL_GATE_POLL_INTERVAL"
+	ApprovalGateStore          = "APPROVAL_GATE_STORE"
+	ApprovalGateCollection     = "APPROVAL_GATE_COLLECTION"
+	MirrorRepo                 = "MIRROR_REPO"
+	MirrorBranch               = "MIRROR_BRANCH"
+)
+
+// Maintenance mode stores
+const (
+	MaintenanceStoreFile    = "file"
+	MaintenanceStoreMongoDB = "mongodb"
+)
+
+// Upload retry queue stores
+const (
+	UploadRetryStoreMemory  = "memory"
+	UploadRetryStoreMongoDB = "mongodb"
+)
+
+// Run history stores
+const (
+	RunHistoryStoreMemory  = "memory"
+	RunHistoryStoreMongoDB = "mongodb"
+)
+
+// PR limit stores
+const (
+	PRLimitStoreMemory  = "memory"
+	PRLimitStoreMongoDB = "mongodb"
+)
+
+// Lease stores
+const (
+	LeaseStoreMemory  = "memory"
+	LeaseStoreMongoDB = "mongodb"
+)
+
+// Approval gate stores
+const (
+	ApprovalGateStoreMemory  = "memory"
+	ApprovalGateStoreMongoDB = "mongodb"
+)
+
+// Webhook archive stores
+const (
+	WebhookArchiveStoreMemory  = "memory"
+	WebhookArchiveStoreMongoDB = "mongodb"
 )
 
 // NewConfig returns a new Config instance with default values
@@ -110,6 +318,10 @@ func NewConfig() *Config {
 		ConfigFile:                 "copier-config.yaml",
 		DeprecationFile:            "deprecated_examples.json",
 		WebserverPath:              "/webhook",
+		GitLabWebhookPath:          "/gitlab/webhook",
+		GitLabBaseURL:              "https://gitlab.com",
+		BitbucketWebhookPath:       "/bitbucket/webhook",
+		BitbucketBaseURL:           "https://api.bitbucket.org/2.0",
 		ConfigRepoBranch:           "main",                                                           // Default branch to fetch config file from
 		PEMKeyName:                 "projects/1054147886816/secrets/CODE_COPIER_PEM/versions/latest", // default secret name for GCP Secret Manager
 		WebhookSecretName:          "projects/1054147886816/secrets/webhook-secret/versions/latest",  // default webhook secret name for GCP Secret Manager
@@ -122,6 +334,42 @@ func NewConfig() *Config {
 		GitHubAPIInitialRetryDelay: 500,                                                              // default initial retry delay in milliseconds (exponential backoff)
 		PRMergePollMaxAttempts:     20,                                                               // default max attempts to poll PR for mergeability (~10 seconds with 500ms interval)
 		PRMergePollInterval:        500,                                                              // default polling interval in milliseconds
+		ShutdownTimeout:            25,                                                               // default seconds to wait for in-flight webhooks on shutdown (App Engine sends SIGKILL 30s after SIGTERM)
+		MaintenanceQueueFile:       "maintenance-queue.jsonl",                                        // default file for webhook deliveries deferred during maintenance
+		MaintenanceStore:           MaintenanceStoreFile,                                             // default maintenance store; the state is per instance
+		MaintenanceCollection:      "maintenance",                                                    // default MongoDB collection for the maintenance state and queue
+		UploadRetryMaxAttempts:     5,                                                                // default retries of a failed upload before it's dead-lettered
+		UploadRetryInitialDelay:    60,                                                               // default seconds before the first retry (exponential backoff)
+		UploadRetryMaxDelay:        1800,                                                             // default cap on the delay between retries, in seconds
+		UploadRetryStore:           UploadRetryStoreMemory,                                           // default retry queue store; pending retries are lost on restart
+		UploadRetryCollection:      "upload_retries",                                                 // default MongoDB collection for the retry queue
+		RunHistorySize:             100,                                                              // default number of webhook runs kept for the dashboard
+		RunHistoryStore:            RunHistoryStoreMemory,                                            // default run history store; history is lost on restart
+		RunHistoryCollection:       "webhook_runs",                                                   // default MongoDB collection for run history
+		WebhookArchiveSize:         100,                                                              // default number of webhook deliveries kept for replay
+		WebhookArchiveStore:        WebhookArchiveStoreMemory,                                        // default webhook archive store; deliveries are lost on restart
+		WebhookArchiveCollection:   "webhook_deliveries",                                             // default MongoDB collection for the webhook archive
+		WriteLogCollection:         "copier_writes",                                                  // default MongoDB collection for the write log
+		CopierPRLabel:              "examples-copier",                                                // default label for PRs the copier opens
+		CopyCommand:                "/copy",                                                          // default PR comment that copies a merged PR again
+		CopierBranchTTLDays:        14,                                                               // default days before stale source PR branches are deleted
+		CopierIgnoreFile:           ".copierignore",                                                  // default source repo file listing files not to copy
+		MaxConcurrentRuns:          4,                                                                // default merged changes processed at once
+		MaxConcurrentRunsPerRepo:   1,                                                                // default merged changes from one source repo processed at once
+		UploadConcurrency:          4,                                                                // default destination repos uploaded to at once
+		GitHubConcurrentRequests:   10,                                                               // default GitHub requests in flight per installation
+		GitHubWriteInterval:        1000,                                                             // default milliseconds between GitHub write requests per installation, per GitHub's guidance
+		GitHubRateLimitReserve:     100,                                                              // default GitHub requests left in the window at which requests pause until it resets
+		ConfigReloadInterval:       300,                                                              // default seconds between copier config reloads
+		BuildCheckPollInterval:     60,                                                               // default seconds between polls of destination check runs
+		PRLimitStore:               PRLimitStoreMemory,                                               // default PR limit store; each instance has its own budget
+		PRLimitCollection:          "pr_limits",                                                      // default MongoDB collection for PR limit budgets
+		LeaseStore:                 LeaseStoreMemory,                                                 // default lease store; leases only hold within one instance
+		LeaseCollection:            "leases",                                                         // default MongoDB collection for leases
+		ApprovalGatePollInterval:   60,                                                               // default seconds between checks of PRs held for approval
+		ApprovalGateStore:          ApprovalGateStoreMemory,                                          // default approval gate store; held PRs are left open on restart
+		ApprovalGateCollection:     "approval_gate",                                                  // default MongoDB collection for PRs held for approval
+		MirrorBranch:               "main",                                                           // default branch of the examples mirror repo
 	}
 }
 
@@ -195,6 +443,46 @@ func LoadEnvironment(envFile string) (*Config, error) {
 	config.SlackIconEmoji = getEnvWithDefault(SlackIconEmoji, ":robot_face:")
 	config.SlackEnabled = getBoolEnvWithDefault(SlackEnabled, config.SlackWebhookURL != "")
 
+	// GitLab merge request webhooks
+	config.GitLabWebhookPath = getEnvWithDefault(GitLabWebhookPath, config.GitLabWebhookPath)
+	config.GitLabWebhookSecret = os.Getenv(GitLabWebhookSecret)
+	config.GitLabBaseURL = strings.TrimSuffix(getEnvWithDefault(GitLabBaseURL, config.GitLabBaseURL), "/")
+	config.GitLabToken = os.Getenv(GitLabToken)
+
+	// Bitbucket Cloud pull request webhooks and destination repos
+	config.BitbucketWebhookPath = getEnvWithDefault(BitbucketWebhookPath, config.BitbucketWebhookPath)
+	config.BitbucketWebhookSecret = os.Getenv(BitbucketWebhookSecret)
+	config.BitbucketBaseURL = strings.TrimSuffix(getEnvWithDefault(BitbucketBaseURL, config.BitbucketBaseURL), "/")
+	config.BitbucketUsername = os.Getenv(BitbucketUsername)
+	config.BitbucketToken = os.Getenv(BitbucketToken)
+
+	// GitHub Enterprise Server
+	config.GitHubAPIBaseURL = os.Getenv(GitHubAPIBaseURL)
+	config.GitHubUploadBaseURL = os.Getenv(GitHubUploadBaseURL)
+	orgAPIBaseURLs, err := ParseOrgURLs(os.Getenv(GitHubOrgAPIBaseURLs))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", GitHubOrgAPIBaseURLs, err)
+	}
+	config.GitHubOrgAPIBaseURLs = orgAPIBaseURLs
+	orgUploadBaseURLs, err := ParseOrgURLs(os.Getenv(GitHubOrgUploadBaseURLs))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", GitHubOrgUploadBaseURLs, err)
+	}
+	config.GitHubOrgUploadBaseURLs = orgUploadBaseURLs
+
+	// GitHub Apps per org
+	for name, field := range map[string]*map[string]string{
+		GitHubOrgAppIDs:          &config.GitHubOrgAppIDs,
+		GitHubOrgAppKeys:         &config.GitHubOrgAppKeys,
+		GitHubOrgInstallationIDs: &config.GitHubOrgInstallationIDs,
+	} {
+		values, err := ParseOrgValues(os.Getenv(name))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*field = values
+	}
+
 	// GitHub API retry configuration
 	config.GitHubAPIMaxRetries = getIntEnvWithDefault(GitHubAPIMaxRetries, config.GitHubAPIMaxRetries)
 	config.GitHubAPIInitialRetryDelay = getIntEnvWithDefault(GitHubAPIInitialRetryDelay, config.GitHubAPIInitialRetryDelay)
@@ -203,6 +491,91 @@ func LoadEnvironment(envFile string) (*Config, error) {
 	config.PRMergePollMaxAttempts = getIntEnvWithDefault(PRMergePollMaxAttempts, config.PRMergePollMaxAttempts)
 	config.PRMergePollInterval = getIntEnvWithDefault(PRMergePollInterval, config.PRMergePollInterval)
 
+	// Graceful shutdown configuration
+	config.ShutdownTimeout = getIntEnvWithDefault(ShutdownTimeout, config.ShutdownTimeout)
+
+	// Maintenance mode
+	config.MaintenanceMode = getBoolEnvWithDefault(MaintenanceMode, false)
+	config.MaintenanceQueueFile = getEnvWithDefault(MaintenanceQueueFile, config.MaintenanceQueueFile)
+	config.MaintenanceStore = strings.ToLower(getEnvWithDefault(MaintenanceStore, config.MaintenanceStore))
+	config.MaintenanceCollection = getEnvWithDefault(MaintenanceCollection, config.MaintenanceCollection)
+	config.AdminToken = os.Getenv(AdminToken)
+
+	// Upload retry queue
+	config.UploadRetryMaxAttempts = getIntEnvWithDefault(UploadRetryMaxAttempts, config.UploadRetryMaxAttempts)
+	config.UploadRetryInitialDelay = getIntEnvWithDefault(UploadRetryInitialDelay, config.UploadRetryInitialDelay)
+	config.UploadRetryMaxDelay = getIntEnvWithDefault(UploadRetryMaxDelay, config.UploadRetryMaxDelay)
+	config.UploadRetryStore = strings.ToLower(getEnvWithDefault(UploadRetryStore, config.UploadRetryStore))
+	config.UploadRetryCollection = getEnvWithDefault(UploadRetryCollection, config.UploadRetryCollection)
+
+	// Run history
+	config.RunHistorySize = getIntEnvWithDefault(RunHistorySize, config.RunHistorySize)
+	config.RunHistoryStore = strings.ToLower(getEnvWithDefault(RunHistoryStore, config.RunHistoryStore))
+	config.RunHistoryCollection = getEnvWithDefault(RunHistoryCollection, config.RunHistoryCollection)
+
+	// Webhook archive
+	config.WebhookArchiveSize = getIntEnvWithDefault(WebhookArchiveSize, config.WebhookArchiveSize)
+	config.WebhookArchiveStore = strings.ToLower(getEnvWithDefault(WebhookArchiveStore, config.WebhookArchiveStore))
+	config.WebhookArchiveCollection = getEnvWithDefault(WebhookArchiveCollection, config.WebhookArchiveCollection)
+
+	// Write log
+	config.WriteLogEnabled = getBoolEnvWithDefault(WriteLogEnabled, false)
+	config.WriteLogCollection = getEnvWithDefault(WriteLogCollection, config.WriteLogCollection)
+	config.WriteLogSigningKey = os.Getenv(WriteLogSigningKey)
+
+	// Loop prevention
+	config.CopierPRLabel = getEnvWithDefault(CopierPRLabel, config.CopierPRLabel)
+
+	// Copy commands. An empty COPY_COMMAND turns them off, so it isn't replaced with the default
+	if command, ok := os.LookupEnv(CopyCommand); ok {
+		config.CopyCommand = strings.TrimSpace(command)
+	}
+
+	// Branch commit strategy
+	config.CopierBranchTTLDays = getIntEnvWithDefault(CopierBranchTTLDays, config.CopierBranchTTLDays)
+	// An empty COPIER_IGNORE_FILE turns source ignore files off, so it isn't replaced with the default
+	if ignoreFile, ok := os.LookupEnv(CopierIgnoreFile); ok {
+		config.CopierIgnoreFile = ignoreFile
+	}
+
+	// Scheduling
+	config.MaxConcurrentRuns = getIntEnvWithDefault(MaxConcurrentRuns, config.MaxConcurrentRuns)
+	config.MaxConcurrentRunsPerRepo = getIntEnvWithDefault(MaxConcurrentRunsPerRepo, config.MaxConcurrentRunsPerRepo)
+
+	// Upload fan-out
+	config.UploadConcurrency = getIntEnvWithDefault(UploadConcurrency, config.UploadConcurrency)
+	config.GitHubConcurrentRequests = getIntEnvWithDefault(GitHubConcurrentRequests, config.GitHubConcurrentRequests)
+	config.GitHubWriteInterval = getIntEnvWithDefault(GitHubWriteInterval, config.GitHubWriteInterval)
+	config.GitHubRateLimitReserve = getIntEnvWithDefault(GitHubRateLimitReserve, config.GitHubRateLimitReserve)
+
+	// PR limits
+	config.PRLimitPerHour = getIntEnvWithDefault(PRLimitPerHour, config.PRLimitPerHour)
+	config.PRLimitPerDay = getIntEnvWithDefault(PRLimitPerDay, config.PRLimitPerDay)
+	config.PRLimitStore = strings.ToLower(getEnvWithDefault(PRLimitStore, config.PRLimitStore))
+	config.PRLimitCollection = getEnvWithDefault(PRLimitCollection, config.PRLimitCollection)
+
+	// Config reload
+	config.ConfigReloadInterval = getIntEnvWithDefault(ConfigReloadInterval, config.ConfigReloadInterval)
+
+	// Reconciliation
+	config.ReconcileInterval = getIntEnvWithDefault(ReconcileInterval, config.ReconcileInterval)
+
+	// Leases
+	config.LeaseStore = strings.ToLower(getEnvWithDefault(LeaseStore, config.LeaseStore))
+	config.LeaseCollection = getEnvWithDefault(LeaseCollection, config.LeaseCollection)
+
+	// Build verification
+	config.BuildCheckPollInterval = getIntEnvWithDefault(BuildCheckPollInterval, config.BuildCheckPollInterval)
+
+	// Approval gate
+	config.ApprovalGatePollInterval = getIntEnvWithDefault(ApprovalGatePollInterval, config.ApprovalGatePollInterval)
+	config.ApprovalGateStore = strings.ToLower(getEnvWithDefault(ApprovalGateStore, config.ApprovalGateStore))
+	config.ApprovalGateCollection = getEnvWithDefault(ApprovalGateCollection, config.ApprovalGateCollection)
+
+	// Examples mirror
+	config.MirrorRepo = os.Getenv(MirrorRepo)
+	config.MirrorBranch = getEnvWithDefault(MirrorBranch, config.MirrorBranch)
+
 	// Export resolved values back into environment so downstream os.Getenv sees defaults
 	_ = os.Setenv(Port, config.Port)
 	_ = os.Setenv(ConfigRepoName, config.ConfigRepoName)
@@ -224,6 +597,7 @@ func LoadEnvironment(envFile string) (*Config, error) {
 	_ = os.Setenv(DefaultRecursiveCopy, fmt.Sprintf("%t", config.DefaultRecursiveCopy))
 	_ = os.Setenv(DefaultPRMerge, fmt.Sprintf("%t", config.DefaultPRMerge))
 	_ = os.Setenv(DefaultCommitMessage, config.DefaultCommitMessage)
+	_ = os.Setenv(GitLabBaseURL, config.GitLabBaseURL)
 
 	if err := validateConfig(config); err != nil {
 		return nil, err
@@ -263,6 +637,55 @@ func getIntEnvWithDefault(key string, defaultValue int) int {
 	return intValue
 }
 
+// ParseOrgURLs parses a comma-separated list of org=url pairs, such as
+// "mirrors=https://ghes.example.com/api/v3/", into URLs keyed by org
+func ParseOrgURLs(value string) (map[string]string, error) {
+	urls, err := parseOrgPairs(value, "url")
+	if err != nil {
+		return nil, err
+	}
+	for org, url := range urls {
+		if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+			return nil, fmt.Errorf("URL for org %s must start with https:// or http://, got %q", org, url)
+		}
+	}
+	return urls, nil
+}
+
+// ParseOrgValues parses a comma-separated list of org=value pairs, such as "mirrors=123456", into values
+// keyed by org
+func ParseOrgValues(value string) (map[string]string, error) {
+	return parseOrgPairs(value, "value")
+}
+
+// parseOrgPairs parses a comma-separated list of org=value pairs, naming the value kind in errors
+func parseOrgPairs(value string, kind string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		org, v, ok := strings.Cut(pair, "=")
+		org, v = strings.TrimSpace(org), strings.TrimSpace(v)
+		if !ok || org == "" || v == "" {
+			return nil, fmt.Errorf("%q is not an org=%s pair", pair, kind)
+		}
+		values[org] = v
+	}
+	return values, nil
+}
+
+// GitLabEnabled returns true if the GitLab webhook endpoint should be served
+func (c *Config) GitLabEnabled() bool {
+	return c.GitLabWebhookSecret != "" || c.GitLabToken != ""
+}
+
+// BitbucketEnabled returns true if the Bitbucket webhook endpoint should be served
+func (c *Config) BitbucketEnabled() bool {
+	return c.BitbucketWebhookSecret != "" || c.BitbucketToken != ""
+}
+
 // validateConfig checks if all required configuration values are set
 func validateConfig(config *Config) error {
 	var missingVars []string
@@ -284,5 +707,45 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("missing required environment variables: %s", strings.Join(missingVars, ", "))
 	}
 
+	// An org with its own GitHub App needs both the App's ID and its private key
+	for org := range config.GitHubOrgAppIDs {
+		if _, ok := config.GitHubOrgAppKeys[org]; !ok {
+			return fmt.Errorf("%s has an App ID for %s but %s has no private key for it", GitHubOrgAppIDs, org, GitHubOrgAppKeys)
+		}
+	}
+	for org := range config.GitHubOrgAppKeys {
+		if _, ok := config.GitHubOrgAppIDs[org]; !ok {
+			return fmt.Errorf("%s has a private key for %s but %s has no App ID for it", GitHubOrgAppKeys, org, GitHubOrgAppIDs)
+		}
+	}
+
+	if config.MaintenanceStore != MaintenanceStoreFile && config.MaintenanceStore != MaintenanceStoreMongoDB {
+		return fmt.Errorf("%s must be %q or %q, got %q", MaintenanceStore, MaintenanceStoreFile, MaintenanceStoreMongoDB, config.MaintenanceStore)
+	}
+
+	if config.UploadRetryStore != UploadRetryStoreMemory && config.UploadRetryStore != UploadRetryStoreMongoDB {
+		return fmt.Errorf("%s must be %q or %q, got %q", UploadRetryStore, UploadRetryStoreMemory, UploadRetryStoreMongoDB, config.UploadRetryStore)
+	}
+
+	if config.RunHistoryStore != RunHistoryStoreMemory && config.RunHistoryStore != RunHistoryStoreMongoDB {
+		return fmt.Errorf("%s must be %q or %q, got %q", RunHistoryStore, RunHistoryStoreMemory, RunHistoryStoreMongoDB, config.RunHistoryStore)
+	}
+
+	if config.PRLimitStore != PRLimitStoreMemory && config.PRLimitStore != PRLimitStoreMongoDB {
+		return fmt.Errorf("%s must be %q or %q, got %q", PRLimitStore, PRLimitStoreMemory, PRLimitStoreMongoDB, config.PRLimitStore)
+	}
+
+	if config.LeaseStore != LeaseStoreMemory && config.LeaseStore != LeaseStoreMongoDB {
+		return fmt.Errorf("%s must be %q or %q, got %q", LeaseStore, LeaseStoreMemory, LeaseStoreMongoDB, config.LeaseStore)
+	}
+
+	if config.ApprovalGateStore != ApprovalGateStoreMemory && config.ApprovalGateStore != ApprovalGateStoreMongoDB {
+		return fmt.Errorf("%s must be %q or %q, got %q", ApprovalGateStore, ApprovalGateStoreMemory, ApprovalGateStoreMongoDB, config.ApprovalGateStore)
+	}
+
+	if config.WebhookArchiveStore != WebhookArchiveStoreMemory && config.WebhookArchiveStore != WebhookArchiveStoreMongoDB {
+		return fmt.Errorf("%s must be %q or %q, got %q", WebhookArchiveStore, WebhookArchiveStoreMemory, WebhookArchiveStoreMongoDB, config.WebhookArchiveStore)
+	}
+
 	return nil
 }
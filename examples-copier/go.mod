@@ -10,6 +10,7 @@ require (
 	github.com/google/go-github/v48 v48.2.0
 	github.com/jarcoal/httpmock v1.4.0
 	github.com/joho/godotenv v1.5.1
+	github.com/mongodb/code-example-tooling/code-copier/notify v0.0.0
 	github.com/pkg/errors v0.9.1
 	github.com/shurcooL/githubv4 v0.0.0-20240727222349-48295856cce7
 	github.com/shurcooL/graphql v0.0.0-20230722043721-ed46e5a46466
@@ -19,6 +20,8 @@ require (
 	gopkg.in/yaml.v3 v3.0.1
 )
 
+replace github.com/mongodb/code-example-tooling/code-copier/notify => ./notify
+
 require (
 	cloud.google.com/go v0.118.3 // indirect
 	cloud.google.com/go/auth v0.15.0 // indirect
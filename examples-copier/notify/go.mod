@@ -0,0 +1,3 @@
+module github.com/mongodb/code-example-tooling/code-copier/notify
+
+go 1.24.0
@@ -0,0 +1,110 @@
+// Package notify sends messages to Slack incoming webhooks.
+//
+// It's a separate module with no dependencies outside the standard library, so other tools in this repo (such as
+// the GDCD audit tool) can depend on it without depending on the copier.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Attachment colors understood by Slack
+const (
+	ColorGood    = "good"    // green
+	ColorWarning = "warning" // yellow
+	ColorDanger  = "danger"  // red
+)
+
+// Message represents a Slack message
+type Message struct {
+	Channel     string       `json:"channel,omitempty"`
+	Username    string       `json:"username,omitempty"`
+	IconEmoji   string       `json:"icon_emoji,omitempty"`
+	Text        string       `json:"text,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment represents a Slack message attachment
+type Attachment struct {
+	Color      string  `json:"color,omitempty"`
+	Title      string  `json:"title,omitempty"`
+	TitleLink  string  `json:"title_link,omitempty"`
+	Text       string  `json:"text,omitempty"`
+	Fields     []Field `json:"fields,omitempty"`
+	Footer     string  `json:"footer,omitempty"`
+	FooterIcon string  `json:"footer_icon,omitempty"`
+	Timestamp  int64   `json:"ts,omitempty"`
+}
+
+// Field represents a field in a Slack attachment
+type Field struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// Client posts messages to a Slack incoming webhook
+type Client struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the given webhook URL. An empty URL creates a disabled client.
+func NewClient(webhookURL string) *Client {
+	return &Client{
+		webhookURL: webhookURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// IsEnabled returns true if the client has a webhook URL to post to
+func (c *Client) IsEnabled() bool {
+	return c.webhookURL != ""
+}
+
+// Send posts a message to the webhook. It does nothing if the client is disabled.
+func (c *Client) Send(ctx context.Context, message *Message) error {
+	if !c.IsEnabled() {
+		return nil
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.webhookURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create slack request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack returned non-200 status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// FormatList formats items as a bulleted list, one item per line
+func FormatList(items []string) string {
+	result := ""
+	for _, item := range items {
+		result += "• " + item + "\n"
+	}
+	return result
+}
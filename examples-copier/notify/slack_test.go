@@ -0,0 +1,81 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/code-copier/notify"
+)
+
+func TestClient_Send(t *testing.T) {
+	var received notify.Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode message: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := notify.NewClient(server.URL)
+	if !client.IsEnabled() {
+		t.Fatal("expected a client with a webhook URL to be enabled")
+	}
+
+	err := client.Send(context.Background(), &notify.Message{
+		Channel: "#test",
+		Attachments: []notify.Attachment{
+			{Color: notify.ColorGood, Title: "Done", Fields: []notify.Field{{Title: "Count", Value: "3", Short: true}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if received.Channel != "#test" {
+		t.Errorf("Channel = %q, want #test", received.Channel)
+	}
+	if len(received.Attachments) != 1 {
+		t.Fatalf("got %d attachments, want 1", len(received.Attachments))
+	}
+	if received.Attachments[0].Title != "Done" || received.Attachments[0].Fields[0].Value != "3" {
+		t.Errorf("unexpected attachment: %+v", received.Attachments[0])
+	}
+}
+
+func TestClient_SendNon200(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := notify.NewClient(server.URL).Send(context.Background(), &notify.Message{Text: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Send() error = %v, want a 403 error", err)
+	}
+}
+
+func TestClient_DisabledSendIsNoop(t *testing.T) {
+	client := notify.NewClient("")
+	if client.IsEnabled() {
+		t.Error("expected a client without a webhook URL to be disabled")
+	}
+	if err := client.Send(context.Background(), &notify.Message{Text: "hi"}); err != nil {
+		t.Errorf("Send() error = %v, want nil", err)
+	}
+}
+
+func TestFormatList(t *testing.T) {
+	if got := notify.FormatList([]string{"a.go", "b.go"}); got != "• a.go\n• b.go\n" {
+		t.Errorf("FormatList() = %q", got)
+	}
+	if got := notify.FormatList(nil); got != "" {
+		t.Errorf("FormatList(nil) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,480 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// PendingApproval is a PR the copier opened for a workflow with auto_merge and an approval gate, waiting to
+// be approved before it's merged
+type PendingApproval struct {
+	ID             string                    `bson:"_id" json:"id"` // The target repo and PR number
+	WorkflowName   string                    `bson:"workflow_name" json:"workflow_name"`
+	SourceRepo     string                    `bson:"source_repo" json:"source_repo"`
+	PRNumber       int                       `bson:"pr_number" json:"pr_number"` // The source PR that was copied, or 0 for a push
+	PRURL          string                    `bson:"pr_url,omitempty" json:"pr_url,omitempty"`
+	TargetRepo     string                    `bson:"target_repo" json:"target_repo"` // "owner/name"
+	TargetBranch   string                    `bson:"target_branch" json:"target_branch"`
+	TargetPRNumber int                       `bson:"target_pr_number" json:"target_pr_number"` // The copier's PR in the target repo
+	TargetPRURL    string                    `bson:"target_pr_url" json:"target_pr_url"`
+	Gate           *types.ApprovalGateConfig `bson:"gate" json:"gate"`
+	Deadline       time.Time                 `bson:"deadline" json:"deadline"`
+	Notifications  *types.NotificationConfig `bson:"notifications,omitempty" json:"notifications,omitempty"` // The workflow's notifications, or nil for the service's
+	CorrelationID  string                    `bson:"correlation_id,omitempty" json:"correlation_id,omitempty"`
+	// NextCheck is when the PR is next checked for approval
+	NextCheck time.Time `bson:"next_check" json:"next_check"`
+	// LeaseOwner is the instance checking the PR, until LeaseUntil
+	LeaseOwner string    `bson:"lease_owner,omitempty" json:"lease_owner,omitempty"`
+	LeaseUntil time.Time `bson:"lease_until,omitempty" json:"lease_until,omitempty"`
+}
+
+// approvalLease is how long an instance holds a PR it claimed to check. If the instance stops before
+// finishing, the PR is checked again when the lease ends.
+const approvalLease = 5 * time.Minute
+
+// approvalPR is the state of a held PR
+type approvalPR struct {
+	Open       bool
+	HeadSHA    string
+	HeadBranch string
+}
+
+// approvalAPI reads held PRs, and merges or closes them
+type approvalAPI interface {
+	GetPullRequest(ctx context.Context, repo string, number int) (approvalPR, error)
+	ListCheckRuns(ctx context.Context, repo string, sha string) ([]*github.CheckRun, error)
+	ListReviews(ctx context.Context, repo string, number int) ([]*github.PullRequestReview, error)
+	MergePullRequest(ctx context.Context, repo string, number int) (string, error)
+	ClosePullRequest(ctx context.Context, repo string, number int) error
+	DeleteBranch(ctx context.Context, repo string, branch string)
+}
+
+// ApprovalGate polls the PRs held by workflows' approval gates, merging each once its required check runs
+// pass and one of its reviewers approves it. PRs that aren't approved before the gate's timeout are left
+// open, merged, or closed, per the gate's on_timeout, and reported to the workflow's Slack notifications.
+// Pending PRs are kept in the store; with the in-memory store, they're left open for review after a restart.
+type ApprovalGate struct {
+	store        ApprovalStore
+	pollInterval time.Duration
+	auditLogger  AuditLogger
+	config       *configs.Config
+	api          approvalAPI
+	now          func() time.Time
+	owner        string // Claims PRs for this instance
+}
+
+// NewApprovalGate creates an approval gate backed by store that polls every APPROVAL_GATE_POLL_INTERVAL
+// seconds
+func NewApprovalGate(store ApprovalStore, config *configs.Config, auditLogger AuditLogger) *ApprovalGate {
+	return &ApprovalGate{
+		store:        store,
+		pollInterval: time.Duration(config.ApprovalGatePollInterval) * time.Second,
+		auditLogger:  auditLogger,
+		config:       config,
+		api:          githubApprovalAPI{},
+		now:          time.Now,
+		owner:        instanceID,
+	}
+}
+
+// Hold adds a PR to wait for approval. It's first checked at the next poll.
+func (g *ApprovalGate) Hold(ctx context.Context, approval *PendingApproval) {
+	approval.ID = fmt.Sprintf("%s#%d", approval.TargetRepo, approval.TargetPRNumber)
+	approval.NextCheck = g.now()
+	if err := g.store.Save(ctx, approval); err != nil {
+		LogErrorCtx(ctx, "failed to hold PR for approval; it's left open for review", err, map[string]interface{}{
+			"workflow_name": approval.WorkflowName,
+			"target_repo":   approval.TargetRepo,
+			"pr_number":     approval.TargetPRNumber,
+		})
+		return
+	}
+	LogInfoCtx(ctx, "holding PR for approval before merging", map[string]interface{}{
+		"workflow_name": approval.WorkflowName,
+		"target_repo":   approval.TargetRepo,
+		"pr_number":     approval.TargetPRNumber,
+		"deadline":      approval.Deadline,
+	})
+}
+
+// Pending returns the PRs still waiting for approval
+func (g *ApprovalGate) Pending(ctx context.Context) ([]*PendingApproval, error) {
+	return g.store.List(ctx)
+}
+
+// ProcessDue claims and checks each held PR that's due. PRs that are approved are merged, and PRs whose
+// deadline has passed get the gate's on_timeout action; both, and PRs merged or closed by someone else,
+// stop being checked. Other PRs are checked again after pollInterval. PRs due within half an interval are
+// checked now, so a poll that starts a little early doesn't skip them.
+func (g *ApprovalGate) ProcessDue(ctx context.Context) {
+	dueBy := g.now().Add(g.pollInterval / 2)
+	seen := make(map[string]bool)
+	for ctx.Err() == nil {
+		approval, err := g.store.Claim(ctx, g.owner, dueBy, g.now(), approvalLease)
+		if err != nil {
+			LogErrorCtx(ctx, "failed to claim PR held for approval", err, nil)
+			return
+		}
+		if approval == nil {
+			return
+		}
+		if seen[approval.ID] {
+			// Already checked in this poll; leave it for the next one
+			approval.NextCheck = g.now()
+			g.release(ctx, approval)
+			return
+		}
+		seen[approval.ID] = true
+
+		if g.check(WithCorrelationID(ctx, approval.CorrelationID), approval) {
+			if err := g.store.Delete(ctx, approval.ID, g.owner); err != nil {
+				LogErrorCtx(ctx, "failed to remove PR from approval gate", err, map[string]interface{}{"id": approval.ID})
+			}
+			continue
+		}
+		approval.NextCheck = g.now().Add(g.pollInterval)
+		g.release(ctx, approval)
+	}
+}
+
+// release saves a PR that's still waiting for approval, to be checked again at its next check
+func (g *ApprovalGate) release(ctx context.Context, approval *PendingApproval) {
+	if err := g.store.Release(ctx, approval, g.owner); err != nil {
+		LogErrorCtx(ctx, "failed to reschedule PR held for approval", err, map[string]interface{}{"id": approval.ID})
+	}
+}
+
+// Run checks held PRs every pollInterval until ctx is cancelled
+func (g *ApprovalGate) Run(ctx context.Context) {
+	if g.pollInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(g.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.ProcessDue(ctx)
+		}
+	}
+}
+
+// check checks one held PR, returning true once it's done with the PR
+func (g *ApprovalGate) check(ctx context.Context, approval *PendingApproval) bool {
+	fields := map[string]interface{}{
+		"workflow_name": approval.WorkflowName,
+		"target_repo":   approval.TargetRepo,
+		"pr_number":     approval.TargetPRNumber,
+	}
+	expired := !g.now().Before(approval.Deadline)
+
+	pr, err := g.api.GetPullRequest(ctx, approval.TargetRepo, approval.TargetPRNumber)
+	if err != nil {
+		fields["error"] = err.Error()
+		LogWarningCtx(ctx, "failed to get PR held for approval", fields)
+		if expired {
+			g.report(ctx, approval, nil, []string{"the PR couldn't be read"}, "left open", true)
+		}
+		return expired
+	}
+	if !pr.Open {
+		LogInfoCtx(ctx, "PR held for approval was merged or closed outside the copier", fields)
+		return true
+	}
+
+	var runs []*github.CheckRun
+	var reviews []*github.PullRequestReview
+	if len(approval.Gate.Checks) > 0 {
+		runs, err = g.api.ListCheckRuns(ctx, approval.TargetRepo, pr.HeadSHA)
+	}
+	if err == nil && len(approval.Gate.Reviewers) > 0 {
+		reviews, err = g.api.ListReviews(ctx, approval.TargetRepo, approval.TargetPRNumber)
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		LogWarningCtx(ctx, "failed to check approval of held PR", fields)
+		if expired {
+			g.report(ctx, approval, nil, []string{"the PR's checks and reviews couldn't be read"}, "left open", true)
+		}
+		return expired
+	}
+
+	approved, failed, waiting := approvalStatus(approval.Gate, runs, reviews)
+	if approved {
+		LogInfoCtx(ctx, "held PR approved, merging", fields)
+		g.merge(ctx, approval, pr, nil, nil, false)
+		return true
+	}
+	if !expired {
+		return false
+	}
+
+	switch approval.Gate.GetOnTimeout() {
+	case types.ApprovalOnTimeoutMerge:
+		if len(failed) == 0 {
+			g.merge(ctx, approval, pr, failed, waiting, true)
+			return true
+		}
+		g.report(ctx, approval, failed, waiting, "left open because required checks failed", true)
+	case types.ApprovalOnTimeoutClose:
+		action := "closed"
+		if err := g.api.ClosePullRequest(ctx, approval.TargetRepo, approval.TargetPRNumber); err != nil {
+			action = fmt.Sprintf("left open: failed to close: %v", err)
+		} else {
+			g.api.DeleteBranch(ctx, approval.TargetRepo, pr.HeadBranch)
+		}
+		g.report(ctx, approval, failed, waiting, action, true)
+	default:
+		g.report(ctx, approval, failed, waiting, "left open", true)
+	}
+	return true
+}
+
+// merge merges a held PR and deletes its branch. A failed merge is reported; a merge after the timeout is
+// reported either way, so the workflow's owners know it wasn't approved.
+func (g *ApprovalGate) merge(ctx context.Context, approval *PendingApproval, pr approvalPR, failed []string,
+	waiting []string, timedOut bool) {
+
+	sha, err := g.api.MergePullRequest(ctx, approval.TargetRepo, approval.TargetPRNumber)
+	if err != nil {
+		g.report(ctx, approval, failed, waiting, fmt.Sprintf("left open: failed to merge: %v", err), timedOut)
+		return
+	}
+	g.api.DeleteBranch(ctx, approval.TargetRepo, pr.HeadBranch)
+	LogInfoCtx(ctx, "merged PR held for approval", map[string]interface{}{
+		"workflow_name": approval.WorkflowName,
+		"target_repo":   approval.TargetRepo,
+		"pr_number":     approval.TargetPRNumber,
+		"merge_sha":     sha,
+		"timed_out":     timedOut,
+	})
+	if timedOut {
+		g.report(ctx, approval, failed, waiting, "merged", true)
+	}
+}
+
+// report records a held PR that wasn't merged as approved in the audit log and sends it to the workflow's
+// notifications. action is what the copier did with the PR.
+func (g *ApprovalGate) report(ctx context.Context, approval *PendingApproval, failed []string, waiting []string,
+	action string, timedOut bool) {
+
+	message := fmt.Sprintf("approved PR #%d couldn't be merged: %s", approval.TargetPRNumber, action)
+	if timedOut {
+		message = fmt.Sprintf("PR #%d wasn't approved before the approval_gate timeout, %s", approval.TargetPRNumber, action)
+	}
+	LogErrorCtx(ctx, message, nil, map[string]interface{}{
+		"workflow_name": approval.WorkflowName,
+		"target_repo":   approval.TargetRepo,
+		"pr_number":     approval.TargetPRNumber,
+		"failed_checks": failed,
+		"waiting_on":    waiting,
+	})
+
+	if g.auditLogger != nil {
+		if err := g.auditLogger.LogErrorEvent(ctx, &AuditEvent{
+			SourceRepo:   approval.SourceRepo,
+			TargetRepo:   approval.TargetRepo,
+			PRNumber:     approval.PRNumber,
+			ErrorMessage: message,
+			AdditionalData: map[string]any{
+				"approval_gate":    true,
+				"workflow_name":    approval.WorkflowName,
+				"target_pr_number": approval.TargetPRNumber,
+				"target_pr_url":    approval.TargetPRURL,
+				"failed_checks":    failed,
+				"waiting_on":       waiting,
+				"timed_out":        timedOut,
+				"action":           action,
+			},
+		}); err != nil {
+			LogWarning(fmt.Sprintf("Failed to record held PR #%d in audit log: %v", approval.TargetPRNumber, err))
+		}
+	}
+
+	notifications := approval.Notifications
+	if notifications == nil {
+		notifications = &types.NotificationConfig{}
+	}
+	event := &ApprovalGateEvent{
+		WorkflowName:   approval.WorkflowName,
+		PRNumber:       approval.PRNumber,
+		PRURL:          approval.PRURL,
+		SourceRepo:     approval.SourceRepo,
+		TargetRepo:     approval.TargetRepo,
+		TargetBranch:   approval.TargetBranch,
+		TargetPRNumber: approval.TargetPRNumber,
+		TargetPRURL:    approval.TargetPRURL,
+		FailedChecks:   failed,
+		WaitingOn:      waiting,
+		TimedOut:       timedOut,
+		Action:         action,
+	}
+	if err := workflowNotifier(notifications, g.config).NotifyApprovalGate(ctx, event); err != nil {
+		LogWarningCtx(ctx, "failed to send approval gate notification", map[string]interface{}{
+			"workflow_name": approval.WorkflowName,
+			"error":         err.Error(),
+		})
+	}
+}
+
+// approvalStatus returns whether a held PR with the given check runs and reviews passes gate, the required
+// check runs that failed, and the check runs and reviews still being waited for
+func approvalStatus(gate *types.ApprovalGateConfig, runs []*github.CheckRun, reviews []*github.PullRequestReview) (
+	approved bool, failed []string, waiting []string) {
+
+	if len(gate.Checks) > 0 {
+		matched, missing := filterCheckRuns(runs, gate.Checks)
+		for _, run := range matched {
+			switch {
+			case run.GetStatus() != "completed":
+				waiting = append(waiting, run.GetName()+": "+run.GetStatus())
+			case failedCheckConclusions[run.GetConclusion()]:
+				failed = append(failed, run.GetName()+": "+run.GetConclusion())
+			}
+		}
+		for _, name := range missing {
+			waiting = append(waiting, name+": not reported")
+		}
+	}
+
+	if len(gate.Reviewers) > 0 && !approvedByReviewer(gate.Reviewers, reviews) {
+		waiting = append(waiting, "approval from "+strings.Join(gate.Reviewers, " or "))
+	}
+
+	sort.Strings(failed)
+	return len(failed) == 0 && len(waiting) == 0, failed, waiting
+}
+
+// approvedByReviewer returns true if one of reviewers' latest review approves the PR. Comments don't
+// replace an earlier approval; a dismissal or request for changes does.
+func approvedByReviewer(reviewers []string, reviews []*github.PullRequestReview) bool {
+	latest := make(map[string]string)
+	for _, review := range reviews {
+		switch state := review.GetState(); state {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			latest[strings.ToLower(review.GetUser().GetLogin())] = state
+		}
+	}
+	for _, reviewer := range reviewers {
+		if latest[strings.ToLower(strings.TrimSpace(reviewer))] == "APPROVED" {
+			return true
+		}
+	}
+	return false
+}
+
+// githubApprovalAPI is the approvalAPI for GitHub repos
+type githubApprovalAPI struct{}
+
+func (githubApprovalAPI) GetPullRequest(ctx context.Context, repo string, number int) (approvalPR, error) {
+	owner, name := parseRepoPath(repo)
+	client, err := GetRestClientForOrg(owner)
+	if err != nil {
+		return approvalPR{}, fmt.Errorf("get GitHub client for org %s: %w", owner, err)
+	}
+	pr, _, err := client.PullRequests.Get(ctx, owner, name, number)
+	if err != nil {
+		return approvalPR{}, err
+	}
+	return approvalPR{
+		Open:       pr.GetState() == "open",
+		HeadSHA:    pr.GetHead().GetSHA(),
+		HeadBranch: pr.GetHead().GetRef(),
+	}, nil
+}
+
+func (githubApprovalAPI) ListCheckRuns(ctx context.Context, repo string, sha string) ([]*github.CheckRun, error) {
+	return listGitHubCheckRuns(ctx, repo, sha)
+}
+
+func (githubApprovalAPI) ListReviews(ctx context.Context, repo string, number int) ([]*github.PullRequestReview, error) {
+	owner, name := parseRepoPath(repo)
+	client, err := GetRestClientForOrg(owner)
+	if err != nil {
+		return nil, fmt.Errorf("get GitHub client for org %s: %w", owner, err)
+	}
+
+	var reviews []*github.PullRequestReview
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.PullRequests.ListReviews(ctx, owner, name, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, page...)
+		if resp.NextPage == 0 {
+			return reviews, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (githubApprovalAPI) MergePullRequest(ctx context.Context, repo string, number int) (string, error) {
+	provider, repo, err := repoProviderFor(repo)
+	if err != nil {
+		return "", err
+	}
+	return provider.MergePullRequest(ctx, repo, number)
+}
+
+func (githubApprovalAPI) ClosePullRequest(ctx context.Context, repo string, number int) error {
+	owner, name := parseRepoPath(repo)
+	client, err := GetRestClientForOrg(owner)
+	if err != nil {
+		return fmt.Errorf("get GitHub client for org %s: %w", owner, err)
+	}
+	_, _, err = client.PullRequests.Edit(ctx, owner, name, number, &github.PullRequest{State: github.String("closed")})
+	return err
+}
+
+func (githubApprovalAPI) DeleteBranch(ctx context.Context, repo string, branch string) {
+	provider, repo, err := repoProviderFor(repo)
+	if err != nil {
+		LogWarningCtx(ctx, "failed to delete branch of held PR", map[string]interface{}{"target_repo": repo, "error": err.Error()})
+		return
+	}
+	provider.DeleteBranch(ctx, repo, branch)
+}
+
+// holdForApproval holds each PR opened for a workflow with an approval gate until it's approved
+func holdForApproval(ctx context.Context, gate *ApprovalGate, change CopyEvent, runs []*workflowRun,
+	uploads map[types.UploadKey]UploadResult) {
+
+	if gate == nil {
+		return
+	}
+	for _, run := range runs {
+		upload, ok := uploads[run.uploadKey()]
+		if !ok || !upload.AwaitingApproval || run.DryRun != nil {
+			continue
+		}
+		approvalGate := getApprovalGate(run.Workflow)
+		if approvalGate == nil {
+			continue
+		}
+		_, repo := types.SplitDestinationRepo(run.Workflow.Destination.Repo)
+		gate.Hold(ctx, &PendingApproval{
+			WorkflowName:   run.Workflow.Name,
+			SourceRepo:     change.Repo,
+			PRNumber:       change.Number,
+			PRURL:          change.URL,
+			TargetRepo:     repo,
+			TargetBranch:   run.Workflow.Destination.Branch,
+			TargetPRNumber: upload.PRNumber,
+			TargetPRURL:    upload.PRURL,
+			Gate:           approvalGate,
+			Deadline:       gate.now().Add(approvalGate.GetTimeout()),
+			Notifications:  run.Workflow.Notifications,
+			CorrelationID:  CorrelationIDFromContext(ctx),
+		})
+	}
+}
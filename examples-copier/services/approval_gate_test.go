@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeApprovalAPI serves one held PR from memory and records what the gate does with it
+type fakeApprovalAPI struct {
+	pr       approvalPR
+	runs     []*github.CheckRun
+	reviews  []*github.PullRequestReview
+	mergeErr error
+	merged   []int
+	closed   []int
+	deleted  []string
+}
+
+func (f *fakeApprovalAPI) GetPullRequest(ctx context.Context, repo string, number int) (approvalPR, error) {
+	return f.pr, nil
+}
+
+func (f *fakeApprovalAPI) ListCheckRuns(ctx context.Context, repo string, sha string) ([]*github.CheckRun, error) {
+	return f.runs, nil
+}
+
+func (f *fakeApprovalAPI) ListReviews(ctx context.Context, repo string, number int) ([]*github.PullRequestReview, error) {
+	return f.reviews, nil
+}
+
+func (f *fakeApprovalAPI) MergePullRequest(ctx context.Context, repo string, number int) (string, error) {
+	if f.mergeErr != nil {
+		return "", f.mergeErr
+	}
+	f.merged = append(f.merged, number)
+	f.pr.Open = false
+	return "merge-sha", nil
+}
+
+func (f *fakeApprovalAPI) ClosePullRequest(ctx context.Context, repo string, number int) error {
+	f.closed = append(f.closed, number)
+	f.pr.Open = false
+	return nil
+}
+
+func (f *fakeApprovalAPI) DeleteBranch(ctx context.Context, repo string, branch string) {
+	f.deleted = append(f.deleted, branch)
+}
+
+// pendingApprovals returns the PRs the gate holds, failing the test if they can't be read
+func pendingApprovals(t *testing.T, g *ApprovalGate) []*PendingApproval {
+	t.Helper()
+	pending, err := g.Pending(context.Background())
+	require.NoError(t, err)
+	return pending
+}
+
+func review(login string, state string) *github.PullRequestReview {
+	return &github.PullRequestReview{User: &github.User{Login: github.String(login)}, State: github.String(state)}
+}
+
+// newTestApprovalGate returns an approval gate with a fake clock and API, holding one PR with gate
+func newTestApprovalGate(t *testing.T, gate *types.ApprovalGateConfig) (*ApprovalGate, *time.Time, *fakeApprovalAPI, *recordingAuditLogger, func() []SlackMessage) {
+	server, messages := slackRecorder(t)
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	api := &fakeApprovalAPI{pr: approvalPR{Open: true, HeadSHA: "head-sha", HeadBranch: "copier/20250101-120000"}}
+	audit := &recordingAuditLogger{}
+
+	g := NewApprovalGate(NewMemoryApprovalStore(), &configs.Config{SlackWebhookURL: server.URL, ApprovalGatePollInterval: 60}, audit)
+	g.now = func() time.Time { return clock }
+	g.api = api
+	g.Hold(context.Background(), &PendingApproval{
+		WorkflowName:   "app",
+		SourceRepo:     "org/src",
+		PRNumber:       42,
+		TargetRepo:     "org/app",
+		TargetBranch:   "main",
+		TargetPRNumber: 7,
+		TargetPRURL:    "https://github.com/org/app/pull/7",
+		Gate:           gate,
+		Deadline:       clock.Add(gate.GetTimeout()),
+	})
+	return g, &clock, api, audit, messages
+}
+
+func TestApprovalGate_MergesOnceApproved(t *testing.T) {
+	g, clock, api, audit, messages := newTestApprovalGate(t, &types.ApprovalGateConfig{
+		Checks:    []string{"build"},
+		Reviewers: []string{"Docs-Lead"},
+	})
+
+	api.runs = []*github.CheckRun{checkRun(1, "build", "in_progress", "")}
+	g.ProcessDue(context.Background())
+	assert.Len(t, pendingApprovals(t, g), 1, "waits while a required check is in progress")
+
+	// Not checked again until the next poll
+	api.runs = []*github.CheckRun{checkRun(1, "build", "completed", "success")}
+	api.reviews = []*github.PullRequestReview{review("docs-lead", "APPROVED")}
+	g.ProcessDue(context.Background())
+	assert.Empty(t, api.merged)
+
+	api.reviews = []*github.PullRequestReview{review("someone", "APPROVED")}
+	*clock = clock.Add(time.Minute)
+	g.ProcessDue(context.Background())
+	assert.Len(t, pendingApprovals(t, g), 1, "waits for a named reviewer")
+
+	api.reviews = append(api.reviews, review("docs-lead", "APPROVED"))
+	*clock = clock.Add(time.Minute)
+	g.ProcessDue(context.Background())
+	assert.Len(t, pendingApprovals(t, g), 0)
+	assert.Equal(t, []int{7}, api.merged)
+	assert.Equal(t, []string{"copier/20250101-120000"}, api.deleted)
+	assert.Empty(t, audit.errors)
+	assert.Empty(t, messages())
+}
+
+func TestApprovalGate_ClosedOutsideCopier(t *testing.T) {
+	g, _, api, _, messages := newTestApprovalGate(t, &types.ApprovalGateConfig{Reviewers: []string{"docs-lead"}})
+
+	api.pr.Open = false
+	g.ProcessDue(context.Background())
+	assert.Len(t, pendingApprovals(t, g), 0)
+	assert.Empty(t, api.merged)
+	assert.Empty(t, messages())
+}
+
+func TestApprovalGate_MergeFailure(t *testing.T) {
+	g, _, api, audit, messages := newTestApprovalGate(t, &types.ApprovalGateConfig{Reviewers: []string{"docs-lead"}})
+
+	api.reviews = []*github.PullRequestReview{review("docs-lead", "APPROVED")}
+	api.mergeErr = errors.New("merge conflict")
+	g.ProcessDue(context.Background())
+	assert.Len(t, pendingApprovals(t, g), 0)
+
+	require.Len(t, audit.errors, 1)
+	assert.Equal(t, false, audit.errors[0].AdditionalData["timed_out"])
+	require.Len(t, messages(), 1)
+	assert.Contains(t, messages()[0].Attachments[0].Title, "Couldn't Be Merged")
+}
+
+func TestApprovalGate_Timeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		onTimeout string
+		runs      []*github.CheckRun
+		merged    bool
+		closed    bool
+		action    string
+	}{
+		{name: "leave open", onTimeout: "", action: "left open"},
+		{name: "merge", onTimeout: types.ApprovalOnTimeoutMerge, merged: true, action: "merged"},
+		{
+			name:      "merge with failed checks",
+			onTimeout: types.ApprovalOnTimeoutMerge,
+			runs:      []*github.CheckRun{checkRun(1, "build", "completed", "failure")},
+			action:    "left open because required checks failed",
+		},
+		{name: "close", onTimeout: types.ApprovalOnTimeoutClose, closed: true, action: "closed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, clock, api, audit, messages := newTestApprovalGate(t, &types.ApprovalGateConfig{
+				Checks:         []string{"build"},
+				Reviewers:      []string{"docs-lead"},
+				TimeoutMinutes: 60,
+				OnTimeout:      tt.onTimeout,
+			})
+			api.runs = tt.runs
+
+			*clock = clock.Add(59 * time.Minute)
+			g.ProcessDue(context.Background())
+			assert.Len(t, pendingApprovals(t, g), 1)
+
+			*clock = clock.Add(time.Minute)
+			g.ProcessDue(context.Background())
+			assert.Len(t, pendingApprovals(t, g), 0)
+			assert.Equal(t, tt.merged, len(api.merged) == 1)
+			assert.Equal(t, tt.closed, len(api.closed) == 1)
+
+			require.Len(t, audit.errors, 1)
+			assert.Equal(t, tt.action, audit.errors[0].AdditionalData["action"])
+			assert.Equal(t, true, audit.errors[0].AdditionalData["timed_out"])
+			require.Len(t, messages(), 1)
+			assert.Contains(t, messages()[0].Attachments[0].Title, "Wasn't Approved in Time")
+		})
+	}
+}
+
+func TestApprovalGate_InstancesSharingStore(t *testing.T) {
+	ctx := context.Background()
+	g, clock, api, _, _ := newTestApprovalGate(t, &types.ApprovalGateConfig{Reviewers: []string{"docs-lead"}})
+	other := NewApprovalGate(g.store, g.config, nil)
+	other.now = g.now
+	other.owner = "other-instance"
+	otherAPI := &fakeApprovalAPI{pr: api.pr}
+	other.api = otherAPI
+
+	// While one instance holds the claim, the other skips the PR
+	claimed, err := g.store.Claim(ctx, g.owner, *clock, *clock, approvalLease)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	other.ProcessDue(ctx)
+	assert.Empty(t, otherAPI.merged)
+	assert.ErrorIs(t, g.store.Release(ctx, claimed, other.owner), errApprovalLeaseLost)
+	require.NoError(t, g.store.Delete(ctx, claimed.ID, other.owner))
+	assert.Len(t, pendingApprovals(t, g), 1)
+
+	// A PR held by one instance is merged by whichever instance polls once it's approved
+	claimed.NextCheck = *clock
+	require.NoError(t, g.store.Release(ctx, claimed, g.owner))
+	otherAPI.reviews = []*github.PullRequestReview{review("docs-lead", "APPROVED")}
+	other.ProcessDue(ctx)
+	assert.Equal(t, []int{7}, otherAPI.merged)
+	assert.Empty(t, pendingApprovals(t, g))
+
+	*clock = clock.Add(time.Minute)
+	g.ProcessDue(ctx)
+	assert.Empty(t, api.merged)
+}
+
+func TestApprovalStatus(t *testing.T) {
+	gate := &types.ApprovalGateConfig{Checks: []string{"build", "test"}, Reviewers: []string{"alice", "bob"}}
+
+	approved, failed, waiting := approvalStatus(gate, []*github.CheckRun{
+		checkRun(1, "build", "completed", "failure"),
+		checkRun(2, "lint", "completed", "failure"),
+	}, nil)
+	assert.False(t, approved)
+	assert.Equal(t, []string{"build: failure"}, failed, "only required checks count")
+	assert.Equal(t, []string{"test: not reported", "approval from alice or bob"}, waiting)
+
+	approved, failed, waiting = approvalStatus(gate, []*github.CheckRun{
+		checkRun(1, "build", "completed", "failure"),
+		checkRun(3, "build", "completed", "success"),
+		checkRun(4, "test", "completed", "skipped"),
+	}, []*github.PullRequestReview{review("bob", "APPROVED")})
+	assert.True(t, approved, "the latest run of a re-run check counts")
+	assert.Empty(t, failed)
+	assert.Empty(t, waiting)
+}
+
+func TestApprovedByReviewer(t *testing.T) {
+	reviewers := []string{"alice"}
+	assert.False(t, approvedByReviewer(reviewers, nil))
+	assert.True(t, approvedByReviewer(reviewers, []*github.PullRequestReview{
+		review("alice", "APPROVED"),
+		review("alice", "COMMENTED"),
+	}), "comments don't replace an approval")
+	assert.False(t, approvedByReviewer(reviewers, []*github.PullRequestReview{
+		review("alice", "APPROVED"),
+		review("alice", "DISMISSED"),
+	}))
+	assert.False(t, approvedByReviewer(reviewers, []*github.PullRequestReview{
+		review("alice", "APPROVED"),
+		review("alice", "CHANGES_REQUESTED"),
+	}))
+}
+
+func TestHoldForApproval(t *testing.T) {
+	gate := &types.ApprovalGateConfig{Reviewers: []string{"docs-lead"}, TimeoutMinutes: 30}
+	g := NewApprovalGate(NewMemoryApprovalStore(), &configs.Config{}, nil)
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return clock }
+
+	strategy := &types.CommitStrategyConfig{Type: "pull_request", AutoMerge: true, ApprovalGate: gate}
+	runs := []*workflowRun{
+		{Workflow: types.Workflow{Name: "app", Destination: types.Destination{Repo: "org/app", Branch: "main"}, CommitStrategy: strategy}},
+		// Not held: PR merged without a gate, failed upload
+		{Workflow: types.Workflow{Name: "ungated", Destination: types.Destination{Repo: "org/ungated", Branch: "main"}}},
+		{Workflow: types.Workflow{Name: "failed", Destination: types.Destination{Repo: "org/broken", Branch: "main"}, CommitStrategy: strategy}},
+	}
+	uploads := map[types.UploadKey]UploadResult{
+		{RepoName: "org/app", BranchPath: "main"}:     {PRURL: "https://github.com/org/app/pull/7", PRNumber: 7, AwaitingApproval: true},
+		{RepoName: "org/ungated", BranchPath: "main"}: {PRURL: "https://github.com/org/ungated/pull/8", PRNumber: 8, CommitSHA: "abc"},
+		{RepoName: "org/broken", BranchPath: "main"}:  {Err: errors.New("forbidden")},
+	}
+
+	holdForApproval(context.Background(), g, CopyEvent{Repo: "org/src", Number: 42}, runs, uploads)
+
+	pending := pendingApprovals(t, g)
+	require.Len(t, pending, 1)
+	held := pending[0]
+	assert.Equal(t, "org/app#7", held.ID)
+	assert.Equal(t, "app", held.WorkflowName)
+	assert.Equal(t, 7, held.TargetPRNumber)
+	assert.Equal(t, 42, held.PRNumber)
+	assert.Equal(t, clock.Add(30*time.Minute), held.Deadline)
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ApprovalStore holds the PRs waiting in the approval gate. Instances that share a store claim a PR before
+// checking it, so only one of them checks, merges, or closes each PR.
+type ApprovalStore interface {
+	Save(ctx context.Context, approval *PendingApproval) error // Adds the PR, or replaces the PR with the same ID
+	// Claim leases the PR with the earliest next check at or before dueBy to owner until now+lease, and moves
+	// its next check to the end of the lease so no one else claims it meanwhile. Returns nil if none is due.
+	Claim(ctx context.Context, owner string, dueBy time.Time, now time.Time, lease time.Duration) (*PendingApproval, error)
+	// Release saves a claimed PR, clearing its lease, if owner still holds the lease
+	Release(ctx context.Context, approval *PendingApproval, owner string) error
+	// Delete removes a PR if owner holds its lease
+	Delete(ctx context.Context, id string, owner string) error
+	List(ctx context.Context) ([]*PendingApproval, error) // Returns PRs in order of their next check
+}
+
+// errApprovalLeaseLost is returned when releasing a PR whose lease another owner now holds, or that's gone
+var errApprovalLeaseLost = errors.New("held PR is no longer leased to this instance")
+
+// MemoryApprovalStore implements ApprovalStore in memory. Held PRs are lost when the process exits.
+type MemoryApprovalStore struct {
+	mu        sync.Mutex
+	approvals map[string]*PendingApproval
+}
+
+// NewMemoryApprovalStore creates an empty in-memory approval store
+func NewMemoryApprovalStore() *MemoryApprovalStore {
+	return &MemoryApprovalStore{approvals: make(map[string]*PendingApproval)}
+}
+
+// Save adds or replaces a PR
+func (s *MemoryApprovalStore) Save(ctx context.Context, approval *PendingApproval) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *approval
+	s.approvals[approval.ID] = &saved
+	return nil
+}
+
+// Claim leases the due PR with the earliest next check
+func (s *MemoryApprovalStore) Claim(ctx context.Context, owner string, dueBy time.Time, now time.Time, lease time.Duration) (*PendingApproval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due *PendingApproval
+	for _, approval := range s.approvals {
+		if !approval.NextCheck.After(dueBy) && (due == nil || approval.NextCheck.Before(due.NextCheck)) {
+			due = approval
+		}
+	}
+	if due == nil {
+		return nil, nil
+	}
+	due.LeaseOwner = owner
+	due.LeaseUntil = now.Add(lease)
+	due.NextCheck = due.LeaseUntil
+	claimed := *due
+	return &claimed, nil
+}
+
+// Release saves a claimed PR with its lease cleared
+func (s *MemoryApprovalStore) Release(ctx context.Context, approval *PendingApproval, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.approvals[approval.ID]
+	if !ok || current.LeaseOwner != owner {
+		return errApprovalLeaseLost
+	}
+	released := *approval
+	released.LeaseOwner = ""
+	released.LeaseUntil = time.Time{}
+	s.approvals[approval.ID] = &released
+	return nil
+}
+
+// Delete removes a PR if owner holds its lease
+func (s *MemoryApprovalStore) Delete(ctx context.Context, id string, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if approval, ok := s.approvals[id]; ok && approval.LeaseOwner == owner {
+		delete(s.approvals, id)
+	}
+	return nil
+}
+
+// List returns copies of the PRs, in order of their next check
+func (s *MemoryApprovalStore) List(ctx context.Context) ([]*PendingApproval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	approvals := make([]*PendingApproval, 0, len(s.approvals))
+	for _, approval := range s.approvals {
+		copied := *approval
+		approvals = append(approvals, &copied)
+	}
+	sort.Slice(approvals, func(i, j int) bool {
+		return approvals[i].NextCheck.Before(approvals[j].NextCheck)
+	})
+	return approvals, nil
+}
+
+// MongoApprovalStore implements ApprovalStore using a MongoDB collection, so held PRs survive restarts and
+// are checked by whichever instance claims them
+type MongoApprovalStore struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewMongoApprovalStore returns a store backed by the given collection, connecting the shared client if it
+// isn't yet
+func NewMongoApprovalStore(ctx context.Context, mongoClient *SharedMongoClient, database, collection string) (*MongoApprovalStore, error) {
+	client, err := mongoClient.Connect(ctx, "the approval gate store is mongodb")
+	if err != nil {
+		return nil, err
+	}
+
+	coll := client.Database(database).Collection(collection)
+	if _, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "next_check", Value: 1}},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return &MongoApprovalStore{client: client, collection: coll}, nil
+}
+
+// Save upserts a PR by ID
+func (s *MongoApprovalStore) Save(ctx context.Context, approval *PendingApproval) error {
+	_, err := s.collection.ReplaceOne(ctx, bson.M{"_id": approval.ID}, approval, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Claim atomically leases the due PR with the earliest next check, so instances sharing the collection never
+// check the same PR at once
+func (s *MongoApprovalStore) Claim(ctx context.Context, owner string, dueBy time.Time, now time.Time, lease time.Duration) (*PendingApproval, error) {
+	until := now.Add(lease)
+	update := bson.M{"$set": bson.M{"lease_owner": owner, "lease_until": until, "next_check": until}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_check", Value: 1}}).
+		SetReturnDocument(options.After)
+	var approval PendingApproval
+	err := s.collection.FindOneAndUpdate(ctx, bson.M{"next_check": bson.M{"$lte": dueBy}}, update, opts).Decode(&approval)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// Release replaces a claimed PR with its lease cleared, if owner still holds the lease
+func (s *MongoApprovalStore) Release(ctx context.Context, approval *PendingApproval, owner string) error {
+	released := *approval
+	released.LeaseOwner = ""
+	released.LeaseUntil = time.Time{}
+	result, err := s.collection.ReplaceOne(ctx, bson.M{"_id": approval.ID, "lease_owner": owner}, &released)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errApprovalLeaseLost
+	}
+	return nil
+}
+
+// Delete removes a PR if owner holds its lease
+func (s *MongoApprovalStore) Delete(ctx context.Context, id string, owner string) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"_id": id, "lease_owner": owner})
+	return err
+}
+
+// List returns the PRs in order of their next check
+func (s *MongoApprovalStore) List(ctx context.Context) ([]*PendingApproval, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "next_check", Value: 1}})
+	cursor, err := s.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	approvals := []*PendingApproval{}
+	if err := cursor.All(ctx, &approvals); err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
@@ -76,24 +76,14 @@ type MongoAuditLogger struct {
 }
 
 // NewMongoAuditLogger creates a new MongoDB audit logger
-func NewMongoAuditLogger(ctx context.Context, mongoURI, database, collection string, enabled bool) (AuditLogger, error) {
+func NewMongoAuditLogger(ctx context.Context, mongoClient *SharedMongoClient, database, collection string, enabled bool) (AuditLogger, error) {
 	if !enabled {
 		return &NoOpAuditLogger{}, nil
 	}
 
-	if mongoURI == "" {
-		return nil, fmt.Errorf("MONGO_URI is required when audit logging is enabled")
-	}
-
-	clientOptions := options.Client().ApplyURI(mongoURI)
-	client, err := mongo.Connect(ctx, clientOptions)
+	client, err := mongoClient.Connect(ctx, "audit logging is enabled")
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
-	}
-
-	// Ping to verify connection
-	if err := client.Ping(ctx, nil); err != nil {
-		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+		return nil, err
 	}
 
 	coll := client.Database(database).Collection(collection)
@@ -278,9 +268,14 @@ func (mal *MongoAuditLogger) GetDailyVolume(ctx context.Context, days int) ([]Da
 	return stats, nil
 }
 
-// Close closes the MongoDB connection
+// Ping checks the MongoDB connection
+func (mal *MongoAuditLogger) Ping(ctx context.Context) error {
+	return mal.client.Ping(ctx, nil)
+}
+
+// Close does nothing: the shared client is disconnected by its owner
 func (mal *MongoAuditLogger) Close(ctx context.Context) error {
-	return mal.client.Disconnect(ctx)
+	return nil
 }
 
 // NoOpAuditLogger is a no-op implementation when audit logging is disabled
@@ -10,7 +10,7 @@ func TestNewMongoAuditLogger_Disabled(t *testing.T) {
 	ctx := context.Background()
 	
 	// When enabled=false, should return NoOpAuditLogger
-	logger, err := NewMongoAuditLogger(ctx, "", "testdb", "testcoll", false)
+	logger, err := NewMongoAuditLogger(ctx, NewSharedMongoClient(""), "testdb", "testcoll", false)
 	if err != nil {
 		t.Fatalf("NewMongoAuditLogger() error = %v, want nil", err)
 	}
@@ -30,7 +30,7 @@ func TestNewMongoAuditLogger_EnabledWithoutURI(t *testing.T) {
 	ctx := context.Background()
 	
 	// When enabled=true but no URI, should return error
-	_, err := NewMongoAuditLogger(ctx, "", "testdb", "testcoll", true)
+	_, err := NewMongoAuditLogger(ctx, NewSharedMongoClient(""), "testdb", "testcoll", true)
 	if err == nil {
 		t.Error("NewMongoAuditLogger() expected error when enabled without URI, got nil")
 	}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// Backfill modes: which source files are replayed through the workflows
+const (
+	// BackfillModeSnapshot copies every file in the source branch, as if it had just been added
+	BackfillModeSnapshot = "snapshot"
+	// BackfillModePRs copies the files changed by PRs merged into the source branch
+	BackfillModePRs = "prs"
+)
+
+// BackfillOptions selects the workflows a backfill runs and the source files it replays
+type BackfillOptions struct {
+	// Workflows limits the backfill to workflows with these names
+	Workflows []string
+	// Destination limits the backfill to workflows that copy to this repo ("owner/repo")
+	Destination string
+	// Mode is BackfillModeSnapshot (the default) or BackfillModePRs
+	Mode string
+	// Since limits BackfillModePRs to PRs merged at or after this time; zero means all merged PRs
+	Since time.Time
+	// DryRun reports what each workflow would copy without writing to the destination
+	DryRun bool
+}
+
+// BackfillSource summarizes the files replayed from one source repo and branch
+type BackfillSource struct {
+	Repo      string
+	Branch    string
+	CommitSHA string   // Head of the branch; files are copied as of this commit
+	PRCount   int      // Merged PRs the files came from, in BackfillModePRs
+	Files     int      // Source files replayed through the workflows
+	Workflows []string // Names of the workflows that ran
+
+	targets map[types.UploadKey]bool // Destination branches the workflows copy to
+}
+
+// BackfillResult is the outcome of a backfill
+type BackfillResult struct {
+	Sources []BackfillSource
+	// DryRuns holds what each workflow would have copied, for dry runs
+	DryRuns []*DryRunReport
+	// Uploads holds the result of each consolidated upload, keyed by destination repo and branch
+	Uploads map[types.UploadKey]UploadResult
+	// Errors holds workflows that failed, as "workflow: error"
+	Errors []string
+}
+
+// RunBackfill replays a source repo's existing files through the configured workflows, for onboarding a
+// new destination. The files are copied as of the head of each source branch, and everything queued for
+// a destination branch is opened as a single pull request, whatever the workflows' commit strategies.
+// Only GitHub sources are supported.
+func RunBackfill(ctx context.Context, config *configs.Config, container *ServiceContainer, opts BackfillOptions) (*BackfillResult, error) {
+	if opts.Mode == "" {
+		opts.Mode = BackfillModeSnapshot
+	}
+	if opts.Mode != BackfillModeSnapshot && opts.Mode != BackfillModePRs {
+		return nil, fmt.Errorf("invalid backfill mode %q (must be %s or %s)", opts.Mode, BackfillModeSnapshot, BackfillModePRs)
+	}
+
+	yamlConfig, err := container.ConfigLoader.LoadConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// The workflows queue files of their own, so uploads queued by webhooks processed meanwhile aren't mixed in
+	container, closeRunState := container.withRunFileState()
+	defer closeRunState()
+
+	workflows, err := selectBackfillWorkflows(yamlConfig.Workflows, opts)
+	if err != nil {
+		return nil, err
+	}
+	workflows = expandDestinationBranches(ctx, workflows)
+
+	result := &BackfillResult{}
+	var sourceRuns [][]*workflowRun // The workflow runs of each source, in the order of result.Sources
+	for _, group := range groupWorkflowsBySource(workflows) {
+		source := group[0].Source
+		summary, changedFiles, err := backfillSourceFiles(ctx, source, opts)
+		if err != nil {
+			return nil, err
+		}
+		summary.targets = make(map[types.UploadKey]bool)
+		for i, workflow := range group {
+			// Workflows copying to several destination branches are listed once
+			if i == 0 || group[i-1].Name != workflow.Name {
+				summary.Workflows = append(summary.Workflows, workflow.Name)
+			}
+			summary.targets[types.UploadKey{RepoName: workflow.Destination.Repo, BranchPath: workflow.Destination.Branch}] = true
+		}
+		result.Sources = append(result.Sources, summary)
+
+		LogInfoCtx(ctx, "backfilling source", map[string]interface{}{
+			"source_repo":    summary.Repo,
+			"source_branch":  summary.Branch,
+			"commit_sha":     summary.CommitSHA,
+			"mode":           opts.Mode,
+			"file_count":     summary.Files,
+			"workflow_count": len(group),
+		})
+
+		runs := processFilesWithWorkflows(ctx, 0, summary.CommitSHA, changedFiles, &types.YAMLConfig{Workflows: group, ConflictPolicy: yamlConfig.ConflictPolicy}, container)
+		sourceRuns = append(sourceRuns, runs)
+		for _, run := range runs {
+			if run.DryRun != nil {
+				result.DryRuns = append(result.DryRuns, run.DryRun)
+			}
+			if run.Err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", run.Workflow.Name, run.Err))
+			}
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	// Open one pull request per destination branch, covering every workflow that copies to it
+	queued := container.FileStateService.GetFilesToUpload()
+	for key, content := range queued {
+		queued[key] = backfillUpload(content, key, result.Sources)
+	}
+	result.Uploads = uploadFiles(ctx, queued, container.PRTemplateFetcher, container.MetricsCollector)
+
+	// Record each pull request in the write log once for every source it copies from
+	for i, source := range result.Sources {
+		uploads := make(map[types.UploadKey]UploadResult)
+		for key, upload := range result.Uploads {
+			if source.targets[key] {
+				uploads[key] = upload
+			}
+		}
+		change := CopyEvent{Platform: types.SourcePlatformGitHub, Repo: source.Repo, CommitSHA: source.CommitSHA, BaseBranch: source.Branch}
+		container.WriteLog.RecordUploads(ctx, change, sourceRuns[i], queued, uploads)
+	}
+
+	return result, nil
+}
+
+// selectBackfillWorkflows returns the workflows matching the backfill's names and destination. Dry-run
+// backfills force every workflow into dry-run mode.
+func selectBackfillWorkflows(workflows []types.Workflow, opts BackfillOptions) ([]types.Workflow, error) {
+	if len(opts.Workflows) == 0 && opts.Destination == "" {
+		return nil, fmt.Errorf("a destination or workflow names are required")
+	}
+
+	names := make(map[string]bool, len(opts.Workflows))
+	for _, name := range opts.Workflows {
+		names[name] = true
+	}
+
+	var selected []types.Workflow
+	found := make(map[string]bool)
+	for _, workflow := range workflows {
+		if len(names) > 0 && !names[workflow.Name] {
+			continue
+		}
+		if opts.Destination != "" && workflow.Destination.Repo != opts.Destination {
+			continue
+		}
+		if workflow.Source.GetPlatform() != types.SourcePlatformGitHub {
+			return nil, fmt.Errorf("workflow %s: backfill only supports GitHub sources", workflow.Name)
+		}
+		if opts.DryRun {
+			dryRun := true
+			workflow.DryRun = &dryRun
+		}
+		selected = append(selected, workflow)
+		found[workflow.Name] = true
+	}
+
+	var missing []string
+	for name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("no matching workflows named: %s", strings.Join(missing, ", "))
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no workflows copy to %s", opts.Destination)
+	}
+	return selected, nil
+}
+
+// groupWorkflowsBySource groups workflows by source repo and branch, in the order they're configured
+func groupWorkflowsBySource(workflows []types.Workflow) [][]types.Workflow {
+	var groups [][]types.Workflow
+	index := make(map[string]int)
+	for _, workflow := range workflows {
+		key := workflow.Source.Repo + "@" + workflow.Source.Branch
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], workflow)
+	}
+	return groups
+}
+
+// backfillSourceFiles resolves the head of the source branch and lists the files to replay from it
+func backfillSourceFiles(ctx context.Context, source types.Source, opts BackfillOptions) (BackfillSource, []types.ChangedFile, error) {
+	summary := BackfillSource{Repo: source.Repo, Branch: source.Branch}
+	if source.IsBranchPattern() {
+		return summary, nil, fmt.Errorf("%s branch %q is a pattern; backfill copies from a single branch", source.Repo, source.Branch)
+	}
+	owner, name, _ := strings.Cut(source.Repo, "/")
+	client := GetRestClient()
+
+	branch, _, err := client.Repositories.GetBranch(ctx, owner, name, source.Branch, true)
+	if err != nil {
+		return summary, nil, fmt.Errorf("failed to get %s branch %s: %w", source.Repo, source.Branch, err)
+	}
+	summary.CommitSHA = branch.GetCommit().GetSHA()
+
+	tree, truncated, err := GetRepoTree(ctx, client, owner, name, summary.CommitSHA)
+	if err != nil {
+		return summary, nil, err
+	}
+	if truncated {
+		return summary, nil, fmt.Errorf("the tree of %s at %s is too large to list", source.Repo, summary.CommitSHA)
+	}
+
+	var paths []string
+	if opts.Mode == BackfillModePRs {
+		prPaths, prCount, err := mergedPRFiles(ctx, client, owner, name, source.Branch, opts.Since)
+		if err != nil {
+			return summary, nil, err
+		}
+		summary.PRCount = prCount
+		paths = filesInTree(prPaths, tree)
+	} else {
+		for path := range tree {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+	}
+
+	changedFiles := make([]types.ChangedFile, len(paths))
+	for i, path := range paths {
+		changedFiles[i] = types.ChangedFile{Path: path, Status: "ADDED"}
+	}
+	summary.Files = len(changedFiles)
+	return summary, changedFiles, nil
+}
+
+// mergedPRFiles returns the paths changed by PRs merged into branch at or after since, and how many PRs
+// there were
+func mergedPRFiles(ctx context.Context, client *github.Client, owner string, repo string, branch string, since time.Time) ([]string, int, error) {
+	opts := &github.PullRequestListOptions{
+		State:       "closed",
+		Base:        branch,
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var merged []int
+	for {
+		prs, resp, err := client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list pull requests for %s/%s: %w", owner, repo, err)
+		}
+		done := false
+		for _, pr := range prs {
+			// PRs are listed most recently updated first, and a PR can't be updated before it's merged
+			if !since.IsZero() && pr.GetUpdatedAt().Before(since) {
+				done = true
+				break
+			}
+			if pr.MergedAt != nil && !pr.GetMergedAt().Before(since) {
+				merged = append(merged, pr.GetNumber())
+			}
+		}
+		if done || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, number := range merged {
+		files, err := GetFilesChangedInPrWithContext(ctx, owner, repo, number)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get files for PR #%d: %w", number, err)
+		}
+		for _, file := range files {
+			if !seen[file.Path] {
+				seen[file.Path] = true
+				paths = append(paths, file.Path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, len(merged), nil
+}
+
+// filesInTree returns the paths that still exist in the source tree. Files that later PRs deleted or
+// renamed are dropped, since the backfill copies the branch as it is now.
+func filesInTree(paths []string, tree map[string]string) []string {
+	var existing []string
+	for _, path := range paths {
+		if _, ok := tree[path]; ok {
+			existing = append(existing, path)
+		}
+	}
+	return existing
+}
+
+// backfillUpload rewrites a queued upload as a single backfill pull request that isn't merged
+// automatically, so the initial copy into a new destination is reviewed as a whole
+func backfillUpload(content types.UploadFileContent, key types.UploadKey, sources []BackfillSource) types.UploadFileContent {
+	var repos []string
+	var lines []string
+	for _, source := range sources {
+		if !source.targets[key] {
+			continue
+		}
+		repos = append(repos, source.Repo)
+		line := fmt.Sprintf("- %s@%s at %s (%d file(s)", source.Repo, source.Branch, source.CommitSHA, source.Files)
+		if source.PRCount > 0 {
+			line += fmt.Sprintf(" from %d merged PR(s)", source.PRCount)
+		}
+		lines = append(lines, line+")")
+	}
+
+	content.CommitStrategy = types.CommitStrategyPR
+	content.AutoMergePR = false
+	content.UsePRTemplate = false
+	content.CommitMessage = fmt.Sprintf("Backfill code examples from %s", strings.Join(repos, ", "))
+	content.PRTitle = content.CommitMessage
+	content.PRBody = fmt.Sprintf("Backfill of %d file(s) into %s:%s from:\n\n%s",
+		len(content.Content), key.RepoName, key.BranchPath, strings.Join(lines, "\n"))
+	return content
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectBackfillWorkflows(t *testing.T) {
+	workflows := []types.Workflow{
+		{Name: "python", Source: types.Source{Repo: "org/src", Branch: "main"}, Destination: types.Destination{Repo: "org/python", Branch: "main"}},
+		{Name: "python-docs", Source: types.Source{Repo: "org/docs", Branch: "main"}, Destination: types.Destination{Repo: "org/python", Branch: "main"}},
+		{Name: "node", Source: types.Source{Repo: "org/src", Branch: "main"}, Destination: types.Destination{Repo: "org/node", Branch: "main"}},
+		{Name: "gitlab", Source: types.Source{Platform: types.SourcePlatformGitLab, Repo: "group/src", Branch: "main"}, Destination: types.Destination{Repo: "org/gitlab", Branch: "main"}},
+	}
+
+	selected, err := selectBackfillWorkflows(workflows, BackfillOptions{Destination: "org/python"})
+	require.NoError(t, err)
+	require.Len(t, selected, 2)
+	assert.Equal(t, "python", selected[0].Name)
+	assert.Equal(t, "python-docs", selected[1].Name)
+	assert.Nil(t, selected[0].DryRun)
+
+	selected, err = selectBackfillWorkflows(workflows, BackfillOptions{Workflows: []string{"node"}, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, selected, 1)
+	assert.True(t, selected[0].IsDryRun(false), "dry-run backfills force workflows into dry-run mode")
+	assert.Nil(t, workflows[2].DryRun, "configured workflows aren't modified")
+
+	_, err = selectBackfillWorkflows(workflows, BackfillOptions{})
+	assert.Error(t, err)
+
+	_, err = selectBackfillWorkflows(workflows, BackfillOptions{Workflows: []string{"node", "ruby"}})
+	assert.EqualError(t, err, "no matching workflows named: ruby")
+
+	_, err = selectBackfillWorkflows(workflows, BackfillOptions{Destination: "org/unknown"})
+	assert.EqualError(t, err, "no workflows copy to org/unknown")
+
+	_, err = selectBackfillWorkflows(workflows, BackfillOptions{Destination: "org/gitlab"})
+	assert.EqualError(t, err, "workflow gitlab: backfill only supports GitHub sources")
+}
+
+func TestGroupWorkflowsBySource(t *testing.T) {
+	groups := groupWorkflowsBySource([]types.Workflow{
+		{Name: "a", Source: types.Source{Repo: "org/src", Branch: "main"}},
+		{Name: "b", Source: types.Source{Repo: "org/docs", Branch: "main"}},
+		{Name: "c", Source: types.Source{Repo: "org/src", Branch: "main"}},
+		{Name: "d", Source: types.Source{Repo: "org/src", Branch: "v2"}},
+	})
+
+	var names [][]string
+	for _, group := range groups {
+		var groupNames []string
+		for _, workflow := range group {
+			groupNames = append(groupNames, workflow.Name)
+		}
+		names = append(names, groupNames)
+	}
+	assert.Equal(t, [][]string{{"a", "c"}, {"b"}, {"d"}}, names)
+}
+
+func TestFilesInTree(t *testing.T) {
+	tree := map[string]string{
+		"examples/a.py":   types.FileModeRegular,
+		"examples/run.sh": types.FileModeExecutable,
+	}
+	paths := []string{"examples/a.py", "examples/deleted.py", "examples/run.sh"}
+	assert.Equal(t, []string{"examples/a.py", "examples/run.sh"}, filesInTree(paths, tree))
+}
+
+func TestBackfillUpload(t *testing.T) {
+	key := types.UploadKey{RepoName: "org/python", BranchPath: "main"}
+	sources := []BackfillSource{
+		{Repo: "org/src", Branch: "main", CommitSHA: "abc123", Files: 12, PRCount: 3, targets: map[types.UploadKey]bool{key: true}},
+		{Repo: "org/other", Branch: "main", CommitSHA: "def456", Files: 5, targets: map[types.UploadKey]bool{{RepoName: "org/node", BranchPath: "main"}: true}},
+	}
+	content := types.UploadFileContent{
+		TargetBranch:   "main",
+		Content:        []github.RepositoryContent{{Path: github.String("a.py")}, {Path: github.String("b.py")}},
+		CommitStrategy: types.CommitStrategyDirect,
+		CommitMessage:  "Update from workflow: python",
+		UsePRTemplate:  true,
+		AutoMergePR:    true,
+	}
+
+	upload := backfillUpload(content, key, sources)
+	assert.Equal(t, types.CommitStrategyPR, upload.CommitStrategy)
+	assert.False(t, upload.AutoMergePR, "backfill PRs are reviewed before merging")
+	assert.False(t, upload.UsePRTemplate)
+	assert.Equal(t, "Backfill code examples from org/src", upload.CommitMessage)
+	assert.Equal(t, upload.CommitMessage, upload.PRTitle)
+	assert.Equal(t, "Backfill of 2 file(s) into org/python:main from:\n\n- org/src@main at abc123 (12 file(s) from 3 merged PR(s))", upload.PRBody)
+	assert.Len(t, upload.Content, 2)
+}
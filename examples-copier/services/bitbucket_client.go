@@ -0,0 +1,514 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	. "github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+const (
+	defaultBitbucketBaseURL = "https://api.bitbucket.org/2.0"
+	bitbucketPageLen        = 100
+	// bitbucketMaxTreeDepth is how many directory levels GetRepoTree lists. Directories deeper than
+	// this aren't listed, and the listing is reported as truncated.
+	bitbucketMaxTreeDepth = 50
+)
+
+// bitbucketCommitHash matches a commit hash, which doesn't need to be resolved from a branch name.
+// Webhook payloads carry abbreviated hashes.
+var bitbucketCommitHash = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// BitbucketClient reads from and writes to Bitbucket Cloud repos using the REST API (2.0). Repos are
+// identified as "workspace/repo". It reads pull requests and files from Bitbucket source repos, and is
+// the RepoProvider for Bitbucket destination repos.
+type BitbucketClient struct {
+	baseURL  string
+	username string
+	token    string
+}
+
+// BitbucketError is returned for API requests that fail with an error status
+type BitbucketError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *BitbucketError) Error() string {
+	return fmt.Sprintf("Bitbucket API returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// NewBitbucketClient creates a client for the Bitbucket API at baseURL. With a username, token is sent
+// as an app password using basic auth; otherwise it's sent as a bearer access token. An empty token
+// only works for reading public repos.
+func NewBitbucketClient(baseURL string, username string, token string) *BitbucketClient {
+	if baseURL == "" {
+		baseURL = defaultBitbucketBaseURL
+	}
+	return &BitbucketClient{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		username: username,
+		token:    token,
+	}
+}
+
+// GetBitbucketClient returns a Bitbucket client configured from BITBUCKET_BASE_URL, BITBUCKET_USERNAME,
+// and BITBUCKET_TOKEN
+func GetBitbucketClient() *BitbucketClient {
+	return NewBitbucketClient(os.Getenv(configs.BitbucketBaseURL), os.Getenv(configs.BitbucketUsername), os.Getenv(configs.BitbucketToken))
+}
+
+// bitbucketBranch is a branch from the refs endpoint
+type bitbucketBranch struct {
+	Name   string `json:"name"`
+	Target struct {
+		Hash string    `json:"hash"`
+		Date time.Time `json:"date"`
+	} `json:"target"`
+}
+
+// bitbucketTreeEntry is one entry from the src endpoint's directory listing
+type bitbucketTreeEntry struct {
+	Type       string   `json:"type"` // "commit_file" or "commit_directory"
+	Path       string   `json:"path"`
+	Attributes []string `json:"attributes"`
+}
+
+// bitbucketDiffStat is one entry from the pull request diffstat endpoint
+type bitbucketDiffStat struct {
+	Status       string `json:"status"` // "added", "removed", "modified", or "renamed"
+	LinesAdded   int    `json:"lines_added"`
+	LinesRemoved int    `json:"lines_removed"`
+	Old          *struct {
+		Path string `json:"path"`
+	} `json:"old"`
+	New *struct {
+		Path string `json:"path"`
+	} `json:"new"`
+}
+
+// bitbucketPullRequest is a pull request from the pullrequests endpoint
+type bitbucketPullRequest struct {
+	ID          int    `json:"id"`
+	State       string `json:"state"`
+	MergeCommit struct {
+		Hash string `json:"hash"`
+	} `json:"merge_commit"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+// bitbucketPage is one page of a paginated response. Next is the URL of the next page, or empty on the last page.
+type bitbucketPage[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+// GetPullRequestChanges lists the files changed in a pull request, mapped to the same statuses
+// GitHub reports (ADDED, MODIFIED, DELETED, RENAMED) so workflows treat them the same way.
+func (c *BitbucketClient) GetPullRequestChanges(ctx context.Context, repo string, id int) ([]ChangedFile, error) {
+	var changedFiles []ChangedFile
+	next := c.repoURL(repo, fmt.Sprintf("pullrequests/%d/diffstat", id)) + "?pagelen=" + strconv.Itoa(bitbucketPageLen)
+	for next != "" {
+		var page bitbucketPage[bitbucketDiffStat]
+		if err := c.do(ctx, http.MethodGet, next, nil, "", &page); err != nil {
+			return nil, fmt.Errorf("failed to get changes for pull request #%d in %s: %w", id, repo, err)
+		}
+		for _, stat := range page.Values {
+			changedFiles = append(changedFiles, bitbucketChangedFile(stat))
+		}
+		next = page.Next
+	}
+
+	LogInfo(fmt.Sprintf("PR has %d changed files.", len(changedFiles)))
+	return changedFiles, nil
+}
+
+// bitbucketChangedFile converts a diffstat entry to a ChangedFile
+func bitbucketChangedFile(stat bitbucketDiffStat) ChangedFile {
+	file := ChangedFile{Status: "MODIFIED", Additions: stat.LinesAdded, Deletions: stat.LinesRemoved}
+	if stat.New != nil {
+		file.Path = stat.New.Path
+	}
+	switch stat.Status {
+	case "added":
+		file.Status = "ADDED"
+	case "removed":
+		file.Status = statusDeleted
+		if stat.Old != nil {
+			file.Path = stat.Old.Path
+		}
+	case "renamed":
+		file.Status = "RENAMED"
+	}
+	return file
+}
+
+// GetFileContents fetches a file from a repo at the given commit or branch. The result is returned
+// as a GitHub RepositoryContent so it can be queued for upload like a file from a GitHub source.
+func (c *BitbucketClient) GetFileContents(ctx context.Context, repo string, filePath string, ref string) (*github.RepositoryContent, error) {
+	content, found, err := c.GetFile(ctx, repo, filePath, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file content: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("failed to get file content: %s not found in %s at %s", filePath, repo, ref)
+	}
+
+	return &github.RepositoryContent{
+		Type:     github.String("file"),
+		Name:     github.String(filePath[strings.LastIndex(filePath, "/")+1:]),
+		Path:     github.String(filePath),
+		Encoding: github.String("base64"),
+		Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+		Size:     github.Int(len(content)),
+	}, nil
+}
+
+// GetFile returns a file's content at the given commit or branch. found is false if the file doesn't exist.
+func (c *BitbucketClient) GetFile(ctx context.Context, repo string, filePath string, ref string) (string, bool, error) {
+	commit, err := c.resolveCommit(ctx, repo, ref)
+	if err != nil {
+		return "", false, err
+	}
+
+	var content bytes.Buffer
+	err = c.do(ctx, http.MethodGet, c.repoURL(repo, "src/"+commit+"/"+escapePath(filePath)), nil, "", &content)
+	var bbErr *BitbucketError
+	if errors.As(err, &bbErr) && bbErr.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return content.String(), true, nil
+}
+
+// GetRepoTree lists every file in a repo at the given commit or branch, returning each file's Git mode
+// keyed by path. truncated is true if the repo has directories deeper than the listing goes.
+func (c *BitbucketClient) GetRepoTree(ctx context.Context, repo string, ref string) (map[string]string, bool, error) {
+	commit, err := c.resolveCommit(ctx, repo, ref)
+	if err != nil {
+		return nil, false, err
+	}
+
+	modes := make(map[string]string)
+	truncated := false
+	query := url.Values{"max_depth": {strconv.Itoa(bitbucketMaxTreeDepth)}, "pagelen": {strconv.Itoa(bitbucketPageLen)}}
+	next := c.repoURL(repo, "src/"+commit+"/") + "?" + query.Encode()
+	for next != "" {
+		var page bitbucketPage[bitbucketTreeEntry]
+		if err := c.do(ctx, http.MethodGet, next, nil, "", &page); err != nil {
+			return nil, false, fmt.Errorf("failed to get tree for %s at %s: %w", repo, ref, err)
+		}
+		for _, entry := range page.Values {
+			switch entry.Type {
+			case "commit_file":
+				modes[entry.Path] = bitbucketFileMode(entry.Attributes)
+			case "commit_directory":
+				if strings.Count(entry.Path, "/")+1 >= bitbucketMaxTreeDepth {
+					truncated = true
+				}
+			}
+		}
+		next = page.Next
+	}
+	return modes, truncated, nil
+}
+
+// bitbucketFileMode returns the Git mode for a file from its attributes
+func bitbucketFileMode(attributes []string) string {
+	for _, attribute := range attributes {
+		if attribute == "executable" {
+			return FileModeExecutable
+		}
+	}
+	return FileModeRegular
+}
+
+// CreateBranch creates branch from the head of baseBranch, replacing it if it already exists
+func (c *BitbucketClient) CreateBranch(ctx context.Context, repo string, branch string, baseBranch string) error {
+	if baseBranch == "" {
+		baseBranch = "main"
+	}
+	base, err := c.getBranch(ctx, repo, baseBranch)
+	if err != nil {
+		LogCriticalCtx(ctx, fmt.Sprintf("Failed to get '%s' branch: %s", baseBranch, err), nil)
+		return err
+	}
+
+	c.DeleteBranch(ctx, repo, branch)
+
+	body := map[string]interface{}{
+		"name":   branch,
+		"target": map[string]string{"hash": base.Target.Hash},
+	}
+	if err := c.doJSON(ctx, http.MethodPost, c.repoURL(repo, "refs/branches"), body, nil); err != nil {
+		LogCriticalCtx(ctx, fmt.Sprintf("Failed to create branch %s: %s", branch, err), nil)
+		return err
+	}
+
+	LogInfoCtx(ctx, fmt.Sprintf("Branch created successfully: %s on %s%s (from %s)", branch, BitbucketRepoPrefix, repo, baseBranch), nil)
+	return nil
+}
+
+// CommitFiles commits the files and deletions in commit to the head of branch. Bitbucket doesn't
+// take file modes when committing, so executable files are written as regular files. The response
+// doesn't include the new commit, so its SHA is returned empty.
+func (c *BitbucketClient) CommitFiles(ctx context.Context, repo string, branch string, commit ProviderCommit) (string, error) {
+	head, err := c.getBranch(ctx, repo, branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to get branch %s: %w", branch, err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	fields := map[string]string{
+		"message": commit.Message,
+		"branch":  branch,
+		"parents": head.Target.Hash,
+	}
+	if commit.Author != nil && commit.Author.GetEmail() != "" {
+		fields["author"] = fmt.Sprintf("%s <%s>", commit.Author.GetName(), commit.Author.GetEmail())
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return "", err
+		}
+	}
+	for path, content := range commit.Files {
+		if mode := commit.FileModes[path]; mode != "" && mode != FileModeRegular {
+			LogWarningCtx(ctx, "Bitbucket commits don't keep file modes; writing as a regular file", map[string]interface{}{
+				"target_repo": BitbucketRepoPrefix + repo,
+				"path":        path,
+				"mode":        mode,
+			})
+		}
+		part, err := form.CreateFormFile(path, path)
+		if err != nil {
+			return "", err
+		}
+		if _, err := io.WriteString(part, content); err != nil {
+			return "", err
+		}
+	}
+	for _, path := range commit.DeletePaths {
+		if _, writing := commit.Files[path]; writing {
+			continue
+		}
+		// A path listed in "files" without content is deleted
+		if err := form.WriteField("files", path); err != nil {
+			return "", err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	if err := c.do(ctx, http.MethodPost, c.repoURL(repo, "src"), &body, form.FormDataContentType(), nil); err != nil {
+		return "", fmt.Errorf("could not create commit: %w", err)
+	}
+	return "", nil
+}
+
+// OpenPullRequest opens a pull request from head to base. The head branch is closed when the pull
+// request is merged.
+func (c *BitbucketClient) OpenPullRequest(ctx context.Context, repo string, head string, base string, title string, body string) (ProviderPullRequest, error) {
+	request := map[string]interface{}{
+		"title":               title,
+		"description":         body,
+		"source":              map[string]interface{}{"branch": map[string]string{"name": head}},
+		"destination":         map[string]interface{}{"branch": map[string]string{"name": base}},
+		"close_source_branch": true,
+	}
+	var pr bitbucketPullRequest
+	if err := c.doJSON(ctx, http.MethodPost, c.repoURL(repo, "pullrequests"), request, &pr); err != nil {
+		return ProviderPullRequest{}, fmt.Errorf("%w: %w", ErrPRCreation, err)
+	}
+	return ProviderPullRequest{Number: pr.ID, URL: pr.Links.HTML.Href}, nil
+}
+
+// LabelPullRequest does nothing, since Bitbucket pull requests don't have labels. The copier's pull
+// requests are still recognized by their branch name.
+func (c *BitbucketClient) LabelPullRequest(ctx context.Context, repo string, number int, label string) {
+	LogDebugCtx(ctx, "Bitbucket pull requests can't be labeled", map[string]interface{}{
+		"target_repo": BitbucketRepoPrefix + repo,
+		"pr_number":   number,
+		"label":       label,
+	})
+}
+
+// RequestReviewers does nothing, since workflows can only request reviewers for GitHub destinations
+func (c *BitbucketClient) RequestReviewers(ctx context.Context, repo string, number int, users []string, teams []string) {
+	LogDebugCtx(ctx, "Bitbucket pull request reviewers aren't supported", map[string]interface{}{
+		"target_repo": BitbucketRepoPrefix + repo,
+		"pr_number":   number,
+	})
+}
+
+// MergePullRequest merges a pull request with a merge commit. Pull requests with conflicts are left open.
+// Returns the SHA of the merge commit.
+func (c *BitbucketClient) MergePullRequest(ctx context.Context, repo string, number int) (string, error) {
+	request := map[string]interface{}{
+		"merge_strategy":      "merge_commit",
+		"close_source_branch": true,
+	}
+	var pr bitbucketPullRequest
+	if err := c.doJSON(ctx, http.MethodPost, c.repoURL(repo, fmt.Sprintf("pullrequests/%d/merge", number)), request, &pr); err != nil {
+		LogCriticalCtx(ctx, fmt.Sprintf("Failed to merge PR: %v", err), nil)
+		return "", fmt.Errorf("merge PR: %w", err)
+	}
+	if pr.State != "MERGED" {
+		return "", fmt.Errorf("failed to merge PR #%d: state is %s", number, pr.State)
+	}
+	LogInfoCtx(ctx, fmt.Sprintf("Successfully merged PR #%d", number), nil)
+	return pr.MergeCommit.Hash, nil
+}
+
+// DeleteBranch deletes branch if it exists, except for 'main'
+func (c *BitbucketClient) DeleteBranch(ctx context.Context, repo string, branch string) {
+	if branch == "main" {
+		LogError("I refuse to delete branch 'main'.")
+		return
+	}
+	err := c.do(ctx, http.MethodDelete, c.repoURL(repo, "refs/branches/"+escapePath(branch)), nil, "", nil)
+	var bbErr *BitbucketError
+	if errors.As(err, &bbErr) && bbErr.StatusCode == http.StatusNotFound {
+		return
+	}
+	if err != nil {
+		LogCriticalCtx(ctx, fmt.Sprintf("Error deleting branch: %v", err), nil)
+	}
+}
+
+// ListBranches lists the branches whose names start with prefix, with the date of the commit at each one's head
+func (c *BitbucketClient) ListBranches(ctx context.Context, repo string, prefix string) ([]ProviderBranch, error) {
+	query := url.Values{}
+	query.Set("q", fmt.Sprintf("name ~ %q", prefix))
+	query.Set("pagelen", strconv.Itoa(bitbucketPageLen))
+
+	var branches []ProviderBranch
+	next := c.repoURL(repo, "refs/branches") + "?" + query.Encode()
+	for next != "" {
+		var page bitbucketPage[bitbucketBranch]
+		if err := c.do(ctx, http.MethodGet, next, nil, "", &page); err != nil {
+			return nil, fmt.Errorf("list branches: %w", err)
+		}
+		for _, b := range page.Values {
+			// The query matches anywhere in the name, so check the prefix here
+			if strings.HasPrefix(b.Name, prefix) {
+				branches = append(branches, ProviderBranch{Name: b.Name, CommittedAt: b.Target.Date})
+			}
+		}
+		next = page.Next
+	}
+	return branches, nil
+}
+
+// getBranch returns a branch and the commit at its head
+func (c *BitbucketClient) getBranch(ctx context.Context, repo string, branch string) (*bitbucketBranch, error) {
+	var b bitbucketBranch
+	if err := c.do(ctx, http.MethodGet, c.repoURL(repo, "refs/branches/"+escapePath(branch)), nil, "", &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// resolveCommit returns the commit hash for ref, which is a commit hash or a branch name
+func (c *BitbucketClient) resolveCommit(ctx context.Context, repo string, ref string) (string, error) {
+	if bitbucketCommitHash.MatchString(ref) {
+		return ref, nil
+	}
+	b, err := c.getBranch(ctx, repo, strings.TrimPrefix(ref, "refs/heads/"))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s in %s: %w", ref, repo, err)
+	}
+	return b.Target.Hash, nil
+}
+
+// repoURL returns the API URL for an endpoint of a repo
+func (c *BitbucketClient) repoURL(repo string, endpoint string) string {
+	return c.baseURL + "/repositories/" + escapePath(repo) + "/" + endpoint
+}
+
+// doJSON sends body as JSON and decodes the JSON response into out
+func (c *BitbucketClient) doJSON(ctx context.Context, method string, reqURL string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, method, reqURL, bytes.NewReader(payload), "application/json", out)
+}
+
+// do sends a request and decodes the JSON response into out. If out is a *bytes.Buffer, the raw
+// response is written to it instead. Error statuses are returned as a *BitbucketError.
+func (c *BitbucketClient) do(ctx context.Context, method string, reqURL string, body io.Reader, contentType string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		if c.username != "" {
+			req.SetBasicAuth(c.username, c.token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+	}
+	if correlationID := CorrelationIDFromContext(ctx); correlationID != "" {
+		req.Header.Set(CorrelationIDHeader, correlationID)
+	}
+
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &BitbucketError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case *bytes.Buffer:
+		_, err := io.Copy(out, resp.Body)
+		return err
+	default:
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode Bitbucket API response: %w", err)
+		}
+		return nil
+	}
+}
+
+// escapePath escapes each segment of a slash-separated path for use in a URL
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,185 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/jarcoal/httpmock"
+	"github.com/mongodb/code-example-tooling/code-copier/services"
+	test "github.com/mongodb/code-example-tooling/code-copier/tests"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	bitbucketTestRepoURL = "https://bitbucket.example.com/2.0/repositories/docs/examples"
+	bitbucketTestHash    = "0123456789abcdef0123456789abcdef01234567"
+)
+
+func registerBitbucketBranch(name string, hash string) {
+	httpmock.RegisterResponder("GET", bitbucketTestRepoURL+"/refs/branches/"+name,
+		httpmock.NewJsonResponderOrPanic(http.StatusOK, map[string]interface{}{
+			"name":   name,
+			"target": map[string]string{"hash": hash},
+		}))
+}
+
+func TestBitbucketClient_GetPullRequestChanges(t *testing.T) {
+	test.WithHTTPMock(t)
+
+	httpmock.RegisterResponderWithQuery("GET", bitbucketTestRepoURL+"/pullrequests/7/diffstat", "pagelen=100",
+		httpmock.NewJsonResponderOrPanic(http.StatusOK, map[string]interface{}{
+			"values": []map[string]interface{}{
+				{"status": "added", "lines_added": 2, "new": map[string]string{"path": "src/new.py"}},
+				{"status": "modified", "lines_added": 1, "lines_removed": 1,
+					"old": map[string]string{"path": "src/app.py"}, "new": map[string]string{"path": "src/app.py"}},
+			},
+			"next": bitbucketTestRepoURL + "/pullrequests/7/diffstat?page=2",
+		}))
+	httpmock.RegisterResponderWithQuery("GET", bitbucketTestRepoURL+"/pullrequests/7/diffstat", "page=2",
+		httpmock.NewJsonResponderOrPanic(http.StatusOK, map[string]interface{}{
+			"values": []map[string]interface{}{
+				{"status": "removed", "lines_removed": 1, "old": map[string]string{"path": "src/gone.py"}},
+				{"status": "renamed", "old": map[string]string{"path": "src/old_name.py"}, "new": map[string]string{"path": "src/new_name.py"}},
+			},
+		}))
+
+	client := services.NewBitbucketClient("https://bitbucket.example.com/2.0/", "", "token")
+	files, err := client.GetPullRequestChanges(context.Background(), "docs/examples", 7)
+	require.NoError(t, err)
+
+	assert.Equal(t, []types.ChangedFile{
+		{Path: "src/new.py", Additions: 2, Status: "ADDED"},
+		{Path: "src/app.py", Additions: 1, Deletions: 1, Status: "MODIFIED"},
+		{Path: "src/gone.py", Deletions: 1, Status: "DELETED"},
+		{Path: "src/new_name.py", Status: "RENAMED"},
+	}, files)
+}
+
+func TestBitbucketClient_GetFileContents(t *testing.T) {
+	test.WithHTTPMock(t)
+
+	registerBitbucketBranch("main", bitbucketTestHash)
+	httpmock.RegisterResponder("GET", bitbucketTestRepoURL+"/src/"+bitbucketTestHash+"/src/app.py",
+		func(req *http.Request) (*http.Response, error) {
+			username, password, ok := req.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "copier-bot", username)
+			assert.Equal(t, "app-password", password)
+			return httpmock.NewStringResponse(http.StatusOK, "hello"), nil
+		})
+	httpmock.RegisterResponder("GET", bitbucketTestRepoURL+"/src/"+bitbucketTestHash+"/missing.py",
+		httpmock.NewStringResponder(http.StatusNotFound, `{"type":"error"}`))
+
+	client := services.NewBitbucketClient("https://bitbucket.example.com/2.0", "copier-bot", "app-password")
+	file, err := client.GetFileContents(context.Background(), "docs/examples", "src/app.py", "main")
+	require.NoError(t, err)
+
+	content, err := file.GetContent()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", content)
+	assert.Equal(t, "app.py", file.GetName())
+	assert.Equal(t, "src/app.py", file.GetPath())
+
+	_, found, err := client.GetFile(context.Background(), "docs/examples", "missing.py", bitbucketTestHash)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestBitbucketClient_GetRepoTree(t *testing.T) {
+	test.WithHTTPMock(t)
+
+	httpmock.RegisterResponder("GET", bitbucketTestRepoURL+"/src/"+bitbucketTestHash+"/",
+		httpmock.NewJsonResponderOrPanic(http.StatusOK, map[string]interface{}{
+			"values": []map[string]interface{}{
+				{"type": "commit_directory", "path": "scripts"},
+				{"type": "commit_file", "path": "scripts/run.sh", "attributes": []string{"executable"}},
+				{"type": "commit_file", "path": "README.md", "attributes": []string{}},
+			},
+		}))
+
+	client := services.NewBitbucketClient("https://bitbucket.example.com/2.0", "", "token")
+	modes, truncated, err := client.GetRepoTree(context.Background(), "docs/examples", bitbucketTestHash)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Equal(t, map[string]string{"scripts/run.sh": types.FileModeExecutable, "README.md": types.FileModeRegular}, modes)
+}
+
+func TestBitbucketClient_CommitFiles(t *testing.T) {
+	test.WithHTTPMock(t)
+
+	registerBitbucketBranch("main", bitbucketTestHash)
+	httpmock.RegisterResponder("POST", bitbucketTestRepoURL+"/src",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+			require.NoError(t, req.ParseMultipartForm(1<<20))
+			assert.Equal(t, "Update examples", req.FormValue("message"))
+			assert.Equal(t, "main", req.FormValue("branch"))
+			assert.Equal(t, bitbucketTestHash, req.FormValue("parents"))
+			assert.Equal(t, "Docs Bot <docs@example.com>", req.FormValue("author"))
+			assert.Equal(t, []string{"old.py"}, req.MultipartForm.Value["files"])
+			assert.Contains(t, req.MultipartForm.File, "src/app.py")
+			return httpmock.NewStringResponse(http.StatusCreated, ""), nil
+		})
+
+	client := services.NewBitbucketClient("https://bitbucket.example.com/2.0", "", "token")
+	_, err := client.CommitFiles(context.Background(), "docs/examples", "main", services.ProviderCommit{
+		Files:       map[string]string{"src/app.py": "print('hi')\n"},
+		DeletePaths: []string{"old.py", "src/app.py"},
+		Message:     "Update examples",
+		Author:      &github.CommitAuthor{Name: github.String("Docs Bot"), Email: github.String("docs@example.com")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, httpmock.GetCallCountInfo()["POST "+bitbucketTestRepoURL+"/src"])
+}
+
+func TestAddFilesToTargetRepoBranch_BitbucketPR(t *testing.T) {
+	test.WithHTTPMock(t)
+	t.Setenv("BITBUCKET_BASE_URL", "https://bitbucket.example.com/2.0")
+	t.Setenv("BITBUCKET_TOKEN", "token")
+
+	registerBitbucketBranch("main", bitbucketTestHash)
+	httpmock.RegisterRegexpResponder("GET", regexp.MustCompile(bitbucketTestRepoURL+`/refs/branches/copier/\d{8}-\d{6}$`),
+		httpmock.NewJsonResponderOrPanic(http.StatusOK, map[string]interface{}{"target": map[string]string{"hash": bitbucketTestHash}}))
+	httpmock.RegisterRegexpResponder("DELETE", regexp.MustCompile(bitbucketTestRepoURL+`/refs/branches/copier/\d{8}-\d{6}$`),
+		httpmock.NewStringResponder(http.StatusNotFound, ""))
+	httpmock.RegisterResponder("POST", bitbucketTestRepoURL+"/refs/branches",
+		httpmock.NewJsonResponderOrPanic(http.StatusCreated, map[string]interface{}{}))
+	httpmock.RegisterResponder("POST", bitbucketTestRepoURL+"/src",
+		httpmock.NewStringResponder(http.StatusCreated, ""))
+
+	var prRequest map[string]interface{}
+	httpmock.RegisterResponder("POST", bitbucketTestRepoURL+"/pullrequests",
+		func(req *http.Request) (*http.Response, error) {
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&prRequest))
+			return httpmock.NewJsonResponse(http.StatusCreated, map[string]interface{}{
+				"id":    12,
+				"links": map[string]interface{}{"html": map[string]string{"href": "https://bitbucket.org/docs/examples/pull-requests/12"}},
+			})
+		})
+
+	key := types.UploadKey{RepoName: "bitbucket:docs/examples", BranchPath: "refs/heads/main"}
+	services.FilesToUpload = map[types.UploadKey]types.UploadFileContent{
+		key: {
+			TargetBranch:   "main",
+			CommitStrategy: "pr",
+			PRTitle:        "Update examples",
+			Content: []github.RepositoryContent{{
+				Name:    github.String("src/app.py"),
+				Content: github.String("print('hi')\n"),
+			}},
+		},
+	}
+	t.Cleanup(func() { services.FilesToUpload = nil })
+
+	results := services.AddFilesToTargetRepoBranchWithFetcher(context.Background(), nil, nil)
+	require.NoError(t, results[key].Err)
+	assert.Equal(t, "https://bitbucket.org/docs/examples/pull-requests/12", results[key].PRURL)
+	assert.Equal(t, "Update examples", prRequest["title"])
+	assert.Equal(t, map[string]interface{}{"branch": map[string]interface{}{"name": "main"}}, prRequest["destination"])
+}
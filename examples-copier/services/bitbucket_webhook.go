@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// bitbucketPullRequestMergedEventType is the X-Event-Key header value for merged pull request events
+const bitbucketPullRequestMergedEventType = "pullrequest:fulfilled"
+
+// bitbucketPullRequestEvent holds the fields of a Bitbucket Cloud pull request webhook payload the copier uses
+type bitbucketPullRequestEvent struct {
+	Repository *struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	PullRequest *struct {
+		ID     int    `json:"id"`
+		Title  string `json:"title"`
+		State  string `json:"state"`
+		Author struct {
+			Nickname    string `json:"nickname"`
+			DisplayName string `json:"display_name"`
+		} `json:"author"`
+		MergeCommit *struct {
+			Hash string `json:"hash"`
+		} `json:"merge_commit"`
+		Source struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+		} `json:"source"`
+		Destination struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+		} `json:"destination"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	} `json:"pullrequest"`
+}
+
+// merged reports whether the event describes a merged pull request
+func (e *bitbucketPullRequestEvent) merged() bool {
+	return e.PullRequest != nil && e.PullRequest.State == "MERGED"
+}
+
+// commitSHA returns the pull request's merge commit
+func (e *bitbucketPullRequestEvent) commitSHA() string {
+	if e.PullRequest.MergeCommit == nil {
+		return ""
+	}
+	return e.PullRequest.MergeCommit.Hash
+}
+
+// validateBitbucketPullRequestEvent checks that a pull request event carries the fields
+// the copier relies on and returns the JSON paths of any that are missing.
+// As with GitHub, the stricter checks only apply to merged pull requests.
+func validateBitbucketPullRequestEvent(evt *bitbucketPullRequestEvent) []string {
+	pr := evt.PullRequest
+	if pr == nil {
+		return []string{"pullrequest"}
+	}
+
+	var missing []string
+	if pr.State == "" {
+		missing = append(missing, "pullrequest.state")
+	}
+	if !evt.merged() {
+		return missing
+	}
+
+	if pr.ID == 0 {
+		missing = append(missing, "pullrequest.id")
+	}
+	if evt.commitSHA() == "" {
+		missing = append(missing, "pullrequest.merge_commit.hash")
+	}
+	if pr.Destination.Branch.Name == "" {
+		missing = append(missing, "pullrequest.destination.branch.name")
+	}
+	if evt.Repository == nil || evt.Repository.FullName == "" {
+		missing = append(missing, "repository.full_name")
+	}
+
+	return missing
+}
+
+// HandleBitbucketWebhookWithContainer handles incoming Bitbucket Cloud pull request webhooks. Merged pull
+// requests are processed the same way as merged GitHub PRs, against workflows whose source platform is "bitbucket".
+func HandleBitbucketWebhookWithContainer(w http.ResponseWriter, r *http.Request, config *configs.Config, container *ServiceContainer) {
+	startTime := time.Now()
+	ctx := r.Context()
+
+	limited := io.LimitReader(r.Body, maxWebhookBodyBytes)
+	payload, err := io.ReadAll(limited)
+	if err != nil {
+		rejectWebhook(ctx, w, r, container, http.StatusBadRequest, WebhookErrorResponse{
+			Error:   webhookErrInvalidBody,
+			Message: "invalid body",
+		}, err)
+		return
+	}
+
+	eventType := r.Header.Get("X-Event-Key")
+	if eventType == "" {
+		rejectWebhook(ctx, w, r, container, http.StatusBadRequest, WebhookErrorResponse{
+			Error:         webhookErrMissingEventType,
+			Message:       "missing event type",
+			MissingFields: []string{"X-Event-Key"},
+		}, nil)
+		return
+	}
+
+	// Bitbucket signs payloads the same way GitHub does, in the X-Hub-Signature header
+	if config.BitbucketWebhookSecret != "" {
+		if !simpleVerifySignature(r.Header.Get("X-Hub-Signature"), payload, []byte(config.BitbucketWebhookSecret)) {
+			rejectWebhook(ctx, w, r, container, http.StatusUnauthorized, WebhookErrorResponse{
+				Error:   webhookErrInvalidSignature,
+				Message: "webhook signature verification failed",
+			}, nil)
+			return
+		}
+	}
+
+	source := container.EventSources.Webhook(types.SourcePlatformBitbucket, eventType)
+	if source == nil {
+		container.MetricsCollector.RecordWebhookIgnored(eventType)
+		LogInfoCtx(ctx, "ignoring non-merged pull request Bitbucket event", map[string]interface{}{
+			"event_type": eventType,
+			"size_bytes": len(payload),
+		})
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Keep the payload so the delivery can be replayed through /admin/replay
+	container.WebhookArchive.Record(ctx, types.SourcePlatformBitbucket, eventType, payload)
+
+	event := convertEvent(ctx, w, r, source, payload, container)
+	if event == nil {
+		return
+	}
+	LogInfoCtx(ctx, "event converted", map[string]interface{}{
+		"event_type": eventType,
+		"elapsed_ms": time.Since(startTime).Milliseconds(),
+	})
+	acceptCopyEvent(ctx, w, r, *event, config, container)
+}
+
+// bitbucketPullRequestEventSource converts merged Bitbucket pull requests. Pull requests the copier
+// opened don't trigger a copy.
+type bitbucketPullRequestEventSource struct{}
+
+func (bitbucketPullRequestEventSource) Name() string { return bitbucketPullRequestMergedEventType }
+
+func (bitbucketPullRequestEventSource) Convert(ctx context.Context, payload []byte) (*CopyEvent, string, error) {
+	var prEvt bitbucketPullRequestEvent
+	if err := json.Unmarshal(payload, &prEvt); err != nil {
+		return nil, "", invalidPayload(err)
+	}
+	if missing := validateBitbucketPullRequestEvent(&prEvt); len(missing) > 0 {
+		return nil, "", missingFields("pullrequest", missing)
+	}
+	if !prEvt.merged() {
+		return nil, fmt.Sprintf("PR not merged (state %q)", prEvt.PullRequest.State), nil
+	}
+
+	// Skip PRs the copier opened, so workflows copying in opposite directions don't trigger each other.
+	// Bitbucket PRs can't be labeled, so they're only recognized by their branch.
+	if copierBranch.MatchString(prEvt.PullRequest.Source.Branch.Name) {
+		return nil, "PR opened by the copier", nil
+	}
+
+	change := &CopyEvent{
+		Platform:   types.SourcePlatformBitbucket,
+		Repo:       prEvt.Repository.FullName,
+		Number:     prEvt.PullRequest.ID,
+		CommitSHA:  prEvt.commitSHA(),
+		BaseBranch: prEvt.PullRequest.Destination.Branch.Name,
+		URL:        prEvt.PullRequest.Links.HTML.Href,
+		Title:      prEvt.PullRequest.Title,
+		Author:     prEvt.PullRequest.Author.Nickname,
+	}
+	if change.Author == "" {
+		change.Author = prEvt.PullRequest.Author.DisplayName
+	}
+
+	LogInfoCtx(ctx, "processing merged PR", map[string]interface{}{
+		"pr_number":   change.Number,
+		"sha":         change.CommitSHA,
+		"repo":        change.Repo,
+		"base_branch": change.BaseBranch,
+	})
+	return change, "", nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/stretchr/testify/assert"
+)
+
+const bitbucketMergedPayload = `{
+	"repository": {"full_name": "docs/examples"},
+	"pullrequest": {
+		"id": 7,
+		"state": "MERGED",
+		"merge_commit": {"hash": "0123456789ab"},
+		"destination": {"branch": {"name": "main"}},
+		"links": {"html": {"href": "https://bitbucket.org/docs/examples/pull-requests/7"}}
+	}
+}`
+
+func bitbucketSignature(payload string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHandleBitbucketWebhook_InvalidSignature(t *testing.T) {
+	config := &configs.Config{BitbucketWebhookSecret: "secret"}
+	container := newGitLabTestContainer(t, config)
+
+	req := httptest.NewRequest("POST", "/bitbucket/webhook", bytes.NewReader([]byte(bitbucketMergedPayload)))
+	req.Header.Set("X-Event-Key", bitbucketPullRequestMergedEventType)
+	req.Header.Set("X-Hub-Signature", bitbucketSignature(bitbucketMergedPayload, "wrong"))
+	req.Header.Set("X-Request-UUID", "uuid-1")
+	w := httptest.NewRecorder()
+
+	HandleBitbucketWebhookWithContainer(w, req, config, container)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), webhookErrInvalidSignature)
+	assert.Contains(t, w.Body.String(), "uuid-1")
+}
+
+func TestHandleBitbucketWebhook_MissingEventType(t *testing.T) {
+	config := &configs.Config{}
+	container := newGitLabTestContainer(t, config)
+
+	req := httptest.NewRequest("POST", "/bitbucket/webhook", bytes.NewReader([]byte(`{}`)))
+	w := httptest.NewRecorder()
+
+	HandleBitbucketWebhookWithContainer(w, req, config, container)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "X-Event-Key")
+}
+
+func TestHandleBitbucketWebhook_IgnoresOtherEvents(t *testing.T) {
+	config := &configs.Config{BitbucketWebhookSecret: "secret"}
+	container := newGitLabTestContainer(t, config)
+
+	payload := `{"push": {}}`
+	req := httptest.NewRequest("POST", "/bitbucket/webhook", bytes.NewReader([]byte(payload)))
+	req.Header.Set("X-Event-Key", "repo:push")
+	req.Header.Set("X-Hub-Signature", bitbucketSignature(payload, "secret"))
+	w := httptest.NewRecorder()
+
+	HandleBitbucketWebhookWithContainer(w, req, config, container)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestHandleBitbucketWebhook_MergedPRMissingFields(t *testing.T) {
+	config := &configs.Config{}
+	container := newGitLabTestContainer(t, config)
+
+	payload := `{"pullrequest": {"id": 7, "state": "MERGED"}}`
+	req := httptest.NewRequest("POST", "/bitbucket/webhook", bytes.NewReader([]byte(payload)))
+	req.Header.Set("X-Event-Key", bitbucketPullRequestMergedEventType)
+	w := httptest.NewRecorder()
+
+	HandleBitbucketWebhookWithContainer(w, req, config, container)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "pullrequest.merge_commit.hash")
+	assert.Contains(t, body, "pullrequest.destination.branch.name")
+	assert.Contains(t, body, "repository.full_name")
+}
+
+func TestHandleBitbucketWebhook_SkipsCopierPR(t *testing.T) {
+	config := &configs.Config{}
+	container := newGitLabTestContainer(t, config)
+
+	payload := `{
+		"repository": {"full_name": "docs/examples"},
+		"pullrequest": {
+			"id": 8,
+			"state": "MERGED",
+			"merge_commit": {"hash": "0123456789ab"},
+			"source": {"branch": {"name": "copier/20250101-120000"}},
+			"destination": {"branch": {"name": "main"}}
+		}
+	}`
+	req := httptest.NewRequest("POST", "/bitbucket/webhook", bytes.NewReader([]byte(payload)))
+	req.Header.Set("X-Event-Key", bitbucketPullRequestMergedEventType)
+	w := httptest.NewRecorder()
+
+	HandleBitbucketWebhookWithContainer(w, req, config, container)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestHandleBitbucketWebhook_RejectsWhileShuttingDown(t *testing.T) {
+	config := &configs.Config{BitbucketWebhookSecret: "secret"}
+	container := newGitLabTestContainer(t, config)
+	container.InFlight.Drain(t.Context())
+
+	req := httptest.NewRequest("POST", "/bitbucket/webhook", bytes.NewReader([]byte(bitbucketMergedPayload)))
+	req.Header.Set("X-Event-Key", bitbucketPullRequestMergedEventType)
+	req.Header.Set("X-Hub-Signature", bitbucketSignature(bitbucketMergedPayload, "secret"))
+	w := httptest.NewRecorder()
+
+	HandleBitbucketWebhookWithContainer(w, req, config, container)
+
+	// The payload is valid, so the handler gets as far as registering the job and is refused
+	// because the server is draining
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), webhookErrShuttingDown)
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	. "github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// sourcePRBranchPrefix starts the name of every branch the branch commit strategy pushes to
+const sourcePRBranchPrefix = "copier/source-"
+
+// sourcePRBranch returns the branch the branch commit strategy pushes a change's files to, such as
+// "copier/source-pr-123", "copier/source-mr-45" for GitLab merge requests, "copier/source-push-1a2b3c4"
+// for pushes, "copier/source-run-987654" for workflow runs, "copier/source-release-v1.2.0" for releases, or
+// "copier/source-chain-5d6e7f8" for chained changes. The name only depends on the change, so copying the same change again updates the same branch.
+func sourcePRBranch(change CopyEvent) string {
+	switch {
+	case change.trigger() == WorkflowTriggerPush:
+		return sourcePRBranchPrefix + "push-" + strings.ToLower(ShortSHA(change.CommitSHA))
+	case change.trigger() == WorkflowTriggerWorkflowRun:
+		return fmt.Sprintf("%srun-%d", sourcePRBranchPrefix, change.RunID)
+	case change.trigger() == WorkflowTriggerRelease:
+		return sourcePRBranchPrefix + "release-" + change.ReleaseTag
+	case change.trigger() == WorkflowTriggerChained:
+		return sourcePRBranchPrefix + "chain-" + strings.ToLower(ShortSHA(change.CommitSHA))
+	case change.Platform == SourcePlatformGitLab:
+		return fmt.Sprintf("%smr-%d", sourcePRBranchPrefix, change.Number)
+	default:
+		return fmt.Sprintf("%spr-%d", sourcePRBranchPrefix, change.Number)
+	}
+}
+
+// addFilesToSourcePRBranch pushes the files to the upload's source PR branch without opening a PR, so CI in
+// the target repo can build it. The branch is recreated from the target branch first, so it only ever has
+// the latest copy of the change. Copier branches that haven't been pushed to for COPIER_BRANCH_TTL_DAYS are
+// deleted afterwards. Returns the new commit's SHA.
+func addFilesToSourcePRBranch(ctx context.Context, provider RepoProvider, repo string, key UploadKey, value UploadFileContent,
+	commitMessage string, author *github.CommitAuthor) (string, error) {
+
+	branch := value.PushBranch
+	if branch == "" {
+		return "", fmt.Errorf("no source PR to name the branch after")
+	}
+
+	baseBranch := strings.TrimPrefix(key.BranchPath, "refs/heads/")
+	if err := provider.CreateBranch(ctx, repo, branch, baseBranch); err != nil {
+		return "", fmt.Errorf("create branch: %w", err)
+	}
+	sha, err := provider.CommitFiles(ctx, repo, branch, ProviderCommit{
+		Files:       uploadEntries(value.Content),
+		FileModes:   value.FileModes,
+		DeletePaths: value.DeletePaths,
+		Message:     commitMessage,
+		Author:      author,
+	})
+	if err != nil {
+		return "", fmt.Errorf("commit to branch %s: %w", branch, err)
+	}
+	LogInfoCtx(ctx, "Pushed files to source PR branch", map[string]interface{}{
+		"target_repo": key.RepoName,
+		"branch":      branch,
+		"base":        baseBranch,
+		"commit_sha":  sha,
+	})
+
+	// An unset or invalid setting falls back to the default
+	ttlDays, _ := parseIntWithDefault(os.Getenv(configs.CopierBranchTTLDays), configs.NewConfig().CopierBranchTTLDays)
+	pruneStaleCopierBranches(ctx, provider, repo, branch, time.Duration(ttlDays)*24*time.Hour, time.Now())
+	return sha, nil
+}
+
+// pruneStaleCopierBranches deletes the source PR branches in repo whose head commit is older than ttl,
+// except keep, the branch just pushed to. A ttl of 0 keeps every branch. Failures are only logged, since
+// they don't affect the upload.
+func pruneStaleCopierBranches(ctx context.Context, provider RepoProvider, repo string, keep string, ttl time.Duration, now time.Time) {
+	if ttl <= 0 {
+		return
+	}
+	branches, err := provider.ListBranches(ctx, repo, sourcePRBranchPrefix)
+	if err != nil {
+		LogWarningCtx(ctx, "Failed to list copier branches for cleanup", map[string]interface{}{
+			"target_repo": repo,
+			"error":       err.Error(),
+		})
+		return
+	}
+	for _, branch := range branches {
+		if branch.Name == keep || !copierBranch.MatchString(branch.Name) || now.Sub(branch.CommittedAt) < ttl {
+			continue
+		}
+		LogInfoCtx(ctx, "Deleting stale copier branch", map[string]interface{}{
+			"target_repo":  repo,
+			"branch":       branch.Name,
+			"committed_at": branch.CommittedAt,
+		})
+		provider.DeleteBranch(ctx, repo, branch.Name)
+	}
+}
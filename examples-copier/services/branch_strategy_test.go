@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// branchTestProvider records the branches the branch strategy creates, commits to, and deletes
+type branchTestProvider struct {
+	RepoProvider
+	branches []ProviderBranch
+	created  []string
+	commits  map[string]ProviderCommit
+	deleted  []string
+}
+
+func (p *branchTestProvider) CreateBranch(ctx context.Context, repo string, branch string, baseBranch string) error {
+	p.created = append(p.created, branch+" from "+baseBranch)
+	return nil
+}
+
+func (p *branchTestProvider) CommitFiles(ctx context.Context, repo string, branch string, commit ProviderCommit) (string, error) {
+	if p.commits == nil {
+		p.commits = make(map[string]ProviderCommit)
+	}
+	p.commits[branch] = commit
+	return "sha-" + branch, nil
+}
+
+func (p *branchTestProvider) ListBranches(ctx context.Context, repo string, prefix string) ([]ProviderBranch, error) {
+	return p.branches, nil
+}
+
+func (p *branchTestProvider) DeleteBranch(ctx context.Context, repo string, branch string) {
+	p.deleted = append(p.deleted, branch)
+}
+
+func TestSourcePRBranch(t *testing.T) {
+	assert.Equal(t, "copier/source-pr-123", sourcePRBranch(CopyEvent{Platform: types.SourcePlatformGitHub, Number: 123}))
+	assert.Equal(t, "copier/source-mr-45", sourcePRBranch(CopyEvent{Platform: types.SourcePlatformGitLab, Number: 45}))
+	assert.Equal(t, "copier/source-push-1a2b3c4", sourcePRBranch(CopyEvent{
+		Platform:  types.SourcePlatformGitHub,
+		Trigger:   types.WorkflowTriggerPush,
+		CommitSHA: "1A2B3C4D5E6F",
+	}))
+	assert.Equal(t, "copier/source-run-987654", sourcePRBranch(CopyEvent{
+		Platform: types.SourcePlatformGitHub,
+		Trigger:  types.WorkflowTriggerWorkflowRun,
+		RunID:    987654,
+	}))
+	assert.Equal(t, "copier/source-release-v1.2.0", sourcePRBranch(CopyEvent{
+		Platform:   types.SourcePlatformGitHub,
+		Trigger:    types.WorkflowTriggerRelease,
+		ReleaseTag: "v1.2.0",
+	}))
+}
+
+func TestCopierBranch_MatchesSourcePRBranches(t *testing.T) {
+	changes := []CopyEvent{
+		{Platform: types.SourcePlatformGitHub, Number: 123},
+		{Platform: types.SourcePlatformGitLab, Number: 45},
+		{Platform: types.SourcePlatformGitHub, Trigger: types.WorkflowTriggerPush, CommitSHA: "1A2B3C4D5E6F"},
+		{Platform: types.SourcePlatformGitHub, Trigger: types.WorkflowTriggerChained, CommitSHA: "5d6e7f8a9b"},
+		{Platform: types.SourcePlatformGitHub, Trigger: types.WorkflowTriggerWorkflowRun, RunID: 987654},
+		{Platform: types.SourcePlatformGitHub, Trigger: types.WorkflowTriggerRelease, ReleaseTag: "v1.2.0"},
+		{Platform: types.SourcePlatformGitHub, Trigger: types.WorkflowTriggerRelease, ReleaseTag: "sdk/v2.0.0-rc.1+build_7"},
+	}
+	for _, change := range changes {
+		branch := sourcePRBranch(change)
+		assert.True(t, copierBranch.MatchString(branch), branch)
+		assert.True(t, isCopierCommit("Merge pull request #7 from org/"+branch+"\n\nUpdate examples"), branch)
+	}
+	assert.False(t, copierBranch.MatchString("copier/source-release-"))
+	assert.False(t, copierBranch.MatchString("copier/source-run-abc"))
+}
+
+func TestPruneStaleCopierBranches_RunAndReleaseBranches(t *testing.T) {
+	now := time.Now()
+	run := sourcePRBranch(CopyEvent{Trigger: types.WorkflowTriggerWorkflowRun, RunID: 42})
+	release := sourcePRBranch(CopyEvent{Trigger: types.WorkflowTriggerRelease, ReleaseTag: "v1.0.0"})
+	provider := &branchTestProvider{branches: []ProviderBranch{
+		{Name: run, CommittedAt: now.AddDate(0, 0, -30)},
+		{Name: release, CommittedAt: now.AddDate(0, 0, -30)},
+	}}
+	pruneStaleCopierBranches(context.Background(), provider, "dst-org/samples", "", 7*24*time.Hour, now)
+	assert.Equal(t, []string{run, release}, provider.deleted)
+}
+
+func TestAddFilesToSourcePRBranch(t *testing.T) {
+	t.Setenv("COPIER_BRANCH_TTL_DAYS", "7")
+	now := time.Now()
+	provider := &branchTestProvider{branches: []ProviderBranch{
+		{Name: "copier/source-pr-123", CommittedAt: now.AddDate(0, 0, -30)}, // the branch being pushed: kept
+		{Name: "copier/source-pr-100", CommittedAt: now.AddDate(0, 0, -8)},  // stale: deleted
+		{Name: "copier/source-pr-110", CommittedAt: now.AddDate(0, 0, -2)},  // recent: kept
+		{Name: "copier/source-notes", CommittedAt: now.AddDate(0, 0, -90)},  // not a copier branch: kept
+	}}
+	key := types.UploadKey{RepoName: "dst-org/samples", BranchPath: "refs/heads/main"}
+	content := types.UploadFileContent{
+		Content:    []github.RepositoryContent{{Name: github.String("server/main.go"), Content: github.String("package main")}},
+		PushBranch: "copier/source-pr-123",
+	}
+
+	sha, err := addFilesToSourcePRBranch(context.Background(), provider, "dst-org/samples", key, content, "Update examples", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "sha-copier/source-pr-123", sha)
+	assert.Equal(t, []string{"copier/source-pr-123 from main"}, provider.created)
+	assert.Contains(t, provider.commits["copier/source-pr-123"].Files, "server/main.go")
+	assert.Equal(t, []string{"copier/source-pr-100"}, provider.deleted)
+}
+
+func TestAddFilesToSourcePRBranch_NoSourcePR(t *testing.T) {
+	provider := &branchTestProvider{}
+	key := types.UploadKey{RepoName: "dst-org/samples", BranchPath: "refs/heads/main"}
+
+	_, err := addFilesToSourcePRBranch(context.Background(), provider, "dst-org/samples", key, types.UploadFileContent{}, "Update examples", nil)
+	require.Error(t, err)
+	assert.Empty(t, provider.created, "nothing is pushed without a branch name")
+}
+
+func TestPruneStaleCopierBranches_Disabled(t *testing.T) {
+	provider := &branchTestProvider{branches: []ProviderBranch{{Name: "copier/source-pr-1"}}}
+	pruneStaleCopierBranches(context.Background(), provider, "dst-org/samples", "", 0, time.Now())
+	assert.Empty(t, provider.deleted)
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// failedCheckConclusions are the check run conclusions that count as a broken build
+var failedCheckConclusions = map[string]bool{
+	"failure":         true,
+	"timed_out":       true,
+	"cancelled":       true,
+	"action_required": true,
+	"startup_failure": true,
+}
+
+// BuildCheck is a commit the copier made on a destination branch, waiting for its check runs to finish
+type BuildCheck struct {
+	WorkflowName  string
+	SourceRepo    string
+	PRNumber      int
+	PRURL         string
+	SourceSHA     string // The source commit that was copied
+	TargetRepo    string // "owner/name"
+	TargetBranch  string
+	CommitSHA     string   // The commit on the target branch whose check runs are checked
+	Checks        []string // Check run names to wait for; empty waits for every check run
+	Deadline      time.Time
+	Notifications *types.NotificationConfig // The workflow's notifications, or nil for the service's
+	CorrelationID string
+}
+
+// BuildVerifier polls the check runs on commits the copier made to destination branches, for workflows with
+// verify_build enabled. When a check run fails, or the check runs don't finish before the workflow's timeout,
+// the commit is recorded in the audit log and reported to the workflow's Slack notifications. Pending checks
+// are kept in memory, so they're dropped on restart.
+type BuildVerifier struct {
+	pollInterval  time.Duration
+	auditLogger   AuditLogger
+	config        *configs.Config
+	listCheckRuns func(ctx context.Context, repo string, sha string) ([]*github.CheckRun, error)
+	now           func() time.Time
+
+	mu      sync.Mutex
+	pending []*BuildCheck
+}
+
+// NewBuildVerifier creates a build verifier that polls every BUILD_CHECK_POLL_INTERVAL seconds
+func NewBuildVerifier(config *configs.Config, auditLogger AuditLogger) *BuildVerifier {
+	return &BuildVerifier{
+		pollInterval:  time.Duration(config.BuildCheckPollInterval) * time.Second,
+		auditLogger:   auditLogger,
+		config:        config,
+		listCheckRuns: listGitHubCheckRuns,
+		now:           time.Now,
+	}
+}
+
+// Watch adds a commit to wait for
+func (v *BuildVerifier) Watch(ctx context.Context, check *BuildCheck) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.pending = append(v.pending, check)
+	LogInfoCtx(ctx, "waiting for destination check runs", map[string]interface{}{
+		"workflow_name": check.WorkflowName,
+		"target_repo":   check.TargetRepo,
+		"commit_sha":    check.CommitSHA,
+		"deadline":      check.Deadline,
+	})
+}
+
+// Pending returns the number of commits still waiting for their check runs
+func (v *BuildVerifier) Pending() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.pending)
+}
+
+// ProcessDue checks the check runs on every pending commit. Commits whose check runs have all finished, or
+// whose deadline has passed, are reported if the build failed and stop being checked.
+func (v *BuildVerifier) ProcessDue(ctx context.Context) {
+	v.mu.Lock()
+	checks := v.pending
+	v.pending = nil
+	v.mu.Unlock()
+
+	var remaining []*BuildCheck
+	for _, check := range checks {
+		if ctx.Err() != nil {
+			remaining = append(remaining, check)
+			continue
+		}
+		if !v.verify(WithCorrelationID(ctx, check.CorrelationID), check) {
+			remaining = append(remaining, check)
+		}
+	}
+
+	v.mu.Lock()
+	v.pending = append(remaining, v.pending...)
+	v.mu.Unlock()
+}
+
+// Run checks pending commits every pollInterval until ctx is cancelled
+func (v *BuildVerifier) Run(ctx context.Context) {
+	if v.pollInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(v.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			v.ProcessDue(ctx)
+		}
+	}
+}
+
+// verify checks one commit's check runs, returning true once it's done with the commit
+func (v *BuildVerifier) verify(ctx context.Context, check *BuildCheck) bool {
+	fields := map[string]interface{}{
+		"workflow_name": check.WorkflowName,
+		"target_repo":   check.TargetRepo,
+		"commit_sha":    check.CommitSHA,
+	}
+	expired := !v.now().Before(check.Deadline)
+
+	runs, err := v.listCheckRuns(ctx, check.TargetRepo, check.CommitSHA)
+	if err != nil {
+		LogWarningCtx(ctx, "failed to list destination check runs", map[string]interface{}{
+			"target_repo": check.TargetRepo,
+			"commit_sha":  check.CommitSHA,
+			"error":       err.Error(),
+		})
+		return expired
+	}
+
+	runs, missing := filterCheckRuns(runs, check.Checks)
+	if len(runs) == 0 && expired {
+		LogWarningCtx(ctx, "no destination check runs reported before the verify_build timeout", fields)
+		return true
+	}
+
+	var failed []string
+	finished := len(runs) > 0 && len(missing) == 0
+	for _, run := range runs {
+		if run.GetStatus() != "completed" {
+			finished = false
+			continue
+		}
+		if failedCheckConclusions[run.GetConclusion()] {
+			failed = append(failed, fmt.Sprintf("%s: %s", run.GetName(), run.GetConclusion()))
+		}
+	}
+	if !finished && !expired {
+		return false
+	}
+
+	timedOut := !finished
+	if timedOut {
+		for _, name := range missing {
+			failed = append(failed, name+": not reported")
+		}
+	}
+	if len(failed) == 0 && !timedOut {
+		LogInfoCtx(ctx, "destination check runs passed", fields)
+		return true
+	}
+	sort.Strings(failed)
+	v.reportFailure(ctx, check, failed, timedOut)
+	return true
+}
+
+// reportFailure records a failed build in the audit log and sends it to the workflow's notifications
+func (v *BuildVerifier) reportFailure(ctx context.Context, check *BuildCheck, failed []string, timedOut bool) {
+	message := fmt.Sprintf("destination check runs failed on %s: %v", check.CommitSHA, failed)
+	if timedOut {
+		message = fmt.Sprintf("destination check runs didn't finish on %s before the verify_build timeout", check.CommitSHA)
+	}
+	LogErrorCtx(ctx, message, nil, map[string]interface{}{
+		"workflow_name": check.WorkflowName,
+		"target_repo":   check.TargetRepo,
+		"target_branch": check.TargetBranch,
+		"commit_sha":    check.CommitSHA,
+	})
+
+	if v.auditLogger != nil {
+		if err := v.auditLogger.LogErrorEvent(ctx, &AuditEvent{
+			SourceRepo:   check.SourceRepo,
+			TargetRepo:   check.TargetRepo,
+			CommitSHA:    check.SourceSHA,
+			PRNumber:     check.PRNumber,
+			ErrorMessage: message,
+			AdditionalData: map[string]any{
+				"build_verification": true,
+				"workflow_name":      check.WorkflowName,
+				"target_branch":      check.TargetBranch,
+				"target_commit_sha":  check.CommitSHA,
+				"failed_checks":      failed,
+				"timed_out":          timedOut,
+			},
+		}); err != nil {
+			LogWarning(fmt.Sprintf("Failed to record failed destination build for %s in audit log: %v", check.CommitSHA, err))
+		}
+	}
+
+	notifications := check.Notifications
+	if notifications == nil {
+		notifications = &types.NotificationConfig{}
+	}
+	event := &BuildFailedEvent{
+		WorkflowName: check.WorkflowName,
+		PRNumber:     check.PRNumber,
+		PRURL:        check.PRURL,
+		SourceRepo:   check.SourceRepo,
+		TargetRepo:   check.TargetRepo,
+		TargetBranch: check.TargetBranch,
+		CommitSHA:    check.CommitSHA,
+		CommitURL:    fmt.Sprintf("https://github.com/%s/commit/%s", check.TargetRepo, check.CommitSHA),
+		FailedChecks: failed,
+		TimedOut:     timedOut,
+	}
+	if err := workflowNotifier(notifications, v.config).NotifyBuildFailed(ctx, event); err != nil {
+		LogWarningCtx(ctx, "failed to send build failure notification", map[string]interface{}{
+			"workflow_name": check.WorkflowName,
+			"error":         err.Error(),
+		})
+	}
+}
+
+// filterCheckRuns returns the check runs with the given names, keeping only the latest run of each name,
+// and the names that have no run yet. With no names, every check run is returned.
+func filterCheckRuns(runs []*github.CheckRun, names []string) (matched []*github.CheckRun, missing []string) {
+	if len(names) == 0 {
+		return runs, nil
+	}
+	byName := make(map[string]*github.CheckRun, len(runs))
+	for _, run := range runs {
+		if latest, ok := byName[run.GetName()]; !ok || run.GetID() > latest.GetID() {
+			byName[run.GetName()] = run
+		}
+	}
+	for _, name := range names {
+		if run, ok := byName[name]; ok {
+			matched = append(matched, run)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	return matched, missing
+}
+
+// listGitHubCheckRuns lists the check runs on a commit in a GitHub repo
+func listGitHubCheckRuns(ctx context.Context, repo string, sha string) ([]*github.CheckRun, error) {
+	owner, name := parseRepoPath(repo)
+	client, err := GetRestClientForOrg(owner)
+	if err != nil {
+		return nil, fmt.Errorf("get GitHub client for org %s: %w", owner, err)
+	}
+
+	var runs []*github.CheckRun
+	opts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		result, resp, err := client.Checks.ListCheckRunsForRef(ctx, owner, name, sha, opts)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, result.CheckRuns...)
+		if resp.NextPage == 0 {
+			return runs, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// watchDestinationBuilds waits for the check runs on each commit a workflow with verify_build copied to its
+// destination. Dry-run workflows, failed uploads, and pull requests left open for review aren't checked.
+func watchDestinationBuilds(ctx context.Context, verifier *BuildVerifier, change CopyEvent, runs []*workflowRun,
+	uploads map[types.UploadKey]UploadResult) {
+
+	if verifier == nil {
+		return
+	}
+	for _, run := range runs {
+		verify := run.Workflow.VerifyBuild
+		if !verify.IsEnabled() || run.DryRun != nil {
+			continue
+		}
+		upload, ok := uploads[run.uploadKey()]
+		if !ok || upload.Err != nil || upload.CommitSHA == "" {
+			continue
+		}
+		platform, repo := types.SplitDestinationRepo(run.Workflow.Destination.Repo)
+		if platform != types.SourcePlatformGitHub {
+			continue
+		}
+		verifier.Watch(ctx, &BuildCheck{
+			WorkflowName:  run.Workflow.Name,
+			SourceRepo:    change.Repo,
+			PRNumber:      change.Number,
+			PRURL:         change.URL,
+			SourceSHA:     change.CommitSHA,
+			TargetRepo:    repo,
+			TargetBranch:  run.Workflow.Destination.Branch,
+			CommitSHA:     upload.CommitSHA,
+			Checks:        verify.Checks,
+			Deadline:      verifier.now().Add(verify.GetTimeout()),
+			Notifications: run.Workflow.Notifications,
+			CorrelationID: CorrelationIDFromContext(ctx),
+		})
+	}
+}
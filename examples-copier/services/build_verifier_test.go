@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkRun(id int64, name, status, conclusion string) *github.CheckRun {
+	run := &github.CheckRun{ID: github.Int64(id), Name: github.String(name), Status: github.String(status)}
+	if conclusion != "" {
+		run.Conclusion = github.String(conclusion)
+	}
+	return run
+}
+
+// newTestBuildVerifier returns a verifier with a fake clock that reports the check runs in *runs
+func newTestBuildVerifier(t *testing.T) (*BuildVerifier, *time.Time, *[]*github.CheckRun, *recordingAuditLogger, func() []SlackMessage) {
+	server, messages := slackRecorder(t)
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	runs := []*github.CheckRun{}
+	audit := &recordingAuditLogger{}
+
+	v := NewBuildVerifier(&configs.Config{SlackWebhookURL: server.URL, BuildCheckPollInterval: 60}, audit)
+	v.now = func() time.Time { return clock }
+	v.listCheckRuns = func(ctx context.Context, repo string, sha string) ([]*github.CheckRun, error) {
+		assert.Equal(t, "org/app", repo)
+		assert.Equal(t, "abc1234def", sha)
+		return runs, nil
+	}
+	return v, &clock, &runs, audit, messages
+}
+
+func testBuildCheck(deadline time.Time, checks ...string) *BuildCheck {
+	return &BuildCheck{
+		WorkflowName: "app",
+		SourceRepo:   "org/src",
+		PRNumber:     42,
+		SourceSHA:    "source-sha",
+		TargetRepo:   "org/app",
+		TargetBranch: "main",
+		CommitSHA:    "abc1234def",
+		Checks:       checks,
+		Deadline:     deadline,
+	}
+}
+
+func TestBuildVerifier_ReportsFailedChecks(t *testing.T) {
+	v, clock, runs, audit, messages := newTestBuildVerifier(t)
+	v.Watch(context.Background(), testBuildCheck(clock.Add(30*time.Minute)))
+
+	*runs = []*github.CheckRun{checkRun(1, "build", "completed", "success"), checkRun(2, "test", "in_progress", "")}
+	v.ProcessDue(context.Background())
+	assert.Equal(t, 1, v.Pending(), "waits while a check run is in progress")
+	assert.Empty(t, audit.errors)
+
+	(*runs)[1] = checkRun(2, "test", "completed", "failure")
+	v.ProcessDue(context.Background())
+	assert.Equal(t, 0, v.Pending())
+
+	require.Len(t, audit.errors, 1)
+	event := audit.errors[0]
+	assert.Equal(t, "org/app", event.TargetRepo)
+	assert.Equal(t, "source-sha", event.CommitSHA)
+	assert.Equal(t, 42, event.PRNumber)
+	assert.Equal(t, true, event.AdditionalData["build_verification"])
+	assert.Equal(t, []string{"test: failure"}, event.AdditionalData["failed_checks"])
+	assert.Equal(t, "abc1234def", event.AdditionalData["target_commit_sha"])
+
+	require.Len(t, messages(), 1)
+	attachment := messages()[0].Attachments[0]
+	assert.Equal(t, "danger", attachment.Color)
+	assert.Contains(t, attachment.Title, "Build Failed")
+	assert.Equal(t, "https://github.com/org/app/commit/abc1234def", attachment.TitleLink)
+	assert.Contains(t, attachment.Fields[len(attachment.Fields)-1].Value, "test: failure")
+}
+
+func TestBuildVerifier_PassingChecks(t *testing.T) {
+	v, clock, runs, audit, messages := newTestBuildVerifier(t)
+	v.Watch(context.Background(), testBuildCheck(clock.Add(30*time.Minute)))
+
+	*runs = []*github.CheckRun{checkRun(1, "build", "completed", "success"), checkRun(2, "lint", "completed", "skipped")}
+	v.ProcessDue(context.Background())
+
+	assert.Equal(t, 0, v.Pending())
+	assert.Empty(t, audit.errors)
+	assert.Empty(t, messages())
+}
+
+func TestBuildVerifier_NamedChecks(t *testing.T) {
+	v, clock, runs, audit, _ := newTestBuildVerifier(t)
+	v.Watch(context.Background(), testBuildCheck(clock.Add(30*time.Minute), "build"))
+
+	// Other check runs are ignored, and only the latest run of a check counts
+	*runs = []*github.CheckRun{
+		checkRun(1, "build", "completed", "failure"),
+		checkRun(2, "lint", "completed", "failure"),
+		checkRun(3, "build", "completed", "success"),
+	}
+	v.ProcessDue(context.Background())
+
+	assert.Equal(t, 0, v.Pending())
+	assert.Empty(t, audit.errors)
+}
+
+func TestBuildVerifier_Timeout(t *testing.T) {
+	v, clock, runs, audit, messages := newTestBuildVerifier(t)
+	v.Watch(context.Background(), testBuildCheck(clock.Add(30*time.Minute), "build", "test"))
+
+	*runs = []*github.CheckRun{checkRun(1, "build", "in_progress", "")}
+	v.ProcessDue(context.Background())
+	assert.Equal(t, 1, v.Pending(), "waits for the test check to be reported")
+
+	*clock = clock.Add(31 * time.Minute)
+	v.ProcessDue(context.Background())
+	assert.Equal(t, 0, v.Pending())
+
+	require.Len(t, audit.errors, 1)
+	assert.Equal(t, true, audit.errors[0].AdditionalData["timed_out"])
+	assert.Equal(t, []string{"test: not reported"}, audit.errors[0].AdditionalData["failed_checks"])
+	require.Len(t, messages(), 1)
+}
+
+func TestBuildVerifier_NoCheckRuns(t *testing.T) {
+	v, clock, _, audit, messages := newTestBuildVerifier(t)
+	v.Watch(context.Background(), testBuildCheck(clock.Add(30*time.Minute)))
+
+	v.ProcessDue(context.Background())
+	assert.Equal(t, 1, v.Pending())
+
+	// A destination without CI isn't reported as a failure
+	*clock = clock.Add(31 * time.Minute)
+	v.ProcessDue(context.Background())
+	assert.Equal(t, 0, v.Pending())
+	assert.Empty(t, audit.errors)
+	assert.Empty(t, messages())
+}
+
+func TestBuildVerifier_ListError(t *testing.T) {
+	v, clock, _, _, _ := newTestBuildVerifier(t)
+	v.listCheckRuns = func(ctx context.Context, repo string, sha string) ([]*github.CheckRun, error) {
+		return nil, errors.New("bad gateway")
+	}
+	v.Watch(context.Background(), testBuildCheck(clock.Add(30*time.Minute)))
+
+	v.ProcessDue(context.Background())
+	assert.Equal(t, 1, v.Pending(), "kept until the deadline")
+
+	*clock = clock.Add(31 * time.Minute)
+	v.ProcessDue(context.Background())
+	assert.Equal(t, 0, v.Pending())
+}
+
+func TestWatchDestinationBuilds(t *testing.T) {
+	v, _, _, _, _ := newTestBuildVerifier(t)
+	verify := &types.VerifyBuildConfig{Enabled: true, TimeoutMinutes: 10}
+	dryRun := true
+
+	runs := []*workflowRun{
+		{Workflow: types.Workflow{Name: "app", Destination: types.Destination{Repo: "org/app", Branch: "main"}, VerifyBuild: verify}},
+		// Not checked: verify_build off, dry run, Bitbucket destination, failed upload, PR awaiting review
+		{Workflow: types.Workflow{Name: "off", Destination: types.Destination{Repo: "org/app", Branch: "main"}}},
+		{Workflow: types.Workflow{Name: "dry", Destination: types.Destination{Repo: "org/app", Branch: "main"}, VerifyBuild: verify, DryRun: &dryRun},
+			DryRun: &DryRunReport{}},
+		{Workflow: types.Workflow{Name: "bitbucket", Destination: types.Destination{Repo: "bitbucket:ws/app", Branch: "main"}, VerifyBuild: verify}},
+		{Workflow: types.Workflow{Name: "failed", Destination: types.Destination{Repo: "org/broken", Branch: "main"}, VerifyBuild: verify}},
+		{Workflow: types.Workflow{Name: "review", Destination: types.Destination{Repo: "org/reviewed", Branch: "main"}, VerifyBuild: verify}},
+	}
+	uploads := map[types.UploadKey]UploadResult{
+		{RepoName: "org/app", BranchPath: "main"}:          {CommitSHA: "abc1234def"},
+		{RepoName: "bitbucket:ws/app", BranchPath: "main"}: {CommitSHA: "bbb"},
+		{RepoName: "org/broken", BranchPath: "main"}:       {Err: errors.New("forbidden")},
+		{RepoName: "org/reviewed", BranchPath: "main"}:     {PRURL: "https://github.com/org/reviewed/pull/3"},
+	}
+
+	watchDestinationBuilds(context.Background(), v, CopyEvent{Repo: "org/src", Number: 42, CommitSHA: "source-sha"}, runs, uploads)
+
+	require.Equal(t, 1, v.Pending())
+	check := v.pending[0]
+	assert.Equal(t, "app", check.WorkflowName)
+	assert.Equal(t, "abc1234def", check.CommitSHA)
+	assert.Equal(t, "source-sha", check.SourceSHA)
+	assert.Equal(t, v.now().Add(10*time.Minute), check.Deadline)
+}
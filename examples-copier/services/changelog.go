@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// changelogHeading starts a changelog the copier creates
+const changelogHeading = "# Changelog\n"
+
+// queueChangelogEntry adds an entry for the files a workflow with a changelog queued for its destination to
+// the changelog in the same upload. A changelog that can't be read from the destination is left alone, with a
+// warning, rather than replaced by one holding only the new entry.
+func queueChangelogEntry(ctx context.Context, state FileStateService, run *workflowRun, sourceCommitSHA string) {
+	workflow := run.Workflow
+	if !workflow.Changelog.IsEnabled() || run.DryRun != nil || len(run.Files)+len(run.Deletions) == 0 {
+		return
+	}
+	key := run.uploadKey()
+	content, ok := state.GetFilesToUpload()[key]
+	if !ok {
+		return
+	}
+
+	changelogPath := workflow.Changelog.GetPath()
+	logFields := map[string]interface{}{
+		"workflow_name":    workflow.Name,
+		"destination_repo": workflow.Destination.Repo,
+		"changelog":        changelogPath,
+	}
+	provider, repo, err := repoProviderFor(workflow.Destination.Repo)
+	if err != nil {
+		logFields["error"] = err.Error()
+		LogWarningCtx(ctx, "skipping changelog entry: failed to get destination repo client", logFields)
+		return
+	}
+
+	entry := changelogEntry(time.Now(), changelogSource(ctx, workflow.Source, sourceCommitSHA), run.Files, run.Deletions)
+	branch := strings.TrimPrefix(key.BranchPath, "refs/heads/")
+	if err := appendChangelogEntry(ctx, provider, repo, branch, &content, changelogPath, entry); err != nil {
+		logFields["error"] = err.Error()
+		LogWarningCtx(ctx, "skipping changelog entry: failed to read the destination's changelog", logFields)
+		return
+	}
+	state.AddFileToUpload(key, content)
+}
+
+// appendChangelogEntry appends entry to the changelog at changelogPath in content. If content doesn't already
+// hold the changelog, such as from another workflow copying to the same branch, it's read from the destination
+// branch, or started if the destination doesn't have one.
+func appendChangelogEntry(ctx context.Context, provider RepoProvider, repo string, branch string,
+	content *types.UploadFileContent, changelogPath string, entry string) error {
+
+	index := -1
+	var current string
+	for i, file := range content.Content {
+		if file.GetName() == changelogPath {
+			index = i
+			current, _ = file.GetContent()
+			break
+		}
+	}
+	if index < 0 {
+		existing, found, err := provider.GetFile(ctx, repo, changelogPath, branch)
+		if err != nil {
+			return err
+		}
+		current = changelogHeading
+		if found {
+			current = existing
+		}
+	}
+
+	if current != "" && !strings.HasSuffix(current, "\n") {
+		current += "\n"
+	}
+	file := github.RepositoryContent{Name: github.String(changelogPath), Content: github.String(current + "\n" + entry)}
+	if index < 0 {
+		content.Content = append(content.Content, file)
+	} else {
+		content.Content[index] = file
+	}
+	return nil
+}
+
+// changelogEntry formats a changelog entry for a sync on date from source, listing the files it copied and
+// deleted
+func changelogEntry(date time.Time, source string, files []string, deletions []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n%s\n\n", date.UTC().Format("2006-01-02"), source)
+	for _, p := range files {
+		fmt.Fprintf(&b, "- Copied `%s`\n", p)
+	}
+	for _, p := range deletions {
+		fmt.Fprintf(&b, "- Deleted `%s`\n", p)
+	}
+	return b.String()
+}
+
+// changelogSource describes where a sync's files came from: the source PR or push, linked, when the sync
+// is for one, or the source branch and commit otherwise
+func changelogSource(ctx context.Context, source types.Source, sourceCommitSHA string) string {
+	sha := ShortSHA(sourceCommitSHA)
+	change, ok := sourceChangeFromContext(ctx)
+	if !ok || change.URL == "" {
+		return fmt.Sprintf("Copied from %s@%s at %s", source.Repo, source.Branch, sha)
+	}
+	line := fmt.Sprintf("Copied from [%s %s](%s) at %s", change.Repo, change.describe(), change.URL, sha)
+	if change.Title != "" {
+		line += ": " + change.Title
+	}
+	return line
+}
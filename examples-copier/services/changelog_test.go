@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangelogEntry(t *testing.T) {
+	date := time.Date(2025, 3, 14, 23, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+	entry := changelogEntry(date, "Copied from org/src@main at abc1234", []string{"a.py", "b.py"}, []string{"old.py"})
+	assert.Equal(t, "## 2025-03-15\n\n"+
+		"Copied from org/src@main at abc1234\n\n"+
+		"- Copied `a.py`\n"+
+		"- Copied `b.py`\n"+
+		"- Deleted `old.py`\n", entry)
+}
+
+func TestChangelogSource(t *testing.T) {
+	source := types.Source{Repo: "org/src", Branch: "main"}
+	assert.Equal(t, "Copied from org/src@main at abc1234", changelogSource(context.Background(), source, "abc1234def"))
+
+	ctx := withSourceChange(context.Background(), CopyEvent{
+		Platform: types.SourcePlatformGitHub,
+		Repo:     "org/src",
+		Number:   42,
+		URL:      "https://github.com/org/src/pull/42",
+		Title:    "Add Python examples",
+	})
+	assert.Equal(t, "Copied from [org/src PR #42](https://github.com/org/src/pull/42) at abc1234: Add Python examples",
+		changelogSource(ctx, source, "abc1234def"))
+}
+
+func TestAppendChangelogEntry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("starts a changelog", func(t *testing.T) {
+		provider := &reconcileTestProvider{files: map[string]string{}}
+		content := types.UploadFileContent{Content: []github.RepositoryContent{{Name: github.String("a.py")}}}
+		require.NoError(t, appendChangelogEntry(ctx, provider, "org/docs", "main", &content, "CHANGELOG.md", "## entry\n"))
+		require.Len(t, content.Content, 2)
+		assert.Equal(t, "# Changelog\n\n## entry\n", uploadEntries(content.Content)["CHANGELOG.md"])
+	})
+
+	t.Run("appends to the destination's changelog", func(t *testing.T) {
+		provider := &reconcileTestProvider{files: map[string]string{"CHANGELOG.md": "# History\n\n## earlier"}}
+		content := types.UploadFileContent{}
+		require.NoError(t, appendChangelogEntry(ctx, provider, "org/docs", "main", &content, "CHANGELOG.md", "## entry\n"))
+		assert.Equal(t, "# History\n\n## earlier\n\n## entry\n", uploadEntries(content.Content)["CHANGELOG.md"])
+	})
+
+	t.Run("appends to a changelog already queued", func(t *testing.T) {
+		provider := &reconcileTestProvider{files: map[string]string{"CHANGELOG.md": "# History\n"}}
+		content := types.UploadFileContent{}
+		require.NoError(t, appendChangelogEntry(ctx, provider, "org/docs", "main", &content, "CHANGELOG.md", "## first\n"))
+		require.NoError(t, appendChangelogEntry(ctx, provider, "org/docs", "main", &content, "CHANGELOG.md", "## second\n"))
+		require.Len(t, content.Content, 1)
+		assert.Equal(t, "# History\n\n## first\n\n## second\n", uploadEntries(content.Content)["CHANGELOG.md"])
+		assert.Equal(t, []string{"CHANGELOG.md"}, provider.fetched, "the destination's changelog is read once")
+	})
+}
+
+func TestQueueChangelogEntry_Skipped(t *testing.T) {
+	state := NewFileStateService()
+	key := types.UploadKey{RepoName: "org/docs", BranchPath: "main"}
+	state.AddFileToUpload(key, types.UploadFileContent{Content: []github.RepositoryContent{{Name: github.String("a.py")}}})
+	workflow := types.Workflow{Name: "python", Destination: types.Destination{Repo: "org/docs", Branch: "main"}}
+
+	// Workflows without a changelog, and runs that queued nothing, don't add entries
+	queueChangelogEntry(context.Background(), state, &workflowRun{Workflow: workflow, Files: []string{"a.py"}}, "abc123")
+	workflow.Changelog = &types.ChangelogConfig{Enabled: true}
+	queueChangelogEntry(context.Background(), state, &workflowRun{Workflow: workflow}, "abc123")
+	assert.Len(t, state.GetFilesToUpload()[key].Content, 1)
+}
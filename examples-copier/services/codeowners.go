@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	. "github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// codeOwnersPaths are where GitHub looks for a CODEOWNERS file, in the order it checks them. The first
+// one found is used.
+var codeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeOwnersRule is one line of a CODEOWNERS file: the paths it matches and their owners. A rule
+// without owners leaves the paths it matches without an owner.
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// parseCodeOwners parses a CODEOWNERS file. Lines whose pattern can't be parsed are skipped, as GitHub
+// does.
+func parseCodeOwners(content string) []codeOwnersRule {
+	var rules []codeOwnersRule
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 && (i == 0 || line[i-1] != '\\') {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pattern, err := codeOwnersPattern(fields[0])
+		if err != nil {
+			continue
+		}
+		rules = append(rules, codeOwnersRule{pattern: pattern, owners: fields[1:]})
+	}
+	return rules
+}
+
+// codeOwnersPattern converts a CODEOWNERS pattern, which follows most .gitignore rules, to a regular
+// expression that matches the paths it applies to:
+//   - a pattern with a leading or inner "/" is relative to the repo root; others match at any depth
+//   - "*" and "?" don't match "/"; "**" matches any number of directories
+//   - a pattern that names a directory, such as "docs/" or "/build/logs", matches everything under it,
+//     but "docs/*" only matches files directly in docs
+func codeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.ReplaceAll(pattern, `\#`, "#")
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	// A last segment without wildcards can be a directory, which owns everything under it
+	lastSegment := pattern[strings.LastIndex(pattern, "/")+1:]
+	if !strings.ContainsAny(lastSegment, "*?") {
+		expr.WriteString("(?:/.*)?")
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// codeOwnersFor returns the owners of paths, in the order they're listed. The last rule that matches a
+// path decides its owners, as on GitHub.
+func codeOwnersFor(rules []codeOwnersRule, paths []string) []string {
+	var owners []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		path = strings.TrimPrefix(path, "/")
+		for i := len(rules) - 1; i >= 0; i-- {
+			if !rules[i].pattern.MatchString(path) {
+				continue
+			}
+			for _, owner := range rules[i].owners {
+				if key := strings.ToLower(owner); !seen[key] {
+					seen[key] = true
+					owners = append(owners, owner)
+				}
+			}
+			break
+		}
+	}
+	return owners
+}
+
+// splitCodeOwners sorts CODEOWNERS owners into users and the slugs of teams in org, the destination
+// repo's owner. Teams in other orgs and owners listed by email address can't be requested, so they're
+// skipped.
+func splitCodeOwners(owners []string, org string) (users []string, teams []string) {
+	for _, owner := range owners {
+		name, ok := strings.CutPrefix(owner, "@")
+		if !ok {
+			continue // an email address
+		}
+		if teamOrg, slug, isTeam := strings.Cut(name, "/"); isTeam {
+			if strings.EqualFold(teamOrg, org) {
+				teams = append(teams, slug)
+			}
+			continue
+		}
+		users = append(users, name)
+	}
+	return users, teams
+}
+
+// fetchCodeOwners returns the rules in the destination repo's CODEOWNERS file on branch, and where it
+// was found. Both are empty if the repo has none.
+func fetchCodeOwners(ctx context.Context, provider RepoProvider, repo string, branch string) ([]codeOwnersRule, string, error) {
+	for _, path := range codeOwnersPaths {
+		content, found, err := provider.GetFile(ctx, repo, path, branch)
+		if err != nil {
+			return nil, "", fmt.Errorf("get %s: %w", path, err)
+		}
+		if found {
+			return parseCodeOwners(content), path, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// touchedPaths returns the target paths an upload writes or deletes
+func touchedPaths(value UploadFileContent) []string {
+	paths := make([]string, 0, len(value.Content)+len(value.DeletePaths))
+	for _, file := range value.Content {
+		paths = append(paths, file.GetPath())
+	}
+	return append(paths, value.DeletePaths...)
+}
+
+// requestReviewers requests reviews on a PR the copier opened from the workflow's reviewers and, with
+// codeowners set, the owners of the paths the upload touches. The PR is open either way, so failures
+// are only logged.
+func requestReviewers(ctx context.Context, provider RepoProvider, repo string, baseBranch string, number int, value UploadFileContent) {
+	cfg := value.Reviewers
+	users := trimmedAll(cfg.Users, "@")
+	teams := trimmedAll(cfg.Teams, "")
+
+	if cfg.CodeOwners {
+		rules, path, err := fetchCodeOwners(ctx, provider, repo, baseBranch)
+		if err != nil {
+			LogWarningCtx(ctx, fmt.Sprintf("Failed to read CODEOWNERS in %s: %v", repo, err), nil)
+		} else if path == "" {
+			LogDebugCtx(ctx, "No CODEOWNERS file in target repo", map[string]interface{}{"target_repo": repo, "branch": baseBranch})
+		} else {
+			org, _ := parseRepoPath(repo)
+			ownerUsers, ownerTeams := splitCodeOwners(codeOwnersFor(rules, touchedPaths(value)), org)
+			users = append(users, ownerUsers...)
+			teams = append(teams, ownerTeams...)
+		}
+	}
+
+	users, teams = uniqueFold(users), uniqueFold(teams)
+	if len(users) == 0 && len(teams) == 0 {
+		return
+	}
+	provider.RequestReviewers(ctx, repo, number, users, teams)
+}
+
+// trimmedAll returns values with surrounding whitespace and prefix removed
+func trimmedAll(values []string, prefix string) []string {
+	trimmed := make([]string, 0, len(values))
+	for _, v := range values {
+		trimmed = append(trimmed, strings.TrimPrefix(strings.TrimSpace(v), prefix))
+	}
+	return trimmed
+}
+
+// uniqueFold removes later duplicates from values, ignoring case, since GitHub names are case-insensitive
+func uniqueFold(values []string) []string {
+	var unique []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if key := strings.ToLower(v); v != "" && !seen[key] {
+			seen[key] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reviewerRecorder serves files from memory and records the reviewers requested. Other RepoProvider
+// methods aren't used.
+type reviewerRecorder struct {
+	RepoProvider
+	files map[string]string
+	users []string
+	teams []string
+	calls int
+}
+
+func (r *reviewerRecorder) GetFile(ctx context.Context, repo string, filePath string, ref string) (string, bool, error) {
+	content, found := r.files[filePath]
+	return content, found, nil
+}
+
+func (r *reviewerRecorder) RequestReviewers(ctx context.Context, repo string, number int, users []string, teams []string) {
+	r.calls++
+	r.users, r.teams = users, teams
+}
+
+func TestCodeOwnersPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		matches []string
+		misses  []string
+	}{
+		{pattern: "*", matches: []string{"README.md", "a/b/c.go"}},
+		{pattern: "*.js", matches: []string{"app.js", "src/app.js"}, misses: []string{"app.jsx"}},
+		{pattern: "/build/logs/", matches: []string{"build/logs/a.log", "build/logs/x/y.log"}, misses: []string{"src/build/logs/a.log"}},
+		{pattern: "docs/*", matches: []string{"docs/intro.md"}, misses: []string{"docs/build/intro.md"}},
+		{pattern: "apps/", matches: []string{"apps/a.go", "src/apps/b/c.go"}, misses: []string{"apps.go"}},
+		{pattern: "/scripts", matches: []string{"scripts/run.sh"}, misses: []string{"tools/scripts/run.sh"}},
+		{pattern: "**/logs", matches: []string{"logs/a.log", "deep/down/logs/a.log"}},
+		{pattern: "/docs/**/*.md", matches: []string{"docs/a.md", "docs/x/y/a.md"}, misses: []string{"docs/a.txt"}},
+		{pattern: "file?.txt", matches: []string{"file1.txt"}, misses: []string{"file10.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			re, err := codeOwnersPattern(tt.pattern)
+			require.NoError(t, err)
+			for _, path := range tt.matches {
+				assert.True(t, re.MatchString(path), "%s should match %s", tt.pattern, path)
+			}
+			for _, path := range tt.misses {
+				assert.False(t, re.MatchString(path), "%s shouldn't match %s", tt.pattern, path)
+			}
+		})
+	}
+}
+
+func TestCodeOwnersFor(t *testing.T) {
+	rules := parseCodeOwners(`
+# Default owners
+*            @org/docs-team
+*.py         @python-dev   # inline comment
+/generated/
+/examples/   @Alice @org/examples ops@example.com
+`)
+	require.Len(t, rules, 4)
+
+	assert.Equal(t, []string{"@org/docs-team"}, codeOwnersFor(rules, []string{"README.md"}))
+	assert.Equal(t, []string{"@python-dev"}, codeOwnersFor(rules, []string{"tools/run.py"}), "the last matching rule wins")
+	assert.Empty(t, codeOwnersFor(rules, []string{"generated/out.json"}), "a rule without owners leaves paths unowned")
+	assert.Equal(t, []string{"@Alice", "@org/examples", "ops@example.com", "@org/docs-team"},
+		codeOwnersFor(rules, []string{"examples/main.go", "examples/util.go", "/index.md"}))
+}
+
+func TestSplitCodeOwners(t *testing.T) {
+	users, teams := splitCodeOwners([]string{"@alice", "@Org/docs", "@other/team", "ops@example.com"}, "org")
+	assert.Equal(t, []string{"alice"}, users)
+	assert.Equal(t, []string{"docs"}, teams, "teams in other orgs can't be requested")
+}
+
+func TestRequestReviewers(t *testing.T) {
+	upload := types.UploadFileContent{
+		Content: []github.RepositoryContent{
+			{Path: github.String("examples/main.go")},
+			{Path: github.String("README.md")},
+		},
+		DeletePaths: []string{"examples/old.go"},
+		Reviewers: &types.ReviewersConfig{
+			CodeOwners: true,
+			Users:      []string{"@docs-lead", "alice"},
+			Teams:      []string{"docs"},
+		},
+	}
+
+	provider := &reviewerRecorder{files: map[string]string{
+		"CODEOWNERS":         "* @org/docs\n/examples/ @Alice @bob",
+		".github/CODEOWNERS": "/examples/ @carol @org/examples",
+	}}
+	requestReviewers(context.Background(), provider, "org/app", "main", 7, upload)
+	assert.Equal(t, 1, provider.calls)
+	assert.Equal(t, []string{"docs-lead", "alice", "carol"}, provider.users, ".github/CODEOWNERS is used first")
+	assert.Equal(t, []string{"docs", "examples"}, provider.teams)
+
+	// Without a CODEOWNERS file, only the listed reviewers are requested
+	provider = &reviewerRecorder{}
+	upload.Reviewers = &types.ReviewersConfig{CodeOwners: true}
+	requestReviewers(context.Background(), provider, "org/app", "main", 7, upload)
+	assert.Equal(t, 0, provider.calls)
+}
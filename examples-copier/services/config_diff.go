@@ -0,0 +1,317 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// ConfigDiff describes how the copier's behavior changes between two versions of a workflow config
+type ConfigDiff struct {
+	Added   []WorkflowSummary `json:"added"`
+	Removed []WorkflowSummary `json:"removed"`
+	Renamed []WorkflowRename  `json:"renamed"`
+	Changed []WorkflowChange  `json:"changed"`
+}
+
+// WorkflowSummary identifies a workflow by its name, source, and destination
+type WorkflowSummary struct {
+	Name        string `json:"name"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+// WorkflowRename is a workflow whose name changed. A renamed workflow whose settings also changed is
+// listed in Changed under its new name.
+type WorkflowRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// WorkflowChange lists the changed settings of a workflow that's in both versions
+type WorkflowChange struct {
+	Workflow string        `json:"workflow"`
+	Changes  []FieldChange `json:"changes"`
+}
+
+// FieldChange is one changed setting. Old is empty for an added list item, and New for a removed one.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// IsEmpty returns true if the two configs behave the same
+func (d *ConfigDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Renamed) == 0 && len(d.Changed) == 0
+}
+
+// DiffConfigs compares two loaded workflow configs. Workflows are matched by name. A workflow that's
+// only in the old config is treated as renamed to one that's only in the new config if the two are
+// otherwise identical, or if they're the only such workflows with the same source and destination.
+func DiffConfigs(oldConfig, newConfig *types.YAMLConfig) *ConfigDiff {
+	diff := &ConfigDiff{
+		Added:   []WorkflowSummary{},
+		Removed: []WorkflowSummary{},
+		Renamed: []WorkflowRename{},
+		Changed: []WorkflowChange{},
+	}
+
+	newByName := make(map[string]*types.Workflow, len(newConfig.Workflows))
+	for i := range newConfig.Workflows {
+		newByName[newConfig.Workflows[i].Name] = &newConfig.Workflows[i]
+	}
+	oldByName := make(map[string]bool, len(oldConfig.Workflows))
+
+	var removed, added []*types.Workflow
+	for i := range oldConfig.Workflows {
+		old := &oldConfig.Workflows[i]
+		oldByName[old.Name] = true
+		if updated, ok := newByName[old.Name]; ok {
+			diff.addChanges(old.Name, old, updated)
+		} else {
+			removed = append(removed, old)
+		}
+	}
+	for i := range newConfig.Workflows {
+		if !oldByName[newConfig.Workflows[i].Name] {
+			added = append(added, &newConfig.Workflows[i])
+		}
+	}
+
+	// Renames: identical workflows first, then the only pair with the same source and destination
+	removed, added = diff.matchRenames(removed, added, func(old, updated *types.Workflow) bool {
+		return sameExceptName(old, updated)
+	}, false)
+	removed, added = diff.matchRenames(removed, added, func(old, updated *types.Workflow) bool {
+		return summarizeWorkflow(old).Source == summarizeWorkflow(updated).Source &&
+			summarizeWorkflow(old).Destination == summarizeWorkflow(updated).Destination
+	}, true)
+
+	for _, w := range removed {
+		diff.Removed = append(diff.Removed, summarizeWorkflow(w))
+	}
+	for _, w := range added {
+		diff.Added = append(diff.Added, summarizeWorkflow(w))
+	}
+	return diff
+}
+
+// matchRenames pairs removed and added workflows that match. If unique is true, a workflow is only
+// paired when it matches exactly one workflow on the other side. Returns the unpaired workflows.
+func (d *ConfigDiff) matchRenames(removed, added []*types.Workflow, matches func(old, updated *types.Workflow) bool, unique bool) ([]*types.Workflow, []*types.Workflow) {
+	paired := make(map[*types.Workflow]bool)
+	var unpairedRemoved []*types.Workflow
+	for _, old := range removed {
+		var candidates []*types.Workflow
+		for _, updated := range added {
+			if !paired[updated] && matches(old, updated) {
+				candidates = append(candidates, updated)
+			}
+		}
+		if len(candidates) == 0 || (unique && len(candidates) > 1) {
+			unpairedRemoved = append(unpairedRemoved, old)
+			continue
+		}
+		if unique {
+			// The added workflow must not match any other removed workflow either
+			others := 0
+			for _, other := range removed {
+				if other != old && matches(other, candidates[0]) {
+					others++
+				}
+			}
+			if others > 0 {
+				unpairedRemoved = append(unpairedRemoved, old)
+				continue
+			}
+		}
+		updated := candidates[0]
+		paired[updated] = true
+		d.Renamed = append(d.Renamed, WorkflowRename{From: old.Name, To: updated.Name})
+		d.addChanges(updated.Name, old, updated)
+	}
+
+	var unpairedAdded []*types.Workflow
+	for _, updated := range added {
+		if !paired[updated] {
+			unpairedAdded = append(unpairedAdded, updated)
+		}
+	}
+	return unpairedRemoved, unpairedAdded
+}
+
+// addChanges records the settings that differ between two versions of a workflow, if any
+func (d *ConfigDiff) addChanges(name string, old, updated *types.Workflow) {
+	changes := diffWorkflows(old, updated)
+	if len(changes) > 0 {
+		d.Changed = append(d.Changed, WorkflowChange{Workflow: name, Changes: changes})
+	}
+}
+
+// diffWorkflows returns the settings that differ between two versions of a workflow
+func diffWorkflows(old, updated *types.Workflow) []FieldChange {
+	var changes []FieldChange
+	field := func(name, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{Field: name, Old: oldValue, New: newValue})
+		}
+	}
+
+	oldSummary, newSummary := summarizeWorkflow(old), summarizeWorkflow(updated)
+	field("source", oldSummary.Source, newSummary.Source)
+	field("destination", oldSummary.Destination, newSummary.Destination)
+	field("trigger", describeTriggers(old.Trigger), describeTriggers(updated.Trigger))
+	field("priority", fmt.Sprint(old.Priority), fmt.Sprint(updated.Priority))
+
+	changes = append(changes, diffList("transformations", describeTransformations(old.Transformations), describeTransformations(updated.Transformations), true)...)
+	changes = append(changes, diffList("exclude", old.Exclude, updated.Exclude, false)...)
+
+	oldStrategy, newStrategy := commitStrategyOrEmpty(old.CommitStrategy), commitStrategyOrEmpty(updated.CommitStrategy)
+	field("commit_strategy.type", oldStrategy.Type, newStrategy.Type)
+	field("commit_strategy.commit_message", oldStrategy.CommitMessage, newStrategy.CommitMessage)
+	field("commit_strategy.pr_title", oldStrategy.PRTitle, newStrategy.PRTitle)
+	field("commit_strategy.pr_body", oldStrategy.PRBody, newStrategy.PRBody)
+	field("commit_strategy.use_pr_template", fmt.Sprint(oldStrategy.UsePRTemplate), fmt.Sprint(newStrategy.UsePRTemplate))
+	field("commit_strategy.auto_merge", fmt.Sprint(oldStrategy.AutoMerge), fmt.Sprint(newStrategy.AutoMerge))
+	field("commit_strategy.sign_off", jsonOrEmpty(oldStrategy.SignOff), jsonOrEmpty(newStrategy.SignOff))
+
+	// Other settings are compared as JSON
+	for _, other := range []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"dry_run", old.DryRun, updated.DryRun},
+		{"deprecation_check", old.DeprecationCheck, updated.DeprecationCheck},
+		{"secret_scan", old.SecretScan, updated.SecretScan},
+		{"schema_validation", old.SchemaValidation, updated.SchemaValidation},
+		{"notifications", old.Notifications, updated.Notifications},
+		{"content_transforms", old.ContentTransforms, updated.ContentTransforms},
+		{"delete_orphans", old.DeleteOrphans, updated.DeleteOrphans},
+		{"lfs", old.LFS, updated.LFS},
+		{"verify_build", old.VerifyBuild, updated.VerifyBuild},
+		{"variables", old.Variables, updated.Variables},
+	} {
+		field(other.name, jsonOrEmpty(other.old), jsonOrEmpty(other.new))
+	}
+	return changes
+}
+
+// diffList returns the items removed from and added to a list setting. If ordered is true, a list
+// whose items only changed order is reported as reordered, such as transformations, which apply in order.
+func diffList(name string, old, updated []string, ordered bool) []FieldChange {
+	var changes []FieldChange
+	remaining := make(map[string]int)
+	for _, item := range updated {
+		remaining[item]++
+	}
+	for _, item := range old {
+		if remaining[item] > 0 {
+			remaining[item]--
+		} else {
+			changes = append(changes, FieldChange{Field: name, Old: item})
+		}
+	}
+	kept := make(map[string]int)
+	for _, item := range old {
+		kept[item]++
+	}
+	for _, item := range updated {
+		if kept[item] > 0 {
+			kept[item]--
+		} else {
+			changes = append(changes, FieldChange{Field: name, New: item})
+		}
+	}
+	if ordered && len(changes) == 0 && !reflect.DeepEqual(old, updated) {
+		changes = append(changes, FieldChange{Field: name + " order", Old: strings.Join(old, "; "), New: strings.Join(updated, "; ")})
+	}
+	return changes
+}
+
+// summarizeWorkflow returns the workflow's name, source, and destination
+func summarizeWorkflow(w *types.Workflow) WorkflowSummary {
+	return WorkflowSummary{
+		Name:        w.Name,
+		Source:      w.Source.Repo + "@" + w.Source.Branch,
+		Destination: w.Destination.Repo + "@" + strings.Join(w.Destination.BranchPatterns(), ","),
+	}
+}
+
+// sameExceptName returns true if two workflows only differ in name
+func sameExceptName(a, b *types.Workflow) bool {
+	aCopy, bCopy := *a, *b
+	aCopy.Name, bCopy.Name = "", ""
+	return jsonOrEmpty(aCopy) == jsonOrEmpty(bCopy)
+}
+
+// describeTransformations returns a one-line description of each transformation
+func describeTransformations(transformations []types.Transformation) []string {
+	descriptions := make([]string, len(transformations))
+	for i, t := range transformations {
+		var description string
+		switch t.GetType() {
+		case types.TransformationTypeMove:
+			description = fmt.Sprintf("move %s -> %s", t.Move.From, t.Move.To)
+			if t.Move.StripPrefix != "" {
+				description += fmt.Sprintf(" (strip_prefix %s)", t.Move.StripPrefix)
+			}
+		case types.TransformationTypeCopy:
+			description = fmt.Sprintf("copy %s -> %s", t.Copy.From, t.Copy.To)
+		case types.TransformationTypeGlob:
+			description = fmt.Sprintf("glob %s -> %s", t.Glob.Pattern, t.Glob.Transform)
+		case types.TransformationTypeRegex:
+			description = fmt.Sprintf("regex %s -> %s", t.Regex.Pattern, t.Regex.Transform)
+		default:
+			description = jsonOrEmpty(t)
+		}
+		if t.Sync {
+			description += " (sync)"
+		}
+		descriptions[i] = description
+	}
+	return descriptions
+}
+
+// describeTriggers returns the workflow's triggers, sorted, or the default trigger
+func describeTriggers(triggers types.WorkflowTriggers) string {
+	if len(triggers) == 0 {
+		return types.WorkflowTriggerPRMerged
+	}
+	sorted := append([]string(nil), triggers...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+func commitStrategyOrEmpty(strategy *types.CommitStrategyConfig) types.CommitStrategyConfig {
+	if strategy == nil {
+		return types.CommitStrategyConfig{}
+	}
+	return *strategy
+}
+
+// jsonOrEmpty returns a setting as compact JSON, or an empty string if it's unset
+func jsonOrEmpty(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return ""
+		}
+	case reflect.Slice, reflect.Map:
+		if v.Len() == 0 {
+			return ""
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,199 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mongodb/code-example-tooling/code-copier/services"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+func loadDiffConfig(t *testing.T, content string) *types.YAMLConfig {
+	t.Helper()
+	config, err := services.NewConfigLoader().LoadConfigFromContent(content, "config.yaml")
+	require.NoError(t, err)
+	return config
+}
+
+const diffBaseConfig = `
+workflows:
+  - name: "go-examples"
+    source:
+      repo: "mongodb/source"
+      branch: "main"
+    destination:
+      repo: "mongodb/go-docs"
+      branch: "main"
+    transformations:
+      - move: { from: "examples/go", to: "code" }
+    commit_strategy:
+      type: "pull_request"
+      pr_title: "Update Go examples"
+  - name: "python-examples"
+    source:
+      repo: "mongodb/source"
+    destination:
+      repo: "mongodb/python-docs"
+    transformations:
+      - move: { from: "examples/python", to: "code" }
+  - name: "java-examples"
+    source:
+      repo: "mongodb/source"
+    destination:
+      repo: "mongodb/java-docs"
+    transformations:
+      - move: { from: "examples/java", to: "code" }
+`
+
+func TestDiffConfigs_NoChanges(t *testing.T) {
+	diff := services.DiffConfigs(loadDiffConfig(t, diffBaseConfig), loadDiffConfig(t, diffBaseConfig))
+
+	assert.True(t, diff.IsEmpty())
+}
+
+func TestDiffConfigs_AddedRemovedRenamedAndChanged(t *testing.T) {
+	updated := `
+workflows:
+  - name: "go-examples"
+    source:
+      repo: "mongodb/source"
+      branch: "main"
+    destination:
+      repo: "mongodb/go-docs"
+      branch: "main"
+    transformations:
+      - move: { from: "examples/go", to: "code" }
+      - copy: { from: "README.md", to: "code/README.md" }
+    commit_strategy:
+      type: "direct"
+  - name: "python-snippets"
+    source:
+      repo: "mongodb/source"
+    destination:
+      repo: "mongodb/python-docs"
+    transformations:
+      - move: { from: "examples/python", to: "code" }
+  - name: "node-examples"
+    source:
+      repo: "mongodb/source"
+    destination:
+      repo: "mongodb/node-docs"
+    transformations:
+      - move: { from: "examples/node", to: "code" }
+`
+	diff := services.DiffConfigs(loadDiffConfig(t, diffBaseConfig), loadDiffConfig(t, updated))
+
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "node-examples", diff.Added[0].Name)
+	assert.Equal(t, "mongodb/node-docs@main", diff.Added[0].Destination)
+
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, "java-examples", diff.Removed[0].Name)
+
+	assert.Equal(t, []services.WorkflowRename{{From: "python-examples", To: "python-snippets"}}, diff.Renamed)
+
+	require.Len(t, diff.Changed, 1)
+	assert.Equal(t, "go-examples", diff.Changed[0].Workflow)
+	assert.ElementsMatch(t, []services.FieldChange{
+		{Field: "transformations", New: "copy README.md -> code/README.md"},
+		{Field: "commit_strategy.type", Old: "pull_request", New: "direct"},
+		{Field: "commit_strategy.pr_title", Old: "Update Go examples"},
+	}, diff.Changed[0].Changes)
+}
+
+func TestDiffConfigs_RenamedWorkflowWithChanges(t *testing.T) {
+	old := `
+workflows:
+  - name: "examples"
+    source:
+      repo: "mongodb/source"
+    destination:
+      repo: "mongodb/docs"
+    transformations:
+      - glob: { pattern: "examples/**/*.go", transform: "code/${relative_path}" }
+    exclude:
+      - "**/*_test.go"
+`
+	updated := `
+workflows:
+  - name: "go-examples"
+    source:
+      repo: "mongodb/source"
+    destination:
+      repo: "mongodb/docs"
+    transformations:
+      - glob: { pattern: "examples/**/*.go", transform: "go/${relative_path}" }
+    exclude:
+      - "**/*_test.go"
+      - "**/vendor/**"
+`
+	diff := services.DiffConfigs(loadDiffConfig(t, old), loadDiffConfig(t, updated))
+
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Removed)
+	assert.Equal(t, []services.WorkflowRename{{From: "examples", To: "go-examples"}}, diff.Renamed)
+	require.Len(t, diff.Changed, 1)
+	assert.Equal(t, "go-examples", diff.Changed[0].Workflow)
+	assert.ElementsMatch(t, []services.FieldChange{
+		{Field: "transformations", Old: "glob examples/**/*.go -> code/${relative_path}"},
+		{Field: "transformations", New: "glob examples/**/*.go -> go/${relative_path}"},
+		{Field: "exclude", New: "**/vendor/**"},
+	}, diff.Changed[0].Changes)
+}
+
+func TestDiffConfigs_ReorderedTransformations(t *testing.T) {
+	old := `
+workflows:
+  - name: "examples"
+    source: { repo: "mongodb/source" }
+    destination: { repo: "mongodb/docs" }
+    transformations:
+      - move: { from: "examples/go", to: "go" }
+      - move: { from: "examples", to: "all" }
+`
+	updated := `
+workflows:
+  - name: "examples"
+    source: { repo: "mongodb/source" }
+    destination: { repo: "mongodb/docs" }
+    transformations:
+      - move: { from: "examples", to: "all" }
+      - move: { from: "examples/go", to: "go" }
+`
+	diff := services.DiffConfigs(loadDiffConfig(t, old), loadDiffConfig(t, updated))
+
+	require.Len(t, diff.Changed, 1)
+	require.Len(t, diff.Changed[0].Changes, 1)
+	assert.Equal(t, "transformations order", diff.Changed[0].Changes[0].Field)
+}
+
+func TestDiffConfigs_AmbiguousRenameIsAddedAndRemoved(t *testing.T) {
+	old := `
+workflows:
+  - name: "a"
+    source: { repo: "mongodb/source" }
+    destination: { repo: "mongodb/docs" }
+    transformations:
+      - move: { from: "a", to: "a" }
+  - name: "b"
+    source: { repo: "mongodb/source" }
+    destination: { repo: "mongodb/docs" }
+    transformations:
+      - move: { from: "b", to: "b" }
+`
+	updated := `
+workflows:
+  - name: "c"
+    source: { repo: "mongodb/source" }
+    destination: { repo: "mongodb/docs" }
+    transformations:
+      - move: { from: "c", to: "c" }
+`
+	diff := services.DiffConfigs(loadDiffConfig(t, old), loadDiffConfig(t, updated))
+
+	assert.Empty(t, diff.Renamed)
+	assert.Len(t, diff.Removed, 2)
+	assert.Len(t, diff.Added, 1)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/google/go-github/v48/github"
 	"gopkg.in/yaml.v3"
@@ -45,7 +46,12 @@ func (cl *DefaultConfigLoader) LoadConfig(ctx context.Context, config *configs.C
 		}
 	}
 
-	return cl.LoadConfigFromContent(content, config.ConfigFile)
+	yamlConfig, err := cl.LoadConfigFromContent(content, config.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	loadSourceIgnores(ctx, yamlConfig, config)
+	return yamlConfig, nil
 }
 
 // LoadConfigFromContent loads configuration from a string
@@ -146,6 +152,39 @@ func (cv *ConfigValidator) TestTransform(sourcePath string, template string, var
 	return transformer.Transform(sourcePath, template, variables)
 }
 
+// TestMove tests a move transformation against a source path. Unlike the copier, which skips
+// files a transformation doesn't match, it returns an error explaining why the path didn't match.
+func (cv *ConfigValidator) TestMove(sourcePath string, move types.MoveTransform) (string, error) {
+	if err := move.Validate(); err != nil {
+		return "", fmt.Errorf("invalid move transformation: %w", err)
+	}
+
+	matched, targetPath, err := ApplyMoveTransform(&move, sourcePath)
+	if err != nil {
+		return "", err
+	}
+	if !matched {
+		return "", explainMoveMismatch(sourcePath, move.From)
+	}
+	return targetPath, nil
+}
+
+// explainMoveMismatch describes why a source path is not under a move transformation's "from" prefix.
+func explainMoveMismatch(sourcePath, from string) error {
+	prefix := types.NormalizePathPrefix(from)
+	if strings.HasPrefix(sourcePath, prefix) {
+		return fmt.Errorf("source path %q is not under from %q: from matches whole directory names, so it covers %q but not %q",
+			sourcePath, from, prefix+"/...", sourcePath)
+	}
+	if normalized := types.NormalizePathPrefix(sourcePath); normalized != sourcePath {
+		if _, ok := types.StripPathPrefix(normalized, prefix); ok {
+			return fmt.Errorf("source path %q is not under from %q: use the path as it appears in the repository (%q)",
+				sourcePath, from, normalized)
+		}
+	}
+	return fmt.Errorf("source path %q is not under from %q", sourcePath, from)
+}
+
 // loadLocalConfigFile attempts to load config from a local file
 // This is useful for local testing and development
 func loadLocalConfigFile(filename string) (string, error) {
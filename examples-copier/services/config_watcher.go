@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// ConfigWatcher serves the last-known-good copier config, and re-fetches the main config and the
+// workflow configs it references on an interval or when an admin asks for a reload. A config that
+// fails to load or validate is logged and never replaces the one in use, so a bad edit in the
+// config repo doesn't stop the copier.
+//
+// ConfigWatcher implements ConfigLoader, so it can stand in for the loader it wraps.
+type ConfigWatcher struct {
+	loader   ConfigLoader
+	config   *configs.Config
+	interval time.Duration
+
+	// reloadMu makes concurrent reloads wait for the one in progress
+	reloadMu sync.Mutex
+	mu       sync.RWMutex
+	current  *types.YAMLConfig
+	status   ConfigStatus
+}
+
+// ConfigStatus describes the config in use and the most recent reload attempt
+type ConfigStatus struct {
+	LoadedAt      time.Time `json:"loaded_at,omitempty"` // When the config in use was loaded
+	WorkflowCount int       `json:"workflow_count"`
+	LastAttempt   time.Time `json:"last_attempt,omitempty"`
+	LastError     string    `json:"last_error,omitempty"` // Why the last attempt was rejected, if it was
+	Reloads       int       `json:"reloads"`              // Successful loads since startup
+	Rejected      int       `json:"rejected"`             // Failed loads since startup
+}
+
+// NewConfigWatcher creates a watcher that loads configs with loader. interval is how often Run
+// re-fetches the config; 0 or less means it's only reloaded on request.
+func NewConfigWatcher(loader ConfigLoader, config *configs.Config, interval time.Duration) *ConfigWatcher {
+	return &ConfigWatcher{loader: loader, config: config, interval: interval}
+}
+
+// LoadConfig returns the last-known-good config, loading it first if it hasn't been loaded yet.
+// The watcher's own environment config is used, so config is ignored.
+func (w *ConfigWatcher) LoadConfig(ctx context.Context, config *configs.Config) (*types.YAMLConfig, error) {
+	if current := w.Current(); current != nil {
+		return current, nil
+	}
+	return w.Reload(ctx)
+}
+
+// LoadConfigFromContent parses content with the wrapped loader. It doesn't change the config in use.
+func (w *ConfigWatcher) LoadConfigFromContent(content string, filename string) (*types.YAMLConfig, error) {
+	return w.loader.LoadConfigFromContent(content, filename)
+}
+
+// Current returns a copy of the config in use, or nil if no config has loaded yet. Callers may
+// replace fields of the copy, such as Workflows, without affecting other callers.
+func (w *ConfigWatcher) Current() *types.YAMLConfig {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.current == nil {
+		return nil
+	}
+	current := *w.current
+	return &current
+}
+
+// Status returns the state of the config in use and the most recent reload attempt
+func (w *ConfigWatcher) Status() ConfigStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.status
+}
+
+// Reload fetches and validates the config. If it loads, it replaces the config in use and is
+// returned. Otherwise the last-known-good config stays in use and the error is returned.
+func (w *ConfigWatcher) Reload(ctx context.Context) (*types.YAMLConfig, error) {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	loaded, err := w.loader.LoadConfig(ctx, w.config)
+
+	w.mu.Lock()
+	w.status.LastAttempt = time.Now()
+	if err != nil {
+		w.status.LastError = err.Error()
+		w.status.Rejected++
+		hasConfig := w.current != nil
+		w.mu.Unlock()
+
+		if hasConfig {
+			LogWarningCtx(ctx, "config reload rejected; keeping last-known-good config", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		return nil, err
+	}
+	w.current = loaded
+	w.status.LoadedAt = w.status.LastAttempt
+	w.status.WorkflowCount = len(loaded.Workflows)
+	w.status.LastError = ""
+	w.status.Reloads++
+	w.mu.Unlock()
+
+	LogInfoCtx(ctx, "config loaded", map[string]interface{}{
+		"workflow_count": len(loaded.Workflows),
+	})
+	return w.Current(), nil
+}
+
+// Run reloads the config on the watcher's interval until ctx is cancelled
+func (w *ConfigWatcher) Run(ctx context.Context) {
+	if w.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Failures are logged by Reload, and the last-known-good config stays in use
+			_, _ = w.Reload(ctx)
+		}
+	}
+}
+
+// ConfigReloadHandler handles the admin config reload endpoint. GET returns the status of the
+// config in use. POST re-fetches the config now, and responds with 422 and the error if it was
+// rejected. Requests must send the admin token as a bearer token.
+func ConfigReloadHandler(config *configs.Config, watcher *ConfigWatcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validAdminToken(r, config.AdminToken) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		status := http.StatusOK
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			if _, err := watcher.Reload(r.Context()); err != nil {
+				status = http.StatusUnprocessableEntity
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(watcher.Status())
+	}
+}
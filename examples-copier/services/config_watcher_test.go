@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const watcherTestConfig = `
+workflows:
+  - name: "%s"
+    source:
+      repo: "mongodb/source-repo"
+      branch: "main"
+    destination:
+      repo: "mongodb/dest-repo"
+      branch: "main"
+    transformations:
+      - move:
+          from: "examples"
+          to: "code-examples"
+`
+
+func writeWatcherTestConfig(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestConfigWatcher_KeepsLastKnownGoodConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "copier-config.yaml")
+	config := &configs.Config{ConfigFile: configFile}
+	watcher := NewConfigWatcher(NewConfigLoader(), config, 0)
+	ctx := context.Background()
+
+	writeWatcherTestConfig(t, configFile, fmt.Sprintf(watcherTestConfig, "first"))
+	loaded, err := watcher.LoadConfig(ctx, config)
+	require.NoError(t, err)
+	assert.Equal(t, "first", loaded.Workflows[0].Name)
+
+	// Changes aren't picked up until the config is reloaded
+	writeWatcherTestConfig(t, configFile, fmt.Sprintf(watcherTestConfig, "second"))
+	loaded, err = watcher.LoadConfig(ctx, config)
+	require.NoError(t, err)
+	assert.Equal(t, "first", loaded.Workflows[0].Name)
+
+	loaded, err = watcher.Reload(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "second", loaded.Workflows[0].Name)
+
+	// A config that fails validation doesn't replace the one in use
+	writeWatcherTestConfig(t, configFile, "workflows:\n  - name: \"missing-source\"\n")
+	_, err = watcher.Reload(ctx)
+	require.Error(t, err)
+	loaded, err = watcher.LoadConfig(ctx, config)
+	require.NoError(t, err)
+	assert.Equal(t, "second", loaded.Workflows[0].Name)
+
+	status := watcher.Status()
+	assert.Equal(t, 2, status.Reloads)
+	assert.Equal(t, 1, status.Rejected)
+	assert.Equal(t, 1, status.WorkflowCount)
+	assert.Contains(t, status.LastError, "config validation failed")
+	assert.True(t, status.LastAttempt.After(status.LoadedAt))
+}
+
+func TestConfigWatcher_CallersGetTheirOwnCopy(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "copier-config.yaml")
+	writeWatcherTestConfig(t, configFile, fmt.Sprintf(watcherTestConfig, "only"))
+	watcher := NewConfigWatcher(NewConfigLoader(), &configs.Config{ConfigFile: configFile}, 0)
+
+	loaded, err := watcher.LoadConfig(context.Background(), nil)
+	require.NoError(t, err)
+	loaded.Workflows = nil
+
+	assert.Len(t, watcher.Current().Workflows, 1)
+}
+
+func TestConfigReloadHandler(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "copier-config.yaml")
+	config := &configs.Config{ConfigFile: configFile, AdminToken: "admin-token"}
+	watcher := NewConfigWatcher(NewConfigLoader(), config, 0)
+	handler := ConfigReloadHandler(config, watcher)
+
+	request := func(method string, token string) (*httptest.ResponseRecorder, ConfigStatus) {
+		req := httptest.NewRequest(method, "/admin/reload", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		var status ConfigStatus
+		if rec.Header().Get("Content-Type") == "application/json" {
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+		}
+		return rec, status
+	}
+
+	rec, _ := request(http.MethodPost, "wrong-token")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	writeWatcherTestConfig(t, configFile, fmt.Sprintf(watcherTestConfig, "first"))
+	rec, status := request(http.MethodPost, "admin-token")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, status.WorkflowCount)
+	assert.Empty(t, status.LastError)
+
+	writeWatcherTestConfig(t, configFile, "not: [valid")
+	rec, status = request(http.MethodPost, "admin-token")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.NotEmpty(t, status.LastError)
+	assert.Equal(t, 1, status.WorkflowCount)
+
+	rec, status = request(http.MethodGet, "admin-token")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 2, status.Reloads+status.Rejected)
+
+	rec, _ = request(http.MethodDelete, "admin-token")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestNewServiceContainer_ConfigWatcher(t *testing.T) {
+	container, err := NewServiceContainer(&configs.Config{ConfigReloadInterval: 60})
+	require.NoError(t, err)
+	require.NotNil(t, container.ConfigWatcher)
+	assert.Same(t, container.ConfigWatcher, container.ConfigLoader)
+
+	container, err = NewServiceContainer(&configs.Config{})
+	require.NoError(t, err)
+	assert.Nil(t, container.ConfigWatcher, "the config is fetched for every webhook when reloading is disabled")
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/go-github/v48/github"
+	. "github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// applyContentTransforms rewrites the file content with the workflow's content_transforms that match the
+// source path, in order, before the file is scanned, validated, and queued for upload. Binary files are
+// left as they are.
+func (wp *workflowProcessor) applyContentTransforms(
+	ctx context.Context,
+	workflow Workflow,
+	sourcePath string,
+	fileContent *github.RepositoryContent,
+	sourceCommitSHA string,
+) error {
+	if len(workflow.ContentTransforms) == 0 {
+		return nil
+	}
+
+	content, err := fileContent.GetContent()
+	if err != nil {
+		return fmt.Errorf("failed to decode file content for content transforms: %w", err)
+	}
+	if !isTextContent(content) {
+		return nil
+	}
+
+	variables := map[string]string{
+		"source_repo": workflow.Source.Repo,
+		"source_path": sourcePath,
+		"commit_sha":  sourceCommitSHA,
+	}
+	transformed, applied, err := transformContent(workflow.ContentTransforms, sourcePath, content, variables)
+	if err != nil {
+		return fmt.Errorf("content transform failed for %s: %w", sourcePath, err)
+	}
+	if transformed == content {
+		return nil
+	}
+
+	// Store the content decoded: GetContent returns it as-is when there's no encoding
+	fileContent.Content = github.String(transformed)
+	fileContent.Encoding = nil
+	fileContent.Size = github.Int(len(transformed))
+
+	LogInfoCtx(ctx, "applied content transforms", map[string]interface{}{
+		"workflow_name": workflow.Name,
+		"source_path":   sourcePath,
+		"applied":       applied,
+	})
+	return nil
+}
+
+// transformContent applies each transform that matches the source path to content, in order. Returns the
+// new content and the number of transforms that matched.
+func transformContent(transforms []ContentTransform, sourcePath, content string, variables map[string]string) (string, int, error) {
+	applied := 0
+	for i, transform := range transforms {
+		if !transform.Matches(sourcePath) {
+			continue
+		}
+		applied++
+
+		var err error
+		switch transform.Type {
+		case ContentTransformReplace:
+			content, err = replaceContent(transform, content)
+		case ContentTransformHeader:
+			content = insertHeader(transform, content, variables)
+		case ContentTransformStripLines:
+			content, err = stripLines(transform, content)
+		default:
+			err = fmt.Errorf("invalid type: %s", transform.Type)
+		}
+		if err != nil {
+			return "", applied, fmt.Errorf("content_transforms[%d]: %w", i, err)
+		}
+	}
+	return content, applied, nil
+}
+
+// replaceContent replaces every match of the transform's pattern
+func replaceContent(transform ContentTransform, content string) (string, error) {
+	re, err := regexp.Compile(transform.Pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re.ReplaceAllString(content, transform.Replacement), nil
+}
+
+// insertHeader inserts the transform's header at the top of the content, after a shebang line if there is one
+func insertHeader(transform ContentTransform, content string, variables map[string]string) string {
+	header := transform.Header
+	for name, value := range variables {
+		header = strings.ReplaceAll(header, "${"+name+"}", value)
+	}
+	if !strings.HasSuffix(header, "\n") {
+		header += "\n"
+	}
+
+	if strings.HasPrefix(content, "#!") {
+		if end := strings.Index(content, "\n"); end >= 0 {
+			return content[:end+1] + header + content[end+1:]
+		}
+		return content + "\n" + header
+	}
+	return header + content
+}
+
+// stripLines removes lines matching the transform's start pattern. If the transform has an end pattern,
+// each line matching start through the next line matching end is removed, inclusive.
+func stripLines(transform ContentTransform, content string) (string, error) {
+	start, err := regexp.Compile(transform.Start)
+	if err != nil {
+		return "", fmt.Errorf("invalid start: %w", err)
+	}
+	var end *regexp.Regexp
+	if transform.End != "" {
+		if end, err = regexp.Compile(transform.End); err != nil {
+			return "", fmt.Errorf("invalid end: %w", err)
+		}
+	}
+
+	var b strings.Builder
+	rangeStart := 0 // Line number of the open range's start, or 0 if no range is open
+	for i, line := range strings.SplitAfter(content, "\n") {
+		text := strings.TrimRight(line, "\r\n")
+		switch {
+		case rangeStart > 0:
+			if end.MatchString(text) {
+				rangeStart = 0
+			}
+		case start.MatchString(text):
+			if end != nil {
+				rangeStart = i + 1
+			}
+		default:
+			b.WriteString(line)
+		}
+	}
+	if rangeStart > 0 {
+		// Fail rather than strip to the end of the file or copy what should have been removed
+		return "", fmt.Errorf("no line matching end %q after the start on line %d", transform.End, rangeStart)
+	}
+	return b.String(), nil
+}
+
+// isTextContent returns true if the content looks like text rather than a binary file
+func isTextContent(content string) bool {
+	return utf8.ValidString(content) && !strings.ContainsRune(content, 0)
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// CopyEvent is a change the pipeline copies from: a merged GitHub or Bitbucket pull request or GitLab merge
+// request, a push to a GitHub branch, a successful GitHub Actions run on one, a published GitHub release,
+// or the files an upstream workflow copied. Event sources convert provider payloads into CopyEvents, so the
+// pipeline doesn't depend on where a change came from.
+type CopyEvent struct {
+	Platform   string `json:"platform"`    // types.SourcePlatformGitHub, types.SourcePlatformGitLab, or types.SourcePlatformBitbucket
+	Repo       string `json:"repo"`        // "owner/name" on GitHub, the full project path on GitLab, "workspace/repo" on Bitbucket
+	Number     int    `json:"number"`      // PR number, or the MR IID on GitLab; 0 for pushes, workflow runs, and releases
+	CommitSHA  string `json:"commit_sha"`  // the merge commit, the head commit of a push or workflow run, or the release tag's commit
+	BaseBranch string `json:"base_branch"` // the branch merged or pushed to, the workflow run's branch, or the release's target branch
+	URL        string `json:"url"`         // the PR or MR, the push's compare view, the workflow run, or the release
+	// Title is the PR or MR title, the name of a workflow run's Actions workflow, or the release name; empty for pushes
+	Title string `json:"title,omitempty"`
+	// Author is the login of the PR or MR author, or of the pusher
+	Author string `json:"author,omitempty"`
+	// Trigger is the workflow trigger the change is for; empty means types.WorkflowTriggerPRMerged
+	Trigger string `json:"trigger,omitempty"`
+	// Source is the name of the event source that produced the event, like "push" or "manual"; empty for
+	// events the copier produces itself, like chained changes and backfills
+	Source string `json:"source,omitempty"`
+	// BeforeSHA is the branch's commit before a push
+	BeforeSHA string `json:"before_sha,omitempty"`
+	// RunID is the ID of a GitHub Actions workflow run, whose artifacts are copied
+	RunID int64 `json:"run_id,omitempty"`
+	// ReleaseTag is the tag of a GitHub release, which is copied from
+	ReleaseTag string `json:"release_tag,omitempty"`
+	// Prerelease is true if the release is marked as a pre-release
+	Prerelease bool `json:"prerelease,omitempty"`
+	// CorrelationID identifies the webhook delivery in logs, GitHub API requests, and pull requests
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// ChainedFiles are the files an upstream workflow's commit wrote and deleted, for chained changes
+	ChainedFiles []types.ChangedFile `json:"chained_files,omitempty"`
+	// Chain lists the repos and branches, as "repo@branch", that copies ran from before a chained change
+	Chain []string `json:"chain,omitempty"`
+}
+
+// sourceChangeKey is the context key for the change a workflow run copies from
+type sourceChangeKey struct{}
+
+// withSourceChange returns a copy of ctx that carries the change being processed, so commit message
+// and PR templates can refer to the source PR, its title, and its author
+func withSourceChange(ctx context.Context, change CopyEvent) context.Context {
+	return context.WithValue(ctx, sourceChangeKey{}, change)
+}
+
+// sourceChangeFromContext returns the change carried by ctx, if any
+func sourceChangeFromContext(ctx context.Context) (CopyEvent, bool) {
+	change, ok := ctx.Value(sourceChangeKey{}).(CopyEvent)
+	return change, ok
+}
+
+// trigger returns the workflow trigger the change is for
+func (c CopyEvent) trigger() string {
+	if c.Trigger == "" {
+		return types.WorkflowTriggerPRMerged
+	}
+	return c.Trigger
+}
+
+// describe returns a short description of the change for notifications, like "PR #12" or "push to main"
+func (c CopyEvent) describe() string {
+	switch {
+	case c.trigger() == types.WorkflowTriggerPush:
+		return fmt.Sprintf("push to %s", c.BaseBranch)
+	case c.trigger() == types.WorkflowTriggerWorkflowRun:
+		return fmt.Sprintf("%s run on %s", c.Title, c.BaseBranch)
+	case c.trigger() == types.WorkflowTriggerRelease:
+		return fmt.Sprintf("release %s", c.ReleaseTag)
+	case c.trigger() == types.WorkflowTriggerChained:
+		return fmt.Sprintf("copy to %s", c.BaseBranch)
+	case c.Platform == types.SourcePlatformGitLab:
+		return fmt.Sprintf("MR !%d", c.Number)
+	default:
+		return fmt.Sprintf("PR #%d", c.Number)
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CorrelationIDHeader is set on GitHub API requests made while processing a webhook delivery, so
+// requests can be matched up with the delivery's log lines
+const CorrelationIDHeader = "X-Copier-Correlation-ID"
+
+// correlationIDKey is the context key for the correlation ID of the delivery being processed
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx that carries the correlation ID. Log lines written with
+// the context and GitHub API requests made with it include the ID. An empty ID returns ctx unchanged.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	if correlationID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// CorrelationIDFromContext returns the correlation ID carried by ctx, or "" if it has none
+func CorrelationIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// correlationIDForRequest returns the correlation ID for a webhook request: the delivery ID GitHub,
+// GitLab, or Bitbucket sent, so the ID also matches the delivery in the webhook's settings, or a new random ID
+func correlationIDForRequest(r *http.Request) string {
+	for _, header := range []string{"X-GitHub-Delivery", "X-Gitlab-Event-UUID", "X-Request-UUID"} {
+		if id := strings.TrimSpace(r.Header.Get(header)); id != "" {
+			return id
+		}
+	}
+	return newCorrelationID()
+}
+
+func newCorrelationID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// appendCorrelationID notes the correlation ID at the end of a PR body, so the logs for the run
+// that opened the PR can be found from the PR
+func appendCorrelationID(prBody string, correlationID string) string {
+	if correlationID == "" {
+		return prBody
+	}
+	note := fmt.Sprintf("<sub>Copier correlation ID: `%s`</sub>", correlationID)
+	if strings.TrimSpace(prBody) == "" {
+		return note
+	}
+	return strings.TrimRight(prBody, "\n") + "\n\n" + note
+}
+
+// correlationTransport sets the correlation ID header on GitHub API requests, and logs each request
+// at debug level with the ID
+type correlationTransport struct {
+	base http.RoundTripper
+}
+
+func (t *correlationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	correlationID := CorrelationIDFromContext(ctx)
+	if correlationID != "" {
+		req = req.Clone(ctx)
+		req.Header.Set(CorrelationIDHeader, correlationID)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	fields := map[string]interface{}{
+		"method":      req.Method,
+		"path":        req.URL.Path,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	if resp != nil {
+		fields["status"] = resp.StatusCode
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	LogDebugCtx(ctx, "GitHub API request", fields)
+	return resp, err
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRequestID_UsesDeliveryID(t *testing.T) {
+	req := httptest.NewRequest("POST", "/events", nil)
+	req.Header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+
+	ctx, requestID := WithRequestID(req)
+	assert.Equal(t, "72d3162e-cc78-11e3-81ab-4c9367dc0958", requestID)
+	assert.Equal(t, requestID, CorrelationIDFromContext(ctx))
+
+	_, generated := WithRequestID(httptest.NewRequest("POST", "/events", nil))
+	assert.Len(t, generated, 32)
+}
+
+func TestLogInfoCtx_JSONFormat(t *testing.T) {
+	t.Setenv("LOG_FORMAT", "json")
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	ctx := WithCorrelationID(context.Background(), "delivery-1")
+	LogInfoCtx(ctx, "processing merged PR", map[string]interface{}{"pr_number": 42})
+	LogErrorCtx(ctx, "upload failed", assert.AnError, nil)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var info map[string]interface{}
+	require.NoError(t, json.Unmarshal(lines[0], &info))
+	assert.Equal(t, "INFO", info["severity"])
+	assert.Equal(t, "processing merged PR", info["message"])
+	assert.Equal(t, "delivery-1", info["correlation_id"])
+	assert.Equal(t, float64(42), info["pr_number"])
+
+	var failure map[string]interface{}
+	require.NoError(t, json.Unmarshal(lines[1], &failure))
+	assert.Equal(t, "ERROR", failure["severity"])
+	assert.Equal(t, assert.AnError.Error(), failure["error"])
+	assert.Equal(t, "delivery-1", failure["correlation_id"])
+}
+
+func TestLogInfoCtx_TextFormatIncludesCorrelationID(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	LogInfoCtx(WithCorrelationID(context.Background(), "delivery-1"), "processing merged PR", nil)
+	assert.Contains(t, buf.String(), `[INFO] processing merged PR | {"correlation_id":"delivery-1"}`)
+}
+
+func TestCorrelationTransport(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(CorrelationIDHeader)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &correlationTransport{base: http.DefaultTransport}}
+	req, err := http.NewRequestWithContext(WithCorrelationID(context.Background(), "delivery-1"), "GET", server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "delivery-1", received)
+	assert.Empty(t, req.Header.Get(CorrelationIDHeader), "the caller's request shouldn't be modified")
+}
+
+func TestAppendCorrelationID(t *testing.T) {
+	assert.Equal(t, "Updated examples\n\n<sub>Copier correlation ID: `delivery-1`</sub>", appendCorrelationID("Updated examples\n", "delivery-1"))
+	assert.Equal(t, "<sub>Copier correlation ID: `delivery-1`</sub>", appendCorrelationID("", "delivery-1"))
+	assert.Equal(t, "Updated examples", appendCorrelationID("Updated examples", ""))
+}
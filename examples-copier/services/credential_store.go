@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+)
+
+// AppCredentials are what the copier authenticates to an org's GitHub App installation with
+type AppCredentials struct {
+	AppID      string
+	PrivateKey *rsa.PrivateKey
+	// InstallationID is the App's installation on the org; empty to look it up from the App's installations
+	InstallationID string
+}
+
+// CredentialStore returns the GitHub App credentials for an org. An empty org is the default installation,
+// INSTALLATION_ID of GITHUB_APP_ID.
+type CredentialStore interface {
+	Credentials(org string) (*AppCredentials, error)
+}
+
+// appCredentialStore maps orgs to GitHub Apps with GITHUB_ORG_APP_IDS, GITHUB_ORG_APP_KEYS, and
+// GITHUB_ORG_INSTALLATION_IDS. Orgs that aren't listed use GITHUB_APP_ID. Each private key is read with
+// readKey, by the name GITHUB_ORG_APP_KEYS gives it or "" for GITHUB_APP_ID's key, and parsed once.
+type appCredentialStore struct {
+	readKey func(name string) ([]byte, error)
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PrivateKey
+}
+
+// NewSecretManagerCredentialStore returns a CredentialStore that reads private keys from Google Cloud Secret
+// Manager. GITHUB_APP_ID's key is the PEM_NAME secret; GITHUB_ORG_APP_KEYS lists secret names.
+func NewSecretManagerCredentialStore() CredentialStore {
+	return &appCredentialStore{readKey: readKeyFromSecretManager, keys: make(map[string]*rsa.PrivateKey)}
+}
+
+// NewEnvCredentialStore returns a CredentialStore that reads private keys from the environment, for tests
+// and local runs. GITHUB_APP_ID's key is GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_B64;
+// GITHUB_ORG_APP_KEYS lists the environment variables with the other Apps' keys, in PEM or base64.
+func NewEnvCredentialStore() CredentialStore {
+	return &appCredentialStore{readKey: readKeyFromEnv, keys: make(map[string]*rsa.PrivateKey)}
+}
+
+// credentialStore is where GitHub App credentials are read from; nil until first used
+var credentialStore CredentialStore
+var credentialStoreMu sync.Mutex
+
+// getCredentialStore returns the credential store, creating the default one on first use: the environment
+// with SKIP_SECRET_MANAGER=true, and Secret Manager otherwise
+func getCredentialStore() CredentialStore {
+	credentialStoreMu.Lock()
+	defer credentialStoreMu.Unlock()
+	if credentialStore == nil {
+		if os.Getenv("SKIP_SECRET_MANAGER") == "true" {
+			credentialStore = NewEnvCredentialStore()
+		} else {
+			credentialStore = NewSecretManagerCredentialStore()
+		}
+	}
+	return credentialStore
+}
+
+// SetCredentialStore replaces the store GitHub App credentials are read from. Cached installation tokens
+// are kept.
+func SetCredentialStore(store CredentialStore) {
+	credentialStoreMu.Lock()
+	defer credentialStoreMu.Unlock()
+	credentialStore = store
+}
+
+// Credentials returns the App ID, private key, and installation ID for the org
+func (s *appCredentialStore) Credentials(org string) (*AppCredentials, error) {
+	creds := &AppCredentials{AppID: os.Getenv(configs.AppId)}
+	keyName := ""
+	if org == "" {
+		creds.InstallationID = os.Getenv(configs.InstallationId)
+	} else {
+		// LoadEnvironment rejects malformed lists, so errors here only leave the defaults in place
+		appIDs, _ := configs.ParseOrgValues(os.Getenv(configs.GitHubOrgAppIDs))
+		appKeys, _ := configs.ParseOrgValues(os.Getenv(configs.GitHubOrgAppKeys))
+		installationIDs, _ := configs.ParseOrgValues(os.Getenv(configs.GitHubOrgInstallationIDs))
+		if appID, ok := appIDs[org]; ok {
+			creds.AppID, keyName = appID, appKeys[org]
+			if keyName == "" {
+				return nil, fmt.Errorf("%s has no private key for org %s", configs.GitHubOrgAppKeys, org)
+			}
+		}
+		creds.InstallationID = installationIDs[org]
+	}
+	if creds.AppID == "" {
+		return nil, fmt.Errorf("missing GitHub App ID for %s", installationName(org))
+	}
+
+	key, err := s.privateKey(keyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load private key of GitHub App %s: %w", creds.AppID, err)
+	}
+	creds.PrivateKey = key
+	return creds, nil
+}
+
+// privateKey returns the parsed private key with the name, reading it the first time it's needed
+func (s *appCredentialStore) privateKey(name string) (*rsa.PrivateKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key, ok := s.keys[name]; ok {
+		return key, nil
+	}
+	pemKey, err := s.readKey(name)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(pemKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse RSA private key: %w", err)
+	}
+	s.keys[name] = key
+	return key, nil
+}
+
+// readKeyFromEnv reads a private key from an environment variable, in PEM or base64. An empty name reads
+// GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_B64.
+func readKeyFromEnv(name string) ([]byte, error) {
+	if name == "" {
+		if pem := os.Getenv("GITHUB_APP_PRIVATE_KEY"); pem != "" {
+			return []byte(pem), nil
+		}
+		if b64 := os.Getenv("GITHUB_APP_PRIVATE_KEY_B64"); b64 != "" {
+			return decodeBase64Key("GITHUB_APP_PRIVATE_KEY_B64", b64)
+		}
+		return nil, fmt.Errorf("SKIP_SECRET_MANAGER=true but no GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_B64 set")
+	}
+
+	value := os.Getenv(name)
+	if value == "" {
+		return nil, fmt.Errorf("SKIP_SECRET_MANAGER=true but no %s set", name)
+	}
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return decodeBase64Key(name, value)
+}
+
+// decodeBase64Key decodes a base64-encoded private key from the environment variable with the name
+func decodeBase64Key(name string, value string) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 private key in %s: %w", name, err)
+	}
+	return decoded, nil
+}
+
+// readKeyFromSecretManager reads a private key from the Secret Manager secret with the name. An empty name
+// reads PEM_NAME.
+func readKeyFromSecretManager(name string) ([]byte, error) {
+	if name == "" {
+		name = os.Getenv(configs.PEMKeyName)
+		if name == "" {
+			name = configs.NewConfig().PEMKeyName
+		}
+	}
+
+	ctx := context.Background()
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Secret Manager client: %w", err)
+	}
+	defer client.Close()
+
+	result, err := client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to access secret version: %w", err)
+	}
+	return result.Payload.Data, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPrivateKeyPEM returns a new RSA private key in PEM
+func testPrivateKeyPEM(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+func TestEnvCredentialStore(t *testing.T) {
+	defaultKey, docsKey := testPrivateKeyPEM(t), testPrivateKeyPEM(t)
+	t.Setenv(configs.AppId, "100")
+	t.Setenv(configs.InstallationId, "1000")
+	t.Setenv("GITHUB_APP_PRIVATE_KEY", defaultKey)
+	t.Setenv(configs.GitHubOrgAppIDs, "docs=200, partners=300")
+	t.Setenv(configs.GitHubOrgAppKeys, "docs=DOCS_APP_KEY, partners=PARTNERS_APP_KEY")
+	t.Setenv(configs.GitHubOrgInstallationIDs, "docs=2000")
+	t.Setenv("DOCS_APP_KEY", base64.StdEncoding.EncodeToString([]byte(docsKey)))
+
+	store := NewEnvCredentialStore()
+
+	creds, err := store.Credentials("")
+	require.NoError(t, err)
+	assert.Equal(t, "100", creds.AppID)
+	assert.Equal(t, "1000", creds.InstallationID)
+	require.NotNil(t, creds.PrivateKey)
+
+	// Orgs that aren't listed use the default App and look up their installation
+	other, err := store.Credentials("mongodb")
+	require.NoError(t, err)
+	assert.Equal(t, "100", other.AppID)
+	assert.Empty(t, other.InstallationID)
+	assert.Same(t, creds.PrivateKey, other.PrivateKey, "keys are parsed once")
+
+	docs, err := store.Credentials("docs")
+	require.NoError(t, err)
+	assert.Equal(t, "200", docs.AppID)
+	assert.Equal(t, "2000", docs.InstallationID)
+	assert.False(t, docs.PrivateKey.Equal(creds.PrivateKey))
+
+	_, err = store.Credentials("partners")
+	assert.ErrorContains(t, err, "no PARTNERS_APP_KEY set")
+}
+
+func TestIssueInstallationTokenForOrg_PerOrgApps(t *testing.T) {
+	defaultKey, docsKey := testPrivateKeyPEM(t), testPrivateKeyPEM(t)
+	t.Setenv(configs.AppId, "100")
+	t.Setenv(configs.InstallationId, "1000")
+	t.Setenv(configs.GitHubOrgAppIDs, "docs=200")
+	t.Setenv(configs.GitHubOrgAppKeys, "docs=DOCS_APP_KEY")
+	t.Setenv(configs.GitHubOrgInstallationIDs, "docs=2000")
+	t.Setenv("GITHUB_APP_PRIVATE_KEY", defaultKey)
+	t.Setenv("DOCS_APP_KEY", docsKey)
+
+	originalClient, originalJWTs, originalStore := HTTPClient, jwtCache, credentialStore
+	t.Cleanup(func() { HTTPClient, jwtCache, credentialStore = originalClient, originalJWTs, originalStore })
+	jwtCache = make(map[string]cachedJWT)
+	credentialStore = NewEnvCredentialStore()
+
+	// Record which App each token request authenticated as
+	issuers := make(map[string]string)
+	HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		claims := jwt.MapClaims{}
+		_, _, err := jwt.NewParser().ParseUnverified(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "), claims)
+		require.NoError(t, err)
+		issuers[req.URL.Path] = claims["iss"].(string)
+		body := `{"token":"token-for-` + claims["iss"].(string) + `","expires_at":"` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`
+		return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}, Request: req}, nil
+	})}
+
+	token, _, err := issueInstallationTokenForOrg("")
+	require.NoError(t, err)
+	assert.Equal(t, "token-for-100", token)
+
+	token, _, err = issueInstallationTokenForOrg("docs")
+	require.NoError(t, err)
+	assert.Equal(t, "token-for-200", token)
+
+	assert.Equal(t, map[string]string{
+		"/app/installations/1000/access_tokens": "100",
+		"/app/installations/2000/access_tokens": "200",
+	}, issuers)
+	assert.Len(t, jwtCache, 2, "each App has its own JWT")
+}
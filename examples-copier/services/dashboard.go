@@ -0,0 +1,181 @@
+package services
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+)
+
+// defaultRunsLimit is how many runs the runs endpoint and dashboard show when no limit is given
+const defaultRunsLimit = 50
+
+// RunsHandler handles the admin runs endpoint. GET returns the most recent webhook runs, up to the
+// limit query parameter, or the run with the id query parameter. Requests must send the admin token.
+func RunsHandler(config *configs.Config, container *ServiceContainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validAdminToken(r, config.AdminToken) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if id := r.URL.Query().Get("id"); id != "" {
+			run, err := container.RunHistory.Get(r.Context(), id)
+			if err != nil {
+				LogErrorCtx(r.Context(), "failed to read run history", err, nil)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if run == nil {
+				http.Error(w, "run not found", http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(run)
+			return
+		}
+
+		runs, err := container.RunHistory.Recent(r.Context(), runsLimit(r))
+		if err != nil {
+			LogErrorCtx(r.Context(), "failed to read run history", err, nil)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": container.RunHistory != nil,
+			"runs":    runs,
+		})
+	}
+}
+
+// DashboardHandler serves an HTML page of the most recent webhook runs. Browsers are prompted for
+// the admin token as a basic auth password; any username is accepted.
+func DashboardHandler(config *configs.Config, container *ServiceContainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validAdminToken(r, config.AdminToken) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="examples-copier", charset="UTF-8"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		runs, err := container.RunHistory.Recent(r.Context(), runsLimit(r))
+		if err != nil {
+			LogErrorCtx(r.Context(), "failed to read run history", err, nil)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := dashboardTemplate.Execute(w, dashboardData{
+			Enabled:     container.RunHistory != nil,
+			Runs:        runs,
+			GeneratedAt: time.Now().UTC(),
+		}); err != nil {
+			LogErrorCtx(r.Context(), "failed to render dashboard", err, nil)
+		}
+	}
+}
+
+// runsLimit returns the limit query parameter, or defaultRunsLimit if it's missing or invalid
+func runsLimit(r *http.Request) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		return defaultRunsLimit
+	}
+	return limit
+}
+
+// dashboardData is the data rendered by dashboardTemplate
+type dashboardData struct {
+	Enabled     bool
+	Runs        []*WebhookRun
+	GeneratedAt time.Time
+}
+
+// runFileCount returns the number of files a run's workflows copied or would have copied
+func runFileCount(run *WebhookRun) int {
+	count := 0
+	for _, workflow := range run.Workflows {
+		count += len(workflow.Files)
+	}
+	return count
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"formatTime": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04:05 UTC") },
+	"fileCount":  runFileCount,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Examples Copier - Recent Runs</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1c2d38; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { text-align: left; padding: 6px 10px; border-bottom: 1px solid #e8edeb; vertical-align: top; }
+  th { background: #f9fbfa; }
+  .status { font-weight: 600; }
+  .succeeded { color: #00684a; }
+  .failed { color: #db3030; }
+  .no_match, .running { color: #889397; }
+  .errors { color: #db3030; margin: 0; padding-left: 1.2em; }
+  details ul { margin: 4px 0; }
+  .muted { color: #889397; }
+  code { font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Recent Webhook Runs</h1>
+<p class="muted">Generated {{formatTime .GeneratedAt}}. JSON: <code>/admin/runs</code></p>
+{{if not .Enabled}}
+<p>Run history is disabled. Set <code>RUN_HISTORY_SIZE</code> to record webhook runs.</p>
+{{else if not .Runs}}
+<p>No webhook runs recorded yet.</p>
+{{else}}
+<table>
+<thead>
+<tr><th>Started</th><th>Source</th><th>Status</th><th>Duration</th><th>Workflows</th><th>Files</th><th>Details</th></tr>
+</thead>
+<tbody>
+{{range .Runs}}
+<tr>
+  <td>{{formatTime .StartedAt}}</td>
+  <td>{{if .PRURL}}<a href="{{.PRURL}}">{{template "source" .}}</a>{{else}}{{template "source" .}}{{end}}<br><span class="muted">{{.BaseBranch}} @ <code>{{.CommitSHA}}</code></span></td>
+  <td class="status {{.Status}}">{{.Status}}</td>
+  <td>{{if .FinishedAt.IsZero}}-{{else}}{{.DurationMs}} ms{{end}}</td>
+  <td>{{len .Workflows}}</td>
+  <td>{{fileCount .}}</td>
+  <td>
+    {{if .Error}}<ul class="errors"><li>{{.Error}}</li></ul>{{end}}
+    {{range .Workflows}}
+    <details>
+      <summary>{{.Name}} &rarr; {{.TargetRepo}}:{{.TargetBranch}}{{if .DryRun}} (dry run){{end}}{{if .TargetPRURL}} &middot; <a href="{{.TargetPRURL}}">target PR</a>{{end}}{{if .Errors}} &middot; <span class="failed">{{len .Errors}} error(s)</span>{{end}}</summary>
+      {{if .Errors}}<ul class="errors">{{range .Errors}}<li>{{.}}</li>{{end}}</ul>{{end}}
+      {{if .Files}}<ul>{{range .Files}}<li><code>{{.}}</code></li>{{end}}</ul>{{end}}
+      {{if .Deletions}}<ul>{{range .Deletions}}<li><code>{{.}}</code> (deleted)</li>{{end}}</ul>{{end}}
+    </details>
+    {{end}}
+  </td>
+</tr>
+{{end}}
+</tbody>
+</table>
+{{end}}
+</body>
+</html>
+{{define "source"}}{{.SourceRepo}}{{if eq .Trigger "push"}} push{{else}}#{{.PRNumber}}{{end}}{{end}}
+`))
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDashboardTestContainer(t *testing.T) (*configs.Config, *ServiceContainer, *WebhookRun) {
+	t.Helper()
+	config := &configs.Config{AdminToken: "admin-token", RunHistorySize: 10}
+	container, err := NewServiceContainer(config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	run := container.RunHistory.Start(ctx, CopyEvent{Repo: "org/src", Number: 42, URL: "https://github.com/org/src/pull/42"})
+	run.addWorkflows([]*workflowRun{{
+		Workflow: types.Workflow{Name: "python", Destination: types.Destination{Repo: "org/dest", Branch: "main"}},
+		Files:    []string{"examples/<script>.py"},
+	}}, map[types.UploadKey]UploadResult{
+		{RepoName: "org/dest", BranchPath: "main"}: {PRURL: "https://github.com/org/dest/pull/9"},
+	})
+	container.RunHistory.Finish(ctx, run)
+	return config, container, run
+}
+
+func TestRunsHandler(t *testing.T) {
+	config, container, run := newDashboardTestContainer(t)
+	handler := RunsHandler(config, container)
+
+	get := func(target string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get("/admin/runs", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, get("/admin/runs", "wrong").Code)
+
+	w := get("/admin/runs?limit=5", "admin-token")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Enabled bool          `json:"enabled"`
+		Runs    []*WebhookRun `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.True(t, body.Enabled)
+	require.Len(t, body.Runs, 1)
+	assert.Equal(t, RunStatusSucceeded, body.Runs[0].Status)
+	assert.Equal(t, "https://github.com/org/dest/pull/9", body.Runs[0].Workflows[0].TargetPRURL)
+
+	w = get("/admin/runs?id="+url.QueryEscape(run.ID), "admin-token")
+	require.Equal(t, http.StatusOK, w.Code)
+	var single WebhookRun
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &single))
+	assert.Equal(t, 42, single.PRNumber)
+
+	assert.Equal(t, http.StatusNotFound, get("/admin/runs?id=unknown", "admin-token").Code)
+}
+
+func TestDashboardHandler(t *testing.T) {
+	config, container, _ := newDashboardTestContainer(t)
+	handler := DashboardHandler(config, container)
+
+	// Browsers are prompted for the token
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/admin/dashboard", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Basic")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/dashboard", nil)
+	req.SetBasicAuth("anyone", "admin-token")
+	w = httptest.NewRecorder()
+	handler(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+	page := w.Body.String()
+	assert.Contains(t, page, `<a href="https://github.com/org/src/pull/42">org/src#42</a>`)
+	assert.Contains(t, page, `<a href="https://github.com/org/dest/pull/9">target PR</a>`)
+	assert.Contains(t, page, "examples/&lt;script&gt;.py", "file names are escaped")
+}
+
+func TestDashboardHandler_HistoryDisabled(t *testing.T) {
+	config := &configs.Config{AdminToken: "admin-token"}
+	container := &ServiceContainer{Config: config, StartTime: time.Now()}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/dashboard", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	w := httptest.NewRecorder()
+	DashboardHandler(config, container)(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Run history is disabled")
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// expandDestinationBranches fans out workflows whose destination lists several branches or a branch pattern,
+// such as versioned docs branches: each is replaced by a copy per destination branch, in place, so every
+// branch gets its own staged upload and pull request. Patterns are matched against the destination repo's
+// branches. Workflows whose branches can't be listed, or that match no branches, are logged and skipped.
+func expandDestinationBranches(ctx context.Context, workflows []types.Workflow) []types.Workflow {
+	var expanded []types.Workflow
+	for _, workflow := range workflows {
+		if !workflow.Destination.IsMultiBranch() {
+			expanded = append(expanded, workflow)
+			continue
+		}
+
+		// The destination repo's branches are only listed to match patterns
+		var provider RepoProvider
+		var repo string
+		var err error
+		if workflow.Destination.HasBranchPattern() {
+			provider, repo, err = repoProviderFor(workflow.Destination.Repo)
+		}
+		var branches []string
+		if err == nil {
+			branches, err = resolveDestinationBranches(ctx, provider, repo, workflow.Destination.BranchPatterns())
+		}
+		if err == nil && len(branches) == 0 {
+			err = fmt.Errorf("no branches match %s", strings.Join(workflow.Destination.BranchPatterns(), ", "))
+		}
+		if err != nil {
+			LogWarningCtx(ctx, "skipping workflow: failed to resolve destination branches", map[string]interface{}{
+				"workflow":         workflow.Name,
+				"destination_repo": workflow.Destination.Repo,
+				"error":            err.Error(),
+			})
+			continue
+		}
+
+		LogInfoCtx(ctx, "copying to multiple destination branches", map[string]interface{}{
+			"workflow":         workflow.Name,
+			"destination_repo": workflow.Destination.Repo,
+			"branches":         branches,
+		})
+		for _, branch := range branches {
+			copied := workflow
+			copied.Destination.Branch = branch
+			copied.Destination.Branches = nil
+			expanded = append(expanded, copied)
+		}
+	}
+	return expanded
+}
+
+// resolveDestinationBranches returns the branches a destination copies to, in the order its patterns list
+// them, without duplicates. Branch names are used as they are; patterns are replaced by the branches of the
+// provider's repo that match them.
+func resolveDestinationBranches(ctx context.Context, provider RepoProvider, repo string, patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var branches []string
+	add := func(branch string) {
+		if !seen[branch] {
+			seen[branch] = true
+			branches = append(branches, branch)
+		}
+	}
+	for _, pattern := range patterns {
+		wildcard := strings.IndexAny(pattern, "*?[")
+		if wildcard < 0 {
+			add(pattern)
+			continue
+		}
+
+		// Only branches starting with the pattern's literal prefix can match it
+		candidates, err := provider.ListBranches(ctx, repo, pattern[:wildcard])
+		if err != nil {
+			return nil, fmt.Errorf("list branches matching %s: %w", pattern, err)
+		}
+		for _, candidate := range candidates {
+			if matched, _ := path.Match(pattern, candidate.Name); matched {
+				add(candidate.Name)
+			}
+		}
+	}
+	return branches, nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingBranchProvider fails to list branches
+type failingBranchProvider struct {
+	branchTestProvider
+}
+
+func (p *failingBranchProvider) ListBranches(ctx context.Context, repo string, prefix string) ([]ProviderBranch, error) {
+	return nil, errors.New("not found")
+}
+
+func TestExpandDestinationBranches(t *testing.T) {
+	workflows := []types.Workflow{
+		{Name: "single", Destination: types.Destination{Repo: "org/docs", Branch: "main"}},
+		{Name: "versioned", Destination: types.Destination{Repo: "org/docs", Branches: []string{"v7.0", "v8.0", "v7.0"}}},
+	}
+
+	expanded := expandDestinationBranches(context.Background(), workflows)
+
+	require.Len(t, expanded, 3)
+	assert.Equal(t, workflows[0], expanded[0], "workflows with one destination branch are kept as they are")
+	for i, branch := range []string{"v7.0", "v8.0"} {
+		assert.Equal(t, "versioned", expanded[i+1].Name)
+		assert.Equal(t, types.Destination{Repo: "org/docs", Branch: branch}, expanded[i+1].Destination)
+	}
+	assert.Equal(t, []string{"v7.0", "v8.0", "v7.0"}, workflows[1].Destination.Branches, "the config isn't changed")
+}
+
+func TestResolveDestinationBranches(t *testing.T) {
+	provider := &branchTestProvider{branches: []ProviderBranch{
+		{Name: "v7.0"}, {Name: "v8.0"}, {Name: "v8.0/hotfix"}, {Name: "main"},
+	}}
+
+	branches, err := resolveDestinationBranches(context.Background(), provider, "org/docs", []string{"main", "v*"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main", "v7.0", "v8.0"}, branches, "* doesn't match /, and branches aren't listed twice")
+
+	branches, err = resolveDestinationBranches(context.Background(), provider, "org/docs", []string{"release/*"})
+	require.NoError(t, err)
+	assert.Empty(t, branches)
+
+	_, err = resolveDestinationBranches(context.Background(), &failingBranchProvider{}, "org/docs", []string{"v*"})
+	assert.ErrorContains(t, err, "list branches matching v*")
+}
+
+func TestTempPRBranch_DistinctWithinASecond(t *testing.T) {
+	t.Cleanup(func() { lastTempPRBranch = time.Time{} })
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "copier/20300101-120000", tempPRBranch(now))
+	assert.Equal(t, "copier/20300101-120001", tempPRBranch(now.Add(500*time.Millisecond)),
+		"PRs to each destination branch opened in the same second get their own branch")
+	assert.Equal(t, "copier/20300101-120005", tempPRBranch(now.Add(5*time.Second)))
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// DryRunReport lists everything a dry-run workflow would have written for a merged PR
+type DryRunReport struct {
+	Workflow   string              `json:"workflow"`
+	SourceRepo string              `json:"source_repo"`
+	PRNumber   int                 `json:"pr_number"`
+	CommitSHA  string              `json:"commit_sha"`
+	Targets    []DryRunTarget      `json:"targets"`
+	Deprecated []DryRunDeprecation `json:"deprecated"`
+}
+
+// DryRunTarget is the commit or PR that would have been made to one destination repo and branch
+type DryRunTarget struct {
+	Repo           string   `json:"repo"`
+	Branch         string   `json:"branch"`
+	CommitStrategy string   `json:"commit_strategy"`
+	CommitMessage  string   `json:"commit_message,omitempty"`
+	PRTitle        string   `json:"pr_title,omitempty"`
+	Files          []string `json:"files"`               // Target paths that would be written
+	Deletions      []string `json:"deletions,omitempty"` // Target paths that would be removed by sync
+	AutoMerge      bool     `json:"auto_merge,omitempty"`
+}
+
+// DryRunDeprecation is a file that would have been recorded in a deprecation file
+type DryRunDeprecation struct {
+	DeprecationFile string `json:"deprecation_file"`
+	File            string `json:"file"`
+	Repo            string `json:"repo"`
+	Branch          string `json:"branch"`
+}
+
+// HasChanges returns true if the workflow would have written or deprecated anything
+func (r *DryRunReport) HasChanges() bool {
+	return len(r.Targets) > 0 || len(r.Deprecated) > 0
+}
+
+// runDryRunWorkflow processes a workflow without queueing anything for the shared upload and deprecation
+// steps, and returns the files it would have written as a report instead. If some files failed, the
+// report covers the rest and the file errors are returned with it.
+func runDryRunWorkflow(ctx context.Context, workflow types.Workflow, changedFiles []types.ChangedFile,
+	prNumber int, sourceCommitSHA string, container *ServiceContainer) (*DryRunReport, error) {
+
+	state, err := processWithoutQueueing(ctx, workflow, changedFiles, prNumber, sourceCommitSHA, container)
+	report := buildDryRunReport(workflow, prNumber, sourceCommitSHA, state)
+	logDryRunReport(ctx, report)
+	return report, err
+}
+
+// processWithoutQueueing processes a workflow against its own file state, so pattern matching,
+// transformations, and content checks all run, and returns that state
+func processWithoutQueueing(ctx context.Context, workflow types.Workflow, changedFiles []types.ChangedFile,
+	prNumber int, sourceCommitSHA string, container *ServiceContainer) (FileStateService, error) {
+
+	state := NewFileStateService()
+	// No metrics collector: dry-run files shouldn't count as uploaded
+	processor := NewWorkflowProcessor(
+		container.PatternMatcher,
+		container.PathTransformer,
+		state,
+		nil,
+		container.MessageTemplater,
+		container.SlackNotifier,
+		nil,
+	)
+
+	err := processor.ProcessWorkflow(ctx, workflow, changedFiles, prNumber, sourceCommitSHA)
+	return state, err
+}
+
+// buildDryRunReport converts the queued uploads and deprecations in state into a report, sorted so
+// that reports for the same PR are stable
+func buildDryRunReport(workflow types.Workflow, prNumber int, sourceCommitSHA string, state FileStateService) *DryRunReport {
+	report := &DryRunReport{
+		Workflow:   workflow.Name,
+		SourceRepo: workflow.Source.Repo,
+		PRNumber:   prNumber,
+		CommitSHA:  sourceCommitSHA,
+		Targets:    []DryRunTarget{},
+		Deprecated: []DryRunDeprecation{},
+	}
+
+	for key, content := range state.GetFilesToUpload() {
+		target := DryRunTarget{
+			Repo:           key.RepoName,
+			Branch:         key.BranchPath,
+			CommitStrategy: string(content.CommitStrategy),
+			CommitMessage:  content.CommitMessage,
+			PRTitle:        content.PRTitle,
+			Files:          make([]string, 0, len(content.Content)),
+			Deletions:      append([]string(nil), content.DeletePaths...),
+			AutoMerge:      content.AutoMergePR,
+		}
+		for _, file := range content.Content {
+			target.Files = append(target.Files, file.GetName())
+		}
+		sort.Strings(target.Files)
+		sort.Strings(target.Deletions)
+		report.Targets = append(report.Targets, target)
+	}
+	sort.Slice(report.Targets, func(i, j int) bool {
+		if report.Targets[i].Repo != report.Targets[j].Repo {
+			return report.Targets[i].Repo < report.Targets[j].Repo
+		}
+		return report.Targets[i].Branch < report.Targets[j].Branch
+	})
+
+	for deprecationFile, entry := range state.GetFilesToDeprecate() {
+		report.Deprecated = append(report.Deprecated, DryRunDeprecation{
+			DeprecationFile: deprecationFile,
+			File:            entry.FileName,
+			Repo:            entry.Repo,
+			Branch:          entry.Branch,
+		})
+	}
+	sort.Slice(report.Deprecated, func(i, j int) bool {
+		return report.Deprecated[i].File < report.Deprecated[j].File
+	})
+
+	return report
+}
+
+// FileCount returns the number of files the workflow would have written or removed
+func (r *DryRunReport) FileCount() int {
+	count := 0
+	for _, target := range r.Targets {
+		count += len(target.Files) + len(target.Deletions)
+	}
+	return count
+}
+
+// logDryRunReport logs the full report, one entry per workflow
+func logDryRunReport(ctx context.Context, report *DryRunReport) {
+	if !report.HasChanges() {
+		LogInfoCtx(ctx, "dry run: workflow would make no changes", map[string]interface{}{
+			"workflow_name": report.Workflow,
+			"pr_number":     report.PRNumber,
+		})
+		return
+	}
+	LogInfoCtx(ctx, "dry run: workflow would make these changes", map[string]interface{}{
+		"workflow_name":    report.Workflow,
+		"source_repo":      report.SourceRepo,
+		"pr_number":        report.PRNumber,
+		"commit_sha":       report.CommitSHA,
+		"file_count":       report.FileCount(),
+		"deprecated_count": len(report.Deprecated),
+		"targets":          report.Targets,
+		"deprecated":       report.Deprecated,
+	})
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDryRunReport(t *testing.T) {
+	state := NewFileStateService()
+	state.AddFileToUpload(types.UploadKey{RepoName: "org/b", BranchPath: "main"}, types.UploadFileContent{
+		Content:        []github.RepositoryContent{{Name: github.String("z.go")}, {Name: github.String("a.go")}},
+		CommitStrategy: types.CommitStrategy("pull_request"),
+		CommitMessage:  "Update examples",
+		PRTitle:        "Update examples",
+		DeletePaths:    []string{"old.go"},
+	})
+	state.AddFileToUpload(types.UploadKey{RepoName: "org/a", BranchPath: "main"}, types.UploadFileContent{
+		Content:        []github.RepositoryContent{{Name: github.String("x.go")}},
+		CommitStrategy: types.CommitStrategy("direct"),
+	})
+	state.AddFileToDeprecate("deprecated_examples.json", types.DeprecatedFileEntry{FileName: "gone.go", Repo: "org/a", Branch: "main"})
+
+	report := buildDryRunReport(types.Workflow{Name: "wf", Source: types.Source{Repo: "org/src"}}, 5, "abc", state)
+
+	assert.Equal(t, "wf", report.Workflow)
+	require.Len(t, report.Targets, 2)
+	assert.Equal(t, "org/a", report.Targets[0].Repo)
+	assert.Equal(t, []string{"x.go"}, report.Targets[0].Files)
+	assert.Equal(t, "org/b", report.Targets[1].Repo)
+	assert.Equal(t, "pull_request", report.Targets[1].CommitStrategy)
+	assert.Equal(t, []string{"a.go", "z.go"}, report.Targets[1].Files)
+	assert.Equal(t, []string{"old.go"}, report.Targets[1].Deletions)
+	assert.Equal(t, []DryRunDeprecation{{DeprecationFile: "deprecated_examples.json", File: "gone.go", Repo: "org/a", Branch: "main"}}, report.Deprecated)
+	assert.Equal(t, 4, report.FileCount())
+	assert.True(t, report.HasChanges())
+}
+
+func TestProcessFilesWithWorkflows_DryRunDoesNotQueue(t *testing.T) {
+	on := true
+	container, err := NewServiceContainer(&configs.Config{})
+	require.NoError(t, err)
+
+	yamlConfig := &types.YAMLConfig{Workflows: []types.Workflow{{
+		Name:        "dry",
+		Source:      types.Source{Repo: "org/src", Branch: "main"},
+		Destination: types.Destination{Repo: "org/dst", Branch: "main"},
+		Transformations: []types.Transformation{
+			{Move: &types.MoveTransform{From: "examples", To: "code"}},
+		},
+		DryRun: &on,
+	}}}
+	changedFiles := []types.ChangedFile{{Path: "examples/old.py", Status: "removed"}}
+
+	runs := processFilesWithWorkflows(context.Background(), 9, "abc", changedFiles, yamlConfig, container)
+
+	require.Len(t, runs, 1)
+	require.NotNil(t, runs[0].DryRun)
+	report := runs[0].DryRun
+	assert.Equal(t, "dry", report.Workflow)
+	require.Len(t, report.Deprecated, 1)
+	assert.Equal(t, "code/old.py", report.Deprecated[0].File)
+	assert.Empty(t, container.FileStateService.GetFilesToUpload())
+	assert.Empty(t, container.FileStateService.GetFilesToDeprecate())
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// EventSource converts the payloads of one kind of delivery into the CopyEvent the pipeline copies from.
+// The webhook handler and the admin trigger endpoint look sources up by name and run what they return, so
+// a new kind of trigger only needs a new source.
+type EventSource interface {
+	// Name identifies the source: the event type header value for webhook sources, like "push" for
+	// GitHub's X-GitHub-Event, or the path segment after /admin/trigger/ for trigger sources, like "manual"
+	Name() string
+	// Convert returns the event a payload triggers. Payloads that don't trigger a copy return a nil event
+	// and the reason, and invalid payloads return an *EventRejection.
+	Convert(ctx context.Context, payload []byte) (*CopyEvent, string, error)
+}
+
+// EventRejection is the error an event source returns for a payload it can't convert. It carries the
+// status and body the delivery is rejected with.
+type EventRejection struct {
+	Status   int
+	Response WebhookErrorResponse
+	Err      error // the underlying error, if any
+}
+
+func (e *EventRejection) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Response.Message, e.Err)
+	}
+	return e.Response.Message
+}
+
+func (e *EventRejection) Unwrap() error {
+	return e.Err
+}
+
+// invalidPayload returns the rejection for a payload that isn't valid JSON for its event
+func invalidPayload(err error) *EventRejection {
+	return &EventRejection{
+		Status:   http.StatusBadRequest,
+		Response: WebhookErrorResponse{Error: webhookErrInvalidPayload, Message: "failed to parse webhook payload"},
+		Err:      err,
+	}
+}
+
+// missingFields returns the rejection for a payload without fields the copier depends on
+func missingFields(event string, missing []string) *EventRejection {
+	return &EventRejection{
+		Status: http.StatusBadRequest,
+		Response: WebhookErrorResponse{
+			Error:         webhookErrMissingFields,
+			Message:       event + " payload is missing required fields",
+			MissingFields: missing,
+		},
+	}
+}
+
+// EventSources holds the registered event sources: webhook sources by platform and event type, and
+// trigger sources, which operators run through /admin/trigger/<name>
+type EventSources struct {
+	webhooks map[string]map[string]EventSource
+	triggers map[string]EventSource
+}
+
+// NewEventSources returns the built-in event sources: GitHub pull_request, push, workflow_run, release, and
+// workflow_dispatch events, GitHub issue_comment events when copy commands are enabled, GitLab merge request
+// and Bitbucket merged pull request events, and the manual and replay triggers
+func NewEventSources(config *configs.Config) *EventSources {
+	sources := &EventSources{
+		webhooks: make(map[string]map[string]EventSource),
+		triggers: make(map[string]EventSource),
+	}
+	sources.RegisterWebhook(types.SourcePlatformGitHub, pullRequestEventSource{copierLabel: config.CopierPRLabel})
+	sources.RegisterWebhook(types.SourcePlatformGitHub, pushEventSource{})
+	sources.RegisterWebhook(types.SourcePlatformGitHub, workflowRunEventSource{})
+	sources.RegisterWebhook(types.SourcePlatformGitHub, releaseEventSource{})
+	sources.RegisterWebhook(types.SourcePlatformGitHub, workflowDispatchEventSource{
+		copierLabel:    config.CopierPRLabel,
+		getPullRequest: getGitHubPullRequest,
+	})
+	if config.CopyCommand != "" {
+		sources.RegisterWebhook(types.SourcePlatformGitHub, issueCommentEventSource{
+			command:        config.CopyCommand,
+			copierLabel:    config.CopierPRLabel,
+			getPullRequest: getGitHubPullRequest,
+		})
+	}
+	sources.RegisterWebhook(types.SourcePlatformGitLab, gitlabMergeRequestEventSource{})
+	sources.RegisterWebhook(types.SourcePlatformBitbucket, bitbucketPullRequestEventSource{})
+	sources.RegisterTrigger(manualEventSource{})
+	sources.RegisterTrigger(replayEventSource{})
+	return sources
+}
+
+// RegisterWebhook registers a source for a platform's webhook deliveries of the event type it's named
+// for, replacing any source already registered for it
+func (s *EventSources) RegisterWebhook(platform string, source EventSource) {
+	if s.webhooks[platform] == nil {
+		s.webhooks[platform] = make(map[string]EventSource)
+	}
+	s.webhooks[platform][source.Name()] = source
+}
+
+// RegisterTrigger registers a source operators can run through the admin trigger endpoint, replacing
+// any source already registered with its name
+func (s *EventSources) RegisterTrigger(source EventSource) {
+	s.triggers[source.Name()] = source
+}
+
+// Webhook returns the source for a platform's event type, or nil if the copier doesn't handle the event
+func (s *EventSources) Webhook(platform string, eventType string) EventSource {
+	return s.webhooks[platform][eventType]
+}
+
+// Trigger returns the trigger source with the name, or nil if there isn't one
+func (s *EventSources) Trigger(name string) EventSource {
+	return s.triggers[name]
+}
+
+// TriggerNames returns the names of the trigger sources, sorted
+func (s *EventSources) TriggerNames() []string {
+	names := make([]string, 0, len(s.triggers))
+	for name := range s.triggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// convertEvent runs a source on a payload and sets the event's source. If the payload doesn't trigger a
+// copy, it records the delivery as ignored and responds 204 No Content; if the source rejects it, it
+// responds with the rejection. It returns the event to accept, or nil if a response was written.
+func convertEvent(ctx context.Context, w http.ResponseWriter, r *http.Request, source EventSource, payload []byte,
+	container *ServiceContainer) *CopyEvent {
+
+	event, reason, err := source.Convert(ctx, payload)
+	if err != nil {
+		var rejection *EventRejection
+		if !errors.As(err, &rejection) {
+			rejection = &EventRejection{
+				Status:   http.StatusBadRequest,
+				Response: WebhookErrorResponse{Error: webhookErrInvalidPayload, Message: err.Error()},
+				Err:      err,
+			}
+		}
+		rejectWebhook(ctx, w, r, container, rejection.Status, rejection.Response, rejection.Err)
+		return nil
+	}
+	if event == nil {
+		container.MetricsCollector.RecordWebhookIgnored(source.Name())
+		LogInfoCtx(ctx, "event doesn't trigger a copy", map[string]interface{}{
+			"source": source.Name(),
+			"reason": reason,
+		})
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	event.Source = source.Name()
+	return event
+}
+
+// TriggerHandler runs trigger sources for operators: POST /admin/trigger/<name> converts the request body
+// with the named source and processes the event like a webhook delivery
+func TriggerHandler(config *configs.Config, container *ServiceContainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validAdminToken(r, config.AdminToken) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/trigger"), "/")
+		source := container.EventSources.Trigger(name)
+		if source == nil {
+			http.Error(w, fmt.Sprintf("unknown trigger %q; use one of: %s", name,
+				strings.Join(container.EventSources.TriggerNames(), ", ")), http.StatusNotFound)
+			return
+		}
+
+		payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+		if err != nil {
+			rejectWebhook(r.Context(), w, r, container, http.StatusBadRequest, WebhookErrorResponse{
+				Error:   webhookErrInvalidBody,
+				Message: "invalid body",
+			}, err)
+			return
+		}
+
+		event := convertEvent(r.Context(), w, r, source, payload, container)
+		if event == nil {
+			return
+		}
+		LogInfoCtx(r.Context(), "processing triggered event", map[string]interface{}{
+			"source":   event.Source,
+			"platform": event.Platform,
+			"repo":     event.Repo,
+			"trigger":  event.trigger(),
+			"sha":      event.CommitSHA,
+		})
+		acceptCopyEvent(r.Context(), w, r, *event, config, container)
+	}
+}
+
+// ManualTrigger is the body of a manual trigger: a merged GitHub PR, or a range of commits pushed to a
+// GitHub branch, to copy from as if its webhook had just been delivered
+type ManualTrigger struct {
+	Repo      string `json:"repo"`                 // "owner/name"
+	PRNumber  int    `json:"pr_number,omitempty"`  // a merged PR, for workflows with the pr_merged trigger
+	Branch    string `json:"branch,omitempty"`     // the branch pushed to, for workflows with the push trigger
+	BeforeSHA string `json:"before_sha,omitempty"` // the branch's commit before the push
+	CommitSHA string `json:"commit_sha,omitempty"` // the branch's commit after the push
+}
+
+// manualEventSource converts manual triggers. PRs are looked up on GitHub and must be merged.
+type manualEventSource struct{}
+
+func (manualEventSource) Name() string { return "manual" }
+
+func (manualEventSource) Convert(ctx context.Context, payload []byte) (*CopyEvent, string, error) {
+	var trigger ManualTrigger
+	if err := json.Unmarshal(payload, &trigger); err != nil {
+		return nil, "", invalidPayload(err)
+	}
+	owner, name, ok := strings.Cut(trigger.Repo, "/")
+	if !ok || owner == "" || name == "" {
+		return nil, "", missingFields("manual trigger", []string{"repo"})
+	}
+
+	if trigger.PRNumber == 0 {
+		var missing []string
+		for field, value := range map[string]string{
+			"branch": trigger.Branch, "before_sha": trigger.BeforeSHA, "commit_sha": trigger.CommitSHA,
+		} {
+			if value == "" {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return nil, "", missingFields("manual trigger without a pr_number", missing)
+		}
+		return &CopyEvent{
+			Platform:   types.SourcePlatformGitHub,
+			Repo:       trigger.Repo,
+			CommitSHA:  trigger.CommitSHA,
+			BaseBranch: trigger.Branch,
+			URL:        fmt.Sprintf("https://github.com/%s/compare/%s...%s", trigger.Repo, trigger.BeforeSHA, trigger.CommitSHA),
+			Trigger:    types.WorkflowTriggerPush,
+			BeforeSHA:  trigger.BeforeSHA,
+		}, "", nil
+	}
+
+	pr, _, err := GetRestClient().PullRequests.Get(ctx, owner, name, trigger.PRNumber)
+	if err != nil {
+		return nil, "", &EventRejection{
+			Status:   http.StatusBadGateway,
+			Response: WebhookErrorResponse{Error: webhookErrInvalidPayload, Message: "failed to look up pull request"},
+			Err:      err,
+		}
+	}
+	if !pr.GetMerged() {
+		return nil, "", &EventRejection{
+			Status: http.StatusBadRequest,
+			Response: WebhookErrorResponse{
+				Error:   webhookErrInvalidPayload,
+				Message: fmt.Sprintf("pull request #%d isn't merged", trigger.PRNumber),
+			},
+		}
+	}
+	return &CopyEvent{
+		Platform:   types.SourcePlatformGitHub,
+		Repo:       trigger.Repo,
+		Number:     pr.GetNumber(),
+		CommitSHA:  pr.GetMergeCommitSHA(),
+		BaseBranch: pr.GetBase().GetRef(),
+		URL:        pr.GetHTMLURL(),
+		Title:      pr.GetTitle(),
+		Author:     pr.GetUser().GetLogin(),
+	}, "", nil
+}
+
+// replayEventSource replays a CopyEvent as it was recorded, like a line of the maintenance queue. The
+// event's correlation ID is dropped so the replay is logged under its own.
+type replayEventSource struct{}
+
+func (replayEventSource) Name() string { return "replay" }
+
+func (replayEventSource) Convert(ctx context.Context, payload []byte) (*CopyEvent, string, error) {
+	var event CopyEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, "", invalidPayload(err)
+	}
+	var missing []string
+	if event.Platform == "" {
+		missing = append(missing, "platform")
+	}
+	if event.Repo == "" {
+		missing = append(missing, "repo")
+	}
+	if event.CommitSHA == "" && event.trigger() != types.WorkflowTriggerRelease {
+		missing = append(missing, "commit_sha")
+	}
+	if event.BaseBranch == "" {
+		missing = append(missing, "base_branch")
+	}
+	if len(missing) > 0 {
+		return nil, "", missingFields("replayed event", missing)
+	}
+	event.CorrelationID = ""
+	return &event, "", nil
+}
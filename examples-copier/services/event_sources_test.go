@@ -0,0 +1,180 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubEventSource converts every payload to the same result
+type stubEventSource struct {
+	name   string
+	event  *CopyEvent
+	reason string
+	err    error
+}
+
+func (s stubEventSource) Name() string { return s.name }
+
+func (s stubEventSource) Convert(ctx context.Context, payload []byte) (*CopyEvent, string, error) {
+	return s.event, s.reason, s.err
+}
+
+func TestNewEventSources_RegistersBuiltInSources(t *testing.T) {
+	sources := NewEventSources(&configs.Config{})
+
+	for _, eventType := range []string{"pull_request", "push", "workflow_run", "release", "workflow_dispatch"} {
+		assert.NotNil(t, sources.Webhook(types.SourcePlatformGitHub, eventType), eventType)
+	}
+	assert.NotNil(t, sources.Webhook(types.SourcePlatformGitLab, gitlabMergeRequestEventType))
+	assert.NotNil(t, sources.Webhook(types.SourcePlatformBitbucket, bitbucketPullRequestMergedEventType))
+	assert.Nil(t, sources.Webhook(types.SourcePlatformGitHub, "issues"))
+	assert.Nil(t, sources.Webhook(types.SourcePlatformGitLab, "push"), "GitHub sources aren't registered for GitLab")
+	assert.Equal(t, []string{"manual", "replay"}, sources.TriggerNames())
+}
+
+func TestHandleWebhook_UsesRegisteredSource(t *testing.T) {
+	config, container := newMaintenanceTestContainer(t, true)
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/webhook", bytes.NewReader([]byte(`{}`)))
+		req.Header.Set("X-GitHub-Event", "issue_comment")
+		w := httptest.NewRecorder()
+		HandleWebhookWithContainer(w, req, config, container)
+		return w
+	}
+
+	// Without a source, the event is ignored
+	assert.Equal(t, http.StatusNoContent, send().Code)
+
+	container.EventSources.RegisterWebhook(types.SourcePlatformGitHub, stubEventSource{name: "issue_comment", reason: "not a command"})
+	assert.Equal(t, http.StatusNoContent, send().Code)
+
+	container.EventSources.RegisterWebhook(types.SourcePlatformGitHub, stubEventSource{
+		name: "issue_comment",
+		err:  missingFields("issue_comment", []string{"issue.number"}),
+	})
+	w := send()
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var resp WebhookErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, webhookErrMissingFields, resp.Error)
+	assert.Equal(t, []string{"issue.number"}, resp.MissingFields)
+
+	container.EventSources.RegisterWebhook(types.SourcePlatformGitHub, stubEventSource{
+		name:  "issue_comment",
+		event: &CopyEvent{Platform: types.SourcePlatformGitHub, Repo: "org/src", Number: 42, CommitSHA: "abc123", BaseBranch: "main"},
+	})
+	w = send()
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	queued, err := container.Maintenance.queued(t.Context())
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	assert.Equal(t, "issue_comment", queued[0].Source)
+	assert.Equal(t, 42, queued[0].Number)
+}
+
+func TestManualEventSource_Push(t *testing.T) {
+	event, _, err := manualEventSource{}.Convert(context.Background(),
+		[]byte(`{"repo": "org/src", "branch": "main", "before_sha": "aaa", "commit_sha": "bbb"}`))
+	require.NoError(t, err)
+	assert.Equal(t, &CopyEvent{
+		Platform:   types.SourcePlatformGitHub,
+		Repo:       "org/src",
+		CommitSHA:  "bbb",
+		BaseBranch: "main",
+		URL:        "https://github.com/org/src/compare/aaa...bbb",
+		Trigger:    types.WorkflowTriggerPush,
+		BeforeSHA:  "aaa",
+	}, event)
+}
+
+func TestManualEventSource_RejectsIncompleteTriggers(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		missing []string
+	}{
+		{name: "no repo", payload: `{"pr_number": 1}`, missing: []string{"repo"}},
+		{name: "repo without owner", payload: `{"repo": "src", "pr_number": 1}`, missing: []string{"repo"}},
+		{name: "push without commits", payload: `{"repo": "org/src", "branch": "main"}`, missing: []string{"before_sha", "commit_sha"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, _, err := manualEventSource{}.Convert(context.Background(), []byte(tt.payload))
+			assert.Nil(t, event)
+			var rejection *EventRejection
+			require.ErrorAs(t, err, &rejection)
+			assert.Equal(t, tt.missing, rejection.Response.MissingFields)
+		})
+	}
+
+	_, _, err := manualEventSource{}.Convert(context.Background(), []byte(`{not json`))
+	var rejection *EventRejection
+	require.ErrorAs(t, err, &rejection)
+	assert.Equal(t, webhookErrInvalidPayload, rejection.Response.Error)
+}
+
+func TestReplayEventSource(t *testing.T) {
+	recorded := CopyEvent{
+		Platform:      types.SourcePlatformGitLab,
+		Repo:          "docs/examples/python",
+		Number:        7,
+		CommitSHA:     "abc123",
+		BaseBranch:    "main",
+		Source:        gitlabMergeRequestEventType,
+		CorrelationID: "original-delivery",
+	}
+	payload, err := json.Marshal(recorded)
+	require.NoError(t, err)
+
+	event, _, err := replayEventSource{}.Convert(context.Background(), payload)
+	require.NoError(t, err)
+	want := recorded
+	want.CorrelationID = ""
+	assert.Equal(t, &want, event)
+
+	_, _, err = replayEventSource{}.Convert(context.Background(), []byte(`{"platform": "github", "trigger": "release"}`))
+	var rejection *EventRejection
+	require.ErrorAs(t, err, &rejection)
+	assert.Equal(t, []string{"repo", "base_branch"}, rejection.Response.MissingFields)
+}
+
+func TestTriggerHandler(t *testing.T) {
+	config, container := newMaintenanceTestContainer(t, true)
+	handler := TriggerHandler(config, container)
+
+	send := func(method string, path string, body string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("POST", "/admin/trigger/manual", `{}`, "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, send("GET", "/admin/trigger/manual", "", "admin-token").Code)
+	w := send("POST", "/admin/trigger/unknown", `{}`, "admin-token")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "manual, replay")
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/admin/trigger/replay", `{}`, "admin-token").Code)
+
+	w = send("POST", "/admin/trigger/manual", `{"repo": "org/src", "branch": "main", "before_sha": "aaa", "commit_sha": "bbb"}`, "admin-token")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	queued, err := container.Maintenance.queued(t.Context())
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	assert.Equal(t, "manual", queued[0].Source)
+	assert.Equal(t, types.WorkflowTriggerPush, queued[0].Trigger)
+	assert.Equal(t, "bbb", queued[0].CommitSHA)
+}
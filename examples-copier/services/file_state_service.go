@@ -12,8 +12,15 @@ type FileStateService interface {
 	GetFilesToDeprecate() map[string]types.DeprecatedFileEntry
 	AddFileToUpload(key types.UploadKey, content types.UploadFileContent)
 	AddFileToDeprecate(file string, entry types.DeprecatedFileEntry)
+	RemoveFileToUpload(key types.UploadKey)
 	ClearFilesToUpload()
 	ClearFilesToDeprecate()
+	QueueSizes() (uploads int, deprecations int)
+}
+
+// FileQueues reports how many uploads and deprecations are staged
+type FileQueues interface {
+	QueueSizes() (uploads int, deprecations int)
 }
 
 // DefaultFileStateService implements FileStateService with thread-safe operations
@@ -73,6 +80,14 @@ func (fss *DefaultFileStateService) AddFileToDeprecate(file string, entry types.
 	fss.filesToDeprecate[file] = entry
 }
 
+// RemoveFileToUpload removes the upload queued for a key
+func (fss *DefaultFileStateService) RemoveFileToUpload(key types.UploadKey) {
+	fss.mu.Lock()
+	defer fss.mu.Unlock()
+
+	delete(fss.filesToUpload, key)
+}
+
 // ClearFilesToUpload clears the files to upload map
 func (fss *DefaultFileStateService) ClearFilesToUpload() {
 	fss.mu.Lock()
@@ -89,3 +104,63 @@ func (fss *DefaultFileStateService) ClearFilesToDeprecate() {
 	fss.filesToDeprecate = make(map[string]types.DeprecatedFileEntry)
 }
 
+
+// QueueSizes returns how many uploads and deprecations are staged
+func (fss *DefaultFileStateService) QueueSizes() (int, int) {
+	fss.mu.RLock()
+	defer fss.mu.RUnlock()
+
+	return len(fss.filesToUpload), len(fss.filesToDeprecate)
+}
+
+// FileStateRegistry tracks the file state of each run in progress. Runs stage files in states of their
+// own, so the registry is what reports the upload and deprecation queues.
+type FileStateRegistry struct {
+	mu     sync.Mutex
+	states map[FileStateService]struct{}
+}
+
+// NewFileStateRegistry creates a registry with no runs in progress
+func NewFileStateRegistry() *FileStateRegistry {
+	return &FileStateRegistry{states: make(map[FileStateService]struct{})}
+}
+
+// Open returns an empty file state for a run, tracked until it's closed. A nil registry returns one that
+// isn't tracked.
+func (r *FileStateRegistry) Open() FileStateService {
+	state := NewFileStateService()
+	if r == nil {
+		return state
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[state] = struct{}{}
+	return state
+}
+
+// Close stops tracking a run's file state once the run is done with it
+func (r *FileStateRegistry) Close(state FileStateService) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.states, state)
+}
+
+// QueueSizes sums the uploads and deprecations staged by the runs in progress
+func (r *FileStateRegistry) QueueSizes() (uploads int, deprecations int) {
+	r.mu.Lock()
+	states := make([]FileStateService, 0, len(r.states))
+	for state := range r.states {
+		states = append(states, state)
+	}
+	r.mu.Unlock()
+
+	for _, state := range states {
+		u, d := state.QueueSizes()
+		uploads += u
+		deprecations += d
+	}
+	return uploads, deprecations
+}
@@ -3,13 +3,13 @@ package services
 import (
 	"context"
 	"crypto/rsa"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	secretmanager "cloud.google.com/go/secretmanager/apiv1"
@@ -27,19 +27,24 @@ type transport struct {
 	token string
 }
 
-var InstallationAccessToken string
 var HTTPClient = http.DefaultClient
 
-// installationTokenCache caches installation access tokens by organization name
+// installationTokenCache caches installation access tokens by organization name. The default
+// installation's token is cached under "".
 var installationTokenCache = make(map[string]string)
 
-// jwtToken caches the GitHub App JWT token
-var jwtToken string
-var jwtExpiry time.Time
+// cachedJWT is a GitHub App JWT and when to stop using it
+type cachedJWT struct {
+	token  string
+	expiry time.Time
+}
+
+// jwtCache caches each GitHub App's JWT by App ID
+var jwtCache = make(map[string]cachedJWT)
+var jwtCacheMu sync.Mutex
 
-// ConfigurePermissions sets up the necessary permissions to interact with the GitHub API.
-// It retrieves the GitHub App's private key from Google Secret Manager, generates a JWT,
-// and exchanges it for an installation access token.
+// ConfigurePermissions loads the environment and issues a new installation access token for the default
+// installation, exiting if it can't. Tokens for other installations are issued when first used.
 func ConfigurePermissions() {
 	envFilePath := os.Getenv("ENV_FILE")
 
@@ -49,23 +54,16 @@ func ConfigurePermissions() {
 
 	}
 
-	pemKey := getPrivateKeyFromSecret()
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemKey)
-	if err != nil {
-		log.Fatal(errors.Wrap(err, "Unable to parse RSA private key"))
-	}
-
-	// Generate JWT — use the numeric GitHub App ID (GITHUB_APP_ID) as "iss"
-	token, err := generateGitHubJWT(os.Getenv(configs.AppId), privateKey)
-	if err != nil {
-		log.Fatal(errors.Wrap(err, "Error generating JWT"))
-	}
-
-	installationToken, err := getInstallationAccessToken("", token, HTTPClient)
+	installationToken, expiresAt, err := issueInstallationTokenForOrg("")
 	if err != nil {
+		recordTokenFailure(defaultInstallation, err)
 		log.Fatal(errors.Wrap(err, "Error getting installation access token"))
 	}
-	InstallationAccessToken = installationToken
+	tokenCacheMu.Lock()
+	installationTokenCache[""] = installationToken
+	installationTokenExpiry[""] = expiresAt
+	tokenCacheMu.Unlock()
+	recordTokenIssued(defaultInstallation, expiresAt, tokenReasonMissing)
 }
 
 // generateGitHubJWT creates a JWT for GitHub App authentication.
@@ -86,45 +84,6 @@ func generateGitHubJWT(appID string, privateKey *rsa.PrivateKey) (string, error)
 	return signedToken, nil
 }
 
-// getPrivateKeyFromSecret retrieves the GitHub App's private key from Google Secret Manager.
-// It supports local testing by allowing the key to be provided via environment variables.
-func getPrivateKeyFromSecret() []byte {
-	if os.Getenv("SKIP_SECRET_MANAGER") == "true" { // for tests and local runs
-		if pem := os.Getenv("GITHUB_APP_PRIVATE_KEY"); pem != "" {
-			return []byte(pem)
-		}
-		if b64 := os.Getenv("GITHUB_APP_PRIVATE_KEY_B64"); b64 != "" {
-			dec, err := base64.StdEncoding.DecodeString(b64)
-			if err != nil {
-				log.Fatalf("Invalid base64 private key: %v", err)
-			}
-			return dec
-		}
-		log.Fatalf("SKIP_SECRET_MANAGER=true but no GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_B64 set")
-	}
-	ctx := context.Background()
-	client, err := secretmanager.NewClient(ctx)
-
-	if err != nil {
-		log.Fatalf("Failed to create Secret Manager client: %v", err)
-	}
-	defer client.Close()
-
-	secretName := os.Getenv(configs.PEMKeyName)
-	if secretName == "" {
-		secretName = configs.NewConfig().PEMKeyName
-	}
-
-	req := &secretmanagerpb.AccessSecretVersionRequest{
-		Name: secretName,
-	}
-	result, err := client.AccessSecretVersion(ctx, req)
-	if err != nil {
-		log.Fatalf("Failed to access secret version: %v", err)
-	}
-	return result.Payload.Data
-}
-
 // getWebhookSecretFromSecretManager retrieves the webhook secret from Google Cloud Secret Manager
 func getWebhookSecretFromSecretManager(secretName string) (string, error) {
 	if os.Getenv("SKIP_SECRET_MANAGER") == "true" {
@@ -218,17 +177,24 @@ func getSecretFromSecretManager(secretName, envVarName string) (string, error) {
 
 // getInstallationAccessToken exchanges a JWT for a GitHub App installation access token.
 func getInstallationAccessToken(installationId, jwtToken string, hc *http.Client) (string, error) {
+	token, _, err := requestInstallationToken(gitHubBaseURLsForOrg(""), installationId, jwtToken, hc)
+	return token, err
+}
+
+// requestInstallationToken exchanges a JWT for a GitHub App installation access token from the instance at
+// urls and returns the token with the time it expires.
+func requestInstallationToken(urls gitHubBaseURLs, installationId, jwtToken string, hc *http.Client) (string, time.Time, error) {
 	if installationId == "" || installationId == configs.InstallationId {
 		installationId = os.Getenv(configs.InstallationId)
 	}
 	if installationId == "" {
-		return "", fmt.Errorf("missing installation ID")
+		return "", time.Time{}, fmt.Errorf("missing installation ID")
 	}
 
-	url := fmt.Sprintf("https://api.github.com/app/installations/%s/access_tokens", installationId)
+	url := urls.apiURL(fmt.Sprintf("app/installations/%s/access_tokens", installationId))
 	req, err := http.NewRequest("POST", url, nil)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return "", time.Time{}, fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+jwtToken)
 	req.Header.Set("Accept", "application/vnd.github+json")
@@ -238,85 +204,107 @@ func getInstallationAccessToken(installationId, jwtToken string, hc *http.Client
 	}
 	resp, err := hc.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("execute request: %w", err)
+		return "", time.Time{}, fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
 		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("status %d: %s", resp.StatusCode, string(b))
+		return "", time.Time{}, fmt.Errorf("status %d: %s", resp.StatusCode, string(b))
 	}
 	var out struct {
-		Token string `json:"token"`
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
 	}
 	if err = json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return "", fmt.Errorf("decode: %w", err)
+		return "", time.Time{}, fmt.Errorf("decode: %w", err)
 	}
-	return out.Token, nil
+	return out.Token, out.ExpiresAt, nil
 }
 
-// GetRestClient returns a GitHub REST API client authenticated with the installation access token.
+// GetRestClient returns a GitHub REST API client authenticated as the default installation. Its token is
+// issued when first needed and refreshed when it nears expiry.
 func GetRestClient() *github.Client {
-	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: InstallationAccessToken})
+	return newRestClient(orgTokenSource{})
+}
 
+// newRestClient returns a GitHub REST API client that authenticates each request with a token from src
+func newRestClient(src oauth2.TokenSource) *github.Client {
 	base := http.DefaultTransport
 	if HTTPClient != nil && HTTPClient.Transport != nil {
 		base = HTTPClient.Transport
 	}
 
+	installation := defaultInstallation
+	urls := gitHubBaseURLsForOrg("")
+	if orgSource, ok := src.(orgTokenSource); ok {
+		installation = installationName(orgSource.org)
+		urls = gitHubBaseURLsForOrg(orgSource.org)
+	}
+
 	httpClient := &http.Client{
 		Transport: &oauth2.Transport{
 			Source: src,
-			Base:   base,
+			Base: &rateLimitTransport{
+				installation: installation,
+				base:         &metricsTransport{base: &correlationTransport{base: base}},
+			},
 		},
 	}
-	return github.NewClient(httpClient)
+	client, err := urls.newClient(httpClient)
+	if err != nil {
+		// LoadEnvironment rejects URLs that can't be parsed, so this only happens if the environment changed since
+		log.Printf("Invalid GitHub Enterprise Server base URLs for %s, using github.com: %v", installation, err)
+		return github.NewClient(httpClient)
+	}
+	return client
 }
 
+// GetGraphQLClient returns a GitHub GraphQL API client authenticated as the default installation
 func GetGraphQLClient() *graphql.Client {
-	if InstallationAccessToken == "" {
-		ConfigurePermissions()
+	token, err := installationTokenForOrg("")
+	if err != nil {
+		// The request fails with a 401 without a token
+		LogWarning(fmt.Sprintf("No installation token for GraphQL requests: %v", err))
 	}
-	client := graphql.NewClient("https://api.github.com/graphql", &http.Client{
-		Transport: &transport{token: InstallationAccessToken},
+	client := graphql.NewClient(gitHubBaseURLsForOrg("").graphQLURL(), &http.Client{
+		Transport: &rateLimitTransport{
+			installation: defaultInstallation,
+			base:         &correlationTransport{base: &transport{token: token}},
+		},
 	})
 	return client
 }
 
-// getOrRefreshJWT returns a valid JWT token, generating a new one if expired
-func getOrRefreshJWT() (string, error) {
-	// Check if we have a valid cached JWT
-	if jwtToken != "" && time.Now().Before(jwtExpiry) {
-		return jwtToken, nil
-	}
+// getOrRefreshJWT returns a valid JWT for the App the credentials are for, generating a new one if the
+// cached one has expired
+func getOrRefreshJWT(creds *AppCredentials) (string, error) {
+	jwtCacheMu.Lock()
+	defer jwtCacheMu.Unlock()
 
-	// Generate new JWT
-	pemKey := getPrivateKeyFromSecret()
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemKey)
-	if err != nil {
-		return "", fmt.Errorf("unable to parse RSA private key: %w", err)
+	// Check if we have a valid cached JWT
+	if cached, ok := jwtCache[creds.AppID]; ok && time.Now().Before(cached.expiry) {
+		return cached.token, nil
 	}
 
-	token, err := generateGitHubJWT(os.Getenv(configs.AppId), privateKey)
+	// Generate JWT — use the numeric GitHub App ID as "iss"
+	token, err := generateGitHubJWT(creds.AppID, creds.PrivateKey)
 	if err != nil {
 		return "", fmt.Errorf("error generating JWT: %w", err)
 	}
 
 	// Cache the JWT (expires in 10 minutes, cache for 9 to be safe)
-	jwtToken = token
-	jwtExpiry = time.Now().Add(9 * time.Minute)
+	expiry := time.Now().Add(9 * time.Minute)
+	jwtCache[creds.AppID] = cachedJWT{token: token, expiry: expiry}
+	recordJWTIssued(creds.AppID, expiry)
 
 	return token, nil
 }
 
-// getInstallationIDForOrg retrieves the installation ID for a specific organization
-func getInstallationIDForOrg(org string) (string, error) {
-	token, err := getOrRefreshJWT()
-	if err != nil {
-		return "", fmt.Errorf("failed to get JWT: %w", err)
-	}
-
-	url := "https://api.github.com/app/installations"
+// getInstallationIDForOrg retrieves the installation ID for a specific organization from the installations
+// of the App the JWT is for
+func getInstallationIDForOrg(org string, token string) (string, error) {
+	url := gitHubBaseURLsForOrg(org).apiURL("app/installations")
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return "", fmt.Errorf("create request: %w", err)
@@ -362,64 +350,55 @@ func getInstallationIDForOrg(org string) (string, error) {
 	return "", fmt.Errorf("no installation found for organization: %s", org)
 }
 
-// SetInstallationTokenForOrg sets a cached installation token for an organization.
-// This is primarily used for testing to bypass the GitHub App authentication flow.
+// SetInstallationTokenForOrg sets a cached installation token for an organization, or for the default
+// installation if org is empty. This is primarily used for testing to bypass the GitHub App authentication
+// flow. Tokens set this way have no expiry and are never refreshed.
 func SetInstallationTokenForOrg(org, token string) {
+	tokenCacheMu.Lock()
+	defer tokenCacheMu.Unlock()
 	installationTokenCache[org] = token
+	delete(installationTokenExpiry, org)
 }
 
-// GetRestClientForOrg returns a GitHub REST API client authenticated for a specific organization
+// GetRestClientForOrg returns a GitHub REST API client authenticated for a specific organization.
+// The client refreshes the org's installation token when it nears expiry, so it can be used for
+// upload sequences that outlast a single token.
 func GetRestClientForOrg(org string) (*github.Client, error) {
-	// Check if we have a cached token for this org
-	if token, ok := installationTokenCache[org]; ok && token != "" {
-		src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-		base := http.DefaultTransport
-		if HTTPClient != nil && HTTPClient.Transport != nil {
-			base = HTTPClient.Transport
-		}
-		httpClient := &http.Client{
-			Transport: &oauth2.Transport{
-				Source: src,
-				Base:   base,
-			},
-		}
-		return github.NewClient(httpClient), nil
+	// Load the token now so a client isn't returned for an org the app can't authenticate to
+	if _, err := installationTokenForOrg(org); err != nil {
+		return nil, err
 	}
+	return newRestClient(orgTokenSource{org: org}), nil
+}
 
-	// Get installation ID for the organization
-	installationID, err := getInstallationIDForOrg(org)
+// issueInstallationTokenForOrg requests a new installation access token for an organization, or for the
+// default installation if org is empty, as the GitHub App the credential store has for it
+func issueInstallationTokenForOrg(org string) (string, time.Time, error) {
+	creds, err := getCredentialStore().Credentials(org)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get installation ID for org %s: %w", org, err)
+		return "", time.Time{}, err
 	}
 
 	// Get JWT token
-	token, err := getOrRefreshJWT()
+	token, err := getOrRefreshJWT(creds)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get JWT: %w", err)
+		return "", time.Time{}, fmt.Errorf("failed to get JWT: %w", err)
 	}
 
-	// Get installation access token
-	installationToken, err := getInstallationAccessToken(installationID, token, HTTPClient)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get installation token for org %s: %w", org, err)
+	// Get installation ID for the organization, unless it's configured
+	installationID := creds.InstallationID
+	if installationID == "" && org != "" {
+		if installationID, err = getInstallationIDForOrg(org, token); err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to get installation ID for org %s: %w", org, err)
+		}
 	}
 
-	// Cache the token
-	installationTokenCache[org] = installationToken
-
-	// Create and return client
-	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: installationToken})
-	base := http.DefaultTransport
-	if HTTPClient != nil && HTTPClient.Transport != nil {
-		base = HTTPClient.Transport
-	}
-	httpClient := &http.Client{
-		Transport: &oauth2.Transport{
-			Source: src,
-			Base:   base,
-		},
+	// Get installation access token
+	installationToken, expiresAt, err := requestInstallationToken(gitHubBaseURLsForOrg(org), installationID, token, HTTPClient)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get installation token for %s: %w", installationName(org), err)
 	}
-	return github.NewClient(httpClient), nil
+	return installationToken, expiresAt, nil
 }
 
 // RoundTrip adds the Authorization header to each request.
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
 	"os"
 	"testing"
 	"time"
@@ -22,22 +24,28 @@ func TestGenerateGitHubJWT_EmptyAppID(t *testing.T) {
 
 func TestJWTCaching(t *testing.T) {
 	// Test JWT caching behavior
-	originalToken := jwtToken
-	originalExpiry := jwtExpiry
+	originalCache := jwtCache
 	defer func() {
-		jwtToken = originalToken
-		jwtExpiry = originalExpiry
+		jwtCache = originalCache
 	}()
+	jwtCache = make(map[string]cachedJWT)
 
-	// Set a cached token that hasn't expired
-	jwtToken = "cached-token"
-	jwtExpiry = time.Now().Add(5 * time.Minute)
+	// A cached token that hasn't expired is returned without needing the private key
+	jwtCache["123"] = cachedJWT{token: "cached-token", expiry: time.Now().Add(5 * time.Minute)}
+	token, err := getOrRefreshJWT(&AppCredentials{AppID: "123"})
+	if err != nil || token != "cached-token" {
+		t.Errorf("getOrRefreshJWT() = %q, %v, want cached-token", token, err)
+	}
 
-	// Note: getOrRefreshJWT is not exported, so we can't test it directly
-	// This test documents the expected caching behavior:
-	// - If jwtToken is set and jwtExpiry is in the future, return cached token
-	// - If jwtToken is empty or jwtExpiry is in the past, generate new token
-	// - Cache the new token and set expiry to 9 minutes from now
+	// Each App has its own JWT
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	token, err = getOrRefreshJWT(&AppCredentials{AppID: "456", PrivateKey: key})
+	if err != nil || token == "cached-token" {
+		t.Errorf("getOrRefreshJWT() for another App = %q, %v", token, err)
+	}
+	if cached := jwtCache["456"]; cached.token != token || !cached.expiry.After(time.Now().Add(8*time.Minute)) {
+		t.Errorf("jwtCache[456] = %+v, want the new token cached for 9 minutes", cached)
+	}
 }
 
 func TestInstallationTokenCache_Structure(t *testing.T) {
@@ -142,18 +150,20 @@ func TestGitHubPrivateKeyPath_FromEnv(t *testing.T) {
 	}
 }
 
-func TestInstallationAccessToken_GlobalVariable(t *testing.T) {
-	// Test that we can manipulate the global InstallationAccessToken
-	originalToken := InstallationAccessToken
+func TestInstallationTokenCache_DefaultInstallation(t *testing.T) {
+	// The default installation's token is cached under the empty org
+	originalCache := installationTokenCache
 	defer func() {
-		InstallationAccessToken = originalToken
+		installationTokenCache = originalCache
 	}()
+	installationTokenCache = make(map[string]string)
 
 	testToken := "ghs_test_token_123"
-	InstallationAccessToken = testToken
+	SetInstallationTokenForOrg("", testToken)
 
-	if InstallationAccessToken != testToken {
-		t.Errorf("InstallationAccessToken = %s, want %s", InstallationAccessToken, testToken)
+	token, err := installationTokenForOrg("")
+	if err != nil || token != testToken {
+		t.Errorf("installationTokenForOrg(\"\") = %q, %v, want %s", token, err, testToken)
 	}
 }
 
@@ -168,27 +178,21 @@ func TestHTTPClient_GlobalVariable(t *testing.T) {
 	// The test just verifies the client exists
 }
 
-func TestJWTExpiry_GlobalVariable(t *testing.T) {
-	// Test that we can manipulate the JWT expiry time
-	originalExpiry := jwtExpiry
+func TestJWTExpiry_Refreshed(t *testing.T) {
+	// An expired JWT is replaced
+	originalCache := jwtCache
 	defer func() {
-		jwtExpiry = originalExpiry
+		jwtCache = originalCache
 	}()
+	jwtCache = map[string]cachedJWT{"123": {token: "expired-token", expiry: time.Now().Add(-1 * time.Hour)}}
 
-	// Set a future expiry
-	futureExpiry := time.Now().Add(1 * time.Hour)
-	jwtExpiry = futureExpiry
-
-	if time.Now().After(jwtExpiry) {
-		t.Error("JWT should not be expired")
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	token, err := getOrRefreshJWT(&AppCredentials{AppID: "123", PrivateKey: key})
+	if err != nil {
+		t.Fatalf("getOrRefreshJWT() error = %v", err)
 	}
-
-	// Set a past expiry
-	pastExpiry := time.Now().Add(-1 * time.Hour)
-	jwtExpiry = pastExpiry
-
-	if !time.Now().After(jwtExpiry) {
-		t.Error("JWT should be expired")
+	if token == "expired-token" {
+		t.Error("JWT should have been refreshed")
 	}
 }
 
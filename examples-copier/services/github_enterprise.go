@@ -0,0 +1,89 @@
+package services
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+)
+
+// gitHubDotComAPIURL is the REST API base URL for github.com
+const gitHubDotComAPIURL = "https://api.github.com/"
+
+// gitHubBaseURLs are the REST API and upload base URLs of the GitHub instance an installation is on. Both
+// are empty for github.com.
+type gitHubBaseURLs struct {
+	API    string
+	Upload string
+}
+
+// gitHubBaseURLsForOrg returns the base URLs for the installation of org, or for the default installation
+// (INSTALLATION_ID) if org is empty. Orgs in GITHUB_ORG_API_BASE_URLS use their own URLs; other orgs are on
+// the same instance as the default installation, set with GITHUB_API_BASE_URL.
+func gitHubBaseURLsForOrg(org string) gitHubBaseURLs {
+	api := os.Getenv(configs.GitHubAPIBaseURL)
+	upload := os.Getenv(configs.GitHubUploadBaseURL)
+	if org != "" {
+		// LoadEnvironment rejects malformed lists, so errors here only leave the defaults in place
+		orgAPIURLs, _ := configs.ParseOrgURLs(os.Getenv(configs.GitHubOrgAPIBaseURLs))
+		orgUploadURLs, _ := configs.ParseOrgURLs(os.Getenv(configs.GitHubOrgUploadBaseURLs))
+		if orgAPI, ok := orgAPIURLs[org]; ok {
+			api, upload = orgAPI, orgUploadURLs[org]
+		}
+	}
+	return newGitHubBaseURLs(api, upload)
+}
+
+// newGitHubBaseURLs normalizes the base URLs of a GitHub Enterprise Server instance the way
+// github.NewEnterpriseClient does: "/api/v3/" is added to an API URL without it, and an empty upload URL
+// is the instance's "/api/uploads/". An empty API URL is github.com.
+func newGitHubBaseURLs(api string, upload string) gitHubBaseURLs {
+	api = strings.TrimSpace(api)
+	if api == "" || strings.TrimSuffix(api, "/")+"/" == gitHubDotComAPIURL {
+		return gitHubBaseURLs{}
+	}
+	api = strings.TrimSuffix(api, "/") + "/"
+	if !strings.HasSuffix(api, "/api/v3/") {
+		api += "api/v3/"
+	}
+
+	upload = strings.TrimSpace(upload)
+	if upload == "" {
+		upload = strings.TrimSuffix(api, "v3/") + "uploads/"
+	}
+	upload = strings.TrimSuffix(upload, "/") + "/"
+	return gitHubBaseURLs{API: api, Upload: upload}
+}
+
+// IsEnterprise returns true if the installation is on GitHub Enterprise Server rather than github.com
+func (u gitHubBaseURLs) IsEnterprise() bool {
+	return u.API != ""
+}
+
+// apiURL returns the REST API URL for path, such as "app/installations"
+func (u gitHubBaseURLs) apiURL(path string) string {
+	base := gitHubDotComAPIURL
+	if u.IsEnterprise() {
+		base = u.API
+	}
+	return base + strings.TrimPrefix(path, "/")
+}
+
+// graphQLURL returns the GraphQL API URL. Enterprise Server serves it at "/api/graphql" rather than
+// under the REST API's "/api/v3/".
+func (u gitHubBaseURLs) graphQLURL() string {
+	if !u.IsEnterprise() {
+		return gitHubDotComAPIURL + "graphql"
+	}
+	return strings.TrimSuffix(u.API, "v3/") + "graphql"
+}
+
+// newClient returns a REST API client for the instance that sends requests through httpClient
+func (u gitHubBaseURLs) newClient(httpClient *http.Client) (*github.Client, error) {
+	if !u.IsEnterprise() {
+		return github.NewClient(httpClient), nil
+	}
+	return github.NewEnterpriseClient(u.API, u.Upload, httpClient)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGitHubBaseURLs(t *testing.T) {
+	tests := []struct {
+		name   string
+		api    string
+		upload string
+		want   gitHubBaseURLs
+	}{
+		{name: "github.com by default", want: gitHubBaseURLs{}},
+		{name: "github.com API URL", api: "https://api.github.com", want: gitHubBaseURLs{}},
+		{
+			name: "instance URL",
+			api:  "https://ghes.example.com",
+			want: gitHubBaseURLs{API: "https://ghes.example.com/api/v3/", Upload: "https://ghes.example.com/api/uploads/"},
+		},
+		{
+			name: "API URL",
+			api:  "https://ghes.example.com/api/v3",
+			want: gitHubBaseURLs{API: "https://ghes.example.com/api/v3/", Upload: "https://ghes.example.com/api/uploads/"},
+		},
+		{
+			name:   "separate upload URL",
+			api:    "https://ghes.example.com/api/v3/",
+			upload: "https://uploads.ghes.example.com/api/uploads",
+			want:   gitHubBaseURLs{API: "https://ghes.example.com/api/v3/", Upload: "https://uploads.ghes.example.com/api/uploads/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, newGitHubBaseURLs(tt.api, tt.upload))
+		})
+	}
+}
+
+func TestGitHubBaseURLsForOrg(t *testing.T) {
+	t.Setenv(configs.GitHubAPIBaseURL, "")
+	t.Setenv(configs.GitHubUploadBaseURL, "")
+	t.Setenv(configs.GitHubOrgAPIBaseURLs, "mirrors=https://ghes.example.com")
+	t.Setenv(configs.GitHubOrgUploadBaseURLs, "")
+
+	assert.False(t, gitHubBaseURLsForOrg("").IsEnterprise())
+	assert.False(t, gitHubBaseURLsForOrg("mongodb").IsEnterprise())
+	assert.Equal(t, "https://ghes.example.com/api/v3/", gitHubBaseURLsForOrg("mirrors").API)
+
+	// With the default installation on Enterprise Server, orgs without their own URLs are on it too
+	t.Setenv(configs.GitHubAPIBaseURL, "https://internal.example.com/api/v3/")
+	assert.Equal(t, "https://internal.example.com/api/v3/", gitHubBaseURLsForOrg("").API)
+	assert.Equal(t, "https://internal.example.com/api/v3/", gitHubBaseURLsForOrg("mongodb").API)
+	assert.Equal(t, "https://ghes.example.com/api/v3/", gitHubBaseURLsForOrg("mirrors").API)
+}
+
+func TestGitHubBaseURLs_URLs(t *testing.T) {
+	dotCom := gitHubBaseURLs{}
+	assert.Equal(t, "https://api.github.com/app/installations", dotCom.apiURL("app/installations"))
+	assert.Equal(t, "https://api.github.com/graphql", dotCom.graphQLURL())
+
+	enterprise := newGitHubBaseURLs("https://ghes.example.com", "")
+	assert.Equal(t, "https://ghes.example.com/api/v3/app/installations", enterprise.apiURL("/app/installations"))
+	assert.Equal(t, "https://ghes.example.com/api/graphql", enterprise.graphQLURL())
+
+	client, err := enterprise.newClient(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://ghes.example.com/api/v3/", client.BaseURL.String())
+	assert.Equal(t, "https://ghes.example.com/api/uploads/", client.UploadURL.String())
+}
+
+func TestRequestInstallationToken_Enterprise(t *testing.T) {
+	var requested string
+	hc := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = req.URL.String()
+		return &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       io.NopCloser(strings.NewReader(`{"token":"ghes-token","expires_at":"2025-01-01T13:00:00Z"}`)),
+			Header:     http.Header{},
+			Request:    req,
+		}, nil
+	})}
+
+	token, _, err := requestInstallationToken(newGitHubBaseURLs("https://ghes.example.com", ""), "7", "jwt", hc)
+	require.NoError(t, err)
+	assert.Equal(t, "ghes-token", token)
+	assert.Equal(t, "https://ghes.example.com/api/v3/app/installations/7/access_tokens", requested)
+}
+
+func TestParseOrgURLs(t *testing.T) {
+	urls, err := configs.ParseOrgURLs(" mirrors = https://ghes.example.com/api/v3/ , internal=http://ghes.internal,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"mirrors":  "https://ghes.example.com/api/v3/",
+		"internal": "http://ghes.internal",
+	}, urls)
+
+	urls, err = configs.ParseOrgURLs("")
+	require.NoError(t, err)
+	assert.Empty(t, urls)
+
+	_, err = configs.ParseOrgURLs("mirrors")
+	assert.EqualError(t, err, `"mirrors" is not an org=url pair`)
+	_, err = configs.ParseOrgURLs("mirrors=ghes.example.com")
+	assert.ErrorContains(t, err, "must start with https://")
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxSecondaryRateLimitWait is the longest a request waits out a secondary rate limit before it's retried.
+// Longer waits, such as for an exhausted primary rate limit, fail the request so the upload retry queue
+// can retry it later.
+const maxSecondaryRateLimitWait = 2 * time.Minute
+
+// defaultSecondaryRateLimitWait is how long requests wait after a 429 response that doesn't say when to
+// retry, as GitHub recommends
+const defaultSecondaryRateLimitWait = time.Minute
+
+// githubRateLimits holds the limits GitHub REST API requests are held to, per installation. The service
+// container sets them from the config, since the REST clients are created outside the container; until
+// then, requests aren't limited.
+var githubRateLimits atomic.Pointer[rateLimitSettings]
+
+// rateLimitSettings are the request limits applied to each installation
+type rateLimitSettings struct {
+	maxConcurrent int           // requests in flight; 0 means no limit
+	writeInterval time.Duration // minimum time between the starts of mutating requests
+	reserve       int           // requests left in the window at which requests pause until it resets; 0 never pauses early
+}
+
+// SetGitHubRateLimits limits the GitHub API requests made with each installation's token: at most
+// maxConcurrent requests in flight (0 for no limit), and at least writeInterval between POST, PATCH, PUT,
+// and DELETE requests. GitHub applies secondary rate limits to concurrent and rapid content-creating
+// requests, and recommends waiting a second between writes. When a response reports reserve or fewer
+// requests left in the rate limit window, the installation's requests pause until the window resets,
+// so long runs like backfills wait for the budget instead of failing once it's exhausted.
+func SetGitHubRateLimits(maxConcurrent int, writeInterval time.Duration, reserve int) {
+	githubRateLimits.Store(&rateLimitSettings{maxConcurrent: maxConcurrent, writeInterval: writeInterval, reserve: reserve})
+	installationLimiters.Range(func(key, _ any) bool {
+		installationLimiters.Delete(key)
+		return true
+	})
+}
+
+// installationLimiters holds the *installationLimiter for each installation, by the name used in logs
+// and metrics: the org, or defaultInstallation
+var installationLimiters sync.Map
+
+// rateLimitNow returns the current time; replaced in tests
+var rateLimitNow = time.Now
+
+// installationLimiter spaces out the requests made with one installation's token. Requests from every
+// upload worker using the installation share it, so concurrent fan-out doesn't trip secondary rate limits.
+type installationLimiter struct {
+	name  string        // the installation, as used in logs and metrics
+	slots chan struct{} // one per request in flight; nil if unlimited
+
+	mu          sync.Mutex
+	nextWrite   time.Time // earliest start for the next mutating request
+	pausedUntil time.Time // set when GitHub reports a rate limit; no requests start before it
+	paused      bool      // whether the pause still has to be logged as resumed
+}
+
+// limiterFor returns the limiter for an installation, creating it with the current settings
+func limiterFor(installation string, settings *rateLimitSettings) *installationLimiter {
+	if limiter, ok := installationLimiters.Load(installation); ok {
+		return limiter.(*installationLimiter)
+	}
+	limiter := &installationLimiter{name: installation}
+	if settings.maxConcurrent > 0 {
+		limiter.slots = make(chan struct{}, settings.maxConcurrent)
+	}
+	actual, _ := installationLimiters.LoadOrStore(installation, limiter)
+	return actual.(*installationLimiter)
+}
+
+// acquire waits until a request can start and returns a function that releases its slot
+func (l *installationLimiter) acquire(ctx context.Context, mutating bool, writeInterval time.Duration) (func(), error) {
+	release := func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			release = func() { <-l.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	l.mu.Lock()
+	now := rateLimitNow()
+	start := now
+	if l.pausedUntil.After(start) {
+		start = l.pausedUntil
+	}
+	if mutating {
+		if l.nextWrite.After(start) {
+			start = l.nextWrite
+		}
+		l.nextWrite = start.Add(writeInterval)
+	}
+	l.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	l.logResume(ctx)
+	return release, nil
+}
+
+// pause holds back the installation's requests until the rate limit GitHub reported has passed. It
+// returns false if the installation was already paused until then or later.
+func (l *installationLimiter) pause(until time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !until.After(l.pausedUntil) {
+		return false
+	}
+	l.pausedUntil = until
+	l.paused = true
+	return true
+}
+
+// logResume logs the first request to start after a pause has passed
+func (l *installationLimiter) logResume(ctx context.Context) {
+	l.mu.Lock()
+	resumed := l.paused && !rateLimitNow().Before(l.pausedUntil)
+	if resumed {
+		l.paused = false
+	}
+	l.mu.Unlock()
+	if resumed {
+		LogInfoCtx(ctx, "GitHub rate limit pause over; resuming requests", map[string]interface{}{
+			"installation": l.name,
+		})
+	}
+}
+
+// isMutatingRequest reports whether GitHub counts the request against its content-creation limits
+func isMutatingRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodPost, http.MethodPatch, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// rateLimitedUntil returns when the installation can make requests again if resp reports a rate limit.
+// GitHub's secondary rate limits return 403 or 429 with a Retry-After header, or with
+// X-RateLimit-Remaining of 0 and X-RateLimit-Reset; a 429 without either means waiting a minute.
+// A 403 without them is a permissions error, not a rate limit.
+func rateLimitedUntil(resp *http.Response) (time.Time, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests) {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return rateLimitNow().Add(time.Duration(seconds) * time.Second), true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if epoch, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Unix(epoch, 0), true
+		}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return rateLimitNow().Add(defaultSecondaryRateLimitWait), true
+	}
+	return time.Time{}, false
+}
+
+// budgetLowUntil returns when the rate limit window resets if resp reports reserve or fewer requests
+// left in it. GraphQL responses report their own budget, in points, the same way.
+func budgetLowUntil(resp *http.Response, reserve int) (time.Time, bool) {
+	if resp == nil || reserve <= 0 {
+		return time.Time{}, false
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil || remaining > reserve {
+		return time.Time{}, false
+	}
+	epoch, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	reset := time.Unix(epoch, 0)
+	if !reset.After(rateLimitNow()) {
+		return time.Time{}, false
+	}
+	return reset, true
+}
+
+// pauseInstallation pauses the installation's requests and records the pause, unless it was already
+// paused until then
+func pauseInstallation(ctx context.Context, limiter *installationLimiter, until time.Time, resp *http.Response, message string) {
+	if !limiter.pause(until) {
+		return
+	}
+	if mc := githubAPIMetrics.Load(); mc != nil {
+		mc.RecordGitHubRateLimitPause(until)
+	}
+	LogWarningCtx(ctx, message, map[string]interface{}{
+		"installation": limiter.name,
+		"resource":     resp.Header.Get("X-RateLimit-Resource"),
+		"remaining":    resp.Header.Get("X-RateLimit-Remaining"),
+		"limit":        resp.Header.Get("X-RateLimit-Limit"),
+		"resume_at":    until.UTC().Format(time.RFC3339),
+	})
+}
+
+// rateLimitTransport applies the installation's rate limits to GitHub REST and GraphQL API requests.
+// When GitHub reports a rate limit, the installation's requests are paused until it passes, and the
+// request is retried once if the wait is short. When a response reports the budget is nearly exhausted,
+// requests are paused until it resets, but the response is returned as is.
+type rateLimitTransport struct {
+	installation string
+	base         http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	settings := githubRateLimits.Load()
+	if settings == nil {
+		return t.base.RoundTrip(req)
+	}
+	limiter := limiterFor(t.installation, settings)
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		release, err := limiter.acquire(ctx, isMutatingRequest(req), settings.writeInterval)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(req)
+		release()
+
+		until, limited := rateLimitedUntil(resp)
+		if !limited {
+			if reset, low := budgetLowUntil(resp, settings.reserve); low {
+				pauseInstallation(ctx, limiter, reset, resp, "GitHub rate limit nearly exhausted; pausing requests until it resets")
+			}
+			return resp, err
+		}
+		pauseInstallation(ctx, limiter, until, resp, "GitHub rate limit reached; pausing requests")
+
+		wait := until.Sub(rateLimitNow())
+		retry, ok := replayRequest(req)
+		if attempt > 0 || wait > maxSecondaryRateLimitWait || !ok {
+			return resp, err
+		}
+		LogWarningCtx(ctx, "GitHub rate limit reached; waiting to retry request", map[string]interface{}{
+			"installation": t.installation,
+			"method":       req.Method,
+			"url":          req.URL.Path,
+			"status":       resp.StatusCode,
+			"wait_ms":      wait.Milliseconds(),
+		})
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		req = retry
+	}
+}
+
+// replayRequest returns a copy of req that can be sent again, with a fresh body. Requests whose body
+// can't be read again can't be replayed.
+func replayRequest(req *http.Request) (*http.Request, bool) {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	retry.Body = body
+	return retry, true
+}
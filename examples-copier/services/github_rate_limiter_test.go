@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withRateLimits(t *testing.T, maxConcurrent int, writeInterval time.Duration) {
+	t.Helper()
+	SetGitHubRateLimits(maxConcurrent, writeInterval, 0)
+	t.Cleanup(func() {
+		githubRateLimits.Store(nil)
+		installationLimiters.Clear()
+	})
+}
+
+func rateLimitResponse(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: http.NoBody}
+	for k, v := range headers {
+		resp.Header.Set(k, v)
+	}
+	return resp
+}
+
+func TestRateLimitedUntil(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	rateLimitNow = func() time.Time { return now }
+	t.Cleanup(func() { rateLimitNow = time.Now })
+
+	reset := now.Add(30 * time.Minute).Unix()
+	tests := []struct {
+		name    string
+		resp    *http.Response
+		limited bool
+		until   time.Time
+	}{
+		{"ok", rateLimitResponse(http.StatusOK, nil), false, time.Time{}},
+		{"forbidden without rate limit headers", rateLimitResponse(http.StatusForbidden, nil), false, time.Time{}},
+		{"secondary limit with retry-after", rateLimitResponse(http.StatusForbidden, map[string]string{"Retry-After": "5"}), true, now.Add(5 * time.Second)},
+		{"primary limit exhausted", rateLimitResponse(http.StatusForbidden, map[string]string{
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset, 10),
+		}), true, time.Unix(reset, 0)},
+		{"too many requests without headers", rateLimitResponse(http.StatusTooManyRequests, nil), true, now.Add(time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, limited := rateLimitedUntil(tt.resp)
+			assert.Equal(t, tt.limited, limited)
+			assert.True(t, tt.until.Equal(until), "until = %v, want %v", until, tt.until)
+		})
+	}
+}
+
+func TestRateLimitTransport_LimitsConcurrentRequests(t *testing.T) {
+	withRateLimits(t, 2, 0)
+
+	var inFlight, maxInFlight atomic.Int32
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		n := inFlight.Add(1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+		return rateLimitResponse(http.StatusOK, nil), nil
+	})
+	transport := &rateLimitTransport{installation: "org", base: base}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/org/repo", nil)
+			_, err := transport.RoundTrip(req)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), maxInFlight.Load())
+}
+
+func TestRateLimitTransport_SpacesOutWrites(t *testing.T) {
+	interval := 30 * time.Millisecond
+	withRateLimits(t, 0, interval)
+
+	var mu sync.Mutex
+	var starts []time.Time
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		return rateLimitResponse(http.StatusOK, nil), nil
+	})
+	transport := &rateLimitTransport{installation: "org", base: base}
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodPost, "https://api.github.com/repos/org/repo/git/blobs", strings.NewReader("{}"))
+		_, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, starts, 3)
+	for i := 1; i < len(starts); i++ {
+		// Allow for timer slack
+		assert.GreaterOrEqual(t, starts[i].Sub(starts[i-1]), interval-5*time.Millisecond)
+	}
+}
+
+func TestRateLimitTransport_RetriesAfterSecondaryRateLimit(t *testing.T) {
+	withRateLimits(t, 0, 0)
+
+	var calls atomic.Int32
+	var bodies []string
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body := make([]byte, 16)
+		n, _ := req.Body.Read(body)
+		bodies = append(bodies, string(body[:n]))
+		if calls.Add(1) == 1 {
+			return rateLimitResponse(http.StatusForbidden, map[string]string{"Retry-After": "0"}), nil
+		}
+		return rateLimitResponse(http.StatusCreated, nil), nil
+	})
+	transport := &rateLimitTransport{installation: "org", base: base}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://api.github.com/repos/org/repo/pulls", strings.NewReader(`{"a":1}`))
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, []string{`{"a":1}`, `{"a":1}`}, bodies, "retry should resend the request body")
+}
+
+func TestRateLimitTransport_DoesNotWaitOutPrimaryRateLimit(t *testing.T) {
+	withRateLimits(t, 0, 0)
+
+	var calls atomic.Int32
+	reset := time.Now().Add(time.Hour).Unix()
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return rateLimitResponse(http.StatusForbidden, map[string]string{
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset, 10),
+		}), nil
+	})
+	transport := &rateLimitTransport{installation: "org", base: base}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/org/repo", nil)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Later requests for the installation wait for the reset rather than being sent
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/repos/org/repo", nil)
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Other installations aren't paused
+	other := &rateLimitTransport{installation: "other-org", base: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return rateLimitResponse(http.StatusOK, nil), nil
+	})}
+	req, _ = http.NewRequest(http.MethodGet, "https://api.github.com/repos/other-org/repo", nil)
+	resp, err = other.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestBudgetLowUntil(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	rateLimitNow = func() time.Time { return now }
+	t.Cleanup(func() { rateLimitNow = time.Now })
+
+	reset := now.Add(20 * time.Minute).Unix()
+	budget := func(remaining string, reset int64) *http.Response {
+		return rateLimitResponse(http.StatusOK, map[string]string{
+			"X-RateLimit-Remaining": remaining,
+			"X-RateLimit-Reset":     strconv.FormatInt(reset, 10),
+		})
+	}
+	tests := []struct {
+		name    string
+		resp    *http.Response
+		reserve int
+		low     bool
+	}{
+		{"above the reserve", budget("101", reset), 100, false},
+		{"at the reserve", budget("100", reset), 100, true},
+		{"reserve off", budget("0", reset), 0, false},
+		{"window already reset", budget("5", now.Add(-time.Second).Unix()), 100, false},
+		{"no rate limit headers", rateLimitResponse(http.StatusOK, nil), 100, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, low := budgetLowUntil(tt.resp, tt.reserve)
+			assert.Equal(t, tt.low, low)
+			if tt.low {
+				assert.True(t, time.Unix(reset, 0).Equal(until), "until = %v", until)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransport_PausesWhenBudgetIsNearlyExhausted(t *testing.T) {
+	SetGitHubRateLimits(0, 0, 10)
+	mc := NewMetricsCollector()
+	SetGitHubAPIMetrics(mc)
+	t.Cleanup(func() {
+		githubRateLimits.Store(nil)
+		installationLimiters.Clear()
+		githubAPIMetrics.Store(nil)
+	})
+
+	var calls atomic.Int32
+	reset := time.Now().Add(time.Hour).Unix()
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return rateLimitResponse(http.StatusOK, map[string]string{
+			"X-RateLimit-Remaining": "10",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset, 10),
+		}), nil
+	})
+	transport := &rateLimitTransport{installation: "org", base: base}
+
+	// The response that reports the low budget is still returned
+	req, _ := http.NewRequest(http.MethodPut, "https://api.github.com/repos/org/repo/contents/a.go", nil)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Later requests wait for the window to reset
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/repos/org/repo", nil)
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), calls.Load())
+
+	rateLimit := mc.GetMetrics(NewFileStateService()).GitHubAPI.RateLimit
+	assert.Equal(t, int64(1), rateLimit.Pauses)
+	assert.True(t, time.Unix(reset, 0).Equal(rateLimit.PausedUntil), "paused until %v", rateLimit.PausedUntil)
+
+	var body strings.Builder
+	require.NoError(t, mc.WritePrometheus(&body, NewFileStateService()))
+	assert.Contains(t, body.String(), "copier_github_rate_limit_pauses_total 1\n")
+	assert.Contains(t, body.String(), "copier_github_rate_limit_paused 1\n")
+}
+
+func TestRateLimitTransport_ResumesAfterPause(t *testing.T) {
+	withRateLimits(t, 0, 0)
+
+	var calls atomic.Int32
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if calls.Add(1) == 1 {
+			return rateLimitResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "0"}), nil
+		}
+		return rateLimitResponse(http.StatusOK, nil), nil
+	})
+	transport := &rateLimitTransport{installation: "org", base: base}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/org/repo", nil)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	limiter := limiterFor("org", githubRateLimits.Load())
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	assert.False(t, limiter.paused, "the pause should be logged as resumed once a request starts after it")
+}
+
+func TestUploadBatchesByRepo(t *testing.T) {
+	uploads := map[types.UploadKey]types.UploadFileContent{
+		{RepoName: "org/b", BranchPath: "refs/heads/main"}:    {},
+		{RepoName: "org/a", BranchPath: "refs/heads/v2"}:      {},
+		{RepoName: "org/a", BranchPath: "refs/heads/main"}:    {},
+		{RepoName: "org/c", BranchPath: "refs/heads/release"}: {},
+	}
+
+	batches := uploadBatchesByRepo(uploads)
+
+	require.Len(t, batches, 3)
+	assert.Equal(t, []types.UploadKey{
+		{RepoName: "org/a", BranchPath: "refs/heads/main"},
+		{RepoName: "org/a", BranchPath: "refs/heads/v2"},
+	}, batches[0])
+	assert.Equal(t, "org/b", batches[1][0].RepoName)
+	assert.Equal(t, "org/c", batches[2][0].RepoName)
+}
@@ -3,8 +3,8 @@ package services
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
+	"strings"
 
 	"github.com/google/go-github/v48/github"
 	"github.com/mongodb/code-example-tooling/code-copier/configs"
@@ -12,6 +12,44 @@ import (
 	"github.com/shurcooL/githubv4"
 )
 
+// GetFilesChangedInPush lists the files changed between the commits before and after a push, mapped to
+// the statuses the GraphQL API reports for PR files (ADDED, MODIFIED, DELETED, RENAMED, ...).
+func GetFilesChangedInPush(ctx context.Context, owner string, repo string, beforeSHA string, afterSHA string) ([]ChangedFile, error) {
+	client := GetRestClient()
+	var changedFiles []ChangedFile
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		comparison, resp, err := client.Repositories.CompareCommits(ctx, owner, repo, beforeSHA, afterSHA, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s...%s in %s/%s: %w", beforeSHA, afterSHA, owner, repo, err)
+		}
+		for _, file := range comparison.Files {
+			changedFiles = append(changedFiles, ChangedFile{
+				Path:      file.GetFilename(),
+				Additions: file.GetAdditions(),
+				Deletions: file.GetDeletions(),
+				Status:    pushFileStatus(file.GetStatus()),
+			})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	LogInfoCtx(ctx, fmt.Sprintf("Push has %d changed files.", len(changedFiles)), nil)
+	return changedFiles, nil
+}
+
+// pushFileStatus maps a REST API file status (added, removed, modified, renamed, ...) to the
+// GraphQL ChangeType the rest of the copier expects
+func pushFileStatus(status string) string {
+	if status == "removed" {
+		return statusDeleted
+	}
+	return strings.ToUpper(status)
+}
+
 // GetFilesChangedInPr retrieves the list of files changed in a specified pull request.
 // It returns a slice of ChangedFile structures containing details about each changed file.
 // Parameters:
@@ -19,20 +57,23 @@ import (
 //   - repo: The repository name (e.g., "docs-sample-apps")
 //   - pr_number: The pull request number
 func GetFilesChangedInPr(owner string, repo string, pr_number int) ([]ChangedFile, error) {
-	if InstallationAccessToken == "" {
-		log.Println("No installation token provided")
-		ConfigurePermissions()
-	}
-
-	client := GetGraphQLClient()
-	ctx := context.Background()
+	return GetFilesChangedInPrWithContext(context.Background(), owner, repo, pr_number)
+}
 
-	var changedFiles []ChangedFile
-	var cursor *githubv4.String = nil
-	hasNextPage := true
+// GetFilesChangedInPrWithContext is GetFilesChangedInPr with a context, so the GitHub API requests
+// carry the context's correlation ID
+func GetFilesChangedInPrWithContext(ctx context.Context, owner string, repo string, pr_number int) ([]ChangedFile, error) {
+	return GetFilesChangedInPrUnderPrefixes(ctx, owner, repo, pr_number, nil)
+}
 
-	// Paginate through all files
-	for hasNextPage {
+// GetFilesChangedInPrUnderPrefixes lists the files changed in a pull request for workflows that only copy
+// files whose paths start with one of the prefixes. GitHub lists pull request files sorted by path, so
+// once a page ends past every prefix no later file can match, and the remaining pages aren't fetched; for
+// a large PR in a monorepo that's most of them. The listing then includes some files past the prefixes
+// but not all of them. Nil prefixes list every file.
+func GetFilesChangedInPrUnderPrefixes(ctx context.Context, owner string, repo string, pr_number int, prefixes []string) ([]ChangedFile, error) {
+	client := GetGraphQLClient()
+	fetchPage := func(cursor *githubv4.String) ([]ChangedFile, *githubv4.String, error) {
 		var prQuery PullRequestQuery
 		variables := map[string]interface{}{
 			"owner":  githubv4.String(owner),
@@ -43,35 +84,45 @@ func GetFilesChangedInPr(owner string, repo string, pr_number int) ([]ChangedFil
 
 		err := client.Query(ctx, &prQuery, variables)
 		if err != nil {
-			LogCritical(fmt.Sprintf("Failed to execute query GetFilesChanged: %v", err))
-			return nil, err
+			LogCriticalCtx(ctx, fmt.Sprintf("Failed to execute query GetFilesChanged: %v", err), nil)
+			return nil, nil, err
 		}
 
-		// Append files from this page
+		var files []ChangedFile
 		for _, edge := range prQuery.Repository.PullRequest.Files.Edges {
-			changedFiles = append(changedFiles, ChangedFile{
+			files = append(files, ChangedFile{
 				Path:      string(edge.Node.Path),
 				Additions: int(edge.Node.Additions),
 				Deletions: int(edge.Node.Deletions),
 				Status:    string(edge.Node.ChangeType),
 			})
 		}
-
-		// Check if there are more pages
-		hasNextPage = prQuery.Repository.PullRequest.Files.PageInfo.HasNextPage
-		if hasNextPage {
-			cursor = &prQuery.Repository.PullRequest.Files.PageInfo.EndCursor
+		if !prQuery.Repository.PullRequest.Files.PageInfo.HasNextPage {
+			return files, nil, nil
 		}
+		return files, &prQuery.Repository.PullRequest.Files.PageInfo.EndCursor, nil
 	}
 
-	LogInfo(fmt.Sprintf("PR has %d changed files.", len(changedFiles)))
+	changedFiles, pages, stoppedEarly, err := listChangedFilePages(fetchPage, prefixes)
+	if err != nil {
+		return nil, err
+	}
+	if stoppedEarly {
+		LogInfoCtx(ctx, "stopped listing PR files past the workflows' source paths", map[string]interface{}{
+			"pages":    pages,
+			"files":    len(changedFiles),
+			"prefixes": prefixes,
+		})
+	}
+
+	LogInfoCtx(ctx, fmt.Sprintf("PR has %d changed files.", len(changedFiles)), nil)
 
 	// Log all files for debugging (especially to see if server files are included)
-	LogInfo("=== ALL FILES FROM GRAPHQL API ===")
+	LogInfoCtx(ctx, "=== ALL FILES FROM GRAPHQL API ===", nil)
 	for i, file := range changedFiles {
-		LogInfo(fmt.Sprintf("  [%d] %s (status: %s)", i, file.Path, file.Status))
+		LogInfoCtx(ctx, fmt.Sprintf("  [%d] %s (status: %s)", i, file.Path, file.Status), nil)
 	}
-	LogInfo("=== END FILE LIST ===")
+	LogInfoCtx(ctx, "=== END FILE LIST ===", nil)
 
 	// Count files by directory for debugging
 	clientCount := 0
@@ -86,7 +137,7 @@ func GetFilesChangedInPr(owner string, repo string, pr_number int) ([]ChangedFil
 			otherCount++
 		}
 	}
-	LogInfo(fmt.Sprintf("File breakdown: client=%d, server=%d, other=%d", clientCount, serverCount, otherCount))
+	LogInfoCtx(ctx, fmt.Sprintf("File breakdown: client=%d, server=%d, other=%d", clientCount, serverCount, otherCount), nil)
 
 	return changedFiles, nil
 }
@@ -110,3 +161,66 @@ func RetrieveFileContents(filePath string) (github.RepositoryContent, error) {
 	}
 	return *fileContent, nil
 }
+
+// GetRepoTree lists every file in the repository at the given commit or ref, returning each file's
+// Git mode keyed by path. truncated is true if GitHub didn't return the whole tree, in which case
+// the listing is incomplete.
+func GetRepoTree(ctx context.Context, client *github.Client, owner string, repo string, ref string) (modes map[string]string, truncated bool, err error) {
+	tree, _, err := client.Git.GetTree(ctx, owner, repo, ref, true)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get tree for %s/%s at %s: %w", owner, repo, ref, err)
+	}
+
+	modes = make(map[string]string, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			modes[entry.GetPath()] = entry.GetMode()
+		}
+	}
+	return modes, tree.GetTruncated(), nil
+}
+
+// listChangedFilePages fetches pages of changed files until the last page or, with prefixes, until a page
+// ends past every prefix. fetchPage returns a page of files and the cursor of the next page, or nil after
+// the last page. Stopping early relies on the files being sorted by path, so if a page lists them out of
+// order every page is fetched.
+func listChangedFilePages(fetchPage func(cursor *githubv4.String) ([]ChangedFile, *githubv4.String, error),
+	prefixes []string) (files []ChangedFile, pages int, stoppedEarly bool, err error) {
+
+	sorted := true
+	var cursor *githubv4.String
+	for {
+		page, next, err := fetchPage(cursor)
+		if err != nil {
+			return nil, pages, false, err
+		}
+		pages++
+		for _, file := range page {
+			if len(files) > 0 && file.Path < files[len(files)-1].Path {
+				sorted = false
+			}
+			files = append(files, file)
+		}
+		if next == nil {
+			return files, pages, false, nil
+		}
+		if sorted && len(files) > 0 && pastPrefixes(files[len(files)-1].Path, prefixes) {
+			return files, pages, true, nil
+		}
+		cursor = next
+	}
+}
+
+// pastPrefixes returns true if no path that sorts after last can start with any of the prefixes. Nil
+// prefixes can match any path.
+func pastPrefixes(last string, prefixes []string) bool {
+	if prefixes == nil {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if prefix >= last || strings.HasPrefix(last, prefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,10 +1,12 @@
 package services_test
 
 import (
+	"context"
 	"encoding/base64"
 	"testing"
 
 	"github.com/google/go-github/v48/github"
+	"github.com/jarcoal/httpmock"
 	"github.com/mongodb/code-example-tooling/code-copier/services"
 	"github.com/stretchr/testify/require"
 
@@ -107,6 +109,69 @@ func TestRetrieveFileContents_Success(t *testing.T) {
 	require.Contains(t, *rc.Content, b64(payload))
 }
 
+func TestGetRepoTree(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	owner, repo := ensureEnv(t)
+	test.SetupOrgToken(owner, "test-token")
+
+	httpmock.RegisterResponder("GET",
+		"https://api.github.com/repos/"+owner+"/"+repo+"/git/trees/abc123?recursive=1",
+		httpmock.NewJsonResponderOrPanic(200, map[string]any{
+			"sha": "abc123",
+			"tree": []map[string]any{
+				{"path": "scripts", "mode": "040000", "type": "tree", "sha": "t1"},
+				{"path": "scripts/setup.sh", "mode": "100755", "type": "blob", "sha": "b1"},
+				{"path": "README.md", "mode": "100644", "type": "blob", "sha": "b2"},
+			},
+		}),
+	)
+
+	modes, truncated, err := services.GetRepoTree(context.Background(), services.GetRestClient(), owner, repo, "abc123")
+	require.NoError(t, err)
+	require.False(t, truncated)
+	require.Equal(t, map[string]string{
+		"scripts/setup.sh": "100755",
+		"README.md":        "100644",
+	}, modes)
+}
+
+func TestGetRepoTree_Truncated(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	owner, repo := ensureEnv(t)
+	test.SetupOrgToken(owner, "test-token")
+
+	httpmock.RegisterResponder("GET",
+		"https://api.github.com/repos/"+owner+"/"+repo+"/git/trees/main?recursive=1",
+		httpmock.NewJsonResponderOrPanic(200, map[string]any{
+			"sha":       "abc123",
+			"truncated": true,
+			"tree": []map[string]any{
+				{"path": "README.md", "mode": "100644", "type": "blob", "sha": "b1"},
+			},
+		}),
+	)
+
+	modes, truncated, err := services.GetRepoTree(context.Background(), services.GetRestClient(), owner, repo, "main")
+	require.NoError(t, err)
+	require.True(t, truncated)
+	require.Equal(t, map[string]string{"README.md": "100644"}, modes)
+}
+
+func TestGetRepoTree_Error(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	owner, repo := ensureEnv(t)
+	test.SetupOrgToken(owner, "test-token")
+
+	httpmock.RegisterResponder("GET",
+		"https://api.github.com/repos/"+owner+"/"+repo+"/git/trees/missing?recursive=1",
+		httpmock.NewStringResponder(404, `{"message":"Not Found"}`),
+	)
+
+	modes, _, err := services.GetRepoTree(context.Background(), services.GetRestClient(), owner, repo, "missing")
+	require.Error(t, err)
+	require.Nil(t, modes)
+}
+
 /*
 // Test that Retrieve and Parse round-trips with one entry
 func TestRetrieveAndParseConfigFile_RoundTripMinimal(t *testing.T) {
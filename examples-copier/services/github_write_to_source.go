@@ -3,8 +3,11 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v48/github"
@@ -13,15 +16,56 @@ import (
 )
 
 func UpdateDeprecationFile() {
+	UpdateDeprecationFileWithContext(context.Background())
+}
+
+// UpdateDeprecationFileWithContext records the files in FilesToDeprecate in the deprecation file,
+// making the GitHub API requests with ctx
+func UpdateDeprecationFileWithContext(ctx context.Context) {
+	updateDeprecationFile(ctx, FilesToDeprecate)
+}
+
+// deprecationFileAttempts is how many times the deprecation file is read, appended to, and written back
+// when another run changes it in between
+const deprecationFileAttempts = 3
+
+// deprecationFileMu serializes this instance's updates to the deprecation file. Updates from other
+// instances are caught by the file's SHA and retried.
+var deprecationFileMu sync.Mutex
+
+// updateDeprecationFile records a run's deprecated files in the deprecation file, keyed by file name
+func updateDeprecationFile(ctx context.Context, filesToDeprecate map[string]Configs) {
 	// Early return if there are no files to deprecate - prevents blank commits
-	if len(FilesToDeprecate) == 0 {
-		LogInfo("No deprecated files to record; skipping deprecation file update")
+	if len(filesToDeprecate) == 0 {
+		LogInfoCtx(ctx, "No deprecated files to record; skipping deprecation file update", nil)
 		return
 	}
 
+	deprecationFileMu.Lock()
+	defer deprecationFileMu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		err := appendToDeprecationFile(ctx, filesToDeprecate)
+		if err == nil {
+			break
+		}
+		if !isDeprecationFileConflict(err) || attempt == deprecationFileAttempts {
+			LogErrorCtx(ctx, "Cannot update deprecation file", err, nil)
+			return
+		}
+		LogWarningCtx(ctx, "deprecation file changed while it was being updated; retrying", map[string]interface{}{
+			"attempt": attempt,
+		})
+	}
+
+	LogInfoCtx(ctx, fmt.Sprintf("Successfully updated %s with %d entries", os.Getenv(configs.DeprecationFile), len(filesToDeprecate)), nil)
+}
+
+// appendToDeprecationFile reads the deprecation file, appends the files to it, and writes it back over the
+// version it read, so the write fails rather than replace entries another run added in between
+func appendToDeprecationFile(ctx context.Context, filesToDeprecate map[string]Configs) error {
 	// Fetch the deprecation file from the repository
 	client := GetRestClient()
-	ctx := context.Background()
 
 	fileContent, _, _, err := client.Repositories.GetContents(
 		ctx,
@@ -33,24 +77,21 @@ func UpdateDeprecationFile() {
 		},
 	)
 	if err != nil {
-		LogError(fmt.Sprintf("Error getting deprecation file: %v", err))
-		return
+		return fmt.Errorf("failed to get deprecation file: %w", err)
 	}
 
 	content, err := fileContent.GetContent()
 	if err != nil {
-		LogError(fmt.Sprintf("Error decoding deprecation file: %v", err))
-		return
+		return fmt.Errorf("failed to decode deprecation file: %w", err)
 	}
 
 	var deprecationFile DeprecationFile
 	err = json.Unmarshal([]byte(content), &deprecationFile)
 	if err != nil {
-		LogError(fmt.Sprintf("Failed to unmarshal %s: %v", configs.DeprecationFile, err))
-		return
+		return fmt.Errorf("failed to unmarshal %s: %w", os.Getenv(configs.DeprecationFile), err)
 	}
 
-	for key, value := range FilesToDeprecate {
+	for key, value := range filesToDeprecate {
 		newDeprecatedFileEntry := DeprecatedFileEntry{
 			FileName:  key,
 			Repo:      value.TargetRepo,
@@ -62,39 +103,41 @@ func UpdateDeprecationFile() {
 
 	updatedJSON, err := json.MarshalIndent(deprecationFile, "", "  ")
 	if err != nil {
-		LogError(fmt.Sprintf("Error marshaling JSON: %v", err))
+		return fmt.Errorf("failed to marshal deprecation file: %w", err)
 	}
 
 	message := fmt.Sprintf("Updating %s.", os.Getenv(configs.DeprecationFile))
-	uploadDeprecationFileChanges(message, string(updatedJSON))
-
-	LogInfo(fmt.Sprintf("Successfully updated %s with %d entries", os.Getenv(configs.DeprecationFile), len(FilesToDeprecate)))
+	return uploadDeprecationFileChanges(ctx, message, string(updatedJSON), fileContent.GetSHA())
 }
 
-func uploadDeprecationFileChanges(message string, newDeprecationFileContents string) {
+// uploadDeprecationFileChanges writes the deprecation file over the version with the given SHA
+func uploadDeprecationFileChanges(ctx context.Context, message string, newDeprecationFileContents string, sha string) error {
 	client := GetRestClient()
-	ctx := context.Background()
-
-	targetFileContent, _, _, err := client.Repositories.GetContents(ctx, os.Getenv(configs.ConfigRepoOwner), os.Getenv(configs.ConfigRepoName),
-		os.Getenv(configs.DeprecationFile), &github.RepositoryContentGetOptions{Ref: os.Getenv(configs.ConfigRepoBranch)})
-
-	if err != nil {
-		LogError(fmt.Sprintf("Error getting deprecation file contents: %v", err))
-	}
 
 	options := &github.RepositoryContentFileOptions{
-		Message: github.String(message),
+		Message: github.String(addCopierTrailer(message)),
 		Content: []byte(newDeprecationFileContents),
+		SHA:     github.String(sha),
 		Branch:  github.String(os.Getenv(configs.ConfigRepoBranch)),
 		Committer: &github.CommitAuthor{Name: github.String(os.Getenv(configs.CommitterName)),
 			Email: github.String(os.Getenv(configs.CommitterEmail))},
 	}
 
-	options.SHA = targetFileContent.SHA
-	_, _, err = client.Repositories.UpdateFile(ctx, os.Getenv(configs.ConfigRepoOwner), os.Getenv(configs.ConfigRepoName), os.Getenv(configs.DeprecationFile), options)
+	_, _, err := client.Repositories.UpdateFile(ctx, os.Getenv(configs.ConfigRepoOwner), os.Getenv(configs.ConfigRepoName), os.Getenv(configs.DeprecationFile), options)
 	if err != nil {
-		LogError(fmt.Sprintf("Cannot update deprecation file: %v", err))
+		return fmt.Errorf("failed to update deprecation file: %w", err)
 	}
 
-	LogInfo(fmt.Sprintf("Deprecation file updated."))
+	LogInfoCtx(ctx, "Deprecation file updated.", nil)
+	return nil
+}
+
+// isDeprecationFileConflict returns true if GitHub refused a deprecation file update because the file
+// changed since it was read
+func isDeprecationFileConflict(err error) bool {
+	var eresp *github.ErrorResponse
+	if !errors.As(err, &eresp) || eresp.Response == nil {
+		return false
+	}
+	return eresp.Response.StatusCode == http.StatusConflict || eresp.Response.StatusCode == http.StatusUnprocessableEntity
 }
@@ -6,7 +6,10 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/go-github/v48/github"
@@ -51,100 +54,224 @@ func normalizeRepoName(repoName string) string {
 // AddFilesToTargetRepoBranch uploads files to the target repository branch
 // using the specified commit strategy (direct or via pull request).
 func AddFilesToTargetRepoBranch() {
-	AddFilesToTargetRepoBranchWithFetcher(nil, nil)
+	AddFilesToTargetRepoBranchWithFetcher(context.Background(), nil, nil)
+}
+
+// UploadResult is the outcome of committing the queued files for one target repo and branch
+type UploadResult struct {
+	PRURL    string // Pull request opened in the target repo, for the pull request strategy
+	PRNumber int
+	// CommitSHA is the commit the files landed on the target branch in: the copier's commit for the
+	// direct strategy, or the merge commit of an automatically merged pull request
+	CommitSHA string
+	// AwaitingApproval is true if the pull request is held by the workflow's approval gate rather than merged
+	AwaitingApproval bool
+	Err              error
+}
+
+// uploadConcurrency is how many destination repos uploadFiles uploads to at
+// once. The service container sets it from UPLOAD_CONCURRENCY; until then, repos are uploaded to one at a time.
+var uploadConcurrency atomic.Int32
+
+// SetUploadConcurrency sets how many destination repos are uploaded to at once. Values below 1 upload
+// to one repo at a time.
+func SetUploadConcurrency(n int) {
+	uploadConcurrency.Store(int32(n))
 }
 
 // AddFilesToTargetRepoBranchWithFetcher uploads files to the target repository branch
 // using the specified commit strategy (direct or via pull request).
 // If prTemplateFetcher is provided, it will be used to fetch PR templates when use_pr_template is true.
 // If metricsCollector is provided, it will be used to record upload failures.
-func AddFilesToTargetRepoBranchWithFetcher(prTemplateFetcher PRTemplateFetcher, metricsCollector *MetricsCollector) {
-	ctx := context.Background()
-
-	for key, value := range FilesToUpload {
-		// Parse the repository to get the organization
-		owner, _ := parseRepoPath(key.RepoName)
+// GitHub API requests are made with ctx, and pull request bodies note its correlation ID.
+// Different destination repos are uploaded to concurrently, by up to SetUploadConcurrency workers;
+// the branches of one repo are uploaded in order by the same worker.
+// Returns the result of each upload, keyed the same as FilesToUpload.
+func AddFilesToTargetRepoBranchWithFetcher(ctx context.Context, prTemplateFetcher PRTemplateFetcher, metricsCollector *MetricsCollector) map[UploadKey]UploadResult {
+	return uploadFiles(ctx, FilesToUpload, prTemplateFetcher, metricsCollector)
+}
 
-		// Get a client authenticated for this organization
-		client, err := GetRestClientForOrg(owner)
-		if err != nil {
-			LogCritical(fmt.Sprintf("Failed to get GitHub client for org %s: %v", owner, err))
-			// Record failure for each file in this batch
-			if metricsCollector != nil {
-				for range value.Content {
-					metricsCollector.RecordFileUploadFailed()
+// uploadFiles uploads a run's staged files the way AddFilesToTargetRepoBranchWithFetcher uploads
+// FilesToUpload, so runs processed at once each upload only their own files
+func uploadFiles(ctx context.Context, uploads map[UploadKey]UploadFileContent, prTemplateFetcher PRTemplateFetcher,
+	metricsCollector *MetricsCollector) map[UploadKey]UploadResult {
+
+	results := make(map[UploadKey]UploadResult, len(uploads))
+	var resultsMu sync.Mutex
+
+	batches := uploadBatchesByRepo(uploads)
+	workers := min(int(uploadConcurrency.Load()), len(batches))
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan []UploadKey)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for keys := range jobs {
+				for _, key := range keys {
+					value := uploads[key]
+					result := uploadToTarget(ctx, key, value, prTemplateFetcher)
+					resultsMu.Lock()
+					results[key] = result
+					resultsMu.Unlock()
+					// Record failure for each file in this batch
+					if result.Err != nil && metricsCollector != nil {
+						for range value.Content {
+							metricsCollector.RecordFileUploadFailed()
+						}
+						if errors.Is(result.Err, ErrPRCreation) {
+							metricsCollector.RecordPRCreationFailed()
+						}
+					}
 				}
 			}
-			continue
+		}()
+	}
+	for _, keys := range batches {
+		jobs <- keys
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// uploadBatchesByRepo groups the queued uploads by destination repo, sorted by repo and branch, so each
+// repo's branches are uploaded in order while different repos are uploaded concurrently
+func uploadBatchesByRepo(uploads map[UploadKey]UploadFileContent) [][]UploadKey {
+	keys := make([]UploadKey, 0, len(uploads))
+	for key := range uploads {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].RepoName != keys[j].RepoName {
+			return keys[i].RepoName < keys[j].RepoName
 		}
+		if keys[i].BranchPath != keys[j].BranchPath {
+			return keys[i].BranchPath < keys[j].BranchPath
+		}
+		return keys[i].RuleName < keys[j].RuleName
+	})
 
-		// Determine commit strategy from value (set by pattern-matching system)
-		strategy := string(value.CommitStrategy)
-		if strategy == "" {
-			strategy = "direct" // default
+	var batches [][]UploadKey
+	for i, key := range keys {
+		if i == 0 || key.RepoName != keys[i-1].RepoName {
+			batches = append(batches, nil)
 		}
+		batches[len(batches)-1] = append(batches[len(batches)-1], key)
+	}
+	return batches
+}
+
+// uploadToTarget commits the queued files for one target repo and branch, using the commit strategy,
+// message, and PR settings in value
+func uploadToTarget(ctx context.Context, key UploadKey, value UploadFileContent, prTemplateFetcher PRTemplateFetcher) UploadResult {
+	// Get the provider for the platform hosting the target repo
+	provider, repo, err := repoProviderFor(key.RepoName)
+	if err != nil {
+		LogErrorCtx(ctx, "Failed to get client for target repo", err, map[string]interface{}{"target_repo": key.RepoName})
+		return UploadResult{Err: err}
+	}
+
+	// Determine commit strategy from value (set by pattern-matching system)
+	strategy := string(value.CommitStrategy)
+	if strategy == "" {
+		strategy = "direct" // default
+	}
 
-		// Get commit message from value or use default
-		commitMsg := value.CommitMessage
+	// Get commit message from value or use default
+	commitMsg := value.CommitMessage
+	if strings.TrimSpace(commitMsg) == "" {
+		commitMsg = os.Getenv(configs.DefaultCommitMessage)
 		if strings.TrimSpace(commitMsg) == "" {
-			commitMsg = os.Getenv(configs.DefaultCommitMessage)
-			if strings.TrimSpace(commitMsg) == "" {
-				commitMsg = configs.NewConfig().DefaultCommitMessage
-			}
+			commitMsg = configs.NewConfig().DefaultCommitMessage
 		}
+	}
+
+	// Get PR title from value or use commit message
+	prTitle := value.PRTitle
+	if strings.TrimSpace(prTitle) == "" {
+		prTitle = commitMsg
+	}
+
+	// Get PR body from value
+	prBody := value.PRBody
+
+	// Only the pull_request strategy opens a PR
+	opensPR := strategy != string(CommitStrategyDirect) && strategy != string(CommitStrategyBranch)
 
-		// Get PR title from value or use commit message
-		prTitle := value.PRTitle
-		if strings.TrimSpace(prTitle) == "" {
-			prTitle = commitMsg
+	// Fetch and merge PR template if requested
+	if value.UsePRTemplate && prTemplateFetcher != nil && opensPR {
+		targetBranch := strings.TrimPrefix(key.BranchPath, "refs/heads/")
+		gp, onGitHub := provider.(*githubProvider)
+		var template string
+		if onGitHub {
+			template, err = prTemplateFetcher.FetchPRTemplate(ctx, gp.client, repo, targetBranch)
+		} else {
+			err = fmt.Errorf("PR templates are only supported for GitHub repos")
 		}
+		if err != nil {
+			LogWarningCtx(ctx, "Failed to fetch PR template", map[string]interface{}{"target_repo": key.RepoName, "error": err.Error()})
+		} else if template != "" {
+			// Merge configured body with template
+			prBody = MergePRBodyWithTemplate(prBody, template)
+			LogInfoCtx(ctx, "Merged PR template", map[string]interface{}{"target_repo": key.RepoName})
+		}
+	}
 
-		// Get PR body from value
-		prBody := value.PRBody
-
-		// Fetch and merge PR template if requested
-		if value.UsePRTemplate && prTemplateFetcher != nil && strategy != "direct" {
-			targetBranch := strings.TrimPrefix(key.BranchPath, "refs/heads/")
-			template, err := prTemplateFetcher.FetchPRTemplate(ctx, client, key.RepoName, targetBranch)
-			if err != nil {
-				LogWarning(fmt.Sprintf("Failed to fetch PR template for %s: %v", key.RepoName, err))
-			} else if template != "" {
-				// Merge configured body with template
-				prBody = MergePRBodyWithTemplate(prBody, template)
-				LogInfo(fmt.Sprintf("Merged PR template for %s", key.RepoName))
-			}
+	// Note the delivery that opened the PR, so its logs can be found from the PR
+	prBody = appendCorrelationID(prBody, CorrelationIDFromContext(ctx))
+
+	// Mark the commit as the copier's so webhooks for it don't trigger more copies
+	commitMsg = addCopierTrailer(commitMsg)
+
+	// Sign off commits and note the sign-off in the PR body for targets that enforce a DCO check
+	var author *github.CommitAuthor
+	if value.SignOff != nil {
+		author = signOffAuthor(value.SignOff)
+		commitMsg = addSignOffTrailer(commitMsg, author)
+		if opensPR {
+			prBody = appendSignOffNote(prBody, value.SignOff)
 		}
+	}
 
-		// Get auto-merge setting from value
-		mergeWithoutReview := value.AutoMergePR
-
-		switch strategy {
-		case "direct": // commits directly to the target branch
-			LogInfo(fmt.Sprintf("Using direct commit strategy for %s on branch %s", key.RepoName, key.BranchPath))
-			if err := addFilesToBranch(ctx, client, key, value.Content, commitMsg); err != nil {
-				LogCritical(fmt.Sprintf("Failed to add files to target branch: %v\n", err))
-				// Record failure for each file in this batch
-				if metricsCollector != nil {
-					for range value.Content {
-						metricsCollector.RecordFileUploadFailed()
-					}
-				}
-			}
-		default: // "pr" or "pull_request" strategy
-			LogInfo(fmt.Sprintf("Using PR commit strategy for %s on branch %s (auto_merge=%v)", key.RepoName, key.BranchPath, mergeWithoutReview))
-			if err := addFilesViaPR(ctx, client, key, value.Content, commitMsg, prTitle, prBody, mergeWithoutReview); err != nil {
-				LogCritical(fmt.Sprintf("Failed via PR path: %v\n", err))
-				// Record failure for each file in this batch
-				if metricsCollector != nil {
-					for range value.Content {
-						metricsCollector.RecordFileUploadFailed()
-					}
-				}
-			}
+	// Get auto-merge setting from value. PRs behind an approval gate are merged once they're approved.
+	gated := value.AutoMergePR && value.ApprovalGate != nil
+	mergeWithoutReview := value.AutoMergePR && !gated
+
+	switch strategy {
+	case "direct": // commits directly to the target branch
+		LogInfoCtx(ctx, "Using direct commit strategy", map[string]interface{}{"target_repo": key.RepoName, "branch": key.BranchPath})
+		sha, err := addFilesToBranch(ctx, provider, repo, key, value.Content, value.FileModes, value.DeletePaths, commitMsg, author)
+		if err != nil {
+			LogErrorCtx(ctx, "Failed to add files to target branch", err, map[string]interface{}{"target_repo": key.RepoName, "branch": key.BranchPath})
+		}
+		return UploadResult{CommitSHA: sha, Err: err}
+	case "branch": // pushes to a branch named after the source PR, without opening a PR
+		LogInfoCtx(ctx, "Using branch commit strategy", map[string]interface{}{"target_repo": key.RepoName, "branch": value.PushBranch})
+		sha, err := addFilesToSourcePRBranch(ctx, provider, repo, key, value, commitMsg, author)
+		if err != nil {
+			LogErrorCtx(ctx, "Failed to push files to source PR branch", err, map[string]interface{}{"target_repo": key.RepoName, "branch": value.PushBranch})
+		}
+		return UploadResult{CommitSHA: sha, Err: err}
+	default: // "pr" or "pull_request" strategy
+		LogInfoCtx(ctx, "Using PR commit strategy", map[string]interface{}{"target_repo": key.RepoName, "branch": key.BranchPath, "auto_merge": mergeWithoutReview, "approval_gate": gated})
+		pr, sha, err := addFilesViaPR(ctx, provider, repo, key, value.Content, value.FileModes, value.DeletePaths, commitMsg, author, prTitle, prBody, mergeWithoutReview, value.OnConflict)
+		if err != nil {
+			LogErrorCtx(ctx, "Failed via PR path", err, map[string]interface{}{"target_repo": key.RepoName, "branch": key.BranchPath})
+		} else if !mergeWithoutReview && value.Reviewers != nil {
+			requestReviewers(ctx, provider, repo, strings.TrimPrefix(key.BranchPath, "refs/heads/"), pr.Number, value)
 		}
+		return UploadResult{PRURL: pr.URL, PRNumber: pr.Number, CommitSHA: sha, AwaitingApproval: gated && err == nil, Err: err}
 	}
 }
 
+// ErrPRCreation is wrapped by errors from opening a pull request in a target repo
+var ErrPRCreation = errors.New("could not create PR")
+
 // createPullRequest opens a pull request from head to base in the specified repository.
 func createPullRequest(ctx context.Context, client *github.Client, repo, head, base, title, body string) (*github.PullRequest, error) {
 	owner, repoName := parseRepoPath(repo)
@@ -156,118 +283,123 @@ func createPullRequest(ctx context.Context, client *github.Client, repo, head, b
 	}
 	created, _, err := client.PullRequests.Create(ctx, owner, repoName, pr)
 	if err != nil {
-		return nil, fmt.Errorf("could not create PR: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrPRCreation, err)
 	}
 	return created, nil
 }
 
+// lastTempPRBranch is when the last temporary PR branch was named
+var (
+	lastTempPRBranch   time.Time
+	lastTempPRBranchMu sync.Mutex
+)
+
+// tempPRBranch names the temporary branch a PR is opened from after the time, like "copier/20250101-120000".
+// PRs opened within the same second, such as one for each branch of a destination listing several, are
+// named after the following seconds so they don't share a branch.
+func tempPRBranch(now time.Time) string {
+	lastTempPRBranchMu.Lock()
+	defer lastTempPRBranchMu.Unlock()
+	now = now.UTC().Truncate(time.Second)
+	if !now.After(lastTempPRBranch) {
+		now = lastTempPRBranch.Add(time.Second)
+	}
+	lastTempPRBranch = now
+	return "copier/" + now.Format("20060102-150405")
+}
+
 // addFilesViaPR creates a temporary branch, commits files to it using the provided commitMessage,
 // opens a pull request with prTitle and prBody, and optionally merges it automatically.
-func addFilesViaPR(ctx context.Context, client *github.Client, key UploadKey,
-	files []github.RepositoryContent, commitMessage string, prTitle string, prBody string, mergeWithoutReview bool,
-) error {
-	tempBranch := "copier/" + time.Now().UTC().Format("20060102-150405")
+// repo is the target repo's path on the provider's platform.
+// fileModes holds the Git mode for non-regular files, keyed by target path. deletePaths are removed in the same commit.
+// author is the commit author, or nil for the authenticated app.
+// onConflict decides what happens if the PR conflicts with the target branch when it's merged.
+// Returns the pull request once it's opened, even if it then can't be merged, and the merge commit's SHA
+// if it was merged.
+func addFilesViaPR(ctx context.Context, provider RepoProvider, repo string, key UploadKey,
+	files []github.RepositoryContent, fileModes map[string]string, deletePaths []string, commitMessage string, author *github.CommitAuthor, prTitle string, prBody string, mergeWithoutReview bool,
+	onConflict string,
+) (ProviderPullRequest, string, error) {
+	tempBranch := tempPRBranch(time.Now())
 
 	// 1) Create branch off the target branch specified in key.BranchPath or default to "main"
 	baseBranch := strings.TrimPrefix(key.BranchPath, "refs/heads/")
-	newRef, err := createBranch(ctx, client, key.RepoName, tempBranch, baseBranch)
-	if err != nil {
-		return fmt.Errorf("create branch: %w", err)
+	if err := provider.CreateBranch(ctx, repo, tempBranch, baseBranch); err != nil {
+		return ProviderPullRequest{}, "", fmt.Errorf("create branch: %w", err)
 	}
-	_ = newRef // we just need it created; ref is not reused directly
 
 	// 2) Commit files to temp branch
-	entries := make(map[string]string, len(files))
-	for _, f := range files {
-		content, _ := f.GetContent()
-		entries[f.GetName()] = content
-	}
-
-	tempKey := UploadKey{RepoName: key.RepoName, BranchPath: "refs/heads/" + tempBranch}
-	treeSHA, baseSHA, err := createCommitTree(ctx, client, tempKey, entries)
-	if err != nil {
-		return fmt.Errorf("create tree on temp branch: %w", err)
+	commit := ProviderCommit{
+		Files:       uploadEntries(files),
+		FileModes:   fileModes,
+		DeletePaths: deletePaths,
+		Message:     commitMessage,
+		Author:      author,
 	}
-	if err = createCommit(ctx, client, tempKey, baseSHA, treeSHA, commitMessage); err != nil {
-		return fmt.Errorf("create commit on temp branch: %w", err)
+	if _, err := provider.CommitFiles(ctx, repo, tempBranch, commit); err != nil {
+		return ProviderPullRequest{}, "", fmt.Errorf("commit to temp branch: %w", err)
 	}
 
 	// 3) Create PR from temp branch to base branch
-	base := strings.TrimPrefix(key.BranchPath, "refs/heads/")
-	pr, err := createPullRequest(ctx, client, key.RepoName, tempBranch, base, prTitle, prBody)
+	pr, err := provider.OpenPullRequest(ctx, repo, tempBranch, baseBranch, prTitle, prBody)
 	if err != nil {
-		return fmt.Errorf("create PR: %w", err)
+		return ProviderPullRequest{}, "", fmt.Errorf("create PR: %w", err)
 	}
 
-	// 4) Optionally merge the PR without review if MergeWithoutReview is true
-	LogInfo(fmt.Sprintf("PR created: #%d from %s to %s", pr.GetNumber(), tempBranch, base))
-	LogInfo(fmt.Sprintf("PR URL: %s", pr.GetHTMLURL()))
-	if mergeWithoutReview {
-		// Poll PR for mergeability; GitHub may take a moment to compute it
-		// Get polling configuration from environment or use defaults
-		cfg := configs.NewConfig()
-		maxAttempts := cfg.PRMergePollMaxAttempts
-		if envAttempts := os.Getenv(configs.PRMergePollMaxAttempts); envAttempts != "" {
-			if parsed, err := parseIntWithDefault(envAttempts, maxAttempts); err == nil {
-				maxAttempts = parsed
-			}
-		}
+	// 4) Label the PR so merging it doesn't trigger more copies
+	provider.LabelPullRequest(ctx, repo, pr.Number, getEnvOrDefault(configs.CopierPRLabel, configs.NewConfig().CopierPRLabel))
 
-		pollInterval := cfg.PRMergePollInterval
-		if envInterval := os.Getenv(configs.PRMergePollInterval); envInterval != "" {
-			if parsed, err := parseIntWithDefault(envInterval, pollInterval); err == nil {
-				pollInterval = parsed
-			}
-		}
-
-		var mergeable *bool
-		var mergeableState string
-		owner, repoName := parseRepoPath(key.RepoName)
-		for i := 0; i < maxAttempts; i++ {
-			current, _, gerr := client.PullRequests.Get(ctx, owner, repoName, pr.GetNumber())
-			if gerr == nil && current != nil {
-				mergeable = current.Mergeable
-				mergeableState = current.GetMergeableState()
-				if mergeable != nil { // computed
-					break
-				}
-			}
-			time.Sleep(time.Duration(pollInterval) * time.Millisecond)
-		}
-		if mergeable != nil && !*mergeable || strings.EqualFold(mergeableState, "dirty") {
-			LogWarning(fmt.Sprintf("PR #%d is not mergeable (state=%s). Likely merge conflicts. Leaving PR open for manual resolution.", pr.GetNumber(), mergeableState))
-			return fmt.Errorf("pull request #%d has merge conflicts (state=%s)", pr.GetNumber(), mergeableState)
+	// 5) Optionally merge the PR without review if MergeWithoutReview is true
+	LogInfoCtx(ctx, "PR created", map[string]interface{}{
+		"target_repo": key.RepoName,
+		"pr_number":   pr.Number,
+		"head":        tempBranch,
+		"base":        baseBranch,
+		"pr_url":      pr.URL,
+	})
+	if mergeWithoutReview {
+		sha, err := provider.MergePullRequest(ctx, repo, pr.Number)
+		if errors.Is(err, ErrMergeConflict) {
+			sha, err = resolveMergeConflict(ctx, provider, repo, pr, tempBranch, baseBranch, commit, onConflict, err)
 		}
-		if err = mergePR(ctx, client, key.RepoName, pr.GetNumber()); err != nil {
-			return fmt.Errorf("merge PR: %w", err)
+		if err != nil {
+			return pr, "", err
 		}
-		deleteBranchIfExists(ctx, client, key.RepoName, &github.Reference{Ref: github.String("refs/heads/" + tempBranch)})
-	} else {
-		LogInfo(fmt.Sprintf("PR created and awaiting review: #%d", pr.GetNumber()))
+		provider.DeleteBranch(ctx, repo, tempBranch)
+		return pr, sha, nil
 	}
-	return nil
+	LogInfoCtx(ctx, "PR created and awaiting review", map[string]interface{}{"target_repo": key.RepoName, "pr_number": pr.Number})
+	return pr, "", nil
 }
 
-// addFilesToBranch builds a tree, creates a commit, and updates the ref (direct to target branch)
-func addFilesToBranch(ctx context.Context, client *github.Client, key UploadKey,
-	files []github.RepositoryContent, message string) error {
+// addFilesToBranch commits the files directly to the target branch, returning the new commit's SHA
+// fileModes holds the Git mode for non-regular files, keyed by target path. deletePaths are removed in the same commit.
+func addFilesToBranch(ctx context.Context, provider RepoProvider, repo string, key UploadKey,
+	files []github.RepositoryContent, fileModes map[string]string, deletePaths []string, message string, author *github.CommitAuthor) (string, error) {
 
+	commit := ProviderCommit{
+		Files:       uploadEntries(files),
+		FileModes:   fileModes,
+		DeletePaths: deletePaths,
+		Message:     message,
+		Author:      author,
+	}
+	sha, err := provider.CommitFiles(ctx, repo, strings.TrimPrefix(key.BranchPath, "refs/heads/"), commit)
+	if err != nil {
+		LogCriticalCtx(ctx, fmt.Sprintf("Error committing to target branch: %v", err), nil)
+		return "", err
+	}
+	return sha, nil
+}
+
+// uploadEntries returns the content of queued files keyed by target path
+func uploadEntries(files []github.RepositoryContent) map[string]string {
 	entries := make(map[string]string, len(files))
 	for _, f := range files {
 		content, _ := f.GetContent()
 		entries[f.GetName()] = content
 	}
-
-	treeSHA, baseSHA, err := createCommitTree(ctx, client, key, entries)
-	if err != nil {
-		LogCritical(fmt.Sprintf("Error creating commit tree: %v\n", err))
-		return err
-	}
-	if err := createCommit(ctx, client, key, baseSHA, treeSHA, message); err != nil {
-		LogCritical(fmt.Sprintf("Error creating commit: %v\n", err))
-		return err
-	}
-	return nil
+	return entries
 }
 
 // createBranch creates a new branch from the specified base branch (defaults to 'main') and deletes it first if it already exists.
@@ -284,7 +416,7 @@ func createBranch(ctx context.Context, client *github.Client, repo, newBranch st
 
 	baseRef, _, err := client.Git.GetRef(ctx, owner, repoName, "refs/heads/"+base)
 	if err != nil {
-		LogCritical(fmt.Sprintf("Failed to get '%s' baseRef: %s", base, err))
+		LogCriticalCtx(ctx, fmt.Sprintf("Failed to get '%s' baseRef: %s", base, err), nil)
 		return nil, err
 	}
 
@@ -301,24 +433,26 @@ func createBranch(ctx context.Context, client *github.Client, repo, newBranch st
 
 	newBranchRef, _, err = client.Git.CreateRef(ctx, owner, repoName, newRef)
 	if err != nil {
-		LogCritical(fmt.Sprintf("Failed to create newBranchRef %s:  %s", newRef, err))
+		LogCriticalCtx(ctx, fmt.Sprintf("Failed to create newBranchRef %s:  %s", newRef, err), nil)
 		return nil, err
 	}
 
-	LogInfo(fmt.Sprintf("Branch created successfully: %s on %s (from %s)", newRef, normalizedRepo, base))
+	LogInfoCtx(ctx, fmt.Sprintf("Branch created successfully: %s on %s (from %s)", newRef, normalizedRepo, base), nil)
 
 	return newBranchRef, nil
 }
 
 // createCommitTree looks up the branch ref once, then builds a tree on top of that base commit.
+// Each entry uses the file's mode from fileModes (e.g. "100755" for executable scripts), or 100644.
+// deletePaths are removed from the tree, unless the same path is also being written.
 func createCommitTree(ctx context.Context, client *github.Client, targetBranch UploadKey,
-	files map[string]string) (treeSHA string, baseSHA string, err error) {
+	files map[string]string, fileModes map[string]string, deletePaths []string) (treeSHA string, baseSHA string, err error) {
 
 	// Normalize repo name for consistent logging
 	normalizedRepo := normalizeRepoName(targetBranch.RepoName)
 	owner, repoName := parseRepoPath(normalizedRepo)
-	LogInfo(fmt.Sprintf("DEBUG createCommitTree: targetBranch.RepoName=%q, normalized=%q, parsed owner=%q, repoName=%q",
-		targetBranch.RepoName, normalizedRepo, owner, repoName))
+	LogInfoCtx(ctx, fmt.Sprintf("DEBUG createCommitTree: targetBranch.RepoName=%q, normalized=%q, parsed owner=%q, repoName=%q",
+		targetBranch.RepoName, normalizedRepo, owner, repoName), nil)
 
 	// 1) Get current ref with retry logic to handle GitHub API eventual consistency
 	// When a branch is just created, it may take a moment to be visible
@@ -349,8 +483,8 @@ func createCommitTree(ctx context.Context, client *github.Client, targetBranch U
 		}
 
 		if attempt < maxRetries {
-			LogWarning(fmt.Sprintf("Failed to get ref for %s (attempt %d/%d): %v. Retrying in %v...",
-				normalizedRepo, attempt, maxRetries, err, retryDelay))
+			LogWarningCtx(ctx, fmt.Sprintf("Failed to get ref for %s (attempt %d/%d): %v. Retrying in %v...",
+				normalizedRepo, attempt, maxRetries, err, retryDelay), nil)
 			time.Sleep(retryDelay)
 			retryDelay *= 2 // Exponential backoff
 		}
@@ -360,7 +494,7 @@ func createCommitTree(ctx context.Context, client *github.Client, targetBranch U
 		if err == nil {
 			err = errors.Errorf("targetRef is nil after %d attempts", maxRetries)
 		}
-		LogCritical(fmt.Sprintf("Failed to get ref for %s after %d attempts: %v\n", normalizedRepo, maxRetries, err))
+		LogCriticalCtx(ctx, fmt.Sprintf("Failed to get ref for %s after %d attempts: %v", normalizedRepo, maxRetries, err), nil)
 		return "", "", err
 	}
 	baseSHA = ref.GetObject().GetSHA()
@@ -371,10 +505,21 @@ func createCommitTree(ctx context.Context, client *github.Client, targetBranch U
 		treeEntries = append(treeEntries, &github.TreeEntry{
 			Path:    github.String(path),
 			Type:    github.String("blob"),
-			Mode:    github.String("100644"),
+			Mode:    github.String(treeEntryMode(fileModes, path)),
 			Content: github.String(content),
 		})
 	}
+	for _, path := range deletePaths {
+		if _, writing := files[path]; writing {
+			continue
+		}
+		// An entry with neither content nor SHA deletes the file
+		treeEntries = append(treeEntries, &github.TreeEntry{
+			Path: github.String(path),
+			Type: github.String("blob"),
+			Mode: github.String(FileModeRegular),
+		})
+	}
 
 	// 3) Create tree on top of baseSHA
 	tree, _, err := client.Git.CreateTree(ctx, owner, repoName, baseSHA, treeEntries)
@@ -384,9 +529,21 @@ func createCommitTree(ctx context.Context, client *github.Client, targetBranch U
 	return tree.GetSHA(), baseSHA, nil
 }
 
+// treeEntryMode returns the Git mode to use for a blob tree entry. Only regular and executable
+// modes are preserved; anything else (e.g. symlinks, whose content we copy as a regular file) is
+// written as a regular file.
+func treeEntryMode(fileModes map[string]string, path string) string {
+	if fileModes[path] == FileModeExecutable {
+		return FileModeExecutable
+	}
+	return FileModeRegular
+}
+
 // createCommit makes the commit using the provided baseSHA, and updates the branch ref to the new commit.
+// Returns the new commit's SHA.
+// author is the commit author, or nil for the authenticated app.
 func createCommit(ctx context.Context, client *github.Client, targetBranch UploadKey,
-	baseSHA string, treeSHA string, message string) error {
+	baseSHA string, treeSHA string, message string, author *github.CommitAuthor) (string, error) {
 
 	owner, repoName := parseRepoPath(targetBranch.RepoName)
 
@@ -395,11 +552,12 @@ func createCommit(ctx context.Context, client *github.Client, targetBranch Uploa
 		Message: github.String(message),
 		Tree:    &github.Tree{SHA: github.String(treeSHA)},
 		Parents: []*github.Commit{parent},
+		Author:  author,
 	}
 
 	newCommit, _, err := client.Git.CreateCommit(ctx, owner, repoName, commit)
 	if err != nil {
-		return fmt.Errorf("could not create commit: %w", err)
+		return "", fmt.Errorf("could not create commit: %w", err)
 	}
 
 	// Update branch ref directly (no second GET)
@@ -411,16 +569,16 @@ func createCommit(ctx context.Context, client *github.Client, targetBranch Uploa
 		// Detect non-fast-forward / conflict scenarios and provide a clearer error
 		if eresp, ok := err.(*github.ErrorResponse); ok {
 			if eresp.Response != nil && eresp.Response.StatusCode == http.StatusUnprocessableEntity {
-				return fmt.Errorf("failed to update ref: non-fast-forward (possible conflict). Consider using PR strategy: %w", err)
+				return "", fmt.Errorf("failed to update ref: non-fast-forward (possible conflict). Consider using PR strategy: %w", err)
 			}
 		}
-		return fmt.Errorf("failed to update ref to new commit: %w", err)
+		return "", fmt.Errorf("failed to update ref to new commit: %w", err)
 	}
-	return nil
+	return newCommit.GetSHA(), nil
 }
 
-// mergePR merges the specified pull request in the given repository.
-func mergePR(ctx context.Context, client *github.Client, repo string, pr_number int) error {
+// mergePR merges the specified pull request in the given repository, returning the merge commit's SHA.
+func mergePR(ctx context.Context, client *github.Client, repo string, pr_number int) (string, error) {
 	owner, repoName := parseRepoPath(repo)
 
 	options := &github.PullRequestOptions{
@@ -428,15 +586,15 @@ func mergePR(ctx context.Context, client *github.Client, repo string, pr_number
 	}
 	result, _, err := client.PullRequests.Merge(ctx, owner, repoName, pr_number, "Merging the pull request", options)
 	if err != nil {
-		LogCritical(fmt.Sprintf("Failed to merge PR: %v\n", err))
-		return err
+		LogCriticalCtx(ctx, fmt.Sprintf("Failed to merge PR: %v", err), nil)
+		return "", err
 	}
 	if result.GetMerged() {
-		LogInfo(fmt.Sprintf("Successfully merged PR #%d\n", pr_number))
-		return nil
+		LogInfoCtx(ctx, fmt.Sprintf("Successfully merged PR #%d", pr_number), nil)
+		return result.GetSHA(), nil
 	} else {
-		LogError(fmt.Sprintf("Failed to merge PR #%d: %s", pr_number, result.GetMessage()))
-		return fmt.Errorf("failed to merge PR #%d: %s", pr_number, result.GetMessage())
+		LogErrorCtx(ctx, fmt.Sprintf("Failed to merge PR #%d: %s", pr_number, result.GetMessage()), nil, nil)
+		return "", fmt.Errorf("failed to merge PR #%d: %s", pr_number, result.GetMessage())
 	}
 }
 
@@ -456,13 +614,13 @@ func deleteBranchIfExists(backgroundContext context.Context, client *github.Clie
 		log.Fatal()
 	}
 
-	LogInfo(fmt.Sprintf("Deleting branch %s on %s", ref.GetRef(), normalizedRepo))
+	LogInfoCtx(backgroundContext, fmt.Sprintf("Deleting branch %s on %s", ref.GetRef(), normalizedRepo), nil)
 	_, _, err := client.Git.GetRef(backgroundContext, owner, repoName, ref.GetRef())
 
 	if err == nil { // Branch exists (there was no error fetching it)
 		_, err = client.Git.DeleteRef(backgroundContext, owner, repoName, ref.GetRef())
 		if err != nil {
-			LogCritical(fmt.Sprintf("Error deleting branch: %v\n", err))
+			LogCriticalCtx(backgroundContext, fmt.Sprintf("Error deleting branch: %v", err), nil)
 		}
 	}
 }
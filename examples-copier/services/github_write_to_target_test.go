@@ -6,6 +6,7 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"io"
 	"net/http"
@@ -270,6 +271,131 @@ func TestAddFilesToTargetRepoBranch_Succeeds(t *testing.T) {
 	services.FilesToUpload = nil
 }
 
+func TestAddFilesToTargetRepoBranch_PreservesFileModes(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+
+	owner, repo := test.EnvOwnerRepo(t)
+	branch := "main"
+
+	test.SetupOrgToken(owner, "test-token")
+	test.MockGitHubWriteEndpoints(owner, repo, branch)
+
+	// Replace the tree responder so the request body can be inspected
+	var treeRequest struct {
+		BaseTree string `json:"base_tree"`
+		Tree     []struct {
+			Path string `json:"path"`
+			Mode string `json:"mode"`
+			Type string `json:"type"`
+		} `json:"tree"`
+	}
+	treesRe := regexp.MustCompile(`^https://api\.github\.com/repos/` + regexp.QuoteMeta(owner) + `/` +
+		regexp.QuoteMeta(repo) + `/git/trees(\?.*)?$`)
+	httpmock.RegisterRegexpResponder("POST", treesRe, func(req *http.Request) (*http.Response, error) {
+		if err := json.NewDecoder(req.Body).Decode(&treeRequest); err != nil {
+			return httpmock.NewStringResponse(400, err.Error()), nil
+		}
+		return httpmock.NewJsonResponse(201, map[string]any{"sha": "newTreeSha"})
+	})
+
+	files := []github.RepositoryContent{
+		{
+			Name:    github.String("app/setup.sh"),
+			Path:    github.String("app/setup.sh"),
+			Content: github.String(base64.StdEncoding.EncodeToString([]byte("#!/bin/sh\necho setup\n"))),
+		},
+		{
+			Name:    github.String("app/README.md"),
+			Path:    github.String("app/README.md"),
+			Content: github.String(base64.StdEncoding.EncodeToString([]byte("# App"))),
+		},
+		{
+			Name:    github.String("app/link"),
+			Path:    github.String("app/link"),
+			Content: github.String(base64.StdEncoding.EncodeToString([]byte("README.md"))),
+		},
+	}
+	services.FilesToUpload = map[types.UploadKey]types.UploadFileContent{
+		{RepoName: repo, BranchPath: "refs/heads/" + branch}: {
+			TargetBranch: branch,
+			Content:      files,
+			FileModes: map[string]string{
+				"app/setup.sh": types.FileModeExecutable,
+				// Symlinks are copied as regular files
+				"app/link": "120000",
+			},
+		},
+	}
+	defer func() { services.FilesToUpload = nil }()
+
+	services.AddFilesToTargetRepoBranch()
+
+	require.Equal(t, "baseSha", treeRequest.BaseTree)
+	modes := map[string]string{}
+	for _, entry := range treeRequest.Tree {
+		require.Equal(t, "blob", entry.Type)
+		modes[entry.Path] = entry.Mode
+	}
+	require.Equal(t, map[string]string{
+		"app/setup.sh":  "100755",
+		"app/README.md": "100644",
+		"app/link":      "100644",
+	}, modes)
+}
+
+func TestAddFilesToTargetRepoBranch_DeletesSyncedPaths(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+
+	owner, repo := test.EnvOwnerRepo(t)
+	branch := "main"
+
+	test.SetupOrgToken(owner, "test-token")
+	test.MockGitHubWriteEndpoints(owner, repo, branch)
+
+	// Decode entries as raw JSON so a null SHA can be told apart from a missing one
+	var treeRequest struct {
+		Tree []map[string]json.RawMessage `json:"tree"`
+	}
+	treesRe := regexp.MustCompile(`^https://api\.github\.com/repos/` + regexp.QuoteMeta(owner) + `/` +
+		regexp.QuoteMeta(repo) + `/git/trees(\?.*)?$`)
+	httpmock.RegisterRegexpResponder("POST", treesRe, func(req *http.Request) (*http.Response, error) {
+		if err := json.NewDecoder(req.Body).Decode(&treeRequest); err != nil {
+			return httpmock.NewStringResponse(400, err.Error()), nil
+		}
+		return httpmock.NewJsonResponse(201, map[string]any{"sha": "newTreeSha"})
+	})
+
+	services.FilesToUpload = map[types.UploadKey]types.UploadFileContent{
+		{RepoName: repo, BranchPath: "refs/heads/" + branch}: {
+			TargetBranch: branch,
+			Content: []github.RepositoryContent{
+				{
+					Name:    github.String("server/main.go"),
+					Path:    github.String("server/main.go"),
+					Content: github.String(base64.StdEncoding.EncodeToString([]byte("package main"))),
+				},
+			},
+			// A path that's also being written is not deleted
+			DeletePaths: []string{"server/old.go", "server/main.go"},
+		},
+	}
+	defer func() { services.FilesToUpload = nil }()
+
+	services.AddFilesToTargetRepoBranch()
+
+	require.Len(t, treeRequest.Tree, 2)
+	entries := map[string]map[string]json.RawMessage{}
+	for _, entry := range treeRequest.Tree {
+		var path string
+		require.NoError(t, json.Unmarshal(entry["path"], &path))
+		entries[path] = entry
+	}
+	require.Contains(t, entries, "server/old.go")
+	require.Equal(t, "null", string(entries["server/old.go"]["sha"]))
+	require.NotContains(t, entries["server/old.go"], "content")
+	require.Contains(t, entries["server/main.go"], "content")
+}
+
 func TestAddFilesToTargetRepoBranch_ViaPR_Succeeds(t *testing.T) {
 	_ = test.WithHTTPMock(t)
 	t.Setenv("COPIER_COMMIT_STRATEGY", "pr")
@@ -277,8 +403,7 @@ func TestAddFilesToTargetRepoBranch_ViaPR_Succeeds(t *testing.T) {
 	owner, repo := test.EnvOwnerRepo(t)
 	baseBranch := "main"
 
-	// Force fresh token; stub token endpoint then configure permissions.
-	services.InstallationAccessToken = ""
+	// Stub the token endpoint; ConfigurePermissions issues a fresh token.
 	test.MockGitHubAppTokenEndpoint(os.Getenv(configs.InstallationId))
 	services.ConfigurePermissions()
 
@@ -443,7 +568,6 @@ func TestAddFiles_ViaPR_MergeConflict_Dirty_NotMerged(t *testing.T) {
 	baseBranch := "main"
 
 	// Fresh token path
-	services.InstallationAccessToken = ""
 	test.MockGitHubAppTokenEndpoint(os.Getenv(configs.InstallationId))
 	services.ConfigurePermissions()
 
@@ -591,7 +715,6 @@ func TestPriority_PRTitleDefaultsToCommitMessage_And_NoAutoMergeWhenConfigPresen
 	baseBranch := "main"
 
 	// Token setup
-	services.InstallationAccessToken = ""
 	test.MockGitHubAppTokenEndpoint(os.Getenv(configs.InstallationId))
 	services.ConfigurePermissions()
 
@@ -664,7 +787,6 @@ func TestDeleteBranchIfExists_NilReference(t *testing.T) {
 	_ = test.WithHTTPMock(t)
 
 	// Force fresh token
-	services.InstallationAccessToken = ""
 	test.MockGitHubAppTokenEndpoint(os.Getenv(configs.InstallationId))
 	services.ConfigurePermissions()
 
@@ -679,3 +801,51 @@ func TestDeleteBranchIfExists_NilReference(t *testing.T) {
 	// Verify no DELETE requests were made (since ref was nil)
 	require.Equal(t, 0, test.CountByMethodAndURLRegexp("DELETE", regexp.MustCompile(`/git/refs/`)))
 }
+
+func TestUpdateDeprecationFile_RetriesWhenTheFileChanged(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	test.SetupOrgToken("", "test-token")
+	t.Setenv(configs.DeprecationFile, "deprecated_examples.json")
+
+	owner, repo := test.EnvOwnerRepo(t)
+	url := "https://api.github.com/repos/" + owner + "/" + repo + "/contents/deprecated_examples.json"
+
+	// Another run writes the file between the first read and write
+	reads := 0
+	httpmock.RegisterResponder("GET", url+"?ref=main", func(req *http.Request) (*http.Response, error) {
+		reads++
+		sha := "first"
+		if reads > 1 {
+			sha = "second"
+		}
+		return httpmock.NewJsonResponse(200, map[string]any{
+			"type":     "file",
+			"encoding": "base64",
+			"sha":      sha,
+			"content":  base64.StdEncoding.EncodeToString([]byte("[]")),
+		})
+	})
+	var writtenSHAs []string
+	httpmock.RegisterResponder("PUT", url, func(req *http.Request) (*http.Response, error) {
+		var body struct {
+			SHA string `json:"sha"`
+		}
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		writtenSHAs = append(writtenSHAs, body.SHA)
+		if body.SHA != "second" {
+			return httpmock.NewStringResponse(409, `{"message": "is at second but expected first"}`), nil
+		}
+		return httpmock.NewJsonResponse(200, map[string]any{"content": map[string]any{"sha": "third"}})
+	})
+
+	prev := services.FilesToDeprecate
+	t.Cleanup(func() { services.FilesToDeprecate = prev })
+	services.FilesToDeprecate = map[string]types.Configs{
+		"examples/old.go": {TargetRepo: "org/target", TargetBranch: "main"},
+	}
+
+	services.UpdateDeprecationFile()
+
+	require.Equal(t, 2, reads)
+	require.Equal(t, []string{"first", "second"}, writtenSHAs)
+}
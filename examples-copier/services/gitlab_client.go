@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	. "github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+const (
+	defaultGitLabBaseURL = "https://gitlab.com"
+	gitlabPerPage        = 100
+)
+
+// GitLabClient reads merge requests and files from GitLab source repos using the REST API (v4).
+// Projects are identified by their full path, such as "group/subgroup/project".
+type GitLabClient struct {
+	baseURL string
+	token   string
+}
+
+// GitLabError is returned for API requests that fail with an error status
+type GitLabError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *GitLabError) Error() string {
+	return fmt.Sprintf("GitLab API returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// NewGitLabClient creates a client for the GitLab instance at baseURL, authenticated with token.
+// An empty token only works for public projects.
+func NewGitLabClient(baseURL string, token string) *GitLabClient {
+	if baseURL == "" {
+		baseURL = defaultGitLabBaseURL
+	}
+	return &GitLabClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+	}
+}
+
+// GetGitLabClient returns a GitLab client configured from GITLAB_BASE_URL and GITLAB_TOKEN
+func GetGitLabClient() *GitLabClient {
+	return NewGitLabClient(os.Getenv(configs.GitLabBaseURL), os.Getenv(configs.GitLabToken))
+}
+
+// gitlabDiff is one entry from the merge request diffs endpoint
+type gitlabDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// gitlabFile is the response from the repository files endpoint
+type gitlabFile struct {
+	FileName string `json:"file_name"`
+	FilePath string `json:"file_path"`
+	Size     int    `json:"size"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+	BlobID   string `json:"blob_id"`
+}
+
+// gitlabTreeEntry is one entry from the repository tree endpoint
+type gitlabTreeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Mode string `json:"mode"`
+}
+
+// GetMergeRequestChanges lists the files changed in a merge request, mapped to the same
+// statuses GitHub reports (ADDED, MODIFIED, DELETED, RENAMED) so workflows treat them the same way.
+func (c *GitLabClient) GetMergeRequestChanges(ctx context.Context, projectPath string, iid int) ([]ChangedFile, error) {
+	var changedFiles []ChangedFile
+	endpoint := fmt.Sprintf("/projects/%s/merge_requests/%d/diffs", gitlabEscape(projectPath), iid)
+
+	for page := 1; page != 0; {
+		var diffs []gitlabDiff
+		nextPage, err := c.get(ctx, endpoint, url.Values{"page": {strconv.Itoa(page)}, "per_page": {strconv.Itoa(gitlabPerPage)}}, &diffs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get changes for merge request !%d in %s: %w", iid, projectPath, err)
+		}
+		for _, diff := range diffs {
+			changedFiles = append(changedFiles, gitlabChangedFile(diff))
+		}
+		page = nextPage
+	}
+
+	LogInfo(fmt.Sprintf("MR has %d changed files.", len(changedFiles)))
+	return changedFiles, nil
+}
+
+// gitlabChangedFile converts a merge request diff to a ChangedFile, counting added and removed lines
+func gitlabChangedFile(diff gitlabDiff) ChangedFile {
+	file := ChangedFile{Path: diff.NewPath, Status: "MODIFIED"}
+	switch {
+	case diff.NewFile:
+		file.Status = "ADDED"
+	case diff.DeletedFile:
+		file.Path = diff.OldPath
+		file.Status = statusDeleted
+	case diff.RenamedFile:
+		file.Status = "RENAMED"
+	}
+
+	for _, line := range strings.Split(diff.Diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			file.Additions++
+		case strings.HasPrefix(line, "-"):
+			file.Deletions++
+		}
+	}
+	return file
+}
+
+// GetFileContents fetches a file from a project at the given commit or ref. The result is returned
+// as a GitHub RepositoryContent so it can be queued for upload like a file from a GitHub source.
+func (c *GitLabClient) GetFileContents(ctx context.Context, projectPath string, filePath string, ref string) (*github.RepositoryContent, error) {
+	var file gitlabFile
+	endpoint := fmt.Sprintf("/projects/%s/repository/files/%s", gitlabEscape(projectPath), gitlabEscape(filePath))
+	if _, err := c.get(ctx, endpoint, url.Values{"ref": {ref}}, &file); err != nil {
+		return nil, fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	return &github.RepositoryContent{
+		Type:     github.String("file"),
+		Name:     github.String(file.FileName),
+		Path:     github.String(file.FilePath),
+		Encoding: github.String(file.Encoding),
+		Content:  github.String(file.Content),
+		SHA:      github.String(file.BlobID),
+		Size:     github.Int(file.Size),
+	}, nil
+}
+
+// GetFile returns a file's content at the given commit or ref. found is false if the file doesn't exist.
+func (c *GitLabClient) GetFile(ctx context.Context, projectPath string, filePath string, ref string) (string, bool, error) {
+	file, err := c.GetFileContents(ctx, projectPath, filePath, ref)
+	var glErr *GitLabError
+	if errors.As(err, &glErr) && glErr.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	content, err := file.GetContent()
+	return content, true, err
+}
+
+// GetRepoTree lists every file in a project at the given commit or ref, returning each file's
+// Git mode keyed by path.
+func (c *GitLabClient) GetRepoTree(ctx context.Context, projectPath string, ref string) (map[string]string, error) {
+	modes := make(map[string]string)
+	endpoint := fmt.Sprintf("/projects/%s/repository/tree", gitlabEscape(projectPath))
+
+	for page := 1; page != 0; {
+		var entries []gitlabTreeEntry
+		query := url.Values{
+			"ref":       {ref},
+			"recursive": {"true"},
+			"page":      {strconv.Itoa(page)},
+			"per_page":  {strconv.Itoa(gitlabPerPage)},
+		}
+		nextPage, err := c.get(ctx, endpoint, query, &entries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tree for %s at %s: %w", projectPath, ref, err)
+		}
+		for _, entry := range entries {
+			if entry.Type == "blob" {
+				modes[entry.Path] = entry.Mode
+			}
+		}
+		page = nextPage
+	}
+	return modes, nil
+}
+
+// get performs a GET request against the API and decodes the JSON response into out.
+// It returns the next page number from the X-Next-Page header, or 0 on the last page.
+func (c *GitLabClient) get(ctx context.Context, endpoint string, query url.Values, out interface{}) (int, error) {
+	reqURL := c.baseURL + "/api/v4" + endpoint
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return 0, &GitLabError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return 0, fmt.Errorf("failed to decode GitLab API response: %w", err)
+	}
+
+	nextPage, _ := strconv.Atoi(resp.Header.Get("X-Next-Page"))
+	return nextPage, nil
+}
+
+// gitlabEscape encodes a project or file path as a single URL path segment, as the API expects
+func gitlabEscape(p string) string {
+	return strings.ReplaceAll(url.PathEscape(p), "/", "%2F")
+}
@@ -0,0 +1,96 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/mongodb/code-example-tooling/code-copier/services"
+	test "github.com/mongodb/code-example-tooling/code-copier/tests"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gitlabTestProjectURL = "https://gitlab.example.com/api/v4/projects/docs%2Fexamples%2Fpython"
+
+func TestGitLabClient_GetMergeRequestChanges(t *testing.T) {
+	test.WithHTTPMock(t)
+
+	page1 := httpmock.NewJsonResponderOrPanic(http.StatusOK, []map[string]interface{}{
+		{"old_path": "src/new.py", "new_path": "src/new.py", "new_file": true, "diff": "@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"old_path": "src/app.py", "new_path": "src/app.py", "diff": "@@ -1,2 +1,2 @@\n-old\n+new\n context\n"},
+	}).HeaderSet(http.Header{"X-Next-Page": {"2"}})
+	page2 := httpmock.NewJsonResponderOrPanic(http.StatusOK, []map[string]interface{}{
+		{"old_path": "src/gone.py", "new_path": "src/gone.py", "deleted_file": true, "diff": "@@ -1 +0,0 @@\n-x\n"},
+		{"old_path": "src/old_name.py", "new_path": "src/new_name.py", "renamed_file": true, "diff": ""},
+	})
+	httpmock.RegisterResponderWithQuery("GET", gitlabTestProjectURL+"/merge_requests/7/diffs", "page=1&per_page=100", page1)
+	httpmock.RegisterResponderWithQuery("GET", gitlabTestProjectURL+"/merge_requests/7/diffs", "page=2&per_page=100", page2)
+
+	client := services.NewGitLabClient("https://gitlab.example.com/", "token")
+	files, err := client.GetMergeRequestChanges(context.Background(), "docs/examples/python", 7)
+	require.NoError(t, err)
+
+	assert.Equal(t, []types.ChangedFile{
+		{Path: "src/new.py", Additions: 2, Status: "ADDED"},
+		{Path: "src/app.py", Additions: 1, Deletions: 1, Status: "MODIFIED"},
+		{Path: "src/gone.py", Deletions: 1, Status: "DELETED"},
+		{Path: "src/new_name.py", Status: "RENAMED"},
+	}, files)
+}
+
+func TestGitLabClient_GetFileContents(t *testing.T) {
+	test.WithHTTPMock(t)
+
+	httpmock.RegisterResponderWithQuery("GET", gitlabTestProjectURL+"/repository/files/src%2Fapp.py", "ref=abc123",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "token", req.Header.Get("PRIVATE-TOKEN"))
+			return httpmock.NewJsonResponse(http.StatusOK, map[string]interface{}{
+				"file_name": "app.py",
+				"file_path": "src/app.py",
+				"size":      5,
+				"encoding":  "base64",
+				"content":   "aGVsbG8=",
+				"blob_id":   "blob1",
+			})
+		})
+
+	client := services.NewGitLabClient("https://gitlab.example.com", "token")
+	file, err := client.GetFileContents(context.Background(), "docs/examples/python", "src/app.py", "abc123")
+	require.NoError(t, err)
+
+	content, err := file.GetContent()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", content)
+	assert.Equal(t, "src/app.py", file.GetPath())
+}
+
+func TestGitLabClient_GetFileContentsNotFound(t *testing.T) {
+	test.WithHTTPMock(t)
+
+	httpmock.RegisterResponder("GET", `=~^`+gitlabTestProjectURL+`/repository/files/`,
+		httpmock.NewStringResponder(http.StatusNotFound, `{"message":"404 File Not Found"}`))
+
+	client := services.NewGitLabClient("https://gitlab.example.com", "")
+	_, err := client.GetFileContents(context.Background(), "docs/examples/python", "missing.py", "abc123")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+}
+
+func TestGitLabClient_GetRepoTree(t *testing.T) {
+	test.WithHTTPMock(t)
+
+	httpmock.RegisterResponder("GET", `=~^`+gitlabTestProjectURL+`/repository/tree`,
+		httpmock.NewJsonResponderOrPanic(http.StatusOK, []map[string]interface{}{
+			{"path": "scripts", "type": "tree", "mode": "040000"},
+			{"path": "scripts/run.sh", "type": "blob", "mode": "100755"},
+			{"path": "README.md", "type": "blob", "mode": "100644"},
+		}))
+
+	client := services.NewGitLabClient("https://gitlab.example.com", "token")
+	modes, err := client.GetRepoTree(context.Background(), "docs/examples/python", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"scripts/run.sh": "100755", "README.md": "100644"}, modes)
+}
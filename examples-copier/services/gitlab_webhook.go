@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// gitlabMergeRequestEventType is the X-Gitlab-Event header value for merge request events
+const gitlabMergeRequestEventType = "Merge Request Hook"
+
+// gitlabMergeRequestEvent holds the fields of a GitLab merge request webhook payload the copier uses
+type gitlabMergeRequestEvent struct {
+	ObjectKind string `json:"object_kind"`
+	User       *struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project *struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		WebURL            string `json:"web_url"`
+	} `json:"project"`
+	ObjectAttributes *struct {
+		IID            int    `json:"iid"`
+		Title          string `json:"title"`
+		URL            string `json:"url"`
+		Action         string `json:"action"`
+		State          string `json:"state"`
+		TargetBranch   string `json:"target_branch"`
+		MergeCommitSHA string `json:"merge_commit_sha"`
+		LastCommit     struct {
+			ID string `json:"id"`
+		} `json:"last_commit"`
+	} `json:"object_attributes"`
+}
+
+// merged reports whether the event describes a merge request that was just merged
+func (e *gitlabMergeRequestEvent) merged() bool {
+	return e.ObjectAttributes != nil && e.ObjectAttributes.Action == "merge" && e.ObjectAttributes.State == "merged"
+}
+
+// commitSHA returns the merge commit, or the last commit of the source branch for fast-forward merges,
+// which don't create a merge commit
+func (e *gitlabMergeRequestEvent) commitSHA() string {
+	if e.ObjectAttributes.MergeCommitSHA != "" {
+		return e.ObjectAttributes.MergeCommitSHA
+	}
+	return e.ObjectAttributes.LastCommit.ID
+}
+
+// validateGitLabMergeRequestEvent checks that a merge request event carries the fields
+// the copier relies on and returns the JSON paths of any that are missing.
+// As with GitHub, the stricter checks only apply to merged MRs.
+func validateGitLabMergeRequestEvent(evt *gitlabMergeRequestEvent) []string {
+	attrs := evt.ObjectAttributes
+	if attrs == nil {
+		return []string{"object_attributes"}
+	}
+
+	var missing []string
+	if attrs.Action == "" {
+		missing = append(missing, "object_attributes.action")
+	}
+	if !evt.merged() {
+		return missing
+	}
+
+	if attrs.IID == 0 {
+		missing = append(missing, "object_attributes.iid")
+	}
+	if evt.commitSHA() == "" {
+		missing = append(missing, "object_attributes.merge_commit_sha")
+	}
+	if attrs.TargetBranch == "" {
+		missing = append(missing, "object_attributes.target_branch")
+	}
+	if evt.Project == nil || evt.Project.PathWithNamespace == "" {
+		missing = append(missing, "project.path_with_namespace")
+	}
+
+	return missing
+}
+
+// verifyGitLabToken compares the X-Gitlab-Token header with the configured secret token.
+// GitLab sends the token as-is rather than signing the payload.
+func verifyGitLabToken(token string, secret string) bool {
+	if token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+// HandleGitLabWebhookWithContainer handles incoming GitLab merge request webhooks. Merged MRs are
+// processed the same way as merged GitHub PRs, against workflows whose source platform is "gitlab".
+func HandleGitLabWebhookWithContainer(w http.ResponseWriter, r *http.Request, config *configs.Config, container *ServiceContainer) {
+	startTime := time.Now()
+	ctx := r.Context()
+
+	limited := io.LimitReader(r.Body, maxWebhookBodyBytes)
+	payload, err := io.ReadAll(limited)
+	if err != nil {
+		rejectWebhook(ctx, w, r, container, http.StatusBadRequest, WebhookErrorResponse{
+			Error:   webhookErrInvalidBody,
+			Message: "invalid body",
+		}, err)
+		return
+	}
+
+	eventType := r.Header.Get("X-Gitlab-Event")
+	if eventType == "" {
+		rejectWebhook(ctx, w, r, container, http.StatusBadRequest, WebhookErrorResponse{
+			Error:         webhookErrMissingEventType,
+			Message:       "missing event type",
+			MissingFields: []string{"X-Gitlab-Event"},
+		}, nil)
+		return
+	}
+
+	if config.GitLabWebhookSecret != "" {
+		if !verifyGitLabToken(r.Header.Get("X-Gitlab-Token"), config.GitLabWebhookSecret) {
+			rejectWebhook(ctx, w, r, container, http.StatusUnauthorized, WebhookErrorResponse{
+				Error:   webhookErrInvalidSignature,
+				Message: "webhook token verification failed",
+			}, nil)
+			return
+		}
+	}
+
+	source := container.EventSources.Webhook(types.SourcePlatformGitLab, eventType)
+	if source == nil {
+		container.MetricsCollector.RecordWebhookIgnored(eventType)
+		LogInfoCtx(ctx, "ignoring non-merge_request GitLab event", map[string]interface{}{
+			"event_type": eventType,
+			"size_bytes": len(payload),
+		})
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Keep the payload so the delivery can be replayed through /admin/replay
+	container.WebhookArchive.Record(ctx, types.SourcePlatformGitLab, eventType, payload)
+
+	event := convertEvent(ctx, w, r, source, payload, container)
+	if event == nil {
+		return
+	}
+	LogInfoCtx(ctx, "event converted", map[string]interface{}{
+		"event_type": eventType,
+		"elapsed_ms": time.Since(startTime).Milliseconds(),
+	})
+	acceptCopyEvent(ctx, w, r, *event, config, container)
+}
+
+// gitlabMergeRequestEventSource converts merged GitLab merge requests. Other merge request actions don't
+// trigger a copy.
+type gitlabMergeRequestEventSource struct{}
+
+func (gitlabMergeRequestEventSource) Name() string { return gitlabMergeRequestEventType }
+
+func (gitlabMergeRequestEventSource) Convert(ctx context.Context, payload []byte) (*CopyEvent, string, error) {
+	var mrEvt gitlabMergeRequestEvent
+	if err := json.Unmarshal(payload, &mrEvt); err != nil {
+		return nil, "", invalidPayload(err)
+	}
+	if missing := validateGitLabMergeRequestEvent(&mrEvt); len(missing) > 0 {
+		return nil, "", missingFields("merge_request", missing)
+	}
+	if !mrEvt.merged() {
+		return nil, fmt.Sprintf("MR not merged (action %q, state %q)", mrEvt.ObjectAttributes.Action, mrEvt.ObjectAttributes.State), nil
+	}
+
+	change := &CopyEvent{
+		Platform:   types.SourcePlatformGitLab,
+		Repo:       mrEvt.Project.PathWithNamespace,
+		Number:     mrEvt.ObjectAttributes.IID,
+		CommitSHA:  mrEvt.commitSHA(),
+		BaseBranch: mrEvt.ObjectAttributes.TargetBranch,
+		URL:        mrEvt.ObjectAttributes.URL,
+		Title:      mrEvt.ObjectAttributes.Title,
+	}
+	if mrEvt.User != nil {
+		change.Author = mrEvt.User.Username
+	}
+
+	LogInfoCtx(ctx, "processing merged MR", map[string]interface{}{
+		"mr_iid":      change.Number,
+		"sha":         change.CommitSHA,
+		"repo":        change.Repo,
+		"base_branch": change.BaseBranch,
+	})
+	return change, "", nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGitLabTestContainer(t *testing.T, config *configs.Config) *ServiceContainer {
+	t.Helper()
+	container, err := NewServiceContainer(config)
+	require.NoError(t, err)
+	return container
+}
+
+func TestVerifyGitLabToken(t *testing.T) {
+	assert.True(t, verifyGitLabToken("secret", "secret"))
+	assert.False(t, verifyGitLabToken("wrong", "secret"))
+	assert.False(t, verifyGitLabToken("", "secret"))
+}
+
+func TestHandleGitLabWebhook_InvalidToken(t *testing.T) {
+	config := &configs.Config{GitLabWebhookSecret: "secret"}
+	container := newGitLabTestContainer(t, config)
+
+	req := httptest.NewRequest("POST", "/gitlab/webhook", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("X-Gitlab-Event", gitlabMergeRequestEventType)
+	req.Header.Set("X-Gitlab-Token", "wrong")
+	req.Header.Set("X-Gitlab-Event-UUID", "uuid-1")
+	w := httptest.NewRecorder()
+
+	HandleGitLabWebhookWithContainer(w, req, config, container)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), webhookErrInvalidSignature)
+	assert.Contains(t, w.Body.String(), "uuid-1")
+}
+
+func TestHandleGitLabWebhook_MissingEventType(t *testing.T) {
+	config := &configs.Config{}
+	container := newGitLabTestContainer(t, config)
+
+	req := httptest.NewRequest("POST", "/gitlab/webhook", bytes.NewReader([]byte(`{}`)))
+	w := httptest.NewRecorder()
+
+	HandleGitLabWebhookWithContainer(w, req, config, container)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "X-Gitlab-Event")
+}
+
+func TestHandleGitLabWebhook_IgnoresOtherEvents(t *testing.T) {
+	config := &configs.Config{GitLabWebhookSecret: "secret"}
+	container := newGitLabTestContainer(t, config)
+
+	req := httptest.NewRequest("POST", "/gitlab/webhook", bytes.NewReader([]byte(`{"object_kind":"push"}`)))
+	req.Header.Set("X-Gitlab-Event", "Push Hook")
+	req.Header.Set("X-Gitlab-Token", "secret")
+	w := httptest.NewRecorder()
+
+	HandleGitLabWebhookWithContainer(w, req, config, container)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestHandleGitLabWebhook_NonMergedMR(t *testing.T) {
+	config := &configs.Config{}
+	container := newGitLabTestContainer(t, config)
+
+	payload := `{"object_kind":"merge_request","object_attributes":{"iid":7,"action":"open","state":"opened"}}`
+	req := httptest.NewRequest("POST", "/gitlab/webhook", bytes.NewReader([]byte(payload)))
+	req.Header.Set("X-Gitlab-Event", gitlabMergeRequestEventType)
+	w := httptest.NewRecorder()
+
+	HandleGitLabWebhookWithContainer(w, req, config, container)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestHandleGitLabWebhook_MergedMRMissingFields(t *testing.T) {
+	config := &configs.Config{}
+	container := newGitLabTestContainer(t, config)
+
+	payload := `{"object_kind":"merge_request","object_attributes":{"iid":7,"action":"merge","state":"merged"}}`
+	req := httptest.NewRequest("POST", "/gitlab/webhook", bytes.NewReader([]byte(payload)))
+	req.Header.Set("X-Gitlab-Event", gitlabMergeRequestEventType)
+	w := httptest.NewRecorder()
+
+	HandleGitLabWebhookWithContainer(w, req, config, container)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "object_attributes.merge_commit_sha")
+	assert.Contains(t, body, "object_attributes.target_branch")
+	assert.Contains(t, body, "project.path_with_namespace")
+}
+
+func TestHandleGitLabWebhook_RejectsWhileShuttingDown(t *testing.T) {
+	config := &configs.Config{}
+	container := newGitLabTestContainer(t, config)
+	container.InFlight.Drain(t.Context())
+
+	payload := `{
+		"object_kind": "merge_request",
+		"project": {"path_with_namespace": "docs/examples/python"},
+		"object_attributes": {
+			"iid": 7,
+			"action": "merge",
+			"state": "merged",
+			"target_branch": "main",
+			"merge_commit_sha": "",
+			"last_commit": {"id": "abc123"}
+		}
+	}`
+	req := httptest.NewRequest("POST", "/gitlab/webhook", bytes.NewReader([]byte(payload)))
+	req.Header.Set("X-Gitlab-Event", gitlabMergeRequestEventType)
+	w := httptest.NewRecorder()
+
+	HandleGitLabWebhookWithContainer(w, req, config, container)
+
+	// The payload is valid (fast-forward merges fall back to the last commit), so the handler
+	// gets as far as registering the job and is refused because the server is draining
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), webhookErrShuttingDown)
+}
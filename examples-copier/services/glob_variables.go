@@ -0,0 +1,278 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kinds of capture groups in a compiled glob pattern
+const (
+	globGroupWildcard = iota // ?, [...], or {...}
+	globGroupStar            // * or **
+	globGroupRelative        // an empty group marking where ${relative_path} starts
+)
+
+// GlobVariables returns the variables a glob pattern captures from a path it matches, for path
+// transforms:
+//   - ${1}, ${2}, ... are the text each wildcard (*, **, ?, [...], or {...}) matched, in pattern order
+//   - ${glob_star_1}, ${glob_star_2}, ... are the text each * or ** matched, in pattern order
+//   - ${relative_path} is the path from the first ** on, or below the pattern's directory if it has no **
+//
+// A ** that matched no directories captures "". ok is false if the pattern doesn't match the path.
+//
+// Example: "examples/*/src/**/*.go" on "examples/go/src/db/connect.go" captures ${1} and
+// ${glob_star_1} = "go", ${2} and ${glob_star_2} = "db", ${3} and ${glob_star_3} = "connect", and
+// ${relative_path} = "db/connect.go".
+func GlobVariables(pattern string, path string) (variables map[string]string, ok bool) {
+	re, groups, err := compileGlob(pattern)
+	if err != nil {
+		return nil, false
+	}
+	match := re.FindStringSubmatchIndex(path)
+	if match == nil {
+		return nil, false
+	}
+
+	variables = make(map[string]string, 2*len(groups))
+	wildcards, stars := 0, 0
+	for i, group := range groups {
+		start, end := match[2*(i+1)], match[2*(i+1)+1]
+		if group == globGroupRelative {
+			variables["relative_path"] = strings.TrimPrefix(path[start:], "/")
+			continue
+		}
+		value := ""
+		if start >= 0 {
+			value = path[start:end]
+		}
+		wildcards++
+		variables[strconv.Itoa(wildcards)] = value
+		if group == globGroupStar {
+			stars++
+			variables["glob_star_"+strconv.Itoa(stars)] = value
+		}
+	}
+	return variables, true
+}
+
+// compileGlob translates a glob pattern to an anchored regular expression with a capture group for
+// each wildcard and one marking where ${relative_path} starts, and returns the kind of each group.
+// It follows the doublestar syntax the copier matches globs with: ** only crosses directories as a
+// whole path segment, and {a,b} alternatives may contain wildcards, which aren't captured separately.
+func compileGlob(pattern string) (*regexp.Regexp, []int, error) {
+	var groups []int
+	var b strings.Builder
+	b.WriteString("^")
+
+	// Without a **, relative_path starts below the directory of the first wildcard
+	relativeAt := -1
+	if !hasDoubleStarSegment(pattern) {
+		firstWildcard := strings.IndexAny(pattern, "*?[{\\")
+		if firstWildcard < 0 {
+			firstWildcard = len(pattern)
+		}
+		relativeAt = strings.LastIndex(pattern[:firstWildcard], "/") + 1
+	}
+
+	for i := 0; i < len(pattern); {
+		if i == relativeAt {
+			b.WriteString("()")
+			groups = append(groups, globGroupRelative)
+		}
+
+		c := pattern[i]
+		switch {
+		case c == '*' && isDoubleStarSegment(pattern, i):
+			// A trailing "/**" matches the directory itself and anything below it, so the slash
+			// already written becomes optional along with what follows
+			trailing := i > 0 && i+2 == len(pattern)
+			if trailing {
+				written := strings.TrimSuffix(b.String(), "/")
+				b.Reset()
+				b.WriteString(written)
+			}
+			if relativeAt < 0 {
+				relativeAt = i
+				b.WriteString("()")
+				groups = append(groups, globGroupRelative)
+			}
+			groups = append(groups, globGroupStar)
+			switch {
+			case trailing:
+				b.WriteString("(?:/(.*))?")
+				i += 2
+			case i+2 < len(pattern):
+				// "**/" matches any number of directories, including none
+				b.WriteString("(?:(.*)/)?")
+				i += 3
+			default:
+				b.WriteString("(.*)")
+				i += 2
+			}
+		case c == '*':
+			// A * next to other text, including a ** that isn't a whole segment, stays in one segment
+			for i < len(pattern) && pattern[i] == '*' {
+				i++
+			}
+			b.WriteString("([^/]*)")
+			groups = append(groups, globGroupStar)
+		case c == '?':
+			b.WriteString("([^/])")
+			groups = append(groups, globGroupWildcard)
+			i++
+		case c == '[':
+			class, n, err := globClass(pattern[i:])
+			if err != nil {
+				return nil, nil, err
+			}
+			b.WriteString("(" + class + ")")
+			groups = append(groups, globGroupWildcard)
+			i += n
+		case c == '{':
+			alternatives, n, err := globAlternatives(pattern[i:])
+			if err != nil {
+				return nil, nil, err
+			}
+			b.WriteString("(" + alternatives + ")")
+			groups = append(groups, globGroupWildcard)
+			i += n
+		case c == '\\' && i+1 < len(pattern):
+			b.WriteString(regexp.QuoteMeta(pattern[i+1 : i+2]))
+			i += 2
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			i++
+		}
+	}
+	if relativeAt == len(pattern) {
+		b.WriteString("()")
+		groups = append(groups, globGroupRelative)
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+	return re, groups, nil
+}
+
+// isDoubleStarSegment reports whether pattern has a ** at i that is a whole path segment
+func isDoubleStarSegment(pattern string, i int) bool {
+	if !strings.HasPrefix(pattern[i:], "**") {
+		return false
+	}
+	return (i == 0 || pattern[i-1] == '/') && (i+2 == len(pattern) || pattern[i+2] == '/')
+}
+
+// hasDoubleStarSegment reports whether pattern has a ** that is a whole path segment
+func hasDoubleStarSegment(pattern string) bool {
+	for i := range pattern {
+		if isDoubleStarSegment(pattern, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// globClass translates the character class at the start of pattern, like [a-z] or [!0-9], to a
+// regular expression that doesn't match "/", and returns its length in the pattern
+func globClass(pattern string) (string, int, error) {
+	end := strings.IndexByte(pattern[1:], ']')
+	if end < 0 {
+		return "", 0, fmt.Errorf("unterminated character class in %q", pattern)
+	}
+	end++
+	// A ] right after [ or [! is part of the class
+	if end == 1 || (end == 2 && (pattern[1] == '!' || pattern[1] == '^')) {
+		next := strings.IndexByte(pattern[end+1:], ']')
+		if next < 0 {
+			return "", 0, fmt.Errorf("unterminated character class in %q", pattern)
+		}
+		end += next + 1
+	}
+
+	body := pattern[1:end]
+	if strings.HasPrefix(body, "!") || strings.HasPrefix(body, "^") {
+		return "[^/" + escapeClass(body[1:]) + "]", end + 1, nil
+	}
+	return "[" + escapeClass(body) + "]", end + 1, nil
+}
+
+// escapeClass escapes the characters in a glob character class body that are special in a regular
+// expression class, keeping ranges like a-z
+func escapeClass(body string) string {
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			if i+1 < len(body) {
+				b.WriteString(regexp.QuoteMeta(body[i+1 : i+2]))
+				i++
+			}
+		case '[', ']', '^', '(', ')':
+			b.WriteByte('\\')
+			b.WriteByte(body[i])
+		default:
+			b.WriteByte(body[i])
+		}
+	}
+	return b.String()
+}
+
+// globAlternatives translates the {a,b} alternatives at the start of pattern to a regular expression
+// without capture groups, and returns its length in the pattern. Alternatives may nest.
+func globAlternatives(pattern string) (string, int, error) {
+	var alternatives []string
+	depth, start := 0, 1
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, pattern[start:i])
+				start = i + 1
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				alternatives = append(alternatives, pattern[start:i])
+				translated := make([]string, len(alternatives))
+				for j, alternative := range alternatives {
+					re, _, err := compileGlob(alternative)
+					if err != nil {
+						return "", 0, err
+					}
+					// Drop the anchors and make the alternative's groups non-capturing
+					expr := strings.TrimSuffix(strings.TrimPrefix(re.String(), "^"), "$")
+					expr = strings.ReplaceAll(expr, "()", "")
+					translated[j] = nonCapturing(expr)
+				}
+				return "(?:" + strings.Join(translated, "|") + ")", i + 1, nil
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated alternatives in %q", pattern)
+}
+
+// nonCapturing makes the capture groups in a regular expression compiled from a glob non-capturing
+func nonCapturing(expr string) string {
+	var b strings.Builder
+	for i := 0; i < len(expr); i++ {
+		switch {
+		case expr[i] == '\\' && i+1 < len(expr):
+			b.WriteString(expr[i : i+2])
+			i++
+		case expr[i] == '(' && !strings.HasPrefix(expr[i:], "(?"):
+			b.WriteString("(?:")
+		default:
+			b.WriteByte(expr[i])
+		}
+	}
+	return b.String()
+}
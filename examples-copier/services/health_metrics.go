@@ -41,6 +41,7 @@ type MetricsData struct {
 	Files      FileMetrics      `json:"files"`
 	GitHubAPI  GitHubAPIMetrics `json:"github_api"`
 	Queues     QueueMetrics     `json:"queues"`
+	Auth       AuthMetrics      `json:"auth"`
 	System     SystemMetrics    `json:"system"`
 }
 
@@ -50,6 +51,7 @@ type WebhookMetrics struct {
 	Processed      int64              `json:"processed"`
 	Failed         int64              `json:"failed"`
 	Ignored        int64              `json:"ignored"` // Non-PR events
+	Deferred       int64              `json:"deferred"` // Merged PRs queued during maintenance
 	EventTypes     map[string]int64   `json:"event_types"` // Count by event type
 	SuccessRate    float64            `json:"success_rate"`
 	ProcessingTime ProcessingTimeStats `json:"processing_time"`
@@ -60,7 +62,10 @@ type FileMetrics struct {
 	Matched          int64              `json:"matched"`
 	Uploaded         int64              `json:"uploaded"`
 	UploadFailed     int64              `json:"upload_failed"`
+	UploadDeadLettered int64            `json:"upload_dead_lettered"` // Failed uploads that ran out of retries
 	Deprecated       int64              `json:"deprecated"`
+	BlockedBySecretScan int64           `json:"blocked_by_secret_scan"`
+	BlockedBySchemaValidation int64     `json:"blocked_by_schema_validation"`
 	UploadSuccessRate float64           `json:"upload_success_rate"`
 	UploadTime       ProcessingTimeStats `json:"upload_time"`
 }
@@ -75,8 +80,10 @@ type GitHubAPIMetrics struct {
 
 // RateLimitInfo represents GitHub API rate limit info
 type RateLimitInfo struct {
-	Remaining int       `json:"remaining"`
-	ResetAt   time.Time `json:"reset_at"`
+	Remaining   int       `json:"remaining"`
+	ResetAt     time.Time `json:"reset_at"`
+	Pauses      int64     `json:"pauses"`       // Times an installation's requests paused for a rate limit
+	PausedUntil time.Time `json:"paused_until"` // When the latest pause ends
 }
 
 // QueueMetrics represents queue size metrics
@@ -84,6 +91,9 @@ type QueueMetrics struct {
 	UploadQueueSize      int `json:"upload_queue_size"`
 	DeprecationQueueSize int `json:"deprecation_queue_size"`
 	RetryQueueSize       int `json:"retry_queue_size"`
+	HeldPRBatches        int `json:"held_pr_batches"`   // Batched PRs held by the destination repos' PR limits
+	RunningChanges       int `json:"running_changes"`   // Merged changes being processed
+	ScheduledChanges     int `json:"scheduled_changes"` // Merged changes waiting for a worker
 }
 
 // SystemMetrics represents system-level metrics
@@ -109,15 +119,34 @@ type MetricsCollector struct {
 	webhookProcessed int64
 	webhookFailed   int64
 	webhookIgnored  int64 // Non-PR events that were ignored
+	webhookDeferred int64 // Merged PRs queued during maintenance
 	eventTypes      map[string]int64 // Count by event type
 	filesMatched    int64
 	filesUploaded   int64
 	filesUploadFailed int64
+	filesUploadDeadLettered int64
+	retryQueueSize  int // Uploads waiting in the retry queue
+	heldPRBatches   int // Batched PRs held by the destination repos' PR limits
+	runningChanges  int // Merged changes being processed
+	scheduledChanges int // Merged changes waiting for a worker
 	filesDeprecated int64
+	filesBlockedBySecretScan int64
+	filesBlockedBySchemaValidation int64
 	githubAPICalls  int64
 	githubAPIErrors int64
 	processingTimes []time.Duration
 	uploadTimes     []time.Duration
+
+	// Metrics for Prometheus: per-workflow counters and latency histograms
+	workflowsMatched      map[string]int64 // Changes each workflow matched, by workflow name
+	filesCopiedByWorkflow map[string]int64 // Files queued for copy, by workflow name
+	prCreationFailed      int64
+	webhookDuration       *histogram
+	githubAPIDuration     *histogram
+	rateLimitRemaining    int // -1 until a GitHub response reports it
+	rateLimitReset        time.Time
+	rateLimitPauses       int64
+	rateLimitPausedUntil  time.Time
 }
 
 // NewMetricsCollector creates a new metrics collector
@@ -127,6 +156,11 @@ func NewMetricsCollector() *MetricsCollector {
 		eventTypes:      make(map[string]int64),
 		processingTimes: make([]time.Duration, 0, 1000),
 		uploadTimes:     make([]time.Duration, 0, 1000),
+		workflowsMatched:      make(map[string]int64),
+		filesCopiedByWorkflow: make(map[string]int64),
+		webhookDuration:       newHistogram(webhookDurationBuckets),
+		githubAPIDuration:     newHistogram(githubAPIDurationBuckets),
+		rateLimitRemaining:    -1,
 	}
 }
 
@@ -142,6 +176,7 @@ func (mc *MetricsCollector) RecordWebhookProcessed(duration time.Duration) {
 	mc.mu.Lock()
 	defer mc.mu.Unlock()
 	mc.webhookProcessed++
+	mc.webhookDuration.observe(duration.Seconds())
 	mc.processingTimes = append(mc.processingTimes, duration)
 	
 	// Keep only last 1000 entries
@@ -165,6 +200,13 @@ func (mc *MetricsCollector) RecordWebhookIgnored(eventType string) {
 	mc.eventTypes[eventType]++
 }
 
+// RecordWebhookDeferred increments the counter of merged PRs queued during maintenance
+func (mc *MetricsCollector) RecordWebhookDeferred() {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.webhookDeferred++
+}
+
 // RecordFileMatched increments file matched counter
 func (mc *MetricsCollector) RecordFileMatched() {
 	mc.mu.Lock()
@@ -172,6 +214,27 @@ func (mc *MetricsCollector) RecordFileMatched() {
 	mc.filesMatched++
 }
 
+// RecordWorkflowMatched increments the counter of changes matched by a workflow
+func (mc *MetricsCollector) RecordWorkflowMatched(workflow string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.workflowsMatched[workflow]++
+}
+
+// RecordWorkflowFileCopied increments the counter of files a workflow queued for copy
+func (mc *MetricsCollector) RecordWorkflowFileCopied(workflow string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.filesCopiedByWorkflow[workflow]++
+}
+
+// RecordPRCreationFailed increments the counter of pull requests that couldn't be opened in a target repo
+func (mc *MetricsCollector) RecordPRCreationFailed() {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.prCreationFailed++
+}
+
 // RecordFileUploaded increments file uploaded counter
 func (mc *MetricsCollector) RecordFileUploaded(duration time.Duration) {
 	mc.mu.Lock()
@@ -192,6 +255,35 @@ func (mc *MetricsCollector) RecordFileUploadFailed() {
 	mc.filesUploadFailed++
 }
 
+// RecordFileUploadDeadLettered increments the counter of files whose upload ran out of retries
+func (mc *MetricsCollector) RecordFileUploadDeadLettered() {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.filesUploadDeadLettered++
+}
+
+// SetHeldPRBatches records the number of batched PRs held by the destination repos' PR limits
+func (mc *MetricsCollector) SetHeldPRBatches(n int) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.heldPRBatches = n
+}
+
+// SetRetryQueueSize records the number of uploads waiting in the retry queue
+func (mc *MetricsCollector) SetRetryQueueSize(size int) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.retryQueueSize = size
+}
+
+// SetSchedulerLoad records the number of merged changes being processed and waiting for a worker
+func (mc *MetricsCollector) SetSchedulerLoad(running int, scheduled int) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.runningChanges = running
+	mc.scheduledChanges = scheduled
+}
+
 // RecordFileDeprecated increments file deprecated counter
 func (mc *MetricsCollector) RecordFileDeprecated() {
 	mc.mu.Lock()
@@ -199,6 +291,20 @@ func (mc *MetricsCollector) RecordFileDeprecated() {
 	mc.filesDeprecated++
 }
 
+// RecordFileBlockedBySecretScan increments the counter of files blocked because they contain potential secrets
+func (mc *MetricsCollector) RecordFileBlockedBySecretScan() {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.filesBlockedBySecretScan++
+}
+
+// RecordFileBlockedBySchemaValidation increments the counter of files blocked because they don't match their JSON Schema
+func (mc *MetricsCollector) RecordFileBlockedBySchemaValidation() {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.filesBlockedBySchemaValidation++
+}
+
 // RecordGitHubAPICall increments GitHub API call counter
 func (mc *MetricsCollector) RecordGitHubAPICall() {
 	mc.mu.Lock()
@@ -213,6 +319,33 @@ func (mc *MetricsCollector) RecordGitHubAPIError() {
 	mc.githubAPIErrors++
 }
 
+// RecordGitHubAPIRequest records a GitHub REST API request: its latency, whether it failed, and the
+// rate limit remaining that GitHub reported. remaining is -1 if the response didn't report it.
+func (mc *MetricsCollector) RecordGitHubAPIRequest(duration time.Duration, failed bool, remaining int, reset time.Time) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.githubAPICalls++
+	if failed {
+		mc.githubAPIErrors++
+	}
+	mc.githubAPIDuration.observe(duration.Seconds())
+	if remaining >= 0 {
+		mc.rateLimitRemaining = remaining
+		mc.rateLimitReset = reset
+	}
+}
+
+// RecordGitHubRateLimitPause records that an installation's GitHub requests paused for a rate limit
+// until the given time
+func (mc *MetricsCollector) RecordGitHubRateLimitPause(until time.Time) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.rateLimitPauses++
+	if until.After(mc.rateLimitPausedUntil) {
+		mc.rateLimitPausedUntil = until
+	}
+}
+
 // GetFilesMatched returns the current files matched count
 func (mc *MetricsCollector) GetFilesMatched() int {
 	mc.mu.RLock()
@@ -234,8 +367,8 @@ func (mc *MetricsCollector) GetFilesUploadFailed() int {
 	return int(mc.filesUploadFailed)
 }
 
-// GetMetrics returns current metrics
-func (mc *MetricsCollector) GetMetrics(fileStateService FileStateService) MetricsData {
+// GetMetrics returns current metrics, with the upload and deprecation queues staged in fileQueues
+func (mc *MetricsCollector) GetMetrics(fileQueues FileQueues) MetricsData {
 	mc.mu.RLock()
 	defer mc.mu.RUnlock()
 
@@ -256,8 +389,7 @@ func (mc *MetricsCollector) GetMetrics(fileStateService FileStateService) Metric
 	}
 
 	// Get queue sizes
-	uploadQueue := fileStateService.GetFilesToUpload()
-	deprecationQueue := fileStateService.GetFilesToDeprecate()
+	uploadQueueSize, deprecationQueueSize := fileQueues.QueueSizes()
 
 	// Copy event types map
 	eventTypesCopy := make(map[string]int64, len(mc.eventTypes))
@@ -271,6 +403,7 @@ func (mc *MetricsCollector) GetMetrics(fileStateService FileStateService) Metric
 			Processed:      mc.webhookProcessed,
 			Failed:         mc.webhookFailed,
 			Ignored:        mc.webhookIgnored,
+			Deferred:       mc.webhookDeferred,
 			EventTypes:     eventTypesCopy,
 			SuccessRate:    webhookSuccessRate,
 			ProcessingTime: calculateStats(mc.processingTimes),
@@ -279,7 +412,10 @@ func (mc *MetricsCollector) GetMetrics(fileStateService FileStateService) Metric
 			Matched:          mc.filesMatched,
 			Uploaded:         mc.filesUploaded,
 			UploadFailed:     mc.filesUploadFailed,
+			UploadDeadLettered: mc.filesUploadDeadLettered,
 			Deprecated:       mc.filesDeprecated,
+			BlockedBySecretScan: mc.filesBlockedBySecretScan,
+			BlockedBySchemaValidation: mc.filesBlockedBySchemaValidation,
 			UploadSuccessRate: uploadSuccessRate,
 			UploadTime:       calculateStats(mc.uploadTimes),
 		},
@@ -287,22 +423,34 @@ func (mc *MetricsCollector) GetMetrics(fileStateService FileStateService) Metric
 			Calls:     mc.githubAPICalls,
 			Errors:    mc.githubAPIErrors,
 			ErrorRate: githubErrorRate,
-			RateLimit: RateLimitInfo{
-				Remaining: 5000, // TODO: Get from GitHub API
-				ResetAt:   time.Now().Add(1 * time.Hour),
-			},
+			RateLimit: mc.rateLimitInfo(),
 		},
 		Queues: QueueMetrics{
-			UploadQueueSize:      len(uploadQueue),
-			DeprecationQueueSize: len(deprecationQueue),
-			RetryQueueSize:       0,
+			UploadQueueSize:      uploadQueueSize,
+			DeprecationQueueSize: deprecationQueueSize,
+			RetryQueueSize:       mc.retryQueueSize,
+			HeldPRBatches:        mc.heldPRBatches,
+			RunningChanges:       mc.runningChanges,
+			ScheduledChanges:     mc.scheduledChanges,
 		},
+		Auth: getAuthMetrics(),
 		System: SystemMetrics{
 			UptimeSeconds: int64(time.Since(mc.startTime).Seconds()),
 		},
 	}
 }
 
+// rateLimitInfo returns the last rate limit GitHub reported. Remaining is -1 before the first
+// response that reports it. mc.mu must be held.
+func (mc *MetricsCollector) rateLimitInfo() RateLimitInfo {
+	return RateLimitInfo{
+		Remaining:   mc.rateLimitRemaining,
+		ResetAt:     mc.rateLimitReset,
+		Pauses:      mc.rateLimitPauses,
+		PausedUntil: mc.rateLimitPausedUntil,
+	}
+}
+
 // calculateStats calculates timing statistics
 func calculateStats(durations []time.Duration) ProcessingTimeStats {
 	if len(durations) == 0 {
@@ -341,11 +489,10 @@ func calculateStats(durations []time.Duration) ProcessingTimeStats {
 	}
 }
 
-// HealthHandler handles /health endpoint
-func HealthHandler(fileStateService FileStateService, startTime time.Time) http.HandlerFunc {
+// HealthHandler handles /health endpoint, reporting the upload and deprecation queues staged in fileQueues
+func HealthHandler(fileQueues FileQueues, startTime time.Time) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		uploadQueue := fileStateService.GetFilesToUpload()
-		deprecationQueue := fileStateService.GetFilesToDeprecate()
+		uploadCount, deprecationCount := fileQueues.QueueSizes()
 
 		health := HealthStatus{
 			Status:  "healthy",
@@ -355,8 +502,8 @@ func HealthHandler(fileStateService FileStateService, startTime time.Time) http.
 				Authenticated: true,
 			},
 			Queues: QueueHealthStatus{
-				UploadCount:      len(uploadQueue),
-				DeprecationCount: len(deprecationQueue),
+				UploadCount:      uploadCount,
+				DeprecationCount: deprecationCount,
 			},
 			Uptime: time.Since(startTime).String(),
 		}
@@ -366,10 +513,16 @@ func HealthHandler(fileStateService FileStateService, startTime time.Time) http.
 	}
 }
 
-// MetricsHandler handles /metrics endpoint
-func MetricsHandler(metricsCollector *MetricsCollector, fileStateService FileStateService) http.HandlerFunc {
+// MetricsHandler handles /metrics endpoint. Metrics are JSON, unless the request asks for the
+// Prometheus text format with ?format=prometheus or an Accept header like the one Prometheus sends.
+func MetricsHandler(metricsCollector *MetricsCollector, fileQueues FileQueues) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		metrics := metricsCollector.GetMetrics(fileStateService)
+		if wantsPrometheus(r) {
+			w.Header().Set("Content-Type", prometheusContentType)
+			_ = metricsCollector.WritePrometheus(w, fileQueues)
+			return
+		}
+		metrics := metricsCollector.GetMetrics(fileQueues)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(metrics)
 	}
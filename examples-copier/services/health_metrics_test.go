@@ -1,16 +1,20 @@
 package services_test
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/jarcoal/httpmock"
 	"github.com/mongodb/code-example-tooling/code-copier/services"
 	"github.com/mongodb/code-example-tooling/code-copier/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	test "github.com/mongodb/code-example-tooling/code-copier/tests"
 )
 
 func TestMetricsCollector_WebhookMetrics(t *testing.T) {
@@ -158,6 +162,42 @@ func TestHealthHandler(t *testing.T) {
 	assert.NotNil(t, health["uptime"])
 }
 
+func TestHealthHandler_ReportsFilesQueuedByRunningWorkflows(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	test.SetupOrgToken("", "test-token")
+	httpmock.RegisterResponder("GET",
+		"https://api.github.com/repos/src-org/app/contents/app/server/main.go?ref=abc123",
+		httpmock.NewJsonResponderOrPanic(200, map[string]any{
+			"type": "file", "encoding": "base64", "path": "app/server/main.go", "content": b64("package main"),
+		}),
+	)
+
+	registry := services.NewFileStateRegistry()
+	handler := services.HealthHandler(registry, time.Now())
+	queues := func() map[string]interface{} {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest("GET", "/health", nil))
+		var health map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+		return health["queues"].(map[string]interface{})
+	}
+
+	// A run stages the workflow's file in a state of its own
+	state := registry.Open()
+	err := newSyncTestProcessor(state).ProcessWorkflow(context.Background(), types.Workflow{
+		Name:            "sample-app",
+		Source:          types.Source{Repo: "src-org/app", Branch: "main"},
+		Destination:     types.Destination{Repo: "dst-org/samples", Branch: "main"},
+		Transformations: []types.Transformation{{Move: &types.MoveTransform{From: "app/server", To: "server"}}},
+	}, []types.ChangedFile{{Path: "app/server/main.go", Status: "modified"}}, 42, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), queues()["upload_count"])
+
+	// Once the run is done, its files are no longer queued
+	registry.Close(state)
+	assert.Equal(t, float64(0), queues()["upload_count"])
+}
+
 func TestMetricsHandler(t *testing.T) {
 	collector := services.NewMetricsCollector()
 	fileStateService := services.NewFileStateService()
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// copyCommandAssociations are the author associations of commenters whose copy commands are run. Anyone
+// can comment on a public repo's PRs, so commands from other users are ignored.
+var copyCommandAssociations = map[string]bool{
+	"OWNER":        true,
+	"MEMBER":       true,
+	"COLLABORATOR": true,
+}
+
+// issueCommentEventSource converts copy commands, comments like "/copy" on a merged GitHub PR, into the PR's
+// merge for workflows with the pr_merged trigger, so a PR can be copied again without an operator. The PR is
+// looked up on GitHub, and comments on issues, unmerged PRs, and PRs the copier opened don't trigger a copy.
+type issueCommentEventSource struct {
+	command     string // the comment prefix that runs a copy, like "/copy"
+	copierLabel string // the label the copier adds to its PRs
+	// getPullRequest looks up the PR a comment is on
+	getPullRequest func(ctx context.Context, owner string, name string, number int) (*github.PullRequest, error)
+}
+
+func (issueCommentEventSource) Name() string { return "issue_comment" }
+
+func (s issueCommentEventSource) Convert(ctx context.Context, payload []byte) (*CopyEvent, string, error) {
+	var evt github.IssueCommentEvent
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return nil, "", invalidPayload(err)
+	}
+	if missing := validateIssueCommentEvent(&evt); len(missing) > 0 {
+		return nil, "", missingFields("issue_comment", missing)
+	}
+
+	comment := evt.GetComment()
+	switch {
+	case evt.GetAction() != "created":
+		return nil, "comment not created", nil
+	case !isCopyCommand(comment.GetBody(), s.command):
+		return nil, "not a copy command", nil
+	case !evt.GetIssue().IsPullRequest():
+		return nil, "comment on an issue", nil
+	case !copyCommandAssociations[comment.GetAuthorAssociation()]:
+		return nil, "commenter isn't a collaborator", nil
+	}
+
+	change, reason, err := lookUpMergedPullRequest(ctx, s.getPullRequest, s.copierLabel, evt.GetRepo(), evt.GetIssue().GetNumber())
+	if change == nil {
+		return nil, reason, err
+	}
+
+	LogInfoCtx(ctx, "processing copy command", map[string]interface{}{
+		"pr_number":   change.Number,
+		"commenter":   comment.GetUser().GetLogin(),
+		"sha":         change.CommitSHA,
+		"repo":        change.Repo,
+		"base_branch": change.BaseBranch,
+	})
+	return change, "", nil
+}
+
+// lookUpMergedPullRequest looks up a PR on GitHub and converts it into its merge, like the PR's pull_request
+// delivery. Returns the reason the PR is ignored if it isn't merged or the copier opened it.
+func lookUpMergedPullRequest(ctx context.Context,
+	getPullRequest func(ctx context.Context, owner string, name string, number int) (*github.PullRequest, error),
+	copierLabel string, repo *github.Repository, number int) (*CopyEvent, string, error) {
+
+	pr, err := getPullRequest(ctx, repo.GetOwner().GetLogin(), repo.GetName(), number)
+	if err != nil {
+		return nil, "", &EventRejection{
+			Status:   http.StatusBadGateway,
+			Response: WebhookErrorResponse{Error: webhookErrInvalidPayload, Message: "failed to look up pull request"},
+			Err:      err,
+		}
+	}
+	if !pr.GetMerged() {
+		return nil, "PR isn't merged", nil
+	}
+	if isCopierPullRequest(pr, copierLabel) {
+		return nil, "PR opened by the copier", nil
+	}
+
+	return &CopyEvent{
+		Platform:   types.SourcePlatformGitHub,
+		Repo:       repo.GetFullName(),
+		Number:     pr.GetNumber(),
+		CommitSHA:  pr.GetMergeCommitSHA(),
+		BaseBranch: pr.GetBase().GetRef(),
+		URL:        pr.GetHTMLURL(),
+		Title:      pr.GetTitle(),
+		Author:     pr.GetUser().GetLogin(),
+	}, "", nil
+}
+
+// isCopyCommand returns true if a comment's first word is the copy command
+func isCopyCommand(body string, command string) bool {
+	fields := strings.Fields(body)
+	return len(fields) > 0 && fields[0] == command
+}
+
+// getGitHubPullRequest looks up a PR with the GitHub REST API
+func getGitHubPullRequest(ctx context.Context, owner string, name string, number int) (*github.PullRequest, error) {
+	pr, _, err := GetRestClient().PullRequests.Get(ctx, owner, name, number)
+	return pr, err
+}
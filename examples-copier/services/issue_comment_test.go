@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issueCommentPayload returns an issue_comment payload for a comment on PR #42 in org/src
+func issueCommentPayload(action, body, association string, onPullRequest bool) []byte {
+	pullRequest := ""
+	if onPullRequest {
+		pullRequest = `, "pull_request": {"url": "https://api.github.com/repos/org/src/pulls/42"}`
+	}
+	return []byte(fmt.Sprintf(`{
+		"action": %q,
+		"issue": {"number": 42%s},
+		"comment": {"body": %q, "author_association": %q, "user": {"login": "reviewer"}},
+		"repository": {"name": "src", "full_name": "org/src", "owner": {"login": "org"}}
+	}`, action, pullRequest, body, association))
+}
+
+// stubPullRequest returns a getPullRequest func that returns pr for PR #42 in org/src
+func stubPullRequest(t *testing.T, pr *github.PullRequest) func(context.Context, string, string, int) (*github.PullRequest, error) {
+	return func(ctx context.Context, owner string, name string, number int) (*github.PullRequest, error) {
+		assert.Equal(t, "org", owner)
+		assert.Equal(t, "src", name)
+		assert.Equal(t, 42, number)
+		return pr, nil
+	}
+}
+
+func mergedPullRequest() *github.PullRequest {
+	return &github.PullRequest{
+		Number:         github.Int(42),
+		Merged:         github.Bool(true),
+		MergeCommitSHA: github.String("abc123"),
+		Base:           &github.PullRequestBranch{Ref: github.String("main")},
+		Head:           &github.PullRequestBranch{Ref: github.String("feature")},
+		HTMLURL:        github.String("https://github.com/org/src/pull/42"),
+		Title:          github.String("Add examples"),
+		User:           &github.User{Login: github.String("author")},
+	}
+}
+
+func TestIssueCommentEventSource_CopyCommand(t *testing.T) {
+	source := issueCommentEventSource{command: "/copy", copierLabel: "examples-copier", getPullRequest: stubPullRequest(t, mergedPullRequest())}
+
+	event, _, err := source.Convert(context.Background(), issueCommentPayload("created", "/copy please", "MEMBER", true))
+	require.NoError(t, err)
+	assert.Equal(t, &CopyEvent{
+		Platform:   types.SourcePlatformGitHub,
+		Repo:       "org/src",
+		Number:     42,
+		CommitSHA:  "abc123",
+		BaseBranch: "main",
+		URL:        "https://github.com/org/src/pull/42",
+		Title:      "Add examples",
+		Author:     "author",
+	}, event)
+}
+
+func TestIssueCommentEventSource_IgnoresOtherComments(t *testing.T) {
+	unmerged := mergedPullRequest()
+	unmerged.Merged = github.Bool(false)
+	copierPR := mergedPullRequest()
+	copierPR.Labels = []*github.Label{{Name: github.String("examples-copier")}}
+
+	tests := []struct {
+		name    string
+		payload []byte
+		pr      *github.PullRequest
+		reason  string
+	}{
+		{name: "edited", payload: issueCommentPayload("edited", "/copy", "MEMBER", true), reason: "comment not created"},
+		{name: "not a command", payload: issueCommentPayload("created", "please /copy", "MEMBER", true), reason: "not a copy command"},
+		{name: "command prefix", payload: issueCommentPayload("created", "/copying", "MEMBER", true), reason: "not a copy command"},
+		{name: "issue", payload: issueCommentPayload("created", "/copy", "MEMBER", false), reason: "comment on an issue"},
+		{name: "outside contributor", payload: issueCommentPayload("created", "/copy", "CONTRIBUTOR", true), reason: "commenter isn't a collaborator"},
+		{name: "unmerged", payload: issueCommentPayload("created", "/copy", "OWNER", true), pr: unmerged, reason: "PR isn't merged"},
+		{name: "copier PR", payload: issueCommentPayload("created", "/copy", "COLLABORATOR", true), pr: copierPR, reason: "PR opened by the copier"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := issueCommentEventSource{command: "/copy", copierLabel: "examples-copier",
+				getPullRequest: func(ctx context.Context, owner string, name string, number int) (*github.PullRequest, error) {
+					require.NotNil(t, tt.pr, "PR looked up for an ignored comment")
+					return tt.pr, nil
+				}}
+			event, reason, err := source.Convert(context.Background(), tt.payload)
+			require.NoError(t, err)
+			assert.Nil(t, event)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestIssueCommentEventSource_Rejections(t *testing.T) {
+	source := issueCommentEventSource{command: "/copy",
+		getPullRequest: func(ctx context.Context, owner string, name string, number int) (*github.PullRequest, error) {
+			return nil, errors.New("not found")
+		}}
+
+	_, _, err := source.Convert(context.Background(), issueCommentPayload("created", "/copy", "MEMBER", true))
+	var rejection *EventRejection
+	require.ErrorAs(t, err, &rejection)
+	assert.Equal(t, http.StatusBadGateway, rejection.Status)
+
+	_, _, err = source.Convert(context.Background(), []byte(`{"action": "created", "comment": {"body": "/copy"}}`))
+	require.ErrorAs(t, err, &rejection)
+	assert.Equal(t, []string{"issue", "repository"}, rejection.Response.MissingFields)
+}
+
+func TestNewEventSources_CopyCommand(t *testing.T) {
+	assert.Nil(t, NewEventSources(&configs.Config{}).Webhook(types.SourcePlatformGitHub, "issue_comment"))
+	assert.NotNil(t, NewEventSources(&configs.Config{CopyCommand: "/copy"}).Webhook(types.SourcePlatformGitHub, "issue_comment"))
+}
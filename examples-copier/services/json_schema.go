@@ -0,0 +1,453 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSONSchema is a parsed JSON Schema document used to validate copied config files.
+//
+// Supported keywords: type, enum, const, properties, required, additionalProperties,
+// items, minItems, maxItems, uniqueItems, minLength, maxLength, pattern, minimum,
+// maximum, exclusiveMinimum, exclusiveMaximum, allOf, anyOf, oneOf, not, and local
+// $ref pointers ("#/definitions/..." or "#/$defs/..."). Other keywords, such as
+// format, are ignored.
+type JSONSchema struct {
+	root     interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// SchemaViolation describes a single place where a document doesn't match its schema
+type SchemaViolation struct {
+	Path    string `json:"path"` // JSON pointer to the invalid value ("" for the document root)
+	Message string `json:"message"`
+}
+
+// String formats the violation for logs and alerts
+func (v SchemaViolation) String() string {
+	if v.Path == "" {
+		return "(root): " + v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// SchemaValidationError is returned when a file is blocked from being copied because it doesn't match its schema
+type SchemaValidationError struct {
+	Path       string
+	SchemaPath string
+	Violations []SchemaViolation
+}
+
+// Error implements the error interface
+func (e *SchemaValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.String())
+	}
+	return fmt.Sprintf("%s does not match schema %s: %s", e.Path, e.SchemaPath, strings.Join(messages, "; "))
+}
+
+// ParseJSONSchema parses a JSON Schema document
+func ParseJSONSchema(data []byte) (*JSONSchema, error) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid schema JSON: %w", err)
+	}
+	switch root.(type) {
+	case map[string]interface{}, bool:
+	default:
+		return nil, fmt.Errorf("schema must be a JSON object or boolean")
+	}
+	return &JSONSchema{root: root, patterns: make(map[string]*regexp.Regexp)}, nil
+}
+
+// ParseStructuredContent parses file content as JSON for .json files and as YAML otherwise.
+// YAML values are normalized to the same types encoding/json produces so they can be validated the same way.
+func ParseStructuredContent(filePath string, content []byte) (interface{}, error) {
+	var doc interface{}
+	if strings.EqualFold(path.Ext(filePath), ".json") {
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return doc, nil
+	}
+
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	return normalizeYAMLValue(doc)
+}
+
+// normalizeYAMLValue converts YAML-decoded values to encoding/json types (map[string]interface{}, float64)
+func normalizeYAMLValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			normalized, err := normalizeYAMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = normalized
+		}
+		return v, nil
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			normalized, err := normalizeYAMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result[fmt.Sprint(key)] = normalized
+		}
+		return result, nil
+	case []interface{}:
+		for i, item := range v {
+			normalized, err := normalizeYAMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = normalized
+		}
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case time.Time:
+		// Unquoted dates are strings as far as JSON Schema is concerned
+		return v.Format(time.RFC3339), nil
+	default:
+		return v, nil
+	}
+}
+
+// Validate validates a document against the schema and returns all violations found
+func (s *JSONSchema) Validate(doc interface{}) []SchemaViolation {
+	var violations []SchemaViolation
+	s.validate(s.root, doc, "", &violations, 0)
+	return violations
+}
+
+// maxSchemaDepth guards against $ref cycles that never consume any of the document
+const maxSchemaDepth = 64
+
+func (s *JSONSchema) validate(schema interface{}, value interface{}, pointer string, violations *[]SchemaViolation, depth int) {
+	addViolation := func(format string, args ...interface{}) {
+		*violations = append(*violations, SchemaViolation{Path: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if depth > maxSchemaDepth {
+		addViolation("schema nesting too deep (possible $ref cycle)")
+		return
+	}
+
+	if allowed, ok := schema.(bool); ok {
+		if !allowed {
+			addViolation("no value is allowed here")
+		}
+		return
+	}
+	sch, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if ref, ok := sch["$ref"].(string); ok {
+		resolved, err := s.resolveRef(ref)
+		if err != nil {
+			addViolation("%v", err)
+			return
+		}
+		s.validate(resolved, value, pointer, violations, depth+1)
+	}
+
+	if typ, ok := sch["type"]; ok && !matchesType(typ, value) {
+		addViolation("expected %s, got %s", describeType(typ), jsonTypeOf(value))
+		// Other keywords assume the type matched
+		return
+	}
+
+	if enum, ok := sch["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if jsonEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			addViolation("value must be one of %s", compactJSON(enum))
+		}
+	}
+
+	if constValue, ok := sch["const"]; ok && !jsonEqual(constValue, value) {
+		addViolation("value must be %s", compactJSON(constValue))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.validateObject(sch, v, pointer, violations, depth)
+	case []interface{}:
+		s.validateArray(sch, v, pointer, violations, depth)
+	case string:
+		length := len([]rune(v))
+		if min, ok := schemaNumber(sch, "minLength"); ok && float64(length) < min {
+			addViolation("string is shorter than minLength %v", min)
+		}
+		if max, ok := schemaNumber(sch, "maxLength"); ok && float64(length) > max {
+			addViolation("string is longer than maxLength %v", max)
+		}
+		if pattern, ok := sch["pattern"].(string); ok {
+			re, err := s.compilePattern(pattern)
+			if err != nil {
+				addViolation("invalid pattern in schema: %v", err)
+			} else if !re.MatchString(v) {
+				addViolation("string does not match pattern %q", pattern)
+			}
+		}
+	case float64:
+		if min, ok := schemaNumber(sch, "minimum"); ok && v < min {
+			addViolation("value %v is less than minimum %v", v, min)
+		}
+		if max, ok := schemaNumber(sch, "maximum"); ok && v > max {
+			addViolation("value %v is greater than maximum %v", v, max)
+		}
+		if min, ok := schemaNumber(sch, "exclusiveMinimum"); ok && v <= min {
+			addViolation("value %v must be greater than %v", v, min)
+		}
+		if max, ok := schemaNumber(sch, "exclusiveMaximum"); ok && v >= max {
+			addViolation("value %v must be less than %v", v, max)
+		}
+	}
+
+	if allOf, ok := sch["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			s.validate(sub, value, pointer, violations, depth+1)
+		}
+	}
+
+	if anyOf, ok := sch["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if len(s.subViolations(sub, value, pointer, depth)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			addViolation("value does not match any of the anyOf schemas")
+		}
+	}
+
+	if oneOf, ok := sch["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, sub := range oneOf {
+			if len(s.subViolations(sub, value, pointer, depth)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			addViolation("value must match exactly one of the oneOf schemas (matched %d)", matches)
+		}
+	}
+
+	if not, ok := sch["not"]; ok && len(s.subViolations(not, value, pointer, depth)) == 0 {
+		addViolation("value must not match the \"not\" schema")
+	}
+}
+
+func (s *JSONSchema) validateObject(sch map[string]interface{}, obj map[string]interface{}, pointer string, violations *[]SchemaViolation, depth int) {
+	if required, ok := sch["required"].([]interface{}); ok {
+		for _, name := range required {
+			key, _ := name.(string)
+			if _, exists := obj[key]; !exists {
+				*violations = append(*violations, SchemaViolation{Path: pointer, Message: fmt.Sprintf("missing required property %q", key)})
+			}
+		}
+	}
+
+	properties, _ := sch["properties"].(map[string]interface{})
+	additional, hasAdditional := sch["additionalProperties"]
+
+	// Sort keys so violations are reported in a stable order
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPointer := pointer + "/" + escapeJSONPointer(key)
+		if propSchema, ok := properties[key]; ok {
+			s.validate(propSchema, obj[key], childPointer, violations, depth+1)
+			continue
+		}
+		if !hasAdditional {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok && !allowed {
+			*violations = append(*violations, SchemaViolation{Path: pointer, Message: fmt.Sprintf("property %q is not allowed", key)})
+			continue
+		}
+		s.validate(additional, obj[key], childPointer, violations, depth+1)
+	}
+}
+
+func (s *JSONSchema) validateArray(sch map[string]interface{}, arr []interface{}, pointer string, violations *[]SchemaViolation, depth int) {
+	if min, ok := schemaNumber(sch, "minItems"); ok && float64(len(arr)) < min {
+		*violations = append(*violations, SchemaViolation{Path: pointer, Message: fmt.Sprintf("array has fewer than minItems %v", min)})
+	}
+	if max, ok := schemaNumber(sch, "maxItems"); ok && float64(len(arr)) > max {
+		*violations = append(*violations, SchemaViolation{Path: pointer, Message: fmt.Sprintf("array has more than maxItems %v", max)})
+	}
+	if unique, ok := sch["uniqueItems"].(bool); ok && unique {
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					*violations = append(*violations, SchemaViolation{Path: pointer, Message: fmt.Sprintf("items %d and %d are equal but uniqueItems is set", i, j)})
+				}
+			}
+		}
+	}
+	if items, ok := sch["items"]; ok {
+		for i, item := range arr {
+			s.validate(items, item, pointer+"/"+strconv.Itoa(i), violations, depth+1)
+		}
+	}
+}
+
+// subViolations validates against a subschema without recording violations on the parent
+func (s *JSONSchema) subViolations(schema interface{}, value interface{}, pointer string, depth int) []SchemaViolation {
+	var violations []SchemaViolation
+	s.validate(schema, value, pointer, &violations, depth+1)
+	return violations
+}
+
+// resolveRef resolves a local JSON pointer reference such as "#/definitions/port"
+func (s *JSONSchema) resolveRef(ref string) (interface{}, error) {
+	if ref == "#" {
+		return s.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q (only local references are supported)", ref)
+	}
+
+	current := s.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		current, ok = obj[token]
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+	return current, nil
+}
+
+func (s *JSONSchema) compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := s.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	s.patterns[pattern] = re
+	return re, nil
+}
+
+// matchesType checks a value against a "type" keyword, which may be a string or an array of strings
+func matchesType(typ interface{}, value interface{}) bool {
+	switch t := typ.(type) {
+	case string:
+		return matchesSingleType(t, value)
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); ok && matchesSingleType(name, value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func matchesSingleType(typ string, value interface{}) bool {
+	actual := jsonTypeOf(value)
+	switch typ {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		return actual == "number"
+	default:
+		return actual == typ
+	}
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func describeType(typ interface{}) string {
+	if types, ok := typ.([]interface{}); ok {
+		names := make([]string, 0, len(types))
+		for _, t := range types {
+			names = append(names, fmt.Sprint(t))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(typ)
+}
+
+func schemaNumber(sch map[string]interface{}, keyword string) (float64, bool) {
+	n, ok := sch[keyword].(float64)
+	return n, ok
+}
+
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func compactJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
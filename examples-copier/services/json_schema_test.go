@@ -0,0 +1,135 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/code-copier/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const appConfigSchema = `{
+  "type": "object",
+  "required": ["name", "port"],
+  "additionalProperties": false,
+  "properties": {
+    "name": {"type": "string", "minLength": 1},
+    "port": {"$ref": "#/definitions/port"},
+    "mode": {"enum": ["dev", "prod"]},
+    "tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
+    "replicas": {"type": "integer", "minimum": 1, "maximum": 7},
+    "uri": {"type": "string", "pattern": "^mongodb(\\+srv)?://"},
+    "auth": {
+      "oneOf": [
+        {"type": "object", "required": ["user"]},
+        {"type": "object", "required": ["certificate"]}
+      ]
+    }
+  },
+  "definitions": {
+    "port": {"type": "integer", "exclusiveMinimum": 0, "maximum": 65535}
+  }
+}`
+
+func TestJSONSchema_Validate(t *testing.T) {
+	schema, err := services.ParseJSONSchema([]byte(appConfigSchema))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		path      string
+		content   string
+		wantPaths []string
+	}{
+		{
+			name:    "valid JSON",
+			path:    "config/app.json",
+			content: `{"name": "app", "port": 27017, "mode": "dev", "tags": ["a", "b"], "replicas": 3, "uri": "mongodb+srv://host", "auth": {"user": "u"}}`,
+		},
+		{
+			name:    "valid YAML",
+			path:    "config/app.yaml",
+			content: "name: app\nport: 8080\nreplicas: 1\n",
+		},
+		{
+			name:      "missing required property",
+			path:      "config/app.json",
+			content:   `{"name": "app"}`,
+			wantPaths: []string{""},
+		},
+		{
+			name:      "wrong type through $ref",
+			path:      "config/app.json",
+			content:   `{"name": "app", "port": "8080"}`,
+			wantPaths: []string{"/port"},
+		},
+		{
+			name:      "additional property not allowed",
+			path:      "config/app.yaml",
+			content:   "name: app\nport: 8080\nextra: true\n",
+			wantPaths: []string{""},
+		},
+		{
+			name:      "enum, integer, pattern, and array item violations",
+			path:      "config/app.json",
+			content:   `{"name": "app", "port": 1, "mode": "test", "replicas": 2.5, "uri": "http://host", "tags": ["a", 1, "a"]}`,
+			wantPaths: []string{"/mode", "/replicas", "/tags", "/tags/1", "/uri"},
+		},
+		{
+			name:      "oneOf matches both",
+			path:      "config/app.json",
+			content:   `{"name": "app", "port": 1, "auth": {"user": "u", "certificate": "c"}}`,
+			wantPaths: []string{"/auth"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := services.ParseStructuredContent(tt.path, []byte(tt.content))
+			require.NoError(t, err)
+
+			var paths []string
+			for _, violation := range schema.Validate(doc) {
+				paths = append(paths, violation.Path)
+			}
+			assert.Equal(t, tt.wantPaths, paths)
+		})
+	}
+}
+
+func TestParseStructuredContent_InvalidSyntax(t *testing.T) {
+	_, err := services.ParseStructuredContent("config/app.json", []byte(`{"name": "app",}`))
+	assert.ErrorContains(t, err, "invalid JSON")
+
+	_, err = services.ParseStructuredContent("config/app.yaml", []byte("name: [unclosed\n"))
+	assert.ErrorContains(t, err, "invalid YAML")
+}
+
+func TestParseJSONSchema_Invalid(t *testing.T) {
+	_, err := services.ParseJSONSchema([]byte(`{"type": `))
+	assert.Error(t, err)
+
+	_, err = services.ParseJSONSchema([]byte(`["not", "a", "schema"]`))
+	assert.Error(t, err)
+}
+
+func TestJSONSchema_UnresolvableRef(t *testing.T) {
+	schema, err := services.ParseJSONSchema([]byte(`{"$ref": "#/definitions/missing"}`))
+	require.NoError(t, err)
+
+	violations := schema.Validate(map[string]interface{}{})
+	require.Len(t, violations, 1)
+	assert.Contains(t, violations[0].Message, "unresolvable $ref")
+}
+
+func TestSchemaValidationError(t *testing.T) {
+	err := &services.SchemaValidationError{
+		Path:       "config/app.json",
+		SchemaPath: "schemas/app.schema.json",
+		Violations: []services.SchemaViolation{
+			{Path: "", Message: `missing required property "port"`},
+			{Path: "/mode", Message: `value must be one of ["dev","prod"]`},
+		},
+	}
+	assert.Equal(t, `config/app.json does not match schema schemas/app.schema.json: (root): missing required property "port"; /mode: value must be one of ["dev","prod"]`, err.Error())
+}
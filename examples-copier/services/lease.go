@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LeaseStore grants named leases, so work that mustn't run twice at once, like a reconciliation, runs on
+// one instance at a time. A lease lasts until it's released or its TTL passes, so an instance that stops
+// without releasing one doesn't hold it forever.
+type LeaseStore interface {
+	// Acquire takes the lease for owner until now+ttl, or extends it if owner already holds it. It returns
+	// false if another owner holds it.
+	Acquire(ctx context.Context, name string, owner string, now time.Time, ttl time.Duration) (bool, error)
+	// Release gives up the lease if owner holds it
+	Release(ctx context.Context, name string, owner string) error
+}
+
+// lease is a held lease
+type lease struct {
+	Name  string    `bson:"_id"`
+	Owner string    `bson:"owner"`
+	Until time.Time `bson:"until"`
+}
+
+// MemoryLeaseStore implements LeaseStore in memory, for a single instance
+type MemoryLeaseStore struct {
+	mu     sync.Mutex
+	leases map[string]lease
+}
+
+// NewMemoryLeaseStore creates an in-memory lease store with no leases held
+func NewMemoryLeaseStore() *MemoryLeaseStore {
+	return &MemoryLeaseStore{leases: make(map[string]lease)}
+}
+
+// Acquire takes or extends the lease
+func (s *MemoryLeaseStore) Acquire(ctx context.Context, name string, owner string, now time.Time, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if held, ok := s.leases[name]; ok && held.Owner != owner && held.Until.After(now) {
+		return false, nil
+	}
+	s.leases[name] = lease{Name: name, Owner: owner, Until: now.Add(ttl)}
+	return true, nil
+}
+
+// Release gives up the lease if owner holds it
+func (s *MemoryLeaseStore) Release(ctx context.Context, name string, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if held, ok := s.leases[name]; ok && held.Owner == owner {
+		delete(s.leases, name)
+	}
+	return nil
+}
+
+// MongoLeaseStore implements LeaseStore using a MongoDB collection, so a lease is held by one instance
+// among all that share the collection
+type MongoLeaseStore struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewMongoLeaseStore returns a store backed by the given collection, connecting the shared client if it
+// isn't yet
+func NewMongoLeaseStore(ctx context.Context, mongoClient *SharedMongoClient, database, collection string) (*MongoLeaseStore, error) {
+	client, err := mongoClient.Connect(ctx, "the lease store is mongodb")
+	if err != nil {
+		return nil, err
+	}
+	return &MongoLeaseStore{client: client, collection: client.Database(database).Collection(collection)}, nil
+}
+
+// Acquire takes the lease if it's free, expired, or already owner's. The upsert only matches a lease owner
+// may take, so when another owner holds it, the upsert tries to insert a second document with the same
+// name and fails with a duplicate key error.
+func (s *MongoLeaseStore) Acquire(ctx context.Context, name string, owner string, now time.Time, ttl time.Duration) (bool, error) {
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"owner": owner},
+			bson.M{"until": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"owner": owner, "until": now.Add(ttl)}}
+	if _, err := s.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to acquire lease %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// Release gives up the lease if owner holds it
+func (s *MongoLeaseStore) Release(ctx context.Context, name string, owner string) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"_id": name, "owner": owner})
+	return err
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/google/go-github/v48/github"
+	. "github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// Paths of the Git LFS files in a repo
+const (
+	gitAttributesFile = ".gitattributes"
+	lfsConfigFile     = ".lfsconfig"
+)
+
+// lfsPointerMaxSize is the largest file treated as an LFS pointer; Git LFS never writes larger pointers
+const lfsPointerMaxSize = 1024
+
+// lfsAttributes is the .gitattributes rule that stores a path with Git LFS
+const lfsAttributes = "filter=lfs diff=lfs merge=lfs -text"
+
+var lfsOIDPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// LFSPointer is the content of a Git LFS pointer file, which is committed in place of the tracked file
+type LFSPointer struct {
+	OID  string // sha256:<hash> of the stored object
+	Size int64  // Size of the stored object in bytes
+}
+
+// ParseLFSPointer returns the pointer if content is a Git LFS pointer file
+func ParseLFSPointer(content string) (LFSPointer, bool) {
+	if len(content) > lfsPointerMaxSize ||
+		!(strings.HasPrefix(content, "version https://git-lfs.github.com/spec/v1\n") ||
+			strings.HasPrefix(content, "version https://hawser.github.com/spec/v1\n")) {
+		return LFSPointer{}, false
+	}
+
+	var pointer LFSPointer
+	sizeFound := false
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n")[1:] {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			return LFSPointer{}, false
+		}
+		switch key {
+		case "oid":
+			pointer.OID = value
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return LFSPointer{}, false
+			}
+			pointer.Size = size
+			sizeFound = true
+		}
+	}
+	if !lfsOIDPattern.MatchString(pointer.OID) || !sizeFound {
+		return LFSPointer{}, false
+	}
+	return pointer, true
+}
+
+// gitAttributesRule is a .gitattributes line that sets or unsets the filter attribute
+type gitAttributesRule struct {
+	pattern string
+	lfs     bool // true for filter=lfs; false for any other filter setting
+}
+
+// parseGitAttributesFilters returns the rules in a .gitattributes file that set the filter attribute, in order
+func parseGitAttributesFilters(content string) []gitAttributesRule {
+	var rules []gitAttributesRule
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "!") {
+			continue
+		}
+		for _, attribute := range fields[1:] {
+			if attribute == "filter" || strings.HasPrefix(attribute, "filter=") ||
+				attribute == "-filter" || attribute == "!filter" {
+				rules = append(rules, gitAttributesRule{pattern: fields[0], lfs: attribute == "filter=lfs"})
+			}
+		}
+	}
+	return rules
+}
+
+// gitAttributesMatch returns true if a .gitattributes pattern matches a path relative to the file's directory.
+// Patterns without a slash match the file name in any directory; others match from the file's directory.
+func gitAttributesMatch(pattern string, relPath string) bool {
+	pattern = strings.ReplaceAll(pattern, "[[:space:]]", "?")
+	if strings.HasSuffix(pattern, "/") {
+		// Directory patterns don't apply attributes to files
+		return false
+	}
+	if !strings.Contains(pattern, "/") {
+		matched, err := path.Match(pattern, path.Base(relPath))
+		return err == nil && matched
+	}
+	matched, err := doublestar.Match(strings.TrimPrefix(pattern, "/"), relPath)
+	return err == nil && matched
+}
+
+// lfsTrackingPattern returns the .gitattributes pattern that stores filePath with LFS, given the
+// .gitattributes files from the repo root down to the file's directory. Deeper files and later lines
+// take precedence, so a later rule that sets a different filter turns LFS off.
+func lfsTrackingPattern(attributes map[string]string, filePath string) (pattern string, tracked bool) {
+	for _, dir := range ancestorDirs(filePath) {
+		content, ok := attributes[dir]
+		if !ok {
+			continue
+		}
+		relPath := filePath
+		if dir != "" {
+			relPath = strings.TrimPrefix(filePath, dir+"/")
+		}
+		for _, rule := range parseGitAttributesFilters(content) {
+			if gitAttributesMatch(rule.pattern, relPath) {
+				pattern, tracked = rule.pattern, rule.lfs
+			}
+		}
+	}
+	return pattern, tracked
+}
+
+// ancestorDirs returns the directories containing filePath, from the repo root ("") down
+func ancestorDirs(filePath string) []string {
+	dirs := []string{""}
+	dir := path.Dir(filePath)
+	if dir == "." {
+		return dirs
+	}
+	parts := strings.Split(dir, "/")
+	for i := range parts {
+		dirs = append(dirs, strings.Join(parts[:i+1], "/"))
+	}
+	return dirs
+}
+
+// escapeGitAttributesPath escapes a path for use as a .gitattributes pattern
+func escapeGitAttributesPath(p string) string {
+	p = strings.NewReplacer(" ", "[[:space:]]", "*", `\*`, "?", `\?`, "[", `\[`).Replace(p)
+	return "/" + p
+}
+
+// sourceGitAttributes returns the .gitattributes files in the source repo at the commit that apply to
+// filePath, keyed by directory. Files are only fetched if the source listing includes them.
+func (wp *workflowProcessor) sourceGitAttributes(ctx context.Context, source Source, sourceCommitSHA string, filePath string) map[string]string {
+	tree := wp.sourceTree(ctx, source, sourceCommitSHA)
+	attributes := make(map[string]string)
+	for _, dir := range ancestorDirs(filePath) {
+		attributesPath := path.Join(dir, gitAttributesFile)
+		if _, ok := tree.modes[attributesPath]; !ok {
+			continue
+		}
+		cacheKey := source.Repo + "@" + sourceCommitSHA + ":" + attributesPath
+		content, ok := wp.gitAttributesCache[cacheKey]
+		if !ok {
+			file, err := retrieveSourceFile(ctx, source, attributesPath, sourceCommitSHA)
+			if err == nil {
+				content, err = file.GetContent()
+			}
+			if err != nil {
+				LogWarningCtx(ctx, "failed to read source .gitattributes", map[string]interface{}{
+					"source_repo": source.Repo,
+					"path":        attributesPath,
+					"error":       err.Error(),
+				})
+			}
+			wp.gitAttributesCache[cacheKey] = content
+		}
+		attributes[dir] = content
+	}
+	return attributes
+}
+
+// handleLFSFile copies or skips a source file that is a Git LFS pointer, according to the workflow's lfs setting.
+// In pointer mode the pointer is queued unchanged, along with the destination .gitattributes rule (and
+// .lfsconfig, if the workflow sets an LFS URL) that makes Git treat it as an LFS file. Returns true if
+// the pointer was queued.
+func (wp *workflowProcessor) handleLFSFile(
+	ctx context.Context,
+	workflow Workflow,
+	sourcePath string,
+	targetPath string,
+	fileContent *github.RepositoryContent,
+	pointer LFSPointer,
+	prNumber int,
+	sourceCommitSHA string,
+) (bool, error) {
+	trackedBy, tracked := lfsTrackingPattern(wp.sourceGitAttributes(ctx, workflow.Source, sourceCommitSHA, sourcePath), sourcePath)
+	if !tracked {
+		trackedBy = ""
+	}
+	logFields := map[string]interface{}{
+		"workflow_name": workflow.Name,
+		"source_path":   sourcePath,
+		"target_path":   targetPath,
+		"lfs_oid":       pointer.OID,
+		"lfs_size":      pointer.Size,
+		"tracked_by":    trackedBy,
+	}
+
+	if workflow.LFS.GetMode() == LFSModeSkip {
+		LogWarningCtx(ctx, "File is stored with Git LFS and was not copied; set lfs.mode to pointer to copy it", logFields)
+		if wp.auditLogger != nil {
+			if err := wp.auditLogger.LogErrorEvent(ctx, &AuditEvent{
+				RuleName:     workflow.Name,
+				SourceRepo:   workflow.Source.Repo,
+				SourcePath:   sourcePath,
+				TargetRepo:   workflow.Destination.Repo,
+				TargetPath:   targetPath,
+				CommitSHA:    sourceCommitSHA,
+				PRNumber:     prNumber,
+				ErrorMessage: "not copied: file is stored with Git LFS (lfs.mode is skip)",
+				FileSize:     pointer.Size,
+				AdditionalData: map[string]any{
+					"skipped":    "git_lfs",
+					"lfs_oid":    pointer.OID,
+					"tracked_by": trackedBy,
+				},
+			}); err != nil {
+				LogWarningCtx(ctx, "Failed to record skipped LFS file in audit log", map[string]interface{}{
+					"source_path": sourcePath,
+					"error":       err.Error(),
+				})
+			}
+		}
+		return false, nil
+	}
+
+	key := UploadKey{RepoName: workflow.Destination.Repo, BranchPath: workflow.Destination.Branch}
+	content := wp.getUploadContent(ctx, workflow, key)
+
+	attributes, err := wp.destinationFile(ctx, workflow, content, gitAttributesFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read destination %s: %w", gitAttributesFile, err)
+	}
+	if _, tracked := lfsTrackingPattern(map[string]string{"": attributes}, targetPath); !tracked {
+		if attributes != "" && !strings.HasSuffix(attributes, "\n") {
+			attributes += "\n"
+		}
+		attributes += escapeGitAttributesPath(targetPath) + " " + lfsAttributes + "\n"
+		setUploadFile(&content, gitAttributesFile, attributes)
+	}
+
+	if url := workflow.LFS.URL; url != "" {
+		lfsConfig, err := wp.destinationFile(ctx, workflow, content, lfsConfigFile)
+		if err != nil {
+			return false, fmt.Errorf("failed to read destination %s: %w", lfsConfigFile, err)
+		}
+		switch current := lfsConfigURL(lfsConfig); current {
+		case url:
+		case "":
+			setUploadFile(&content, lfsConfigFile, fmt.Sprintf("[lfs]\n\turl = %s\n", url))
+		default:
+			return false, fmt.Errorf("destination %s already sets the LFS url to %s, not %s", lfsConfigFile, current, url)
+		}
+	}
+
+	fileContent.Name = github.String(targetPath)
+	setUploadContent(&content, *fileContent)
+	wp.renderUploadMessages(ctx, workflow, &content, prNumber, sourceCommitSHA)
+	wp.fileStateService.AddFileToUpload(key, content)
+
+	LogInfoCtx(ctx, "Queued Git LFS pointer", logFields)
+	return true, nil
+}
+
+// destinationFile returns a file's content on the workflow's destination branch, preferring a version
+// already queued for upload. A missing file is returned as empty.
+func (wp *workflowProcessor) destinationFile(ctx context.Context, workflow Workflow, content UploadFileContent, filePath string) (string, error) {
+	for _, queued := range content.Content {
+		if queued.GetName() == filePath {
+			return queued.GetContent()
+		}
+	}
+
+	provider, repo, err := repoProviderFor(workflow.Destination.Repo)
+	if err != nil {
+		return "", err
+	}
+	file, _, err := provider.GetFile(ctx, repo, filePath, workflow.Destination.Branch)
+	return file, err
+}
+
+// setUploadFile queues a generated file, replacing any queued version of the same path
+func setUploadFile(content *UploadFileContent, filePath string, text string) {
+	setUploadContent(content, github.RepositoryContent{
+		Name:    github.String(filePath),
+		Content: github.String(text),
+		Size:    github.Int(len(text)),
+	})
+}
+
+// setUploadContent queues a file, replacing any queued version of the same path
+func setUploadContent(content *UploadFileContent, file github.RepositoryContent) {
+	for i, queued := range content.Content {
+		if queued.GetName() == file.GetName() {
+			content.Content[i] = file
+			return
+		}
+	}
+	content.Content = append(content.Content, file)
+}
+
+// lfsConfigURL returns the url set in the [lfs] section of a .lfsconfig file
+func lfsConfigURL(content string) string {
+	inLFS := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inLFS = strings.EqualFold(strings.Trim(line, "[] "), "lfs")
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && inLFS && strings.EqualFold(strings.TrimSpace(key), "url") {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
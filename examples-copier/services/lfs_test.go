@@ -0,0 +1,69 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testLFSPointer = "version https://git-lfs.github.com/spec/v1\n" +
+	"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n" +
+	"size 12345\n"
+
+func TestParseLFSPointer(t *testing.T) {
+	pointer, ok := ParseLFSPointer(testLFSPointer)
+	assert.True(t, ok)
+	assert.Equal(t, "sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393", pointer.OID)
+	assert.Equal(t, int64(12345), pointer.Size)
+
+	notPointers := map[string]string{
+		"plain text":     "package main\n",
+		"missing size":   "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n",
+		"bad oid":        "version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 1\n",
+		"version in doc": "# Pointers start with\nversion https://git-lfs.github.com/spec/v1\n",
+		"too large":      testLFSPointer + "x-note " + strings.Repeat("a", lfsPointerMaxSize) + "\n",
+	}
+	for name, content := range notPointers {
+		_, ok := ParseLFSPointer(content)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestLFSTrackingPattern(t *testing.T) {
+	attributes := map[string]string{
+		"": "# Large assets\n*.png filter=lfs diff=lfs merge=lfs -text\n" +
+			"/data/**/*.bson filter=lfs diff=lfs merge=lfs -text\n" +
+			"*.md text\n",
+		"assets/icons": "small.png -filter -diff -merge text\n",
+	}
+
+	tests := []struct {
+		path    string
+		pattern string
+		tracked bool
+	}{
+		{"logo.png", "*.png", true},
+		{"assets/images/logo.png", "*.png", true},
+		{"data/2024/dump/users.bson", "/data/**/*.bson", true},
+		{"nested/data/users.bson", "", false},
+		{"README.md", "", false},
+		{"assets/icons/small.png", "small.png", false}, // the deeper file turns LFS off
+	}
+	for _, tt := range tests {
+		pattern, tracked := lfsTrackingPattern(attributes, tt.path)
+		assert.Equal(t, tt.tracked, tracked, tt.path)
+		assert.Equal(t, tt.pattern, pattern, tt.path)
+	}
+
+	// Rules written for destination target paths match the path they were written for
+	escaped := escapeGitAttributesPath("media/demo video[1].mp4")
+	_, tracked := lfsTrackingPattern(map[string]string{"": escaped + " " + lfsAttributes}, "media/demo video[1].mp4")
+	assert.True(t, tracked)
+}
+
+func TestLFSConfigURL(t *testing.T) {
+	assert.Equal(t, "https://lfs.example.com/objects", lfsConfigURL("[core]\n\turl = ignored\n[lfs]\n\turl = https://lfs.example.com/objects\n"))
+	assert.Equal(t, "", lfsConfigURL("[remote \"origin\"]\n\turl = https://example.com\n"))
+	assert.Equal(t, "", lfsConfigURL(""))
+}
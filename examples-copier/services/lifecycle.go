@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// InFlightJob describes a merged PR that is being processed in the background
+type InFlightJob struct {
+	PRNumber        int       `json:"pr_number"`
+	SourceRepo      string    `json:"source_repo"`
+	SourceCommitSHA string    `json:"source_commit_sha"`
+	BaseBranch      string    `json:"base_branch"`
+	StartedAt       time.Time `json:"started_at"`
+}
+
+// InFlightTracker tracks background webhook processing so shutdown can wait for it to finish.
+// Once draining starts, no new jobs are accepted.
+type InFlightTracker struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	jobs     map[int64]InFlightJob
+	nextID   int64
+	draining bool
+}
+
+// NewInFlightTracker creates a new in-flight job tracker
+func NewInFlightTracker() *InFlightTracker {
+	return &InFlightTracker{
+		jobs: make(map[int64]InFlightJob),
+	}
+}
+
+// Start registers a job and returns a function to call when it finishes.
+// Returns false if the tracker is draining and the job should not be started.
+func (t *InFlightTracker) Start(job InFlightJob) (func(), bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.draining {
+		return nil, false
+	}
+
+	if job.StartedAt.IsZero() {
+		job.StartedAt = time.Now()
+	}
+	id := t.nextID
+	t.nextID++
+	t.jobs[id] = job
+	t.wg.Add(1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			delete(t.jobs, id)
+			t.mu.Unlock()
+			t.wg.Done()
+		})
+	}, true
+}
+
+// Count returns the number of jobs currently in flight
+func (t *InFlightTracker) Count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.jobs)
+}
+
+// IsDraining returns true once Drain has been called
+func (t *InFlightTracker) IsDraining() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.draining
+}
+
+// Drain stops accepting new jobs and waits for in-flight jobs to finish or for ctx to be done.
+// Returns the jobs that were still running when ctx was done (empty if all finished).
+func (t *InFlightTracker) Drain(ctx context.Context) []InFlightJob {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	unfinished := make([]InFlightJob, 0, len(t.jobs))
+	for _, job := range t.jobs {
+		unfinished = append(unfinished, job)
+	}
+	return unfinished
+}
+
+// Warmup pre-loads the copier config and the GitHub installation tokens for every org the
+// workflows read from or write to, so the first webhook after a deploy doesn't pay for them.
+// GitLab sources are skipped since they don't use GitHub tokens. Token failures are logged and skipped; the token is fetched again when it's needed.
+func Warmup(ctx context.Context, container *ServiceContainer) error {
+	startTime := time.Now()
+
+	yamlConfig, err := container.ConfigLoader.LoadConfig(ctx, container.Config)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	orgs := make(map[string]bool)
+	for _, workflow := range yamlConfig.Workflows {
+		var repos []string
+		if workflow.Destination.GetPlatform() == types.SourcePlatformGitHub {
+			repos = append(repos, workflow.Destination.Repo)
+		}
+		if workflow.Source.GetPlatform() == types.SourcePlatformGitHub {
+			repos = append(repos, workflow.Source.Repo)
+		}
+		for _, repo := range repos {
+			if owner, _, found := strings.Cut(repo, "/"); found && owner != "" {
+				orgs[owner] = true
+			}
+		}
+	}
+
+	tokensLoaded := 0
+	for org := range orgs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := GetRestClientForOrg(org); err != nil {
+			LogWarningCtx(ctx, "warmup: failed to load installation token", map[string]interface{}{
+				"org":   org,
+				"error": err.Error(),
+			})
+			continue
+		}
+		tokensLoaded++
+	}
+
+	LogInfoCtx(ctx, "warmup complete", map[string]interface{}{
+		"workflow_count": len(yamlConfig.Workflows),
+		"org_count":      len(orgs),
+		"tokens_loaded":  tokensLoaded,
+		"elapsed_ms":     time.Since(startTime).Milliseconds(),
+	})
+	return nil
+}
+
+// WarmupHandler handles App Engine warmup requests (/_ah/warmup)
+func WarmupHandler(container *ServiceContainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := Warmup(r.Context(), container); err != nil {
+			LogErrorCtx(r.Context(), "warmup failed", err, nil)
+			http.Error(w, "warmup failed", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	}
+}
+
+// RecordUnfinishedJobs records jobs that were interrupted by shutdown in the audit log and
+// sends a Slack alert, so the PRs can be replayed instead of being silently dropped.
+func RecordUnfinishedJobs(ctx context.Context, container *ServiceContainer, jobs []InFlightJob) {
+	for _, job := range jobs {
+		err := fmt.Errorf("shutdown before processing completed (started %s ago)", time.Since(job.StartedAt).Round(time.Second))
+
+		LogCritical(fmt.Sprintf("Interrupted processing of PR #%d from %s at %s: %v", job.PRNumber, job.SourceRepo, job.SourceCommitSHA, err))
+
+		if auditErr := container.AuditLogger.LogErrorEvent(ctx, &AuditEvent{
+			SourceRepo:   job.SourceRepo,
+			CommitSHA:    job.SourceCommitSHA,
+			PRNumber:     job.PRNumber,
+			ErrorMessage: err.Error(),
+			AdditionalData: map[string]any{
+				"interrupted_by_shutdown": true,
+				"base_branch":             job.BaseBranch,
+			},
+		}); auditErr != nil {
+			LogWarning(fmt.Sprintf("Failed to record interrupted PR #%d in audit log: %v", job.PRNumber, auditErr))
+		}
+
+		container.SlackNotifier.NotifyError(ctx, &ErrorEvent{
+			Operation:  "shutdown",
+			Error:      err,
+			PRNumber:   job.PRNumber,
+			SourceRepo: job.SourceRepo,
+			AdditionalInfo: map[string]interface{}{
+				"base_branch": job.BaseBranch,
+				"sha":         job.SourceCommitSHA,
+			},
+		})
+	}
+}
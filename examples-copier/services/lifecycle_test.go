@@ -0,0 +1,74 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlightTracker_StartAndDone(t *testing.T) {
+	tracker := services.NewInFlightTracker()
+
+	done, ok := tracker.Start(services.InFlightJob{PRNumber: 1})
+	require.True(t, ok)
+	assert.Equal(t, 1, tracker.Count())
+
+	done()
+	assert.Equal(t, 0, tracker.Count())
+
+	// Calling done twice must not panic or double-decrement
+	done()
+	assert.Equal(t, 0, tracker.Count())
+}
+
+func TestInFlightTracker_DrainWaitsForJobs(t *testing.T) {
+	tracker := services.NewInFlightTracker()
+
+	done, ok := tracker.Start(services.InFlightJob{PRNumber: 1})
+	require.True(t, ok)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		done()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	unfinished := tracker.Drain(ctx)
+	assert.Empty(t, unfinished)
+	assert.Equal(t, 0, tracker.Count())
+}
+
+func TestInFlightTracker_DrainTimeoutReturnsUnfinishedJobs(t *testing.T) {
+	tracker := services.NewInFlightTracker()
+
+	_, ok := tracker.Start(services.InFlightJob{PRNumber: 42, SourceRepo: "org/repo"})
+	require.True(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	unfinished := tracker.Drain(ctx)
+	require.Len(t, unfinished, 1)
+	assert.Equal(t, 42, unfinished[0].PRNumber)
+	assert.Equal(t, "org/repo", unfinished[0].SourceRepo)
+	assert.False(t, unfinished[0].StartedAt.IsZero())
+}
+
+func TestInFlightTracker_RejectsJobsWhileDraining(t *testing.T) {
+	tracker := services.NewInFlightTracker()
+	assert.False(t, tracker.IsDraining())
+
+	tracker.Drain(context.Background())
+	assert.True(t, tracker.IsDraining())
+
+	done, ok := tracker.Start(services.InFlightJob{PRNumber: 1})
+	assert.False(t, ok)
+	assert.Nil(t, done)
+	assert.Equal(t, 0, tracker.Count())
+}
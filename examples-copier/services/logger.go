@@ -5,10 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
-	"time"
 
 	"cloud.google.com/go/logging"
 	"github.com/mongodb/code-example-tooling/code-copier/configs"
@@ -79,35 +80,124 @@ func LogDebug(message string) {
 	if googleInfoLogger != nil && gcpLoggingEnabled {
 		googleInfoLogger.Println("[DEBUG] " + message)
 	}
-	log.Println("[DEBUG] " + message)
+	writeStdout(slog.LevelDebug, message, nil)
 }
 
 func LogInfo(message string) {
+	logInfo(message, nil)
+}
+
+func LogWarning(message string) {
+	logWarning(message, nil)
+}
+
+func LogError(message string) {
+	logError(message, nil)
+}
+
+func LogCritical(message string) {
+	if googleCriticalLogger != nil && gcpLoggingEnabled {
+		googleCriticalLogger.Println(message)
+	}
+	writeStdout(levelCritical, message, nil)
+}
+
+func logInfo(message string, fields map[string]interface{}) {
 	if googleInfoLogger != nil && gcpLoggingEnabled {
-		googleInfoLogger.Println(message)
+		googleInfoLogger.Println(formatLogFields(message, fields))
 	}
-	log.Println("[INFO] " + message)
+	writeStdout(slog.LevelInfo, message, fields)
 }
 
-func LogWarning(message string) {
+func logWarning(message string, fields map[string]interface{}) {
 	if googleWarningLogger != nil && gcpLoggingEnabled {
-		googleWarningLogger.Println(message)
+		googleWarningLogger.Println(formatLogFields(message, fields))
 	}
-	log.Println("[WARN] " + message)
+	writeStdout(slog.LevelWarn, message, fields)
 }
 
-func LogError(message string) {
+func logError(message string, fields map[string]interface{}) {
 	if googleErrorLogger != nil && gcpLoggingEnabled {
-		googleErrorLogger.Println(message)
+		googleErrorLogger.Println(formatLogFields(message, fields))
 	}
-	log.Println("[ERROR] " + message)
+	writeStdout(slog.LevelError, message, fields)
 }
 
-func LogCritical(message string) {
-	if googleCriticalLogger != nil && gcpLoggingEnabled {
-		googleCriticalLogger.Println(message)
+// levelCritical is the slog level for LogCritical, above slog.LevelError
+const levelCritical = slog.Level(12)
+
+// levelPrefixes are the line prefixes for each level in the text log format
+var levelPrefixes = map[slog.Level]string{
+	slog.LevelDebug: "[DEBUG] ",
+	slog.LevelInfo:  "[INFO] ",
+	slog.LevelWarn:  "[WARN] ",
+	slog.LevelError: "[ERROR] ",
+	levelCritical:   "[CRITICAL] ",
+}
+
+// writeStdout writes a log line to the standard logger's output. With LOG_FORMAT=json, each line is
+// a JSON object with the message and each field as a key; otherwise it's the level prefix, the
+// message, and the fields as JSON.
+func writeStdout(level slog.Level, message string, fields map[string]interface{}) {
+	if !isJSONLogFormat() {
+		log.Println(levelPrefixes[level] + formatLogFields(message, fields))
+		return
+	}
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, key := range sortedFieldKeys(fields) {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+	jsonLogger().LogAttrs(context.Background(), level, message, attrs...)
+}
+
+// jsonLogger returns a logger that writes JSON lines to the standard logger's output. It uses the
+// "severity" and "message" keys, which Cloud Logging reads from the structured logs of Cloud Run
+// and App Engine services.
+func jsonLogger() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(log.Writer(), &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return attr
+			}
+			switch attr.Key {
+			case slog.LevelKey:
+				level, _ := attr.Value.Any().(slog.Level)
+				return slog.String("severity", severityForLevel(level))
+			case slog.MessageKey:
+				attr.Key = "message"
+			}
+			return attr
+		},
+	}))
+}
+
+func severityForLevel(level slog.Level) string {
+	switch {
+	case level >= levelCritical:
+		return "CRITICAL"
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARNING"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
 	}
-	log.Println("[CRITICAL] " + message)
+}
+
+func sortedFieldKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isJSONLogFormat() bool {
+	return strings.EqualFold(os.Getenv("LOG_FORMAT"), "json")
 }
 
 func isDebugEnabled() bool {
@@ -124,16 +214,26 @@ func isCloudLoggingDisabled() bool {
 
 // Context-aware logging functions
 
+// LogDebugCtx logs a debug message with context and additional fields, when debug logging is enabled
+func LogDebugCtx(ctx context.Context, message string, fields map[string]interface{}) {
+	if !isDebugEnabled() {
+		return
+	}
+	fields = contextFields(ctx, fields)
+	if googleInfoLogger != nil && gcpLoggingEnabled {
+		googleInfoLogger.Println("[DEBUG] " + formatLogFields(message, fields))
+	}
+	writeStdout(slog.LevelDebug, message, fields)
+}
+
 // LogInfoCtx logs an info message with context and additional fields
 func LogInfoCtx(ctx context.Context, message string, fields map[string]interface{}) {
-	msg := formatLogMessage(ctx, message, fields)
-	LogInfo(msg)
+	logInfo(message, contextFields(ctx, fields))
 }
 
 // LogWarningCtx logs a warning message with context and additional fields
 func LogWarningCtx(ctx context.Context, message string, fields map[string]interface{}) {
-	msg := formatLogMessage(ctx, message, fields)
-	LogWarning(msg)
+	logWarning(message, contextFields(ctx, fields))
 }
 
 // LogErrorCtx logs an error message with context and additional fields
@@ -144,8 +244,16 @@ func LogErrorCtx(ctx context.Context, message string, err error, fields map[stri
 	if err != nil {
 		fields["error"] = err.Error()
 	}
-	msg := formatLogMessage(ctx, message, fields)
-	LogError(msg)
+	logError(message, contextFields(ctx, fields))
+}
+
+// LogCriticalCtx logs a critical message with context and additional fields
+func LogCriticalCtx(ctx context.Context, message string, fields map[string]interface{}) {
+	fields = contextFields(ctx, fields)
+	if googleCriticalLogger != nil && gcpLoggingEnabled {
+		googleCriticalLogger.Println(formatLogFields(message, fields))
+	}
+	writeStdout(levelCritical, message, fields)
 }
 
 // LogWebhookOperation logs webhook-related operations
@@ -197,7 +305,12 @@ func LogAndReturnError(ctx context.Context, operation string, message string, er
 
 // formatLogMessage formats a log message with context and fields
 func formatLogMessage(ctx context.Context, message string, fields map[string]interface{}) string {
-	if fields == nil || len(fields) == 0 {
+	return formatLogFields(message, contextFields(ctx, fields))
+}
+
+// formatLogFields formats a log message with fields as JSON, for the text log format
+func formatLogFields(message string, fields map[string]interface{}) string {
+	if len(fields) == 0 {
 		return message
 	}
 
@@ -210,13 +323,33 @@ func formatLogMessage(ctx context.Context, message string, fields map[string]int
 	return fmt.Sprintf("%s | %s", message, string(fieldsJSON))
 }
 
-// WithRequestID adds a request ID to the context and returns both the context and the ID
+// contextFields returns fields with the correlation ID from ctx added, if ctx has one. fields
+// isn't modified.
+func contextFields(ctx context.Context, fields map[string]interface{}) map[string]interface{} {
+	correlationID := CorrelationIDFromContext(ctx)
+	if correlationID == "" {
+		return fields
+	}
+	if _, ok := fields["correlation_id"]; ok {
+		return fields
+	}
+	withID := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		withID[k] = v
+	}
+	withID["correlation_id"] = correlationID
+	return withID
+}
+
+// WithRequestID adds a request ID to the context and returns both the context and the ID. The ID is
+// the webhook delivery ID when there is one, and is also the context's correlation ID, so every log
+// line, GitHub API request, and pull request for the delivery can be traced back to it.
 func WithRequestID(r *http.Request) (context.Context, string) {
-	// Generate a simple request ID
-	requestID := fmt.Sprintf("%d", time.Now().UnixNano())
+	requestID := correlationIDForRequest(r)
 
 	// Add to context
 	ctx := context.WithValue(r.Context(), "request_id", requestID)
+	ctx = WithCorrelationID(ctx, requestID)
 
 	return ctx, requestID
 }
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
+)
+
+// copierTrailer is added to every commit the copier makes, so webhooks for those commits can be
+// recognized and skipped. Without it, two workflows copying between the same repos in opposite
+// directions would trigger each other indefinitely.
+const copierTrailer = "Copied-by: examples-copier"
+
+// copierBranchName matches the temporary branches the copier opens PRs from (see addFilesViaPR), and the
+// branches the branch commit strategy pushes to (see sourcePRBranch). Release branches end in the tag, so
+// they allow the characters tags are usually made of.
+const copierBranchName = `copier/(\d{8}-\d{6}|source-((pr|mr|push|chain)-[0-9a-f]+|run-\d+|release-[\w.+/-]+))`
+
+// copierBranch matches a copier branch name
+var copierBranch = regexp.MustCompile(`^` + copierBranchName + `$`)
+
+// copierMergeCommit matches the message of the merge commit GitHub creates when a copier PR is merged
+var copierMergeCommit = regexp.MustCompile(`^Merge pull request #\d+ from [^/\s]+/` + copierBranchName + `(\s|$)`)
+
+// addCopierTrailer marks a commit message as made by the copier
+func addCopierTrailer(message string) string {
+	return addTrailer(message, copierTrailer)
+}
+
+// isCopierCommit reports whether a commit message is for a commit the copier made, or the merge of a copier PR
+func isCopierCommit(message string) bool {
+	if copierMergeCommit.MatchString(message) {
+		return true
+	}
+	for _, line := range strings.Split(message, "\n") {
+		if strings.TrimSpace(line) == copierTrailer {
+			return true
+		}
+	}
+	return false
+}
+
+// isCopierPush reports whether every commit in a push was made by the copier
+func isCopierPush(evt *github.PushEvent) bool {
+	messages := make([]string, 0, len(evt.Commits))
+	for _, commit := range evt.Commits {
+		messages = append(messages, commit.GetMessage())
+	}
+	if len(messages) == 0 && evt.HeadCommit != nil {
+		messages = append(messages, evt.GetHeadCommit().GetMessage())
+	}
+	if len(messages) == 0 {
+		return false
+	}
+	for _, message := range messages {
+		if !isCopierCommit(message) {
+			return false
+		}
+	}
+	return true
+}
+
+// isCopierPullRequest reports whether a PR was opened by the copier: it has the copier label, or was
+// opened from one of the copier's temporary branches
+func isCopierPullRequest(pr *github.PullRequest, label string) bool {
+	if copierBranch.MatchString(pr.GetHead().GetRef()) {
+		return true
+	}
+	if label == "" {
+		return false
+	}
+	for _, l := range pr.Labels {
+		if strings.EqualFold(l.GetName(), label) {
+			return true
+		}
+	}
+	return false
+}
+
+// addCopierLabel labels a PR the copier opened. PRs are still recognized by their branch name if
+// labeling fails, so failures are only logged.
+func addCopierLabel(ctx context.Context, client *github.Client, repo string, number int, label string) {
+	if label == "" {
+		return
+	}
+	owner, repoName := parseRepoPath(repo)
+	if _, _, err := client.Issues.AddLabelsToIssue(ctx, owner, repoName, number, []string{label}); err != nil {
+		LogWarningCtx(ctx, fmt.Sprintf("Failed to add label %q to PR #%d in %s: %v", label, number, repo, err), nil)
+	}
+}
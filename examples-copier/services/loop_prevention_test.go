@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddCopierTrailer(t *testing.T) {
+	message := addCopierTrailer("Update examples\n")
+	assert.Equal(t, "Update examples\n\n"+copierTrailer, message)
+	assert.Equal(t, message, addCopierTrailer(message), "the trailer isn't added twice")
+	assert.True(t, isCopierCommit(message))
+}
+
+func TestIsCopierCommit(t *testing.T) {
+	assert.True(t, isCopierCommit("Update examples\n\n"+copierTrailer+"\nSigned-off-by: Bot <bot@example.com>"))
+	assert.True(t, isCopierCommit("Merge pull request #12 from org/copier/20250101-120000\n\nUpdate examples"))
+	assert.True(t, isCopierCommit("Merge pull request #13 from org/copier/source-pr-123\n\nUpdate examples"))
+	assert.False(t, isCopierCommit("Merge pull request #12 from org/feature\n\nUpdate examples"))
+	assert.False(t, isCopierCommit("Fix typo in example\n\nMentions Copied-by: examples-copier inline"))
+}
+
+func TestIsCopierPush(t *testing.T) {
+	copied := &github.HeadCommit{Message: github.String("Update examples\n\n" + copierTrailer)}
+	manual := &github.HeadCommit{Message: github.String("Fix typo")}
+
+	assert.True(t, isCopierPush(&github.PushEvent{Commits: []*github.HeadCommit{copied}}))
+	assert.False(t, isCopierPush(&github.PushEvent{Commits: []*github.HeadCommit{copied, manual}}),
+		"pushes with other commits are still processed")
+	assert.True(t, isCopierPush(&github.PushEvent{HeadCommit: copied}))
+	assert.False(t, isCopierPush(&github.PushEvent{}))
+}
+
+func TestIsCopierPullRequest(t *testing.T) {
+	branch := func(ref string) *github.PullRequestBranch { return &github.PullRequestBranch{Ref: github.String(ref)} }
+
+	assert.True(t, isCopierPullRequest(&github.PullRequest{Head: branch("copier/20250101-120000")}, ""))
+	assert.True(t, isCopierPullRequest(&github.PullRequest{Head: branch("copier/source-pr-123")}, ""))
+	assert.False(t, isCopierPullRequest(&github.PullRequest{Head: branch("copier-fixes")}, "examples-copier"))
+	assert.True(t, isCopierPullRequest(&github.PullRequest{
+		Head:   branch("renamed"),
+		Labels: []*github.Label{{Name: github.String("docs")}, {Name: github.String("Examples-Copier")}},
+	}, "examples-copier"))
+	assert.False(t, isCopierPullRequest(&github.PullRequest{
+		Head:   branch("renamed"),
+		Labels: []*github.Label{{Name: github.String("examples-copier")}},
+	}, ""))
+}
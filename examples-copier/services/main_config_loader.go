@@ -66,7 +66,12 @@ func (mcl *DefaultMainConfigLoader) LoadMainConfig(ctx context.Context, config *
 		}
 	}
 
-	return mcl.LoadMainConfigFromContent(ctx, content, config)
+	yamlConfig, err := mcl.LoadMainConfigFromContent(ctx, content, config)
+	if err != nil {
+		return nil, err
+	}
+	loadSourceIgnores(ctx, yamlConfig, config)
+	return yamlConfig, nil
 }
 
 // LoadMainConfigFromContent loads main configuration from a string and resolves references
@@ -106,8 +111,9 @@ func (mcl *DefaultMainConfigLoader) LoadMainConfigFromContent(ctx context.Contex
 // resolveWorkflowReferences resolves all workflow config references and merges them
 func (mcl *DefaultMainConfigLoader) resolveWorkflowReferences(ctx context.Context, mainConfig *types.MainConfig, config *configs.Config) (*types.YAMLConfig, error) {
 	mergedConfig := &types.YAMLConfig{
-		Defaults:  mainConfig.Defaults,
-		Workflows: []types.Workflow{},
+		Defaults:       mainConfig.Defaults,
+		Workflows:      []types.Workflow{},
+		ConflictPolicy: mainConfig.ConflictPolicy,
 	}
 
 	// Process each workflow config reference
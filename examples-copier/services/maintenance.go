@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+)
+
+// MaintenanceController switches the copier in and out of maintenance mode. During maintenance,
+// merged PRs are still accepted but are persisted to a queue instead of being processed, so config
+// repo migrations and credential rotations don't lose events. The queue is processed when
+// maintenance ends.
+type MaintenanceController struct {
+	store MaintenanceStore
+	now   func() time.Time
+}
+
+// MaintenanceStatus is the JSON body returned by the admin maintenance endpoint
+type MaintenanceStatus struct {
+	Enabled bool       `json:"enabled"`
+	Since   *time.Time `json:"since,omitempty"`
+	Queued  int        `json:"queued"`
+	Drained int        `json:"drained,omitempty"`
+}
+
+// maintenanceClaimLease is how long a drain holds the changes it claimed. Changes still held when the
+// lease ends, because the instance stopped, are processed by the next drain.
+const maintenanceClaimLease = time.Hour
+
+// maintenanceDrains numbers this instance's drains, so each makes its own claim
+var maintenanceDrains atomic.Int64
+
+// NewMaintenanceController creates a controller backed by the given store
+func NewMaintenanceController(store MaintenanceStore) *MaintenanceController {
+	return &MaintenanceController{store: store, now: time.Now}
+}
+
+// newMaintenanceController returns the controller for the configured store, turning maintenance mode on
+// if MAINTENANCE_MODE is set. Without it, a shared store keeps whatever state it has.
+func newMaintenanceController(ctx context.Context, config *configs.Config, mongoClient *SharedMongoClient) (*MaintenanceController, error) {
+	var store MaintenanceStore = NewFileMaintenanceStore(config.MaintenanceQueueFile)
+	if config.MaintenanceStore == configs.MaintenanceStoreMongoDB {
+		mongoStore, err := NewMongoMaintenanceStore(ctx, mongoClient, config.AuditDatabase, config.MaintenanceCollection)
+		if err != nil {
+			return nil, err
+		}
+		store = mongoStore
+	}
+	m := NewMaintenanceController(store)
+	if config.MaintenanceMode {
+		if _, err := m.SetEnabled(ctx, true); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Enabled returns true while maintenance mode is on. It returns false if the state can't be read.
+func (m *MaintenanceController) Enabled(ctx context.Context) bool {
+	if m == nil {
+		return false
+	}
+	enabled, _, err := m.store.State(ctx)
+	if err != nil {
+		LogWarningCtx(ctx, "failed to read maintenance state", map[string]interface{}{"error": err.Error()})
+		return false
+	}
+	return enabled
+}
+
+// SetEnabled turns maintenance mode on or off and returns the previous state
+func (m *MaintenanceController) SetEnabled(ctx context.Context, enabled bool) (bool, error) {
+	return m.store.SetEnabled(ctx, enabled, m.now())
+}
+
+// Status returns the current maintenance state and the number of queued deliveries
+func (m *MaintenanceController) Status(ctx context.Context) (MaintenanceStatus, error) {
+	enabled, since, err := m.store.State(ctx)
+	if err != nil {
+		return MaintenanceStatus{}, err
+	}
+	status := MaintenanceStatus{Enabled: enabled}
+	if enabled {
+		status.Since = &since
+	}
+	entries, err := m.store.List(ctx)
+	if err != nil {
+		return MaintenanceStatus{}, err
+	}
+	status.Queued = len(entries)
+	return status, nil
+}
+
+// DeferIfEnabled queues the change if maintenance mode is on. deferred is false if maintenance is
+// off, in which case the change should be processed now. If the state can't be read, deferred is
+// true with the error, so the delivery is rejected and redelivered rather than processed during
+// maintenance.
+func (m *MaintenanceController) DeferIfEnabled(ctx context.Context, change CopyEvent) (deferred bool, err error) {
+	if m == nil {
+		return false, nil
+	}
+	return m.store.Defer(ctx, change, m.now())
+}
+
+// queued returns the changes in the queue, in the order they arrived
+func (m *MaintenanceController) queued(ctx context.Context) ([]CopyEvent, error) {
+	entries, err := m.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]CopyEvent, 0, len(entries))
+	for _, entry := range entries {
+		changes = append(changes, entry.Change)
+	}
+	return changes, nil
+}
+
+// DrainMaintenanceQueue claims the changes deferred during maintenance and processes them, one at a
+// time, in the background. Every change is registered as in flight first, so a shutdown during the
+// drain records the unprocessed ones for replay. Each change leaves the queue once it's processed.
+// Returns the number of changes that will be processed.
+func DrainMaintenanceQueue(ctx context.Context, config *configs.Config, container *ServiceContainer) (int, error) {
+	m := container.Maintenance
+	claim := fmt.Sprintf("%s-drain-%d", instanceID, maintenanceDrains.Add(1))
+	entries, err := m.store.Claim(ctx, claim, m.now(), maintenanceClaimLease)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	dones := make([]func(), 0, len(entries))
+	for _, entry := range entries {
+		change := entry.Change
+		done, ok := container.InFlight.Start(InFlightJob{
+			PRNumber:        change.Number,
+			SourceRepo:      change.Repo,
+			SourceCommitSHA: change.CommitSHA,
+			BaseBranch:      change.BaseBranch,
+		})
+		if !ok {
+			// Shutting down: put everything back so the next instance processes it
+			for _, d := range dones {
+				d()
+			}
+			if err := m.store.Release(ctx, entries, claim); err != nil {
+				return 0, fmt.Errorf("server is shutting down and %d deferred changes could not be requeued: %w", len(entries), err)
+			}
+			return 0, fmt.Errorf("server is shutting down; %d deferred changes were requeued", len(entries))
+		}
+		dones = append(dones, done)
+	}
+
+	LogInfoCtx(ctx, "draining maintenance queue", map[string]interface{}{
+		"count": len(entries),
+	})
+
+	// Changes are scheduled in the order they arrived, alongside new webhooks
+	var wg sync.WaitGroup
+	wg.Add(len(entries))
+	for i, entry := range entries {
+		done := dones[i]
+		scheduleMergedChange(entry.Change, config, container, func() {
+			if err := m.store.Done(context.Background(), entry, claim); err != nil {
+				LogWarning(fmt.Sprintf("Failed to remove processed change from maintenance queue: %v", err))
+			}
+			done()
+			wg.Done()
+		})
+	}
+	go func() {
+		wg.Wait()
+		LogInfoCtx(context.Background(), "maintenance queue drained", map[string]interface{}{
+			"count": len(entries),
+		})
+	}()
+
+	return len(entries), nil
+}
+
+// MaintenanceHandler handles the admin maintenance endpoint. GET returns the current status.
+// POST with {"enabled": true} starts maintenance; {"enabled": false} ends it and processes the
+// queued deliveries. Requests must send the admin token as a bearer token.
+func MaintenanceHandler(config *configs.Config, container *ServiceContainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validAdminToken(r, config.AdminToken) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var drained int
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var req struct {
+				Enabled *bool `json:"enabled"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+				http.Error(w, `request body must be {"enabled": true|false}`, http.StatusBadRequest)
+				return
+			}
+
+			wasEnabled, err := container.Maintenance.SetEnabled(r.Context(), *req.Enabled)
+			if err != nil {
+				LogErrorCtx(r.Context(), "failed to change maintenance mode", err, nil)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			LogInfoCtx(r.Context(), "maintenance mode changed", map[string]interface{}{
+				"enabled":     *req.Enabled,
+				"was_enabled": wasEnabled,
+			})
+
+			if !*req.Enabled {
+				n, err := DrainMaintenanceQueue(r.Context(), config, container)
+				if err != nil {
+					LogErrorCtx(r.Context(), "failed to drain maintenance queue", err, nil)
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				drained = n
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		status, err := container.Maintenance.Status(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		status.Drained = drained
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(status)
+	}
+}
+
+// validAdminToken checks the request's bearer token, or its basic auth password for browsers, against
+// the configured admin token
+func validAdminToken(r *http.Request, adminToken string) bool {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		_, token, found = r.BasicAuth()
+	}
+	if adminToken == "" || !found {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
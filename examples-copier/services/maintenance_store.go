@@ -0,0 +1,331 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MaintenanceStore holds whether maintenance mode is on and the changes deferred until it ends. The queue
+// is drained by claiming its changes, so when instances share a store, each change is processed by only
+// the instance that claimed it.
+type MaintenanceStore interface {
+	State(ctx context.Context) (enabled bool, since time.Time, err error)
+	SetEnabled(ctx context.Context, enabled bool, now time.Time) (previous bool, err error)
+	// Defer queues the change if maintenance mode is on. deferred is false if it's off, in which case the
+	// change should be processed now.
+	Defer(ctx context.Context, change CopyEvent, now time.Time) (deferred bool, err error)
+	// Claim leases the queued changes no one holds to claim until now+lease, in the order they arrived.
+	// Returns nothing while maintenance mode is on.
+	Claim(ctx context.Context, claim string, now time.Time, lease time.Duration) ([]*DeferredChange, error)
+	Done(ctx context.Context, entry *DeferredChange, claim string) error        // Removes a processed change
+	Release(ctx context.Context, entries []*DeferredChange, claim string) error // Puts claimed changes back
+	List(ctx context.Context) ([]*DeferredChange, error)                        // Returns the queue in arrival order
+}
+
+// DeferredChange is a change queued during maintenance
+type DeferredChange struct {
+	ID         string    `bson:"_id" json:"id"`
+	Change     CopyEvent `bson:"change" json:"change"`
+	QueuedAt   time.Time `bson:"queued_at" json:"queued_at"`
+	Claim      string    `bson:"claim,omitempty" json:"claim,omitempty"`
+	LeaseUntil time.Time `bson:"lease_until,omitempty" json:"lease_until,omitempty"`
+}
+
+// FileMaintenanceStore implements MaintenanceStore with an in-memory flag and a JSON lines queue file.
+// The state belongs to one instance, so claiming the queue takes every change in it.
+type FileMaintenanceStore struct {
+	// mu is held while checking the flag and writing the queue, so a change can't slip between the queue
+	// being drained and maintenance ending
+	mu      sync.Mutex
+	enabled bool
+	since   time.Time
+	path    string
+}
+
+// NewFileMaintenanceStore creates a store that persists deferred changes to path
+func NewFileMaintenanceStore(path string) *FileMaintenanceStore {
+	return &FileMaintenanceStore{path: path}
+}
+
+// State returns whether maintenance mode is on and when it started
+func (s *FileMaintenanceStore) State(ctx context.Context) (bool, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enabled, s.since, nil
+}
+
+// SetEnabled turns maintenance mode on or off and returns the previous state
+func (s *FileMaintenanceStore) SetEnabled(ctx context.Context, enabled bool, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.enabled
+	if enabled && !previous {
+		s.since = now
+	}
+	s.enabled = enabled
+	return previous, nil
+}
+
+// Defer appends the change to the queue file if maintenance mode is on
+func (s *FileMaintenanceStore) Defer(ctx context.Context, change CopyEvent, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.enabled {
+		return false, nil
+	}
+	return true, s.appendQueue([]CopyEvent{change})
+}
+
+// Claim returns the queued changes and removes the queue file
+func (s *FileMaintenanceStore) Claim(ctx context.Context, claim string, now time.Time, lease time.Duration) ([]*DeferredChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.enabled {
+		return nil, nil
+	}
+	entries, err := s.readQueue()
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	if err := os.Remove(s.path); err != nil {
+		return nil, fmt.Errorf("failed to clear maintenance queue: %w", err)
+	}
+	return entries, nil
+}
+
+// Done does nothing, since claimed changes were already removed from the file
+func (s *FileMaintenanceStore) Done(ctx context.Context, entry *DeferredChange, claim string) error {
+	return nil
+}
+
+// Release appends claimed changes back to the queue file
+func (s *FileMaintenanceStore) Release(ctx context.Context, entries []*DeferredChange, claim string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changes := make([]CopyEvent, 0, len(entries))
+	for _, entry := range entries {
+		changes = append(changes, entry.Change)
+	}
+	return s.appendQueue(changes)
+}
+
+// List returns the changes in the queue file
+func (s *FileMaintenanceStore) List(ctx context.Context) ([]*DeferredChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readQueue()
+}
+
+// appendQueue appends changes to the queue file. Callers must hold s.mu.
+func (s *FileMaintenanceStore) appendQueue(changes []CopyEvent) error {
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open maintenance queue: %w", err)
+	}
+	defer f.Close()
+
+	for _, change := range changes {
+		line, err := json.Marshal(change)
+		if err != nil {
+			return fmt.Errorf("failed to encode deferred change: %w", err)
+		}
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("failed to write maintenance queue: %w", err)
+		}
+	}
+	return f.Sync()
+}
+
+// readQueue reads the queue file, numbering the changes by line. A missing file is an empty queue.
+// Callers must hold s.mu.
+func (s *FileMaintenanceStore) readQueue() ([]*DeferredChange, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open maintenance queue: %w", err)
+	}
+	defer f.Close()
+
+	var entries []*DeferredChange
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var change CopyEvent
+		if err := json.Unmarshal([]byte(text), &change); err != nil {
+			LogWarning(fmt.Sprintf("Skipping unreadable maintenance queue entry: %v", err))
+			continue
+		}
+		entries = append(entries, &DeferredChange{ID: strconv.Itoa(line), Change: change})
+	}
+	return entries, scanner.Err()
+}
+
+// maintenanceStateID is the ID of the document holding the maintenance state, alongside the queued changes
+const maintenanceStateID = "state"
+
+// MongoMaintenanceStore implements MaintenanceStore using a MongoDB collection, so maintenance mode and its
+// queue are shared by all instances and survive restarts
+type MongoMaintenanceStore struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewMongoMaintenanceStore returns a store backed by the given collection, connecting the shared client if
+// it isn't yet
+func NewMongoMaintenanceStore(ctx context.Context, mongoClient *SharedMongoClient, database, collection string) (*MongoMaintenanceStore, error) {
+	client, err := mongoClient.Connect(ctx, "the maintenance store is mongodb")
+	if err != nil {
+		return nil, err
+	}
+
+	coll := client.Database(database).Collection(collection)
+	if _, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "queued_at", Value: 1}},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return &MongoMaintenanceStore{client: client, collection: coll}, nil
+}
+
+// maintenanceState is the document holding the maintenance state
+type maintenanceState struct {
+	Enabled bool      `bson:"enabled"`
+	Since   time.Time `bson:"since,omitempty"`
+}
+
+// State returns whether maintenance mode is on and when it started. No state document means it's off.
+func (s *MongoMaintenanceStore) State(ctx context.Context) (bool, time.Time, error) {
+	var state maintenanceState
+	err := s.collection.FindOne(ctx, bson.M{"_id": maintenanceStateID}).Decode(&state)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, time.Time{}, nil
+	}
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("failed to read maintenance state: %w", err)
+	}
+	return state.Enabled, state.Since, nil
+}
+
+// SetEnabled turns maintenance mode on or off for every instance and returns the previous state
+func (s *MongoMaintenanceStore) SetEnabled(ctx context.Context, enabled bool, now time.Time) (bool, error) {
+	var previous maintenanceState
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+	err := s.collection.FindOneAndUpdate(ctx, bson.M{"_id": maintenanceStateID},
+		bson.M{"$set": bson.M{"enabled": enabled}}, opts).Decode(&previous)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return false, fmt.Errorf("failed to update maintenance state: %w", err)
+	}
+	if enabled && !previous.Enabled {
+		if _, err := s.collection.UpdateOne(ctx, bson.M{"_id": maintenanceStateID, "enabled": true},
+			bson.M{"$set": bson.M{"since": now}}); err != nil {
+			return previous.Enabled, fmt.Errorf("failed to update maintenance state: %w", err)
+		}
+	}
+	return previous.Enabled, nil
+}
+
+// Defer queues the change if maintenance mode is on. Another instance can end maintenance and drain the
+// queue between the check and the insert, so the state is checked again after, and if maintenance has
+// ended the change is taken back to be processed now, unless a drain already claimed it.
+func (s *MongoMaintenanceStore) Defer(ctx context.Context, change CopyEvent, now time.Time) (bool, error) {
+	enabled, _, err := s.State(ctx)
+	if err != nil {
+		return true, err
+	}
+	if !enabled {
+		return false, nil
+	}
+
+	entry := &DeferredChange{ID: primitive.NewObjectID().Hex(), Change: change, QueuedAt: now}
+	if _, err := s.collection.InsertOne(ctx, entry); err != nil {
+		return true, fmt.Errorf("failed to queue deferred change: %w", err)
+	}
+
+	if enabled, _, err := s.State(ctx); err != nil || enabled {
+		return true, nil
+	}
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": entry.ID, "claim": bson.M{"$exists": false}})
+	if err != nil {
+		return true, nil
+	}
+	return result.DeletedCount == 0, nil
+}
+
+// Claim atomically leases the queued changes no one holds, so instances draining at once never process the
+// same change. A claim whose lease has ended, because its instance stopped, can be claimed again.
+func (s *MongoMaintenanceStore) Claim(ctx context.Context, claim string, now time.Time, lease time.Duration) ([]*DeferredChange, error) {
+	enabled, _, err := s.State(ctx)
+	if err != nil || enabled {
+		return nil, err
+	}
+
+	filter := bson.M{
+		"change": bson.M{"$exists": true},
+		"$or": bson.A{
+			bson.M{"claim": bson.M{"$exists": false}},
+			bson.M{"lease_until": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"claim": claim, "lease_until": now.Add(lease)}}
+	if _, err := s.collection.UpdateMany(ctx, filter, update); err != nil {
+		return nil, fmt.Errorf("failed to claim maintenance queue: %w", err)
+	}
+	return s.find(ctx, bson.M{"claim": claim})
+}
+
+// Done removes a processed change if claim still holds it
+func (s *MongoMaintenanceStore) Done(ctx context.Context, entry *DeferredChange, claim string) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"_id": entry.ID, "claim": claim})
+	return err
+}
+
+// Release clears the claim on changes claim still holds, so the next drain processes them
+func (s *MongoMaintenanceStore) Release(ctx context.Context, entries []*DeferredChange, claim string) error {
+	ids := make(bson.A, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	_, err := s.collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}, "claim": claim},
+		bson.M{"$unset": bson.M{"claim": "", "lease_until": ""}})
+	return err
+}
+
+// List returns the queued changes, including claimed ones not yet processed
+func (s *MongoMaintenanceStore) List(ctx context.Context) ([]*DeferredChange, error) {
+	return s.find(ctx, bson.M{"change": bson.M{"$exists": true}})
+}
+
+// find returns the queued changes matching filter in the order they arrived
+func (s *MongoMaintenanceStore) find(ctx context.Context, filter bson.M) ([]*DeferredChange, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "queued_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read maintenance queue: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	entries := []*DeferredChange{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to read maintenance queue: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMaintenanceTestContainer(t *testing.T, enabled bool) (*configs.Config, *ServiceContainer) {
+	t.Helper()
+	config := &configs.Config{
+		MaintenanceMode:      enabled,
+		MaintenanceQueueFile: filepath.Join(t.TempDir(), "queue.jsonl"),
+		AdminToken:           "admin-token",
+	}
+	container, err := NewServiceContainer(config)
+	require.NoError(t, err)
+	return config, container
+}
+
+// maintenanceStatus returns the controller's status, failing the test if it can't be read
+func maintenanceStatus(t *testing.T, m *MaintenanceController) MaintenanceStatus {
+	t.Helper()
+	status, err := m.Status(t.Context())
+	require.NoError(t, err)
+	return status
+}
+
+func TestMaintenanceController_DeferIfEnabled(t *testing.T) {
+	ctx := t.Context()
+	m := NewMaintenanceController(NewFileMaintenanceStore(filepath.Join(t.TempDir(), "queue.jsonl")))
+	change := CopyEvent{Platform: types.SourcePlatformGitHub, Repo: "mongodb/docs", Number: 1, CommitSHA: "abc", BaseBranch: "main"}
+
+	deferred, err := m.DeferIfEnabled(ctx, change)
+	require.NoError(t, err)
+	assert.False(t, deferred)
+	assert.Equal(t, 0, maintenanceStatus(t, m).Queued)
+
+	_, err = m.SetEnabled(ctx, true)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		deferred, err = m.DeferIfEnabled(ctx, change)
+		require.NoError(t, err)
+		assert.True(t, deferred)
+	}
+	status := maintenanceStatus(t, m)
+	assert.True(t, status.Enabled)
+	assert.NotNil(t, status.Since)
+	assert.Equal(t, 2, status.Queued)
+
+	// The queue isn't handed out until maintenance ends
+	entries, err := m.store.Claim(ctx, "drain", time.Now(), time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	_, err = m.SetEnabled(ctx, false)
+	require.NoError(t, err)
+	entries, err = m.store.Claim(ctx, "drain", time.Now(), time.Minute)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, change, entries[0].Change)
+	assert.Equal(t, change, entries[1].Change)
+	assert.Equal(t, 0, maintenanceStatus(t, m).Queued)
+}
+
+func TestMaintenanceController_NilIsDisabled(t *testing.T) {
+	var m *MaintenanceController
+	assert.False(t, m.Enabled(t.Context()))
+	deferred, err := m.DeferIfEnabled(t.Context(), CopyEvent{})
+	assert.False(t, deferred)
+	assert.NoError(t, err)
+}
+
+func TestHandleWebhook_DefersMergedMRDuringMaintenance(t *testing.T) {
+	config, container := newMaintenanceTestContainer(t, true)
+
+	payload := `{
+		"object_kind": "merge_request",
+		"project": {"path_with_namespace": "docs/examples/python"},
+		"object_attributes": {"iid": 7, "action": "merge", "state": "merged", "target_branch": "main", "merge_commit_sha": "abc123"}
+	}`
+	req := httptest.NewRequest("POST", "/gitlab/webhook", bytes.NewReader([]byte(payload)))
+	req.Header.Set("X-Gitlab-Event", gitlabMergeRequestEventType)
+	w := httptest.NewRecorder()
+
+	HandleGitLabWebhookWithContainer(w, req, config, container)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.JSONEq(t, `{"status":"queued"}`, w.Body.String())
+	assert.Equal(t, 0, container.InFlight.Count())
+	assert.Equal(t, 1, maintenanceStatus(t, container.Maintenance).Queued)
+	assert.Equal(t, int64(1), container.MetricsCollector.GetMetrics(container.FileStateService).Webhooks.Deferred)
+}
+
+func TestDrainMaintenanceQueue_RequeuesWhenShuttingDown(t *testing.T) {
+	config, container := newMaintenanceTestContainer(t, true)
+	_, err := container.Maintenance.DeferIfEnabled(t.Context(), CopyEvent{Repo: "mongodb/docs", Number: 1})
+	require.NoError(t, err)
+
+	_, err = container.Maintenance.SetEnabled(t.Context(), false)
+	require.NoError(t, err)
+	container.InFlight.Drain(t.Context())
+
+	n, err := DrainMaintenanceQueue(t.Context(), config, container)
+	require.Error(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 1, maintenanceStatus(t, container.Maintenance).Queued)
+}
+
+// claimRecordingMaintenanceStore records the claims a drain makes and releases
+type claimRecordingMaintenanceStore struct {
+	*FileMaintenanceStore
+	claims   []string
+	released []string
+}
+
+func (s *claimRecordingMaintenanceStore) Claim(ctx context.Context, claim string, now time.Time, lease time.Duration) ([]*DeferredChange, error) {
+	s.claims = append(s.claims, claim)
+	return s.FileMaintenanceStore.Claim(ctx, claim, now, lease)
+}
+
+func (s *claimRecordingMaintenanceStore) Release(ctx context.Context, entries []*DeferredChange, claim string) error {
+	s.released = append(s.released, claim)
+	return s.FileMaintenanceStore.Release(ctx, entries, claim)
+}
+
+func TestDrainMaintenanceQueue_ReleasesItsOwnClaim(t *testing.T) {
+	config, container := newMaintenanceTestContainer(t, false)
+	store := &claimRecordingMaintenanceStore{FileMaintenanceStore: NewFileMaintenanceStore(config.MaintenanceQueueFile)}
+	container.Maintenance = NewMaintenanceController(store)
+	_, err := container.Maintenance.SetEnabled(t.Context(), true)
+	require.NoError(t, err)
+	_, err = container.Maintenance.DeferIfEnabled(t.Context(), CopyEvent{Repo: "mongodb/docs", Number: 1})
+	require.NoError(t, err)
+
+	// Nothing is claimed while maintenance is on
+	n, err := DrainMaintenanceQueue(t.Context(), config, container)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	_, err = container.Maintenance.SetEnabled(t.Context(), false)
+	require.NoError(t, err)
+	container.InFlight.Drain(t.Context())
+	_, err = DrainMaintenanceQueue(t.Context(), config, container)
+	require.Error(t, err)
+
+	require.Len(t, store.claims, 2)
+	assert.NotEqual(t, store.claims[0], store.claims[1], "each drain makes its own claim")
+	assert.True(t, strings.HasPrefix(store.claims[1], instanceID))
+	assert.Equal(t, store.claims[1:], store.released)
+	assert.Equal(t, 1, maintenanceStatus(t, container.Maintenance).Queued)
+}
+
+func TestMaintenanceHandler(t *testing.T) {
+	config, container := newMaintenanceTestContainer(t, false)
+	handler := MaintenanceHandler(config, container)
+
+	send := func(method string, body string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/maintenance", bytes.NewReader([]byte(body)))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "", "wrong").Code)
+	assert.Equal(t, http.StatusBadRequest, send("POST", `{}`, "admin-token").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, send("DELETE", "", "admin-token").Code)
+
+	w := send("POST", `{"enabled": true}`, "admin-token")
+	require.Equal(t, http.StatusOK, w.Code)
+	var status MaintenanceStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.True(t, status.Enabled)
+	assert.True(t, container.Maintenance.Enabled(t.Context()))
+
+	w = send("POST", `{"enabled": false}`, "admin-token")
+	require.Equal(t, http.StatusOK, w.Code)
+	status = MaintenanceStatus{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.False(t, status.Enabled)
+	assert.Equal(t, 0, status.Drained)
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// ErrMergeConflict is wrapped by errors from merging a pull request that conflicts with its base branch
+var ErrMergeConflict = errors.New("merge conflicts")
+
+// ErrSkippedForConflict is wrapped by the error from an upload whose PR was closed by the skip on-conflict
+// strategy
+var ErrSkippedForConflict = errors.New("copy skipped because the PR conflicts with the target branch")
+
+// conflictResolver is implemented by the providers that can resolve a copier PR's merge conflicts for the
+// overwrite and skip on-conflict strategies
+type conflictResolver interface {
+	// OverwriteBranch commits commit's files on top of the head of baseBranch, then force-moves branch to
+	// that commit in one step, keeping pull requests from it open. Returns the new commit's SHA.
+	OverwriteBranch(ctx context.Context, repo string, branch string, baseBranch string, commit ProviderCommit) (string, error)
+	// ClosePullRequest closes a pull request without merging it
+	ClosePullRequest(ctx context.Context, repo string, number int) error
+}
+
+// resolveMergeConflict handles a copier PR that couldn't be merged because of mergeErr, a merge conflict,
+// per the workflow's on-conflict strategy:
+//   - overwrite commits the files again on the head of the target branch and moves the PR's branch to that
+//     commit, so the copied files replace whatever changed in the destination, then merges the PR again.
+//     The branch is never reset to the target branch's head on its own, which GitHub would treat as the PR
+//     being merged. The caller deletes the branch once it's merged.
+//   - skip closes the PR, deletes its branch, and returns an error wrapping ErrSkippedForConflict so the
+//     workflow's notifications report it
+//   - manual returns mergeErr, leaving the PR open for someone to resolve
+//
+// Returns the merge commit's SHA if the PR was merged.
+func resolveMergeConflict(ctx context.Context, provider RepoProvider, repo string, pr ProviderPullRequest,
+	branch string, baseBranch string, commit ProviderCommit, onConflict string, mergeErr error) (string, error) {
+
+	if onConflict == "" || onConflict == OnConflictManual {
+		LogWarningCtx(ctx, "Leaving conflicting PR open for manual resolution", map[string]interface{}{
+			"target_repo": repo,
+			"pr_number":   pr.Number,
+		})
+		return "", mergeErr
+	}
+	resolver, ok := provider.(conflictResolver)
+	if !ok {
+		return "", fmt.Errorf("%w; on_conflict %s isn't supported for this destination", mergeErr, onConflict)
+	}
+
+	LogWarningCtx(ctx, "Resolving PR merge conflict", map[string]interface{}{
+		"target_repo": repo,
+		"pr_number":   pr.Number,
+		"on_conflict": onConflict,
+	})
+	switch onConflict {
+	case OnConflictOverwrite:
+		if _, err := resolver.OverwriteBranch(ctx, repo, branch, baseBranch, commit); err != nil {
+			return "", fmt.Errorf("%w; overwrite failed: %w", mergeErr, err)
+		}
+		sha, err := provider.MergePullRequest(ctx, repo, pr.Number)
+		if err != nil {
+			return "", fmt.Errorf("merge after overwriting: %w", err)
+		}
+		return sha, nil
+	case OnConflictSkip:
+		if err := resolver.ClosePullRequest(ctx, repo, pr.Number); err != nil {
+			return "", fmt.Errorf("%w; close PR: %w", mergeErr, err)
+		}
+		provider.DeleteBranch(ctx, repo, branch)
+		return "", fmt.Errorf("%w: closed PR #%d", ErrSkippedForConflict, pr.Number)
+	default:
+		return "", fmt.Errorf("%w; unknown on_conflict %s", mergeErr, onConflict)
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conflictingProvider opens one PR whose first merge conflicts with the target branch, and records what's
+// done to resolve it. Other RepoProvider methods aren't used.
+type conflictingProvider struct {
+	RepoProvider
+	merges   int
+	commits  []string // branches committed to
+	resets   []string // overwritten branches, with the branch they were rebuilt on
+	closed   []int
+	deleted  []string
+	resetErr error
+}
+
+func (p *conflictingProvider) CreateBranch(ctx context.Context, repo string, branch string, baseBranch string) error {
+	return nil
+}
+
+func (p *conflictingProvider) CommitFiles(ctx context.Context, repo string, branch string, commit ProviderCommit) (string, error) {
+	p.commits = append(p.commits, branch)
+	return "commit-sha", nil
+}
+
+func (p *conflictingProvider) OpenPullRequest(ctx context.Context, repo string, head string, base string, title string, body string) (ProviderPullRequest, error) {
+	return ProviderPullRequest{Number: 7, URL: "https://github.com/org/app/pull/7"}, nil
+}
+
+func (p *conflictingProvider) LabelPullRequest(ctx context.Context, repo string, number int, label string) {
+}
+
+func (p *conflictingProvider) MergePullRequest(ctx context.Context, repo string, number int) (string, error) {
+	p.merges++
+	if p.merges == 1 {
+		return "", fmt.Errorf("pull request #%d has %w (state=dirty)", number, ErrMergeConflict)
+	}
+	return "merge-sha", nil
+}
+
+func (p *conflictingProvider) DeleteBranch(ctx context.Context, repo string, branch string) {
+	p.deleted = append(p.deleted, branch)
+}
+
+func (p *conflictingProvider) OverwriteBranch(ctx context.Context, repo string, branch string, baseBranch string, commit ProviderCommit) (string, error) {
+	p.resets = append(p.resets, branch+"<-"+baseBranch)
+	if p.resetErr != nil {
+		return "", p.resetErr
+	}
+	return "overwrite-sha", nil
+}
+
+func (p *conflictingProvider) ClosePullRequest(ctx context.Context, repo string, number int) error {
+	p.closed = append(p.closed, number)
+	return nil
+}
+
+func addConflictingFiles(t *testing.T, provider RepoProvider, onConflict string) (ProviderPullRequest, string, error) {
+	t.Helper()
+	files := []github.RepositoryContent{{Name: github.String("examples/a.go"), Content: github.String("package a\n")}}
+	key := types.UploadKey{RepoName: "org/app", BranchPath: "refs/heads/main"}
+	return addFilesViaPR(context.Background(), provider, "org/app", key, files, nil, nil, "Copy files", nil, "Copy files", "", true, onConflict)
+}
+
+func TestAddFilesViaPR_OnConflictOverwrite(t *testing.T) {
+	provider := &conflictingProvider{}
+	pr, sha, err := addConflictingFiles(t, provider, types.OnConflictOverwrite)
+
+	require.NoError(t, err)
+	assert.Equal(t, 7, pr.Number)
+	assert.Equal(t, "merge-sha", sha)
+	assert.Equal(t, 2, provider.merges)
+	require.Len(t, provider.resets, 1)
+	assert.Regexp(t, `^copier/\d{8}-\d{6}<-main$`, provider.resets[0])
+	assert.Len(t, provider.commits, 1, "the files are committed again along with the branch move, not after it")
+	assert.Len(t, provider.deleted, 1)
+	assert.Empty(t, provider.closed)
+}
+
+func TestAddFilesViaPR_OnConflictSkip(t *testing.T) {
+	provider := &conflictingProvider{}
+	pr, sha, err := addConflictingFiles(t, provider, types.OnConflictSkip)
+
+	require.ErrorIs(t, err, ErrSkippedForConflict)
+	assert.Contains(t, err.Error(), "closed PR #7")
+	assert.Equal(t, "https://github.com/org/app/pull/7", pr.URL)
+	assert.Empty(t, sha)
+	assert.Equal(t, []int{7}, provider.closed)
+	assert.Len(t, provider.deleted, 1)
+	assert.Empty(t, provider.resets)
+}
+
+func TestAddFilesViaPR_OnConflictManual(t *testing.T) {
+	provider := &conflictingProvider{}
+	_, _, err := addConflictingFiles(t, provider, types.OnConflictManual)
+
+	require.ErrorIs(t, err, ErrMergeConflict)
+	assert.Equal(t, 1, provider.merges)
+	assert.Empty(t, provider.resets)
+	assert.Empty(t, provider.closed)
+	assert.Empty(t, provider.deleted, "the PR's branch is left for someone to resolve")
+}
+
+func TestResolveMergeConflict_Failures(t *testing.T) {
+	mergeErr := fmt.Errorf("pull request #7 has %w", ErrMergeConflict)
+	pr := ProviderPullRequest{Number: 7}
+
+	// A failed overwrite leaves the PR open and reports the conflict
+	provider := &conflictingProvider{resetErr: errors.New("forbidden")}
+	_, err := resolveMergeConflict(context.Background(), provider, "org/app", pr, "copier/x", "main", ProviderCommit{}, types.OnConflictOverwrite, mergeErr)
+	require.ErrorIs(t, err, ErrMergeConflict)
+	assert.Contains(t, err.Error(), "forbidden")
+	assert.Empty(t, provider.commits)
+
+	// Providers that can't resolve conflicts leave the PR open
+	unsupported := &reviewerRecorder{}
+	_, err = resolveMergeConflict(context.Background(), unsupported, "org/app", pr, "copier/x", "main", ProviderCommit{}, types.OnConflictSkip, mergeErr)
+	require.ErrorIs(t, err, ErrMergeConflict)
+	assert.Contains(t, err.Error(), "isn't supported")
+}
+
+func TestGitHubProviderOverwriteBranch(t *testing.T) {
+	var parents []string
+	var refUpdates []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/org/app/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "base-sha"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/org/app/git/trees":
+			fmt.Fprint(w, `{"sha": "tree-sha"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/org/app/git/commits":
+			var commit struct {
+				Parents []string `json:"parents"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&commit)
+			parents = commit.Parents
+			fmt.Fprint(w, `{"sha": "new-sha"}`)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/org/app/git/refs/heads/copier/x":
+			update := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&update)
+			refUpdates = append(refUpdates, update)
+			fmt.Fprint(w, `{"ref": "refs/heads/copier/x", "object": {"sha": "new-sha"}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	provider := &githubProvider{client: client}
+	commit := ProviderCommit{Files: map[string]string{"examples/a.go": "package a\n"}, Message: "Copy files"}
+	sha, err := provider.OverwriteBranch(context.Background(), "org/app", "copier/x", "main", commit)
+
+	require.NoError(t, err)
+	assert.Equal(t, "new-sha", sha)
+	assert.Equal(t, []string{"base-sha"}, parents, "the commit is built on the target branch's head")
+	require.Len(t, refUpdates, 1, "the PR's branch is moved once, straight to the new commit")
+	assert.Equal(t, "new-sha", refUpdates[0]["sha"])
+	assert.Equal(t, true, refUpdates[0]["force"])
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// mirrorUploadKey returns the key files for the examples mirror repo are queued under
+func mirrorUploadKey(config *configs.Config) types.UploadKey {
+	return types.UploadKey{RepoName: config.MirrorRepo, BranchPath: config.MirrorBranch}
+}
+
+// queueMirrorFiles queues the files a workflow with mirror enabled queued for its destination to the examples
+// mirror repo as well, under the workflow's mirror directory. Files the change deleted, and files the workflow
+// queued for removal from its destination, are removed from the mirror. Mirror commits go straight to the
+// mirror branch; nothing else is expected to write to the mirror. Deleted files are mapped to their targets with
+// patternMatcher and pathTransformer, the same way the workflow maps changed files.
+func queueMirrorFiles(ctx context.Context, state FileStateService, config *configs.Config, patternMatcher PatternMatcher,
+	pathTransformer PathTransformer, run *workflowRun, changedFiles []types.ChangedFile) {
+
+	if config == nil || config.MirrorRepo == "" || !run.Workflow.Mirror.IsEnabled() || run.DryRun != nil {
+		return
+	}
+	dir := run.Workflow.Mirror.Dir(run.Workflow.Destination.Repo)
+
+	queued := state.GetFilesToUpload()[run.uploadKey()]
+	copied := make(map[string]bool, len(run.Files))
+	for _, p := range run.Files {
+		copied[p] = true
+	}
+	var files []github.RepositoryContent
+	modes := make(map[string]string)
+	for _, file := range queued.Content {
+		if !copied[file.GetName()] {
+			continue
+		}
+		mirrored := file
+		mirrored.Name = github.String(dir + "/" + file.GetName())
+		files = append(files, mirrored)
+		if mode, ok := queued.FileModes[file.GetName()]; ok {
+			modes[mirrored.GetName()] = mode
+		}
+	}
+
+	removed := make(map[string]bool)
+	for _, p := range run.Deletions {
+		removed[dir+"/"+p] = true
+	}
+	wp := &workflowProcessor{patternMatcher: patternMatcher, pathTransformer: pathTransformer}
+	for _, file := range changedFiles {
+		if !isDeletedFile(file) {
+			continue
+		}
+		if targetPath, ok := wp.mapSourcePath(ctx, run.Workflow, file.Path); ok {
+			removed[dir+"/"+targetPath] = true
+		}
+	}
+
+	if len(files) == 0 && len(removed) == 0 {
+		return
+	}
+
+	key := mirrorUploadKey(config)
+	content, exists := state.GetFilesToUpload()[key]
+	if !exists {
+		content = types.UploadFileContent{
+			Content:        []github.RepositoryContent{},
+			CommitStrategy: types.CommitStrategyDirect,
+		}
+	}
+	content.Content = append(content.Content, files...)
+	if len(modes) > 0 && content.FileModes == nil {
+		content.FileModes = make(map[string]string, len(modes))
+	}
+	for p, mode := range modes {
+		content.FileModes[p] = mode
+	}
+	for p := range removed {
+		content.DeletePaths = append(content.DeletePaths, p)
+	}
+	sort.Strings(content.DeletePaths)
+	content.CommitMessage = mirrorCommitMessage(ctx, len(content.Content)+len(content.DeletePaths))
+	state.AddFileToUpload(key, content)
+
+	LogInfoCtx(ctx, "queued files for the examples mirror", map[string]interface{}{
+		"workflow_name": run.Workflow.Name,
+		"mirror_repo":   config.MirrorRepo,
+		"mirror_dir":    dir,
+		"files":         len(files),
+		"deletions":     len(removed),
+	})
+}
+
+// mirrorCommitMessage returns the message for a mirror commit of fileCount files
+func mirrorCommitMessage(ctx context.Context, fileCount int) string {
+	change, ok := sourceChangeFromContext(ctx)
+	if !ok {
+		return fmt.Sprintf("Update examples mirror (%d files)", fileCount)
+	}
+	return fmt.Sprintf("Update examples mirror from %s %s (%d files)", change.Repo, change.describe(), fileCount)
+}
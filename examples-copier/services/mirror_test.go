@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueMirrorFiles(t *testing.T) {
+	config := &configs.Config{MirrorRepo: "org/all-examples", MirrorBranch: "main"}
+	state := NewFileStateService()
+	destination := types.UploadKey{RepoName: "org/go-docs", BranchPath: "main"}
+	state.AddFileToUpload(destination, types.UploadFileContent{
+		CommitStrategy: types.CommitStrategyPR,
+		Content: []github.RepositoryContent{
+			{Name: github.String("code/main.go"), Content: github.String("package main")},
+			{Name: github.String("code/run.sh"), Content: github.String("#!/bin/sh")},
+			{Name: github.String("other/queued-by-another-workflow.go")},
+		},
+		FileModes:   map[string]string{"code/run.sh": types.FileModeExecutable},
+		DeletePaths: []string{"code/stale.go"},
+	})
+	run := &workflowRun{
+		Workflow: types.Workflow{
+			Name:        "go",
+			Destination: types.Destination{Repo: "org/go-docs", Branch: "main"},
+			Transformations: []types.Transformation{
+				{Move: &types.MoveTransform{From: "examples/go", To: "code"}},
+				{Regex: &types.RegexTransform{Pattern: `^scripts/(?P<file>.+)$`, Transform: "code/scripts/${file}"}},
+			},
+			Mirror: &types.MirrorConfig{Enabled: true, Product: "atlas", Language: "go"},
+		},
+		Files:     []string{"code/main.go", "code/run.sh"},
+		Deletions: []string{"code/stale.go"},
+	}
+	changedFiles := []types.ChangedFile{
+		{Path: "examples/go/main.go", Status: "MODIFIED"},
+		{Path: "examples/go/old.go", Status: statusDeleted},
+		{Path: "scripts/setup.sh", Status: statusDeleted},
+		{Path: "README.md", Status: statusDeleted},
+	}
+	ctx := withSourceChange(context.Background(), CopyEvent{Repo: "org/src", Number: 42})
+
+	queueMirrorFiles(ctx, state, config, NewPatternMatcher(), NewPathTransformer(), run, changedFiles)
+
+	mirror, ok := state.GetFilesToUpload()[types.UploadKey{RepoName: "org/all-examples", BranchPath: "main"}]
+	require.True(t, ok)
+	var names []string
+	for _, file := range mirror.Content {
+		names = append(names, file.GetName())
+	}
+	assert.Equal(t, []string{"atlas/go/code/main.go", "atlas/go/code/run.sh"}, names)
+	assert.Equal(t, "package main", *mirror.Content[0].Content)
+	assert.Equal(t, map[string]string{"atlas/go/code/run.sh": types.FileModeExecutable}, mirror.FileModes)
+	assert.Equal(t, []string{"atlas/go/code/old.go", "atlas/go/code/scripts/setup.sh", "atlas/go/code/stale.go"}, mirror.DeletePaths)
+	assert.Equal(t, types.CommitStrategyDirect, mirror.CommitStrategy)
+	assert.Equal(t, "Update examples mirror from org/src PR #42 (5 files)", mirror.CommitMessage)
+
+	// The destination's queued upload is unchanged
+	assert.Len(t, state.GetFilesToUpload()[destination].Content, 3)
+	assert.Equal(t, "code/main.go", state.GetFilesToUpload()[destination].Content[0].GetName())
+}
+
+func TestQueueMirrorFiles_Skipped(t *testing.T) {
+	run := &workflowRun{
+		Workflow: types.Workflow{Name: "go", Destination: types.Destination{Repo: "org/go-docs", Branch: "main"}},
+		Files:    []string{"code/main.go"},
+	}
+	enabled := &types.MirrorConfig{Enabled: true}
+
+	tests := []struct {
+		name   string
+		config *configs.Config
+		mirror *types.MirrorConfig
+		dryRun *DryRunReport
+	}{
+		{name: "no mirror repo", config: &configs.Config{}, mirror: enabled},
+		{name: "mirror not enabled for the workflow", config: &configs.Config{MirrorRepo: "org/all-examples"}},
+		{name: "dry run", config: &configs.Config{MirrorRepo: "org/all-examples"}, mirror: enabled, dryRun: &DryRunReport{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewFileStateService()
+			state.AddFileToUpload(run.uploadKey(), types.UploadFileContent{
+				Content: []github.RepositoryContent{{Name: github.String("code/main.go")}},
+			})
+			r := *run
+			r.Workflow.Mirror = tt.mirror
+			r.DryRun = tt.dryRun
+
+			queueMirrorFiles(context.Background(), state, tt.config, NewPatternMatcher(), NewPathTransformer(), &r, nil)
+
+			assert.Len(t, state.GetFilesToUpload(), 1)
+		})
+	}
+}
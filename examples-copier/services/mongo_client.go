@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SharedMongoClient is the one MongoDB client every MongoDB-backed store uses, so they share a connection
+// pool instead of each opening their own. It connects the first time a store needs it, and is disconnected
+// once by its owner; stores don't disconnect it when they close.
+type SharedMongoClient struct {
+	uri string
+
+	mu     sync.Mutex
+	client *mongo.Client
+}
+
+// NewSharedMongoClient creates a client for mongoURI that connects when it's first used
+func NewSharedMongoClient(mongoURI string) *SharedMongoClient {
+	return &SharedMongoClient{uri: mongoURI}
+}
+
+// Connect returns the connected client, connecting and pinging MongoDB the first time. feature describes
+// what needs MongoDB, for the error if MONGO_URI isn't set, such as "the write log is enabled".
+func (c *SharedMongoClient) Connect(ctx context.Context, feature string) (*mongo.Client, error) {
+	if c == nil || c.uri == "" {
+		return nil, fmt.Errorf("MONGO_URI is required when %s", feature)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != nil {
+		return c.client, nil
+	}
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(c.uri))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+	c.client = client
+	return client, nil
+}
+
+// Disconnect closes the client if it connected
+func (c *SharedMongoClient) Disconnect(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client == nil {
+		return nil
+	}
+	err := c.client.Disconnect(ctx)
+	c.client = nil
+	return err
+}
+
+// instanceID identifies this process among the instances that share MongoDB, as the owner of the jobs and
+// leases it claims
+var instanceID = newInstanceID()
+
+// newInstanceID returns the App Engine instance name, or the hostname, with the process ID and a random
+// suffix so restarts on the same host get a new ID
+func newInstanceID() string {
+	name := os.Getenv("GAE_INSTANCE")
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", name, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedMongoClient_RequiresURI(t *testing.T) {
+	ctx := context.Background()
+	client := NewSharedMongoClient("")
+
+	_, err := client.Connect(ctx, "the write log is enabled")
+	require.Error(t, err)
+	assert.Equal(t, "MONGO_URI is required when the write log is enabled", err.Error())
+	assert.NoError(t, client.Disconnect(ctx), "disconnecting a client that never connected does nothing")
+
+	var unset *SharedMongoClient
+	_, err = unset.Connect(ctx, "audit logging is enabled")
+	assert.Error(t, err)
+	assert.NoError(t, unset.Disconnect(ctx))
+}
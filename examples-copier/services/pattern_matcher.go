@@ -86,9 +86,12 @@ func (pm *DefaultPatternMatcher) matchGlob(filePath, pattern string) types.Match
 	}
 
 	if matched {
-		variables := map[string]string{
-			"matched_pattern": pattern,
+		// Add the wildcard captures, like ${1} and ${relative_path}, for path transforms
+		variables, _ := GlobVariables(pattern, filePath)
+		if variables == nil {
+			variables = make(map[string]string)
 		}
+		variables["matched_pattern"] = pattern
 		return types.NewMatchResult(true, variables)
 	}
 
@@ -221,9 +224,20 @@ func (mt *DefaultMessageTemplater) RenderPRBody(template string, ctx *types.Mess
 	return mt.render(template, ctx)
 }
 
-// render performs the actual template rendering
+// render performs the actual template rendering. Templates using Go template syntax are executed
+// first; ${var} placeholders are then substituted, so values like PR titles aren't parsed as templates.
 func (mt *DefaultMessageTemplater) render(template string, ctx *types.MessageContext) string {
 	result := template
+
+	// Config validation catches template errors, so a failure here leaves the template text as-is
+	if types.IsGoTemplate(template) {
+		rendered, err := types.ExecuteMessageTemplate("message", template, ctx)
+		if err != nil {
+			LogWarning(fmt.Sprintf("failed to render message template: %v", err))
+		} else {
+			result = rendered
+		}
+	}
 	
 	// Built-in context variables
 	replacements := map[string]string{
@@ -235,6 +249,10 @@ func (mt *DefaultMessageTemplater) render(template string, ctx *types.MessageCon
 		"${file_count}":    fmt.Sprintf("%d", ctx.FileCount),
 		"${pr_number}":     fmt.Sprintf("%d", ctx.PRNumber),
 		"${commit_sha}":    ctx.CommitSHA,
+		"${pr_url}":        ctx.SourcePRURL,
+		"${pr_title}":      ctx.SourcePRTitle,
+		"${author}":        ctx.Author,
+		"${release_tag}":   ctx.ReleaseTag,
 	}
 	
 	// Apply built-in replacements
@@ -140,6 +140,97 @@ func TestPatternMatcher_Glob(t *testing.T) {
 	}
 }
 
+func TestPatternMatcher_GlobVariables(t *testing.T) {
+	matcher := services.NewPatternMatcher()
+
+	result := matcher.Match("examples/go/database/connect.go", types.SourcePattern{
+		Type:    types.PatternTypeGlob,
+		Pattern: "examples/*/**/*.go",
+	})
+	require.True(t, result.Matched)
+	assert.Equal(t, map[string]string{
+		"matched_pattern": "examples/*/**/*.go",
+		"1":               "go",
+		"2":               "database",
+		"3":               "connect",
+		"glob_star_1":     "go",
+		"glob_star_2":     "database",
+		"glob_star_3":     "connect",
+		"relative_path":   "database/connect.go",
+	}, result.Variables)
+}
+
+func TestGlobVariables(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    map[string]string
+	}{
+		{
+			name:    "trailing double star",
+			pattern: "mflix/server/**",
+			path:    "mflix/server/java-spring/src/main.java",
+			want:    map[string]string{"1": "java-spring/src/main.java", "glob_star_1": "java-spring/src/main.java", "relative_path": "java-spring/src/main.java"},
+		},
+		{
+			name:    "leading double star",
+			pattern: "**/*.go",
+			path:    "a/b/main.go",
+			want:    map[string]string{"1": "a/b", "2": "main", "glob_star_1": "a/b", "glob_star_2": "main", "relative_path": "a/b/main.go"},
+		},
+		{
+			name:    "double star matching no directories",
+			pattern: "examples/**/*.go",
+			path:    "examples/main.go",
+			want:    map[string]string{"1": "", "2": "main", "glob_star_1": "", "glob_star_2": "main", "relative_path": "main.go"},
+		},
+		{
+			name:    "single stars",
+			pattern: "source/*/generated/*.js",
+			path:    "source/examples/generated/app.js",
+			want:    map[string]string{"1": "examples", "2": "app", "glob_star_1": "examples", "glob_star_2": "app", "relative_path": "examples/generated/app.js"},
+		},
+		{
+			name:    "single characters, classes, and alternatives aren't stars",
+			pattern: "tests/test?_[a-c].{js,ts}",
+			path:    "tests/test1_b.ts",
+			want:    map[string]string{"1": "1", "2": "b", "3": "ts", "relative_path": "test1_b.ts"},
+		},
+		{
+			name:    "negated class",
+			pattern: "v[!0-7]/*.py",
+			path:    "v8/crud.py",
+			want:    map[string]string{"1": "8", "2": "crud", "glob_star_1": "crud", "relative_path": "v8/crud.py"},
+		},
+		{
+			name:    "alternatives with wildcards",
+			pattern: "{docs,examples/*}/*.md",
+			path:    "examples/go/README.md",
+			want:    map[string]string{"1": "examples/go", "2": "README", "glob_star_1": "README", "relative_path": "examples/go/README.md"},
+		},
+		{
+			name:    "no wildcards",
+			pattern: "mflix/README.md",
+			path:    "mflix/README.md",
+			want:    map[string]string{"relative_path": "README.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := services.GlobVariables(tt.pattern, tt.path)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, ok := services.GlobVariables("examples/*/main.go", "examples/go/database/main.go")
+	assert.False(t, ok, "a star doesn't cross directories")
+	_, ok = services.GlobVariables("examples/[a-", "examples/a")
+	assert.False(t, ok, "invalid patterns don't match")
+}
+
 func TestPatternMatcher_Regex(t *testing.T) {
 	matcher := services.NewPatternMatcher()
 
@@ -412,3 +503,60 @@ func TestMessageTemplater_RenderPRBody(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageTemplater_GoTemplates(t *testing.T) {
+	templater := services.NewMessageTemplater()
+	ctx := &types.MessageContext{
+		RuleName:      "java-examples",
+		SourceRepo:    "org/source",
+		FileCount:     2,
+		SourcePRURL:   "https://github.com/org/source/pull/42",
+		SourcePRTitle: "Add aggregation examples for the {{ new }} API",
+		Author:        "octocat",
+		Files:         []string{"java/Agg.java", "java/Match.java"},
+		Variables:     map[string]string{"team": "drivers"},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "fields and functions",
+			template: "{{ .RuleName | upper }}: {{ len .Files }} files by @{{ .Author }}",
+			want:     "JAVA-EXAMPLES: 2 files by @octocat",
+		},
+		{
+			name:     "file list",
+			template: "Files:{{ range .Files }}\n- {{ basename . }}{{ end }}",
+			want:     "Files:\n- Agg.java\n- Match.java",
+		},
+		{
+			name:     "custom variables and placeholders together",
+			template: "{{ .Variables.team }} / ${team} / ${pr_url}",
+			want:     "drivers / drivers / https://github.com/org/source/pull/42",
+		},
+		{
+			name:     "missing variable renders empty",
+			template: "[{{ .Variables.missing }}] {{ .CommitSHA | default \"unknown\" }}",
+			want:     "[] unknown",
+		},
+		{
+			name:     "values aren't parsed as templates",
+			template: "{{ .SourcePRTitle }}",
+			want:     "Add aggregation examples for the {{ new }} API",
+		},
+		{
+			name:     "invalid template is left as-is",
+			template: "Update {{ .SourceRepo",
+			want:     "Update {{ .SourceRepo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, templater.RenderPRBody(tt.template, ctx))
+		})
+	}
+}
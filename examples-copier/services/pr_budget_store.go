@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// prBudgetMaxAttempts is how many times a shared budget is re-read when another instance updates it first
+const prBudgetMaxAttempts = 5
+
+// prLimit is one of the PR limits, as a token bucket's capacity and refill period
+type prLimit struct {
+	capacity int
+	period   time.Duration
+}
+
+// PRBudgetStore keeps the token buckets of each destination repo's PR limits
+type PRBudgetStore interface {
+	// Take takes a token from each of the repo's buckets if they all have one. It returns whether it took
+	// them, and when the repo can open its next PR. A repo that hasn't opened a PR yet starts with full
+	// buckets.
+	Take(ctx context.Context, repo string, limits []prLimit, now time.Time) (taken bool, next time.Time, err error)
+}
+
+// takeTokens takes a token from each bucket if they all have one, and returns whether it took them and
+// when the buckets next all have a token
+func takeTokens(buckets []*tokenBucket, now time.Time) (bool, time.Time) {
+	taken := true
+	for _, bucket := range buckets {
+		if bucket.nextToken(now).After(now) {
+			taken = false
+		}
+	}
+	if taken {
+		for _, bucket := range buckets {
+			bucket.tokens--
+		}
+	}
+	next := now
+	for _, bucket := range buckets {
+		if at := bucket.nextToken(now); at.After(next) {
+			next = at
+		}
+	}
+	return taken, next
+}
+
+// newRepoBuckets returns full buckets for limits
+func newRepoBuckets(limits []prLimit, now time.Time) []*tokenBucket {
+	buckets := make([]*tokenBucket, 0, len(limits))
+	for _, limit := range limits {
+		buckets = append(buckets, newTokenBucket(limit.capacity, limit.period, now))
+	}
+	return buckets
+}
+
+// MemoryPRBudgetStore implements PRBudgetStore in memory, so each instance has its own budget
+type MemoryPRBudgetStore struct {
+	mu      sync.Mutex
+	buckets map[string][]*tokenBucket // Hourly and daily buckets, by destination repo
+}
+
+// NewMemoryPRBudgetStore creates an in-memory store where every repo has its full budget
+func NewMemoryPRBudgetStore() *MemoryPRBudgetStore {
+	return &MemoryPRBudgetStore{buckets: make(map[string][]*tokenBucket)}
+}
+
+// Take takes a token from each of the repo's buckets if they all have one
+func (s *MemoryPRBudgetStore) Take(ctx context.Context, repo string, limits []prLimit, now time.Time) (bool, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buckets, ok := s.buckets[repo]
+	if !ok {
+		buckets = newRepoBuckets(limits, now)
+		s.buckets[repo] = buckets
+	}
+	taken, next := takeTokens(buckets, now)
+	return taken, next, nil
+}
+
+// prBudget is a repo's buckets as stored in MongoDB. Version is bumped by every update, so an update
+// based on buckets another instance has since changed matches nothing and is retried.
+type prBudget struct {
+	Repo    string           `bson:"_id"`
+	Buckets []prBudgetBucket `bson:"buckets"`
+	Version int64            `bson:"version"`
+}
+
+// prBudgetBucket is a token bucket's state
+type prBudgetBucket struct {
+	Capacity int           `bson:"capacity"`
+	Period   time.Duration `bson:"period"`
+	Tokens   float64       `bson:"tokens"`
+	Updated  time.Time     `bson:"updated"`
+}
+
+// MongoPRBudgetStore implements PRBudgetStore using a MongoDB collection, so every instance draws from the
+// same budget for each repo
+type MongoPRBudgetStore struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewMongoPRBudgetStore returns a store backed by the given collection, connecting the shared client if it
+// isn't yet
+func NewMongoPRBudgetStore(ctx context.Context, mongoClient *SharedMongoClient, database, collection string) (*MongoPRBudgetStore, error) {
+	client, err := mongoClient.Connect(ctx, "the PR limit store is mongodb")
+	if err != nil {
+		return nil, err
+	}
+	return &MongoPRBudgetStore{client: client, collection: client.Database(database).Collection(collection)}, nil
+}
+
+// Take reads the repo's buckets, takes a token from each if they all have one, and writes them back if
+// no other instance has changed them meanwhile, re-reading them if one has. Buckets whose limits changed
+// since they were stored start full.
+func (s *MongoPRBudgetStore) Take(ctx context.Context, repo string, limits []prLimit, now time.Time) (bool, time.Time, error) {
+	for attempt := 0; attempt < prBudgetMaxAttempts; attempt++ {
+		var stored prBudget
+		err := s.collection.FindOne(ctx, bson.M{"_id": repo}).Decode(&stored)
+		exists := err == nil
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return false, now, fmt.Errorf("failed to read PR budget of %s: %w", repo, err)
+		}
+
+		buckets := newRepoBuckets(limits, now)
+		if exists && sameLimits(stored.Buckets, limits) {
+			for i, bucket := range stored.Buckets {
+				buckets[i].tokens = bucket.Tokens
+				buckets[i].updated = bucket.Updated
+			}
+		}
+		taken, next := takeTokens(buckets, now)
+		if !taken && exists {
+			return false, next, nil
+		}
+
+		updated := prBudget{Repo: repo, Version: stored.Version + 1}
+		for _, bucket := range buckets {
+			updated.Buckets = append(updated.Buckets, prBudgetBucket{
+				Capacity: int(bucket.capacity),
+				Period:   bucket.period,
+				Tokens:   bucket.tokens,
+				Updated:  bucket.updated,
+			})
+		}
+		if !exists {
+			if _, err := s.collection.InsertOne(ctx, updated); err != nil {
+				if mongo.IsDuplicateKeyError(err) {
+					continue
+				}
+				return false, now, fmt.Errorf("failed to write PR budget of %s: %w", repo, err)
+			}
+			return taken, next, nil
+		}
+		result, err := s.collection.ReplaceOne(ctx, bson.M{"_id": repo, "version": stored.Version}, updated)
+		if err != nil {
+			return false, now, fmt.Errorf("failed to write PR budget of %s: %w", repo, err)
+		}
+		if result.MatchedCount == 1 {
+			return taken, next, nil
+		}
+	}
+	return false, now, fmt.Errorf("PR budget of %s kept changing; gave up after %d attempts", repo, prBudgetMaxAttempts)
+}
+
+// sameLimits returns true if stored buckets were made for limits
+func sameLimits(stored []prBudgetBucket, limits []prLimit) bool {
+	if len(stored) != len(limits) {
+		return false
+	}
+	for i, limit := range limits {
+		if stored[i].Capacity != limit.capacity || stored[i].Period != limit.period {
+			return false
+		}
+	}
+	return true
+}
@@ -51,7 +51,7 @@ func (f *DefaultPRTemplateFetcher) FetchPRTemplate(ctx context.Context, client *
 	for _, path := range templatePaths {
 		content, err := f.fetchFileContent(ctx, client, owner, repo, path, branch)
 		if err == nil && content != "" {
-			LogInfo(fmt.Sprintf("Found PR template in %s/%s at %s", owner, repo, path))
+			LogInfoCtx(ctx, fmt.Sprintf("Found PR template in %s/%s at %s", owner, repo, path), nil)
 			return content, nil
 		}
 		// Continue to next location if not found
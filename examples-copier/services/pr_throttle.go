@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// prThrottlePollInterval is how often held PR batches are checked for budget to open them
+const prThrottlePollInterval = time.Minute
+
+// ErrPRThrottled is wrapped by the results of uploads held because their destination repo opened as
+// many PRs as its limits allow
+var ErrPRThrottled = errors.New("destination repo reached its PR limit")
+
+// tokenBucket allows up to capacity events at once, and refills at capacity events per period
+type tokenBucket struct {
+	capacity float64
+	period   time.Duration
+	tokens   float64
+	updated  time.Time
+}
+
+func newTokenBucket(capacity int, period time.Duration, now time.Time) *tokenBucket {
+	return &tokenBucket{capacity: float64(capacity), period: period, tokens: float64(capacity), updated: now}
+}
+
+// refill adds the tokens earned since the bucket was last updated
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = min(b.capacity, b.tokens+b.capacity*elapsed.Seconds()/b.period.Seconds())
+		b.updated = now
+	}
+}
+
+// nextToken returns when the bucket has a whole token, which is now if it already has one
+func (b *tokenBucket) nextToken(now time.Time) time.Time {
+	b.refill(now)
+	if b.tokens >= 1 {
+		return now
+	}
+	return now.Add(time.Duration((1 - b.tokens) / b.capacity * float64(b.period)))
+}
+
+// prBatch is the PR uploads held for one destination branch, merged to be opened as a single PR
+type prBatch struct {
+	content   types.UploadFileContent
+	change    CopyEvent // the latest change whose upload was held
+	uploads   int       // held uploads merged into the batch
+	heldSince time.Time
+	gated     []*workflowRun // workflows with an approval gate whose uploads are in the batch
+}
+
+// PRThrottle limits how many PRs the copier opens in each destination repo per hour and per day, so a
+// misconfigured workflow that suddenly matches a whole repo can't open dozens of PRs before anyone notices.
+// Each repo has a token bucket for each limit, kept in a budget store that instances may share. PR uploads
+// past either limit are held, and every upload held for the same destination branch is merged into one
+// batch, opened as a single PR once the repo has budget. Batched PRs that fail with transient errors are
+// queued for retry, and batched PRs for workflows with an approval gate are held until they're approved.
+// Batches are kept in memory, so batches still held at shutdown are opened then, past the limits, rather
+// than lost.
+type PRThrottle struct {
+	perHour      int
+	perDay       int
+	budget       PRBudgetStore
+	metrics      *MetricsCollector
+	writeLog     *WriteLog
+	retryQueue   *RetryQueue
+	approvalGate *ApprovalGate
+	upload       func(ctx context.Context, key types.UploadKey, content types.UploadFileContent) UploadResult
+	now          func() time.Time
+
+	mu      sync.Mutex
+	batches map[types.UploadKey]*prBatch // Held uploads, by destination repo and branch
+}
+
+// NewPRThrottle creates a PR throttle with the limits in config, drawing from the repos' budgets in budget,
+// that opens batches with uploadToTarget. Batches it opens are recorded in writeLog, which may be nil.
+// Batches that fail are queued in retryQueue, and batched PRs awaiting approval are held by approvalGate;
+// either may be nil.
+func NewPRThrottle(config *configs.Config, budget PRBudgetStore, metrics *MetricsCollector, prTemplateFetcher PRTemplateFetcher,
+	writeLog *WriteLog, retryQueue *RetryQueue, approvalGate *ApprovalGate) *PRThrottle {
+
+	return &PRThrottle{
+		perHour:      max(config.PRLimitPerHour, 0),
+		perDay:       max(config.PRLimitPerDay, 0),
+		budget:       budget,
+		metrics:      metrics,
+		writeLog:     writeLog,
+		retryQueue:   retryQueue,
+		approvalGate: approvalGate,
+		upload: func(ctx context.Context, key types.UploadKey, content types.UploadFileContent) UploadResult {
+			return uploadToTarget(ctx, key, content, prTemplateFetcher)
+		},
+		now:     time.Now,
+		batches: make(map[types.UploadKey]*prBatch),
+	}
+}
+
+// Enabled returns true if PRs are limited
+func (t *PRThrottle) Enabled() bool {
+	return t != nil && (t.perHour > 0 || t.perDay > 0)
+}
+
+// Admit takes budget for the queued uploads that open PRs and returns the uploads to make now, and the
+// keys of those held for a batched PR. An upload admitted for a destination branch with a held batch
+// opens one PR with the batch's files too. Uploads that don't open PRs are always admitted. runs are the
+// workflows that queued the uploads, so a batched PR can be held for their approval gates.
+func (t *PRThrottle) Admit(ctx context.Context, change CopyEvent, runs []*workflowRun,
+	queued map[types.UploadKey]types.UploadFileContent) (map[types.UploadKey]types.UploadFileContent, map[types.UploadKey]bool) {
+
+	if !t.Enabled() {
+		return queued, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	admitted := make(map[types.UploadKey]types.UploadFileContent, len(queued))
+	held := make(map[types.UploadKey]bool)
+	now := t.now()
+	for _, key := range sortedUploadKeys(queued) {
+		content := queued[key]
+		if !opensPR(content) {
+			admitted[key] = content
+			continue
+		}
+
+		batchKey := prBatchKey(key)
+		taken, next := t.take(ctx, key.RepoName, now)
+		if taken {
+			if batch := t.batches[batchKey]; batch != nil {
+				content = mergeUploads(batch.content, content)
+				content.PRBody = appendBatchNote(content.PRBody, batch.uploads+1)
+				delete(t.batches, batchKey)
+			}
+			admitted[key] = content
+			continue
+		}
+
+		batch := t.batches[batchKey]
+		if batch == nil {
+			batch = &prBatch{content: content, heldSince: now}
+			t.batches[batchKey] = batch
+		} else {
+			batch.content = mergeUploads(batch.content, content)
+		}
+		batch.change = change
+		batch.uploads++
+		batch.addGated(key, runs)
+		held[key] = true
+		LogWarningCtx(ctx, "PR limit reached; upload held for a batched PR", map[string]interface{}{
+			"target_repo":    key.RepoName,
+			"target_branch":  key.BranchPath,
+			"held_uploads":   batch.uploads,
+			"file_count":     len(batch.content.Content),
+			"per_hour_limit": t.perHour,
+			"per_day_limit":  t.perDay,
+			"next_pr_at":     next,
+		})
+	}
+	t.updateSize()
+	return admitted, held
+}
+
+// ProcessDue opens a PR for each held batch whose destination repo has budget again. Returns the number
+// of batches opened.
+func (t *PRThrottle) ProcessDue(ctx context.Context) int {
+	if !t.Enabled() {
+		return 0
+	}
+	t.mu.Lock()
+	var due []types.UploadKey
+	batches := make(map[types.UploadKey]*prBatch)
+	now := t.now()
+	for _, key := range sortedBatchKeys(t.batches) {
+		if ctx.Err() != nil {
+			break
+		}
+		if taken, _ := t.take(ctx, key.RepoName, now); !taken {
+			continue
+		}
+		due = append(due, key)
+		batches[key] = t.batches[key]
+		delete(t.batches, key)
+	}
+	t.updateSize()
+	t.mu.Unlock()
+
+	for _, key := range due {
+		t.open(ctx, key, batches[key])
+	}
+	return len(due)
+}
+
+// Run opens held batches every prThrottlePollInterval until ctx is cancelled
+func (t *PRThrottle) Run(ctx context.Context) {
+	if !t.Enabled() {
+		return
+	}
+	ticker := time.NewTicker(prThrottlePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.ProcessDue(ctx)
+		}
+	}
+}
+
+// Close opens the batches still held, past the limits, so their files aren't lost at shutdown
+func (t *PRThrottle) Close(ctx context.Context) {
+	if !t.Enabled() {
+		return
+	}
+	t.mu.Lock()
+	batches := t.batches
+	t.batches = make(map[types.UploadKey]*prBatch)
+	t.updateSize()
+	t.mu.Unlock()
+
+	for _, key := range sortedBatchKeys(batches) {
+		LogWarningCtx(ctx, "opening batched PR past the PR limit at shutdown", map[string]interface{}{
+			"target_repo":   key.RepoName,
+			"target_branch": key.BranchPath,
+			"held_uploads":  batches[key].uploads,
+		})
+		t.open(ctx, key, batches[key])
+	}
+}
+
+// open opens the PR for a held batch. A PR that fails with a transient error is queued for retry, and a
+// PR awaiting approval is held for the approval gates of the batch's workflows.
+func (t *PRThrottle) open(ctx context.Context, key types.UploadKey, batch *prBatch) {
+	batchCtx := WithCorrelationID(ctx, batch.change.CorrelationID)
+	content := batch.content
+	content.PRBody = appendBatchNote(content.PRBody, batch.uploads)
+	result := t.upload(batchCtx, key, content)
+	fields := map[string]interface{}{
+		"target_repo":   key.RepoName,
+		"target_branch": key.BranchPath,
+		"held_uploads":  batch.uploads,
+		"file_count":    len(content.Content),
+		"held_since":    batch.heldSince,
+	}
+	if result.Err != nil {
+		queued := map[types.UploadKey]types.UploadFileContent{key: content}
+		if t.retryQueue.EnqueueFailed(batchCtx, batch.change, queued, map[types.UploadKey]UploadResult{key: result})[key] {
+			return
+		}
+		LogErrorCtx(batchCtx, "failed to open batched PR", result.Err, fields)
+		if t.metrics != nil {
+			for range content.Content {
+				t.metrics.RecordFileUploadFailed()
+			}
+		}
+		return
+	}
+	fields["pr_url"] = result.PRURL
+	LogInfoCtx(batchCtx, "opened batched PR", fields)
+	t.writeLog.Record(batchCtx, batch.change, nil, key, content, result)
+
+	uploads := make(map[types.UploadKey]UploadResult, len(batch.gated))
+	for _, run := range batch.gated {
+		uploads[run.uploadKey()] = result
+	}
+	holdForApproval(batchCtx, t.approvalGate, batch.change, batch.gated, uploads)
+}
+
+// take takes a token from each of the repo's buckets if they all have one, and returns when the repo can
+// open its next PR. If the budget can't be read, nothing is taken, so uploads are held until it can.
+func (t *PRThrottle) take(ctx context.Context, repo string, now time.Time) (bool, time.Time) {
+	var limits []prLimit
+	if t.perHour > 0 {
+		limits = append(limits, prLimit{capacity: t.perHour, period: time.Hour})
+	}
+	if t.perDay > 0 {
+		limits = append(limits, prLimit{capacity: t.perDay, period: 24 * time.Hour})
+	}
+	taken, next, err := t.budget.Take(ctx, repo, limits, now)
+	if err != nil {
+		LogErrorCtx(ctx, "failed to take from the PR limit budget", err, map[string]interface{}{"target_repo": repo})
+		return false, now.Add(prThrottlePollInterval)
+	}
+	return taken, next
+}
+
+// updateSize records the number of held batches in the metrics collector. t.mu must be held.
+func (t *PRThrottle) updateSize() {
+	if t.metrics != nil {
+		t.metrics.SetHeldPRBatches(len(t.batches))
+	}
+}
+
+// addGated adds the runs with an approval gate that queued the upload under key, replacing an earlier run of
+// the same workflow
+func (b *prBatch) addGated(key types.UploadKey, runs []*workflowRun) {
+	for _, run := range runs {
+		if run.uploadKey() != key || run.DryRun != nil || getApprovalGate(run.Workflow) == nil {
+			continue
+		}
+		b.gated = slices.DeleteFunc(b.gated, func(gated *workflowRun) bool { return gated.Workflow.Name == run.Workflow.Name })
+		b.gated = append(b.gated, run)
+	}
+}
+
+// opensPR returns true if an upload opens a PR: the pull_request strategy, but not direct commits or the
+// branch strategy
+func opensPR(content types.UploadFileContent) bool {
+	strategy := content.CommitStrategy
+	return strategy != "" && strategy != types.CommitStrategyDirect && strategy != types.CommitStrategyBranch
+}
+
+// prBatchKey returns the key of the batch an upload is held in: uploads from every workflow writing to the
+// same destination branch share one
+func prBatchKey(key types.UploadKey) types.UploadKey {
+	return types.UploadKey{RepoName: key.RepoName, BranchPath: key.BranchPath, CommitStrategy: key.CommitStrategy}
+}
+
+// sortedBatchKeys returns the keys of the batches, sorted by repo and branch
+func sortedBatchKeys(batches map[types.UploadKey]*prBatch) []types.UploadKey {
+	keys := make([]types.UploadKey, 0, len(batches))
+	for key := range batches {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].RepoName != keys[j].RepoName {
+			return keys[i].RepoName < keys[j].RepoName
+		}
+		return keys[i].BranchPath < keys[j].BranchPath
+	})
+	return keys
+}
+
+// mergeUploads merges a later upload to the same destination branch into an earlier one. Files written or
+// deleted later replace the earlier change to the same path, and the later upload's messages and PR settings
+// are used.
+func mergeUploads(earlier, later types.UploadFileContent) types.UploadFileContent {
+	merged := later
+	changed := make(map[string]bool, len(later.Content)+len(later.DeletePaths))
+	for _, file := range later.Content {
+		changed[file.GetName()] = true
+	}
+	for _, path := range later.DeletePaths {
+		changed[path] = true
+	}
+
+	merged.Content = nil
+	for _, file := range earlier.Content {
+		if !changed[file.GetName()] {
+			merged.Content = append(merged.Content, file)
+		}
+	}
+	merged.Content = append(merged.Content, later.Content...)
+
+	merged.DeletePaths = nil
+	for _, path := range earlier.DeletePaths {
+		if !changed[path] {
+			merged.DeletePaths = append(merged.DeletePaths, path)
+		}
+	}
+	merged.DeletePaths = append(merged.DeletePaths, later.DeletePaths...)
+
+	merged.FileModes = nil
+	for _, modes := range []map[string]string{earlier.FileModes, later.FileModes} {
+		for path, mode := range modes {
+			if merged.FileModes == nil {
+				merged.FileModes = make(map[string]string)
+			}
+			merged.FileModes[path] = mode
+		}
+	}
+	for path := range changed {
+		if _, ok := later.FileModes[path]; !ok {
+			delete(merged.FileModes, path)
+		}
+	}
+	return merged
+}
+
+// appendBatchNote notes in a PR body that the PR batches uploads held by the PR limits
+func appendBatchNote(body string, uploads int) string {
+	if uploads < 2 {
+		return body
+	}
+	note := fmt.Sprintf("_This PR batches %d updates held because the repo reached the copier's PR limit._", uploads)
+	if strings.TrimSpace(body) == "" {
+		return note
+	}
+	return body + "\n\n" + note
+}
+
+// heldUploadResult is the upload result of an upload held for a batched PR
+func heldUploadResult() UploadResult {
+	return UploadResult{Err: fmt.Errorf("%w; held for a batched PR", ErrPRThrottled)}
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPRThrottle returns a throttle with a fake clock and an upload function that records the uploads
+// it's given
+func newTestPRThrottle(perHour, perDay int) (*PRThrottle, *time.Time, *[]types.UploadFileContent) {
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	var uploads []types.UploadFileContent
+	config := &configs.Config{PRLimitPerHour: perHour, PRLimitPerDay: perDay}
+	t := NewPRThrottle(config, NewMemoryPRBudgetStore(), NewMetricsCollector(), nil, nil, nil, nil)
+	t.now = func() time.Time { return clock }
+	t.upload = func(ctx context.Context, key types.UploadKey, content types.UploadFileContent) UploadResult {
+		uploads = append(uploads, content)
+		return UploadResult{PRURL: "https://github.com/org/dest/pull/1"}
+	}
+	return t, &clock, &uploads
+}
+
+func prUpload(rule string, files ...string) (types.UploadKey, types.UploadFileContent) {
+	key := types.UploadKey{RepoName: "org/dest", BranchPath: "main", RuleName: rule, CommitStrategy: "pull_request"}
+	content := types.UploadFileContent{CommitStrategy: types.CommitStrategyPR, PRBody: "Copied from " + rule}
+	for _, file := range files {
+		content.Content = append(content.Content, github.RepositoryContent{Name: github.String(file)})
+	}
+	return key, content
+}
+
+func fileNames(content types.UploadFileContent) []string {
+	var names []string
+	for _, file := range content.Content {
+		names = append(names, file.GetName())
+	}
+	return names
+}
+
+func TestPRThrottle_DisabledAdmitsEverything(t *testing.T) {
+	throttle, _, _ := newTestPRThrottle(0, 0)
+	key, content := prUpload("a", "a.py")
+	queued := map[types.UploadKey]types.UploadFileContent{key: content}
+
+	admitted, held := throttle.Admit(context.Background(), CopyEvent{}, nil, queued)
+	assert.Equal(t, queued, admitted)
+	assert.Empty(t, held)
+	assert.False(t, throttle.Enabled())
+}
+
+func TestPRThrottle_HoldsPRsPastTheLimit(t *testing.T) {
+	throttle, _, _ := newTestPRThrottle(1, 0)
+	ctx := context.Background()
+
+	key1, content1 := prUpload("a", "a.py")
+	admitted, held := throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key1: content1})
+	assert.Contains(t, admitted, key1)
+	assert.Empty(t, held)
+
+	key2, content2 := prUpload("b", "b.py")
+	admitted, held = throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key2: content2})
+	assert.Empty(t, admitted)
+	assert.True(t, held[key2])
+	assert.Equal(t, 1, throttle.metrics.heldPRBatches)
+}
+
+func TestPRThrottle_InstancesSharingBudget(t *testing.T) {
+	first, _, _ := newTestPRThrottle(1, 0)
+	second, _, _ := newTestPRThrottle(1, 0)
+	second.budget = first.budget
+	ctx := context.Background()
+
+	key1, content1 := prUpload("a", "a.py")
+	admitted, _ := first.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key1: content1})
+	assert.Contains(t, admitted, key1)
+
+	// The other instance has spent the repo's budget
+	key2, content2 := prUpload("b", "b.py")
+	admitted, held := second.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key2: content2})
+	assert.Empty(t, admitted)
+	assert.True(t, held[key2])
+}
+
+func TestPRThrottle_DirectCommitsAreNotLimited(t *testing.T) {
+	throttle, _, _ := newTestPRThrottle(1, 0)
+	queued := make(map[types.UploadKey]types.UploadFileContent)
+	for _, repo := range []string{"org/one", "org/two"} {
+		key := types.UploadKey{RepoName: "org/dest", BranchPath: "main", RuleName: repo, CommitStrategy: "direct"}
+		queued[key] = types.UploadFileContent{CommitStrategy: types.CommitStrategyDirect}
+	}
+
+	admitted, held := throttle.Admit(context.Background(), CopyEvent{}, nil, queued)
+	assert.Len(t, admitted, 2)
+	assert.Empty(t, held)
+}
+
+func TestPRThrottle_BatchesHeldUploadsIntoOnePR(t *testing.T) {
+	throttle, clock, uploads := newTestPRThrottle(1, 0)
+	ctx := context.Background()
+
+	key1, content1 := prUpload("a", "a.py")
+	throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key1: content1})
+	key2, content2 := prUpload("b", "b.py", "shared.py")
+	throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key2: content2})
+	key3, content3 := prUpload("c", "shared.py", "c.py")
+	throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key3: content3})
+
+	// No budget yet
+	assert.Equal(t, 0, throttle.ProcessDue(ctx))
+
+	*clock = clock.Add(time.Hour)
+	assert.Equal(t, 1, throttle.ProcessDue(ctx))
+	require.Len(t, *uploads, 1)
+	assert.ElementsMatch(t, []string{"b.py", "shared.py", "c.py"}, fileNames((*uploads)[0]))
+	assert.Contains(t, (*uploads)[0].PRBody, "Copied from c")
+	assert.Contains(t, (*uploads)[0].PRBody, "batches 2 updates")
+	assert.Equal(t, 0, throttle.metrics.heldPRBatches)
+
+	// The batch used the budget
+	assert.Equal(t, 0, throttle.ProcessDue(ctx))
+}
+
+func TestPRThrottle_AdmittedUploadTakesTheHeldBatch(t *testing.T) {
+	throttle, clock, _ := newTestPRThrottle(1, 0)
+	ctx := context.Background()
+
+	key1, content1 := prUpload("a", "a.py")
+	throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key1: content1})
+	key2, content2 := prUpload("b", "b.py")
+	throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key2: content2})
+
+	*clock = clock.Add(time.Hour)
+	key3, content3 := prUpload("c", "c.py")
+	admitted, held := throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key3: content3})
+	assert.Empty(t, held)
+	require.Contains(t, admitted, key3)
+	assert.ElementsMatch(t, []string{"b.py", "c.py"}, fileNames(admitted[key3]))
+	assert.Equal(t, 0, throttle.ProcessDue(ctx))
+}
+
+func TestPRThrottle_DailyLimit(t *testing.T) {
+	throttle, clock, _ := newTestPRThrottle(10, 2)
+	ctx := context.Background()
+
+	for i, rule := range []string{"a", "b", "c"} {
+		key, content := prUpload(rule, rule+".py")
+		_, held := throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key: content})
+		assert.Equal(t, i == 2, held[key], rule)
+	}
+
+	// The hourly bucket has budget, but the daily one only earns a PR back every 12 hours
+	*clock = clock.Add(time.Hour)
+	assert.Equal(t, 0, throttle.ProcessDue(ctx))
+	*clock = clock.Add(11 * time.Hour)
+	assert.Equal(t, 1, throttle.ProcessDue(ctx))
+}
+
+func TestPRThrottle_LimitsArePerRepo(t *testing.T) {
+	throttle, _, _ := newTestPRThrottle(1, 0)
+	ctx := context.Background()
+
+	key1, content1 := prUpload("a", "a.py")
+	key2, content2 := prUpload("b", "b.py")
+	key2.RepoName = "org/other"
+	admitted, held := throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key1: content1, key2: content2})
+	assert.Len(t, admitted, 2)
+	assert.Empty(t, held)
+}
+
+func TestPRThrottle_FailedBatchIsQueuedForRetry(t *testing.T) {
+	throttle, clock, _ := newTestPRThrottle(1, 0)
+	retryQueue, _, _, _ := newTestRetryQueue(3)
+	throttle.retryQueue = retryQueue
+	throttle.upload = func(ctx context.Context, key types.UploadKey, content types.UploadFileContent) UploadResult {
+		return UploadResult{Err: githubError(http.StatusBadGateway)}
+	}
+	ctx := context.Background()
+
+	key1, content1 := prUpload("a", "a.py")
+	throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key1: content1})
+	key2, content2 := prUpload("b", "b.py")
+	throttle.Admit(ctx, CopyEvent{Repo: "org/src", Number: 42}, nil, map[types.UploadKey]types.UploadFileContent{key2: content2})
+
+	*clock = clock.Add(time.Hour)
+	assert.Equal(t, 1, throttle.ProcessDue(ctx))
+
+	jobs, err := retryQueue.store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, prBatchKey(key2), jobs[0].Key)
+	assert.Equal(t, []string{"b.py"}, fileNames(jobs[0].Content))
+	assert.Equal(t, 42, jobs[0].PRNumber)
+}
+
+func TestPRThrottle_HoldsBatchedPRForApproval(t *testing.T) {
+	throttle, clock, _ := newTestPRThrottle(1, 0)
+	gate := NewApprovalGate(NewMemoryApprovalStore(), &configs.Config{}, nil)
+	throttle.approvalGate = gate
+	throttle.upload = func(ctx context.Context, key types.UploadKey, content types.UploadFileContent) UploadResult {
+		return UploadResult{PRURL: "https://github.com/org/dest/pull/7", PRNumber: 7, AwaitingApproval: true}
+	}
+	ctx := context.Background()
+
+	run := &workflowRun{Workflow: types.Workflow{
+		Name:        "gated",
+		Destination: types.Destination{Repo: "org/dest", Branch: "main"},
+		CommitStrategy: &types.CommitStrategyConfig{
+			Type: "pull_request", AutoMerge: true, ApprovalGate: &types.ApprovalGateConfig{Reviewers: []string{"octocat"}},
+		},
+	}}
+	key := run.uploadKey()
+	content := types.UploadFileContent{
+		CommitStrategy: types.CommitStrategyPR,
+		Content:        []github.RepositoryContent{{Name: github.String("a.py")}},
+	}
+	throttle.Admit(ctx, CopyEvent{}, []*workflowRun{run}, map[types.UploadKey]types.UploadFileContent{key: content})
+	_, held := throttle.Admit(ctx, CopyEvent{}, []*workflowRun{run}, map[types.UploadKey]types.UploadFileContent{key: content})
+	require.True(t, held[key])
+
+	*clock = clock.Add(time.Hour)
+	assert.Equal(t, 1, throttle.ProcessDue(ctx))
+	pending := pendingApprovals(t, gate)
+	require.Len(t, pending, 1)
+	assert.Equal(t, 7, pending[0].TargetPRNumber)
+	assert.Equal(t, "gated", pending[0].WorkflowName)
+}
+
+func TestPRThrottle_CloseOpensHeldBatches(t *testing.T) {
+	throttle, _, uploads := newTestPRThrottle(1, 0)
+	ctx := context.Background()
+
+	key1, content1 := prUpload("a", "a.py")
+	throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key1: content1})
+	key2, content2 := prUpload("b", "b.py")
+	throttle.Admit(ctx, CopyEvent{}, nil, map[types.UploadKey]types.UploadFileContent{key2: content2})
+	require.Empty(t, *uploads)
+
+	// The repo has no budget, but the batch is opened rather than lost
+	throttle.Close(ctx)
+	require.Len(t, *uploads, 1)
+	assert.Equal(t, []string{"b.py"}, fileNames((*uploads)[0]))
+	assert.Equal(t, 0, throttle.metrics.heldPRBatches)
+}
+
+func TestMergeUploads(t *testing.T) {
+	earlier := types.UploadFileContent{
+		Content:       []github.RepositoryContent{{Name: github.String("a.py")}, {Name: github.String("b.py")}},
+		DeletePaths:   []string{"old.py", "c.py"},
+		FileModes:     map[string]string{"a.py": "100755", "b.py": "100755"},
+		CommitMessage: "earlier",
+	}
+	later := types.UploadFileContent{
+		Content:       []github.RepositoryContent{{Name: github.String("c.py")}},
+		DeletePaths:   []string{"b.py"},
+		CommitMessage: "later",
+	}
+
+	merged := mergeUploads(earlier, later)
+	assert.Equal(t, []string{"a.py", "c.py"}, fileNames(merged))
+	assert.Equal(t, []string{"old.py", "b.py"}, merged.DeletePaths)
+	assert.Equal(t, map[string]string{"a.py": "100755"}, merged.FileModes)
+	assert.Equal(t, "later", merged.CommitMessage)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// prometheusContentType is the content type of the Prometheus text exposition format
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Histogram bucket upper bounds, in seconds
+var (
+	webhookDurationBuckets   = []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+	githubAPIDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+)
+
+// histogram is a cumulative histogram in the shape Prometheus expects. It isn't safe for
+// concurrent use; the MetricsCollector guards it with its mutex.
+type histogram struct {
+	bounds []float64
+	counts []uint64 // Observations in each bucket; the last bucket is +Inf
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(value float64) {
+	i := sort.SearchFloat64s(h.bounds, value)
+	h.counts[i]++
+	h.sum += value
+	h.count++
+}
+
+// snapshot returns a copy of the histogram that can be read without holding the lock
+func (h *histogram) snapshot() histogram {
+	return histogram{
+		bounds: h.bounds,
+		counts: append([]uint64(nil), h.counts...),
+		sum:    h.sum,
+		count:  h.count,
+	}
+}
+
+// wantsPrometheus reports whether a /metrics request asked for the Prometheus text format
+// rather than JSON. ?format= takes precedence over the Accept header.
+func wantsPrometheus(r *http.Request) bool {
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case "prometheus":
+		return true
+	case "json":
+		return false
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/openmetrics-text") || strings.Contains(accept, "text/plain")
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (mc *MetricsCollector) WritePrometheus(w io.Writer, fileQueues FileQueues) error {
+	data := mc.GetMetrics(fileQueues)
+
+	mc.mu.RLock()
+	workflowsMatched := copyCounts(mc.workflowsMatched)
+	filesCopied := copyCounts(mc.filesCopiedByWorkflow)
+	prCreationFailed := mc.prCreationFailed
+	webhookDuration := mc.webhookDuration.snapshot()
+	githubAPIDuration := mc.githubAPIDuration.snapshot()
+	mc.mu.RUnlock()
+
+	p := &promWriter{w: w}
+
+	p.counter("copier_webhooks_received_total", "Webhooks received.", float64(data.Webhooks.Received))
+	p.counter("copier_webhooks_processed_total", "Merged pull request webhooks processed.", float64(data.Webhooks.Processed))
+	p.counter("copier_webhooks_failed_total", "Webhooks that failed processing.", float64(data.Webhooks.Failed))
+	p.counter("copier_webhooks_ignored_total", "Webhooks ignored because they weren't for a merged pull request.", float64(data.Webhooks.Ignored))
+	p.counter("copier_webhooks_deferred_total", "Merged pull requests queued during maintenance.", float64(data.Webhooks.Deferred))
+	p.histogram("copier_webhook_processing_seconds", "Time to process a merged pull request webhook.", webhookDuration)
+
+	p.labeledCounter("copier_workflows_matched_total", "Merged pull requests each workflow matched.", "workflow", workflowsMatched)
+	p.labeledCounter("copier_files_copied_total", "Files each workflow queued for copy.", "workflow", filesCopied)
+
+	p.counter("copier_files_matched_total", "Changed files matched by a workflow.", float64(data.Files.Matched))
+	p.counter("copier_files_uploaded_total", "Files uploaded to target repositories.", float64(data.Files.Uploaded))
+	p.counter("copier_files_upload_failed_total", "File uploads to target repositories that failed.", float64(data.Files.UploadFailed))
+	p.counter("copier_files_upload_dead_lettered_total", "Failed uploads that ran out of retries.", float64(data.Files.UploadDeadLettered))
+	p.counter("copier_files_deprecated_total", "Files recorded as deprecated.", float64(data.Files.Deprecated))
+	p.counter("copier_files_blocked_by_secret_scan_total", "Files not copied because the secret scan flagged them.", float64(data.Files.BlockedBySecretScan))
+	p.counter("copier_files_blocked_by_schema_validation_total", "Files not copied because they failed schema validation.", float64(data.Files.BlockedBySchemaValidation))
+	p.counter("copier_pr_creation_failures_total", "Pull requests that couldn't be created in a target repository.", float64(prCreationFailed))
+
+	p.counter("copier_github_api_requests_total", "GitHub REST API requests.", float64(data.GitHubAPI.Calls))
+	p.counter("copier_github_api_errors_total", "GitHub REST API requests that failed or returned an error status.", float64(data.GitHubAPI.Errors))
+	p.histogram("copier_github_api_request_duration_seconds", "GitHub REST API request latency.", githubAPIDuration)
+	if data.GitHubAPI.RateLimit.Remaining >= 0 {
+		p.gauge("copier_github_rate_limit_remaining", "Requests left in the current GitHub rate limit window.", float64(data.GitHubAPI.RateLimit.Remaining))
+		p.gauge("copier_github_rate_limit_reset_timestamp_seconds", "When the GitHub rate limit window resets, as a Unix timestamp.", float64(data.GitHubAPI.RateLimit.ResetAt.Unix()))
+	}
+	p.counter("copier_github_rate_limit_pauses_total", "Times an installation's GitHub requests paused for a rate limit.", float64(data.GitHubAPI.RateLimit.Pauses))
+	paused := 0.0
+	if data.GitHubAPI.RateLimit.PausedUntil.After(time.Now()) {
+		paused = 1
+	}
+	p.gauge("copier_github_rate_limit_paused", "Whether GitHub requests are paused for a rate limit.", paused)
+
+	p.gauge("copier_upload_queue_size", "Files waiting to be uploaded.", float64(data.Queues.UploadQueueSize))
+	p.gauge("copier_deprecation_queue_size", "Files waiting to be recorded as deprecated.", float64(data.Queues.DeprecationQueueSize))
+	p.gauge("copier_retry_queue_size", "Uploads waiting in the retry queue.", float64(data.Queues.RetryQueueSize))
+	p.gauge("copier_held_pr_batches", "Batched pull requests held by the destination repos' PR limits.", float64(data.Queues.HeldPRBatches))
+	p.gauge("copier_running_changes", "Merged changes being processed.", float64(data.Queues.RunningChanges))
+	p.gauge("copier_scheduled_changes", "Merged changes waiting for a worker.", float64(data.Queues.ScheduledChanges))
+
+	p.gauge("copier_uptime_seconds", "Seconds since the service started.", float64(data.System.UptimeSeconds))
+
+	return p.err
+}
+
+func copyCounts(counts map[string]int64) map[string]int64 {
+	copied := make(map[string]int64, len(counts))
+	for k, v := range counts {
+		copied[k] = v
+	}
+	return copied
+}
+
+// promWriter writes metric families in the Prometheus text format, keeping the first write error
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *promWriter) printf(format string, args ...any) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+func (p *promWriter) header(name, help, kind string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (p *promWriter) counter(name, help string, value float64) {
+	p.header(name, help, "counter")
+	p.printf("%s %s\n", name, formatPromValue(value))
+}
+
+func (p *promWriter) gauge(name, help string, value float64) {
+	p.header(name, help, "gauge")
+	p.printf("%s %s\n", name, formatPromValue(value))
+}
+
+// labeledCounter writes one sample per label value, sorted so the output is stable
+func (p *promWriter) labeledCounter(name, help, label string, values map[string]int64) {
+	p.header(name, help, "counter")
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p.printf("%s{%s=\"%s\"} %d\n", name, label, escapePromLabel(k), values[k])
+	}
+}
+
+func (p *promWriter) histogram(name, help string, h histogram) {
+	p.header(name, help, "histogram")
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		p.printf("%s_bucket{le=\"%s\"} %d\n", name, formatPromValue(bound), cumulative)
+	}
+	p.printf("%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	p.printf("%s_sum %s\n", name, formatPromValue(h.sum))
+	p.printf("%s_count %d\n", name, h.count)
+}
+
+func formatPromValue(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapePromLabel(value string) string {
+	return promLabelEscaper.Replace(value)
+}
+
+// githubAPIMetrics is the collector that GitHub REST API requests are recorded in. The service
+// container sets it, since the REST clients are created outside the container.
+var githubAPIMetrics atomic.Pointer[MetricsCollector]
+
+// SetGitHubAPIMetrics sets the collector that GitHub REST API requests are recorded in
+func SetGitHubAPIMetrics(mc *MetricsCollector) {
+	githubAPIMetrics.Store(mc)
+}
+
+// metricsTransport records the latency, outcome, and reported rate limit of each GitHub REST API request
+type metricsTransport struct {
+	base http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	mc := githubAPIMetrics.Load()
+	if mc == nil {
+		return resp, err
+	}
+
+	failed := err != nil
+	remaining := -1
+	var reset time.Time
+	if resp != nil {
+		failed = failed || resp.StatusCode >= http.StatusBadRequest
+		if value, parseErr := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); parseErr == nil {
+			remaining = value
+			if epoch, parseErr := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); parseErr == nil {
+				reset = time.Unix(epoch, 0)
+			}
+		}
+	}
+	mc.RecordGitHubAPIRequest(time.Since(start), failed, remaining, reset)
+	return resp, err
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsHandler_Prometheus(t *testing.T) {
+	collector := NewMetricsCollector()
+	collector.RecordWebhookReceived()
+	collector.RecordWebhookProcessed(3 * time.Second)
+	collector.RecordWorkflowMatched("go-examples")
+	collector.RecordWorkflowMatched("go-examples")
+	collector.RecordWorkflowMatched(`quoted "name"`)
+	collector.RecordWorkflowFileCopied("go-examples")
+	collector.RecordFileUploadFailed()
+	collector.RecordPRCreationFailed()
+
+	handler := MetricsHandler(collector, NewFileStateService())
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "text/plain;version=0.0.4;q=1,*/*;q=0.1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, prometheusContentType, w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	for _, line := range []string{
+		"# TYPE copier_webhooks_received_total counter",
+		"copier_webhooks_received_total 1",
+		`copier_workflows_matched_total{workflow="go-examples"} 2`,
+		`copier_workflows_matched_total{workflow="quoted \"name\""} 1`,
+		`copier_files_copied_total{workflow="go-examples"} 1`,
+		"copier_files_upload_failed_total 1",
+		"copier_pr_creation_failures_total 1",
+		`copier_webhook_processing_seconds_bucket{le="2.5"} 0`,
+		`copier_webhook_processing_seconds_bucket{le="5"} 1`,
+		`copier_webhook_processing_seconds_bucket{le="+Inf"} 1`,
+		"copier_webhook_processing_seconds_sum 3",
+		"copier_webhook_processing_seconds_count 1",
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+	// No rate limit has been reported yet
+	assert.NotContains(t, body, "copier_github_rate_limit_remaining")
+}
+
+func TestMetricsHandler_FormatNegotiation(t *testing.T) {
+	handler := MetricsHandler(NewMetricsCollector(), NewFileStateService())
+
+	tests := []struct {
+		name       string
+		url        string
+		accept     string
+		prometheus bool
+	}{
+		{"default", "/metrics", "", false},
+		{"browser", "/metrics", "text/html,application/xhtml+xml,*/*;q=0.8", false},
+		{"prometheus accept", "/metrics", "text/plain;version=0.0.4", true},
+		{"openmetrics accept", "/metrics", "application/openmetrics-text;version=1.0.0", true},
+		{"query", "/metrics?format=prometheus", "", true},
+		{"query overrides accept", "/metrics?format=json", "text/plain", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			assert.Equal(t, tt.prometheus, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"))
+		})
+	}
+}
+
+func TestMetricsTransport(t *testing.T) {
+	collector := NewMetricsCollector()
+	SetGitHubAPIMetrics(collector)
+	defer SetGitHubAPIMetrics(nil)
+
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &metricsTransport{base: http.DefaultTransport}}
+	for _, path := range []string{"/ok", "/missing"} {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	metrics := collector.GetMetrics(NewFileStateService())
+	assert.Equal(t, int64(2), metrics.GitHubAPI.Calls)
+	assert.Equal(t, int64(1), metrics.GitHubAPI.Errors)
+	assert.Equal(t, 4321, metrics.GitHubAPI.RateLimit.Remaining)
+	assert.True(t, reset.Equal(metrics.GitHubAPI.RateLimit.ResetAt))
+
+	var body strings.Builder
+	require.NoError(t, collector.WritePrometheus(&body, NewFileStateService()))
+	assert.Contains(t, body.String(), "copier_github_rate_limit_remaining 4321\n")
+	assert.Contains(t, body.String(), "copier_github_api_request_duration_seconds_count 2\n")
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
+	. "github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// commentSyntax is how a file type writes a one-line comment: a line comment has only a prefix, a block
+// comment has a prefix and a suffix
+type commentSyntax struct {
+	prefix string
+	suffix string
+}
+
+// parseCommentSyntax parses a comment_syntax value: a line comment prefix, or a block comment's start
+// and end separated by a space. An empty value means the file type has no comments.
+func parseCommentSyntax(value string) (commentSyntax, bool) {
+	fields := strings.Fields(value)
+	switch len(fields) {
+	case 1:
+		return commentSyntax{prefix: fields[0]}, true
+	case 2:
+		return commentSyntax{prefix: fields[0], suffix: fields[1]}, true
+	}
+	return commentSyntax{}, false
+}
+
+// builtinCommentSyntax is the comment syntax of the file types code examples are usually written in, keyed
+// by lowercase extension or, for files without one, file name. File types that can't have comments, such
+// as JSON, aren't listed, so they're copied without a header.
+var builtinCommentSyntax = map[string]string{
+	// C-style line comments
+	".c": "//", ".h": "//", ".cc": "//", ".cpp": "//", ".hpp": "//", ".cs": "//", ".go": "//",
+	".java": "//", ".kt": "//", ".kts": "//", ".scala": "//", ".swift": "//", ".dart": "//",
+	".rs": "//", ".php": "//", ".js": "//", ".jsx": "//", ".mjs": "//", ".cjs": "//", ".ts": "//",
+	".tsx": "//", ".groovy": "//", ".gradle": "//", ".jsonc": "//", ".json5": "//", ".proto": "//",
+	".scss": "//", ".less": "//",
+	// Hash comments
+	".py": "#", ".rb": "#", ".sh": "#", ".bash": "#", ".zsh": "#", ".ps1": "#", ".pl": "#",
+	".r": "#", ".yaml": "#", ".yml": "#", ".toml": "#", ".tf": "#", ".hcl": "#", ".conf": "#",
+	".cfg": "#", ".ini": "#", ".properties": "#", ".env": "#", ".ex": "#", ".exs": "#",
+	"Dockerfile": "#", "Makefile": "#", "Gemfile": "#", "Rakefile": "#", ".gitignore": "#",
+	// Other line comments
+	".sql": "--", ".lua": "--", ".hs": "--", ".rst": "..",
+	// Block comments
+	".css": "/* */", ".md": "<!-- -->", ".markdown": "<!-- -->", ".mdx": "{/* */}", ".html": "<!-- -->",
+	".htm": "<!-- -->", ".xml": "<!-- -->", ".svg": "<!-- -->", ".vue": "<!-- -->",
+}
+
+// provenanceCommentSyntax returns the comment syntax the workflow uses for the file at filePath, and
+// false if the file gets no header. The workflow's comment_syntax takes precedence over the built-in
+// syntax, and a file name takes precedence over its extension.
+func provenanceCommentSyntax(cfg *ProvenanceHeaderConfig, filePath string) (commentSyntax, bool) {
+	name := path.Base(filePath)
+	ext := strings.ToLower(path.Ext(name))
+	for _, key := range []string{name, ext} {
+		if key == "" {
+			continue
+		}
+		if value, ok := cfg.CommentSyntax[key]; ok {
+			return parseCommentSyntax(value)
+		}
+	}
+	for _, key := range []string{name, ext} {
+		if value, ok := builtinCommentSyntax[key]; ok {
+			return parseCommentSyntax(value)
+		}
+	}
+	return commentSyntax{}, false
+}
+
+// provenanceHeaderPattern matches a header line written from template with any variable values, so a
+// header from an earlier copy is replaced rather than stacked on top of
+func provenanceHeaderPattern(template string, syntax commentSyntax) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString(`^[ \t]*` + regexp.QuoteMeta(syntax.prefix) + ` `)
+	variable := regexp.MustCompile(`\$\{[a-z_]+\}`)
+	last := 0
+	for _, loc := range variable.FindAllStringIndex(template, -1) {
+		expr.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		expr.WriteString(`.*`)
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(template[last:]))
+	if syntax.suffix != "" {
+		expr.WriteString(` ` + regexp.QuoteMeta(syntax.suffix))
+	}
+	expr.WriteString(`[ \t]*\r?$`)
+	return regexp.MustCompile(expr.String())
+}
+
+// prologueLine matches lines that must stay at the top of a file: a shebang, an XML declaration, and
+// Python's encoding declaration, which must be on the first or second line
+var prologueLine = regexp.MustCompile(`^(#!|<\?xml\s|[ \t\f]*#.*?coding[:=][ \t]*[-\w.]+)`)
+
+// frontMatterExts are the file types whose YAML front matter must stay at the top of the file
+var frontMatterExts = map[string]bool{".md": true, ".mdx": true, ".markdown": true}
+
+// addProvenanceHeader returns content with header, a comment line, at the top, after any prologue lines
+// and, with frontMatter set, any YAML front matter. If the content already starts with a header matching
+// existing, it's replaced. A blank line separates a new header from the content so it isn't taken for
+// the first declaration's doc comment.
+func addProvenanceHeader(content string, header string, existing *regexp.Regexp, frontMatter bool) string {
+	lines := strings.SplitAfter(content, "\n")
+	insertAt := 0
+	for insertAt < len(lines) && insertAt < 2 && prologueLine.MatchString(lines[insertAt]) {
+		insertAt++
+	}
+	if frontMatter && insertAt == 0 && len(lines) > 0 && strings.TrimRight(lines[0], "\r\n") == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimRight(lines[i], "\r\n") == "---" {
+				insertAt = i + 1
+				break
+			}
+		}
+	}
+	prologue := strings.Join(lines[:insertAt], "")
+	if prologue != "" && !strings.HasSuffix(prologue, "\n") {
+		prologue += "\n"
+	}
+
+	rest := lines[insertAt:]
+	if len(rest) > 0 && existing.MatchString(strings.TrimSuffix(rest[0], "\n")) {
+		return prologue + header + "\n" + strings.Join(rest[1:], "")
+	}
+	body := strings.Join(rest, "")
+	if body == "" {
+		return prologue + header + "\n"
+	}
+	return prologue + header + "\n\n" + body
+}
+
+// applyProvenanceHeader adds the workflow's provenance header to a copied file, in the comment syntax of
+// the file type at targetPath. Files without comment syntax and binary files are left as they are.
+func (wp *workflowProcessor) applyProvenanceHeader(
+	ctx context.Context,
+	workflow Workflow,
+	sourcePath string,
+	targetPath string,
+	fileContent *github.RepositoryContent,
+	sourceCommitSHA string,
+) error {
+	cfg := workflow.ProvenanceHeader
+	if !cfg.IsEnabled() {
+		return nil
+	}
+	syntax, ok := provenanceCommentSyntax(cfg, targetPath)
+	if !ok {
+		LogDebugCtx(ctx, "no comment syntax for file, copying without provenance header", map[string]interface{}{
+			"workflow_name": workflow.Name,
+			"target_path":   targetPath,
+		})
+		return nil
+	}
+
+	content, err := fileContent.GetContent()
+	if err != nil {
+		return fmt.Errorf("failed to decode file content for provenance header: %w", err)
+	}
+	if !isTextContent(content) {
+		return nil
+	}
+
+	template := cfg.GetTemplate()
+	text := template
+	for name, value := range map[string]string{
+		"source_repo": workflow.Source.Repo,
+		"source_path": sourcePath,
+		"commit_sha":  sourceCommitSHA,
+	} {
+		text = strings.ReplaceAll(text, "${"+name+"}", value)
+	}
+	header := syntax.prefix + " " + text
+	if syntax.suffix != "" {
+		header += " " + syntax.suffix
+	}
+
+	frontMatter := frontMatterExts[strings.ToLower(path.Ext(targetPath))]
+	withHeader := addProvenanceHeader(content, header, provenanceHeaderPattern(template, syntax), frontMatter)
+	if withHeader == content {
+		return nil
+	}
+	fileContent.Content = github.String(withHeader)
+	fileContent.Encoding = nil
+	fileContent.Size = github.Int(len(withHeader))
+	return nil
+}
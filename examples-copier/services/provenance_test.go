@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvenanceCommentSyntax(t *testing.T) {
+	cfg := &types.ProvenanceHeaderConfig{
+		Enabled:       true,
+		CommentSyntax: map[string]string{".py": ";", "Jenkinsfile": "//", ".yaml": ""},
+	}
+
+	tests := []struct {
+		path   string
+		syntax commentSyntax
+		ok     bool
+	}{
+		{path: "src/main.go", syntax: commentSyntax{prefix: "//"}, ok: true},
+		{path: "src/Main.JAVA", syntax: commentSyntax{prefix: "//"}, ok: true},
+		{path: "docs/README.md", syntax: commentSyntax{prefix: "<!--", suffix: "-->"}, ok: true},
+		{path: "docker/Dockerfile", syntax: commentSyntax{prefix: "#"}, ok: true},
+		{path: "ci/Jenkinsfile", syntax: commentSyntax{prefix: "//"}, ok: true},
+		{path: "app.py", syntax: commentSyntax{prefix: ";"}, ok: true}, // overridden
+		{path: "config.yaml"},  // turned off
+		{path: "package.json"}, // no comments
+		{path: "LICENSE"},      // unknown
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			syntax, ok := provenanceCommentSyntax(cfg, tt.path)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.syntax, syntax)
+		})
+	}
+}
+
+func TestAddProvenanceHeader(t *testing.T) {
+	template := types.DefaultProvenanceTemplate
+	hash := commentSyntax{prefix: "#"}
+	header := "# Generated from org/src/app.py @ def456; do not edit"
+
+	tests := []struct {
+		name        string
+		content     string
+		frontMatter bool
+		want        string
+	}{
+		{
+			name:    "top of file",
+			content: "print('hi')\n",
+			want:    header + "\n\nprint('hi')\n",
+		},
+		{
+			name:    "after shebang and encoding",
+			content: "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\nprint('hi')\n",
+			want:    "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\n" + header + "\n\nprint('hi')\n",
+		},
+		{
+			name:    "replaces an earlier header",
+			content: "# Generated from org/src/app.py @ abc123; do not edit\n\nprint('hi')\n",
+			want:    header + "\n\nprint('hi')\n",
+		},
+		{
+			name:    "empty file",
+			content: "",
+			want:    header + "\n",
+		},
+		{
+			name:        "after front matter",
+			content:     "---\ntitle: Connect\n---\n# Connect\n",
+			frontMatter: true,
+			want:        "---\ntitle: Connect\n---\n" + header + "\n\n# Connect\n",
+		},
+		{
+			name:    "front matter ignored for other file types",
+			content: "---\nkey: value\n",
+			want:    header + "\n\n---\nkey: value\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := addProvenanceHeader(tt.content, header, provenanceHeaderPattern(template, hash), tt.frontMatter)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	xml := commentSyntax{prefix: "<!--", suffix: "-->"}
+	xmlHeader := "<!-- Generated from org/src/pom.xml @ def456; do not edit -->"
+	got := addProvenanceHeader("<?xml version=\"1.0\"?>\n<project/>\n", xmlHeader, provenanceHeaderPattern(template, xml), false)
+	assert.Equal(t, "<?xml version=\"1.0\"?>\n"+xmlHeader+"\n\n<project/>\n", got)
+	assert.Equal(t, got, addProvenanceHeader(got, xmlHeader, provenanceHeaderPattern(template, xml), false))
+}
+
+func TestApplyProvenanceHeader(t *testing.T) {
+	wp := &workflowProcessor{}
+	workflow := types.Workflow{
+		Name:             "go",
+		Source:           types.Source{Repo: "org/src"},
+		ProvenanceHeader: &types.ProvenanceHeaderConfig{Enabled: true},
+	}
+	encoded := func(content string) *github.RepositoryContent {
+		return &github.RepositoryContent{
+			Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+			Encoding: github.String("base64"),
+		}
+	}
+
+	file := encoded("package main\n")
+	require.NoError(t, wp.applyProvenanceHeader(context.Background(), workflow, "code/main.go", "examples/main.go", file, "abc123"))
+	content, err := file.GetContent()
+	require.NoError(t, err)
+	assert.Equal(t, "// Generated from org/src/code/main.go @ abc123; do not edit\n\npackage main\n", content)
+
+	// JSON has no comments, so it's copied as-is
+	file = encoded("{}\n")
+	require.NoError(t, wp.applyProvenanceHeader(context.Background(), workflow, "code/a.json", "examples/a.json", file, "abc123"))
+	assert.Equal(t, "base64", file.GetEncoding())
+
+	// Without the setting, nothing changes
+	workflow.ProvenanceHeader = nil
+	file = encoded("package main\n")
+	require.NoError(t, wp.applyProvenanceHeader(context.Background(), workflow, "code/main.go", "examples/main.go", file, "abc123"))
+	assert.Equal(t, "base64", file.GetEncoding())
+}
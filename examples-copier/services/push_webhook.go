@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// pushEventSource converts pushes to GitHub branches for workflows with the push trigger. Tag pushes,
+// branch deletions, new branches (which have no earlier commit to compare against), and pushes of
+// commits the copier made don't trigger a copy.
+type pushEventSource struct{}
+
+func (pushEventSource) Name() string { return "push" }
+
+func (pushEventSource) Convert(ctx context.Context, payload []byte) (*CopyEvent, string, error) {
+	var evt github.PushEvent
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return nil, "", invalidPayload(err)
+	}
+	if missing := validatePushEvent(&evt); len(missing) > 0 {
+		return nil, "", missingFields("push", missing)
+	}
+
+	branch, isBranch := strings.CutPrefix(evt.GetRef(), "refs/heads/")
+	switch {
+	case !isBranch:
+		return nil, "not a branch", nil
+	case evt.GetDeleted():
+		return nil, "branch deleted", nil
+	case evt.GetCreated() || strings.Trim(evt.GetBefore(), "0") == "":
+		return nil, "branch created", nil
+	case isCopierPush(&evt):
+		return nil, "commits made by the copier", nil
+	}
+
+	change := &CopyEvent{
+		Platform:   types.SourcePlatformGitHub,
+		Repo:       evt.GetRepo().GetFullName(),
+		CommitSHA:  evt.GetAfter(),
+		BaseBranch: branch,
+		URL:        evt.GetCompare(),
+		Trigger:    types.WorkflowTriggerPush,
+		BeforeSHA:  evt.GetBefore(),
+		Author:     evt.GetSender().GetLogin(),
+	}
+
+	LogInfoCtx(ctx, "processing push", map[string]interface{}{
+		"sha":        change.CommitSHA,
+		"before_sha": change.BeforeSHA,
+		"repo":       change.Repo,
+		"branch":     change.BaseBranch,
+		"forced":     evt.GetForced(),
+	})
+	return change, "", nil
+}
+
+// skipPushedMerges drops workflows that also run on merged PRs when the push is the result of merging a
+// PR into the branch, since the pull_request event for the merge already runs them. If the pushed commit's
+// PRs can't be listed, the workflows are kept.
+func skipPushedMerges(ctx context.Context, change CopyEvent, workflows []types.Workflow) []types.Workflow {
+	var both []string
+	for _, workflow := range workflows {
+		if workflow.Trigger.Has(types.WorkflowTriggerPRMerged) {
+			both = append(both, workflow.Name)
+		}
+	}
+	if len(both) == 0 {
+		return workflows
+	}
+
+	merged, err := pushedCommitMergedPR(ctx, change)
+	if err != nil {
+		LogWarningCtx(ctx, "failed to check whether push is a merged PR; running all push-triggered workflows", map[string]interface{}{
+			"repo":  change.Repo,
+			"sha":   change.CommitSHA,
+			"error": err.Error(),
+		})
+		return workflows
+	}
+	if merged == 0 {
+		return workflows
+	}
+
+	LogInfoCtx(ctx, "push is a merged PR; skipping workflows that run on merged PRs", map[string]interface{}{
+		"repo":      change.Repo,
+		"sha":       change.CommitSHA,
+		"pr_number": merged,
+		"workflows": both,
+	})
+	var remaining []types.Workflow
+	for _, workflow := range workflows {
+		if !workflow.Trigger.Has(types.WorkflowTriggerPRMerged) {
+			remaining = append(remaining, workflow)
+		}
+	}
+	return remaining
+}
+
+// pushedCommitMergedPR returns the number of the PR merged into the pushed branch whose merge commit is
+// the pushed commit, or 0 if the push wasn't a PR merge
+func pushedCommitMergedPR(ctx context.Context, change CopyEvent) (int, error) {
+	owner, name, _ := strings.Cut(change.Repo, "/")
+	prs, _, err := GetRestClient().PullRequests.ListPullRequestsWithCommit(ctx, owner, name, change.CommitSHA, nil)
+	if err != nil {
+		return 0, err
+	}
+	for _, pr := range prs {
+		if pr.MergedAt != nil && pr.GetMergeCommitSHA() == change.CommitSHA && pr.GetBase().GetRef() == change.BaseBranch {
+			return pr.GetNumber(), nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postPushEvent(t *testing.T, evt *github.PushEvent) (*httptest.ResponseRecorder, *ServiceContainer) {
+	t.Helper()
+	config := &configs.Config{
+		ConfigRepoOwner: "test-owner",
+		ConfigRepoName:  "test-repo",
+		ConfigFile:      "nonexistent-config.yaml",
+	}
+	container, err := NewServiceContainer(config)
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(evt)
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
+	req.Header.Set("X-GitHub-Event", "push")
+	w := httptest.NewRecorder()
+	HandleWebhookWithContainer(w, req, config, container)
+	return w, container
+}
+
+func TestHandleWebhookWithContainer_Push(t *testing.T) {
+	repo := &github.PushEventRepository{FullName: github.String("test-owner/source")}
+
+	t.Run("ignores tags", func(t *testing.T) {
+		w, container := postPushEvent(t, &github.PushEvent{
+			Ref: github.String("refs/tags/v1.0.0"), Before: github.String("abc123"), After: github.String("def456"), Repo: repo,
+		})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, int64(1), container.MetricsCollector.webhookIgnored)
+	})
+
+	t.Run("ignores branch deletions", func(t *testing.T) {
+		w, _ := postPushEvent(t, &github.PushEvent{
+			Ref: github.String("refs/heads/old"), Deleted: github.Bool(true),
+		})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("ignores new branches", func(t *testing.T) {
+		w, _ := postPushEvent(t, &github.PushEvent{
+			Ref: github.String("refs/heads/new"), Created: github.Bool(true),
+			Before: github.String("0000000000000000000000000000000000000000"), After: github.String("def456"), Repo: repo,
+		})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("ignores copier commits", func(t *testing.T) {
+		w, container := postPushEvent(t, &github.PushEvent{
+			Ref: github.String("refs/heads/main"), Before: github.String("abc123"), After: github.String("def456"), Repo: repo,
+			Commits: []*github.HeadCommit{{Message: github.String("Update examples\n\n" + copierTrailer)}},
+		})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, int64(1), container.MetricsCollector.webhookIgnored)
+	})
+
+	t.Run("rejects missing fields", func(t *testing.T) {
+		w, container := postPushEvent(t, &github.PushEvent{Ref: github.String("refs/heads/main")})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var resp WebhookErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, webhookErrMissingFields, resp.Error)
+		assert.Equal(t, []string{"after", "repository.full_name"}, resp.MissingFields)
+		assert.Equal(t, int64(1), container.MetricsCollector.webhookFailed)
+	})
+
+	t.Run("accepts branch pushes", func(t *testing.T) {
+		SetInstallationTokenForOrg("", "test-token")
+		w, _ := postPushEvent(t, &github.PushEvent{
+			Ref: github.String("refs/heads/main"), Before: github.String("abc123"), After: github.String("def456"), Repo: repo,
+		})
+		assert.Equal(t, http.StatusAccepted, w.Code)
+	})
+}
+
+func TestMatchWorkflows_Trigger(t *testing.T) {
+	source := types.Source{Repo: "org/src", Branch: "main"}
+	workflows := []types.Workflow{
+		{Name: "default", Source: source},
+		{Name: "push", Source: source, Trigger: types.WorkflowTriggers{types.WorkflowTriggerPush}},
+		{Name: "both", Source: source, Trigger: types.WorkflowTriggers{types.WorkflowTriggerPRMerged, types.WorkflowTriggerPush}},
+		{Name: "other-branch", Source: types.Source{Repo: "org/src", Branch: "dev"}, Trigger: types.WorkflowTriggers{types.WorkflowTriggerPush}},
+	}
+	names := func(workflows []types.Workflow) []string {
+		var names []string
+		for _, workflow := range workflows {
+			names = append(names, workflow.Name)
+		}
+		return names
+	}
+
+	merged := CopyEvent{Platform: types.SourcePlatformGitHub, Repo: "org/src", Number: 1, BaseBranch: "main"}
+	assert.Equal(t, []string{"default", "both"}, names(matchWorkflows(workflows, merged)))
+
+	push := CopyEvent{Platform: types.SourcePlatformGitHub, Repo: "org/src", BaseBranch: "main", Trigger: types.WorkflowTriggerPush}
+	assert.Equal(t, []string{"push", "both"}, names(matchWorkflows(workflows, push)))
+	assert.Equal(t, "push to main", push.describe())
+}
+
+func TestMatchWorkflows_BranchPattern(t *testing.T) {
+	workflows := []types.Workflow{
+		{Name: "releases", Source: types.Source{Repo: "org/src", Branch: "release/*"}},
+		{Name: "main", Source: types.Source{Repo: "org/src", Branch: "main"}},
+	}
+
+	matching := matchWorkflows(workflows, CopyEvent{Platform: types.SourcePlatformGitHub, Repo: "org/src", Number: 1, BaseBranch: "release/8.0"})
+	require.Len(t, matching, 1)
+	assert.Equal(t, "releases", matching[0].Name)
+
+	assert.Empty(t, matchWorkflows(workflows, CopyEvent{Platform: types.SourcePlatformGitHub, Repo: "org/src", Number: 2, BaseBranch: "release/8.0/hotfix"}))
+}
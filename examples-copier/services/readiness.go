@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// readinessCheckTimeout bounds each dependency check, so a hung dependency fails the check instead of
+// the probe
+const readinessCheckTimeout = 3 * time.Second
+
+// Statuses of a liveness or readiness check
+const (
+	CheckStatusOK     = "ok"
+	CheckStatusFailed = "failed"
+	ReadinessReady    = "ready"
+	ReadinessNotReady = "not_ready"
+	ReadinessDraining = "draining"
+)
+
+// LivenessStatus is the /healthz response
+type LivenessStatus struct {
+	Status   string `json:"status"`
+	Uptime   string `json:"uptime"`
+	InFlight int    `json:"in_flight"`
+	Draining bool   `json:"draining"`
+}
+
+// ReadinessStatus is the /readyz response
+type ReadinessStatus struct {
+	Status string                     `json:"status"`
+	Checks map[string]DependencyCheck `json:"checks"`
+}
+
+// DependencyCheck is the result of checking one dependency the copier needs to process webhooks
+type DependencyCheck struct {
+	Status    string         `json:"status"`
+	LatencyMs int64          `json:"latency_ms"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// mongoPinger is a MongoDB-backed store that can check its connection
+type mongoPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// HealthzHandler handles /healthz. It reports whether the process is up, without checking dependencies,
+// so an outage of GitHub or MongoDB doesn't get healthy instances restarted.
+func HealthzHandler(container *ServiceContainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := LivenessStatus{
+			Status:   CheckStatusOK,
+			Uptime:   time.Since(container.StartTime).Round(time.Second).String(),
+			InFlight: container.InFlight.Count(),
+			Draining: container.InFlight.IsDraining(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+	}
+}
+
+// ReadyzHandler handles /readyz. It responds 200 once the copier can process webhooks, and 503 with the
+// failing checks otherwise, so traffic isn't sent to an instance before its config loads.
+func ReadyzHandler(container *ServiceContainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := CheckReadiness(r.Context(), container)
+		w.Header().Set("Content-Type", "application/json")
+		if status.Status != ReadinessReady {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(status)
+	}
+}
+
+// CheckReadiness checks that the copier config is loaded and valid, that the GitHub App credentials can
+// mint an installation token, and that MongoDB is reachable if any store uses it. An instance that is
+// draining for shutdown is never ready.
+func CheckReadiness(ctx context.Context, container *ServiceContainer) ReadinessStatus {
+	status := ReadinessStatus{
+		Status: ReadinessReady,
+		Checks: map[string]DependencyCheck{
+			"config": runDependencyCheck(ctx, func(ctx context.Context) (map[string]any, error) {
+				return checkConfigLoaded(ctx, container)
+			}),
+			"github": runDependencyCheck(ctx, func(ctx context.Context) (map[string]any, error) {
+				return checkGitHubToken()
+			}),
+		},
+	}
+	if pingers := mongoPingers(container); len(pingers) > 0 {
+		status.Checks["mongodb"] = runDependencyCheck(ctx, func(ctx context.Context) (map[string]any, error) {
+			return checkMongoDB(ctx, pingers)
+		})
+	}
+
+	for _, check := range status.Checks {
+		if check.Status != CheckStatusOK {
+			status.Status = ReadinessNotReady
+		}
+	}
+	if container.InFlight.IsDraining() {
+		status.Status = ReadinessDraining
+	}
+	return status
+}
+
+// runDependencyCheck runs check with a timeout and records how long it took
+func runDependencyCheck(ctx context.Context, check func(ctx context.Context) (map[string]any, error)) DependencyCheck {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check(ctx)
+	result := DependencyCheck{
+		Status:    CheckStatusOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Details:   details,
+	}
+	if err != nil {
+		result.Status = CheckStatusFailed
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// checkConfigLoaded checks the config the copier processes webhooks with. With a config watcher, that's the
+// last-known-good config, which is nil until the first load succeeds. Otherwise the config is fetched and
+// validated, as it is for each webhook.
+func checkConfigLoaded(ctx context.Context, container *ServiceContainer) (map[string]any, error) {
+	if container.ConfigWatcher != nil {
+		current := container.ConfigWatcher.Current()
+		watcherStatus := container.ConfigWatcher.Status()
+		if current == nil {
+			if watcherStatus.LastError != "" {
+				return nil, fmt.Errorf("config not loaded: %s", watcherStatus.LastError)
+			}
+			return nil, fmt.Errorf("config not loaded yet")
+		}
+		details := map[string]any{
+			"workflow_count": len(current.Workflows),
+			"loaded_at":      watcherStatus.LoadedAt,
+		}
+		if watcherStatus.LastError != "" {
+			details["last_reload_error"] = watcherStatus.LastError
+		}
+		return details, nil
+	}
+
+	yamlConfig, err := container.ConfigLoader.LoadConfig(ctx, container.Config)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"workflow_count": len(yamlConfig.Workflows)}, nil
+}
+
+// checkGitHubToken checks that the default installation has a usable token, minting one if the cached token
+// is missing or about to expire
+func checkGitHubToken() (map[string]any, error) {
+	if _, err := installationTokenForOrg(""); err != nil {
+		return nil, err
+	}
+	details := map[string]any{}
+	tokenCacheMu.Lock()
+	if expiresAt, ok := installationTokenExpiry[""]; ok && !expiresAt.IsZero() {
+		details["token_expires_at"] = expiresAt
+	}
+	tokenCacheMu.Unlock()
+	return details, nil
+}
+
+// mongoPingers returns the MongoDB-backed stores in use, by name
+func mongoPingers(container *ServiceContainer) map[string]mongoPinger {
+	pingers := make(map[string]mongoPinger)
+	if pinger, ok := container.AuditLogger.(mongoPinger); ok {
+		pingers["audit_log"] = pinger
+	}
+	if container.WriteLog != nil {
+		if pinger, ok := container.WriteLog.store.(mongoPinger); ok {
+			pingers["write_log"] = pinger
+		}
+	}
+	if container.RetryQueue != nil {
+		if pinger, ok := container.RetryQueue.store.(mongoPinger); ok {
+			pingers["retry_store"] = pinger
+		}
+	}
+	if container.RunHistory != nil {
+		if pinger, ok := container.RunHistory.store.(mongoPinger); ok {
+			pingers["run_history"] = pinger
+		}
+	}
+	if container.WebhookArchive != nil {
+		if pinger, ok := container.WebhookArchive.store.(mongoPinger); ok {
+			pingers["webhook_archive"] = pinger
+		}
+	}
+	return pingers
+}
+
+// checkMongoDB pings each MongoDB-backed store. Details has each store's result; the check fails if any
+// store can't reach MongoDB.
+func checkMongoDB(ctx context.Context, pingers map[string]mongoPinger) (map[string]any, error) {
+	details := make(map[string]any, len(pingers))
+	var failed []string
+	for name, pinger := range pingers {
+		if err := pinger.Ping(ctx); err != nil {
+			details[name] = err.Error()
+			failed = append(failed, name)
+			continue
+		}
+		details[name] = CheckStatusOK
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return details, fmt.Errorf("failed to ping MongoDB from %s", strings.Join(failed, ", "))
+	}
+	return details, nil
+}
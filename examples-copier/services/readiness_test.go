@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePinger is a MongoDB-backed store whose ping returns err
+type fakePinger struct {
+	err error
+}
+
+func (p fakePinger) Ping(ctx context.Context) error { return p.err }
+
+// fakePingRetryStore is an in-memory retry store that can be pinged
+type fakePingRetryStore struct {
+	*MemoryRetryStore
+	fakePinger
+}
+
+// readinessTestContainer returns a container whose config watcher has loaded configFile's config, if it
+// exists, and whose default installation has a token
+func readinessTestContainer(t *testing.T, configFile string) *ServiceContainer {
+	t.Helper()
+	config := &configs.Config{ConfigFile: configFile}
+	watcher := NewConfigWatcher(NewConfigLoader(), config, 0)
+	_, _ = watcher.Reload(context.Background())
+
+	originalToken := installationTokenCache[""]
+	originalExpiry, hadExpiry := installationTokenExpiry[""]
+	t.Cleanup(func() {
+		SetInstallationTokenForOrg("", originalToken)
+		if hadExpiry {
+			installationTokenExpiry[""] = originalExpiry
+		}
+	})
+	SetInstallationTokenForOrg("", "test-token")
+
+	return &ServiceContainer{
+		Config:        config,
+		ConfigLoader:  watcher,
+		ConfigWatcher: watcher,
+		AuditLogger:   &NoOpAuditLogger{},
+		StartTime:     time.Now().Add(-time.Minute),
+		InFlight:      NewInFlightTracker(),
+	}
+}
+
+func TestReadyzHandler_Ready(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "copier-config.yaml")
+	writeWatcherTestConfig(t, configFile, fmt.Sprintf(watcherTestConfig, "ready"))
+	container := readinessTestContainer(t, configFile)
+
+	w := httptest.NewRecorder()
+	ReadyzHandler(container)(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var status ReadinessStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, ReadinessReady, status.Status)
+	assert.Equal(t, CheckStatusOK, status.Checks["config"].Status)
+	assert.Equal(t, float64(1), status.Checks["config"].Details["workflow_count"])
+	assert.Equal(t, CheckStatusOK, status.Checks["github"].Status)
+	assert.NotContains(t, status.Checks, "mongodb", "MongoDB isn't checked when no store uses it")
+}
+
+func TestReadyzHandler_ConfigNotLoaded(t *testing.T) {
+	container := readinessTestContainer(t, filepath.Join(t.TempDir(), "missing.yaml"))
+	container.ConfigWatcher = NewConfigWatcher(NewConfigLoader(), container.Config, 0)
+
+	w := httptest.NewRecorder()
+	ReadyzHandler(container)(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var status ReadinessStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, ReadinessNotReady, status.Status)
+	assert.Equal(t, CheckStatusFailed, status.Checks["config"].Status)
+	assert.Equal(t, "config not loaded yet", status.Checks["config"].Error)
+	assert.Equal(t, CheckStatusOK, status.Checks["github"].Status)
+}
+
+func TestCheckReadiness_ConfigRejected(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "copier-config.yaml")
+	writeWatcherTestConfig(t, configFile, "workflows:\n  - name: \"missing-source\"\n")
+	container := readinessTestContainer(t, configFile)
+
+	status := CheckReadiness(context.Background(), container)
+
+	assert.Equal(t, ReadinessNotReady, status.Status)
+	assert.Contains(t, status.Checks["config"].Error, "config not loaded: ")
+}
+
+func TestCheckReadiness_GitHubTokenFails(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "copier-config.yaml")
+	writeWatcherTestConfig(t, configFile, fmt.Sprintf(watcherTestConfig, "ready"))
+	container := readinessTestContainer(t, configFile)
+
+	originalStore := credentialStore
+	t.Cleanup(func() { credentialStore = originalStore })
+	credentialStore = staticCredentialStore{}
+	SetInstallationTokenForOrg("", "")
+
+	status := CheckReadiness(context.Background(), container)
+
+	assert.Equal(t, ReadinessNotReady, status.Status)
+	assert.Equal(t, CheckStatusOK, status.Checks["config"].Status)
+	assert.Equal(t, CheckStatusFailed, status.Checks["github"].Status)
+	assert.Contains(t, status.Checks["github"].Error, `no credentials for ""`)
+}
+
+func TestCheckReadiness_MongoDB(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "copier-config.yaml")
+	writeWatcherTestConfig(t, configFile, fmt.Sprintf(watcherTestConfig, "ready"))
+	container := readinessTestContainer(t, configFile)
+	container.RetryQueue = &RetryQueue{store: fakePingRetryStore{MemoryRetryStore: NewMemoryRetryStore()}}
+
+	status := CheckReadiness(context.Background(), container)
+	assert.Equal(t, ReadinessReady, status.Status)
+	assert.Equal(t, map[string]any{"retry_store": CheckStatusOK}, status.Checks["mongodb"].Details)
+
+	container.RetryQueue = &RetryQueue{store: fakePingRetryStore{
+		MemoryRetryStore: NewMemoryRetryStore(),
+		fakePinger:       fakePinger{err: errors.New("server selection timeout")},
+	}}
+
+	status = CheckReadiness(context.Background(), container)
+	assert.Equal(t, ReadinessNotReady, status.Status)
+	assert.Equal(t, CheckStatusFailed, status.Checks["mongodb"].Status)
+	assert.Equal(t, "failed to ping MongoDB from retry_store", status.Checks["mongodb"].Error)
+	assert.Equal(t, "server selection timeout", status.Checks["mongodb"].Details["retry_store"])
+}
+
+func TestReadiness_Draining(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "copier-config.yaml")
+	writeWatcherTestConfig(t, configFile, fmt.Sprintf(watcherTestConfig, "ready"))
+	container := readinessTestContainer(t, configFile)
+	container.InFlight.Drain(context.Background())
+
+	assert.Equal(t, ReadinessDraining, CheckReadiness(context.Background(), container).Status)
+
+	// The process is still live while it drains
+	w := httptest.NewRecorder()
+	HealthzHandler(container)(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var liveness LivenessStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &liveness))
+	assert.Equal(t, CheckStatusOK, liveness.Status)
+	assert.True(t, liveness.Draining)
+	assert.Equal(t, "1m0s", liveness.Uptime)
+}
@@ -0,0 +1,635 @@
+package services
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// ReconcileOptions selects the workflows a reconciliation checks
+type ReconcileOptions struct {
+	// Workflows limits the reconciliation to workflows with these names; empty checks every workflow
+	Workflows []string
+	// DryRun reports drift without opening catch-up pull requests
+	DryRun bool
+}
+
+// ReconcileTarget is the drift found in one destination repo and branch
+type ReconcileTarget struct {
+	Repo    string   `json:"repo"`
+	Branch  string   `json:"branch"`
+	Missing []string `json:"missing,omitempty"` // Files the workflows produce that the destination doesn't have
+	Changed []string `json:"changed,omitempty"` // Files whose content or mode differs from the transformed source
+	Deleted []string `json:"deleted,omitempty"` // Files sync transformations remove that the destination still has
+	PRURL   string   `json:"pr_url,omitempty"`  // Catch-up pull request, unless the run was a dry run
+	Error   string   `json:"error,omitempty"`
+}
+
+// drifted returns true if the destination differs from what the workflows produce
+func (t ReconcileTarget) drifted() bool {
+	return len(t.Missing)+len(t.Changed)+len(t.Deleted) > 0
+}
+
+// ReconcileResult is the outcome of a reconciliation
+type ReconcileResult struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DryRun     bool      `json:"dry_run,omitempty"`
+	// Workflows holds the names of the workflows that were checked
+	Workflows []string `json:"workflows"`
+	// Skipped holds workflows that can't be reconciled, as "workflow: reason"
+	Skipped []string `json:"skipped,omitempty"`
+	// Targets holds the destination branches that drifted or couldn't be checked
+	Targets []ReconcileTarget `json:"targets,omitempty"`
+	// Errors holds sources and workflows that failed, as "name: error"
+	Errors []string `json:"errors,omitempty"`
+}
+
+// RunReconcile catches destinations up on changes the copier missed, such as webhooks dropped while it
+// was down. Each workflow's source branch is replayed through the workflow as it is now, and the hash of
+// every transformed file is compared with the file on the destination branch. Files that are missing or
+// differ, and files sync transformations would have deleted, are opened as one catch-up pull request per
+// destination branch. Destinations that are already in sync are left alone. Only GitHub sources are
+// supported.
+func RunReconcile(ctx context.Context, config *configs.Config, container *ServiceContainer, opts ReconcileOptions) (*ReconcileResult, error) {
+	result := &ReconcileResult{StartedAt: time.Now(), DryRun: opts.DryRun}
+
+	yamlConfig, err := container.ConfigLoader.LoadConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	workflows, skipped, err := selectReconcileWorkflows(yamlConfig.Workflows, opts, config.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	workflows = expandDestinationBranches(ctx, workflows)
+	result.Skipped = skipped
+
+	// The workflows queue files of their own, so uploads queued by webhooks processed meanwhile aren't mixed in
+	scoped, closeRunState := container.withRunFileState()
+	defer closeRunState()
+
+	var sources []BackfillSource
+	changelogs := make(map[types.UploadKey]map[string][]string) // Sources of each changelog, by destination and path
+	var sourceRuns [][]*workflowRun                             // The workflow runs of each source, in the order of sources
+	for _, group := range groupWorkflowsBySource(workflows) {
+		source := group[0].Source
+		summary, files, err := backfillSourceFiles(ctx, source, BackfillOptions{Mode: BackfillModeSnapshot})
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s@%s: %v", source.Repo, source.Branch, err))
+			continue
+		}
+		summary.targets = make(map[types.UploadKey]bool)
+		for i, workflow := range group {
+			// Workflows copying to several destination branches are listed once
+			if i == 0 || group[i-1].Name != workflow.Name {
+				summary.Workflows = append(summary.Workflows, workflow.Name)
+			}
+			summary.targets[types.UploadKey{RepoName: workflow.Destination.Repo, BranchPath: workflow.Destination.Branch}] = true
+		}
+		result.Workflows = append(result.Workflows, summary.Workflows...)
+
+		// Changelog entries list the drift found, not every file replayed, so they're added once it's known
+		for i := range group {
+			if !group[i].Changelog.IsEnabled() {
+				continue
+			}
+			key := types.UploadKey{RepoName: group[i].Destination.Repo, BranchPath: group[i].Destination.Branch}
+			if changelogs[key] == nil {
+				changelogs[key] = make(map[string][]string)
+			}
+			path := group[i].Changelog.GetPath()
+			changelogs[key][path] = append(changelogs[key][path], fmt.Sprintf("%s@%s at %s", summary.Repo, summary.Branch, summary.CommitSHA))
+			group[i].Changelog = nil
+		}
+
+		LogInfoCtx(ctx, "reconciling source", map[string]interface{}{
+			"source_repo":    summary.Repo,
+			"source_branch":  summary.Branch,
+			"commit_sha":     summary.CommitSHA,
+			"file_count":     summary.Files,
+			"workflow_count": len(group),
+		})
+
+		runs := processFilesWithWorkflows(ctx, 0, summary.CommitSHA, files, &types.YAMLConfig{Workflows: group, ConflictPolicy: yamlConfig.ConflictPolicy}, scoped)
+		for _, run := range runs {
+			if run.Err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", run.Workflow.Name, run.Err))
+			}
+		}
+		sources = append(sources, summary)
+		sourceRuns = append(sourceRuns, runs)
+	}
+
+	queued := scoped.FileStateService.GetFilesToUpload()
+	drifted := make(map[types.UploadKey]types.UploadFileContent)
+	targetIndex := make(map[types.UploadKey]int) // Index in result.Targets of each drifted destination
+	for _, key := range sortedUploadKeys(queued) {
+		target := ReconcileTarget{Repo: key.RepoName, Branch: strings.TrimPrefix(key.BranchPath, "refs/heads/")}
+		provider, repo, err := repoProviderFor(key.RepoName)
+		if err == nil {
+			var content types.UploadFileContent
+			content, target, err = reconcileDrift(ctx, provider, repo, key, queued[key])
+			if err == nil && target.drifted() {
+				drifted[key] = content
+				targetIndex[key] = len(result.Targets)
+			}
+		}
+		if err != nil {
+			target.Error = err.Error()
+		}
+		if err != nil || target.drifted() {
+			result.Targets = append(result.Targets, target)
+		}
+	}
+
+	if opts.DryRun || len(drifted) == 0 {
+		result.FinishedAt = time.Now()
+		return result, nil
+	}
+
+	// Open one catch-up pull request per drifted destination branch
+	uploads := make(map[types.UploadKey]UploadResult, len(drifted))
+	for key, content := range drifted {
+		target := &result.Targets[targetIndex[key]]
+		if err := addReconcileChangelogs(ctx, key, &content, *target, changelogs[key]); err != nil {
+			LogWarningCtx(ctx, "skipping changelog entry: failed to read the destination's changelog", map[string]interface{}{
+				"destination_repo": key.RepoName,
+				"error":            err.Error(),
+			})
+		}
+		content = reconcileUpload(content, key, sources)
+		drifted[key] = content
+		upload := uploadToTarget(ctx, key, content, container.PRTemplateFetcher)
+		uploads[key] = upload
+
+		target.PRURL = upload.PRURL
+		if upload.Err != nil {
+			target.Error = upload.Err.Error()
+		}
+	}
+
+	// Record each pull request in the write log once for every source it copies from
+	for i, source := range sources {
+		sourceUploads := make(map[types.UploadKey]UploadResult)
+		for key, upload := range uploads {
+			if source.targets[key] {
+				sourceUploads[key] = upload
+			}
+		}
+		change := CopyEvent{Platform: types.SourcePlatformGitHub, Repo: source.Repo, CommitSHA: source.CommitSHA, BaseBranch: source.Branch}
+		container.WriteLog.RecordUploads(ctx, change, sourceRuns[i], drifted, sourceUploads)
+	}
+
+	result.FinishedAt = time.Now()
+	return result, nil
+}
+
+// selectReconcileWorkflows returns the workflows a reconciliation checks, and the workflows it can't
+// check with the reason why. Named workflows that don't exist are an error.
+func selectReconcileWorkflows(workflows []types.Workflow, opts ReconcileOptions, globalDryRun bool) ([]types.Workflow, []string, error) {
+	names := make(map[string]bool, len(opts.Workflows))
+	for _, name := range opts.Workflows {
+		names[name] = true
+	}
+
+	var selected []types.Workflow
+	var skipped []string
+	found := make(map[string]bool)
+	for _, workflow := range workflows {
+		if len(names) > 0 && !names[workflow.Name] {
+			continue
+		}
+		found[workflow.Name] = true
+		switch {
+		case workflow.Source.GetPlatform() != types.SourcePlatformGitHub:
+			skipped = append(skipped, workflow.Name+": reconciliation only supports GitHub sources")
+		case workflow.Source.IsBranchPattern():
+			skipped = append(skipped, workflow.Name+": source branch is a pattern")
+		case workflow.IsDryRun(globalDryRun):
+			skipped = append(skipped, workflow.Name+": workflow is in dry-run mode")
+		default:
+			selected = append(selected, workflow)
+		}
+	}
+
+	var missing []string
+	for name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, nil, fmt.Errorf("no matching workflows named: %s", strings.Join(missing, ", "))
+	}
+	return selected, skipped, nil
+}
+
+// destinationFile is a file on a destination branch, as listed for reconciliation
+type destinationFile struct {
+	mode string
+	sha  string // Git blob SHA, or empty if the provider doesn't list it
+}
+
+// listDestinationFiles lists every file on a destination branch. GitHub lists each file's blob SHA with
+// the tree; for other providers the SHA is left empty and computed from the file's content when needed.
+func listDestinationFiles(ctx context.Context, provider RepoProvider, repo string, branch string) (map[string]destinationFile, error) {
+	if gp, onGitHub := provider.(*githubProvider); onGitHub {
+		owner, name := parseRepoPath(repo)
+		tree, _, err := gp.client.Git.GetTree(ctx, owner, name, branch, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tree for %s at %s: %w", repo, branch, err)
+		}
+		if tree.GetTruncated() {
+			return nil, fmt.Errorf("the tree of %s at %s is too large to list", repo, branch)
+		}
+		files := make(map[string]destinationFile, len(tree.Entries))
+		for _, entry := range tree.Entries {
+			if entry.GetType() == "blob" {
+				files[entry.GetPath()] = destinationFile{mode: entry.GetMode(), sha: entry.GetSHA()}
+			}
+		}
+		return files, nil
+	}
+
+	modes, truncated, err := provider.GetRepoTree(ctx, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		return nil, fmt.Errorf("the file listing of %s at %s is truncated", repo, branch)
+	}
+	files := make(map[string]destinationFile, len(modes))
+	for path, mode := range modes {
+		files[path] = destinationFile{mode: mode}
+	}
+	return files, nil
+}
+
+// reconcileDrift compares the files queued for a destination branch with the files on it. It returns the
+// queued upload cut down to the files that are missing or differ, and the deletions the branch still
+// needs, along with a summary of the drift.
+func reconcileDrift(ctx context.Context, provider RepoProvider, repo string, key types.UploadKey,
+	content types.UploadFileContent) (types.UploadFileContent, ReconcileTarget, error) {
+
+	branch := strings.TrimPrefix(key.BranchPath, "refs/heads/")
+	target := ReconcileTarget{Repo: key.RepoName, Branch: branch}
+
+	existing, err := listDestinationFiles(ctx, provider, repo, branch)
+	if err != nil {
+		return content, target, err
+	}
+
+	var files []github.RepositoryContent
+	modes := make(map[string]string)
+	for _, file := range content.Content {
+		path := file.GetName()
+		want, err := file.GetContent()
+		if err != nil {
+			return content, target, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		wantMode := content.FileModes[path]
+		if wantMode == "" {
+			wantMode = types.FileModeRegular
+		}
+
+		current, exists := existing[path]
+		switch {
+		case !exists:
+			target.Missing = append(target.Missing, path)
+		case current.mode != wantMode:
+			target.Changed = append(target.Changed, path)
+		default:
+			sha := current.sha
+			if sha == "" {
+				have, found, err := provider.GetFile(ctx, repo, path, branch)
+				if err != nil {
+					return content, target, fmt.Errorf("failed to get %s: %w", path, err)
+				}
+				if found {
+					sha = gitBlobSHA(have)
+				}
+			}
+			if sha == gitBlobSHA(want) {
+				continue
+			}
+			target.Changed = append(target.Changed, path)
+		}
+		files = append(files, file)
+		if mode, ok := content.FileModes[path]; ok {
+			modes[path] = mode
+		}
+	}
+
+	var deletePaths []string
+	for _, path := range content.DeletePaths {
+		if _, exists := existing[path]; exists {
+			deletePaths = append(deletePaths, path)
+		}
+	}
+	target.Deleted = deletePaths
+
+	content.Content = files
+	content.FileModes = modes
+	content.DeletePaths = deletePaths
+	return content, target, nil
+}
+
+// addReconcileChangelogs adds an entry listing a destination's drift to each of its changelogs, given the
+// sources of the workflows that keep each changelog
+func addReconcileChangelogs(ctx context.Context, key types.UploadKey, content *types.UploadFileContent,
+	target ReconcileTarget, changelogs map[string][]string) error {
+
+	if len(changelogs) == 0 {
+		return nil
+	}
+	provider, repo, err := repoProviderFor(key.RepoName)
+	if err != nil {
+		return err
+	}
+	files := append(append([]string{}, target.Missing...), target.Changed...)
+	sort.Strings(files)
+
+	paths := make([]string, 0, len(changelogs))
+	for path := range changelogs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		source := "Reconciled with " + strings.Join(changelogs[path], ", ")
+		entry := changelogEntry(time.Now(), source, files, target.Deleted)
+		if err := appendChangelogEntry(ctx, provider, repo, target.Branch, content, path, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gitBlobSHA returns the SHA Git gives a blob with content, as listed in tree entries
+func gitBlobSHA(content string) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write([]byte(content))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// reconcileUpload rewrites a drifted destination's upload as a catch-up pull request that isn't merged
+// automatically, so changes the copier missed are reviewed before they land
+func reconcileUpload(content types.UploadFileContent, key types.UploadKey, sources []BackfillSource) types.UploadFileContent {
+	var repos []string
+	var lines []string
+	for _, source := range sources {
+		if !source.targets[key] {
+			continue
+		}
+		repos = append(repos, source.Repo)
+		lines = append(lines, fmt.Sprintf("- %s@%s at %s", source.Repo, source.Branch, source.CommitSHA))
+	}
+
+	content.CommitStrategy = types.CommitStrategyPR
+	content.AutoMergePR = false
+	content.UsePRTemplate = false
+	content.CommitMessage = fmt.Sprintf("Reconcile code examples with %s", strings.Join(repos, ", "))
+	content.PRTitle = content.CommitMessage
+	content.PRBody = fmt.Sprintf("%s:%s had drifted from its sources. This PR updates %d file(s) and deletes %d, "+
+		"to match:\n\n%s", key.RepoName, key.BranchPath, len(content.Content), len(content.DeletePaths), strings.Join(lines, "\n"))
+	return content
+}
+
+// sortedUploadKeys returns the keys of the queued uploads sorted by repo and branch
+func sortedUploadKeys(uploads map[types.UploadKey]types.UploadFileContent) []types.UploadKey {
+	var keys []types.UploadKey
+	for _, batch := range uploadBatchesByRepo(uploads) {
+		keys = append(keys, batch...)
+	}
+	return keys
+}
+
+// Leases that keep reconciliations to one instance at a time
+const (
+	reconcileRunLease      = "reconcile"          // Held while a reconciliation runs, and renewed until it finishes
+	reconcileScheduleLease = "reconcile-schedule" // Held for an interval by the instance that ran the scheduled reconciliation
+	reconcileRunLeaseTTL   = 5 * time.Minute
+)
+
+// Reconciler runs reconciliations on an interval or when an admin asks for one, one at a time, and keeps
+// the result of the most recent run. Instances sharing a lease store take turns: a reconciliation runs
+// only on the instance holding the run lease, and each interval's scheduled reconciliation runs only on
+// the instance that takes the schedule lease.
+type Reconciler struct {
+	interval  time.Duration
+	reconcile func(ctx context.Context, opts ReconcileOptions) (*ReconcileResult, error)
+	leases    LeaseStore
+	owner     string // Takes leases for this instance
+	now       func() time.Time
+
+	running atomic.Bool
+	mu      sync.RWMutex
+	last    *ReconcileResult
+	lastErr string
+}
+
+// ReconcileStatus describes the reconciler's schedule and its most recent run
+type ReconcileStatus struct {
+	IntervalSeconds int              `json:"interval_seconds"` // 0 if reconciliations only run on request
+	Running         bool             `json:"running"`
+	LastRun         *ReconcileResult `json:"last_run,omitempty"`
+	LastError       string           `json:"last_error,omitempty"` // Why the last run failed, if it did
+}
+
+// NewReconciler creates a reconciler for the container's workflows that takes turns with other instances
+// through leases. interval is how often Run reconciles; 0 or less means reconciliations only run on request.
+func NewReconciler(config *configs.Config, container *ServiceContainer, interval time.Duration, leases LeaseStore) *Reconciler {
+	return &Reconciler{
+		interval: interval,
+		reconcile: func(ctx context.Context, opts ReconcileOptions) (*ReconcileResult, error) {
+			return RunReconcile(ctx, config, container, opts)
+		},
+		leases: leases,
+		owner:  instanceID,
+		now:    time.Now,
+	}
+}
+
+// Status returns the reconciler's schedule and the result of its most recent run
+func (r *Reconciler) Status() ReconcileStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return ReconcileStatus{
+		IntervalSeconds: int(r.interval / time.Second),
+		Running:         r.running.Load(),
+		LastRun:         r.last,
+		LastError:       r.lastErr,
+	}
+}
+
+// Start begins a reconciliation in the background. It returns false, without starting one, if a
+// reconciliation is already running on this or another instance.
+func (r *Reconciler) Start(ctx context.Context, opts ReconcileOptions) bool {
+	if !r.running.CompareAndSwap(false, true) {
+		return false
+	}
+	if !r.acquire(ctx, reconcileRunLease, reconcileRunLeaseTTL) {
+		r.running.Store(false)
+		return false
+	}
+	go func() {
+		defer r.running.Store(false)
+		r.runLeased(ctx, opts)
+	}()
+	return true
+}
+
+// Run reconciles every workflow on the reconciler's interval until ctx is cancelled. A tick that comes
+// while a reconciliation is still running, or after another instance ran this interval's, is skipped.
+func (r *Reconciler) Run(ctx context.Context) {
+	if r.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.runScheduled(ctx)
+		}
+	}
+}
+
+// runScheduled runs the scheduled reconciliation if this instance takes the schedule lease. The lease isn't
+// released, so other instances skip their ticks until the interval has passed.
+func (r *Reconciler) runScheduled(ctx context.Context) {
+	if !r.running.CompareAndSwap(false, true) {
+		return
+	}
+	defer r.running.Store(false)
+	if !r.acquire(ctx, reconcileScheduleLease, r.interval) || !r.acquire(ctx, reconcileRunLease, reconcileRunLeaseTTL) {
+		return
+	}
+	r.runLeased(ctx, ReconcileOptions{})
+}
+
+// runLeased runs a reconciliation while holding the run lease, renewing it until the reconciliation
+// finishes and then releasing it
+func (r *Reconciler) runLeased(ctx context.Context, opts ReconcileOptions) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(reconcileRunLeaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if !r.acquire(ctx, reconcileRunLease, reconcileRunLeaseTTL) {
+					LogWarningCtx(ctx, "lost the reconciliation lease; another instance may start one", nil)
+				}
+			}
+		}
+	}()
+
+	r.runOnce(ctx, opts)
+	close(done)
+	if err := r.leases.Release(context.Background(), reconcileRunLease, r.owner); err != nil {
+		LogWarningCtx(ctx, "failed to release the reconciliation lease", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// acquire takes or renews a lease, treating a lease store error as the lease being held elsewhere
+func (r *Reconciler) acquire(ctx context.Context, name string, ttl time.Duration) bool {
+	ok, err := r.leases.Acquire(ctx, name, r.owner, r.now(), ttl)
+	if err != nil {
+		LogWarningCtx(ctx, "failed to acquire reconciliation lease", map[string]interface{}{
+			"lease": name,
+			"error": err.Error(),
+		})
+		return false
+	}
+	return ok
+}
+
+// runOnce runs a reconciliation and records its result
+func (r *Reconciler) runOnce(ctx context.Context, opts ReconcileOptions) {
+	result, err := r.reconcile(ctx, opts)
+
+	r.mu.Lock()
+	if err != nil {
+		r.lastErr = err.Error()
+	} else {
+		r.last = result
+		r.lastErr = ""
+	}
+	r.mu.Unlock()
+
+	if err != nil {
+		LogErrorCtx(ctx, "reconciliation failed", err, nil)
+		return
+	}
+	drifted := 0
+	for _, target := range result.Targets {
+		if target.drifted() {
+			drifted++
+		}
+	}
+	LogInfoCtx(ctx, "reconciliation finished", map[string]interface{}{
+		"workflow_count": len(result.Workflows),
+		"drifted":        drifted,
+		"dry_run":        result.DryRun,
+		"error_count":    len(result.Errors),
+	})
+}
+
+// ReconcileHandler handles the admin reconcile endpoint. GET returns the status of the last
+// reconciliation. POST starts one in the background and responds with 202, or 409 if one is already
+// running on this or another instance; ?dry_run=true reports drift without opening pull requests, and ?workflow=a,b limits it to
+// the named workflows. Requests must send the admin token as a bearer token.
+func ReconcileHandler(config *configs.Config, reconciler *Reconciler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validAdminToken(r, config.AdminToken) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		status := http.StatusOK
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			opts := ReconcileOptions{DryRun: r.URL.Query().Get("dry_run") == "true"}
+			for _, name := range strings.Split(r.URL.Query().Get("workflow"), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					opts.Workflows = append(opts.Workflows, name)
+				}
+			}
+			// Don't use the request context, as it's cancelled when the request completes
+			ctx := WithCorrelationID(context.Background(), CorrelationIDFromContext(r.Context()))
+			status = http.StatusAccepted
+			if !reconciler.Start(ctx, opts) {
+				status = http.StatusConflict
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(reconciler.Status())
+	}
+}
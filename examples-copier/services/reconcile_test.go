@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reconcileTestProvider serves a destination branch's files from memory
+type reconcileTestProvider struct {
+	RepoProvider
+	files   map[string]string
+	modes   map[string]string
+	fetched []string
+}
+
+func (p *reconcileTestProvider) GetRepoTree(ctx context.Context, repo string, ref string) (map[string]string, bool, error) {
+	modes := make(map[string]string, len(p.files))
+	for path := range p.files {
+		modes[path] = types.FileModeRegular
+		if mode, ok := p.modes[path]; ok {
+			modes[path] = mode
+		}
+	}
+	return modes, false, nil
+}
+
+func (p *reconcileTestProvider) GetFile(ctx context.Context, repo string, filePath string, ref string) (string, bool, error) {
+	p.fetched = append(p.fetched, filePath)
+	content, found := p.files[filePath]
+	return content, found, nil
+}
+
+func TestSelectReconcileWorkflows(t *testing.T) {
+	dryRun := true
+	workflows := []types.Workflow{
+		{Name: "python", Source: types.Source{Repo: "org/src", Branch: "main"}},
+		{Name: "gitlab", Source: types.Source{Platform: types.SourcePlatformGitLab, Repo: "group/src", Branch: "main"}},
+		{Name: "releases", Source: types.Source{Repo: "org/src", Branch: "release/*"}},
+		{Name: "preview", Source: types.Source{Repo: "org/src", Branch: "main"}, DryRun: &dryRun},
+	}
+
+	selected, skipped, err := selectReconcileWorkflows(workflows, ReconcileOptions{}, false)
+	require.NoError(t, err)
+	require.Len(t, selected, 1)
+	assert.Equal(t, "python", selected[0].Name)
+	assert.Equal(t, []string{
+		"gitlab: reconciliation only supports GitHub sources",
+		"releases: source branch is a pattern",
+		"preview: workflow is in dry-run mode",
+	}, skipped)
+
+	selected, skipped, err = selectReconcileWorkflows(workflows, ReconcileOptions{Workflows: []string{"python"}}, false)
+	require.NoError(t, err)
+	assert.Len(t, selected, 1)
+	assert.Empty(t, skipped)
+
+	selected, _, err = selectReconcileWorkflows(workflows, ReconcileOptions{}, true)
+	require.NoError(t, err)
+	assert.Empty(t, selected, "no workflows are reconciled when the copier is in dry-run mode")
+
+	_, _, err = selectReconcileWorkflows(workflows, ReconcileOptions{Workflows: []string{"python", "ruby"}}, false)
+	assert.EqualError(t, err, "no matching workflows named: ruby")
+}
+
+func TestReconcileDrift(t *testing.T) {
+	provider := &reconcileTestProvider{
+		files: map[string]string{
+			"examples/same.py":    "print('same')\n",
+			"examples/stale.py":   "print('old')\n",
+			"examples/run.sh":     "echo run\n",
+			"examples/removed.py": "print('removed')\n",
+		},
+	}
+	key := types.UploadKey{RepoName: "org/python", BranchPath: "refs/heads/main"}
+	content := types.UploadFileContent{
+		Content: []github.RepositoryContent{
+			{Name: github.String("examples/same.py"), Content: github.String("print('same')\n")},
+			{Name: github.String("examples/stale.py"), Content: github.String("print('new')\n")},
+			{Name: github.String("examples/run.sh"), Content: github.String("echo run\n")},
+			{Name: github.String("examples/added.py"), Content: github.String("print('added')\n")},
+		},
+		FileModes:   map[string]string{"examples/run.sh": types.FileModeExecutable},
+		DeletePaths: []string{"examples/removed.py", "examples/already-gone.py"},
+	}
+
+	drift, target, err := reconcileDrift(context.Background(), provider, "org/python", key, content)
+	require.NoError(t, err)
+	assert.Equal(t, "main", target.Branch)
+	assert.Equal(t, []string{"examples/added.py"}, target.Missing)
+	assert.Equal(t, []string{"examples/stale.py", "examples/run.sh"}, target.Changed)
+	assert.Equal(t, []string{"examples/removed.py"}, target.Deleted)
+	assert.True(t, target.drifted())
+
+	assert.Equal(t, map[string]string{
+		"examples/stale.py": "print('new')\n",
+		"examples/run.sh":   "echo run\n",
+		"examples/added.py": "print('added')\n",
+	}, uploadEntries(drift.Content))
+	assert.Equal(t, map[string]string{"examples/run.sh": types.FileModeExecutable}, drift.FileModes)
+	assert.Equal(t, []string{"examples/removed.py"}, drift.DeletePaths)
+	assert.Equal(t, []string{"examples/same.py", "examples/stale.py"}, provider.fetched,
+		"only files with the expected mode are fetched to compare")
+}
+
+func TestReconcileDrift_InSync(t *testing.T) {
+	provider := &reconcileTestProvider{files: map[string]string{"examples/same.py": "print('same')\n"}}
+	key := types.UploadKey{RepoName: "org/python", BranchPath: "main"}
+	content := types.UploadFileContent{
+		Content: []github.RepositoryContent{{Name: github.String("examples/same.py"), Content: github.String("print('same')\n")}},
+	}
+
+	drift, target, err := reconcileDrift(context.Background(), provider, "org/python", key, content)
+	require.NoError(t, err)
+	assert.False(t, target.drifted())
+	assert.Empty(t, drift.Content)
+}
+
+func TestGitBlobSHA(t *testing.T) {
+	// As computed by `echo hello | git hash-object --stdin`
+	assert.Equal(t, "ce013625030ba8dba906f756967f9e9ca394464a", gitBlobSHA("hello\n"))
+	assert.Equal(t, "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391", gitBlobSHA(""))
+}
+
+func TestReconcileUpload(t *testing.T) {
+	key := types.UploadKey{RepoName: "org/python", BranchPath: "main"}
+	sources := []BackfillSource{
+		{Repo: "org/src", Branch: "main", CommitSHA: "abc123", targets: map[types.UploadKey]bool{key: true}},
+		{Repo: "org/other", Branch: "main", CommitSHA: "def456", targets: map[types.UploadKey]bool{}},
+	}
+	content := types.UploadFileContent{
+		Content:        []github.RepositoryContent{{Name: github.String("a.py")}},
+		DeletePaths:    []string{"b.py"},
+		CommitStrategy: types.CommitStrategyDirect,
+		AutoMergePR:    true,
+	}
+
+	upload := reconcileUpload(content, key, sources)
+	assert.Equal(t, types.CommitStrategyPR, upload.CommitStrategy)
+	assert.False(t, upload.AutoMergePR, "catch-up PRs are reviewed before merging")
+	assert.Equal(t, "Reconcile code examples with org/src", upload.PRTitle)
+	assert.Contains(t, upload.PRBody, "updates 1 file(s) and deletes 1")
+	assert.Contains(t, upload.PRBody, "- org/src@main at abc123")
+	assert.NotContains(t, upload.PRBody, "org/other")
+}
+
+// newTestReconciler returns a reconciler that runs reconcile, taking leases from leases as owner
+func newTestReconciler(leases LeaseStore, owner string,
+	reconcile func(ctx context.Context, opts ReconcileOptions) (*ReconcileResult, error)) *Reconciler {
+	return &Reconciler{interval: time.Hour, reconcile: reconcile, leases: leases, owner: owner, now: time.Now}
+}
+
+func TestReconciler_InstancesTakeTurns(t *testing.T) {
+	ctx := context.Background()
+	leases := NewMemoryLeaseStore()
+	release := make(chan struct{})
+	var runs atomic.Int32
+	reconcile := func(ctx context.Context, opts ReconcileOptions) (*ReconcileResult, error) {
+		runs.Add(1)
+		<-release
+		return &ReconcileResult{}, nil
+	}
+	a := newTestReconciler(leases, "instance-a", reconcile)
+	b := newTestReconciler(leases, "instance-b", reconcile)
+
+	// A requested reconciliation holds the run lease until it finishes
+	require.True(t, a.Start(ctx, ReconcileOptions{}))
+	assert.False(t, b.Start(ctx, ReconcileOptions{}), "already running on another instance")
+	close(release)
+	require.Eventually(t, func() bool { return !a.Status().Running }, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return b.Start(ctx, ReconcileOptions{}) }, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return !b.Status().Running }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), runs.Load())
+
+	// Only one instance runs each interval's scheduled reconciliation
+	a.runScheduled(ctx)
+	b.runScheduled(ctx)
+	assert.Equal(t, int32(3), runs.Load())
+	a.runScheduled(ctx)
+	assert.Equal(t, int32(4), runs.Load(), "the instance holding the schedule lease runs the next one")
+}
+
+func TestMemoryLeaseStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryLeaseStore()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	ok, _ := store.Acquire(ctx, "job", "a", now, time.Minute)
+	assert.True(t, ok)
+	ok, _ = store.Acquire(ctx, "job", "b", now.Add(30*time.Second), time.Minute)
+	assert.False(t, ok, "held by another owner")
+	ok, _ = store.Acquire(ctx, "job", "a", now.Add(30*time.Second), time.Minute)
+	assert.True(t, ok, "the owner renews it")
+	ok, _ = store.Acquire(ctx, "job", "b", now.Add(time.Minute), time.Minute)
+	assert.False(t, ok, "renewed until 90 seconds in")
+	ok, _ = store.Acquire(ctx, "job", "b", now.Add(2*time.Minute), time.Minute)
+	assert.True(t, ok, "expired")
+
+	require.NoError(t, store.Release(ctx, "job", "a"))
+	ok, _ = store.Acquire(ctx, "job", "a", now.Add(2*time.Minute), time.Minute)
+	assert.False(t, ok, "only the owner releases it")
+	require.NoError(t, store.Release(ctx, "job", "b"))
+	ok, _ = store.Acquire(ctx, "job", "a", now.Add(2*time.Minute), time.Minute)
+	assert.True(t, ok)
+}
+
+func TestReconcileHandler(t *testing.T) {
+	config := &configs.Config{AdminToken: "admin-token"}
+	release := make(chan struct{})
+	var ran ReconcileOptions
+	reconciler := newTestReconciler(NewMemoryLeaseStore(), "instance-a", func(ctx context.Context, opts ReconcileOptions) (*ReconcileResult, error) {
+		ran = opts
+		<-release
+		return &ReconcileResult{DryRun: opts.DryRun, Workflows: opts.Workflows}, nil
+	})
+	handler := ReconcileHandler(config, reconciler)
+
+	request := func(method string, target string, token string) (*httptest.ResponseRecorder, ReconcileStatus) {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		var status ReconcileStatus
+		if rec.Header().Get("Content-Type") == "application/json" {
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+		}
+		return rec, status
+	}
+
+	rec, _ := request(http.MethodPost, "/admin/reconcile", "wrong-token")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec, status := request(http.MethodPost, "/admin/reconcile?dry_run=true&workflow=python,node", "admin-token")
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.True(t, status.Running)
+
+	rec, _ = request(http.MethodPost, "/admin/reconcile", "admin-token")
+	assert.Equal(t, http.StatusConflict, rec.Code, "only one reconciliation runs at a time")
+
+	close(release)
+	require.Eventually(t, func() bool { return !reconciler.Status().Running }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, ReconcileOptions{DryRun: true, Workflows: []string{"python", "node"}}, ran)
+
+	rec, status = request(http.MethodGet, "/admin/reconcile", "admin-token")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.NotNil(t, status.LastRun)
+	assert.True(t, status.LastRun.DryRun)
+	assert.Empty(t, status.LastError)
+
+	rec, _ = request(http.MethodDelete, "/admin/reconcile", "admin-token")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// commitSHAPattern matches a full commit SHA, which a release can target in place of a branch
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// releaseEventSource converts published GitHub releases for workflows with the release trigger. The
+// release's files are copied from the commit its tag points to. Other release actions and drafts don't
+// trigger a copy.
+type releaseEventSource struct{}
+
+func (releaseEventSource) Name() string { return "release" }
+
+func (releaseEventSource) Convert(ctx context.Context, payload []byte) (*CopyEvent, string, error) {
+	var evt github.ReleaseEvent
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return nil, "", invalidPayload(err)
+	}
+	if missing := validateReleaseEvent(&evt); len(missing) > 0 {
+		return nil, "", missingFields("release", missing)
+	}
+
+	release := evt.GetRelease()
+	switch {
+	case evt.GetAction() != "published":
+		return nil, "not published", nil
+	case release.GetDraft():
+		return nil, "draft release", nil
+	}
+
+	change := &CopyEvent{
+		Platform:   types.SourcePlatformGitHub,
+		Repo:       evt.GetRepo().GetFullName(),
+		BaseBranch: releaseBranch(&evt),
+		URL:        release.GetHTMLURL(),
+		Title:      release.GetName(),
+		Author:     evt.GetSender().GetLogin(),
+		Trigger:    types.WorkflowTriggerRelease,
+		ReleaseTag: release.GetTagName(),
+		Prerelease: release.GetPrerelease(),
+	}
+
+	LogInfoCtx(ctx, "processing release", map[string]interface{}{
+		"tag":        change.ReleaseTag,
+		"prerelease": change.Prerelease,
+		"repo":       change.Repo,
+		"branch":     change.BaseBranch,
+	})
+	return change, "", nil
+}
+
+// releaseBranch returns the branch a release was cut from: its target, or the repo's default branch if
+// the release targets a commit
+func releaseBranch(evt *github.ReleaseEvent) string {
+	target := evt.GetRelease().GetTargetCommitish()
+	if target == "" || commitSHAPattern.MatchString(target) {
+		return evt.GetRepo().GetDefaultBranch()
+	}
+	return target
+}
+
+// resolveReleaseCommit sets the change's commit SHA to the commit its release tag points to
+func resolveReleaseCommit(ctx context.Context, change *CopyEvent) error {
+	owner, name, _ := strings.Cut(change.Repo, "/")
+	sha, _, err := GetRestClient().Repositories.GetCommitSHA1(ctx, owner, name, change.ReleaseTag, "")
+	if err != nil {
+		return fmt.Errorf("failed to get commit for tag %s in %s: %w", change.ReleaseTag, change.Repo, err)
+	}
+	change.CommitSHA = sha
+	LogInfoCtx(ctx, "resolved release tag", map[string]interface{}{
+		"tag": change.ReleaseTag,
+		"sha": sha,
+	})
+	return nil
+}
+
+// getFilesChangedInRelease lists the files changed since the release before the change's. Without an
+// earlier release, every file in the repo at the release's tag is listed as added.
+func getFilesChangedInRelease(ctx context.Context, owner string, name string, change CopyEvent) ([]types.ChangedFile, error) {
+	client := GetRestClient()
+	releases, _, err := client.Repositories.ListReleases(ctx, owner, name, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases in %s/%s: %w", owner, name, err)
+	}
+	if previous := previousReleaseTag(releases, change.ReleaseTag, change.Prerelease); previous != "" {
+		LogInfoCtx(ctx, "comparing with previous release", map[string]interface{}{
+			"tag":          change.ReleaseTag,
+			"previous_tag": previous,
+		})
+		return GetFilesChangedInPush(ctx, owner, name, previous, change.CommitSHA)
+	}
+
+	modes, truncated, err := GetRepoTree(ctx, client, owner, name, change.CommitSHA)
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		LogWarningCtx(ctx, "repo tree truncated; first release copies a partial file list", map[string]interface{}{
+			"tag":   change.ReleaseTag,
+			"files": len(modes),
+		})
+	}
+	paths := make([]string, 0, len(modes))
+	for filePath := range modes {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+	changedFiles := make([]types.ChangedFile, 0, len(paths))
+	for _, filePath := range paths {
+		changedFiles = append(changedFiles, types.ChangedFile{Path: filePath, Status: "ADDED"})
+	}
+	LogInfoCtx(ctx, fmt.Sprintf("First release has %d files.", len(changedFiles)), nil)
+	return changedFiles, nil
+}
+
+// previousReleaseTag returns the tag of the release published before the one tagged tag, from releases
+// listed newest first. A pre-release is compared with the release before it; a full release skips past
+// pre-releases to the previous full release, so it includes everything they changed. Returns "" if
+// there's no earlier release.
+func previousReleaseTag(releases []*github.RepositoryRelease, tag string, prerelease bool) string {
+	found := false
+	for _, release := range releases {
+		if !found {
+			found = release.GetTagName() == tag
+			continue
+		}
+		if release.GetDraft() || (release.GetPrerelease() && !prerelease) {
+			continue
+		}
+		return release.GetTagName()
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postReleaseEvent(t *testing.T, evt *github.ReleaseEvent) (*httptest.ResponseRecorder, *ServiceContainer) {
+	t.Helper()
+	config := &configs.Config{
+		ConfigRepoOwner: "test-owner",
+		ConfigRepoName:  "test-repo",
+		ConfigFile:      "nonexistent-config.yaml",
+	}
+	container, err := NewServiceContainer(config)
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(evt)
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
+	req.Header.Set("X-GitHub-Event", "release")
+	w := httptest.NewRecorder()
+	HandleWebhookWithContainer(w, req, config, container)
+	return w, container
+}
+
+func TestHandleWebhookWithContainer_Release(t *testing.T) {
+	repo := &github.Repository{FullName: github.String("test-owner/source"), DefaultBranch: github.String("main")}
+
+	tests := []struct {
+		name    string
+		action  string
+		release *github.RepositoryRelease
+	}{
+		{name: "ignores releases that weren't published", action: "created",
+			release: &github.RepositoryRelease{TagName: github.String("v1.2.0")}},
+		{name: "ignores drafts", action: "published",
+			release: &github.RepositoryRelease{TagName: github.String("v1.2.0"), Draft: github.Bool(true)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, container := postReleaseEvent(t, &github.ReleaseEvent{Action: github.String(tt.action), Release: tt.release, Repo: repo})
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, int64(1), container.MetricsCollector.webhookIgnored)
+		})
+	}
+
+	t.Run("rejects missing fields", func(t *testing.T) {
+		w, _ := postReleaseEvent(t, &github.ReleaseEvent{
+			Action: github.String("published"), Release: &github.RepositoryRelease{}, Repo: repo,
+		})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var resp WebhookErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, []string{"release.tag_name"}, resp.MissingFields)
+	})
+}
+
+func TestReleaseBranch(t *testing.T) {
+	repo := &github.Repository{DefaultBranch: github.String("main")}
+	release := func(target string) *github.ReleaseEvent {
+		return &github.ReleaseEvent{Repo: repo, Release: &github.RepositoryRelease{TargetCommitish: github.String(target)}}
+	}
+	assert.Equal(t, "v2.x", releaseBranch(release("v2.x")))
+	assert.Equal(t, "main", releaseBranch(release("")))
+	assert.Equal(t, "main", releaseBranch(release("0123456789abcdef0123456789abcdef01234567")))
+}
+
+func TestMatchWorkflows_Release(t *testing.T) {
+	source := types.Source{Repo: "org/src", Branch: "main"}
+	trigger := types.WorkflowTriggers{types.WorkflowTriggerRelease}
+	workflows := []types.Workflow{
+		{Name: "merged", Source: source},
+		{Name: "any-release", Source: source, Trigger: trigger},
+		{Name: "v-tags", Source: source, Trigger: trigger, Release: &types.ReleaseConfig{Tags: []string{"v*"}, Prereleases: true}},
+	}
+
+	change := CopyEvent{Platform: types.SourcePlatformGitHub, Repo: "org/src", BaseBranch: "main",
+		Trigger: types.WorkflowTriggerRelease, ReleaseTag: "v1.2.0"}
+	assert.Len(t, matchWorkflows(workflows, change), 2)
+	assert.Equal(t, "release v1.2.0", change.describe())
+	assert.Equal(t, "copier/source-release-v1.2.0", sourcePRBranch(change))
+
+	change.Prerelease = true
+	matching := matchWorkflows(workflows, change)
+	require.Len(t, matching, 1)
+	assert.Equal(t, "v-tags", matching[0].Name)
+
+	change.ReleaseTag = "nightly"
+	assert.Empty(t, matchWorkflows(workflows, change))
+}
+
+func TestPreviousReleaseTag(t *testing.T) {
+	release := func(tag string, prerelease bool, draft bool) *github.RepositoryRelease {
+		return &github.RepositoryRelease{TagName: github.String(tag), Prerelease: github.Bool(prerelease), Draft: github.Bool(draft)}
+	}
+	// Newest first, as GitHub lists them
+	releases := []*github.RepositoryRelease{
+		release("v1.3.0", false, false),
+		release("v1.3.0-rc2", true, false),
+		release("v1.3.0-rc1", true, false),
+		release("v1.2.1", false, true),
+		release("v1.2.0", false, false),
+	}
+
+	assert.Equal(t, "v1.2.0", previousReleaseTag(releases, "v1.3.0", false), "full releases skip pre-releases and drafts")
+	assert.Equal(t, "v1.3.0-rc1", previousReleaseTag(releases, "v1.3.0-rc2", true))
+	assert.Equal(t, "", previousReleaseTag(releases, "v1.2.0", false))
+	assert.Equal(t, "", previousReleaseTag(releases, "v0.9.0", false))
+}
+
+func TestReleaseTagVariable(t *testing.T) {
+	wp := &workflowProcessor{patternMatcher: NewPatternMatcher(), pathTransformer: NewPathTransformer(), messageTemplater: NewMessageTemplater()}
+	workflow := types.Workflow{
+		Name:        "driver-examples",
+		Source:      types.Source{Repo: "org/driver", Branch: "main"},
+		Destination: types.Destination{Repo: "org/docs", Branch: "main"},
+		CommitStrategy: &types.CommitStrategyConfig{
+			CommitMessage: "Update examples to ${release_tag}",
+			PRTitle:       "Examples from {{ .ReleaseTag }}",
+		},
+	}
+	ctx := withSourceChange(context.Background(), CopyEvent{Repo: "org/driver", BaseBranch: "main",
+		Trigger: types.WorkflowTriggerRelease, ReleaseTag: "v1.2.0"})
+
+	matched, targetPath, err := wp.applyTransformation(ctx, workflow,
+		types.Transformation{Move: &types.MoveTransform{From: "examples", To: "go/${release_tag}"}}, "examples/main.go")
+	require.NoError(t, err)
+	assert.True(t, matched)
+	assert.Equal(t, "go/v1.2.0/main.go", targetPath)
+
+	content := types.UploadFileContent{Content: []github.RepositoryContent{{Name: github.String(targetPath)}}}
+	wp.renderUploadMessages(ctx, workflow, &content, 0, "abc123")
+	assert.Equal(t, "Update examples to v1.2.0", content.CommitMessage)
+	assert.Equal(t, "Examples from v1.2.0", content.PRTitle)
+}
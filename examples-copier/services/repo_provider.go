@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	. "github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// RepoProvider writes to and reads from destination repos on one hosting platform. Repos are passed
+// as their path on the platform, such as "owner/repo", and branches by name.
+type RepoProvider interface {
+	// GetFile returns a file's content at ref. found is false if the file doesn't exist.
+	GetFile(ctx context.Context, repo string, filePath string, ref string) (content string, found bool, err error)
+	// GetRepoTree lists every file at ref, returning each file's Git mode keyed by path. truncated is
+	// true if the listing is incomplete.
+	GetRepoTree(ctx context.Context, repo string, ref string) (modes map[string]string, truncated bool, err error)
+	// CreateBranch creates branch from the head of baseBranch, replacing it if it already exists
+	CreateBranch(ctx context.Context, repo string, branch string, baseBranch string) error
+	// CommitFiles commits the files and deletions in commit to the head of branch, returning the
+	// new commit's SHA if the platform reports it
+	CommitFiles(ctx context.Context, repo string, branch string, commit ProviderCommit) (string, error)
+	// OpenPullRequest opens a pull request from head to base
+	OpenPullRequest(ctx context.Context, repo string, head string, base string, title string, body string) (ProviderPullRequest, error)
+	// LabelPullRequest labels a pull request. Failures are only logged.
+	LabelPullRequest(ctx context.Context, repo string, number int, label string)
+	// RequestReviewers requests reviews on a pull request from users and the teams with the slugs in
+	// teams. Failures are only logged.
+	RequestReviewers(ctx context.Context, repo string, number int, users []string, teams []string)
+	// MergePullRequest merges a pull request, returning the merge commit's SHA, or an error if it
+	// can't be merged
+	MergePullRequest(ctx context.Context, repo string, number int) (string, error)
+	// DeleteBranch deletes branch if it exists. Failures are only logged.
+	DeleteBranch(ctx context.Context, repo string, branch string)
+	// ListBranches lists the branches whose names start with prefix, with when each was last committed to
+	ListBranches(ctx context.Context, repo string, prefix string) ([]ProviderBranch, error)
+}
+
+// ProviderCommit is a commit to make with a RepoProvider
+type ProviderCommit struct {
+	Files       map[string]string // file content keyed by path
+	FileModes   map[string]string // Git mode for non-regular files, keyed by path
+	DeletePaths []string          // removed unless the same path is in Files
+	Message     string
+	Author      *github.CommitAuthor // nil for the authenticated account
+}
+
+// ProviderBranch is a branch listed with a RepoProvider
+type ProviderBranch struct {
+	Name        string
+	CommittedAt time.Time // when the commit at the branch's head was made
+}
+
+// ProviderPullRequest is a pull request opened with a RepoProvider
+type ProviderPullRequest struct {
+	Number int
+	URL    string
+}
+
+// repoProviderFor returns the provider for a destination repo, and the repo's path on the provider's
+// platform. Repos prefixed with "bitbucket:" are on Bitbucket Cloud; all others are on GitHub.
+func repoProviderFor(repoName string) (RepoProvider, string, error) {
+	platform, repo := SplitDestinationRepo(repoName)
+	if platform == SourcePlatformBitbucket {
+		return GetBitbucketClient(), repo, nil
+	}
+
+	owner, _ := parseRepoPath(repo)
+	client, err := GetRestClientForOrg(owner)
+	if err != nil {
+		return nil, "", fmt.Errorf("get GitHub client for org %s: %w", owner, err)
+	}
+	return &githubProvider{client: client}, repo, nil
+}
+
+// githubProvider is the RepoProvider for GitHub repos. It writes through the Git data API so a
+// commit can write many files at once.
+type githubProvider struct {
+	client *github.Client
+}
+
+func (p *githubProvider) GetFile(ctx context.Context, repo string, filePath string, ref string) (string, bool, error) {
+	owner, name := parseRepoPath(repo)
+	file, _, resp, err := p.client.Repositories.GetContents(ctx, owner, name, filePath,
+		&github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	content, err := file.GetContent()
+	return content, true, err
+}
+
+func (p *githubProvider) GetRepoTree(ctx context.Context, repo string, ref string) (map[string]string, bool, error) {
+	owner, name := parseRepoPath(repo)
+	return GetRepoTree(ctx, p.client, owner, name, ref)
+}
+
+func (p *githubProvider) CreateBranch(ctx context.Context, repo string, branch string, baseBranch string) error {
+	_, err := createBranch(ctx, p.client, repo, branch, baseBranch)
+	return err
+}
+
+func (p *githubProvider) CommitFiles(ctx context.Context, repo string, branch string, commit ProviderCommit) (string, error) {
+	key := UploadKey{RepoName: repo, BranchPath: "refs/heads/" + branch}
+	treeSHA, baseSHA, err := createCommitTree(ctx, p.client, key, commit.Files, commit.FileModes, commit.DeletePaths)
+	if err != nil {
+		return "", fmt.Errorf("create tree: %w", err)
+	}
+	sha, err := createCommit(ctx, p.client, key, baseSHA, treeSHA, commit.Message, commit.Author)
+	if err != nil {
+		return "", fmt.Errorf("create commit: %w", err)
+	}
+	return sha, nil
+}
+
+func (p *githubProvider) OpenPullRequest(ctx context.Context, repo string, head string, base string, title string, body string) (ProviderPullRequest, error) {
+	pr, err := createPullRequest(ctx, p.client, repo, head, base, title, body)
+	if err != nil {
+		return ProviderPullRequest{}, err
+	}
+	return ProviderPullRequest{Number: pr.GetNumber(), URL: pr.GetHTMLURL()}, nil
+}
+
+func (p *githubProvider) LabelPullRequest(ctx context.Context, repo string, number int, label string) {
+	addCopierLabel(ctx, p.client, repo, number, label)
+}
+
+func (p *githubProvider) RequestReviewers(ctx context.Context, repo string, number int, users []string, teams []string) {
+	owner, name := parseRepoPath(repo)
+	request := github.ReviewersRequest{Reviewers: users, TeamReviewers: teams}
+	if _, _, err := p.client.PullRequests.RequestReviewers(ctx, owner, name, number, request); err != nil {
+		LogWarningCtx(ctx, fmt.Sprintf("Failed to request reviewers on PR #%d in %s: %v", number, repo, err), map[string]interface{}{
+			"users": users,
+			"teams": teams,
+		})
+		return
+	}
+	LogInfoCtx(ctx, "Requested reviewers", map[string]interface{}{
+		"target_repo": repo,
+		"pr_number":   number,
+		"users":       users,
+		"teams":       teams,
+	})
+}
+
+// MergePullRequest polls the pull request until GitHub has computed whether it's mergeable, then
+// merges it. Pull requests with conflicts are left open.
+func (p *githubProvider) MergePullRequest(ctx context.Context, repo string, number int) (string, error) {
+	// Get polling configuration from environment or use defaults
+	cfg := configs.NewConfig()
+	maxAttempts := cfg.PRMergePollMaxAttempts
+	if envAttempts := os.Getenv(configs.PRMergePollMaxAttempts); envAttempts != "" {
+		if parsed, err := parseIntWithDefault(envAttempts, maxAttempts); err == nil {
+			maxAttempts = parsed
+		}
+	}
+
+	pollInterval := cfg.PRMergePollInterval
+	if envInterval := os.Getenv(configs.PRMergePollInterval); envInterval != "" {
+		if parsed, err := parseIntWithDefault(envInterval, pollInterval); err == nil {
+			pollInterval = parsed
+		}
+	}
+
+	var mergeable *bool
+	var mergeableState string
+	owner, repoName := parseRepoPath(repo)
+	for i := 0; i < maxAttempts; i++ {
+		current, _, gerr := p.client.PullRequests.Get(ctx, owner, repoName, number)
+		if gerr == nil && current != nil {
+			mergeable = current.Mergeable
+			mergeableState = current.GetMergeableState()
+			if mergeable != nil { // computed
+				break
+			}
+		}
+		time.Sleep(time.Duration(pollInterval) * time.Millisecond)
+	}
+	if mergeable != nil && !*mergeable || strings.EqualFold(mergeableState, "dirty") {
+		LogWarningCtx(ctx, "PR is not mergeable. Likely merge conflicts.", map[string]interface{}{
+			"target_repo":     repo,
+			"pr_number":       number,
+			"mergeable_state": mergeableState,
+		})
+		return "", fmt.Errorf("pull request #%d has %w (state=%s)", number, ErrMergeConflict, mergeableState)
+	}
+	sha, err := mergePR(ctx, p.client, repo, number)
+	if err != nil {
+		return "", fmt.Errorf("merge PR: %w", err)
+	}
+	return sha, nil
+}
+
+func (p *githubProvider) DeleteBranch(ctx context.Context, repo string, branch string) {
+	deleteBranchIfExists(ctx, p.client, repo, &github.Reference{Ref: github.String("refs/heads/" + branch)})
+}
+
+// OverwriteBranch builds a commit of commit's files whose parent is the head of baseBranch, then
+// force-updates branch to it. Unlike CreateBranch, the branch isn't deleted first, so pull requests from it
+// stay open, and since the branch only moves once, its head is never already in baseBranch.
+func (p *githubProvider) OverwriteBranch(ctx context.Context, repo string, branch string, baseBranch string, commit ProviderCommit) (string, error) {
+	owner, name := parseRepoPath(repo)
+	base := UploadKey{RepoName: repo, BranchPath: "refs/heads/" + baseBranch}
+	treeSHA, baseSHA, err := createCommitTree(ctx, p.client, base, commit.Files, commit.FileModes, commit.DeletePaths)
+	if err != nil {
+		return "", fmt.Errorf("create tree on %s: %w", baseBranch, err)
+	}
+	newCommit, _, err := p.client.Git.CreateCommit(ctx, owner, name, &github.Commit{
+		Message: github.String(commit.Message),
+		Tree:    &github.Tree{SHA: github.String(treeSHA)},
+		Parents: []*github.Commit{{SHA: github.String(baseSHA)}},
+		Author:  commit.Author,
+	})
+	if err != nil {
+		return "", fmt.Errorf("create commit: %w", err)
+	}
+	ref := &github.Reference{Ref: github.String("refs/heads/" + branch), Object: &github.GitObject{SHA: newCommit.SHA}}
+	if _, _, err := p.client.Git.UpdateRef(ctx, owner, name, ref, true); err != nil {
+		return "", fmt.Errorf("move %s to the new commit: %w", branch, err)
+	}
+	return newCommit.GetSHA(), nil
+}
+
+func (p *githubProvider) ClosePullRequest(ctx context.Context, repo string, number int) error {
+	owner, name := parseRepoPath(repo)
+	_, _, err := p.client.PullRequests.Edit(ctx, owner, name, number, &github.PullRequest{State: github.String("closed")})
+	return err
+}
+
+func (p *githubProvider) ListBranches(ctx context.Context, repo string, prefix string) ([]ProviderBranch, error) {
+	owner, name := parseRepoPath(repo)
+	opts := &github.ReferenceListOptions{Ref: "heads/" + prefix, ListOptions: github.ListOptions{PerPage: 100}}
+	var branches []ProviderBranch
+	for {
+		refs, resp, err := p.client.Git.ListMatchingRefs(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list branches: %w", err)
+		}
+		for _, ref := range refs {
+			commit, _, err := p.client.Git.GetCommit(ctx, owner, name, ref.GetObject().GetSHA())
+			if err != nil {
+				return nil, fmt.Errorf("get commit for %s: %w", ref.GetRef(), err)
+			}
+			branches = append(branches, ProviderBranch{
+				Name:        strings.TrimPrefix(ref.GetRef(), "refs/heads/"),
+				CommittedAt: commit.GetCommitter().GetDate(),
+			})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			return branches, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,376 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// retryPollInterval is how often the retry queue checks for uploads that are due
+const retryPollInterval = 15 * time.Second
+
+// retryLease is how long an instance holds a job it claimed to retry. If the instance stops before
+// finishing, the job becomes due again when the lease ends.
+const retryLease = 15 * time.Minute
+
+// RetryJob is an upload that failed with a transient GitHub error and is waiting to be retried
+type RetryJob struct {
+	ID            string                  `bson:"_id" json:"id"`
+	Key           types.UploadKey         `bson:"key" json:"key"`
+	Content       types.UploadFileContent `bson:"content" json:"content"`
+	SourceRepo    string                  `bson:"source_repo" json:"source_repo"`
+	PRNumber      int                     `bson:"pr_number" json:"pr_number"`
+	CommitSHA     string                  `bson:"commit_sha" json:"commit_sha"`
+	Attempts      int                     `bson:"attempts" json:"attempts"` // Failed attempts, including the original upload
+	LastError     string                  `bson:"last_error" json:"last_error"`
+	FirstFailedAt time.Time               `bson:"first_failed_at" json:"first_failed_at"`
+	NextAttempt   time.Time               `bson:"next_attempt" json:"next_attempt"`
+	// CorrelationID is the correlation ID of the webhook delivery whose upload failed
+	CorrelationID string `bson:"correlation_id,omitempty" json:"correlation_id,omitempty"`
+	// LeaseOwner is the instance retrying the job, until LeaseUntil
+	LeaseOwner string    `bson:"lease_owner,omitempty" json:"lease_owner,omitempty"`
+	LeaseUntil time.Time `bson:"lease_until,omitempty" json:"lease_until,omitempty"`
+}
+
+// RetryQueue retries uploads that failed with transient GitHub errors (5xx responses, rate limits, and
+// network errors) with exponential backoff. An upload that still fails after the maximum number of
+// retries, or then fails with an error that isn't transient, is dead-lettered: removed from the queue,
+// recorded in the audit log, and reported to Slack.
+type RetryQueue struct {
+	store        RetryStore
+	maxRetries   int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	auditLogger  AuditLogger
+	notifier     SlackNotifier
+	metrics      *MetricsCollector
+	writeLog     *WriteLog
+	upload       func(ctx context.Context, key types.UploadKey, content types.UploadFileContent) UploadResult
+	now          func() time.Time
+	owner        string     // Claims jobs for this instance
+	mu           sync.Mutex // Held while processing; jobs are claimed in the store so other instances skip them
+}
+
+// NewRetryQueue creates a retry queue that keeps jobs in store and retries them with uploadToTarget.
+// Retries that succeed are recorded in writeLog, which may be nil.
+func NewRetryQueue(store RetryStore, config *configs.Config, auditLogger AuditLogger, notifier SlackNotifier,
+	metrics *MetricsCollector, prTemplateFetcher PRTemplateFetcher, writeLog *WriteLog) *RetryQueue {
+
+	return &RetryQueue{
+		store:        store,
+		maxRetries:   config.UploadRetryMaxAttempts,
+		initialDelay: time.Duration(config.UploadRetryInitialDelay) * time.Second,
+		maxDelay:     time.Duration(config.UploadRetryMaxDelay) * time.Second,
+		auditLogger:  auditLogger,
+		notifier:     notifier,
+		metrics:      metrics,
+		writeLog:     writeLog,
+		upload: func(ctx context.Context, key types.UploadKey, content types.UploadFileContent) UploadResult {
+			return uploadToTarget(ctx, key, content, prTemplateFetcher)
+		},
+		now:   time.Now,
+		owner: instanceID,
+	}
+}
+
+// Enabled returns true if failed uploads are retried
+func (q *RetryQueue) Enabled() bool {
+	return q != nil && q.maxRetries > 0
+}
+
+// EnqueueFailed queues the failed uploads in results for retry, if they failed with a transient error.
+// queued holds the content that was uploaded for each key. Returns the keys that were queued.
+func (q *RetryQueue) EnqueueFailed(ctx context.Context, change CopyEvent,
+	queued map[types.UploadKey]types.UploadFileContent, results map[types.UploadKey]UploadResult) map[types.UploadKey]bool {
+
+	if !q.Enabled() {
+		return nil
+	}
+
+	retrying := make(map[types.UploadKey]bool)
+	now := q.now()
+	for key, result := range results {
+		if result.Err == nil || !shouldRetryUpload(result) {
+			continue
+		}
+		job := &RetryJob{
+			ID:            fmt.Sprintf("%s@%s#%d-%d", key.RepoName, key.BranchPath, change.Number, now.UnixNano()),
+			Key:           key,
+			Content:       queued[key],
+			SourceRepo:    change.Repo,
+			PRNumber:      change.Number,
+			CommitSHA:     change.CommitSHA,
+			Attempts:      1,
+			LastError:     result.Err.Error(),
+			FirstFailedAt: now,
+			NextAttempt:   now.Add(q.backoff(1, result.Err)),
+			CorrelationID: CorrelationIDFromContext(ctx),
+		}
+		if err := q.store.Save(ctx, job); err != nil {
+			LogErrorCtx(ctx, "failed to queue upload for retry", err, map[string]interface{}{
+				"target_repo":   key.RepoName,
+				"target_branch": key.BranchPath,
+			})
+			continue
+		}
+		retrying[key] = true
+		LogWarningCtx(ctx, "upload failed with a transient error; queued for retry", map[string]interface{}{
+			"target_repo":   key.RepoName,
+			"target_branch": key.BranchPath,
+			"pr_number":     change.Number,
+			"next_attempt":  job.NextAttempt,
+			"error":         job.LastError,
+		})
+	}
+	q.updateSize(ctx)
+	return retrying
+}
+
+// ProcessDue claims and retries each job whose next attempt is due. Jobs that succeed are removed; jobs
+// that fail are rescheduled with a longer delay, or dead-lettered. Jobs another instance has claimed are
+// skipped. Returns the number of jobs attempted.
+func (q *RetryQueue) ProcessDue(ctx context.Context) int {
+	if !q.Enabled() {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	attempted := 0
+	seen := make(map[string]bool)
+	for ctx.Err() == nil {
+		job, err := q.store.Claim(ctx, q.owner, q.now(), retryLease)
+		if err != nil {
+			LogErrorCtx(ctx, "failed to claim job from upload retry queue", err, nil)
+			break
+		}
+		if job == nil {
+			break
+		}
+		if seen[job.ID] {
+			// Rescheduled without a delay in this pass; leave it for the next one
+			job.NextAttempt = q.now()
+			if err := q.store.Release(ctx, job, q.owner); err != nil {
+				LogErrorCtx(ctx, "failed to release upload retry", err, map[string]interface{}{"job_id": job.ID})
+			}
+			break
+		}
+		seen[job.ID] = true
+		attempted++
+
+		jobCtx := WithCorrelationID(ctx, job.CorrelationID)
+		result := q.upload(jobCtx, job.Key, job.Content)
+		if result.Err == nil {
+			if err := q.store.Delete(ctx, job.ID, q.owner); err != nil {
+				LogErrorCtx(jobCtx, "failed to remove job from upload retry queue", err, map[string]interface{}{"job_id": job.ID})
+			}
+			LogInfoCtx(jobCtx, "retried upload succeeded", map[string]interface{}{
+				"target_repo":   job.Key.RepoName,
+				"target_branch": job.Key.BranchPath,
+				"pr_number":     job.PRNumber,
+				"attempts":      job.Attempts + 1,
+				"pr_url":        result.PRURL,
+			})
+			q.writeLog.Record(jobCtx, CopyEvent{Repo: job.SourceRepo, Number: job.PRNumber, CommitSHA: job.CommitSHA},
+				nil, job.Key, job.Content, result)
+			continue
+		}
+
+		job.Attempts++
+		job.LastError = result.Err.Error()
+		if !shouldRetryUpload(result) || job.Attempts > q.maxRetries {
+			q.deadLetter(jobCtx, job, result.Err)
+			continue
+		}
+		job.NextAttempt = q.now().Add(q.backoff(job.Attempts, result.Err))
+		if err := q.store.Release(ctx, job, q.owner); err != nil {
+			LogErrorCtx(jobCtx, "failed to reschedule upload retry", err, map[string]interface{}{"job_id": job.ID})
+			continue
+		}
+		LogWarningCtx(jobCtx, "retried upload failed; rescheduled", map[string]interface{}{
+			"target_repo":   job.Key.RepoName,
+			"target_branch": job.Key.BranchPath,
+			"pr_number":     job.PRNumber,
+			"attempts":      job.Attempts,
+			"next_attempt":  job.NextAttempt,
+			"error":         job.LastError,
+		})
+	}
+	q.updateSize(ctx)
+	return attempted
+}
+
+// Run processes due jobs every retryPollInterval until ctx is cancelled
+func (q *RetryQueue) Run(ctx context.Context) {
+	if !q.Enabled() {
+		return
+	}
+	ticker := time.NewTicker(retryPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.ProcessDue(ctx)
+		}
+	}
+}
+
+// Close closes the store. Jobs in a store that doesn't persist them would be lost, so they're
+// dead-lettered first, which records them in the audit log for replay.
+func (q *RetryQueue) Close(ctx context.Context) error {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.store.Persistent() {
+		jobs, err := q.store.List(ctx)
+		if err != nil {
+			LogErrorCtx(ctx, "failed to list upload retry queue", err, nil)
+		}
+		for _, job := range jobs {
+			q.deadLetter(ctx, job, fmt.Errorf("shutdown before retry; last error: %s", job.LastError))
+		}
+	}
+	return q.store.Close(ctx)
+}
+
+// deadLetter removes a job that won't be retried again and records it in the audit log and Slack. The job
+// is one this instance claimed, or, at shutdown, one no one has.
+func (q *RetryQueue) deadLetter(ctx context.Context, job *RetryJob, err error) {
+	if deleteErr := q.store.Delete(ctx, job.ID, job.LeaseOwner); deleteErr != nil {
+		LogErrorCtx(ctx, "failed to remove job from upload retry queue", deleteErr, map[string]interface{}{"job_id": job.ID})
+	}
+
+	files := make([]string, 0, len(job.Content.Content))
+	for _, file := range job.Content.Content {
+		files = append(files, file.GetName())
+	}
+
+	LogErrorCtx(ctx, "upload dead-lettered", err, map[string]interface{}{
+		"target_repo":   job.Key.RepoName,
+		"target_branch": job.Key.BranchPath,
+		"pr_number":     job.PRNumber,
+		"attempts":      job.Attempts,
+		"file_count":    len(files),
+	})
+
+	if q.auditLogger != nil {
+		if auditErr := q.auditLogger.LogErrorEvent(ctx, &AuditEvent{
+			SourceRepo:   job.SourceRepo,
+			TargetRepo:   job.Key.RepoName,
+			CommitSHA:    job.CommitSHA,
+			PRNumber:     job.PRNumber,
+			ErrorMessage: err.Error(),
+			AdditionalData: map[string]any{
+				"dead_letter":     true,
+				"attempts":        job.Attempts,
+				"target_branch":   job.Key.BranchPath,
+				"commit_strategy": string(job.Content.CommitStrategy),
+				"files":           files,
+				"first_failed_at": job.FirstFailedAt,
+			},
+		}); auditErr != nil {
+			LogWarning(fmt.Sprintf("Failed to record dead-lettered upload for PR #%d in audit log: %v", job.PRNumber, auditErr))
+		}
+	}
+
+	if q.metrics != nil {
+		for range files {
+			q.metrics.RecordFileUploadDeadLettered()
+		}
+	}
+
+	if q.notifier != nil {
+		q.notifier.NotifyError(ctx, &ErrorEvent{
+			Operation:  "upload_retry",
+			Error:      err,
+			PRNumber:   job.PRNumber,
+			SourceRepo: job.SourceRepo,
+			AdditionalInfo: map[string]interface{}{
+				"target_repo":   job.Key.RepoName,
+				"target_branch": job.Key.BranchPath,
+				"attempts":      job.Attempts,
+				"file_count":    len(files),
+			},
+		})
+	}
+}
+
+// backoff returns the delay before the next attempt after the given number of failed attempts. The delay
+// doubles with each attempt up to maxDelay, but is never shorter than the wait a rate limit error asks for.
+func (q *RetryQueue) backoff(attempts int, err error) time.Duration {
+	delay := q.initialDelay
+	for i := 1; i < attempts && delay < q.maxDelay; i++ {
+		delay *= 2
+	}
+	if q.maxDelay > 0 && delay > q.maxDelay {
+		delay = q.maxDelay
+	}
+
+	var rateLimitErr *github.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		if wait := rateLimitErr.Rate.Reset.Time.Sub(q.now()); wait > delay {
+			delay = wait
+		}
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) && abuseErr.RetryAfter != nil && *abuseErr.RetryAfter > delay {
+		delay = *abuseErr.RetryAfter
+	}
+	return delay
+}
+
+// updateSize records the number of queued jobs in the metrics collector
+func (q *RetryQueue) updateSize(ctx context.Context) {
+	if q.metrics == nil {
+		return
+	}
+	jobs, err := q.store.List(ctx)
+	if err != nil {
+		return
+	}
+	q.metrics.SetRetryQueueSize(len(jobs))
+}
+
+// shouldRetryUpload returns true if a failed upload can be retried. An upload that opened a pull request
+// before failing (for example, because the PR couldn't be merged) isn't retried, since a retry would
+// open a second PR.
+func shouldRetryUpload(result UploadResult) bool {
+	return result.PRURL == "" && isTransientGitHubError(result.Err)
+}
+
+// isTransientGitHubError returns true for errors that are likely to succeed on a later attempt:
+// rate limits, 5xx responses from GitHub or Bitbucket, and network errors
+func isTransientGitHubError(err error) bool {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseErr) {
+		return true
+	}
+
+	var respErr *github.ErrorResponse
+	if errors.As(err, &respErr) && respErr.Response != nil {
+		code := respErr.Response.StatusCode
+		return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+	var bitbucketErr *BitbucketError
+	if errors.As(err, &bitbucketErr) {
+		return bitbucketErr.StatusCode == http.StatusTooManyRequests || bitbucketErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditLogger records the error events it's given
+type recordingAuditLogger struct {
+	NoOpAuditLogger
+	errors []*AuditEvent
+}
+
+func (l *recordingAuditLogger) LogErrorEvent(ctx context.Context, event *AuditEvent) error {
+	l.errors = append(l.errors, event)
+	return nil
+}
+
+// githubError returns the error go-github returns for a response with the given status code
+func githubError(statusCode int) error {
+	return &github.ErrorResponse{
+		Response: &http.Response{
+			StatusCode: statusCode,
+			Request:    httptest.NewRequest(http.MethodPost, "https://api.github.com/repos/org/dest/git/trees", nil),
+		},
+		Message: http.StatusText(statusCode),
+	}
+}
+
+// newTestRetryQueue returns a queue with a fake clock and an upload function that returns each of
+// results in turn, then succeeds
+func newTestRetryQueue(maxRetries int, results ...UploadResult) (*RetryQueue, *time.Time, *recordingAuditLogger, *int) {
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	audit := &recordingAuditLogger{}
+	uploads := 0
+	config := &configs.Config{UploadRetryMaxAttempts: maxRetries, UploadRetryInitialDelay: 60, UploadRetryMaxDelay: 600}
+	q := NewRetryQueue(NewMemoryRetryStore(), config, audit, nil, NewMetricsCollector(), nil, nil)
+	q.now = func() time.Time { return clock }
+	q.upload = func(ctx context.Context, key types.UploadKey, content types.UploadFileContent) UploadResult {
+		uploads++
+		if uploads <= len(results) {
+			return results[uploads-1]
+		}
+		return UploadResult{}
+	}
+	return q, &clock, audit, &uploads
+}
+
+func failedUpload(ctx context.Context, q *RetryQueue, err error) map[types.UploadKey]bool {
+	key := types.UploadKey{RepoName: "org/dest", BranchPath: "main"}
+	queued := map[types.UploadKey]types.UploadFileContent{
+		key: {Content: []github.RepositoryContent{{Name: github.String("code/a.py")}}},
+	}
+	results := map[types.UploadKey]UploadResult{key: {Err: err}}
+	return q.EnqueueFailed(ctx, CopyEvent{Repo: "org/src", Number: 42, CommitSHA: "abc123"}, queued, results)
+}
+
+func TestIsTransientGitHubError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"bad gateway", githubError(http.StatusBadGateway), true},
+		{"too many requests", githubError(http.StatusTooManyRequests), true},
+		{"wrapped server error", fmt.Errorf("create tree: %w", githubError(http.StatusServiceUnavailable)), true},
+		{"rate limit", &github.RateLimitError{}, true},
+		{"secondary rate limit", &github.AbuseRateLimitError{}, true},
+		{"network error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"not found", githubError(http.StatusNotFound), false},
+		{"conflict", githubError(http.StatusUnprocessableEntity), false},
+		{"other error", errors.New("pull request #7 has merge conflicts"), false},
+		{"bitbucket server error", fmt.Errorf("could not create commit: %w", &BitbucketError{StatusCode: http.StatusBadGateway}), true},
+		{"bitbucket not found", &BitbucketError{StatusCode: http.StatusNotFound}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isTransientGitHubError(tt.err))
+		})
+	}
+}
+
+func TestRetryQueue_Backoff(t *testing.T) {
+	q, clock, _, _ := newTestRetryQueue(5)
+	err := githubError(http.StatusBadGateway)
+
+	assert.Equal(t, time.Minute, q.backoff(1, err))
+	assert.Equal(t, 2*time.Minute, q.backoff(2, err))
+	assert.Equal(t, 8*time.Minute, q.backoff(4, err))
+	assert.Equal(t, 10*time.Minute, q.backoff(5, err), "capped at the max delay")
+
+	rateLimited := &github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: clock.Add(30 * time.Minute)}}}
+	assert.Equal(t, 30*time.Minute, q.backoff(1, rateLimited), "waits for the rate limit to reset")
+}
+
+func TestRetryQueue_RetriesUntilSuccess(t *testing.T) {
+	ctx := context.Background()
+	q, clock, audit, uploads := newTestRetryQueue(3, UploadResult{Err: githubError(http.StatusBadGateway)})
+
+	retrying := failedUpload(ctx, q, githubError(http.StatusBadGateway))
+	assert.True(t, retrying[types.UploadKey{RepoName: "org/dest", BranchPath: "main"}])
+
+	// Not due yet
+	assert.Equal(t, 0, q.ProcessDue(ctx))
+
+	// First retry fails and is rescheduled with a longer delay
+	*clock = clock.Add(time.Minute)
+	assert.Equal(t, 1, q.ProcessDue(ctx))
+	jobs, _ := q.store.List(ctx)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, 2, jobs[0].Attempts)
+	assert.Equal(t, clock.Add(2*time.Minute), jobs[0].NextAttempt)
+
+	// Second retry succeeds
+	*clock = clock.Add(2 * time.Minute)
+	assert.Equal(t, 1, q.ProcessDue(ctx))
+	jobs, _ = q.store.List(ctx)
+	assert.Empty(t, jobs)
+	assert.Equal(t, 2, *uploads)
+	assert.Empty(t, audit.errors)
+	assert.Equal(t, 0, q.metrics.GetMetrics(NewFileStateService()).Queues.RetryQueueSize)
+}
+
+func TestRetryQueue_KeepsCorrelationID(t *testing.T) {
+	q, clock, _, _ := newTestRetryQueue(3)
+	var retriedWith string
+	q.upload = func(ctx context.Context, key types.UploadKey, content types.UploadFileContent) UploadResult {
+		retriedWith = CorrelationIDFromContext(ctx)
+		return UploadResult{}
+	}
+
+	failedUpload(WithCorrelationID(context.Background(), "delivery-1"), q, githubError(http.StatusBadGateway))
+	*clock = clock.Add(time.Minute)
+	assert.Equal(t, 1, q.ProcessDue(context.Background()))
+	assert.Equal(t, "delivery-1", retriedWith)
+}
+
+func TestRetryQueue_DeadLetter(t *testing.T) {
+	ctx := context.Background()
+	err := githubError(http.StatusBadGateway)
+	q, clock, audit, _ := newTestRetryQueue(2, UploadResult{Err: err}, UploadResult{Err: err})
+
+	failedUpload(ctx, q, err)
+	for i := 0; i < 2; i++ {
+		*clock = clock.Add(time.Hour)
+		q.ProcessDue(ctx)
+	}
+
+	jobs, _ := q.store.List(ctx)
+	assert.Empty(t, jobs)
+	require.Len(t, audit.errors, 1)
+	event := audit.errors[0]
+	assert.Equal(t, "org/src", event.SourceRepo)
+	assert.Equal(t, "org/dest", event.TargetRepo)
+	assert.Equal(t, 42, event.PRNumber)
+	assert.Equal(t, true, event.AdditionalData["dead_letter"])
+	assert.Equal(t, 3, event.AdditionalData["attempts"])
+	assert.Equal(t, []string{"code/a.py"}, event.AdditionalData["files"])
+	assert.Equal(t, int64(1), q.metrics.GetMetrics(NewFileStateService()).Files.UploadDeadLettered)
+}
+
+func TestRetryQueue_DeadLettersPermanentErrorOnRetry(t *testing.T) {
+	ctx := context.Background()
+	q, clock, audit, _ := newTestRetryQueue(5, UploadResult{Err: githubError(http.StatusNotFound)})
+
+	failedUpload(ctx, q, githubError(http.StatusBadGateway))
+	*clock = clock.Add(time.Minute)
+	q.ProcessDue(ctx)
+
+	jobs, _ := q.store.List(ctx)
+	assert.Empty(t, jobs)
+	assert.Len(t, audit.errors, 1)
+}
+
+func TestRetryQueue_EnqueueFailed_SkipsNonRetryable(t *testing.T) {
+	ctx := context.Background()
+
+	q, _, _, _ := newTestRetryQueue(5)
+	assert.Empty(t, failedUpload(ctx, q, githubError(http.StatusNotFound)), "permanent errors aren't retried")
+
+	key := types.UploadKey{RepoName: "org/dest", BranchPath: "main"}
+	opened := map[types.UploadKey]UploadResult{
+		key: {PRURL: "https://github.com/org/dest/pull/7", Err: githubError(http.StatusBadGateway)},
+	}
+	assert.Empty(t, q.EnqueueFailed(ctx, CopyEvent{}, nil, opened), "uploads that opened a PR aren't retried")
+
+	disabled, _, _, _ := newTestRetryQueue(0)
+	assert.Empty(t, failedUpload(ctx, disabled, githubError(http.StatusBadGateway)))
+
+	var missing *RetryQueue
+	assert.Empty(t, failedUpload(ctx, missing, githubError(http.StatusBadGateway)))
+}
+
+func TestRetryQueue_CloseDeadLettersInMemoryJobs(t *testing.T) {
+	ctx := context.Background()
+	q, _, audit, _ := newTestRetryQueue(5)
+
+	failedUpload(ctx, q, githubError(http.StatusBadGateway))
+	require.NoError(t, q.Close(ctx))
+
+	require.Len(t, audit.errors, 1)
+	assert.Contains(t, audit.errors[0].ErrorMessage, "shutdown before retry")
+}
+
+func TestRetryQueue_InstancesSharingStoreRetryJobOnce(t *testing.T) {
+	ctx := context.Background()
+	q, clock, _, uploads := newTestRetryQueue(3)
+	other, _, _, otherUploads := newTestRetryQueue(3)
+	other.store = q.store
+	other.now = q.now
+	other.owner = "other-instance"
+
+	failedUpload(ctx, q, githubError(http.StatusBadGateway))
+	*clock = clock.Add(time.Minute)
+
+	// The first instance's claim hides the job from the second until its lease ends
+	job, err := q.store.Claim(ctx, q.owner, q.now(), retryLease)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, 0, other.ProcessDue(ctx))
+	assert.Equal(t, 0, *otherUploads)
+
+	// Only the claiming instance can remove it
+	require.NoError(t, q.store.Delete(ctx, job.ID, other.owner))
+	jobs, _ := q.store.List(ctx)
+	require.Len(t, jobs, 1)
+	assert.ErrorIs(t, q.store.Release(ctx, job, other.owner), errRetryLeaseLost)
+
+	// Once the lease ends, the job is due again and either instance can claim it
+	*clock = clock.Add(retryLease)
+	assert.Equal(t, 1, other.ProcessDue(ctx))
+	assert.Equal(t, 0, q.ProcessDue(ctx))
+	assert.Equal(t, 1, *otherUploads)
+	assert.Equal(t, 0, *uploads)
+	jobs, _ = q.store.List(ctx)
+	assert.Empty(t, jobs)
+}
+
+func TestMemoryRetryStore_ClaimEarliestDueJob(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRetryStore()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Save(ctx, &RetryJob{ID: "later", NextAttempt: now.Add(-time.Minute)}))
+	require.NoError(t, store.Save(ctx, &RetryJob{ID: "earlier", NextAttempt: now.Add(-time.Hour)}))
+	require.NoError(t, store.Save(ctx, &RetryJob{ID: "future", NextAttempt: now.Add(time.Hour)}))
+
+	first, err := store.Claim(ctx, "a", now, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "earlier", first.ID)
+	assert.Equal(t, "a", first.LeaseOwner)
+	assert.Equal(t, now.Add(time.Minute), first.NextAttempt)
+
+	second, _ := store.Claim(ctx, "b", now, time.Minute)
+	assert.Equal(t, "later", second.ID)
+
+	none, err := store.Claim(ctx, "c", now, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	// Releasing clears the lease and keeps the job's new schedule
+	first.NextAttempt = now
+	require.NoError(t, store.Release(ctx, first, "a"))
+	again, _ := store.Claim(ctx, "c", now, time.Minute)
+	assert.Equal(t, "earlier", again.ID)
+	assert.Equal(t, "c", again.LeaseOwner)
+}
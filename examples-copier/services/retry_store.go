@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RetryStore holds the uploads waiting in the retry queue. Instances that share a store claim a job before
+// retrying it, so only one of them retries each job.
+type RetryStore interface {
+	Save(ctx context.Context, job *RetryJob) error // Adds the job, or replaces the job with the same ID
+	// Claim leases the due job with the earliest next attempt to owner until now+lease, and moves its next
+	// attempt to the end of the lease so no one else claims it meanwhile. Returns nil if no job is due.
+	Claim(ctx context.Context, owner string, now time.Time, lease time.Duration) (*RetryJob, error)
+	// Release saves a claimed job, clearing its lease, if owner still holds the lease
+	Release(ctx context.Context, job *RetryJob, owner string) error
+	// Delete removes a job if owner holds its lease. An empty owner removes a job no one has claimed.
+	Delete(ctx context.Context, id string, owner string) error
+	List(ctx context.Context) ([]*RetryJob, error) // Returns jobs in order of their next attempt
+	Persistent() bool                              // Whether jobs survive a restart
+	Close(ctx context.Context) error
+}
+
+// errRetryLeaseLost is returned when releasing a job whose lease another owner now holds, or that's gone
+var errRetryLeaseLost = errors.New("retry job is no longer leased to this instance")
+
+// MemoryRetryStore implements RetryStore in memory. Jobs are lost when the process exits.
+type MemoryRetryStore struct {
+	mu   sync.Mutex
+	jobs map[string]*RetryJob
+}
+
+// NewMemoryRetryStore creates an empty in-memory retry store
+func NewMemoryRetryStore() *MemoryRetryStore {
+	return &MemoryRetryStore{jobs: make(map[string]*RetryJob)}
+}
+
+// Save adds or replaces a job
+func (s *MemoryRetryStore) Save(ctx context.Context, job *RetryJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *job
+	s.jobs[job.ID] = &saved
+	return nil
+}
+
+// Claim leases the due job with the earliest next attempt
+func (s *MemoryRetryStore) Claim(ctx context.Context, owner string, now time.Time, lease time.Duration) (*RetryJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due *RetryJob
+	for _, job := range s.jobs {
+		if !job.NextAttempt.After(now) && (due == nil || job.NextAttempt.Before(due.NextAttempt)) {
+			due = job
+		}
+	}
+	if due == nil {
+		return nil, nil
+	}
+	due.LeaseOwner = owner
+	due.LeaseUntil = now.Add(lease)
+	due.NextAttempt = due.LeaseUntil
+	claimed := *due
+	return &claimed, nil
+}
+
+// Release saves a claimed job with its lease cleared
+func (s *MemoryRetryStore) Release(ctx context.Context, job *RetryJob, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.jobs[job.ID]
+	if !ok || current.LeaseOwner != owner {
+		return errRetryLeaseLost
+	}
+	released := *job
+	released.LeaseOwner = ""
+	released.LeaseUntil = time.Time{}
+	s.jobs[job.ID] = &released
+	return nil
+}
+
+// Delete removes a job if owner holds its lease
+func (s *MemoryRetryStore) Delete(ctx context.Context, id string, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if job, ok := s.jobs[id]; ok && job.LeaseOwner == owner {
+		delete(s.jobs, id)
+	}
+	return nil
+}
+
+// List returns copies of all jobs, in order of their next attempt
+func (s *MemoryRetryStore) List(ctx context.Context) ([]*RetryJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]*RetryJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].NextAttempt.Before(jobs[j].NextAttempt)
+	})
+	return jobs, nil
+}
+
+// Persistent returns false: jobs are lost on restart
+func (s *MemoryRetryStore) Persistent() bool { return false }
+
+// Close does nothing for the in-memory store
+func (s *MemoryRetryStore) Close(ctx context.Context) error { return nil }
+
+// MongoRetryStore implements RetryStore using a MongoDB collection, so pending retries survive
+// restarts and deploys
+type MongoRetryStore struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewMongoRetryStore returns a store backed by the given collection, connecting the shared client if it
+// isn't yet
+func NewMongoRetryStore(ctx context.Context, mongoClient *SharedMongoClient, database, collection string) (*MongoRetryStore, error) {
+	client, err := mongoClient.Connect(ctx, "the upload retry store is mongodb")
+	if err != nil {
+		return nil, err
+	}
+
+	coll := client.Database(database).Collection(collection)
+	if _, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "next_attempt", Value: 1}},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return &MongoRetryStore{client: client, collection: coll}, nil
+}
+
+// Save upserts a job by ID
+func (s *MongoRetryStore) Save(ctx context.Context, job *RetryJob) error {
+	_, err := s.collection.ReplaceOne(ctx, bson.M{"_id": job.ID}, job, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Claim atomically leases the due job with the earliest next attempt, so instances sharing the collection
+// never retry the same job at once
+func (s *MongoRetryStore) Claim(ctx context.Context, owner string, now time.Time, lease time.Duration) (*RetryJob, error) {
+	until := now.Add(lease)
+	update := bson.M{"$set": bson.M{"lease_owner": owner, "lease_until": until, "next_attempt": until}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt", Value: 1}}).
+		SetReturnDocument(options.After)
+	var job RetryJob
+	err := s.collection.FindOneAndUpdate(ctx, bson.M{"next_attempt": bson.M{"$lte": now}}, update, opts).Decode(&job)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Release replaces a claimed job with its lease cleared, if owner still holds the lease
+func (s *MongoRetryStore) Release(ctx context.Context, job *RetryJob, owner string) error {
+	released := *job
+	released.LeaseOwner = ""
+	released.LeaseUntil = time.Time{}
+	result, err := s.collection.ReplaceOne(ctx, bson.M{"_id": job.ID, "lease_owner": owner}, &released)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errRetryLeaseLost
+	}
+	return nil
+}
+
+// Delete removes a job if owner holds its lease
+func (s *MongoRetryStore) Delete(ctx context.Context, id string, owner string) error {
+	filter := bson.M{"_id": id, "lease_owner": owner}
+	if owner == "" {
+		filter = bson.M{"_id": id, "lease_owner": bson.M{"$in": bson.A{nil, ""}}}
+	}
+	_, err := s.collection.DeleteOne(ctx, filter)
+	return err
+}
+
+// List returns all jobs, in order of their next attempt
+func (s *MongoRetryStore) List(ctx context.Context) ([]*RetryJob, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "next_attempt", Value: 1}})
+	cursor, err := s.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []*RetryJob
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Persistent returns true: jobs are kept in MongoDB across restarts
+func (s *MongoRetryStore) Persistent() bool { return true }
+
+// Ping checks the MongoDB connection
+func (s *MongoRetryStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx, nil)
+}
+
+// Close does nothing: the shared client is disconnected by its owner
+func (s *MongoRetryStore) Close(ctx context.Context) error { return nil }
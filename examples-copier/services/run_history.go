@@ -0,0 +1,372 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Outcomes of a webhook run
+const (
+	RunStatusSucceeded = "succeeded" // Every matching workflow copied its files without errors
+	RunStatusFailed    = "failed"    // Processing stopped early, or a workflow or upload had errors
+	RunStatusNoMatch   = "no_match"  // No workflow matched the source repo and branch
+	RunStatusRunning   = "running"   // Still being processed; only seen in history while in flight
+)
+
+// WebhookRun records what the copier did for one merged PR or MR, or a push
+type WebhookRun struct {
+	ID         string               `json:"id" bson:"_id"`
+	Platform   string               `json:"platform" bson:"platform"`
+	SourceRepo string               `json:"source_repo" bson:"source_repo"`
+	PRNumber   int                  `json:"pr_number" bson:"pr_number"`
+	PRURL      string               `json:"pr_url" bson:"pr_url"`
+	CommitSHA  string               `json:"commit_sha" bson:"commit_sha"`
+	BaseBranch string               `json:"base_branch" bson:"base_branch"`
+	Trigger    string               `json:"trigger" bson:"trigger"`
+	StartedAt  time.Time            `json:"started_at" bson:"started_at"`
+	FinishedAt time.Time            `json:"finished_at" bson:"finished_at"`
+	DurationMs int64                `json:"duration_ms" bson:"duration_ms"`
+	Status     string               `json:"status" bson:"status"`
+	Error      string               `json:"error,omitempty" bson:"error,omitempty"` // Why processing stopped before the workflows ran
+	Workflows  []WebhookRunWorkflow `json:"workflows" bson:"workflows"`
+}
+
+// WebhookRunWorkflow records what one matching workflow did during a webhook run
+type WebhookRunWorkflow struct {
+	Name         string   `json:"name" bson:"name"`
+	TargetRepo   string   `json:"target_repo" bson:"target_repo"`
+	TargetBranch string   `json:"target_branch" bson:"target_branch"`
+	Files        []string `json:"files" bson:"files"`
+	Deletions    []string `json:"deletions,omitempty" bson:"deletions,omitempty"`
+	TargetPRURL  string   `json:"target_pr_url,omitempty" bson:"target_pr_url,omitempty"`
+	DryRun       bool     `json:"dry_run,omitempty" bson:"dry_run,omitempty"`
+	Errors       []string `json:"errors,omitempty" bson:"errors,omitempty"`
+}
+
+// newWebhookRun starts the record of a run for a merged change
+func newWebhookRun(change CopyEvent, startedAt time.Time) *WebhookRun {
+	return &WebhookRun{
+		ID:         fmt.Sprintf("%s#%d-%d", change.Repo, change.Number, startedAt.UnixNano()),
+		Platform:   change.Platform,
+		SourceRepo: change.Repo,
+		PRNumber:   change.Number,
+		PRURL:      change.URL,
+		CommitSHA:  change.CommitSHA,
+		BaseBranch: change.BaseBranch,
+		Trigger:    change.trigger(),
+		StartedAt:  startedAt,
+		Status:     RunStatusRunning,
+		Workflows:  []WebhookRunWorkflow{},
+	}
+}
+
+// fail marks the run as failed before its workflows ran
+func (r *WebhookRun) fail(err error) {
+	r.Status = RunStatusFailed
+	r.Error = err.Error()
+}
+
+// addWorkflows records the outcome of each workflow, including the target PR and any upload error for
+// its destination, and sets the run's status
+func (r *WebhookRun) addWorkflows(runs []*workflowRun, uploads map[types.UploadKey]UploadResult) {
+	r.Status = RunStatusSucceeded
+	for _, run := range runs {
+		workflow := WebhookRunWorkflow{
+			Name:         run.Workflow.Name,
+			TargetRepo:   run.Workflow.Destination.Repo,
+			TargetBranch: run.Workflow.Destination.Branch,
+			Files:        run.Files,
+			Deletions:    run.Deletions,
+			DryRun:       run.DryRun != nil,
+		}
+		if workflow.Files == nil {
+			workflow.Files = []string{}
+		}
+		if run.DryRun != nil {
+			for _, target := range run.DryRun.Targets {
+				for _, file := range target.Files {
+					workflow.Files = append(workflow.Files, file)
+				}
+			}
+		}
+		if run.Err != nil {
+			workflow.Errors = append(workflow.Errors, errorMessages(run.Err)...)
+		}
+		if upload, ok := uploads[run.uploadKey()]; ok {
+			workflow.TargetPRURL = upload.PRURL
+			if upload.Err != nil {
+				workflow.Errors = append(workflow.Errors, "upload: "+upload.Err.Error())
+			}
+		}
+		if len(workflow.Errors) > 0 {
+			r.Status = RunStatusFailed
+		}
+		r.Workflows = append(r.Workflows, workflow)
+	}
+}
+
+// finish sets the run's end time and duration
+func (r *WebhookRun) finish(finishedAt time.Time) {
+	r.FinishedAt = finishedAt
+	r.DurationMs = finishedAt.Sub(r.StartedAt).Milliseconds()
+}
+
+// RunHistoryStore holds recent webhook runs
+type RunHistoryStore interface {
+	Save(ctx context.Context, run *WebhookRun) error              // Adds the run, or replaces the run with the same ID
+	Recent(ctx context.Context, limit int) ([]*WebhookRun, error) // Returns the most recent runs first
+	Get(ctx context.Context, id string) (*WebhookRun, error)      // Returns nil if there's no run with the ID
+	Close(ctx context.Context) error
+}
+
+// RunHistory keeps the most recent webhook runs for the dashboard. A nil RunHistory records nothing.
+type RunHistory struct {
+	store RunHistoryStore
+	now   func() time.Time
+}
+
+// NewRunHistory creates a run history backed by the given store
+func NewRunHistory(store RunHistoryStore) *RunHistory {
+	return &RunHistory{store: store, now: time.Now}
+}
+
+// Start records that processing of a merged change has started, and returns the run to update. Pushes
+// and workflow runs aren't recorded until they finish, since most don't match a workflow.
+func (h *RunHistory) Start(ctx context.Context, change CopyEvent) *WebhookRun {
+	now := time.Now
+	if h != nil {
+		now = h.now
+	}
+	run := newWebhookRun(change, now())
+	if run.Trigger == types.WorkflowTriggerPRMerged {
+		h.save(ctx, run)
+	}
+	return run
+}
+
+// Finish records the outcome of a run. Pushes and workflow runs that matched no workflow aren't recorded.
+func (h *RunHistory) Finish(ctx context.Context, run *WebhookRun) {
+	if h == nil {
+		return
+	}
+	run.finish(h.now())
+	if run.Trigger != types.WorkflowTriggerPRMerged && run.Status == RunStatusNoMatch {
+		return
+	}
+	h.save(ctx, run)
+}
+
+// Recent returns up to limit runs, most recent first
+func (h *RunHistory) Recent(ctx context.Context, limit int) ([]*WebhookRun, error) {
+	if h == nil {
+		return []*WebhookRun{}, nil
+	}
+	return h.store.Recent(ctx, limit)
+}
+
+// Get returns the run with the given ID, or nil if it isn't in the history
+func (h *RunHistory) Get(ctx context.Context, id string) (*WebhookRun, error) {
+	if h == nil {
+		return nil, nil
+	}
+	return h.store.Get(ctx, id)
+}
+
+// Close closes the store
+func (h *RunHistory) Close(ctx context.Context) error {
+	if h == nil {
+		return nil
+	}
+	return h.store.Close(ctx)
+}
+
+// save stores the run, logging rather than returning errors so a history outage doesn't affect copying
+func (h *RunHistory) save(ctx context.Context, run *WebhookRun) {
+	if h == nil {
+		return
+	}
+	if err := h.store.Save(ctx, run); err != nil {
+		LogWarningCtx(ctx, "failed to record webhook run", map[string]interface{}{
+			"run_id": run.ID,
+			"error":  err.Error(),
+		})
+	}
+}
+
+// newRunHistory returns the run history for the configured store, or nil if run history is disabled
+func newRunHistory(ctx context.Context, config *configs.Config, mongoClient *SharedMongoClient) (*RunHistory, error) {
+	if config.RunHistorySize <= 0 {
+		return nil, nil
+	}
+	if config.RunHistoryStore == configs.RunHistoryStoreMongoDB {
+		store, err := NewMongoRunHistoryStore(ctx, mongoClient, config.AuditDatabase, config.RunHistoryCollection, config.RunHistorySize)
+		if err != nil {
+			return nil, err
+		}
+		return NewRunHistory(store), nil
+	}
+	return NewRunHistory(NewMemoryRunHistoryStore(config.RunHistorySize)), nil
+}
+
+// MemoryRunHistoryStore implements RunHistoryStore in memory, keeping the most recent runs up to a limit.
+// Runs are lost when the process exits.
+type MemoryRunHistoryStore struct {
+	mu   sync.Mutex
+	size int
+	runs []*WebhookRun // Oldest first
+}
+
+// NewMemoryRunHistoryStore creates an empty in-memory store that keeps up to size runs
+func NewMemoryRunHistoryStore(size int) *MemoryRunHistoryStore {
+	return &MemoryRunHistoryStore{size: size}
+}
+
+// Save adds or replaces a run, dropping the oldest runs past the size limit
+func (s *MemoryRunHistoryStore) Save(ctx context.Context, run *WebhookRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := copyWebhookRun(run)
+	for i, existing := range s.runs {
+		if existing.ID == run.ID {
+			s.runs[i] = saved
+			return nil
+		}
+	}
+	s.runs = append(s.runs, saved)
+	if len(s.runs) > s.size {
+		s.runs = s.runs[len(s.runs)-s.size:]
+	}
+	return nil
+}
+
+// Recent returns copies of up to limit runs, most recent first
+func (s *MemoryRunHistoryStore) Recent(ctx context.Context, limit int) ([]*WebhookRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := make([]*WebhookRun, 0, len(s.runs))
+	for _, run := range s.runs {
+		runs = append(runs, copyWebhookRun(run))
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+// Get returns a copy of the run with the given ID
+func (s *MemoryRunHistoryStore) Get(ctx context.Context, id string) (*WebhookRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, run := range s.runs {
+		if run.ID == id {
+			return copyWebhookRun(run), nil
+		}
+	}
+	return nil, nil
+}
+
+// Close does nothing for the in-memory store
+func (s *MemoryRunHistoryStore) Close(ctx context.Context) error { return nil }
+
+// copyWebhookRun copies a run and its workflows, so callers can't modify stored runs
+func copyWebhookRun(run *WebhookRun) *WebhookRun {
+	copied := *run
+	copied.Workflows = append([]WebhookRunWorkflow{}, run.Workflows...)
+	return &copied
+}
+
+// MongoRunHistoryStore implements RunHistoryStore using a MongoDB collection, so the history survives
+// restarts and is shared by all instances
+type MongoRunHistoryStore struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+	size       int
+}
+
+// NewMongoRunHistoryStore returns a store backed by the given collection that keeps up to size runs,
+// connecting the shared client if it isn't yet
+func NewMongoRunHistoryStore(ctx context.Context, mongoClient *SharedMongoClient, database, collection string, size int) (*MongoRunHistoryStore, error) {
+	client, err := mongoClient.Connect(ctx, "the run history store is mongodb")
+	if err != nil {
+		return nil, err
+	}
+
+	coll := client.Database(database).Collection(collection)
+	if _, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "started_at", Value: -1}},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return &MongoRunHistoryStore{client: client, collection: coll, size: size}, nil
+}
+
+// Save upserts a run by ID and removes runs older than the most recent size runs
+func (s *MongoRunHistoryStore) Save(ctx context.Context, run *WebhookRun) error {
+	if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": run.ID}, run, options.Replace().SetUpsert(true)); err != nil {
+		return err
+	}
+
+	// Find the oldest run to keep, and delete anything older
+	var oldest WebhookRun
+	opts := options.FindOne().SetSort(bson.D{{Key: "started_at", Value: -1}}).SetSkip(int64(s.size - 1))
+	if err := s.collection.FindOne(ctx, bson.M{}, opts).Decode(&oldest); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+	_, err := s.collection.DeleteMany(ctx, bson.M{"started_at": bson.M{"$lt": oldest.StartedAt}})
+	return err
+}
+
+// Recent returns up to limit runs, most recent first
+func (s *MongoRunHistoryStore) Recent(ctx context.Context, limit int) ([]*WebhookRun, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "started_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := s.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	runs := []*WebhookRun{}
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// Get returns the run with the given ID, or nil if there isn't one
+func (s *MongoRunHistoryStore) Get(ctx context.Context, id string) (*WebhookRun, error) {
+	var run WebhookRun
+	if err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&run); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Ping checks the MongoDB connection
+func (s *MongoRunHistoryStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx, nil)
+}
+
+// Close does nothing: the shared client is disconnected by its owner
+func (s *MongoRunHistoryStore) Close(ctx context.Context) error { return nil }
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRunHistoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRunHistoryStore(3)
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 1; i <= 4; i++ {
+		run := newWebhookRun(CopyEvent{Repo: "org/src", Number: i}, start.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, store.Save(ctx, run))
+	}
+
+	// Only the most recent runs are kept, newest first
+	runs, err := store.Recent(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, []int{4, 3, 2}, []int{runs[0].PRNumber, runs[1].PRNumber, runs[2].PRNumber})
+
+	runs, err = store.Recent(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+
+	// Saving a run again replaces it
+	runs[0].Status = RunStatusSucceeded
+	require.NoError(t, store.Save(ctx, runs[0]))
+	got, err := store.Get(ctx, runs[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, RunStatusSucceeded, got.Status)
+	all, _ := store.Recent(ctx, 0)
+	assert.Len(t, all, 3)
+
+	missing, err := store.Get(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestRunHistory_StartAndFinish(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	history := NewRunHistory(NewMemoryRunHistoryStore(10))
+	history.now = func() time.Time { return clock }
+
+	run := history.Start(ctx, CopyEvent{Platform: types.SourcePlatformGitHub, Repo: "org/src", Number: 7, CommitSHA: "abc123"})
+	recorded, err := history.Get(ctx, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, RunStatusRunning, recorded.Status, "in-flight runs are visible")
+
+	clock = clock.Add(1500 * time.Millisecond)
+	run.fail(fmt.Errorf("failed to load config: %w", errors.New("not found")))
+	history.Finish(ctx, run)
+
+	recorded, err = history.Get(ctx, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, RunStatusFailed, recorded.Status)
+	assert.Equal(t, "failed to load config: not found", recorded.Error)
+	assert.Equal(t, int64(1500), recorded.DurationMs)
+}
+
+func TestRunHistory_Push(t *testing.T) {
+	ctx := context.Background()
+	history := NewRunHistory(NewMemoryRunHistoryStore(10))
+	push := CopyEvent{Platform: types.SourcePlatformGitHub, Repo: "org/src", CommitSHA: "def456", Trigger: types.WorkflowTriggerPush}
+
+	// Pushes that match no workflow aren't recorded
+	run := history.Start(ctx, push)
+	assert.Equal(t, types.WorkflowTriggerPush, run.Trigger)
+	run.Status = RunStatusNoMatch
+	history.Finish(ctx, run)
+	runs, err := history.Recent(ctx, 0)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	run = history.Start(ctx, push)
+	recorded, err := history.Get(ctx, run.ID)
+	require.NoError(t, err)
+	assert.Nil(t, recorded, "pushes are recorded when they finish")
+	run.addWorkflows(nil, nil)
+	history.Finish(ctx, run)
+	recorded, err = history.Get(ctx, run.ID)
+	require.NoError(t, err)
+	require.NotNil(t, recorded)
+	assert.Equal(t, RunStatusSucceeded, recorded.Status)
+}
+
+func TestRunHistory_Nil(t *testing.T) {
+	ctx := context.Background()
+	var history *RunHistory
+
+	run := history.Start(ctx, CopyEvent{Repo: "org/src", Number: 1})
+	require.NotNil(t, run, "runs can still be updated when history is disabled")
+	history.Finish(ctx, run)
+
+	runs, err := history.Recent(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+	assert.NoError(t, history.Close(ctx))
+}
+
+func TestWebhookRun_AddWorkflows(t *testing.T) {
+	copied := &workflowRun{
+		Workflow: types.Workflow{Name: "python", Destination: types.Destination{Repo: "org/python", Branch: "main"}},
+		Files:    []string{"examples/a.py"},
+	}
+	failed := &workflowRun{
+		Workflow: types.Workflow{Name: "node", Destination: types.Destination{Repo: "org/node", Branch: "main"}},
+		Files:    []string{"examples/a.js"},
+	}
+	dryRun := &workflowRun{
+		Workflow: types.Workflow{Name: "go", Destination: types.Destination{Repo: "org/go", Branch: "main"}},
+		DryRun:   &DryRunReport{Targets: []DryRunTarget{{Files: []string{"examples/a.go"}}}},
+	}
+	uploads := map[types.UploadKey]UploadResult{
+		{RepoName: "org/python", BranchPath: "main"}: {PRURL: "https://github.com/org/python/pull/3"},
+		{RepoName: "org/node", BranchPath: "main"}:   {Err: errors.New("create tree: 502")},
+	}
+
+	run := newWebhookRun(CopyEvent{Repo: "org/src", Number: 1}, time.Now())
+	run.addWorkflows([]*workflowRun{copied, dryRun}, uploads)
+	assert.Equal(t, RunStatusSucceeded, run.Status)
+	require.Len(t, run.Workflows, 2)
+	assert.Equal(t, "https://github.com/org/python/pull/3", run.Workflows[0].TargetPRURL)
+	assert.True(t, run.Workflows[1].DryRun)
+	assert.Equal(t, []string{"examples/a.go"}, run.Workflows[1].Files)
+
+	run = newWebhookRun(CopyEvent{Repo: "org/src", Number: 2}, time.Now())
+	run.addWorkflows([]*workflowRun{copied, failed}, uploads)
+	assert.Equal(t, RunStatusFailed, run.Status)
+	assert.Equal(t, []string{"upload: create tree: 502"}, run.Workflows[1].Errors)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+)
+
+// Scheduler runs merged changes in the background on a bounded pool of workers. Each source repo has
+// its own queue, and a free worker takes the next change from the repo served least recently, so a
+// burst of merges in one repo doesn't starve the others. At most perRepo changes from the same repo run at once.
+type Scheduler struct {
+	mu      sync.Mutex
+	workers int // Changes run at once across all repos; 0 means no limit
+	perRepo int // Changes run at once for one source repo; 0 means no limit
+	running int
+	// queues holds each source repo's waiting changes, oldest first
+	queues map[string][]func()
+	// order is the repos with waiting changes, in the order they started waiting
+	order  []string
+	byRepo map[string]int // Running changes per source repo
+	// served is when each busy repo last had a change started, as a sequence number
+	served  map[string]uint64
+	seq     uint64
+	metrics *MetricsCollector
+}
+
+// NewScheduler creates a scheduler that runs up to workers changes at once, and up to perRepo
+// changes from the same source repo. A limit of 0 or less means no limit.
+func NewScheduler(workers int, perRepo int, metrics *MetricsCollector) *Scheduler {
+	return &Scheduler{
+		workers: max(workers, 0),
+		perRepo: max(perRepo, 0),
+		queues:  make(map[string][]func()),
+		byRepo:  make(map[string]int),
+		served:  make(map[string]uint64),
+		metrics: metrics,
+	}
+}
+
+// Submit queues run for the source repo. It runs in its own goroutine once a worker is free and
+// the repo is under its limit.
+func (s *Scheduler) Submit(repo string, run func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queues[repo]) == 0 {
+		s.order = append(s.order, repo)
+	}
+	s.queues[repo] = append(s.queues[repo], run)
+	s.dispatch()
+}
+
+// SchedulerStats is a snapshot of the scheduler's load
+type SchedulerStats struct {
+	Running int            `json:"running"`
+	Queued  int            `json:"queued"`
+	ByRepo  map[string]int `json:"queued_by_repo,omitempty"` // Waiting changes per source repo
+}
+
+// Stats returns how many changes are running and waiting
+func (s *Scheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := SchedulerStats{Running: s.running, ByRepo: make(map[string]int, len(s.queues))}
+	for repo, queue := range s.queues {
+		stats.Queued += len(queue)
+		stats.ByRepo[repo] = len(queue)
+	}
+	return stats
+}
+
+// dispatch starts waiting changes until the pool is full or every waiting repo is at its limit.
+// s.mu must be held.
+func (s *Scheduler) dispatch() {
+	for s.workers == 0 || s.running < s.workers {
+		i := s.nextRepo()
+		if i < 0 {
+			break
+		}
+		repo := s.order[i]
+		run := s.queues[repo][0]
+		s.queues[repo] = s.queues[repo][1:]
+		if len(s.queues[repo]) == 0 {
+			delete(s.queues, repo)
+			s.order = append(s.order[:i], s.order[i+1:]...)
+		}
+
+		s.seq++
+		s.served[repo] = s.seq
+		s.running++
+		s.byRepo[repo]++
+		go s.run(repo, run)
+	}
+	s.recordMetrics()
+}
+
+// nextRepo returns the index in s.order of the repo to serve next: of the repos under their limit,
+// the one served least recently. Repos with nothing running that weren't served since they went idle
+// come first, in the order they started waiting. Returns -1 if no repo can be served.
+func (s *Scheduler) nextRepo() int {
+	next := -1
+	for i, repo := range s.order {
+		if s.perRepo > 0 && s.byRepo[repo] >= s.perRepo {
+			continue
+		}
+		if next < 0 || s.served[repo] < s.served[s.order[next]] {
+			next = i
+		}
+	}
+	return next
+}
+
+// run runs a change and frees its worker
+func (s *Scheduler) run(repo string, run func()) {
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.running--
+		if s.byRepo[repo]--; s.byRepo[repo] == 0 {
+			delete(s.byRepo, repo)
+			if len(s.queues[repo]) == 0 {
+				delete(s.served, repo)
+			}
+		}
+		s.dispatch()
+	}()
+	run()
+}
+
+// recordMetrics reports the number of running and waiting changes. s.mu must be held.
+func (s *Scheduler) recordMetrics() {
+	if s.metrics == nil {
+		return
+	}
+	queued := 0
+	for _, queue := range s.queues {
+		queued += len(queue)
+	}
+	s.metrics.SetSchedulerLoad(s.running, queued)
+}
+
+// scheduleMergedChange processes a merged change in the background on the container's scheduler.
+// done is called once processing finishes.
+func scheduleMergedChange(change CopyEvent, config *configs.Config, container *ServiceContainer, done func()) {
+	process := func() {
+		defer done()
+		// Don't use a request context, as it's cancelled when the request completes
+		ctx := WithCorrelationID(context.Background(), change.CorrelationID)
+		handleMergedPRWithContainer(ctx, change, config, container)
+	}
+	if container.Scheduler == nil {
+		go process()
+		return
+	}
+	container.Scheduler.Submit(change.Repo, process)
+}
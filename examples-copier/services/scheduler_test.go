@@ -0,0 +1,131 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schedulerJobs creates jobs that report when they start and run until released
+type schedulerJobs struct {
+	started  chan string
+	releases map[string]chan struct{}
+}
+
+func newSchedulerJobs() *schedulerJobs {
+	return &schedulerJobs{started: make(chan string, 10), releases: make(map[string]chan struct{})}
+}
+
+func (j *schedulerJobs) job(name string) func() {
+	release := make(chan struct{})
+	j.releases[name] = release
+	return func() {
+		j.started <- name
+		<-release
+	}
+}
+
+func (j *schedulerJobs) release(name string) {
+	close(j.releases[name])
+}
+
+func (j *schedulerJobs) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case name := <-j.started:
+		return name
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a job to start")
+		return ""
+	}
+}
+
+func (j *schedulerJobs) assertNoneStarted(t *testing.T) {
+	t.Helper()
+	select {
+	case name := <-j.started:
+		t.Fatalf("job %s started while the scheduler was full", name)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestScheduler_Fairness(t *testing.T) {
+	metrics := NewMetricsCollector()
+	scheduler := NewScheduler(1, 1, metrics)
+	jobs := newSchedulerJobs()
+
+	// A burst from one repo doesn't hold up a change from another
+	scheduler.Submit("org/busy", jobs.job("busy-1"))
+	scheduler.Submit("org/busy", jobs.job("busy-2"))
+	scheduler.Submit("org/busy", jobs.job("busy-3"))
+	scheduler.Submit("org/quiet", jobs.job("quiet-1"))
+
+	assert.Equal(t, "busy-1", jobs.next(t))
+	jobs.assertNoneStarted(t)
+
+	stats := scheduler.Stats()
+	assert.Equal(t, 1, stats.Running)
+	assert.Equal(t, 3, stats.Queued)
+	assert.Equal(t, map[string]int{"org/busy": 2, "org/quiet": 1}, stats.ByRepo)
+	queues := metrics.GetMetrics(NewFileStateService()).Queues
+	assert.Equal(t, 1, queues.RunningChanges)
+	assert.Equal(t, 3, queues.ScheduledChanges)
+
+	jobs.release("busy-1")
+	assert.Equal(t, "quiet-1", jobs.next(t))
+	jobs.release("quiet-1")
+	assert.Equal(t, "busy-2", jobs.next(t))
+	jobs.release("busy-2")
+	assert.Equal(t, "busy-3", jobs.next(t))
+	jobs.release("busy-3")
+
+	require.Eventually(t, func() bool { return scheduler.Stats().Running == 0 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 0, metrics.GetMetrics(NewFileStateService()).Queues.ScheduledChanges)
+}
+
+func TestScheduler_PerRepoLimit(t *testing.T) {
+	scheduler := NewScheduler(3, 1, nil)
+	jobs := newSchedulerJobs()
+
+	scheduler.Submit("org/a", jobs.job("a-1"))
+	scheduler.Submit("org/a", jobs.job("a-2"))
+	scheduler.Submit("org/b", jobs.job("b-1"))
+
+	// Workers are free, but org/a already has a change running
+	assert.ElementsMatch(t, []string{"a-1", "b-1"}, []string{jobs.next(t), jobs.next(t)})
+	jobs.assertNoneStarted(t)
+	assert.Equal(t, 1, scheduler.Stats().Queued)
+
+	jobs.release("b-1")
+	jobs.assertNoneStarted(t)
+	jobs.release("a-1")
+	assert.Equal(t, "a-2", jobs.next(t))
+	jobs.release("a-2")
+}
+
+func TestScheduler_NoLimits(t *testing.T) {
+	scheduler := NewScheduler(0, 0, nil)
+	jobs := newSchedulerJobs()
+	var wg sync.WaitGroup
+
+	names := []string{"a-1", "a-2", "a-3", "a-4", "a-5"}
+	for _, name := range names {
+		wg.Add(1)
+		job := jobs.job(name)
+		scheduler.Submit("org/a", func() {
+			defer wg.Done()
+			job()
+		})
+	}
+	for range names {
+		jobs.next(t)
+	}
+	assert.Equal(t, 5, scheduler.Stats().Running)
+	for _, name := range names {
+		jobs.release(name)
+	}
+	wg.Wait()
+}
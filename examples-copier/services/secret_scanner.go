@@ -0,0 +1,145 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SecretFinding describes a potential secret found in file content.
+// The secret itself is never stored; only a redacted form is kept so findings can be logged and alerted on safely.
+type SecretFinding struct {
+	Rule     string `json:"rule"`
+	Line     int    `json:"line"`
+	Redacted string `json:"redacted"`
+}
+
+// SecretsDetectedError is returned when a file is blocked from being copied because it contains potential secrets
+type SecretsDetectedError struct {
+	Path     string
+	Findings []SecretFinding
+}
+
+// Error implements the error interface
+func (e *SecretsDetectedError) Error() string {
+	rules := make([]string, 0, len(e.Findings))
+	for _, finding := range e.Findings {
+		rules = append(rules, fmt.Sprintf("%s (line %d)", finding.Rule, finding.Line))
+	}
+	return fmt.Sprintf("potential secrets detected in %s: %s", e.Path, strings.Join(rules, ", "))
+}
+
+// secretRule is a named pattern for a type of credential.
+// secretGroup is the capture group holding the secret value (0 for the whole match).
+type secretRule struct {
+	name        string
+	pattern     *regexp.Regexp
+	secretGroup int
+}
+
+var secretRules = []secretRule{
+	{
+		name:    "private_key",
+		pattern: regexp.MustCompile(`-----BEGIN (?:RSA |DSA |EC |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		name:    "aws_access_key_id",
+		pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	},
+	{
+		name:        "aws_secret_access_key",
+		pattern:     regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`),
+		secretGroup: 1,
+	},
+	{
+		name:    "github_token",
+		pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`),
+	},
+	{
+		name:    "slack_token",
+		pattern: regexp.MustCompile(`\bxox[abprs]-[0-9A-Za-z-]{10,}`),
+	},
+	{
+		name:    "google_api_key",
+		pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`),
+	},
+	{
+		name:    "stripe_live_key",
+		pattern: regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`),
+	},
+	{
+		name:        "connection_string_password",
+		pattern:     regexp.MustCompile(`\b(?:mongodb(?:\+srv)?|postgres(?:ql)?|mysql|redis|amqps?)://[^:@/\s"'<>]+:([^@/\s"']+)@`),
+		secretGroup: 1,
+	},
+	{
+		name:        "generic_secret_assignment",
+		pattern:     regexp.MustCompile(`(?i)\b(?:api[_-]?key|secret[_-]?key|access[_-]?token|auth[_-]?token|client[_-]?secret)["']?\s*[:=]\s*["']([A-Za-z0-9_\-/+=.]{16,})["']`),
+		secretGroup: 1,
+	},
+}
+
+// placeholderMarkers are substrings that indicate a value is a documentation placeholder rather than a real secret
+var placeholderMarkers = []string{"<", ">", "${", "{", "}", "%s", "your", "example", "placeholder", "changeme", "redacted", "xxxx", "****", "...."}
+
+// placeholderValues are common placeholder values used in connection strings and config samples
+var placeholderValues = map[string]bool{
+	"password":    true,
+	"pass":        true,
+	"pwd":         true,
+	"secret":      true,
+	"db_password": true,
+	"dbpassword":  true,
+}
+
+// ScanForSecrets scans file content for credential patterns such as API keys, tokens,
+// connection strings with passwords, and private keys.
+// Values that look like documentation placeholders (e.g. "<password>" or "${API_KEY}") are ignored.
+func ScanForSecrets(content string) []SecretFinding {
+	// Skip binary content
+	if strings.Contains(content, "\x00") {
+		return nil
+	}
+
+	var findings []SecretFinding
+	for i, line := range strings.Split(content, "\n") {
+		for _, rule := range secretRules {
+			for _, match := range rule.pattern.FindAllStringSubmatch(line, -1) {
+				secret := match[rule.secretGroup]
+				if isPlaceholderSecret(secret) {
+					continue
+				}
+				findings = append(findings, SecretFinding{
+					Rule:     rule.name,
+					Line:     i + 1,
+					Redacted: RedactSecret(secret),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// RedactSecret returns a form of the secret that is safe to log: the first four characters followed by asterisks.
+// Short values are fully masked.
+func RedactSecret(secret string) string {
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return secret[:4] + "****"
+}
+
+// isPlaceholderSecret returns true if the value looks like a placeholder rather than a real secret
+func isPlaceholderSecret(value string) bool {
+	lower := strings.ToLower(value)
+	if placeholderValues[lower] {
+		return true
+	}
+	for _, marker := range placeholderMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	// A value made of a single repeated character (e.g. "aaaaaaaa") is a placeholder
+	return strings.Count(lower, lower[:1]) == len(lower)
+}
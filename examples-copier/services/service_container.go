@@ -12,6 +12,7 @@ import (
 type ServiceContainer struct {
 	Config           *configs.Config
 	FileStateService FileStateService
+	RunFileStates    *FileStateRegistry // Staged files of each run in progress, reported as the file queues
 
 	// New services
 	ConfigLoader      ConfigLoader
@@ -22,9 +23,23 @@ type ServiceContainer struct {
 	AuditLogger       AuditLogger
 	MetricsCollector  *MetricsCollector
 	SlackNotifier     SlackNotifier
+	Mongo             *SharedMongoClient // shared by every MongoDB-backed store; connects when first used
+	RetryQueue        *RetryQueue
+	PRThrottle        *PRThrottle
+	BuildVerifier     *BuildVerifier
+	ApprovalGate      *ApprovalGate
+	RunHistory        *RunHistory
+	WebhookArchive    *WebhookArchive // nil if the webhook archive is disabled
+	WriteLog          *WriteLog       // nil if the write log is disabled
+	Scheduler         *Scheduler
+	ConfigWatcher     *ConfigWatcher // nil if the config is fetched for every webhook
+	Reconciler        *Reconciler
+	EventSources      *EventSources
 
 	// Server state
-	StartTime time.Time
+	StartTime   time.Time
+	InFlight    *InFlightTracker
+	Maintenance *MaintenanceController
 }
 
 // NewServiceContainer creates and initializes all services
@@ -42,11 +57,22 @@ func NewServiceContainer(config *configs.Config) (*ServiceContainer, error) {
 		configLoader = NewConfigLoader()
 	}
 
+	// Serve the last-known-good config between reloads, rather than fetching it for every webhook
+	var configWatcher *ConfigWatcher
+	if config.ConfigReloadInterval > 0 {
+		configWatcher = NewConfigWatcher(configLoader, config, time.Duration(config.ConfigReloadInterval)*time.Second)
+		configLoader = configWatcher
+	}
+
 	patternMatcher := NewPatternMatcher()
 	pathTransformer := NewPathTransformer()
 	messageTemplater := NewMessageTemplater()
 	prTemplateFetcher := NewPRTemplateFetcher()
 	metricsCollector := NewMetricsCollector()
+	SetGitHubAPIMetrics(metricsCollector)
+	SetGitHubRateLimits(config.GitHubConcurrentRequests, time.Duration(config.GitHubWriteInterval)*time.Millisecond,
+		config.GitHubRateLimitReserve)
+	SetUploadConcurrency(config.UploadConcurrency)
 
 	// Initialize Slack notifier
 	slackNotifier := NewSlackNotifier(
@@ -56,11 +82,12 @@ func NewServiceContainer(config *configs.Config) (*ServiceContainer, error) {
 		config.SlackIconEmoji,
 	)
 
-	// Initialize audit logger
+	// Initialize audit logger. Every MongoDB-backed store shares one client.
 	ctx := context.Background()
+	mongoClient := NewSharedMongoClient(config.MongoURI)
 	auditLogger, err := NewMongoAuditLogger(
 		ctx,
-		config.MongoURI,
+		mongoClient,
 		config.AuditDatabase,
 		config.AuditCollection,
 		config.AuditEnabled,
@@ -69,9 +96,72 @@ func NewServiceContainer(config *configs.Config) (*ServiceContainer, error) {
 		return nil, fmt.Errorf("failed to initialize audit logger: %w", err)
 	}
 
-	return &ServiceContainer{
+	// Initialize upload retry queue
+	var retryStore RetryStore = NewMemoryRetryStore()
+	if config.UploadRetryStore == configs.UploadRetryStoreMongoDB && config.UploadRetryMaxAttempts > 0 {
+		retryStore, err = NewMongoRetryStore(ctx, mongoClient, config.AuditDatabase, config.UploadRetryCollection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize upload retry store: %w", err)
+		}
+	}
+
+	// Initialize the signed log of the commits and PRs the copier makes
+	writeLog, err := newWriteLog(ctx, config, mongoClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize write log: %w", err)
+	}
+	retryQueue := NewRetryQueue(retryStore, config, auditLogger, slackNotifier, metricsCollector, prTemplateFetcher, writeLog)
+
+	// Initialize the approval gate's store of PRs held for approval
+	var approvalStore ApprovalStore = NewMemoryApprovalStore()
+	if config.ApprovalGateStore == configs.ApprovalGateStoreMongoDB {
+		approvalStore, err = NewMongoApprovalStore(ctx, mongoClient, config.AuditDatabase, config.ApprovalGateCollection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize approval gate store: %w", err)
+		}
+	}
+	approvalGate := NewApprovalGate(approvalStore, config, auditLogger)
+
+	// Initialize webhook run history for the dashboard
+	runHistory, err := newRunHistory(ctx, config, mongoClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize run history: %w", err)
+	}
+
+	// Initialize the budgets of the PR limits
+	var prBudget PRBudgetStore = NewMemoryPRBudgetStore()
+	if config.PRLimitStore == configs.PRLimitStoreMongoDB && (config.PRLimitPerHour > 0 || config.PRLimitPerDay > 0) {
+		prBudget, err = NewMongoPRBudgetStore(ctx, mongoClient, config.AuditDatabase, config.PRLimitCollection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize PR limit store: %w", err)
+		}
+	}
+
+	// Initialize the leases that keep reconciliations to one instance
+	var leases LeaseStore = NewMemoryLeaseStore()
+	if config.LeaseStore == configs.LeaseStoreMongoDB {
+		leases, err = NewMongoLeaseStore(ctx, mongoClient, config.AuditDatabase, config.LeaseCollection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize lease store: %w", err)
+		}
+	}
+
+	// Initialize maintenance mode, whose state and queue may be shared by all instances
+	maintenance, err := newMaintenanceController(ctx, config, mongoClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize maintenance mode: %w", err)
+	}
+
+	// Initialize the archive of webhook payloads for replay
+	webhookArchive, err := newWebhookArchive(ctx, config, mongoClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize webhook archive: %w", err)
+	}
+
+	container := &ServiceContainer{
 		Config:            config,
 		FileStateService:  fileStateService,
+		RunFileStates:     NewFileStateRegistry(),
 		ConfigLoader:      configLoader,
 		PatternMatcher:    patternMatcher,
 		PathTransformer:   pathTransformer,
@@ -80,14 +170,55 @@ func NewServiceContainer(config *configs.Config) (*ServiceContainer, error) {
 		AuditLogger:       auditLogger,
 		MetricsCollector:  metricsCollector,
 		SlackNotifier:     slackNotifier,
+		Mongo:             mongoClient,
+		RetryQueue:        retryQueue,
+		PRThrottle:        NewPRThrottle(config, prBudget, metricsCollector, prTemplateFetcher, writeLog, retryQueue, approvalGate),
+		BuildVerifier:     NewBuildVerifier(config, auditLogger),
+		ApprovalGate:      approvalGate,
+		RunHistory:        runHistory,
+		WebhookArchive:    webhookArchive,
+		WriteLog:          writeLog,
+		Scheduler:         NewScheduler(config.MaxConcurrentRuns, config.MaxConcurrentRunsPerRepo, metricsCollector),
+		ConfigWatcher:     configWatcher,
+		EventSources:      NewEventSources(config),
 		StartTime:         time.Now(),
-	}, nil
+		InFlight:          NewInFlightTracker(),
+		Maintenance:       maintenance,
+	}
+	container.Reconciler = NewReconciler(config, container, time.Duration(config.ReconcileInterval)*time.Second, leases)
+	return container, nil
+}
+
+// withRunFileState returns a copy of the container whose FileStateService holds only one run's staged
+// files, so runs processed at once don't commit or drop each other's files. The state is reported in the
+// file queues until the returned func is called.
+func (sc *ServiceContainer) withRunFileState() (*ServiceContainer, func()) {
+	scoped := *sc
+	scoped.FileStateService = sc.RunFileStates.Open()
+	return &scoped, func() { sc.RunFileStates.Close(scoped.FileStateService) }
 }
 
-// Close cleans up resources
+// Close cleans up resources. Held PR batches are opened first, since failed ones are queued for retry,
+// and then the retry queue is closed, since it may record pending retries in the audit log. The shared
+// MongoDB client is disconnected last.
 func (sc *ServiceContainer) Close(ctx context.Context) error {
+	sc.PRThrottle.Close(ctx)
+	if err := sc.RetryQueue.Close(ctx); err != nil {
+		LogWarning(fmt.Sprintf("Failed to close upload retry queue: %v", err))
+	}
+	if err := sc.RunHistory.Close(ctx); err != nil {
+		LogWarning(fmt.Sprintf("Failed to close run history: %v", err))
+	}
+	if err := sc.WebhookArchive.Close(ctx); err != nil {
+		LogWarning(fmt.Sprintf("Failed to close webhook archive: %v", err))
+	}
+	if err := sc.WriteLog.Close(ctx); err != nil {
+		LogWarning(fmt.Sprintf("Failed to close write log: %v", err))
+	}
 	if sc.AuditLogger != nil {
-		return sc.AuditLogger.Close(ctx)
+		if err := sc.AuditLogger.Close(ctx); err != nil {
+			LogWarning(fmt.Sprintf("Failed to close audit logger: %v", err))
+		}
 	}
-	return nil
+	return sc.Mongo.Disconnect(ctx)
 }
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+const (
+	// shadowDiffContext is the number of unchanged lines shown around each change in a shadow diff
+	shadowDiffContext = 3
+	// maxDiffCells caps the size of the table the line diff builds. Files whose changed region is larger
+	// are shown as the old lines removed and the new lines added.
+	maxDiffCells = 4_000_000
+)
+
+// ShadowFileDiff is the change a shadow workflow would have made to one destination file
+type ShadowFileDiff struct {
+	Repo   string
+	Branch string
+	Path   string
+	Status string // "added", "modified", or "removed"
+	Diff   string // Unified diff; empty for binary files
+}
+
+// runShadowWorkflow processes a shadow workflow like a dry run, then posts the diff each destination file
+// would have had to the workflow's owners
+func runShadowWorkflow(ctx context.Context, workflow types.Workflow, changedFiles []types.ChangedFile,
+	prNumber int, sourceCommitSHA string, container *ServiceContainer) (*DryRunReport, error) {
+
+	state, err := processWithoutQueueing(ctx, workflow, changedFiles, prNumber, sourceCommitSHA, container)
+	report := buildDryRunReport(workflow, prNumber, sourceCommitSHA, state)
+	logDryRunReport(ctx, report)
+	if !report.HasChanges() && err == nil {
+		return report, nil
+	}
+
+	event := &ShadowRunEvent{
+		WorkflowName: workflow.Name,
+		PRNumber:     prNumber,
+		SourceRepo:   workflow.Source.Repo,
+		TargetRepo:   workflow.Destination.Repo,
+		TargetBranch: workflow.Destination.Branch,
+	}
+	if change, ok := sourceChangeFromContext(ctx); ok {
+		event.PRURL = change.URL
+	}
+	if err != nil {
+		event.Errors = append(event.Errors, errorMessages(err)...)
+	}
+
+	uploads := state.GetFilesToUpload()
+	keys := make([]types.UploadKey, 0, len(uploads))
+	for key := range uploads {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].RepoName != keys[j].RepoName {
+			return keys[i].RepoName < keys[j].RepoName
+		}
+		return keys[i].BranchPath < keys[j].BranchPath
+	})
+	for _, key := range keys {
+		provider, repo, providerErr := repoProviderFor(key.RepoName)
+		if providerErr != nil {
+			event.Errors = append(event.Errors, providerErr.Error())
+			continue
+		}
+		diffs, diffErrs := shadowTargetDiffs(ctx, provider, repo, key, uploads[key])
+		event.Diffs = append(event.Diffs, diffs...)
+		event.Errors = append(event.Errors, diffErrs...)
+	}
+
+	if len(event.Diffs) == 0 && len(event.Errors) == 0 {
+		LogInfoCtx(ctx, "shadow run: destination files already match", map[string]interface{}{
+			"workflow_name": workflow.Name,
+			"pr_number":     prNumber,
+		})
+		return report, err
+	}
+
+	notifications := workflow.Shadow.Notifications
+	if notifications == nil {
+		notifications = workflow.Notifications
+	}
+	if notifications == nil {
+		notifications = &types.NotificationConfig{}
+	}
+	if notifyErr := workflowNotifier(notifications, container.Config).NotifyShadowRun(ctx, event); notifyErr != nil {
+		LogWarningCtx(ctx, "failed to send shadow run notification", map[string]interface{}{
+			"workflow_name": workflow.Name,
+			"error":         notifyErr.Error(),
+		})
+	}
+	return report, err
+}
+
+// shadowTargetDiffs compares the files queued for one destination repo and branch with what's there now.
+// Files that wouldn't change are left out. Files that couldn't be read are returned as error messages.
+func shadowTargetDiffs(ctx context.Context, provider RepoProvider, repo string, key types.UploadKey,
+	content types.UploadFileContent) ([]ShadowFileDiff, []string) {
+
+	var diffs []ShadowFileDiff
+	var errs []string
+	for _, file := range content.Content {
+		targetPath := file.GetName()
+		newContent, err := file.GetContent()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: decode copied file: %v", targetPath, err))
+			continue
+		}
+		oldContent, found, err := provider.GetFile(ctx, repo, targetPath, key.BranchPath)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: get current file: %v", targetPath, err))
+			continue
+		}
+		if found && oldContent == newContent {
+			continue
+		}
+		status := "modified"
+		if !found {
+			status = "added"
+		}
+		diffs = append(diffs, shadowFileDiff(key, targetPath, status, oldContent, newContent))
+	}
+
+	for _, targetPath := range content.DeletePaths {
+		oldContent, found, err := provider.GetFile(ctx, repo, targetPath, key.BranchPath)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: get current file: %v", targetPath, err))
+			continue
+		}
+		if !found {
+			continue
+		}
+		diffs = append(diffs, shadowFileDiff(key, targetPath, "removed", oldContent, ""))
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, errs
+}
+
+// shadowFileDiff returns the diff of one destination file. Binary files get no diff.
+func shadowFileDiff(key types.UploadKey, targetPath string, status string, oldContent string, newContent string) ShadowFileDiff {
+	d := ShadowFileDiff{Repo: key.RepoName, Branch: key.BranchPath, Path: targetPath, Status: status}
+	if isTextContent(oldContent) && isTextContent(newContent) {
+		d.Diff = unifiedDiff(targetPath, status, oldContent, newContent)
+	}
+	return d
+}
+
+// diffOp is one line of a line diff: ' ' for a line in both files, '-' for a removed line, and '+' for an
+// added one
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns the unified diff between two versions of the file at targetPath
+func unifiedDiff(targetPath string, status string, oldContent string, newContent string) string {
+	ops := diffLines(splitLines(oldContent), splitLines(newContent))
+
+	var out strings.Builder
+	switch status {
+	case "added":
+		fmt.Fprintf(&out, "--- /dev/null\n+++ b/%s\n", targetPath)
+	case "removed":
+		fmt.Fprintf(&out, "--- a/%s\n+++ /dev/null\n", targetPath)
+	default:
+		fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", targetPath, targetPath)
+	}
+
+	for start := 0; start < len(ops); {
+		// Find the next change, then extend the hunk while changes are within two contexts of each other
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for i := first + 1; i < len(ops) && i <= last+2*shadowDiffContext; i++ {
+			if ops[i].kind != ' ' {
+				last = i
+			}
+		}
+		hunkStart := max(first-shadowDiffContext, start)
+		hunkEnd := min(last+shadowDiffContext+1, len(ops))
+
+		oldLine, newLine := 1, 1
+		for _, op := range ops[:hunkStart] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, op := range ops[hunkStart:hunkEnd] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldLine--
+		}
+		if newCount == 0 {
+			newLine--
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		for _, op := range ops[hunkStart:hunkEnd] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			out.WriteByte('\n')
+		}
+		start = hunkEnd
+	}
+	return out.String()
+}
+
+// splitLines splits content into lines without their line endings
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// diffLines returns the shortest edit from a to b as a line diff. Lines the two have in common at the start
+// and end are matched first, and the longest common subsequence of the rest decides the edit.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// diffMiddle diffs the changed region of two files using their longest common subsequence
+func diffMiddle(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	oldContent := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\n"
+	newContent := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\nadded\n"
+	want := `--- a/app.py
++++ b/app.py
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -12,3 +12,4 @@
+ l
+ m
+ n
++added
+`
+	assert.Equal(t, want, unifiedDiff("app.py", "modified", oldContent, newContent))
+
+	assert.Equal(t, "--- /dev/null\n+++ b/new.go\n@@ -0,0 +1,2 @@\n+package main\n+\n",
+		unifiedDiff("new.go", "added", "", "package main\n\n"))
+	assert.Equal(t, "--- a/old.go\n+++ /dev/null\n@@ -1,1 +0,0 @@\n-package main\n",
+		unifiedDiff("old.go", "removed", "package main\n", ""))
+
+	// Changes close together share a hunk
+	diff := unifiedDiff("x", "modified", "1\n2\n3\n4\n5\n6\n7\n8\n", "1\nTWO\n3\n4\n5\n6\nSEVEN\n8\n")
+	assert.Equal(t, 1, strings.Count(diff, "@@ -"))
+}
+
+func TestDiffLines(t *testing.T) {
+	ops := diffLines([]string{"x", "a", "b", "c"}, []string{"a", "b", "y", "c"})
+	var kinds []string
+	for _, op := range ops {
+		kinds = append(kinds, string(op.kind)+op.line)
+	}
+	assert.Equal(t, []string{"-x", " a", " b", "+y", " c"}, kinds)
+}
+
+func TestShadowTargetDiffs(t *testing.T) {
+	provider := &reviewerRecorder{files: map[string]string{
+		"docs/same.py":    "print('same')\n",
+		"docs/changed.py": "print('old')\n",
+		"docs/gone.py":    "print('gone')\n",
+	}}
+	key := types.UploadKey{RepoName: "org/app", BranchPath: "main"}
+	content := types.UploadFileContent{
+		Content: []github.RepositoryContent{
+			{Name: github.String("docs/same.py"), Content: github.String("print('same')\n")},
+			{Name: github.String("docs/changed.py"), Content: github.String("print('new')\n")},
+			{Name: github.String("docs/new.py"), Content: github.String("print('new')\n")},
+		},
+		DeletePaths: []string{"docs/gone.py", "docs/never-there.py"},
+	}
+
+	diffs, errs := shadowTargetDiffs(context.Background(), provider, "org/app", key, content)
+	assert.Empty(t, errs)
+	require.Len(t, diffs, 3, "unchanged files and files already gone are left out")
+	assert.Equal(t, "docs/changed.py", diffs[0].Path)
+	assert.Equal(t, "modified", diffs[0].Status)
+	assert.Contains(t, diffs[0].Diff, "-print('old')\n+print('new')\n")
+	assert.Equal(t, "docs/gone.py", diffs[1].Path)
+	assert.Equal(t, "removed", diffs[1].Status)
+	assert.Equal(t, "docs/new.py", diffs[2].Path)
+	assert.Equal(t, "added", diffs[2].Status)
+}
+
+func TestNotifyShadowRun(t *testing.T) {
+	server, messages := slackRecorder(t)
+	notifier := NewSlackNotifier(server.URL, "#owners", "", "")
+
+	large := ShadowFileDiff{Path: "big.txt", Status: "added", Diff: strings.Repeat("+line\n", maxShadowDiffChars)}
+	require.NoError(t, notifier.NotifyShadowRun(context.Background(), &ShadowRunEvent{
+		WorkflowName: "new-app",
+		PRNumber:     12,
+		SourceRepo:   "org/src",
+		TargetRepo:   "org/app",
+		TargetBranch: "main",
+		Diffs: []ShadowFileDiff{
+			{Path: "app.py", Status: "modified", Diff: "--- a/app.py\n+++ b/app.py\n@@ -1,1 +1,1 @@\n-old\n+new\n"},
+			{Path: "logo.png", Status: "added"},
+			large,
+		},
+	}))
+
+	require.Len(t, messages(), 1)
+	attachment := messages()[0].Attachments[0]
+	assert.Contains(t, attachment.Title, "new-app Would Change 3 Files for PR #12")
+	assert.Contains(t, attachment.Text, "+new")
+	assert.Contains(t, attachment.Text, "Binary file logo.png added")
+	assert.NotContains(t, attachment.Text, "+line")
+	require.Len(t, attachment.Fields, 3)
+	assert.Equal(t, "Diffs Not Shown", attachment.Fields[2].Title)
+	assert.Contains(t, attachment.Fields[2].Value, "big.txt (added)")
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// defaultSignOffNote is appended to PR bodies for workflows with sign-off enabled when no note is configured
+const defaultSignOffNote = "The commits in this pull request were copied automatically from the source repository " +
+	"and are signed off under the [Developer Certificate of Origin](https://developercertificate.org/)."
+
+// trailerLine matches a git trailer line, such as "Signed-off-by: Name <email>"
+var trailerLine = regexp.MustCompile(`^[A-Za-z0-9-]+: \S`)
+
+// signOffAuthor returns the identity commits are authored and signed off by. Names and emails that
+// aren't set in the workflow fall back to COMMITTER_NAME and COMMITTER_EMAIL. DCO checks require the
+// sign-off to match the commit author, so the same identity is used for both.
+func signOffAuthor(signOff *types.SignOffConfig) *github.CommitAuthor {
+	defaults := configs.NewConfig()
+	name := signOff.Name
+	if name == "" {
+		name = getEnvOrDefault(configs.CommitterName, defaults.CommitterName)
+	}
+	email := signOff.Email
+	if email == "" {
+		email = getEnvOrDefault(configs.CommitterEmail, defaults.CommitterEmail)
+	}
+	return &github.CommitAuthor{Name: github.String(name), Email: github.String(email)}
+}
+
+// addSignOffTrailer appends a Signed-off-by trailer for author to message
+func addSignOffTrailer(message string, author *github.CommitAuthor) string {
+	return addTrailer(message, fmt.Sprintf("Signed-off-by: %s <%s>", author.GetName(), author.GetEmail()))
+}
+
+// addTrailer appends a git trailer to message. The trailer joins an existing trailer block at the
+// end of the message, and isn't added twice.
+func addTrailer(message string, trailer string) string {
+	message = strings.TrimRight(message, "\n")
+
+	lines := strings.Split(message, "\n")
+	for _, line := range lines {
+		if strings.TrimSpace(line) == trailer {
+			return message
+		}
+	}
+	if len(lines) > 1 && trailerLine.MatchString(lines[len(lines)-1]) {
+		return message + "\n" + trailer
+	}
+	return message + "\n\n" + trailer
+}
+
+// appendSignOffNote appends the workflow's sign-off note to a PR body
+func appendSignOffNote(prBody string, signOff *types.SignOffConfig) string {
+	note := signOff.PRNote
+	if strings.TrimSpace(note) == "" {
+		note = defaultSignOffNote
+	}
+	if strings.TrimSpace(prBody) == "" {
+		return note
+	}
+	return strings.TrimRight(prBody, "\n") + "\n\n---\n\n" + note
+}
+
+// getEnvOrDefault returns the environment variable, or def if it's unset or blank
+func getEnvOrDefault(key, def string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return def
+}
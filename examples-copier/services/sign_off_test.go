@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignOffAuthor(t *testing.T) {
+	t.Setenv(configs.CommitterName, "Docs Bot")
+	t.Setenv(configs.CommitterEmail, "docs-bot@example.com")
+
+	author := signOffAuthor(&types.SignOffConfig{Enabled: true})
+	assert.Equal(t, "Docs Bot", author.GetName())
+	assert.Equal(t, "docs-bot@example.com", author.GetEmail())
+
+	author = signOffAuthor(&types.SignOffConfig{Enabled: true, Name: "Release Bot", Email: "release@example.com"})
+	assert.Equal(t, "Release Bot", author.GetName())
+	assert.Equal(t, "release@example.com", author.GetEmail())
+}
+
+func TestAddSignOffTrailer(t *testing.T) {
+	author := &github.CommitAuthor{Name: github.String("Docs Bot"), Email: github.String("docs-bot@example.com")}
+	trailer := "Signed-off-by: Docs Bot <docs-bot@example.com>"
+
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"subject only", "Copy examples\n", "Copy examples\n\n" + trailer},
+		{"subject and body", "Copy examples\n\nFrom org/src#12", "Copy examples\n\nFrom org/src#12\n\n" + trailer},
+		{"existing trailers", "Copy examples\n\nCo-authored-by: Dev <dev@example.com>", "Copy examples\n\nCo-authored-by: Dev <dev@example.com>\n" + trailer},
+		{"already signed off", "Copy examples\n\n" + trailer, "Copy examples\n\n" + trailer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, addSignOffTrailer(tt.message, author))
+		})
+	}
+}
+
+func TestAppendSignOffNote(t *testing.T) {
+	assert.Equal(t, defaultSignOffNote, appendSignOffNote("", &types.SignOffConfig{Enabled: true}))
+	assert.Equal(t, "Copied from org/src#12\n\n---\n\nDCO: signed off by the docs team.",
+		appendSignOffNote("Copied from org/src#12\n", &types.SignOffConfig{Enabled: true, PRNote: "DCO: signed off by the docs team."}))
+}
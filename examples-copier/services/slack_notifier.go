@@ -1,12 +1,12 @@
 package services
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"strings"
 	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/notify"
 )
 
 // SlackNotifier handles sending notifications to Slack
@@ -23,6 +23,21 @@ type SlackNotifier interface {
 	// NotifyDeprecation sends a notification when files are deprecated
 	NotifyDeprecation(ctx context.Context, event *DeprecationEvent) error
 	
+	// NotifySecretsDetected sends a notification when a file is blocked because it contains potential secrets
+	NotifySecretsDetected(ctx context.Context, event *SecretsDetectedEvent) error
+
+	// NotifyWorkflowOutcome sends a summary of what one workflow copied for a merged PR, and any errors
+	NotifyWorkflowOutcome(ctx context.Context, event *WorkflowOutcomeEvent) error
+
+	// NotifyBuildFailed sends a notification when the destination's check runs fail on a commit the copier made
+	NotifyBuildFailed(ctx context.Context, event *BuildFailedEvent) error
+	
+	// NotifyApprovalGate sends a notification when a PR held by an approval gate isn't merged as approved
+	NotifyApprovalGate(ctx context.Context, event *ApprovalGateEvent) error
+	
+	// NotifyShadowRun sends the diff a workflow in shadow mode would have made to its destination
+	NotifyShadowRun(ctx context.Context, event *ShadowRunEvent) error
+	
 	// IsEnabled returns true if Slack notifications are enabled
 	IsEnabled() bool
 }
@@ -36,6 +51,7 @@ type PRProcessedEvent struct {
 	FilesMatched  int
 	FilesCopied   int
 	FilesFailed   int
+	DryRunFiles   int // Files dry-run workflows would have copied or removed
 	ProcessingTime time.Duration
 }
 
@@ -66,14 +82,89 @@ type DeprecationEvent struct {
 	Files       []string
 }
 
+// SecretsDetectedEvent contains information about a file blocked by secret scanning
+type SecretsDetectedEvent struct {
+	PRNumber     int
+	SourceRepo   string
+	TargetRepo   string
+	WorkflowName string
+	FilePath     string
+	Findings     []SecretFinding
+}
+
+// WorkflowOutcomeEvent contains the outcome of one workflow for a merged PR
+type WorkflowOutcomeEvent struct {
+	WorkflowName string
+	PRNumber     int
+	PRURL        string
+	SourceRepo   string
+	TargetRepo   string
+	TargetBranch string
+	TargetPRURL  string   // PR opened in the target repo, for the pull request commit strategy
+	Files        []string // Target paths copied
+	Deletions    []string // Target paths removed by sync
+	Errors       []string
+}
+
+// Succeeded returns true if the workflow had no errors
+func (e *WorkflowOutcomeEvent) Succeeded() bool {
+	return len(e.Errors) == 0
+}
+
+// BuildFailedEvent contains information about failed check runs on a commit a workflow copied to its destination
+type BuildFailedEvent struct {
+	WorkflowName string
+	PRNumber     int
+	PRURL        string
+	SourceRepo   string
+	TargetRepo   string
+	TargetBranch string
+	CommitSHA    string   // Commit on the target branch the check runs ran on
+	CommitURL    string
+	FailedChecks []string // "name: conclusion" for each failed check run
+	TimedOut     bool     // The check runs didn't finish before the workflow's verify_build timeout
+}
+
+// ApprovalGateEvent contains information about a PR held by a workflow's approval gate that wasn't approved
+// before the timeout, or couldn't be merged once it was
+type ApprovalGateEvent struct {
+	WorkflowName   string
+	PRNumber       int
+	PRURL          string
+	SourceRepo     string
+	TargetRepo     string
+	TargetBranch   string
+	TargetPRNumber int
+	TargetPRURL    string
+	FailedChecks   []string // "name: conclusion" for each required check run that failed
+	WaitingOn      []string // Required check runs and reviews that hadn't passed
+	TimedOut       bool     // The PR wasn't approved before the approval gate's timeout
+	Action         string   // What the copier did with the PR, such as "left open" or "closed"
+}
+
+// ShadowRunEvent contains the changes a workflow in shadow mode would have made for a merged PR
+type ShadowRunEvent struct {
+	WorkflowName string
+	PRNumber     int
+	PRURL        string
+	SourceRepo   string
+	TargetRepo   string
+	TargetBranch string
+	Diffs        []ShadowFileDiff // Destination files that would have changed
+	Errors       []string
+}
+
+// maxShadowDiffChars caps the diff text in a shadow run message, which Slack truncates past a few
+// thousand characters
+const maxShadowDiffChars = 3000
+
 // DefaultSlackNotifier implements SlackNotifier using Slack webhooks
 type DefaultSlackNotifier struct {
-	webhookURL string
-	enabled    bool
-	channel    string
-	username   string
-	iconEmoji  string
-	httpClient *http.Client
+	client    *notify.Client
+	enabled   bool
+	channel   string
+	username  string
+	iconEmoji string
 }
 
 // NewSlackNotifier creates a new Slack notifier
@@ -81,14 +172,11 @@ func NewSlackNotifier(webhookURL, channel, username, iconEmoji string) SlackNoti
 	enabled := webhookURL != ""
 	
 	return &DefaultSlackNotifier{
-		webhookURL: webhookURL,
-		enabled:    enabled,
-		channel:    channel,
-		username:   username,
-		iconEmoji:  iconEmoji,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		client:    notify.NewClient(webhookURL),
+		enabled:   enabled,
+		channel:   channel,
+		username:  username,
+		iconEmoji: iconEmoji,
 	}
 }
 
@@ -108,6 +196,17 @@ func (sn *DefaultSlackNotifier) NotifyPRProcessed(ctx context.Context, event *PR
 		color = "warning" // yellow
 	}
 	
+	fields := []SlackField{
+		{Title: "Repository", Value: event.SourceRepo, Short: true},
+		{Title: "Files Matched", Value: fmt.Sprintf("%d", event.FilesMatched), Short: true},
+		{Title: "Files Copied", Value: fmt.Sprintf("%d", event.FilesCopied), Short: true},
+		{Title: "Files Failed", Value: fmt.Sprintf("%d", event.FilesFailed), Short: true},
+	}
+	if event.DryRunFiles > 0 {
+		fields = append(fields, SlackField{Title: "Dry Run (not copied)", Value: fmt.Sprintf("%d", event.DryRunFiles), Short: true})
+	}
+	fields = append(fields, SlackField{Title: "Processing Time", Value: event.ProcessingTime.String(), Short: true})
+
 	message := &SlackMessage{
 		Channel:   sn.channel,
 		Username:  sn.username,
@@ -115,16 +214,10 @@ func (sn *DefaultSlackNotifier) NotifyPRProcessed(ctx context.Context, event *PR
 		Attachments: []SlackAttachment{
 			{
 				Color:      color,
-				Title:      fmt.Sprintf("✅ PR #%d Processed", event.PRNumber),
+				Title:      fmt.Sprintf("✅ %s Processed", changeLabel(event.PRNumber)),
 				TitleLink:  event.PRURL,
 				Text:       event.PRTitle,
-				Fields: []SlackField{
-					{Title: "Repository", Value: event.SourceRepo, Short: true},
-					{Title: "Files Matched", Value: fmt.Sprintf("%d", event.FilesMatched), Short: true},
-					{Title: "Files Copied", Value: fmt.Sprintf("%d", event.FilesCopied), Short: true},
-					{Title: "Files Failed", Value: fmt.Sprintf("%d", event.FilesFailed), Short: true},
-					{Title: "Processing Time", Value: event.ProcessingTime.String(), Short: true},
-				},
+				Fields:     fields,
 				Footer:     "Examples Copier",
 				FooterIcon: "https://github.githubassets.com/images/modules/logos_page/GitHub-Mark.png",
 				Timestamp:  time.Now().Unix(),
@@ -199,7 +292,7 @@ func (sn *DefaultSlackNotifier) NotifyFilesCopied(ctx context.Context, event *Fi
 		Attachments: []SlackAttachment{
 			{
 				Color:      "good", // green
-				Title:      fmt.Sprintf("📋 Files Copied from PR #%d", event.PRNumber),
+				Title:      fmt.Sprintf("📋 Files Copied from %s", changeLabel(event.PRNumber)),
 				Text:       filesText,
 				Fields: []SlackField{
 					{Title: "Source", Value: event.SourceRepo, Short: true},
@@ -232,7 +325,7 @@ func (sn *DefaultSlackNotifier) NotifyDeprecation(ctx context.Context, event *De
 		Attachments: []SlackAttachment{
 			{
 				Color:      "warning", // yellow
-				Title:      fmt.Sprintf("⚠️ Files Deprecated from PR #%d", event.PRNumber),
+				Title:      fmt.Sprintf("⚠️ Files Deprecated from %s", changeLabel(event.PRNumber)),
 				Text:       filesText,
 				Fields: []SlackField{
 					{Title: "Repository", Value: event.SourceRepo, Short: true},
@@ -248,67 +341,295 @@ func (sn *DefaultSlackNotifier) NotifyDeprecation(ctx context.Context, event *De
 	return sn.sendMessage(ctx, message)
 }
 
-// sendMessage sends a message to Slack
-func (sn *DefaultSlackNotifier) sendMessage(ctx context.Context, message *SlackMessage) error {
-	payload, err := json.Marshal(message)
-	if err != nil {
-		return fmt.Errorf("failed to marshal slack message: %w", err)
+// NotifySecretsDetected sends a notification when a file is blocked because it contains potential secrets
+func (sn *DefaultSlackNotifier) NotifySecretsDetected(ctx context.Context, event *SecretsDetectedEvent) error {
+	if !sn.enabled {
+		return nil
 	}
 	
-	req, err := http.NewRequestWithContext(ctx, "POST", sn.webhookURL, bytes.NewBuffer(payload))
-	if err != nil {
-		return fmt.Errorf("failed to create slack request: %w", err)
+	// Only redacted values are included so the alert itself does not leak the secret
+	findings := make([]string, 0, len(event.Findings))
+	for _, finding := range event.Findings {
+		findings = append(findings, fmt.Sprintf("line %d: %s (%s)", finding.Line, finding.Rule, finding.Redacted))
 	}
 	
-	req.Header.Set("Content-Type", "application/json")
-	
-	resp, err := sn.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send slack message: %w", err)
+	message := &SlackMessage{
+		Channel:   sn.channel,
+		Username:  sn.username,
+		IconEmoji: sn.iconEmoji,
+		Attachments: []SlackAttachment{
+			{
+				Color:      "danger", // red
+				Title:      fmt.Sprintf("🔒 Potential Secrets Detected in %s - Copy Blocked", changeLabel(event.PRNumber)),
+				Text:       fmt.Sprintf("`%s` was not copied to %s.\n```\n%s```", event.FilePath, event.TargetRepo, formatFileList(findings)),
+				Fields: []SlackField{
+					{Title: "Source", Value: event.SourceRepo, Short: true},
+					{Title: "Target", Value: event.TargetRepo, Short: true},
+					{Title: "Workflow", Value: event.WorkflowName, Short: true},
+					{Title: "Findings", Value: fmt.Sprintf("%d", len(event.Findings)), Short: true},
+				},
+				Footer:     "Examples Copier",
+				FooterIcon: "https://github.githubassets.com/images/modules/logos_page/GitHub-Mark.png",
+				Timestamp:  time.Now().Unix(),
+			},
+		},
 	}
-	defer resp.Body.Close()
 	
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("slack returned non-200 status: %d", resp.StatusCode)
+	return sn.sendMessage(ctx, message)
+}
+
+// NotifyWorkflowOutcome sends a summary of what one workflow copied for a merged PR, and any errors
+func (sn *DefaultSlackNotifier) NotifyWorkflowOutcome(ctx context.Context, event *WorkflowOutcomeEvent) error {
+	if !sn.enabled {
+		return nil
 	}
-	
-	return nil
+
+	color := "good" // green
+	title := fmt.Sprintf("✅ Workflow %s Copied %s", event.WorkflowName, changeLabel(event.PRNumber))
+	if !event.Succeeded() {
+		color = "danger" // red
+		title = fmt.Sprintf("❌ Workflow %s Failed for %s", event.WorkflowName, changeLabel(event.PRNumber))
+	}
+	titleLink := event.TargetPRURL
+	if titleLink == "" {
+		titleLink = event.PRURL
+	}
+
+	// Limit files shown to first 10
+	files := append(append([]string(nil), event.Files...), event.Deletions...)
+	text := ""
+	if len(files) > 10 {
+		text = fmt.Sprintf("```\n%s\n... and %d more```", formatFileList(files[:10]), len(files)-10)
+	} else if len(files) > 0 {
+		text = fmt.Sprintf("```\n%s```", formatFileList(files))
+	}
+
+	source := event.SourceRepo
+	if event.PRURL != "" {
+		source = fmt.Sprintf("<%s|%s#%d>", event.PRURL, event.SourceRepo, event.PRNumber)
+		if event.PRNumber == 0 {
+			source = fmt.Sprintf("<%s|%s push>", event.PRURL, event.SourceRepo)
+		}
+	}
+	fields := []SlackField{
+		{Title: "Source", Value: source, Short: true},
+		{Title: "Target", Value: fmt.Sprintf("%s (%s)", event.TargetRepo, event.TargetBranch), Short: true},
+		{Title: "Files Copied", Value: fmt.Sprintf("%d", len(event.Files)), Short: true},
+	}
+	if len(event.Deletions) > 0 {
+		fields = append(fields, SlackField{Title: "Files Removed", Value: fmt.Sprintf("%d", len(event.Deletions)), Short: true})
+	}
+	if event.TargetPRURL != "" {
+		fields = append(fields, SlackField{Title: "Target PR", Value: event.TargetPRURL, Short: false})
+	}
+	if len(event.Errors) > 0 {
+		fields = append(fields, SlackField{Title: "Errors", Value: formatFileList(event.Errors), Short: false})
+	}
+
+	message := &SlackMessage{
+		Channel:   sn.channel,
+		Username:  sn.username,
+		IconEmoji: sn.iconEmoji,
+		Attachments: []SlackAttachment{
+			{
+				Color:      color,
+				Title:      title,
+				TitleLink:  titleLink,
+				Text:       text,
+				Fields:     fields,
+				Footer:     "Examples Copier",
+				FooterIcon: "https://github.githubassets.com/images/modules/logos_page/GitHub-Mark.png",
+				Timestamp:  time.Now().Unix(),
+			},
+		},
+	}
+
+	return sn.sendMessage(ctx, message)
+}
+
+// NotifyBuildFailed sends a notification when the destination's check runs fail on a commit the copier made
+func (sn *DefaultSlackNotifier) NotifyBuildFailed(ctx context.Context, event *BuildFailedEvent) error {
+	if !sn.enabled {
+		return nil
+	}
+
+	title := fmt.Sprintf("🔥 Destination Build Failed After Workflow %s Copied %s", event.WorkflowName, changeLabel(event.PRNumber))
+	if event.TimedOut && len(event.FailedChecks) == 0 {
+		title = fmt.Sprintf("⏱️ Destination Build Didn't Finish After Workflow %s Copied %s", event.WorkflowName, changeLabel(event.PRNumber))
+	}
+
+	source := event.SourceRepo
+	if event.PRURL != "" {
+		source = fmt.Sprintf("<%s|%s>", event.PRURL, event.SourceRepo)
+	}
+	commit := ShortSHA(event.CommitSHA)
+	fields := []SlackField{
+		{Title: "Source", Value: source, Short: true},
+		{Title: "Target", Value: fmt.Sprintf("%s (%s)", event.TargetRepo, event.TargetBranch), Short: true},
+		{Title: "Commit", Value: commit, Short: true},
+	}
+	if len(event.FailedChecks) > 0 {
+		fields = append(fields, SlackField{Title: "Failed Checks", Value: formatFileList(event.FailedChecks), Short: false})
+	}
+
+	message := &SlackMessage{
+		Channel:   sn.channel,
+		Username:  sn.username,
+		IconEmoji: sn.iconEmoji,
+		Attachments: []SlackAttachment{
+			{
+				Color:      "danger", // red
+				Title:      title,
+				TitleLink:  event.CommitURL,
+				Fields:     fields,
+				Footer:     "Examples Copier",
+				FooterIcon: "https://github.githubassets.com/images/modules/logos_page/GitHub-Mark.png",
+				Timestamp:  time.Now().Unix(),
+			},
+		},
+	}
+
+	return sn.sendMessage(ctx, message)
+}
+
+// NotifyApprovalGate sends a notification when a PR held by an approval gate isn't merged as approved
+func (sn *DefaultSlackNotifier) NotifyApprovalGate(ctx context.Context, event *ApprovalGateEvent) error {
+	if !sn.enabled {
+		return nil
+	}
+
+	title := fmt.Sprintf("⏳ PR #%d From Workflow %s Wasn't Approved in Time", event.TargetPRNumber, event.WorkflowName)
+	color := "warning"
+	if !event.TimedOut {
+		title = fmt.Sprintf("❌ Approved PR #%d From Workflow %s Couldn't Be Merged", event.TargetPRNumber, event.WorkflowName)
+		color = "danger"
+	}
+
+	source := event.SourceRepo
+	if event.PRURL != "" {
+		source = fmt.Sprintf("<%s|%s>", event.PRURL, event.SourceRepo)
+	}
+	fields := []SlackField{
+		{Title: "Source", Value: source, Short: true},
+		{Title: "Target", Value: fmt.Sprintf("%s (%s)", event.TargetRepo, event.TargetBranch), Short: true},
+		{Title: "Outcome", Value: event.Action, Short: false},
+	}
+	if len(event.FailedChecks) > 0 {
+		fields = append(fields, SlackField{Title: "Failed Checks", Value: formatFileList(event.FailedChecks), Short: false})
+	}
+	if len(event.WaitingOn) > 0 {
+		fields = append(fields, SlackField{Title: "Waiting On", Value: formatFileList(event.WaitingOn), Short: false})
+	}
+
+	message := &SlackMessage{
+		Channel:   sn.channel,
+		Username:  sn.username,
+		IconEmoji: sn.iconEmoji,
+		Attachments: []SlackAttachment{
+			{
+				Color:      color,
+				Title:      title,
+				TitleLink:  event.TargetPRURL,
+				Fields:     fields,
+				Footer:     "Examples Copier",
+				FooterIcon: "https://github.githubassets.com/images/modules/logos_page/GitHub-Mark.png",
+				Timestamp:  time.Now().Unix(),
+			},
+		},
+	}
+
+	return sn.sendMessage(ctx, message)
+}
+
+func (sn *DefaultSlackNotifier) NotifyShadowRun(ctx context.Context, event *ShadowRunEvent) error {
+	if !sn.enabled {
+		return nil
+	}
+
+	title := fmt.Sprintf("👀 Shadow Workflow %s Would Change %d Files for %s", event.WorkflowName, len(event.Diffs), changeLabel(event.PRNumber))
+	color := "#439FE0" // blue
+	if len(event.Errors) > 0 {
+		color = "warning"
+	}
+
+	// Diffs are shown in full until they'd pass maxShadowDiffChars; the rest are listed by path
+	var diffText strings.Builder
+	var notShown []string
+	for _, d := range event.Diffs {
+		diff := d.Diff
+		if diff == "" {
+			diff = fmt.Sprintf("Binary file %s %s\n", d.Path, d.Status)
+		}
+		if diffText.Len()+len(diff) > maxShadowDiffChars {
+			notShown = append(notShown, fmt.Sprintf("%s (%s)", d.Path, d.Status))
+			continue
+		}
+		diffText.WriteString(diff)
+	}
+	text := ""
+	if diffText.Len() > 0 {
+		text = fmt.Sprintf("```\n%s```", diffText.String())
+	}
+
+	source := event.SourceRepo
+	if event.PRURL != "" {
+		source = fmt.Sprintf("<%s|%s>", event.PRURL, event.SourceRepo)
+	}
+	fields := []SlackField{
+		{Title: "Source", Value: source, Short: true},
+		{Title: "Target", Value: fmt.Sprintf("%s (%s)", event.TargetRepo, event.TargetBranch), Short: true},
+	}
+	if len(notShown) > 0 {
+		fields = append(fields, SlackField{Title: "Diffs Not Shown", Value: formatFileList(notShown), Short: false})
+	}
+	if len(event.Errors) > 0 {
+		fields = append(fields, SlackField{Title: "Errors", Value: formatFileList(event.Errors), Short: false})
+	}
+
+	message := &SlackMessage{
+		Channel:   sn.channel,
+		Username:  sn.username,
+		IconEmoji: sn.iconEmoji,
+		Attachments: []SlackAttachment{
+			{
+				Color:      color,
+				Title:      title,
+				TitleLink:  event.PRURL,
+				Text:       text,
+				Fields:     fields,
+				Footer:     "Examples Copier (shadow mode: nothing was written)",
+				FooterIcon: "https://github.githubassets.com/images/modules/logos_page/GitHub-Mark.png",
+				Timestamp:  time.Now().Unix(),
+			},
+		},
+	}
+
+	return sn.sendMessage(ctx, message)
+}
+
+// sendMessage sends a message to Slack
+func (sn *DefaultSlackNotifier) sendMessage(ctx context.Context, message *SlackMessage) error {
+	return sn.client.Send(ctx, message)
 }
 
 // formatFileList formats a list of files for display
 func formatFileList(files []string) string {
-	result := ""
-	for _, file := range files {
-		result += "• " + file + "\n"
-	}
-	return result
+	return notify.FormatList(files)
 }
 
 // SlackMessage represents a Slack message
-type SlackMessage struct {
-	Channel     string             `json:"channel,omitempty"`
-	Username    string             `json:"username,omitempty"`
-	IconEmoji   string             `json:"icon_emoji,omitempty"`
-	Text        string             `json:"text,omitempty"`
-	Attachments []SlackAttachment  `json:"attachments,omitempty"`
-}
+type SlackMessage = notify.Message
 
 // SlackAttachment represents a Slack message attachment
-type SlackAttachment struct {
-	Color      string       `json:"color,omitempty"`
-	Title      string       `json:"title,omitempty"`
-	TitleLink  string       `json:"title_link,omitempty"`
-	Text       string       `json:"text,omitempty"`
-	Fields     []SlackField `json:"fields,omitempty"`
-	Footer     string       `json:"footer,omitempty"`
-	FooterIcon string       `json:"footer_icon,omitempty"`
-	Timestamp  int64        `json:"ts,omitempty"`
-}
+type SlackAttachment = notify.Attachment
 
 // SlackField represents a field in a Slack attachment
-type SlackField struct {
-	Title string `json:"title"`
-	Value string `json:"value"`
-	Short bool   `json:"short"`
-}
+type SlackField = notify.Field
 
+// changeLabel names the change that triggered a copy: "PR #12", or "Push" for pushes, which have no number
+func changeLabel(prNumber int) string {
+	if prNumber == 0 {
+		return "Push"
+	}
+	return fmt.Sprintf("PR #%d", prNumber)
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// sourceIgnoreCacheTTL is how long a source repo's ignore file is used before it's fetched again
+const sourceIgnoreCacheTTL = 5 * time.Minute
+
+// sourceIgnoreEntry is a source repo's ignore file, as last fetched
+type sourceIgnoreEntry struct {
+	lines     []string // nil if the repo doesn't have one
+	fetchedAt time.Time
+}
+
+var (
+	sourceIgnoreCacheMu sync.Mutex
+	// sourceIgnoreCache holds source repos' ignore files, keyed by platform:repo@branch:file
+	sourceIgnoreCache = make(map[string]sourceIgnoreEntry)
+)
+
+// loadSourceIgnores sets each workflow's SourceIgnore to the ignore file in its source repo and branch,
+// fetching each repo's file at most once every sourceIgnoreCacheTTL. Workflows with branch patterns copy
+// from many branches, so they don't use an ignore file.
+func loadSourceIgnores(ctx context.Context, yamlConfig *types.YAMLConfig, config *configs.Config) {
+	if config.CopierIgnoreFile == "" {
+		return
+	}
+	for i := range yamlConfig.Workflows {
+		source := yamlConfig.Workflows[i].Source
+		if source.IsBranchPattern() {
+			continue
+		}
+		yamlConfig.Workflows[i].SourceIgnore = sourceIgnoreLines(ctx, source, config.CopierIgnoreFile)
+	}
+}
+
+// sourceIgnoreLines returns the lines of the ignore file in the source repo and branch, from the cache if it
+// was fetched recently. If the file can't be fetched, the last copy fetched is used.
+func sourceIgnoreLines(ctx context.Context, source types.Source, fileName string) []string {
+	key := fmt.Sprintf("%s:%s@%s:%s", source.GetPlatform(), source.Repo, source.Branch, fileName)
+	sourceIgnoreCacheMu.Lock()
+	entry, ok := sourceIgnoreCache[key]
+	sourceIgnoreCacheMu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < sourceIgnoreCacheTTL {
+		return entry.lines
+	}
+
+	content, found, err := getSourceFile(ctx, source, fileName, source.Branch)
+	if err != nil {
+		LogWarningCtx(ctx, "failed to read source ignore file", map[string]interface{}{
+			"source_repo":   source.Repo,
+			"source_branch": source.Branch,
+			"path":          fileName,
+			"error":         err.Error(),
+		})
+		return entry.lines
+	}
+
+	entry = sourceIgnoreEntry{fetchedAt: time.Now()}
+	if found {
+		entry.lines = strings.Split(content, "\n")
+		LogInfoCtx(ctx, "loaded source ignore file", map[string]interface{}{
+			"source_repo":   source.Repo,
+			"source_branch": source.Branch,
+			"path":          fileName,
+		})
+	}
+	sourceIgnoreCacheMu.Lock()
+	sourceIgnoreCache[key] = entry
+	sourceIgnoreCacheMu.Unlock()
+	return entry.lines
+}
+
+// getSourceFile returns a file's content from a source repo, on GitHub, GitLab, or Bitbucket, at ref. found
+// is false if the file doesn't exist.
+func getSourceFile(ctx context.Context, source types.Source, filePath string, ref string) (string, bool, error) {
+	switch source.GetPlatform() {
+	case types.SourcePlatformGitLab:
+		return GetGitLabClient().GetFile(ctx, source.Repo, filePath, ref)
+	case types.SourcePlatformBitbucket:
+		return GetBitbucketClient().GetFile(ctx, source.Repo, filePath, ref)
+	}
+
+	owner, _ := parseRepoPath(source.Repo)
+	client, err := GetRestClientForOrg(owner)
+	if err != nil {
+		return "", false, fmt.Errorf("get GitHub client for org %s: %w", owner, err)
+	}
+	return (&githubProvider{client: client}).GetFile(ctx, source.Repo, filePath, ref)
+}
+
+// sourceIgnoreRule is a pattern line of an ignore file
+type sourceIgnoreRule struct {
+	pattern  string
+	negate   bool // "!pattern" re-includes files an earlier line ignored
+	dirOnly  bool // "pattern/" only matches directories
+	anchored bool // patterns with a slash match from the repo root; others match a name at any depth
+}
+
+// parseSourceIgnore returns the rules in an ignore file's lines, which use .gitignore syntax
+func parseSourceIgnore(lines []string) []sourceIgnoreRule {
+	var rules []sourceIgnoreRule
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule sourceIgnoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// matches reports whether the rule matches a file or directory path
+func (r sourceIgnoreRule) matches(p string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !r.anchored {
+		p = path.Base(p)
+	}
+	matched, err := doublestar.Match(r.pattern, p)
+	return err == nil && matched
+}
+
+// sourceIgnored reports whether an ignore file's lines ignore a source file. As with .gitignore, the last
+// matching line decides, and a file in an ignored directory can't be re-included.
+func sourceIgnored(lines []string, filePath string) bool {
+	rules := parseSourceIgnore(lines)
+	if len(rules) == 0 {
+		return false
+	}
+
+	ignored := func(p string, isDir bool) bool {
+		result := false
+		for _, rule := range rules {
+			if rule.matches(p, isDir) {
+				result = !rule.negate
+			}
+		}
+		return result
+	}
+
+	dirs := ancestorDirs(filePath)
+	for _, dir := range dirs[1:] {
+		if ignored(dir, true) {
+			return true
+		}
+	}
+	return ignored(filePath, false)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceIgnored(t *testing.T) {
+	lines := strings.Split(`# Scratch work isn't copied
+*.tmp
+/notes.md
+drafts/
+examples/**/internal/*.py
+!examples/keep.tmp
+private/
+!private/public.py
+\#literal.py
+`, "\n")
+
+	tests := []struct {
+		path    string
+		ignored bool
+	}{
+		{"scratch.tmp", true},
+		{"examples/go/scratch.tmp", true},
+		{"examples/keep.tmp", false},
+		{"notes.md", true},
+		{"examples/notes.md", false},
+		{"drafts/example.py", true},
+		{"examples/drafts/example.py", true},
+		{"drafts", false},
+		{"examples/python/internal/helper.py", true},
+		{"examples/python/helper.py", false},
+		{"private/public.py", true},
+		{"#literal.py", true},
+		{"examples/main.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.ignored, sourceIgnored(lines, tt.path))
+		})
+	}
+
+	assert.False(t, sourceIgnored(nil, "scratch.tmp"))
+}
+
+func TestSourceExcludedBy(t *testing.T) {
+	wp := &workflowProcessor{patternMatcher: NewPatternMatcher(), pathTransformer: NewPathTransformer()}
+	workflow := types.Workflow{
+		Name:            "wf",
+		Exclude:         []string{"**/*_test.go"},
+		SourceIgnore:    []string{"*.tmp", "!main_test.go"},
+		Transformations: []types.Transformation{{Move: &types.MoveTransform{From: "examples", To: "code"}}},
+	}
+
+	assert.Equal(t, "workflow exclude patterns", wp.sourceExcludedBy(workflow, "examples/main_test.go"),
+		"the ignore file can't re-include files the workflow excludes")
+	assert.Equal(t, "source ignore file", wp.sourceExcludedBy(workflow, "examples/scratch.tmp"))
+	assert.Empty(t, wp.sourceExcludedBy(workflow, "examples/main.go"))
+
+	_, ok := wp.mapSourcePath(context.Background(), workflow, "examples/scratch.tmp")
+	assert.False(t, ok)
+	targetPath, ok := wp.mapSourcePath(context.Background(), workflow, "examples/main.go")
+	assert.True(t, ok)
+	assert.Equal(t, "code/main.go", targetPath)
+}
+
+func TestLoadSourceIgnores(t *testing.T) {
+	t.Setenv(configs.GitLabBaseURL, "https://gitlab.example.com")
+	originalClient := HTTPClient
+	t.Cleanup(func() {
+		HTTPClient = originalClient
+		sourceIgnoreCacheMu.Lock()
+		sourceIgnoreCache = make(map[string]sourceIgnoreEntry)
+		sourceIgnoreCacheMu.Unlock()
+	})
+
+	requests := make(map[string]int)
+	HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests[req.URL.EscapedPath()]++
+		status, body := http.StatusNotFound, `{"message":"404 File Not Found"}`
+		if strings.Contains(req.URL.EscapedPath(), "/docs%2Fwith-ignore/") {
+			status = http.StatusOK
+			body = `{"file_path":".copierignore","encoding":"base64","content":"` +
+				base64.StdEncoding.EncodeToString([]byte("*.tmp\n")) + `"}`
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}, Request: req}, nil
+	})}
+
+	workflow := func(repo, branch string) types.Workflow {
+		return types.Workflow{Name: repo, Source: types.Source{Platform: types.SourcePlatformGitLab, Repo: repo, Branch: branch}}
+	}
+	newConfig := func() *types.YAMLConfig {
+		return &types.YAMLConfig{Workflows: []types.Workflow{
+			workflow("docs/with-ignore", "main"),
+			workflow("docs/without-ignore", "main"),
+			workflow("docs/with-ignore", "release/*"),
+		}}
+	}
+	config := &configs.Config{CopierIgnoreFile: ".copierignore"}
+
+	yamlConfig := newConfig()
+	loadSourceIgnores(context.Background(), yamlConfig, config)
+	assert.Equal(t, []string{"*.tmp", ""}, yamlConfig.Workflows[0].SourceIgnore)
+	assert.Nil(t, yamlConfig.Workflows[1].SourceIgnore)
+	assert.Nil(t, yamlConfig.Workflows[2].SourceIgnore, "workflows with branch patterns don't use an ignore file")
+	assert.Len(t, requests, 2)
+
+	// Each repo's file is cached, including a missing one
+	yamlConfig = newConfig()
+	loadSourceIgnores(context.Background(), yamlConfig, config)
+	assert.Equal(t, []string{"*.tmp", ""}, yamlConfig.Workflows[0].SourceIgnore)
+	for path, count := range requests {
+		assert.Equal(t, 1, count, path)
+	}
+
+	// Until it expires
+	sourceIgnoreCacheMu.Lock()
+	for key, entry := range sourceIgnoreCache {
+		entry.fetchedAt = time.Now().Add(-sourceIgnoreCacheTTL)
+		sourceIgnoreCache[key] = entry
+	}
+	sourceIgnoreCacheMu.Unlock()
+	loadSourceIgnores(context.Background(), newConfig(), config)
+	for path, count := range requests {
+		assert.Equal(t, 2, count, path)
+	}
+
+	// An empty file name turns ignore files off
+	yamlConfig = newConfig()
+	loadSourceIgnores(context.Background(), yamlConfig, &configs.Config{})
+	assert.Nil(t, yamlConfig.Workflows[0].SourceIgnore)
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// tokenRefreshWindow is how long before expiry an installation token is replaced. Installation tokens
+// last an hour, so refreshing early keeps a long upload sequence from failing with a 401 partway through.
+const tokenRefreshWindow = 5 * time.Minute
+
+// defaultInstallation is the name used in logs and metrics for the token of INSTALLATION_ID
+const defaultInstallation = "(default)"
+
+// installationName returns the name of an org's installation for logs and metrics
+func installationName(org string) string {
+	if org == "" {
+		return defaultInstallation
+	}
+	return org
+}
+
+// Reasons a new installation token was issued
+const (
+	tokenReasonMissing       = "missing"
+	tokenReasonNearingExpiry = "nearing_expiry"
+	tokenReasonExpired       = "expired"
+)
+
+// tokenCacheMu guards installationTokenCache and installationTokenExpiry
+var tokenCacheMu sync.Mutex
+
+// installationTokenExpiry holds when each cached token expires, keyed like installationTokenCache. Tokens
+// without an entry, such as those set by SetInstallationTokenForOrg, are never refreshed.
+var installationTokenExpiry = make(map[string]time.Time)
+
+// tokenNow returns the current time; replaced in tests
+var tokenNow = time.Now
+
+// orgTokenSource is an oauth2.TokenSource for an org's installation token, or the default installation's
+// if org is empty. Each request gets the cached token, which is replaced first if it's within
+// tokenRefreshWindow of expiring.
+type orgTokenSource struct {
+	org string
+}
+
+// Token returns the org's installation token, refreshing it if needed
+func (s orgTokenSource) Token() (*oauth2.Token, error) {
+	token, err := installationTokenForOrg(s.org)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: token}, nil
+}
+
+// installationTokenForOrg returns the cached installation token for an org, or the default installation's
+// if org is empty. A new token is issued if there isn't one, or if the cached one is within
+// tokenRefreshWindow of expiring.
+func installationTokenForOrg(org string) (string, error) {
+	tokenCacheMu.Lock()
+	defer tokenCacheMu.Unlock()
+
+	cached := installationTokenCache[org]
+	expiresAt, hasExpiry := installationTokenExpiry[org]
+	if cached != "" && !hasExpiry {
+		return cached, nil
+	}
+	reason := refreshReason(cached, expiresAt)
+	if reason == "" {
+		return cached, nil
+	}
+
+	token, newExpiry, err := issueInstallationTokenForOrg(org)
+	if err != nil {
+		oauthToken, fallbackErr := fallbackToken(installationName(org), cached, expiresAt, err)
+		if fallbackErr != nil {
+			return "", fallbackErr
+		}
+		return oauthToken.AccessToken, nil
+	}
+
+	installationTokenCache[org] = token
+	installationTokenExpiry[org] = newExpiry
+	recordTokenIssued(installationName(org), newExpiry, reason)
+	return token, nil
+}
+
+// refreshReason returns why a token needs to be replaced, or "" if it can still be used. Tokens
+// without an expiry never need replacing.
+func refreshReason(token string, expiresAt time.Time) string {
+	switch {
+	case token == "":
+		return tokenReasonMissing
+	case expiresAt.IsZero():
+		return ""
+	case !tokenNow().Before(expiresAt):
+		return tokenReasonExpired
+	case expiresAt.Sub(tokenNow()) <= tokenRefreshWindow:
+		return tokenReasonNearingExpiry
+	default:
+		return ""
+	}
+}
+
+// fallbackToken records a failed refresh and returns the current token if it hasn't expired yet
+func fallbackToken(installation, token string, expiresAt time.Time, err error) (*oauth2.Token, error) {
+	recordTokenFailure(installation, err)
+	if token != "" && tokenNow().Before(expiresAt) {
+		LogWarning(fmt.Sprintf("Using installation token for %s until it expires at %s", installation, expiresAt.Format(time.RFC3339)))
+		return &oauth2.Token{AccessToken: token}, nil
+	}
+	return nil, err
+}
+
+// tokenStats counts installation token and JWT lifecycle events for the metrics endpoint
+var tokenStats struct {
+	mu        sync.Mutex
+	issued    int64
+	refreshed int64
+	failures  int64
+	jwts      int64
+}
+
+// recordTokenIssued counts and logs a new installation token. Tokens issued because the previous one
+// was expiring or had expired count as refreshes.
+func recordTokenIssued(installation string, expiresAt time.Time, reason string) {
+	tokenStats.mu.Lock()
+	tokenStats.issued++
+	if reason != tokenReasonMissing {
+		tokenStats.refreshed++
+	}
+	tokenStats.mu.Unlock()
+
+	message := "installation token issued"
+	if reason != tokenReasonMissing {
+		message = "installation token refreshed"
+	}
+	LogInfoCtx(context.Background(), message, map[string]interface{}{
+		"installation": installation,
+		"reason":       reason,
+		"expires_at":   expiresAt.Format(time.RFC3339),
+		"expires_in_s": int64(expiresAt.Sub(tokenNow()).Seconds()),
+	})
+}
+
+// recordTokenFailure counts and logs a failed installation token request
+func recordTokenFailure(installation string, err error) {
+	tokenStats.mu.Lock()
+	tokenStats.failures++
+	tokenStats.mu.Unlock()
+
+	LogErrorCtx(context.Background(), "installation token request failed", err, map[string]interface{}{
+		"installation": installation,
+	})
+}
+
+// recordJWTIssued counts and logs a new GitHub App JWT
+func recordJWTIssued(appID string, cachedUntil time.Time) {
+	tokenStats.mu.Lock()
+	tokenStats.jwts++
+	tokenStats.mu.Unlock()
+
+	LogDebug(fmt.Sprintf("GitHub App %s JWT issued (cached until %s)", appID, cachedUntil.Format(time.RFC3339)))
+}
+
+// AuthMetrics represents installation token lifecycle metrics
+type AuthMetrics struct {
+	TokensIssued    int64         `json:"tokens_issued"`
+	TokensRefreshed int64         `json:"tokens_refreshed"` // Issued because the previous token was expiring or had expired
+	TokenFailures   int64         `json:"token_failures"`
+	JWTsIssued      int64         `json:"jwts_issued"`
+	Tokens          []TokenStatus `json:"tokens"`
+}
+
+// TokenStatus is the expiry of one cached installation token
+type TokenStatus struct {
+	Installation     string    `json:"installation"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	ExpiresInSeconds int64     `json:"expires_in_seconds"`
+}
+
+// getAuthMetrics returns the token lifecycle counters and the expiry of each cached token
+func getAuthMetrics() AuthMetrics {
+	tokenStats.mu.Lock()
+	metrics := AuthMetrics{
+		TokensIssued:    tokenStats.issued,
+		TokensRefreshed: tokenStats.refreshed,
+		TokenFailures:   tokenStats.failures,
+		JWTsIssued:      tokenStats.jwts,
+		Tokens:          []TokenStatus{},
+	}
+	tokenStats.mu.Unlock()
+
+	tokenCacheMu.Lock()
+	defer tokenCacheMu.Unlock()
+	now := tokenNow()
+	for org, expiresAt := range installationTokenExpiry {
+		if expiresAt.IsZero() {
+			continue
+		}
+		metrics.Tokens = append(metrics.Tokens, TokenStatus{
+			Installation:     installationName(org),
+			ExpiresAt:        expiresAt,
+			ExpiresInSeconds: int64(expiresAt.Sub(now).Seconds()),
+		})
+	}
+	sort.Slice(metrics.Tokens, func(i, j int) bool {
+		return metrics.Tokens[i].Installation < metrics.Tokens[j].Installation
+	})
+	return metrics
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticCredentialStore serves fixed credentials by org
+type staticCredentialStore map[string]*AppCredentials
+
+func (s staticCredentialStore) Credentials(org string) (*AppCredentials, error) {
+	creds, ok := s[org]
+	if !ok {
+		return nil, fmt.Errorf("no credentials for %q", org)
+	}
+	return creds, nil
+}
+
+// roundTripFunc lets a function serve as an http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// fakeTokenAPI answers the GitHub App installation endpoints for "org", issuing numbered tokens that
+// expire an hour after the fake clock, and records the token each repo request was sent with
+type fakeTokenAPI struct {
+	mu       sync.Mutex
+	clock    time.Time
+	issued   int
+	fail     bool
+	repoAuth []string
+}
+
+func (f *fakeTokenAPI) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	respond := func(status int, body any) (*http.Response, error) {
+		b, _ := json.Marshal(body)
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(string(b))), Header: http.Header{}, Request: req}, nil
+	}
+	switch {
+	case req.URL.Path == "/app/installations":
+		return respond(http.StatusOK, []map[string]any{{"id": 1, "account": map[string]string{"login": "org"}}})
+	case req.URL.Path == "/app/installations/1/access_tokens":
+		if f.fail {
+			return respond(http.StatusBadGateway, map[string]string{"message": "bad gateway"})
+		}
+		f.issued++
+		return respond(http.StatusCreated, map[string]any{
+			"token":      fmt.Sprintf("token-%d", f.issued),
+			"expires_at": f.clock.Add(time.Hour),
+		})
+	default:
+		f.repoAuth = append(f.repoAuth, req.Header.Get("Authorization"))
+		return respond(http.StatusOK, map[string]string{"name": "dest"})
+	}
+}
+
+// useFakeTokenAPI points the auth globals at a fake API and clock, restoring them when the test ends
+func useFakeTokenAPI(t *testing.T) *fakeTokenAPI {
+	api := &fakeTokenAPI{clock: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	originalClient, originalNow := HTTPClient, tokenNow
+	originalCache, originalExpiry := installationTokenCache, installationTokenExpiry
+	originalJWTs, originalStore := jwtCache, credentialStore
+	t.Cleanup(func() {
+		HTTPClient, tokenNow = originalClient, originalNow
+		installationTokenCache, installationTokenExpiry = originalCache, originalExpiry
+		jwtCache, credentialStore = originalJWTs, originalStore
+	})
+
+	HTTPClient = &http.Client{Transport: api}
+	tokenNow = func() time.Time {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		return api.clock
+	}
+	installationTokenCache = make(map[string]string)
+	installationTokenExpiry = make(map[string]time.Time)
+	jwtCache = map[string]cachedJWT{"1": {token: "test-jwt", expiry: time.Now().Add(time.Hour)}}
+	credentialStore = staticCredentialStore{"org": {AppID: "1"}}
+	return api
+}
+
+func (f *fakeTokenAPI) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.clock = f.clock.Add(d)
+}
+
+func TestInstallationTokenForOrg_Lifecycle(t *testing.T) {
+	api := useFakeTokenAPI(t)
+	before := getAuthMetrics()
+
+	// Issued when missing, then reused while fresh
+	token, err := installationTokenForOrg("org")
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	api.advance(50 * time.Minute)
+	token, err = installationTokenForOrg("org")
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	// Refreshed within the refresh window
+	api.advance(6 * time.Minute)
+	token, err = installationTokenForOrg("org")
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+	after := getAuthMetrics()
+	assert.Equal(t, int64(2), after.TokensIssued-before.TokensIssued)
+	assert.Equal(t, int64(1), after.TokensRefreshed-before.TokensRefreshed)
+	require.Len(t, after.Tokens, 1)
+	assert.Equal(t, "org", after.Tokens[0].Installation)
+	assert.Equal(t, int64(3600), after.Tokens[0].ExpiresInSeconds)
+}
+
+func TestInstallationTokenForOrg_RefreshFailure(t *testing.T) {
+	api := useFakeTokenAPI(t)
+	_, err := installationTokenForOrg("org")
+	require.NoError(t, err)
+	before := getAuthMetrics()
+
+	// A token that hasn't expired yet is still used if the refresh fails
+	api.fail = true
+	api.advance(57 * time.Minute)
+	token, err := installationTokenForOrg("org")
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	// Once it has expired, the error is returned
+	api.advance(5 * time.Minute)
+	_, err = installationTokenForOrg("org")
+	assert.ErrorContains(t, err, "status 502")
+
+	assert.Equal(t, int64(2), getAuthMetrics().TokenFailures-before.TokenFailures)
+}
+
+func TestGetRestClientForOrg_RefreshesDuringLongSequence(t *testing.T) {
+	api := useFakeTokenAPI(t)
+
+	client, err := GetRestClientForOrg("org")
+	require.NoError(t, err)
+
+	_, _, err = client.Repositories.Get(t.Context(), "org", "dest")
+	require.NoError(t, err)
+
+	// The same client picks up a new token once the first one nears expiry
+	api.advance(58 * time.Minute)
+	_, _, err = client.Repositories.Get(t.Context(), "org", "dest")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, api.repoAuth)
+}
+
+func TestSetInstallationTokenForOrg_NeverRefreshed(t *testing.T) {
+	api := useFakeTokenAPI(t)
+	SetInstallationTokenForOrg("org", "static-token")
+
+	api.advance(24 * time.Hour)
+	token, err := installationTokenForOrg("org")
+	require.NoError(t, err)
+	assert.Equal(t, "static-token", token)
+	assert.Equal(t, 0, api.issued)
+}
+
+func TestRefreshReason(t *testing.T) {
+	originalNow := tokenNow
+	t.Cleanup(func() { tokenNow = originalNow })
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tokenNow = func() time.Time { return now }
+
+	assert.Equal(t, tokenReasonMissing, refreshReason("", time.Time{}))
+	assert.Equal(t, "", refreshReason("token", time.Time{}))
+	assert.Equal(t, "", refreshReason("token", now.Add(time.Hour)))
+	assert.Equal(t, tokenReasonNearingExpiry, refreshReason("token", now.Add(tokenRefreshWindow)))
+	assert.Equal(t, tokenReasonExpired, refreshReason("token", now))
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WebhookDelivery is a webhook delivery as it was received, kept so it can be replayed
+type WebhookDelivery struct {
+	ID         string    `json:"id" bson:"_id"` // The delivery ID the platform sent, or the request's correlation ID
+	Platform   string    `json:"platform" bson:"platform"`
+	EventType  string    `json:"event_type" bson:"event_type"`
+	ReceivedAt time.Time `json:"received_at" bson:"received_at"`
+	Payload    []byte    `json:"payload" bson:"payload"`
+}
+
+// WebhookArchiveStore holds recent webhook deliveries
+type WebhookArchiveStore interface {
+	Save(ctx context.Context, delivery *WebhookDelivery) error    // Adds the delivery, or replaces the delivery with the same ID
+	Get(ctx context.Context, id string) (*WebhookDelivery, error) // Returns nil if there's no delivery with the ID
+	Close(ctx context.Context) error
+}
+
+// WebhookArchive keeps the raw payloads of recent webhook deliveries, so operators can replay a delivery
+// after fixing a config bug without crafting its payload by hand. A nil WebhookArchive records nothing.
+type WebhookArchive struct {
+	store WebhookArchiveStore
+	now   func() time.Time
+}
+
+// NewWebhookArchive creates a webhook archive backed by the given store
+func NewWebhookArchive(store WebhookArchiveStore) *WebhookArchive {
+	return &WebhookArchive{store: store, now: time.Now}
+}
+
+// Record stores a delivery's payload under the request's correlation ID, which is the delivery ID GitHub,
+// GitLab, or Bitbucket sent. Errors are logged rather than returned so an archive outage doesn't affect
+// copying.
+func (a *WebhookArchive) Record(ctx context.Context, platform string, eventType string, payload []byte) {
+	if a == nil {
+		return
+	}
+	delivery := &WebhookDelivery{
+		ID:         CorrelationIDFromContext(ctx),
+		Platform:   platform,
+		EventType:  eventType,
+		ReceivedAt: a.now(),
+		Payload:    payload,
+	}
+	if delivery.ID == "" {
+		delivery.ID = newCorrelationID()
+	}
+	if err := a.store.Save(ctx, delivery); err != nil {
+		LogWarningCtx(ctx, "failed to archive webhook delivery", map[string]interface{}{
+			"delivery_id": delivery.ID,
+			"error":       err.Error(),
+		})
+	}
+}
+
+// Get returns the delivery with the given ID, or nil if it isn't in the archive
+func (a *WebhookArchive) Get(ctx context.Context, id string) (*WebhookDelivery, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return a.store.Get(ctx, id)
+}
+
+// Close closes the store
+func (a *WebhookArchive) Close(ctx context.Context) error {
+	if a == nil {
+		return nil
+	}
+	return a.store.Close(ctx)
+}
+
+// newWebhookArchive returns the webhook archive for the configured store, or nil if the archive is disabled
+func newWebhookArchive(ctx context.Context, config *configs.Config, mongoClient *SharedMongoClient) (*WebhookArchive, error) {
+	if config.WebhookArchiveSize <= 0 {
+		return nil, nil
+	}
+	if config.WebhookArchiveStore == configs.WebhookArchiveStoreMongoDB {
+		store, err := NewMongoWebhookArchiveStore(ctx, mongoClient, config.AuditDatabase, config.WebhookArchiveCollection, config.WebhookArchiveSize)
+		if err != nil {
+			return nil, err
+		}
+		return NewWebhookArchive(store), nil
+	}
+	return NewWebhookArchive(NewMemoryWebhookArchiveStore(config.WebhookArchiveSize)), nil
+}
+
+// ReplayHandler replays archived webhook deliveries for operators: POST /admin/replay/<delivery-id> converts
+// the delivery's payload with the event source for its platform and event type, as the config is now, and
+// processes the event like a new delivery. The replay is logged under its own correlation ID.
+func ReplayHandler(config *configs.Config, container *ServiceContainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validAdminToken(r, config.AdminToken) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if container.WebhookArchive == nil {
+			http.Error(w, "webhook archive is disabled; set WEBHOOK_ARCHIVE_SIZE to keep deliveries for replay", http.StatusNotFound)
+			return
+		}
+
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/replay"), "/")
+		if id == "" {
+			http.Error(w, "missing delivery ID; use /admin/replay/<delivery-id>", http.StatusBadRequest)
+			return
+		}
+		delivery, err := container.WebhookArchive.Get(r.Context(), id)
+		if err != nil {
+			LogErrorCtx(r.Context(), "failed to read webhook archive", err, nil)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if delivery == nil {
+			http.Error(w, fmt.Sprintf("delivery %q not found; only the last %d deliveries are kept", id, config.WebhookArchiveSize),
+				http.StatusNotFound)
+			return
+		}
+		source := container.EventSources.Webhook(delivery.Platform, delivery.EventType)
+		if source == nil {
+			http.Error(w, fmt.Sprintf("the copier doesn't handle %s %s events", delivery.Platform, delivery.EventType),
+				http.StatusUnprocessableEntity)
+			return
+		}
+
+		ctx := WithCorrelationID(r.Context(), newCorrelationID())
+		r = r.WithContext(ctx)
+		LogInfoCtx(ctx, "replaying webhook delivery", map[string]interface{}{
+			"delivery_id": delivery.ID,
+			"platform":    delivery.Platform,
+			"event_type":  delivery.EventType,
+			"received_at": delivery.ReceivedAt,
+		})
+		event := convertEvent(ctx, w, r, source, delivery.Payload, container)
+		if event == nil {
+			return
+		}
+		acceptCopyEvent(ctx, w, r, *event, config, container)
+	}
+}
+
+// MemoryWebhookArchiveStore implements WebhookArchiveStore in memory, keeping the most recent deliveries up
+// to a limit. Deliveries are lost when the process exits.
+type MemoryWebhookArchiveStore struct {
+	mu         sync.Mutex
+	size       int
+	deliveries []*WebhookDelivery // Oldest first
+}
+
+// NewMemoryWebhookArchiveStore creates an empty in-memory store that keeps up to size deliveries
+func NewMemoryWebhookArchiveStore(size int) *MemoryWebhookArchiveStore {
+	return &MemoryWebhookArchiveStore{size: size}
+}
+
+// Save adds or replaces a delivery, dropping the oldest deliveries past the size limit
+func (s *MemoryWebhookArchiveStore) Save(ctx context.Context, delivery *WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *delivery
+	for i, existing := range s.deliveries {
+		if existing.ID == delivery.ID {
+			s.deliveries = append(s.deliveries[:i], s.deliveries[i+1:]...)
+			break
+		}
+	}
+	s.deliveries = append(s.deliveries, &saved)
+	if len(s.deliveries) > s.size {
+		s.deliveries = s.deliveries[len(s.deliveries)-s.size:]
+	}
+	return nil
+}
+
+// Get returns a copy of the delivery with the given ID
+func (s *MemoryWebhookArchiveStore) Get(ctx context.Context, id string) (*WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, delivery := range s.deliveries {
+		if delivery.ID == id {
+			copied := *delivery
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+// Close does nothing for the in-memory store
+func (s *MemoryWebhookArchiveStore) Close(ctx context.Context) error { return nil }
+
+// MongoWebhookArchiveStore implements WebhookArchiveStore using a MongoDB collection, so deliveries survive
+// restarts and any instance can replay them
+type MongoWebhookArchiveStore struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+	size       int
+}
+
+// NewMongoWebhookArchiveStore returns a store backed by the given collection that keeps up to size
+// deliveries, connecting the shared client if it isn't yet
+func NewMongoWebhookArchiveStore(ctx context.Context, mongoClient *SharedMongoClient, database, collection string, size int) (*MongoWebhookArchiveStore, error) {
+	client, err := mongoClient.Connect(ctx, "the webhook archive store is mongodb")
+	if err != nil {
+		return nil, err
+	}
+
+	coll := client.Database(database).Collection(collection)
+	if _, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "received_at", Value: -1}},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return &MongoWebhookArchiveStore{client: client, collection: coll, size: size}, nil
+}
+
+// Save upserts a delivery by ID and removes deliveries older than the most recent size deliveries
+func (s *MongoWebhookArchiveStore) Save(ctx context.Context, delivery *WebhookDelivery) error {
+	if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": delivery.ID}, delivery, options.Replace().SetUpsert(true)); err != nil {
+		return err
+	}
+
+	// Find the oldest delivery to keep, and delete anything older
+	var oldest WebhookDelivery
+	opts := options.FindOne().SetSort(bson.D{{Key: "received_at", Value: -1}}).SetSkip(int64(s.size - 1))
+	if err := s.collection.FindOne(ctx, bson.M{}, opts).Decode(&oldest); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+	_, err := s.collection.DeleteMany(ctx, bson.M{"received_at": bson.M{"$lt": oldest.ReceivedAt}})
+	return err
+}
+
+// Get returns the delivery with the given ID, or nil if there isn't one
+func (s *MongoWebhookArchiveStore) Get(ctx context.Context, id string) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	if err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&delivery); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// Ping checks the MongoDB connection
+func (s *MongoWebhookArchiveStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx, nil)
+}
+
+// Close does nothing: the shared client is disconnected by its owner
+func (s *MongoWebhookArchiveStore) Close(ctx context.Context) error { return nil }
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryWebhookArchiveStore_KeepsMostRecent(t *testing.T) {
+	store := NewMemoryWebhookArchiveStore(2)
+	ctx := context.Background()
+	for _, id := range []string{"one", "two", "three"} {
+		require.NoError(t, store.Save(ctx, &WebhookDelivery{ID: id, Payload: []byte(`{}`)}))
+	}
+
+	delivery, err := store.Get(ctx, "one")
+	require.NoError(t, err)
+	assert.Nil(t, delivery)
+
+	delivery, err = store.Get(ctx, "three")
+	require.NoError(t, err)
+	require.NotNil(t, delivery)
+	assert.Equal(t, "three", delivery.ID)
+
+	// Redelivering replaces the stored delivery and keeps it from being dropped next
+	require.NoError(t, store.Save(ctx, &WebhookDelivery{ID: "two", EventType: "push"}))
+	require.NoError(t, store.Save(ctx, &WebhookDelivery{ID: "four"}))
+	delivery, err = store.Get(ctx, "two")
+	require.NoError(t, err)
+	require.NotNil(t, delivery)
+	assert.Equal(t, "push", delivery.EventType)
+}
+
+func TestWebhookArchive_RecordsUnderDeliveryID(t *testing.T) {
+	archive := NewWebhookArchive(NewMemoryWebhookArchiveStore(10))
+	ctx := WithCorrelationID(context.Background(), "delivery-1")
+	archive.Record(ctx, types.SourcePlatformGitLab, gitlabMergeRequestEventType, []byte(`{"object_kind":"merge_request"}`))
+
+	delivery, err := archive.Get(ctx, "delivery-1")
+	require.NoError(t, err)
+	require.NotNil(t, delivery)
+	assert.Equal(t, types.SourcePlatformGitLab, delivery.Platform)
+	assert.Equal(t, gitlabMergeRequestEventType, delivery.EventType)
+	assert.JSONEq(t, `{"object_kind":"merge_request"}`, string(delivery.Payload))
+
+	// A disabled archive records nothing
+	var disabled *WebhookArchive
+	disabled.Record(ctx, types.SourcePlatformGitHub, "push", []byte(`{}`))
+	delivery, err = disabled.Get(ctx, "delivery-1")
+	require.NoError(t, err)
+	assert.Nil(t, delivery)
+}
+
+func TestReplayHandler(t *testing.T) {
+	config, container := newMaintenanceTestContainer(t, true)
+	handler := ReplayHandler(config, container)
+
+	send := func(method string, path string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("POST", "/admin/replay/delivery-1", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, send("GET", "/admin/replay/delivery-1", "admin-token").Code)
+	w := send("POST", "/admin/replay/delivery-1", "admin-token")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "disabled")
+
+	config.WebhookArchiveSize = 10
+	container.WebhookArchive = NewWebhookArchive(NewMemoryWebhookArchiveStore(config.WebhookArchiveSize))
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/admin/replay/", "admin-token").Code)
+	assert.Equal(t, http.StatusNotFound, send("POST", "/admin/replay/delivery-1", "admin-token").Code)
+
+	// Deliver a push webhook, which is archived under its delivery ID
+	payload := `{"ref": "refs/heads/main", "before": "aaa", "after": "bbb", "repository": {"full_name": "org/src"}}`
+	req := httptest.NewRequest("POST", "/events", bytes.NewReader([]byte(payload)))
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-GitHub-Delivery", "delivery-1")
+	ctx, _ := WithRequestID(req)
+	w = httptest.NewRecorder()
+	HandleWebhookWithContainer(w, req.WithContext(ctx), config, container)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	w = send("POST", "/admin/replay/delivery-1", "admin-token")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	queued, err := container.Maintenance.queued(t.Context())
+	require.NoError(t, err)
+	require.Len(t, queued, 2)
+	assert.Equal(t, "delivery-1", queued[0].CorrelationID)
+	assert.Equal(t, "push", queued[1].Source)
+	assert.Equal(t, "bbb", queued[1].CommitSHA)
+	assert.NotEqual(t, "delivery-1", queued[1].CorrelationID)
+}
@@ -5,9 +5,12 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -76,17 +79,20 @@ func HandleWebhookWithContainer(w http.ResponseWriter, r *http.Request, config *
 	limited := io.LimitReader(r.Body, maxWebhookBodyBytes)
 	payload, err := io.ReadAll(limited)
 	if err != nil {
-		LogWebhookOperation(ctx, "read_body", "failed to read webhook body", err)
-		container.MetricsCollector.RecordWebhookFailed()
-		http.Error(w, "invalid body", http.StatusBadRequest)
+		rejectWebhook(ctx, w, r, container, http.StatusBadRequest, WebhookErrorResponse{
+			Error:   webhookErrInvalidBody,
+			Message: "invalid body",
+		}, err)
 		return
 	}
 
 	eventType := r.Header.Get("X-GitHub-Event")
 	if eventType == "" {
-		LogWebhookOperation(ctx, "missing_event", "missing X-GitHub-Event header", nil)
-		container.MetricsCollector.RecordWebhookFailed()
-		http.Error(w, "missing event type", http.StatusBadRequest)
+		rejectWebhook(ctx, w, r, container, http.StatusBadRequest, WebhookErrorResponse{
+			Error:         webhookErrMissingEventType,
+			Message:       "missing event type",
+			MissingFields: []string{"X-GitHub-Event"},
+		}, nil)
 		return
 	}
 
@@ -99,9 +105,10 @@ func HandleWebhookWithContainer(w http.ResponseWriter, r *http.Request, config *
 	if config.WebhookSecret != "" {
 		sigHeader := r.Header.Get("X-Hub-Signature-256")
 		if !simpleVerifySignature(sigHeader, payload, []byte(config.WebhookSecret)) {
-			LogWebhookOperation(ctx, "signature_verification", "webhook signature verification failed", nil)
-			container.MetricsCollector.RecordWebhookFailed()
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			rejectWebhook(ctx, w, r, container, http.StatusUnauthorized, WebhookErrorResponse{
+				Error:   webhookErrInvalidSignature,
+				Message: "webhook signature verification failed",
+			}, nil)
 			return
 		}
 		LogInfoCtx(ctx, "signature verified", map[string]interface{}{
@@ -109,24 +116,14 @@ func HandleWebhookWithContainer(w http.ResponseWriter, r *http.Request, config *
 		})
 	}
 
-	// Parse webhook event
-	evt, err := github.ParseWebHook(eventType, payload)
-	if err != nil {
-		LogWebhookOperation(ctx, "parse_payload", "failed to parse webhook payload", err,
-			map[string]interface{}{"event_type": eventType})
-		container.MetricsCollector.RecordWebhookFailed()
-		http.Error(w, "bad webhook", http.StatusBadRequest)
-		return
-	}
-
-	// Check if it's a pull_request event
-	prEvt, ok := evt.(*github.PullRequestEvent)
-	if !ok || prEvt.GetPullRequest() == nil {
+	// Convert the event with the source registered for its type
+	source := container.EventSources.Webhook(types.SourcePlatformGitHub, eventType)
+	if source == nil {
 		// Record ignored webhook with event type
 		container.MetricsCollector.RecordWebhookIgnored(eventType)
 
 		// Log with event type for better debugging
-		LogInfoCtx(ctx, "ignoring non-pull_request event", map[string]interface{}{
+		LogInfoCtx(ctx, "ignoring event the copier doesn't handle", map[string]interface{}{
 			"event_type": eventType,
 			"size_bytes": len(payload),
 		})
@@ -134,6 +131,39 @@ func HandleWebhookWithContainer(w http.ResponseWriter, r *http.Request, config *
 		return
 	}
 
+	// Keep the payload so the delivery can be replayed through /admin/replay
+	container.WebhookArchive.Record(ctx, types.SourcePlatformGitHub, eventType, payload)
+
+	event := convertEvent(ctx, w, r, source, payload, container)
+	if event == nil {
+		return
+	}
+	LogInfoCtx(ctx, "event converted", map[string]interface{}{
+		"event_type": eventType,
+		"elapsed_ms": time.Since(startTime).Milliseconds(),
+	})
+	acceptCopyEvent(ctx, w, r, *event, config, container)
+}
+
+// pullRequestEventSource converts merged GitHub pull requests for workflows with the pr_merged trigger.
+// PRs closed without merging and PRs the copier opened don't trigger a copy.
+type pullRequestEventSource struct {
+	copierLabel string // the label the copier adds to its PRs
+}
+
+func (pullRequestEventSource) Name() string { return "pull_request" }
+
+func (s pullRequestEventSource) Convert(ctx context.Context, payload []byte) (*CopyEvent, string, error) {
+	var prEvt github.PullRequestEvent
+	if err := json.Unmarshal(payload, &prEvt); err != nil {
+		return nil, "", invalidPayload(err)
+	}
+
+	// Reject pull_request payloads that are missing fields we depend on
+	if missing := validatePullRequestEvent(&prEvt); len(missing) > 0 {
+		return nil, "", missingFields("pull_request", missing)
+	}
+
 	action := prEvt.GetAction()
 	merged := prEvt.GetPullRequest().GetMerged()
 
@@ -143,41 +173,86 @@ func HandleWebhookWithContainer(w http.ResponseWriter, r *http.Request, config *
 	})
 
 	if !(action == "closed" && merged) {
-		LogInfoCtx(ctx, "skipping non-merged PR", map[string]interface{}{
-			"action": action,
-			"merged": merged,
-		})
-		w.WriteHeader(http.StatusNoContent)
-		return
+		return nil, "not a merged PR", nil
 	}
 
-	// Process the merged PR
-	prNumber := prEvt.GetPullRequest().GetNumber()
-	sourceCommitSHA := prEvt.GetPullRequest().GetMergeCommitSHA()
-
-	// Extract repository info from webhook payload
-	repo := prEvt.GetRepo()
-	if repo == nil {
-		LogWarningCtx(ctx, "webhook missing repository info", nil)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+	// Skip PRs the copier opened, so workflows copying in opposite directions don't trigger each other
+	if isCopierPullRequest(prEvt.GetPullRequest(), s.copierLabel) {
+		return nil, "PR opened by the copier", nil
 	}
 
+	// Extract repository info from webhook payload (presence checked by validatePullRequestEvent)
+	prNumber := prEvt.GetPullRequest().GetNumber()
+	repo := prEvt.GetRepo()
 	repoOwner := repo.GetOwner().GetLogin()
 	repoName := repo.GetName()
 
-	// Extract the base branch (the branch the PR was merged into)
-	baseBranch := prEvt.GetPullRequest().GetBase().GetRef()
+	change := &CopyEvent{
+		Platform:   types.SourcePlatformGitHub,
+		Repo:       fmt.Sprintf("%s/%s", repoOwner, repoName),
+		Number:     prNumber,
+		CommitSHA:  prEvt.GetPullRequest().GetMergeCommitSHA(),
+		BaseBranch: prEvt.GetPullRequest().GetBase().GetRef(), // the branch the PR was merged into
+		URL:        fmt.Sprintf("https://github.com/%s/%s/pull/%d", repoOwner, repoName, prNumber),
+		Title:      prEvt.GetPullRequest().GetTitle(),
+		Author:     prEvt.GetPullRequest().GetUser().GetLogin(),
+	}
 
 	LogInfoCtx(ctx, "processing merged PR", map[string]interface{}{
-		"pr_number":   prNumber,
-		"sha":         sourceCommitSHA,
-		"repo":        fmt.Sprintf("%s/%s", repoOwner, repoName),
-		"base_branch": baseBranch,
-		"elapsed_ms":  time.Since(startTime).Milliseconds(),
+		"pr_number":   change.Number,
+		"sha":         change.CommitSHA,
+		"repo":        change.Repo,
+		"base_branch": change.BaseBranch,
 	})
+	return change, "", nil
+}
+
+// acceptCopyEvent responds 202 Accepted and schedules the merged change for processing in the background
+func acceptCopyEvent(ctx context.Context, w http.ResponseWriter, r *http.Request, change CopyEvent, config *configs.Config, container *ServiceContainer) {
+	startTime := time.Now()
+	if change.CorrelationID == "" {
+		change.CorrelationID = CorrelationIDFromContext(ctx)
+	}
 
-	// Respond immediately to avoid GitHub webhook timeout
+	// During maintenance, persist the change so it's processed when maintenance ends
+	if deferred, err := container.Maintenance.DeferIfEnabled(ctx, change); deferred {
+		if err != nil {
+			rejectWebhook(ctx, w, r, container, http.StatusServiceUnavailable, WebhookErrorResponse{
+				Error:   webhookErrMaintenance,
+				Message: "in maintenance and failed to queue delivery; redeliver this webhook",
+			}, err)
+			return
+		}
+		container.MetricsCollector.RecordWebhookDeferred()
+		LogInfoCtx(ctx, "maintenance mode: deferred merged change", map[string]interface{}{
+			"platform":  change.Platform,
+			"repo":      change.Repo,
+			"pr_number": change.Number,
+			"sha":       change.CommitSHA,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status":"queued"}`))
+		return
+	}
+
+	// Register the job so shutdown waits for it. Once the server is draining, refuse new work so
+	// the delivery shows as failed and can be redelivered to another instance.
+	done, ok := container.InFlight.Start(InFlightJob{
+		PRNumber:        change.Number,
+		SourceRepo:      change.Repo,
+		SourceCommitSHA: change.CommitSHA,
+		BaseBranch:      change.BaseBranch,
+	})
+	if !ok {
+		rejectWebhook(ctx, w, r, container, http.StatusServiceUnavailable, WebhookErrorResponse{
+			Error:   webhookErrShuttingDown,
+			Message: "server is shutting down; redeliver this webhook",
+		}, nil)
+		return
+	}
+
+	// Respond immediately to avoid webhook timeouts
 	LogInfoCtx(ctx, "sending immediate response", map[string]interface{}{
 		"elapsed_ms": time.Since(startTime).Milliseconds(),
 	})
@@ -198,20 +273,26 @@ func HandleWebhookWithContainer(w http.ResponseWriter, r *http.Request, config *
 		})
 	}
 
-	// Process asynchronously in background with a new context
-	// Don't use the request context as it will be cancelled when the request completes
-	bgCtx := context.Background()
-	go handleMergedPRWithContainer(bgCtx, prNumber, sourceCommitSHA, repoOwner, repoName, baseBranch, config, container)
+	// Process in the background, once the scheduler has a worker free for the source repo
+	scheduleMergedChange(change, config, container, done)
 }
 
-// handleMergedPRWithContainer processes a merged PR using the new pattern matching system
-func handleMergedPRWithContainer(ctx context.Context, prNumber int, sourceCommitSHA string, repoOwner string, repoName string, baseBranch string, config *configs.Config, container *ServiceContainer) {
+// handleMergedPRWithContainer processes a merged PR or MR using the new pattern matching system
+func handleMergedPRWithContainer(ctx context.Context, change CopyEvent, config *configs.Config, container *ServiceContainer) {
+	ctx = withSourceChange(ctx, change)
 	startTime := time.Now()
+	prNumber := change.Number
 
-	// Configure GitHub permissions
-	if InstallationAccessToken == "" {
-		ConfigurePermissions()
-	}
+	// The run stages files of its own, so changes processed at once don't commit or drop each other's files
+	container, closeRunState := container.withRunFileState()
+	defer closeRunState()
+	sourceCommitSHA := change.CommitSHA
+	webhookRepo := change.Repo
+	baseBranch := change.BaseBranch
+
+	// Record the run for the dashboard
+	history := container.RunHistory.Start(ctx, change)
+	defer container.RunHistory.Finish(ctx, history)
 
 	// Load configuration using new loader
 	// Note: config.ConfigRepoOwner and config.ConfigRepoName are already set from env.yaml
@@ -220,29 +301,36 @@ func handleMergedPRWithContainer(ctx context.Context, prNumber int, sourceCommit
 	if err != nil {
 		LogAndReturnError(ctx, "config_load", "failed to load config", err)
 		container.MetricsCollector.RecordWebhookFailed()
+		history.fail(fmt.Errorf("failed to load config: %w", err))
 
 		// Send error notification to Slack
 		container.SlackNotifier.NotifyError(ctx, &ErrorEvent{
 			Operation:  "config_load",
 			Error:      err,
 			PRNumber:   prNumber,
-			SourceRepo: fmt.Sprintf("%s/%s", repoOwner, repoName),
+			SourceRepo: webhookRepo,
 		})
 		return
 	}
 
-	// Find workflows matching this source repo and branch
-	webhookRepo := fmt.Sprintf("%s/%s", repoOwner, repoName)
-	var matchingWorkflows []types.Workflow
-	for _, workflow := range yamlConfig.Workflows {
-		// Match both repository and branch
-		if workflow.Source.Repo == webhookRepo && workflow.Source.Branch == baseBranch {
-			matchingWorkflows = append(matchingWorkflows, workflow)
-		}
+	// Find workflows matching this source platform, repo, branch, and trigger
+	matchingWorkflows := matchWorkflows(yamlConfig.Workflows, change)
+	if change.trigger() == types.WorkflowTriggerPush {
+		matchingWorkflows = skipPushedMerges(ctx, change, matchingWorkflows)
 	}
 
 	if len(matchingWorkflows) == 0 {
+		history.Status = RunStatusNoMatch
+		// Most pushes and workflow runs aren't meant to trigger a copy, so they aren't treated as failures
+		if change.trigger() != types.WorkflowTriggerPRMerged {
+			LogInfoCtx(ctx, fmt.Sprintf("no %s-triggered workflows configured for source repository and branch", change.trigger()), map[string]interface{}{
+				"webhook_repo": webhookRepo,
+				"base_branch":  baseBranch,
+			})
+			return
+		}
 		LogWarningCtx(ctx, "no workflows configured for source repository and branch", map[string]interface{}{
+			"platform":       change.Platform,
 			"webhook_repo":   webhookRepo,
 			"base_branch":    baseBranch,
 			"workflow_count": len(yamlConfig.Workflows),
@@ -256,15 +344,45 @@ func handleMergedPRWithContainer(ctx context.Context, prNumber int, sourceCommit
 		"base_branch":    baseBranch,
 		"matching_count": len(matchingWorkflows),
 	})
+	for _, workflow := range matchingWorkflows {
+		container.MetricsCollector.RecordWorkflowMatched(workflow.Name)
+	}
 
-	// Store matching workflows for processing
-	yamlConfig.Workflows = matchingWorkflows
+	// Store matching workflows for processing, with a copy for each branch of destinations listing several,
+	// keeping every workflow to find chained workflows afterwards
+	allWorkflows := yamlConfig.Workflows
+	yamlConfig.Workflows = expandDestinationBranches(ctx, matchingWorkflows)
 
-	// Get changed files from PR (from the source repository that triggered the webhook)
-	changedFiles, err := GetFilesChangedInPr(repoOwner, repoName, prNumber)
+	// Releases are copied from the commit their tag points to
+	if change.trigger() == types.WorkflowTriggerRelease {
+		if err := resolveReleaseCommit(ctx, &change); err != nil {
+			LogAndReturnError(ctx, "resolve_release", "failed to resolve release tag", err)
+			container.MetricsCollector.RecordWebhookFailed()
+			history.fail(fmt.Errorf("failed to resolve release tag: %w", err))
+			container.SlackNotifier.NotifyError(ctx, &ErrorEvent{
+				Operation:  "resolve_release",
+				Error:      err,
+				PRNumber:   prNumber,
+				SourceRepo: webhookRepo,
+			})
+			return
+		}
+		ctx = withSourceChange(ctx, change)
+		sourceCommitSHA = change.CommitSHA
+	}
+
+	// Get changed files from the PR or MR (from the source repository that triggered the webhook), or the
+	// files in a workflow run's artifacts
+	var changedFiles []types.ChangedFile
+	if change.trigger() == types.WorkflowTriggerWorkflowRun {
+		ctx, changedFiles, err = withRunArtifacts(ctx, change, matchingWorkflows)
+	} else {
+		changedFiles, err = getMergedChangeFiles(ctx, change, matchingWorkflows)
+	}
 	if err != nil {
 		LogAndReturnError(ctx, "get_files", "failed to get changed files", err)
 		container.MetricsCollector.RecordWebhookFailed()
+		history.fail(fmt.Errorf("failed to get changed files: %w", err))
 
 		// Send error notification to Slack
 		container.SlackNotifier.NotifyError(ctx, &ErrorEvent{
@@ -280,35 +398,54 @@ func handleMergedPRWithContainer(ctx context.Context, prNumber int, sourceCommit
 		"count": len(changedFiles),
 	})
 
-	// Track metrics before processing
-	filesMatchedBefore := container.MetricsCollector.GetFilesMatched()
-	filesUploadedBefore := container.MetricsCollector.GetFilesUploaded()
-	filesFailedBefore := container.MetricsCollector.GetFilesUploadFailed()
-
 	// Process files with workflow processor
-	processFilesWithWorkflows(ctx, prNumber, sourceCommitSHA, changedFiles, yamlConfig, container)
-
-	// Upload queued files
-	FilesToUpload = container.FileStateService.GetFilesToUpload()
-	AddFilesToTargetRepoBranchWithFetcher(container.PRTemplateFetcher, container.MetricsCollector)
-	container.FileStateService.ClearFilesToUpload()
-
-	// Update deprecation file - copy from FileStateService to global map for legacy function
-	deprecationMap := container.FileStateService.GetFilesToDeprecate()
-	FilesToDeprecate = make(map[string]types.Configs)
-	for _, entry := range deprecationMap {
-		FilesToDeprecate[entry.FileName] = types.Configs{
+	runs := processFilesWithWorkflows(ctx, prNumber, sourceCommitSHA, changedFiles, yamlConfig, container)
+	dryRunFiles := 0
+	for _, run := range runs {
+		if run.DryRun != nil {
+			dryRunFiles += run.DryRun.FileCount()
+		}
+	}
+
+	// Upload queued files, holding PRs past their destination repo's PR limits to open as one batched PR later
+	queued, held := container.PRThrottle.Admit(ctx, change, runs, container.FileStateService.GetFilesToUpload())
+	uploads := uploadFiles(ctx, queued, container.PRTemplateFetcher, container.MetricsCollector)
+	for key := range held {
+		uploads[key] = heldUploadResult()
+	}
+
+	// Queue uploads that failed with transient GitHub errors for retry
+	for key := range container.RetryQueue.EnqueueFailed(ctx, change, queued, uploads) {
+		result := uploads[key]
+		result.Err = fmt.Errorf("%w (queued for retry)", result.Err)
+		uploads[key] = result
+	}
+
+	// Record the commits and PRs the uploads made in the write log
+	container.WriteLog.RecordUploads(ctx, change, runs, queued, uploads)
+
+	// Post per-workflow summaries for workflows with notifications configured
+	notifyWorkflowOutcomes(ctx, change, runs, uploads, container.Config)
+	history.addWorkflows(runs, uploads)
+
+	// Check the destination builds of workflows with verify_build once the commits land
+	watchDestinationBuilds(ctx, container.BuildVerifier, change, runs, uploads)
+
+	// Hold PRs from workflows with an approval gate until they're approved
+	holdForApproval(ctx, container.ApprovalGate, change, runs, uploads)
+
+	// Update deprecation file with the files the run deprecated
+	deprecated := make(map[string]types.Configs)
+	for _, entry := range container.FileStateService.GetFilesToDeprecate() {
+		deprecated[entry.FileName] = types.Configs{
 			TargetRepo:   entry.Repo,
 			TargetBranch: entry.Branch,
 		}
 	}
-	UpdateDeprecationFile()
-	container.FileStateService.ClearFilesToDeprecate()
+	updateDeprecationFile(ctx, deprecated)
 
-	// Calculate metrics after processing
-	filesMatched := container.MetricsCollector.GetFilesMatched() - filesMatchedBefore
-	filesUploaded := container.MetricsCollector.GetFilesUploaded() - filesUploadedBefore
-	filesFailed := container.MetricsCollector.GetFilesUploadFailed() - filesFailedBefore
+	// Count the run's own files, since the shared metrics include changes processed at the same time
+	filesMatched, filesUploaded, filesFailed := runFileCounts(runs, queued, uploads)
 	processingTime := time.Since(startTime)
 
 	LogInfoCtx(ctx, "--Done--", map[string]interface{}{
@@ -316,28 +453,114 @@ func handleMergedPRWithContainer(ctx context.Context, prNumber int, sourceCommit
 		"sha":       sourceCommitSHA,
 	})
 
-	// Send success notification to Slack
+	// Send success notification to Slack, titled by the PR or MR, or by the change when it has no title
+	prTitle := change.Title
+	if prTitle == "" {
+		prTitle = change.describe()
+	}
 	container.SlackNotifier.NotifyPRProcessed(ctx, &PRProcessedEvent{
 		PRNumber:       prNumber,
-		PRTitle:        fmt.Sprintf("PR #%d", prNumber), // TODO: Get actual PR title from GitHub
-		PRURL:          fmt.Sprintf("https://github.com/%s/pull/%d", webhookRepo, prNumber),
+		PRTitle:        prTitle,
+		PRURL:          change.URL,
 		SourceRepo:     webhookRepo,
 		FilesMatched:   filesMatched,
 		FilesCopied:    filesUploaded,
 		FilesFailed:    filesFailed,
+		DryRunFiles:    dryRunFiles,
 		ProcessingTime: processingTime,
 	})
+
+	// Run the workflows that copy on from the commits the uploads landed
+	runChainedWorkflows(ctx, change, allWorkflows, queued, uploads, config, container)
+}
+
+// getMergedChangeFiles lists the files changed in a merged PR or MR, a push, or a release, from the platform that sent it,
+// or the files an upstream workflow copied for a chained change.
+// For a GitHub PR, files past the source paths of every workflow may be left out.
+func getMergedChangeFiles(ctx context.Context, change CopyEvent, workflows []types.Workflow) ([]types.ChangedFile, error) {
+	if change.trigger() == types.WorkflowTriggerChained {
+		return change.ChainedFiles, nil
+	}
+	switch change.Platform {
+	case types.SourcePlatformGitLab:
+		return GetGitLabClient().GetMergeRequestChanges(ctx, change.Repo, change.Number)
+	case types.SourcePlatformBitbucket:
+		return GetBitbucketClient().GetPullRequestChanges(ctx, change.Repo, change.Number)
+	}
+	owner, name, _ := strings.Cut(change.Repo, "/")
+	switch change.trigger() {
+	case types.WorkflowTriggerPush:
+		return GetFilesChangedInPush(ctx, owner, name, change.BeforeSHA, change.CommitSHA)
+	case types.WorkflowTriggerRelease:
+		return getFilesChangedInRelease(ctx, owner, name, change)
+	}
+	return GetFilesChangedInPrUnderPrefixes(ctx, owner, name, change.Number, workflowSourcePrefixes(workflows))
+}
+
+// workflowSourcePrefixes returns the prefixes of the source paths the workflows' transformations match, or
+// nil if any transformation can match any path
+func workflowSourcePrefixes(workflows []types.Workflow) []string {
+	var prefixes []string
+	for _, workflow := range workflows {
+		for _, transformation := range workflow.Transformations {
+			prefix := transformation.SourcePathPrefix()
+			if prefix == "" {
+				return nil
+			}
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// matchWorkflows returns the workflows for the change's source platform, repo, and branch that run on its
+// trigger. A workflow whose source branch is a pattern matches changes on any branch the pattern matches.
+// Workflow runs only match workflows whose artifacts come from the run's Actions workflow, and releases only
+// match workflows whose release settings accept the release's tag. Workflows are returned highest priority
+// first, in config order for the same priority.
+func matchWorkflows(workflows []types.Workflow, change CopyEvent) []types.Workflow {
+	var matching []types.Workflow
+	for _, workflow := range workflows {
+		if workflow.Source.GetPlatform() == change.Platform && workflow.Source.Repo == change.Repo &&
+			workflow.Source.MatchesBranch(change.BaseBranch) && workflow.Trigger.Has(change.trigger()) &&
+			(change.trigger() != types.WorkflowTriggerWorkflowRun || workflow.Artifacts.MatchesWorkflow(change.Title)) &&
+			(change.trigger() != types.WorkflowTriggerRelease || workflow.Release.MatchesRelease(change.ReleaseTag, change.Prerelease)) {
+			matching = append(matching, workflow)
+		}
+	}
+	types.SortWorkflowsByPriority(matching)
+	return matching
 }
 
-// processFilesWithWorkflows processes changed files using the workflow system
+// processFilesWithWorkflows processes changed files using the workflow system and returns what each
+// workflow queued. Workflows run in priority order, and the config's conflict policy decides which of them
+// copy a file more than one matches. Workflows in dry-run mode aren't queued for upload; their runs hold
+// reports of what would have changed instead.
 func processFilesWithWorkflows(ctx context.Context, prNumber int, sourceCommitSHA string,
-	changedFiles []types.ChangedFile, yamlConfig *types.YAMLConfig, container *ServiceContainer) {
+	changedFiles []types.ChangedFile, yamlConfig *types.YAMLConfig, container *ServiceContainer) []*workflowRun {
 
 	LogInfoCtx(ctx, "processing files with workflows", map[string]interface{}{
 		"file_count":     len(changedFiles),
 		"workflow_count": len(yamlConfig.Workflows),
 	})
 
+	// Workflows run highest priority first
+	workflows := yamlConfig.WorkflowsByPriority()
+
+	// Workflows copying a workflow run's artifacts only see the artifacts they list
+	filesByWorkflow := make([][]types.ChangedFile, len(workflows))
+	for i, workflow := range workflows {
+		filesByWorkflow[i] = changedFiles
+		if change, ok := sourceChangeFromContext(ctx); ok && change.trigger() == types.WorkflowTriggerWorkflowRun {
+			filesByWorkflow[i] = workflowArtifactFiles(workflow, changedFiles)
+		}
+	}
+
+	// Decide which workflows copy the files more than one of them matches
+	pathMapper := &workflowProcessor{patternMatcher: container.PatternMatcher, pathTransformer: container.PathTransformer}
+	conflictErrs := resolveWorkflowConflicts(ctx, pathMapper, yamlConfig.GetConflictPolicy(), workflows, filesByWorkflow)
+
 	// Create workflow processor
 	workflowProcessor := NewWorkflowProcessor(
 		container.PatternMatcher,
@@ -345,26 +568,63 @@ func processFilesWithWorkflows(ctx context.Context, prNumber int, sourceCommitSH
 		container.FileStateService,
 		container.MetricsCollector,
 		container.MessageTemplater,
+		container.SlackNotifier,
+		container.AuditLogger,
 	)
 
 	// Process each workflow
-	for _, workflow := range yamlConfig.Workflows {
+	var runs []*workflowRun
+	dryRunCount := 0
+	for i, workflow := range workflows {
 		if err := ctx.Err(); err != nil {
 			LogWebhookOperation(ctx, "workflow_processing", "workflow processing cancelled", err)
-			return
+			return runs
+		}
+		run := &workflowRun{Workflow: workflow}
+		runs = append(runs, run)
+		workflowFiles := filesByWorkflow[i]
+
+		// Dry-run workflows only report what they would change; shadow workflows also post the diff
+		shadow := workflow.Shadow.IsEnabled()
+		if shadow || workflow.IsDryRun(container.Config != nil && container.Config.DryRun) {
+			dryRunCount++
+			if shadow {
+				run.DryRun, run.Err = runShadowWorkflow(ctx, workflow, workflowFiles, prNumber, sourceCommitSHA, container)
+			} else {
+				run.DryRun, run.Err = runDryRunWorkflow(ctx, workflow, workflowFiles, prNumber, sourceCommitSHA, container)
+			}
+			if conflictErrs[i] != nil {
+				run.Err = errors.Join(run.Err, conflictErrs[i])
+			}
+			if run.Err != nil {
+				LogErrorCtx(ctx, "failed to process workflow", run.Err, map[string]interface{}{
+					"workflow_name": workflow.Name,
+					"dry_run":       true,
+					"shadow":        shadow,
+				})
+			}
+			continue
 		}
 
-		err := workflowProcessor.ProcessWorkflow(ctx, workflow, changedFiles, prNumber, sourceCommitSHA)
-		if err != nil {
-			LogErrorCtx(ctx, "failed to process workflow", err, map[string]interface{}{
+		filesBefore, deletionsBefore := queuedPaths(container.FileStateService, run.uploadKey())
+		run.Err = workflowProcessor.ProcessWorkflow(ctx, workflow, workflowFiles, prNumber, sourceCommitSHA)
+		filesAfter, deletionsAfter := queuedPaths(container.FileStateService, run.uploadKey())
+		run.Files = newPaths(filesBefore, filesAfter)
+		run.Deletions = newPaths(deletionsBefore, deletionsAfter)
+		queueMirrorFiles(ctx, container.FileStateService, container.Config, container.PatternMatcher, container.PathTransformer, run, workflowFiles)
+		queueChangelogEntry(ctx, container.FileStateService, run, sourceCommitSHA)
+		run.Err = errors.Join(run.Err, conflictErrs[i])
+		if run.Err != nil {
+			LogErrorCtx(ctx, "failed to process workflow", run.Err, map[string]interface{}{
 				"workflow_name": workflow.Name,
 			})
 			// Continue processing other workflows
-			continue
 		}
 	}
 
 	LogInfoCtx(ctx, "workflow processing complete", map[string]interface{}{
 		"workflow_count": len(yamlConfig.Workflows),
+		"dry_run_count":  dryRunCount,
 	})
+	return runs
 }
@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/rsa"
@@ -11,13 +12,17 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/google/go-github/v48/github"
 	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/shurcooL/githubv4"
 )
 
 func TestSimpleVerifySignature(t *testing.T) {
@@ -199,20 +204,20 @@ func TestHandleWebhookWithContainer_NonPREvent(t *testing.T) {
 		t.Fatalf("NewServiceContainer() error = %v", err)
 	}
 
-	// Create a push event (not a PR event)
-	pushEvent := map[string]interface{}{
-		"ref": "refs/heads/main",
+	// Create an issues event (not a PR or push event)
+	issuesEvent := map[string]interface{}{
+		"action": "opened",
 	}
-	payload, _ := json.Marshal(pushEvent)
+	payload, _ := json.Marshal(issuesEvent)
 
 	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
-	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-GitHub-Event", "issues")
 
 	w := httptest.NewRecorder()
 
 	HandleWebhookWithContainer(w, req, config, container)
 
-	// Should return 204 No Content for non-PR events
+	// Should return 204 No Content for events other than pull_request and push
 	if w.Code != http.StatusNoContent {
 		t.Errorf("Status code = %d, want %d", w.Code, http.StatusNoContent)
 	}
@@ -254,6 +259,52 @@ func TestHandleWebhookWithContainer_NonMergedPR(t *testing.T) {
 	}
 }
 
+func TestHandleWebhookWithContainer_CopierPR(t *testing.T) {
+	config := &configs.Config{
+		ConfigRepoOwner: "test-owner",
+		ConfigRepoName:  "test-repo",
+		CopierPRLabel:   "examples-copier",
+	}
+
+	container, err := NewServiceContainer(config)
+	if err != nil {
+		t.Fatalf("NewServiceContainer() error = %v", err)
+	}
+
+	// A merged PR with the copier's label
+	prEvent := &github.PullRequestEvent{
+		Action: github.String("closed"),
+		PullRequest: &github.PullRequest{
+			Number:         github.Int(7),
+			Merged:         github.Bool(true),
+			MergeCommitSHA: github.String("abc123"),
+			Base:           &github.PullRequestBranch{Ref: github.String("main")},
+			Head:           &github.PullRequestBranch{Ref: github.String("update-examples")},
+			Labels:         []*github.Label{{Name: github.String("examples-copier")}},
+		},
+		Repo: &github.Repository{
+			Name:  github.String("test-repo"),
+			Owner: &github.User{Login: github.String("test-owner")},
+		},
+	}
+	payload, _ := json.Marshal(prEvent)
+
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
+	req.Header.Set("X-GitHub-Event", "pull_request")
+
+	w := httptest.NewRecorder()
+
+	HandleWebhookWithContainer(w, req, config, container)
+
+	// Should return 204 No Content so the copy doesn't loop back
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Status code = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if got := container.MetricsCollector.webhookIgnored; got != 1 {
+		t.Errorf("webhookIgnored = %d, want 1", got)
+	}
+}
+
 func TestHandleWebhookWithContainer_MergedPR(t *testing.T) {
 	// Note: This test triggers a background goroutine that processes the merged PR.
 	// The goroutine will fail when trying to load config/fetch files from GitHub,
@@ -278,9 +329,9 @@ func TestHandleWebhookWithContainer_MergedPR(t *testing.T) {
 	os.Setenv("GITHUB_APP_PRIVATE_KEY", string(pemBytes))
 	os.Setenv("GITHUB_APP_PRIVATE_KEY_B64", base64.StdEncoding.EncodeToString(pemBytes))
 
-	// Set InstallationAccessToken to prevent ConfigurePermissions from being called
+	// Set the default installation's token so it isn't requested from GitHub
 	// We don't reset this because the background goroutine may still need it after the test completes
-	InstallationAccessToken = "test-token"
+	SetInstallationTokenForOrg("", "test-token")
 
 	config := &configs.Config{
 		ConfigRepoOwner: "test-owner",
@@ -356,7 +407,7 @@ func TestHandleWebhookWithContainer_MergedPRToDevelopmentBranch(t *testing.T) {
 	os.Setenv("GITHUB_APP_PRIVATE_KEY", string(pemBytes))
 	os.Setenv("GITHUB_APP_PRIVATE_KEY_B64", base64.StdEncoding.EncodeToString(pemBytes))
 
-	InstallationAccessToken = "test-token"
+	SetInstallationTokenForOrg("", "test-token")
 
 	config := &configs.Config{
 		ConfigRepoOwner: "test-owner",
@@ -459,7 +510,7 @@ func TestHandleWebhookWithContainer_MergedPRWithDifferentBranches(t *testing.T)
 	os.Setenv("GITHUB_APP_PRIVATE_KEY", string(pemBytes))
 	os.Setenv("GITHUB_APP_PRIVATE_KEY_B64", base64.StdEncoding.EncodeToString(pemBytes))
 
-	InstallationAccessToken = "test-token"
+	SetInstallationTokenForOrg("", "test-token")
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -544,3 +595,244 @@ func TestStatusDeleted(t *testing.T) {
 	}
 }
 
+
+func TestRenderUploadMessages_SourceChange(t *testing.T) {
+	wp := &workflowProcessor{messageTemplater: NewMessageTemplater()}
+	workflow := types.Workflow{
+		Name:        "java-examples",
+		Source:      types.Source{Repo: "org/source", Branch: "main"},
+		Destination: types.Destination{Repo: "org/docs", Branch: "main"},
+		Variables:   map[string]string{"team": "drivers"},
+		CommitStrategy: &types.CommitStrategyConfig{
+			CommitMessage: "Copy {{ .FileCount }} files for ${team}",
+			PRTitle:       "{{ .SourcePRTitle }} ({{ .RuleName }})",
+			PRBody:        "From {{ .SourcePRURL }} by @{{ .Author }}{{ range .Files }}\n- {{ . }}{{ end }}{{ range .DeletedFiles }}\n- {{ . }} (deleted){{ end }}",
+		},
+	}
+	content := types.UploadFileContent{
+		Content:     []github.RepositoryContent{{Name: github.String("java/Agg.java")}},
+		DeletePaths: []string{"java/Old.java"},
+	}
+	ctx := withSourceChange(context.Background(), CopyEvent{
+		URL:    "https://github.com/org/source/pull/42",
+		Title:  "Add aggregation examples",
+		Author: "octocat",
+	})
+
+	wp.renderUploadMessages(ctx, workflow, &content, 42, "abc123")
+
+	if content.CommitMessage != "Copy 2 files for drivers" {
+		t.Errorf("CommitMessage = %q", content.CommitMessage)
+	}
+	if content.PRTitle != "Add aggregation examples (java-examples)" {
+		t.Errorf("PRTitle = %q", content.PRTitle)
+	}
+	wantBody := "From https://github.com/org/source/pull/42 by @octocat\n- java/Agg.java\n- java/Old.java (deleted)"
+	if content.PRBody != wantBody {
+		t.Errorf("PRBody = %q, want %q", content.PRBody, wantBody)
+	}
+
+	// Runs without a source change, such as backfills, leave the PR fields empty
+	wp.renderUploadMessages(context.Background(), workflow, &content, 0, "abc123")
+	if !strings.HasPrefix(content.PRBody, "From  by @\n") {
+		t.Errorf("PRBody without a source change = %q", content.PRBody)
+	}
+}
+
+func TestApplyTransformation_BranchVariables(t *testing.T) {
+	wp := &workflowProcessor{patternMatcher: NewPatternMatcher(), pathTransformer: NewPathTransformer()}
+	workflow := types.Workflow{
+		Name:   "versioned-examples",
+		Source: types.Source{Repo: "org/source", Branch: "release/*"},
+	}
+	ctx := withSourceChange(context.Background(), CopyEvent{Repo: "org/source", BaseBranch: "release/8.0"})
+
+	tests := []struct {
+		name           string
+		transformation types.Transformation
+		want           string
+	}{
+		{
+			name:           "move",
+			transformation: types.Transformation{Move: &types.MoveTransform{From: "examples", To: "docs/v${source_branch_suffix}/examples"}},
+			want:           "docs/v8.0/examples/go/main.go",
+		},
+		{
+			name:           "copy",
+			transformation: types.Transformation{Copy: &types.CopyTransform{From: "examples/go/main.go", To: "${source_branch}/main.go"}},
+			want:           "release/8.0/main.go",
+		},
+		{
+			name:           "glob",
+			transformation: types.Transformation{Glob: &types.GlobTransform{Pattern: "examples/**", Transform: "v${source_branch_suffix}/${relative_path}"}},
+			want:           "v8.0/go/main.go",
+		},
+		{
+			name:           "regex",
+			transformation: types.Transformation{Regex: &types.RegexTransform{Pattern: `^examples/(?P<lang>[^/]+)/(?P<file>.+)$`, Transform: "${source_branch_suffix}/${lang}/${file}"}},
+			want:           "8.0/go/main.go",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, targetPath, err := wp.applyTransformation(ctx, workflow, tt.transformation, "examples/go/main.go")
+			if err != nil {
+				t.Fatalf("applyTransformation failed: %v", err)
+			}
+			if !matched || targetPath != tt.want {
+				t.Errorf("applyTransformation = %v, %q, want true, %q", matched, targetPath, tt.want)
+			}
+		})
+	}
+
+	if _, _, err := wp.applyTransformation(ctx, workflow, types.Transformation{Move: &types.MoveTransform{From: "examples", To: "${version}"}}, "examples/go/main.go"); err == nil {
+		t.Error("expected an error for an unknown variable in a move destination")
+	}
+}
+
+func TestApplyTransformation_GlobVariables(t *testing.T) {
+	wp := &workflowProcessor{patternMatcher: NewPatternMatcher(), pathTransformer: NewPathTransformer()}
+	workflow := types.Workflow{Name: "examples", Source: types.Source{Repo: "org/source", Branch: "main"}}
+
+	tests := []struct {
+		name       string
+		glob       types.GlobTransform
+		sourcePath string
+		want       string
+	}{
+		{
+			name:       "language directory by position",
+			glob:       types.GlobTransform{Pattern: "examples/*/src/**", Transform: "code/${1}/${2}"},
+			sourcePath: "examples/python/src/crud/insert.py",
+			want:       "code/python/crud/insert.py",
+		},
+		{
+			name:       "star captures",
+			glob:       types.GlobTransform{Pattern: "mflix/*/**/*.js", Transform: "${glob_star_1}/${glob_star_3}.mjs"},
+			sourcePath: "mflix/server/routes/movies.js",
+			want:       "server/movies.mjs",
+		},
+		{
+			name:       "relative path without a double star",
+			glob:       types.GlobTransform{Pattern: "snippets/*/*.go", Transform: "go/${relative_path}"},
+			sourcePath: "snippets/atlas/connect.go",
+			want:       "go/atlas/connect.go",
+		},
+		{
+			name:       "alternatives and single characters",
+			glob:       types.GlobTransform{Pattern: "v{7,8}.?/*.py", Transform: "${1}x/${3}.py"},
+			sourcePath: "v8.0/aggregate.py",
+			want:       "8x/aggregate.py",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, targetPath, err := wp.applyTransformation(context.Background(), workflow, types.Transformation{Glob: &tt.glob}, tt.sourcePath)
+			if err != nil {
+				t.Fatalf("applyTransformation failed: %v", err)
+			}
+			if !matched || targetPath != tt.want {
+				t.Errorf("applyTransformation = %v, %q, want true, %q", matched, targetPath, tt.want)
+			}
+		})
+	}
+
+	glob := types.GlobTransform{Pattern: "examples/*.go", Transform: "${2}"}
+	if _, _, err := wp.applyTransformation(context.Background(), workflow, types.Transformation{Glob: &glob}, "examples/main.go"); err == nil {
+		t.Error("expected an error for a wildcard the pattern doesn't have")
+	}
+}
+
+func TestWorkflowSourcePrefixes(t *testing.T) {
+	workflows := []types.Workflow{
+		{Name: "server", Transformations: []types.Transformation{
+			{Move: &types.MoveTransform{From: "mflix/server", To: "server"}},
+			{Glob: &types.GlobTransform{Pattern: "mflix/shared/*.js", Transform: "shared/${filename}"}},
+		}},
+		{Name: "readme", Transformations: []types.Transformation{
+			{Copy: &types.CopyTransform{From: "README.md", To: "README.md"}},
+		}},
+	}
+	got := workflowSourcePrefixes(workflows)
+	want := []string{"README.md", "mflix/server", "mflix/shared/"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("workflowSourcePrefixes = %v, want %v", got, want)
+	}
+
+	workflows = append(workflows, types.Workflow{Name: "all-go", Transformations: []types.Transformation{
+		{Glob: &types.GlobTransform{Pattern: "**/*.go", Transform: "${filename}"}},
+	}})
+	if got := workflowSourcePrefixes(workflows); got != nil {
+		t.Errorf("workflowSourcePrefixes = %v, want nil for a workflow that can match any path", got)
+	}
+}
+
+// changedFilePages returns a fetchPage function serving the pages in order, and a pointer to the number of
+// pages fetched
+func changedFilePages(pages ...[]string) (func(cursor *githubv4.String) ([]types.ChangedFile, *githubv4.String, error), *int) {
+	fetched := 0
+	return func(cursor *githubv4.String) ([]types.ChangedFile, *githubv4.String, error) {
+		var files []types.ChangedFile
+		for _, path := range pages[fetched] {
+			files = append(files, types.ChangedFile{Path: path, Status: "MODIFIED"})
+		}
+		fetched++
+		if fetched == len(pages) {
+			return files, nil, nil
+		}
+		next := githubv4.String(fmt.Sprintf("page-%d", fetched))
+		return files, &next, nil
+	}, &fetched
+}
+
+func TestListChangedFilePages(t *testing.T) {
+	pages := [][]string{
+		{"docs/a.md", "mflix/client/app.js"},
+		{"mflix/server/app.js", "mflix/server/db.js"},
+		{"mflix/tests/app.test.js", "web/index.html"},
+		{"zzz/last.txt"},
+	}
+
+	tests := []struct {
+		name        string
+		prefixes    []string
+		wantPages   int
+		wantFiles   int
+		wantStopped bool
+	}{
+		{name: "no prefixes lists every page", prefixes: nil, wantPages: 4, wantFiles: 7},
+		{name: "stops after the page that passes every prefix", prefixes: []string{"mflix/server"}, wantPages: 3, wantFiles: 6, wantStopped: true},
+		{name: "keeps going while a later path could match", prefixes: []string{"mflix/server", "web/"}, wantPages: 4, wantFiles: 7},
+		{name: "stops after the first page when it's already past", prefixes: []string{"app/"}, wantPages: 1, wantFiles: 2, wantStopped: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetchPage, fetched := changedFilePages(pages...)
+			files, n, stopped, err := listChangedFilePages(fetchPage, tt.prefixes)
+			if err != nil {
+				t.Fatalf("listChangedFilePages failed: %v", err)
+			}
+			if n != tt.wantPages || *fetched != tt.wantPages || stopped != tt.wantStopped {
+				t.Errorf("fetched %d pages (reported %d), stopped early %v; want %d, %v", *fetched, n, stopped, tt.wantPages, tt.wantStopped)
+			}
+			if len(files) != tt.wantFiles {
+				t.Errorf("got %d files, want %d", len(files), tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestListChangedFilePages_Unsorted(t *testing.T) {
+	fetchPage, fetched := changedFilePages(
+		[]string{"mflix/server/db.js", "docs/a.md"},
+		[]string{"web/index.html"},
+		[]string{"mflix/server/app.js"},
+	)
+	files, _, stopped, err := listChangedFilePages(fetchPage, []string{"mflix/server"})
+	if err != nil {
+		t.Fatalf("listChangedFilePages failed: %v", err)
+	}
+	if stopped || *fetched != 3 || len(files) != 4 {
+		t.Errorf("expected every page of an unsorted listing, got %d pages and %d files (stopped early %v)", *fetched, len(files), stopped)
+	}
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/go-github/v48/github"
+)
+
+// WebhookErrorResponse is the JSON body returned for rejected webhook deliveries.
+// It tells the sender what was wrong with the request so it can be fixed and redelivered.
+type WebhookErrorResponse struct {
+	Error         string   `json:"error"`
+	Message       string   `json:"message,omitempty"`
+	MissingFields []string `json:"missing_fields,omitempty"`
+	DeliveryID    string   `json:"delivery_id,omitempty"`
+	EventType     string   `json:"event_type,omitempty"`
+}
+
+// Error codes returned in WebhookErrorResponse.Error
+const (
+	webhookErrInvalidBody      = "invalid_body"
+	webhookErrMissingEventType = "missing_event_type"
+	webhookErrInvalidSignature = "invalid_signature"
+	webhookErrInvalidPayload   = "invalid_payload"
+	webhookErrMissingFields    = "missing_fields"
+	webhookErrShuttingDown     = "shutting_down"
+	webhookErrMaintenance      = "maintenance"
+)
+
+// validatePullRequestEvent checks that a pull_request event carries the fields
+// the copier relies on and returns the JSON paths of any that are missing.
+// Only merged PRs are processed, so the stricter checks only apply when the
+// event describes a closed and merged PR.
+func validatePullRequestEvent(evt *github.PullRequestEvent) []string {
+	var missing []string
+
+	if evt.GetAction() == "" {
+		missing = append(missing, "action")
+	}
+
+	pr := evt.GetPullRequest()
+	if pr == nil {
+		return append(missing, "pull_request")
+	}
+
+	if !(evt.GetAction() == "closed" && pr.GetMerged()) {
+		return missing
+	}
+
+	if pr.GetNumber() == 0 {
+		missing = append(missing, "pull_request.number")
+	}
+	if pr.GetMergeCommitSHA() == "" {
+		missing = append(missing, "pull_request.merge_commit_sha")
+	}
+	if pr.GetBase().GetRef() == "" {
+		missing = append(missing, "pull_request.base.ref")
+	}
+
+	repo := evt.GetRepo()
+	if repo == nil {
+		return append(missing, "repository")
+	}
+	if repo.GetName() == "" {
+		missing = append(missing, "repository.name")
+	}
+	if repo.GetOwner().GetLogin() == "" {
+		missing = append(missing, "repository.owner.login")
+	}
+
+	return missing
+}
+
+// validatePushEvent checks that a push event to a branch carries the fields the copier relies on
+// and returns the JSON paths of any that are missing. Pushes that delete a branch aren't processed,
+// so only the ref is required for them.
+func validatePushEvent(evt *github.PushEvent) []string {
+	var missing []string
+
+	if evt.GetRef() == "" {
+		missing = append(missing, "ref")
+	}
+	if evt.GetDeleted() {
+		return missing
+	}
+	if evt.GetAfter() == "" {
+		missing = append(missing, "after")
+	}
+	if evt.GetRepo().GetFullName() == "" {
+		missing = append(missing, "repository.full_name")
+	}
+
+	return missing
+}
+
+// validateWorkflowRunEvent checks that a workflow_run event carries the fields the copier relies on
+// and returns the JSON paths of any that are missing
+func validateWorkflowRunEvent(evt *github.WorkflowRunEvent) []string {
+	var missing []string
+
+	if evt.WorkflowRun == nil {
+		return append(missing, "workflow_run")
+	}
+	run := evt.GetWorkflowRun()
+	if run.GetID() == 0 {
+		missing = append(missing, "workflow_run.id")
+	}
+	if run.GetHeadSHA() == "" {
+		missing = append(missing, "workflow_run.head_sha")
+	}
+	if run.GetHeadBranch() == "" {
+		missing = append(missing, "workflow_run.head_branch")
+	}
+	if evt.GetRepo().GetFullName() == "" {
+		missing = append(missing, "repository.full_name")
+	}
+
+	return missing
+}
+
+// validateReleaseEvent checks that a release event carries the fields the copier relies on and returns
+// the JSON paths of any that are missing
+func validateReleaseEvent(evt *github.ReleaseEvent) []string {
+	var missing []string
+
+	if evt.Release == nil {
+		return append(missing, "release")
+	}
+	if evt.GetRelease().GetTagName() == "" {
+		missing = append(missing, "release.tag_name")
+	}
+	if evt.GetRepo().GetFullName() == "" {
+		missing = append(missing, "repository.full_name")
+	}
+
+	return missing
+}
+
+// rejectWebhook logs a rejected delivery with its delivery ID and event type (from the GitHub, GitLab, or Bitbucket headers),
+// records the failure, and writes a structured JSON error response.
+func rejectWebhook(ctx context.Context, w http.ResponseWriter, r *http.Request, container *ServiceContainer,
+	status int, resp WebhookErrorResponse, err error) {
+
+	resp.DeliveryID = r.Header.Get("X-GitHub-Delivery")
+	resp.EventType = r.Header.Get("X-GitHub-Event")
+	if r.Header.Get("X-Gitlab-Event") != "" || r.Header.Get("X-Gitlab-Event-UUID") != "" {
+		resp.DeliveryID = r.Header.Get("X-Gitlab-Event-UUID")
+		resp.EventType = r.Header.Get("X-Gitlab-Event")
+	}
+	if r.Header.Get("X-Event-Key") != "" {
+		resp.DeliveryID = r.Header.Get("X-Request-UUID")
+		resp.EventType = r.Header.Get("X-Event-Key")
+	}
+
+	fields := map[string]interface{}{
+		"status":      status,
+		"error_code":  resp.Error,
+		"delivery_id": resp.DeliveryID,
+		"event_type":  resp.EventType,
+	}
+	if len(resp.MissingFields) > 0 {
+		fields["missing_fields"] = resp.MissingFields
+	}
+	LogWebhookOperation(ctx, "reject", "webhook rejected: "+resp.Message, err, fields)
+
+	if container != nil && container.MetricsCollector != nil {
+		container.MetricsCollector.RecordWebhookFailed()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// validateIssueCommentEvent checks that an issue_comment event carries the fields the copier relies on
+// and returns the JSON paths of any that are missing
+func validateIssueCommentEvent(evt *github.IssueCommentEvent) []string {
+	var missing []string
+
+	if evt.GetAction() == "" {
+		missing = append(missing, "action")
+	}
+	if evt.Comment == nil {
+		missing = append(missing, "comment")
+	}
+	if evt.Issue == nil {
+		missing = append(missing, "issue")
+	} else if evt.GetIssue().GetNumber() == 0 {
+		missing = append(missing, "issue.number")
+	}
+
+	repo := evt.GetRepo()
+	if repo == nil {
+		return append(missing, "repository")
+	}
+	if repo.GetName() == "" {
+		missing = append(missing, "repository.name")
+	}
+	if repo.GetOwner().GetLogin() == "" {
+		missing = append(missing, "repository.owner.login")
+	}
+	if repo.GetFullName() == "" {
+		missing = append(missing, "repository.full_name")
+	}
+
+	return missing
+}
+
+// validateWorkflowDispatchEvent checks that a workflow_dispatch event carries the fields the copier relies on
+// and returns the JSON paths of any that are missing
+func validateWorkflowDispatchEvent(evt *github.WorkflowDispatchEvent) []string {
+	var missing []string
+
+	repo := evt.GetRepo()
+	if repo == nil {
+		return append(missing, "repository")
+	}
+	if repo.GetName() == "" {
+		missing = append(missing, "repository.name")
+	}
+	if repo.GetOwner().GetLogin() == "" {
+		missing = append(missing, "repository.owner.login")
+	}
+	if repo.GetFullName() == "" {
+		missing = append(missing, "repository.full_name")
+	}
+
+	return missing
+}
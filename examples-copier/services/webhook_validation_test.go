@@ -0,0 +1,223 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+)
+
+func TestValidatePullRequestEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		evt  *github.PullRequestEvent
+		want []string
+	}{
+		{
+			name: "missing pull_request",
+			evt:  &github.PullRequestEvent{Action: github.String("closed")},
+			want: []string{"pull_request"},
+		},
+		{
+			name: "missing action and pull_request",
+			evt:  &github.PullRequestEvent{},
+			want: []string{"action", "pull_request"},
+		},
+		{
+			name: "non-merged PR only needs action and pull_request",
+			evt: &github.PullRequestEvent{
+				Action:      github.String("opened"),
+				PullRequest: &github.PullRequest{Number: github.Int(1)},
+			},
+			want: nil,
+		},
+		{
+			name: "merged PR missing details",
+			evt: &github.PullRequestEvent{
+				Action:      github.String("closed"),
+				PullRequest: &github.PullRequest{Merged: github.Bool(true)},
+			},
+			want: []string{
+				"pull_request.number",
+				"pull_request.merge_commit_sha",
+				"pull_request.base.ref",
+				"repository",
+			},
+		},
+		{
+			name: "merged PR missing repository owner",
+			evt: &github.PullRequestEvent{
+				Action: github.String("closed"),
+				PullRequest: &github.PullRequest{
+					Number:         github.Int(1),
+					Merged:         github.Bool(true),
+					MergeCommitSHA: github.String("abc123"),
+					Base:           &github.PullRequestBranch{Ref: github.String("main")},
+				},
+				Repo: &github.Repository{Name: github.String("repo")},
+			},
+			want: []string{"repository.owner.login"},
+		},
+		{
+			name: "complete merged PR",
+			evt: &github.PullRequestEvent{
+				Action: github.String("closed"),
+				PullRequest: &github.PullRequest{
+					Number:         github.Int(1),
+					Merged:         github.Bool(true),
+					MergeCommitSHA: github.String("abc123"),
+					Base:           &github.PullRequestBranch{Ref: github.String("main")},
+				},
+				Repo: &github.Repository{
+					Name:  github.String("repo"),
+					Owner: &github.User{Login: github.String("owner")},
+				},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validatePullRequestEvent(tt.evt)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validatePullRequestEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePushEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		evt  *github.PushEvent
+		want []string
+	}{
+		{
+			name: "empty push",
+			evt:  &github.PushEvent{},
+			want: []string{"ref", "after", "repository.full_name"},
+		},
+		{
+			name: "branch deletion only needs ref",
+			evt:  &github.PushEvent{Ref: github.String("refs/heads/old"), Deleted: github.Bool(true)},
+			want: nil,
+		},
+		{
+			name: "complete push",
+			evt: &github.PushEvent{
+				Ref:   github.String("refs/heads/main"),
+				After: github.String("def456"),
+				Repo:  &github.PushEventRepository{FullName: github.String("owner/repo")},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validatePushEvent(tt.evt)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validatePushEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookWithContainer_MergedPRMissingFields(t *testing.T) {
+	config := &configs.Config{
+		ConfigRepoOwner: "test-owner",
+		ConfigRepoName:  "test-repo",
+		AuditEnabled:    false,
+	}
+
+	container, err := NewServiceContainer(config)
+	if err != nil {
+		t.Fatalf("NewServiceContainer() error = %v", err)
+	}
+
+	// Merged PR without merge_commit_sha, base ref, or repository
+	prEvent := &github.PullRequestEvent{
+		Action: github.String("closed"),
+		PullRequest: &github.PullRequest{
+			Number: github.Int(42),
+			Merged: github.Bool(true),
+		},
+	}
+	payload, _ := json.Marshal(prEvent)
+
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	req.Header.Set("X-GitHub-Delivery", "delivery-123")
+
+	w := httptest.NewRecorder()
+
+	HandleWebhookWithContainer(w, req, config, container)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Status code = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+
+	var resp WebhookErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if resp.Error != webhookErrMissingFields {
+		t.Errorf("Error = %q, want %q", resp.Error, webhookErrMissingFields)
+	}
+	if resp.DeliveryID != "delivery-123" {
+		t.Errorf("DeliveryID = %q, want delivery-123", resp.DeliveryID)
+	}
+	if resp.EventType != "pull_request" {
+		t.Errorf("EventType = %q, want pull_request", resp.EventType)
+	}
+	wantMissing := []string{"pull_request.merge_commit_sha", "pull_request.base.ref", "repository"}
+	if !reflect.DeepEqual(resp.MissingFields, wantMissing) {
+		t.Errorf("MissingFields = %v, want %v", resp.MissingFields, wantMissing)
+	}
+
+	if got := container.MetricsCollector.webhookFailed; got != 1 {
+		t.Errorf("webhookFailed = %d, want 1", got)
+	}
+}
+
+func TestHandleWebhookWithContainer_InvalidJSON(t *testing.T) {
+	config := &configs.Config{
+		ConfigRepoOwner: "test-owner",
+		ConfigRepoName:  "test-repo",
+		AuditEnabled:    false,
+	}
+
+	container, err := NewServiceContainer(config)
+	if err != nil {
+		t.Fatalf("NewServiceContainer() error = %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader([]byte(`{not json`)))
+	req.Header.Set("X-GitHub-Event", "pull_request")
+
+	w := httptest.NewRecorder()
+
+	HandleWebhookWithContainer(w, req, config, container)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Status code = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	var resp WebhookErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Error != webhookErrInvalidPayload {
+		t.Errorf("Error = %q, want %q", resp.Error, webhookErrInvalidPayload)
+	}
+}
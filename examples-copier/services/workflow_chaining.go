@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// maxChainDepth is how many chained copies can follow the change that started them
+const maxChainDepth = 5
+
+// chainHop identifies a repo and branch a copy ran from or landed on, as "repo@branch". Bitbucket repos
+// carry the same prefix as in destinations.
+func chainHop(platform string, repo string, branch string) string {
+	switch platform {
+	case types.SourcePlatformBitbucket:
+		repo = types.BitbucketRepoPrefix + repo
+	case types.SourcePlatformGitLab:
+		repo = "gitlab:" + repo
+	}
+	return repo + "@" + strings.TrimPrefix(branch, "refs/heads/")
+}
+
+// chainedChanges returns the changes that run chained workflows after a change's uploads: one for each
+// upload that landed a commit on a destination branch that a workflow with the chained trigger copies from.
+// Only direct commits and merged PRs land on the branch; PRs left open or held for approval, and pushes to
+// copier branches, aren't chained. Branches the chain already copied from aren't chained to again, so
+// workflows that copy in a cycle stop, and chains stop after maxChainDepth copies.
+func chainedChanges(ctx context.Context, change CopyEvent, workflows []types.Workflow,
+	queued map[types.UploadKey]types.UploadFileContent, uploads map[types.UploadKey]UploadResult) []CopyEvent {
+
+	keys := make([]types.UploadKey, 0, len(uploads))
+	for key := range uploads {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].RepoName != keys[j].RepoName {
+			return keys[i].RepoName < keys[j].RepoName
+		}
+		return keys[i].BranchPath < keys[j].BranchPath
+	})
+
+	chain := append(append([]string(nil), change.Chain...), chainHop(change.Platform, change.Repo, change.BaseBranch))
+	var changes []CopyEvent
+	for _, key := range keys {
+		result, value := uploads[key], queued[key]
+		if result.Err != nil || result.CommitSHA == "" || result.AwaitingApproval ||
+			value.CommitStrategy == types.CommitStrategyBranch {
+			continue
+		}
+
+		platform, repo := types.SplitDestinationRepo(key.RepoName)
+		branch := strings.TrimPrefix(key.BranchPath, "refs/heads/")
+		chained := CopyEvent{
+			Platform:      platform,
+			Repo:          repo,
+			CommitSHA:     result.CommitSHA,
+			BaseBranch:    branch,
+			URL:           result.PRURL,
+			Title:         change.Title,
+			Author:        change.Author,
+			Trigger:       types.WorkflowTriggerChained,
+			ChainedFiles:  chainedFiles(value),
+			Chain:         chain,
+			CorrelationID: change.CorrelationID,
+		}
+		if len(matchWorkflows(workflows, chained)) == 0 {
+			continue
+		}
+
+		fields := map[string]interface{}{
+			"repo":   key.RepoName,
+			"branch": branch,
+			"sha":    result.CommitSHA,
+			"chain":  chain,
+		}
+		if slices.Contains(chain, chainHop(platform, repo, branch)) {
+			LogWarningCtx(ctx, "skipping chained workflows that would copy in a cycle", fields)
+			continue
+		}
+		if len(chain) > maxChainDepth {
+			LogWarningCtx(ctx, "skipping chained workflows past the maximum chain depth", fields)
+			continue
+		}
+		changes = append(changes, chained)
+	}
+	return changes
+}
+
+// chainedFiles returns the files an upload wrote and deleted, as the changed files of the commit it made
+func chainedFiles(value types.UploadFileContent) []types.ChangedFile {
+	files := make([]types.ChangedFile, 0, len(value.Content)+len(value.DeletePaths))
+	for _, file := range value.Content {
+		files = append(files, types.ChangedFile{Path: file.GetName(), Status: "MODIFIED"})
+	}
+	for _, path := range value.DeletePaths {
+		files = append(files, types.ChangedFile{Path: path, Status: statusDeleted})
+	}
+	return files
+}
+
+// runChainedWorkflows runs the chained workflows for the commits a change's uploads landed, in this process,
+// rather than waiting for the destination's webhook, which loop prevention skips since the copier made the
+// commit. Each chained change is processed like a merged PR, and can start chains of its own.
+func runChainedWorkflows(ctx context.Context, change CopyEvent, workflows []types.Workflow,
+	queued map[types.UploadKey]types.UploadFileContent, uploads map[types.UploadKey]UploadResult,
+	config *configs.Config, container *ServiceContainer) {
+
+	for _, chained := range chainedChanges(ctx, change, workflows, queued, uploads) {
+		LogInfoCtx(ctx, "running chained workflows", map[string]interface{}{
+			"source_repo": change.Repo,
+			"repo":        chained.Repo,
+			"branch":      chained.BaseBranch,
+			"sha":         chained.CommitSHA,
+			"depth":       len(chained.Chain),
+		})
+		handleMergedPRWithContainer(ctx, chained, config, container)
+	}
+}
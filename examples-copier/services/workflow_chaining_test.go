@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainedChanges(t *testing.T) {
+	workflow := func(name, repo, platform string, trigger ...string) types.Workflow {
+		return types.Workflow{
+			Name:    name,
+			Source:  types.Source{Repo: repo, Branch: "main", Platform: platform},
+			Trigger: types.WorkflowTriggers(trigger),
+		}
+	}
+	workflows := []types.Workflow{
+		workflow("drivers-to-staging", "mongodb/go-driver", ""),
+		workflow("staging-to-docs", "mongodb/staging", "", types.WorkflowTriggerChained),
+		workflow("bitbucket-to-docs", "docs/examples", types.SourcePlatformBitbucket, types.WorkflowTriggerChained),
+		workflow("unchained", "mongodb/other", ""),
+	}
+	change := CopyEvent{
+		Platform:      types.SourcePlatformGitHub,
+		Repo:          "mongodb/go-driver",
+		Number:        12,
+		CommitSHA:     "abc123",
+		BaseBranch:    "main",
+		Author:        "octocat",
+		CorrelationID: "delivery-1",
+	}
+
+	staging := types.UploadKey{RepoName: "mongodb/staging", BranchPath: "main"}
+	bitbucket := types.UploadKey{RepoName: "bitbucket:docs/examples", BranchPath: "refs/heads/main"}
+	other := types.UploadKey{RepoName: "mongodb/other", BranchPath: "main"}
+	queued := map[types.UploadKey]types.UploadFileContent{
+		staging: {
+			Content:     []github.RepositoryContent{{Name: github.String("go/main.go")}},
+			DeletePaths: []string{"go/old.go"},
+		},
+		bitbucket: {Content: []github.RepositoryContent{{Name: github.String("go/main.go")}}},
+		other:     {Content: []github.RepositoryContent{{Name: github.String("go/main.go")}}},
+	}
+	uploads := map[types.UploadKey]UploadResult{
+		staging:   {CommitSHA: "def456"},
+		bitbucket: {CommitSHA: "789abc"},
+		other:     {CommitSHA: "fedcba"},
+	}
+
+	changes := chainedChanges(context.Background(), change, workflows, queued, uploads)
+	require.Len(t, changes, 2, "only branches chained workflows copy from are chained")
+
+	assert.Equal(t, types.SourcePlatformBitbucket, changes[0].Platform)
+	assert.Equal(t, "docs/examples", changes[0].Repo)
+	assert.Equal(t, "main", changes[0].BaseBranch)
+
+	chained := changes[1]
+	assert.Equal(t, types.SourcePlatformGitHub, chained.Platform)
+	assert.Equal(t, "mongodb/staging", chained.Repo)
+	assert.Equal(t, "def456", chained.CommitSHA)
+	assert.Equal(t, types.WorkflowTriggerChained, chained.trigger())
+	assert.Equal(t, "octocat", chained.Author)
+	assert.Equal(t, "delivery-1", chained.CorrelationID)
+	assert.Equal(t, []string{"mongodb/go-driver@main"}, chained.Chain)
+	assert.Equal(t, []types.ChangedFile{
+		{Path: "go/main.go", Status: "MODIFIED"},
+		{Path: "go/old.go", Status: statusDeleted},
+	}, chained.ChainedFiles)
+	assert.Equal(t, "copy to main", chained.describe())
+	assert.Equal(t, "copier/source-chain-def456", sourcePRBranch(chained))
+
+	files, err := getMergedChangeFiles(context.Background(), chained, workflows)
+	require.NoError(t, err)
+	assert.Equal(t, chained.ChainedFiles, files)
+}
+
+func TestChainedChanges_SkipsUploadsThatDidNotLand(t *testing.T) {
+	workflows := []types.Workflow{{
+		Name:    "staging-to-docs",
+		Source:  types.Source{Repo: "mongodb/staging", Branch: "main"},
+		Trigger: types.WorkflowTriggers{types.WorkflowTriggerChained},
+	}}
+	change := CopyEvent{Platform: types.SourcePlatformGitHub, Repo: "mongodb/go-driver", BaseBranch: "main"}
+	key := types.UploadKey{RepoName: "mongodb/staging", BranchPath: "main"}
+
+	tests := []struct {
+		name   string
+		value  types.UploadFileContent
+		result UploadResult
+	}{
+		{name: "failed", result: UploadResult{CommitSHA: "def456", Err: errors.New("conflict")}},
+		{name: "PR left open", result: UploadResult{PRNumber: 3}},
+		{name: "held for approval", result: UploadResult{PRNumber: 3, CommitSHA: "def456", AwaitingApproval: true}},
+		{name: "copier branch", value: types.UploadFileContent{CommitStrategy: types.CommitStrategyBranch},
+			result: UploadResult{CommitSHA: "def456"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := chainedChanges(context.Background(), change, workflows,
+				map[types.UploadKey]types.UploadFileContent{key: tt.value},
+				map[types.UploadKey]UploadResult{key: tt.result})
+			assert.Empty(t, changes)
+		})
+	}
+}
+
+func TestChainedChanges_StopsCyclesAndDeepChains(t *testing.T) {
+	workflows := []types.Workflow{
+		{Name: "a-to-b", Source: types.Source{Repo: "org/a", Branch: "main"}, Trigger: types.WorkflowTriggers{types.WorkflowTriggerChained}},
+		{Name: "b-to-a", Source: types.Source{Repo: "org/b", Branch: "main"}, Trigger: types.WorkflowTriggers{types.WorkflowTriggerChained}},
+	}
+	key := types.UploadKey{RepoName: "org/a", BranchPath: "main"}
+	queued := map[types.UploadKey]types.UploadFileContent{key: {Content: []github.RepositoryContent{{Name: github.String("a.go")}}}}
+	uploads := map[types.UploadKey]UploadResult{key: {CommitSHA: "def456"}}
+
+	// org/a's workflow copied to org/b, whose chained workflow copied back to org/a
+	change := CopyEvent{
+		Platform:   types.SourcePlatformGitHub,
+		Repo:       "org/b",
+		BaseBranch: "main",
+		Trigger:    types.WorkflowTriggerChained,
+		Chain:      []string{"org/a@main"},
+	}
+	assert.Empty(t, chainedChanges(context.Background(), change, workflows, queued, uploads))
+
+	change.Chain = []string{"org/c@main", "org/d@main", "org/e@main", "org/f@main", "org/g@main"}
+	assert.Empty(t, chainedChanges(context.Background(), change, workflows, queued, uploads))
+
+	change.Chain = change.Chain[1:]
+	changes := chainedChanges(context.Background(), change, workflows, queued, uploads)
+	require.Len(t, changes, 1)
+	assert.Len(t, changes[0].Chain, maxChainDepth)
+}
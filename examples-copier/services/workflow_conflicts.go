@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// WorkflowConflictError is a workflow's error under conflict_policy error: other workflows matched some of
+// the changed files it matched, so none of them copied those files
+type WorkflowConflictError struct {
+	Workflow  string
+	Conflicts map[string][]string // Every workflow that matched each conflicting file, in priority order
+}
+
+func (e *WorkflowConflictError) Error() string {
+	paths := make([]string, 0, len(e.Conflicts))
+	for path := range e.Conflicts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	described := make([]string, len(paths))
+	for i, path := range paths {
+		described[i] = fmt.Sprintf("%s (%s)", path, strings.Join(e.Conflicts[path], ", "))
+	}
+	return fmt.Sprintf("%d files matched by more than one workflow weren't copied under conflict_policy %s: %s",
+		len(paths), types.ConflictPolicyError, strings.Join(described, "; "))
+}
+
+// resolveWorkflowConflicts applies the conflict policy to the changed files each workflow would copy.
+// workflows are in priority order, and files[i] holds workflows[i]'s changed files; it's updated in place.
+// Under first-match-wins, a file that more than one workflow matches is left only to the first of them.
+// Under error, it's removed from all of them, and each gets a *WorkflowConflictError in the returned
+// errors, which are indexed like workflows.
+func resolveWorkflowConflicts(ctx context.Context, wp *workflowProcessor, policy string,
+	workflows []types.Workflow, files [][]types.ChangedFile) []error {
+
+	errs := make([]error, len(workflows))
+	if policy == types.ConflictPolicyAll || len(workflows) < 2 {
+		return errs
+	}
+
+	// The workflows that match each file, in priority order
+	matchedBy := make(map[string][]int)
+	var paths []string
+	for i, workflow := range workflows {
+		for _, file := range files[i] {
+			if _, ok := wp.mapSourcePath(ctx, workflow, file.Path); !ok {
+				continue
+			}
+			if _, seen := matchedBy[file.Path]; !seen {
+				paths = append(paths, file.Path)
+			}
+			matchedBy[file.Path] = append(matchedBy[file.Path], i)
+		}
+	}
+
+	skipped := make([]map[string]bool, len(workflows))
+	conflicts := make([]map[string][]string, len(workflows))
+	for _, path := range paths {
+		// A workflow fanned out to several destination branches runs once per branch under the same name,
+		// and its runs don't conflict with each other
+		matched := matchedBy[path]
+		var names []string
+		for _, i := range matched {
+			if !slices.Contains(names, workflows[i].Name) {
+				names = append(names, workflows[i].Name)
+			}
+		}
+		if len(names) < 2 {
+			continue
+		}
+		LogWarningCtx(ctx, "file matched by more than one workflow", map[string]interface{}{
+			"file_path":       path,
+			"workflows":       names,
+			"conflict_policy": policy,
+		})
+
+		losers := matched
+		if policy != types.ConflictPolicyError {
+			losers = slices.DeleteFunc(slices.Clone(matched), func(i int) bool { return workflows[i].Name == names[0] })
+		}
+		for _, i := range losers {
+			if skipped[i] == nil {
+				skipped[i] = make(map[string]bool)
+			}
+			skipped[i][path] = true
+			if policy == types.ConflictPolicyError {
+				if conflicts[i] == nil {
+					conflicts[i] = make(map[string][]string)
+				}
+				conflicts[i][path] = names
+			}
+		}
+	}
+
+	for i := range workflows {
+		if len(skipped[i]) > 0 {
+			kept := make([]types.ChangedFile, 0, len(files[i]))
+			for _, file := range files[i] {
+				if !skipped[i][file.Path] {
+					kept = append(kept, file)
+				}
+			}
+			files[i] = kept
+		}
+		if len(conflicts[i]) > 0 {
+			errs[i] = &WorkflowConflictError{Workflow: workflows[i].Name, Conflicts: conflicts[i]}
+		}
+	}
+	return errs
+}
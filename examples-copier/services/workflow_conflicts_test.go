@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func conflictTestWorkflows() []types.Workflow {
+	workflow := func(name, from string, priority int) types.Workflow {
+		return types.Workflow{
+			Name:            name,
+			Priority:        priority,
+			Source:          types.Source{Repo: "org/src", Branch: "main"},
+			Destination:     types.Destination{Repo: "org/" + name, Branch: "main"},
+			Transformations: []types.Transformation{{Move: &types.MoveTransform{From: from, To: "code"}}},
+		}
+	}
+	return []types.Workflow{
+		workflow("everything", "examples", 0),
+		workflow("python", "examples/python", 5),
+	}
+}
+
+func conflictTestFiles(workflows []types.Workflow) [][]types.ChangedFile {
+	files := make([][]types.ChangedFile, len(workflows))
+	for i := range files {
+		files[i] = []types.ChangedFile{
+			{Path: "examples/python/main.py", Status: "removed"},
+			{Path: "examples/go/main.go", Status: "removed"},
+		}
+	}
+	return files
+}
+
+func changedFilePaths(files []types.ChangedFile) []string {
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+func TestResolveWorkflowConflicts(t *testing.T) {
+	wp := &workflowProcessor{patternMatcher: NewPatternMatcher(), pathTransformer: NewPathTransformer()}
+	workflows := conflictTestWorkflows()
+	types.SortWorkflowsByPriority(workflows)
+
+	t.Run("all", func(t *testing.T) {
+		files := conflictTestFiles(workflows)
+		errs := resolveWorkflowConflicts(context.Background(), wp, types.ConflictPolicyAll, workflows, files)
+		assert.Equal(t, []error{nil, nil}, errs)
+		assert.Len(t, files[0], 2)
+		assert.Len(t, files[1], 2)
+	})
+
+	t.Run("first-match-wins", func(t *testing.T) {
+		files := conflictTestFiles(workflows)
+		errs := resolveWorkflowConflicts(context.Background(), wp, types.ConflictPolicyFirstMatchWins, workflows, files)
+		assert.Equal(t, []error{nil, nil}, errs)
+		assert.Equal(t, "python", workflows[0].Name)
+		assert.Len(t, files[0], 2, "files the python workflow doesn't match aren't conflicts")
+		assert.Equal(t, []string{"examples/go/main.go"}, changedFilePaths(files[1]))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		files := conflictTestFiles(workflows)
+		errs := resolveWorkflowConflicts(context.Background(), wp, types.ConflictPolicyError, workflows, files)
+		require.Len(t, errs, 2)
+		for i, err := range errs {
+			var conflictErr *WorkflowConflictError
+			require.ErrorAs(t, err, &conflictErr)
+			assert.Equal(t, workflows[i].Name, conflictErr.Workflow)
+			assert.Equal(t, map[string][]string{"examples/python/main.py": {"python", "everything"}}, conflictErr.Conflicts)
+			assert.Equal(t, []string{"examples/go/main.go"}, changedFilePaths(files[i]))
+		}
+		assert.EqualError(t, errs[0], "1 files matched by more than one workflow weren't copied under conflict_policy error: "+
+			"examples/python/main.py (python, everything)")
+	})
+}
+
+func TestProcessFilesWithWorkflows_ConflictPolicy(t *testing.T) {
+	on := true
+	container, err := NewServiceContainer(&configs.Config{})
+	require.NoError(t, err)
+
+	workflows := conflictTestWorkflows()
+	for i := range workflows {
+		workflows[i].DryRun = &on
+	}
+	yamlConfig := &types.YAMLConfig{Workflows: workflows, ConflictPolicy: types.ConflictPolicyFirstMatchWins}
+	changedFiles := conflictTestFiles(workflows)[0]
+
+	runs := processFilesWithWorkflows(context.Background(), 9, "abc", changedFiles, yamlConfig, container)
+
+	require.Len(t, runs, 2)
+	assert.Equal(t, "python", runs[0].Workflow.Name, "higher priority workflows run first")
+	require.NotNil(t, runs[0].DryRun)
+	require.Len(t, runs[0].DryRun.Deprecated, 1)
+	assert.Equal(t, "code/main.py", runs[0].DryRun.Deprecated[0].File)
+
+	require.NotNil(t, runs[1].DryRun)
+	require.Len(t, runs[1].DryRun.Deprecated, 1)
+	assert.Equal(t, "code/go/main.go", runs[1].DryRun.Deprecated[0].File)
+	assert.NoError(t, runs[1].Err)
+}
+
+func TestResolveWorkflowConflicts_DestinationBranches(t *testing.T) {
+	wp := &workflowProcessor{patternMatcher: NewPatternMatcher(), pathTransformer: NewPathTransformer()}
+	workflows := conflictTestWorkflows()
+	types.SortWorkflowsByPriority(workflows)
+	python := workflows[0]
+	python.Destination.Branches = []string{"v7.0", "v8.0"}
+	workflows = append(expandDestinationBranches(context.Background(), []types.Workflow{python}), workflows[1])
+
+	files := conflictTestFiles(workflows)
+	errs := resolveWorkflowConflicts(context.Background(), wp, types.ConflictPolicyFirstMatchWins, workflows, files)
+	assert.Equal(t, []error{nil, nil, nil}, errs)
+	assert.Len(t, files[0], 2, "a workflow's branches don't conflict with each other")
+	assert.Len(t, files[1], 2)
+	assert.Equal(t, []string{"examples/go/main.go"}, changedFilePaths(files[2]))
+
+	files = conflictTestFiles(workflows)
+	errs = resolveWorkflowConflicts(context.Background(), wp, types.ConflictPolicyError, workflows, files)
+	for i, err := range errs {
+		var conflictErr *WorkflowConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, map[string][]string{"examples/python/main.py": {"python", "everything"}}, conflictErr.Conflicts, i)
+	}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
+)
+
+// dispatchPRInput is the workflow_dispatch input naming the merged PR to copy again
+const dispatchPRInput = "pr_number"
+
+// workflowDispatchEventSource converts workflow_dispatch events, sent when someone runs a GitHub Actions
+// workflow from the Actions tab or the API, into the merge of the PR named by the workflow's pr_number input,
+// for workflows with the pr_merged trigger. Only users with write access can dispatch a workflow, so it's a
+// way to copy a PR again without an operator, like a copy command. Dispatches without a pr_number input, and
+// of PRs that aren't merged or that the copier opened, don't trigger a copy.
+type workflowDispatchEventSource struct {
+	copierLabel string // the label the copier adds to its PRs
+	// getPullRequest looks up the PR named by the dispatch
+	getPullRequest func(ctx context.Context, owner string, name string, number int) (*github.PullRequest, error)
+}
+
+func (workflowDispatchEventSource) Name() string { return "workflow_dispatch" }
+
+func (s workflowDispatchEventSource) Convert(ctx context.Context, payload []byte) (*CopyEvent, string, error) {
+	var evt github.WorkflowDispatchEvent
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return nil, "", invalidPayload(err)
+	}
+	if missing := validateWorkflowDispatchEvent(&evt); len(missing) > 0 {
+		return nil, "", missingFields("workflow_dispatch", missing)
+	}
+
+	number, ok, err := dispatchPRNumber(evt.Inputs)
+	if err != nil {
+		return nil, "", invalidPayload(err)
+	}
+	if !ok {
+		return nil, "no " + dispatchPRInput + " input", nil
+	}
+
+	change, reason, err := lookUpMergedPullRequest(ctx, s.getPullRequest, s.copierLabel, evt.GetRepo(), number)
+	if change == nil {
+		return nil, reason, err
+	}
+
+	LogInfoCtx(ctx, "processing workflow dispatch", map[string]interface{}{
+		"pr_number":   change.Number,
+		"workflow":    evt.GetWorkflow(),
+		"sender":      evt.GetSender().GetLogin(),
+		"sha":         change.CommitSHA,
+		"repo":        change.Repo,
+		"base_branch": change.BaseBranch,
+	})
+	return change, "", nil
+}
+
+// dispatchPRNumber returns the PR number in a dispatch's pr_number input, and false if the input isn't set.
+// Inputs are usually strings, but a number is accepted too.
+func dispatchPRNumber(inputs json.RawMessage) (int, bool, error) {
+	if len(inputs) == 0 {
+		return 0, false, nil
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(inputs, &values); err != nil {
+		return 0, false, fmt.Errorf("parse inputs: %w", err)
+	}
+
+	var number int
+	switch value := values[dispatchPRInput].(type) {
+	case nil:
+		return 0, false, nil
+	case string:
+		if strings.TrimSpace(value) == "" {
+			return 0, false, nil
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(value), "#"))
+		if err != nil {
+			return 0, false, fmt.Errorf("%s input %q isn't a PR number", dispatchPRInput, value)
+		}
+		number = n
+	case float64:
+		number = int(value)
+	default:
+		return 0, false, fmt.Errorf("%s input isn't a PR number", dispatchPRInput)
+	}
+	if number <= 0 {
+		return 0, false, fmt.Errorf("%s input %d isn't a PR number", dispatchPRInput, number)
+	}
+	return number, true, nil
+}
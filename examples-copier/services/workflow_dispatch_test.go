@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workflowDispatchPayload returns a workflow_dispatch payload for a workflow in org/src with the given inputs
+func workflowDispatchPayload(inputs string) []byte {
+	return []byte(fmt.Sprintf(`{
+		"inputs": %s,
+		"ref": "refs/heads/main",
+		"workflow": ".github/workflows/copy.yml",
+		"sender": {"login": "maintainer"},
+		"repository": {"name": "src", "full_name": "org/src", "owner": {"login": "org"}}
+	}`, inputs))
+}
+
+func TestWorkflowDispatchEventSource_CopiesPR(t *testing.T) {
+	source := workflowDispatchEventSource{copierLabel: "examples-copier", getPullRequest: stubPullRequest(t, mergedPullRequest())}
+
+	for _, inputs := range []string{`{"pr_number": "42"}`, `{"pr_number": "#42"}`, `{"pr_number": 42}`} {
+		event, _, err := source.Convert(context.Background(), workflowDispatchPayload(inputs))
+		require.NoError(t, err, inputs)
+		assert.Equal(t, &CopyEvent{
+			Platform:   types.SourcePlatformGitHub,
+			Repo:       "org/src",
+			Number:     42,
+			CommitSHA:  "abc123",
+			BaseBranch: "main",
+			URL:        "https://github.com/org/src/pull/42",
+			Title:      "Add examples",
+			Author:     "author",
+		}, event, inputs)
+	}
+}
+
+func TestWorkflowDispatchEventSource_IgnoresOtherDispatches(t *testing.T) {
+	unmerged := mergedPullRequest()
+	unmerged.Merged = github.Bool(false)
+	copierPR := mergedPullRequest()
+	copierPR.Labels = []*github.Label{{Name: github.String("examples-copier")}}
+
+	tests := []struct {
+		name   string
+		inputs string
+		pr     *github.PullRequest
+		reason string
+	}{
+		{name: "no inputs", inputs: `null`, reason: "no pr_number input"},
+		{name: "other inputs", inputs: `{"environment": "staging"}`, reason: "no pr_number input"},
+		{name: "empty input", inputs: `{"pr_number": ""}`, reason: "no pr_number input"},
+		{name: "unmerged", inputs: `{"pr_number": "42"}`, pr: unmerged, reason: "PR isn't merged"},
+		{name: "copier PR", inputs: `{"pr_number": "42"}`, pr: copierPR, reason: "PR opened by the copier"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := workflowDispatchEventSource{copierLabel: "examples-copier",
+				getPullRequest: func(ctx context.Context, owner string, name string, number int) (*github.PullRequest, error) {
+					require.NotNil(t, tt.pr, "PR looked up for an ignored dispatch")
+					return tt.pr, nil
+				}}
+			event, reason, err := source.Convert(context.Background(), workflowDispatchPayload(tt.inputs))
+			require.NoError(t, err)
+			assert.Nil(t, event)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestWorkflowDispatchEventSource_Rejections(t *testing.T) {
+	source := workflowDispatchEventSource{
+		getPullRequest: func(ctx context.Context, owner string, name string, number int) (*github.PullRequest, error) {
+			return nil, errors.New("not found")
+		}}
+
+	_, _, err := source.Convert(context.Background(), workflowDispatchPayload(`{"pr_number": "42"}`))
+	var rejection *EventRejection
+	require.ErrorAs(t, err, &rejection)
+	assert.Equal(t, http.StatusBadGateway, rejection.Status)
+
+	for _, inputs := range []string{`{"pr_number": "main"}`, `{"pr_number": "0"}`, `{"pr_number": true}`} {
+		_, _, err = source.Convert(context.Background(), workflowDispatchPayload(inputs))
+		require.ErrorAs(t, err, &rejection, inputs)
+		assert.Equal(t, http.StatusBadRequest, rejection.Status, inputs)
+	}
+
+	_, _, err = source.Convert(context.Background(), []byte(`{"inputs": {"pr_number": "42"}}`))
+	require.ErrorAs(t, err, &rejection)
+	assert.Equal(t, []string{"repository"}, rejection.Response.MissingFields)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v48/github"
+	. "github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// Names of the workflow limits, as they appear in the workflow config
+const (
+	limitMaxFiles           = "max_files"
+	limitMaxTotalBytes      = "max_total_bytes"
+	limitMaxSingleFileBytes = "max_single_file_bytes"
+)
+
+// WorkflowLimitError is returned when a workflow run would copy more than its limits allow
+type WorkflowLimitError struct {
+	Workflow string
+	Limit    string // The limit that was exceeded, e.g. "max_files"
+	Max      int64
+	Actual   int64
+	Path     string // The source file, for max_single_file_bytes
+}
+
+// Error implements the error interface
+func (e *WorkflowLimitError) Error() string {
+	switch e.Limit {
+	case limitMaxFiles:
+		return fmt.Sprintf("workflow %s would copy %d files, more than %s (%d); nothing was copied",
+			e.Workflow, e.Actual, e.Limit, e.Max)
+	case limitMaxSingleFileBytes:
+		return fmt.Sprintf("%s is %d bytes, more than %s (%d); the file was not copied",
+			e.Path, e.Actual, e.Limit, e.Max)
+	default:
+		return fmt.Sprintf("workflow %s would copy %d bytes, more than %s (%d); nothing was copied",
+			e.Workflow, e.Actual, e.Limit, e.Max)
+	}
+}
+
+// checkFileCountLimit returns a *WorkflowLimitError if the changed files the workflow would copy are more than
+// its max_files limit. It runs before any file is fetched, so a run over the limit stages nothing.
+func (wp *workflowProcessor) checkFileCountLimit(ctx context.Context, workflow Workflow, changedFiles []ChangedFile, prNumber int) error {
+	maxFiles := workflow.Limits.GetMaxFiles()
+	if maxFiles == 0 {
+		return nil
+	}
+
+	count := 0
+	for _, file := range changedFiles {
+		if isDeletedFile(file) {
+			continue
+		}
+		if _, ok := wp.mapSourcePath(ctx, workflow, file.Path); ok {
+			count++
+		}
+	}
+	if count <= maxFiles {
+		return nil
+	}
+
+	limitErr := &WorkflowLimitError{Workflow: workflow.Name, Limit: limitMaxFiles, Max: int64(maxFiles), Actual: int64(count)}
+	wp.reportLimitExceeded(ctx, workflow, limitErr, prNumber)
+	return limitErr
+}
+
+// checkFileSizeLimit returns a *WorkflowLimitError if a file is larger than the workflow's max_single_file_bytes limit
+func (wp *workflowProcessor) checkFileSizeLimit(
+	ctx context.Context,
+	workflow Workflow,
+	sourcePath string,
+	fileContent *github.RepositoryContent,
+	prNumber int,
+) error {
+	maxBytes := workflow.Limits.GetMaxSingleFileBytes()
+	if maxBytes == 0 {
+		return nil
+	}
+
+	size := stagedFileSize(fileContent)
+	if size <= maxBytes {
+		return nil
+	}
+
+	limitErr := &WorkflowLimitError{Workflow: workflow.Name, Limit: limitMaxSingleFileBytes, Max: maxBytes, Actual: size, Path: sourcePath}
+	wp.reportLimitExceeded(ctx, workflow, limitErr, prNumber)
+	return limitErr
+}
+
+// checkTotalSizeLimit returns a *WorkflowLimitError if the files the run staged, those after the first
+// stagedBefore entries of the upload, add up to more than the workflow's max_total_bytes limit.
+func (wp *workflowProcessor) checkTotalSizeLimit(ctx context.Context, workflow Workflow, key UploadKey, stagedBefore int, prNumber int) error {
+	maxBytes := workflow.Limits.GetMaxTotalBytes()
+	if maxBytes == 0 {
+		return nil
+	}
+
+	content, ok := wp.fileStateService.GetFilesToUpload()[key]
+	if !ok || len(content.Content) <= stagedBefore {
+		return nil
+	}
+
+	var total int64
+	for i := stagedBefore; i < len(content.Content); i++ {
+		total += stagedFileSize(&content.Content[i])
+	}
+	if total <= maxBytes {
+		return nil
+	}
+
+	limitErr := &WorkflowLimitError{Workflow: workflow.Name, Limit: limitMaxTotalBytes, Max: maxBytes, Actual: total}
+	wp.reportLimitExceeded(ctx, workflow, limitErr, prNumber)
+	return limitErr
+}
+
+// reportLimitExceeded logs and alerts on a workflow run that exceeded one of its limits
+func (wp *workflowProcessor) reportLimitExceeded(ctx context.Context, workflow Workflow, limitErr *WorkflowLimitError, prNumber int) {
+	LogWarningCtx(ctx, "Workflow limit exceeded", map[string]interface{}{
+		"workflow_name":    workflow.Name,
+		"destination_repo": workflow.Destination.Repo,
+		"limit":            limitErr.Limit,
+		"max":              limitErr.Max,
+		"actual":           limitErr.Actual,
+		"source_path":      limitErr.Path,
+	})
+
+	if wp.slackNotifier == nil {
+		return
+	}
+	if err := wp.slackNotifier.NotifyError(ctx, &ErrorEvent{
+		Operation:  "workflow_limits",
+		Error:      limitErr,
+		PRNumber:   prNumber,
+		SourceRepo: workflow.Source.Repo,
+		AdditionalInfo: map[string]interface{}{
+			"workflow":    workflow.Name,
+			"target_repo": workflow.Destination.Repo,
+			"limit":       limitErr.Limit,
+		},
+	}); err != nil {
+		LogErrorCtx(ctx, "Failed to send workflow limit alert", err, map[string]interface{}{
+			"workflow_name": workflow.Name,
+		})
+	}
+}
+
+// stagedFileSize returns the size of a file's content as it will be copied
+func stagedFileSize(file *github.RepositoryContent) int64 {
+	if text, err := file.GetContent(); err == nil && text != "" {
+		return int64(len(text))
+	}
+	return int64(file.GetSize())
+}
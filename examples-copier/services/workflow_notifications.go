@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// workflowRun records what one workflow did while processing a merged PR
+type workflowRun struct {
+	Workflow  types.Workflow
+	Files     []string      // Target paths the workflow queued for upload
+	Deletions []string      // Target paths the workflow queued for removal by sync
+	Err       error         // Files that failed, or the error that stopped the workflow
+	DryRun    *DryRunReport // Set for dry-run workflows, which don't queue anything
+}
+
+// uploadKey returns the key the workflow's files are queued under
+func (r *workflowRun) uploadKey() types.UploadKey {
+	return types.UploadKey{RepoName: r.Workflow.Destination.Repo, BranchPath: r.Workflow.Destination.Branch}
+}
+
+// queuedPaths returns the target paths queued for upload and for deletion under key
+func queuedPaths(state FileStateService, key types.UploadKey) (files map[string]bool, deletions map[string]bool) {
+	content := state.GetFilesToUpload()[key]
+	files = make(map[string]bool, len(content.Content))
+	for _, file := range content.Content {
+		files[file.GetName()] = true
+	}
+	deletions = make(map[string]bool, len(content.DeletePaths))
+	for _, p := range content.DeletePaths {
+		deletions[p] = true
+	}
+	return files, deletions
+}
+
+// runFileCounts returns how many files a change's workflows queued to copy or remove, and how many of the
+// files in its uploads were copied and failed to copy. It counts only the change's own runs and uploads,
+// so it's unaffected by changes processed at the same time. Uploads held by the PR throttle aren't in
+// queued, so their files are neither copied nor failed yet.
+func runFileCounts(runs []*workflowRun, queued map[types.UploadKey]types.UploadFileContent,
+	uploads map[types.UploadKey]UploadResult) (matched, copied, failed int) {
+
+	for _, run := range runs {
+		matched += len(run.Files) + len(run.Deletions)
+	}
+	for key, content := range queued {
+		if uploads[key].Err != nil {
+			failed += len(content.Content)
+		} else {
+			copied += len(content.Content)
+		}
+	}
+	return matched, copied, failed
+}
+
+// newPaths returns the sorted paths in after that aren't in before
+func newPaths(before, after map[string]bool) []string {
+	var paths []string
+	for p := range after {
+		if !before[p] {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// notifyWorkflowOutcomes posts a summary for each workflow that has notifications configured, once its files
+// have been uploaded. Workflows that matched no files and had no errors, and dry-run workflows, aren't posted.
+func notifyWorkflowOutcomes(ctx context.Context, change CopyEvent, runs []*workflowRun,
+	uploads map[types.UploadKey]UploadResult, config *configs.Config) {
+
+	for _, run := range runs {
+		notifications := run.Workflow.Notifications
+		if notifications == nil || run.DryRun != nil {
+			continue
+		}
+		if len(run.Files) == 0 && len(run.Deletions) == 0 && run.Err == nil {
+			continue
+		}
+
+		event := &WorkflowOutcomeEvent{
+			WorkflowName: run.Workflow.Name,
+			PRNumber:     change.Number,
+			PRURL:        change.URL,
+			SourceRepo:   change.Repo,
+			TargetRepo:   run.Workflow.Destination.Repo,
+			TargetBranch: run.Workflow.Destination.Branch,
+			Files:        run.Files,
+			Deletions:    run.Deletions,
+		}
+		if run.Err != nil {
+			event.Errors = append(event.Errors, errorMessages(run.Err)...)
+		}
+		if upload, ok := uploads[run.uploadKey()]; ok {
+			event.TargetPRURL = upload.PRURL
+			if upload.Err != nil {
+				event.Errors = append(event.Errors, "upload: "+upload.Err.Error())
+			}
+		}
+		if event.Succeeded() && !notifications.NotifyOnSuccess() {
+			continue
+		}
+
+		if err := workflowNotifier(notifications, config).NotifyWorkflowOutcome(ctx, event); err != nil {
+			LogWarningCtx(ctx, "failed to send workflow notification", map[string]interface{}{
+				"workflow_name": run.Workflow.Name,
+				"error":         err.Error(),
+			})
+		}
+	}
+}
+
+// workflowNotifier returns a notifier for the workflow's Slack webhook and channel. The service's
+// SLACK_WEBHOOK_URL, channel, username, and icon are used for anything the workflow doesn't set.
+func workflowNotifier(notifications *types.NotificationConfig, config *configs.Config) SlackNotifier {
+	if config == nil {
+		config = &configs.Config{}
+	}
+	webhookURL := notifications.WebhookURL()
+	channel := notifications.SlackChannel
+	if webhookURL == "" {
+		webhookURL = config.SlackWebhookURL
+		if channel == "" {
+			channel = config.SlackChannel
+		}
+	}
+	return NewSlackNotifier(webhookURL, channel, config.SlackUsername, config.SlackIconEmoji)
+}
+
+// errorMessages splits an error that wraps several joined errors, such as the file errors from
+// ProcessWorkflow, into one message per error
+func errorMessages(err error) []string {
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		return []string{err.Error()}
+	}
+	messages := make([]string, 0, len(joined.Unwrap()))
+	for _, e := range joined.Unwrap() {
+		messages = append(messages, e.Error())
+	}
+	return messages
+}
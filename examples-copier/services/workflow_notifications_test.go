@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slackRecorder is a Slack webhook that records the messages it receives
+func slackRecorder(t *testing.T) (*httptest.Server, func() []SlackMessage) {
+	var mu sync.Mutex
+	var messages []SlackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message SlackMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		mu.Lock()
+		messages = append(messages, message)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []SlackMessage {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]SlackMessage(nil), messages...)
+	}
+}
+
+func TestNotifyWorkflowOutcomes(t *testing.T) {
+	global, globalMessages := slackRecorder(t)
+	team, teamMessages := slackRecorder(t)
+	t.Setenv("TEAM_SLACK_WEBHOOK", team.URL)
+	off := false
+
+	destination := types.Destination{Repo: "org/dest", Branch: "main"}
+	runs := []*workflowRun{
+		{
+			// Copied files, posted to the service webhook
+			Workflow: types.Workflow{Name: "copied", Destination: destination, Notifications: &types.NotificationConfig{}},
+			Files:    []string{"code/a.py", "code/b.py"},
+		},
+		{
+			// Failed, posted to the workflow's own webhook even though successes aren't
+			Workflow: types.Workflow{Name: "failed", Destination: types.Destination{Repo: "org/other", Branch: "main"},
+				Notifications: &types.NotificationConfig{SlackWebhookURL: "${TEAM_SLACK_WEBHOOK}", OnSuccess: &off}},
+			Err: fmt.Errorf("1 of 2 files failed: %w", errors.Join(errors.New("examples/c.py: not found"))),
+		},
+		{
+			// Matched nothing
+			Workflow: types.Workflow{Name: "no-op", Destination: destination, Notifications: &types.NotificationConfig{}},
+		},
+		{
+			// Notifications not configured
+			Workflow: types.Workflow{Name: "silent", Destination: destination},
+			Files:    []string{"code/d.py"},
+		},
+		{
+			// Successes not posted
+			Workflow: types.Workflow{Name: "quiet", Destination: destination,
+				Notifications: &types.NotificationConfig{SlackWebhookURL: "${TEAM_SLACK_WEBHOOK}", OnSuccess: &off}},
+			Files: []string{"code/e.py"},
+		},
+	}
+	uploads := map[types.UploadKey]UploadResult{
+		{RepoName: "org/dest", BranchPath: "main"}: {PRURL: "https://github.com/org/dest/pull/7"},
+	}
+	change := CopyEvent{Repo: "org/src", Number: 42, URL: "https://github.com/org/src/pull/42"}
+	config := &configs.Config{SlackWebhookURL: global.URL, SlackChannel: "#code-examples"}
+
+	notifyWorkflowOutcomes(context.Background(), change, runs, uploads, config)
+
+	messages := globalMessages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "#code-examples", messages[0].Channel)
+	attachment := messages[0].Attachments[0]
+	assert.Equal(t, "good", attachment.Color)
+	assert.Contains(t, attachment.Title, "copied")
+	assert.Equal(t, "https://github.com/org/dest/pull/7", attachment.TitleLink)
+	assert.Contains(t, attachment.Text, "code/a.py")
+
+	messages = teamMessages()
+	require.Len(t, messages, 1)
+	assert.Empty(t, messages[0].Channel)
+	attachment = messages[0].Attachments[0]
+	assert.Equal(t, "danger", attachment.Color)
+	assert.Contains(t, attachment.Title, "failed")
+	assert.Equal(t, "https://github.com/org/src/pull/42", attachment.TitleLink)
+	assert.Contains(t, attachment.Fields[len(attachment.Fields)-1].Value, "examples/c.py: not found")
+}
+
+func TestNotifyWorkflowOutcomes_UploadFailure(t *testing.T) {
+	server, messages := slackRecorder(t)
+
+	runs := []*workflowRun{{
+		Workflow: types.Workflow{Name: "copied", Destination: types.Destination{Repo: "org/dest", Branch: "main"},
+			Notifications: &types.NotificationConfig{SlackWebhookURL: server.URL}},
+		Files: []string{"code/a.py"},
+	}}
+	uploads := map[types.UploadKey]UploadResult{
+		{RepoName: "org/dest", BranchPath: "main"}: {Err: errors.New("create PR: forbidden")},
+	}
+
+	notifyWorkflowOutcomes(context.Background(), CopyEvent{Repo: "org/src", Number: 1}, runs, uploads, nil)
+
+	require.Len(t, messages(), 1)
+	attachment := messages()[0].Attachments[0]
+	assert.Equal(t, "danger", attachment.Color)
+	assert.Contains(t, attachment.Fields[len(attachment.Fields)-1].Value, "upload: create PR: forbidden")
+}
+
+func TestRunFileCounts(t *testing.T) {
+	copiedKey := types.UploadKey{RepoName: "org/one", BranchPath: "main"}
+	failedKey := types.UploadKey{RepoName: "org/two", BranchPath: "main"}
+	runs := []*workflowRun{
+		{Files: []string{"a.py", "b.py"}, Deletions: []string{"old.py"}},
+		{Files: []string{"c.py"}},
+		{DryRun: &DryRunReport{}},
+	}
+	queued := map[types.UploadKey]types.UploadFileContent{
+		copiedKey: {Content: make([]github.RepositoryContent, 2)},
+		failedKey: {Content: make([]github.RepositoryContent, 1)},
+	}
+	uploads := map[types.UploadKey]UploadResult{
+		copiedKey: {PRURL: "https://github.com/org/one/pull/1"},
+		failedKey: {Err: errors.New("create PR: forbidden")},
+		// Held by the PR throttle, so not in queued
+		{RepoName: "org/three", BranchPath: "main"}: heldUploadResult(),
+	}
+
+	matched, copied, failed := runFileCounts(runs, queued, uploads)
+	assert.Equal(t, 4, matched)
+	assert.Equal(t, 2, copied)
+	assert.Equal(t, 1, failed)
+}
+
+func TestErrorMessages(t *testing.T) {
+	joined := fmt.Errorf("2 of 2 files failed: %w", errors.Join(errors.New("a.py: boom"), errors.New("b.py: bang")))
+	assert.Equal(t, []string{"a.py: boom", "b.py: bang"}, errorMessages(joined))
+	assert.Equal(t, []string{"config: bad"}, errorMessages(errors.New("config: bad")))
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"path/filepath"
+	"path"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,6 +26,23 @@ type workflowProcessor struct {
 	fileStateService FileStateService
 	metricsCollector *MetricsCollector
 	messageTemplater MessageTemplater
+	slackNotifier    SlackNotifier
+	auditLogger      AuditLogger // records files that aren't copied; may be nil
+
+	// schemaCache holds JSON Schemas loaded from source repos, keyed by repo@commit:path
+	schemaCache map[string]*JSONSchema
+
+	// sourceTreeCache holds the file listings of source repos, keyed by repo@commit
+	sourceTreeCache map[string]*repoTree
+
+	// gitAttributesCache holds .gitattributes files from source repos, keyed by repo@commit:path
+	gitAttributesCache map[string]string
+}
+
+// repoTree is a listing of the files in a repo at a commit
+type repoTree struct {
+	modes    map[string]string // Git file mode keyed by path
+	complete bool              // false if the listing failed or GitHub truncated it
 }
 
 // NewWorkflowProcessor creates a new workflow processor
@@ -33,17 +52,25 @@ func NewWorkflowProcessor(
 	fileStateService FileStateService,
 	metricsCollector *MetricsCollector,
 	messageTemplater MessageTemplater,
+	slackNotifier SlackNotifier,
+	auditLogger AuditLogger,
 ) WorkflowProcessor {
 	return &workflowProcessor{
-		patternMatcher:   patternMatcher,
-		pathTransformer:  pathTransformer,
-		fileStateService: fileStateService,
-		metricsCollector: metricsCollector,
-		messageTemplater: messageTemplater,
+		patternMatcher:     patternMatcher,
+		pathTransformer:    pathTransformer,
+		fileStateService:   fileStateService,
+		metricsCollector:   metricsCollector,
+		messageTemplater:   messageTemplater,
+		slackNotifier:      slackNotifier,
+		auditLogger:        auditLogger,
+		schemaCache:        make(map[string]*JSONSchema),
+		sourceTreeCache:    make(map[string]*repoTree),
+		gitAttributesCache: make(map[string]string),
 	}
 }
 
-// ProcessWorkflow processes a single workflow
+// ProcessWorkflow processes a single workflow. A file that fails is skipped so the rest of the workflow
+// still runs; the errors for all failed files are returned together.
 func (wp *workflowProcessor) ProcessWorkflow(
 	ctx context.Context,
 	workflow Workflow,
@@ -52,24 +79,48 @@ func (wp *workflowProcessor) ProcessWorkflow(
 	sourceCommitSHA string,
 ) error {
 	LogInfoCtx(ctx, "Processing workflow", map[string]interface{}{
-		"workflow_name":   workflow.Name,
-		"source_repo":     workflow.Source.Repo,
+		"workflow_name":    workflow.Name,
+		"source_repo":      workflow.Source.Repo,
 		"destination_repo": workflow.Destination.Repo,
-		"file_count":      len(changedFiles),
+		"file_count":       len(changedFiles),
 	})
 
 	// Track files matched and skipped
 	filesMatched := 0
 	filesSkipped := 0
+	var fileErrs []error
+
+	// Destination files whose source was deleted, for workflows that remove them
+	var orphans []string
+
+	// Refuse a run that would copy more files than the workflow allows before anything is fetched
+	if err := wp.checkFileCountLimit(ctx, workflow, changedFiles, prNumber); err != nil {
+		return err
+	}
+
+	// Remember what was already staged for the destination so a run over max_total_bytes can be undone
+	key := UploadKey{RepoName: workflow.Destination.Repo, BranchPath: workflow.Destination.Branch}
+	staged, wasStaged := wp.fileStateService.GetFilesToUpload()[key]
 
 	// Process each changed file
 	for _, file := range changedFiles {
+		if isDeletedFile(file) && workflow.DeleteOrphans.IsEnabled() {
+			if targetPath, ok := wp.mapSourcePath(ctx, workflow, file.Path); ok {
+				orphans = append(orphans, targetPath)
+				filesMatched++
+			} else {
+				filesSkipped++
+			}
+			continue
+		}
+
 		matched, err := wp.processFileForWorkflow(ctx, workflow, file, prNumber, sourceCommitSHA)
 		if err != nil {
 			LogErrorCtx(ctx, "Failed to process file for workflow", err, map[string]interface{}{
 				"workflow_name": workflow.Name,
 				"file_path":     file.Path,
 			})
+			fileErrs = append(fileErrs, fmt.Errorf("%s: %w", file.Path, err))
 			continue
 		}
 
@@ -80,12 +131,36 @@ func (wp *workflowProcessor) ProcessWorkflow(
 		}
 	}
 
+	if err := wp.checkTotalSizeLimit(ctx, workflow, key, len(staged.Content), prNumber); err != nil {
+		if wasStaged {
+			wp.fileStateService.AddFileToUpload(key, staged)
+		} else {
+			wp.fileStateService.RemoveFileToUpload(key)
+		}
+		return err
+	}
+
+	if len(orphans) > 0 {
+		if err := wp.deleteOrphans(ctx, workflow, orphans, prNumber, sourceCommitSHA); err != nil {
+			fileErrs = append(fileErrs, err)
+		}
+	}
+
+	// Remove destination files that no longer exist in the source for transformations with sync enabled
+	if filesMatched > 0 {
+		wp.syncDestination(ctx, workflow, prNumber, sourceCommitSHA)
+	}
+
 	LogInfoCtx(ctx, "Workflow processing complete", map[string]interface{}{
-		"workflow_name":  workflow.Name,
-		"files_matched":  filesMatched,
-		"files_skipped":  filesSkipped,
+		"workflow_name": workflow.Name,
+		"files_matched": filesMatched,
+		"files_skipped": filesSkipped,
+		"files_failed":  len(fileErrs),
 	})
 
+	if len(fileErrs) > 0 {
+		return fmt.Errorf("%d of %d files failed: %w", len(fileErrs), len(changedFiles), errors.Join(fileErrs...))
+	}
 	return nil
 }
 
@@ -98,8 +173,8 @@ func (wp *workflowProcessor) processFileForWorkflow(
 	sourceCommitSHA string,
 ) (bool, error) {
 	// Check if file is excluded
-	if wp.isExcluded(file.Path, workflow.Exclude) {
-		LogInfoCtx(ctx, "File excluded by workflow exclude patterns", map[string]interface{}{
+	if excludedBy := wp.sourceExcludedBy(workflow, file.Path); excludedBy != "" {
+		LogInfoCtx(ctx, "File excluded by "+excludedBy, map[string]interface{}{
 			"workflow_name": workflow.Name,
 			"file_path":     file.Path,
 		})
@@ -119,17 +194,17 @@ func (wp *workflowProcessor) processFileForWorkflow(
 
 		// File matched this transformation
 		LogInfoCtx(ctx, "File matched transformation", map[string]interface{}{
-			"workflow_name":      workflow.Name,
-			"transformation_idx": i,
+			"workflow_name":       workflow.Name,
+			"transformation_idx":  i,
 			"transformation_type": transformation.GetType(),
-			"source_path":        file.Path,
-			"target_path":        targetPath,
+			"source_path":         file.Path,
+			"target_path":         targetPath,
 		})
 
 		// Handle file based on status
-		if file.Status == "removed" {
+		if isDeletedFile(file) {
 			// Add to deprecation map
-			wp.addToDeprecationMap(workflow, targetPath)
+			wp.addToDeprecationMap(ctx, workflow, file.Path, targetPath, prNumber, sourceCommitSHA)
 		} else {
 			// Add to upload queue
 			err := wp.addToUploadQueue(ctx, workflow, file, targetPath, prNumber, sourceCommitSHA)
@@ -157,59 +232,115 @@ func (wp *workflowProcessor) applyTransformation(
 	transformation Transformation,
 	sourcePath string,
 ) (matched bool, targetPath string, err error) {
+	branchVariables := workflow.Source.BranchVariables(sourceBranch(ctx, workflow))
+	if change, ok := sourceChangeFromContext(ctx); ok && change.ReleaseTag != "" {
+		branchVariables["release_tag"] = change.ReleaseTag
+	}
 	switch transformation.GetType() {
 	case TransformationTypeMove:
-		return wp.applyMoveTransformation(transformation.Move, sourcePath)
+		return wp.applyMoveTransformation(transformation.Move, sourcePath, branchVariables)
 	case TransformationTypeCopy:
-		return wp.applyCopyTransformation(transformation.Copy, sourcePath)
+		return wp.applyCopyTransformation(transformation.Copy, sourcePath, branchVariables)
 	case TransformationTypeGlob:
-		return wp.applyGlobTransformation(transformation.Glob, sourcePath)
+		return wp.applyGlobTransformation(transformation.Glob, sourcePath, branchVariables)
 	case TransformationTypeRegex:
-		return wp.applyRegexTransformation(transformation.Regex, sourcePath)
+		return wp.applyRegexTransformation(transformation.Regex, sourcePath, branchVariables)
 	default:
 		return false, "", fmt.Errorf("unknown transformation type: %s", transformation.GetType())
 	}
 }
 
+// sourceBranch returns the branch the workflow copies from: the branch of the change being processed,
+// which for a branch pattern can be any matching branch, or else the configured branch
+func sourceBranch(ctx context.Context, workflow Workflow) string {
+	if change, ok := sourceChangeFromContext(ctx); ok && workflow.Source.MatchesBranch(change.BaseBranch) {
+		return change.BaseBranch
+	}
+	return workflow.Source.Branch
+}
+
+// expandBranchVariables replaces ${source_branch}, ${source_branch_suffix}, and ${release_tag} in a move or copy
+// destination, so changes from each branch of a pattern can go to their own directory
+func (wp *workflowProcessor) expandBranchVariables(sourcePath string, to string, branchVariables map[string]string) (string, error) {
+	if !strings.Contains(to, "${") {
+		return to, nil
+	}
+	expanded, err := wp.pathTransformer.Transform(sourcePath, to, branchVariables)
+	if err != nil {
+		return "", fmt.Errorf("invalid destination %q: %w", to, err)
+	}
+	return expanded, nil
+}
+
 // applyMoveTransformation applies a move transformation
 func (wp *workflowProcessor) applyMoveTransformation(
 	move *MoveTransform,
 	sourcePath string,
+	branchVariables map[string]string,
 ) (matched bool, targetPath string, err error) {
-	// Check if source path starts with the "from" prefix
-	from := strings.TrimSuffix(move.From, "/")
-	
-	if sourcePath == from {
+	to, err := wp.expandBranchVariables(sourcePath, move.To, branchVariables)
+	if err != nil {
+		return false, "", err
+	}
+	expanded := *move
+	expanded.To = to
+	return ApplyMoveTransform(&expanded, sourcePath)
+}
+
+// ApplyMoveTransform maps a source path through a move transformation. Files match only when they
+// are "from" itself or fall under it at a path segment boundary, so "examples" does not match
+// "examples-old/main.go". The part of the path after strip_prefix (or "from" when unset) is placed
+// under "to". Source paths containing "." or ".." segments are rejected rather than resolved, since
+// they could place files outside "to".
+func ApplyMoveTransform(move *MoveTransform, sourcePath string) (matched bool, targetPath string, err error) {
+	if _, ok := StripPathPrefix(sourcePath, move.From); !ok {
+		return false, "", nil
+	}
+
+	if path.Clean(sourcePath) != sourcePath {
+		return false, "", fmt.Errorf("source path %q is not a clean repository path", sourcePath)
+	}
+
+	stripPrefix := move.StripPrefix
+	if stripPrefix == "" {
+		stripPrefix = move.From
+	}
+	relativePath, ok := StripPathPrefix(sourcePath, stripPrefix)
+	if !ok {
+		return false, "", fmt.Errorf("source path %q matched from %q but is not under strip_prefix %q", sourcePath, move.From, stripPrefix)
+	}
+
+	if relativePath == "" {
 		// Exact match - move the file to the "to" path
 		return true, move.To, nil
 	}
-	
-	if strings.HasPrefix(sourcePath, from+"/") {
-		// Path is under the "from" directory - preserve relative path
-		relativePath := strings.TrimPrefix(sourcePath, from+"/")
-		targetPath = filepath.Join(move.To, relativePath)
-		return true, targetPath, nil
-	}
 
-	return false, "", nil
+	// Path is under the "from" directory - preserve relative path
+	return true, path.Join(move.To, relativePath), nil
 }
 
 // applyCopyTransformation applies a copy transformation
 func (wp *workflowProcessor) applyCopyTransformation(
 	copy *CopyTransform,
 	sourcePath string,
+	branchVariables map[string]string,
 ) (matched bool, targetPath string, err error) {
 	// Copy only matches exact file path
-	if sourcePath == copy.From {
-		return true, copy.To, nil
+	if sourcePath != copy.From {
+		return false, "", nil
 	}
-	return false, "", nil
+	to, err := wp.expandBranchVariables(sourcePath, copy.To, branchVariables)
+	if err != nil {
+		return false, "", err
+	}
+	return true, to, nil
 }
 
 // applyGlobTransformation applies a glob transformation
 func (wp *workflowProcessor) applyGlobTransformation(
 	glob *GlobTransform,
 	sourcePath string,
+	branchVariables map[string]string,
 ) (matched bool, targetPath string, err error) {
 	// Use doublestar for glob matching
 	matched, err = doublestar.Match(glob.Pattern, sourcePath)
@@ -222,6 +353,7 @@ func (wp *workflowProcessor) applyGlobTransformation(
 
 	// Extract variables for path transformation
 	variables := wp.extractGlobVariables(glob.Pattern, sourcePath)
+	mergeBranchVariables(variables, branchVariables)
 
 	// Apply path transformation using the correct signature
 	targetPath, err = wp.pathTransformer.Transform(sourcePath, glob.Transform, variables)
@@ -236,20 +368,26 @@ func (wp *workflowProcessor) applyGlobTransformation(
 func (wp *workflowProcessor) applyRegexTransformation(
 	regex *RegexTransform,
 	sourcePath string,
+	branchVariables map[string]string,
 ) (matched bool, targetPath string, err error) {
 	// Use existing pattern matcher for regex
 	sourcePattern := SourcePattern{
 		Type:    PatternTypeRegex,
 		Pattern: regex.Pattern,
 	}
-	
+
 	matchResult := wp.patternMatcher.Match(sourcePath, sourcePattern)
 	if !matchResult.Matched {
 		return false, "", nil
 	}
 
 	// Apply path transformation with captured variables
-	targetPath, err = wp.pathTransformer.Transform(sourcePath, regex.Transform, matchResult.Variables)
+	variables := make(map[string]string, len(matchResult.Variables))
+	for name, value := range matchResult.Variables {
+		variables[name] = value
+	}
+	mergeBranchVariables(variables, branchVariables)
+	targetPath, err = wp.pathTransformer.Transform(sourcePath, regex.Transform, variables)
 	if err != nil {
 		return false, "", fmt.Errorf("path transformation failed: %w", err)
 	}
@@ -257,25 +395,25 @@ func (wp *workflowProcessor) applyRegexTransformation(
 	return true, targetPath, nil
 }
 
-// extractGlobVariables extracts variables from a glob pattern match
+// mergeBranchVariables adds the branch variables to a pattern's variables. Variables the pattern
+// captured take precedence.
+func mergeBranchVariables(variables map[string]string, branchVariables map[string]string) {
+	for name, value := range branchVariables {
+		if _, ok := variables[name]; !ok {
+			variables[name] = value
+		}
+	}
+}
+
+// extractGlobVariables extracts the variables a glob pattern captures from a path it matches: the wildcard
+// captures ${1}, ${2}, ..., the * and ** captures ${glob_star_1}, ${glob_star_2}, ..., and ${relative_path}.
+// For pattern "mflix/server/**" matching "mflix/server/java-spring/src/main.java", relative_path is
+// "java-spring/src/main.java".
 func (wp *workflowProcessor) extractGlobVariables(pattern, path string) map[string]string {
-	variables := make(map[string]string)
-	
-	// Extract common variables
-	// For pattern "mflix/server/**" matching "mflix/server/java-spring/src/main.java"
-	// Extract relative_path = "java-spring/src/main.java"
-	
-	// Find the ** in the pattern
-	starStarIdx := strings.Index(pattern, "**")
-	if starStarIdx >= 0 {
-		prefix := pattern[:starStarIdx]
-		if strings.HasPrefix(path, prefix) {
-			relativePath := strings.TrimPrefix(path, prefix)
-			relativePath = strings.TrimPrefix(relativePath, "/")
-			variables["relative_path"] = relativePath
-		}
-	}
-	
+	variables, ok := GlobVariables(pattern, path)
+	if !ok {
+		return make(map[string]string)
+	}
 	return variables
 }
 
@@ -294,8 +432,23 @@ func (wp *workflowProcessor) isExcluded(path string, excludePatterns []string) b
 	return false
 }
 
-// addToDeprecationMap adds a file to the deprecation map
-func (wp *workflowProcessor) addToDeprecationMap(workflow Workflow, targetPath string) {
+// sourceExcludedBy returns what excludes a source file from the workflow, or "" if nothing does. The
+// workflow's exclude patterns take precedence over the source repo's ignore file, which can only exclude
+// more files.
+func (wp *workflowProcessor) sourceExcludedBy(workflow Workflow, sourcePath string) string {
+	if wp.isExcluded(sourcePath, workflow.Exclude) {
+		return "workflow exclude patterns"
+	}
+	if sourceIgnored(workflow.SourceIgnore, sourcePath) {
+		return "source ignore file"
+	}
+	return ""
+}
+
+// addToDeprecationMap adds a file to the deprecation map, and records it in the audit log so docs audits
+// can find pages that still include it. sourcePath is empty for orphans, whose source paths aren't known.
+func (wp *workflowProcessor) addToDeprecationMap(ctx context.Context, workflow Workflow, sourcePath string, targetPath string,
+	prNumber int, sourceCommitSHA string) {
 	deprecationFile := "deprecated_examples.json"
 	if workflow.DeprecationCheck != nil && workflow.DeprecationCheck.File != "" {
 		deprecationFile = workflow.DeprecationCheck.File
@@ -308,6 +461,28 @@ func (wp *workflowProcessor) addToDeprecationMap(workflow Workflow, targetPath s
 	}
 
 	wp.fileStateService.AddFileToDeprecate(deprecationFile, entry)
+
+	if wp.auditLogger != nil {
+		if err := wp.auditLogger.LogDeprecationEvent(ctx, &AuditEvent{
+			RuleName:   workflow.Name,
+			SourceRepo: workflow.Source.Repo,
+			SourcePath: sourcePath,
+			TargetRepo: workflow.Destination.Repo,
+			TargetPath: targetPath,
+			CommitSHA:  sourceCommitSHA,
+			PRNumber:   prNumber,
+			Success:    true,
+			AdditionalData: map[string]any{
+				"deprecation_file": deprecationFile,
+				"target_branch":    workflow.Destination.Branch,
+			},
+		}); err != nil {
+			LogWarningCtx(ctx, "Failed to record deprecated file in audit log", map[string]interface{}{
+				"target_path": targetPath,
+				"error":       err.Error(),
+			})
+		}
+	}
 }
 
 // addToUploadQueue adds a file to the upload queue
@@ -319,20 +494,47 @@ func (wp *workflowProcessor) addToUploadQueue(
 	prNumber int,
 	sourceCommitSHA string,
 ) error {
-	// Parse source repo owner/name
-	parts := strings.Split(workflow.Source.Repo, "/")
-	if len(parts) != 2 {
-		return fmt.Errorf("invalid source repo format: expected owner/repo, got: %s", workflow.Source.Repo)
-	}
-	sourceRepoOwner := parts[0]
-	sourceRepoName := parts[1]
-
 	// Fetch file content from source repository
-	fileContent, err := RetrieveFileContentsWithConfigAndBranch(ctx, file.Path, sourceCommitSHA, sourceRepoOwner, sourceRepoName)
+	fileContent, err := retrieveSourceFile(ctx, workflow.Source, file.Path, sourceCommitSHA)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve file content: %w", err)
 	}
 
+	// Git LFS pointers are copied as-is or skipped, per the workflow's lfs setting, rather than as plain text
+	if text, err := fileContent.GetContent(); err == nil {
+		if pointer, ok := ParseLFSPointer(text); ok {
+			queued, err := wp.handleLFSFile(ctx, workflow, file.Path, targetPath, fileContent, pointer, prNumber, sourceCommitSHA)
+			if queued && wp.metricsCollector != nil {
+				wp.metricsCollector.RecordFileUploaded(0 * time.Second)
+				wp.metricsCollector.RecordWorkflowFileCopied(workflow.Name)
+			}
+			return err
+		}
+	}
+
+	// Rewrite the content first so the checks below see what will be copied
+	if err := wp.applyContentTransforms(ctx, workflow, file.Path, fileContent, sourceCommitSHA); err != nil {
+		return err
+	}
+	if err := wp.applyProvenanceHeader(ctx, workflow, file.Path, targetPath, fileContent, sourceCommitSHA); err != nil {
+		return err
+	}
+
+	// Block files larger than the workflow allows
+	if err := wp.checkFileSizeLimit(ctx, workflow, file.Path, fileContent, prNumber); err != nil {
+		return err
+	}
+
+	// Block files containing potential secrets before they reach the destination repo
+	if err := wp.scanForSecrets(ctx, workflow, file.Path, fileContent, prNumber); err != nil {
+		return err
+	}
+
+	// Block config files that don't match the workflow's JSON Schemas
+	if err := wp.validateAgainstSchemas(ctx, workflow, file.Path, fileContent, prNumber, sourceCommitSHA); err != nil {
+		return err
+	}
+
 	// Update file name to target path
 	fileContent.Name = github.String(targetPath)
 
@@ -343,6 +545,58 @@ func (wp *workflowProcessor) addToUploadQueue(
 	}
 
 	// Get existing entries from FileStateService
+	content := wp.getUploadContent(ctx, workflow, key)
+
+	// Add file to content
+	content.Content = append(content.Content, *fileContent)
+
+	// Preserve the source file mode so executable scripts stay executable in the destination
+	if mode := wp.sourceFileMode(ctx, workflow.Source, sourceCommitSHA, file.Path); mode != FileModeRegular {
+		if content.FileModes == nil {
+			content.FileModes = make(map[string]string)
+		}
+		content.FileModes[targetPath] = mode
+	} else {
+		delete(content.FileModes, targetPath)
+	}
+
+	wp.renderUploadMessages(ctx, workflow, &content, prNumber, sourceCommitSHA)
+
+	// Add back to FileStateService
+	wp.fileStateService.AddFileToUpload(key, content)
+
+	// Record metric (with zero duration since we're just queuing)
+	if wp.metricsCollector != nil {
+		wp.metricsCollector.RecordFileUploaded(0 * time.Second)
+		wp.metricsCollector.RecordWorkflowFileCopied(workflow.Name)
+	}
+
+	return nil
+}
+
+// retrieveSourceFile fetches a file from the workflow's source repo, on GitHub, GitLab, or Bitbucket, at the given commit
+func retrieveSourceFile(ctx context.Context, source Source, filePath string, sourceCommitSHA string) (*github.RepositoryContent, error) {
+	// Files from a workflow run's artifacts aren't in the source repo
+	if file, ok := artifactFile(ctx, filePath); ok {
+		return file, nil
+	}
+
+	switch source.GetPlatform() {
+	case SourcePlatformGitLab:
+		return GetGitLabClient().GetFileContents(ctx, source.Repo, filePath, sourceCommitSHA)
+	case SourcePlatformBitbucket:
+		return GetBitbucketClient().GetFileContents(ctx, source.Repo, filePath, sourceCommitSHA)
+	}
+
+	parts := strings.Split(source.Repo, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid source repo format: expected owner/repo, got: %s", source.Repo)
+	}
+	return RetrieveFileContentsWithConfigAndBranch(ctx, filePath, sourceCommitSHA, parts[0], parts[1])
+}
+
+// getUploadContent returns the queued upload for the key, or a new one using the workflow's commit settings
+func (wp *workflowProcessor) getUploadContent(ctx context.Context, workflow Workflow, key UploadKey) UploadFileContent {
 	filesToUpload := wp.fileStateService.GetFilesToUpload()
 	content, exists := filesToUpload[key]
 	if !exists {
@@ -351,21 +605,43 @@ func (wp *workflowProcessor) addToUploadQueue(
 			CommitStrategy: CommitStrategy(getCommitStrategyType(workflow)),
 			UsePRTemplate:  getUsePRTemplate(workflow),
 			AutoMergePR:    getAutoMerge(workflow),
+			ApprovalGate:   getApprovalGate(workflow),
+			Reviewers:      getReviewers(workflow),
+			OnConflict:     workflow.CommitStrategy.GetOnConflict(),
+			SignOff:        getSignOff(workflow),
+		}
+		if change, ok := sourceChangeFromContext(ctx); ok && content.CommitStrategy == CommitStrategyBranch {
+			content.PushBranch = sourcePRBranch(change)
 		}
 	}
+	return content
+}
 
-	// Add file to content
-	content.Content = append(content.Content, *fileContent)
-
+// renderUploadMessages renders the workflow's commit message, PR title, and PR body for the queued upload
+func (wp *workflowProcessor) renderUploadMessages(ctx context.Context, workflow Workflow, content *UploadFileContent, prNumber int, sourceCommitSHA string) {
 	// Render templates with message context
 	msgCtx := NewMessageContext()
+	msgCtx.RuleName = workflow.Name
 	msgCtx.SourceRepo = workflow.Source.Repo
-	msgCtx.SourceBranch = workflow.Source.Branch
+	msgCtx.SourceBranch = sourceBranch(ctx, workflow)
 	msgCtx.TargetRepo = workflow.Destination.Repo
 	msgCtx.TargetBranch = workflow.Destination.Branch
 	msgCtx.PRNumber = prNumber
 	msgCtx.CommitSHA = sourceCommitSHA
-	msgCtx.FileCount = len(content.Content)
+	msgCtx.FileCount = len(content.Content) + len(content.DeletePaths)
+	for _, file := range content.Content {
+		msgCtx.Files = append(msgCtx.Files, file.GetName())
+	}
+	msgCtx.DeletedFiles = append(msgCtx.DeletedFiles, content.DeletePaths...)
+	for name, value := range workflow.Variables {
+		msgCtx.Variables[name] = value
+	}
+	if change, ok := sourceChangeFromContext(ctx); ok {
+		msgCtx.SourcePRURL = change.URL
+		msgCtx.SourcePRTitle = change.Title
+		msgCtx.Author = change.Author
+		msgCtx.ReleaseTag = change.ReleaseTag
+	}
 
 	// Render commit message
 	if workflow.CommitStrategy != nil && workflow.CommitStrategy.CommitMessage != "" {
@@ -385,18 +661,418 @@ func (wp *workflowProcessor) addToUploadQueue(
 	if workflow.CommitStrategy != nil && workflow.CommitStrategy.PRBody != "" {
 		content.PRBody = wp.messageTemplater.RenderPRBody(workflow.CommitStrategy.PRBody, msgCtx)
 	}
+}
 
-	// Add back to FileStateService
+// sourceFileMode returns the Git mode of a file in the source repo at the given commit.
+// If the source tree can't be listed, the file is treated as a regular file so the copy still goes through.
+func (wp *workflowProcessor) sourceFileMode(ctx context.Context, source Source, sourceCommitSHA string, filePath string) string {
+	if mode, ok := wp.sourceTree(ctx, source, sourceCommitSHA).modes[filePath]; ok {
+		return mode
+	}
+	return FileModeRegular
+}
+
+// sourceTree returns the listing of the source repo at the given commit. The listing is fetched once
+// per commit; failures are cached too, as an incomplete listing, so they aren't retried for every file.
+func (wp *workflowProcessor) sourceTree(ctx context.Context, source Source, sourceCommitSHA string) *repoTree {
+	sourceRepo := source.Repo
+	cacheKey := sourceRepo + "@" + sourceCommitSHA
+	if tree, ok := wp.sourceTreeCache[cacheKey]; ok {
+		return tree
+	}
+
+	tree := &repoTree{}
+	var modes map[string]string
+	var truncated bool
+	var err error
+	switch source.GetPlatform() {
+	case SourcePlatformGitLab:
+		modes, err = GetGitLabClient().GetRepoTree(ctx, sourceRepo, sourceCommitSHA)
+	case SourcePlatformBitbucket:
+		modes, truncated, err = GetBitbucketClient().GetRepoTree(ctx, sourceRepo, sourceCommitSHA)
+	default:
+		owner, name, _ := strings.Cut(sourceRepo, "/")
+		modes, truncated, err = GetRepoTree(ctx, GetRestClient(), owner, name, sourceCommitSHA)
+	}
+	if err != nil {
+		LogWarningCtx(ctx, "failed to list source repo files; copying files as regular files", map[string]interface{}{
+			"source_repo": sourceRepo,
+			"commit_sha":  sourceCommitSHA,
+			"error":       err.Error(),
+		})
+	} else {
+		if truncated {
+			LogWarningCtx(ctx, "source repo file listing was truncated", map[string]interface{}{
+				"source_repo": sourceRepo,
+				"commit_sha":  sourceCommitSHA,
+			})
+		}
+		tree.modes = modes
+		tree.complete = !truncated
+	}
+	wp.sourceTreeCache[cacheKey] = tree
+	return tree
+}
+
+// syncDestination queues deletions for files under the destination directory of each sync
+// transformation that no source file at the merge commit maps to. Files matching the workflow's
+// exclude patterns are never deleted. Sync is skipped, with a warning, if either repo's file
+// listing is incomplete, since deleting based on a partial listing could remove files that still exist.
+func (wp *workflowProcessor) syncDestination(ctx context.Context, workflow Workflow, prNumber int, sourceCommitSHA string) {
+	var syncTransformations []Transformation
+	for _, transformation := range workflow.Transformations {
+		if transformation.Sync {
+			syncTransformations = append(syncTransformations, transformation)
+		}
+	}
+	if len(syncTransformations) == 0 {
+		return
+	}
+
+	logFields := map[string]interface{}{
+		"workflow_name":    workflow.Name,
+		"source_repo":      workflow.Source.Repo,
+		"destination_repo": workflow.Destination.Repo,
+	}
+
+	source := wp.sourceTree(ctx, workflow.Source, sourceCommitSHA)
+	if !source.complete {
+		LogWarningCtx(ctx, "skipping destination sync: source repo file listing is incomplete", logFields)
+		return
+	}
+
+	provider, destRepo, err := repoProviderFor(workflow.Destination.Repo)
+	if err != nil {
+		LogErrorCtx(ctx, "skipping destination sync: failed to get destination repo client", err, logFields)
+		return
+	}
+	destFiles, truncated, err := provider.GetRepoTree(ctx, destRepo, workflow.Destination.Branch)
+	if err != nil {
+		LogErrorCtx(ctx, "skipping destination sync: failed to list destination repo files", err, logFields)
+		return
+	}
+	if truncated {
+		LogWarningCtx(ctx, "skipping destination sync: destination repo file listing was truncated", logFields)
+		return
+	}
+
+	// Every target path the workflow produces from the source tree at the merge commit
+	expected := make(map[string]bool)
+	for sourcePath := range source.modes {
+		if targetPath, ok := wp.mapSourcePath(ctx, workflow, sourcePath); ok {
+			expected[targetPath] = true
+		}
+	}
+
+	var deletePaths []string
+	for _, transformation := range syncTransformations {
+		_, destRoot := transformation.SyncRoots()
+		for destPath := range destFiles {
+			if _, under := StripPathPrefix(destPath, destRoot); !under {
+				continue
+			}
+			if expected[destPath] || wp.isExcluded(destPath, workflow.Exclude) {
+				continue
+			}
+			deletePaths = append(deletePaths, destPath)
+		}
+	}
+	if len(deletePaths) == 0 {
+		return
+	}
+	sort.Strings(deletePaths)
+
+	key := UploadKey{
+		RepoName:   workflow.Destination.Repo,
+		BranchPath: workflow.Destination.Branch,
+	}
+	content := wp.getUploadContent(ctx, workflow, key)
+	queued := make(map[string]bool, len(content.DeletePaths))
+	for _, p := range content.DeletePaths {
+		queued[p] = true
+	}
+	for _, p := range deletePaths {
+		if !queued[p] {
+			content.DeletePaths = append(content.DeletePaths, p)
+			queued[p] = true
+		}
+	}
+	wp.renderUploadMessages(ctx, workflow, &content, prNumber, sourceCommitSHA)
 	wp.fileStateService.AddFileToUpload(key, content)
 
-	// Record metric (with zero duration since we're just queuing)
+	logFields["delete_count"] = len(deletePaths)
+	LogInfoCtx(ctx, "Queued destination files removed from source for deletion", logFields)
+}
+
+// deleteOrphans queues the destination copies of deleted source files for removal, for workflows with
+// delete_orphans enabled. Files that aren't in the destination are skipped. The removal is opened as a
+// pull request that isn't merged automatically, so it's reviewed. If more files would be removed than
+// the workflow's max_deletions, none are: they're added to the deprecation file instead and an error is
+// returned. If the destination can't be listed, the files are also added to the deprecation file.
+func (wp *workflowProcessor) deleteOrphans(ctx context.Context, workflow Workflow, targetPaths []string, prNumber int, sourceCommitSHA string) error {
+	logFields := map[string]interface{}{
+		"workflow_name":    workflow.Name,
+		"destination_repo": workflow.Destination.Repo,
+		"orphan_count":     len(targetPaths),
+	}
+	deprecateAll := func() {
+		for _, targetPath := range targetPaths {
+			wp.addToDeprecationMap(ctx, workflow, "", targetPath, prNumber, sourceCommitSHA)
+		}
+	}
+
+	provider, destRepo, err := repoProviderFor(workflow.Destination.Repo)
+	if err != nil {
+		LogErrorCtx(ctx, "deprecating orphaned files instead of deleting: failed to get destination repo client", err, logFields)
+		deprecateAll()
+		return nil
+	}
+	destFiles, truncated, err := provider.GetRepoTree(ctx, destRepo, workflow.Destination.Branch)
+	if err != nil {
+		LogErrorCtx(ctx, "deprecating orphaned files instead of deleting: failed to list destination repo files", err, logFields)
+		deprecateAll()
+		return nil
+	}
+	if truncated {
+		LogWarningCtx(ctx, "deprecating orphaned files instead of deleting: destination repo file listing was truncated", logFields)
+		deprecateAll()
+		return nil
+	}
+
+	var deletePaths []string
+	for _, targetPath := range targetPaths {
+		if _, exists := destFiles[targetPath]; exists {
+			deletePaths = append(deletePaths, targetPath)
+		}
+	}
+	if len(deletePaths) == 0 {
+		LogInfoCtx(ctx, "orphaned files are already absent from the destination", logFields)
+		return nil
+	}
+	sort.Strings(deletePaths)
+
+	if limit := workflow.DeleteOrphans.GetMaxDeletions(); len(deletePaths) > limit {
+		logFields["delete_count"] = len(deletePaths)
+		logFields["max_deletions"] = limit
+		LogWarningCtx(ctx, "too many orphaned files to delete; adding them to the deprecation file instead", logFields)
+		deprecateAll()
+		return fmt.Errorf("delete_orphans: %d destination files would be deleted, more than max_deletions (%d); added to the deprecation file instead",
+			len(deletePaths), limit)
+	}
+
+	key := orphanUploadKey(workflow)
+	content := wp.getUploadContent(ctx, workflow, key)
+	queued := make(map[string]bool, len(content.DeletePaths))
+	for _, p := range content.DeletePaths {
+		queued[p] = true
+	}
+	for _, p := range deletePaths {
+		if !queued[p] {
+			content.DeletePaths = append(content.DeletePaths, p)
+			queued[p] = true
+		}
+	}
+	content.CommitStrategy = CommitStrategyPR
+	content.AutoMergePR = false
+	wp.renderUploadMessages(ctx, workflow, &content, prNumber, sourceCommitSHA)
+	wp.fileStateService.AddFileToUpload(key, content)
+
+	logFields["delete_count"] = len(deletePaths)
+	LogInfoCtx(ctx, "Queued orphaned destination files for deletion", logFields)
+	return nil
+}
+
+// orphanUploadKey returns the key orphaned destination files are queued for deletion under. It's separate
+// from the destination's own key so only the deletions are held for review and the workflow's other files
+// keep its commit strategy.
+func orphanUploadKey(workflow Workflow) UploadKey {
+	return UploadKey{
+		RepoName:       workflow.Destination.Repo,
+		BranchPath:     workflow.Destination.Branch,
+		RuleName:       workflow.Name,
+		CommitStrategy: string(CommitStrategyPR),
+	}
+}
+
+// isDeletedFile returns true if the changed file was deleted from the source
+func isDeletedFile(file ChangedFile) bool {
+	return file.Status == statusDeleted || file.Status == "removed"
+}
+
+// mapSourcePath returns the target path the workflow maps a source file to, applying exclude
+// patterns and the source ignore file and then the first matching transformation, the same way changed files are handled.
+func (wp *workflowProcessor) mapSourcePath(ctx context.Context, workflow Workflow, sourcePath string) (string, bool) {
+	if wp.sourceExcludedBy(workflow, sourcePath) != "" {
+		return "", false
+	}
+	for _, transformation := range workflow.Transformations {
+		matched, targetPath, err := wp.applyTransformation(ctx, workflow, transformation, sourcePath)
+		if err != nil {
+			// Changed files that fail a transformation aren't copied, so they don't map anywhere
+			return "", false
+		}
+		if matched {
+			return targetPath, true
+		}
+	}
+	return "", false
+}
+
+// scanForSecrets scans the file content for credential patterns unless scanning is disabled
+// or the path is allowed by the workflow's secret_scan config.
+// Returns a *SecretsDetectedError if potential secrets are found, after logging and alerting.
+func (wp *workflowProcessor) scanForSecrets(
+	ctx context.Context,
+	workflow Workflow,
+	sourcePath string,
+	fileContent *github.RepositoryContent,
+	prNumber int,
+) error {
+	if !workflow.SecretScan.IsEnabled() || workflow.SecretScan.IsAllowed(sourcePath) {
+		return nil
+	}
+
+	content, err := fileContent.GetContent()
+	if err != nil {
+		return fmt.Errorf("failed to decode file content for secret scan: %w", err)
+	}
+
+	findings := ScanForSecrets(content)
+	if len(findings) == 0 {
+		return nil
+	}
+
+	LogWarningCtx(ctx, "Potential secrets detected, file will not be copied", map[string]interface{}{
+		"workflow_name":    workflow.Name,
+		"source_path":      sourcePath,
+		"destination_repo": workflow.Destination.Repo,
+		"findings":         findings,
+	})
+
 	if wp.metricsCollector != nil {
-		wp.metricsCollector.RecordFileUploaded(0 * time.Second)
+		wp.metricsCollector.RecordFileBlockedBySecretScan()
+	}
+
+	if wp.slackNotifier != nil {
+		if err := wp.slackNotifier.NotifySecretsDetected(ctx, &SecretsDetectedEvent{
+			PRNumber:     prNumber,
+			SourceRepo:   workflow.Source.Repo,
+			TargetRepo:   workflow.Destination.Repo,
+			WorkflowName: workflow.Name,
+			FilePath:     sourcePath,
+			Findings:     findings,
+		}); err != nil {
+			LogErrorCtx(ctx, "Failed to send secret scan alert", err, map[string]interface{}{
+				"workflow_name": workflow.Name,
+				"source_path":   sourcePath,
+			})
+		}
+	}
+
+	return &SecretsDetectedError{Path: sourcePath, Findings: findings}
+}
+
+// validateAgainstSchemas validates the file content against the JSON Schema of every schema_validation rule
+// whose files pattern matches the source path. Schemas are read from the source repo at the same commit.
+// Returns a *SchemaValidationError if the content can't be parsed or doesn't match, after logging and alerting.
+func (wp *workflowProcessor) validateAgainstSchemas(
+	ctx context.Context,
+	workflow Workflow,
+	sourcePath string,
+	fileContent *github.RepositoryContent,
+	prNumber int,
+	sourceCommitSHA string,
+) error {
+	for _, rule := range workflow.SchemaValidation {
+		if !rule.Matches(sourcePath) {
+			continue
+		}
+
+		schema, err := wp.loadSchema(ctx, workflow.Source, sourceCommitSHA, rule.Schema)
+		if err != nil {
+			// Fail closed: without the schema we can't tell whether the file is valid
+			return fmt.Errorf("failed to load schema %s for %s: %w", rule.Schema, sourcePath, err)
+		}
+
+		content, err := fileContent.GetContent()
+		if err != nil {
+			return fmt.Errorf("failed to decode file content for schema validation: %w", err)
+		}
+
+		var violations []SchemaViolation
+		doc, err := ParseStructuredContent(sourcePath, []byte(content))
+		if err != nil {
+			violations = []SchemaViolation{{Message: err.Error()}}
+		} else {
+			violations = schema.Validate(doc)
+		}
+		if len(violations) == 0 {
+			continue
+		}
+
+		validationErr := &SchemaValidationError{Path: sourcePath, SchemaPath: rule.Schema, Violations: violations}
+
+		LogWarningCtx(ctx, "File does not match its JSON Schema, file will not be copied", map[string]interface{}{
+			"workflow_name":    workflow.Name,
+			"source_path":      sourcePath,
+			"schema":           rule.Schema,
+			"destination_repo": workflow.Destination.Repo,
+			"violations":       violations,
+		})
+
+		if wp.metricsCollector != nil {
+			wp.metricsCollector.RecordFileBlockedBySchemaValidation()
+		}
+
+		if wp.slackNotifier != nil {
+			if err := wp.slackNotifier.NotifyError(ctx, &ErrorEvent{
+				Operation:  "schema_validation",
+				Error:      validationErr,
+				PRNumber:   prNumber,
+				SourceRepo: workflow.Source.Repo,
+				AdditionalInfo: map[string]interface{}{
+					"workflow":    workflow.Name,
+					"target_repo": workflow.Destination.Repo,
+				},
+			}); err != nil {
+				LogErrorCtx(ctx, "Failed to send schema validation alert", err, map[string]interface{}{
+					"workflow_name": workflow.Name,
+					"source_path":   sourcePath,
+				})
+			}
+		}
+
+		return validationErr
 	}
 
 	return nil
 }
 
+// loadSchema fetches and parses a JSON Schema from the source repo, caching it for the rest of the run
+func (wp *workflowProcessor) loadSchema(ctx context.Context, source Source, sourceCommitSHA string, schemaPath string) (*JSONSchema, error) {
+	cacheKey := fmt.Sprintf("%s@%s:%s", source.Repo, sourceCommitSHA, schemaPath)
+	if schema, ok := wp.schemaCache[cacheKey]; ok {
+		return schema, nil
+	}
+
+	schemaFile, err := retrieveSourceFile(ctx, source, schemaPath, sourceCommitSHA)
+	if err != nil {
+		return nil, err
+	}
+	schemaContent, err := schemaFile.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode schema content: %w", err)
+	}
+
+	schema, err := ParseJSONSchema([]byte(schemaContent))
+	if err != nil {
+		return nil, err
+	}
+
+	wp.schemaCache[cacheKey] = schema
+	return schema, nil
+}
+
 // Helper functions to extract config values
 
 func getCommitStrategyType(workflow Workflow) string {
@@ -440,3 +1116,24 @@ func getAutoMerge(workflow Workflow) bool {
 	}
 	return false
 }
+
+func getApprovalGate(workflow Workflow) *ApprovalGateConfig {
+	if workflow.CommitStrategy != nil && workflow.CommitStrategy.AutoMerge {
+		return workflow.CommitStrategy.ApprovalGate
+	}
+	return nil
+}
+
+func getReviewers(workflow Workflow) *ReviewersConfig {
+	if workflow.CommitStrategy != nil {
+		return workflow.CommitStrategy.Reviewers
+	}
+	return nil
+}
+
+func getSignOff(workflow Workflow) *SignOffConfig {
+	if workflow.CommitStrategy != nil && workflow.CommitStrategy.SignOff != nil && workflow.CommitStrategy.SignOff.Enabled {
+		return workflow.CommitStrategy.SignOff
+	}
+	return nil
+}
@@ -0,0 +1,570 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/mongodb/code-example-tooling/code-copier/services"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	test "github.com/mongodb/code-example-tooling/code-copier/tests"
+)
+
+func TestApplyMoveTransform(t *testing.T) {
+	tests := []struct {
+		name       string
+		move       types.MoveTransform
+		sourcePath string
+		wantMatch  bool
+		wantTarget string
+		wantErr    bool
+	}{
+		{
+			name:       "file under from",
+			move:       types.MoveTransform{From: "examples", To: "code-examples"},
+			sourcePath: "examples/go/main.go",
+			wantMatch:  true,
+			wantTarget: "code-examples/go/main.go",
+		},
+		{
+			name:       "from with trailing slash",
+			move:       types.MoveTransform{From: "examples/", To: "code-examples/"},
+			sourcePath: "examples/go/main.go",
+			wantMatch:  true,
+			wantTarget: "code-examples/go/main.go",
+		},
+		{
+			name:       "exact file match",
+			move:       types.MoveTransform{From: "examples/README.md", To: "docs/README.md"},
+			sourcePath: "examples/README.md",
+			wantMatch:  true,
+			wantTarget: "docs/README.md",
+		},
+		{
+			name:       "sibling directory sharing the prefix is not matched",
+			move:       types.MoveTransform{From: "examples", To: "code-examples"},
+			sourcePath: "examples-old/go/main.go",
+			wantMatch:  false,
+		},
+		{
+			name:       "file outside from is not matched",
+			move:       types.MoveTransform{From: "examples", To: "code-examples"},
+			sourcePath: "src/main.go",
+			wantMatch:  false,
+		},
+		{
+			name:       "strip_prefix keeps path below the stripped directory",
+			move:       types.MoveTransform{From: "examples/go", To: "code", StripPrefix: "examples"},
+			sourcePath: "examples/go/db/connect.go",
+			wantMatch:  true,
+			wantTarget: "code/go/db/connect.go",
+		},
+		{
+			name:       "strip_prefix does not widen the match",
+			move:       types.MoveTransform{From: "examples/go", To: "code", StripPrefix: "examples"},
+			sourcePath: "examples/python/main.py",
+			wantMatch:  false,
+		},
+		{
+			name:       "parent directory segments are rejected",
+			move:       types.MoveTransform{From: "examples", To: "code-examples"},
+			sourcePath: "examples/../secrets/key.pem",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, target, err := services.ApplyMoveTransform(&tt.move, tt.sourcePath)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMatch, matched)
+			assert.Equal(t, tt.wantTarget, target)
+		})
+	}
+}
+
+func TestConfigValidator_TestMove(t *testing.T) {
+	validator := services.NewConfigValidator()
+
+	target, err := validator.TestMove("examples/go/main.go", types.MoveTransform{From: "examples", To: "code-examples"})
+	require.NoError(t, err)
+	assert.Equal(t, "code-examples/go/main.go", target)
+
+	_, err = validator.TestMove("examples-old/main.go", types.MoveTransform{From: "examples", To: "code-examples"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "whole directory names")
+
+	_, err = validator.TestMove("./examples/main.go", types.MoveTransform{From: "examples", To: "code-examples"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"examples/main.go"`)
+
+	_, err = validator.TestMove("src/main.go", types.MoveTransform{From: "examples", To: "code-examples"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not under from")
+
+	_, err = validator.TestMove("examples/main.go", types.MoveTransform{From: "../examples", To: "code-examples"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid move transformation")
+}
+
+// mockTree registers a recursive Git Trees API response listing the given files (path -> mode)
+func mockTree(owner, repo, ref string, files map[string]string, truncated bool) {
+	entries := make([]map[string]any, 0, len(files))
+	for path, mode := range files {
+		entries = append(entries, map[string]any{"path": path, "mode": mode, "type": "blob", "sha": "sha-" + path})
+	}
+	httpmock.RegisterResponder("GET",
+		"https://api.github.com/repos/"+owner+"/"+repo+"/git/trees/"+ref+"?recursive=1",
+		httpmock.NewJsonResponderOrPanic(200, map[string]any{"sha": ref, "truncated": truncated, "tree": entries}),
+	)
+}
+
+func newSyncTestProcessor(fileStateService services.FileStateService) services.WorkflowProcessor {
+	return services.NewWorkflowProcessor(
+		services.NewPatternMatcher(),
+		services.NewPathTransformer(),
+		fileStateService,
+		nil,
+		services.NewMessageTemplater(),
+		services.NewSlackNotifier("", "", "", ""),
+		nil,
+	)
+}
+
+func syncTestWorkflow() types.Workflow {
+	return types.Workflow{
+		Name:        "sample-app",
+		Source:      types.Source{Repo: "src-org/app", Branch: "main"},
+		Destination: types.Destination{Repo: "dst-org/samples", Branch: "main"},
+		Transformations: []types.Transformation{
+			{Move: &types.MoveTransform{From: "app/server", To: "server"}, Sync: true},
+		},
+		Exclude: []string{"**/*.env"},
+	}
+}
+
+func TestProcessWorkflow_SyncQueuesDeletions(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	test.SetupOrgToken("dst-org", "test-token")
+
+	mockTree("src-org", "app", "abc123", map[string]string{
+		"app/server/main.go": "100644",
+		"app/server/run.sh":  "100755",
+		"README.md":          "100644",
+	}, false)
+	mockTree("dst-org", "samples", "main", map[string]string{
+		"server/main.go":   "100644",
+		"server/run.sh":    "100755",
+		"server/old.go":    "100644", // removed from source: deleted
+		"server/old/a.go":  "100644", // removed from source: deleted
+		"server/local.env": "100644", // excluded: kept
+		"other/x.go":       "100644", // outside the synced directory: kept
+	}, false)
+	httpmock.RegisterResponder("GET",
+		"https://api.github.com/repos/src-org/app/contents/app/server/main.go?ref=abc123",
+		httpmock.NewJsonResponderOrPanic(200, map[string]any{
+			"type": "file", "encoding": "base64", "path": "app/server/main.go", "content": b64("package main"),
+		}),
+	)
+
+	fileStateService := services.NewFileStateService()
+	processor := newSyncTestProcessor(fileStateService)
+
+	err := processor.ProcessWorkflow(context.Background(), syncTestWorkflow(),
+		[]types.ChangedFile{{Path: "app/server/main.go", Status: "modified"}}, 42, "abc123")
+	require.NoError(t, err)
+
+	uploads := fileStateService.GetFilesToUpload()
+	upload, ok := uploads[types.UploadKey{RepoName: "dst-org/samples", BranchPath: "main"}]
+	require.True(t, ok, "expected an upload for the destination repo")
+	require.Len(t, upload.Content, 1)
+	assert.Equal(t, "server/main.go", upload.Content[0].GetName())
+	assert.Equal(t, []string{"server/old.go", "server/old/a.go"}, upload.DeletePaths)
+}
+
+func TestProcessWorkflow_SyncSkippedWhenListingTruncated(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	test.SetupOrgToken("dst-org", "test-token")
+
+	mockTree("src-org", "app", "abc123", map[string]string{"app/server/main.go": "100644"}, false)
+	mockTree("dst-org", "samples", "main", map[string]string{
+		"server/main.go": "100644",
+		"server/old.go":  "100644",
+	}, true)
+	httpmock.RegisterResponder("GET",
+		"https://api.github.com/repos/src-org/app/contents/app/server/main.go?ref=abc123",
+		httpmock.NewJsonResponderOrPanic(200, map[string]any{
+			"type": "file", "encoding": "base64", "path": "app/server/main.go", "content": b64("package main"),
+		}),
+	)
+
+	fileStateService := services.NewFileStateService()
+	processor := newSyncTestProcessor(fileStateService)
+
+	err := processor.ProcessWorkflow(context.Background(), syncTestWorkflow(),
+		[]types.ChangedFile{{Path: "app/server/main.go", Status: "modified"}}, 42, "abc123")
+	require.NoError(t, err)
+
+	upload := fileStateService.GetFilesToUpload()[types.UploadKey{RepoName: "dst-org/samples", BranchPath: "main"}]
+	require.Len(t, upload.Content, 1)
+	assert.Empty(t, upload.DeletePaths)
+}
+
+func deleteOrphansTestWorkflow(maxDeletions int) types.Workflow {
+	return types.Workflow{
+		Name:        "sample-app",
+		Source:      types.Source{Repo: "src-org/app", Branch: "main"},
+		Destination: types.Destination{Repo: "dst-org/samples", Branch: "main"},
+		Transformations: []types.Transformation{
+			{Move: &types.MoveTransform{From: "app/server", To: "server"}},
+		},
+		CommitStrategy: &types.CommitStrategyConfig{Type: "direct"},
+		DeleteOrphans:  &types.DeleteOrphansConfig{Enabled: true, MaxDeletions: maxDeletions},
+	}
+}
+
+func TestProcessWorkflow_DeleteOrphans(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	test.SetupOrgToken("dst-org", "test-token")
+	test.SetupOrgToken("", "test-token")
+
+	mockTree("dst-org", "samples", "main", map[string]string{
+		"server/main.go": "100644",
+		"server/old.go":  "100644",
+	}, false)
+
+	httpmock.RegisterResponder("GET",
+		"https://api.github.com/repos/src-org/app/contents/app/server/main.go?ref=abc123",
+		httpmock.NewJsonResponderOrPanic(200, map[string]any{
+			"type": "file", "encoding": "base64", "path": "app/server/main.go", "content": b64("package main"),
+		}),
+	)
+
+	fileStateService := services.NewFileStateService()
+	processor := newSyncTestProcessor(fileStateService)
+
+	err := processor.ProcessWorkflow(context.Background(), deleteOrphansTestWorkflow(0), []types.ChangedFile{
+		{Path: "app/server/main.go", Status: "modified"},
+		{Path: "app/server/old.go", Status: "DELETED"},
+		{Path: "app/server/never-copied.go", Status: "DELETED"}, // not in the destination: skipped
+		{Path: "docs/old.md", Status: "DELETED"},                // no transformation matches: skipped
+	}, 42, "abc123")
+	require.NoError(t, err)
+
+	uploads := fileStateService.GetFilesToUpload()
+	require.Len(t, uploads, 2)
+
+	upload, ok := uploads[types.UploadKey{
+		RepoName: "dst-org/samples", BranchPath: "main", RuleName: "sample-app", CommitStrategy: string(types.CommitStrategyPR),
+	}]
+	require.True(t, ok, "expected a separate upload for the deletions")
+	assert.Equal(t, []string{"server/old.go"}, upload.DeletePaths)
+	assert.Empty(t, upload.Content)
+	assert.Equal(t, types.CommitStrategyPR, upload.CommitStrategy, "deletions are opened as a pull request")
+	assert.False(t, upload.AutoMergePR)
+
+	copied, ok := uploads[types.UploadKey{RepoName: "dst-org/samples", BranchPath: "main"}]
+	require.True(t, ok, "expected an upload for the copied file")
+	require.Len(t, copied.Content, 1)
+	assert.Empty(t, copied.DeletePaths)
+	assert.Equal(t, types.CommitStrategyDirect, copied.CommitStrategy, "copied files keep the workflow's strategy")
+	assert.Empty(t, fileStateService.GetFilesToDeprecate())
+}
+
+func TestProcessWorkflow_DeleteOrphansOverLimit(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	test.SetupOrgToken("dst-org", "test-token")
+
+	mockTree("dst-org", "samples", "main", map[string]string{
+		"server/a.go": "100644",
+		"server/b.go": "100644",
+	}, false)
+
+	fileStateService := services.NewFileStateService()
+	processor := newSyncTestProcessor(fileStateService)
+
+	err := processor.ProcessWorkflow(context.Background(), deleteOrphansTestWorkflow(1), []types.ChangedFile{
+		{Path: "app/server/a.go", Status: "DELETED"},
+		{Path: "app/server/b.go", Status: "DELETED"},
+	}, 42, "abc123")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "more than max_deletions (1)")
+
+	assert.Empty(t, fileStateService.GetFilesToUpload(), "nothing is deleted over the limit")
+	assert.NotEmpty(t, fileStateService.GetFilesToDeprecate(), "the files are deprecated instead")
+}
+
+// lfsAuditLogger records the error events written to the audit log
+type lfsAuditLogger struct {
+	services.NoOpAuditLogger
+	events []*services.AuditEvent
+}
+
+func (l *lfsAuditLogger) LogErrorEvent(ctx context.Context, event *services.AuditEvent) error {
+	l.events = append(l.events, event)
+	return nil
+}
+
+func (l *lfsAuditLogger) LogDeprecationEvent(ctx context.Context, event *services.AuditEvent) error {
+	l.events = append(l.events, event)
+	return nil
+}
+
+const testLFSPointer = "version https://git-lfs.github.com/spec/v1\n" +
+	"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n" +
+	"size 12345\n"
+
+func mockLFSSource() {
+	mockTree("src-org", "app", "abc123", map[string]string{
+		".gitattributes":      "100644",
+		"app/server/logo.png": "100644",
+		"app/server/main.go":  "100644",
+	}, false)
+	for path, content := range map[string]string{
+		".gitattributes":      "*.png filter=lfs diff=lfs merge=lfs -text\n",
+		"app/server/logo.png": testLFSPointer,
+		"app/server/main.go":  "package main",
+	} {
+		httpmock.RegisterResponder("GET",
+			"https://api.github.com/repos/src-org/app/contents/"+path+"?ref=abc123",
+			httpmock.NewJsonResponderOrPanic(200, map[string]any{
+				"type": "file", "encoding": "base64", "path": path, "content": b64(content),
+			}),
+		)
+	}
+}
+
+func lfsTestWorkflow(lfs *types.LFSConfig) types.Workflow {
+	return types.Workflow{
+		Name:        "sample-app",
+		Source:      types.Source{Repo: "src-org/app", Branch: "main"},
+		Destination: types.Destination{Repo: "dst-org/samples", Branch: "main"},
+		Transformations: []types.Transformation{
+			{Move: &types.MoveTransform{From: "app/server", To: "server"}},
+		},
+		LFS: lfs,
+	}
+}
+
+func TestProcessWorkflow_LFSSkip(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	mockLFSSource()
+
+	fileStateService := services.NewFileStateService()
+	auditLogger := &lfsAuditLogger{}
+	processor := services.NewWorkflowProcessor(
+		services.NewPatternMatcher(),
+		services.NewPathTransformer(),
+		fileStateService,
+		nil,
+		services.NewMessageTemplater(),
+		services.NewSlackNotifier("", "", "", ""),
+		auditLogger,
+	)
+
+	err := processor.ProcessWorkflow(context.Background(), lfsTestWorkflow(nil), []types.ChangedFile{
+		{Path: "app/server/logo.png", Status: "modified"},
+		{Path: "app/server/main.go", Status: "modified"},
+	}, 42, "abc123")
+	require.NoError(t, err)
+
+	upload := fileStateService.GetFilesToUpload()[types.UploadKey{RepoName: "dst-org/samples", BranchPath: "main"}]
+	require.Len(t, upload.Content, 1, "only the regular file is copied")
+	assert.Equal(t, "server/main.go", upload.Content[0].GetName())
+
+	require.Len(t, auditLogger.events, 1)
+	event := auditLogger.events[0]
+	assert.Equal(t, "app/server/logo.png", event.SourcePath)
+	assert.Equal(t, "server/logo.png", event.TargetPath)
+	assert.Equal(t, int64(12345), event.FileSize)
+	assert.Contains(t, event.ErrorMessage, "Git LFS")
+	assert.Equal(t, "*.png", event.AdditionalData["tracked_by"])
+}
+
+func TestProcessWorkflow_LFSPointer(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	test.SetupOrgToken("dst-org", "test-token")
+	mockLFSSource()
+	httpmock.RegisterResponder("GET",
+		"https://api.github.com/repos/dst-org/samples/contents/.gitattributes?ref=main",
+		httpmock.NewJsonResponderOrPanic(200, map[string]any{
+			"type": "file", "encoding": "base64", "path": ".gitattributes", "content": b64("*.sh text eol=lf"),
+		}),
+	)
+	httpmock.RegisterResponder("GET",
+		"https://api.github.com/repos/dst-org/samples/contents/.lfsconfig?ref=main",
+		httpmock.NewJsonResponderOrPanic(404, map[string]any{"message": "Not Found"}),
+	)
+
+	fileStateService := services.NewFileStateService()
+	processor := newSyncTestProcessor(fileStateService)
+
+	workflow := lfsTestWorkflow(&types.LFSConfig{Mode: types.LFSModePointer, URL: "https://lfs.example.com/samples"})
+	err := processor.ProcessWorkflow(context.Background(), workflow, []types.ChangedFile{
+		{Path: "app/server/logo.png", Status: "modified"},
+	}, 42, "abc123")
+	require.NoError(t, err)
+
+	upload := fileStateService.GetFilesToUpload()[types.UploadKey{RepoName: "dst-org/samples", BranchPath: "main"}]
+	files := make(map[string]string)
+	for _, file := range upload.Content {
+		content, err := file.GetContent()
+		require.NoError(t, err)
+		files[file.GetName()] = content
+	}
+	assert.Equal(t, testLFSPointer, files["server/logo.png"], "the pointer is copied unchanged")
+	assert.Equal(t, "*.sh text eol=lf\n/server/logo.png filter=lfs diff=lfs merge=lfs -text\n", files[".gitattributes"])
+	assert.Equal(t, "[lfs]\n\turl = https://lfs.example.com/samples\n", files[".lfsconfig"])
+}
+
+func limitsTestWorkflow(limits *types.LimitsConfig) types.Workflow {
+	return types.Workflow{
+		Name:        "sample-app",
+		Source:      types.Source{Repo: "src-org/app", Branch: "main"},
+		Destination: types.Destination{Repo: "dst-org/samples", Branch: "main"},
+		Transformations: []types.Transformation{
+			{Move: &types.MoveTransform{From: "app/server", To: "server"}},
+		},
+		Limits: limits,
+	}
+}
+
+func mockLimitsSource() {
+	files := map[string]string{
+		"app/server/main.go":  "package main",
+		"app/server/util.go":  "package main\n\nfunc util() {}",
+		"app/server/blob.bin": strings.Repeat("x", 4096),
+	}
+	modes := make(map[string]string, len(files))
+	for path, content := range files {
+		modes[path] = "100644"
+		httpmock.RegisterResponder("GET",
+			"https://api.github.com/repos/src-org/app/contents/"+path+"?ref=abc123",
+			httpmock.NewJsonResponderOrPanic(200, map[string]any{
+				"type": "file", "encoding": "base64", "path": path, "content": b64(content), "size": len(content),
+			}),
+		)
+	}
+	mockTree("src-org", "app", "abc123", modes, false)
+}
+
+func TestProcessWorkflow_MaxFiles(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	mockLimitsSource()
+
+	fileStateService := services.NewFileStateService()
+	processor := newSyncTestProcessor(fileStateService)
+
+	err := processor.ProcessWorkflow(context.Background(), limitsTestWorkflow(&types.LimitsConfig{MaxFiles: 1}), []types.ChangedFile{
+		{Path: "app/server/main.go", Status: "modified"},
+		{Path: "app/server/util.go", Status: "added"},
+		{Path: "docs/index.md", Status: "modified"},    // no transformation matches: not counted
+		{Path: "app/server/old.go", Status: "DELETED"}, // deletions aren't copied: not counted
+	}, 42, "abc123")
+	require.Error(t, err)
+
+	var limitErr *services.WorkflowLimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "max_files", limitErr.Limit)
+	assert.Equal(t, int64(2), limitErr.Actual)
+	assert.Empty(t, fileStateService.GetFilesToUpload(), "nothing is staged over the limit")
+	assert.Zero(t, httpmock.GetTotalCallCount(), "no file is fetched over the limit")
+}
+
+func TestProcessWorkflow_MaxSingleFileBytes(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	mockLimitsSource()
+
+	fileStateService := services.NewFileStateService()
+	processor := newSyncTestProcessor(fileStateService)
+
+	err := processor.ProcessWorkflow(context.Background(), limitsTestWorkflow(&types.LimitsConfig{MaxSingleFileBytes: 1024}), []types.ChangedFile{
+		{Path: "app/server/main.go", Status: "modified"},
+		{Path: "app/server/blob.bin", Status: "added"},
+	}, 42, "abc123")
+	require.Error(t, err)
+
+	var limitErr *services.WorkflowLimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "max_single_file_bytes", limitErr.Limit)
+	assert.Equal(t, "app/server/blob.bin", limitErr.Path)
+	assert.Equal(t, int64(4096), limitErr.Actual)
+
+	upload := fileStateService.GetFilesToUpload()[types.UploadKey{RepoName: "dst-org/samples", BranchPath: "main"}]
+	require.Len(t, upload.Content, 1, "files under the limit are still copied")
+	assert.Equal(t, "server/main.go", upload.Content[0].GetName())
+}
+
+func TestProcessWorkflow_MaxTotalBytes(t *testing.T) {
+	_ = test.WithHTTPMock(t)
+	mockLimitsSource()
+
+	key := types.UploadKey{RepoName: "dst-org/samples", BranchPath: "main"}
+	fileStateService := services.NewFileStateService()
+	processor := newSyncTestProcessor(fileStateService)
+
+	// A run under the limit is staged
+	workflow := limitsTestWorkflow(&types.LimitsConfig{MaxTotalBytes: 100})
+	require.NoError(t, processor.ProcessWorkflow(context.Background(), workflow,
+		[]types.ChangedFile{{Path: "app/server/main.go", Status: "modified"}}, 42, "abc123"))
+	require.Len(t, fileStateService.GetFilesToUpload()[key].Content, 1)
+
+	// A run over the limit is undone, leaving what earlier runs staged
+	err := processor.ProcessWorkflow(context.Background(), workflow, []types.ChangedFile{
+		{Path: "app/server/util.go", Status: "added"},
+		{Path: "app/server/blob.bin", Status: "added"},
+	}, 43, "abc123")
+	require.Error(t, err)
+
+	var limitErr *services.WorkflowLimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "max_total_bytes", limitErr.Limit)
+	assert.Greater(t, limitErr.Actual, int64(4096))
+
+	upload := fileStateService.GetFilesToUpload()[key]
+	require.Len(t, upload.Content, 1)
+	assert.Equal(t, "server/main.go", upload.Content[0].GetName())
+
+	// With nothing staged before, a run over the limit leaves no upload at all
+	fileStateService.ClearFilesToUpload()
+	err = processor.ProcessWorkflow(context.Background(), workflow,
+		[]types.ChangedFile{{Path: "app/server/blob.bin", Status: "added"}}, 44, "abc123")
+	require.Error(t, err)
+	assert.Empty(t, fileStateService.GetFilesToUpload())
+}
+
+func TestProcessWorkflow_DeprecationAuditEvent(t *testing.T) {
+	fileStateService := services.NewFileStateService()
+	auditLogger := &lfsAuditLogger{}
+	processor := services.NewWorkflowProcessor(
+		services.NewPatternMatcher(),
+		services.NewPathTransformer(),
+		fileStateService,
+		nil,
+		services.NewMessageTemplater(),
+		services.NewSlackNotifier("", "", "", ""),
+		auditLogger,
+	)
+
+	err := processor.ProcessWorkflow(context.Background(), lfsTestWorkflow(nil), []types.ChangedFile{
+		{Path: "app/server/old.go", Status: "DELETED"},
+	}, 42, "abc123")
+	require.NoError(t, err)
+
+	require.Len(t, fileStateService.GetFilesToDeprecate(), 1)
+	require.Len(t, auditLogger.events, 1)
+	event := auditLogger.events[0]
+	assert.Equal(t, "dst-org/samples", event.TargetRepo)
+	assert.Equal(t, "server/old.go", event.TargetPath)
+	assert.Equal(t, "app/server/old.go", event.SourcePath)
+	assert.Equal(t, "deprecated_examples.json", event.AdditionalData["deprecation_file"])
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// maxArtifactBytes caps the size of each artifact a workflow_run copies, both as downloaded and unzipped
+const maxArtifactBytes = 100 << 20
+
+// workflowRunEventSource converts successful GitHub Actions runs for workflows with the workflow_run trigger.
+// Runs that haven't completed or didn't succeed don't trigger a copy, nor do runs for pull requests, since
+// their artifacts were built from changes that haven't been merged, and possibly from a fork.
+type workflowRunEventSource struct{}
+
+func (workflowRunEventSource) Name() string { return "workflow_run" }
+
+func (workflowRunEventSource) Convert(ctx context.Context, payload []byte) (*CopyEvent, string, error) {
+	var evt github.WorkflowRunEvent
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return nil, "", invalidPayload(err)
+	}
+	if missing := validateWorkflowRunEvent(&evt); len(missing) > 0 {
+		return nil, "", missingFields("workflow_run", missing)
+	}
+
+	run := evt.GetWorkflowRun()
+	repo := evt.GetRepo().GetFullName()
+	switch {
+	case evt.GetAction() != "completed":
+		return nil, "not completed", nil
+	case run.GetConclusion() != "success":
+		return nil, "did not succeed", nil
+	case strings.HasPrefix(run.GetEvent(), "pull_request"):
+		return nil, "run for a pull request", nil
+	case run.HeadRepository != nil && run.GetHeadRepository().GetFullName() != repo:
+		return nil, "run for another repository", nil
+	}
+
+	change := &CopyEvent{
+		Platform:   types.SourcePlatformGitHub,
+		Repo:       repo,
+		CommitSHA:  run.GetHeadSHA(),
+		BaseBranch: run.GetHeadBranch(),
+		URL:        run.GetHTMLURL(),
+		Title:      run.GetName(),
+		Author:     evt.GetSender().GetLogin(),
+		Trigger:    types.WorkflowTriggerWorkflowRun,
+		RunID:      run.GetID(),
+	}
+
+	LogInfoCtx(ctx, "processing workflow run", map[string]interface{}{
+		"run_id":   change.RunID,
+		"workflow": change.Title,
+		"sha":      change.CommitSHA,
+		"repo":     change.Repo,
+		"branch":   change.BaseBranch,
+	})
+	return change, "", nil
+}
+
+// artifactFilesKey is the context key for the artifact files a workflow run copies
+type artifactFilesKey struct{}
+
+// withRunArtifacts downloads the artifacts the workflows copy from the change's workflow run, and returns a
+// copy of ctx that serves their files in place of source repo files, along with the files as changed files.
+// Each file's path starts with the name of its artifact.
+func withRunArtifacts(ctx context.Context, change CopyEvent, workflows []types.Workflow) (context.Context, []types.ChangedFile, error) {
+	names := make(map[string]bool)
+	for _, workflow := range workflows {
+		for _, name := range workflow.Artifacts.Names {
+			names[name] = true
+		}
+	}
+
+	files, err := downloadRunArtifacts(ctx, change, names)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	paths := make([]string, 0, len(files))
+	for filePath := range files {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+	changedFiles := make([]types.ChangedFile, 0, len(paths))
+	for _, filePath := range paths {
+		changedFiles = append(changedFiles, types.ChangedFile{Path: filePath, Status: "ADDED"})
+	}
+	return context.WithValue(ctx, artifactFilesKey{}, files), changedFiles, nil
+}
+
+// artifactFile returns a file from the artifacts carried by ctx, if it's one of them
+func artifactFile(ctx context.Context, filePath string) (*github.RepositoryContent, bool) {
+	files, _ := ctx.Value(artifactFilesKey{}).(map[string][]byte)
+	content, ok := files[filePath]
+	if !ok {
+		return nil, false
+	}
+	return &github.RepositoryContent{
+		Type:     github.String("file"),
+		Name:     github.String(path.Base(filePath)),
+		Path:     github.String(filePath),
+		Encoding: github.String("base64"),
+		Content:  github.String(base64.StdEncoding.EncodeToString(content)),
+		Size:     github.Int(len(content)),
+	}, true
+}
+
+// workflowArtifactFiles returns the files from the artifacts the workflow copies
+func workflowArtifactFiles(workflow types.Workflow, files []types.ChangedFile) []types.ChangedFile {
+	var matching []types.ChangedFile
+	for _, file := range files {
+		artifact, _, _ := strings.Cut(file.Path, "/")
+		if workflow.Artifacts.HasArtifact(artifact) {
+			matching = append(matching, file)
+		}
+	}
+	return matching
+}
+
+// downloadRunArtifacts downloads and unzips the named artifacts of the change's workflow run, returning
+// their files keyed by path under the artifact's name. Artifacts the run didn't upload are logged and
+// skipped; expired artifacts and artifacts over maxArtifactBytes are errors.
+func downloadRunArtifacts(ctx context.Context, change CopyEvent, names map[string]bool) (map[string][]byte, error) {
+	owner, name, _ := strings.Cut(change.Repo, "/")
+	client := GetRestClient()
+	files := make(map[string][]byte)
+	found := make(map[string]bool)
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		list, resp, err := client.Actions.ListWorkflowRunArtifacts(ctx, owner, name, change.RunID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list artifacts of run %d: %w", change.RunID, err)
+		}
+		for _, artifact := range list.Artifacts {
+			artifactName := artifact.GetName()
+			if !names[artifactName] {
+				continue
+			}
+			if artifact.GetExpired() {
+				return nil, fmt.Errorf("artifact %s of run %d has expired", artifactName, change.RunID)
+			}
+			if artifact.GetSizeInBytes() > maxArtifactBytes {
+				return nil, fmt.Errorf("artifact %s of run %d is %d bytes, over the %d byte limit",
+					artifactName, change.RunID, artifact.GetSizeInBytes(), maxArtifactBytes)
+			}
+
+			downloadURL, _, err := client.Actions.DownloadArtifact(ctx, owner, name, artifact.GetID(), true)
+			if err != nil {
+				return nil, fmt.Errorf("get download URL for artifact %s: %w", artifactName, err)
+			}
+			archive, err := fetchArtifactZip(ctx, downloadURL.String())
+			if err != nil {
+				return nil, fmt.Errorf("download artifact %s: %w", artifactName, err)
+			}
+			if err := unzipArtifact(artifactName, archive, files); err != nil {
+				return nil, fmt.Errorf("unzip artifact %s: %w", artifactName, err)
+			}
+			found[artifactName] = true
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	var missing []string
+	for artifactName := range names {
+		if !found[artifactName] {
+			missing = append(missing, artifactName)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		LogWarningCtx(ctx, "workflow run didn't upload some artifacts", map[string]interface{}{
+			"run_id":  change.RunID,
+			"missing": missing,
+		})
+	}
+
+	LogInfoCtx(ctx, fmt.Sprintf("Workflow run artifacts have %d files.", len(files)), nil)
+	return files, nil
+}
+
+// fetchArtifactZip downloads an artifact's archive from the short-lived URL GitHub redirects to, which
+// doesn't need the installation token
+func fetchArtifactZip(ctx context.Context, downloadURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxArtifactBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxArtifactBytes {
+		return nil, fmt.Errorf("over the %d byte limit", maxArtifactBytes)
+	}
+	return data, nil
+}
+
+// unzipArtifact adds the files in an artifact's archive to files, keyed by artifactName + "/" + their path
+// in the archive. Entries outside the archive's root are skipped.
+func unzipArtifact(artifactName string, archive []byte, files map[string][]byte) error {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, entry := range reader.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		name := path.Clean(strings.ReplaceAll(entry.Name, `\`, "/"))
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			continue
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("open %s: %w", entry.Name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxArtifactBytes-total+1))
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("read %s: %w", entry.Name, err)
+		}
+		total += int64(len(content))
+		if total > maxArtifactBytes {
+			return fmt.Errorf("unzipped files are over the %d byte limit", maxArtifactBytes)
+		}
+		files[artifactName+"/"+name] = content
+	}
+	return nil
+}
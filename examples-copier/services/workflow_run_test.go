@@ -0,0 +1,137 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postWorkflowRunEvent(t *testing.T, evt *github.WorkflowRunEvent) (*httptest.ResponseRecorder, *ServiceContainer) {
+	t.Helper()
+	config := &configs.Config{
+		ConfigRepoOwner: "test-owner",
+		ConfigRepoName:  "test-repo",
+		ConfigFile:      "nonexistent-config.yaml",
+	}
+	container, err := NewServiceContainer(config)
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(evt)
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
+	req.Header.Set("X-GitHub-Event", "workflow_run")
+	w := httptest.NewRecorder()
+	HandleWebhookWithContainer(w, req, config, container)
+	return w, container
+}
+
+func TestHandleWebhookWithContainer_WorkflowRun(t *testing.T) {
+	repo := &github.Repository{FullName: github.String("test-owner/source")}
+	run := func(event string, conclusion string) *github.WorkflowRun {
+		return &github.WorkflowRun{
+			ID: github.Int64(987), Name: github.String("Build examples"), HeadBranch: github.String("main"),
+			HeadSHA: github.String("abc123"), Event: github.String(event), Conclusion: github.String(conclusion),
+			HeadRepository: repo,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		action string
+		run    *github.WorkflowRun
+	}{
+		{name: "ignores runs that haven't completed", action: "in_progress", run: run("push", "")},
+		{name: "ignores failed runs", action: "completed", run: run("push", "failure")},
+		{name: "ignores runs for pull requests", action: "completed", run: run("pull_request", "success")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, container := postWorkflowRunEvent(t, &github.WorkflowRunEvent{Action: github.String(tt.action), WorkflowRun: tt.run, Repo: repo})
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, int64(1), container.MetricsCollector.webhookIgnored)
+		})
+	}
+
+	t.Run("rejects missing fields", func(t *testing.T) {
+		w, _ := postWorkflowRunEvent(t, &github.WorkflowRunEvent{
+			Action: github.String("completed"), WorkflowRun: &github.WorkflowRun{ID: github.Int64(987)}, Repo: repo,
+		})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var resp WebhookErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, []string{"workflow_run.head_sha", "workflow_run.head_branch"}, resp.MissingFields)
+	})
+}
+
+func TestMatchWorkflows_WorkflowRun(t *testing.T) {
+	source := types.Source{Repo: "org/src", Branch: "main"}
+	trigger := types.WorkflowTriggers{types.WorkflowTriggerWorkflowRun}
+	workflows := []types.Workflow{
+		{Name: "merged", Source: source},
+		{Name: "any-run", Source: source, Trigger: trigger, Artifacts: &types.ArtifactsConfig{Names: []string{"bundle"}}},
+		{Name: "docs-run", Source: source, Trigger: trigger, Artifacts: &types.ArtifactsConfig{Workflows: []string{"Build docs"}, Names: []string{"site"}}},
+	}
+
+	change := CopyEvent{Platform: types.SourcePlatformGitHub, Repo: "org/src", BaseBranch: "main",
+		Trigger: types.WorkflowTriggerWorkflowRun, Title: "Build examples", RunID: 987}
+	matching := matchWorkflows(workflows, change)
+	require.Len(t, matching, 1)
+	assert.Equal(t, "any-run", matching[0].Name)
+	assert.Equal(t, "Build examples run on main", change.describe())
+	assert.Equal(t, "copier/source-run-987", sourcePRBranch(change))
+
+	change.Title = "Build docs"
+	assert.Len(t, matchWorkflows(workflows, change), 2)
+}
+
+func TestUnzipArtifact(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"python/":          "",
+		"python/app.py":    "print('hi')",
+		"README.md":        "# Examples",
+		"../outside.txt":   "nope",
+		"python/../js.txt": "js",
+	} {
+		f, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	files := make(map[string][]byte)
+	require.NoError(t, unzipArtifact("bundle", buf.Bytes(), files))
+	assert.Equal(t, map[string][]byte{
+		"bundle/python/app.py": []byte("print('hi')"),
+		"bundle/README.md":     []byte("# Examples"),
+		"bundle/js.txt":        []byte("js"),
+	}, files)
+
+	assert.Error(t, unzipArtifact("bundle", []byte("not a zip"), files))
+}
+
+func TestArtifactFiles(t *testing.T) {
+	files := []types.ChangedFile{{Path: "bundle/app.py"}, {Path: "site/index.html"}, {Path: "bundle-old/app.py"}}
+	workflow := types.Workflow{Artifacts: &types.ArtifactsConfig{Names: []string{"bundle"}}}
+	assert.Equal(t, []types.ChangedFile{{Path: "bundle/app.py"}}, workflowArtifactFiles(workflow, files))
+
+	ctx := context.WithValue(context.Background(), artifactFilesKey{}, map[string][]byte{"bundle/app.py": []byte("print('hi')")})
+	file, err := retrieveSourceFile(ctx, types.Source{Repo: "org/src"}, "bundle/app.py", "abc123")
+	require.NoError(t, err)
+	content, err := file.GetContent()
+	require.NoError(t, err)
+	assert.Equal(t, "print('hi')", content)
+	assert.Equal(t, "app.py", file.GetName())
+}
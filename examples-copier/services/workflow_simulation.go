@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+)
+
+// WorkflowSimulation is how one workflow would map the files in a source repo tree
+type WorkflowSimulation struct {
+	Workflow    string        `json:"workflow"`
+	Destination string        `json:"destination"`
+	Mappings    []PathMapping `json:"mappings"`
+	// Excluded holds files skipped by the workflow's exclude patterns or the source repo's ignore file
+	Excluded []string `json:"excluded"`
+	// Unmatched holds files no transformation matched
+	Unmatched []string `json:"unmatched"`
+	// Collisions holds target paths more than one source file maps to; only one of them would be copied
+	Collisions map[string][]string `json:"collisions,omitempty"`
+}
+
+// PathMapping is a source file and the destination path a workflow copies it to
+type PathMapping struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// SimulateWorkflows runs the files in a source repo tree through each workflow that copies from the repo
+// and branch, applying exclude patterns and transformations the same way changed files are handled. It's
+// for checking a workflow's coverage before enabling it. Workflows that copy from another repo, or from a
+// branch that doesn't match, are skipped.
+func SimulateWorkflows(config *types.YAMLConfig, repo string, branch string, paths []string) []WorkflowSimulation {
+	sorted := append([]string(nil), paths...)
+	sort.Strings(sorted)
+
+	wp := &workflowProcessor{patternMatcher: NewPatternMatcher(), pathTransformer: NewPathTransformer()}
+	ctx := withSourceChange(context.Background(), CopyEvent{Platform: types.SourcePlatformGitHub, Repo: repo, BaseBranch: branch})
+
+	simulations := []WorkflowSimulation{}
+	for _, workflow := range config.Workflows {
+		if workflow.Source.GetPlatform() != types.SourcePlatformGitHub || workflow.Source.Repo != repo ||
+			!workflow.Source.MatchesBranch(branch) {
+			continue
+		}
+
+		simulation := WorkflowSimulation{
+			Workflow:    workflow.Name,
+			Destination: workflow.Destination.Repo + "@" + strings.Join(workflow.Destination.BranchPatterns(), ","),
+			Mappings:    []PathMapping{},
+			Excluded:    []string{},
+			Unmatched:   []string{},
+		}
+		sources := make(map[string][]string)
+		for _, path := range sorted {
+			if wp.sourceExcludedBy(workflow, path) != "" {
+				simulation.Excluded = append(simulation.Excluded, path)
+				continue
+			}
+			targetPath, ok := wp.mapSourcePath(ctx, workflow, path)
+			if !ok {
+				simulation.Unmatched = append(simulation.Unmatched, path)
+				continue
+			}
+			simulation.Mappings = append(simulation.Mappings, PathMapping{Source: path, Target: targetPath})
+			sources[targetPath] = append(sources[targetPath], path)
+		}
+		for targetPath, from := range sources {
+			if len(from) > 1 {
+				if simulation.Collisions == nil {
+					simulation.Collisions = make(map[string][]string)
+				}
+				simulation.Collisions[targetPath] = from
+			}
+		}
+		simulations = append(simulations, simulation)
+	}
+	return simulations
+}
+
+// PatternCorpusResult is how the workflows' source patterns match a list of paths
+type PatternCorpusResult struct {
+	Paths int `json:"paths"`
+	// Matched is how many paths at least one workflow copies
+	Matched   int                    `json:"matched"`
+	Workflows []WorkflowCorpusResult `json:"workflows"`
+	// Unmatched holds the paths no workflow copies, because they're excluded or no transformation matches
+	Unmatched []string `json:"unmatched"`
+	// MultipleMatches holds the paths more than one workflow copies, with the workflows in priority order
+	MultipleMatches map[string][]string `json:"multiple_matches,omitempty"`
+}
+
+// WorkflowCorpusResult is how one workflow's source patterns match a list of paths
+type WorkflowCorpusResult struct {
+	Workflow  string `json:"workflow"`
+	Source    string `json:"source"`
+	Matched   int    `json:"matched"`
+	Excluded  int    `json:"excluded"`
+	Unmatched int    `json:"unmatched"`
+}
+
+// MatchPatternCorpus runs a list of paths through every workflow's exclude patterns and transformations, or
+// only those of workflows that copy from repo if it isn't empty, to check the patterns against a corpus of
+// known paths without a source repo. Workflows are reported in priority order. Paths are trimmed, and blank
+// and duplicate paths are skipped.
+func MatchPatternCorpus(config *types.YAMLConfig, repo string, paths []string) PatternCorpusResult {
+	seen := make(map[string]bool, len(paths))
+	var corpus []string
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+		corpus = append(corpus, path)
+	}
+	sort.Strings(corpus)
+
+	wp := &workflowProcessor{patternMatcher: NewPatternMatcher(), pathTransformer: NewPathTransformer()}
+	result := PatternCorpusResult{Paths: len(corpus), Workflows: []WorkflowCorpusResult{}, Unmatched: []string{}}
+	matchedBy := make(map[string][]string, len(corpus))
+	for _, workflow := range config.WorkflowsByPriority() {
+		if repo != "" && workflow.Source.Repo != repo {
+			continue
+		}
+
+		ctx := withSourceChange(context.Background(), CopyEvent{
+			Platform:   workflow.Source.GetPlatform(),
+			Repo:       workflow.Source.Repo,
+			BaseBranch: workflow.Source.Branch,
+		})
+		workflowResult := WorkflowCorpusResult{
+			Workflow: workflow.Name,
+			Source:   workflow.Source.Repo + "@" + workflow.Source.Branch,
+		}
+		for _, path := range corpus {
+			if wp.sourceExcludedBy(workflow, path) != "" {
+				workflowResult.Excluded++
+				continue
+			}
+			if _, ok := wp.mapSourcePath(ctx, workflow, path); !ok {
+				workflowResult.Unmatched++
+				continue
+			}
+			workflowResult.Matched++
+			matchedBy[path] = append(matchedBy[path], workflow.Name)
+		}
+		result.Workflows = append(result.Workflows, workflowResult)
+	}
+
+	for _, path := range corpus {
+		switch workflows := matchedBy[path]; {
+		case len(workflows) == 0:
+			result.Unmatched = append(result.Unmatched, path)
+		case len(workflows) > 1:
+			if result.MultipleMatches == nil {
+				result.MultipleMatches = make(map[string][]string)
+			}
+			result.MultipleMatches[path] = workflows
+			fallthrough
+		default:
+			result.Matched++
+		}
+	}
+	return result
+}
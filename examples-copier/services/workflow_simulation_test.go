@@ -0,0 +1,190 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mongodb/code-example-tooling/code-copier/services"
+)
+
+const simulateConfig = `
+workflows:
+  - name: "go-examples"
+    source:
+      repo: "mongodb/source"
+      branch: "main"
+    destination:
+      repo: "mongodb/go-docs"
+      branch: "main"
+    transformations:
+      - move: { from: "examples/go", to: "code" }
+      - glob: { pattern: "shared/*.go", transform: "code/shared/${filename}" }
+    exclude:
+      - "**/*_test.go"
+  - name: "release-examples"
+    source:
+      repo: "mongodb/source"
+      branch: "release/*"
+    destination:
+      repo: "mongodb/go-docs"
+    transformations:
+      - move: { from: "examples/go", to: "v${source_branch_suffix}" }
+  - name: "other-source"
+    source:
+      repo: "mongodb/other"
+    destination:
+      repo: "mongodb/go-docs"
+    transformations:
+      - move: { from: "examples", to: "code" }
+`
+
+func TestSimulateWorkflows(t *testing.T) {
+	config := loadDiffConfig(t, simulateConfig)
+	paths := []string{
+		"shared/main.go",
+		"examples/go/main.go",
+		"examples/go/main_test.go",
+		"README.md",
+		"examples/go/util/util.go",
+	}
+
+	simulations := services.SimulateWorkflows(config, "mongodb/source", "main", paths)
+
+	require.Len(t, simulations, 1)
+	simulation := simulations[0]
+	assert.Equal(t, "go-examples", simulation.Workflow)
+	assert.Equal(t, "mongodb/go-docs@main", simulation.Destination)
+	assert.Equal(t, []services.PathMapping{
+		{Source: "examples/go/main.go", Target: "code/main.go"},
+		{Source: "examples/go/util/util.go", Target: "code/util/util.go"},
+		{Source: "shared/main.go", Target: "code/shared/main.go"},
+	}, simulation.Mappings)
+	assert.Equal(t, []string{"examples/go/main_test.go"}, simulation.Excluded)
+	assert.Equal(t, []string{"README.md"}, simulation.Unmatched)
+	assert.Empty(t, simulation.Collisions)
+}
+
+func TestSimulateWorkflows_BranchPattern(t *testing.T) {
+	config := loadDiffConfig(t, simulateConfig)
+
+	simulations := services.SimulateWorkflows(config, "mongodb/source", "release/8.0", []string{"examples/go/main.go"})
+
+	require.Len(t, simulations, 1)
+	assert.Equal(t, "release-examples", simulations[0].Workflow)
+	assert.Equal(t, []services.PathMapping{{Source: "examples/go/main.go", Target: "v8.0/main.go"}}, simulations[0].Mappings)
+}
+
+func TestSimulateWorkflows_Collisions(t *testing.T) {
+	config := loadDiffConfig(t, `
+workflows:
+  - name: "flatten"
+    source:
+      repo: "mongodb/source"
+    destination:
+      repo: "mongodb/docs"
+    transformations:
+      - glob: { pattern: "examples/**/*.go", transform: "code/${filename}" }
+`)
+
+	simulations := services.SimulateWorkflows(config, "mongodb/source", "main", []string{
+		"examples/a/main.go",
+		"examples/b/main.go",
+		"examples/b/other.go",
+	})
+
+	require.Len(t, simulations, 1)
+	assert.Equal(t, map[string][]string{
+		"code/main.go": {"examples/a/main.go", "examples/b/main.go"},
+	}, simulations[0].Collisions)
+}
+
+func TestSimulateWorkflows_Regex(t *testing.T) {
+	config := loadDiffConfig(t, `
+workflows:
+  - name: "server"
+    source:
+      repo: "mongodb/source"
+    destination:
+      repo: "mongodb/docs"
+    transformations:
+      - regex: { pattern: "^mflix/server/(?P<lang>[^/]+)/(?P<file>.+)$", transform: "server/${lang}/${file}" }
+`)
+
+	simulations := services.SimulateWorkflows(config, "mongodb/source", "main", []string{
+		"mflix/server/java/App.java",
+		"mflix/client/App.js",
+	})
+
+	require.Len(t, simulations, 1)
+	assert.Equal(t, []services.PathMapping{
+		{Source: "mflix/server/java/App.java", Target: "server/java/App.java"},
+	}, simulations[0].Mappings)
+	assert.Equal(t, []string{"mflix/client/App.js"}, simulations[0].Unmatched)
+}
+
+func TestSimulateWorkflows_NoMatchingWorkflows(t *testing.T) {
+	config := loadDiffConfig(t, simulateConfig)
+
+	assert.Empty(t, services.SimulateWorkflows(config, "mongodb/unknown", "main", []string{"examples/go/main.go"}))
+}
+
+func TestMatchPatternCorpus(t *testing.T) {
+	config := loadDiffConfig(t, simulateConfig)
+	paths := []string{
+		"examples/go/main.go",
+		"  examples/go/main_test.go  ",
+		"",
+		"README.md",
+		"examples/go/main.go",
+		"examples/util.go",
+	}
+
+	result := services.MatchPatternCorpus(config, "mongodb/source", paths)
+
+	assert.Equal(t, 4, result.Paths, "blank and duplicate paths are skipped")
+	assert.Equal(t, 2, result.Matched)
+	assert.Equal(t, []string{"README.md", "examples/util.go"}, result.Unmatched)
+	assert.Equal(t, []services.WorkflowCorpusResult{
+		{Workflow: "go-examples", Source: "mongodb/source@main", Matched: 1, Excluded: 1, Unmatched: 2},
+		{Workflow: "release-examples", Source: "mongodb/source@release/*", Matched: 2, Unmatched: 2},
+	}, result.Workflows)
+	assert.Equal(t, map[string][]string{
+		"examples/go/main.go": {"go-examples", "release-examples"},
+	}, result.MultipleMatches)
+}
+
+func TestMatchPatternCorpus_AllWorkflows(t *testing.T) {
+	config := loadDiffConfig(t, simulateConfig)
+
+	result := services.MatchPatternCorpus(config, "", []string{"examples/util.go", "README.md"})
+
+	require.Len(t, result.Workflows, 3)
+	assert.Equal(t, "other-source", result.Workflows[2].Workflow)
+	assert.Equal(t, 1, result.Workflows[2].Matched)
+	assert.Equal(t, 1, result.Matched)
+	assert.Equal(t, []string{"README.md"}, result.Unmatched)
+	assert.Empty(t, result.MultipleMatches)
+}
+
+func TestMatchPatternCorpus_Regex(t *testing.T) {
+	config := loadDiffConfig(t, `
+workflows:
+  - name: "server"
+    source:
+      repo: "mongodb/source"
+    destination:
+      repo: "mongodb/docs"
+    transformations:
+      - regex: { pattern: "^mflix/server/(?P<lang>[^/]+)/(?P<file>.+)$", transform: "server/${lang}/${file}" }
+`)
+
+	result := services.MatchPatternCorpus(config, "mongodb/source", []string{"mflix/server/java/App.java", "mflix/client/App.js"})
+
+	assert.Equal(t, 1, result.Matched)
+	assert.Equal(t, []services.WorkflowCorpusResult{
+		{Workflow: "server", Source: "mongodb/source@main", Matched: 1, Unmatched: 1},
+	}, result.Workflows)
+	assert.Equal(t, []string{"mflix/client/App.js"}, result.Unmatched)
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mongodb/code-example-tooling/code-copier/configs"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WriteRecord is a durable record of one commit or pull request the copier made in a destination repo.
+// Each record is signed with an HMAC of its fields, so a record that was edited after it was written
+// fails verification.
+type WriteRecord struct {
+	ID              string    `json:"id" bson:"_id"`
+	Timestamp       time.Time `json:"timestamp" bson:"timestamp"`
+	SourcePlatform  string    `json:"source_platform" bson:"source_platform"`
+	SourceRepo      string    `json:"source_repo" bson:"source_repo"`
+	SourcePRNumber  int       `json:"source_pr_number,omitempty" bson:"source_pr_number,omitempty"`
+	SourcePRURL     string    `json:"source_pr_url,omitempty" bson:"source_pr_url,omitempty"`
+	SourceCommitSHA string    `json:"source_commit_sha" bson:"source_commit_sha"`
+	Workflows       []string  `json:"workflows" bson:"workflows"`
+	TargetRepo      string    `json:"target_repo" bson:"target_repo"`
+	TargetBranch    string    `json:"target_branch" bson:"target_branch"`
+	CommitStrategy  string    `json:"commit_strategy" bson:"commit_strategy"`
+	// CommitSHA is the commit on the target branch: the copier's commit, or the merge commit of an
+	// automatically merged pull request. It's empty for a pull request left open for review.
+	CommitSHA     string   `json:"commit_sha,omitempty" bson:"commit_sha,omitempty"`
+	TargetPRURL   string   `json:"target_pr_url,omitempty" bson:"target_pr_url,omitempty"`
+	Files         []string `json:"files" bson:"files"`
+	Deletions     []string `json:"deletions,omitempty" bson:"deletions,omitempty"`
+	CorrelationID string   `json:"correlation_id,omitempty" bson:"correlation_id,omitempty"`
+	Signature     string   `json:"signature" bson:"signature"`
+}
+
+// WriteQuery selects write records. Zero values match every record.
+type WriteQuery struct {
+	Repo  string    // Records whose source or target repo is Repo
+	Since time.Time // Records written at or after Since
+	Until time.Time // Records written before Until
+	Limit int
+}
+
+// WriteLogStore holds write records
+type WriteLogStore interface {
+	Save(ctx context.Context, record *WriteRecord) error
+	List(ctx context.Context, query WriteQuery) ([]*WriteRecord, error) // Returns the most recent records first
+	Close(ctx context.Context) error
+}
+
+// WriteLog records every commit and pull request the copier makes, for compliance. A nil WriteLog
+// records nothing.
+type WriteLog struct {
+	store WriteLogStore
+	key   []byte
+	now   func() time.Time
+}
+
+// NewWriteLog creates a write log that signs records with key and saves them in store
+func NewWriteLog(store WriteLogStore, key []byte) *WriteLog {
+	return &WriteLog{store: store, key: key, now: time.Now}
+}
+
+// newWriteLog returns the write log backed by MongoDB, or nil if the write log is disabled
+func newWriteLog(ctx context.Context, config *configs.Config, mongoClient *SharedMongoClient) (*WriteLog, error) {
+	if !config.WriteLogEnabled {
+		return nil, nil
+	}
+	if config.WriteLogSigningKey == "" {
+		return nil, fmt.Errorf("%s is required when the write log is enabled", configs.WriteLogSigningKey)
+	}
+	store, err := NewMongoWriteLogStore(ctx, mongoClient, config.AuditDatabase, config.WriteLogCollection)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriteLog(store, []byte(config.WriteLogSigningKey)), nil
+}
+
+// RecordUploads records each successful upload of a merged change. runs are the workflows that queued
+// the uploads; each upload lists the workflows that copy to its target repo and branch.
+func (l *WriteLog) RecordUploads(ctx context.Context, change CopyEvent, runs []*workflowRun,
+	queued map[types.UploadKey]types.UploadFileContent, uploads map[types.UploadKey]UploadResult) {
+
+	if l == nil {
+		return
+	}
+	keys := make([]types.UploadKey, 0, len(uploads))
+	for key := range uploads {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].RepoName != keys[j].RepoName {
+			return keys[i].RepoName < keys[j].RepoName
+		}
+		return keys[i].BranchPath < keys[j].BranchPath
+	})
+
+	for _, key := range keys {
+		upload := uploads[key]
+		if upload.Err != nil {
+			continue
+		}
+		var workflows []string
+		for _, run := range runs {
+			if run.uploadKey() == key || orphanUploadKey(run.Workflow) == key {
+				workflows = append(workflows, run.Workflow.Name)
+			}
+		}
+		l.Record(ctx, change, workflows, key, queued[key], upload)
+	}
+}
+
+// Record signs and saves the record of one upload, logging rather than returning errors so a write log
+// outage doesn't affect copying
+func (l *WriteLog) Record(ctx context.Context, change CopyEvent, workflows []string, key types.UploadKey,
+	content types.UploadFileContent, upload UploadResult) {
+
+	if l == nil {
+		return
+	}
+	record := newWriteRecord(change, workflows, key, content, upload, l.now())
+	record.CorrelationID = CorrelationIDFromContext(ctx)
+	record.Signature = SignWriteRecord(l.key, record)
+	if err := l.store.Save(ctx, record); err != nil {
+		LogErrorCtx(ctx, "failed to record copier write", err, map[string]interface{}{
+			"target_repo":   record.TargetRepo,
+			"target_branch": record.TargetBranch,
+			"commit_sha":    record.CommitSHA,
+		})
+	}
+}
+
+// List returns the records matching query, most recent first
+func (l *WriteLog) List(ctx context.Context, query WriteQuery) ([]*WriteRecord, error) {
+	if l == nil {
+		return []*WriteRecord{}, nil
+	}
+	return l.store.List(ctx, query)
+}
+
+// Close closes the store
+func (l *WriteLog) Close(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	return l.store.Close(ctx)
+}
+
+// newWriteRecord builds the unsigned record of one upload
+func newWriteRecord(change CopyEvent, workflows []string, key types.UploadKey, content types.UploadFileContent,
+	upload UploadResult, now time.Time) *WriteRecord {
+
+	strategy := string(content.CommitStrategy)
+	if strategy == "" {
+		strategy = string(types.CommitStrategyDirect)
+	}
+	record := &WriteRecord{
+		ID:              fmt.Sprintf("%s@%s#%d-%d", key.RepoName, key.BranchPath, change.Number, now.UnixNano()),
+		Timestamp:       now.UTC().Truncate(time.Millisecond),
+		SourcePlatform:  change.Platform,
+		SourceRepo:      change.Repo,
+		SourcePRNumber:  change.Number,
+		SourcePRURL:     change.URL,
+		SourceCommitSHA: change.CommitSHA,
+		Workflows:       append([]string{}, workflows...),
+		TargetRepo:      key.RepoName,
+		TargetBranch:    key.BranchPath,
+		CommitStrategy:  strategy,
+		CommitSHA:       upload.CommitSHA,
+		TargetPRURL:     upload.PRURL,
+		Files:           []string{},
+		Deletions:       append([]string(nil), content.DeletePaths...),
+	}
+	for _, file := range content.Content {
+		record.Files = append(record.Files, file.GetName())
+	}
+	sort.Strings(record.Files)
+	sort.Strings(record.Deletions)
+	return record
+}
+
+// SignWriteRecord returns the hex HMAC-SHA256 of the record's fields, other than its signature, with key.
+// Timestamps are signed at millisecond precision, which is what MongoDB stores, and empty lists the same
+// whether or not they were decoded as nil.
+func SignWriteRecord(key []byte, record *WriteRecord) string {
+	unsigned := *record
+	unsigned.Signature = ""
+	unsigned.Timestamp = record.Timestamp.UTC().Truncate(time.Millisecond)
+	if unsigned.Workflows == nil {
+		unsigned.Workflows = []string{}
+	}
+	if unsigned.Files == nil {
+		unsigned.Files = []string{}
+	}
+	data, _ := json.Marshal(unsigned)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWriteRecord returns true if the record's signature matches its fields
+func VerifyWriteRecord(key []byte, record *WriteRecord) bool {
+	signature, err := hex.DecodeString(record.Signature)
+	if err != nil {
+		return false
+	}
+	expected, _ := hex.DecodeString(SignWriteRecord(key, record))
+	return hmac.Equal(signature, expected)
+}
+
+// ShortSHA abbreviates a commit SHA to its first seven characters, the way GitHub displays it
+func ShortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// MongoWriteLogStore implements WriteLogStore using a MongoDB collection. Records are only ever inserted.
+type MongoWriteLogStore struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewMongoWriteLogStore returns a store backed by the given collection, connecting the shared client if
+// it isn't yet
+func NewMongoWriteLogStore(ctx context.Context, mongoClient *SharedMongoClient, database, collection string) (*MongoWriteLogStore, error) {
+	client, err := mongoClient.Connect(ctx, "the write log is enabled")
+	if err != nil {
+		return nil, err
+	}
+
+	coll := client.Database(database).Collection(collection)
+	if _, err := coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "source_repo", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "target_repo", Value: 1}, {Key: "timestamp", Value: -1}}},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return &MongoWriteLogStore{client: client, collection: coll}, nil
+}
+
+// Save inserts a record
+func (s *MongoWriteLogStore) Save(ctx context.Context, record *WriteRecord) error {
+	_, err := s.collection.InsertOne(ctx, record)
+	return err
+}
+
+// List returns the records matching query, most recent first
+func (s *MongoWriteLogStore) List(ctx context.Context, query WriteQuery) ([]*WriteRecord, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+	cursor, err := s.collection.Find(ctx, writeQueryFilter(query), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	records := []*WriteRecord{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Ping checks the MongoDB connection
+func (s *MongoWriteLogStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx, nil)
+}
+
+// Close does nothing: the shared client is disconnected by its owner
+func (s *MongoWriteLogStore) Close(ctx context.Context) error { return nil }
+
+// writeQueryFilter returns the MongoDB filter for a query
+func writeQueryFilter(query WriteQuery) bson.M {
+	filter := bson.M{}
+	if query.Repo != "" {
+		filter["$or"] = bson.A{bson.M{"source_repo": query.Repo}, bson.M{"target_repo": query.Repo}}
+	}
+	timestamp := bson.M{}
+	if !query.Since.IsZero() {
+		timestamp["$gte"] = query.Since
+	}
+	if !query.Until.IsZero() {
+		timestamp["$lt"] = query.Until
+	}
+	if len(timestamp) > 0 {
+		filter["timestamp"] = timestamp
+	}
+	return filter
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+	"github.com/mongodb/code-example-tooling/code-copier/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// recordingWriteLogStore keeps saved write records in memory
+type recordingWriteLogStore struct {
+	records []*WriteRecord
+}
+
+func (s *recordingWriteLogStore) Save(ctx context.Context, record *WriteRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *recordingWriteLogStore) List(ctx context.Context, query WriteQuery) ([]*WriteRecord, error) {
+	return s.records, nil
+}
+
+func (s *recordingWriteLogStore) Close(ctx context.Context) error { return nil }
+
+func newTestWriteLog() (*WriteLog, *recordingWriteLogStore) {
+	store := &recordingWriteLogStore{}
+	log := NewWriteLog(store, []byte("test-key"))
+	log.now = func() time.Time { return time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return log, store
+}
+
+func TestWriteLog_RecordUploads(t *testing.T) {
+	log, store := newTestWriteLog()
+	docs := types.UploadKey{RepoName: "org/docs", BranchPath: "main"}
+	failed := types.UploadKey{RepoName: "org/failed", BranchPath: "main"}
+	queued := map[types.UploadKey]types.UploadFileContent{
+		docs: {
+			CommitStrategy: types.CommitStrategyPR,
+			Content:        []github.RepositoryContent{{Name: github.String("code/b.py")}, {Name: github.String("code/a.py")}},
+			DeletePaths:    []string{"code/old.py"},
+		},
+		failed: {Content: []github.RepositoryContent{{Name: github.String("code/a.py")}}},
+	}
+	uploads := map[types.UploadKey]UploadResult{
+		docs:   {PRURL: "https://github.com/org/docs/pull/7", CommitSHA: "merge123"},
+		failed: {Err: errors.New("boom")},
+	}
+	runs := []*workflowRun{
+		{Workflow: types.Workflow{Name: "python", Destination: types.Destination{Repo: "org/docs", Branch: "main"}}},
+		{Workflow: types.Workflow{Name: "other", Destination: types.Destination{Repo: "org/failed", Branch: "main"}}},
+	}
+	change := CopyEvent{Platform: types.SourcePlatformGitHub, Repo: "org/src", Number: 42, CommitSHA: "abc123", URL: "https://github.com/org/src/pull/42"}
+
+	log.RecordUploads(WithCorrelationID(context.Background(), "delivery-1"), change, runs, queued, uploads)
+
+	require.Len(t, store.records, 1)
+	record := store.records[0]
+	assert.Equal(t, "org/src", record.SourceRepo)
+	assert.Equal(t, 42, record.SourcePRNumber)
+	assert.Equal(t, "abc123", record.SourceCommitSHA)
+	assert.Equal(t, []string{"python"}, record.Workflows)
+	assert.Equal(t, "org/docs", record.TargetRepo)
+	assert.Equal(t, "pull_request", record.CommitStrategy)
+	assert.Equal(t, "merge123", record.CommitSHA)
+	assert.Equal(t, "https://github.com/org/docs/pull/7", record.TargetPRURL)
+	assert.Equal(t, []string{"code/a.py", "code/b.py"}, record.Files)
+	assert.Equal(t, []string{"code/old.py"}, record.Deletions)
+	assert.Equal(t, "delivery-1", record.CorrelationID)
+	assert.True(t, VerifyWriteRecord([]byte("test-key"), record))
+}
+
+func TestVerifyWriteRecord(t *testing.T) {
+	log, store := newTestWriteLog()
+	key := types.UploadKey{RepoName: "org/docs", BranchPath: "main"}
+	log.Record(context.Background(), CopyEvent{Repo: "org/src", CommitSHA: "abc123"}, nil, key,
+		types.UploadFileContent{Content: []github.RepositoryContent{{Name: github.String("code/a.py")}}}, UploadResult{CommitSHA: "def456"})
+	require.Len(t, store.records, 1)
+	record := store.records[0]
+
+	assert.False(t, VerifyWriteRecord([]byte("other-key"), record))
+
+	tampered := *record
+	tampered.Files = []string{"code/a.py", "code/secret.py"}
+	assert.False(t, VerifyWriteRecord([]byte("test-key"), &tampered))
+
+	// A record read back from MongoDB verifies, although its time zone and empty lists may decode differently
+	data, err := bson.Marshal(record)
+	require.NoError(t, err)
+	var decoded WriteRecord
+	require.NoError(t, bson.Unmarshal(data, &decoded))
+	decoded.Timestamp = decoded.Timestamp.Local()
+	decoded.Workflows = nil
+	assert.True(t, VerifyWriteRecord([]byte("test-key"), &decoded))
+}
+
+func TestWriteLog_Nil(t *testing.T) {
+	var log *WriteLog
+	log.RecordUploads(context.Background(), CopyEvent{}, nil, nil, map[types.UploadKey]UploadResult{{RepoName: "org/docs"}: {}})
+	records, err := log.List(context.Background(), WriteQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+	assert.NoError(t, log.Close(context.Background()))
+}
+
+func TestWriteQueryFilter(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, bson.M{}, writeQueryFilter(WriteQuery{}))
+	assert.Equal(t, bson.M{
+		"$or":       bson.A{bson.M{"source_repo": "org/docs"}, bson.M{"target_repo": "org/docs"}},
+		"timestamp": bson.M{"$gte": since, "$lt": until},
+	}, writeQueryFilter(WriteQuery{Repo: "org/docs", Since: since, Until: until}))
+}
+
+func TestRetryQueue_RecordsSuccessfulRetryInWriteLog(t *testing.T) {
+	ctx := context.Background()
+	q, clock, _, _ := newTestRetryQueue(3)
+	log, store := newTestWriteLog()
+	q.writeLog = log
+
+	failedUpload(ctx, q, githubError(http.StatusBadGateway))
+	*clock = clock.Add(time.Minute)
+	assert.Equal(t, 1, q.ProcessDue(ctx))
+
+	require.Len(t, store.records, 1)
+	assert.Equal(t, "org/src", store.records[0].SourceRepo)
+	assert.Equal(t, 42, store.records[0].SourcePRNumber)
+	assert.Equal(t, "org/dest", store.records[0].TargetRepo)
+	assert.Equal(t, []string{"code/a.py"}, store.records[0].Files)
+}
@@ -2,8 +2,14 @@ package types
 
 import (
 	"fmt"
+	"os"
+	"path"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
 )
 
 // PatternType defines the type of pattern matching to use
@@ -61,6 +67,39 @@ func (sp *SourcePattern) Validate() error {
 type YAMLConfig struct {
 	Workflows []Workflow `yaml:"workflows" json:"workflows"`
 	Defaults  *Defaults  `yaml:"defaults,omitempty" json:"defaults,omitempty"`
+	// ConflictPolicy decides what happens to a file that more than one workflow matches; defaults to all
+	ConflictPolicy string `yaml:"conflict_policy,omitempty" json:"conflict_policy,omitempty"`
+}
+
+// Conflict policies for a changed file that more than one workflow matches
+const (
+	ConflictPolicyAll            = "all"              // every matching workflow copies the file, in priority order
+	ConflictPolicyFirstMatchWins = "first-match-wins" // only the highest-priority matching workflow copies the file
+	ConflictPolicyError          = "error"            // no workflow copies the file, and each matching workflow fails
+)
+
+// GetConflictPolicy returns the conflict policy, defaulting to all
+func (c *YAMLConfig) GetConflictPolicy() string {
+	if c.ConflictPolicy == "" {
+		return ConflictPolicyAll
+	}
+	return c.ConflictPolicy
+}
+
+// WorkflowsByPriority returns the workflows in the order they're processed: highest priority first, and
+// in config order for the same priority
+func (c *YAMLConfig) WorkflowsByPriority() []Workflow {
+	workflows := append([]Workflow(nil), c.Workflows...)
+	SortWorkflowsByPriority(workflows)
+	return workflows
+}
+
+// SortWorkflowsByPriority sorts workflows highest priority first, keeping the order of workflows with the
+// same priority
+func SortWorkflowsByPriority(workflows []Workflow) {
+	sort.SliceStable(workflows, func(i, j int) bool {
+		return workflows[i].Priority > workflows[j].Priority
+	})
 }
 
 // ============================================================================
@@ -71,6 +110,7 @@ type YAMLConfig struct {
 type MainConfig struct {
 	Defaults        *Defaults         `yaml:"defaults,omitempty" json:"defaults,omitempty"`
 	WorkflowConfigs []WorkflowConfigRef `yaml:"workflow_configs" json:"workflow_configs"`
+	ConflictPolicy  string            `yaml:"conflict_policy,omitempty" json:"conflict_policy,omitempty"`
 }
 
 // WorkflowConfigRef references a workflow configuration file
@@ -247,18 +287,193 @@ func (e *ExcludeOrRef) IsRef() bool {
 
 // CommitStrategyConfig defines commit strategy settings
 type CommitStrategyConfig struct {
-	Type          string `yaml:"type" json:"type"` // "direct" or "pull_request"
+	Type          string `yaml:"type" json:"type"` // "direct", "pull_request", or "branch"
 	CommitMessage string `yaml:"commit_message,omitempty" json:"commit_message,omitempty"`
 	PRTitle       string `yaml:"pr_title,omitempty" json:"pr_title,omitempty"`
 	PRBody        string `yaml:"pr_body,omitempty" json:"pr_body,omitempty"`
 	UsePRTemplate bool   `yaml:"use_pr_template,omitempty" json:"use_pr_template,omitempty"` // If true, fetch and use PR template from target repo
 	AutoMerge     bool   `yaml:"auto_merge,omitempty" json:"auto_merge,omitempty"`
+	// ApprovalGate holds auto-merged PRs until they're approved, rather than merging them as soon as they're opened
+	ApprovalGate *ApprovalGateConfig `yaml:"approval_gate,omitempty" json:"approval_gate,omitempty"`
+	// Reviewers requests reviews on the PRs the copier opens
+	Reviewers *ReviewersConfig `yaml:"reviewers,omitempty" json:"reviewers,omitempty"`
+	// SignOff adds a DCO sign-off to commits, for target repos that require one
+	SignOff *SignOffConfig `yaml:"sign_off,omitempty" json:"sign_off,omitempty"`
+	// OnConflict is what happens when an auto-merged PR conflicts with the target branch: one of the
+	// OnConflict values. Defaults to OnConflictManual.
+	OnConflict string `yaml:"on_conflict,omitempty" json:"on_conflict,omitempty"`
+}
+
+// On-conflict strategies: what the copier does when a PR it's merging conflicts with the target branch,
+// because the destination files changed after the PR's branch was created
+const (
+	OnConflictManual    = "manual"    // leave the PR open for someone to resolve
+	OnConflictOverwrite = "overwrite" // rebuild the PR's branch from the target branch, so the copied files win, and merge again
+	OnConflictSkip      = "skip"      // close the PR and report the copy as skipped
+)
+
+// GetOnConflict returns the commit strategy's on-conflict strategy
+func (c *CommitStrategyConfig) GetOnConflict() string {
+	if c == nil || c.OnConflict == "" {
+		return OnConflictManual
+	}
+	return c.OnConflict
 }
 
 // Validate validates the commit strategy configuration
 func (c *CommitStrategyConfig) Validate() error {
-	if c.Type != "" && c.Type != "direct" && c.Type != "pull_request" {
-		return fmt.Errorf("invalid type: %s (must be direct or pull_request)", c.Type)
+	if c.Type != "" && c.Type != "direct" && c.Type != "pull_request" && c.Type != "branch" {
+		return fmt.Errorf("invalid type: %s (must be direct, pull_request, or branch)", c.Type)
+	}
+	switch c.OnConflict {
+	case "", OnConflictManual, OnConflictOverwrite, OnConflictSkip:
+	default:
+		return fmt.Errorf("invalid on_conflict: %s (must be manual, overwrite, or skip)", c.OnConflict)
+	}
+	if c.SignOff != nil {
+		if err := c.SignOff.Validate(); err != nil {
+			return fmt.Errorf("sign_off: %w", err)
+		}
+	}
+	if c.ApprovalGate != nil {
+		if err := c.ApprovalGate.Validate(); err != nil {
+			return fmt.Errorf("approval_gate: %w", err)
+		}
+	}
+	if c.Reviewers != nil {
+		if err := c.Reviewers.Validate(); err != nil {
+			return fmt.Errorf("reviewers: %w", err)
+		}
+	}
+	for _, tmpl := range []struct{ field, text string }{
+		{"commit_message", c.CommitMessage},
+		{"pr_title", c.PRTitle},
+		{"pr_body", c.PRBody},
+	} {
+		if err := validateMessageTemplate(tmpl.field, tmpl.text); err != nil {
+			return fmt.Errorf("%s: invalid template: %w", tmpl.field, err)
+		}
+	}
+	return nil
+}
+
+// ReviewersConfig requests reviews on the PRs the copier opens in GitHub destinations. Reviews aren't
+// requested on PRs the copier merges as soon as they're opened.
+type ReviewersConfig struct {
+	// CodeOwners requests reviews from the owners of the touched paths in the destination repo's
+	// CODEOWNERS file on the target branch
+	CodeOwners bool `yaml:"codeowners,omitempty" json:"codeowners,omitempty"`
+	// Users are GitHub usernames to always request reviews from
+	Users []string `yaml:"users,omitempty" json:"users,omitempty"`
+	// Teams are slugs of teams in the destination repo's org to always request reviews from
+	Teams []string `yaml:"teams,omitempty" json:"teams,omitempty"`
+}
+
+// Validate validates the reviewers configuration
+func (c *ReviewersConfig) Validate() error {
+	if !c.CodeOwners && len(c.Users) == 0 && len(c.Teams) == 0 {
+		return fmt.Errorf("at least one of codeowners, users, or teams is required")
+	}
+	for i, user := range c.Users {
+		if strings.TrimSpace(strings.TrimPrefix(user, "@")) == "" {
+			return fmt.Errorf("users[%d] must not be empty", i)
+		}
+	}
+	for i, team := range c.Teams {
+		if strings.TrimSpace(team) == "" {
+			return fmt.Errorf("teams[%d] must not be empty", i)
+		}
+		if strings.Contains(team, "/") {
+			return fmt.Errorf("teams[%d] must be a team slug without the org: %s", i, team)
+		}
+	}
+	return nil
+}
+
+// DefaultApprovalTimeoutMinutes is how long an approval gate waits for a PR to be approved
+const DefaultApprovalTimeoutMinutes = 24 * 60
+
+// What an approval gate does with a PR that isn't approved before its timeout
+const (
+	ApprovalOnTimeoutLeaveOpen = "leave_open" // Leave the PR open for review
+	ApprovalOnTimeoutMerge     = "merge"      // Merge the PR anyway, unless a required check failed
+	ApprovalOnTimeoutClose     = "close"      // Close the PR and delete its branch
+)
+
+// ApprovalGateConfig holds a PR the copier would merge automatically until its required check runs pass and,
+// if reviewers are named, one of them approves it. The gate only applies with auto_merge, to GitHub
+// destinations; the PR is checked every APPROVAL_GATE_POLL_INTERVAL seconds.
+type ApprovalGateConfig struct {
+	// Checks are the names of the check runs on the PR that must pass
+	Checks []string `yaml:"checks,omitempty" json:"checks,omitempty"`
+	// Reviewers are GitHub usernames; an approving review from any of them is required
+	Reviewers []string `yaml:"reviewers,omitempty" json:"reviewers,omitempty"`
+	// TimeoutMinutes is how long to wait. Defaults to DefaultApprovalTimeoutMinutes.
+	TimeoutMinutes int `yaml:"timeout_minutes,omitempty" json:"timeout_minutes,omitempty"`
+	// OnTimeout is what to do with a PR that isn't approved in time. Defaults to ApprovalOnTimeoutLeaveOpen.
+	OnTimeout string `yaml:"on_timeout,omitempty" json:"on_timeout,omitempty"`
+}
+
+// GetTimeout returns how long to wait for the PR to be approved
+func (c *ApprovalGateConfig) GetTimeout() time.Duration {
+	if c == nil || c.TimeoutMinutes == 0 {
+		return DefaultApprovalTimeoutMinutes * time.Minute
+	}
+	return time.Duration(c.TimeoutMinutes) * time.Minute
+}
+
+// GetOnTimeout returns what to do with a PR that isn't approved in time
+func (c *ApprovalGateConfig) GetOnTimeout() string {
+	if c == nil || c.OnTimeout == "" {
+		return ApprovalOnTimeoutLeaveOpen
+	}
+	return c.OnTimeout
+}
+
+// Validate validates the approval gate configuration
+func (c *ApprovalGateConfig) Validate() error {
+	if len(c.Checks) == 0 && len(c.Reviewers) == 0 {
+		return fmt.Errorf("at least one of checks or reviewers is required")
+	}
+	for i, check := range c.Checks {
+		if strings.TrimSpace(check) == "" {
+			return fmt.Errorf("checks[%d] must not be empty", i)
+		}
+	}
+	for i, reviewer := range c.Reviewers {
+		if strings.TrimSpace(reviewer) == "" {
+			return fmt.Errorf("reviewers[%d] must not be empty", i)
+		}
+	}
+	if c.TimeoutMinutes < 0 {
+		return fmt.Errorf("timeout_minutes must not be negative")
+	}
+	switch c.GetOnTimeout() {
+	case ApprovalOnTimeoutLeaveOpen, ApprovalOnTimeoutMerge, ApprovalOnTimeoutClose:
+	default:
+		return fmt.Errorf("invalid on_timeout: %s (must be %s, %s, or %s)", c.OnTimeout,
+			ApprovalOnTimeoutLeaveOpen, ApprovalOnTimeoutMerge, ApprovalOnTimeoutClose)
+	}
+	return nil
+}
+
+// SignOffConfig defines Developer Certificate of Origin (DCO) sign-off settings. When enabled, commits
+// are authored by the sign-off identity and end with a Signed-off-by trailer for it, and PR bodies
+// include the sign-off note.
+type SignOffConfig struct {
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	Name    string `yaml:"name,omitempty" json:"name,omitempty"`       // defaults to COMMITTER_NAME
+	Email   string `yaml:"email,omitempty" json:"email,omitempty"`     // defaults to COMMITTER_EMAIL
+	PRNote  string `yaml:"pr_note,omitempty" json:"pr_note,omitempty"` // appended to PR bodies; defaults to a DCO statement
+}
+
+// Validate validates the sign-off configuration
+func (s *SignOffConfig) Validate() error {
+	if s.Email != "" && !strings.Contains(s.Email, "@") {
+		return fmt.Errorf("invalid email: %s", s.Email)
+	}
+	if strings.ContainsAny(s.Name, "<>\n") {
+		return fmt.Errorf("invalid name: %q", s.Name)
 	}
 	return nil
 }
@@ -269,6 +484,595 @@ type DeprecationConfig struct {
 	File    string `yaml:"file,omitempty" json:"file,omitempty"` // defaults to deprecated_examples.json
 }
 
+// DefaultMaxOrphanDeletions is how many destination files a workflow with delete_orphans removes per run
+// when max_deletions isn't set
+const DefaultMaxOrphanDeletions = 20
+
+// DeleteOrphansConfig removes the destination copies of deleted source files in a pull request, instead
+// of only listing them in the deprecation file
+type DeleteOrphansConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// MaxDeletions is the most files removed in one run; if more source files are deleted, none are
+	// removed and they're listed in the deprecation file instead. Defaults to DefaultMaxOrphanDeletions.
+	MaxDeletions int `yaml:"max_deletions,omitempty" json:"max_deletions,omitempty"`
+}
+
+// IsEnabled returns true if orphaned destination files should be deleted
+func (c *DeleteOrphansConfig) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// GetMaxDeletions returns the most files removed in one run
+func (c *DeleteOrphansConfig) GetMaxDeletions() int {
+	if c == nil || c.MaxDeletions == 0 {
+		return DefaultMaxOrphanDeletions
+	}
+	return c.MaxDeletions
+}
+
+// Validate validates the delete orphans configuration
+func (c *DeleteOrphansConfig) Validate() error {
+	if c.MaxDeletions < 0 {
+		return fmt.Errorf("max_deletions must not be negative")
+	}
+	return nil
+}
+
+// LFSMode defines what a workflow does with source files that are Git LFS pointers
+type LFSMode string
+
+const (
+	// LFSModeSkip doesn't copy LFS files; each one is logged and recorded in the audit log
+	LFSModeSkip LFSMode = "skip"
+	// LFSModePointer copies the pointer file and tracks the target path with LFS in the destination
+	LFSModePointer LFSMode = "pointer"
+)
+
+// LFSConfig defines how a workflow copies files stored with Git LFS. Without it, the pointer files
+// would be committed to the destination as plain text, which breaks anything that reads them.
+type LFSConfig struct {
+	Mode LFSMode `yaml:"mode,omitempty" json:"mode,omitempty"` // defaults to skip
+	// URL is the LFS server written to the destination's .lfsconfig in pointer mode, so the pointers
+	// resolve to the objects the source already stored (for example, on an S3- or GCS-backed server)
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+}
+
+// GetMode returns the LFS mode, defaulting to skip
+func (c *LFSConfig) GetMode() LFSMode {
+	if c == nil || c.Mode == "" {
+		return LFSModeSkip
+	}
+	return c.Mode
+}
+
+// Validate validates the LFS configuration
+func (c *LFSConfig) Validate() error {
+	if c.GetMode() != LFSModeSkip && c.GetMode() != LFSModePointer {
+		return fmt.Errorf("invalid mode: %s (must be %s or %s)", c.Mode, LFSModeSkip, LFSModePointer)
+	}
+	if c.URL != "" {
+		if c.GetMode() != LFSModePointer {
+			return fmt.Errorf("url is only used with mode %s", LFSModePointer)
+		}
+		if !strings.HasPrefix(c.URL, "https://") {
+			return fmt.Errorf("url must be an https URL")
+		}
+	}
+	return nil
+}
+
+// DefaultBuildVerifyTimeoutMinutes is how long a workflow with verify_build waits for the destination's
+// check runs to finish
+const DefaultBuildVerifyTimeoutMinutes = 30
+
+// VerifyBuildConfig checks the destination's build after the copier's commit lands on the target
+// branch. Failed check runs are recorded in the audit log and sent to the workflow's notifications.
+// Only GitHub destinations have check runs.
+type VerifyBuildConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// TimeoutMinutes is how long to wait for the check runs to finish. Defaults to
+	// DefaultBuildVerifyTimeoutMinutes.
+	TimeoutMinutes int `yaml:"timeout_minutes,omitempty" json:"timeout_minutes,omitempty"`
+	// Checks are the names of the check runs to wait for. Defaults to every check run on the commit.
+	Checks []string `yaml:"checks,omitempty" json:"checks,omitempty"`
+}
+
+// IsEnabled returns true if the destination's build should be checked
+func (c *VerifyBuildConfig) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// GetTimeout returns how long to wait for the check runs to finish
+func (c *VerifyBuildConfig) GetTimeout() time.Duration {
+	if c == nil || c.TimeoutMinutes == 0 {
+		return DefaultBuildVerifyTimeoutMinutes * time.Minute
+	}
+	return time.Duration(c.TimeoutMinutes) * time.Minute
+}
+
+// Validate validates the verify build configuration
+func (c *VerifyBuildConfig) Validate() error {
+	if c.TimeoutMinutes < 0 {
+		return fmt.Errorf("timeout_minutes must not be negative")
+	}
+	for i, check := range c.Checks {
+		if strings.TrimSpace(check) == "" {
+			return fmt.Errorf("checks[%d] must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// MirrorConfig adds the files a workflow copies to the read-only examples mirror repo (MIRROR_REPO) as well
+// as its destination, under "<product>/<language>/", so internal consumers have one place to browse the
+// current examples from every selected workflow. Files removed from the destination are removed from the
+// mirror too.
+type MirrorConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Product is the mirror's top-level directory for the workflow. Defaults to the destination repo's name.
+	Product string `yaml:"product,omitempty" json:"product,omitempty"`
+	// Language is the directory under Product. Optional; without it files go directly under Product.
+	Language string `yaml:"language,omitempty" json:"language,omitempty"`
+}
+
+// IsEnabled returns true if the workflow's files are added to the mirror
+func (c *MirrorConfig) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// Dir returns the mirror directory for a workflow copying to destinationRepo, e.g. "atlas-sdk/go"
+func (c *MirrorConfig) Dir(destinationRepo string) string {
+	product := c.Product
+	if product == "" {
+		_, repo := SplitDestinationRepo(destinationRepo)
+		product = path.Base(repo)
+	}
+	if c.Language == "" {
+		return product
+	}
+	return product + "/" + c.Language
+}
+
+// Validate validates the mirror configuration
+func (c *MirrorConfig) Validate() error {
+	if err := validateMirrorDir("product", c.Product); err != nil {
+		return err
+	}
+	return validateMirrorDir("language", c.Language)
+}
+
+// LimitsConfig caps how much a single run of a workflow may copy, so a misconfigured pattern can't flood the
+// destination with build artifacts or other large files. Limits are checked before anything is staged for upload;
+// a run that exceeds any of them copies nothing. Zero means no limit.
+type LimitsConfig struct {
+	MaxFiles           int   `yaml:"max_files,omitempty" json:"max_files,omitempty"`                         // Files copied per run
+	MaxTotalBytes      int64 `yaml:"max_total_bytes,omitempty" json:"max_total_bytes,omitempty"`             // Total size of the files copied per run
+	MaxSingleFileBytes int64 `yaml:"max_single_file_bytes,omitempty" json:"max_single_file_bytes,omitempty"` // Size of any one file
+}
+
+// GetMaxFiles returns the maximum number of files per run, or 0 for no limit
+func (c *LimitsConfig) GetMaxFiles() int {
+	if c == nil {
+		return 0
+	}
+	return c.MaxFiles
+}
+
+// GetMaxTotalBytes returns the maximum total size of the files copied per run, or 0 for no limit
+func (c *LimitsConfig) GetMaxTotalBytes() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.MaxTotalBytes
+}
+
+// GetMaxSingleFileBytes returns the maximum size of a single copied file, or 0 for no limit
+func (c *LimitsConfig) GetMaxSingleFileBytes() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.MaxSingleFileBytes
+}
+
+// Validate validates the limits configuration
+func (c *LimitsConfig) Validate() error {
+	if c.MaxFiles < 0 {
+		return fmt.Errorf("max_files must not be negative")
+	}
+	if c.MaxTotalBytes < 0 {
+		return fmt.Errorf("max_total_bytes must not be negative")
+	}
+	if c.MaxSingleFileBytes < 0 {
+		return fmt.Errorf("max_single_file_bytes must not be negative")
+	}
+	if c.MaxSingleFileBytes > 0 && c.MaxTotalBytes > 0 && c.MaxSingleFileBytes > c.MaxTotalBytes {
+		return fmt.Errorf("max_single_file_bytes must not be greater than max_total_bytes")
+	}
+	return nil
+}
+
+// DefaultChangelogPath is where a workflow's changelog is kept in the destination repo if it doesn't set a path
+const DefaultChangelogPath = "CHANGELOG.md"
+
+// ChangelogConfig keeps a changelog in the destination repo with an entry for each sync: the date, a link to the
+// source change, and the files it copied and deleted. The entry is added in the same commit as the files, so the
+// destination's consumers can follow its history without digging through the copier's PRs.
+type ChangelogConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Path is the changelog's path in the destination repo. Defaults to DefaultChangelogPath.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+}
+
+// IsEnabled returns true if the workflow keeps a changelog in its destination
+func (c *ChangelogConfig) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// GetPath returns the changelog's path in the destination repo
+func (c *ChangelogConfig) GetPath() string {
+	if c == nil || c.Path == "" {
+		return DefaultChangelogPath
+	}
+	return c.Path
+}
+
+// Validate validates the changelog configuration
+func (c *ChangelogConfig) Validate() error {
+	if c.Path == "" {
+		return nil
+	}
+	if strings.HasPrefix(c.Path, "/") || strings.HasSuffix(c.Path, "/") || path.Clean(c.Path) != c.Path || strings.HasPrefix(c.Path, "../") || c.Path == ".." {
+		return fmt.Errorf("path %q must be a relative file path", c.Path)
+	}
+	return nil
+}
+
+// ArtifactsConfig names the GitHub Actions artifacts a workflow with the workflow_run trigger copies. When a
+// run of one of the listed Actions workflows succeeds on the source branch, the files in each listed artifact
+// are copied as if they were in the source repo under a directory named after the artifact, so
+// transformations match artifact "bundle" as "bundle/...".
+type ArtifactsConfig struct {
+	// Workflows are the names of the Actions workflows whose runs trigger the workflow; empty means any
+	Workflows []string `yaml:"workflows,omitempty" json:"workflows,omitempty"`
+	// Names are the names of the artifacts to copy
+	Names []string `yaml:"names" json:"names"`
+}
+
+// MatchesWorkflow returns true if runs of the Actions workflow named name trigger the workflow
+func (c *ArtifactsConfig) MatchesWorkflow(name string) bool {
+	if c == nil {
+		return false
+	}
+	if len(c.Workflows) == 0 {
+		return true
+	}
+	for _, workflow := range c.Workflows {
+		if workflow == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasArtifact returns true if the workflow copies the artifact named name
+func (c *ArtifactsConfig) HasArtifact(name string) bool {
+	if c == nil {
+		return false
+	}
+	for _, artifact := range c.Names {
+		if artifact == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the artifacts configuration
+func (c *ArtifactsConfig) Validate() error {
+	if len(c.Names) == 0 {
+		return fmt.Errorf("names is required")
+	}
+	for i, name := range c.Names {
+		if strings.TrimSpace(name) == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("names[%d] must be an artifact name without slashes: %q", i, name)
+		}
+	}
+	for i, workflow := range c.Workflows {
+		if strings.TrimSpace(workflow) == "" {
+			return fmt.Errorf("workflows[%d] must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// ReleaseConfig chooses which GitHub releases run a workflow with the release trigger
+type ReleaseConfig struct {
+	// Tags are glob patterns for the tags of releases that trigger the workflow, such as "v*"; empty means any
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	// Prereleases also triggers the workflow for releases marked as pre-releases
+	Prereleases bool `yaml:"prereleases,omitempty" json:"prereleases,omitempty"`
+}
+
+// MatchesRelease returns true if the release with the tag triggers the workflow. Without a release
+// configuration, every release that isn't a pre-release does.
+func (c *ReleaseConfig) MatchesRelease(tag string, prerelease bool) bool {
+	if c == nil {
+		return !prerelease
+	}
+	if prerelease && !c.Prereleases {
+		return false
+	}
+	if len(c.Tags) == 0 {
+		return true
+	}
+	for _, pattern := range c.Tags {
+		if matched, err := path.Match(pattern, tag); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the release configuration
+func (c *ReleaseConfig) Validate() error {
+	for i, pattern := range c.Tags {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("tags[%d] must not be empty", i)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("tags[%d] is not a valid pattern: %q", i, pattern)
+		}
+	}
+	return nil
+}
+
+// validateMirrorDir checks that an optional mirror directory is a single path segment
+func validateMirrorDir(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	if strings.Contains(value, "/") || value == "." || value == ".." || strings.TrimSpace(value) != value {
+		return fmt.Errorf("%s %q must be a single directory name", field, value)
+	}
+	return nil
+}
+
+// SecretScanConfig defines secret scanning settings for files copied by a workflow
+type SecretScanConfig struct {
+	Enabled    *bool    `yaml:"enabled,omitempty" json:"enabled,omitempty"`         // defaults to true
+	AllowPaths []string `yaml:"allow_paths,omitempty" json:"allow_paths,omitempty"` // Regex patterns for source paths that are not scanned (e.g. test fixtures with fake keys)
+}
+
+// IsEnabled returns true if secret scanning is enabled. Scanning is on unless explicitly disabled,
+// since destination repos are frequently public.
+func (s *SecretScanConfig) IsEnabled() bool {
+	return s == nil || s.Enabled == nil || *s.Enabled
+}
+
+// IsAllowed returns true if the source path matches one of the allow_paths patterns
+func (s *SecretScanConfig) IsAllowed(sourcePath string) bool {
+	if s == nil {
+		return false
+	}
+	for _, pattern := range s.AllowPaths {
+		if matched, err := regexp.MatchString(pattern, sourcePath); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the secret scan configuration
+func (s *SecretScanConfig) Validate() error {
+	for i, pattern := range s.AllowPaths {
+		if pattern == "" {
+			return fmt.Errorf("allow_paths[%d] is empty", i)
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("allow_paths[%d] is not a valid regex: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// NotificationConfig defines where a workflow posts a summary of each run
+type NotificationConfig struct {
+	SlackWebhookURL string `yaml:"slack_webhook_url,omitempty" json:"slack_webhook_url,omitempty"` // defaults to the service's SLACK_WEBHOOK_URL; ${VAR} references are read from the environment
+	SlackChannel    string `yaml:"slack_channel,omitempty" json:"slack_channel,omitempty"`         // defaults to the webhook's channel
+	OnSuccess       *bool  `yaml:"on_success,omitempty" json:"on_success,omitempty"`               // defaults to true; failures are always posted
+}
+
+// WebhookURL returns the Slack webhook URL with ${VAR} references replaced from the environment, so the URL
+// doesn't have to be committed to the config repo
+func (n *NotificationConfig) WebhookURL() string {
+	return os.ExpandEnv(n.SlackWebhookURL)
+}
+
+// NotifyOnSuccess returns true if runs without errors should be posted
+func (n *NotificationConfig) NotifyOnSuccess() bool {
+	return n.OnSuccess == nil || *n.OnSuccess
+}
+
+// Validate validates the notification configuration
+func (n *NotificationConfig) Validate() error {
+	if n.SlackWebhookURL != "" && !strings.Contains(n.SlackWebhookURL, "${") && !strings.HasPrefix(n.SlackWebhookURL, "https://") {
+		return fmt.Errorf("slack_webhook_url must be an https URL or an ${ENV_VAR} reference")
+	}
+	return nil
+}
+
+// SchemaValidationRule validates copied files matching a glob pattern against a JSON Schema
+type SchemaValidationRule struct {
+	Files  string `yaml:"files" json:"files"`   // Glob pattern for source paths (e.g. "configs/**/*.json")
+	Schema string `yaml:"schema" json:"schema"` // Path to the JSON Schema file in the source repo
+}
+
+// Matches returns true if the source path matches the rule's files pattern
+func (s *SchemaValidationRule) Matches(sourcePath string) bool {
+	matched, err := doublestar.Match(s.Files, sourcePath)
+	return err == nil && matched
+}
+
+// Validate validates the schema validation rule
+func (s *SchemaValidationRule) Validate() error {
+	if s.Files == "" {
+		return fmt.Errorf("files is required")
+	}
+	if !doublestar.ValidatePattern(s.Files) {
+		return fmt.Errorf("files is not a valid glob pattern: %s", s.Files)
+	}
+	if s.Schema == "" {
+		return fmt.Errorf("schema is required")
+	}
+	return validateRepoPath("schema", s.Schema)
+}
+
+// ContentTransformType defines how a content transform rewrites a file
+type ContentTransformType string
+
+const (
+	ContentTransformReplace    ContentTransformType = "replace"     // Regex find and replace
+	ContentTransformHeader     ContentTransformType = "header"      // Insert text at the top of the file
+	ContentTransformStripLines ContentTransformType = "strip_lines" // Remove lines, or ranges of lines between markers
+)
+
+// ContentTransform rewrites the content of copied files before they're queued for upload
+type ContentTransform struct {
+	Type  ContentTransformType `yaml:"type" json:"type"`
+	Files string               `yaml:"files,omitempty" json:"files,omitempty"` // Glob pattern for source paths; defaults to all files
+
+	// replace: every match of pattern is replaced; replacement may use $1 or ${name} for capture groups
+	Pattern     string `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Replacement string `yaml:"replacement,omitempty" json:"replacement,omitempty"`
+
+	// header: text inserted at the top of the file, after a shebang line if there is one.
+	// ${source_repo}, ${source_path}, and ${commit_sha} are replaced.
+	Header string `yaml:"header,omitempty" json:"header,omitempty"`
+
+	// strip_lines: lines matching start are removed. If end is set, every line from a start match
+	// through the next end match is removed.
+	Start string `yaml:"start,omitempty" json:"start,omitempty"`
+	End   string `yaml:"end,omitempty" json:"end,omitempty"`
+}
+
+// Matches returns true if the transform applies to the source path
+func (c *ContentTransform) Matches(sourcePath string) bool {
+	if c.Files == "" {
+		return true
+	}
+	matched, err := doublestar.Match(c.Files, sourcePath)
+	return err == nil && matched
+}
+
+// Validate validates the content transform
+func (c *ContentTransform) Validate() error {
+	if c.Files != "" && !doublestar.ValidatePattern(c.Files) {
+		return fmt.Errorf("files is not a valid glob pattern: %s", c.Files)
+	}
+
+	switch c.Type {
+	case ContentTransformReplace:
+		if c.Pattern == "" {
+			return fmt.Errorf("pattern is required for replace transforms")
+		}
+		if _, err := regexp.Compile(c.Pattern); err != nil {
+			return fmt.Errorf("pattern is not a valid regex: %w", err)
+		}
+	case ContentTransformHeader:
+		if c.Header == "" {
+			return fmt.Errorf("header is required for header transforms")
+		}
+	case ContentTransformStripLines:
+		if c.Start == "" {
+			return fmt.Errorf("start is required for strip_lines transforms")
+		}
+		if _, err := regexp.Compile(c.Start); err != nil {
+			return fmt.Errorf("start is not a valid regex: %w", err)
+		}
+		if _, err := regexp.Compile(c.End); err != nil {
+			return fmt.Errorf("end is not a valid regex: %w", err)
+		}
+	case "":
+		return fmt.Errorf("type is required")
+	default:
+		return fmt.Errorf("invalid type: %s (must be replace, header, or strip_lines)", c.Type)
+	}
+	return nil
+}
+
+// DefaultProvenanceTemplate is the text of the provenance header when the workflow doesn't set one
+const DefaultProvenanceTemplate = "Generated from ${source_repo}/${source_path} @ ${commit_sha}; do not edit"
+
+// ProvenanceHeaderConfig adds a comment to the top of each copied file naming the source file and commit it
+// was copied from, so people reading the destination repo know to change the source instead. The comment
+// uses the file type's comment syntax; files whose type has no comment syntax, such as JSON, are copied
+// without one.
+type ProvenanceHeaderConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Template is the header's text. ${source_repo}, ${source_path}, and ${commit_sha} are replaced.
+	// Defaults to DefaultProvenanceTemplate.
+	Template string `yaml:"template,omitempty" json:"template,omitempty"`
+	// CommentSyntax adds to or overrides the built-in comment syntax, keyed by extension (".tf") or file
+	// name ("Dockerfile"). A value is a line comment prefix ("#") or a block comment's start and end
+	// separated by a space ("<!-- -->"). An empty value turns the header off for those files.
+	CommentSyntax map[string]string `yaml:"comment_syntax,omitempty" json:"comment_syntax,omitempty"`
+}
+
+// IsEnabled returns true if the workflow adds a provenance header to copied files
+func (c *ProvenanceHeaderConfig) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// GetTemplate returns the header's text before variables are replaced
+func (c *ProvenanceHeaderConfig) GetTemplate() string {
+	if c == nil || c.Template == "" {
+		return DefaultProvenanceTemplate
+	}
+	return c.Template
+}
+
+// Validate validates the provenance header configuration
+func (c *ProvenanceHeaderConfig) Validate() error {
+	if strings.ContainsAny(c.Template, "\r\n") {
+		return fmt.Errorf("template must be a single line")
+	}
+	for key, syntax := range c.CommentSyntax {
+		if key == "" || key == "." || strings.Contains(key, "/") {
+			return fmt.Errorf("comment_syntax: %q must be an extension such as \".tf\" or a file name", key)
+		}
+		if len(strings.Fields(syntax)) > 2 {
+			return fmt.Errorf("comment_syntax[%s]: %q must be a line comment prefix or a block comment's start and end", key, syntax)
+		}
+	}
+	return nil
+}
+
+// ShadowConfig runs a new workflow in shadow mode: files are matched and transformed as usual, but
+// instead of writing to the destination, the diff each change would have made is posted to Slack for the
+// workflow's owner to review. Once the diffs look right, set enabled to false, or remove the block, and
+// the workflow goes live.
+type ShadowConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Notifications is where shadow diffs are posted. Defaults to the workflow's notifications, then the
+	// service's SLACK_WEBHOOK_URL.
+	Notifications *NotificationConfig `yaml:"notifications,omitempty" json:"notifications,omitempty"`
+}
+
+// IsEnabled returns true if the workflow runs in shadow mode
+func (c *ShadowConfig) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// Validate validates the shadow configuration
+func (c *ShadowConfig) Validate() error {
+	if c.Notifications != nil {
+		if err := c.Notifications.Validate(); err != nil {
+			return fmt.Errorf("notifications: %w", err)
+		}
+	}
+	return nil
+}
+
 // ============================================================================
 // Workflow-based configuration types
 // ============================================================================
@@ -278,6 +1082,8 @@ type Defaults struct {
 	CommitStrategy   *CommitStrategyConfig `yaml:"commit_strategy,omitempty" json:"commit_strategy,omitempty"`
 	DeprecationCheck *DeprecationConfig    `yaml:"deprecation_check,omitempty" json:"deprecation_check,omitempty"`
 	Exclude          []string              `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+	SecretScan       *SecretScanConfig     `yaml:"secret_scan,omitempty" json:"secret_scan,omitempty"`
+	Notifications    *NotificationConfig   `yaml:"notifications,omitempty" json:"notifications,omitempty"`
 }
 
 // Workflow defines a complete source → destination mapping with transformations
@@ -289,25 +1095,194 @@ type Workflow struct {
 	Exclude          []string              `yaml:"exclude,omitempty" json:"exclude,omitempty"`
 	CommitStrategy   *CommitStrategyConfig `yaml:"commit_strategy,omitempty" json:"commit_strategy,omitempty"`
 	DeprecationCheck *DeprecationConfig    `yaml:"deprecation_check,omitempty" json:"deprecation_check,omitempty"`
+	SecretScan       *SecretScanConfig     `yaml:"secret_scan,omitempty" json:"secret_scan,omitempty"`
+	SchemaValidation []SchemaValidationRule `yaml:"schema_validation,omitempty" json:"schema_validation,omitempty"`
+	DryRun           *bool                 `yaml:"dry_run,omitempty" json:"dry_run,omitempty"` // overrides the service-level DRY_RUN setting
+	Notifications    *NotificationConfig   `yaml:"notifications,omitempty" json:"notifications,omitempty"`
+	ContentTransforms []ContentTransform   `yaml:"content_transforms,omitempty" json:"content_transforms,omitempty"`
+	ProvenanceHeader *ProvenanceHeaderConfig `yaml:"provenance_header,omitempty" json:"provenance_header,omitempty"`
+	Shadow           *ShadowConfig         `yaml:"shadow,omitempty" json:"shadow,omitempty"` // posts would-be diffs to Slack instead of writing
+	Trigger          WorkflowTriggers      `yaml:"trigger,omitempty" json:"trigger,omitempty"` // defaults to pr_merged
+	DeleteOrphans    *DeleteOrphansConfig  `yaml:"delete_orphans,omitempty" json:"delete_orphans,omitempty"`
+	LFS              *LFSConfig            `yaml:"lfs,omitempty" json:"lfs,omitempty"` // defaults to skipping LFS files
+	VerifyBuild      *VerifyBuildConfig    `yaml:"verify_build,omitempty" json:"verify_build,omitempty"`
+	Mirror           *MirrorConfig         `yaml:"mirror,omitempty" json:"mirror,omitempty"`
+	Limits           *LimitsConfig         `yaml:"limits,omitempty" json:"limits,omitempty"`
+	Changelog        *ChangelogConfig      `yaml:"changelog,omitempty" json:"changelog,omitempty"`
+	Artifacts        *ArtifactsConfig      `yaml:"artifacts,omitempty" json:"artifacts,omitempty"` // required by the workflow_run trigger
+	Release          *ReleaseConfig        `yaml:"release,omitempty" json:"release,omitempty"` // used with the release trigger
+	// Variables are custom values for commit message and PR templates, as ${name} or {{ .Variables.name }}
+	Variables map[string]string `yaml:"variables,omitempty" json:"variables,omitempty"`
+	// Priority orders the workflows that match a change: higher runs first, and the same priority runs in
+	// config order. With conflict_policy first-match-wins, it decides which workflow copies a shared file.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
 	// Internal fields for $ref support (not serialized)
 	TransformationsRef string `yaml:"-" json:"-"`
 	ExcludeRef         string `yaml:"-" json:"-"`
 	CommitStrategyRef  string `yaml:"-" json:"-"`
+
+	// SourceIgnore holds the lines of the source repo's .copierignore file, loaded with the config. Files
+	// it matches aren't copied, in addition to those matched by Exclude.
+	SourceIgnore []string `yaml:"-" json:"-"`
+}
+
+// IsDryRun returns true if the workflow should only report what it would change. The workflow's
+// dry_run setting, if set, overrides the service-level default.
+func (w *Workflow) IsDryRun(serviceDryRun bool) bool {
+	if w.DryRun != nil {
+		return *w.DryRun
+	}
+	return serviceDryRun
+}
+
+// Workflow triggers: the source repo events that run a workflow
+const (
+	WorkflowTriggerPRMerged = "pr_merged" // a PR or MR merged into the source branch
+	WorkflowTriggerPush     = "push"      // a push to the source branch (GitHub only)
+	// WorkflowTriggerWorkflowRun is a successful GitHub Actions run on the source branch, whose artifacts are
+	// copied (GitHub only)
+	WorkflowTriggerWorkflowRun = "workflow_run"
+	// WorkflowTriggerRelease is a published GitHub release, whose tag is copied from (GitHub only)
+	WorkflowTriggerRelease = "release"
+	// WorkflowTriggerChained is a commit another workflow's copy landed on the source branch, run in the same
+	// process (GitHub and Bitbucket only, since those are the destination platforms)
+	WorkflowTriggerChained = "chained"
+	workflowTriggerBoth    = "both" // shorthand for pr_merged and push
+)
+
+// WorkflowTriggers lists the events that run a workflow. In YAML it can be a single trigger or a list
+// of triggers; "both" is shorthand for [pr_merged, push]. No triggers means pr_merged.
+type WorkflowTriggers []string
+
+// UnmarshalYAML accepts a single trigger or a list of triggers
+func (t *WorkflowTriggers) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var triggers []string
+	var single string
+	if err := unmarshal(&single); err == nil {
+		triggers = []string{single}
+	} else if err := unmarshal(&triggers); err != nil {
+		return fmt.Errorf("trigger must be a string or a list of strings")
+	}
+
+	*t = nil
+	for _, trigger := range triggers {
+		if trigger == workflowTriggerBoth {
+			*t = append(*t, WorkflowTriggerPRMerged, WorkflowTriggerPush)
+		} else {
+			*t = append(*t, trigger)
+		}
+	}
+	return nil
+}
+
+// Has returns true if the workflow runs on the trigger
+func (t WorkflowTriggers) Has(trigger string) bool {
+	if len(t) == 0 {
+		return trigger == WorkflowTriggerPRMerged
+	}
+	for _, tr := range t {
+		if tr == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the triggers
+func (t WorkflowTriggers) Validate() error {
+	for _, trigger := range t {
+		if trigger != WorkflowTriggerPRMerged && trigger != WorkflowTriggerPush && trigger != WorkflowTriggerWorkflowRun &&
+			trigger != WorkflowTriggerRelease && trigger != WorkflowTriggerChained {
+			return fmt.Errorf("invalid trigger: %q (must be %s, %s, %s, %s, or %s)", trigger,
+				WorkflowTriggerPRMerged, WorkflowTriggerPush, WorkflowTriggerWorkflowRun, WorkflowTriggerRelease, WorkflowTriggerChained)
+		}
+	}
+	return nil
 }
 
 // Source defines the source repository and branch
 type Source struct {
 	Repo           string `yaml:"repo" json:"repo"`
-	Branch         string `yaml:"branch,omitempty" json:"branch,omitempty"`         // defaults to "main"
+	Branch         string `yaml:"branch,omitempty" json:"branch,omitempty"`         // defaults to "main"; can be a pattern like "release/*"
 	InstallationID string `yaml:"installation_id,omitempty" json:"installation_id,omitempty"` // optional override
+	Platform       string `yaml:"platform,omitempty" json:"platform,omitempty"`               // "github" (default), "gitlab", or "bitbucket"
 }
 
-// Destination defines the destination repository and branch
+// Source platforms. GitLab repos are identified by their full project path, such as "group/subgroup/project".
+// Bitbucket Cloud repos are identified as "workspace/repo".
+const (
+	SourcePlatformGitHub    = "github"
+	SourcePlatformGitLab    = "gitlab"
+	SourcePlatformBitbucket = "bitbucket"
+)
+
+// BitbucketRepoPrefix marks a destination repo hosted on Bitbucket Cloud, as in "bitbucket:workspace/repo".
+// Destination repos without a prefix are on GitHub.
+const BitbucketRepoPrefix = "bitbucket:"
+
+// SplitDestinationRepo returns the platform hosting a destination repo and the repo's path on that platform
+func SplitDestinationRepo(repo string) (platform string, path string) {
+	if path, ok := strings.CutPrefix(repo, BitbucketRepoPrefix); ok {
+		return SourcePlatformBitbucket, path
+	}
+	return SourcePlatformGitHub, repo
+}
+
+// GetPlatform returns the platform hosting the source repo, defaulting to GitHub
+func (s Source) GetPlatform() string {
+	if s.Platform == "" {
+		return SourcePlatformGitHub
+	}
+	return s.Platform
+}
+
+// Destination defines the destination repository and branch. In YAML, branch can also be a list of
+// branches or patterns, such as [v7.0, v8.0] or "v*", to copy the same files to each matching branch.
 type Destination struct {
-	Repo           string `yaml:"repo" json:"repo"`
-	Branch         string `yaml:"branch,omitempty" json:"branch,omitempty"`         // defaults to "main"
-	InstallationID string `yaml:"installation_id,omitempty" json:"installation_id,omitempty"` // optional override
+	Repo           string   `yaml:"repo" json:"repo"`                                 // "owner/repo" on GitHub, or "bitbucket:workspace/repo"
+	Branch         string   `yaml:"branch,omitempty" json:"branch,omitempty"`         // defaults to "main"; can be a pattern like "v*"
+	Branches       []string `yaml:"-" json:"branches,omitempty"`                      // set instead of Branch when branch is a list
+	InstallationID string   `yaml:"installation_id,omitempty" json:"installation_id,omitempty"` // optional override
+}
+
+// destinationYAML is a Destination as written in YAML, with Branch a string or a list of strings
+type destinationYAML[B any] struct {
+	Repo           string `yaml:"repo"`
+	Branch         B      `yaml:"branch,omitempty"`
+	InstallationID string `yaml:"installation_id,omitempty"`
+}
+
+// UnmarshalYAML accepts a single branch or a list of branches
+func (d *Destination) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single destinationYAML[string]
+	if err := unmarshal(&single); err == nil {
+		*d = Destination{Repo: single.Repo, Branch: single.Branch, InstallationID: single.InstallationID}
+		return nil
+	}
+	var list destinationYAML[[]string]
+	if err := unmarshal(&list); err != nil {
+		return fmt.Errorf("destination branch must be a string or a list of strings: %w", err)
+	}
+	*d = Destination{Repo: list.Repo, Branches: list.Branch, InstallationID: list.InstallationID}
+	if len(d.Branches) == 1 {
+		d.Branch, d.Branches = d.Branches[0], nil
+	}
+	return nil
+}
+
+// MarshalYAML writes branch as a list when the destination has more than one
+func (d Destination) MarshalYAML() (interface{}, error) {
+	if len(d.Branches) > 0 {
+		return destinationYAML[[]string]{Repo: d.Repo, Branch: d.Branches, InstallationID: d.InstallationID}, nil
+	}
+	return destinationYAML[string]{Repo: d.Repo, Branch: d.Branch, InstallationID: d.InstallationID}, nil
+}
+
+// GetPlatform returns the platform hosting the destination repo, from the repo's prefix
+func (d Destination) GetPlatform() string {
+	platform, _ := SplitDestinationRepo(d.Repo)
+	return platform
 }
 
 // TransformationType defines the type of transformation
@@ -327,12 +1302,17 @@ type Transformation struct {
 	Copy  *CopyTransform  `yaml:"copy,omitempty" json:"copy,omitempty"`
 	Glob  *GlobTransform  `yaml:"glob,omitempty" json:"glob,omitempty"`
 	Regex *RegexTransform `yaml:"regex,omitempty" json:"regex,omitempty"`
+
+	// Sync removes files under the destination directory that no longer exist in the mapped source
+	// directory at the merge commit, like rsync --delete. Only supported for move and glob transformations.
+	Sync bool `yaml:"sync,omitempty" json:"sync,omitempty"`
 }
 
 // MoveTransform moves files from one directory to another
 type MoveTransform struct {
-	From string `yaml:"from" json:"from"` // Source path (can be directory or file)
-	To   string `yaml:"to" json:"to"`     // Destination path
+	From        string `yaml:"from" json:"from"`                                     // Source path (can be directory or file)
+	To          string `yaml:"to" json:"to"`                                         // Destination path
+	StripPrefix string `yaml:"strip_prefix,omitempty" json:"strip_prefix,omitempty"` // Prefix replaced by "to" (defaults to "from"); must contain "from"
 }
 
 // CopyTransform copies a single file to a new location
@@ -358,6 +1338,9 @@ func (c *YAMLConfig) Validate() error {
 	if len(c.Workflows) == 0 {
 		return fmt.Errorf("at least one workflow is required")
 	}
+	if err := validateConflictPolicy(c.ConflictPolicy); err != nil {
+		return err
+	}
 
 	for i, workflow := range c.Workflows {
 		if err := workflow.Validate(); err != nil {
@@ -373,6 +1356,9 @@ func (m *MainConfig) Validate() error {
 	if len(m.WorkflowConfigs) == 0 {
 		return fmt.Errorf("at least one workflow config reference is required")
 	}
+	if err := validateConflictPolicy(m.ConflictPolicy); err != nil {
+		return err
+	}
 
 	for i, ref := range m.WorkflowConfigs {
 		if err := ref.Validate(); err != nil {
@@ -383,6 +1369,16 @@ func (m *MainConfig) Validate() error {
 	return nil
 }
 
+// validateConflictPolicy checks that a conflict policy is empty or one of the known policies
+func validateConflictPolicy(policy string) error {
+	switch policy {
+	case "", ConflictPolicyAll, ConflictPolicyFirstMatchWins, ConflictPolicyError:
+		return nil
+	}
+	return fmt.Errorf("invalid conflict_policy: %s (must be '%s', '%s', or '%s')",
+		policy, ConflictPolicyAll, ConflictPolicyFirstMatchWins, ConflictPolicyError)
+}
+
 // Validate validates a workflow config reference
 func (w *WorkflowConfigRef) Validate() error {
 	// Skip validation for disabled workflow configs
@@ -452,7 +1448,7 @@ func (c *YAMLConfig) SetDefaults() {
 		}
 
 		// Set destination defaults
-		if workflow.Destination.Branch == "" {
+		if workflow.Destination.Branch == "" && len(workflow.Destination.Branches) == 0 {
 			workflow.Destination.Branch = "main"
 		}
 
@@ -480,6 +1476,18 @@ func (c *YAMLConfig) SetDefaults() {
 			if !workflow.CommitStrategy.UsePRTemplate && c.Defaults.CommitStrategy.UsePRTemplate {
 				workflow.CommitStrategy.UsePRTemplate = c.Defaults.CommitStrategy.UsePRTemplate
 			}
+			if workflow.CommitStrategy.SignOff == nil {
+				workflow.CommitStrategy.SignOff = c.Defaults.CommitStrategy.SignOff
+			}
+			if workflow.CommitStrategy.ApprovalGate == nil {
+				workflow.CommitStrategy.ApprovalGate = c.Defaults.CommitStrategy.ApprovalGate
+			}
+			if workflow.CommitStrategy.Reviewers == nil {
+				workflow.CommitStrategy.Reviewers = c.Defaults.CommitStrategy.Reviewers
+			}
+			if workflow.CommitStrategy.OnConflict == "" {
+				workflow.CommitStrategy.OnConflict = c.Defaults.CommitStrategy.OnConflict
+			}
 			// Note: AutoMerge is intentionally not inherited here to avoid accidentally
 			// enabling auto-merge when a workflow specifies its own commit_strategy
 		}
@@ -492,6 +1500,14 @@ func (c *YAMLConfig) SetDefaults() {
 			workflow.Exclude = c.Defaults.Exclude
 		}
 
+		if workflow.SecretScan == nil && c.Defaults != nil && c.Defaults.SecretScan != nil {
+			workflow.SecretScan = c.Defaults.SecretScan
+		}
+
+		if workflow.Notifications == nil && c.Defaults != nil && c.Defaults.Notifications != nil {
+			workflow.Notifications = c.Defaults.Notifications
+		}
+
 		// Set commit strategy defaults
 		if workflow.CommitStrategy != nil && workflow.CommitStrategy.Type == "" {
 			workflow.CommitStrategy.Type = "pull_request"
@@ -527,7 +1543,7 @@ func (w *WorkflowConfig) SetDefaults() {
 		}
 
 		// Set destination defaults
-		if workflow.Destination.Branch == "" {
+		if workflow.Destination.Branch == "" && len(workflow.Destination.Branches) == 0 {
 			workflow.Destination.Branch = "main"
 		}
 
@@ -555,6 +1571,18 @@ func (w *WorkflowConfig) SetDefaults() {
 			if !workflow.CommitStrategy.UsePRTemplate && w.Defaults.CommitStrategy.UsePRTemplate {
 				workflow.CommitStrategy.UsePRTemplate = w.Defaults.CommitStrategy.UsePRTemplate
 			}
+			if workflow.CommitStrategy.SignOff == nil {
+				workflow.CommitStrategy.SignOff = w.Defaults.CommitStrategy.SignOff
+			}
+			if workflow.CommitStrategy.ApprovalGate == nil {
+				workflow.CommitStrategy.ApprovalGate = w.Defaults.CommitStrategy.ApprovalGate
+			}
+			if workflow.CommitStrategy.Reviewers == nil {
+				workflow.CommitStrategy.Reviewers = w.Defaults.CommitStrategy.Reviewers
+			}
+			if workflow.CommitStrategy.OnConflict == "" {
+				workflow.CommitStrategy.OnConflict = w.Defaults.CommitStrategy.OnConflict
+			}
 			// Note: AutoMerge is intentionally not inherited here to avoid accidentally
 			// enabling auto-merge when a workflow specifies its own commit_strategy
 		}
@@ -567,6 +1595,14 @@ func (w *WorkflowConfig) SetDefaults() {
 			workflow.Exclude = w.Defaults.Exclude
 		}
 
+		if workflow.SecretScan == nil && w.Defaults != nil && w.Defaults.SecretScan != nil {
+			workflow.SecretScan = w.Defaults.SecretScan
+		}
+
+		if workflow.Notifications == nil && w.Defaults != nil && w.Defaults.Notifications != nil {
+			workflow.Notifications = w.Defaults.Notifications
+		}
+
 		// Set commit strategy defaults
 		if workflow.CommitStrategy != nil && workflow.CommitStrategy.Type == "" {
 			workflow.CommitStrategy.Type = "pull_request"
@@ -619,6 +1655,14 @@ func (w *WorkflowConfig) ApplyGlobalDefaults(globalDefaults *Defaults) {
 	if len(w.Defaults.Exclude) == 0 && globalDefaults != nil && len(globalDefaults.Exclude) > 0 {
 		w.Defaults.Exclude = globalDefaults.Exclude
 	}
+
+	if w.Defaults.SecretScan == nil && globalDefaults != nil && globalDefaults.SecretScan != nil {
+		w.Defaults.SecretScan = globalDefaults.SecretScan
+	}
+
+	if w.Defaults.Notifications == nil && globalDefaults != nil && globalDefaults.Notifications != nil {
+		w.Defaults.Notifications = globalDefaults.Notifications
+	}
 }
 
 // MatchResult represents the result of a pattern match
@@ -679,9 +1723,10 @@ func (tc *TransformContext) AddBuiltInVariables() {
 	}
 }
 
-// MessageContext holds context for message template rendering
+// MessageContext holds context for message template rendering. Go templates see the fields by name,
+// such as {{ .SourcePRURL }} or {{ range .Files }}.
 type MessageContext struct {
-	RuleName      string            // Name of the copy rule
+	RuleName      string            // Name of the workflow (or legacy copy rule)
 	SourceRepo    string            // Source repository
 	TargetRepo    string            // Target repository
 	SourceBranch  string            // Source branch
@@ -689,7 +1734,13 @@ type MessageContext struct {
 	FileCount     int               // Number of files being copied
 	PRNumber      int               // PR number that triggered the copy
 	CommitSHA     string            // Commit SHA
-	Variables     map[string]string // Variables from pattern matching
+	SourcePRURL   string            // URL of the source PR or MR, or the compare view of a push
+	SourcePRTitle string            // Title of the source PR or MR
+	Author        string            // Author of the source PR or MR, or the pusher
+	ReleaseTag    string            // Tag of the source release, for workflows with the release trigger
+	Files         []string          // Destination paths of the files being copied
+	DeletedFiles  []string          // Destination paths of the files being deleted
+	Variables     map[string]string // The workflow's custom variables and variables from pattern matching
 }
 
 // NewMessageContext creates a new message context
@@ -714,6 +1765,24 @@ func (w *Workflow) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		Exclude          ExcludeOrRef          `yaml:"exclude,omitempty"`
 		CommitStrategy   CommitStrategyOrRef   `yaml:"commit_strategy,omitempty"`
 		DeprecationCheck *DeprecationConfig    `yaml:"deprecation_check,omitempty"`
+		SecretScan       *SecretScanConfig     `yaml:"secret_scan,omitempty"`
+		SchemaValidation []SchemaValidationRule `yaml:"schema_validation,omitempty"`
+		DryRun           *bool                 `yaml:"dry_run,omitempty"`
+		Notifications    *NotificationConfig   `yaml:"notifications,omitempty"`
+		ContentTransforms []ContentTransform   `yaml:"content_transforms,omitempty"`
+		ProvenanceHeader *ProvenanceHeaderConfig `yaml:"provenance_header,omitempty"`
+		Shadow           *ShadowConfig         `yaml:"shadow,omitempty"`
+		Trigger          WorkflowTriggers      `yaml:"trigger,omitempty"`
+		DeleteOrphans    *DeleteOrphansConfig  `yaml:"delete_orphans,omitempty"`
+		LFS              *LFSConfig            `yaml:"lfs,omitempty"`
+		VerifyBuild      *VerifyBuildConfig    `yaml:"verify_build,omitempty"`
+		Mirror           *MirrorConfig         `yaml:"mirror,omitempty"`
+		Limits           *LimitsConfig         `yaml:"limits,omitempty"`
+		Changelog        *ChangelogConfig      `yaml:"changelog,omitempty"`
+		Artifacts        *ArtifactsConfig      `yaml:"artifacts,omitempty"`
+		Release          *ReleaseConfig        `yaml:"release,omitempty"`
+		Variables        map[string]string     `yaml:"variables,omitempty"`
+		Priority         int                   `yaml:"priority,omitempty"`
 	}
 
 	var alias workflowAlias
@@ -726,6 +1795,24 @@ func (w *Workflow) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	w.Source = alias.Source
 	w.Destination = alias.Destination
 	w.DeprecationCheck = alias.DeprecationCheck
+	w.SecretScan = alias.SecretScan
+	w.SchemaValidation = alias.SchemaValidation
+	w.DryRun = alias.DryRun
+	w.Notifications = alias.Notifications
+	w.ContentTransforms = alias.ContentTransforms
+	w.ProvenanceHeader = alias.ProvenanceHeader
+	w.Shadow = alias.Shadow
+	w.Trigger = alias.Trigger
+	w.DeleteOrphans = alias.DeleteOrphans
+	w.LFS = alias.LFS
+	w.VerifyBuild = alias.VerifyBuild
+	w.Mirror = alias.Mirror
+	w.Limits = alias.Limits
+	w.Changelog = alias.Changelog
+	w.Artifacts = alias.Artifacts
+	w.Release = alias.Release
+	w.Variables = alias.Variables
+	w.Priority = alias.Priority
 
 	// Handle transformations (inline or $ref)
 	if alias.Transformations.IsRef() {
@@ -781,6 +1868,133 @@ func (w *Workflow) Validate() error {
 		if err := w.CommitStrategy.Validate(); err != nil {
 			return fmt.Errorf("commit_strategy: %w", err)
 		}
+		if w.CommitStrategy.ApprovalGate != nil && w.Destination.GetPlatform() != SourcePlatformGitHub {
+			return fmt.Errorf("commit_strategy: approval_gate: only supported for GitHub destinations")
+		}
+		if w.CommitStrategy.Reviewers != nil && w.Destination.GetPlatform() != SourcePlatformGitHub {
+			return fmt.Errorf("commit_strategy: reviewers: only supported for GitHub destinations")
+		}
+		if w.CommitStrategy.GetOnConflict() != OnConflictManual && w.Destination.GetPlatform() != SourcePlatformGitHub {
+			return fmt.Errorf("commit_strategy: on_conflict: only manual is supported for non-GitHub destinations")
+		}
+		// The branch strategy pushes to a branch named after the source change, which every destination
+		// branch would share
+		if w.CommitStrategy.Type == "branch" && w.Destination.IsMultiBranch() {
+			return fmt.Errorf("commit_strategy: branch: not supported with more than one destination branch")
+		}
+	}
+
+	// Validate secret scan if provided
+	if w.SecretScan != nil {
+		if err := w.SecretScan.Validate(); err != nil {
+			return fmt.Errorf("secret_scan: %w", err)
+		}
+	}
+
+	if w.Notifications != nil {
+		if err := w.Notifications.Validate(); err != nil {
+			return fmt.Errorf("notifications: %w", err)
+		}
+	}
+
+	for i, rule := range w.SchemaValidation {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("schema_validation[%d]: %w", i, err)
+		}
+	}
+
+	for i, transform := range w.ContentTransforms {
+		if err := transform.Validate(); err != nil {
+			return fmt.Errorf("content_transforms[%d]: %w", i, err)
+		}
+	}
+
+	if w.ProvenanceHeader != nil {
+		if err := w.ProvenanceHeader.Validate(); err != nil {
+			return fmt.Errorf("provenance_header: %w", err)
+		}
+	}
+
+	if w.Shadow != nil {
+		if err := w.Shadow.Validate(); err != nil {
+			return fmt.Errorf("shadow: %w", err)
+		}
+	}
+
+	if w.DeleteOrphans != nil {
+		if err := w.DeleteOrphans.Validate(); err != nil {
+			return fmt.Errorf("delete_orphans: %w", err)
+		}
+	}
+
+	if w.LFS != nil {
+		if err := w.LFS.Validate(); err != nil {
+			return fmt.Errorf("lfs: %w", err)
+		}
+	}
+
+	if w.VerifyBuild != nil {
+		if err := w.VerifyBuild.Validate(); err != nil {
+			return fmt.Errorf("verify_build: %w", err)
+		}
+		if w.VerifyBuild.IsEnabled() && w.Destination.GetPlatform() != SourcePlatformGitHub {
+			return fmt.Errorf("verify_build: only supported for GitHub destinations")
+		}
+	}
+
+	if w.Mirror != nil {
+		if err := w.Mirror.Validate(); err != nil {
+			return fmt.Errorf("mirror: %w", err)
+		}
+	}
+
+	if w.Limits != nil {
+		if err := w.Limits.Validate(); err != nil {
+			return fmt.Errorf("limits: %w", err)
+		}
+	}
+
+	if w.Changelog != nil {
+		if err := w.Changelog.Validate(); err != nil {
+			return fmt.Errorf("changelog: %w", err)
+		}
+	}
+
+	if err := w.Trigger.Validate(); err != nil {
+		return fmt.Errorf("trigger: %w", err)
+	}
+	if w.Trigger.Has(WorkflowTriggerPush) && w.Source.GetPlatform() != SourcePlatformGitHub {
+		return fmt.Errorf("trigger: %s is only supported for GitHub sources", WorkflowTriggerPush)
+	}
+	if w.Trigger.Has(WorkflowTriggerWorkflowRun) {
+		if w.Source.GetPlatform() != SourcePlatformGitHub {
+			return fmt.Errorf("trigger: %s is only supported for GitHub sources", WorkflowTriggerWorkflowRun)
+		}
+		if w.Artifacts == nil {
+			return fmt.Errorf("trigger: %s requires artifacts", WorkflowTriggerWorkflowRun)
+		}
+	} else if w.Artifacts != nil {
+		return fmt.Errorf("artifacts: only used with the %s trigger", WorkflowTriggerWorkflowRun)
+	}
+	if w.Artifacts != nil {
+		if err := w.Artifacts.Validate(); err != nil {
+			return fmt.Errorf("artifacts: %w", err)
+		}
+	}
+	if w.Trigger.Has(WorkflowTriggerRelease) {
+		if w.Source.GetPlatform() != SourcePlatformGitHub {
+			return fmt.Errorf("trigger: %s is only supported for GitHub sources", WorkflowTriggerRelease)
+		}
+	} else if w.Release != nil {
+		return fmt.Errorf("release: only used with the %s trigger", WorkflowTriggerRelease)
+	}
+	if w.Trigger.Has(WorkflowTriggerChained) && w.Source.GetPlatform() == SourcePlatformGitLab {
+		return fmt.Errorf("trigger: %s isn't supported for GitLab sources, which the copier doesn't write to", WorkflowTriggerChained)
+	}
+	if w.Release != nil {
+		if err := w.Release.Validate(); err != nil {
+			return fmt.Errorf("release: %w", err)
+		}
 	}
 
 	return nil
@@ -794,17 +2008,116 @@ func (s *Source) Validate() error {
 	if s.Branch == "" {
 		s.Branch = "main" // default
 	}
+	if _, err := path.Match(s.Branch, ""); err != nil {
+		return fmt.Errorf("invalid branch pattern %q: %w", s.Branch, err)
+	}
+	switch s.GetPlatform() {
+	case SourcePlatformGitHub, SourcePlatformGitLab:
+	case SourcePlatformBitbucket:
+		if !isWorkspaceRepo(s.Repo) {
+			return fmt.Errorf("bitbucket repo must be \"workspace/repo\", got %q", s.Repo)
+		}
+	default:
+		return fmt.Errorf("platform must be %q, %q, or %q, got %q", SourcePlatformGitHub, SourcePlatformGitLab, SourcePlatformBitbucket, s.Platform)
+	}
 	return nil
 }
 
+// IsBranchPattern reports whether the source branch is a pattern, such as "release/*", rather than
+// a single branch
+func (s Source) IsBranchPattern() bool {
+	return strings.ContainsAny(s.Branch, "*?[")
+}
+
+// MatchesBranch reports whether changes to branch are copied from the source: the source branch
+// itself, or any branch matching its pattern. As in path matching, "*" doesn't match "/", so
+// "release/*" matches "release/8.0" but not "release/8.0/hotfix".
+func (s Source) MatchesBranch(branch string) bool {
+	if !s.IsBranchPattern() {
+		return s.Branch == branch
+	}
+	matched, err := path.Match(s.Branch, branch)
+	return err == nil && matched
+}
+
+// BranchVariables returns the transform variables for a change on branch:
+//   - source_branch: the branch, such as "release/8.0"
+//   - source_branch_suffix: the part of the branch after the pattern's literal prefix, such as "8.0"
+//     for the pattern "release/*"; empty when the source branch isn't a pattern
+func (s Source) BranchVariables(branch string) map[string]string {
+	suffix := ""
+	if s.IsBranchPattern() {
+		prefix := s.Branch[:strings.IndexAny(s.Branch, "*?[")]
+		suffix = strings.TrimPrefix(branch, prefix)
+	}
+	return map[string]string{
+		"source_branch":        branch,
+		"source_branch_suffix": suffix,
+	}
+}
+
+// isWorkspaceRepo reports whether repo has the form "workspace/repo"
+func isWorkspaceRepo(repo string) bool {
+	workspace, name, found := strings.Cut(repo, "/")
+	return found && workspace != "" && name != "" && !strings.Contains(name, "/")
+}
+
+// BranchPatterns returns the branches the destination copies to, each a branch name or a pattern
+func (d Destination) BranchPatterns() []string {
+	if len(d.Branches) > 0 {
+		return d.Branches
+	}
+	return []string{d.Branch}
+}
+
+// HasBranchPattern reports whether any of the destination's branches is a pattern, such as "v*"
+func (d Destination) HasBranchPattern() bool {
+	for _, pattern := range d.BranchPatterns() {
+		if strings.ContainsAny(pattern, "*?[") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMultiBranch reports whether the destination lists more than one branch or a branch pattern, whose
+// branches are resolved when the workflow runs
+func (d Destination) IsMultiBranch() bool {
+	return len(d.Branches) > 0 || d.HasBranchPattern()
+}
+
+// MatchesBranch reports whether the destination copies to branch: one of its branches, or a branch
+// matching one of its patterns. As with source branches, "*" doesn't match "/".
+func (d Destination) MatchesBranch(branch string) bool {
+	for _, pattern := range d.BranchPatterns() {
+		if matched, err := path.Match(pattern, branch); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
 // Validate validates a destination
 func (d *Destination) Validate() error {
 	if d.Repo == "" {
 		return fmt.Errorf("repo is required")
 	}
-	if d.Branch == "" {
+	if d.Branch == "" && len(d.Branches) == 0 {
 		d.Branch = "main" // default
 	}
+	for _, pattern := range d.Branches {
+		if pattern == "" {
+			return fmt.Errorf("branch list can't contain an empty branch")
+		}
+	}
+	for _, pattern := range d.BranchPatterns() {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid branch pattern %q: %w", pattern, err)
+		}
+	}
+	if platform, path := SplitDestinationRepo(d.Repo); platform == SourcePlatformBitbucket && !isWorkspaceRepo(path) {
+		return fmt.Errorf("bitbucket repo must be \"%sworkspace/repo\", got %q", BitbucketRepoPrefix, d.Repo)
+	}
 	return nil
 }
 
@@ -832,6 +2145,12 @@ func (t *Transformation) Validate() error {
 		return fmt.Errorf("only one of move, copy, glob, or regex can be specified")
 	}
 
+	if t.Sync {
+		if err := t.validateSync(); err != nil {
+			return err
+		}
+	}
+
 	// Validate the specific transformation type
 	if t.Move != nil {
 		return t.Move.Validate()
@@ -849,6 +2168,77 @@ func (t *Transformation) Validate() error {
 	return nil
 }
 
+// validateSync checks that a transformation with sync enabled maps one directory onto another,
+// so the destination directory it clears is well defined.
+func (t *Transformation) validateSync() error {
+	if t.Move == nil && t.Glob == nil {
+		return fmt.Errorf("sync is only supported for move and glob transformations")
+	}
+	if _, destRoot := t.SyncRoots(); destRoot == "" {
+		return fmt.Errorf("sync requires a destination directory; it can't clear the repository root")
+	}
+	return nil
+}
+
+// SyncRoots returns the source and destination directories a sync transformation keeps in step.
+// For move transformations these are "from" and "to". For glob transformations they are the
+// directories before the first wildcard in the pattern and before the first variable in the
+// transform, e.g. pattern "mflix/server/**/*.js" with transform "server/${relative_path}" gives
+// "mflix/server" and "server". Returns empty strings for other transformation types.
+func (t *Transformation) SyncRoots() (sourceRoot, destRoot string) {
+	switch {
+	case t.Move != nil:
+		return NormalizePathPrefix(t.Move.From), NormalizePathPrefix(t.Move.To)
+	case t.Glob != nil:
+		return staticDirPrefix(t.Glob.Pattern, "*?[{"), staticDirPrefix(t.Glob.Transform, "$")
+	default:
+		return "", ""
+	}
+}
+
+// SourcePathPrefix returns a string every source path the transformation matches starts with, or "" if
+// it can match any path. It's the "from" path of move and copy transformations, the part of a glob pattern
+// before the first wildcard, and the literal prefix of a regex pattern anchored with "^". Regex patterns
+// that aren't anchored can match anywhere in a path, so they give "".
+func (t *Transformation) SourcePathPrefix() string {
+	switch {
+	case t.Move != nil:
+		return NormalizePathPrefix(t.Move.From)
+	case t.Copy != nil:
+		return t.Copy.From
+	case t.Glob != nil:
+		if i := strings.IndexAny(t.Glob.Pattern, "*?[{\\"); i >= 0 {
+			return t.Glob.Pattern[:i]
+		}
+		return t.Glob.Pattern
+	case t.Regex != nil:
+		if !strings.HasPrefix(t.Regex.Pattern, "^") {
+			return ""
+		}
+		re, err := regexp.Compile(t.Regex.Pattern)
+		if err != nil {
+			return ""
+		}
+		prefix, _ := re.LiteralPrefix()
+		return prefix
+	default:
+		return ""
+	}
+}
+
+// staticDirPrefix returns the directory part of p that comes before the first of the special characters
+func staticDirPrefix(p, special string) string {
+	if i := strings.IndexAny(p, special); i >= 0 {
+		p = p[:i]
+		if j := strings.LastIndex(p, "/"); j >= 0 {
+			p = p[:j]
+		} else {
+			p = ""
+		}
+	}
+	return NormalizePathPrefix(p)
+}
+
 // Validate validates a move transformation
 func (m *MoveTransform) Validate() error {
 	if m.From == "" {
@@ -857,9 +2247,72 @@ func (m *MoveTransform) Validate() error {
 	if m.To == "" {
 		return fmt.Errorf("to is required")
 	}
+	if err := validateRepoPath("from", m.From); err != nil {
+		return err
+	}
+	if err := validateRepoPath("to", m.To); err != nil {
+		return err
+	}
+	if strings.ContainsAny(m.From, "*?[") {
+		return fmt.Errorf("from %q contains glob characters; use a glob transformation instead", m.From)
+	}
+	if m.StripPrefix != "" {
+		if err := validateRepoPath("strip_prefix", m.StripPrefix); err != nil {
+			return err
+		}
+		if _, ok := StripPathPrefix(NormalizePathPrefix(m.From), m.StripPrefix); !ok {
+			return fmt.Errorf("strip_prefix %q must be %q or one of its parent directories", m.StripPrefix, m.From)
+		}
+	}
 	return nil
 }
 
+// validateRepoPath checks that a transformation path is relative to the repository root
+// and does not step outside it.
+func validateRepoPath(field, p string) error {
+	if strings.HasPrefix(p, "/") {
+		return fmt.Errorf("%s %q must be relative to the repository root", field, p)
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return fmt.Errorf("%s %q must not contain '..'", field, p)
+		}
+	}
+	return nil
+}
+
+// NormalizePathPrefix cleans a directory prefix so it can be compared against repository paths,
+// e.g. "./examples/" -> "examples"
+func NormalizePathPrefix(prefix string) string {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return ""
+	}
+	cleaned := path.Clean(prefix)
+	if cleaned == "." {
+		return ""
+	}
+	return strings.TrimPrefix(cleaned, "/")
+}
+
+// StripPathPrefix removes a directory prefix from a file path. Unlike strings.TrimPrefix, the prefix
+// only matches whole path segments, so "examples" matches "examples/go/main.go" but not
+// "examples-old/main.go". Returns the remaining relative path ("" if filePath equals the prefix)
+// and whether filePath falls under the prefix. An empty prefix matches every path.
+func StripPathPrefix(filePath, prefix string) (string, bool) {
+	prefix = NormalizePathPrefix(prefix)
+	if prefix == "" {
+		return filePath, true
+	}
+	if filePath == prefix {
+		return "", true
+	}
+	if strings.HasPrefix(filePath, prefix+"/") {
+		return strings.TrimPrefix(filePath, prefix+"/"), true
+	}
+	return "", false
+}
+
 // Validate validates a copy transformation
 func (c *CopyTransform) Validate() error {
 	if c.From == "" {
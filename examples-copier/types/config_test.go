@@ -2,9 +2,11 @@ package types
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 // TestYAMLConfig_SetDefaults_CommitStrategyMerging tests that commit strategy
@@ -270,3 +272,1054 @@ func TestWorkflowConfig_SetDefaults_CommitStrategyMerging(t *testing.T) {
 	assert.False(t, workflow2.CommitStrategy.AutoMerge)
 }
 
+
+func TestMoveTransform_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		move    MoveTransform
+		wantErr string
+	}{
+		{name: "valid", move: MoveTransform{From: "examples", To: "code-examples"}},
+		{name: "valid with strip_prefix", move: MoveTransform{From: "examples/go", To: "code", StripPrefix: "examples"}},
+		{name: "strip_prefix equal to from", move: MoveTransform{From: "examples/go/", To: "code", StripPrefix: "examples/go"}},
+		{name: "missing from", move: MoveTransform{To: "code"}, wantErr: "from is required"},
+		{name: "missing to", move: MoveTransform{From: "examples"}, wantErr: "to is required"},
+		{name: "absolute from", move: MoveTransform{From: "/examples", To: "code"}, wantErr: "must be relative"},
+		{name: "parent directory in from", move: MoveTransform{From: "../examples", To: "code"}, wantErr: "must not contain '..'"},
+		{name: "parent directory in to", move: MoveTransform{From: "examples", To: "code/../.."}, wantErr: "must not contain '..'"},
+		{name: "glob characters in from", move: MoveTransform{From: "examples/*", To: "code"}, wantErr: "glob characters"},
+		{name: "strip_prefix not containing from", move: MoveTransform{From: "examples/go", To: "code", StripPrefix: "src"}, wantErr: "strip_prefix"},
+		{name: "strip_prefix partial segment", move: MoveTransform{From: "examples/go", To: "code", StripPrefix: "exam"}, wantErr: "strip_prefix"},
+		{name: "strip_prefix below from", move: MoveTransform{From: "examples", To: "code", StripPrefix: "examples/go"}, wantErr: "strip_prefix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.move.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestTransformation_ValidateSync(t *testing.T) {
+	tests := []struct {
+		name           string
+		transformation Transformation
+		wantErr        string
+	}{
+		{name: "move", transformation: Transformation{Move: &MoveTransform{From: "app/server", To: "server"}, Sync: true}},
+		{name: "glob", transformation: Transformation{Glob: &GlobTransform{Pattern: "app/server/**", Transform: "server/${relative_path}"}, Sync: true}},
+		{name: "copy", transformation: Transformation{Copy: &CopyTransform{From: "a.go", To: "b.go"}, Sync: true}, wantErr: "only supported for move and glob"},
+		{name: "regex", transformation: Transformation{Regex: &RegexTransform{Pattern: "^(?P<f>.*)$", Transform: "${f}"}, Sync: true}, wantErr: "only supported for move and glob"},
+		{name: "glob into repo root", transformation: Transformation{Glob: &GlobTransform{Pattern: "app/**", Transform: "${relative_path}"}, Sync: true}, wantErr: "repository root"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.transformation.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestTransformation_SyncRoots(t *testing.T) {
+	tests := []struct {
+		name           string
+		transformation Transformation
+		wantSource     string
+		wantDest       string
+	}{
+		{name: "move", transformation: Transformation{Move: &MoveTransform{From: "app/server/", To: "server"}}, wantSource: "app/server", wantDest: "server"},
+		{name: "glob", transformation: Transformation{Glob: &GlobTransform{Pattern: "mflix/server/**/*.js", Transform: "server/${relative_path}"}}, wantSource: "mflix/server", wantDest: "server"},
+		{name: "glob with partial segment", transformation: Transformation{Glob: &GlobTransform{Pattern: "mflix/serv*/**", Transform: "samples/v${version}/${relative_path}"}}, wantSource: "mflix", wantDest: "samples"},
+		{name: "copy", transformation: Transformation{Copy: &CopyTransform{From: "a.go", To: "b.go"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, dest := tt.transformation.SyncRoots()
+			assert.Equal(t, tt.wantSource, source)
+			assert.Equal(t, tt.wantDest, dest)
+		})
+	}
+}
+
+func TestTransformation_SourcePathPrefix(t *testing.T) {
+	tests := []struct {
+		name           string
+		transformation Transformation
+		want           string
+	}{
+		{name: "move", transformation: Transformation{Move: &MoveTransform{From: "./app/server/", To: "server"}}, want: "app/server"},
+		{name: "copy", transformation: Transformation{Copy: &CopyTransform{From: "app/README.md", To: "README.md"}}, want: "app/README.md"},
+		{name: "glob", transformation: Transformation{Glob: &GlobTransform{Pattern: "mflix/serv*/**/*.js", Transform: "${relative_path}"}}, want: "mflix/serv"},
+		{name: "glob without wildcards", transformation: Transformation{Glob: &GlobTransform{Pattern: "mflix/app.js", Transform: "app.js"}}, want: "mflix/app.js"},
+		{name: "glob starting with wildcard", transformation: Transformation{Glob: &GlobTransform{Pattern: "**/*.go", Transform: "${filename}"}}},
+		{name: "anchored regex", transformation: Transformation{Regex: &RegexTransform{Pattern: `^mflix/server/(?P<file>.+)$`, Transform: "${file}"}}, want: "mflix/server/"},
+		{name: "unanchored regex", transformation: Transformation{Regex: &RegexTransform{Pattern: `mflix/server/(?P<file>.+)$`, Transform: "${file}"}}},
+		{name: "case-insensitive regex", transformation: Transformation{Regex: &RegexTransform{Pattern: `^(?i)mflix/(?P<file>.+)$`, Transform: "${file}"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.transformation.SourcePathPrefix())
+		})
+	}
+}
+
+func TestStripPathPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		prefix   string
+		wantRel  string
+		wantOK   bool
+	}{
+		{name: "file under prefix", filePath: "examples/go/main.go", prefix: "examples", wantRel: "go/main.go", wantOK: true},
+		{name: "trailing slash on prefix", filePath: "examples/go/main.go", prefix: "examples/", wantRel: "go/main.go", wantOK: true},
+		{name: "leading dot slash on prefix", filePath: "examples/go/main.go", prefix: "./examples", wantRel: "go/main.go", wantOK: true},
+		{name: "exact match", filePath: "examples/README.md", prefix: "examples/README.md", wantRel: "", wantOK: true},
+		{name: "sibling with shared prefix", filePath: "examples-old/main.go", prefix: "examples", wantOK: false},
+		{name: "outside prefix", filePath: "src/main.go", prefix: "examples", wantOK: false},
+		{name: "empty prefix matches everything", filePath: "src/main.go", prefix: "", wantRel: "src/main.go", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rel, ok := StripPathPrefix(tt.filePath, tt.prefix)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantRel, rel)
+		})
+	}
+}
+
+func TestSecretScanConfig(t *testing.T) {
+	disabled := false
+	enabled := true
+
+	var unset *SecretScanConfig
+	assert.True(t, unset.IsEnabled(), "scanning should be enabled when not configured")
+	assert.True(t, (&SecretScanConfig{}).IsEnabled())
+	assert.True(t, (&SecretScanConfig{Enabled: &enabled}).IsEnabled())
+	assert.False(t, (&SecretScanConfig{Enabled: &disabled}).IsEnabled())
+
+	config := &SecretScanConfig{AllowPaths: []string{`^testdata/`, `\.pem$`}}
+	assert.NoError(t, config.Validate())
+	assert.True(t, config.IsAllowed("testdata/keys.txt"))
+	assert.True(t, config.IsAllowed("examples/fake.pem"))
+	assert.False(t, config.IsAllowed("examples/main.go"))
+	assert.False(t, unset.IsAllowed("testdata/keys.txt"))
+
+	assert.Error(t, (&SecretScanConfig{AllowPaths: []string{""}}).Validate())
+	assert.Error(t, (&SecretScanConfig{AllowPaths: []string{"[invalid"}}).Validate())
+}
+
+func TestDeleteOrphansConfig(t *testing.T) {
+	var unset *DeleteOrphansConfig
+	assert.False(t, unset.IsEnabled())
+	assert.Equal(t, DefaultMaxOrphanDeletions, unset.GetMaxDeletions())
+	assert.True(t, (&DeleteOrphansConfig{Enabled: true}).IsEnabled())
+	assert.Equal(t, 5, (&DeleteOrphansConfig{Enabled: true, MaxDeletions: 5}).GetMaxDeletions())
+
+	assert.NoError(t, (&DeleteOrphansConfig{Enabled: true}).Validate())
+	assert.Error(t, (&DeleteOrphansConfig{Enabled: true, MaxDeletions: -1}).Validate())
+}
+
+func TestWorkflow_UnmarshalYAML_DeleteOrphans(t *testing.T) {
+	input := `
+name: docs
+source:
+  repo: org/src
+destination:
+  repo: org/dest
+transformations:
+  - move: { from: "src", to: "dest" }
+delete_orphans:
+  enabled: true
+  max_deletions: 5
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	assert.True(t, workflow.DeleteOrphans.IsEnabled())
+	assert.Equal(t, 5, workflow.DeleteOrphans.GetMaxDeletions())
+}
+
+func TestLFSConfig(t *testing.T) {
+	var unset *LFSConfig
+	assert.Equal(t, LFSModeSkip, unset.GetMode())
+	assert.Equal(t, LFSModePointer, (&LFSConfig{Mode: LFSModePointer}).GetMode())
+
+	assert.NoError(t, (&LFSConfig{}).Validate())
+	assert.NoError(t, (&LFSConfig{Mode: LFSModePointer, URL: "https://lfs.example.com/samples"}).Validate())
+	assert.Error(t, (&LFSConfig{Mode: "copy"}).Validate())
+	assert.Error(t, (&LFSConfig{URL: "https://lfs.example.com/samples"}).Validate(), "url needs pointer mode")
+	assert.Error(t, (&LFSConfig{Mode: LFSModePointer, URL: "http://lfs.example.com"}).Validate())
+
+	input := `
+name: assets
+source:
+  repo: org/src
+destination:
+  repo: org/dest
+transformations:
+  - move: { from: "src", to: "dest" }
+lfs:
+  mode: pointer
+  url: https://lfs.example.com/samples
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	assert.Equal(t, LFSModePointer, workflow.LFS.GetMode())
+	assert.Equal(t, "https://lfs.example.com/samples", workflow.LFS.URL)
+	assert.NoError(t, workflow.Validate())
+}
+
+func TestMirrorConfig(t *testing.T) {
+	var unset *MirrorConfig
+	assert.False(t, unset.IsEnabled())
+	assert.Equal(t, "go-docs", (&MirrorConfig{Enabled: true}).Dir("mongodb/go-docs"))
+	assert.Equal(t, "samples", (&MirrorConfig{Enabled: true}).Dir("bitbucket:team/samples"))
+	assert.Equal(t, "atlas/go", (&MirrorConfig{Enabled: true, Product: "atlas", Language: "go"}).Dir("mongodb/go-docs"))
+
+	assert.NoError(t, (&MirrorConfig{Enabled: true, Product: "atlas", Language: "go"}).Validate())
+	assert.Error(t, (&MirrorConfig{Enabled: true, Product: "atlas/sdk"}).Validate())
+	assert.Error(t, (&MirrorConfig{Enabled: true, Language: ".."}).Validate())
+
+	input := `
+name: go
+source:
+  repo: org/src
+destination:
+  repo: org/go-docs
+transformations:
+  - move: { from: "src", to: "dest" }
+mirror:
+  enabled: true
+  product: atlas
+  language: go
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	assert.True(t, workflow.Mirror.IsEnabled())
+	assert.Equal(t, "atlas/go", workflow.Mirror.Dir(workflow.Destination.Repo))
+}
+
+func TestLimitsConfig(t *testing.T) {
+	var unset *LimitsConfig
+	assert.Equal(t, 0, unset.GetMaxFiles())
+	assert.Equal(t, int64(0), unset.GetMaxTotalBytes())
+	assert.Equal(t, int64(0), unset.GetMaxSingleFileBytes())
+
+	assert.NoError(t, (&LimitsConfig{MaxFiles: 100, MaxTotalBytes: 1 << 20, MaxSingleFileBytes: 1 << 10}).Validate())
+	assert.NoError(t, (&LimitsConfig{MaxSingleFileBytes: 1 << 10}).Validate())
+	assert.Error(t, (&LimitsConfig{MaxFiles: -1}).Validate())
+	assert.Error(t, (&LimitsConfig{MaxTotalBytes: -1}).Validate())
+	assert.Error(t, (&LimitsConfig{MaxTotalBytes: 10, MaxSingleFileBytes: 20}).Validate())
+
+	input := `
+name: go
+source:
+  repo: org/src
+destination:
+  repo: org/go-docs
+transformations:
+  - move: { from: "src", to: "dest" }
+limits:
+  max_files: 50
+  max_total_bytes: 10485760
+  max_single_file_bytes: 1048576
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	assert.Equal(t, 50, workflow.Limits.GetMaxFiles())
+	assert.Equal(t, int64(10485760), workflow.Limits.GetMaxTotalBytes())
+	assert.Equal(t, int64(1048576), workflow.Limits.GetMaxSingleFileBytes())
+	assert.NoError(t, workflow.Validate())
+}
+
+func TestChangelogConfig(t *testing.T) {
+	var unset *ChangelogConfig
+	assert.False(t, unset.IsEnabled())
+	assert.Equal(t, DefaultChangelogPath, unset.GetPath())
+	assert.Equal(t, "docs/SYNC-LOG.md", (&ChangelogConfig{Enabled: true, Path: "docs/SYNC-LOG.md"}).GetPath())
+
+	assert.NoError(t, (&ChangelogConfig{Enabled: true}).Validate())
+	assert.NoError(t, (&ChangelogConfig{Enabled: true, Path: "docs/SYNC-LOG.md"}).Validate())
+	assert.Error(t, (&ChangelogConfig{Enabled: true, Path: "/CHANGELOG.md"}).Validate())
+	assert.Error(t, (&ChangelogConfig{Enabled: true, Path: "docs/"}).Validate())
+	assert.Error(t, (&ChangelogConfig{Enabled: true, Path: "../CHANGELOG.md"}).Validate())
+	assert.Error(t, (&ChangelogConfig{Enabled: true, Path: "docs/./CHANGELOG.md"}).Validate())
+
+	input := `
+name: go
+source:
+  repo: org/src
+destination:
+  repo: org/go-docs
+transformations:
+  - move: { from: "src", to: "dest" }
+changelog:
+  enabled: true
+  path: SYNC-LOG.md
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	assert.True(t, workflow.Changelog.IsEnabled())
+	assert.Equal(t, "SYNC-LOG.md", workflow.Changelog.GetPath())
+	assert.NoError(t, workflow.Validate())
+}
+
+func TestApprovalGateConfig(t *testing.T) {
+	var unset *ApprovalGateConfig
+	assert.Equal(t, 24*time.Hour, unset.GetTimeout())
+	assert.Equal(t, ApprovalOnTimeoutLeaveOpen, unset.GetOnTimeout())
+	assert.Equal(t, 10*time.Minute, (&ApprovalGateConfig{TimeoutMinutes: 10}).GetTimeout())
+
+	assert.NoError(t, (&ApprovalGateConfig{Checks: []string{"build"}}).Validate())
+	assert.NoError(t, (&ApprovalGateConfig{Reviewers: []string{"docs-lead"}, OnTimeout: ApprovalOnTimeoutClose}).Validate())
+	assert.ErrorContains(t, (&ApprovalGateConfig{}).Validate(), "at least one of checks or reviewers")
+	assert.Error(t, (&ApprovalGateConfig{Checks: []string{" "}}).Validate())
+	assert.Error(t, (&ApprovalGateConfig{Reviewers: []string{""}}).Validate())
+	assert.Error(t, (&ApprovalGateConfig{Checks: []string{"build"}, TimeoutMinutes: -1}).Validate())
+	assert.ErrorContains(t, (&ApprovalGateConfig{Checks: []string{"build"}, OnTimeout: "wait"}).Validate(), "invalid on_timeout")
+
+	input := `
+name: app
+source:
+  repo: org/src
+destination:
+  repo: bitbucket:workspace/app
+transformations:
+  - move: { from: "src", to: "dest" }
+commit_strategy:
+  type: pull_request
+  auto_merge: true
+  approval_gate:
+    reviewers: [docs-lead]
+    on_timeout: merge
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	require.NotNil(t, workflow.CommitStrategy.ApprovalGate)
+	assert.Equal(t, ApprovalOnTimeoutMerge, workflow.CommitStrategy.ApprovalGate.GetOnTimeout())
+	assert.ErrorContains(t, workflow.Validate(), "only supported for GitHub destinations")
+}
+
+func TestReviewersConfig(t *testing.T) {
+	assert.NoError(t, (&ReviewersConfig{CodeOwners: true}).Validate())
+	assert.NoError(t, (&ReviewersConfig{Users: []string{"@docs-lead"}, Teams: []string{"docs"}}).Validate())
+	assert.ErrorContains(t, (&ReviewersConfig{}).Validate(), "at least one of codeowners, users, or teams")
+	assert.Error(t, (&ReviewersConfig{Users: []string{"@"}}).Validate())
+	assert.Error(t, (&ReviewersConfig{Teams: []string{" "}}).Validate())
+	assert.ErrorContains(t, (&ReviewersConfig{Teams: []string{"org/docs"}}).Validate(), "without the org")
+
+	input := `
+name: app
+source:
+  repo: org/src
+destination:
+  repo: bitbucket:workspace/app
+transformations:
+  - move: { from: "src", to: "dest" }
+commit_strategy:
+  type: pull_request
+  reviewers:
+    codeowners: true
+    teams: [docs]
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	require.NotNil(t, workflow.CommitStrategy.Reviewers)
+	assert.True(t, workflow.CommitStrategy.Reviewers.CodeOwners)
+	assert.Equal(t, []string{"docs"}, workflow.CommitStrategy.Reviewers.Teams)
+	assert.ErrorContains(t, workflow.Validate(), "reviewers: only supported for GitHub destinations")
+}
+
+func TestArtifactsConfig(t *testing.T) {
+	var unset *ArtifactsConfig
+	assert.False(t, unset.MatchesWorkflow("Build"))
+	assert.True(t, (&ArtifactsConfig{Names: []string{"bundle"}}).MatchesWorkflow("Build"))
+	assert.False(t, (&ArtifactsConfig{Workflows: []string{"Docs"}, Names: []string{"bundle"}}).MatchesWorkflow("Build"))
+
+	assert.NoError(t, (&ArtifactsConfig{Names: []string{"bundle"}}).Validate())
+	assert.ErrorContains(t, (&ArtifactsConfig{}).Validate(), "names is required")
+	assert.Error(t, (&ArtifactsConfig{Names: []string{"dist/bundle"}}).Validate())
+	assert.Error(t, (&ArtifactsConfig{Names: []string{"bundle"}, Workflows: []string{""}}).Validate())
+
+	parse := func(input string) Workflow {
+		var workflow Workflow
+		require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+		return workflow
+	}
+	base := `
+name: app
+source:
+  repo: org/src
+destination:
+  repo: org/app
+transformations:
+  - move: { from: "bundle", to: "examples" }
+`
+	workflow := parse(base + `
+trigger: workflow_run
+artifacts:
+  workflows: [Build examples]
+  names: [bundle]
+`)
+	assert.NoError(t, workflow.Validate())
+	assert.True(t, workflow.Trigger.Has(WorkflowTriggerWorkflowRun))
+	assert.Equal(t, []string{"bundle"}, workflow.Artifacts.Names)
+
+	workflow = parse(base + "trigger: workflow_run\n")
+	assert.ErrorContains(t, workflow.Validate(), "requires artifacts")
+	workflow = parse(base + "artifacts: { names: [bundle] }\n")
+	assert.ErrorContains(t, workflow.Validate(), "only used with the workflow_run trigger")
+}
+
+func TestReleaseConfig(t *testing.T) {
+	var unset *ReleaseConfig
+	assert.True(t, unset.MatchesRelease("v1.2.0", false))
+	assert.False(t, unset.MatchesRelease("v1.3.0-beta1", true))
+	tags := &ReleaseConfig{Tags: []string{"v*"}}
+	assert.True(t, tags.MatchesRelease("v1.2.0", false))
+	assert.False(t, tags.MatchesRelease("nightly", false))
+	assert.False(t, tags.MatchesRelease("v1.3.0-beta1", true))
+	assert.True(t, (&ReleaseConfig{Prereleases: true}).MatchesRelease("v1.3.0-beta1", true))
+
+	assert.NoError(t, tags.Validate())
+	assert.Error(t, (&ReleaseConfig{Tags: []string{"v["}}).Validate())
+	assert.Error(t, (&ReleaseConfig{Tags: []string{" "}}).Validate())
+
+	parse := func(input string) Workflow {
+		var workflow Workflow
+		require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+		return workflow
+	}
+	base := `
+name: app
+source:
+  repo: org/src
+destination:
+  repo: org/app
+transformations:
+  - move: { from: "examples", to: "examples/${release_tag}" }
+`
+	workflow := parse(base + `
+trigger: release
+release:
+  tags: ["v*"]
+  prereleases: true
+`)
+	assert.NoError(t, workflow.Validate())
+	assert.True(t, workflow.Trigger.Has(WorkflowTriggerRelease))
+	assert.True(t, workflow.Release.Prereleases)
+
+	workflow = parse(base + "release: { tags: [v*] }\n")
+	assert.ErrorContains(t, workflow.Validate(), "only used with the release trigger")
+	workflow = parse(base + "trigger: release\n")
+	workflow.Source.Platform = SourcePlatformGitLab
+	assert.ErrorContains(t, workflow.Validate(), "only supported for GitHub sources")
+}
+
+func TestVerifyBuildConfig(t *testing.T) {
+	var unset *VerifyBuildConfig
+	assert.False(t, unset.IsEnabled())
+	assert.Equal(t, 30*time.Minute, unset.GetTimeout())
+	assert.Equal(t, 10*time.Minute, (&VerifyBuildConfig{Enabled: true, TimeoutMinutes: 10}).GetTimeout())
+
+	assert.NoError(t, (&VerifyBuildConfig{Enabled: true, Checks: []string{"build"}}).Validate())
+	assert.Error(t, (&VerifyBuildConfig{Enabled: true, TimeoutMinutes: -1}).Validate())
+	assert.Error(t, (&VerifyBuildConfig{Enabled: true, Checks: []string{" "}}).Validate())
+
+	input := `
+name: app
+source:
+  repo: org/src
+destination:
+  repo: bitbucket:workspace/app
+transformations:
+  - move: { from: "src", to: "dest" }
+verify_build:
+  enabled: true
+  checks: [build, test]
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	assert.True(t, workflow.VerifyBuild.IsEnabled())
+	assert.Equal(t, []string{"build", "test"}, workflow.VerifyBuild.Checks)
+	assert.ErrorContains(t, workflow.Validate(), "only supported for GitHub destinations")
+
+	workflow.Destination.Repo = "org/app"
+	assert.NoError(t, workflow.Validate())
+}
+
+func TestWorkflowConfig_SetDefaults_SecretScan(t *testing.T) {
+	disabled := false
+	workflowConfig := &WorkflowConfig{
+		Defaults: &Defaults{
+			SecretScan: &SecretScanConfig{AllowPaths: []string{`^fixtures/`}},
+		},
+		Workflows: []Workflow{
+			{Name: "inherits"},
+			{Name: "overrides", SecretScan: &SecretScanConfig{Enabled: &disabled}},
+		},
+	}
+
+	workflowConfig.SetDefaults()
+
+	require.NotNil(t, workflowConfig.Workflows[0].SecretScan)
+	assert.Equal(t, []string{`^fixtures/`}, workflowConfig.Workflows[0].SecretScan.AllowPaths)
+	assert.False(t, workflowConfig.Workflows[1].SecretScan.IsEnabled())
+}
+
+func TestWorkflow_UnmarshalYAML_SecretScan(t *testing.T) {
+	var workflow Workflow
+	err := yaml.Unmarshal([]byte(`
+name: "with-secret-scan"
+source:
+  repo: "org/source"
+destination:
+  repo: "org/dest"
+transformations:
+  - move: { from: "src", to: "dest" }
+secret_scan:
+  enabled: false
+  allow_paths: ["^fixtures/"]
+`), &workflow)
+	require.NoError(t, err)
+
+	require.NotNil(t, workflow.SecretScan)
+	assert.False(t, workflow.SecretScan.IsEnabled())
+	assert.Equal(t, []string{"^fixtures/"}, workflow.SecretScan.AllowPaths)
+}
+
+func TestSchemaValidationRule(t *testing.T) {
+	rule := SchemaValidationRule{Files: "configs/**/*.json", Schema: "schemas/app.schema.json"}
+	assert.NoError(t, rule.Validate())
+	assert.True(t, rule.Matches("configs/app.json"))
+	assert.True(t, rule.Matches("configs/nested/app.json"))
+	assert.False(t, rule.Matches("configs/app.yaml"))
+
+	assert.Error(t, (&SchemaValidationRule{Schema: "schemas/app.schema.json"}).Validate())
+	assert.Error(t, (&SchemaValidationRule{Files: "configs/[", Schema: "schemas/app.schema.json"}).Validate())
+	assert.Error(t, (&SchemaValidationRule{Files: "**/*.json"}).Validate())
+	assert.Error(t, (&SchemaValidationRule{Files: "**/*.json", Schema: "../schemas/app.schema.json"}).Validate())
+}
+
+func TestSource_ValidatePlatform(t *testing.T) {
+	source := Source{Repo: "docs/examples/python"}
+	require.NoError(t, source.Validate())
+	assert.Equal(t, SourcePlatformGitHub, source.GetPlatform())
+	assert.Equal(t, "main", source.Branch)
+
+	source = Source{Repo: "docs/examples/python", Platform: SourcePlatformGitLab}
+	require.NoError(t, source.Validate())
+	assert.Equal(t, SourcePlatformGitLab, source.GetPlatform())
+
+	source = Source{Repo: "docs/examples", Platform: SourcePlatformBitbucket}
+	require.NoError(t, source.Validate())
+	assert.Equal(t, SourcePlatformBitbucket, source.GetPlatform())
+
+	// Bitbucket repos are "workspace/repo"
+	source = Source{Repo: "docs/examples/python", Platform: SourcePlatformBitbucket}
+	assert.Error(t, source.Validate())
+
+	source = Source{Repo: "docs/examples", Platform: "gitea"}
+	err := source.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "platform")
+}
+
+func TestSource_BranchPattern(t *testing.T) {
+	source := Source{Repo: "org/examples", Branch: "release/*"}
+	require.NoError(t, source.Validate())
+	assert.True(t, source.IsBranchPattern())
+	assert.True(t, source.MatchesBranch("release/8.0"))
+	assert.False(t, source.MatchesBranch("release/8.0/hotfix"))
+	assert.False(t, source.MatchesBranch("main"))
+	assert.Equal(t, map[string]string{"source_branch": "release/8.0", "source_branch_suffix": "8.0"},
+		source.BranchVariables("release/8.0"))
+
+	source = Source{Repo: "org/examples", Branch: "v[0-9]*"}
+	require.NoError(t, source.Validate())
+	assert.True(t, source.MatchesBranch("v8.0"))
+	assert.Equal(t, "8.0", source.BranchVariables("v8.0")["source_branch_suffix"])
+
+	source = Source{Repo: "org/examples", Branch: "main"}
+	assert.False(t, source.IsBranchPattern())
+	assert.True(t, source.MatchesBranch("main"))
+	assert.False(t, source.MatchesBranch("main2"))
+	assert.Equal(t, "", source.BranchVariables("main")["source_branch_suffix"])
+
+	source = Source{Repo: "org/examples", Branch: "release/["}
+	err := source.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid branch pattern")
+}
+
+func TestDestination_Platform(t *testing.T) {
+	dest := Destination{Repo: "mongodb/docs-code-examples"}
+	require.NoError(t, dest.Validate())
+	assert.Equal(t, SourcePlatformGitHub, dest.GetPlatform())
+
+	dest = Destination{Repo: "bitbucket:docs/examples"}
+	require.NoError(t, dest.Validate())
+	assert.Equal(t, SourcePlatformBitbucket, dest.GetPlatform())
+	platform, path := SplitDestinationRepo(dest.Repo)
+	assert.Equal(t, SourcePlatformBitbucket, platform)
+	assert.Equal(t, "docs/examples", path)
+
+	for _, repo := range []string{"bitbucket:docs", "bitbucket:/examples", "bitbucket:docs/examples/python"} {
+		dest = Destination{Repo: repo}
+		assert.Error(t, dest.Validate(), repo)
+	}
+}
+
+func TestDestination_Branches(t *testing.T) {
+	tests := []struct {
+		yaml     string
+		branch   string
+		branches []string
+	}{
+		{yaml: `{repo: org/docs, branch: v8.0}`, branch: "v8.0"},
+		{yaml: `{repo: org/docs, branch: 8.0}`, branch: "8.0"},
+		{yaml: `{repo: org/docs, branch: [v7.0, v8.0]}`, branches: []string{"v7.0", "v8.0"}},
+		{yaml: `{repo: org/docs, branch: [v8.0]}`, branch: "v8.0"},
+		{yaml: `{repo: org/docs}`},
+	}
+	for _, tt := range tests {
+		var dest Destination
+		require.NoError(t, yaml.Unmarshal([]byte(tt.yaml), &dest), tt.yaml)
+		assert.Equal(t, "org/docs", dest.Repo, tt.yaml)
+		assert.Equal(t, tt.branch, dest.Branch, tt.yaml)
+		assert.Equal(t, tt.branches, dest.Branches, tt.yaml)
+	}
+
+	var dest Destination
+	assert.Error(t, yaml.Unmarshal([]byte(`{repo: org/docs, branch: {name: main}}`), &dest))
+
+	dest = Destination{Repo: "org/docs", Branches: []string{"v7.0", "v8.*"}}
+	require.NoError(t, dest.Validate())
+	assert.True(t, dest.IsMultiBranch())
+	assert.True(t, dest.HasBranchPattern())
+	assert.True(t, dest.MatchesBranch("v7.0"))
+	assert.True(t, dest.MatchesBranch("v8.2"))
+	assert.False(t, dest.MatchesBranch("v6.0"))
+	assert.Empty(t, dest.Branch, "listed branches aren't replaced by the default")
+
+	out, err := yaml.Marshal(dest)
+	require.NoError(t, err)
+	var roundTripped Destination
+	require.NoError(t, yaml.Unmarshal(out, &roundTripped))
+	assert.Equal(t, dest, roundTripped)
+
+	dest = Destination{Repo: "org/docs", Branch: "main"}
+	assert.False(t, dest.IsMultiBranch())
+	dest = Destination{Repo: "org/docs", Branch: "release/*"}
+	assert.True(t, dest.IsMultiBranch())
+
+	for _, branches := range [][]string{{"v7.0", ""}, {"v["}} {
+		dest = Destination{Repo: "org/docs", Branches: branches}
+		assert.Error(t, dest.Validate(), branches)
+	}
+
+	workflow := Workflow{
+		Name:            "versioned-docs",
+		Source:          Source{Repo: "org/src", Branch: "main"},
+		Destination:     Destination{Repo: "org/docs", Branches: []string{"v7.0", "v8.0"}},
+		Transformations: []Transformation{{Move: &MoveTransform{From: "src", To: "dest"}}},
+		CommitStrategy:  &CommitStrategyConfig{Type: "pull_request"},
+	}
+	require.NoError(t, workflow.Validate())
+	workflow.CommitStrategy.Type = "branch"
+	err = workflow.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "more than one destination branch")
+}
+
+func TestWorkflowTriggers(t *testing.T) {
+	var triggers WorkflowTriggers
+	assert.True(t, triggers.Has(WorkflowTriggerPRMerged), "workflows run on merged PRs by default")
+	assert.False(t, triggers.Has(WorkflowTriggerPush))
+
+	tests := []struct {
+		yaml string
+		want WorkflowTriggers
+	}{
+		{yaml: `trigger: push`, want: WorkflowTriggers{WorkflowTriggerPush}},
+		{yaml: `trigger: both`, want: WorkflowTriggers{WorkflowTriggerPRMerged, WorkflowTriggerPush}},
+		{yaml: `trigger: [pr_merged, push]`, want: WorkflowTriggers{WorkflowTriggerPRMerged, WorkflowTriggerPush}},
+		{yaml: `trigger: chained`, want: WorkflowTriggers{WorkflowTriggerChained}},
+	}
+	for _, tt := range tests {
+		var workflow Workflow
+		require.NoError(t, yaml.Unmarshal([]byte(tt.yaml), &workflow), tt.yaml)
+		assert.Equal(t, tt.want, workflow.Trigger, tt.yaml)
+		require.NoError(t, workflow.Trigger.Validate())
+	}
+
+	assert.Error(t, WorkflowTriggers{"tag"}.Validate())
+
+	workflow := Workflow{
+		Name:            "gitlab-push",
+		Source:          Source{Repo: "group/project", Branch: "main", Platform: SourcePlatformGitLab},
+		Destination:     Destination{Repo: "org/dest", Branch: "main"},
+		Transformations: []Transformation{{Move: &MoveTransform{From: "src", To: "dest"}}},
+		Trigger:         WorkflowTriggers{WorkflowTriggerPush},
+	}
+	err := workflow.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only supported for GitHub")
+
+	workflow.Trigger = WorkflowTriggers{WorkflowTriggerChained}
+	err = workflow.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "isn't supported for GitLab sources")
+
+	workflow.Source = Source{Repo: "workspace/repo", Branch: "main", Platform: SourcePlatformBitbucket}
+	assert.NoError(t, workflow.Validate())
+}
+
+func TestWorkflow_IsDryRun(t *testing.T) {
+	on, off := true, false
+
+	assert.False(t, (&Workflow{}).IsDryRun(false))
+	assert.True(t, (&Workflow{}).IsDryRun(true))
+	assert.True(t, (&Workflow{DryRun: &on}).IsDryRun(false))
+	assert.False(t, (&Workflow{DryRun: &off}).IsDryRun(true))
+
+	var workflow Workflow
+	err := yaml.Unmarshal([]byte(`
+name: "dry-run"
+source:
+  repo: "org/source"
+destination:
+  repo: "org/dest"
+transformations:
+  - move: { from: "src", to: "dest" }
+dry_run: true
+`), &workflow)
+	require.NoError(t, err)
+	assert.True(t, workflow.IsDryRun(false))
+}
+
+func TestNotificationConfig(t *testing.T) {
+	off := false
+	t.Setenv("TEST_SLACK_WEBHOOK", "https://hooks.slack.com/services/T/B/X")
+
+	config := &NotificationConfig{SlackWebhookURL: "${TEST_SLACK_WEBHOOK}"}
+	require.NoError(t, config.Validate())
+	assert.Equal(t, "https://hooks.slack.com/services/T/B/X", config.WebhookURL())
+	assert.True(t, config.NotifyOnSuccess())
+	assert.False(t, (&NotificationConfig{OnSuccess: &off}).NotifyOnSuccess())
+
+	assert.NoError(t, (&NotificationConfig{SlackChannel: "#copier"}).Validate())
+	assert.Error(t, (&NotificationConfig{SlackWebhookURL: "http://hooks.slack.com/services/T/B/X"}).Validate())
+
+	globalDefaults := &Defaults{Notifications: &NotificationConfig{SlackChannel: "#global"}}
+	workflowConfig := &WorkflowConfig{
+		Workflows: []Workflow{
+			{Name: "inherits"},
+			{Name: "overrides", Notifications: &NotificationConfig{SlackChannel: "#team"}},
+		},
+	}
+	workflowConfig.ApplyGlobalDefaults(globalDefaults)
+	workflowConfig.SetDefaults()
+
+	assert.Equal(t, "#global", workflowConfig.Workflows[0].Notifications.SlackChannel)
+	assert.Equal(t, "#team", workflowConfig.Workflows[1].Notifications.SlackChannel)
+
+	var workflow Workflow
+	err := yaml.Unmarshal([]byte(`
+name: "with-notifications"
+source:
+  repo: "org/source"
+destination:
+  repo: "org/dest"
+transformations:
+  - move: { from: "src", to: "dest" }
+notifications:
+  slack_channel: "#copier"
+  on_success: false
+`), &workflow)
+	require.NoError(t, err)
+	require.NotNil(t, workflow.Notifications)
+	assert.Equal(t, "#copier", workflow.Notifications.SlackChannel)
+	assert.False(t, workflow.Notifications.NotifyOnSuccess())
+}
+
+func TestContentTransform(t *testing.T) {
+	transform := ContentTransform{Type: ContentTransformReplace, Files: "**/*.py", Pattern: `<password>`}
+	assert.NoError(t, transform.Validate())
+	assert.True(t, transform.Matches("examples/connect.py"))
+	assert.False(t, transform.Matches("examples/connect.js"))
+	assert.True(t, (&ContentTransform{Type: ContentTransformHeader, Header: "# Generated"}).Matches("any/file.go"))
+
+	assert.NoError(t, (&ContentTransform{Type: ContentTransformStripLines, Start: "start-internal", End: "end-internal"}).Validate())
+	assert.Error(t, (&ContentTransform{Pattern: "x"}).Validate())
+	assert.Error(t, (&ContentTransform{Type: "rename"}).Validate())
+	assert.Error(t, (&ContentTransform{Type: ContentTransformReplace}).Validate())
+	assert.Error(t, (&ContentTransform{Type: ContentTransformReplace, Pattern: "("}).Validate())
+	assert.Error(t, (&ContentTransform{Type: ContentTransformHeader}).Validate())
+	assert.Error(t, (&ContentTransform{Type: ContentTransformStripLines, Start: "x", End: "["}).Validate())
+	assert.Error(t, (&ContentTransform{Type: ContentTransformHeader, Header: "x", Files: "src/["}).Validate())
+}
+
+func TestWorkflow_UnmarshalYAML_ContentTransforms(t *testing.T) {
+	input := `
+name: python
+source:
+  repo: org/src
+destination:
+  repo: org/dest
+transformations:
+  - move: { from: "src", to: "dest" }
+content_transforms:
+  - type: replace
+    files: "**/*.py"
+    pattern: "mongodb\\+srv://[^\"]+"
+    replacement: "<connection-string>"
+  - type: strip_lines
+    start: "# start-internal"
+    end: "# end-internal"
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	require.Len(t, workflow.ContentTransforms, 2)
+	assert.Equal(t, ContentTransformReplace, workflow.ContentTransforms[0].Type)
+	assert.Equal(t, "<connection-string>", workflow.ContentTransforms[0].Replacement)
+	assert.Equal(t, "# end-internal", workflow.ContentTransforms[1].End)
+	assert.NoError(t, workflow.Validate())
+}
+
+func TestCommitStrategyConfig_SignOff(t *testing.T) {
+	input := `
+name: docs
+source:
+  repo: org/src
+destination:
+  repo: org/dest
+transformations:
+  - move: { from: "src", to: "dest" }
+commit_strategy:
+  type: pull_request
+  sign_off:
+    enabled: true
+    email: docs-bot@example.com
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	require.NotNil(t, workflow.CommitStrategy.SignOff)
+	assert.True(t, workflow.CommitStrategy.SignOff.Enabled)
+	assert.Equal(t, "docs-bot@example.com", workflow.CommitStrategy.SignOff.Email)
+	assert.NoError(t, workflow.Validate())
+
+	assert.Error(t, (&CommitStrategyConfig{SignOff: &SignOffConfig{Enabled: true, Email: "not-an-email"}}).Validate())
+	assert.Error(t, (&CommitStrategyConfig{SignOff: &SignOffConfig{Enabled: true, Name: "Bot <bot@example.com>"}}).Validate())
+}
+
+func TestCommitStrategyConfig_MessageTemplates(t *testing.T) {
+	input := `
+name: docs
+source:
+  repo: org/src
+destination:
+  repo: org/dest
+transformations:
+  - move: { from: "src", to: "dest" }
+variables:
+  team: docs-platform
+commit_strategy:
+  type: pull_request
+  pr_title: "{{ .SourcePRTitle | truncate 50 }} (${team})"
+  pr_body: |
+    Copied from {{ .SourcePRURL }} by @{{ .Author }}
+    {{ range .Files }}
+    - {{ . }}{{ end }}
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	assert.Equal(t, map[string]string{"team": "docs-platform"}, workflow.Variables)
+	assert.NoError(t, workflow.Validate())
+
+	tests := []struct {
+		name     string
+		strategy CommitStrategyConfig
+		wantErr  string
+	}{
+		{"unclosed action", CommitStrategyConfig{PRTitle: "Update {{ .SourceRepo"}, "pr_title: invalid template"},
+		{"unknown function", CommitStrategyConfig{PRBody: "{{ shout .Author }}"}, "pr_body: invalid template"},
+		{"unknown field", CommitStrategyConfig{CommitMessage: "{{ .PullRequest }}"}, "commit_message: invalid template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.strategy.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	// ${var} placeholders aren't Go templates and aren't parsed
+	assert.NoError(t, (&CommitStrategyConfig{PRTitle: "Update ${lang} {examples}"}).Validate())
+}
+
+func TestWorkflowConfig_SetDefaults_SignOff(t *testing.T) {
+	signOff := &SignOffConfig{Enabled: true}
+	workflowConfig := &WorkflowConfig{
+		Defaults: &Defaults{CommitStrategy: &CommitStrategyConfig{SignOff: signOff}},
+		Workflows: []Workflow{
+			{Name: "inherits", CommitStrategy: &CommitStrategyConfig{PRTitle: "Copy examples"}},
+			{Name: "overrides", CommitStrategy: &CommitStrategyConfig{SignOff: &SignOffConfig{Enabled: false}}},
+		},
+	}
+	workflowConfig.SetDefaults()
+
+	assert.Same(t, signOff, workflowConfig.Workflows[0].CommitStrategy.SignOff)
+	assert.False(t, workflowConfig.Workflows[1].CommitStrategy.SignOff.Enabled)
+}
+
+func TestProvenanceHeaderConfig(t *testing.T) {
+	var unset *ProvenanceHeaderConfig
+	assert.False(t, unset.IsEnabled())
+	assert.Equal(t, DefaultProvenanceTemplate, unset.GetTemplate())
+	assert.Equal(t, "Copied from ${source_path}", (&ProvenanceHeaderConfig{Template: "Copied from ${source_path}"}).GetTemplate())
+
+	assert.NoError(t, (&ProvenanceHeaderConfig{Enabled: true, CommentSyntax: map[string]string{".tf": "#", "Jenkinsfile": "//", ".adoc": "////", ".txt": ""}}).Validate())
+	assert.NoError(t, (&ProvenanceHeaderConfig{Enabled: true, CommentSyntax: map[string]string{".jsp": "<%-- --%>"}}).Validate())
+	assert.ErrorContains(t, (&ProvenanceHeaderConfig{Template: "line one\nline two"}).Validate(), "single line")
+	assert.Error(t, (&ProvenanceHeaderConfig{CommentSyntax: map[string]string{"docs/README": "#"}}).Validate())
+	assert.Error(t, (&ProvenanceHeaderConfig{CommentSyntax: map[string]string{".x": "(* *) extra"}}).Validate())
+
+	input := `
+name: go
+source:
+  repo: org/src
+destination:
+  repo: org/go-docs
+transformations:
+  - move: { from: "src", to: "dest" }
+provenance_header:
+  enabled: true
+  comment_syntax:
+    ".tmpl": "{{/* */}}"
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	assert.True(t, workflow.ProvenanceHeader.IsEnabled())
+	assert.Equal(t, "{{/* */}}", workflow.ProvenanceHeader.CommentSyntax[".tmpl"])
+	assert.NoError(t, workflow.Validate())
+}
+
+func TestCommitStrategyOnConflict(t *testing.T) {
+	var unset *CommitStrategyConfig
+	assert.Equal(t, OnConflictManual, unset.GetOnConflict())
+	assert.Equal(t, OnConflictSkip, (&CommitStrategyConfig{OnConflict: "skip"}).GetOnConflict())
+
+	assert.NoError(t, (&CommitStrategyConfig{Type: "pull_request", OnConflict: "overwrite"}).Validate())
+	assert.ErrorContains(t, (&CommitStrategyConfig{Type: "pull_request", OnConflict: "rebase"}).Validate(), "invalid on_conflict")
+
+	workflow := Workflow{
+		Name:            "bitbucket",
+		Source:          Source{Repo: "org/src"},
+		Destination:     Destination{Repo: "bitbucket:org/app"},
+		Transformations: []Transformation{{Move: &MoveTransform{From: "src", To: "dest"}}},
+		CommitStrategy:  &CommitStrategyConfig{Type: "pull_request", AutoMerge: true, OnConflict: "skip"},
+	}
+	assert.ErrorContains(t, workflow.Validate(), "on_conflict: only manual is supported")
+
+	config := &WorkflowConfig{
+		Defaults: &Defaults{CommitStrategy: &CommitStrategyConfig{Type: "pull_request", OnConflict: "overwrite"}},
+		Workflows: []Workflow{
+			{Name: "inherits", CommitStrategy: &CommitStrategyConfig{Type: "pull_request"}},
+			{Name: "own", CommitStrategy: &CommitStrategyConfig{Type: "pull_request", OnConflict: "skip"}},
+		},
+	}
+	config.SetDefaults()
+	assert.Equal(t, OnConflictOverwrite, config.Workflows[0].CommitStrategy.OnConflict)
+	assert.Equal(t, OnConflictSkip, config.Workflows[1].CommitStrategy.OnConflict)
+}
+
+func TestShadowConfig(t *testing.T) {
+	var unset *ShadowConfig
+	assert.False(t, unset.IsEnabled())
+	assert.False(t, (&ShadowConfig{}).IsEnabled())
+	assert.ErrorContains(t, (&ShadowConfig{Enabled: true, Notifications: &NotificationConfig{SlackWebhookURL: "http://hooks"}}).Validate(), "notifications")
+
+	input := `
+name: new-app
+source:
+  repo: org/src
+destination:
+  repo: org/app
+transformations:
+  - move: { from: "src", to: "dest" }
+shadow:
+  enabled: true
+  notifications:
+    slack_channel: "#app-owners"
+`
+	var workflow Workflow
+	require.NoError(t, yaml.Unmarshal([]byte(input), &workflow))
+	assert.True(t, workflow.Shadow.IsEnabled())
+	assert.Equal(t, "#app-owners", workflow.Shadow.Notifications.SlackChannel)
+	assert.NoError(t, workflow.Validate())
+}
+
+func TestConflictPolicy(t *testing.T) {
+	assert.Equal(t, ConflictPolicyAll, (&YAMLConfig{}).GetConflictPolicy())
+	assert.Equal(t, ConflictPolicyError, (&YAMLConfig{ConflictPolicy: "error"}).GetConflictPolicy())
+
+	config := &YAMLConfig{ConflictPolicy: "last-match-wins", Workflows: []Workflow{{
+		Name:            "wf",
+		Source:          Source{Repo: "org/src"},
+		Destination:     Destination{Repo: "org/dst"},
+		Transformations: []Transformation{{Move: &MoveTransform{From: "a", To: "b"}}},
+	}}}
+	config.SetDefaults()
+	assert.ErrorContains(t, config.Validate(), "invalid conflict_policy: last-match-wins")
+
+	config.ConflictPolicy = ConflictPolicyFirstMatchWins
+	assert.NoError(t, config.Validate())
+}
+
+func TestWorkflowsByPriority(t *testing.T) {
+	input := `
+conflict_policy: first-match-wins
+workflows:
+  - name: low
+    source: { repo: org/src }
+    destination: { repo: org/a }
+    transformations: [{ move: { from: "a", to: "b" } }]
+  - name: high
+    priority: 10
+    source: { repo: org/src }
+    destination: { repo: org/b }
+    transformations: [{ move: { from: "a", to: "b" } }]
+  - name: also-low
+    source: { repo: org/src }
+    destination: { repo: org/c }
+    transformations: [{ move: { from: "a", to: "b" } }]
+`
+	var config YAMLConfig
+	require.NoError(t, yaml.Unmarshal([]byte(input), &config))
+	assert.Equal(t, ConflictPolicyFirstMatchWins, config.GetConflictPolicy())
+	assert.Equal(t, 10, config.Workflows[1].Priority)
+
+	var names []string
+	for _, workflow := range config.WorkflowsByPriority() {
+		names = append(names, workflow.Name)
+	}
+	// Workflows with the same priority keep their config order
+	assert.Equal(t, []string{"high", "low", "also-low"}, names)
+	assert.Equal(t, "low", config.Workflows[0].Name, "the config's own order is unchanged")
+}
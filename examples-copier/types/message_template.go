@@ -0,0 +1,87 @@
+package types
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// MessageTemplateFuncs are the functions commit message and PR templates can call, in addition to
+// the text/template built-ins
+var MessageTemplateFuncs = template.FuncMap{
+	"join":     strings.Join,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"trim":     strings.TrimSpace,
+	"basename": path.Base,
+	"replace": func(old, new, s string) string {
+		return strings.ReplaceAll(s, old, new)
+	},
+	// default returns def if s is empty: {{ .Author | default "unknown" }}
+	"default": func(def, s string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	},
+	// truncate shortens s to at most n characters: {{ .SourcePRTitle | truncate 50 }}
+	"truncate": func(n int, s string) string {
+		runes := []rune(s)
+		if n < 0 || len(runes) <= n {
+			return s
+		}
+		return string(runes[:n])
+	},
+}
+
+// IsGoTemplate reports whether a message template uses Go template syntax. Templates that only use
+// ${var} placeholders are rendered by substitution alone.
+func IsGoTemplate(text string) bool {
+	return strings.Contains(text, "{{")
+}
+
+// ParseMessageTemplate parses a commit message or PR template. Missing map keys, such as an unset
+// custom variable, render as empty strings.
+func ParseMessageTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(MessageTemplateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// ExecuteMessageTemplate parses a commit message or PR template and renders it with the message context
+func ExecuteMessageTemplate(name, text string, ctx *MessageContext) (string, error) {
+	tmpl, err := ParseMessageTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ctx); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// validateMessageTemplate checks that a template parses and renders against an example context, so
+// syntax errors and references to fields that don't exist fail when the config loads rather than
+// when the copier opens a PR
+func validateMessageTemplate(name, text string) error {
+	if !IsGoTemplate(text) {
+		return nil
+	}
+	example := &MessageContext{
+		RuleName:      "example-workflow",
+		SourceRepo:    "owner/source",
+		TargetRepo:    "owner/target",
+		SourceBranch:  "main",
+		TargetBranch:  "main",
+		FileCount:     1,
+		PRNumber:      1,
+		CommitSHA:     "0000000",
+		SourcePRURL:   "https://github.com/owner/source/pull/1",
+		SourcePRTitle: "Example",
+		Author:        "octocat",
+		Files:         []string{"example.go"},
+		Variables:     map[string]string{},
+	}
+	_, err := ExecuteMessageTemplate(name, text, example)
+	return err
+}
@@ -116,14 +116,37 @@ type UploadFileContent struct {
 	PRBody         string                     `json:"pr_body,omitempty"`
 	UsePRTemplate  bool                       `json:"use_pr_template,omitempty"`  // If true, fetch and merge PR template from target repo
 	AutoMergePR    bool                       `json:"auto_merge_pr,omitempty"`
+	// ApprovalGate holds an auto-merged PR until it's approved; nil merges it as soon as it's opened
+	ApprovalGate *ApprovalGateConfig `json:"approval_gate,omitempty"`
+	// Reviewers requests reviews on the PR the copier opens; nil requests none
+	Reviewers *ReviewersConfig `json:"reviewers,omitempty"`
+	// OnConflict is what happens when the auto-merged PR conflicts with the target branch; empty means OnConflictManual
+	OnConflict string `json:"on_conflict,omitempty"`
+	// FileModes holds the Git file mode for files that aren't regular files (e.g. "100755" for executable
+	// scripts), keyed by target path. Files without an entry are written with FileModeRegular.
+	FileModes map[string]string `json:"file_modes,omitempty"`
+	// DeletePaths are target paths to remove in the same commit, from transformations with sync enabled
+	DeletePaths []string `json:"delete_paths,omitempty"`
+	// SignOff is set when commits to the target must be signed off for a DCO check
+	SignOff *SignOffConfig `json:"sign_off,omitempty"`
+	// PushBranch is the branch the branch strategy pushes to, named after the source PR
+	PushBranch string `json:"push_branch,omitempty"`
 }
 
+// Git file modes for blob tree entries
+const (
+	FileModeRegular    = "100644"
+	FileModeExecutable = "100755"
+)
+
 // CommitStrategy represents the strategy for committing changes
 type CommitStrategy string
 
 const (
 	CommitStrategyDirect CommitStrategy = "direct"
 	CommitStrategyPR     CommitStrategy = "pull_request"
+	// CommitStrategyBranch pushes to a branch named after the source PR, without opening a PR
+	CommitStrategyBranch CommitStrategy = "branch"
 )
 
 type CreateFileRequest struct {